@@ -2,6 +2,8 @@ package main
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
 	"fmt"
 	"io/fs"
 	"log"
@@ -10,19 +12,38 @@ import (
 	"os/signal"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"headless_form/internal/adapter/api"
+	"headless_form/internal/adapter/api/request"
+	"headless_form/internal/adapter/archive"
+	"headless_form/internal/adapter/chat"
 	"headless_form/internal/adapter/email"
+	"headless_form/internal/adapter/events"
+	"headless_form/internal/adapter/filestore"
+	"headless_form/internal/adapter/httpstats"
+	"headless_form/internal/adapter/logger"
 	"headless_form/internal/adapter/middleware"
+	"headless_form/internal/adapter/monitor"
+	"headless_form/internal/adapter/pdf"
+	"headless_form/internal/adapter/secrets"
+	"headless_form/internal/adapter/spam"
+	"headless_form/internal/adapter/spool"
+	"headless_form/internal/adapter/sqltrace"
 	"headless_form/internal/adapter/storage/sqlite"
+	"headless_form/internal/adapter/transform"
 	"headless_form/internal/adapter/webhook"
 	"headless_form/internal/core/domain"
+	"headless_form/internal/core/ids"
+	"headless_form/internal/core/passhash"
+	"headless_form/internal/core/ports"
 	"headless_form/internal/core/service"
 	"headless_form/web"
 
 	"github.com/joho/godotenv"
+	"golang.org/x/crypto/bcrypt"
 )
 
 func main() {
@@ -31,6 +52,25 @@ func main() {
 		log.Println("No .env file found, using environment variables")
 	}
 
+	// Logging: LOG_LEVEL accepts per-module overrides, e.g. "info,webhook=debug,sql=warn"
+	logLevel, moduleLevels, err := logger.ParseLevels(os.Getenv("LOG_LEVEL"))
+	if err != nil {
+		log.Fatalf("Invalid LOG_LEVEL: %v", err)
+	}
+	logMaxSize, _ := strconv.Atoi(os.Getenv("LOG_MAX_SIZE_MB"))
+	logMaxAge, _ := time.ParseDuration(os.Getenv("LOG_MAX_AGE"))
+	if err := logger.Init(logger.Config{
+		Level:        logLevel,
+		ModuleLevels: moduleLevels,
+		Format:       os.Getenv("LOG_FORMAT"),
+		File:         os.Getenv("LOG_FILE"),
+		MaxSizeMB:    logMaxSize,
+		MaxAge:       logMaxAge,
+	}); err != nil {
+		log.Fatalf("Failed to init logging: %v", err)
+	}
+	defer func() { _ = logger.Close() }()
+
 	// 1. Environment Config
 	port := os.Getenv("PORT")
 	if port == "" {
@@ -49,18 +89,96 @@ func main() {
 		baseURL = fmt.Sprintf("http://localhost:%s", port)
 	}
 
-	// 2. Storage
+	// ID generation: "uuid" (default) or "short" prefixed IDs (form_x7Ab3...).
+	// Existing UUIDs stay valid either way.
+	// ID_SHORT_LENGTH sets the length for every entity, ID_SHORT_LENGTH_<ENTITY> (e.g.
+	// ID_SHORT_LENGTH_SUBMISSION) overrides it for one.
+	if os.Getenv("ID_STYLE") == string(ids.StyleShort) {
+		config := ids.ShortConfig(envIDLength("ID_SHORT_LENGTH"))
+		for entity, cfg := range config.Entities {
+			if length := envIDLength("ID_SHORT_LENGTH_" + strings.ToUpper(string(entity))); length != 0 {
+				cfg.Length = length
+				config.Entities[entity] = cfg
+			}
+		}
+		if err := config.Validate(); err != nil {
+			log.Fatalf("Invalid ID settings: %v", err)
+		}
+		ids.SetDefault(ids.NewGenerator(config))
+		log.Println("🆔 Using short prefixed IDs for new records")
+	}
+
+	// Password hashing: bcrypt (default) or argon2id. Existing hashes keep working and are
+	// upgraded on the user's next login when they use another algorithm or weaker parameters.
+	switch alg := passhash.Algorithm(os.Getenv("PASSWORD_HASH")); alg {
+	case "", passhash.AlgorithmBcrypt:
+		cost, _ := strconv.Atoi(os.Getenv("BCRYPT_COST"))
+		if cost != 0 && (cost < bcrypt.MinCost || cost > bcrypt.MaxCost) {
+			log.Fatalf("Invalid BCRYPT_COST %d (want %d-%d)", cost, bcrypt.MinCost, bcrypt.MaxCost)
+		}
+		passhash.SetDefault(passhash.Bcrypt{Cost: cost})
+	case passhash.AlgorithmArgon2id:
+		memory, _ := strconv.ParseUint(os.Getenv("ARGON2_MEMORY_KB"), 10, 32)
+		iterations, _ := strconv.ParseUint(os.Getenv("ARGON2_ITERATIONS"), 10, 32)
+		parallelism, _ := strconv.ParseUint(os.Getenv("ARGON2_PARALLELISM"), 10, 8)
+		hasher := passhash.Argon2id{Memory: uint32(memory), Iterations: uint32(iterations), Parallelism: uint8(parallelism)}
+		if err := hasher.Validate(); err != nil {
+			log.Fatalf("Invalid ARGON2_* settings: %v", err)
+		}
+		passhash.SetDefault(hasher)
+		log.Println("🔑 Hashing passwords with Argon2id")
+	default:
+		log.Fatalf("Invalid PASSWORD_HASH %q (want bcrypt or argon2id)", alg)
+	}
+
+	// 2. Storage: SQLite only. The Postgres repositories aren't finished, so the server
+	// doesn't run on DATABASE_URL.
+	if os.Getenv("DATABASE_URL") != "" {
+		log.Println("⚠️  DATABASE_URL is ignored: the server only runs on SQLite so far")
+	}
 	dataDir := os.Getenv("DATA_DIR")
+	if os.Getenv("DB_ENCRYPTION_KEY") != "" {
+		// The pure-Go driver can't open SQLCipher databases. Refuse to start rather than
+		// keep data unencrypted while the operator believes otherwise.
+		log.Fatalf("DB_ENCRYPTION_KEY is set, but database encryption is not supported by this build; encrypt the DATA_DIR volume instead (see docs/DEPLOYMENT.md)")
+	}
 	dbPath := "data.db"
 	if dataDir != "" {
 		dbPath = filepath.Join(dataDir, "data.db")
 	}
 
-	store, err := sqlite.New(dbPath)
+	// Optional query instrumentation: statements slower than SQL_SLOW_QUERY are logged
+	// and reported at /api/v1/admin/db-stats
+	var queryMonitor *sqltrace.Monitor
+	if v, perr := time.ParseDuration(os.Getenv("SQL_SLOW_QUERY")); perr == nil && v > 0 {
+		queryMonitor = sqltrace.NewMonitor(v)
+	}
+	var store *sqlite.Store
+	if queryMonitor != nil {
+		store, err = sqlite.NewWithMonitor(dbPath, queryMonitor)
+	} else {
+		store, err = sqlite.New(dbPath)
+	}
 	if err != nil {
 		log.Fatalf("Failed to init storage: %v", err)
 	}
 
+	// Secret columns are encrypted with SECRETS_KEY, or reference Vault / AWS Secrets Manager
+	secretCodec, err := newSecretCodec()
+	if err != nil {
+		log.Fatalf("Invalid secrets configuration: %v", err)
+	}
+	store.SetSecretCodec(secretCodec)
+	if secretCodec.Encrypts() {
+		sealed, err := store.SealSecrets(context.Background())
+		if err != nil {
+			log.Fatalf("Failed to encrypt stored secrets: %v", err)
+		}
+		if sealed > 0 {
+			log.Printf("🔐 Encrypted %d stored secrets with SECRETS_KEY", sealed)
+		}
+	}
+
 	// 3. Email Configuration
 	smtpPort, _ := strconv.Atoi(os.Getenv("SMTP_PORT"))
 	if smtpPort == 0 {
@@ -83,6 +201,8 @@ func main() {
 	}
 
 	emailService := email.NewService(emailConfig)
+	// Emails are queued in the database and sent by a background worker that retries failures
+	emailService.SetQueue(store.EmailQueue())
 
 	if emailConfig.Enabled {
 		log.Printf("📧 Email notifications enabled (SMTP: %s:%d)", emailConfig.Host, emailConfig.Port)
@@ -97,39 +217,344 @@ func main() {
 	authService := service.NewAuthService(store, service.AuthConfig{
 		JWTSecret:     jwtSecret,
 		TokenDuration: 24 * time.Hour,
+		// Escape hatch when the site settings disable password login and SSO breaks
+		ForcePasswordLogin: os.Getenv("FORCE_PASSWORD_LOGIN") == "true",
 	})
 
+	// Per-operation database timeouts (e.g. OPERATION_TIMEOUTS=lookup=5s,export=30s)
+	timeouts, err := service.ParseTimeouts(os.Getenv("OPERATION_TIMEOUTS"))
+	if err != nil {
+		log.Fatalf("Invalid OPERATION_TIMEOUTS: %v", err)
+	}
+	formService.SetTimeouts(timeouts)
+	submService.SetTimeouts(timeouts)
+	statsService.SetTimeouts(timeouts)
+	authService.SetTimeouts(timeouts)
+
+	// Masked exports hash values with a server key, so they can't be matched to guesses
+	submService.SetExportHashKey(exportHashKey(jwtSecret))
+
 	// 5. Webhook service
 	webhookService := webhook.NewService()
 	log.Println("🔗 Webhook service initialized")
 
-	// 6. Notification callback (email + webhook)
+	// Every delivery and its attempts are logged, for debugging and replays
+	webhookService.SetDeliveryLog(store.WebhookDelivery())
+	// Webhook secrets referencing Vault / AWS Secrets Manager are only resolved to sign payloads
+	webhookService.SetSecretCodec(secretCodec)
+	webhookDeliveryService := service.NewWebhookDeliveryService(store)
+	webhookDeliveryService.SetTimeouts(timeouts)
+	webhookDeliveryService.SetReplayer(webhookService.Replay)
+
+	// In-app notification center, fed by the submission, alert, comment and webhook callbacks
+	notificationService := service.NewNotificationService(store)
+	notificationService.SetTimeouts(timeouts)
+	webhookService.SetFailureCallback(notificationService.NotifyWebhookFailed)
+
+	// Slack and Discord channels set per form
+	chatService := chat.NewService()
+
+	// 6. Notification callback (email, webhook and chat channels)
 	submService.SetNotificationCallback(func(form *domain.Form, submission *domain.Submission, data map[string]interface{}) {
-		// Send email notification
-		if len(form.NotifyEmails) > 0 {
+		// Email the recipients of the notify rules the submission matches, or the notify list
+		if recipients := form.NotifyRecipients(data); len(recipients) > 0 {
+			// Field labels in the language the submitter's browser asked for
+			locale := form.MatchLocale(request.ParseAcceptLanguage(submission.Meta.Server.Language))
 			emailData := email.SubmissionData{
 				FormName:     form.Name,
 				FormID:       form.PublicID,
 				SubmissionID: submission.ID,
 				SubmittedAt:  submission.CreatedAt,
 				Fields:       data,
+				Labels:       form.Labels(locale),
 				DashboardURL: fmt.Sprintf("%s/forms/%s", baseURL, form.PublicID),
+				FieldLimit:   form.EmailFieldLimit,
+				Test:         submission.IsTest,
+			}
+			if t := form.EmailTemplate; t != nil {
+				emailData.Template = &email.Template{Subject: t.Subject, HTML: t.HTML, Text: t.Text}
+			}
+			if form.AttachPDF && len(recipients[domain.NotifyTemplateFull]) > 0 {
+				emailData.Attachments = []email.Attachment{{
+					Filename:    "submission_" + submission.ID + ".pdf",
+					ContentType: "application/pdf",
+					Data:        pdf.Render(pdf.SubmissionDocument(form, submission, data, true, locale)),
+				}}
 			}
 
-			if err := emailService.SendSubmissionNotification(form.NotifyEmails, emailData); err != nil {
-				log.Printf("Failed to send email notification: %v", err)
+			for _, template := range []domain.NotifyTemplate{domain.NotifyTemplateFull, domain.NotifyTemplateSummary} {
+				if len(recipients[template]) == 0 {
+					continue
+				}
+				emailData.Summary = template == domain.NotifyTemplateSummary
+				if err := emailService.SendSubmissionNotification(recipients[template], emailData); err != nil {
+					log.Printf("Failed to send email notification: %v", err)
+				}
+			}
+		}
+
+		// Confirm the submission to the submitter; test submissions aren't confirmed
+		if reply := form.AutoReply; reply != nil && !submission.IsTest {
+			if to := reply.Recipient(data); to != "" {
+				locale := form.MatchLocale(request.ParseAcceptLanguage(submission.Meta.Server.Language))
+				replyData := email.SubmissionData{
+					FormName:     form.Name,
+					FormID:       form.PublicID,
+					SubmissionID: submission.ID,
+					SubmittedAt:  submission.CreatedAt,
+					Fields:       data,
+					Labels:       form.Labels(locale),
+				}
+				tmpl := email.Template{Subject: reply.Subject, HTML: reply.HTML, Text: reply.Text}
+				if err := emailService.SendAutoReply(to, reply.ReplyTo, tmpl, replyData); err != nil {
+					log.Printf("Failed to send auto-reply: %v", err)
+				}
 			}
 		}
 
 		// Trigger webhook
 		webhookService.TriggerSubmission(form, submission, data)
+
+		// Post to the form's Slack and Discord channels
+		if len(form.Channels) > 0 {
+			locale := form.MatchLocale(request.ParseAcceptLanguage(submission.Meta.Server.Language))
+			dashboardURL := fmt.Sprintf("%s/forms/%s", baseURL, form.PublicID)
+			chatService.NotifySubmission(context.Background(), form, submission, data, form.Labels(locale), dashboardURL)
+		}
+
+		// Notify users who starred the form
+		notificationService.NotifySubmission(form, submission)
+	})
+	// Tombstone webhooks for deleted submissions
+	submService.SetDeletionCallback(webhookService.TriggerDeletion)
+
+	// Teams sharing forms (memberships are checked by the auth middleware)
+	teamService := service.NewTeamService(store)
+	teamService.SetTimeouts(timeouts)
+
+	// Alerts (evaluated in the background, delivered by email to the form's notify list)
+	alertService := service.NewAlertService(store)
+	alertService.SetTimeouts(timeouts)
+	alertService.SetWebhookStatsProvider(webhookService.DeliveryStats)
+	alertService.SetAlertCallback(func(form *domain.Form, rule *domain.AlertRule, message string) {
+		log.Printf("[ALERT] %s (%s): %s", form.Name, rule.Type, message)
+		notificationService.NotifyAlert(form, rule, message)
+		if len(form.NotifyEmails) == 0 {
+			return
+		}
+		dashboardURL := fmt.Sprintf("%s/forms/%s", baseURL, form.PublicID)
+		if err := emailService.SendAlert(form.NotifyEmails, form.Name, message, dashboardURL); err != nil {
+			log.Printf("Failed to send alert email: %v", err)
+		}
+	})
+	// Read-only maintenance mode for backups and migrations: writes get 503 and the purge jobs
+	// pause. Toggled with PUT /api/v1/admin/maintenance, or on from startup with MAINTENANCE_MODE=true.
+	maintenance := middleware.NewMaintenance()
+	if os.Getenv("MAINTENANCE_MODE") == "true" {
+		maintenance.Enable(os.Getenv("MAINTENANCE_MESSAGE"), 0, "")
+		log.Println("🚧 Maintenance mode: the instance is read-only")
+	}
+
+	// Scheduled jobs (purges, alerts, monitoring, demo resets) run on one instance at a time when several
+	// share the database, coordinated through the locks table. INSTANCE_ID names this instance.
+	jobLocks := service.NewJobLocks(store, os.Getenv("INSTANCE_ID"))
+
+	bgCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+	alertService.Start(bgCtx, 5*time.Minute, jobLocks.Paused(service.JobAlerts, 5*time.Minute, maintenance.Enabled))
+
+	// Submission comments; mentioned users are emailed a link to the submission
+	commentService := service.NewCommentService(store)
+	commentService.SetTimeouts(timeouts)
+	commentService.SetMentionCallback(func(m service.Mention) {
+		notificationService.NotifyMention(m)
+		author := m.Author.Name
+		if author == "" {
+			author = m.Author.Email
+		}
+		dashboardURL := fmt.Sprintf("%s/forms/%s", baseURL, m.Form.PublicID)
+		if err := emailService.SendMention(m.Mentioned.Email, author, m.Form.Name, m.Comment.Body, dashboardURL); err != nil {
+			log.Printf("Failed to send mention email: %v", err)
+		}
 	})
 
+	// Files uploaded with submissions go to a local directory or an S3-compatible bucket
+	var fileStorage ports.FileStorage
+	switch os.Getenv("UPLOAD_STORAGE") {
+	case "s3":
+		s3Store, err := filestore.NewS3(filestore.S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Region:    os.Getenv("S3_REGION"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+			PathStyle: os.Getenv("S3_PATH_STYLE") == "true",
+		})
+		if err != nil {
+			log.Fatalf("Failed to configure S3 upload storage: %v", err)
+		}
+		fileStorage = s3Store
+		log.Printf("📎 Uploads stored in S3 bucket %s", os.Getenv("S3_BUCKET"))
+	case "", "local":
+		uploadDir := os.Getenv("UPLOAD_DIR")
+		if uploadDir == "" {
+			uploadDir = filepath.Join(dataDir, "uploads")
+		}
+		fileStorage = filestore.NewLocal(uploadDir)
+	default:
+		log.Fatalf("Unknown UPLOAD_STORAGE %q (use local or s3)", os.Getenv("UPLOAD_STORAGE"))
+	}
+	submService.SetFileStorage(fileStorage)
+	formService.SetFileStorage(fileStorage)
+
+	// Deleted forms are archived, then kept in the trash (restorable) until the retention job purges them
+	archiveDir := os.Getenv("ARCHIVE_DIR")
+	if archiveDir == "" {
+		archiveDir = filepath.Join(dataDir, "archives")
+	}
+	formService.SetArchiver(archive.New(archiveDir).Write)
+	if v, err := time.ParseDuration(os.Getenv("FORM_DELETE_GRACE")); err == nil && v > 0 {
+		formService.SetDeleteGracePeriod(v)
+	}
+	formService.StartPurge(bgCtx, time.Hour, jobLocks.Paused(service.JobFormPurge, time.Hour, maintenance.Enabled))
+
+	// Test submissions (seeded data) are kept out of stats and purged after TEST_DATA_RETENTION
+	if v, err := time.ParseDuration(os.Getenv("TEST_DATA_RETENTION")); err == nil && v > 0 {
+		submService.SetTestRetention(v)
+	}
+	submService.StartTestPurge(bgCtx, time.Hour, jobLocks.Paused(service.JobTestPurge, time.Hour, maintenance.Enabled))
+
+	// Submissions past their form's retention period are deleted or anonymized
+	submService.StartRetention(bgCtx, time.Hour, jobLocks.Paused(service.JobRetention, time.Hour, maintenance.Enabled))
+
+	// The webhook delivery log, which holds submission data in its payloads, is pruned after WEBHOOK_LOG_RETENTION
+	if v, err := time.ParseDuration(os.Getenv("WEBHOOK_LOG_RETENTION")); err == nil && v > 0 {
+		webhookDeliveryService.SetRetention(v)
+	}
+	webhookDeliveryService.StartPrune(bgCtx, time.Hour, jobLocks.Paused(service.JobWebhookLog, time.Hour, maintenance.Enabled))
+
+	// Queued emails are sent by one instance; sent and dead ones are pruned after EMAIL_QUEUE_RETENTION
+	emailQueueService := service.NewEmailQueueService(store)
+	emailQueueService.SetTimeouts(timeouts)
+	if v, err := time.ParseDuration(os.Getenv("EMAIL_QUEUE_RETENTION")); err == nil && v > 0 {
+		emailQueueService.SetRetention(v)
+	}
+	if emailConfig.Enabled {
+		emailService.StartQueue(bgCtx, 30*time.Second, jobLocks.Paused(service.JobEmailQueue, 30*time.Second, maintenance.Enabled))
+	}
+	emailQueueService.StartPrune(bgCtx, time.Hour, jobLocks.Paused(service.JobEmailPrune, time.Hour, maintenance.Enabled))
+
+	// Content of submissions marked as spam is quarantined on every form until SPAM_FINGERPRINT_TTL passes without it
+	fingerprintService := service.NewSpamFingerprintService(store)
+	fingerprintService.SetTimeouts(timeouts)
+	if v, err := time.ParseDuration(os.Getenv("SPAM_FINGERPRINT_TTL")); err == nil && v > 0 {
+		fingerprintService.SetTTL(v)
+	}
+	submService.SetSpamCallback(fingerprintService.Learn)
+	fingerprintService.StartPrune(bgCtx, time.Hour, jobLocks.Paused(service.JobSpamPrune, time.Hour, maintenance.Enabled))
+
+	// Optional queue-and-forward: submissions the database refuses are kept on disk and stored once it recovers
+	if os.Getenv("SUBMISSION_QUEUE") == "true" {
+		queueDir := os.Getenv("SUBMISSION_QUEUE_DIR")
+		if queueDir == "" {
+			queueDir = filepath.Join(dataDir, "queue")
+		}
+		queueMax, _ := strconv.Atoi(os.Getenv("SUBMISSION_QUEUE_MAX"))
+		queue, err := spool.Open(queueDir, queueMax)
+		if err != nil {
+			log.Fatalf("Failed to open submission queue: %v", err)
+		}
+		submService.SetQueue(queue)
+		submService.StartQueueReplay(bgCtx, 15*time.Second, maintenance.Enabled)
+		log.Printf("📥 Submission queue enabled (%s, %d queued)", queueDir, queue.Len())
+	}
+
+	// Background tasks; anything left running by a previous process can't resume
+	taskService := service.NewTaskService(store)
+	if n, err := taskService.FailInterrupted(bgCtx); err != nil {
+		log.Printf("⚠️  Failed to clean up interrupted tasks: %v", err)
+	} else if n > 0 {
+		log.Printf("⚠️  Marked %d interrupted task(s) as failed", n)
+	}
+	// New submissions are streamed to dashboards following their form (see SubmissionFeed)
+	submissionFeed := service.NewSubmissionFeed()
+	submService.SetFeed(submissionFeed)
+	// Task progress and new submissions are streamed from whichever instance the dashboard is connected to
+	if redisURL := os.Getenv("REDIS_URL"); redisURL != "" {
+		bus, err := events.NewRedis(redisURL, os.Getenv("REDIS_CHANNEL"))
+		if err != nil {
+			log.Fatalf("Failed to configure Redis events: %v", err)
+		}
+		taskService.SetEventBus(bus)
+		submissionFeed.SetEventBus(bus)
+		log.Printf("📡 Real-time events shared through Redis")
+	}
+
+	// Files tasks produce (exports) are kept with the uploads until EXPORT_RETENTION has passed
+	taskService.SetFileStorage(fileStorage)
+	if v, err := time.ParseDuration(os.Getenv("EXPORT_RETENTION")); err == nil && v > 0 {
+		taskService.SetFileRetention(v)
+	}
+	taskService.StartFilePurge(bgCtx, time.Hour, jobLocks.Paused(service.JobTaskFiles, time.Hour, maintenance.Enabled))
+
+	// Demo mode: a public demo login over sample data, wiped and reseeded every DEMO_RESET_INTERVAL.
+	// Account and settings changes are refused (see middleware.DemoBlockedRoutes).
+	var demoService *service.DemoService
+	if os.Getenv("DEMO_MODE") == "true" {
+		demoService = service.NewDemoService(store, store.Reset, service.DemoCredentials{
+			Email:    os.Getenv("DEMO_EMAIL"),
+			Password: os.Getenv("DEMO_PASSWORD"),
+		})
+		demoService.SetTimeouts(timeouts)
+		if err := demoService.Seed(bgCtx); err != nil {
+			log.Fatalf("Failed to seed demo data: %v", err)
+		}
+		resetInterval := service.DefaultDemoResetInterval
+		if v, err := time.ParseDuration(os.Getenv("DEMO_RESET_INTERVAL")); err == nil && v > 0 {
+			resetInterval = v
+		}
+		demoService.Start(bgCtx, resetInterval, jobLocks.Paused(service.JobDemoReset, resetInterval, maintenance.Enabled))
+		log.Printf("🎭 Demo mode enabled: log in as %s, data resets every %s", demoService.Credentials().Email, resetInterval)
+	}
+
+	// Declarative provisioning: users and forms from FORMS_CONFIG_FILE are created or updated on boot
+	if path := os.Getenv("FORMS_CONFIG_FILE"); path != "" {
+		provisioner := service.NewProvisionService(store, formService)
+		provisioner.SetTimeouts(timeouts)
+		if err := provision(bgCtx, path, provisioner); err != nil {
+			log.Fatalf("Failed to apply %s: %v", path, err)
+		}
+	}
+
 	// 6. Auth Handler
 	authHandler := api.NewAuthHandler(authService, emailService, baseURL)
+	if demoService != nil {
+		authHandler.SetDemoCredentials(demoService.Credentials())
+	}
 
 	// 7. API Router
 	router := api.NewRouter(formService, submService, statsService)
+	router.SetBaseURL(baseURL)
+	router.SetQueryMonitor(queryMonitor)
+	// Request counts, error rates and latencies per route, at /api/v1/admin/http-stats
+	httpStats := httpstats.NewCollector()
+	router.SetHTTPStats(httpStats)
+	router.SetMaintenance(maintenance)
+	// External spam service (Akismet), chosen in the site settings
+	router.SetSpamProviders(spam.SettingsProviders(store.Settings(), nil))
+	router.SetContentFilters(spam.SettingsFilters(store.Settings()))
+	router.SetSpamSettings(spam.SettingsSpam(store.Settings()))
+	router.SetSpamFingerprints(fingerprintService)
+	router.SetSubmissionFeed(submissionFeed)
+	// Test data seeding: super_admin only, and never in production unless ALLOW_SEED=true.
+	// Users can always create and purge sample data of their own.
+	router.SetTasks(taskService)
+	seedService := service.NewSeedService(store, taskService)
+	seedService.SetTimeouts(timeouts)
+	router.SetSeeder(seedService, isDev || os.Getenv("ALLOW_SEED") == "true")
+	fieldMigrations := service.NewFieldMigrationService(store, taskService)
+	fieldMigrations.SetTimeouts(timeouts)
+	router.SetFieldMigrations(fieldMigrations)
 	mux := http.NewServeMux()
 
 	// Auth routes (public with rate limiting)
@@ -145,8 +570,33 @@ func main() {
 	mux.Handle("POST /api/v1/auth/reset-password",
 		middleware.AuthLimiter.Middleware()(http.HandlerFunc(authHandler.HandleResetPassword)))
 
+	// SSO sign-in with the providers in the site settings (public with rate limiting)
+	mux.Handle("GET /api/v1/auth/oauth/{provider}/start",
+		middleware.AuthLimiter.Middleware()(http.HandlerFunc(authHandler.HandleOAuthStart)))
+	mux.Handle("GET /api/v1/auth/oauth/{provider}/callback",
+		middleware.AuthLimiter.Middleware()(http.HandlerFunc(authHandler.HandleOAuthCallback)))
+
 	// Protected auth routes
-	authMiddleware := middleware.AuthMiddleware(authService)
+	formTokenService := service.NewFormTokenService(store)
+	formTokenService.SetTimeouts(timeouts)
+	authenticate := middleware.AuthMiddleware(authService, formTokenService)
+
+	// Dashboard API calls are rate limited per user/token rather than per IP,
+	// with optional per-role quotas (e.g. RATE_LIMIT_ROLES=admin=1000,form_token=600)
+	if v, err := strconv.Atoi(os.Getenv("RATE_LIMIT_API")); err == nil && v > 0 {
+		middleware.APILimiter.SetLimit(v)
+	}
+	roleLimits, err := middleware.ParseRoleLimits(os.Getenv("RATE_LIMIT_ROLES"))
+	if err != nil {
+		log.Fatalf("Invalid RATE_LIMIT_ROLES: %v", err)
+	}
+	for role, limit := range roleLimits {
+		middleware.APILimiter.SetRoleLimit(role, limit)
+	}
+	identityLimit := middleware.APILimiter.IdentityMiddleware()
+	authMiddleware := func(next http.Handler) http.Handler {
+		return authenticate(identityLimit(next))
+	}
 	mux.Handle("GET /api/v1/auth/me",
 		authMiddleware(http.HandlerFunc(authHandler.HandleMe)))
 
@@ -176,6 +626,70 @@ func main() {
 		authMiddleware(http.HandlerFunc(settingsHandler.HandleUpdateSettings)))
 	mux.Handle("POST /api/v1/settings/test-smtp",
 		authMiddleware(http.HandlerFunc(settingsHandler.HandleTestSMTP)))
+	mux.Handle("GET /api/v1/settings/filters",
+		authMiddleware(http.HandlerFunc(settingsHandler.HandleGetFilters)))
+	mux.Handle("PUT /api/v1/settings/filters",
+		authMiddleware(http.HandlerFunc(settingsHandler.HandleSetFilters)))
+	mux.Handle("GET /api/v1/settings/spam",
+		authMiddleware(http.HandlerFunc(settingsHandler.HandleGetSpam)))
+	mux.Handle("PUT /api/v1/settings/spam",
+		authMiddleware(http.HandlerFunc(settingsHandler.HandleSetSpam)))
+	mux.Handle("GET /api/v1/settings/oauth",
+		authMiddleware(http.HandlerFunc(settingsHandler.HandleGetOAuth)))
+	mux.Handle("PUT /api/v1/settings/oauth",
+		authMiddleware(http.HandlerFunc(settingsHandler.HandleSetOAuth)))
+	mux.Handle("GET /api/v1/settings/exports",
+		authMiddleware(http.HandlerFunc(settingsHandler.HandleGetExports)))
+	mux.Handle("PUT /api/v1/settings/exports",
+		authMiddleware(http.HandlerFunc(settingsHandler.HandleSetExports)))
+
+	// Teams sharing forms among their members (team members or admin)
+	teamHandler := api.NewTeamHandler(teamService, formService)
+	teamHandler.RegisterRoutes(mux, authMiddleware)
+
+	// Alert rule routes (form owner or admin)
+	alertHandler := api.NewAlertHandler(alertService, formService)
+	alertHandler.RegisterRoutes(mux, authMiddleware)
+
+	// Read-only API tokens scoped to one form (form owner or admin)
+	formTokenHandler := api.NewFormTokenHandler(formTokenService, formService)
+	formTokenHandler.RegisterRoutes(mux, authMiddleware)
+
+	// Comment threads on submissions (form owner or admin)
+	commentHandler := api.NewCommentHandler(commentService, submService, formService)
+	commentHandler.RegisterRoutes(mux, authMiddleware)
+
+	// Webhook delivery log and replays (form owner or admin)
+	webhookDeliveryHandler := api.NewWebhookDeliveryHandler(webhookDeliveryService, formService, submService)
+	webhookDeliveryHandler.RegisterRoutes(mux, authMiddleware)
+
+	// Outgoing email queue (admin only)
+	emailQueueHandler := api.NewEmailQueueHandler(emailQueueService)
+	emailQueueHandler.RegisterRoutes(mux, authMiddleware)
+	spamFingerprintHandler := api.NewSpamFingerprintHandler(fingerprintService)
+	spamFingerprintHandler.RegisterRoutes(mux, authMiddleware)
+
+	// The current user's notification center
+	notificationHandler := api.NewNotificationHandler(notificationService)
+	notificationHandler.RegisterRoutes(mux, authMiddleware)
+
+	// Background task status, cancellation and progress stream (creator or admin)
+	taskHandler := api.NewTaskHandler(taskService)
+	taskHandler.RegisterRoutes(mux, authMiddleware)
+
+	// Content-Security-Policy violation reports from browsers (logged)
+	mux.Handle("POST "+middleware.CSPReportPath,
+		middleware.PublicLimiter.Middleware()(http.HandlerFunc(middleware.HandleCSPReport)))
+
+	// Heartbeat monitor (dry-run self-checks of active forms, public status + badge)
+	monitorInterval := 5 * time.Minute
+	if v, err := time.ParseDuration(os.Getenv("MONITOR_INTERVAL")); err == nil && v > 0 {
+		monitorInterval = v
+	}
+	monitorService := monitor.NewService(baseURL, formService.ListForms, formService.GetForm)
+	monitorService.Start(bgCtx, monitorInterval, jobLocks.Paused(service.JobMonitor, monitorInterval, maintenance.Enabled))
+	monitorHandler := api.NewMonitorHandler(monitorService, formService)
+	monitorHandler.RegisterPublicRoutes(mux)
 
 	// Register public routes (with optional auth for private form submissions)
 	optionalAuth := middleware.OptionalAuthMiddleware(authService)
@@ -192,21 +706,25 @@ func main() {
 		log.Fatalf("Failed to load embedded web assets: %v", err)
 	}
 	fileServer := http.FileServer(http.FS(webBuild))
+	indexHTML, err := fs.ReadFile(webBuild, "index.html")
+	if err != nil {
+		log.Fatalf("Failed to load index.html: %v", err)
+	}
 
-	// Serve static files for all non-API routes
+	// Serve static files for all non-API routes. The SPA's index.html is rendered
+	// per request with the CSP nonce on its script tags, so it must not be cached.
 	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		path := r.URL.Path
-		if path == "/" {
-			path = "/index.html"
-		}
-
-		if _, err := fs.Stat(webBuild, path[1:]); err == nil {
-			fileServer.ServeHTTP(w, r)
-			return
+		if path != "/" && path != "/index.html" {
+			if _, err := fs.Stat(webBuild, path[1:]); err == nil {
+				fileServer.ServeHTTP(w, r)
+				return
+			}
 		}
 
-		r.URL.Path = "/"
-		fileServer.ServeHTTP(w, r)
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		_, _ = w.Write(middleware.InjectNonce(indexHTML, middleware.CSPNonce(r.Context())))
 	})
 
 	// 9. Apply middleware chain
@@ -214,9 +732,16 @@ func main() {
 		IsDevelopment: isDev,
 	}
 
+	// Request metrics per route wrap the mux itself, which sets the matched route on the request
+	var routes http.Handler = httpStats.Middleware(mux)
+	if demoService != nil {
+		routes = middleware.DemoGuard(middleware.DemoBlockedRoutes)(routes)
+	}
+	routes = middleware.MaintenanceGuard(maintenance, middleware.MaintenanceAllowedRoutes)(routes)
+
 	handler := middleware.SecurityHeaders()(
 		middleware.CORSMiddleware(corsConfig)(
-			middleware.LoggingMiddleware(mux)))
+			middleware.LoggingMiddleware(routes)))
 
 	// 10. Create server with timeouts
 	server := &http.Server{
@@ -233,8 +758,10 @@ func main() {
 	log.Printf("║   http://localhost:%s                     ║", port)
 	log.Printf("╚════════════════════════════════════════════╝")
 
-	// Graceful shutdown
+	// Graceful shutdown. main waits for it to finish before exiting.
+	shutdownDone := make(chan struct{})
 	go func() {
+		defer close(shutdownDone)
 		sigChan := make(chan os.Signal, 1)
 		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 		<-sigChan
@@ -246,11 +773,112 @@ func main() {
 		if err := server.Shutdown(ctx); err != nil {
 			log.Printf("Server forced to shutdown: %v", err)
 		}
+		// Send batched webhooks now rather than lose them, and let deliveries in progress finish
+		flushCtx, cancelFlush := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelFlush()
+		if err := webhookService.Flush(flushCtx); err != nil {
+			log.Printf("Webhook deliveries still running at shutdown, left pending in the delivery log: %v", err)
+		}
+		// Other instances take over the scheduled jobs right away. The locks get their own
+		// deadline, as a slow shutdown may have used up ctx.
+		stopBackground()
+		releaseCtx, cancelRelease := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancelRelease()
+		jobLocks.ReleaseAll(releaseCtx)
 	}()
 
 	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 		log.Fatalf("Server failed: %v", err)
 	}
+	<-shutdownDone
 
 	log.Println("Server stopped gracefully")
 }
+
+// provision applies the provisioning config at path: YAML for .yaml and .yml files, JSON otherwise
+func provision(ctx context.Context, path string, provisioner *service.ProvisionService) error {
+	data, err := os.ReadFile(path) // #nosec G304 -- path comes from the operator's environment
+	if err != nil {
+		return err
+	}
+	parse := service.ParseProvisionConfig
+	if ext := strings.ToLower(filepath.Ext(path)); ext == ".yaml" || ext == ".yml" {
+		parse = service.ParseProvisionYAML
+	}
+	cfg, err := parse(data)
+	if err != nil {
+		return err
+	}
+	for _, f := range cfg.Forms {
+		if f.Webhook != nil && f.Webhook.Transform != "" {
+			if _, err := transform.Compile(f.Webhook.Transform); err != nil {
+				return fmt.Errorf("form %q: %w", f.Slug, err)
+			}
+		}
+		if t := f.EmailTemplate; t != nil {
+			if err := email.ValidateTemplate(email.Template{Subject: t.Subject, HTML: t.HTML, Text: t.Text}); err != nil {
+				return fmt.Errorf("form %q: %w", f.Slug, err)
+			}
+		}
+	}
+
+	report, err := provisioner.Apply(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	log.Printf("📋 Provisioned from %s: %d user(s) created, %d updated; %d form(s) created, %d updated; %d unchanged",
+		path, report.UsersCreated, report.UsersUpdated, report.FormsCreated, report.FormsUpdated, report.Unchanged)
+	return nil
+}
+
+// newSecretCodec configures secret column encryption and the secret managers secrets may reference
+func newSecretCodec() (*secrets.Codec, error) {
+	cfg := secrets.Config{
+		Key:     os.Getenv("SECRETS_KEY"),
+		Sources: make(map[string]secrets.Source),
+	}
+	for _, key := range strings.Split(os.Getenv("SECRETS_PREVIOUS_KEYS"), ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			cfg.PreviousKeys = append(cfg.PreviousKeys, key)
+		}
+	}
+	if v := os.Getenv("SECRETS_CACHE_TTL"); v != "" {
+		ttl, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("SECRETS_CACHE_TTL: %w", err)
+		}
+		cfg.CacheTTL = ttl
+	}
+	if addr := os.Getenv("VAULT_ADDR"); addr != "" {
+		cfg.Sources[secrets.SchemeVault] = secrets.NewVault(addr, os.Getenv("VAULT_TOKEN"), os.Getenv("VAULT_NAMESPACE"))
+	}
+	if region := os.Getenv("AWS_SECRETS_REGION"); region != "" {
+		cfg.Sources[secrets.SchemeAWS] = secrets.NewAWSSecretsManager(region,
+			os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY"), os.Getenv("AWS_SESSION_TOKEN"))
+	}
+	return secrets.New(cfg)
+}
+
+// envIDLength returns the short ID length set in the environment variable name, or 0 if unset
+func envIDLength(name string) int {
+	v := os.Getenv(name)
+	if v == "" {
+		return 0
+	}
+	length, err := strconv.Atoi(v)
+	if err != nil {
+		log.Fatalf("Invalid %s %q: %v", name, v, err)
+	}
+	return length
+}
+
+// exportHashKey returns the key masked exports hash values with: EXPORT_HASH_KEY, or
+// else one derived from the JWT secret
+func exportHashKey(jwtSecret string) []byte {
+	if key := os.Getenv("EXPORT_HASH_KEY"); key != "" {
+		return []byte(key)
+	}
+	mac := hmac.New(sha256.New, []byte(jwtSecret))
+	mac.Write([]byte("headless-forms export hashes"))
+	return mac.Sum(nil)
+}
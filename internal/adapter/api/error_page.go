@@ -0,0 +1,120 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"headless_form/internal/adapter/api/response"
+	"headless_form/internal/core/domain"
+)
+
+// errorPageTemplate is the hosted page shown to people whose form post was rejected
+var errorPageTemplate = template.Must(template.New("error").Parse(`<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1.0">
+  <title>Your submission wasn't sent</title>
+</head>
+<body style="font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif; line-height: 1.6; color: #333; max-width: 520px; margin: 60px auto; padding: 0 20px;">
+  <h1 style="font-size: 22px; margin: 0 0 12px;">Your submission wasn't sent</h1>
+  <p style="margin: 0 0 12px;">{{.Message}}</p>
+  {{if .Fields}}<ul style="margin: 0 0 12px; padding-left: 20px;">{{range .Fields}}<li>{{.}}</li>{{end}}</ul>{{end}}
+  {{if .Back}}<p style="margin: 20px 0 0;"><a href="{{.Back}}" style="color: #667eea;">Go back to the form</a></p>{{end}}
+  <p style="margin: 24px 0 0; color: #999; font-size: 12px;">Reason: {{.Reason}}</p>
+</body>
+</html>`))
+
+// errorPageData fills errorPageTemplate
+type errorPageData struct {
+	Message string
+	Fields  []string // Messages of invalid fields
+	Back    string   // Page the form was posted from
+	Reason  string
+}
+
+// WantsHTML reports whether the request is a browser navigation posting a form, which
+// should be answered with a page rather than JSON. fetch() calls sending form data
+// don't ask for HTML and keep getting JSON.
+func (c *SubmissionContext) WantsHTML() bool {
+	return c.IsHTMLForm() && strings.Contains(c.R.Header.Get("Accept"), "text/html")
+}
+
+// errorCapture records the response a stage error maps to, so it can be answered
+// with a page instead
+type errorCapture struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (e *errorCapture) Header() http.Header { return e.header }
+
+func (e *errorCapture) WriteHeader(status int) {
+	if e.status == 0 {
+		e.status = status
+	}
+}
+
+func (e *errorCapture) Write(b []byte) (int, error) {
+	e.WriteHeader(http.StatusOK)
+	return e.body.Write(b)
+}
+
+// writeFormError answers a rejected browser form post: with a redirect to the form's
+// error page URL, carrying the lowercased error code as ?reason=, or with the hosted
+// error page and the status the JSON error would have had
+func writeFormError(c *SubmissionContext, err error) {
+	captured := &errorCapture{header: http.Header{}}
+	writeStageError(captured, err)
+	var envelope response.Envelope
+	_ = json.Unmarshal(captured.body.Bytes(), &envelope)
+	reason := strings.ToLower(envelope.Code)
+
+	// Headers such as Retry-After still apply
+	for k, v := range captured.header {
+		if k != "Content-Type" {
+			c.W.Header()[k] = v
+		}
+	}
+
+	var cfg *domain.ErrorPageConfig
+	if c.Form != nil {
+		cfg = c.Form.ErrorPage
+	}
+	if cfg != nil && cfg.URL != "" {
+		http.Redirect(c.W, c.R, cfg.RedirectURL(reason), http.StatusSeeOther)
+		return
+	}
+
+	data := errorPageData{Message: envelope.Message, Reason: reason}
+	if cfg != nil && cfg.Message != "" {
+		data.Message = cfg.Message
+	}
+	var fieldErrs domain.FieldErrors
+	if errors.As(err, &fieldErrs) {
+		for _, fe := range fieldErrs {
+			data.Fields = append(data.Fields, fe.Message)
+		}
+	}
+	if back, err := url.Parse(c.R.Referer()); err == nil && (back.Scheme == "http" || back.Scheme == "https") {
+		data.Back = back.String()
+	}
+
+	var page bytes.Buffer
+	contentType := "text/html; charset=utf-8"
+	if err := errorPageTemplate.Execute(&page, data); err != nil {
+		// Fall back to the JSON error rather than a blank page
+		log.Printf("[ERROR] Failed to render error page: %v", err)
+		page, contentType = captured.body, "application/json"
+	}
+	c.W.Header().Set("Content-Type", contentType)
+	c.W.WriteHeader(captured.status)
+	_, _ = c.W.Write(page.Bytes())
+}
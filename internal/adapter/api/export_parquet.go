@@ -0,0 +1,282 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"headless_form/internal/adapter/api/response"
+	"headless_form/internal/adapter/export"
+	"headless_form/internal/adapter/middleware"
+	"headless_form/internal/adapter/parquet"
+	"headless_form/internal/core/domain"
+	"headless_form/internal/core/service"
+)
+
+// parquetContentType is the media type registered for Parquet files
+const parquetContentType = "application/vnd.apache.parquet"
+
+// parquetProgressEvery is how many rows are written between progress updates
+const parquetProgressEvery = 1000
+
+// parquetMetaColumns start every Parquet export, like the CSV export's fixed columns
+var parquetMetaColumns = []parquet.Column{
+	{Name: "id", Type: parquet.String, Required: true},
+	{Name: "created_at", Type: parquet.Timestamp, Required: true},
+	{Name: "status", Type: parquet.String},
+	{Name: "ip", Type: parquet.String},
+	{Name: "country", Type: parquet.String},
+	{Name: "spam_score", Type: parquet.Int64},
+	{Name: "is_spam", Type: parquet.Boolean},
+	{Name: "consent_version", Type: parquet.String},
+	{Name: "consent_accepted_at", Type: parquet.Timestamp},
+}
+
+// parquetExportResult is the result of a Parquet export task
+type parquetExportResult struct {
+	FormID  string `json:"form_id"`
+	Rows    int64  `json:"rows"`
+	Columns int    `json:"columns"`
+}
+
+// HandleExportParquet: POST /api/v1/forms/{form_id}/export/parquet?include_test=true
+// Starts a background task writing the form's submissions to a Parquet file, one typed
+// column per field. Follow it with GET /api/v1/tasks/{task_id} and download the file
+// from GET /api/v1/tasks/{task_id}/download once completed.
+func (h *Router) HandleExportParquet(w http.ResponseWriter, r *http.Request) {
+	if h.tasks == nil {
+		response.NotFound(w, "Parquet exports are not available")
+		return
+	}
+
+	form, err := h.formService.GetForm(r.Context(), r.PathValue("form_id"))
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	if !middleware.CanAccessForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "Access denied", response.CodeForbidden)
+		return
+	}
+	profile, ok := h.exportProfile(w, r, form)
+	if !ok {
+		return
+	}
+	includeTest := r.URL.Query().Get("include_test") == "true"
+
+	task, err := h.tasks.Start(domain.TaskTypeExportParquet, middleware.GetUserID(r.Context()), func(ctx context.Context, p *service.TaskProgress) (interface{}, error) {
+		return h.exportParquet(ctx, p, form, profile, includeTest)
+	})
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	response.Accepted(w, task)
+}
+
+// exportParquet writes the form's submissions, masked by profile when set, to a temporary
+// Parquet file and saves it as the task's file
+func (h *Router) exportParquet(ctx context.Context, p *service.TaskProgress, form *domain.Form, profile *domain.ExportProfile, includeTest bool) (interface{}, error) {
+	submissions, err := h.submissionService.ListSubmissions(ctx, form.PublicID)
+	if err != nil {
+		return nil, err
+	}
+	if !includeTest {
+		submissions = withoutTestSubmissions(submissions)
+	}
+	for i, sub := range submissions {
+		submissions[i] = profile.Mask(sub, form)
+	}
+	form = profile.MaskForm(form)
+
+	allData := make([]map[string]interface{}, len(submissions))
+	for i, sub := range submissions {
+		_ = json.Unmarshal(sub.Data, &allData[i])
+	}
+	fieldColumns, keys := parquetFieldColumns(form, allData)
+	columns := append(append([]parquet.Column{}, parquetMetaColumns...), fieldColumns...)
+
+	tmp, err := os.CreateTemp("", "export-*.parquet")
+	if err != nil {
+		return nil, fmt.Errorf("create export file: %w", err)
+	}
+	defer func() {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+	}()
+
+	pw, err := parquet.NewWriter(tmp, columns)
+	if err != nil {
+		return nil, err
+	}
+	for i, sub := range submissions {
+		if i%parquetProgressEvery == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			p.Update(float64(i)*90/float64(len(submissions)), fmt.Sprintf("Wrote %d of %d submissions", i, len(submissions)))
+		}
+		if err := pw.Write(parquetRow(sub, allData[i], fieldColumns, keys)); err != nil {
+			return nil, err
+		}
+	}
+	if err := pw.Close(); err != nil {
+		return nil, fmt.Errorf("write export file: %w", err)
+	}
+
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	p.Update(95, "Saving file")
+	if err := p.SaveFile(ctx, form.Name+"_submissions.parquet", parquetContentType, tmp, size); err != nil {
+		return nil, err
+	}
+	return parquetExportResult{FormID: form.PublicID, Rows: pw.NumRows(), Columns: len(columns)}, nil
+}
+
+// parquetFieldColumns returns a column per submitted field: the form's schema fields
+// first, in order, then any other keys found in the data, sorted. A column holds
+// numbers for fields typed number in the schema, numbers or booleans when every
+// value of the field is one, and text otherwise.
+// keys are the data keys of the columns, which are renamed "field_<key>" if the
+// key clashes with a fixed column.
+func parquetFieldColumns(form *domain.Form, allData []map[string]interface{}) (columns []parquet.Column, keys []string) {
+	seen := make(map[string]bool)
+	for _, f := range form.Fields {
+		if !seen[f.Name] {
+			seen[f.Name] = true
+			keys = append(keys, f.Name)
+		}
+	}
+	var extra []string
+	for _, data := range allData {
+		for key := range data {
+			if !seen[key] {
+				seen[key] = true
+				extra = append(extra, key)
+			}
+		}
+	}
+	sort.Strings(extra)
+	keys = append(keys, extra...)
+
+	names := make(map[string]bool)
+	for _, col := range parquetMetaColumns {
+		names[col.Name] = true
+	}
+	for _, key := range keys {
+		name := key
+		for names[name] {
+			name = "field_" + name
+		}
+		names[name] = true
+		typ := inferParquetType(allData, key)
+		if f := form.Field(key); f != nil && f.Type == domain.FieldTypeNumber {
+			typ = parquet.Double
+		}
+		columns = append(columns, parquet.Column{Name: name, Type: typ})
+	}
+	return columns, keys
+}
+
+// inferParquetType picks Double or Boolean for fields only ever holding numbers or
+// booleans, and String for anything else (or fields that are always empty)
+func inferParquetType(allData []map[string]interface{}, key string) parquet.Type {
+	numbers, bools, others := 0, 0, 0
+	for _, data := range allData {
+		switch data[key].(type) {
+		case nil:
+		case float64:
+			numbers++
+		case bool:
+			bools++
+		default:
+			others++
+		}
+	}
+	switch {
+	case numbers > 0 && bools == 0 && others == 0:
+		return parquet.Double
+	case bools > 0 && numbers == 0 && others == 0:
+		return parquet.Boolean
+	default:
+		return parquet.String
+	}
+}
+
+// parquetRow builds a submission's row: the fixed columns, then one value per field column
+func parquetRow(sub *domain.Submission, data map[string]interface{}, fieldColumns []parquet.Column, keys []string) []interface{} {
+	row := make([]interface{}, 0, len(parquetMetaColumns)+len(keys))
+	row = append(row, sub.ID, sub.CreatedAt, nullString(string(sub.Status)))
+
+	ip := sub.Meta.Server.IP
+	if sub.Meta.Server.Anonymized {
+		ip = "anonymized"
+	}
+	row = append(row, nullString(ip), nullString(sub.Meta.Server.Country))
+	if sub.Meta.Spam != nil {
+		row = append(row, int64(sub.Meta.Spam.Score), sub.Meta.Spam.IsSpam)
+	} else {
+		row = append(row, nil, nil)
+	}
+	if sub.Meta.Consent != nil {
+		row = append(row, nullString(sub.Meta.Consent.Version), sub.Meta.Consent.AcceptedAt)
+	} else {
+		row = append(row, nil, nil)
+	}
+
+	for i, key := range keys {
+		v, ok := data[key]
+		if !ok || v == nil {
+			row = append(row, nil)
+			continue
+		}
+		switch fieldColumns[i].Type {
+		case parquet.String:
+			row = append(row, export.FieldText(v))
+		case parquet.Double:
+			row = append(row, parquetNumber(v))
+		default:
+			row = append(row, v)
+		}
+	}
+	return row
+}
+
+// parquetNumber returns a number field's value as a float64, parsing numbers sent
+// as text by HTML forms; values that aren't numbers are stored as null
+func parquetNumber(v interface{}) interface{} {
+	switch val := v.(type) {
+	case float64:
+		return val
+	case string:
+		if f, err := strconv.ParseFloat(strings.TrimSpace(val), 64); err == nil {
+			return f
+		}
+	}
+	return nil
+}
+
+// nullString stores empty text as null
+func nullString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
@@ -3,9 +3,16 @@ package api
 import (
 	"net/http"
 	"strconv"
+	"time"
 
+	"headless_form/internal/adapter/api/request"
 	"headless_form/internal/adapter/api/response"
+	"headless_form/internal/adapter/captcha"
+	"headless_form/internal/adapter/httpstats"
+	"headless_form/internal/adapter/linkcheck"
+	"headless_form/internal/adapter/middleware"
 	"headless_form/internal/adapter/spam"
+	"headless_form/internal/adapter/sqltrace"
 	"headless_form/internal/core/service"
 )
 
@@ -26,22 +33,139 @@ func parseIntParam(r *http.Request, name string, defaultVal int) int {
 	return val
 }
 
+// parsePage reads a list endpoint's page and limit query parameters. Pages start at 1;
+// a limit outside 1..maxLimit falls back to defaultLimit.
+func parsePage(r *http.Request, defaultLimit, maxLimit int) (page, limit int) {
+	page = parseIntParam(r, "page", 1)
+	limit = parseIntParam(r, "limit", defaultLimit)
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > maxLimit {
+		limit = defaultLimit
+	}
+	return page, limit
+}
+
 // Router is the main API handler that routes requests to appropriate handlers
 type Router struct {
 	formService       *service.FormService
 	submissionService *service.SubmissionService
 	statsService      *service.StatsService
 	spamDetector      *spam.Detector
+	fingerprints      *service.SpamFingerprintService
+	captcha           *captcha.Verifier
+	links             *linkcheck.Checker
+	ipHasher          *request.IPHasher
+	submitPipeline    *SubmissionPipeline
+	dryRunPipeline    *SubmissionPipeline
+	idempotency       *idempotencyKeys
+	baseURL           string
+	queryMonitor      *sqltrace.Monitor
+	httpStats         *httpstats.Collector
+	feed              *service.SubmissionFeed
+	seeder            *service.SeedService
+	fieldMigrations   *service.FieldMigrationService
+	tasks             *service.TaskService
+	seedAllowed       bool
+	maintenance       *middleware.Maintenance
 }
 
 // NewRouter creates a new Router with the given services
 func NewRouter(formService *service.FormService, submService *service.SubmissionService, statsService *service.StatsService) *Router {
-	return &Router{
+	h := &Router{
 		formService:       formService,
 		submissionService: submService,
 		statsService:      statsService,
 		spamDetector:      spam.NewDetector(spam.DefaultConfig()),
+		captcha:           captcha.NewVerifier(),
+		links:             linkcheck.NewChecker(),
+		ipHasher:          request.NewIPHasher(24 * time.Hour),
+		idempotency:       newIdempotencyKeys(),
 	}
+	h.submitPipeline = h.newSubmissionPipeline()
+	h.dryRunPipeline = h.newDryRunPipeline()
+	return h
+}
+
+// SetBaseURL sets the public base URL used to build links to hosted form pages
+func (h *Router) SetBaseURL(baseURL string) {
+	h.baseURL = baseURL
+}
+
+// SetQueryMonitor enables the database query stats endpoint
+func (h *Router) SetQueryMonitor(m *sqltrace.Monitor) {
+	h.queryMonitor = m
+}
+
+// SetHTTPStats enables the request metrics endpoint, reporting what c collected
+func (h *Router) SetHTTPStats(c *httpstats.Collector) {
+	h.httpStats = c
+}
+
+// SetSubmissionFeed enables the live submission stream, following feed
+func (h *Router) SetSubmissionFeed(feed *service.SubmissionFeed) {
+	h.feed = feed
+}
+
+// SetMaintenance enables the maintenance mode endpoints, which toggle m
+func (h *Router) SetMaintenance(m *middleware.Maintenance) {
+	h.maintenance = m
+}
+
+// SetSeeder enables the admin seed endpoints and the sample data endpoints. allowed
+// is false in production unless seeding was explicitly enabled; it doesn't restrict
+// sample data, which users create for themselves.
+func (h *Router) SetSeeder(seeder *service.SeedService, allowed bool) {
+	h.seeder = seeder
+	h.seedAllowed = allowed
+}
+
+// SetFieldMigrations enables the admin endpoints renaming fields across a form's submissions
+func (h *Router) SetFieldMigrations(m *service.FieldMigrationService) {
+	h.fieldMigrations = m
+}
+
+// SetSpamProviders makes the spam check also ask the external service src returns
+func (h *Router) SetSpamProviders(src spam.ProviderSource) {
+	h.spamDetector.SetProviderSource(src)
+}
+
+// SetContentFilters makes the spam stage also check the global content filters src returns
+func (h *Router) SetContentFilters(src spam.FilterSource) {
+	h.spamDetector.SetFilterSource(src)
+}
+
+// SetSpamSettings makes the spam check use the site-wide weights, blocked domains and
+// Safe Browsing key src returns
+func (h *Router) SetSpamSettings(src spam.SettingsSource) {
+	h.spamDetector.SetSettingsSource(src)
+}
+
+// SetSpamFingerprints makes submissions repeating the content of confirmed spam score
+// repeat_spam
+func (h *Router) SetSpamFingerprints(fingerprints *service.SpamFingerprintService) {
+	h.fingerprints = fingerprints
+}
+
+// SetCaptchaVerifier replaces the verifier of captcha tokens, e.g. to use other endpoints
+func (h *Router) SetCaptchaVerifier(v *captcha.Verifier) {
+	h.captcha = v
+}
+
+// SetLinkChecker replaces the checker of file_url links, e.g. to allow local addresses
+func (h *Router) SetLinkChecker(c *linkcheck.Checker) {
+	h.links = c
+}
+
+// SetTasks enables exports running as background tasks, such as Parquet exports
+func (h *Router) SetTasks(tasks *service.TaskService) {
+	h.tasks = tasks
+}
+
+// SubmitPipeline returns the submission pipeline so extra stages can be registered at startup
+func (h *Router) SubmitPipeline() *SubmissionPipeline {
+	return h.submitPipeline
 }
 
 // =============================================================================
@@ -55,9 +179,24 @@ func (h *Router) RegisterPublicRoutes(mux *http.ServeMux, optionalAuth func(http
 	// Health check - always public
 	mux.HandleFunc("GET /api/health", h.HandleHealthCheck)
 
+	// Error code catalog - lets clients map codes to localized messages
+	mux.HandleFunc("GET /api/v1/meta/errors", h.HandleErrorCatalog)
+
+	// Hosted form responses get their own header policy; forms may allow framing them
+	hosted := middleware.WithHeaderPolicy(middleware.HostedFormPolicy)
+
 	// Endpoint Form Submission URL - public by default (access control handled in handler)
 	// Uses optional auth to extract user context for private forms
-	mux.Handle("POST /api/v1/submissions/{form_id}", optionalAuth(http.HandlerFunc(h.HandleSubmit)))
+	mux.Handle("POST /api/v1/submissions/{form_id}", hosted(optionalAuth(http.HandlerFunc(h.HandleSubmit))))
+	// CORS preflight for fetch() submissions, answered from the form's allowed origins in every environment
+	mux.HandleFunc("OPTIONS /api/v1/submissions/{form_id}", h.HandleSubmitPreflight)
+
+	// Opt-in public stats (JSON + SVG badge) for embedding response counts on other sites
+	mux.Handle("GET /api/v1/forms/{form_id}/public-stats", hosted(middleware.PublicLimiter.Middleware()(http.HandlerFunc(h.HandlePublicStats))))
+	mux.Handle("GET /api/v1/forms/{form_id}/public-stats/badge.svg", hosted(middleware.PublicLimiter.Middleware()(http.HandlerFunc(h.HandlePublicStatsBadge))))
+
+	// Localized field labels for pages rendering the form (?lang= or Accept-Language)
+	mux.Handle("GET /api/v1/forms/{form_id}/fields", hosted(middleware.PublicLimiter.Middleware()(http.HandlerFunc(h.HandlePublicFields))))
 }
 
 // RegisterProtectedRoutes registers routes that require JWT authentication
@@ -69,21 +208,103 @@ func (h *Router) RegisterProtectedRoutes(mux *http.ServeMux, authMiddleware func
 	// Forms CRUD (protected)
 	mux.Handle("POST /api/v1/forms", authMiddleware(http.HandlerFunc(h.HandleCreateForm)))
 	mux.Handle("GET /api/v1/forms", authMiddleware(http.HandlerFunc(h.HandleListForms)))
+	mux.Handle("GET /api/v1/forms/trash", authMiddleware(http.HandlerFunc(h.HandleListTrash)))
 	mux.Handle("GET /api/v1/forms/{form_id}", authMiddleware(http.HandlerFunc(h.HandleGetForm)))
 	mux.Handle("PUT /api/v1/forms/{form_id}", authMiddleware(http.HandlerFunc(h.HandleUpdateForm)))
 	mux.Handle("DELETE /api/v1/forms/{form_id}", authMiddleware(http.HandlerFunc(h.HandleDeleteForm)))
+	mux.Handle("POST /api/v1/forms/{form_id}/restore", authMiddleware(http.HandlerFunc(h.HandleRestoreForm)))
+	mux.Handle("PUT /api/v1/forms/{form_id}/star", authMiddleware(http.HandlerFunc(h.HandleStarForm)))
+	mux.Handle("DELETE /api/v1/forms/{form_id}/star", authMiddleware(http.HandlerFunc(h.HandleUnstarForm)))
+	mux.Handle("PUT /api/v1/forms/{form_id}/archive", authMiddleware(http.HandlerFunc(h.HandleArchiveForm)))
+	mux.Handle("DELETE /api/v1/forms/{form_id}/archive", authMiddleware(http.HandlerFunc(h.HandleUnarchiveForm)))
 	mux.Handle("GET /api/v1/forms/{form_id}/stats", authMiddleware(http.HandlerFunc(h.HandleFormStats)))
+	mux.Handle("GET /api/v1/forms/{form_id}/stats/fields", authMiddleware(http.HandlerFunc(h.HandleFieldStats)))
+	mux.Handle("GET /api/v1/forms/{form_id}/stats/export", authMiddleware(http.HandlerFunc(h.HandleExportStatsCSV)))
+	mux.Handle("PUT /api/v1/forms/{form_id}/webhook/transform", authMiddleware(http.HandlerFunc(h.HandleSetWebhookTransform)))
+	mux.Handle("POST /api/v1/forms/{form_id}/webhook/transform/preview", authMiddleware(http.HandlerFunc(h.HandlePreviewWebhookTransform)))
+	mux.Handle("PUT /api/v1/forms/{form_id}/webhook/signing", authMiddleware(http.HandlerFunc(h.HandleSetWebhookSigning)))
+	mux.Handle("PUT /api/v1/forms/{form_id}/webhook/meta", authMiddleware(http.HandlerFunc(h.HandleSetWebhookMeta)))
+	mux.Handle("PUT /api/v1/forms/{form_id}/webhook/rate", authMiddleware(http.HandlerFunc(h.HandleSetWebhookRate)))
+	mux.Handle("DELETE /api/v1/forms/{form_id}/webhook/rate", authMiddleware(http.HandlerFunc(h.HandleDeleteWebhookRate)))
+	mux.Handle("PUT /api/v1/forms/{form_id}/schedule", authMiddleware(http.HandlerFunc(h.HandleSetSchedule)))
+	mux.Handle("DELETE /api/v1/forms/{form_id}/schedule", authMiddleware(http.HandlerFunc(h.HandleDeleteSchedule)))
+	mux.Handle("POST /api/v1/forms/{form_id}/rotate-public-id", authMiddleware(http.HandlerFunc(h.HandleRotatePublicID)))
+	mux.Handle("PUT /api/v1/forms/{form_id}/consent", authMiddleware(http.HandlerFunc(h.HandleSetConsent)))
+	mux.Handle("DELETE /api/v1/forms/{form_id}/consent", authMiddleware(http.HandlerFunc(h.HandleDeleteConsent)))
+	mux.Handle("PUT /api/v1/forms/{form_id}/uploads", authMiddleware(http.HandlerFunc(h.HandleSetUploads)))
+	mux.Handle("DELETE /api/v1/forms/{form_id}/uploads", authMiddleware(http.HandlerFunc(h.HandleDeleteUploads)))
+	mux.Handle("PUT /api/v1/forms/{form_id}/submit-response", authMiddleware(http.HandlerFunc(h.HandleSetSubmitResponse)))
+	mux.Handle("DELETE /api/v1/forms/{form_id}/submit-response", authMiddleware(http.HandlerFunc(h.HandleDeleteSubmitResponse)))
+	mux.Handle("PUT /api/v1/forms/{form_id}/error-page", authMiddleware(http.HandlerFunc(h.HandleSetErrorPage)))
+	mux.Handle("DELETE /api/v1/forms/{form_id}/error-page", authMiddleware(http.HandlerFunc(h.HandleDeleteErrorPage)))
+	mux.Handle("PUT /api/v1/forms/{form_id}/spam", authMiddleware(http.HandlerFunc(h.HandleSetSpam)))
+	mux.Handle("DELETE /api/v1/forms/{form_id}/spam", authMiddleware(http.HandlerFunc(h.HandleDeleteSpam)))
+	mux.Handle("POST /api/v1/forms/{form_id}/spam/rescore", authMiddleware(http.HandlerFunc(h.HandleRescoreSpam)))
+	mux.Handle("PUT /api/v1/forms/{form_id}/captcha", authMiddleware(http.HandlerFunc(h.HandleSetCaptcha)))
+	mux.Handle("DELETE /api/v1/forms/{form_id}/captcha", authMiddleware(http.HandlerFunc(h.HandleDeleteCaptcha)))
+	mux.Handle("GET /api/v1/forms/{form_id}/filters", authMiddleware(http.HandlerFunc(h.HandleListFilters)))
+	mux.Handle("PUT /api/v1/forms/{form_id}/filters", authMiddleware(http.HandlerFunc(h.HandleSetFilters)))
+	mux.Handle("POST /api/v1/forms/{form_id}/filters", authMiddleware(http.HandlerFunc(h.HandleAddFilter)))
+	mux.Handle("DELETE /api/v1/forms/{form_id}/filters/{filter_id}", authMiddleware(http.HandlerFunc(h.HandleDeleteFilter)))
+	mux.Handle("PUT /api/v1/forms/{form_id}/email-template", authMiddleware(http.HandlerFunc(h.HandleSetEmailTemplate)))
+	mux.Handle("DELETE /api/v1/forms/{form_id}/email-template", authMiddleware(http.HandlerFunc(h.HandleDeleteEmailTemplate)))
+	mux.Handle("PUT /api/v1/forms/{form_id}/email-field-limit", authMiddleware(http.HandlerFunc(h.HandleSetEmailFieldLimit)))
+	mux.Handle("PUT /api/v1/forms/{form_id}/link-field", authMiddleware(http.HandlerFunc(h.HandleSetLinkField)))
+	mux.Handle("PUT /api/v1/forms/{form_id}/hidden-fields", authMiddleware(http.HandlerFunc(h.HandleSetHiddenFields)))
+	mux.Handle("PUT /api/v1/forms/{form_id}/auto-reply", authMiddleware(http.HandlerFunc(h.HandleSetAutoReply)))
+	mux.Handle("DELETE /api/v1/forms/{form_id}/auto-reply", authMiddleware(http.HandlerFunc(h.HandleDeleteAutoReply)))
+	mux.Handle("PUT /api/v1/forms/{form_id}/notify-rules", authMiddleware(http.HandlerFunc(h.HandleSetNotifyRules)))
+	mux.Handle("DELETE /api/v1/forms/{form_id}/notify-rules", authMiddleware(http.HandlerFunc(h.HandleDeleteNotifyRules)))
+	mux.Handle("PUT /api/v1/forms/{form_id}/channels", authMiddleware(http.HandlerFunc(h.HandleSetChannels)))
+	mux.Handle("DELETE /api/v1/forms/{form_id}/channels", authMiddleware(http.HandlerFunc(h.HandleDeleteChannels)))
+	mux.Handle("PUT /api/v1/forms/{form_id}/normalize", authMiddleware(http.HandlerFunc(h.HandleSetNormalize)))
+	mux.Handle("DELETE /api/v1/forms/{form_id}/normalize", authMiddleware(http.HandlerFunc(h.HandleDeleteNormalize)))
+	mux.Handle("PUT /api/v1/forms/{form_id}/embed", authMiddleware(http.HandlerFunc(h.HandleSetEmbed)))
+	mux.Handle("PUT /api/v1/forms/{form_id}/fields", authMiddleware(http.HandlerFunc(h.HandleSetFields)))
+	mux.Handle("PUT /api/v1/forms/{form_id}/timezone", authMiddleware(http.HandlerFunc(h.HandleSetTimezone)))
+	mux.Handle("PUT /api/v1/forms/{form_id}/retention", authMiddleware(http.HandlerFunc(h.HandleSetRetention)))
+	mux.Handle("POST /api/v1/forms/{form_id}/test-notification", authMiddleware(http.HandlerFunc(h.HandleTestNotification)))
+	mux.Handle("GET /api/v1/forms/{form_id}/qr", authMiddleware(http.HandlerFunc(h.HandleFormQR)))
+	mux.Handle("GET /api/v1/forms/{form_id}/audit", authMiddleware(http.HandlerFunc(h.HandleFormAuditLog)))
+
+	// Declarative form management by slug (infrastructure as code)
+	mux.Handle("GET /api/v1/form-specs/{slug}", authMiddleware(http.HandlerFunc(h.HandleGetFormSpec)))
+	mux.Handle("PUT /api/v1/form-specs/{slug}", authMiddleware(http.HandlerFunc(h.HandleApplyFormSpec)))
 
 	// Submission management (protected) - viewing/managing submissions requires auth
 	mux.Handle("GET /api/v1/forms/{form_id}/submissions", authMiddleware(http.HandlerFunc(h.HandleListSubmissions)))
+	mux.Handle("GET /api/v1/forms/{form_id}/submissions/spam", authMiddleware(http.HandlerFunc(h.HandleListSpam)))
+	mux.Handle("GET /api/v1/forms/{form_id}/submissions/stream", authMiddleware(http.HandlerFunc(h.HandleSubmissionStream)))
+	mux.Handle("DELETE /api/v1/forms/{form_id}/submissions", authMiddleware(http.HandlerFunc(h.HandleDeleteSubmissions)))
+	mux.Handle("POST /api/v1/forms/{form_id}/submissions/bulk", authMiddleware(http.HandlerFunc(h.HandleBulkSubmissions)))
 	mux.Handle("GET /api/v1/forms/{form_id}/export/csv", authMiddleware(http.HandlerFunc(h.HandleExportCSV)))
+	mux.Handle("GET /api/v1/forms/{form_id}/export/json", authMiddleware(http.HandlerFunc(h.HandleExportJSON)))
+	mux.Handle("GET /api/v1/forms/{form_id}/export/xlsx", authMiddleware(http.HandlerFunc(h.HandleExportXLSX)))
+	mux.Handle("POST /api/v1/forms/{form_id}/export/parquet", authMiddleware(http.HandlerFunc(h.HandleExportParquet)))
 	mux.Handle("GET /api/v1/submissions/{sub_id}", authMiddleware(http.HandlerFunc(h.HandleGetSubmission)))
+	mux.Handle("GET /api/v1/submissions/{sub_id}/pdf", authMiddleware(http.HandlerFunc(h.HandleSubmissionPDF)))
+	mux.Handle("GET /api/v1/submissions/{sub_id}/files/{file_id}", authMiddleware(http.HandlerFunc(h.HandleSubmissionFile)))
+	mux.Handle("GET /api/v1/submissions/{sub_id}/related", authMiddleware(http.HandlerFunc(h.HandleRelatedSubmissions)))
+	mux.Handle("POST /api/v1/submissions/{sub_id}/links", authMiddleware(http.HandlerFunc(h.HandleLinkSubmission)))
+	mux.Handle("DELETE /api/v1/submissions/{sub_id}/links/{linked_id}", authMiddleware(http.HandlerFunc(h.HandleUnlinkSubmission)))
 	mux.Handle("PUT /api/v1/submissions/{sub_id}/read", authMiddleware(http.HandlerFunc(h.HandleMarkAsRead)))
 	mux.Handle("PUT /api/v1/submissions/{sub_id}/unread", authMiddleware(http.HandlerFunc(h.HandleMarkAsUnread)))
+	mux.Handle("PUT /api/v1/submissions/{sub_id}/spam", authMiddleware(http.HandlerFunc(h.HandleMarkAsSpam)))
+	mux.Handle("PUT /api/v1/submissions/{sub_id}/not-spam", authMiddleware(http.HandlerFunc(h.HandleMarkAsNotSpam)))
 	mux.Handle("DELETE /api/v1/submissions/{sub_id}", authMiddleware(http.HandlerFunc(h.HandleDeleteSubmission)))
 
+	// Sample data (protected)
+	mux.Handle("POST /api/v1/sample-data", authMiddleware(http.HandlerFunc(h.HandleCreateSampleData)))
+	mux.Handle("DELETE /api/v1/sample-data", authMiddleware(http.HandlerFunc(h.HandlePurgeSampleData)))
+
 	// Admin / Testing (protected)
 	mux.Handle("POST /api/v1/admin/seed", authMiddleware(http.HandlerFunc(h.HandleSeed)))
+	mux.Handle("POST /api/v1/admin/forms/{form_id}/field-migrations/preview", authMiddleware(http.HandlerFunc(h.HandlePreviewFieldMigration)))
+	mux.Handle("POST /api/v1/admin/forms/{form_id}/field-migrations", authMiddleware(http.HandlerFunc(h.HandleStartFieldMigration)))
+	mux.Handle("GET /api/v1/admin/db-stats", authMiddleware(http.HandlerFunc(h.HandleDBStats)))
+	mux.Handle("GET /api/v1/admin/http-stats", authMiddleware(http.HandlerFunc(h.HandleHTTPStats)))
+	mux.Handle("GET /api/v1/admin/maintenance", authMiddleware(http.HandlerFunc(h.HandleGetMaintenance)))
+	mux.Handle("PUT /api/v1/admin/maintenance", authMiddleware(http.HandlerFunc(h.HandleSetMaintenance)))
 }
 
 // =============================================================================
@@ -91,13 +312,14 @@ func (h *Router) RegisterProtectedRoutes(mux *http.ServeMux, authMiddleware func
 // =============================================================================
 
 // HandleHealthCheck: GET /api/health
-// Returns health status with database connectivity check
+// Returns health status with database connectivity check, "maintenance": true
+// while the instance is read-only, and how many submissions wait to be stored
 func (h *Router) HandleHealthCheck(w http.ResponseWriter, r *http.Request) {
 	status := "healthy"
 	checks := make(map[string]interface{})
 
 	// Check database connection by fetching dashboard stats
-	_, err := h.statsService.GetDashboardStats(r.Context())
+	_, err := h.statsService.GetDashboardStats(r.Context(), "")
 	if err != nil {
 		checks["database"] = map[string]interface{}{
 			"status": "unhealthy",
@@ -110,16 +332,32 @@ func (h *Router) HandleHealthCheck(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	response.Success(w, map[string]interface{}{
+	health := map[string]interface{}{
 		"status":  status,
 		"version": "1.2.0",
 		"checks":  checks,
+	}
+	if h.maintenance != nil && h.maintenance.Enabled() {
+		health["maintenance"] = true
+	}
+	if n := h.submissionService.QueueLen(); n > 0 {
+		health["queued_submissions"] = n
+	}
+	response.Success(w, health)
+}
+
+// HandleErrorCatalog: GET /api/v1/meta/errors
+// Returns every error code the API can return with its HTTP status and description
+func (h *Router) HandleErrorCatalog(w http.ResponseWriter, r *http.Request) {
+	response.Success(w, map[string]interface{}{
+		"errors": response.Catalog,
 	})
 }
 
 // HandleDashboardStats: GET /api/v1/stats
+// Days are counted in the caller's profile timezone.
 func (h *Router) HandleDashboardStats(w http.ResponseWriter, r *http.Request) {
-	stats, err := h.statsService.GetDashboardStats(r.Context())
+	stats, err := h.statsService.GetDashboardStats(r.Context(), middleware.GetUserID(r.Context()))
 	if response.HandleError(w, err) {
 		return
 	}
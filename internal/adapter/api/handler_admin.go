@@ -1,23 +1,123 @@
 package api
 
 import (
+	"encoding/csv"
 	"encoding/json"
 	"log"
+	"maps"
+	"mime"
 	"net/http"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 
+	"headless_form/internal/adapter/api/request"
 	"headless_form/internal/adapter/api/response"
+	"headless_form/internal/adapter/export"
 	"headless_form/internal/adapter/middleware"
 	"headless_form/internal/core/domain"
 )
 
 // =============================================================================
-// Admin Handlers (Seed, Export)
+// Admin Handlers (Seed, Export, DB and HTTP stats, Maintenance)
 // =============================================================================
 
+// HandleDBStats: GET /api/v1/admin/db-stats
+// Returns query timing metrics and recent slow queries (admin only).
+// Only available when the server runs with SQL_SLOW_QUERY set.
+func (h *Router) HandleDBStats(w http.ResponseWriter, r *http.Request) {
+	if !middleware.IsAdmin(r.Context()) {
+		response.Error(w, http.StatusForbidden, "Admin access required", response.CodeForbidden)
+		return
+	}
+	if h.queryMonitor == nil {
+		response.NotFound(w, "Query monitoring is not enabled")
+		return
+	}
+	response.Success(w, h.queryMonitor.Stats())
+}
+
+// HandleHTTPStats: GET /api/v1/admin/http-stats
+// Returns request counts, error rates and latency percentiles per API route since
+// startup (super_admin only). Counts are per instance.
+func (h *Router) HandleHTTPStats(w http.ResponseWriter, r *http.Request) {
+	if !middleware.IsSuperAdmin(r.Context()) {
+		response.Error(w, http.StatusForbidden, "Super admin access required", response.CodeForbidden)
+		return
+	}
+	if h.httpStats == nil {
+		response.NotFound(w, "Request metrics are not enabled")
+		return
+	}
+	response.Success(w, h.httpStats.Stats())
+}
+
+// HandleGetMaintenance: GET /api/v1/admin/maintenance
+// Returns whether the instance is in read-only maintenance mode (admin only).
+func (h *Router) HandleGetMaintenance(w http.ResponseWriter, r *http.Request) {
+	if !middleware.IsAdmin(r.Context()) {
+		response.Error(w, http.StatusForbidden, "Admin access required", response.CodeForbidden)
+		return
+	}
+	if h.maintenance == nil {
+		response.NotFound(w, "Maintenance mode is not available")
+		return
+	}
+	response.Success(w, h.maintenance.State())
+}
+
+// HandleSetMaintenance: PUT /api/v1/admin/maintenance
+// Turns read-only maintenance mode on or off (super_admin only), e.g.
+// {"enabled": true, "message": "Backing up", "retry_after": 600}. While it's on,
+// reads keep working and every write, submissions included, gets 503 MAINTENANCE
+// with Retry-After (see middleware.MaintenanceGuard).
+func (h *Router) HandleSetMaintenance(w http.ResponseWriter, r *http.Request) {
+	if !middleware.IsSuperAdmin(r.Context()) {
+		response.Error(w, http.StatusForbidden, "Super admin access required", response.CodeForbidden)
+		return
+	}
+	if h.maintenance == nil {
+		response.NotFound(w, "Maintenance mode is not available")
+		return
+	}
+
+	var req struct {
+		Enabled    bool   `json:"enabled"`
+		Message    string `json:"message"`
+		RetryAfter int    `json:"retry_after"` // Seconds
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid request body", response.CodeInvalidBody)
+		return
+	}
+	if req.RetryAfter < 0 || len(req.Message) > 500 {
+		response.BadRequest(w, "retry_after must not be negative and message must be at most 500 characters", response.CodeValidationError)
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+	if !req.Enabled {
+		log.Printf("[ADMIN] Maintenance mode disabled by %s", userID)
+		response.Success(w, h.maintenance.Disable())
+		return
+	}
+	log.Printf("[ADMIN] Maintenance mode enabled by %s", userID)
+	response.Success(w, h.maintenance.Enable(strings.TrimSpace(req.Message), time.Duration(req.RetryAfter)*time.Second, userID))
+}
+
 // HandleSeed: POST /api/v1/admin/seed
-// Creates test data for performance testing
+// Starts a background task creating test data for performance testing (super_admin only).
+// Takes optional "forms" (default 1000, max 10000) and "submissions_per_form" (default 100,
+// max 1000); larger counts are refused with 400.
+// Disabled when ENV=production unless ALLOW_SEED=true. Seeded submissions are flagged
+// is_test, so they're left out of stats and exports and purged after the test retention period.
+// Follow progress with GET /api/v1/tasks/{task_id} (or its /events stream).
 func (h *Router) HandleSeed(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeSeed(w, r) {
+		return
+	}
+
 	var req struct {
 		Forms              int `json:"forms"`
 		SubmissionsPerForm int `json:"submissions_per_form"`
@@ -35,66 +135,170 @@ func (h *Router) HandleSeed(w http.ResponseWriter, r *http.Request) {
 		req.SubmissionsPerForm = 100
 	}
 
-	// Cap to prevent excessive load
-	if req.Forms > 10000 {
-		req.Forms = 10000
+	task, err := h.seeder.Start(middleware.GetUserID(r.Context()), req.Forms, req.SubmissionsPerForm)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
 	}
-	if req.SubmissionsPerForm > 1000 {
-		req.SubmissionsPerForm = 1000
+
+	response.Accepted(w, task)
+}
+
+// HandleCreateSampleData: POST /api/v1/sample-data
+// Starts a background task creating realistic sample forms and submissions owned by the
+// caller, so they can explore stats and analytics: names, emails, countries, submissions
+// spread over the last six months and some spam. Unlike seeded test data they count in
+// stats. Takes optional "forms" (default 3) and "submissions_per_form" (default 150, max 500);
+// a user can have at most 10 sample forms. Follow progress with GET /api/v1/tasks/{task_id}.
+func (h *Router) HandleCreateSampleData(w http.ResponseWriter, r *http.Request) {
+	if h.seeder == nil {
+		response.NotFound(w, "Sample data is not available")
+		return
 	}
 
-	ctx := r.Context()
-	formsCreated := 0
-	submissionsCreated := 0
+	var req struct {
+		Forms              int `json:"forms"`
+		SubmissionsPerForm int `json:"submissions_per_form"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			response.BadRequest(w, "Invalid request body", response.CodeInvalidBody)
+			return
+		}
+	}
 
-	for i := 0; i < req.Forms; i++ {
-		// Create form (seeded forms have no specific owner)
-		ownerID := middleware.GetUserID(ctx) // Use authenticated user or empty
-		form, err := h.formService.CreateForm(ctx,
-			"Test Form "+string(rune('A'+i%26))+"-"+strconv.Itoa(i+1),
-			"",
-			nil,
-			"", // webhook_url
-			"", // webhook_secret
-			ownerID,
-			"public", // accessMode
-			"",       // submissionKey
-		)
-		if err != nil {
-			continue
+	task, err := h.seeder.StartSample(r.Context(), middleware.GetUserID(r.Context()), req.Forms, req.SubmissionsPerForm)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
 		}
-		formsCreated++
-
-		// Create submissions for this form
-		for j := 0; j < req.SubmissionsPerForm; j++ {
-			data := map[string]interface{}{
-				"name":    "User " + strconv.Itoa(j+1),
-				"email":   "user" + strconv.Itoa(j+1) + "@example.com",
-				"message": "Test message from user " + strconv.Itoa(j+1) + " on form " + strconv.Itoa(i+1),
-				"phone":   "555-" + strconv.Itoa(1000+j),
-			}
-			meta := map[string]interface{}{
-				"source":    "seed",
-				"userAgent": "SeedBot/1.0",
-			}
+		response.HandleError(w, err)
+		return
+	}
 
-			_, err := h.submissionService.Submit(ctx, form.PublicID, data, meta)
-			if err == nil {
-				submissionsCreated++
-			}
+	response.Accepted(w, task)
+}
+
+// HandlePurgeSampleData: DELETE /api/v1/sample-data
+// Deletes the caller's sample forms, including ones in the trash, with their submissions
+func (h *Router) HandlePurgeSampleData(w http.ResponseWriter, r *http.Request) {
+	if h.seeder == nil {
+		response.NotFound(w, "Sample data is not available")
+		return
+	}
+
+	purged, err := h.seeder.PurgeSample(r.Context(), middleware.GetUserID(r.Context()))
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, map[string]interface{}{"deleted": purged})
+}
+
+// HandlePreviewFieldMigration: POST /api/v1/admin/forms/{form_id}/field-migrations/preview
+// Dry runs a field migration such as {"from": "e-mail", "to": "email", "transform": "lower"}
+// (admin only): counts the submissions it would change or skip as conflicts and shows
+// up to 10 of them before and after, without saving anything.
+func (h *Router) HandlePreviewFieldMigration(w http.ResponseWriter, r *http.Request) {
+	m, ok := h.decodeFieldMigration(w, r)
+	if !ok {
+		return
+	}
+	preview, err := h.fieldMigrations.Preview(r.Context(), r.PathValue("form_id"), m)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	response.Success(w, preview)
+}
+
+// HandleStartFieldMigration: POST /api/v1/admin/forms/{form_id}/field-migrations
+// Starts a background task applying a field migration to all of the form's submissions
+// (admin only). A submission already holding a different value under "to" is left alone
+// unless "overwrite" is true. The form's field settings are renamed along with the data,
+// and the result is recorded in the form's audit log. Follow progress with GET /api/v1/tasks/{task_id}.
+func (h *Router) HandleStartFieldMigration(w http.ResponseWriter, r *http.Request) {
+	m, ok := h.decodeFieldMigration(w, r)
+	if !ok {
+		return
+	}
+	task, err := h.fieldMigrations.Start(r.Context(), r.PathValue("form_id"), middleware.GetUserID(r.Context()), m)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
 		}
+		response.HandleError(w, err)
+		return
+	}
+	log.Printf("[ADMIN] Field migration %s -> %s on form %s started by %s", m.From, m.To, r.PathValue("form_id"), middleware.GetUserID(r.Context()))
+	response.Accepted(w, task)
+}
+
+// decodeFieldMigration checks the caller is an admin and reads the migration from the body.
+// Writes the error response and returns false if either fails.
+func (h *Router) decodeFieldMigration(w http.ResponseWriter, r *http.Request) (domain.FieldMigration, bool) {
+	var m domain.FieldMigration
+	if !middleware.IsAdmin(r.Context()) {
+		response.Error(w, http.StatusForbidden, "Admin access required", response.CodeForbidden)
+		return m, false
+	}
+	if h.fieldMigrations == nil {
+		response.NotFound(w, "Field migrations are not available")
+		return m, false
+	}
+	if err := json.NewDecoder(r.Body).Decode(&m); err != nil {
+		response.BadRequest(w, "Invalid request body", response.CodeInvalidBody)
+		return m, false
 	}
+	return m, true
+}
 
-	response.Success(w, map[string]interface{}{
-		"message":             "Seeding complete",
-		"forms_created":       formsCreated,
-		"submissions_created": submissionsCreated,
-	})
+// authorizeSeed checks seeding is enabled and the caller is a super admin.
+// Writes the error response and returns false if not.
+func (h *Router) authorizeSeed(w http.ResponseWriter, r *http.Request) bool {
+	if !middleware.IsSuperAdmin(r.Context()) {
+		response.Error(w, http.StatusForbidden, "Super admin access required", response.CodeForbidden)
+		return false
+	}
+	if h.seeder == nil || !h.seedAllowed {
+		response.Error(w, http.StatusForbidden, "Seeding is disabled in production (set ALLOW_SEED=true to enable)", response.CodeSeedDisabled)
+		return false
+	}
+	return true
 }
 
-// HandleExportCSV: GET /api/v1/forms/{form_id}/export/csv
+// HandleExportCSV: GET /api/v1/forms/{form_id}/export/csv?include_test=true&date_format=iso8601&tz=Europe/Berlin
+// Test submissions are only exported when include_test=true. Timestamps are written
+// in date_format (see request.ParseTimeFormat), in tz or else the form's timezone.
 func (h *Router) HandleExportCSV(w http.ResponseWriter, r *http.Request) {
+	h.handleExport(w, r, "csv")
+}
+
+// HandleExportJSON: GET /api/v1/forms/{form_id}/export/json
+// Exports an array with one object per submission, keeping nested field values as they
+// were submitted. Takes the same query parameters as the CSV export.
+func (h *Router) HandleExportJSON(w http.ResponseWriter, r *http.Request) {
+	h.handleExport(w, r, "json")
+}
+
+// HandleExportXLSX: GET /api/v1/forms/{form_id}/export/xlsx
+// Exports an Excel workbook with the CSV export's columns, numbers and booleans as typed
+// cells. Takes the same query parameters as the CSV export.
+func (h *Router) HandleExportXLSX(w http.ResponseWriter, r *http.Request) {
+	h.handleExport(w, r, "xlsx")
+}
+
+// handleExport streams the form's submissions as a file download in the named export format
+func (h *Router) handleExport(w http.ResponseWriter, r *http.Request, name string) {
 	publicID := r.PathValue("form_id")
+	format, _ := export.Lookup(name)
 
 	// Get form to verify it exists and get name
 	form, err := h.formService.GetForm(r.Context(), publicID)
@@ -105,163 +309,181 @@ func (h *Router) HandleExportCSV(w http.ResponseWriter, r *http.Request) {
 		response.HandleError(w, err)
 		return
 	}
+	if !middleware.CanAccessForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "Access denied", response.CodeForbidden)
+		return
+	}
+	profile, ok := h.exportProfile(w, r, form)
+	if !ok {
+		return
+	}
 
-	// Get all submissions (no pagination for export)
-	submissions, err := h.submissionService.ListSubmissions(r.Context(), publicID)
+	timeFormat, err := request.ParseTimeFormat(r, h.formService.Location(r.Context(), form))
 	if err != nil {
-		response.HandleError(w, err)
+		writeTimeFormatError(w, err)
 		return
 	}
+	includeTest := r.URL.Query().Get("include_test") == "true"
 
-	// Collect all unique field keys and metadata
-	fieldSet := make(map[string]bool)
-	var allData []map[string]interface{}
-	var allMeta []map[string]interface{}
-	for _, sub := range submissions {
-		var data map[string]interface{}
-		if err := json.Unmarshal(sub.Data, &data); err == nil {
-			for key := range data {
-				fieldSet[key] = true
+	// Tabular formats need every field column up front: a first pass collects the field keys,
+	// so rows can then be written as they're read
+	var fields []string
+	if format.Tabular {
+		fieldSet := make(map[string]bool)
+		err = h.submissionService.EachSubmission(r.Context(), publicID, func(sub *domain.Submission) error {
+			if sub.IsTest && !includeTest {
+				return nil
 			}
-			allData = append(allData, data)
-		} else {
-			allData = append(allData, nil)
-		}
-
-		// Parse meta for IP, country, spam
-		var meta map[string]interface{}
-		if err := json.Unmarshal(sub.Meta, &meta); err == nil {
-			allMeta = append(allMeta, meta)
-		} else {
-			allMeta = append(allMeta, nil)
+			var data map[string]json.RawMessage
+			if json.Unmarshal(profile.Mask(sub, form).Data, &data) == nil {
+				for key := range data {
+					fieldSet[key] = true
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			if response.HandleDomainError(w, err) {
+				return
+			}
+			response.HandleError(w, err)
+			return
 		}
+		fields = slices.Sorted(maps.Keys(fieldSet))
 	}
 
-	// Convert to sorted slice
-	var fields []string
-	for key := range fieldSet {
-		fields = append(fields, key)
-	}
-
-	// Build CSV content
-	csv := buildCSVContent(submissions, allData, allMeta, fields)
-
 	// Set headers for file download
-	filename := form.Name + "_submissions.csv"
-	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	filename := form.Name + "_submissions." + format.Extension
+	w.Header().Set("Content-Type", format.ContentType)
 	w.Header().Set("Content-Disposition", "attachment; filename=\""+filename+"\"")
-	if _, err := w.Write([]byte(csv)); err != nil {
+
+	// Stream the rows; each exporter buffers its output and sends it in chunks
+	exp := format.New(w)
+	err = exp.WriteHeader(fields)
+	if err == nil {
+		err = h.submissionService.EachSubmission(r.Context(), publicID, func(sub *domain.Submission) error {
+			if sub.IsTest && !includeTest {
+				return nil
+			}
+			return exp.WriteRow(export.NewRow(profile.Mask(sub, form), timeFormat.Format))
+		})
+	}
+	if closeErr := exp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
 		// Log but don't return error - headers already sent
-		log.Printf("[ERROR] Failed to write CSV response: %v", err)
+		log.Printf("[ERROR] Failed to write %s export: %v", format.Name, err)
 	}
 }
 
-// buildCSVContent creates CSV string from submissions data
-func buildCSVContent(submissions []*domain.Submission, allData, allMeta []map[string]interface{}, fields []string) string {
-	var csv string
-
-	// Header row: id, created_at, status, metadata columns, + dynamic fields
-	csv = "id,created_at,status,ip,country,spam_score,is_spam"
-	for _, f := range fields {
-		csv += "," + escapeCSV(f)
-	}
-	csv += "\n"
-
-	// Data rows
-	for i, sub := range submissions {
-		csv += escapeCSV(sub.ID) + ","
-		csv += escapeCSV(sub.CreatedAt.Format("2006-01-02 15:04:05")) + ","
-		csv += escapeCSV(string(sub.Status)) + ","
-
-		// Extract metadata
-		ip, country, spamScore, isSpam := extractMetadata(allMeta[i])
-		csv += escapeCSV(ip) + ","
-		csv += escapeCSV(country) + ","
-		csv += escapeCSV(spamScore) + ","
-		csv += escapeCSV(isSpam)
-
-		// Dynamic fields
-		data := allData[i]
-		for _, f := range fields {
-			csv += "," + escapeCSV(formatFieldValue(data, f))
+// exportProfile returns the profile masking the form's submissions the user reads or
+// exports, nil when they see them in full. Writes the error response and returns false
+// if their role may not (see domain.ExportSettings).
+func (h *Router) exportProfile(w http.ResponseWriter, r *http.Request, form *domain.Form) (*domain.ExportProfile, bool) {
+	settings, err := h.submissionService.ExportSettings(r.Context())
+	if err != nil {
+		response.HandleError(w, err)
+		return nil, false
+	}
+	profile, err := settings.Access(middleware.ExportRole(r.Context(), form))
+	if err != nil {
+		if !response.HandleDomainError(w, err) {
+			response.HandleError(w, err)
 		}
-		csv += "\n"
+		return nil, false
 	}
-
-	return csv
+	return profile, true
 }
 
-// extractMetadata gets IP, country, and spam info from meta
-func extractMetadata(meta map[string]interface{}) (ip, country, spamScore, isSpam string) {
-	if meta == nil {
-		return "", "", "", ""
-	}
+// HandleExportStatsCSV: GET /api/v1/forms/{form_id}/stats/export?from=2026-01-01&to=2026-01-31&tz=Europe/Berlin
+// Exports a daily time series (submissions, spam, conversions, top referrers) as CSV.
+// Dates are days in tz or else the form's timezone. Defaults to the last 30 days when no range is given.
+func (h *Router) HandleExportStatsCSV(w http.ResponseWriter, r *http.Request) {
+	publicID := r.PathValue("form_id")
 
-	if serverMeta, ok := meta["_server"].(map[string]interface{}); ok {
-		if v, ok := serverMeta["ip"].(string); ok {
-			ip = v
+	form, err := h.formService.GetForm(r.Context(), publicID)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
 		}
-		if v, ok := serverMeta["country"].(string); ok {
-			country = v
+		response.HandleError(w, err)
+		return
+	}
+	if !middleware.CanAccessForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "Access denied", response.CodeForbidden)
+		return
+	}
+
+	loc, err := request.ParseTimezone(r)
+	if err != nil {
+		writeTimeFormatError(w, err)
+		return
+	}
+	if loc == nil {
+		loc = h.formService.Location(r.Context(), form)
+	}
+	to := domain.StartOfDay(time.Now(), loc)
+	from := to.AddDate(0, 0, -29)
+	if v := r.URL.Query().Get("from"); v != "" {
+		if from, err = time.ParseInLocation("2006-01-02", v, loc); err != nil {
+			response.BadRequest(w, "from must be a date in YYYY-MM-DD format", response.CodeInvalidDate)
+			return
 		}
 	}
-	if spamMeta, ok := meta["_spam"].(map[string]interface{}); ok {
-		if v, ok := spamMeta["score"].(float64); ok {
-			spamScore = strconv.FormatFloat(v, 'f', 0, 64)
+	if v := r.URL.Query().Get("to"); v != "" {
+		if to, err = time.ParseInLocation("2006-01-02", v, loc); err != nil {
+			response.BadRequest(w, "to must be a date in YYYY-MM-DD format", response.CodeInvalidDate)
+			return
 		}
-		if v, ok := spamMeta["is_spam"].(bool); ok {
-			isSpam = strconv.FormatBool(v)
+	}
+
+	series, err := h.statsService.GetFormDailyStats(r.Context(), publicID, from, to, loc)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
 		}
+		response.HandleError(w, err)
+		return
 	}
-	return
-}
 
-// formatFieldValue formats a field value for CSV output
-func formatFieldValue(data map[string]interface{}, field string) string {
-	if data == nil {
-		return ""
+	filename := form.Name + "_stats_" + from.Format("20060102") + "-" + to.Format("20060102") + ".csv"
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+
+	// Referrers come from request headers, so they're kept from being read as formulas
+	out := csv.NewWriter(w)
+	_ = out.Write([]string{"date", "submissions", "spam", "conversions", "top_referrers"})
+	for _, day := range series {
+		_ = out.Write([]string{
+			day.Date,
+			strconv.Itoa(day.Submissions),
+			strconv.Itoa(day.Spam),
+			strconv.Itoa(day.Conversions),
+			export.SafeCell(strings.Join(day.TopReferrers, " | ")),
+		})
 	}
-	v, ok := data[field]
-	if !ok {
-		return ""
-	}
-
-	switch t := v.(type) {
-	case string:
-		return t
-	case float64:
-		return strconv.FormatFloat(t, 'f', -1, 64)
-	case bool:
-		return strconv.FormatBool(t)
-	default:
-		// JSON encode complex types
-		if b, err := json.Marshal(v); err == nil {
-			return string(b)
-		}
-		return ""
+	out.Flush()
+	if err := out.Error(); err != nil {
+		log.Printf("[ERROR] Failed to write stats CSV response: %v", err)
 	}
 }
 
-// escapeCSV escapes a value for CSV format
-func escapeCSV(s string) string {
-	needsQuote := false
-	for _, c := range s {
-		if c == ',' || c == '"' || c == '\n' || c == '\r' {
-			needsQuote = true
-			break
-		}
-	}
-	if !needsQuote {
-		return s
+// writeTimeFormatError rejects an export's date_format or tz parameter
+func writeTimeFormatError(w http.ResponseWriter, err error) {
+	if response.HandleDomainError(w, err) {
+		return
 	}
-	// Escape quotes by doubling them
-	escaped := ""
-	for _, c := range s {
-		if c == '"' {
-			escaped += "\"\""
-		} else {
-			escaped += string(c)
+	response.BadRequest(w, err.Error(), response.CodeInvalidDateFormat)
+}
+
+// withoutTestSubmissions drops submissions flagged as test data
+func withoutTestSubmissions(submissions []*domain.Submission) []*domain.Submission {
+	kept := submissions[:0:0]
+	for _, sub := range submissions {
+		if !sub.IsTest {
+			kept = append(kept, sub)
 		}
 	}
-	return "\"" + escaped + "\""
+	return kept
 }
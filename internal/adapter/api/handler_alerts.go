@@ -0,0 +1,115 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"headless_form/internal/adapter/api/response"
+	"headless_form/internal/adapter/middleware"
+	"headless_form/internal/core/domain"
+	"headless_form/internal/core/service"
+)
+
+// AlertHandler handles per-form alert rule endpoints
+type AlertHandler struct {
+	alertService *service.AlertService
+	formService  *service.FormService
+}
+
+// NewAlertHandler creates a new alert handler
+func NewAlertHandler(alertService *service.AlertService, formService *service.FormService) *AlertHandler {
+	return &AlertHandler{alertService: alertService, formService: formService}
+}
+
+// RegisterRoutes registers alert routes (auth required)
+func (h *AlertHandler) RegisterRoutes(mux *http.ServeMux, authMiddleware func(http.Handler) http.Handler) {
+	mux.Handle("GET /api/v1/forms/{form_id}/alerts", authMiddleware(http.HandlerFunc(h.HandleListAlerts)))
+	mux.Handle("POST /api/v1/forms/{form_id}/alerts", authMiddleware(http.HandlerFunc(h.HandleCreateAlert)))
+	mux.Handle("DELETE /api/v1/forms/{form_id}/alerts/{alert_id}", authMiddleware(http.HandlerFunc(h.HandleDeleteAlert)))
+}
+
+// authorizeForm loads the form and checks the current user can manage it.
+// Writes the error response and returns false if not.
+func (h *AlertHandler) authorizeForm(w http.ResponseWriter, r *http.Request) bool {
+	form, err := h.formService.GetForm(r.Context(), r.PathValue("form_id"))
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return false
+		}
+		response.HandleError(w, err)
+		return false
+	}
+	if !middleware.CanManageForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "Access denied", response.CodeForbidden)
+		return false
+	}
+	return true
+}
+
+// HandleListAlerts: GET /api/v1/forms/{form_id}/alerts
+func (h *AlertHandler) HandleListAlerts(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeForm(w, r) {
+		return
+	}
+
+	rules, err := h.alertService.ListRules(r.Context(), r.PathValue("form_id"))
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	if rules == nil {
+		rules = []*domain.AlertRule{}
+	}
+
+	response.Success(w, map[string]interface{}{
+		"alerts": rules,
+	})
+}
+
+// HandleCreateAlert: POST /api/v1/forms/{form_id}/alerts
+func (h *AlertHandler) HandleCreateAlert(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeForm(w, r) {
+		return
+	}
+
+	var req struct {
+		Type        string  `json:"type"`
+		Threshold   float64 `json:"threshold"`
+		WindowHours int     `json:"window_hours"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid JSON body", response.CodeInvalidBody)
+		return
+	}
+
+	rule, err := h.alertService.CreateRule(r.Context(), r.PathValue("form_id"), domain.AlertType(req.Type), req.Threshold, req.WindowHours)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Created(w, rule)
+}
+
+// HandleDeleteAlert: DELETE /api/v1/forms/{form_id}/alerts/{alert_id}
+func (h *AlertHandler) HandleDeleteAlert(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeForm(w, r) {
+		return
+	}
+
+	if err := h.alertService.DeleteRule(r.Context(), r.PathValue("form_id"), r.PathValue("alert_id")); err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, map[string]string{"message": "Alert deleted successfully"})
+}
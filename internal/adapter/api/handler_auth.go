@@ -3,11 +3,13 @@ package api
 import (
 	"encoding/json"
 	"fmt"
+	"log"
 	"net/http"
 
 	"headless_form/internal/adapter/api/response"
 	"headless_form/internal/adapter/email"
 	"headless_form/internal/adapter/middleware"
+	"headless_form/internal/adapter/oauth"
 	"headless_form/internal/core/domain"
 	"headless_form/internal/core/service"
 )
@@ -17,11 +19,18 @@ type AuthHandler struct {
 	authService  *service.AuthService
 	emailService *email.Service
 	baseURL      string
+	demo         *service.DemoCredentials // Shown on the login page in demo mode
+	sso          *oauth.Client            // Talks to the sign-in providers in the site settings
 }
 
 // NewAuthHandler creates a new auth handler
 func NewAuthHandler(authService *service.AuthService, emailService *email.Service, baseURL string) *AuthHandler {
-	return &AuthHandler{authService: authService, emailService: emailService, baseURL: baseURL}
+	return &AuthHandler{authService: authService, emailService: emailService, baseURL: baseURL, sso: oauth.NewClient()}
+}
+
+// SetDemoCredentials publishes the demo login through the setup endpoint (demo mode)
+func (h *AuthHandler) SetDemoCredentials(c service.DemoCredentials) {
+	h.demo = &c
 }
 
 // RegisterPublicRoutes registers public auth routes (no auth required)
@@ -31,6 +40,8 @@ func (h *AuthHandler) RegisterPublicRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("GET /api/v1/auth/setup", h.HandleSetupRequired)
 	mux.HandleFunc("POST /api/v1/auth/forgot-password", h.HandleForgotPassword)
 	mux.HandleFunc("POST /api/v1/auth/reset-password", h.HandleResetPassword)
+	mux.HandleFunc("GET /api/v1/auth/oauth/{provider}/start", h.HandleOAuthStart)
+	mux.HandleFunc("GET /api/v1/auth/oauth/{provider}/callback", h.HandleOAuthCallback)
 }
 
 // RegisterProtectedRoutes registers protected auth routes (auth required)
@@ -47,6 +58,10 @@ func (h *AuthHandler) RegisterProtectedRoutes(mux *http.ServeMux, authMiddleware
 	mux.Handle("POST /api/v1/users", authMiddleware(http.HandlerFunc(h.HandleCreateUser)))
 	mux.Handle("PUT /api/v1/users/{user_id}", authMiddleware(http.HandlerFunc(h.HandleUpdateUser)))
 	mux.Handle("DELETE /api/v1/users/{user_id}", authMiddleware(http.HandlerFunc(h.HandleDeleteUser)))
+
+	// Moving users between instances
+	mux.Handle("GET /api/v1/users/export", authMiddleware(http.HandlerFunc(h.HandleExportUsers)))
+	mux.Handle("POST /api/v1/users/import", authMiddleware(http.HandlerFunc(h.HandleImportUsers)))
 }
 
 // RegisterRequest represents the registration request body
@@ -72,12 +87,17 @@ type AuthResponse struct {
 func (h *AuthHandler) HandleRegister(w http.ResponseWriter, r *http.Request) {
 	var req RegisterRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.BadRequest(w, "Invalid request body", "INVALID_BODY")
+		response.BadRequest(w, "Invalid request body", response.CodeInvalidBody)
 		return
 	}
 
 	if req.Email == "" || req.Password == "" {
-		response.BadRequest(w, "Email and password are required", "MISSING_FIELDS")
+		response.BadRequest(w, "Email and password are required", response.CodeMissingFields)
+		return
+	}
+
+	// The first user can always register; that's how the instance is set up
+	if hasUsers, _ := h.authService.HasUsers(r.Context()); hasUsers && !h.passwordLoginAllowed(w, r) {
 		return
 	}
 
@@ -85,13 +105,13 @@ func (h *AuthHandler) HandleRegister(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		switch err {
 		case domain.ErrUserExists:
-			response.Error(w, http.StatusConflict, "User already exists", "USER_EXISTS")
+			response.Error(w, http.StatusConflict, "User already exists", response.CodeUserExists)
 		case domain.ErrPasswordTooShort:
-			response.BadRequest(w, "Password must be at least 8 characters", "PASSWORD_TOO_SHORT")
+			response.BadRequest(w, "Password must be at least 8 characters", response.CodePasswordTooShort)
 		case domain.ErrEmailRequired:
-			response.BadRequest(w, "Email is required", "EMAIL_REQUIRED")
+			response.BadRequest(w, "Email is required", response.CodeEmailRequired)
 		default:
-			response.Error(w, http.StatusInternalServerError, "Failed to register", "REGISTER_FAILED")
+			response.Error(w, http.StatusInternalServerError, "Failed to register", response.CodeRegisterFailed)
 		}
 		return
 	}
@@ -99,7 +119,7 @@ func (h *AuthHandler) HandleRegister(w http.ResponseWriter, r *http.Request) {
 	// Generate token for immediate login
 	token, _, err := h.authService.Login(r.Context(), req.Email, req.Password)
 	if err != nil {
-		response.Error(w, http.StatusInternalServerError, "Registration successful but failed to generate token", "TOKEN_FAILED")
+		response.Error(w, http.StatusInternalServerError, "Registration successful but failed to generate token", response.CodeTokenFailed)
 		return
 	}
 
@@ -113,18 +133,22 @@ func (h *AuthHandler) HandleRegister(w http.ResponseWriter, r *http.Request) {
 func (h *AuthHandler) HandleLogin(w http.ResponseWriter, r *http.Request) {
 	var req LoginRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.BadRequest(w, "Invalid request body", "INVALID_BODY")
+		response.BadRequest(w, "Invalid request body", response.CodeInvalidBody)
 		return
 	}
 
 	if req.Email == "" || req.Password == "" {
-		response.BadRequest(w, "Email and password are required", "MISSING_FIELDS")
+		response.BadRequest(w, "Email and password are required", response.CodeMissingFields)
+		return
+	}
+
+	if !h.passwordLoginAllowed(w, r) {
 		return
 	}
 
 	token, user, err := h.authService.Login(r.Context(), req.Email, req.Password)
 	if err != nil {
-		response.Error(w, http.StatusUnauthorized, "Invalid credentials", "INVALID_CREDENTIALS")
+		response.Error(w, http.StatusUnauthorized, "Invalid credentials", response.CodeInvalidCredentials)
 		return
 	}
 
@@ -138,7 +162,7 @@ func (h *AuthHandler) HandleLogin(w http.ResponseWriter, r *http.Request) {
 func (h *AuthHandler) HandleMe(w http.ResponseWriter, r *http.Request) {
 	userID := middleware.GetUserID(r.Context())
 	if userID == "" {
-		response.Error(w, http.StatusUnauthorized, "Not authenticated", "UNAUTHORIZED")
+		response.Error(w, http.StatusUnauthorized, "Not authenticated", response.CodeUnauthorized)
 		return
 	}
 
@@ -151,24 +175,39 @@ func (h *AuthHandler) HandleMe(w http.ResponseWriter, r *http.Request) {
 	response.Success(w, user.ToPublic())
 }
 
-// HandleSetupRequired checks if initial setup is needed
+// HandleSetupRequired checks if initial setup is needed, and tells the login page which
+// sign-in providers to offer and whether password login is on.
+// In demo mode it also returns the demo login.
 func (h *AuthHandler) HandleSetupRequired(w http.ResponseWriter, r *http.Request) {
 	hasUsers, err := h.authService.HasUsers(r.Context())
 	if err != nil {
-		response.Error(w, http.StatusInternalServerError, "Failed to check setup status", "CHECK_FAILED")
+		response.Error(w, http.StatusInternalServerError, "Failed to check setup status", response.CodeCheckFailed)
 		return
 	}
 
-	response.Success(w, map[string]bool{
-		"setup_required": !hasUsers,
-	})
+	oauthSettings, err := h.authService.OAuthSettings(r.Context())
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Failed to check setup status", response.CodeCheckFailed)
+		return
+	}
+	passwordLogin, _ := h.authService.PasswordLoginEnabled(r.Context())
+
+	data := map[string]interface{}{
+		"setup_required":  !hasUsers,
+		"password_login":  passwordLogin,
+		"oauth_providers": h.oauthProviders(oauthSettings),
+	}
+	if h.demo != nil {
+		data["demo"] = h.demo
+	}
+	response.Success(w, data)
 }
 
-// HandleListUsers returns all users (admin only)
+// HandleListUsers returns a page of users (admin only)
 func (h *AuthHandler) HandleListUsers(w http.ResponseWriter, r *http.Request) {
 	// Check if current user is admin or super_admin
 	if !middleware.IsAdmin(r.Context()) {
-		response.Error(w, http.StatusForbidden, "Admin access required", "FORBIDDEN")
+		response.Error(w, http.StatusForbidden, "Admin access required", response.CodeForbidden)
 		return
 	}
 
@@ -178,15 +217,19 @@ func (h *AuthHandler) HandleListUsers(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// The user list is small enough to page in memory
+	page, limit := parsePage(r, 100, 500)
+	start := min((page-1)*limit, len(users))
+	end := min(start+limit, len(users))
+
 	// Convert to public representation
-	var publicUsers []*domain.UserPublic
-	for _, u := range users {
+	publicUsers := make([]*domain.UserPublic, 0, end-start)
+	for _, u := range users[start:end] {
 		publicUsers = append(publicUsers, u.ToPublic())
 	}
 
-	response.Success(w, map[string]interface{}{
-		"users": publicUsers,
-		"total": len(publicUsers),
+	response.Paginated(w, r, "users", publicUsers, page, limit, len(users), map[string]interface{}{
+		"total": len(users),
 	})
 }
 
@@ -194,7 +237,7 @@ func (h *AuthHandler) HandleListUsers(w http.ResponseWriter, r *http.Request) {
 func (h *AuthHandler) HandleCreateUser(w http.ResponseWriter, r *http.Request) {
 	// Check if current user is admin or super_admin
 	if !middleware.IsAdmin(r.Context()) {
-		response.Error(w, http.StatusForbidden, "Admin access required", "FORBIDDEN")
+		response.Error(w, http.StatusForbidden, "Admin access required", response.CodeForbidden)
 		return
 	}
 
@@ -206,12 +249,12 @@ func (h *AuthHandler) HandleCreateUser(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.BadRequest(w, "Invalid request body", "INVALID_BODY")
+		response.BadRequest(w, "Invalid request body", response.CodeInvalidBody)
 		return
 	}
 
 	if req.Email == "" || req.Password == "" {
-		response.BadRequest(w, "Email and password are required", "MISSING_FIELDS")
+		response.BadRequest(w, "Email and password are required", response.CodeMissingFields)
 		return
 	}
 
@@ -225,9 +268,9 @@ func (h *AuthHandler) HandleCreateUser(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		switch err {
 		case domain.ErrUserExists:
-			response.Error(w, http.StatusConflict, "User already exists", "USER_EXISTS")
+			response.Error(w, http.StatusConflict, "User already exists", response.CodeUserExists)
 		case domain.ErrPasswordTooShort:
-			response.BadRequest(w, "Password must be at least 8 characters", "PASSWORD_TOO_SHORT")
+			response.BadRequest(w, "Password must be at least 8 characters", response.CodePasswordTooShort)
 		default:
 			response.HandleError(w, err)
 		}
@@ -241,20 +284,20 @@ func (h *AuthHandler) HandleCreateUser(w http.ResponseWriter, r *http.Request) {
 func (h *AuthHandler) HandleDeleteUser(w http.ResponseWriter, r *http.Request) {
 	// Check if current user is admin or super_admin
 	if !middleware.IsAdmin(r.Context()) {
-		response.Error(w, http.StatusForbidden, "Admin access required", "FORBIDDEN")
+		response.Error(w, http.StatusForbidden, "Admin access required", response.CodeForbidden)
 		return
 	}
 
 	userID := r.PathValue("user_id")
 	if userID == "" {
-		response.BadRequest(w, "User ID required", "MISSING_USER_ID")
+		response.BadRequest(w, "User ID required", response.CodeMissingUserID)
 		return
 	}
 
 	// Prevent self-deletion
 	currentUserID := middleware.GetUserID(r.Context())
 	if userID == currentUserID {
-		response.BadRequest(w, "Cannot delete your own account", "SELF_DELETE")
+		response.BadRequest(w, "Cannot delete your own account", response.CodeSelfDelete)
 		return
 	}
 
@@ -262,7 +305,7 @@ func (h *AuthHandler) HandleDeleteUser(w http.ResponseWriter, r *http.Request) {
 		if err == domain.ErrUserNotFound {
 			response.NotFound(w, "User not found")
 		} else {
-			response.Error(w, http.StatusBadRequest, err.Error(), "DELETE_FAILED")
+			response.Error(w, http.StatusBadRequest, err.Error(), response.CodeDeleteFailed)
 		}
 		return
 	}
@@ -274,27 +317,38 @@ func (h *AuthHandler) HandleDeleteUser(w http.ResponseWriter, r *http.Request) {
 func (h *AuthHandler) HandleUpdateProfile(w http.ResponseWriter, r *http.Request) {
 	userID := middleware.GetUserID(r.Context())
 	if userID == "" {
-		response.Error(w, http.StatusUnauthorized, "Not authenticated", "UNAUTHORIZED")
+		response.Error(w, http.StatusUnauthorized, "Not authenticated", response.CodeUnauthorized)
 		return
 	}
 
 	var req struct {
-		Name  string `json:"name"`
-		Email string `json:"email"`
+		Name     string  `json:"name"`
+		Email    string  `json:"email"`
+		Timezone *string `json:"timezone"` // IANA zone for dashboard stats; "" resets to UTC
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.BadRequest(w, "Invalid request body", "INVALID_BODY")
+		response.BadRequest(w, "Invalid request body", response.CodeInvalidBody)
 		return
 	}
 
+	if req.Timezone != nil {
+		if _, err := domain.NormalizeTimezone(*req.Timezone); err != nil {
+			response.BadRequest(w, err.Error(), response.CodeValidationError)
+			return
+		}
+	}
+
 	user, err := h.authService.UpdateUser(r.Context(), userID, req.Name, req.Email, nil)
+	if err == nil && req.Timezone != nil {
+		user, err = h.authService.SetTimezone(r.Context(), userID, *req.Timezone)
+	}
 	if err != nil {
 		switch err {
 		case domain.ErrUserNotFound:
 			response.NotFound(w, "User not found")
 		case domain.ErrUserExists:
-			response.Error(w, http.StatusConflict, "Email already in use", "EMAIL_EXISTS")
+			response.Error(w, http.StatusConflict, "Email already in use", response.CodeEmailExists)
 		default:
 			response.HandleError(w, err)
 		}
@@ -308,7 +362,7 @@ func (h *AuthHandler) HandleUpdateProfile(w http.ResponseWriter, r *http.Request
 func (h *AuthHandler) HandleUpdatePassword(w http.ResponseWriter, r *http.Request) {
 	userID := middleware.GetUserID(r.Context())
 	if userID == "" {
-		response.Error(w, http.StatusUnauthorized, "Not authenticated", "UNAUTHORIZED")
+		response.Error(w, http.StatusUnauthorized, "Not authenticated", response.CodeUnauthorized)
 		return
 	}
 
@@ -318,21 +372,21 @@ func (h *AuthHandler) HandleUpdatePassword(w http.ResponseWriter, r *http.Reques
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.BadRequest(w, "Invalid request body", "INVALID_BODY")
+		response.BadRequest(w, "Invalid request body", response.CodeInvalidBody)
 		return
 	}
 
 	if req.CurrentPassword == "" || req.NewPassword == "" {
-		response.BadRequest(w, "Current and new password are required", "MISSING_FIELDS")
+		response.BadRequest(w, "Current and new password are required", response.CodeMissingFields)
 		return
 	}
 
 	if err := h.authService.UpdatePassword(r.Context(), userID, req.CurrentPassword, req.NewPassword); err != nil {
 		switch err {
 		case domain.ErrInvalidCredentials:
-			response.Error(w, http.StatusUnauthorized, "Current password is incorrect", "INVALID_PASSWORD")
+			response.Error(w, http.StatusUnauthorized, "Current password is incorrect", response.CodeInvalidPassword)
 		case domain.ErrPasswordTooShort:
-			response.BadRequest(w, "Password must be at least 8 characters", "PASSWORD_TOO_SHORT")
+			response.BadRequest(w, "Password must be at least 8 characters", response.CodePasswordTooShort)
 		default:
 			response.HandleError(w, err)
 		}
@@ -346,13 +400,13 @@ func (h *AuthHandler) HandleUpdatePassword(w http.ResponseWriter, r *http.Reques
 func (h *AuthHandler) HandleUpdateUser(w http.ResponseWriter, r *http.Request) {
 	// Check if current user is admin or super_admin
 	if !middleware.IsAdmin(r.Context()) {
-		response.Error(w, http.StatusForbidden, "Admin access required", "FORBIDDEN")
+		response.Error(w, http.StatusForbidden, "Admin access required", response.CodeForbidden)
 		return
 	}
 
 	userID := r.PathValue("user_id")
 	if userID == "" {
-		response.BadRequest(w, "User ID required", "MISSING_USER_ID")
+		response.BadRequest(w, "User ID required", response.CodeMissingUserID)
 		return
 	}
 
@@ -363,7 +417,7 @@ func (h *AuthHandler) HandleUpdateUser(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.BadRequest(w, "Invalid request body", "INVALID_BODY")
+		response.BadRequest(w, "Invalid request body", response.CodeInvalidBody)
 		return
 	}
 
@@ -373,7 +427,7 @@ func (h *AuthHandler) HandleUpdateUser(w http.ResponseWriter, r *http.Request) {
 		r := domain.UserRole(req.Role)
 		// Validate role
 		if r != domain.RoleSuperAdmin && r != domain.RoleAdmin && r != domain.RoleUser {
-			response.BadRequest(w, "Invalid role. Must be 'super_admin', 'admin', or 'user'", "INVALID_ROLE")
+			response.BadRequest(w, "Invalid role. Must be 'super_admin', 'admin', or 'user'", response.CodeInvalidRole)
 			return
 		}
 		role = &r
@@ -385,7 +439,7 @@ func (h *AuthHandler) HandleUpdateUser(w http.ResponseWriter, r *http.Request) {
 		case domain.ErrUserNotFound:
 			response.NotFound(w, "User not found")
 		case domain.ErrUserExists:
-			response.Error(w, http.StatusConflict, "Email already in use", "EMAIL_EXISTS")
+			response.Error(w, http.StatusConflict, "Email already in use", response.CodeEmailExists)
 		default:
 			response.HandleError(w, err)
 		}
@@ -395,6 +449,79 @@ func (h *AuthHandler) HandleUpdateUser(w http.ResponseWriter, r *http.Request) {
 	response.Success(w, user.ToPublic())
 }
 
+// HandleExportUsers: GET /api/v1/users/export?include_password_hashes=true
+// Downloads every user and who owns which form, to import into another instance with
+// POST /api/v1/users/import (admin only). Password hashes are left out unless asked for,
+// which takes a super admin; users imported without one are invited to set a password.
+func (h *AuthHandler) HandleExportUsers(w http.ResponseWriter, r *http.Request) {
+	if !middleware.IsAdmin(r.Context()) {
+		response.Error(w, http.StatusForbidden, "Admin access required", response.CodeForbidden)
+		return
+	}
+	withPasswords := r.URL.Query().Get("include_password_hashes") == "true"
+	if withPasswords && !middleware.IsSuperAdmin(r.Context()) {
+		response.Error(w, http.StatusForbidden, "Super admin access required to export password hashes", response.CodeForbidden)
+		return
+	}
+
+	export, err := h.authService.ExportUsers(r.Context(), withPasswords)
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+	log.Printf("[ADMIN] %d users exported by %s (password hashes: %t)", len(export.Users), middleware.GetUserID(r.Context()), withPasswords)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", "attachment; filename=\"users_"+export.ExportedAt.Format("2006-01-02")+".json\"")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(export)
+}
+
+// HandleImportUsers: POST /api/v1/users/import?dry_run=true
+// Imports a user export from another instance (super admin only): users not here yet are
+// created, matched by email, and the listed forms found here are handed to their owners.
+// Users without a password hash are emailed a link to set one. With dry_run=true nothing
+// is saved and no email is sent.
+func (h *AuthHandler) HandleImportUsers(w http.ResponseWriter, r *http.Request) {
+	if !middleware.IsSuperAdmin(r.Context()) {
+		response.Error(w, http.StatusForbidden, "Super admin access required", response.CodeForbidden)
+		return
+	}
+
+	var export domain.UserExport
+	if err := json.NewDecoder(r.Body).Decode(&export); err != nil {
+		response.BadRequest(w, "Invalid request body", response.CodeInvalidBody)
+		return
+	}
+
+	dryRun := r.URL.Query().Get("dry_run") == "true"
+	res, err := h.authService.ImportUsers(r.Context(), &export, dryRun)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+
+	for email, token := range res.InviteTokens {
+		if h.emailService == nil {
+			break
+		}
+		setupURL := fmt.Sprintf("%s/reset-password?token=%s", h.baseURL, token)
+		if err := h.emailService.SendInvite(email, setupURL, service.UserInviteTTL); err != nil {
+			log.Printf("[ADMIN] Failed to send invite to %s: %v", email, err)
+		}
+	}
+	if !dryRun {
+		log.Printf("[ADMIN] Users imported by %s: %d created, %d invited, %d forms assigned",
+			middleware.GetUserID(r.Context()), len(res.Created), len(res.Invited), res.FormsAssigned)
+	}
+
+	response.Success(w, res)
+}
+
 // HandleForgotPassword initiates password reset flow
 func (h *AuthHandler) HandleForgotPassword(w http.ResponseWriter, r *http.Request) {
 	var req struct {
@@ -402,12 +529,16 @@ func (h *AuthHandler) HandleForgotPassword(w http.ResponseWriter, r *http.Reques
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.BadRequest(w, "Invalid request body", "INVALID_BODY")
+		response.BadRequest(w, "Invalid request body", response.CodeInvalidBody)
 		return
 	}
 
 	if req.Email == "" {
-		response.BadRequest(w, "Email is required", "EMAIL_REQUIRED")
+		response.BadRequest(w, "Email is required", response.CodeEmailRequired)
+		return
+	}
+
+	if !h.passwordLoginAllowed(w, r) {
 		return
 	}
 
@@ -434,21 +565,25 @@ func (h *AuthHandler) HandleResetPassword(w http.ResponseWriter, r *http.Request
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.BadRequest(w, "Invalid request body", "INVALID_BODY")
+		response.BadRequest(w, "Invalid request body", response.CodeInvalidBody)
 		return
 	}
 
 	if req.Token == "" || req.NewPassword == "" {
-		response.BadRequest(w, "Token and new password are required", "MISSING_FIELDS")
+		response.BadRequest(w, "Token and new password are required", response.CodeMissingFields)
+		return
+	}
+
+	if !h.passwordLoginAllowed(w, r) {
 		return
 	}
 
 	if err := h.authService.ResetPassword(r.Context(), req.Token, req.NewPassword); err != nil {
 		switch err {
 		case domain.ErrInvalidResetToken:
-			response.BadRequest(w, "Invalid or expired reset token", "INVALID_TOKEN")
+			response.BadRequest(w, "Invalid or expired reset token", response.CodeInvalidToken)
 		case domain.ErrPasswordTooShort:
-			response.BadRequest(w, "Password must be at least 8 characters", "PASSWORD_TOO_SHORT")
+			response.BadRequest(w, "Password must be at least 8 characters", response.CodePasswordTooShort)
 		default:
 			response.HandleError(w, err)
 		}
@@ -0,0 +1,156 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"headless_form/internal/adapter/api/response"
+	"headless_form/internal/adapter/middleware"
+	"headless_form/internal/core/domain"
+	"headless_form/internal/core/service"
+)
+
+// CommentHandler handles comment threads on submissions
+type CommentHandler struct {
+	commentService    *service.CommentService
+	submissionService *service.SubmissionService
+	formService       *service.FormService
+}
+
+// NewCommentHandler creates a new comment handler
+func NewCommentHandler(commentService *service.CommentService, submissionService *service.SubmissionService, formService *service.FormService) *CommentHandler {
+	return &CommentHandler{commentService: commentService, submissionService: submissionService, formService: formService}
+}
+
+// RegisterRoutes registers comment routes (auth required)
+func (h *CommentHandler) RegisterRoutes(mux *http.ServeMux, authMiddleware func(http.Handler) http.Handler) {
+	mux.Handle("GET /api/v1/submissions/{sub_id}/comments", authMiddleware(http.HandlerFunc(h.HandleListComments)))
+	mux.Handle("POST /api/v1/submissions/{sub_id}/comments", authMiddleware(http.HandlerFunc(h.HandleCreateComment)))
+	mux.Handle("PUT /api/v1/submissions/{sub_id}/comments/{comment_id}", authMiddleware(http.HandlerFunc(h.HandleUpdateComment)))
+	mux.Handle("DELETE /api/v1/submissions/{sub_id}/comments/{comment_id}", authMiddleware(http.HandlerFunc(h.HandleDeleteComment)))
+}
+
+// authorizeSubmission loads the submission and its form and checks the current user can access them.
+// Writes the error response and returns nil if not.
+func (h *CommentHandler) authorizeSubmission(w http.ResponseWriter, r *http.Request) (*domain.Submission, *domain.Form) {
+	sub, err := h.submissionService.GetSubmission(r.Context(), r.PathValue("sub_id"))
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return nil, nil
+		}
+		response.HandleError(w, err)
+		return nil, nil
+	}
+	form, err := h.formService.GetFormByID(r.Context(), sub.FormID)
+	if err != nil {
+		response.NotFound(w, "Associated form not found")
+		return nil, nil
+	}
+	if !middleware.CanAccessForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "Access denied", response.CodeForbidden)
+		return nil, nil
+	}
+	return sub, form
+}
+
+// HandleListComments: GET /api/v1/submissions/{sub_id}/comments
+// Returns the submission's comments oldest first; replies have parent_id set.
+func (h *CommentHandler) HandleListComments(w http.ResponseWriter, r *http.Request) {
+	sub, _ := h.authorizeSubmission(w, r)
+	if sub == nil {
+		return
+	}
+
+	comments, err := h.commentService.ListComments(r.Context(), sub.ID)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	if comments == nil {
+		comments = []*domain.Comment{}
+	}
+
+	response.Success(w, map[string]interface{}{
+		"comments": comments,
+	})
+}
+
+// HandleCreateComment: POST /api/v1/submissions/{sub_id}/comments
+// Body: {"body": "Can you follow up, @jane@example.com?", "parent_id": "optional comment to reply to"}
+func (h *CommentHandler) HandleCreateComment(w http.ResponseWriter, r *http.Request) {
+	sub, form := h.authorizeSubmission(w, r)
+	if sub == nil {
+		return
+	}
+
+	var req struct {
+		Body     string `json:"body"`
+		ParentID string `json:"parent_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid JSON body", response.CodeInvalidBody)
+		return
+	}
+
+	comment, err := h.commentService.AddComment(r.Context(), sub, form, middleware.GetUserID(r.Context()), req.ParentID, req.Body)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Created(w, comment)
+}
+
+// HandleUpdateComment: PUT /api/v1/submissions/{sub_id}/comments/{comment_id}
+// Only the author can edit a comment.
+func (h *CommentHandler) HandleUpdateComment(w http.ResponseWriter, r *http.Request) {
+	sub, form := h.authorizeSubmission(w, r)
+	if sub == nil {
+		return
+	}
+
+	var req struct {
+		Body string `json:"body"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid JSON body", response.CodeInvalidBody)
+		return
+	}
+
+	comment, err := h.commentService.UpdateComment(r.Context(), sub, form, r.PathValue("comment_id"), middleware.GetUserID(r.Context()), req.Body)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, comment)
+}
+
+// HandleDeleteComment: DELETE /api/v1/submissions/{sub_id}/comments/{comment_id}
+// Deletes the comment and its replies. Authors can delete their own comments, admins any.
+func (h *CommentHandler) HandleDeleteComment(w http.ResponseWriter, r *http.Request) {
+	sub, _ := h.authorizeSubmission(w, r)
+	if sub == nil {
+		return
+	}
+
+	err := h.commentService.DeleteComment(r.Context(), sub.ID, r.PathValue("comment_id"), middleware.GetUserID(r.Context()), middleware.IsAdmin(r.Context()))
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, map[string]string{"message": "Comment deleted successfully"})
+}
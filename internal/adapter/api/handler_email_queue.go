@@ -0,0 +1,51 @@
+package api
+
+import (
+	"net/http"
+
+	"headless_form/internal/adapter/api/response"
+	"headless_form/internal/adapter/middleware"
+	"headless_form/internal/core/domain"
+	"headless_form/internal/core/service"
+)
+
+// EmailQueueHandler exposes the outgoing email queue to admins
+type EmailQueueHandler struct {
+	queueService *service.EmailQueueService
+}
+
+// NewEmailQueueHandler creates a new email queue handler
+func NewEmailQueueHandler(queueService *service.EmailQueueService) *EmailQueueHandler {
+	return &EmailQueueHandler{queueService: queueService}
+}
+
+// RegisterRoutes registers email queue routes (admin only)
+func (h *EmailQueueHandler) RegisterRoutes(mux *http.ServeMux, authMiddleware func(http.Handler) http.Handler) {
+	mux.Handle("GET /api/v1/admin/email-queue", authMiddleware(http.HandlerFunc(h.HandleList)))
+}
+
+// HandleList: GET /api/v1/admin/email-queue?status=dead&page=1&limit=20
+// Returns queued, sent and dead emails newest first, without their messages, and
+// how many emails have each status (admin only).
+func (h *EmailQueueHandler) HandleList(w http.ResponseWriter, r *http.Request) {
+	if !middleware.IsAdmin(r.Context()) {
+		response.Error(w, http.StatusForbidden, "Admin access required", response.CodeForbidden)
+		return
+	}
+	page, limit := parsePage(r, 20, 100)
+	status := domain.EmailStatus(r.URL.Query().Get("status"))
+
+	emails, total, counts, err := h.queueService.List(r.Context(), status, page, limit)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	if emails == nil {
+		emails = []*domain.QueuedEmail{}
+	}
+
+	response.Paginated(w, r, "emails", emails, page, limit, total, map[string]interface{}{"counts": counts})
+}
@@ -1,55 +1,74 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"image/color"
+	"io"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
+	"headless_form/internal/adapter/api/request"
 	"headless_form/internal/adapter/api/response"
+	"headless_form/internal/adapter/email"
 	"headless_form/internal/adapter/middleware"
+	"headless_form/internal/adapter/qr"
+	"headless_form/internal/adapter/transform"
 	"headless_form/internal/core/domain"
+	"headless_form/internal/core/service"
 )
 
 // =============================================================================
 // Form CRUD Handlers
 // =============================================================================
 
-// HandleListForms: GET /api/v1/forms?page=1&limit=20
+// HandleListForms: GET /api/v1/forms?page=1&limit=20&sort=name&order=asc&status=active&access_mode=public&search=contact&starred=true
+// sort is one of created_at (default), updated_at, name, submission_count, last_submission_at; order defaults to desc.
+// Forms the current user starred are flagged "starred"; starred=true lists only those.
+// team_id=<id> lists only the forms shared with that team.
+// Archived forms are left out unless include_archived=true or status=archived.
 func (h *Router) HandleListForms(w http.ResponseWriter, r *http.Request) {
-	page := parseIntParam(r, "page", 1)
-	limit := parseIntParam(r, "limit", 20)
+	page, limit := parsePage(r, 20, 100)
 
-	if page < 1 {
-		page = 1
+	q := r.URL.Query()
+	query := domain.FormListQuery{
+		Status:     domain.FormStatus(q.Get("status")),
+		AccessMode: domain.AccessMode(q.Get("access_mode")),
+		Search:     q.Get("search"),
+		TeamID:     q.Get("team_id"),
+		Sort:       domain.FormSort(q.Get("sort")),
+		Ascending:  q.Get("order") == "asc",
+
+		Viewer:      middleware.GetUserID(r.Context()),
+		StarredOnly: q.Get("starred") == "true",
+
+		IncludeArchived: q.Get("include_archived") == "true",
 	}
-	if limit < 1 || limit > 100 {
-		limit = 20
+	if order := q.Get("order"); order != "" && order != "asc" && order != "desc" {
+		response.BadRequest(w, "order must be asc or desc", response.CodeValidationError)
+		return
 	}
 
-	var forms []*domain.Form
-	var total int
-	var err error
-
-	// Check user role - admin/super_admin see all forms, users see only their own
-	if middleware.IsAdmin(r.Context()) {
-		forms, total, err = h.formService.ListFormsPaginated(r.Context(), page, limit)
-	} else {
-		ownerID := middleware.GetUserID(r.Context())
-		forms, total, err = h.formService.ListFormsByOwnerPaginated(r.Context(), ownerID, page, limit)
+	// Check user role - admin/super_admin see all forms, users see their own and their teams'
+	if !middleware.IsAdmin(r.Context()) {
+		query.OwnerID = middleware.GetUserID(r.Context())
+		query.TeamIDs = middleware.GetTeamIDs(r.Context())
 	}
 
-	if response.HandleError(w, err) {
+	forms, total, err := h.formService.ListFormsPaginated(r.Context(), query, page, limit)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
 		return
 	}
 
-	response.Success(w, map[string]interface{}{
-		"forms": forms,
-		"pagination": map[string]interface{}{
-			"page":        page,
-			"limit":       limit,
-			"total":       total,
-			"total_pages": (total + limit - 1) / limit,
-		},
-	})
+	response.Paginated(w, r, "forms", forms, page, limit, total, nil)
 }
 
 // HandleGetForm: GET /api/v1/forms/{form_id}
@@ -65,10 +84,14 @@ func (h *Router) HandleGetForm(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Check if user can access this form
-	if !middleware.CanAccessForm(r.Context(), form.OwnerID) {
-		response.Error(w, http.StatusForbidden, "Access denied", "FORBIDDEN")
+	if !middleware.CanAccessForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "Access denied", response.CodeForbidden)
 		return
 	}
+	// Team viewers see the form, not its credentials
+	if !middleware.CanManageForm(r.Context(), form) {
+		form = form.WithoutSecrets()
+	}
 
 	response.Success(w, form)
 }
@@ -87,6 +110,34 @@ func (h *Router) HandleFormStats(w http.ResponseWriter, r *http.Request) {
 	response.Success(w, stats)
 }
 
+// HandleFieldStats: GET /api/v1/forms/{form_id}/stats/fields
+func (h *Router) HandleFieldStats(w http.ResponseWriter, r *http.Request) {
+	publicID := r.PathValue("form_id")
+
+	form, err := h.formService.GetForm(r.Context(), publicID)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	if !middleware.CanAccessForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "Access denied", response.CodeForbidden)
+		return
+	}
+
+	stats, err := h.statsService.GetFieldStats(r.Context(), publicID)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	response.Success(w, stats)
+}
+
 // HandleCreateForm: POST /api/v1/forms
 func (h *Router) HandleCreateForm(w http.ResponseWriter, r *http.Request) {
 	var req struct {
@@ -100,7 +151,11 @@ func (h *Router) HandleCreateForm(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.BadRequest(w, "Invalid JSON body", "INVALID_BODY")
+		response.BadRequest(w, "Invalid JSON body", response.CodeInvalidBody)
+		return
+	}
+
+	if !allowSecretReference(w, r, req.WebhookSecret, "") {
 		return
 	}
 
@@ -119,6 +174,17 @@ func (h *Router) HandleCreateForm(w http.ResponseWriter, r *http.Request) {
 	response.Created(w, form)
 }
 
+// allowSecretReference only lets admins point a webhook secret at a secret manager, since
+// its value is whatever the server's credentials can read there. A form keeping the
+// reference it already has is fine. Writes a 403 and returns false otherwise.
+func allowSecretReference(w http.ResponseWriter, r *http.Request, secret, current string) bool {
+	if !domain.SecretReference(secret) || secret == current || middleware.IsAdmin(r.Context()) {
+		return true
+	}
+	response.Error(w, http.StatusForbidden, "Only admins can reference secrets in a secret manager", response.CodeForbidden)
+	return false
+}
+
 // HandleUpdateForm: PUT /api/v1/forms/{form_id}
 func (h *Router) HandleUpdateForm(w http.ResponseWriter, r *http.Request) {
 	publicID := r.PathValue("form_id")
@@ -132,8 +198,8 @@ func (h *Router) HandleUpdateForm(w http.ResponseWriter, r *http.Request) {
 		response.HandleError(w, err)
 		return
 	}
-	if !middleware.CanAccessForm(r.Context(), form.OwnerID) {
-		response.Error(w, http.StatusForbidden, "You can only edit your own forms", "FORBIDDEN")
+	if !middleware.CanManageForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "You can only edit your own forms", response.CodeForbidden)
 		return
 	}
 
@@ -146,19 +212,27 @@ func (h *Router) HandleUpdateForm(w http.ResponseWriter, r *http.Request) {
 		WebhookSecret string   `json:"webhook_secret"`
 		AccessMode    string   `json:"access_mode"`
 		SubmissionKey string   `json:"submission_key"`
+		AttachPDF     *bool    `json:"attach_pdf"`
+		PublicStats   *bool    `json:"public_stats"`
+		Anonymous     *bool    `json:"anonymous"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.BadRequest(w, "Invalid JSON body", "INVALID_BODY")
+		response.BadRequest(w, "Invalid JSON body", response.CodeInvalidBody)
+		return
+	}
+
+	if !allowSecretReference(w, r, req.WebhookSecret, form.WebhookSecret) {
 		return
 	}
 
 	status := domain.FormStatusActive
-	if req.Status == "inactive" {
-		status = domain.FormStatusInactive
+	switch domain.FormStatus(req.Status) {
+	case domain.FormStatusInactive, domain.FormStatusArchived:
+		status = domain.FormStatus(req.Status)
 	}
 
-	updatedForm, err := h.formService.UpdateForm(r.Context(), publicID, req.Name, req.RedirectURL, req.NotifyEmails, status, req.WebhookURL, req.WebhookSecret, req.AccessMode, req.SubmissionKey)
+	updatedForm, err := h.formService.UpdateForm(r.Context(), publicID, req.Name, req.RedirectURL, req.NotifyEmails, status, req.WebhookURL, req.WebhookSecret, req.AccessMode, req.SubmissionKey, req.AttachPDF, req.PublicStats, req.Anonymous)
 	if err != nil {
 		if response.HandleDomainError(w, err) {
 			return
@@ -170,7 +244,85 @@ func (h *Router) HandleUpdateForm(w http.ResponseWriter, r *http.Request) {
 	response.Success(w, updatedForm)
 }
 
-// HandleDeleteForm: DELETE /api/v1/forms/{form_id}
+// HandleStarForm: PUT /api/v1/forms/{form_id}/star
+// Pins the form for the current user so it can be listed with ?starred=true.
+func (h *Router) HandleStarForm(w http.ResponseWriter, r *http.Request) {
+	h.setStarred(w, r, true)
+}
+
+// HandleUnstarForm: DELETE /api/v1/forms/{form_id}/star
+func (h *Router) HandleUnstarForm(w http.ResponseWriter, r *http.Request) {
+	h.setStarred(w, r, false)
+}
+
+func (h *Router) setStarred(w http.ResponseWriter, r *http.Request, starred bool) {
+	publicID := r.PathValue("form_id")
+	form, err := h.formService.GetForm(r.Context(), publicID)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	if !middleware.CanAccessForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "Access denied", response.CodeForbidden)
+		return
+	}
+
+	form, err = h.formService.SetStarred(r.Context(), publicID, middleware.GetUserID(r.Context()), starred)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	response.Success(w, form)
+}
+
+// HandleArchiveForm: PUT /api/v1/forms/{form_id}/archive
+// Closes the form to submissions and hides it from form lists, keeping its submissions.
+func (h *Router) HandleArchiveForm(w http.ResponseWriter, r *http.Request) {
+	h.setArchived(w, r, true)
+}
+
+// HandleUnarchiveForm: DELETE /api/v1/forms/{form_id}/archive
+// Makes an archived form active again
+func (h *Router) HandleUnarchiveForm(w http.ResponseWriter, r *http.Request) {
+	h.setArchived(w, r, false)
+}
+
+func (h *Router) setArchived(w http.ResponseWriter, r *http.Request, archived bool) {
+	publicID := r.PathValue("form_id")
+	form, err := h.formService.GetForm(r.Context(), publicID)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	if !middleware.CanManageForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "You can only edit your own forms", response.CodeForbidden)
+		return
+	}
+
+	form, err = h.formService.SetArchived(r.Context(), publicID, archived)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	response.Success(w, form)
+}
+
+// HandleDeleteForm: DELETE /api/v1/forms/{form_id}?confirm=<token>
+// Deletion takes two steps: without confirm, returns a short-lived confirmation token (202).
+// With a valid token, the form is archived and moved into a grace period during which
+// it can be restored; it's purged (with all submissions) once the grace period ends.
 func (h *Router) HandleDeleteForm(w http.ResponseWriter, r *http.Request) {
 	publicID := r.PathValue("form_id")
 
@@ -183,12 +335,260 @@ func (h *Router) HandleDeleteForm(w http.ResponseWriter, r *http.Request) {
 		response.HandleError(w, err)
 		return
 	}
-	if !middleware.CanAccessForm(r.Context(), form.OwnerID) {
-		response.Error(w, http.StatusForbidden, "You can only delete your own forms", "FORBIDDEN")
+	if !middleware.CanManageForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "You can only delete your own forms", response.CodeForbidden)
+		return
+	}
+
+	confirm := r.URL.Query().Get("confirm")
+	if confirm == "" {
+		token, expiresAt, err := h.formService.RequestDelete(r.Context(), publicID)
+		if response.HandleError(w, err) {
+			return
+		}
+		response.Accepted(w, map[string]interface{}{
+			"message":       "Confirm deletion by repeating this request with ?confirm=<confirm_token>",
+			"confirm_token": token,
+			"expires_at":    expiresAt,
+		})
+		return
+	}
+
+	deleted, err := h.formService.ConfirmDelete(r.Context(), publicID, confirm)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, map[string]interface{}{
+		"message":  "Form deleted. It can be restored until purge_at.",
+		"purge_at": deleted.PurgeAt,
+	})
+}
+
+// HandleListTrash: GET /api/v1/forms/trash
+// Lists deleted forms awaiting purge. Admins see all, users see their own.
+func (h *Router) HandleListTrash(w http.ResponseWriter, r *http.Request) {
+	ownerID := ""
+	if !middleware.IsAdmin(r.Context()) {
+		ownerID = middleware.GetUserID(r.Context())
+	}
+
+	forms, err := h.formService.ListTrash(r.Context(), ownerID)
+	if response.HandleError(w, err) {
+		return
+	}
+	if forms == nil {
+		forms = []*domain.Form{}
+	}
+
+	response.Success(w, map[string]interface{}{
+		"forms": forms,
+	})
+}
+
+// HandleRestoreForm: POST /api/v1/forms/{form_id}/restore
+// Restores a deleted form that is still within its grace period
+func (h *Router) HandleRestoreForm(w http.ResponseWriter, r *http.Request) {
+	publicID := r.PathValue("form_id")
+
+	form, err := h.formService.GetDeletedForm(r.Context(), publicID)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	if !middleware.CanManageForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "Access denied", response.CodeForbidden)
+		return
+	}
+
+	restored, err := h.formService.RestoreForm(r.Context(), publicID)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, restored)
+}
+
+// HandleSetWebhookTransform: PUT /api/v1/forms/{form_id}/webhook/transform
+// Stores a transform script that maps submissions to the webhook payload
+func (h *Router) HandleSetWebhookTransform(w http.ResponseWriter, r *http.Request) {
+	publicID := r.PathValue("form_id")
+
+	form, err := h.formService.GetForm(r.Context(), publicID)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	if !middleware.CanManageForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "You can only edit your own forms", response.CodeForbidden)
+		return
+	}
+
+	var req struct {
+		Script  string `json:"script"`
+		Enabled bool   `json:"enabled"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid JSON body", response.CodeInvalidBody)
+		return
+	}
+
+	if req.Script != "" {
+		if _, err := transform.Compile(req.Script); err != nil {
+			response.BadRequest(w, err.Error(), response.CodeInvalidTransform)
+			return
+		}
+	}
+
+	updatedForm, err := h.formService.SetWebhookTransform(r.Context(), publicID, req.Script, req.Enabled)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, updatedForm)
+}
+
+// HandleGetFormSpec: GET /api/v1/form-specs/{slug}
+// Returns the form's current configuration as a spec, e.g. to detect drift or bootstrap a config file.
+func (h *Router) HandleGetFormSpec(w http.ResponseWriter, r *http.Request) {
+	form, err := h.formService.GetFormBySlug(r.Context(), r.PathValue("slug"))
+	if err == nil && form.IsDeleted() {
+		err = domain.ErrFormNotFound
+	}
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	if !middleware.CanAccessForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "You can only view your own forms", response.CodeForbidden)
+		return
+	}
+	if !middleware.CanManageForm(r.Context(), form) {
+		form = form.WithoutSecrets()
+	}
+
+	response.Success(w, map[string]interface{}{
+		"slug":      form.Slug,
+		"public_id": form.PublicID,
+		"spec":      form.Spec(),
+	})
+}
+
+// HandleApplyFormSpec: PUT /api/v1/form-specs/{slug}
+// Creates the form with this slug from the spec in the body, or updates the existing
+// one to match it. Idempotent: re-applying a spec reports "unchanged".
+func (h *Router) HandleApplyFormSpec(w http.ResponseWriter, r *http.Request) {
+	slug := r.PathValue("slug")
+
+	existing, err := h.formService.GetFormBySlug(r.Context(), slug)
+	if err != nil && !errors.Is(err, domain.ErrFormNotFound) {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	if existing != nil && !middleware.CanManageForm(r.Context(), existing) {
+		response.Error(w, http.StatusForbidden, "You can only edit your own forms", response.CodeForbidden)
+		return
+	}
+
+	var spec domain.FormSpec
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields() // Catch typos in config files rather than silently dropping settings
+	if err := dec.Decode(&spec); err != nil {
+		response.BadRequest(w, "Invalid spec: "+err.Error(), response.CodeInvalidBody)
+		return
+	}
+	if spec.Webhook != nil {
+		current := ""
+		if existing != nil {
+			current = existing.WebhookSecret
+		}
+		if !allowSecretReference(w, r, spec.Webhook.Secret, current) {
+			return
+		}
+	}
+	if spec.Webhook != nil && spec.Webhook.Transform != "" {
+		if _, err := transform.Compile(spec.Webhook.Transform); err != nil {
+			response.BadRequest(w, err.Error(), response.CodeInvalidTransform)
+			return
+		}
+	}
+	if t := spec.EmailTemplate; t != nil {
+		if err := email.ValidateTemplate(email.Template{Subject: t.Subject, HTML: t.HTML, Text: t.Text}); err != nil {
+			response.BadRequest(w, err.Error(), response.CodeInvalidEmailTemplate)
+			return
+		}
+	}
+
+	form, result, err := h.formService.ApplyFormSpec(r.Context(), slug, middleware.GetUserID(r.Context()), spec)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+
+	data := map[string]interface{}{"result": result, "form": form}
+	if result == domain.ApplyCreated {
+		response.Created(w, data)
+		return
+	}
+	response.Success(w, data)
+}
+
+// HandleSetWebhookSigning: PUT /api/v1/forms/{form_id}/webhook/signing
+// Body: {"algorithm": "sha512", "timestamped": true}
+func (h *Router) HandleSetWebhookSigning(w http.ResponseWriter, r *http.Request) {
+	publicID := r.PathValue("form_id")
+
+	form, err := h.formService.GetForm(r.Context(), publicID)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	if !middleware.CanManageForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "You can only edit your own forms", response.CodeForbidden)
+		return
+	}
+
+	var req struct {
+		Algorithm   string `json:"algorithm"`
+		Timestamped bool   `json:"timestamped"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid JSON body", response.CodeInvalidBody)
 		return
 	}
 
-	if err := h.formService.DeleteForm(r.Context(), publicID); err != nil {
+	updatedForm, err := h.formService.SetWebhookSigning(r.Context(), publicID, req.Algorithm, req.Timestamped)
+	if err != nil {
 		if response.HandleDomainError(w, err) {
 			return
 		}
@@ -196,5 +596,1747 @@ func (h *Router) HandleDeleteForm(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	response.Success(w, map[string]string{"message": "Form deleted successfully"})
+	response.Success(w, updatedForm)
+}
+
+// HandleSetWebhookMeta: PUT /api/v1/forms/{form_id}/webhook/meta
+// Body: {"include_meta": true} adds request metadata and the spam verdict to webhook payloads
+func (h *Router) HandleSetWebhookMeta(w http.ResponseWriter, r *http.Request) {
+	publicID := r.PathValue("form_id")
+
+	form, err := h.formService.GetForm(r.Context(), publicID)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	if !middleware.CanManageForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "You can only edit your own forms", response.CodeForbidden)
+		return
+	}
+
+	var req struct {
+		IncludeMeta bool `json:"include_meta"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid JSON body", response.CodeInvalidBody)
+		return
+	}
+
+	updatedForm, err := h.formService.SetWebhookIncludeMeta(r.Context(), publicID, req.IncludeMeta)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, updatedForm)
+}
+
+// HandleSetWebhookRate: PUT /api/v1/forms/{form_id}/webhook/rate
+// Body: {"max_per_minute": 60, "batch_interval": 300, "max_batch_size": 100} spaces webhook
+// requests out to 60 a minute and sends new submissions together every 5 minutes
+func (h *Router) HandleSetWebhookRate(w http.ResponseWriter, r *http.Request) {
+	publicID := r.PathValue("form_id")
+
+	form, err := h.formService.GetForm(r.Context(), publicID)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	if !middleware.CanManageForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "You can only edit your own forms", response.CodeForbidden)
+		return
+	}
+
+	var req domain.WebhookRate
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid JSON body", response.CodeInvalidBody)
+		return
+	}
+
+	updatedForm, err := h.formService.SetWebhookRate(r.Context(), publicID, &req)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, updatedForm)
+}
+
+// HandleDeleteWebhookRate: DELETE /api/v1/forms/{form_id}/webhook/rate
+// Sends each webhook event right away again
+func (h *Router) HandleDeleteWebhookRate(w http.ResponseWriter, r *http.Request) {
+	publicID := r.PathValue("form_id")
+
+	form, err := h.formService.GetForm(r.Context(), publicID)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	if !middleware.CanManageForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "You can only edit your own forms", response.CodeForbidden)
+		return
+	}
+
+	updatedForm, err := h.formService.SetWebhookRate(r.Context(), publicID, nil)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, updatedForm)
+}
+
+// HandleSetSchedule: PUT /api/v1/forms/{form_id}/schedule
+// Body: {"opens_at": "2026-05-01T09:00:00Z", "closes_at": "2026-05-15T17:00:00Z"} takes
+// submissions only in between; either may be left out for no limit on that side
+func (h *Router) HandleSetSchedule(w http.ResponseWriter, r *http.Request) {
+	publicID := r.PathValue("form_id")
+
+	form, err := h.formService.GetForm(r.Context(), publicID)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	if !middleware.CanManageForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "You can only edit your own forms", response.CodeForbidden)
+		return
+	}
+
+	var req struct {
+		OpensAt  *time.Time `json:"opens_at"`
+		ClosesAt *time.Time `json:"closes_at"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid JSON body; times must be RFC 3339", response.CodeInvalidBody)
+		return
+	}
+
+	updatedForm, err := h.formService.SetSchedule(r.Context(), publicID, req.OpensAt, req.ClosesAt)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, updatedForm)
+}
+
+// HandleDeleteSchedule: DELETE /api/v1/forms/{form_id}/schedule
+// Opens the form to submissions regardless of time again
+func (h *Router) HandleDeleteSchedule(w http.ResponseWriter, r *http.Request) {
+	publicID := r.PathValue("form_id")
+
+	form, err := h.formService.GetForm(r.Context(), publicID)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	if !middleware.CanManageForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "You can only edit your own forms", response.CodeForbidden)
+		return
+	}
+
+	updatedForm, err := h.formService.SetSchedule(r.Context(), publicID, nil, nil)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, updatedForm)
+}
+
+// HandleRotatePublicID: POST /api/v1/forms/{form_id}/rotate-public-id
+// Body (optional): {"grace_hours": 72} keeps the old public ID accepting submissions for 72 hours
+func (h *Router) HandleRotatePublicID(w http.ResponseWriter, r *http.Request) {
+	publicID := r.PathValue("form_id")
+
+	form, err := h.formService.GetForm(r.Context(), publicID)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	if !middleware.CanManageForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "You can only edit your own forms", response.CodeForbidden)
+		return
+	}
+
+	var req struct {
+		GraceHours int `json:"grace_hours"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		response.BadRequest(w, "Invalid JSON body", response.CodeInvalidBody)
+		return
+	}
+
+	updatedForm, err := h.formService.RotatePublicID(r.Context(), publicID, time.Duration(req.GraceHours)*time.Hour)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, updatedForm)
+}
+
+// HandlePreviewWebhookTransform: POST /api/v1/forms/{form_id}/webhook/transform/preview
+// Runs a transform script (or the saved one) against sample data without sending anything
+func (h *Router) HandlePreviewWebhookTransform(w http.ResponseWriter, r *http.Request) {
+	publicID := r.PathValue("form_id")
+
+	form, err := h.formService.GetForm(r.Context(), publicID)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	if !middleware.CanManageForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "Access denied", response.CodeForbidden)
+		return
+	}
+
+	var req struct {
+		Script string                 `json:"script"`
+		Data   map[string]interface{} `json:"data"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid JSON body", response.CodeInvalidBody)
+		return
+	}
+	if req.Script == "" {
+		req.Script = form.WebhookTransform
+	}
+
+	script, err := transform.Compile(req.Script)
+	if err != nil {
+		response.BadRequest(w, err.Error(), response.CodeInvalidTransform)
+		return
+	}
+	payload, err := script.Run(r.Context(), transform.Input{
+		FormID:       form.PublicID,
+		FormName:     form.Name,
+		SubmissionID: "preview",
+		Timestamp:    time.Now(),
+		Data:         req.Data,
+	})
+	if err != nil {
+		response.BadRequest(w, err.Error(), response.CodeInvalidTransform)
+		return
+	}
+
+	response.Success(w, map[string]interface{}{"payload": payload})
+}
+
+// HandleSetConsent: PUT /api/v1/forms/{form_id}/consent
+// Sets the checkbox fields every submission must tick and the consent text version
+func (h *Router) HandleSetConsent(w http.ResponseWriter, r *http.Request) {
+	publicID := r.PathValue("form_id")
+
+	form, err := h.formService.GetForm(r.Context(), publicID)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	if !middleware.CanManageForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "You can only edit your own forms", response.CodeForbidden)
+		return
+	}
+
+	var req domain.ConsentConfig
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid JSON body", response.CodeInvalidBody)
+		return
+	}
+
+	updatedForm, err := h.formService.SetConsent(r.Context(), publicID, &req)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, updatedForm)
+}
+
+// HandleDeleteConsent: DELETE /api/v1/forms/{form_id}/consent
+// Stops requiring consent. Consent already recorded on submissions is kept.
+func (h *Router) HandleDeleteConsent(w http.ResponseWriter, r *http.Request) {
+	publicID := r.PathValue("form_id")
+
+	form, err := h.formService.GetForm(r.Context(), publicID)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	if !middleware.CanManageForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "You can only edit your own forms", response.CodeForbidden)
+		return
+	}
+
+	updatedForm, err := h.formService.SetConsent(r.Context(), publicID, nil)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, updatedForm)
+}
+
+// HandleSetUploads: PUT /api/v1/forms/{form_id}/uploads
+// Lets the form accept files, e.g. {"max_file_size": 5242880, "max_files": 3, "allowed_types": ["image/*", ".pdf"]}
+func (h *Router) HandleSetUploads(w http.ResponseWriter, r *http.Request) {
+	publicID := r.PathValue("form_id")
+
+	form, err := h.formService.GetForm(r.Context(), publicID)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	if !middleware.CanManageForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "You can only edit your own forms", response.CodeForbidden)
+		return
+	}
+
+	var req domain.UploadConfig
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid JSON body", response.CodeInvalidBody)
+		return
+	}
+
+	updatedForm, err := h.formService.SetUploads(r.Context(), publicID, &req)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, updatedForm)
+}
+
+// HandleDeleteUploads: DELETE /api/v1/forms/{form_id}/uploads
+// Stops accepting files. Files already uploaded stay with their submissions.
+func (h *Router) HandleDeleteUploads(w http.ResponseWriter, r *http.Request) {
+	publicID := r.PathValue("form_id")
+
+	form, err := h.formService.GetForm(r.Context(), publicID)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	if !middleware.CanManageForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "You can only edit your own forms", response.CodeForbidden)
+		return
+	}
+
+	updatedForm, err := h.formService.SetUploads(r.Context(), publicID, nil)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, updatedForm)
+}
+
+// HandleSetSubmitResponse: PUT /api/v1/forms/{form_id}/submit-response
+// Sets how successful submissions are answered, e.g. {"raw": true, "status": 200} to return
+// the bare submission with 200 OK instead of the enveloped 201 Created
+func (h *Router) HandleSetSubmitResponse(w http.ResponseWriter, r *http.Request) {
+	publicID := r.PathValue("form_id")
+
+	form, err := h.formService.GetForm(r.Context(), publicID)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	if !middleware.CanManageForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "You can only edit your own forms", response.CodeForbidden)
+		return
+	}
+
+	var req domain.SubmitResponseConfig
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid JSON body", response.CodeInvalidBody)
+		return
+	}
+
+	updatedForm, err := h.formService.SetSubmitResponse(r.Context(), publicID, &req)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, updatedForm)
+}
+
+// HandleDeleteSubmitResponse: DELETE /api/v1/forms/{form_id}/submit-response
+// Restores the default enveloped 201 Created response to submissions
+func (h *Router) HandleDeleteSubmitResponse(w http.ResponseWriter, r *http.Request) {
+	publicID := r.PathValue("form_id")
+
+	form, err := h.formService.GetForm(r.Context(), publicID)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	if !middleware.CanManageForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "You can only edit your own forms", response.CodeForbidden)
+		return
+	}
+
+	updatedForm, err := h.formService.SetSubmitResponse(r.Context(), publicID, nil)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, updatedForm)
+}
+
+// HandleSetErrorPage: PUT /api/v1/forms/{form_id}/error-page
+// Sets where rejected browser form posts go, e.g. {"url": "https://example.com/oops"} to
+// redirect them there with ?reason=content_rejected, or {"message": "..."} for the hosted page
+func (h *Router) HandleSetErrorPage(w http.ResponseWriter, r *http.Request) {
+	publicID := r.PathValue("form_id")
+
+	form, err := h.formService.GetForm(r.Context(), publicID)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	if !middleware.CanManageForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "You can only edit your own forms", response.CodeForbidden)
+		return
+	}
+
+	var req domain.ErrorPageConfig
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid JSON body", response.CodeInvalidBody)
+		return
+	}
+
+	updatedForm, err := h.formService.SetErrorPage(r.Context(), publicID, &req)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, updatedForm)
+}
+
+// HandleDeleteErrorPage: DELETE /api/v1/forms/{form_id}/error-page
+// Restores the hosted error page with each reason's own message
+func (h *Router) HandleDeleteErrorPage(w http.ResponseWriter, r *http.Request) {
+	publicID := r.PathValue("form_id")
+
+	form, err := h.formService.GetForm(r.Context(), publicID)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	if !middleware.CanManageForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "You can only edit your own forms", response.CodeForbidden)
+		return
+	}
+
+	updatedForm, err := h.formService.SetErrorPage(r.Context(), publicID, nil)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, updatedForm)
+}
+
+// HandleSetSpam: PUT /api/v1/forms/{form_id}/spam
+// Sets the form's spam check, e.g. {"threshold": 70, "honeypot_fields": ["nickname"]} or
+// {"disabled": true}. Unset limits take the defaults.
+func (h *Router) HandleSetSpam(w http.ResponseWriter, r *http.Request) {
+	publicID := r.PathValue("form_id")
+
+	form, err := h.formService.GetForm(r.Context(), publicID)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	if !middleware.CanManageForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "You can only edit your own forms", response.CodeForbidden)
+		return
+	}
+
+	var req domain.SpamConfig
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid JSON body", response.CodeInvalidBody)
+		return
+	}
+
+	updatedForm, err := h.formService.SetSpam(r.Context(), publicID, &req)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, updatedForm)
+}
+
+// HandleDeleteSpam: DELETE /api/v1/forms/{form_id}/spam
+// Restores the default spam check
+func (h *Router) HandleDeleteSpam(w http.ResponseWriter, r *http.Request) {
+	publicID := r.PathValue("form_id")
+
+	form, err := h.formService.GetForm(r.Context(), publicID)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	if !middleware.CanManageForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "You can only edit your own forms", response.CodeForbidden)
+		return
+	}
+
+	updatedForm, err := h.formService.SetSpam(r.Context(), publicID, nil)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, updatedForm)
+}
+
+// HandleRescoreSpam: POST /api/v1/forms/{form_id}/spam/rescore?days=30
+// Starts a background task scoring the form's submissions of the last days (30 by
+// default) again with its current spam settings, moving them between the inbox and the
+// quarantine. The task's result counts how many changed state.
+func (h *Router) HandleRescoreSpam(w http.ResponseWriter, r *http.Request) {
+	if h.tasks == nil {
+		response.NotFound(w, "Re-scoring is not available")
+		return
+	}
+
+	form, err := h.formService.GetForm(r.Context(), r.PathValue("form_id"))
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	if !middleware.CanManageForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "You can only edit your own forms", response.CodeForbidden)
+		return
+	}
+
+	days := domain.DefaultRescoreDays
+	if v := r.URL.Query().Get("days"); v != "" {
+		if days, err = strconv.Atoi(v); err != nil || days < 1 || days > domain.MaxRescoreDays {
+			response.HandleDomainError(w, domain.ErrInvalidRescore)
+			return
+		}
+	}
+	if form.Spam != nil && form.Spam.Disabled {
+		response.HandleDomainError(w, domain.ErrSpamCheckDisabled)
+		return
+	}
+
+	// The site-wide settings are read once for the whole run
+	config := h.spamDetector.Tune(r.Context(), spamConfig(form.Spam))
+	score := func(ctx context.Context, form *domain.Form, data map[string]interface{}, previous *domain.SpamMeta) domain.SpamMeta {
+		result := h.spamDetector.Rescore(ctx, config, form.Filters, previous, data)
+		return domain.SpamMeta{Score: result.Score, IsSpam: result.IsSpam, Flags: result.Flags, Threshold: result.Threshold}
+	}
+	since := time.Now().UTC().AddDate(0, 0, -days)
+	userID := middleware.GetUserID(r.Context())
+
+	task, err := h.tasks.Start(domain.TaskTypeSpamRescore, userID, func(ctx context.Context, p *service.TaskProgress) (interface{}, error) {
+		return h.submissionService.RescoreSpam(ctx, p, form, since, userID, score)
+	})
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	response.Accepted(w, task)
+}
+
+// HandleSetCaptcha: PUT /api/v1/forms/{form_id}/captcha
+// Makes submissions pass a captcha, e.g. {"provider": "turnstile", "secret": "0x4AAA..."}.
+// The widget's token field is verified with the provider and not stored.
+func (h *Router) HandleSetCaptcha(w http.ResponseWriter, r *http.Request) {
+	publicID := r.PathValue("form_id")
+
+	form, err := h.formService.GetForm(r.Context(), publicID)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	if !middleware.CanManageForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "You can only edit your own forms", response.CodeForbidden)
+		return
+	}
+
+	var req domain.CaptchaConfig
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid JSON body", response.CodeInvalidBody)
+		return
+	}
+
+	updatedForm, err := h.formService.SetCaptcha(r.Context(), publicID, &req)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, updatedForm)
+}
+
+// HandleDeleteCaptcha: DELETE /api/v1/forms/{form_id}/captcha
+// Stops requiring a captcha
+func (h *Router) HandleDeleteCaptcha(w http.ResponseWriter, r *http.Request) {
+	publicID := r.PathValue("form_id")
+
+	form, err := h.formService.GetForm(r.Context(), publicID)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	if !middleware.CanManageForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "You can only edit your own forms", response.CodeForbidden)
+		return
+	}
+
+	updatedForm, err := h.formService.SetCaptcha(r.Context(), publicID, nil)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, updatedForm)
+}
+
+// HandleListFilters: GET /api/v1/forms/{form_id}/filters
+// Lists the form's content filters. The global ones are managed in the site settings.
+func (h *Router) HandleListFilters(w http.ResponseWriter, r *http.Request) {
+	publicID := r.PathValue("form_id")
+
+	form, err := h.formService.GetForm(r.Context(), publicID)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	if !middleware.CanAccessForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "You can only view your own forms", response.CodeForbidden)
+		return
+	}
+
+	filters := form.Filters
+	if filters == nil {
+		filters = []domain.FilterRule{}
+	}
+	response.Success(w, map[string]interface{}{"filters": filters})
+}
+
+// HandleSetFilters: PUT /api/v1/forms/{form_id}/filters
+// Replaces the form's content filters, e.g.
+// {"filters": [{"pattern": "casino", "action": "reject"}, {"pattern": "(?i)crypto\\s+offer", "regex": true}]}
+func (h *Router) HandleSetFilters(w http.ResponseWriter, r *http.Request) {
+	publicID := r.PathValue("form_id")
+
+	form, err := h.formService.GetForm(r.Context(), publicID)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	if !middleware.CanManageForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "You can only edit your own forms", response.CodeForbidden)
+		return
+	}
+
+	var req struct {
+		Filters []domain.FilterRule `json:"filters"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid JSON body", response.CodeInvalidBody)
+		return
+	}
+
+	updatedForm, err := h.formService.SetFilters(r.Context(), publicID, req.Filters)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, updatedForm)
+}
+
+// HandleAddFilter: POST /api/v1/forms/{form_id}/filters
+// Adds one content filter and returns it with its ID
+func (h *Router) HandleAddFilter(w http.ResponseWriter, r *http.Request) {
+	publicID := r.PathValue("form_id")
+
+	form, err := h.formService.GetForm(r.Context(), publicID)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	if !middleware.CanManageForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "You can only edit your own forms", response.CodeForbidden)
+		return
+	}
+
+	var req domain.FilterRule
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid JSON body", response.CodeInvalidBody)
+		return
+	}
+
+	rule, err := h.formService.AddFilter(r.Context(), publicID, req)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Created(w, rule)
+}
+
+// HandleDeleteFilter: DELETE /api/v1/forms/{form_id}/filters/{filter_id}
+func (h *Router) HandleDeleteFilter(w http.ResponseWriter, r *http.Request) {
+	publicID := r.PathValue("form_id")
+
+	form, err := h.formService.GetForm(r.Context(), publicID)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	if !middleware.CanManageForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "You can only edit your own forms", response.CodeForbidden)
+		return
+	}
+
+	updatedForm, err := h.formService.DeleteFilter(r.Context(), publicID, r.PathValue("filter_id"))
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, updatedForm)
+}
+
+// HandleSetEmailTemplate: PUT /api/v1/forms/{form_id}/email-template
+// Replaces parts of the notification email with Go templates over the submission, e.g.
+// {"subject": "New lead: {{.Fields.company}}", "text": "..."}
+func (h *Router) HandleSetEmailTemplate(w http.ResponseWriter, r *http.Request) {
+	publicID := r.PathValue("form_id")
+
+	form, err := h.formService.GetForm(r.Context(), publicID)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	if !middleware.CanManageForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "You can only edit your own forms", response.CodeForbidden)
+		return
+	}
+
+	var req domain.EmailTemplate
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid JSON body", response.CodeInvalidBody)
+		return
+	}
+	if err := email.ValidateTemplate(email.Template{Subject: req.Subject, HTML: req.HTML, Text: req.Text}); err != nil {
+		response.BadRequest(w, err.Error(), response.CodeInvalidEmailTemplate)
+		return
+	}
+
+	updatedForm, err := h.formService.SetEmailTemplate(r.Context(), publicID, &req)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, updatedForm)
+}
+
+// HandleDeleteEmailTemplate: DELETE /api/v1/forms/{form_id}/email-template
+// Goes back to the built-in notification email
+func (h *Router) HandleDeleteEmailTemplate(w http.ResponseWriter, r *http.Request) {
+	publicID := r.PathValue("form_id")
+
+	form, err := h.formService.GetForm(r.Context(), publicID)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	if !middleware.CanManageForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "You can only edit your own forms", response.CodeForbidden)
+		return
+	}
+
+	updatedForm, err := h.formService.SetEmailTemplate(r.Context(), publicID, nil)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, updatedForm)
+}
+
+// HandleSetAutoReply: PUT /api/v1/forms/{form_id}/auto-reply
+// Emails submitters a confirmation at the address in one of their fields, e.g.
+// {"field": "email", "subject": "Thanks, {{.Fields.name}}", "text": "..."}
+func (h *Router) HandleSetAutoReply(w http.ResponseWriter, r *http.Request) {
+	publicID := r.PathValue("form_id")
+
+	form, err := h.formService.GetForm(r.Context(), publicID)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	if !middleware.CanManageForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "You can only edit your own forms", response.CodeForbidden)
+		return
+	}
+
+	var req domain.AutoReplyConfig
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid JSON body", response.CodeInvalidBody)
+		return
+	}
+	if err := email.ValidateTemplate(email.Template{Subject: req.Subject, HTML: req.HTML, Text: req.Text}); err != nil {
+		response.BadRequest(w, err.Error(), response.CodeInvalidEmailTemplate)
+		return
+	}
+
+	updatedForm, err := h.formService.SetAutoReply(r.Context(), publicID, &req)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, updatedForm)
+}
+
+// HandleDeleteAutoReply: DELETE /api/v1/forms/{form_id}/auto-reply
+// Stops emailing submitters
+func (h *Router) HandleDeleteAutoReply(w http.ResponseWriter, r *http.Request) {
+	publicID := r.PathValue("form_id")
+
+	form, err := h.formService.GetForm(r.Context(), publicID)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	if !middleware.CanManageForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "You can only edit your own forms", response.CodeForbidden)
+		return
+	}
+
+	updatedForm, err := h.formService.SetAutoReply(r.Context(), publicID, nil)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, updatedForm)
+}
+
+// HandleSetNotifyRules: PUT /api/v1/forms/{form_id}/notify-rules
+// Replaces the form's notification rules, e.g.
+// {"rules": [{"recipients": ["sales@example.com"], "condition": "topic == \"sales\"", "template": "full"}]}
+func (h *Router) HandleSetNotifyRules(w http.ResponseWriter, r *http.Request) {
+	publicID := r.PathValue("form_id")
+
+	form, err := h.formService.GetForm(r.Context(), publicID)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	if !middleware.CanManageForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "You can only edit your own forms", response.CodeForbidden)
+		return
+	}
+
+	var req struct {
+		Rules []domain.NotifyRule `json:"rules"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid JSON body", response.CodeInvalidBody)
+		return
+	}
+
+	updatedForm, err := h.formService.SetNotifyRules(r.Context(), publicID, req.Rules)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, updatedForm)
+}
+
+// HandleDeleteNotifyRules: DELETE /api/v1/forms/{form_id}/notify-rules
+// Removes the rules, so the notify list gets every submission again
+func (h *Router) HandleDeleteNotifyRules(w http.ResponseWriter, r *http.Request) {
+	publicID := r.PathValue("form_id")
+
+	form, err := h.formService.GetForm(r.Context(), publicID)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	if !middleware.CanManageForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "You can only edit your own forms", response.CodeForbidden)
+		return
+	}
+
+	updatedForm, err := h.formService.SetNotifyRules(r.Context(), publicID, nil)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, updatedForm)
+}
+
+// HandleSetChannels: PUT /api/v1/forms/{form_id}/channels
+// Replaces the chat channels new submissions are posted to, e.g.
+// {"channels": [{"type": "slack", "url": "https://hooks.slack.com/services/…", "fields": 5}]}
+func (h *Router) HandleSetChannels(w http.ResponseWriter, r *http.Request) {
+	publicID := r.PathValue("form_id")
+
+	form, err := h.formService.GetForm(r.Context(), publicID)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	if !middleware.CanManageForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "You can only edit your own forms", response.CodeForbidden)
+		return
+	}
+
+	var req struct {
+		Channels []domain.NotifyChannel `json:"channels"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid JSON body", response.CodeInvalidBody)
+		return
+	}
+
+	updatedForm, err := h.formService.SetChannels(r.Context(), publicID, req.Channels)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, updatedForm)
+}
+
+// HandleDeleteChannels: DELETE /api/v1/forms/{form_id}/channels
+// Stops posting submissions to chat channels
+func (h *Router) HandleDeleteChannels(w http.ResponseWriter, r *http.Request) {
+	publicID := r.PathValue("form_id")
+
+	form, err := h.formService.GetForm(r.Context(), publicID)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	if !middleware.CanManageForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "You can only edit your own forms", response.CodeForbidden)
+		return
+	}
+
+	updatedForm, err := h.formService.SetChannels(r.Context(), publicID, nil)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, updatedForm)
+}
+
+// HandleSetNormalize: PUT /api/v1/forms/{form_id}/normalize
+// Cleans up submitted text before it's stored: NFC, control characters and surrounding
+// whitespace, e.g. {"lowercase_emails": true} to lowercase email fields too.
+func (h *Router) HandleSetNormalize(w http.ResponseWriter, r *http.Request) {
+	publicID := r.PathValue("form_id")
+
+	form, err := h.formService.GetForm(r.Context(), publicID)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	if !middleware.CanManageForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "You can only edit your own forms", response.CodeForbidden)
+		return
+	}
+
+	var req domain.NormalizeConfig
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid JSON body", response.CodeInvalidBody)
+		return
+	}
+
+	updatedForm, err := h.formService.SetNormalize(r.Context(), publicID, &req)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, updatedForm)
+}
+
+// HandleDeleteNormalize: DELETE /api/v1/forms/{form_id}/normalize
+// Stores submitted text as sent again
+func (h *Router) HandleDeleteNormalize(w http.ResponseWriter, r *http.Request) {
+	publicID := r.PathValue("form_id")
+
+	form, err := h.formService.GetForm(r.Context(), publicID)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	if !middleware.CanManageForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "You can only edit your own forms", response.CodeForbidden)
+		return
+	}
+
+	updatedForm, err := h.formService.SetNormalize(r.Context(), publicID, nil)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, updatedForm)
+}
+
+// HandleSetEmbed: PUT /api/v1/forms/{form_id}/embed
+// Sets the origins allowed to embed the form's public responses in an iframe,
+// e.g. {"frame_ancestors": ["https://example.com"]}. An empty list denies framing.
+func (h *Router) HandleSetEmbed(w http.ResponseWriter, r *http.Request) {
+	publicID := r.PathValue("form_id")
+
+	form, err := h.formService.GetForm(r.Context(), publicID)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	if !middleware.CanManageForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "You can only edit your own forms", response.CodeForbidden)
+		return
+	}
+
+	var req struct {
+		FrameAncestors []string `json:"frame_ancestors"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid JSON body", response.CodeInvalidBody)
+		return
+	}
+
+	updatedForm, err := h.formService.SetFrameAncestors(r.Context(), publicID, req.FrameAncestors)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, updatedForm)
+}
+
+// HandleSetFields: PUT /api/v1/forms/{form_id}/fields
+// Replaces the form's field schema, e.g. {"fields": [{"name": "email", "label": "Email",
+// "type": "email", "required": true, "translations": {"de": {"label": "E-Mail"}}}]}.
+// An empty list removes it.
+func (h *Router) HandleSetFields(w http.ResponseWriter, r *http.Request) {
+	publicID := r.PathValue("form_id")
+
+	form, err := h.formService.GetForm(r.Context(), publicID)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	if !middleware.CanManageForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "You can only edit your own forms", response.CodeForbidden)
+		return
+	}
+
+	var req struct {
+		Fields []domain.FormField `json:"fields"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid JSON body", response.CodeInvalidBody)
+		return
+	}
+
+	updatedForm, err := h.formService.SetFields(r.Context(), publicID, req.Fields)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, updatedForm)
+}
+
+// HandleSetTimezone: PUT /api/v1/forms/{form_id}/timezone
+// Sets the IANA time zone the form's stats and exports count days in, e.g.
+// {"timezone": "Europe/Berlin"}. An empty timezone falls back to the owner's.
+func (h *Router) HandleSetTimezone(w http.ResponseWriter, r *http.Request) {
+	publicID := r.PathValue("form_id")
+
+	form, err := h.formService.GetForm(r.Context(), publicID)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	if !middleware.CanManageForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "You can only edit your own forms", response.CodeForbidden)
+		return
+	}
+
+	var req struct {
+		Timezone string `json:"timezone"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid JSON body", response.CodeInvalidBody)
+		return
+	}
+
+	updatedForm, err := h.formService.SetTimezone(r.Context(), publicID, req.Timezone)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, updatedForm)
+}
+
+// HandleSetRetention: PUT /api/v1/forms/{form_id}/retention
+// Sets how long the form keeps submissions, e.g. {"retention_days": 365, "retention_action": "anonymize"}.
+// Older ones are deleted (the default action) or anonymized hourly; 0 days keeps them forever.
+func (h *Router) HandleSetRetention(w http.ResponseWriter, r *http.Request) {
+	publicID := r.PathValue("form_id")
+
+	form, err := h.formService.GetForm(r.Context(), publicID)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	if !middleware.CanManageForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "You can only edit your own forms", response.CodeForbidden)
+		return
+	}
+
+	var req struct {
+		RetentionDays   int    `json:"retention_days"`
+		RetentionAction string `json:"retention_action"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid JSON body", response.CodeInvalidBody)
+		return
+	}
+
+	updatedForm, err := h.formService.SetRetention(r.Context(), publicID, req.RetentionDays, req.RetentionAction)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, updatedForm)
+}
+
+// HandleSetEmailFieldLimit: PUT /api/v1/forms/{form_id}/email-field-limit
+// Sets how many characters of each field value notification emails show, e.g.
+// {"email_field_limit": 500}. Longer values are cut and the email links to the full
+// submission; 0 takes the default of 1000.
+func (h *Router) HandleSetEmailFieldLimit(w http.ResponseWriter, r *http.Request) {
+	publicID := r.PathValue("form_id")
+
+	form, err := h.formService.GetForm(r.Context(), publicID)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	if !middleware.CanManageForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "You can only edit your own forms", response.CodeForbidden)
+		return
+	}
+
+	var req struct {
+		EmailFieldLimit int `json:"email_field_limit"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid JSON body", response.CodeInvalidBody)
+		return
+	}
+
+	updatedForm, err := h.formService.SetEmailFieldLimit(r.Context(), publicID, req.EmailFieldLimit)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, updatedForm)
+}
+
+// HandleSetLinkField: PUT /api/v1/forms/{form_id}/link-field
+// Sets the field holding the ID of the submission each new one follows up on, e.g.
+// {"link_field": "application_id"}. New submissions naming a submission of a form with the
+// same owner or team are linked to it; "" stops linking.
+func (h *Router) HandleSetLinkField(w http.ResponseWriter, r *http.Request) {
+	publicID := r.PathValue("form_id")
+
+	form, err := h.formService.GetForm(r.Context(), publicID)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	if !middleware.CanManageForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "You can only edit your own forms", response.CodeForbidden)
+		return
+	}
+
+	var req struct {
+		LinkField string `json:"link_field"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid JSON body", response.CodeInvalidBody)
+		return
+	}
+
+	updatedForm, err := h.formService.SetLinkField(r.Context(), publicID, req.LinkField)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, updatedForm)
+}
+
+// HandleSetHiddenFields: PUT /api/v1/forms/{form_id}/hidden-fields
+// Sets fields merged into every submission server-side, e.g.
+// {"hidden_fields": {"campaign": "spring2025"}}. They replace any value the client sends
+// for them; an empty object removes them.
+func (h *Router) HandleSetHiddenFields(w http.ResponseWriter, r *http.Request) {
+	publicID := r.PathValue("form_id")
+
+	form, err := h.formService.GetForm(r.Context(), publicID)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	if !middleware.CanManageForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "You can only edit your own forms", response.CodeForbidden)
+		return
+	}
+
+	var req struct {
+		HiddenFields map[string]string `json:"hidden_fields"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid JSON body", response.CodeInvalidBody)
+		return
+	}
+
+	updatedForm, err := h.formService.SetHiddenFields(r.Context(), publicID, req.HiddenFields)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, updatedForm)
+}
+
+// HandleTestNotification: POST /api/v1/forms/{form_id}/test-notification
+// Sends the form's email and webhook notifications for a synthetic submission marked as a test.
+// The optional body {"data": {...}} replaces the sample fields. Nothing is stored.
+func (h *Router) HandleTestNotification(w http.ResponseWriter, r *http.Request) {
+	publicID := r.PathValue("form_id")
+
+	form, err := h.formService.GetForm(r.Context(), publicID)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	if !middleware.CanManageForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "Access denied", response.CodeForbidden)
+		return
+	}
+
+	var req struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		response.BadRequest(w, "Invalid JSON body", response.CodeInvalidBody)
+		return
+	}
+
+	submission, err := h.submissionService.SendTestNotification(r.Context(), publicID, req.Data)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+
+	// Who was emailed, by template, after the form's notify rules
+	var data map[string]interface{}
+	_ = json.Unmarshal(submission.Data, &data)
+	response.Accepted(w, map[string]interface{}{
+		"submission":    submission,
+		"notify_emails": form.NotifyEmails,
+		"recipients":    form.NotifyRecipients(data),
+		"webhook":       form.WebhookURL != "",
+		"channels":      len(form.Channels),
+	})
+}
+
+// HandleFormQR: GET /api/v1/forms/{form_id}/qr?format=png&size=256&url=...
+// Returns a QR code linking to the hosted form page ({BASE_URL}/f/{form_id}) or to url if given.
+// Options: format=png|svg, size (64-2048px), ec=L|M|Q|H, margin (0-16 modules), fg/bg hex colors.
+func (h *Router) HandleFormQR(w http.ResponseWriter, r *http.Request) {
+	publicID := r.PathValue("form_id")
+
+	form, err := h.formService.GetForm(r.Context(), publicID)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	if !middleware.CanAccessForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "Access denied", response.CodeForbidden)
+		return
+	}
+
+	q := r.URL.Query()
+	target := q.Get("url")
+	if target == "" {
+		target = strings.TrimRight(h.baseURL, "/") + "/f/" + form.PublicID
+	} else if u, err := url.Parse(target); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		response.BadRequest(w, "url must be an absolute http(s) URL", response.CodeValidationError)
+		return
+	}
+
+	opts := qr.DefaultRenderOptions()
+	opts.Size = parseIntParam(r, "size", opts.Size)
+	opts.QuietZone = parseIntParam(r, "margin", opts.QuietZone)
+	if opts.Size < 64 || opts.Size > 2048 {
+		response.BadRequest(w, "size must be between 64 and 2048", response.CodeValidationError)
+		return
+	}
+	if opts.QuietZone < 0 || opts.QuietZone > 16 {
+		response.BadRequest(w, "margin must be between 0 and 16", response.CodeValidationError)
+		return
+	}
+	for param, dst := range map[string]*color.RGBA{"fg": &opts.Foreground, "bg": &opts.Background} {
+		if v := q.Get(param); v != "" {
+			c, err := qr.ParseColor(v)
+			if err != nil {
+				response.BadRequest(w, param+" must be a hex color like #1a2b3c", response.CodeValidationError)
+				return
+			}
+			*dst = c
+		}
+	}
+
+	code, err := qr.Encode(target, qr.ParseLevel(q.Get("ec")))
+	if err != nil {
+		response.BadRequest(w, "url is too long to encode as a QR code", response.CodeValidationError)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "private, max-age=3600")
+	if q.Get("format") == "svg" {
+		w.Header().Set("Content-Type", "image/svg+xml")
+		_, _ = w.Write(code.SVG(opts))
+		return
+	}
+
+	img, err := code.PNG(opts)
+	if response.HandleError(w, err) {
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	_, _ = w.Write(img)
+}
+
+// =============================================================================
+// Public Stats (opt-in per form)
+// =============================================================================
+
+// publicStatsCacheControl lets browsers and CDNs cache public stats for five minutes
+const publicStatsCacheControl = "public, max-age=300"
+
+// getPublicStatsForm loads a form that has opted into public stats.
+// Forms that haven't opted in are reported as not found so their existence isn't leaked.
+func (h *Router) getPublicStatsForm(w http.ResponseWriter, r *http.Request) (*domain.Form, bool) {
+	form, err := h.formService.GetForm(r.Context(), r.PathValue("form_id"))
+	if err == nil && !form.PublicStats {
+		err = domain.ErrFormNotFound
+	}
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return nil, false
+		}
+		response.HandleError(w, err)
+		return nil, false
+	}
+	middleware.AllowFraming(w, form.FrameAncestors)
+	return form, true
+}
+
+// HandlePublicStats: GET /api/v1/forms/{form_id}/public-stats
+// Returns the response count for forms with public_stats enabled
+func (h *Router) HandlePublicStats(w http.ResponseWriter, r *http.Request) {
+	form, ok := h.getPublicStatsForm(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Cache-Control", publicStatsCacheControl)
+	response.Success(w, map[string]interface{}{
+		"form_id":   form.PublicID,
+		"responses": form.SubmissionCount,
+		"label":     responsesLabel(form.SubmissionCount),
+	})
+}
+
+// HandlePublicStatsBadge: GET /api/v1/forms/{form_id}/public-stats/badge.svg
+func (h *Router) HandlePublicStatsBadge(w http.ResponseWriter, r *http.Request) {
+	form, ok := h.getPublicStatsForm(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/svg+xml")
+	w.Header().Set("Cache-Control", publicStatsCacheControl)
+	_, _ = w.Write([]byte(renderBadge("responses", formatCount(form.SubmissionCount), "#007ec6")))
+}
+
+// localizedField is a field's text in one language, as hosted pages render it
+type localizedField struct {
+	Name string `json:"name"`
+	domain.FieldText
+	domain.FieldRules
+}
+
+// HandlePublicFields: GET /api/v1/forms/{form_id}/fields
+// Returns the form's field labels, placeholders and help text in the language asked
+// for by ?lang= or Accept-Language, falling back to the default text, along with
+// each field's validation rules for checking input before it's sent.
+func (h *Router) HandlePublicFields(w http.ResponseWriter, r *http.Request) {
+	form, err := h.formService.GetForm(r.Context(), r.PathValue("form_id"))
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	if !middleware.SetFormCORS(w, r, form) {
+		response.HandleDomainError(w, domain.ErrOriginNotAllowed)
+		return
+	}
+
+	locale := form.MatchLocale(request.PreferredLanguages(r))
+	fields := make([]localizedField, len(form.Fields))
+	for i, f := range form.Fields {
+		fields[i] = localizedField{Name: f.Name, FieldText: f.Text(locale), FieldRules: f.FieldRules}
+	}
+
+	w.Header().Set("Vary", "Accept-Language, Origin")
+	if locale != "" {
+		w.Header().Set("Content-Language", locale)
+	}
+	response.Success(w, map[string]interface{}{
+		"form_id": form.PublicID,
+		"locale":  locale,
+		"locales": form.Locales(),
+		"fields":  fields,
+	})
+}
+
+// responsesLabel formats a count as "1 response" / "1,234 responses"
+func responsesLabel(n int) string {
+	if n == 1 {
+		return "1 response"
+	}
+	return formatCount(n) + " responses"
+}
+
+// formatCount adds thousands separators: 1234567 -> "1,234,567"
+func formatCount(n int) string {
+	if n < 0 {
+		return "-" + formatCount(-n)
+	}
+	s := strconv.Itoa(n)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
+
+// HandleFormAuditLog: GET /api/v1/forms/{form_id}/audit
+// Lists recent destructive actions on the form (e.g. bulk submission deletes), newest first.
+func (h *Router) HandleFormAuditLog(w http.ResponseWriter, r *http.Request) {
+	publicID := r.PathValue("form_id")
+
+	form, err := h.formService.GetForm(r.Context(), publicID)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	if !middleware.CanAccessForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "Access denied", response.CodeForbidden)
+		return
+	}
+
+	limit := parseIntParam(r, "limit", 50)
+	if limit < 1 || limit > 200 {
+		limit = 50
+	}
+
+	entries, err := h.submissionService.ListAuditLog(r.Context(), publicID, limit)
+	if response.HandleError(w, err) {
+		return
+	}
+	if entries == nil {
+		entries = []*domain.AuditEntry{}
+	}
+	response.Success(w, map[string]interface{}{"entries": entries})
 }
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"headless_form/internal/adapter/api/response"
+	"headless_form/internal/adapter/middleware"
+	"headless_form/internal/adapter/monitor"
+	"headless_form/internal/core/service"
+)
+
+// MonitorHandler exposes heartbeat monitor results for form endpoints
+type MonitorHandler struct {
+	monitor     *monitor.Service
+	formService *service.FormService
+}
+
+// NewMonitorHandler creates a new monitor handler
+func NewMonitorHandler(monitorService *monitor.Service, formService *service.FormService) *MonitorHandler {
+	return &MonitorHandler{monitor: monitorService, formService: formService}
+}
+
+// RegisterPublicRoutes registers status routes. These are public so sites embedding a form
+// can show whether the endpoint is healthy.
+func (h *MonitorHandler) RegisterPublicRoutes(mux *http.ServeMux) {
+	hosted := middleware.WithHeaderPolicy(middleware.HostedFormPolicy)
+	mux.Handle("GET /api/v1/forms/{form_id}/health", hosted(http.HandlerFunc(h.HandleFormHealth)))
+	mux.Handle("GET /api/v1/forms/{form_id}/health/badge.svg", hosted(http.HandlerFunc(h.HandleFormHealthBadge)))
+}
+
+// HandleFormHealth: GET /api/v1/forms/{form_id}/health
+func (h *MonitorHandler) HandleFormHealth(w http.ResponseWriter, r *http.Request) {
+	publicID := r.PathValue("form_id")
+	if _, err := h.formService.GetForm(r.Context(), publicID); err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, h.monitor.Status(publicID))
+}
+
+// HandleFormHealthBadge: GET /api/v1/forms/{form_id}/health/badge.svg
+func (h *MonitorHandler) HandleFormHealthBadge(w http.ResponseWriter, r *http.Request) {
+	publicID := r.PathValue("form_id")
+	if _, err := h.formService.GetForm(r.Context(), publicID); err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+
+	status := h.monitor.Status(publicID)
+	label := status.State
+	color := "#9f9f9f" // unknown
+	switch status.State {
+	case monitor.StateUp:
+		label = fmt.Sprintf("up %.1f%%", status.Uptime24h)
+		color = "#4c1"
+	case monitor.StateDown:
+		color = "#e05d44"
+	}
+
+	w.Header().Set("Content-Type", "image/svg+xml")
+	w.Header().Set("Cache-Control", "no-cache, max-age=0")
+	_, _ = fmt.Fprint(w, renderBadge("form endpoint", label, color))
+}
+
+// renderBadge renders a flat shields.io-style SVG badge
+func renderBadge(label, value, color string) string {
+	// Approximate text widths (6px per character plus padding)
+	lw := len(label)*6 + 10
+	vw := len(value)*6 + 10
+	return fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="20" role="img" aria-label="%s: %s">
+<rect width="%d" height="20" fill="#555"/>
+<rect x="%d" width="%d" height="20" fill="%s"/>
+<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">
+<text x="%d" y="14">%s</text>
+<text x="%d" y="14">%s</text>
+</g>
+</svg>`, lw+vw, label, value, lw, lw, vw, color, lw/2, label, lw+vw/2, value)
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"net/http"
+
+	"headless_form/internal/adapter/api/response"
+	"headless_form/internal/adapter/middleware"
+	"headless_form/internal/core/domain"
+	"headless_form/internal/core/service"
+)
+
+// NotificationHandler exposes the current user's in-app notification center
+type NotificationHandler struct {
+	notificationService *service.NotificationService
+}
+
+// NewNotificationHandler creates a new notification handler
+func NewNotificationHandler(notificationService *service.NotificationService) *NotificationHandler {
+	return &NotificationHandler{notificationService: notificationService}
+}
+
+// RegisterRoutes registers notification routes (auth required)
+func (h *NotificationHandler) RegisterRoutes(mux *http.ServeMux, authMiddleware func(http.Handler) http.Handler) {
+	mux.Handle("GET /api/v1/notifications", authMiddleware(http.HandlerFunc(h.HandleListNotifications)))
+	mux.Handle("GET /api/v1/notifications/unread-count", authMiddleware(http.HandlerFunc(h.HandleUnreadCount)))
+	mux.Handle("PUT /api/v1/notifications/read-all", authMiddleware(http.HandlerFunc(h.HandleMarkAllRead)))
+	mux.Handle("PUT /api/v1/notifications/{notification_id}/read", authMiddleware(http.HandlerFunc(h.HandleMarkRead)))
+}
+
+// HandleListNotifications: GET /api/v1/notifications?page=1&limit=20&unread=true
+// Returns the user's notifications newest first, with their unread count.
+func (h *NotificationHandler) HandleListNotifications(w http.ResponseWriter, r *http.Request) {
+	page, limit := parsePage(r, 20, 100)
+	unreadOnly := r.URL.Query().Get("unread") == "true"
+
+	notifications, total, unread, err := h.notificationService.ListNotifications(r.Context(), middleware.GetUserID(r.Context()), unreadOnly, page, limit)
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+	if notifications == nil {
+		notifications = []*domain.Notification{}
+	}
+
+	response.Paginated(w, r, "notifications", notifications, page, limit, total, map[string]interface{}{"unread_count": unread})
+}
+
+// HandleUnreadCount: GET /api/v1/notifications/unread-count
+// Cheap endpoint for polling the dashboard's notification badge.
+func (h *NotificationHandler) HandleUnreadCount(w http.ResponseWriter, r *http.Request) {
+	unread, err := h.notificationService.UnreadCount(r.Context(), middleware.GetUserID(r.Context()))
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+	response.Success(w, map[string]int{"unread_count": unread})
+}
+
+// HandleMarkRead: PUT /api/v1/notifications/{notification_id}/read
+func (h *NotificationHandler) HandleMarkRead(w http.ResponseWriter, r *http.Request) {
+	err := h.notificationService.MarkRead(r.Context(), middleware.GetUserID(r.Context()), r.PathValue("notification_id"))
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	response.Success(w, map[string]string{"message": "Notification marked as read"})
+}
+
+// HandleMarkAllRead: PUT /api/v1/notifications/read-all
+func (h *NotificationHandler) HandleMarkAllRead(w http.ResponseWriter, r *http.Request) {
+	n, err := h.notificationService.MarkAllRead(r.Context(), middleware.GetUserID(r.Context()))
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+	response.Success(w, map[string]int{"marked": n})
+}
@@ -0,0 +1,178 @@
+package api
+
+import (
+	"crypto/subtle"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"headless_form/internal/adapter/api/response"
+	"headless_form/internal/adapter/oauth"
+	"headless_form/internal/core/domain"
+)
+
+// oauthCookie holds the provider, state and PKCE verifier of a sign-in in progress
+const oauthCookie = "hf_oauth"
+
+// oauthCookieMaxAge is how long the user has to sign in at the provider, in seconds
+const oauthCookieMaxAge = 600
+
+// SetOAuthClient replaces the client used to talk to sign-in providers, e.g. to use
+// other endpoints
+func (h *AuthHandler) SetOAuthClient(c *oauth.Client) {
+	h.sso = c
+}
+
+// oauthProviderInfo is a sign-in provider as the login page sees it
+type oauthProviderInfo struct {
+	ID       string                   `json:"id"`
+	Type     domain.OAuthProviderType `json:"type"`
+	Name     string                   `json:"name"`
+	StartURL string                   `json:"start_url"`
+}
+
+// HandleOAuthStart sends the browser to the provider to sign in
+// GET /api/v1/auth/oauth/{provider}/start
+func (h *AuthHandler) HandleOAuthStart(w http.ResponseWriter, r *http.Request) {
+	p := h.oauthProvider(w, r)
+	if p == nil {
+		return
+	}
+
+	state, err := oauth.NewVerifier()
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+	verifier, err := oauth.NewVerifier()
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+	authURL, err := h.sso.AuthCodeURL(r.Context(), p, h.oauthCallbackURL(p), state, verifier)
+	if err != nil {
+		log.Printf("[AUTH] Sign-in with %s failed to start: %v", p.ID, err)
+		h.oauthFailed(w, r, "The sign-in provider could not be reached")
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     oauthCookie,
+		Value:    p.ID + "." + state + "." + verifier,
+		Path:     "/api/v1/auth/oauth/",
+		MaxAge:   oauthCookieMaxAge,
+		HttpOnly: true,
+		Secure:   strings.HasPrefix(h.baseURL, "https://"),
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// HandleOAuthCallback finishes a sign-in: the provider's code is exchanged for the user's
+// verified email, matched to an account, and the browser is sent back to the dashboard
+// with a token in the URL fragment (/login#token=...), or an error (/login#error=...).
+// GET /api/v1/auth/oauth/{provider}/callback
+func (h *AuthHandler) HandleOAuthCallback(w http.ResponseWriter, r *http.Request) {
+	p := h.oauthProvider(w, r)
+	if p == nil {
+		return
+	}
+	http.SetCookie(w, &http.Cookie{Name: oauthCookie, Path: "/api/v1/auth/oauth/", MaxAge: -1, HttpOnly: true})
+
+	q := r.URL.Query()
+	if q.Get("error") != "" {
+		h.oauthFailed(w, r, "Sign-in was cancelled or denied")
+		return
+	}
+	var state, verifier string
+	if c, err := r.Cookie(oauthCookie); err == nil {
+		if parts := strings.Split(c.Value, "."); len(parts) == 3 && parts[0] == p.ID {
+			state, verifier = parts[1], parts[2]
+		}
+	}
+	if state == "" || q.Get("code") == "" || subtle.ConstantTimeCompare([]byte(state), []byte(q.Get("state"))) != 1 {
+		h.oauthFailed(w, r, "Sign-in expired, please try again")
+		return
+	}
+
+	identity, err := h.sso.Exchange(r.Context(), p, h.oauthCallbackURL(p), q.Get("code"), verifier)
+	if err != nil {
+		log.Printf("[AUTH] Sign-in with %s failed: %v", p.ID, err)
+		h.oauthFailed(w, r, "The sign-in provider could not confirm your account")
+		return
+	}
+	token, user, err := h.authService.LoginWithProvider(r.Context(), p, identity.Email, identity.Name)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrOAuthNoAccount), errors.Is(err, domain.ErrOAuthEmailNotVerified),
+			errors.Is(err, domain.ErrOAuthDomainNotAllowed):
+			h.oauthFailed(w, r, err.Error())
+		default:
+			log.Printf("[AUTH] Sign-in with %s failed: %v", p.ID, err)
+			h.oauthFailed(w, r, "Sign-in failed")
+		}
+		return
+	}
+
+	log.Printf("[AUTH] User %s signed in with %s", user.ID, p.ID)
+	http.Redirect(w, r, h.baseURL+"/login#token="+url.QueryEscape(token), http.StatusFound)
+}
+
+// oauthProvider returns the configured provider named in the path, answering 404 when
+// there's none
+func (h *AuthHandler) oauthProvider(w http.ResponseWriter, r *http.Request) *domain.OAuthProvider {
+	settings, err := h.authService.OAuthSettings(r.Context())
+	if err != nil {
+		response.HandleError(w, err)
+		return nil
+	}
+	p := settings.Provider(r.PathValue("provider"))
+	if p == nil {
+		response.NotFound(w, "Sign-in provider not found")
+	}
+	return p
+}
+
+// oauthCallbackURL is the redirect URL to register with the provider
+func (h *AuthHandler) oauthCallbackURL(p *domain.OAuthProvider) string {
+	return h.baseURL + "/api/v1/auth/oauth/" + p.ID + "/callback"
+}
+
+// oauthFailed sends the browser back to the login page with the message
+func (h *AuthHandler) oauthFailed(w http.ResponseWriter, r *http.Request, message string) {
+	http.Redirect(w, r, h.baseURL+"/login#error="+url.QueryEscape(message), http.StatusFound)
+}
+
+// oauthProviders lists the sign-in providers for the login page
+func (h *AuthHandler) oauthProviders(settings *domain.OAuthSettings) []oauthProviderInfo {
+	providers := []oauthProviderInfo{}
+	if settings == nil {
+		return providers
+	}
+	for i := range settings.Providers {
+		p := &settings.Providers[i]
+		providers = append(providers, oauthProviderInfo{
+			ID:       p.ID,
+			Type:     p.Type,
+			Name:     p.Label(),
+			StartURL: "/api/v1/auth/oauth/" + p.ID + "/start",
+		})
+	}
+	return providers
+}
+
+// passwordLoginAllowed answers 403 and returns false when password login is turned off
+func (h *AuthHandler) passwordLoginAllowed(w http.ResponseWriter, r *http.Request) bool {
+	enabled, err := h.authService.PasswordLoginEnabled(r.Context())
+	if err != nil {
+		response.HandleError(w, err)
+		return false
+	}
+	if !enabled {
+		response.Error(w, http.StatusForbidden, domain.ErrPasswordLoginDisabled.Error(), response.CodePasswordLoginDisabled)
+		return false
+	}
+	return true
+}
@@ -10,6 +10,7 @@ import (
 	"headless_form/internal/adapter/middleware"
 	"headless_form/internal/core/domain"
 	"headless_form/internal/core/ports"
+	"headless_form/internal/core/service"
 )
 
 // SettingsHandler handles site settings API endpoints
@@ -27,7 +28,7 @@ func NewSettingsHandler(repo ports.Repository) *SettingsHandler {
 func (h *SettingsHandler) HandleGetSettings(w http.ResponseWriter, r *http.Request) {
 	// Verify super_admin role
 	if !middleware.IsSuperAdmin(r.Context()) {
-		response.Error(w, http.StatusForbidden, "Super admin access required", "FORBIDDEN")
+		response.Error(w, http.StatusForbidden, "Super admin access required", response.CodeForbidden)
 		return
 	}
 
@@ -46,7 +47,7 @@ func (h *SettingsHandler) HandleGetSettings(w http.ResponseWriter, r *http.Reque
 func (h *SettingsHandler) HandleUpdateSettings(w http.ResponseWriter, r *http.Request) {
 	// Verify super_admin role
 	if !middleware.IsSuperAdmin(r.Context()) {
-		response.Error(w, http.StatusForbidden, "Super admin access required", "FORBIDDEN")
+		response.Error(w, http.StatusForbidden, "Super admin access required", response.CodeForbidden)
 		return
 	}
 
@@ -60,10 +61,13 @@ func (h *SettingsHandler) HandleUpdateSettings(w http.ResponseWriter, r *http.Re
 		SMTPFrom     string `json:"smtp_from"`
 		SMTPFromName string `json:"smtp_from_name"`
 		SMTPSecure   bool   `json:"smtp_secure"`
+
+		SpamProvider    string `json:"spam_provider"`
+		SpamProviderKey string `json:"spam_provider_key"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.BadRequest(w, "Invalid JSON body", "INVALID_BODY")
+		response.BadRequest(w, "Invalid JSON body", response.CodeInvalidBody)
 		return
 	}
 
@@ -80,12 +84,36 @@ func (h *SettingsHandler) HandleUpdateSettings(w http.ResponseWriter, r *http.Re
 		SMTPFromName: req.SMTPFromName,
 		SMTPSecure:   req.SMTPSecure,
 		UpdatedBy:    middleware.GetUserID(r.Context()),
+
+		SpamProvider:    req.SpamProvider,
+		SpamProviderKey: req.SpamProviderKey,
 	}
 
-	// If password is masked, don't update it
+	// If password or API key is masked, don't update it
 	if settings.SMTPPassword == "********" {
 		settings.SMTPPassword = ""
 	}
+	if settings.SpamProviderKey == "********" {
+		settings.SpamProviderKey = ""
+	}
+
+	// Content filters, spam, sign-in and export settings are managed with their own
+	// /settings endpoints and stay as they are. An empty API key keeps the saved one,
+	// which the provider can then use.
+	check := *settings
+	if saved, err := h.repo.Settings().Get(r.Context()); err == nil {
+		settings.Filters = saved.Filters
+		settings.Spam = saved.Spam
+		settings.OAuth = saved.OAuth
+		settings.Exports = saved.Exports
+		if check.SpamProviderKey == "" {
+			check.SpamProviderKey = saved.SpamProviderKey
+		}
+	}
+	if err := check.ValidateSpamProvider(); err != nil {
+		response.BadRequest(w, err.Error(), response.CodeValidationError)
+		return
+	}
 
 	if err := h.repo.Settings().Save(r.Context(), settings); err != nil {
 		response.HandleError(w, err)
@@ -95,12 +123,279 @@ func (h *SettingsHandler) HandleUpdateSettings(w http.ResponseWriter, r *http.Re
 	response.Success(w, settings.ToPublic())
 }
 
+// HandleGetFilters returns the global content filters (super_admin only)
+// GET /api/v1/settings/filters
+func (h *SettingsHandler) HandleGetFilters(w http.ResponseWriter, r *http.Request) {
+	if !middleware.IsSuperAdmin(r.Context()) {
+		response.Error(w, http.StatusForbidden, "Super admin access required", response.CodeForbidden)
+		return
+	}
+
+	settings, err := h.repo.Settings().Get(r.Context())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	filters := settings.Filters
+	if filters == nil {
+		filters = []domain.FilterRule{}
+	}
+	response.Success(w, map[string]interface{}{"filters": filters})
+}
+
+// HandleSetFilters replaces the global content filters, checked on every form's
+// submissions before the form's own (super_admin only)
+// PUT /api/v1/settings/filters
+func (h *SettingsHandler) HandleSetFilters(w http.ResponseWriter, r *http.Request) {
+	if !middleware.IsSuperAdmin(r.Context()) {
+		response.Error(w, http.StatusForbidden, "Super admin access required", response.CodeForbidden)
+		return
+	}
+
+	var req struct {
+		Filters []domain.FilterRule `json:"filters"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid JSON body", response.CodeInvalidBody)
+		return
+	}
+	if err := service.PrepareFilters(req.Filters); err != nil {
+		response.BadRequest(w, err.Error(), response.CodeValidationError)
+		return
+	}
+
+	settings, err := h.repo.Settings().Get(r.Context())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+	settings.Filters = req.Filters
+	settings.UpdatedBy = middleware.GetUserID(r.Context())
+	if err := h.repo.Settings().Save(r.Context(), settings); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	filters := settings.Filters
+	if filters == nil {
+		filters = []domain.FilterRule{}
+	}
+	response.Success(w, map[string]interface{}{"filters": filters})
+}
+
+// HandleGetSpam returns the spam settings for every form, with the Safe Browsing key
+// masked (super_admin only)
+// GET /api/v1/settings/spam
+func (h *SettingsHandler) HandleGetSpam(w http.ResponseWriter, r *http.Request) {
+	if !middleware.IsSuperAdmin(r.Context()) {
+		response.Error(w, http.StatusForbidden, "Super admin access required", response.CodeForbidden)
+		return
+	}
+
+	settings, err := h.repo.Settings().Get(r.Context())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+	response.Success(w, spamSettingsResponse(settings.Spam))
+}
+
+// HandleSetSpam replaces the flag weights, blocked link domains and Safe Browsing key
+// used for every form's submissions (super_admin only). A masked key keeps the saved one.
+// PUT /api/v1/settings/spam
+func (h *SettingsHandler) HandleSetSpam(w http.ResponseWriter, r *http.Request) {
+	if !middleware.IsSuperAdmin(r.Context()) {
+		response.Error(w, http.StatusForbidden, "Super admin access required", response.CodeForbidden)
+		return
+	}
+
+	var req domain.SpamSettings
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid JSON body", response.CodeInvalidBody)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		response.BadRequest(w, err.Error(), response.CodeValidationError)
+		return
+	}
+
+	settings, err := h.repo.Settings().Get(r.Context())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+	if req.SafeBrowsingKey == "********" {
+		req.SafeBrowsingKey = ""
+		if settings.Spam != nil {
+			req.SafeBrowsingKey = settings.Spam.SafeBrowsingKey
+		}
+	}
+	settings.Spam = &req
+	settings.UpdatedBy = middleware.GetUserID(r.Context())
+	if err := h.repo.Settings().Save(r.Context(), settings); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+	response.Success(w, spamSettingsResponse(settings.Spam))
+}
+
+// spamSettingsResponse returns the spam settings for a response, masked, with every
+// flag's weight filled in
+func spamSettingsResponse(s *domain.SpamSettings) *domain.SpamSettings {
+	public := &domain.SpamSettings{}
+	if s != nil {
+		public = s.ToPublic()
+	}
+	weights := make(map[string]int, len(domain.DefaultSpamWeights))
+	for flag := range domain.DefaultSpamWeights {
+		weights[flag] = s.Weight(flag)
+	}
+	public.Weights = weights
+	return public
+}
+
+// HandleGetOAuth returns the sign-in providers, with their client secrets masked
+// (super_admin only)
+// GET /api/v1/settings/oauth
+func (h *SettingsHandler) HandleGetOAuth(w http.ResponseWriter, r *http.Request) {
+	if !middleware.IsSuperAdmin(r.Context()) {
+		response.Error(w, http.StatusForbidden, "Super admin access required", response.CodeForbidden)
+		return
+	}
+
+	settings, err := h.repo.Settings().Get(r.Context())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+	response.Success(w, oauthSettingsResponse(settings.OAuth))
+}
+
+// HandleSetOAuth replaces the sign-in providers and whether password login stays on
+// (super_admin only). A masked client secret keeps the provider's saved one.
+// PUT /api/v1/settings/oauth
+func (h *SettingsHandler) HandleSetOAuth(w http.ResponseWriter, r *http.Request) {
+	if !middleware.IsSuperAdmin(r.Context()) {
+		response.Error(w, http.StatusForbidden, "Super admin access required", response.CodeForbidden)
+		return
+	}
+
+	var req domain.OAuthSettings
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid JSON body", response.CodeInvalidBody)
+		return
+	}
+
+	settings, err := h.repo.Settings().Get(r.Context())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+	for i := range req.Providers {
+		if req.Providers[i].ClientSecret == "********" {
+			req.Providers[i].ClientSecret = ""
+			if saved := settings.OAuth.Provider(req.Providers[i].ID); saved != nil {
+				req.Providers[i].ClientSecret = saved.ClientSecret
+			}
+		}
+	}
+	if err := req.Validate(); err != nil {
+		response.BadRequest(w, err.Error(), response.CodeValidationError)
+		return
+	}
+
+	settings.OAuth = &req
+	settings.UpdatedBy = middleware.GetUserID(r.Context())
+	if err := h.repo.Settings().Save(r.Context(), settings); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+	response.Success(w, oauthSettingsResponse(settings.OAuth))
+}
+
+// oauthSettingsResponse returns the sign-in providers for a response, masked
+func oauthSettingsResponse(s *domain.OAuthSettings) *domain.OAuthSettings {
+	if s == nil {
+		return &domain.OAuthSettings{Providers: []domain.OAuthProvider{}}
+	}
+	return s.ToPublic()
+}
+
+// HandleGetExports returns which roles may export submissions and the profiles masking
+// their exports (super_admin only)
+// GET /api/v1/settings/exports
+func (h *SettingsHandler) HandleGetExports(w http.ResponseWriter, r *http.Request) {
+	if !middleware.IsSuperAdmin(r.Context()) {
+		response.Error(w, http.StatusForbidden, "Super admin access required", response.CodeForbidden)
+		return
+	}
+
+	settings, err := h.repo.Settings().Get(r.Context())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+	response.Success(w, exportSettingsResponse(settings.Exports))
+}
+
+// HandleSetExports replaces the export permission of each role and the masked export
+// profiles (super_admin only). Admins always export in full.
+// PUT /api/v1/settings/exports
+func (h *SettingsHandler) HandleSetExports(w http.ResponseWriter, r *http.Request) {
+	if !middleware.IsSuperAdmin(r.Context()) {
+		response.Error(w, http.StatusForbidden, "Super admin access required", response.CodeForbidden)
+		return
+	}
+
+	var req domain.ExportSettings
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid JSON body", response.CodeInvalidBody)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		response.BadRequest(w, err.Error(), response.CodeValidationError)
+		return
+	}
+
+	settings, err := h.repo.Settings().Get(r.Context())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+	settings.Exports = &req
+	settings.UpdatedBy = middleware.GetUserID(r.Context())
+	if err := h.repo.Settings().Save(r.Context(), settings); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+	response.Success(w, exportSettingsResponse(settings.Exports))
+}
+
+// exportSettingsResponse returns the export settings for a response, with every role's
+// permission filled in
+func exportSettingsResponse(s *domain.ExportSettings) *domain.ExportSettings {
+	public := &domain.ExportSettings{Profiles: []domain.ExportProfile{}}
+	if s != nil && s.Profiles != nil {
+		public.Profiles = s.Profiles
+	}
+	public.Roles = make(map[domain.ExportRole]string)
+	for _, role := range []domain.ExportRole{domain.ExportRoleUser, domain.ExportRoleFormToken,
+		domain.ExportRoleTeamOwner, domain.ExportRoleTeamEditor, domain.ExportRoleTeamViewer} {
+		public.Roles[role] = domain.ExportFull
+		if s != nil && s.Roles[role] != "" {
+			public.Roles[role] = s.Roles[role]
+		}
+	}
+	return public
+}
+
 // HandleTestSMTP tests SMTP connection (super_admin only)
 // POST /api/v1/settings/test-smtp
 func (h *SettingsHandler) HandleTestSMTP(w http.ResponseWriter, r *http.Request) {
 	// Verify super_admin role
 	if !middleware.IsSuperAdmin(r.Context()) {
-		response.Error(w, http.StatusForbidden, "Super admin access required", "FORBIDDEN")
+		response.Error(w, http.StatusForbidden, "Super admin access required", response.CodeForbidden)
 		return
 	}
 
@@ -115,17 +410,17 @@ func (h *SettingsHandler) HandleTestSMTP(w http.ResponseWriter, r *http.Request)
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.BadRequest(w, "Invalid JSON body", "INVALID_BODY")
+		response.BadRequest(w, "Invalid JSON body", response.CodeInvalidBody)
 		return
 	}
 
 	if req.Host == "" || req.Port == 0 {
-		response.BadRequest(w, "SMTP host and port are required", "MISSING_SMTP_CONFIG")
+		response.BadRequest(w, "SMTP host and port are required", response.CodeMissingSMTPConfig)
 		return
 	}
 
 	if req.TestTo == "" {
-		response.BadRequest(w, "Test email recipient is required", "MISSING_TEST_TO")
+		response.BadRequest(w, "Test email recipient is required", response.CodeMissingTestTo)
 		return
 	}
 
@@ -159,7 +454,7 @@ func (h *SettingsHandler) HandleTestSMTP(w http.ResponseWriter, r *http.Request)
 
 	err := smtp.SendMail(addr, auth, from, []string{req.TestTo}, msg)
 	if err != nil {
-		response.Error(w, http.StatusBadRequest, fmt.Sprintf("SMTP test failed: %v", err), "SMTP_TEST_FAILED")
+		response.Error(w, http.StatusBadRequest, fmt.Sprintf("SMTP test failed: %v", err), response.CodeSMTPTestFailed)
 		return
 	}
 
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"net/http"
+
+	"headless_form/internal/adapter/api/response"
+	"headless_form/internal/adapter/middleware"
+	"headless_form/internal/core/domain"
+	"headless_form/internal/core/service"
+)
+
+// SpamFingerprintHandler exposes the fingerprints of confirmed spam to admins
+type SpamFingerprintHandler struct {
+	fingerprintService *service.SpamFingerprintService
+}
+
+// NewSpamFingerprintHandler creates a new spam fingerprint handler
+func NewSpamFingerprintHandler(fingerprintService *service.SpamFingerprintService) *SpamFingerprintHandler {
+	return &SpamFingerprintHandler{fingerprintService: fingerprintService}
+}
+
+// RegisterRoutes registers spam fingerprint routes (admin only)
+func (h *SpamFingerprintHandler) RegisterRoutes(mux *http.ServeMux, authMiddleware func(http.Handler) http.Handler) {
+	mux.Handle("GET /api/v1/admin/spam-fingerprints", authMiddleware(http.HandlerFunc(h.HandleList)))
+	mux.Handle("DELETE /api/v1/admin/spam-fingerprints/{hash}", authMiddleware(http.HandlerFunc(h.HandleDelete)))
+}
+
+// HandleList: GET /api/v1/admin/spam-fingerprints?page=1&limit=20
+// Returns the fingerprints of content marked as spam, most hits first (admin only).
+func (h *SpamFingerprintHandler) HandleList(w http.ResponseWriter, r *http.Request) {
+	if !middleware.IsAdmin(r.Context()) {
+		response.Error(w, http.StatusForbidden, "Admin access required", response.CodeForbidden)
+		return
+	}
+	page, limit := parsePage(r, 20, 100)
+
+	fingerprints, total, err := h.fingerprintService.List(r.Context(), page, limit)
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+	if fingerprints == nil {
+		fingerprints = []*domain.SpamFingerprint{}
+	}
+
+	response.Paginated(w, r, "fingerprints", fingerprints, page, limit, total, nil)
+}
+
+// HandleDelete: DELETE /api/v1/admin/spam-fingerprints/{hash}
+// Stops quarantining the fingerprint's content (admin only).
+func (h *SpamFingerprintHandler) HandleDelete(w http.ResponseWriter, r *http.Request) {
+	if !middleware.IsAdmin(r.Context()) {
+		response.Error(w, http.StatusForbidden, "Admin access required", response.CodeForbidden)
+		return
+	}
+
+	if err := h.fingerprintService.Delete(r.Context(), r.PathValue("hash")); err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, map[string]string{"message": "Spam fingerprint deleted"})
+}
@@ -3,12 +3,18 @@ package api
 import (
 	"encoding/json"
 	"fmt"
+	"io"
+	"log"
+	"mime"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"headless_form/internal/adapter/api/request"
 	"headless_form/internal/adapter/api/response"
 	"headless_form/internal/adapter/middleware"
+	"headless_form/internal/adapter/pdf"
 	"headless_form/internal/core/domain"
 )
 
@@ -16,20 +22,44 @@ import (
 // Submission Handlers
 // =============================================================================
 
-// HandleListSubmissions: GET /api/v1/forms/{form_id}/submissions?page=1&limit=50
+// HandleListSubmissions: GET /api/v1/forms/{form_id}/submissions?page=1&limit=50&q=berlin&status=unread&from=2026-01-01&to=2026-01-31&field.plan=pro
+// q searches every submitted value; field.<name>=value keeps submissions whose field equals value.
+// from and to are dates (both included, days in the form's timezone or ?tz=) or RFC 3339 times.
+// Without a status only the inbox is listed, leaving quarantined spam out; status=all lists everything.
+// sanitize=escape or sanitize=strip makes submitted values safe to render as HTML (see parseSanitize).
 func (h *Router) HandleListSubmissions(w http.ResponseWriter, r *http.Request) {
-	publicID := r.PathValue("form_id")
-	page := parseIntParam(r, "page", 1)
-	limit := parseIntParam(r, "limit", 50)
+	switch status := r.URL.Query().Get("status"); status {
+	case "":
+		h.listSubmissions(w, r, domain.SubmissionListInbox)
+	case "all":
+		h.listSubmissions(w, r, "")
+	default:
+		h.listSubmissions(w, r, status)
+	}
+}
+
+// HandleListSpam: GET /api/v1/forms/{form_id}/submissions/spam
+// Lists the form's quarantined submissions, with the same search, date and field filters.
+func (h *Router) HandleListSpam(w http.ResponseWriter, r *http.Request) {
+	h.listSubmissions(w, r, domain.SubmissionListSpam)
+}
 
-	if page < 1 {
-		page = 1
+// streamKeepAlive is how often an idle submission stream sends a comment so proxies keep it open
+const streamKeepAlive = 15 * time.Second
+
+// HandleSubmissionStream: GET /api/v1/forms/{form_id}/submissions/stream
+// Streams the form's new submissions as Server-Sent Events, one "submission" event each,
+// spam and test submissions included, until the client disconnects. Takes ?sanitize= like the list.
+func (h *Router) HandleSubmissionStream(w http.ResponseWriter, r *http.Request) {
+	if h.feed == nil {
+		response.NotFound(w, "Live submissions are not enabled")
+		return
 	}
-	if limit < 1 || limit > 200 {
-		limit = 50
+	sanitize, ok := parseSanitize(w, r)
+	if !ok {
+		return
 	}
-
-	subms, total, err := h.submissionService.ListSubmissionsPaginated(r.Context(), publicID, page, limit)
+	form, err := h.formService.GetForm(r.Context(), r.PathValue("form_id"))
 	if err != nil {
 		if response.HandleDomainError(w, err) {
 			return
@@ -37,115 +67,218 @@ func (h *Router) HandleListSubmissions(w http.ResponseWriter, r *http.Request) {
 		response.HandleError(w, err)
 		return
 	}
+	if !middleware.CanAccessForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "Access denied", response.CodeForbidden)
+		return
+	}
+	profile, ok := h.exportProfile(w, r, form)
+	if !ok {
+		return
+	}
 
-	response.Success(w, map[string]interface{}{
-		"submissions": subms,
-		"pagination": map[string]interface{}{
-			"page":        page,
-			"limit":       limit,
-			"total":       total,
-			"total_pages": (total + limit - 1) / limit,
-		},
-	})
-}
+	submissions, unsubscribe := h.feed.Subscribe(form.ID)
+	defer unsubscribe()
 
-// HandleSubmit: POST /api/v1/submissions/{form_id}
-// This is the Endpoint Form Submission URL - public access with form-level access control
-func (h *Router) HandleSubmit(w http.ResponseWriter, r *http.Request) {
-	publicID := r.PathValue("form_id")
-	contentType := r.Header.Get("Content-Type")
+	// Streams outlive the server's write timeout
+	rc := http.NewResponseController(w)
+	_ = rc.SetWriteDeadline(time.Time{})
 
-	var data map[string]interface{}
-	var clientMeta map[string]interface{}
-	clientMeta = make(map[string]interface{})
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if _, err := fmt.Fprint(w, ": connected\n\n"); err != nil || rc.Flush() != nil {
+		return
+	}
 
-	// 1. Parse Payload based on Content-Type
-	if strings.Contains(contentType, "application/x-www-form-urlencoded") || strings.Contains(contentType, "multipart/form-data") {
-		// Standard HTML Form
-		if err := r.ParseForm(); err != nil {
-			response.BadRequest(w, "Invalid form data", "INVALID_FORM")
+	keepAlive := time.NewTicker(streamKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
 			return
-		}
-		data = make(map[string]interface{})
-		for k, v := range r.PostForm {
-			if len(v) > 0 {
-				data[k] = v[0] // Simplify to single value for standard fields
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil || rc.Flush() != nil {
+				return
+			}
+		case sub := <-submissions:
+			data, _ := json.Marshal(profile.Mask(&sub, form).Sanitized(sanitize))
+			if _, err := fmt.Fprintf(w, "id: %s\nevent: submission\ndata: %s\n\n", sub.ID, data); err != nil || rc.Flush() != nil {
+				return
 			}
 		}
-	} else {
-		// Default to JSON (API/Fetch)
-		var payload map[string]interface{}
-		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
-			response.BadRequest(w, "Invalid JSON body", "INVALID_BODY")
+	}
+}
+
+// listSubmissions sends a page of the form's submissions with the given list status
+func (h *Router) listSubmissions(w http.ResponseWriter, r *http.Request, status string) {
+	publicID := r.PathValue("form_id")
+	page, limit := parsePage(r, 50, 200)
+	sanitize, ok := parseSanitize(w, r)
+	if !ok {
+		return
+	}
+
+	form, err := h.formService.GetForm(r.Context(), publicID)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
 			return
 		}
+		response.HandleError(w, err)
+		return
+	}
+	if !middleware.CanAccessForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "Access denied", response.CodeForbidden)
+		return
+	}
+	profile, ok := h.exportProfile(w, r, form)
+	if !ok {
+		return
+	}
 
-		// Hybrid JSON Support (Flat vs Nested)
-		if nestedData, ok := payload["data"].(map[string]interface{}); ok {
-			data = nestedData
-			if nestedMeta, ok := payload["meta"].(map[string]interface{}); ok {
-				clientMeta = nestedMeta
+	q := r.URL.Query()
+	query := domain.SubmissionListQuery{Search: q.Get("q"), Status: status}
+	for key, values := range q {
+		if name, ok := strings.CutPrefix(key, "field."); ok && len(values) > 0 {
+			if query.Fields == nil {
+				query.Fields = make(map[string]string)
 			}
-		} else {
-			data = payload
+			query.Fields[name] = values[0]
 		}
 	}
-
-	// 2. Collect server-side metadata (TRUSTED - auto-detected from request)
-	serverMeta := request.GetServerMeta(r)
-
-	// 3. Spam detection (using singleton detector for rate limiting state)
-	spamScore := h.spamDetector.Analyze(serverMeta.IP, serverMeta.UserAgent, data, 0)
-	h.spamDetector.RecordSubmission(serverMeta.IP) // Track for rate limiting
-
-	// 4. Build combined meta with separated _server, _client, and _spam
-	meta := map[string]interface{}{
-		"_server": serverMeta, // Trusted server-collected data
-		"_client": clientMeta, // Client-provided data (may be spoofed)
-		"_spam":   spamScore,  // Spam detection result
+	// Searching masked values would tell them anyway, so masked roles can't search
+	// them, nor run a full-text search, which covers every field
+	if profile != nil {
+		masked := query.Search != ""
+		for name := range query.Fields {
+			masked = masked || profile.Masks(name, form)
+		}
+		if masked {
+			response.Error(w, http.StatusForbidden, "Your role may not search masked fields", response.CodeExportNotAllowed)
+			return
+		}
 	}
-
-	// For private forms: inject auth user ID from context
-	if userID := r.Context().Value("user_id"); userID != nil {
-		if uid, ok := userID.(string); ok {
-			meta["_auth_user_id"] = uid
+	if q.Get("from") != "" || q.Get("to") != "" {
+		loc, err := request.ParseTimezone(r)
+		if err != nil {
+			writeTimeFormatError(w, err)
+			return
+		}
+		if loc == nil {
+			loc = h.formService.Location(r.Context(), form)
+		}
+		var ok bool
+		if query.From, ok = parseListDate(q.Get("from"), loc, false); !ok {
+			response.BadRequest(w, "from must be a date (YYYY-MM-DD) or RFC 3339 timestamp", response.CodeInvalidDate)
+			return
+		}
+		if query.To, ok = parseListDate(q.Get("to"), loc, true); !ok {
+			response.BadRequest(w, "to must be a date (YYYY-MM-DD) or RFC 3339 timestamp", response.CodeInvalidDate)
+			return
 		}
 	}
 
-	// 5. Submit
-	subm, err := h.submissionService.Submit(r.Context(), publicID, data, meta)
+	subms, counts, err := h.submissionService.ListSubmissionsPaginated(r.Context(), form.PublicID, middleware.GetUserID(r.Context()), query, page, limit)
 	if err != nil {
 		if response.HandleDomainError(w, err) {
 			return
 		}
-		response.Error(w, http.StatusBadRequest, err.Error(), "SUBMISSION_FAILED")
+		response.HandleError(w, err)
 		return
 	}
 
-	// 6. Handle Response (Redirect vs JSON)
-	form, _ := h.formService.GetForm(r.Context(), publicID)
+	for i, sub := range subms {
+		subms[i] = profile.Mask(sub, form).Sanitized(sanitize)
+	}
+	response.Paginated(w, r, "submissions", subms, page, limit, counts.Total, map[string]interface{}{"counts": counts})
+}
 
-	redirectURL := ""
-	if q := r.URL.Query().Get("redirect_to"); q != "" {
-		redirectURL = q
-	} else if form != nil && form.RedirectURL != "" {
-		redirectURL = form.RedirectURL
+// parseSanitize reads ?sanitize=: escape turns HTML special characters in submitted values
+// into entities, strip removes tags and leaves the text. It answers 400 for other values.
+func parseSanitize(w http.ResponseWriter, r *http.Request) (domain.SanitizeMode, bool) {
+	mode, err := domain.ParseSanitizeMode(r.URL.Query().Get("sanitize"))
+	if err != nil {
+		response.BadRequest(w, err.Error(), response.CodeValidationError)
+		return "", false
 	}
+	return mode, true
+}
 
-	// Only redirect if likely initiated by browser form (HTML content type)
-	isHTMLForm := strings.Contains(contentType, "application/x-www-form-urlencoded") || strings.Contains(contentType, "multipart/form-data")
+// parseListDate reads a date range bound: an RFC 3339 time, or a date in loc. A date
+// ending the range includes the whole day. Empty values are the zero time.
+func parseListDate(v string, loc *time.Location, end bool) (time.Time, bool) {
+	if v == "" {
+		return time.Time{}, true
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, true
+	}
+	day, err := time.ParseInLocation("2006-01-02", v, loc)
+	if err != nil {
+		return time.Time{}, false
+	}
+	if end {
+		day = day.AddDate(0, 0, 1)
+	}
+	return day, true
+}
+
+// HandleSubmit: POST /api/v1/submissions/{form_id}
+// This is the Endpoint Form Submission URL - public access with form-level access control.
+// The work is done by the submission pipeline (see pipeline.go).
+func (h *Router) HandleSubmit(w http.ResponseWriter, r *http.Request) {
+	publicID := r.PathValue("form_id")
 
-	if redirectURL != "" && isHTMLForm {
-		http.Redirect(w, r, redirectURL, http.StatusFound)
+	// Dry run (used by the heartbeat monitor): verify the form would accept a submission
+	// from this request, its origin and access rules included, without storing anything
+	if r.URL.Query().Get("dry_run") == "true" {
+		h.dryRunPipeline.Run(w, r, publicID)
 		return
 	}
 
-	response.Created(w, subm)
+	h.submitPipeline.Run(w, r, publicID)
+}
+
+// HandleSubmitPreflight: OPTIONS /api/v1/submissions/{form_id}
+// Answers CORS preflights with the form's policy: 204 when the origin is allowed, 403 otherwise
+func (h *Router) HandleSubmitPreflight(w http.ResponseWriter, r *http.Request) {
+	form, previous, err := h.formService.GetSubmitForm(r.Context(), r.PathValue("form_id"))
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	if previous {
+		setPublicIDDeprecation(w, form)
+	}
+	if !middleware.SetFormCORS(w, r, form) {
+		response.HandleDomainError(w, domain.ErrOriginNotAllowed)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// setPublicIDDeprecation marks a response to a rotated-out public ID as deprecated,
+// with when the old ID stops working and the URL to submit to instead
+func setPublicIDDeprecation(w http.ResponseWriter, form *domain.Form) {
+	w.Header().Set("Deprecation", "true")
+	w.Header().Set("Sunset", form.PreviousPublicIDUntil.UTC().Format(http.TimeFormat))
+	w.Header().Set("Link", fmt.Sprintf(`</api/v1/submissions/%s>; rel="successor-version"`, form.PublicID))
+	w.Header().Set("Access-Control-Expose-Headers", "Deprecation, Sunset, Link")
 }
 
 // HandleGetSubmission: GET /api/v1/submissions/{sub_id}
+// Opening a submission records it as opened by the current user; opened_by lists everyone who has.
+// details holds the meta sections read into flat fields, with the browser, OS and device told
+// from the user agent. Takes ?sanitize= like the list.
 func (h *Router) HandleGetSubmission(w http.ResponseWriter, r *http.Request) {
 	subID := r.PathValue("sub_id")
+	sanitize, ok := parseSanitize(w, r)
+	if !ok {
+		return
+	}
 
 	// Get submission
 	sub, err := h.submissionService.GetSubmission(r.Context(), subID)
@@ -164,16 +297,133 @@ func (h *Router) HandleGetSubmission(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !middleware.CanAccessForm(r.Context(), form.OwnerID) {
-		response.Error(w, http.StatusForbidden, "Access denied", "FORBIDDEN")
+	if !middleware.CanAccessForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "Access denied", response.CodeForbidden)
+		return
+	}
+	profile, ok := h.exportProfile(w, r, form)
+	if !ok {
+		return
+	}
+
+	// Form tokens act as the owner; reads through them aren't the owner opening it
+	userID := middleware.GetUserID(r.Context())
+	if userID != "" && middleware.GetUserRole(r.Context()) != middleware.RoleFormToken {
+		if err := h.submissionService.OpenSubmission(r.Context(), sub, userID); err != nil {
+			log.Printf("[ERROR] Failed to record view of %s: %v", sub.ID, err)
+		}
+	}
+
+	sub = profile.Mask(sub, form).Sanitized(sanitize)
+	response.Success(w, submissionDetail{Submission: sub, Details: sub.Meta.Details()})
+}
+
+// submissionDetail is a submission with its metadata read into flat fields
+type submissionDetail struct {
+	*domain.Submission
+	Details domain.SubmissionDetails `json:"details"`
+}
+
+// HandleSubmissionPDF: GET /api/v1/submissions/{sub_id}/pdf?labels=raw
+// Renders the submission as a printable PDF. Field keys are shown as readable labels
+// ("first_name" -> "First name") unless labels=raw.
+func (h *Router) HandleSubmissionPDF(w http.ResponseWriter, r *http.Request) {
+	subID := r.PathValue("sub_id")
+
+	sub, err := h.submissionService.GetSubmission(r.Context(), subID)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+
+	form, err := h.formService.GetFormByID(r.Context(), sub.FormID)
+	if err != nil || form == nil {
+		response.NotFound(w, "Associated form not found")
+		return
+	}
+	if !middleware.CanAccessForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "Access denied", response.CodeForbidden)
 		return
 	}
+	profile, ok := h.exportProfile(w, r, form)
+	if !ok {
+		return
+	}
+	sub, form = profile.Mask(sub, form), profile.MaskForm(form)
 
-	response.Success(w, sub)
+	var data map[string]interface{}
+	_ = json.Unmarshal(sub.Data, &data)
+
+	locale := form.MatchLocale(request.PreferredLanguages(r))
+	doc := pdf.SubmissionDocument(form, sub, data, r.URL.Query().Get("labels") != "raw", locale)
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", "inline; filename=\"submission_"+sub.ID+".pdf\"")
+	if _, err := w.Write(pdf.Render(doc)); err != nil {
+		log.Printf("[ERROR] Failed to write PDF response: %v", err)
+	}
 }
 
-// verifySubmissionOwnership checks if the current user can access a submission
-func (h *Router) verifySubmissionOwnership(r *http.Request, subID string) (*domain.Submission, error) {
+// HandleSubmissionFile: GET /api/v1/submissions/{sub_id}/files/{file_id}
+// Downloads a file uploaded with the submission. It is always sent as an attachment
+// so uploaded HTML or SVG can't run in the dashboard's origin.
+func (h *Router) HandleSubmissionFile(w http.ResponseWriter, r *http.Request) {
+	subID := r.PathValue("sub_id")
+
+	sub, err := h.submissionService.GetSubmission(r.Context(), subID)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+
+	form, err := h.formService.GetFormByID(r.Context(), sub.FormID)
+	if err != nil || form == nil {
+		response.NotFound(w, "Associated form not found")
+		return
+	}
+	if !middleware.CanAccessForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "Access denied", response.CodeForbidden)
+		return
+	}
+	profile, ok := h.exportProfile(w, r, form)
+	if !ok {
+		return
+	}
+	// Files uploaded to masked fields aren't the user's to download
+	if _, found := profile.Mask(sub, form).Meta.File(r.PathValue("file_id")); !found {
+		if _, exists := sub.Meta.File(r.PathValue("file_id")); exists {
+			response.Error(w, http.StatusForbidden, "Your role may not download files of masked fields", response.CodeExportNotAllowed)
+			return
+		}
+	}
+
+	ref, file, err := h.submissionService.OpenFile(r.Context(), sub, r.PathValue("file_id"))
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	defer func() { _ = file.Close() }()
+
+	w.Header().Set("Content-Type", ref.ContentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": ref.Name}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Length", strconv.FormatInt(ref.Size, 10))
+	if _, err := io.Copy(w, file); err != nil {
+		log.Printf("[ERROR] Failed to write file response: %v", err)
+	}
+}
+
+// verifySubmissionOwnership checks if the current user can access a submission,
+// or change it when manage is set
+func (h *Router) verifySubmissionOwnership(r *http.Request, subID string, manage bool) (*domain.Submission, error) {
 	sub, err := h.submissionService.GetSubmission(r.Context(), subID)
 	if err != nil {
 		return nil, err
@@ -184,23 +434,141 @@ func (h *Router) verifySubmissionOwnership(r *http.Request, subID string) (*doma
 		return nil, domain.ErrFormNotFound
 	}
 
-	if !middleware.CanAccessForm(r.Context(), form.OwnerID) {
+	allowed := middleware.CanAccessForm
+	if manage {
+		allowed = middleware.CanManageForm
+	}
+	if !allowed(r.Context(), form) {
 		return nil, fmt.Errorf("access denied")
 	}
 
 	return sub, nil
 }
 
+// HandleRelatedSubmissions: GET /api/v1/submissions/{sub_id}/related
+// Lists the submissions linked to this one: those it references (e.g. the application
+// an interview feedback is about) and those referencing it, with their forms. Submissions
+// on forms the user can't access are left out. Takes ?sanitize= like the list.
+func (h *Router) HandleRelatedSubmissions(w http.ResponseWriter, r *http.Request) {
+	sanitize, ok := parseSanitize(w, r)
+	if !ok {
+		return
+	}
+	sub, err := h.verifySubmissionOwnership(r, r.PathValue("sub_id"), false)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.Error(w, http.StatusForbidden, "Access denied", response.CodeForbidden)
+		return
+	}
+
+	related, err := h.submissionService.RelatedSubmissions(r.Context(), sub)
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+	settings, err := h.submissionService.ExportSettings(r.Context())
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+	visible := make([]domain.RelatedSubmission, 0, len(related))
+	for _, rel := range related {
+		if !middleware.CanAccessForm(r.Context(), rel.Form) {
+			continue
+		}
+		// Masked like the form's own submissions; left out where the user's role may not read them
+		profile, err := settings.Access(middleware.ExportRole(r.Context(), rel.Form))
+		if err != nil {
+			continue
+		}
+		rel.Submission = profile.Mask(rel.Submission, rel.Form).Sanitized(sanitize)
+		visible = append(visible, rel)
+	}
+
+	response.Success(w, visible)
+}
+
+// HandleLinkSubmission: POST /api/v1/submissions/{sub_id}/links
+// Links the submission to one it refers to, e.g. {"submission_id": "sub_..."}. The user must
+// be able to edit this submission's form and see the other's, and both forms need the same
+// owner or team.
+func (h *Router) HandleLinkSubmission(w http.ResponseWriter, r *http.Request) {
+	sub, err := h.verifySubmissionOwnership(r, r.PathValue("sub_id"), true)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.Error(w, http.StatusForbidden, "Access denied", response.CodeForbidden)
+		return
+	}
+
+	var req struct {
+		SubmissionID string `json:"submission_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid JSON body", response.CodeInvalidBody)
+		return
+	}
+	if req.SubmissionID == "" {
+		response.BadRequest(w, "submission_id is required", response.CodeValidationError)
+		return
+	}
+	linked, err := h.verifySubmissionOwnership(r, req.SubmissionID, false)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.Error(w, http.StatusForbidden, "Access denied", response.CodeForbidden)
+		return
+	}
+
+	link, err := h.submissionService.LinkSubmissions(r.Context(), sub, linked, middleware.GetUserID(r.Context()))
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Created(w, link)
+}
+
+// HandleUnlinkSubmission: DELETE /api/v1/submissions/{sub_id}/links/{linked_id}
+// Removes the link between the two submissions, whichever of them refers to the other
+func (h *Router) HandleUnlinkSubmission(w http.ResponseWriter, r *http.Request) {
+	sub, err := h.verifySubmissionOwnership(r, r.PathValue("sub_id"), true)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.Error(w, http.StatusForbidden, "Access denied", response.CodeForbidden)
+		return
+	}
+
+	if err := h.submissionService.UnlinkSubmissions(r.Context(), sub.ID, r.PathValue("linked_id")); err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, map[string]string{"message": "Link removed"})
+}
+
 // HandleMarkAsRead: PUT /api/v1/submissions/{sub_id}/read
 func (h *Router) HandleMarkAsRead(w http.ResponseWriter, r *http.Request) {
 	subID := r.PathValue("sub_id")
 
 	// Verify ownership before marking as read
-	if _, err := h.verifySubmissionOwnership(r, subID); err != nil {
+	if _, err := h.verifySubmissionOwnership(r, subID, false); err != nil {
 		if response.HandleDomainError(w, err) {
 			return
 		}
-		response.Error(w, http.StatusForbidden, "Access denied", "FORBIDDEN")
+		response.Error(w, http.StatusForbidden, "Access denied", response.CodeForbidden)
 		return
 	}
 
@@ -217,11 +585,11 @@ func (h *Router) HandleMarkAsUnread(w http.ResponseWriter, r *http.Request) {
 	subID := r.PathValue("sub_id")
 
 	// Verify ownership before marking as unread
-	if _, err := h.verifySubmissionOwnership(r, subID); err != nil {
+	if _, err := h.verifySubmissionOwnership(r, subID, false); err != nil {
 		if response.HandleDomainError(w, err) {
 			return
 		}
-		response.Error(w, http.StatusForbidden, "Access denied", "FORBIDDEN")
+		response.Error(w, http.StatusForbidden, "Access denied", response.CodeForbidden)
 		return
 	}
 
@@ -233,16 +601,49 @@ func (h *Router) HandleMarkAsUnread(w http.ResponseWriter, r *http.Request) {
 	response.Success(w, map[string]string{"message": "Marked as unread"})
 }
 
+// HandleMarkAsSpam: PUT /api/v1/submissions/{sub_id}/spam
+func (h *Router) HandleMarkAsSpam(w http.ResponseWriter, r *http.Request) {
+	h.setSpam(w, r, true)
+}
+
+// HandleMarkAsNotSpam: PUT /api/v1/submissions/{sub_id}/not-spam
+func (h *Router) HandleMarkAsNotSpam(w http.ResponseWriter, r *http.Request) {
+	h.setSpam(w, r, false)
+}
+
+// setSpam quarantines the submission as spam, or releases it to the inbox
+func (h *Router) setSpam(w http.ResponseWriter, r *http.Request, spam bool) {
+	sub, err := h.verifySubmissionOwnership(r, r.PathValue("sub_id"), true)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.Error(w, http.StatusForbidden, "Access denied", response.CodeForbidden)
+		return
+	}
+
+	mark, message := h.submissionService.MarkAsNotSpam, "Marked as not spam"
+	if spam {
+		mark, message = h.submissionService.MarkAsSpam, "Marked as spam"
+	}
+	if err := mark(r.Context(), sub.FormID, sub.ID); err != nil {
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, map[string]string{"message": message})
+}
+
 // HandleDeleteSubmission: DELETE /api/v1/submissions/{sub_id}
 func (h *Router) HandleDeleteSubmission(w http.ResponseWriter, r *http.Request) {
 	subID := r.PathValue("sub_id")
 
 	// Verify ownership before deleting
-	if _, err := h.verifySubmissionOwnership(r, subID); err != nil {
+	if _, err := h.verifySubmissionOwnership(r, subID, true); err != nil {
 		if response.HandleDomainError(w, err) {
 			return
 		}
-		response.Error(w, http.StatusForbidden, "Access denied", "FORBIDDEN")
+		response.Error(w, http.StatusForbidden, "Access denied", response.CodeForbidden)
 		return
 	}
 
@@ -253,3 +654,107 @@ func (h *Router) HandleDeleteSubmission(w http.ResponseWriter, r *http.Request)
 
 	response.Success(w, map[string]string{"message": "Submission deleted successfully"})
 }
+
+// HandleDeleteSubmissions: DELETE /api/v1/forms/{form_id}/submissions
+// Bulk-deletes submissions matching ?status=, ?before= (YYYY-MM-DD or RFC 3339) and/or ?test=true|false.
+// At least one filter is required so a bare DELETE can't wipe a form.
+func (h *Router) HandleDeleteSubmissions(w http.ResponseWriter, r *http.Request) {
+	publicID := r.PathValue("form_id")
+
+	form, err := h.formService.GetForm(r.Context(), publicID)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	if !middleware.CanManageForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "Access denied", response.CodeForbidden)
+		return
+	}
+
+	var filter domain.SubmissionFilter
+	query := r.URL.Query()
+	if v := query.Get("status"); v != "" {
+		status := domain.SubmissionStatus(v)
+		if status != domain.SubmissionStatusUnread && status != domain.SubmissionStatusRead && status != domain.SubmissionStatusSpam {
+			response.BadRequest(w, "status must be unread, read or spam", response.CodeValidationError)
+			return
+		}
+		filter.Status = status
+	}
+	if v := query.Get("before"); v != "" {
+		before, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			if before, err = time.Parse("2006-01-02", v); err != nil {
+				response.BadRequest(w, "before must be a date (YYYY-MM-DD) or RFC 3339 timestamp", response.CodeInvalidDate)
+				return
+			}
+		}
+		filter.Before = before
+	}
+	if v := query.Get("test"); v != "" {
+		isTest, err := strconv.ParseBool(v)
+		if err != nil {
+			response.BadRequest(w, "test must be true or false", response.CodeValidationError)
+			return
+		}
+		filter.IsTest = &isTest
+	}
+
+	deleted, err := h.submissionService.DeleteSubmissions(r.Context(), publicID, filter, middleware.GetUserID(r.Context()))
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, map[string]interface{}{"deleted": deleted})
+}
+
+// HandleBulkSubmissions: POST /api/v1/forms/{form_id}/submissions/bulk
+// Applies {"action": "delete|mark_read|mark_unread|mark_spam|mark_not_spam", "ids": [...]} to up to
+// 1000 of the form's submissions at once. IDs of other forms' submissions are ignored.
+func (h *Router) HandleBulkSubmissions(w http.ResponseWriter, r *http.Request) {
+	publicID := r.PathValue("form_id")
+
+	form, err := h.formService.GetForm(r.Context(), publicID)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	if !middleware.CanManageForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "Access denied", response.CodeForbidden)
+		return
+	}
+
+	var req struct {
+		Action domain.BulkAction `json:"action"`
+		IDs    []string          `json:"ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid request body", response.CodeInvalidBody)
+		return
+	}
+
+	affected, err := h.submissionService.BulkAction(r.Context(), publicID, req.Action, req.IDs, middleware.GetUserID(r.Context()))
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, map[string]interface{}{
+		"action":    req.Action,
+		"requested": len(req.IDs),
+		"affected":  affected,
+	})
+}
@@ -0,0 +1,174 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"headless_form/internal/adapter/api/response"
+	"headless_form/internal/adapter/middleware"
+	"headless_form/internal/core/domain"
+	"headless_form/internal/core/service"
+)
+
+// taskKeepAlive is how often an idle progress stream sends a comment so proxies keep it open
+const taskKeepAlive = 15 * time.Second
+
+// TaskHandler exposes background task status, cancellation and progress streams
+type TaskHandler struct {
+	taskService *service.TaskService
+}
+
+// NewTaskHandler creates a new task handler
+func NewTaskHandler(taskService *service.TaskService) *TaskHandler {
+	return &TaskHandler{taskService: taskService}
+}
+
+// RegisterRoutes registers task routes (auth required)
+func (h *TaskHandler) RegisterRoutes(mux *http.ServeMux, authMiddleware func(http.Handler) http.Handler) {
+	mux.Handle("GET /api/v1/tasks/{task_id}", authMiddleware(http.HandlerFunc(h.HandleGetTask)))
+	mux.Handle("POST /api/v1/tasks/{task_id}/cancel", authMiddleware(http.HandlerFunc(h.HandleCancelTask)))
+	mux.Handle("GET /api/v1/tasks/{task_id}/events", authMiddleware(http.HandlerFunc(h.HandleTaskEvents)))
+	mux.Handle("GET /api/v1/tasks/{task_id}/download", authMiddleware(http.HandlerFunc(h.HandleDownloadTaskFile)))
+}
+
+// loadTask loads the task and checks the current user started it or is an admin.
+// Writes the error response and returns nil if not.
+func (h *TaskHandler) loadTask(w http.ResponseWriter, r *http.Request) *domain.Task {
+	task, err := h.taskService.Get(r.Context(), r.PathValue("task_id"))
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return nil
+		}
+		response.HandleError(w, err)
+		return nil
+	}
+	if !middleware.IsAdmin(r.Context()) && middleware.GetUserID(r.Context()) != task.CreatedBy {
+		// Don't reveal other users' tasks exist
+		response.NotFound(w, "Task not found")
+		return nil
+	}
+	return task
+}
+
+// HandleGetTask: GET /api/v1/tasks/{task_id}
+// Returns the task's status, progress and, once completed, its result.
+func (h *TaskHandler) HandleGetTask(w http.ResponseWriter, r *http.Request) {
+	task := h.loadTask(w, r)
+	if task == nil {
+		return
+	}
+	response.Success(w, task)
+}
+
+// HandleDownloadTaskFile: GET /api/v1/tasks/{task_id}/download
+// Downloads the file a completed task produced, such as an export, until it expires.
+func (h *TaskHandler) HandleDownloadTaskFile(w http.ResponseWriter, r *http.Request) {
+	task := h.loadTask(w, r)
+	if task == nil {
+		return
+	}
+
+	file, content, err := h.taskService.OpenFile(r.Context(), task)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	defer func() { _ = content.Close() }()
+
+	w.Header().Set("Content-Type", file.ContentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": file.Name}))
+	w.Header().Set("Content-Length", strconv.FormatInt(file.Size, 10))
+	if _, err := io.Copy(w, content); err != nil {
+		log.Printf("[ERROR] Failed to write task file: %v", err)
+	}
+}
+
+// HandleCancelTask: POST /api/v1/tasks/{task_id}/cancel
+// Asks a running task to stop; it is marked cancelled once its current step finishes.
+func (h *TaskHandler) HandleCancelTask(w http.ResponseWriter, r *http.Request) {
+	if h.loadTask(w, r) == nil {
+		return
+	}
+
+	task, err := h.taskService.Cancel(r.Context(), r.PathValue("task_id"))
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	response.Accepted(w, task)
+}
+
+// HandleTaskEvents: GET /api/v1/tasks/{task_id}/events
+// Streams the task as Server-Sent Events: a "progress" event with the current state,
+// one per update while it runs, and a final "done" event before the stream closes.
+func (h *TaskHandler) HandleTaskEvents(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("task_id")
+	// Subscribe before reading the state so no update falls in between
+	updates, unsubscribe := h.taskService.Subscribe(id)
+	defer unsubscribe()
+
+	task := h.loadTask(w, r)
+	if task == nil {
+		return
+	}
+
+	// Progress streams outlive the server's write timeout
+	rc := http.NewResponseController(w)
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	send := func(event string, t *domain.Task) bool {
+		data, _ := json.Marshal(t)
+		if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data); err != nil {
+			return false
+		}
+		return rc.Flush() == nil
+	}
+
+	if task.Status.IsFinal() {
+		send("done", task)
+		return
+	}
+	if !send("progress", task) {
+		return
+	}
+
+	keepAlive := time.NewTicker(taskKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil || rc.Flush() != nil {
+				return
+			}
+		case t, ok := <-updates:
+			if !ok {
+				if final, err := h.taskService.Get(r.Context(), id); err == nil {
+					send("done", final)
+				}
+				return
+			}
+			if !send("progress", &t) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"headless_form/internal/adapter/api/response"
+	"headless_form/internal/adapter/middleware"
+	"headless_form/internal/core/domain"
+	"headless_form/internal/core/service"
+)
+
+// TeamHandler handles team and team membership endpoints
+type TeamHandler struct {
+	teamService *service.TeamService
+	formService *service.FormService
+}
+
+// NewTeamHandler creates a new team handler
+func NewTeamHandler(teamService *service.TeamService, formService *service.FormService) *TeamHandler {
+	return &TeamHandler{teamService: teamService, formService: formService}
+}
+
+// RegisterRoutes registers team routes (auth required)
+func (h *TeamHandler) RegisterRoutes(mux *http.ServeMux, authMiddleware func(http.Handler) http.Handler) {
+	mux.Handle("GET /api/v1/teams", authMiddleware(http.HandlerFunc(h.HandleListTeams)))
+	mux.Handle("POST /api/v1/teams", authMiddleware(http.HandlerFunc(h.HandleCreateTeam)))
+	mux.Handle("GET /api/v1/teams/{team_id}", authMiddleware(http.HandlerFunc(h.HandleGetTeam)))
+	mux.Handle("PUT /api/v1/teams/{team_id}", authMiddleware(http.HandlerFunc(h.HandleRenameTeam)))
+	mux.Handle("DELETE /api/v1/teams/{team_id}", authMiddleware(http.HandlerFunc(h.HandleDeleteTeam)))
+	mux.Handle("PUT /api/v1/teams/{team_id}/members", authMiddleware(http.HandlerFunc(h.HandleSetMember)))
+	mux.Handle("DELETE /api/v1/teams/{team_id}/members/{user_id}", authMiddleware(http.HandlerFunc(h.HandleRemoveMember)))
+	mux.Handle("PUT /api/v1/forms/{form_id}/team", authMiddleware(http.HandlerFunc(h.HandleSetFormTeam)))
+}
+
+// authorizeTeam checks the current user is a member of the team, and one of its owners
+// when manage is set. Admins can reach every team. Writes the error response and returns
+// false if not; teams the user isn't in are reported as not found.
+func (h *TeamHandler) authorizeTeam(w http.ResponseWriter, r *http.Request, manage bool) bool {
+	if middleware.IsAdmin(r.Context()) {
+		return true
+	}
+	role, ok := middleware.GetTeamRoles(r.Context())[r.PathValue("team_id")]
+	if !ok {
+		response.NotFound(w, "Team not found")
+		return false
+	}
+	if manage && role != domain.TeamRoleOwner {
+		response.Error(w, http.StatusForbidden, "Only team owners can do this", response.CodeForbidden)
+		return false
+	}
+	return true
+}
+
+// HandleListTeams: GET /api/v1/teams
+// Lists the current user's teams with their role in each; admins see every team.
+func (h *TeamHandler) HandleListTeams(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	if middleware.IsAdmin(r.Context()) {
+		userID = ""
+	}
+	teams, err := h.teamService.ListTeams(r.Context(), userID)
+	if response.HandleError(w, err) {
+		return
+	}
+	response.Success(w, map[string]interface{}{
+		"teams": teams,
+	})
+}
+
+// HandleCreateTeam: POST /api/v1/teams
+// Creates a team with the current user as its owner.
+func (h *TeamHandler) HandleCreateTeam(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid JSON body", response.CodeInvalidBody)
+		return
+	}
+
+	team, err := h.teamService.CreateTeam(r.Context(), req.Name, middleware.GetUserID(r.Context()))
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	response.Created(w, team)
+}
+
+// HandleGetTeam: GET /api/v1/teams/{team_id}
+func (h *TeamHandler) HandleGetTeam(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeTeam(w, r, false) {
+		return
+	}
+	team, err := h.teamService.GetTeam(r.Context(), r.PathValue("team_id"))
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	response.Success(w, team)
+}
+
+// HandleRenameTeam: PUT /api/v1/teams/{team_id}
+func (h *TeamHandler) HandleRenameTeam(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeTeam(w, r, true) {
+		return
+	}
+	var req struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid JSON body", response.CodeInvalidBody)
+		return
+	}
+
+	team, err := h.teamService.RenameTeam(r.Context(), r.PathValue("team_id"), req.Name)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	response.Success(w, team)
+}
+
+// HandleDeleteTeam: DELETE /api/v1/teams/{team_id}
+// The team's forms stay with their owners and are no longer shared.
+func (h *TeamHandler) HandleDeleteTeam(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeTeam(w, r, true) {
+		return
+	}
+	if err := h.teamService.DeleteTeam(r.Context(), r.PathValue("team_id")); err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	response.Success(w, map[string]string{"message": "Team deleted successfully"})
+}
+
+// HandleSetMember: PUT /api/v1/teams/{team_id}/members
+// Adds the user with the email as owner, editor or viewer, or changes their role.
+func (h *TeamHandler) HandleSetMember(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeTeam(w, r, true) {
+		return
+	}
+	var req struct {
+		Email string          `json:"email"`
+		Role  domain.TeamRole `json:"role"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid JSON body", response.CodeInvalidBody)
+		return
+	}
+
+	team, err := h.teamService.SetMember(r.Context(), r.PathValue("team_id"), req.Email, req.Role)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	response.Success(w, team)
+}
+
+// HandleRemoveMember: DELETE /api/v1/teams/{team_id}/members/{user_id}
+// Team owners can remove anyone; members can remove themselves to leave the team.
+func (h *TeamHandler) HandleRemoveMember(w http.ResponseWriter, r *http.Request) {
+	leaving := r.PathValue("user_id") == middleware.GetUserID(r.Context())
+	if !h.authorizeTeam(w, r, !leaving) {
+		return
+	}
+	if err := h.teamService.RemoveMember(r.Context(), r.PathValue("team_id"), r.PathValue("user_id")); err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	response.Success(w, map[string]string{"message": "Member removed successfully"})
+}
+
+// HandleSetFormTeam: PUT /api/v1/forms/{form_id}/team
+// Shares the form with a team the user can manage forms of ({"team_id": "..."}),
+// or stops sharing it ({"team_id": ""}).
+func (h *TeamHandler) HandleSetFormTeam(w http.ResponseWriter, r *http.Request) {
+	form, err := h.formService.GetForm(r.Context(), r.PathValue("form_id"))
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	if !middleware.CanManageForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "Access denied", response.CodeForbidden)
+		return
+	}
+
+	var req struct {
+		TeamID string `json:"team_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid JSON body", response.CodeInvalidBody)
+		return
+	}
+	if req.TeamID != "" && !middleware.IsAdmin(r.Context()) {
+		role, ok := middleware.GetTeamRoles(r.Context())[req.TeamID]
+		if !ok {
+			response.NotFound(w, "Team not found")
+			return
+		}
+		if !role.CanManageForms() {
+			response.Error(w, http.StatusForbidden, "Team viewers can't share forms with the team", response.CodeForbidden)
+			return
+		}
+	}
+
+	form, err = h.teamService.SetFormTeam(r.Context(), form.PublicID, req.TeamID)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	// Unsharing can leave a team editor without the right to manage the form
+	if !middleware.CanManageForm(r.Context(), form) {
+		form = form.WithoutSecrets()
+	}
+	response.Success(w, form)
+}
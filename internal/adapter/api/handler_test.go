@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"headless_form/internal/core/domain"
 	"headless_form/internal/core/ports"
@@ -50,6 +51,61 @@ func (m *MockRepository) Settings() ports.SettingsRepository {
 	return nil // Not used in current tests
 }
 
+func (m *MockRepository) Alert() ports.AlertRepository {
+	return nil // Not used in current tests
+}
+
+func (m *MockRepository) FormToken() ports.FormTokenRepository {
+	return nil // Not used in current tests
+}
+
+func (m *MockRepository) Audit() ports.AuditRepository {
+	return &MockAuditRepository{}
+}
+
+func (m *MockRepository) Task() ports.TaskRepository {
+	return nil // Not used in handler tests
+}
+
+func (m *MockRepository) Notification() ports.NotificationRepository {
+	return nil // Not used in handler tests
+}
+
+func (m *MockRepository) Lock() ports.LockRepository {
+	return nil // Not used in handler tests
+}
+
+func (m *MockRepository) WebhookDelivery() ports.WebhookDeliveryRepository {
+	return nil // Not used in handler tests
+}
+
+func (m *MockRepository) EmailQueue() ports.EmailQueueRepository {
+	return nil // Not used in handler tests
+}
+
+func (m *MockRepository) SpamFingerprint() ports.SpamFingerprintRepository {
+	return nil // Not used in handler tests
+}
+
+func (m *MockRepository) Team() ports.TeamRepository {
+	return nil // Not used in handler tests
+}
+
+func (m *MockRepository) Comment() ports.CommentRepository {
+	return nil // Not used in handler tests
+}
+
+// MockAuditRepository discards audit entries
+type MockAuditRepository struct{}
+
+func (r *MockAuditRepository) Create(ctx context.Context, entry *domain.AuditEntry) error {
+	return nil
+}
+
+func (r *MockAuditRepository) ListByFormID(ctx context.Context, formID string, limit int) ([]*domain.AuditEntry, error) {
+	return nil, nil
+}
+
 // MockUserRepository for testing
 type MockUserRepository struct{}
 
@@ -94,6 +150,12 @@ func (r *MockFormRepository) Create(ctx context.Context, f *domain.Form) error {
 }
 
 func (r *MockFormRepository) Update(ctx context.Context, f *domain.Form) error {
+	// The public ID changes when it's rotated
+	for publicID, existing := range r.forms {
+		if existing.ID == f.ID {
+			delete(r.forms, publicID)
+		}
+	}
 	r.forms[f.PublicID] = f
 	return nil
 }
@@ -102,6 +164,24 @@ func (r *MockFormRepository) GetByPublicID(ctx context.Context, publicID string)
 	return r.forms[publicID], nil
 }
 
+func (r *MockFormRepository) GetBySlug(ctx context.Context, slug string) (*domain.Form, error) {
+	for _, f := range r.forms {
+		if f.Slug == slug {
+			return f, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *MockFormRepository) GetByPreviousPublicID(ctx context.Context, publicID string) (*domain.Form, error) {
+	for _, f := range r.forms {
+		if f.PreviousPublicID == publicID {
+			return f, nil
+		}
+	}
+	return nil, nil
+}
+
 func (r *MockFormRepository) GetByID(ctx context.Context, id string) (*domain.Form, error) {
 	for _, f := range r.forms {
 		if f.ID == id {
@@ -133,18 +213,53 @@ func (r *MockFormRepository) IncrementSubmissionCount(ctx context.Context, formI
 	return nil
 }
 
-func (r *MockFormRepository) ListPaginated(ctx context.Context, limit, offset int) ([]*domain.Form, int, error) {
-	var list []*domain.Form
+func (r *MockFormRepository) ListPurgeable(ctx context.Context, before time.Time) ([]*domain.Form, error) {
+	return nil, nil
+}
+
+func (r *MockFormRepository) ListDeleted(ctx context.Context, ownerID string) ([]*domain.Form, error) {
+	return nil, nil
+}
+
+func (r *MockFormRepository) Star(ctx context.Context, userID, formID string) error {
+	return nil
+}
+
+func (r *MockFormRepository) Unstar(ctx context.Context, userID, formID string) error {
+	return nil
+}
+
+func (r *MockFormRepository) ListStarredBy(ctx context.Context, formID string) ([]string, error) {
+	return nil, nil
+}
+
+func (r *MockFormRepository) SetOwner(ctx context.Context, formID, ownerID string) error {
 	for _, f := range r.forms {
-		list = append(list, f)
+		if f.ID == formID {
+			f.OwnerID = ownerID
+			break
+		}
 	}
-	return list, len(list), nil
+	return nil
+}
+
+func (r *MockFormRepository) SetTeam(ctx context.Context, formID, teamID string) error {
+	for _, f := range r.forms {
+		if f.ID == formID {
+			f.TeamID = teamID
+		}
+	}
+	return nil
+}
+
+func (r *MockFormRepository) ListWithRetention(ctx context.Context) ([]*domain.Form, error) {
+	return nil, nil
 }
 
-func (r *MockFormRepository) ListByOwnerPaginated(ctx context.Context, ownerID string, limit, offset int) ([]*domain.Form, int, error) {
+func (r *MockFormRepository) ListPaginated(ctx context.Context, query domain.FormListQuery, limit, offset int) ([]*domain.Form, int, error) {
 	var list []*domain.Form
 	for _, f := range r.forms {
-		if f.OwnerID == ownerID {
+		if query.OwnerID == "" || f.OwnerID == query.OwnerID {
 			list = append(list, f)
 		}
 	}
@@ -170,9 +285,79 @@ func (r *MockSubmissionRepository) GetByFormID(ctx context.Context, formID strin
 	return r.submissions[formID], nil
 }
 
-func (r *MockSubmissionRepository) GetByFormIDPaginated(ctx context.Context, formID string, limit, offset int) ([]*domain.Submission, int, error) {
+func (r *MockSubmissionRepository) EachByFormID(ctx context.Context, formID string, fn func(*domain.Submission) error) error {
+	for _, s := range r.submissions[formID] {
+		if err := fn(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *MockSubmissionRepository) GetByFormIDPaginated(ctx context.Context, formID, viewerID string, limit, offset int) ([]*domain.Submission, domain.SubmissionCounts, error) {
+	subs := r.submissions[formID]
+	return subs, domain.SubmissionCounts{Total: len(subs)}, nil
+}
+
+func (r *MockSubmissionRepository) SearchByFormID(ctx context.Context, formID, viewerID string, query domain.SubmissionListQuery, limit, offset int) ([]*domain.Submission, domain.SubmissionCounts, error) {
 	subs := r.submissions[formID]
-	return subs, len(subs), nil
+	return subs, domain.SubmissionCounts{Total: len(subs)}, nil
+}
+
+func (r *MockSubmissionRepository) UpdateData(ctx context.Context, id string, data json.RawMessage) error {
+	return nil
+}
+
+func (r *MockSubmissionRepository) DeleteMatching(ctx context.Context, formID string, filter domain.SubmissionFilter) (int, error) {
+	return 0, nil
+}
+
+func (r *MockSubmissionRepository) UpdateStatusBatch(ctx context.Context, formID string, ids []string, status domain.SubmissionStatus) (int, error) {
+	return 0, nil
+}
+
+func (r *MockSubmissionRepository) SetSpamBatch(ctx context.Context, formID string, ids []string, spam bool) (int, error) {
+	return 0, nil
+}
+
+func (r *MockSubmissionRepository) SetSpamVerdict(ctx context.Context, id string, spam domain.SpamMeta) error {
+	return nil
+}
+
+func (r *MockSubmissionRepository) DeleteBatch(ctx context.Context, formID string, ids []string) (int, error) {
+	return 0, nil
+}
+
+func (r *MockSubmissionRepository) DeleteTestBefore(ctx context.Context, before time.Time) ([]domain.Tombstone, error) {
+	return nil, nil
+}
+
+func (r *MockSubmissionRepository) ListExpired(ctx context.Context, formID string, before time.Time, limit int) ([]*domain.Submission, error) {
+	return nil, nil
+}
+
+func (r *MockSubmissionRepository) Anonymize(ctx context.Context, id string, data json.RawMessage, meta domain.SubmissionMeta) error {
+	return nil
+}
+
+func (r *MockSubmissionRepository) RecordView(ctx context.Context, submissionID, userID string, at time.Time) error {
+	return nil
+}
+
+func (r *MockSubmissionRepository) ListViews(ctx context.Context, submissionID string) ([]domain.SubmissionView, error) {
+	return nil, nil
+}
+
+func (r *MockSubmissionRepository) Link(ctx context.Context, link *domain.SubmissionLink) error {
+	return nil
+}
+
+func (r *MockSubmissionRepository) Unlink(ctx context.Context, submissionID, linkedID string) (bool, error) {
+	return false, nil
+}
+
+func (r *MockSubmissionRepository) ListLinks(ctx context.Context, submissionID string) ([]domain.SubmissionLink, error) {
+	return nil, nil
 }
 
 func (r *MockSubmissionRepository) UpdateStatus(ctx context.Context, id string, status domain.SubmissionStatus) error {
@@ -189,14 +374,22 @@ type MockStatsRepository struct {
 	submissions map[string][]*domain.Submission
 }
 
-func (r *MockStatsRepository) GetDashboardStats(ctx context.Context) (*domain.DashboardStats, error) {
+func (r *MockStatsRepository) GetDashboardStats(ctx context.Context, loc *time.Location) (*domain.DashboardStats, error) {
 	return &domain.DashboardStats{TotalForms: len(r.forms)}, nil
 }
 
-func (r *MockStatsRepository) GetFormStats(ctx context.Context, formID string) (*domain.FormStats, error) {
+func (r *MockStatsRepository) GetFormStats(ctx context.Context, formID string, loc *time.Location) (*domain.FormStats, error) {
 	return &domain.FormStats{FormID: formID}, nil
 }
 
+func (r *MockStatsRepository) GetFormDailyStats(ctx context.Context, formID string, from, to time.Time, loc *time.Location) ([]domain.DailyFormStats, error) {
+	return nil, nil
+}
+
+func (r *MockStatsRepository) GetFormActivity(ctx context.Context, formID string, since time.Time) (*domain.FormActivity, error) {
+	return &domain.FormActivity{Submissions: len(r.submissions[formID])}, nil
+}
+
 // Tests
 func TestHandleCreateForm(t *testing.T) {
 	repo := NewMockRepository()
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"headless_form/internal/adapter/api/response"
+	"headless_form/internal/adapter/middleware"
+	"headless_form/internal/core/domain"
+	"headless_form/internal/core/service"
+)
+
+// FormTokenHandler manages read-only API tokens scoped to a form
+type FormTokenHandler struct {
+	tokenService *service.FormTokenService
+	formService  *service.FormService
+}
+
+// NewFormTokenHandler creates a new form token handler
+func NewFormTokenHandler(tokenService *service.FormTokenService, formService *service.FormService) *FormTokenHandler {
+	return &FormTokenHandler{tokenService: tokenService, formService: formService}
+}
+
+// RegisterRoutes registers token management routes (auth required)
+func (h *FormTokenHandler) RegisterRoutes(mux *http.ServeMux, authMiddleware func(http.Handler) http.Handler) {
+	mux.Handle("GET /api/v1/forms/{form_id}/tokens", authMiddleware(http.HandlerFunc(h.HandleListTokens)))
+	mux.Handle("POST /api/v1/forms/{form_id}/tokens", authMiddleware(http.HandlerFunc(h.HandleCreateToken)))
+	mux.Handle("DELETE /api/v1/forms/{form_id}/tokens/{token_id}", authMiddleware(http.HandlerFunc(h.HandleRevokeToken)))
+}
+
+// authorizeForm loads the form and checks the current user can manage it.
+// Writes the error response and returns false if not.
+func (h *FormTokenHandler) authorizeForm(w http.ResponseWriter, r *http.Request) bool {
+	form, err := h.formService.GetForm(r.Context(), r.PathValue("form_id"))
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return false
+		}
+		response.HandleError(w, err)
+		return false
+	}
+	if !middleware.CanManageForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "Access denied", response.CodeForbidden)
+		return false
+	}
+	return true
+}
+
+// HandleListTokens: GET /api/v1/forms/{form_id}/tokens
+func (h *FormTokenHandler) HandleListTokens(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeForm(w, r) {
+		return
+	}
+
+	tokens, err := h.tokenService.ListTokens(r.Context(), r.PathValue("form_id"))
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	if tokens == nil {
+		tokens = []*domain.FormToken{}
+	}
+
+	response.Success(w, map[string]interface{}{
+		"tokens": tokens,
+	})
+}
+
+// HandleCreateToken: POST /api/v1/forms/{form_id}/tokens
+// The plaintext token is only included in this response.
+func (h *FormTokenHandler) HandleCreateToken(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeForm(w, r) {
+		return
+	}
+
+	var req struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid JSON body", response.CodeInvalidBody)
+		return
+	}
+
+	token, plaintext, err := h.tokenService.CreateToken(r.Context(), r.PathValue("form_id"), req.Name, middleware.GetUserID(r.Context()))
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Created(w, map[string]interface{}{
+		"token":   plaintext,
+		"details": token,
+	})
+}
+
+// HandleRevokeToken: DELETE /api/v1/forms/{form_id}/tokens/{token_id}
+func (h *FormTokenHandler) HandleRevokeToken(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeForm(w, r) {
+		return
+	}
+
+	if err := h.tokenService.RevokeToken(r.Context(), r.PathValue("form_id"), r.PathValue("token_id")); err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+
+	response.Success(w, map[string]string{"message": "Token revoked successfully"})
+}
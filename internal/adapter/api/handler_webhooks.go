@@ -0,0 +1,138 @@
+package api
+
+import (
+	"net/http"
+
+	"headless_form/internal/adapter/api/response"
+	"headless_form/internal/adapter/middleware"
+	"headless_form/internal/core/domain"
+	"headless_form/internal/core/service"
+)
+
+// WebhookDeliveryHandler exposes a form's webhook delivery log
+type WebhookDeliveryHandler struct {
+	deliveryService   *service.WebhookDeliveryService
+	formService       *service.FormService
+	submissionService *service.SubmissionService
+}
+
+// NewWebhookDeliveryHandler creates a new webhook delivery handler. submService's export
+// settings decide who may see and replay payloads.
+func NewWebhookDeliveryHandler(deliveryService *service.WebhookDeliveryService, formService *service.FormService, submService *service.SubmissionService) *WebhookDeliveryHandler {
+	return &WebhookDeliveryHandler{deliveryService: deliveryService, formService: formService, submissionService: submService}
+}
+
+// RegisterRoutes registers webhook delivery routes (auth required)
+func (h *WebhookDeliveryHandler) RegisterRoutes(mux *http.ServeMux, authMiddleware func(http.Handler) http.Handler) {
+	mux.Handle("GET /api/v1/forms/{form_id}/webhook-deliveries", authMiddleware(http.HandlerFunc(h.HandleListDeliveries)))
+	mux.Handle("GET /api/v1/forms/{form_id}/webhook-deliveries/{delivery_id}", authMiddleware(http.HandlerFunc(h.HandleGetDelivery)))
+	mux.Handle("POST /api/v1/forms/{form_id}/webhook-deliveries/{delivery_id}/replay", authMiddleware(http.HandlerFunc(h.HandleReplayDelivery)))
+}
+
+// authorizeForm loads the form and checks the current user can manage it.
+// Writes the error response and returns nil if not.
+func (h *WebhookDeliveryHandler) authorizeForm(w http.ResponseWriter, r *http.Request) *domain.Form {
+	form, err := h.formService.GetForm(r.Context(), r.PathValue("form_id"))
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return nil
+		}
+		response.HandleError(w, err)
+		return nil
+	}
+	if !middleware.CanManageForm(r.Context(), form) {
+		response.Error(w, http.StatusForbidden, "Access denied", response.CodeForbidden)
+		return nil
+	}
+	return form
+}
+
+// readsPayloads reports whether the current user sees the form's submissions in full.
+// Payloads hold submissions as the webhook sent them, transformed or not, so they can't
+// be masked: users whose export profile masks submissions, or who may not read them,
+// don't get them.
+func (h *WebhookDeliveryHandler) readsPayloads(r *http.Request, form *domain.Form) (bool, error) {
+	settings, err := h.submissionService.ExportSettings(r.Context())
+	if err != nil {
+		return false, err
+	}
+	profile, err := settings.Access(middleware.ExportRole(r.Context(), form))
+	return err == nil && profile == nil, nil
+}
+
+// HandleListDeliveries: GET /api/v1/forms/{form_id}/webhook-deliveries?page=1&limit=20
+// Returns the form's deliveries newest first, without their payloads.
+func (h *WebhookDeliveryHandler) HandleListDeliveries(w http.ResponseWriter, r *http.Request) {
+	if h.authorizeForm(w, r) == nil {
+		return
+	}
+	page, limit := parsePage(r, 20, 100)
+
+	deliveries, total, err := h.deliveryService.ListDeliveries(r.Context(), r.PathValue("form_id"), page, limit)
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	if deliveries == nil {
+		deliveries = []*domain.WebhookDelivery{}
+	}
+
+	response.Paginated(w, r, "deliveries", deliveries, page, limit, total, nil)
+}
+
+// HandleGetDelivery: GET /api/v1/forms/{form_id}/webhook-deliveries/{delivery_id}
+// The payload is left out for users who don't see the form's submissions in full.
+func (h *WebhookDeliveryHandler) HandleGetDelivery(w http.ResponseWriter, r *http.Request) {
+	form := h.authorizeForm(w, r)
+	if form == nil {
+		return
+	}
+	delivery, err := h.deliveryService.GetDelivery(r.Context(), r.PathValue("form_id"), r.PathValue("delivery_id"))
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	full, err := h.readsPayloads(r, form)
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+	if !full {
+		delivery.Payload = nil
+	}
+	response.Success(w, delivery)
+}
+
+// HandleReplayDelivery: POST /api/v1/forms/{form_id}/webhook-deliveries/{delivery_id}/replay
+// Sends the delivery's payload again and returns the new delivery, attempted in the background.
+// Refused for users who may not see the payload, as it goes to the form's current webhook URL.
+func (h *WebhookDeliveryHandler) HandleReplayDelivery(w http.ResponseWriter, r *http.Request) {
+	form := h.authorizeForm(w, r)
+	if form == nil {
+		return
+	}
+	full, err := h.readsPayloads(r, form)
+	if err != nil {
+		response.HandleError(w, err)
+		return
+	}
+	if !full {
+		response.HandleDomainError(w, domain.ErrExportNotAllowed)
+		return
+	}
+	delivery, err := h.deliveryService.Replay(r.Context(), r.PathValue("form_id"), r.PathValue("delivery_id"))
+	if err != nil {
+		if response.HandleDomainError(w, err) {
+			return
+		}
+		response.HandleError(w, err)
+		return
+	}
+	response.Accepted(w, delivery)
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"sync"
+	"time"
+)
+
+// IdempotencyHeader lets clients retry a submission without it being stored twice:
+// a repeated key for the same form returns the submission the first request created
+const IdempotencyHeader = "Idempotency-Key"
+
+const (
+	idempotencyTTL       = 24 * time.Hour // How long a key is remembered
+	maxIdempotencyKeyLen = 255
+)
+
+// idempotencyKeys remembers which submission each key created. Like rate limiting
+// state it's kept in memory, which covers client retries but not restarts.
+type idempotencyKeys struct {
+	mu      sync.Mutex
+	seen    map[string]idempotencyEntry // form ID + key -> submission
+	sweptAt time.Time
+	now     func() time.Time
+}
+
+type idempotencyEntry struct {
+	submissionID string
+	at           time.Time
+}
+
+func newIdempotencyKeys() *idempotencyKeys {
+	return &idempotencyKeys{seen: make(map[string]idempotencyEntry), now: time.Now}
+}
+
+// Get returns the submission created with the key, or "" if it's new or expired
+func (k *idempotencyKeys) Get(formID, key string) string {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	entry, ok := k.seen[formID+"\x00"+key]
+	if !ok || k.now().Sub(entry.at) >= idempotencyTTL {
+		return ""
+	}
+	return entry.submissionID
+}
+
+// Put records the submission created with the key, dropping expired keys hourly
+func (k *idempotencyKeys) Put(formID, key, submissionID string) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	now := k.now()
+	if now.Sub(k.sweptAt) >= time.Hour {
+		for id, entry := range k.seen {
+			if now.Sub(entry.at) >= idempotencyTTL {
+				delete(k.seen, id)
+			}
+		}
+		k.sweptAt = now
+	}
+	k.seen[formID+"\x00"+key] = idempotencyEntry{submissionID: submissionID, at: now}
+}
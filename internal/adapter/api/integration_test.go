@@ -4,14 +4,39 @@
 package api_test
 
 import (
+	"archive/zip"
+	"bufio"
 	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
 	"net/http"
+	"net/http/cookiejar"
 	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"headless_form/internal/adapter/api"
+	"headless_form/internal/adapter/captcha"
+	"headless_form/internal/adapter/filestore"
+	"headless_form/internal/adapter/httpstats"
+	"headless_form/internal/adapter/linkcheck"
+	"headless_form/internal/adapter/middleware"
+	"headless_form/internal/adapter/monitor"
+	"headless_form/internal/adapter/oauth"
 	"headless_form/internal/adapter/storage/sqlite"
+	"headless_form/internal/core/domain"
 	"headless_form/internal/core/service"
 )
 
@@ -21,6 +46,8 @@ type TestServer struct {
 	Store  *sqlite.Store
 	Token  string // JWT token for authenticated requests
 	Mux    *http.ServeMux
+	Router *api.Router
+	Auth   func(http.Handler) http.Handler // Middleware protected routes were registered with
 }
 
 // NewTestServer creates a new test server with in-memory database
@@ -36,6 +63,7 @@ func NewTestServer(t *testing.T) *TestServer {
 	// Create services
 	formService := service.NewFormService(store)
 	submService := service.NewSubmissionService(store)
+	submService.SetFileStorage(filestore.NewLocal(t.TempDir()))
 	statsService := service.NewStatsService(store)
 
 	// Create router and mux
@@ -54,9 +82,37 @@ func NewTestServer(t *testing.T) *TestServer {
 		Server: server,
 		Store:  store,
 		Mux:    mux,
+		Router: router,
 	}
 }
 
+// NewUserTestServer is like NewTestServer, with user stored and every request made as them
+func NewUserTestServer(t *testing.T, user *domain.User) *TestServer {
+	t.Helper()
+
+	store, err := sqlite.New(":memory:")
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	if err := store.User().Create(context.Background(), user); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	asUser := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), middleware.UserIDKey, user.ID)
+			ctx = context.WithValue(ctx, middleware.RoleKey, string(user.Role))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+
+	router := api.NewRouter(service.NewFormService(store), service.NewSubmissionService(store), service.NewStatsService(store))
+	mux := http.NewServeMux()
+	router.RegisterPublicRoutes(mux, asUser)
+	router.RegisterProtectedRoutes(mux, asUser)
+
+	return &TestServer{Server: httptest.NewServer(mux), Store: store, Mux: mux, Router: router, Auth: asUser}
+}
+
 // Close cleans up the test server
 func (ts *TestServer) Close() {
 	ts.Server.Close()
@@ -277,19 +333,61 @@ func TestDeleteForm(t *testing.T) {
 	data := createResult["data"].(map[string]interface{})
 	publicID := data["public_id"].(string)
 
-	// Delete the form
-	deleteResp := ts.Request(t, "DELETE", "/api/v1/forms/"+publicID, nil)
+	// First step only issues a confirmation token
+	requestResp := ts.Request(t, "DELETE", "/api/v1/forms/"+publicID, nil)
+	if requestResp.StatusCode != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", requestResp.StatusCode)
+	}
+	var requestResult map[string]interface{}
+	ParseResponse(t, requestResp, &requestResult)
+	token := requestResult["data"].(map[string]interface{})["confirm_token"].(string)
+
+	// A wrong token is rejected
+	badResp := ts.Request(t, "DELETE", "/api/v1/forms/"+publicID+"?confirm=wrong", nil)
+	badResp.Body.Close()
+	if badResp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 for wrong token, got %d", badResp.StatusCode)
+	}
 
+	// Confirm the delete
+	deleteResp := ts.Request(t, "DELETE", "/api/v1/forms/"+publicID+"?confirm="+token, nil)
+	deleteResp.Body.Close()
 	if deleteResp.StatusCode != http.StatusOK {
 		t.Errorf("expected 200, got %d", deleteResp.StatusCode)
 	}
 
-	// Verify it's deleted
+	// Verify it's deleted and no longer accepts submissions
 	getResp := ts.Request(t, "GET", "/api/v1/forms/"+publicID, nil)
-	defer getResp.Body.Close()
+	getResp.Body.Close()
 	if getResp.StatusCode != http.StatusNotFound {
 		t.Errorf("expected 404 after delete, got %d", getResp.StatusCode)
 	}
+	submitResp := ts.Request(t, "POST", "/api/v1/submissions/"+publicID, map[string]interface{}{"email": "a@example.com"})
+	submitResp.Body.Close()
+	if submitResp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404 when submitting to deleted form, got %d", submitResp.StatusCode)
+	}
+
+	// The form is listed in the trash
+	trashResp := ts.Request(t, "GET", "/api/v1/forms/trash", nil)
+	var trashResult map[string]interface{}
+	ParseResponse(t, trashResp, &trashResult)
+	trashed := trashResult["data"].(map[string]interface{})["forms"].([]interface{})
+	if len(trashed) != 1 || trashed[0].(map[string]interface{})["public_id"] != publicID {
+		t.Errorf("expected deleted form in trash, got %v", trashed)
+	}
+
+	// Restore during the grace period
+	restoreResp := ts.Request(t, "POST", "/api/v1/forms/"+publicID+"/restore", nil)
+	restoreResp.Body.Close()
+	if restoreResp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 on restore, got %d", restoreResp.StatusCode)
+	}
+	getResp = ts.Request(t, "GET", "/api/v1/forms/"+publicID, nil)
+	defer getResp.Body.Close()
+	if getResp.StatusCode != http.StatusOK {
+		t.Errorf("expected 200 after restore, got %d", getResp.StatusCode)
+	}
 }
 
 // =============================================================================
@@ -332,6 +430,207 @@ func TestSubmitForm(t *testing.T) {
 	}
 }
 
+func TestSubmitFormDryRun(t *testing.T) {
+	ts := NewTestServer(t)
+	defer ts.Close()
+
+	createResp := ts.Request(t, "POST", "/api/v1/forms", map[string]interface{}{
+		"name": "Dry Run Form",
+	})
+
+	var createResult map[string]interface{}
+	ParseResponse(t, createResp, &createResult)
+
+	data := createResult["data"].(map[string]interface{})
+	publicID := data["public_id"].(string)
+
+	dryResp := ts.Request(t, "POST", "/api/v1/submissions/"+publicID+"?dry_run=true", map[string]interface{}{})
+	if dryResp.StatusCode != http.StatusOK {
+		t.Errorf("expected 200, got %d", dryResp.StatusCode)
+	}
+	_ = dryResp.Body.Close()
+
+	// Nothing should have been stored
+	listResp := ts.Request(t, "GET", "/api/v1/forms/"+publicID+"/submissions", nil)
+	var listResult map[string]interface{}
+	ParseResponse(t, listResp, &listResult)
+
+	listData := listResult["data"].(map[string]interface{})
+	if subs, _ := listData["submissions"].([]interface{}); len(subs) != 0 {
+		t.Errorf("expected no submissions after dry run, got %d", len(subs))
+	}
+}
+
+func TestSubmitFormDryRun_Checks(t *testing.T) {
+	ts := NewTestServer(t)
+	defer ts.Close()
+
+	create := func(body map[string]interface{}) string {
+		var created map[string]interface{}
+		ParseResponse(t, ts.Request(t, "POST", "/api/v1/forms", body), &created)
+		return created["data"].(map[string]interface{})["public_id"].(string)
+	}
+	var restricted map[string]interface{}
+	ParseResponse(t, ts.Request(t, "PUT", "/api/v1/form-specs/restricted", map[string]interface{}{"name": "Restricted", "allowed_origins": []string{"https://site.example"}}), &restricted)
+	restrictedID := restricted["data"].(map[string]interface{})["form"].(map[string]interface{})["public_id"].(string)
+	keyID := create(map[string]interface{}{"name": "Keyed", "access_mode": "with_key", "submission_key": "k3y"})
+	privateID := create(map[string]interface{}{"name": "Private", "access_mode": "private", "submission_key": "s3cret"})
+
+	dryRun := func(formID, origin, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/v1/submissions/"+formID+"?dry_run=true", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		w := httptest.NewRecorder()
+		ts.Mux.ServeHTTP(w, req)
+		return w
+	}
+
+	// Dry runs face the same origin and access checks as submissions
+	if w := dryRun(restrictedID, "https://evil.example", `{}`); w.Code != http.StatusForbidden || !strings.Contains(w.Body.String(), "ORIGIN_NOT_ALLOWED") {
+		t.Errorf("expected 403 ORIGIN_NOT_ALLOWED, got %d %s", w.Code, w.Body.String())
+	}
+	if w := dryRun(restrictedID, "https://site.example", `{}`); w.Code != http.StatusOK || w.Header().Get("Access-Control-Allow-Origin") != "https://site.example" {
+		t.Errorf("expected 200 with CORS headers for an allowed origin, got %d %v", w.Code, w.Header())
+	}
+	if w := dryRun(keyID, "", `{}`); w.Code == http.StatusOK {
+		t.Errorf("expected a dry run without the submission key refused, got %d", w.Code)
+	}
+	if w := dryRun(keyID, "", `{"_submission_key":"k3y"}`); w.Code != http.StatusOK {
+		t.Errorf("expected 200 with the submission key, got %d %s", w.Code, w.Body.String())
+	}
+	if w := dryRun(privateID, "", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 for a private form, got %d %s", w.Code, w.Body.String())
+	}
+
+	// The heartbeat monitor gets past them like a client would
+	formService := service.NewFormService(ts.Store)
+	checks := monitor.NewService(ts.Server.URL, formService.ListForms, formService.GetForm)
+	checks.RunOnce(context.Background())
+	for _, formID := range []string{restrictedID, keyID, privateID} {
+		if status := checks.Status(formID); status.State != monitor.StateUp {
+			t.Errorf("expected form %s up, got %+v", formID, status)
+		}
+	}
+}
+
+func TestSubmitResponseConfig(t *testing.T) {
+	ts := NewTestServer(t)
+	defer ts.Close()
+
+	createResp := ts.Request(t, "POST", "/api/v1/forms", map[string]interface{}{"name": "Bare Form"})
+	var createResult map[string]interface{}
+	ParseResponse(t, createResp, &createResult)
+	publicID := createResult["data"].(map[string]interface{})["public_id"].(string)
+
+	submit := func(accept string) (int, map[string]interface{}) {
+		req := httptest.NewRequest("POST", "/api/v1/submissions/"+publicID, strings.NewReader(`{"email":"a@example.com"}`))
+		req.Header.Set("Content-Type", "application/json")
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		w := httptest.NewRecorder()
+		ts.Mux.ServeHTTP(w, req)
+		var body map[string]interface{}
+		_ = json.Unmarshal(w.Body.Bytes(), &body)
+		return w.Code, body
+	}
+
+	// Enveloped 201 by default, bare on request
+	if code, body := submit(""); code != http.StatusCreated || body["status"] != "success" {
+		t.Fatalf("expected enveloped 201, got %d %v", code, body)
+	}
+	if code, body := submit("application/json; envelope=false"); code != http.StatusCreated || body["form_id"] == nil || body["status"] == "success" {
+		t.Fatalf("expected bare 201, got %d %v", code, body)
+	}
+
+	if resp := ts.Request(t, "PUT", "/api/v1/forms/"+publicID+"/submit-response", map[string]interface{}{"status": 202}); resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for status 202, got %d", resp.StatusCode)
+	}
+	if resp := ts.Request(t, "PUT", "/api/v1/forms/"+publicID+"/submit-response", map[string]interface{}{"raw": true, "status": 200}); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	if code, body := submit(""); code != http.StatusOK || body["form_id"] == nil || body["status"] == "success" {
+		t.Fatalf("expected bare 200, got %d %v", code, body)
+	}
+	if code, body := submit("application/json;envelope=true"); code != http.StatusOK || body["status"] != "success" {
+		t.Fatalf("expected enveloped 200, got %d %v", code, body)
+	}
+
+	if resp := ts.Request(t, "PUT", "/api/v1/forms/"+publicID+"/submit-response", map[string]interface{}{"status": 204}); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	req := httptest.NewRequest("POST", "/api/v1/submissions/"+publicID, strings.NewReader(`{"email":"b@example.com"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	ts.Mux.ServeHTTP(w, req)
+	if w.Code != http.StatusNoContent || w.Body.Len() != 0 {
+		t.Fatalf("expected empty 204, got %d %q", w.Code, w.Body.String())
+	}
+
+	// Errors keep the envelope either way
+	req = httptest.NewRequest("POST", "/api/v1/submissions/missing", strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json; envelope=false")
+	w = httptest.NewRecorder()
+	ts.Mux.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound || !strings.Contains(w.Body.String(), `"status":"error"`) {
+		t.Fatalf("expected enveloped 404, got %d %s", w.Code, w.Body.String())
+	}
+	if resp := ts.Request(t, "DELETE", "/api/v1/forms/"+publicID+"/submit-response", nil); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	if code, _ := submit(""); code != http.StatusCreated {
+		t.Fatalf("expected default 201 after reset, got %d", code)
+	}
+}
+
+func TestSubmitPipelineCustomStage(t *testing.T) {
+	ts := NewTestServer(t)
+	defer ts.Close()
+
+	// Reject submissions without a "token" field before they are stored
+	ok := ts.Router.SubmitPipeline().InsertBefore(api.StagePersist, api.SubmissionStage{
+		Name: "token",
+		Run: func(c *api.SubmissionContext) error {
+			if c.Data["token"] == nil {
+				return &api.StageError{Status: http.StatusForbidden, Message: "captcha required", Code: "CAPTCHA_REQUIRED"}
+			}
+			return nil
+		},
+	})
+	if !ok {
+		t.Fatal("expected persist stage to exist")
+	}
+
+	stages := ts.Router.SubmitPipeline().Stages()
+	for i, name := range stages {
+		if name == "token" && stages[i+1] != api.StagePersist {
+			t.Errorf("expected token before persist, got %v", stages)
+		}
+	}
+
+	createResp := ts.Request(t, "POST", "/api/v1/forms", map[string]interface{}{
+		"name": "Pipeline Form",
+	})
+	var createResult map[string]interface{}
+	ParseResponse(t, createResp, &createResult)
+	publicID := createResult["data"].(map[string]interface{})["public_id"].(string)
+
+	resp := ts.Request(t, "POST", "/api/v1/submissions/"+publicID, map[string]interface{}{"email": "a@b.com"})
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("expected 403, got %d", resp.StatusCode)
+	}
+	_ = resp.Body.Close()
+
+	resp = ts.Request(t, "POST", "/api/v1/submissions/"+publicID, map[string]interface{}{"email": "a@b.com", "token": "x"})
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("expected 201, got %d", resp.StatusCode)
+	}
+	_ = resp.Body.Close()
+}
+
 func TestListSubmissions(t *testing.T) {
 	ts := NewTestServer(t)
 	defer ts.Close()
@@ -370,130 +669,4448 @@ func TestListSubmissions(t *testing.T) {
 	if len(submissions) != 3 {
 		t.Errorf("expected 3 submissions, got %d", len(submissions))
 	}
+	if counts := listData["counts"].(map[string]interface{}); counts["unread"].(float64) != 3 {
+		t.Errorf("expected 3 unread, got %v", counts)
+	}
 }
 
-// =============================================================================
-// Stats Tests
-// =============================================================================
+func TestSubmissionStream(t *testing.T) {
+	plain := NewTestServer(t)
+	defer plain.Close()
+	var created map[string]interface{}
+	ParseResponse(t, plain.Request(t, "POST", "/api/v1/forms", map[string]interface{}{"name": "No Feed"}), &created)
+	plainID := created["data"].(map[string]interface{})["public_id"].(string)
+	if resp := plain.Request(t, "GET", "/api/v1/forms/"+plainID+"/submissions/stream", nil); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404 without a feed, got %d", resp.StatusCode)
+	}
 
-func TestDashboardStats(t *testing.T) {
-	ts := NewTestServer(t)
+	store, err := sqlite.New(":memory:")
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	feed := service.NewSubmissionFeed()
+	submService := service.NewSubmissionService(store)
+	submService.SetFeed(feed)
+	router := api.NewRouter(service.NewFormService(store), submService, service.NewStatsService(store))
+	router.SetSubmissionFeed(feed)
+	mux := http.NewServeMux()
+	noAuth := func(h http.Handler) http.Handler { return h }
+	router.RegisterPublicRoutes(mux, noAuth)
+	router.RegisterProtectedRoutes(mux, noAuth)
+	ts := &TestServer{Server: httptest.NewServer(mux), Store: store, Mux: mux, Router: router}
 	defer ts.Close()
 
-	// Create some data
-	createResp := ts.Request(t, "POST", "/api/v1/forms", map[string]interface{}{
-		"name": "Stats Test Form",
-	})
-
-	var createResult map[string]interface{}
-	ParseResponse(t, createResp, &createResult)
-
-	data := createResult["data"].(map[string]interface{})
-	publicID := data["public_id"].(string)
-
-	ts.Request(t, "POST", "/api/v1/submissions/"+publicID, map[string]interface{}{
-		"name": "Test User",
-	})
-
-	// Get stats
-	resp := ts.Request(t, "GET", "/api/v1/stats", nil)
+	ParseResponse(t, ts.Request(t, "POST", "/api/v1/forms", map[string]interface{}{"name": "Live"}), &created)
+	publicID := created["data"].(map[string]interface{})["public_id"].(string)
+	ParseResponse(t, ts.Request(t, "POST", "/api/v1/forms", map[string]interface{}{"name": "Other"}), &created)
+	otherID := created["data"].(map[string]interface{})["public_id"].(string)
 
-	if resp.StatusCode != http.StatusOK {
-		t.Errorf("expected 200, got %d", resp.StatusCode)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", ts.Server.URL+"/api/v1/forms/"+publicID+"/submissions/stream", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); resp.StatusCode != http.StatusOK || ct != "text/event-stream" {
+		t.Fatalf("expected an event stream, got %d %q", resp.StatusCode, ct)
+	}
+	events := bufio.NewReader(resp.Body)
+	if line, _ := events.ReadString('\n'); line != ": connected\n" {
+		t.Fatalf("expected the stream to open, got %q", line)
 	}
 
-	var result map[string]interface{}
-	ParseResponse(t, resp, &result)
+	ts.Request(t, "POST", "/api/v1/submissions/"+otherID, map[string]interface{}{"message": "elsewhere"})
+	var submitted map[string]interface{}
+	ParseResponse(t, ts.Request(t, "POST", "/api/v1/submissions/"+publicID, map[string]interface{}{"message": "live"}), &submitted)
+	subID := submitted["data"].(map[string]interface{})["id"].(string)
 
-	statsData := result["data"].(map[string]interface{})
-	if statsData["total_forms"] == nil {
-		t.Error("expected total_forms in stats")
+	var event []string
+	for len(event) < 3 {
+		line, err := events.ReadString('\n')
+		if err != nil {
+			t.Fatalf("stream ended: %v", err)
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			event = append(event, line)
+		}
+	}
+	if event[0] != "id: "+subID || event[1] != "event: submission" {
+		t.Fatalf("expected the submission event, got %q", event)
+	}
+	var sub map[string]interface{}
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(event[2], "data: ")), &sub); err != nil {
+		t.Fatalf("decode event data: %v", err)
+	}
+	if sub["id"] != subID || sub["status"] != "unread" || !strings.Contains(fmt.Sprint(sub["data"]), "live") {
+		t.Errorf("unexpected submission %v", sub)
 	}
 }
 
-// =============================================================================
-// Error Handling Tests
-// =============================================================================
-
-func TestFormNotFound(t *testing.T) {
+func TestSearchSubmissions(t *testing.T) {
 	ts := NewTestServer(t)
 	defer ts.Close()
 
-	resp := ts.Request(t, "GET", "/api/v1/forms/nonexistent-id", nil)
-	defer resp.Body.Close()
+	var created map[string]interface{}
+	ParseResponse(t, ts.Request(t, "POST", "/api/v1/forms", map[string]interface{}{"name": "Searchable"}), &created)
+	publicID := created["data"].(map[string]interface{})["public_id"].(string)
+	for _, data := range []map[string]interface{}{
+		{"name": "Ada", "plan": "pro"},
+		{"name": "Grace", "plan": "free"},
+		{"name": "Adam", "plan": "free"},
+	} {
+		ts.Request(t, "POST", "/api/v1/submissions/"+publicID, data).Body.Close()
+	}
 
-	if resp.StatusCode != http.StatusNotFound {
-		t.Errorf("expected 404, got %d", resp.StatusCode)
+	list := func(params string) []string {
+		t.Helper()
+		resp := ts.Request(t, "GET", "/api/v1/forms/"+publicID+"/submissions?"+params, nil)
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			t.Fatalf("%s: expected 200, got %d", params, resp.StatusCode)
+		}
+		var result map[string]interface{}
+		ParseResponse(t, resp, &result)
+		var names []string
+		subs, _ := result["data"].(map[string]interface{})["submissions"].([]interface{})
+		for _, sub := range subs {
+			names = append(names, sub.(map[string]interface{})["data"].(map[string]interface{})["name"].(string))
+		}
+		return names
+	}
+
+	if got := list("q=ada"); len(got) != 2 {
+		t.Errorf("q=ada: expected Ada and Adam, got %v", got)
+	}
+	if got := list("q=ada&field.plan=free"); len(got) != 1 || got[0] != "Adam" {
+		t.Errorf("expected Adam, got %v", got)
+	}
+	today := time.Now().UTC().Format("2006-01-02")
+	if got := list("from=" + today + "&to=" + today + "&tz=UTC"); len(got) != 3 {
+		t.Errorf("expected today's 3 submissions, got %v", got)
+	}
+	if got := list("to=2000-01-01"); len(got) != 0 {
+		t.Errorf("expected none before 2000, got %v", got)
+	}
+
+	for _, params := range []string{"status=archived", "from=yesterday", "from=2026-02-01&to=2026-01-01"} {
+		resp := ts.Request(t, "GET", "/api/v1/forms/"+publicID+"/submissions?"+params, nil)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", params, resp.StatusCode)
+		}
 	}
 }
 
-func TestInvalidFormCreate(t *testing.T) {
+func TestDeleteSubmissionsByFilter(t *testing.T) {
 	ts := NewTestServer(t)
 	defer ts.Close()
 
-	// Empty name should fail validation
-	resp := ts.Request(t, "POST", "/api/v1/forms", map[string]interface{}{
-		"name": "", // Empty name
-	})
-	defer resp.Body.Close()
+	createResp := ts.Request(t, "POST", "/api/v1/forms", map[string]interface{}{"name": "Bulk Delete Form"})
+	var createResult map[string]interface{}
+	ParseResponse(t, createResp, &createResult)
+	publicID := createResult["data"].(map[string]interface{})["public_id"].(string)
+
+	var subID string
+	for i := 0; i < 3; i++ {
+		resp := ts.Request(t, "POST", "/api/v1/submissions/"+publicID, map[string]interface{}{"n": i})
+		var result map[string]interface{}
+		ParseResponse(t, resp, &result)
+		subID = result["data"].(map[string]interface{})["id"].(string)
+	}
+	ts.Request(t, "PUT", "/api/v1/submissions/"+subID+"/read", nil).Body.Close()
 
+	// A bare DELETE is rejected
+	resp := ts.Request(t, "DELETE", "/api/v1/forms/"+publicID+"/submissions", nil)
 	if resp.StatusCode != http.StatusBadRequest {
-		t.Errorf("expected 400, got %d", resp.StatusCode)
+		t.Errorf("expected 400 without filters, got %d", resp.StatusCode)
 	}
-}
+	resp.Body.Close()
 
-func TestSubmitToNonexistentForm(t *testing.T) {
-	ts := NewTestServer(t)
-	defer ts.Close()
+	resp = ts.Request(t, "DELETE", "/api/v1/forms/"+publicID+"/submissions?status=unread&before=2999-01-01", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	var result map[string]interface{}
+	ParseResponse(t, resp, &result)
+	if deleted := result["data"].(map[string]interface{})["deleted"].(float64); deleted != 2 {
+		t.Errorf("expected 2 deleted, got %v", deleted)
+	}
 
-	resp := ts.Request(t, "POST", "/api/v1/submissions/nonexistent-form", map[string]interface{}{
-		"name": "Test",
-	})
-	defer resp.Body.Close()
+	listResp := ts.Request(t, "GET", "/api/v1/forms/"+publicID+"/submissions", nil)
+	var listResult map[string]interface{}
+	ParseResponse(t, listResp, &listResult)
+	if subs := listResult["data"].(map[string]interface{})["submissions"].([]interface{}); len(subs) != 1 {
+		t.Errorf("expected the read submission to remain, got %d", len(subs))
+	}
 
-	if resp.StatusCode != http.StatusNotFound {
-		t.Errorf("expected 404, got %d", resp.StatusCode)
+	auditResp := ts.Request(t, "GET", "/api/v1/forms/"+publicID+"/audit", nil)
+	var auditResult map[string]interface{}
+	ParseResponse(t, auditResp, &auditResult)
+	entries := auditResult["data"].(map[string]interface{})["entries"].([]interface{})
+	if len(entries) != 1 || entries[0].(map[string]interface{})["action"] != "submissions.bulk_deleted" {
+		t.Errorf("expected one audit entry, got %v", entries)
 	}
 }
 
-// =============================================================================
-// Pagination Tests
-// =============================================================================
-
-func TestFormsPagination(t *testing.T) {
+func TestBulkSubmissionActions(t *testing.T) {
 	ts := NewTestServer(t)
 	defer ts.Close()
 
-	// Create 5 forms
-	for i := 0; i < 5; i++ {
-		ts.Request(t, "POST", "/api/v1/forms", map[string]interface{}{
-			"name": "Pagination Form " + string(rune('A'+i)),
-		})
-	}
-
-	// Get page 1 with limit 2
-	resp := ts.Request(t, "GET", "/api/v1/forms?page=1&limit=2", nil)
+	createResp := ts.Request(t, "POST", "/api/v1/forms", map[string]interface{}{"name": "Bulk Actions Form"})
+	var createResult map[string]interface{}
+	ParseResponse(t, createResp, &createResult)
+	publicID := createResult["data"].(map[string]interface{})["public_id"].(string)
 
-	if resp.StatusCode != http.StatusOK {
-		t.Errorf("expected 200, got %d", resp.StatusCode)
+	var subIDs []string
+	for i := 0; i < 3; i++ {
+		resp := ts.Request(t, "POST", "/api/v1/submissions/"+publicID, map[string]interface{}{"n": i})
+		var result map[string]interface{}
+		ParseResponse(t, resp, &result)
+		subIDs = append(subIDs, result["data"].(map[string]interface{})["id"].(string))
+	}
+	bulk := func(body map[string]interface{}) (int, map[string]interface{}) {
+		resp := ts.Request(t, "POST", "/api/v1/forms/"+publicID+"/submissions/bulk", body)
+		var result map[string]interface{}
+		ParseResponse(t, resp, &result)
+		data, _ := result["data"].(map[string]interface{})
+		return resp.StatusCode, data
 	}
 
-	var result map[string]interface{}
-	ParseResponse(t, resp, &result)
-
-	data := result["data"].(map[string]interface{})
-	forms := data["forms"].([]interface{})
-	pagination := data["pagination"].(map[string]interface{})
+	if status, _ := bulk(map[string]interface{}{"action": "archive", "ids": subIDs}); status != http.StatusBadRequest {
+		t.Errorf("expected 400 for an unknown action, got %d", status)
+	}
+	if status, _ := bulk(map[string]interface{}{"action": "mark_read", "ids": []string{}}); status != http.StatusBadRequest {
+		t.Errorf("expected 400 without ids, got %d", status)
+	}
 
-	if len(forms) != 2 {
-		t.Errorf("expected 2 forms, got %d", len(forms))
+	status, data := bulk(map[string]interface{}{"action": "mark_read", "ids": append(subIDs[:2:2], "sub_unknown")})
+	if status != http.StatusOK || data["affected"].(float64) != 2 || data["requested"].(float64) != 3 {
+		t.Fatalf("expected 2 of 3 marked read, got %d %v", status, data)
+	}
+	if status, data := bulk(map[string]interface{}{"action": "mark_spam", "ids": subIDs[:1]}); status != http.StatusOK || data["affected"].(float64) != 1 {
+		t.Fatalf("expected 1 marked spam, got %d %v", status, data)
+	}
+	spamResp := ts.Request(t, "GET", "/api/v1/submissions/"+subIDs[0], nil)
+	var spamResult map[string]interface{}
+	ParseResponse(t, spamResp, &spamResult)
+	meta := spamResult["data"].(map[string]interface{})["meta"].(map[string]interface{})
+	if spam, _ := meta["_spam"].(map[string]interface{}); spam == nil || spam["is_spam"] != true || spam["manual"] != true {
+		t.Errorf("expected the submission marked as manual spam, got %v", meta["_spam"])
 	}
 
-	if pagination["total"].(float64) != 5 {
-		t.Errorf("expected total 5, got %v", pagination["total"])
+	if status, data := bulk(map[string]interface{}{"action": "delete", "ids": subIDs[:2]}); status != http.StatusOK || data["affected"].(float64) != 2 {
+		t.Fatalf("expected 2 deleted, got %d %v", status, data)
 	}
+	listResp := ts.Request(t, "GET", "/api/v1/forms/"+publicID+"/submissions", nil)
+	var listResult map[string]interface{}
+	ParseResponse(t, listResp, &listResult)
+	subs := listResult["data"].(map[string]interface{})["submissions"].([]interface{})
+	if len(subs) != 1 || subs[0].(map[string]interface{})["id"] != subIDs[2] || subs[0].(map[string]interface{})["status"] != "unread" {
+		t.Errorf("expected only the third, unread submission to remain, got %v", subs)
+	}
+}
 
-	if pagination["total_pages"].(float64) != 3 {
-		t.Errorf("expected 3 total pages, got %v", pagination["total_pages"])
+func TestSpamQuarantine(t *testing.T) {
+	ts := NewTestServer(t)
+	defer ts.Close()
+
+	createResp := ts.Request(t, "POST", "/api/v1/forms", map[string]interface{}{"name": "Quarantine Form"})
+	var createResult map[string]interface{}
+	ParseResponse(t, createResp, &createResult)
+	publicID := createResult["data"].(map[string]interface{})["public_id"].(string)
+
+	submit := func(body map[string]interface{}) map[string]interface{} {
+		resp := ts.Request(t, "POST", "/api/v1/submissions/"+publicID, body)
+		var result map[string]interface{}
+		ParseResponse(t, resp, &result)
+		return result["data"].(map[string]interface{})
+	}
+	list := func(path string) ([]interface{}, map[string]interface{}) {
+		resp := ts.Request(t, "GET", path, nil)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("GET %s: expected 200, got %d", path, resp.StatusCode)
+		}
+		var result map[string]interface{}
+		ParseResponse(t, resp, &result)
+		data := result["data"].(map[string]interface{})
+		subs, _ := data["submissions"].([]interface{})
+		return subs, data["counts"].(map[string]interface{})
+	}
+
+	ham := submit(map[string]interface{}{"name": "Ada"})
+	// A filled honeypot scores past the threshold
+	spam := submit(map[string]interface{}{"name": "Bot", "_hp": "http://spam.example"})
+	if ham["status"] != "unread" || spam["status"] != "spam" {
+		t.Fatalf("expected unread and spam, got %v and %v", ham["status"], spam["status"])
+	}
+
+	subs, counts := list("/api/v1/forms/" + publicID + "/submissions")
+	if len(subs) != 1 || subs[0].(map[string]interface{})["id"] != ham["id"] || counts["spam"].(float64) != 0 {
+		t.Errorf("expected only the genuine submission in the inbox, got %v %v", subs, counts)
+	}
+	if subs, counts = list("/api/v1/forms/" + publicID + "/submissions?status=all"); len(subs) != 2 || counts["spam"].(float64) != 1 {
+		t.Errorf("expected both submissions with status=all, got %d %v", len(subs), counts)
+	}
+	subs, _ = list("/api/v1/forms/" + publicID + "/submissions/spam")
+	if len(subs) != 1 || subs[0].(map[string]interface{})["id"] != spam["id"] {
+		t.Errorf("expected the quarantined submission in the spam list, got %v", subs)
+	}
+
+	// Not spam releases it to the inbox, and it can be quarantined again
+	if resp := ts.Request(t, "PUT", "/api/v1/submissions/"+spam["id"].(string)+"/not-spam", nil); resp.StatusCode != http.StatusOK {
+		t.Fatalf("not-spam: expected 200, got %d", resp.StatusCode)
+	}
+	if subs, _ = list("/api/v1/forms/" + publicID + "/submissions"); len(subs) != 2 {
+		t.Errorf("expected the released submission in the inbox, got %d", len(subs))
+	}
+	if resp := ts.Request(t, "PUT", "/api/v1/submissions/"+ham["id"].(string)+"/spam", nil); resp.StatusCode != http.StatusOK {
+		t.Fatalf("spam: expected 200, got %d", resp.StatusCode)
+	}
+	subs, _ = list("/api/v1/forms/" + publicID + "/submissions/spam")
+	if len(subs) != 1 || subs[0].(map[string]interface{})["id"] != ham["id"] {
+		t.Errorf("expected the marked submission in the spam list, got %v", subs)
+	}
+}
+
+func TestFormSpamConfig(t *testing.T) {
+	ts := NewTestServer(t)
+	defer ts.Close()
+
+	createResp := ts.Request(t, "POST", "/api/v1/forms", map[string]interface{}{"name": "Spam Settings Form"})
+	var createResult map[string]interface{}
+	ParseResponse(t, createResp, &createResult)
+	publicID := createResult["data"].(map[string]interface{})["public_id"].(string)
+
+	setSpam := func(body map[string]interface{}) (int, map[string]interface{}) {
+		resp := ts.Request(t, "PUT", "/api/v1/forms/"+publicID+"/spam", body)
+		var result map[string]interface{}
+		ParseResponse(t, resp, &result)
+		data, _ := result["data"].(map[string]interface{})
+		return resp.StatusCode, data
+	}
+	submit := func(body map[string]interface{}) map[string]interface{} {
+		resp := ts.Request(t, "POST", "/api/v1/submissions/"+publicID, body)
+		var result map[string]interface{}
+		ParseResponse(t, resp, &result)
+		return result["data"].(map[string]interface{})
+	}
+
+	if status, _ := setSpam(map[string]interface{}{"threshold": 101}); status != http.StatusBadRequest {
+		t.Errorf("expected 400 for a threshold over 100, got %d", status)
+	}
+
+	// Custom honeypot fields replace the built-in ones
+	status, form := setSpam(map[string]interface{}{"honeypot_fields": []string{"nickname"}})
+	if status != http.StatusOK {
+		t.Fatalf("expected 200, got %d", status)
+	}
+	spam := form["spam"].(map[string]interface{})
+	if spam["threshold"].(float64) != 50 || spam["rate_limit_window"].(float64) != 60 {
+		t.Errorf("expected the default limits filled in, got %v", spam)
+	}
+	if sub := submit(map[string]interface{}{"name": "Bot", "nickname": "x"}); sub["status"] != "spam" {
+		t.Errorf("expected the custom honeypot to quarantine, got %v", sub["status"])
+	}
+	if sub := submit(map[string]interface{}{"name": "Ada", "_hp": "x"}); sub["status"] != "unread" {
+		t.Errorf("expected the built-in honeypot ignored, got %v", sub["status"])
+	}
+
+	// A disabled check doesn't score submissions at all
+	if status, _ := setSpam(map[string]interface{}{"disabled": true}); status != http.StatusOK {
+		t.Fatalf("expected 200, got %d", status)
+	}
+	sub := submit(map[string]interface{}{"name": "Bot", "nickname": "x"})
+	if meta, _ := sub["meta"].(map[string]interface{}); sub["status"] != "unread" || meta["_spam"] != nil {
+		t.Errorf("expected an unscored submission, got %v %v", sub["status"], sub["meta"])
+	}
+
+	resp := ts.Request(t, "DELETE", "/api/v1/forms/"+publicID+"/spam", nil)
+	var result map[string]interface{}
+	ParseResponse(t, resp, &result)
+	if resp.StatusCode != http.StatusOK || result["data"].(map[string]interface{})["spam"] != nil {
+		t.Errorf("expected the defaults restored, got %d %v", resp.StatusCode, result["data"])
+	}
+	if sub := submit(map[string]interface{}{"name": "Bot", "_hp": "x"}); sub["status"] != "spam" {
+		t.Errorf("expected the built-in honeypot to quarantine again, got %v", sub["status"])
+	}
+}
+
+func TestSpamLanguageAndDomains(t *testing.T) {
+	ts := NewTestServer(t)
+	defer ts.Close()
+
+	createResp := ts.Request(t, "POST", "/api/v1/forms", map[string]interface{}{"name": "Language Form"})
+	var createResult map[string]interface{}
+	ParseResponse(t, createResp, &createResult)
+	publicID := createResult["data"].(map[string]interface{})["public_id"].(string)
+
+	submit := func(message string) map[string]interface{} {
+		resp := ts.Request(t, "POST", "/api/v1/submissions/"+publicID, map[string]interface{}{"message": message})
+		var result map[string]interface{}
+		ParseResponse(t, resp, &result)
+		return result["data"].(map[string]interface{})
+	}
+	flags := func(sub map[string]interface{}) string {
+		spam := sub["meta"].(map[string]interface{})["_spam"].(map[string]interface{})
+		return fmt.Sprint(spam["flags"])
+	}
+
+	if resp := ts.Request(t, "PUT", "/api/v1/forms/"+publicID+"/spam", map[string]interface{}{"language": "klingon"}); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 for an unknown language, got %d", resp.StatusCode)
+	}
+	if resp := ts.Request(t, "PUT", "/api/v1/forms/"+publicID+"/spam", map[string]interface{}{"language": "EN"}); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected the language set, got %d", resp.StatusCode)
+	}
+
+	foreign := "Здравствуйте, предлагаем продвижение вашего сайта недорого"
+	sub := submit(foreign)
+	if sub["status"] != "unread" || !strings.Contains(flags(sub), "foreign_script") {
+		t.Errorf("expected foreign text flagged below the threshold, got %v %v", sub["status"], flags(sub))
+	}
+
+	// Site-wide weights and blocked domains
+	ts.Router.SetSpamSettings(func(context.Context) *domain.SpamSettings {
+		return &domain.SpamSettings{
+			Weights:        map[string]int{"foreign_script": 60},
+			BlockedDomains: []string{"spam.example"},
+		}
+	})
+	if sub := submit(foreign); sub["status"] != "spam" {
+		t.Errorf("expected the heavier weight to quarantine, got %v %v", sub["status"], flags(sub))
+	}
+	sub = submit("Great deals at https://shop.spam.example/today")
+	if sub["status"] != "spam" || !strings.Contains(flags(sub), "blocked_domain:shop.spam.example") {
+		t.Errorf("expected the blocked domain to quarantine, got %v %v", sub["status"], flags(sub))
+	}
+	if sub := submit("Hello, I'd like to know more about https://example.com"); sub["status"] != "unread" {
+		t.Errorf("expected a clean submission accepted, got %v %v", sub["status"], flags(sub))
+	}
+}
+
+func TestSpamFingerprints(t *testing.T) {
+	store, err := sqlite.New(":memory:")
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	admin := &domain.User{ID: "admin-1", Email: "admin@example.com", PasswordHash: "x", Role: domain.RoleAdmin}
+	if err := store.User().Create(context.Background(), admin); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	asAdmin := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), middleware.UserIDKey, admin.ID)
+			ctx = context.WithValue(ctx, middleware.RoleKey, string(admin.Role))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+
+	fingerprints := service.NewSpamFingerprintService(store)
+	submService := service.NewSubmissionService(store)
+	submService.SetSpamCallback(fingerprints.Learn)
+	router := api.NewRouter(service.NewFormService(store), submService, service.NewStatsService(store))
+	router.SetSpamFingerprints(fingerprints)
+	mux := http.NewServeMux()
+	router.RegisterPublicRoutes(mux, asAdmin)
+	router.RegisterProtectedRoutes(mux, asAdmin)
+	api.NewSpamFingerprintHandler(fingerprints).RegisterRoutes(mux, asAdmin)
+	ts := &TestServer{Server: httptest.NewServer(mux), Store: store, Mux: mux, Router: router, Auth: asAdmin}
+	defer ts.Close()
+
+	createForm := func(name string) string {
+		var result map[string]interface{}
+		ParseResponse(t, ts.Request(t, "POST", "/api/v1/forms", map[string]interface{}{"name": name}), &result)
+		return result["data"].(map[string]interface{})["public_id"].(string)
+	}
+	submit := func(publicID string, data map[string]interface{}) map[string]interface{} {
+		var result map[string]interface{}
+		ParseResponse(t, ts.Request(t, "POST", "/api/v1/submissions/"+publicID, data), &result)
+		return result["data"].(map[string]interface{})
+	}
+	listFingerprints := func() []interface{} {
+		var result map[string]interface{}
+		ParseResponse(t, ts.Request(t, "GET", "/api/v1/admin/spam-fingerprints", nil), &result)
+		fps, _ := result["data"].(map[string]interface{})["fingerprints"].([]interface{})
+		return fps
+	}
+
+	first, second := createForm("First"), createForm("Second")
+	pitch := "Buy cheap followers now, best prices guaranteed for your business!"
+	sub := submit(first, map[string]interface{}{"email": "a@example.com", "message": pitch})
+	if sub["status"] != "unread" {
+		t.Fatalf("expected the first submission accepted, got %v", sub["status"])
+	}
+	if resp := ts.Request(t, "PUT", "/api/v1/submissions/"+sub["id"].(string)+"/spam", nil); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected marked as spam, got %d", resp.StatusCode)
+	}
+
+	// The same content on another form, from another address and differently spaced
+	repeat := submit(second, map[string]interface{}{"email": "b@example.com", "text": "BUY cheap followers now -- best prices guaranteed for your business"})
+	flags := fmt.Sprint(repeat["meta"].(map[string]interface{})["_spam"].(map[string]interface{})["flags"])
+	if repeat["status"] != "spam" || !strings.Contains(flags, "repeat_spam:") {
+		t.Errorf("expected the repeated content quarantined, got %v %v", repeat["status"], flags)
+	}
+	if other := submit(second, map[string]interface{}{"message": "Hello, could you send me a quote for the website?"}); other["status"] != "unread" {
+		t.Errorf("expected other content accepted, got %v", other["status"])
+	}
+
+	fps := listFingerprints()
+	if len(fps) != 1 {
+		t.Fatalf("expected one fingerprint, got %v", fps)
+	}
+	fp := fps[0].(map[string]interface{})
+	if fp["hits"] != float64(1) || fp["confirmations"] != float64(1) || !strings.Contains(fp["sample"].(string), "cheap followers") {
+		t.Errorf("unexpected fingerprint %v", fp)
+	}
+
+	// Releasing the spam forgets its content
+	if resp := ts.Request(t, "PUT", "/api/v1/submissions/"+repeat["id"].(string)+"/not-spam", nil); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected released, got %d", resp.StatusCode)
+	}
+	if fps := listFingerprints(); len(fps) != 0 {
+		t.Errorf("expected the fingerprint forgotten, got %v", fps)
+	}
+	if again := submit(second, map[string]interface{}{"message": pitch}); again["status"] != "unread" {
+		t.Errorf("expected the released content accepted, got %v", again["status"])
+	}
+
+	// Admins delete fingerprints by hash
+	ts.Request(t, "PUT", "/api/v1/submissions/"+sub["id"].(string)+"/spam", nil)
+	hash := listFingerprints()[0].(map[string]interface{})["hash"].(string)
+	if resp := ts.Request(t, "DELETE", "/api/v1/admin/spam-fingerprints/"+hash, nil); resp.StatusCode != http.StatusOK {
+		t.Errorf("expected deleted, got %d", resp.StatusCode)
+	}
+	if resp := ts.Request(t, "DELETE", "/api/v1/admin/spam-fingerprints/"+hash, nil); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404 for a deleted fingerprint, got %d", resp.StatusCode)
+	}
+}
+
+func TestSpamRescore(t *testing.T) {
+	ts := NewTestServer(t)
+	defer ts.Close()
+	tasks := service.NewTaskService(ts.Store)
+	ts.Router.SetTasks(tasks)
+	api.NewTaskHandler(tasks).RegisterRoutes(ts.Mux, func(h http.Handler) http.Handler { return h })
+
+	var result map[string]interface{}
+	ParseResponse(t, ts.Request(t, "POST", "/api/v1/forms", map[string]interface{}{"name": "Rescore Form"}), &result)
+	publicID := result["data"].(map[string]interface{})["public_id"].(string)
+	submit := func(message string) string {
+		var result map[string]interface{}
+		ParseResponse(t, ts.Request(t, "POST", "/api/v1/submissions/"+publicID, map[string]interface{}{"message": message}), &result)
+		return result["data"].(map[string]interface{})["id"].(string)
+	}
+	status := func(id string) string {
+		var result map[string]interface{}
+		ParseResponse(t, ts.Request(t, "GET", "/api/v1/submissions/"+id, nil), &result)
+		return result["data"].(map[string]interface{})["status"].(string)
+	}
+	rescore := func(query string) map[string]interface{} {
+		t.Helper()
+		var result map[string]interface{}
+		resp := ts.Request(t, "POST", "/api/v1/forms/"+publicID+"/spam/rescore"+query, nil)
+		ParseResponse(t, resp, &result)
+		if resp.StatusCode != http.StatusAccepted {
+			t.Fatalf("expected 202, got %d %v", resp.StatusCode, result)
+		}
+		taskID := result["data"].(map[string]interface{})["id"].(string)
+		var task map[string]interface{}
+		for i := 0; i < 200; i++ {
+			ParseResponse(t, ts.Request(t, "GET", "/api/v1/tasks/"+taskID, nil), &result)
+			if task = result["data"].(map[string]interface{}); task["status"] != "running" && task["status"] != "pending" {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		if task["status"] != "completed" || task["type"] != "spam_rescore" {
+			t.Fatalf("unexpected task state: %v", task)
+		}
+		return task["result"].(map[string]interface{})
+	}
+
+	linked := submit("Great deals at https://shop.spam.example/today")
+	clean := submit("Hello, I'd like a quote")
+	manual := submit("Check https://shop.spam.example/manual")
+	ts.Request(t, "PUT", "/api/v1/submissions/"+manual+"/not-spam", nil)
+
+	// The domain gets blocked after the submissions came in
+	ts.Router.SetSpamSettings(func(context.Context) *domain.SpamSettings {
+		return &domain.SpamSettings{BlockedDomains: []string{"spam.example"}, Weights: map[string]int{"blocked_domain": 60}}
+	})
+	res := rescore("")
+	if res["scanned"] != float64(3) || res["quarantined"] != float64(1) || res["released"] != float64(0) || res["skipped"] != float64(1) {
+		t.Errorf("unexpected result %v", res)
+	}
+	if status(linked) != "spam" || status(clean) != "unread" || status(manual) != "unread" {
+		t.Errorf("expected only the linked submission quarantined, got %s %s %s", status(linked), status(clean), status(manual))
+	}
+
+	// A higher threshold releases it again
+	if resp := ts.Request(t, "PUT", "/api/v1/forms/"+publicID+"/spam", map[string]interface{}{"threshold": 80}); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected the threshold set, got %d", resp.StatusCode)
+	}
+	if res := rescore("?days=7"); res["quarantined"] != float64(0) || res["released"] != float64(1) {
+		t.Errorf("unexpected result %v", res)
+	}
+	if status(linked) != "unread" {
+		t.Errorf("expected the linked submission released, got %s", status(linked))
+	}
+
+	if resp := ts.Request(t, "POST", "/api/v1/forms/"+publicID+"/spam/rescore?days=0", nil); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 for days=0, got %d", resp.StatusCode)
+	}
+	ts.Request(t, "PUT", "/api/v1/forms/"+publicID+"/spam", map[string]interface{}{"disabled": true})
+	if resp := ts.Request(t, "POST", "/api/v1/forms/"+publicID+"/spam/rescore", nil); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 with the spam check off, got %d", resp.StatusCode)
+	}
+}
+
+func TestFormCaptcha(t *testing.T) {
+	ts := NewTestServer(t)
+	defer ts.Close()
+
+	// Siteverify stand-in: "good" passes, anything else fails
+	var mu sync.Mutex
+	var verified url.Values
+	down := false
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		mu.Lock()
+		defer mu.Unlock()
+		verified = r.PostForm
+		if down {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		if r.PostForm.Get("response") == "good" {
+			_, _ = w.Write([]byte(`{"success": true}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"success": false, "error-codes": ["invalid-input-response"]}`))
+	}))
+	defer provider.Close()
+	verifier := captcha.NewVerifier()
+	verifier.Endpoints = map[domain.CaptchaProvider]string{domain.CaptchaTurnstile: provider.URL}
+	ts.Router.SetCaptchaVerifier(verifier)
+
+	createResp := ts.Request(t, "POST", "/api/v1/forms", map[string]interface{}{"name": "Captcha Form"})
+	var createResult map[string]interface{}
+	ParseResponse(t, createResp, &createResult)
+	publicID := createResult["data"].(map[string]interface{})["public_id"].(string)
+
+	resp := ts.Request(t, "PUT", "/api/v1/forms/"+publicID+"/captcha", map[string]interface{}{"provider": "friendlycaptcha", "secret": "x"})
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 for an unknown provider, got %d", resp.StatusCode)
+	}
+	resp = ts.Request(t, "PUT", "/api/v1/forms/"+publicID+"/captcha", map[string]interface{}{"provider": "turnstile", "secret": "0xsecret"})
+	var result map[string]interface{}
+	ParseResponse(t, resp, &result)
+	if resp.StatusCode != http.StatusOK || result["data"].(map[string]interface{})["captcha"] == nil {
+		t.Fatalf("expected the captcha set, got %d %v", resp.StatusCode, result)
+	}
+	// The secret is write-only
+	if captcha := result["data"].(map[string]interface{})["captcha"].(map[string]interface{}); captcha["secret"] != nil || captcha["has_secret"] != true {
+		t.Errorf("expected the captcha secret left out, got %v", captcha)
+	}
+	for _, path := range []string{"/api/v1/forms/" + publicID, "/api/v1/forms"} {
+		resp := ts.Request(t, "GET", path, nil)
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if strings.Contains(string(body), "0xsecret") {
+			t.Errorf("expected the captcha secret left out of %s, got %s", path, body)
+		}
+	}
+
+	submit := func(body map[string]interface{}) (int, map[string]interface{}) {
+		resp := ts.Request(t, "POST", "/api/v1/submissions/"+publicID, body)
+		var result map[string]interface{}
+		ParseResponse(t, resp, &result)
+		return resp.StatusCode, result
+	}
+
+	for _, body := range []map[string]interface{}{
+		{"email": "a@example.com"},
+		{"email": "a@example.com", "cf-turnstile-response": "bad"},
+	} {
+		if status, result := submit(body); status != http.StatusForbidden || result["code"] != "CAPTCHA_FAILED" {
+			t.Errorf("expected 403 CAPTCHA_FAILED for %v, got %d %v", body, status, result)
+		}
+	}
+
+	status, result := submit(map[string]interface{}{"email": "a@example.com", "cf-turnstile-response": "good"})
+	if status != http.StatusCreated {
+		t.Fatalf("expected 201 with a valid token, got %d %v", status, result)
+	}
+	if data := result["data"].(map[string]interface{})["data"].(map[string]interface{}); data["cf-turnstile-response"] != nil {
+		t.Errorf("expected the token left out of the stored data, got %v", data)
+	}
+	mu.Lock()
+	if verified.Get("secret") != "0xsecret" || verified.Get("remoteip") == "" {
+		t.Errorf("expected the secret and client IP sent to the provider, got %v", verified)
+	}
+	down = true
+	mu.Unlock()
+
+	// Submissions can't be checked while the provider is down
+	if status, result := submit(map[string]interface{}{"cf-turnstile-response": "good"}); status != http.StatusServiceUnavailable || result["code"] != "CAPTCHA_UNAVAILABLE" {
+		t.Errorf("expected 503 CAPTCHA_UNAVAILABLE, got %d %v", status, result)
+	}
+
+	resp = ts.Request(t, "DELETE", "/api/v1/forms/"+publicID+"/captcha", nil)
+	_ = resp.Body.Close()
+	if status, _ := submit(map[string]interface{}{"email": "a@example.com"}); status != http.StatusCreated {
+		t.Errorf("expected 201 once the captcha is removed, got %d", status)
+	}
+}
+
+func TestFormNormalize(t *testing.T) {
+	ts := NewTestServer(t)
+	defer ts.Close()
+
+	createResp := ts.Request(t, "POST", "/api/v1/forms", map[string]interface{}{"name": "Normalize Form"})
+	var createResult map[string]interface{}
+	ParseResponse(t, createResp, &createResult)
+	publicID := createResult["data"].(map[string]interface{})["public_id"].(string)
+
+	submit := func() map[string]interface{} {
+		resp := ts.Request(t, "POST", "/api/v1/submissions/"+publicID, map[string]interface{}{
+			"email":   "  Ada@Example.COM ",
+			"name":    "Jose\u0301",
+			"message": "line one\r\nline\x00\x07two\t",
+			"tags":    []interface{}{" cafe\u0301 "},
+		})
+		var result map[string]interface{}
+		ParseResponse(t, resp, &result)
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("expected 201, got %d %v", resp.StatusCode, result)
+		}
+		return result["data"].(map[string]interface{})["data"].(map[string]interface{})
+	}
+
+	// Without settings the data is stored as sent
+	if data := submit(); data["name"] != "Jose\u0301" || data["email"] != "  Ada@Example.COM " {
+		t.Errorf("expected the data untouched, got %v", data)
+	}
+
+	resp := ts.Request(t, "PUT", "/api/v1/forms/"+publicID+"/normalize", map[string]interface{}{"lowercase_emails": true})
+	var result map[string]interface{}
+	ParseResponse(t, resp, &result)
+	if resp.StatusCode != http.StatusOK || result["data"].(map[string]interface{})["normalize"] == nil {
+		t.Fatalf("expected the normalize settings set, got %d %v", resp.StatusCode, result)
+	}
+
+	data := submit()
+	if data["email"] != "ada@example.com" {
+		t.Errorf("expected the email trimmed and lowercased, got %q", data["email"])
+	}
+	if data["name"] != "Jos\u00e9" {
+		t.Errorf("expected the name in NFC, got %q", data["name"])
+	}
+	if data["message"] != "line one\nline two" {
+		t.Errorf("expected line breaks kept and control characters collapsed, got %q", data["message"])
+	}
+	if tags := data["tags"].([]interface{}); tags[0] != "caf\u00e9" {
+		t.Errorf("expected list values cleaned too, got %v", tags)
+	}
+
+	fields := make([]string, 51)
+	for i := range fields {
+		fields[i] = "email_" + strconv.Itoa(i)
+	}
+	resp = ts.Request(t, "PUT", "/api/v1/forms/"+publicID+"/normalize", map[string]interface{}{"email_fields": fields})
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 for too many email fields, got %d", resp.StatusCode)
+	}
+
+	resp = ts.Request(t, "DELETE", "/api/v1/forms/"+publicID+"/normalize", nil)
+	ParseResponse(t, resp, &result)
+	if result["data"].(map[string]interface{})["normalize"] != nil {
+		t.Errorf("expected the normalize settings removed, got %v", result["data"])
+	}
+}
+
+func TestFormChannels(t *testing.T) {
+	ts := NewTestServer(t)
+	defer ts.Close()
+
+	createResp := ts.Request(t, "POST", "/api/v1/forms", map[string]interface{}{"name": "Chat Form"})
+	var createResult map[string]interface{}
+	ParseResponse(t, createResp, &createResult)
+	publicID := createResult["data"].(map[string]interface{})["public_id"].(string)
+
+	for _, channel := range []map[string]interface{}{
+		{"type": "teams", "url": "https://hooks.slack.com/services/T0/B0/x"},
+		{"type": "slack", "url": "http://hooks.slack.com/services/T0/B0/x"},
+		{"type": "slack", "url": "https://internal.example.com/services/T0/B0/x"},
+		{"type": "discord", "url": "https://hooks.slack.com/services/T0/B0/x"},
+		{"type": "discord", "url": "https://discord.com/api/webhooks/1/abc", "fields": 11},
+	} {
+		resp := ts.Request(t, "PUT", "/api/v1/forms/"+publicID+"/channels", map[string]interface{}{"channels": []interface{}{channel}})
+		var result map[string]interface{}
+		ParseResponse(t, resp, &result)
+		if resp.StatusCode != http.StatusBadRequest || result["code"] != "VALIDATION_ERROR" {
+			t.Errorf("%v: expected 400 VALIDATION_ERROR, got %d %v", channel, resp.StatusCode, result)
+		}
+	}
+
+	resp := ts.Request(t, "PUT", "/api/v1/forms/"+publicID+"/channels", map[string]interface{}{"channels": []map[string]interface{}{
+		{"type": "slack", "url": " https://hooks.slack.com/services/T0/B0/x "},
+		{"type": "discord", "url": "https://discord.com/api/webhooks/1/abc", "fields": 3},
+	}})
+	var result map[string]interface{}
+	ParseResponse(t, resp, &result)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected the channels set, got %d %v", resp.StatusCode, result)
+	}
+	channels := result["data"].(map[string]interface{})["channels"].([]interface{})
+	slack := channels[0].(map[string]interface{})
+	if len(channels) != 2 || slack["url"] != "https://hooks.slack.com/services/T0/B0/x" || slack["fields"].(float64) != 5 {
+		t.Errorf("expected the channels normalized, got %v", channels)
+	}
+
+	resp = ts.Request(t, "GET", "/api/v1/forms/"+publicID, nil)
+	ParseResponse(t, resp, &result)
+	if got := result["data"].(map[string]interface{})["channels"].([]interface{}); len(got) != 2 {
+		t.Errorf("expected the channels stored, got %v", got)
+	}
+
+	resp = ts.Request(t, "POST", "/api/v1/forms/"+publicID+"/test-notification", nil)
+	ParseResponse(t, resp, &result)
+	if got := result["data"].(map[string]interface{})["channels"]; got != float64(2) {
+		t.Errorf("expected the test notification to count the channels, got %v", got)
+	}
+
+	resp = ts.Request(t, "DELETE", "/api/v1/forms/"+publicID+"/channels", nil)
+	ParseResponse(t, resp, &result)
+	if resp.StatusCode != http.StatusOK || result["data"].(map[string]interface{})["channels"] != nil {
+		t.Errorf("expected the channels removed, got %d %v", resp.StatusCode, result["data"])
+	}
+}
+
+func TestFormNotifyRules(t *testing.T) {
+	ts := NewTestServer(t)
+	defer ts.Close()
+
+	createResp := ts.Request(t, "POST", "/api/v1/forms", map[string]interface{}{
+		"name":          "Routed Form",
+		"notify_emails": []string{"team@example.com"},
+	})
+	var createResult map[string]interface{}
+	ParseResponse(t, createResp, &createResult)
+	publicID := createResult["data"].(map[string]interface{})["public_id"].(string)
+
+	recipients := func(data map[string]interface{}) map[string]interface{} {
+		resp := ts.Request(t, "POST", "/api/v1/forms/"+publicID+"/test-notification", map[string]interface{}{"data": data})
+		var result map[string]interface{}
+		ParseResponse(t, resp, &result)
+		got, _ := result["data"].(map[string]interface{})["recipients"].(map[string]interface{})
+		return got
+	}
+
+	// Without rules the notify list gets everything
+	if got := fmt.Sprint(recipients(map[string]interface{}{"topic": "sales"})); got != "map[full:[team@example.com]]" {
+		t.Errorf("expected the notify list, got %s", got)
+	}
+
+	for _, rules := range []interface{}{
+		[]map[string]interface{}{{"recipients": []string{}}},
+		[]map[string]interface{}{{"recipients": []string{"not an email"}}},
+		[]map[string]interface{}{{"recipients": []string{"a@example.com"}, "condition": "topic sales"}},
+		[]map[string]interface{}{{"recipients": []string{"a@example.com"}, "condition": `topic == "sales`}},
+		[]map[string]interface{}{{"recipients": []string{"a@example.com"}, "template": "fancy"}},
+	} {
+		resp := ts.Request(t, "PUT", "/api/v1/forms/"+publicID+"/notify-rules", map[string]interface{}{"rules": rules})
+		var result map[string]interface{}
+		ParseResponse(t, resp, &result)
+		if resp.StatusCode != http.StatusBadRequest || result["code"] != "VALIDATION_ERROR" {
+			t.Errorf("expected 400 VALIDATION_ERROR for %v, got %d %v", rules, resp.StatusCode, result)
+		}
+	}
+
+	resp := ts.Request(t, "PUT", "/api/v1/forms/"+publicID+"/notify-rules", map[string]interface{}{
+		"rules": []map[string]interface{}{
+			{"recipients": []string{"sales@example.com"}, "condition": `topic == "sales"`},
+			{"recipients": []string{"support@example.com"}, "condition": `topic != "sales"`},
+			{"recipients": []string{"manager@example.com", "sales@example.com"}, "template": "summary"},
+			{"recipients": []string{"vip@example.com"}, "condition": "interests == 3"},
+		},
+	})
+	var result map[string]interface{}
+	ParseResponse(t, resp, &result)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected the rules set, got %d %v", resp.StatusCode, result)
+	}
+	rules := result["data"].(map[string]interface{})["notify_rules"].([]interface{})
+	if len(rules) != 4 || rules[0].(map[string]interface{})["template"] != "full" {
+		t.Errorf("expected 4 rules defaulting to the full template, got %v", rules)
+	}
+
+	for _, tc := range []struct {
+		data map[string]interface{}
+		want string
+	}{
+		// sales@ matches a full and a summary rule and gets the full email only
+		{map[string]interface{}{"topic": "sales"}, "map[full:[sales@example.com] summary:[manager@example.com]]"},
+		{map[string]interface{}{"topic": "billing"}, "map[full:[support@example.com] summary:[manager@example.com sales@example.com]]"},
+		{map[string]interface{}{"topic": "sales", "interests": []interface{}{1, 3}}, "map[full:[sales@example.com vip@example.com] summary:[manager@example.com]]"},
+	} {
+		if got := fmt.Sprint(recipients(tc.data)); got != tc.want {
+			t.Errorf("%v: expected %s, got %s", tc.data, tc.want, got)
+		}
+	}
+
+	resp = ts.Request(t, "DELETE", "/api/v1/forms/"+publicID+"/notify-rules", nil)
+	ParseResponse(t, resp, &result)
+	if result["data"].(map[string]interface{})["notify_rules"] != nil {
+		t.Errorf("expected the rules removed, got %v", result["data"])
+	}
+	if got := fmt.Sprint(recipients(map[string]interface{}{"topic": "sales"})); got != "map[full:[team@example.com]]" {
+		t.Errorf("expected the notify list again, got %s", got)
+	}
+}
+
+func TestFormEmailTemplate(t *testing.T) {
+	ts := NewTestServer(t)
+	defer ts.Close()
+
+	createResp := ts.Request(t, "POST", "/api/v1/forms", map[string]interface{}{"name": "Template Form"})
+	var createResult map[string]interface{}
+	ParseResponse(t, createResp, &createResult)
+	publicID := createResult["data"].(map[string]interface{})["public_id"].(string)
+
+	for _, tc := range []struct {
+		body map[string]interface{}
+		code string
+	}{
+		{map[string]interface{}{"subject": "  "}, "VALIDATION_ERROR"},
+		{map[string]interface{}{"subject": "New lead: {{.Fields.company"}, "INVALID_EMAIL_TEMPLATE"},
+		{map[string]interface{}{"text": "{{range 1000000}}x{{end}}"}, "INVALID_EMAIL_TEMPLATE"},
+	} {
+		resp := ts.Request(t, "PUT", "/api/v1/forms/"+publicID+"/email-template", tc.body)
+		var result map[string]interface{}
+		ParseResponse(t, resp, &result)
+		if resp.StatusCode != http.StatusBadRequest || result["code"] != tc.code {
+			t.Errorf("expected 400 %s for %v, got %d %v", tc.code, tc.body, resp.StatusCode, result)
+		}
+	}
+
+	resp := ts.Request(t, "PUT", "/api/v1/forms/"+publicID+"/email-template", map[string]interface{}{
+		"subject": " New lead: {{.Fields.company}} ",
+		"html":    "<p>{{.Fields.message}}</p>",
+	})
+	var result map[string]interface{}
+	ParseResponse(t, resp, &result)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected the template set, got %d %v", resp.StatusCode, result)
+	}
+
+	getResp := ts.Request(t, "GET", "/api/v1/forms/"+publicID, nil)
+	ParseResponse(t, getResp, &result)
+	tmpl, _ := result["data"].(map[string]interface{})["email_template"].(map[string]interface{})
+	if tmpl["subject"] != "New lead: {{.Fields.company}}" || tmpl["html"] != "<p>{{.Fields.message}}</p>" || tmpl["text"] != nil {
+		t.Errorf("expected the trimmed template stored, got %v", tmpl)
+	}
+
+	resp = ts.Request(t, "DELETE", "/api/v1/forms/"+publicID+"/email-template", nil)
+	ParseResponse(t, resp, &result)
+	if result["data"].(map[string]interface{})["email_template"] != nil {
+		t.Errorf("expected the template removed, got %v", result["data"])
+	}
+}
+
+func TestFormAutoReply(t *testing.T) {
+	ts := NewTestServer(t)
+	defer ts.Close()
+
+	createResp := ts.Request(t, "POST", "/api/v1/forms", map[string]interface{}{"name": "Auto Reply Form"})
+	var createResult map[string]interface{}
+	ParseResponse(t, createResp, &createResult)
+	publicID := createResult["data"].(map[string]interface{})["public_id"].(string)
+
+	for _, tc := range []struct {
+		body map[string]interface{}
+		code string
+	}{
+		{map[string]interface{}{"subject": "Thanks", "text": "Hi"}, "VALIDATION_ERROR"},
+		{map[string]interface{}{"field": "email", "text": "Hi"}, "VALIDATION_ERROR"},
+		{map[string]interface{}{"field": "email", "subject": "Thanks"}, "VALIDATION_ERROR"},
+		{map[string]interface{}{"field": "email", "subject": "Thanks", "text": "Hi", "reply_to": "Support <support@example.com>"}, "VALIDATION_ERROR"},
+		{map[string]interface{}{"field": "email", "subject": "Thanks, {{.Fields.name", "text": "Hi"}, "INVALID_EMAIL_TEMPLATE"},
+	} {
+		resp := ts.Request(t, "PUT", "/api/v1/forms/"+publicID+"/auto-reply", tc.body)
+		var result map[string]interface{}
+		ParseResponse(t, resp, &result)
+		if resp.StatusCode != http.StatusBadRequest || result["code"] != tc.code {
+			t.Errorf("expected 400 %s for %v, got %d %v", tc.code, tc.body, resp.StatusCode, result)
+		}
+	}
+
+	resp := ts.Request(t, "PUT", "/api/v1/forms/"+publicID+"/auto-reply", map[string]interface{}{
+		"field":    " contact_email ",
+		"subject":  "Thanks, {{.Fields.name}}",
+		"text":     "We'll be in touch.",
+		"reply_to": "support@example.com",
+	})
+	var result map[string]interface{}
+	ParseResponse(t, resp, &result)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected the auto-reply set, got %d %v", resp.StatusCode, result)
+	}
+
+	getResp := ts.Request(t, "GET", "/api/v1/forms/"+publicID, nil)
+	ParseResponse(t, getResp, &result)
+	reply, _ := result["data"].(map[string]interface{})["auto_reply"].(map[string]interface{})
+	if reply["field"] != "contact_email" || reply["subject"] != "Thanks, {{.Fields.name}}" || reply["reply_to"] != "support@example.com" {
+		t.Errorf("expected the trimmed auto-reply stored, got %v", reply)
+	}
+
+	resp = ts.Request(t, "DELETE", "/api/v1/forms/"+publicID+"/auto-reply", nil)
+	ParseResponse(t, resp, &result)
+	if result["data"].(map[string]interface{})["auto_reply"] != nil {
+		t.Errorf("expected the auto-reply removed, got %v", result["data"])
+	}
+}
+
+func TestFormFilters(t *testing.T) {
+	ts := NewTestServer(t)
+	defer ts.Close()
+
+	createResp := ts.Request(t, "POST", "/api/v1/forms", map[string]interface{}{"name": "Filter Form"})
+	var createResult map[string]interface{}
+	ParseResponse(t, createResp, &createResult)
+	publicID := createResult["data"].(map[string]interface{})["public_id"].(string)
+
+	submit := func(message string) (int, map[string]interface{}) {
+		resp := ts.Request(t, "POST", "/api/v1/submissions/"+publicID, map[string]interface{}{"name": "Ada", "message": message})
+		var result map[string]interface{}
+		ParseResponse(t, resp, &result)
+		return resp.StatusCode, result
+	}
+
+	for _, body := range []map[string]interface{}{
+		{"filters": []interface{}{map[string]interface{}{"pattern": "  "}}},
+		{"filters": []interface{}{map[string]interface{}{"pattern": "casino(", "regex": true}}},
+		{"filters": []interface{}{map[string]interface{}{"pattern": "casino", "action": "delete"}}},
+		{"filters": []interface{}{map[string]interface{}{"id": "a", "pattern": "x"}, map[string]interface{}{"id": "a", "pattern": "y"}}},
+	} {
+		resp := ts.Request(t, "PUT", "/api/v1/forms/"+publicID+"/filters", body)
+		var result map[string]interface{}
+		ParseResponse(t, resp, &result)
+		if resp.StatusCode != http.StatusBadRequest || result["code"] != "VALIDATION_ERROR" {
+			t.Errorf("expected 400 VALIDATION_ERROR for %v, got %d %v", body, resp.StatusCode, result)
+		}
+	}
+
+	resp := ts.Request(t, "PUT", "/api/v1/forms/"+publicID+"/filters", map[string]interface{}{
+		"filters": []interface{}{map[string]interface{}{"pattern": " casino "}},
+	})
+	var result map[string]interface{}
+	ParseResponse(t, resp, &result)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected the filters set, got %d %v", resp.StatusCode, result)
+	}
+	rule := result["data"].(map[string]interface{})["filters"].([]interface{})[0].(map[string]interface{})
+	if rule["id"] == "" || rule["pattern"] != "casino" || rule["action"] != "flag" {
+		t.Errorf("expected an id, trimmed keyword and flag action, got %v", rule)
+	}
+	flagID := rule["id"].(string)
+
+	resp = ts.Request(t, "POST", "/api/v1/forms/"+publicID+"/filters", map[string]interface{}{
+		"pattern": `(?i)crypto\s+offer`, "regex": true, "action": "reject",
+	})
+	ParseResponse(t, resp, &result)
+	if resp.StatusCode != http.StatusCreated || result["data"].(map[string]interface{})["id"] == "" {
+		t.Fatalf("expected the filter added, got %d %v", resp.StatusCode, result)
+	}
+	rejectID := result["data"].(map[string]interface{})["id"].(string)
+
+	resp = ts.Request(t, "GET", "/api/v1/forms/"+publicID+"/filters", nil)
+	ParseResponse(t, resp, &result)
+	if filters := result["data"].(map[string]interface{})["filters"].([]interface{}); len(filters) != 2 {
+		t.Errorf("expected 2 filters, got %v", filters)
+	}
+
+	if status, _ := submit("Occasionally I write"); status != http.StatusCreated {
+		t.Errorf("expected a clean submission accepted, got %d", status)
+	}
+	status, result := submit("Best CASINO in town")
+	sub, _ := result["data"].(map[string]interface{})
+	if status != http.StatusCreated || sub["status"] != "spam" {
+		t.Fatalf("expected the flagged submission quarantined, got %d %v", status, result)
+	}
+	spam := sub["meta"].(map[string]interface{})["_spam"].(map[string]interface{})
+	if flags := fmt.Sprint(spam["flags"]); !strings.Contains(flags, "filter:"+flagID) {
+		t.Errorf("expected the filter in the spam flags, got %v", flags)
+	}
+	if status, result := submit("a Crypto offer for you"); status != http.StatusBadRequest || result["code"] != "CONTENT_REJECTED" {
+		t.Errorf("expected 400 CONTENT_REJECTED, got %d %v", status, result)
+	}
+
+	// Filters apply with the spam check disabled
+	if resp := ts.Request(t, "PUT", "/api/v1/forms/"+publicID+"/spam", map[string]interface{}{"disabled": true}); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected the spam check disabled, got %d", resp.StatusCode)
+	}
+	if status, result := submit("casino"); status != http.StatusCreated || result["data"].(map[string]interface{})["status"] != "spam" {
+		t.Errorf("expected the filter to quarantine with spam disabled, got %d %v", status, result)
+	}
+
+	// Global filters are checked too
+	ts.Router.SetContentFilters(func(context.Context) []domain.FilterRule {
+		return []domain.FilterRule{{ID: "global", Pattern: "lottery", Action: domain.FilterReject}}
+	})
+	if status, result := submit("You won the lottery"); status != http.StatusBadRequest || result["code"] != "CONTENT_REJECTED" {
+		t.Errorf("expected the global filter to reject, got %d %v", status, result)
+	}
+
+	if resp := ts.Request(t, "DELETE", "/api/v1/forms/"+publicID+"/filters/flt_missing", nil); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown filter, got %d", resp.StatusCode)
+	}
+	resp = ts.Request(t, "DELETE", "/api/v1/forms/"+publicID+"/filters/"+rejectID, nil)
+	ParseResponse(t, resp, &result)
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected the filter deleted, got %d %v", resp.StatusCode, result)
+	}
+	if status, _ := submit("a crypto offer"); status != http.StatusCreated {
+		t.Errorf("expected the deleted filter no longer applied, got %d", status)
+	}
+}
+
+// =============================================================================
+// Stats Tests
+// =============================================================================
+
+func TestDashboardStats(t *testing.T) {
+	ts := NewTestServer(t)
+	defer ts.Close()
+
+	// Create some data
+	createResp := ts.Request(t, "POST", "/api/v1/forms", map[string]interface{}{
+		"name": "Stats Test Form",
+	})
+
+	var createResult map[string]interface{}
+	ParseResponse(t, createResp, &createResult)
+
+	data := createResult["data"].(map[string]interface{})
+	publicID := data["public_id"].(string)
+
+	ts.Request(t, "POST", "/api/v1/submissions/"+publicID, map[string]interface{}{
+		"name": "Test User",
+	})
+
+	// Get stats
+	resp := ts.Request(t, "GET", "/api/v1/stats", nil)
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected 200, got %d", resp.StatusCode)
+	}
+
+	var result map[string]interface{}
+	ParseResponse(t, resp, &result)
+
+	statsData := result["data"].(map[string]interface{})
+	if statsData["total_forms"] == nil {
+		t.Error("expected total_forms in stats")
+	}
+}
+
+// =============================================================================
+// Error Handling Tests
+// =============================================================================
+
+func TestFormNotFound(t *testing.T) {
+	ts := NewTestServer(t)
+	defer ts.Close()
+
+	resp := ts.Request(t, "GET", "/api/v1/forms/nonexistent-id", nil)
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", resp.StatusCode)
+	}
+}
+
+func TestInvalidFormCreate(t *testing.T) {
+	ts := NewTestServer(t)
+	defer ts.Close()
+
+	// Empty name should fail validation
+	resp := ts.Request(t, "POST", "/api/v1/forms", map[string]interface{}{
+		"name": "", // Empty name
+	})
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", resp.StatusCode)
+	}
+}
+
+func TestSubmitToNonexistentForm(t *testing.T) {
+	ts := NewTestServer(t)
+	defer ts.Close()
+
+	resp := ts.Request(t, "POST", "/api/v1/submissions/nonexistent-form", map[string]interface{}{
+		"name": "Test",
+	})
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", resp.StatusCode)
+	}
+}
+
+// =============================================================================
+// Pagination Tests
+// =============================================================================
+
+func TestFormsPagination(t *testing.T) {
+	ts := NewTestServer(t)
+	defer ts.Close()
+
+	// Create 5 forms
+	for i := 0; i < 5; i++ {
+		ts.Request(t, "POST", "/api/v1/forms", map[string]interface{}{
+			"name": "Pagination Form " + string(rune('A'+i)),
+		})
+	}
+
+	// Get page 1 with limit 2
+	resp := ts.Request(t, "GET", "/api/v1/forms?page=1&limit=2", nil)
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected 200, got %d", resp.StatusCode)
+	}
+
+	var result map[string]interface{}
+	ParseResponse(t, resp, &result)
+
+	data := result["data"].(map[string]interface{})
+	forms := data["forms"].([]interface{})
+	pagination := data["pagination"].(map[string]interface{})
+
+	if len(forms) != 2 {
+		t.Errorf("expected 2 forms, got %d", len(forms))
+	}
+
+	if pagination["total"].(float64) != 5 {
+		t.Errorf("expected total 5, got %v", pagination["total"])
+	}
+
+	if pagination["total_pages"].(float64) != 3 {
+		t.Errorf("expected 3 total pages, got %v", pagination["total_pages"])
+	}
+
+	if pagination["next"] != "/api/v1/forms?limit=2&page=2" {
+		t.Errorf("unexpected next link %v", pagination["next"])
+	}
+	if _, ok := pagination["prev"]; ok {
+		t.Errorf("first page should have no prev link, got %v", pagination["prev"])
+	}
+	if link := resp.Header.Get("Link"); link != `</api/v1/forms?limit=2&page=2>; rel="next"` {
+		t.Errorf("unexpected Link header %q", link)
+	}
+
+	// The last page links back but not forward
+	resp = ts.Request(t, "GET", "/api/v1/forms?page=3&limit=2", nil)
+	ParseResponse(t, resp, &result)
+	pagination = result["data"].(map[string]interface{})["pagination"].(map[string]interface{})
+	if _, ok := pagination["next"]; ok {
+		t.Errorf("last page should have no next link, got %v", pagination["next"])
+	}
+	if pagination["prev"] != "/api/v1/forms?limit=2&page=2" {
+		t.Errorf("unexpected prev link %v", pagination["prev"])
+	}
+}
+
+func TestFormsSortAndFilter(t *testing.T) {
+	ts := NewTestServer(t)
+	defer ts.Close()
+
+	for _, name := range []string{"Contact B", "newsletter", "Contact A"} {
+		ts.Request(t, "POST", "/api/v1/forms", map[string]interface{}{"name": name})
+	}
+
+	resp := ts.Request(t, "GET", "/api/v1/forms?search=contact&sort=name&order=asc", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	var result map[string]interface{}
+	ParseResponse(t, resp, &result)
+	forms := result["data"].(map[string]interface{})["forms"].([]interface{})
+	if len(forms) != 2 {
+		t.Fatalf("expected 2 matching forms, got %d", len(forms))
+	}
+	if first := forms[0].(map[string]interface{})["name"]; first != "Contact A" {
+		t.Errorf("expected Contact A first, got %v", first)
+	}
+
+	for _, query := range []string{"sort=color", "order=sideways", "status=paused"} {
+		resp := ts.Request(t, "GET", "/api/v1/forms?"+query, nil)
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", query, resp.StatusCode)
+		}
+		_ = resp.Body.Close()
+	}
+}
+
+func TestWebhookSigningSettings(t *testing.T) {
+	ts := NewTestServer(t)
+	defer ts.Close()
+
+	var created map[string]interface{}
+	ParseResponse(t, ts.Request(t, "POST", "/api/v1/forms", map[string]interface{}{"name": "Hooks"}), &created)
+	form := created["data"].(map[string]interface{})
+	if form["webhook_signature_algorithm"] != "sha256" || form["webhook_sign_timestamp"] != false {
+		t.Fatalf("expected legacy sha256 signing by default, got %v / %v", form["webhook_signature_algorithm"], form["webhook_sign_timestamp"])
+	}
+	formID := form["public_id"].(string)
+
+	resp := ts.Request(t, "PUT", "/api/v1/forms/"+formID+"/webhook/signing", map[string]interface{}{"algorithm": "md5"})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 for unsupported algorithm, got %d", resp.StatusCode)
+	}
+
+	resp = ts.Request(t, "PUT", "/api/v1/forms/"+formID+"/webhook/signing", map[string]interface{}{"algorithm": "sha512", "timestamped": true})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+
+	var got map[string]interface{}
+	ParseResponse(t, ts.Request(t, "GET", "/api/v1/forms/"+formID, nil), &got)
+	form = got["data"].(map[string]interface{})
+	if form["webhook_signature_algorithm"] != "sha512" || form["webhook_sign_timestamp"] != true {
+		t.Errorf("expected sha512 timestamped signing, got %v / %v", form["webhook_signature_algorithm"], form["webhook_sign_timestamp"])
+	}
+}
+
+func TestRotatePublicID(t *testing.T) {
+	ts := NewTestServer(t)
+	defer ts.Close()
+
+	var created map[string]interface{}
+	ParseResponse(t, ts.Request(t, "POST", "/api/v1/forms", map[string]interface{}{"name": "Scraped"}), &created)
+	oldID := created["data"].(map[string]interface{})["public_id"].(string)
+
+	resp := ts.Request(t, "POST", "/api/v1/forms/"+oldID+"/rotate-public-id", map[string]interface{}{"grace_hours": 1000})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 for a grace period over 30 days, got %d", resp.StatusCode)
+	}
+
+	var rotated map[string]interface{}
+	ParseResponse(t, ts.Request(t, "POST", "/api/v1/forms/"+oldID+"/rotate-public-id", map[string]interface{}{"grace_hours": 24}), &rotated)
+	form := rotated["data"].(map[string]interface{})
+	newID, _ := form["public_id"].(string)
+	if newID == "" || newID == oldID || form["previous_public_id"] != oldID || form["previous_public_id_until"] == nil {
+		t.Fatalf("unexpected rotated form %v", form)
+	}
+
+	// Both IDs accept submissions during the grace period; the old one is marked deprecated
+	resp = ts.Request(t, "POST", "/api/v1/submissions/"+oldID, map[string]interface{}{"msg": "old"})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201 on the old ID, got %d", resp.StatusCode)
+	}
+	if resp.Header.Get("Deprecation") != "true" || resp.Header.Get("Sunset") == "" || !strings.Contains(resp.Header.Get("Link"), newID) {
+		t.Errorf("expected deprecation headers pointing to %s, got %v", newID, resp.Header)
+	}
+	resp = ts.Request(t, "POST", "/api/v1/submissions/"+newID, map[string]interface{}{"msg": "new"})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated || resp.Header.Get("Deprecation") != "" {
+		t.Errorf("expected 201 without deprecation on the new ID, got %d %v", resp.StatusCode, resp.Header)
+	}
+
+	// Management endpoints only know the new ID
+	resp = ts.Request(t, "GET", "/api/v1/forms/"+oldID, nil)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404 for the old ID, got %d", resp.StatusCode)
+	}
+
+	// Rotating again without a grace period retires both earlier IDs at once
+	ParseResponse(t, ts.Request(t, "POST", "/api/v1/forms/"+newID+"/rotate-public-id", nil), &rotated)
+	form = rotated["data"].(map[string]interface{})
+	if form["previous_public_id"] != nil {
+		t.Errorf("expected no previous ID without a grace period, got %v", form["previous_public_id"])
+	}
+	for _, id := range []string{oldID, newID} {
+		resp = ts.Request(t, "POST", "/api/v1/submissions/"+id, map[string]interface{}{"msg": "late"})
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("expected 404 submitting to retired ID %s, got %d", id, resp.StatusCode)
+		}
+	}
+}
+
+func TestFormEmbed(t *testing.T) {
+	ts := NewTestServer(t)
+	defer ts.Close()
+	handler := middleware.SecurityHeaders()(ts.Mux)
+
+	var created map[string]interface{}
+	ParseResponse(t, ts.Request(t, "POST", "/api/v1/forms", map[string]interface{}{"name": "Embedded"}), &created)
+	formID := created["data"].(map[string]interface{})["public_id"].(string)
+
+	submit := func() http.Header {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/api/v1/submissions/"+formID, strings.NewReader(`{"email":"a@example.com"}`))
+		req.Header.Set("Content-Type", "application/json")
+		handler.ServeHTTP(w, req)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+		}
+		return w.Header()
+	}
+
+	// Not embeddable until the form opts in
+	if h := submit(); h.Get("X-Frame-Options") != "DENY" || !strings.Contains(h.Get("Content-Security-Policy"), "frame-ancestors 'none'") {
+		t.Errorf("expected framing denied by default, got %v", h)
+	}
+
+	resp := ts.Request(t, "PUT", "/api/v1/forms/"+formID+"/embed", map[string]interface{}{"frame_ancestors": []string{"https://example.com/page"}})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 for an ancestor with a path, got %d", resp.StatusCode)
+	}
+
+	var updated map[string]interface{}
+	resp = ts.Request(t, "PUT", "/api/v1/forms/"+formID+"/embed", map[string]interface{}{"frame_ancestors": []string{"https://Example.com", "https://*.example.org"}})
+	ParseResponse(t, resp, &updated)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+
+	h := submit()
+	if h.Get("X-Frame-Options") != "" {
+		t.Errorf("expected no X-Frame-Options on an embeddable form, got %q", h.Get("X-Frame-Options"))
+	}
+	if csp := h.Get("Content-Security-Policy"); !strings.HasSuffix(csp, "frame-ancestors https://example.com https://*.example.org") {
+		t.Errorf("unexpected CSP %q", csp)
+	}
+
+	// The dashboard stays locked down
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/forms/"+formID, nil))
+	if w.Header().Get("X-Frame-Options") != "DENY" {
+		t.Errorf("expected dashboard routes to deny framing, got %v", w.Header())
+	}
+}
+
+func TestSubmissionCORS(t *testing.T) {
+	ts := NewTestServer(t)
+	defer ts.Close()
+	// Production CORS settings; the submission endpoint must not depend on them
+	handler := middleware.CORSMiddleware(middleware.SecurityConfig{AllowedOrigins: []string{"https://dashboard.example"}})(ts.Mux)
+
+	var open, restricted map[string]interface{}
+	ParseResponse(t, ts.Request(t, "PUT", "/api/v1/form-specs/open", map[string]interface{}{"name": "Open"}), &open)
+	ParseResponse(t, ts.Request(t, "PUT", "/api/v1/form-specs/restricted", map[string]interface{}{"name": "Restricted", "allowed_origins": []string{"https://site.example"}}), &restricted)
+	openID := open["data"].(map[string]interface{})["form"].(map[string]interface{})["public_id"].(string)
+	restrictedID := restricted["data"].(map[string]interface{})["form"].(map[string]interface{})["public_id"].(string)
+
+	do := func(method, formID, origin string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/api/v1/submissions/"+formID, strings.NewReader(`{"email":"a@example.com"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Origin", origin)
+		if method == http.MethodOptions {
+			req.Header.Set("Access-Control-Request-Method", "POST")
+			req.Header.Set("Access-Control-Request-Headers", "content-type")
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	// Public forms accept every origin with a wildcard
+	w := do(http.MethodOptions, openID, "https://anywhere.example")
+	if w.Code != http.StatusNoContent || w.Header().Get("Access-Control-Allow-Origin") != "*" {
+		t.Fatalf("expected wildcard preflight, got %d %v", w.Code, w.Header())
+	}
+	if !strings.Contains(w.Header().Get("Access-Control-Allow-Headers"), "Content-Type") || w.Header().Get("Access-Control-Allow-Credentials") != "" {
+		t.Errorf("unexpected preflight headers %v", w.Header())
+	}
+	if w = do(http.MethodPost, openID, "https://anywhere.example"); w.Code != http.StatusCreated || w.Header().Get("Access-Control-Allow-Origin") != "*" {
+		t.Errorf("expected wildcard submission, got %d %v", w.Code, w.Header())
+	}
+
+	// Restricted forms echo allowed origins and reject others
+	w = do(http.MethodOptions, restrictedID, "https://site.example")
+	if w.Code != http.StatusNoContent || w.Header().Get("Access-Control-Allow-Origin") != "https://site.example" || w.Header().Get("Vary") != "Origin" {
+		t.Errorf("expected allowed origin echoed, got %d %v", w.Code, w.Header())
+	}
+	if w = do(http.MethodOptions, restrictedID, "https://evil.example"); w.Code != http.StatusForbidden || w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("expected rejected preflight, got %d %v", w.Code, w.Header())
+	}
+	w = do(http.MethodPost, restrictedID, "https://evil.example")
+	if w.Code != http.StatusForbidden || !strings.Contains(w.Body.String(), "ORIGIN_NOT_ALLOWED") {
+		t.Errorf("expected 403 ORIGIN_NOT_ALLOWED, got %d %s", w.Code, w.Body.String())
+	}
+	if n, _ := ts.Store.Submission().GetByFormID(context.Background(), restricted["data"].(map[string]interface{})["form"].(map[string]interface{})["id"].(string)); len(n) != 0 {
+		t.Errorf("expected the rejected submission not to be stored, got %d", len(n))
+	}
+
+	// Server-side posts without an Origin header are unaffected
+	if w = do(http.MethodPost, restrictedID, ""); w.Code != http.StatusCreated {
+		t.Errorf("expected 201 without Origin, got %d", w.Code)
+	}
+
+	// Without an Origin the Referer's origin is checked
+	for referer, want := range map[string]int{
+		"https://site.example/contact?ref=1": http.StatusCreated,
+		"https://evil.example/contact":       http.StatusForbidden,
+		"not a url":                          http.StatusForbidden,
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/submissions/"+restrictedID, strings.NewReader(`{"email":"a@example.com"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Referer", referer)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if w.Code != want || w.Header().Get("Access-Control-Allow-Origin") != "" {
+			t.Errorf("referer %q: expected %d without CORS headers, got %d %v", referer, want, w.Code, w.Header())
+		}
+	}
+
+	// Other routes keep the global policy
+	if w = do(http.MethodOptions, "", "https://evil.example"); w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("expected the global policy elsewhere, got %v", w.Header())
+	}
+}
+
+func TestSignedSubmission(t *testing.T) {
+	ts := NewTestServer(t)
+	defer ts.Close()
+
+	var created map[string]interface{}
+	ParseResponse(t, ts.Request(t, "POST", "/api/v1/forms", map[string]interface{}{
+		"name": "Backend", "access_mode": "private", "submission_key": "s3cret",
+	}), &created)
+	formID := created["data"].(map[string]interface{})["public_id"].(string)
+
+	timestamp := time.Now().UTC().Format(time.RFC3339)
+	submit := func(body, signature, timestamp string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/v1/submissions/"+formID, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if signature != "" {
+			req.Header.Set(api.SignatureHeader, signature)
+		}
+		if timestamp != "" {
+			req.Header.Set(api.SignatureTimestampHeader, timestamp)
+		}
+		w := httptest.NewRecorder()
+		ts.Mux.ServeHTTP(w, req)
+		return w
+	}
+	sign := func(prefix, body string) string {
+		mac := hmac.New(sha256.New, []byte("s3cret"))
+		mac.Write([]byte(prefix + body))
+		return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	}
+	body := `{"email":"backend@example.com"}`
+	signature := sign(timestamp+".", body)
+
+	if w := submit(body, "", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 without a signature, got %d", w.Code)
+	}
+	if w := submit(`{"email":"attacker@example.com"}`, signature, timestamp); w.Code != http.StatusUnauthorized || !strings.Contains(w.Body.String(), "INVALID_SIGNATURE") {
+		t.Errorf("expected 401 INVALID_SIGNATURE for a tampered body, got %d %s", w.Code, w.Body.String())
+	}
+	if w := submit(body, sign("", body), ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 without a timestamp, got %d %s", w.Code, w.Body.String())
+	}
+	old := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	if w := submit(body, sign(old+".", body), old); w.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 for a replayed timestamp, got %d %s", w.Code, w.Body.String())
+	}
+	// Signed bodies are limited before they're read for the signature
+	large := `{"email":"backend@example.com","note":"` + strings.Repeat("x", 2<<20) + `"}`
+	if w := submit(large, sign(timestamp+".", large), timestamp); w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected 413 for an oversized signed body, got %d", w.Code)
+	}
+
+	w := submit(body, signature, timestamp)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201 for a signed submission, got %d %s", w.Code, w.Body.String())
+	}
+	var result map[string]interface{}
+	_ = json.Unmarshal(w.Body.Bytes(), &result)
+	data := result["data"].(map[string]interface{})
+	if data["data"].(map[string]interface{})["email"] != "backend@example.com" {
+		t.Errorf("expected the signed body to be stored, got %v", data["data"])
+	}
+	if auth, _ := data["meta"].(map[string]interface{})["_auth"].(map[string]interface{}); auth["signed"] != true {
+		t.Errorf("expected the submission marked as signed, got %v", data["meta"])
+	}
+}
+
+func TestIdempotentSubmission(t *testing.T) {
+	ts := NewTestServer(t)
+	defer ts.Close()
+	var created map[string]interface{}
+	ParseResponse(t, ts.Request(t, "POST", "/api/v1/forms", map[string]interface{}{"name": "Retry Form"}), &created)
+	formID := created["data"].(map[string]interface{})["public_id"].(string)
+
+	submit := func(key, email string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/v1/submissions/"+formID, strings.NewReader(`{"email":"`+email+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(api.IdempotencyHeader, key)
+		w := httptest.NewRecorder()
+		ts.Mux.ServeHTTP(w, req)
+		return w
+	}
+	submissionID := func(w *httptest.ResponseRecorder) string {
+		var result map[string]interface{}
+		_ = json.Unmarshal(w.Body.Bytes(), &result)
+		return result["data"].(map[string]interface{})["id"].(string)
+	}
+
+	first := submit("attempt-1", "a@example.com")
+	if first.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d %s", first.Code, first.Body.String())
+	}
+	retry := submit("attempt-1", "a@example.com")
+	if retry.Code != http.StatusCreated || retry.Header().Get("Idempotent-Replayed") != "true" {
+		t.Fatalf("expected a replayed 201, got %d %v", retry.Code, retry.Header())
+	}
+	if submissionID(retry) != submissionID(first) {
+		t.Errorf("expected the retry to return the original submission")
+	}
+	if other := submit("attempt-2", "b@example.com"); other.Code != http.StatusCreated || submissionID(other) == submissionID(first) {
+		t.Errorf("expected a new key to create a new submission, got %d", other.Code)
+	}
+	if long := submit(strings.Repeat("k", 256), "c@example.com"); long.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an oversized key, got %d", long.Code)
+	}
+
+	var list map[string]interface{}
+	ParseResponse(t, ts.Request(t, "GET", "/api/v1/forms/"+formID+"/submissions", nil), &list)
+	if subs := list["data"].(map[string]interface{})["submissions"].([]interface{}); len(subs) != 2 {
+		t.Errorf("expected 2 stored submissions, got %d", len(subs))
+	}
+}
+
+func TestFormFields(t *testing.T) {
+	ts := NewTestServer(t)
+	defer ts.Close()
+
+	var created map[string]interface{}
+	ParseResponse(t, ts.Request(t, "POST", "/api/v1/forms", map[string]interface{}{"name": "Contact"}), &created)
+	formID := created["data"].(map[string]interface{})["public_id"].(string)
+
+	schema := map[string]interface{}{"fields": []map[string]interface{}{
+		{"name": "email", "label": "Email", "placeholder": "you@example.com", "translations": map[string]interface{}{
+			"de":    map[string]string{"label": "E-Mail", "placeholder": "sie@beispiel.de"},
+			"pt_BR": map[string]string{"label": "E-mail"},
+		}},
+		{"name": "message", "label": "Message", "help": "Max. 500 words", "translations": map[string]interface{}{
+			"de": map[string]string{"label": "Nachricht"},
+		}},
+	}}
+	if resp := ts.Request(t, "PUT", "/api/v1/forms/"+formID+"/fields", schema); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+
+	fetch := func(query, acceptLanguage string) (map[string]interface{}, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest("GET", "/api/v1/forms/"+formID+"/fields"+query, nil)
+		if acceptLanguage != "" {
+			req.Header.Set("Accept-Language", acceptLanguage)
+		}
+		w := httptest.NewRecorder()
+		ts.Mux.ServeHTTP(w, req)
+		var result map[string]interface{}
+		_ = json.Unmarshal(w.Body.Bytes(), &result)
+		data, _ := result["data"].(map[string]interface{})
+		return data, w
+	}
+	field := func(data map[string]interface{}, i int) map[string]interface{} {
+		return data["fields"].([]interface{})[i].(map[string]interface{})
+	}
+
+	// Accept-Language picks the closest translation; untranslated parts fall back
+	data, w := fetch("", "fr, de-AT;q=0.9, en;q=0.5")
+	if data["locale"] != "de" || w.Header().Get("Content-Language") != "de" {
+		t.Errorf("expected German, got %v %v", data["locale"], w.Header())
+	}
+	if f := field(data, 1); f["label"] != "Nachricht" || f["help"] != "Max. 500 words" {
+		t.Errorf("expected translated label with default help, got %v", f)
+	}
+	if locales := data["locales"].([]interface{}); len(locales) != 2 || locales[0] != "de" || locales[1] != "pt-br" {
+		t.Errorf("expected normalized locales, got %v", locales)
+	}
+
+	// ?lang= wins over the header; unknown languages get the default text
+	if data, _ = fetch("?lang=pt-BR", "de"); data["locale"] != "pt-br" || field(data, 0)["label"] != "E-mail" {
+		t.Errorf("expected ?lang= to pick Portuguese, got %v", data)
+	}
+	if data, _ = fetch("", "ja"); data["locale"] != "" || field(data, 0)["placeholder"] != "you@example.com" {
+		t.Errorf("expected the default text, got %v", data)
+	}
+
+	// The schema is part of the form and its spec
+	var form map[string]interface{}
+	ParseResponse(t, ts.Request(t, "GET", "/api/v1/forms/"+formID, nil), &form)
+	if fields, _ := form["data"].(map[string]interface{})["fields"].([]interface{}); len(fields) != 2 {
+		t.Errorf("expected the stored schema on the form, got %v", form["data"])
+	}
+
+	invalid := map[string]map[string]interface{}{
+		"missing name": {"fields": []map[string]interface{}{{"label": "Email"}}},
+		"duplicate":    {"fields": []map[string]interface{}{{"name": "a"}, {"name": "a"}}},
+		"bad locale":   {"fields": []map[string]interface{}{{"name": "a", "translations": map[string]interface{}{"not a tag": map[string]string{"label": "x"}}}}},
+	}
+	for name, body := range invalid {
+		if resp := ts.Request(t, "PUT", "/api/v1/forms/"+formID+"/fields", body); resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", name, resp.StatusCode)
+		}
+	}
+
+	// Field rules are enforced on submissions, with an error per field
+	rules := map[string]interface{}{"fields": []map[string]interface{}{
+		{"name": "email", "label": "Email", "type": "email", "required": true},
+		{"name": "message", "max_length": 10},
+	}}
+	if resp := ts.Request(t, "PUT", "/api/v1/forms/"+formID+"/fields", rules); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 for rules, got %d", resp.StatusCode)
+	}
+	if data, _ = fetch("", ""); field(data, 0)["type"] != "email" || field(data, 0)["required"] != true {
+		t.Errorf("expected the rules in the public fields, got %v", data)
+	}
+	resp := ts.Request(t, "POST", "/api/v1/submissions/"+formID, map[string]interface{}{"email": "nope", "message": "far too long"})
+	var failed map[string]interface{}
+	ParseResponse(t, resp, &failed)
+	if resp.StatusCode != http.StatusBadRequest || failed["status"] != "fail" || failed["code"] != "INVALID_FIELDS" {
+		t.Fatalf("expected 400 INVALID_FIELDS, got %d %v", resp.StatusCode, failed)
+	}
+	if errs := failed["data"].(map[string]interface{})["fields"].([]interface{}); len(errs) != 2 {
+		t.Errorf("expected 2 field errors, got %v", errs)
+	}
+	if resp := ts.Request(t, "POST", "/api/v1/submissions/"+formID, map[string]interface{}{"email": "ada@example.com", "message": "Hi"}); resp.StatusCode != http.StatusCreated {
+		t.Errorf("expected a valid submission to be accepted, got %d", resp.StatusCode)
+	}
+}
+
+func TestFileURLFields(t *testing.T) {
+	ts := NewTestServer(t)
+	defer ts.Close()
+
+	files := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/cv.pdf", "/big.pdf":
+			w.Header().Set("Content-Type", "application/pdf")
+			w.Header().Set("Content-Length", map[string]string{"/cv.pdf": "500", "/big.pdf": "5000"}[r.URL.Path])
+		case "/photo.png":
+			w.Header().Set("Content-Type", "image/png")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer files.Close()
+	ts.Router.SetLinkChecker(&linkcheck.Checker{Client: files.Client()})
+
+	var created map[string]interface{}
+	ParseResponse(t, ts.Request(t, "POST", "/api/v1/forms", map[string]interface{}{"name": "Jobs"}), &created)
+	formID := created["data"].(map[string]interface{})["public_id"].(string)
+
+	for _, rules := range []map[string]interface{}{
+		{"allowed_hosts": []string{"https://example.com"}},
+		{"max_size": -1},
+		{"allowed_types": []string{"pdf"}},
+	} {
+		body := map[string]interface{}{"fields": []map[string]interface{}{{"name": "cv", "type": "file_url", "file_url": rules}}}
+		if resp := ts.Request(t, "PUT", "/api/v1/forms/"+formID+"/fields", body); resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("expected 400 for %v, got %d", rules, resp.StatusCode)
+		}
+	}
+
+	schema := map[string]interface{}{"fields": []map[string]interface{}{
+		{"name": "cv", "type": "file_url", "required": true, "file_url": map[string]interface{}{
+			"allowed_hosts": []string{"127.0.0.1"}, "verify": true, "max_size": 1000, "allowed_types": []string{"application/pdf"},
+		}},
+		{"name": "portfolio", "type": "file_url", "file_url": map[string]interface{}{"allowed_hosts": []string{"*.Example.com"}}},
+	}}
+	if resp := ts.Request(t, "PUT", "/api/v1/forms/"+formID+"/fields", schema); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	submit := func(data map[string]interface{}) (int, map[string]interface{}) {
+		resp := ts.Request(t, "POST", "/api/v1/submissions/"+formID, data)
+		var result map[string]interface{}
+		ParseResponse(t, resp, &result)
+		return resp.StatusCode, result
+	}
+	rule := func(result map[string]interface{}) string {
+		data, _ := result["data"].(map[string]interface{})
+		errs, _ := data["fields"].([]interface{})
+		if len(errs) != 1 {
+			return fmt.Sprint(result)
+		}
+		return errs[0].(map[string]interface{})["rule"].(string)
+	}
+
+	for _, tc := range []struct {
+		data map[string]interface{}
+		rule string
+	}{
+		{map[string]interface{}{"cv": strings.Replace(files.URL, "https", "http", 1) + "/cv.pdf"}, "type"},
+		{map[string]interface{}{"cv": "https://files.example.net/cv.pdf"}, "host"},
+		{map[string]interface{}{"cv": files.URL + "/cv.pdf", "portfolio": "https://example.com.evil.net/work"}, "host"},
+		{map[string]interface{}{"cv": files.URL + "/big.pdf"}, "max_size"},
+		{map[string]interface{}{"cv": files.URL + "/photo.png"}, "type"},
+	} {
+		if status, result := submit(tc.data); status != http.StatusBadRequest || rule(result) != tc.rule {
+			t.Errorf("expected a %s error for %v, got %d %v", tc.rule, tc.data, status, result)
+		}
+	}
+
+	status, result := submit(map[string]interface{}{"cv": files.URL + "/cv.pdf", "portfolio": "https://me.example.com/work"})
+	if status != http.StatusCreated {
+		t.Fatalf("expected 201, got %d %v", status, result)
+	}
+	links := result["data"].(map[string]interface{})["meta"].(map[string]interface{})["_links"].([]interface{})
+	if link := links[0].(map[string]interface{}); len(links) != 1 || link["field"] != "cv" || link["verified"] != true ||
+		link["size"] != float64(500) || link["content_type"] != "application/pdf" {
+		t.Errorf("expected the verified cv link in the meta, got %v", links)
+	}
+
+	// Links that can't be verified are accepted, with the reason recorded
+	status, result = submit(map[string]interface{}{"cv": files.URL + "/gone.pdf"})
+	if status != http.StatusCreated {
+		t.Fatalf("expected 201 for a dead link, got %d %v", status, result)
+	}
+	links = result["data"].(map[string]interface{})["meta"].(map[string]interface{})["_links"].([]interface{})
+	if link := links[0].(map[string]interface{}); link["verified"] != false || link["status"] != float64(404) {
+		t.Errorf("expected the 404 recorded, got %v", links)
+	}
+
+	// The default checker doesn't connect to local addresses
+	ts.Router.SetLinkChecker(linkcheck.NewChecker())
+	status, result = submit(map[string]interface{}{"cv": files.URL + "/cv.pdf"})
+	if status != http.StatusCreated {
+		t.Fatalf("expected 201, got %d %v", status, result)
+	}
+	links = result["data"].(map[string]interface{})["meta"].(map[string]interface{})["_links"].([]interface{})
+	if link := links[0].(map[string]interface{}); link["verified"] != false || link["error"] != "link points to a private address" {
+		t.Errorf("expected the local link refused, got %v", links)
+	}
+}
+
+func TestFieldStats(t *testing.T) {
+	ts := NewTestServer(t)
+	defer ts.Close()
+
+	var created map[string]interface{}
+	ParseResponse(t, ts.Request(t, "POST", "/api/v1/forms", map[string]interface{}{"name": "Quote"}), &created)
+	formID := created["data"].(map[string]interface{})["public_id"].(string)
+
+	schema := map[string]interface{}{"fields": []map[string]interface{}{
+		{"name": "budget", "type": "number"},
+		{"name": "start", "type": "date"},
+		{"name": "notes"},
+	}}
+	if resp := ts.Request(t, "PUT", "/api/v1/forms/"+formID+"/fields", schema); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	for _, data := range []map[string]interface{}{
+		{"budget": 500, "start": "2026-03-01"},
+		{"budget": "1500", "start": "2026-03-03"},
+	} {
+		if resp := ts.Request(t, "POST", "/api/v1/submissions/"+formID, data); resp.StatusCode != http.StatusCreated {
+			t.Fatalf("expected 201, got %d", resp.StatusCode)
+		}
+	}
+
+	resp := ts.Request(t, "GET", "/api/v1/forms/"+formID+"/stats/fields", nil)
+	var result map[string]interface{}
+	ParseResponse(t, resp, &result)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d %v", resp.StatusCode, result)
+	}
+	stats := result["data"].([]interface{})
+	if len(stats) != 2 {
+		t.Fatalf("expected stats for the number and date fields, got %v", stats)
+	}
+	budget := stats[0].(map[string]interface{})["number"].(map[string]interface{})
+	if budget["sum"] != float64(2000) || budget["average"] != float64(1000) {
+		t.Errorf("unexpected budget stats %v", budget)
+	}
+	start := stats[1].(map[string]interface{})["date"].(map[string]interface{})
+	if start["min"] != "2026-03-01" || start["max"] != "2026-03-03" || len(start["histogram"].([]interface{})) != 3 {
+		t.Errorf("unexpected start stats %v", start)
+	}
+}
+
+func TestStatsTimezone(t *testing.T) {
+	// 26 hours apart, so "today" is never the same date in both
+	const userZone, formZone = "Pacific/Kiritimati", "Etc/GMT+12"
+	userLoc, err := time.LoadLocation(userZone)
+	if err != nil {
+		t.Skipf("time zone database unavailable: %v", err)
+	}
+	formLoc, _ := time.LoadLocation(formZone)
+
+	ts := NewUserTestServer(t, &domain.User{ID: "user-1", Email: "user@example.com", PasswordHash: "x", Role: "user", Timezone: userZone})
+	defer ts.Close()
+
+	var created map[string]interface{}
+	ParseResponse(t, ts.Request(t, "POST", "/api/v1/forms", map[string]interface{}{"name": "Contact"}), &created)
+	formID := created["data"].(map[string]interface{})["public_id"].(string)
+	if resp := ts.Request(t, "POST", "/api/v1/submissions/"+formID, map[string]string{"email": "a@example.com"}); resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", resp.StatusCode)
+	}
+
+	// The dashboard counts days in the user's timezone
+	var stats map[string]interface{}
+	ParseResponse(t, ts.Request(t, "GET", "/api/v1/stats", nil), &stats)
+	data := stats["data"].(map[string]interface{})
+	daily := data["daily_submissions"].([]interface{})
+	today := daily[len(daily)-1].(map[string]interface{})
+	if today["date"] != time.Now().In(userLoc).Format("2006-01-02") || today["count"] != float64(1) || data["submissions_today"] != float64(1) {
+		t.Errorf("expected today's submission on the user's date, got %v", data)
+	}
+
+	exportedToday := func() string {
+		req := httptest.NewRequest("GET", "/api/v1/forms/"+formID+"/stats/export", nil)
+		w := httptest.NewRecorder()
+		ts.Mux.ServeHTTP(w, req)
+		lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+		return lines[len(lines)-1]
+	}
+
+	// Forms without a timezone use their owner's
+	if last := exportedToday(); !strings.HasPrefix(last, time.Now().In(userLoc).Format("2006-01-02")+",1,") {
+		t.Errorf("expected the export to end on the owner's today, got %q", last)
+	}
+
+	if resp := ts.Request(t, "PUT", "/api/v1/forms/"+formID+"/timezone", map[string]string{"timezone": "Mars/Olympus"}); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 for an unknown timezone, got %d", resp.StatusCode)
+	}
+	var updated map[string]interface{}
+	ParseResponse(t, ts.Request(t, "PUT", "/api/v1/forms/"+formID+"/timezone", map[string]string{"timezone": formZone}), &updated)
+	if tz := updated["data"].(map[string]interface{})["timezone"]; tz != formZone {
+		t.Fatalf("expected timezone %s, got %v", formZone, tz)
+	}
+
+	if last := exportedToday(); !strings.HasPrefix(last, time.Now().In(formLoc).Format("2006-01-02")+",1,") {
+		t.Errorf("expected the export to end on the form's today, got %q", last)
+	}
+	ParseResponse(t, ts.Request(t, "GET", "/api/v1/forms/"+formID+"/stats", nil), &stats)
+	if n := stats["data"].(map[string]interface{})["submissions_today"]; n != float64(1) {
+		t.Errorf("expected 1 submission today in the form's timezone, got %v", n)
+	}
+}
+
+func TestExportStatsCSV(t *testing.T) {
+	ts := NewTestServer(t)
+	defer ts.Close()
+
+	var created map[string]interface{}
+	ParseResponse(t, ts.Request(t, "POST", "/api/v1/forms", map[string]interface{}{"name": `Q3 "launch"`}), &created)
+	publicID := created["data"].(map[string]interface{})["public_id"].(string)
+
+	// Referers are whatever the client sent, formulas included
+	req, _ := http.NewRequest("POST", ts.Server.URL+"/api/v1/submissions/"+publicID, strings.NewReader(`{"email": "ada@example.com"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Referer", `=HYPERLINK("https://evil.example","x")`)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", resp.StatusCode)
+	}
+
+	resp = ts.Request(t, "GET", "/api/v1/forms/"+publicID+"/stats/export", nil)
+	defer resp.Body.Close()
+	if _, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition")); err != nil || !strings.HasPrefix(params["filename"], `Q3 "launch"_stats_`) {
+		t.Errorf("expected the form name in the filename, got %q (%v)", resp.Header.Get("Content-Disposition"), err)
+	}
+	rows, err := csv.NewReader(resp.Body).ReadAll()
+	if err != nil || len(rows) != 31 {
+		t.Fatalf("expected a header and 30 days, got %d rows (%v)", len(rows), err)
+	}
+	today := rows[len(rows)-1]
+	if today[1] != "1" || today[4] != `'=HYPERLINK("https://evil.example","x")` {
+		t.Errorf("expected today's submission with its referer kept from being a formula, got %q", today)
+	}
+}
+
+func TestFormSpecs(t *testing.T) {
+	ts := NewTestServer(t)
+	defer ts.Close()
+
+	spec := map[string]interface{}{
+		"name":          "Contact",
+		"notify_emails": []string{"team@example.com"},
+		"webhook":       map[string]interface{}{"url": "https://hooks.example.com/contact", "signature_algorithm": "sha512"},
+		"consent":       map[string]interface{}{"fields": []string{"terms"}, "version": "2026-01"},
+	}
+	apply := func(body interface{}) (int, map[string]interface{}) {
+		resp := ts.Request(t, "PUT", "/api/v1/form-specs/contact-us", body)
+		var result map[string]interface{}
+		ParseResponse(t, resp, &result)
+		return resp.StatusCode, result
+	}
+
+	status, result := apply(spec)
+	if status != http.StatusCreated {
+		t.Fatalf("expected 201, got %d %v", status, result)
+	}
+	data := result["data"].(map[string]interface{})
+	form := data["form"].(map[string]interface{})
+	if data["result"] != "created" || form["slug"] != "contact-us" || form["webhook_signature_algorithm"] != "sha512" || form["access_mode"] != "public" {
+		t.Fatalf("unexpected created form %v", data)
+	}
+	publicID := form["public_id"].(string)
+
+	status, result = apply(spec)
+	if data := result["data"].(map[string]interface{}); status != http.StatusOK || data["result"] != "unchanged" {
+		t.Fatalf("expected re-applying to be a no-op, got %d %v", status, result)
+	}
+
+	spec["status"] = "inactive"
+	delete(spec, "webhook")
+	_, result = apply(spec)
+	data = result["data"].(map[string]interface{})
+	form = data["form"].(map[string]interface{})
+	if data["result"] != "updated" || form["public_id"] != publicID || form["status"] != "inactive" || form["webhook_url"] != nil {
+		t.Fatalf("expected the same form updated without a webhook, got %v", data)
+	}
+
+	var got map[string]interface{}
+	ParseResponse(t, ts.Request(t, "GET", "/api/v1/form-specs/contact-us", nil), &got)
+	gotSpec := got["data"].(map[string]interface{})["spec"].(map[string]interface{})
+	if gotSpec["name"] != "Contact" || gotSpec["status"] != "inactive" || gotSpec["webhook"] != nil {
+		t.Errorf("unexpected spec %v", gotSpec)
+	}
+
+	for name, tc := range map[string]struct {
+		path string
+		body interface{}
+	}{
+		"invalid slug":  {"/api/v1/form-specs/Not_A_Slug", map[string]interface{}{"name": "X"}},
+		"unknown field": {"/api/v1/form-specs/contact-us", map[string]interface{}{"name": "X", "notify_email": "typo"}},
+		"bad access":    {"/api/v1/form-specs/contact-us", map[string]interface{}{"name": "X", "access_mode": "secret"}},
+		"missing name":  {"/api/v1/form-specs/contact-us", map[string]interface{}{}},
+	} {
+		resp := ts.Request(t, "PUT", tc.path, tc.body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", name, resp.StatusCode)
+		}
+	}
+}
+
+func TestFormStars(t *testing.T) {
+	ts := NewUserTestServer(t, &domain.User{ID: "user-1", Email: "user@example.com", PasswordHash: "x", Role: "user"})
+	defer ts.Close()
+
+	var publicIDs []string
+	for _, name := range []string{"Daily", "Rarely"} {
+		var created map[string]interface{}
+		ParseResponse(t, ts.Request(t, "POST", "/api/v1/forms", map[string]interface{}{"name": name}), &created)
+		publicIDs = append(publicIDs, created["data"].(map[string]interface{})["public_id"].(string))
+	}
+
+	var starred map[string]interface{}
+	resp := ts.Request(t, "PUT", "/api/v1/forms/"+publicIDs[0]+"/star", nil)
+	ParseResponse(t, resp, &starred)
+	if resp.StatusCode != http.StatusOK || starred["data"].(map[string]interface{})["starred"] != true {
+		t.Fatalf("expected starred form, got %d %v", resp.StatusCode, starred)
+	}
+
+	listStarred := func() []interface{} {
+		var result map[string]interface{}
+		ParseResponse(t, ts.Request(t, "GET", "/api/v1/forms?starred=true", nil), &result)
+		forms, _ := result["data"].(map[string]interface{})["forms"].([]interface{})
+		return forms
+	}
+	if forms := listStarred(); len(forms) != 1 || forms[0].(map[string]interface{})["name"] != "Daily" {
+		t.Fatalf("expected only the starred form, got %v", forms)
+	}
+
+	resp = ts.Request(t, "DELETE", "/api/v1/forms/"+publicIDs[0]+"/star", nil)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 on unstar, got %d", resp.StatusCode)
+	}
+	if forms := listStarred(); len(forms) != 0 {
+		t.Errorf("expected no starred forms after unstar, got %d", len(forms))
+	}
+
+	resp = ts.Request(t, "PUT", "/api/v1/forms/missing/star", nil)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404 for unknown form, got %d", resp.StatusCode)
+	}
+}
+
+func TestSubmissionViews(t *testing.T) {
+	ts := NewUserTestServer(t, &domain.User{ID: "user-1", Email: "user@example.com", PasswordHash: "x", Name: "Reviewer", Role: "user"})
+	defer ts.Close()
+
+	var created map[string]interface{}
+	ParseResponse(t, ts.Request(t, "POST", "/api/v1/forms", map[string]interface{}{"name": "Shared"}), &created)
+	formID := created["data"].(map[string]interface{})["public_id"].(string)
+	for i := 0; i < 2; i++ {
+		ts.Request(t, "POST", "/api/v1/submissions/"+formID, map[string]interface{}{"n": i}).Body.Close()
+	}
+
+	listCounts := func() map[string]interface{} {
+		var result map[string]interface{}
+		ParseResponse(t, ts.Request(t, "GET", "/api/v1/forms/"+formID+"/submissions", nil), &result)
+		return result["data"].(map[string]interface{})
+	}
+	data := listCounts()
+	if unopened := data["counts"].(map[string]interface{})["unopened"]; unopened != float64(2) {
+		t.Fatalf("expected 2 unopened, got %v", unopened)
+	}
+	subID := data["submissions"].([]interface{})[0].(map[string]interface{})["id"].(string)
+
+	var detail map[string]interface{}
+	ParseResponse(t, ts.Request(t, "GET", "/api/v1/submissions/"+subID, nil), &detail)
+	openedBy, _ := detail["data"].(map[string]interface{})["opened_by"].([]interface{})
+	if len(openedBy) != 1 || openedBy[0].(map[string]interface{})["name"] != "Reviewer" {
+		t.Fatalf("expected opened_by with the reviewer, got %v", detail["data"])
+	}
+
+	data = listCounts()
+	counts := data["counts"].(map[string]interface{})
+	if counts["unopened"] != float64(1) || counts["unread"] != float64(2) {
+		t.Errorf("expected 1 unopened and the shared unread count unchanged, got %v", counts)
+	}
+}
+
+func TestSubmissionComments(t *testing.T) {
+	user := &domain.User{ID: "user-1", Email: "user@example.com", PasswordHash: "x", Name: "Reviewer", Role: "user"}
+	ts := NewUserTestServer(t, user)
+	defer ts.Close()
+	api.NewCommentHandler(service.NewCommentService(ts.Store), service.NewSubmissionService(ts.Store), service.NewFormService(ts.Store)).RegisterRoutes(ts.Mux, ts.Auth)
+
+	var created map[string]interface{}
+	ParseResponse(t, ts.Request(t, "POST", "/api/v1/forms", map[string]interface{}{"name": "Leads"}), &created)
+	formID := created["data"].(map[string]interface{})["public_id"].(string)
+	var submitted map[string]interface{}
+	ParseResponse(t, ts.Request(t, "POST", "/api/v1/submissions/"+formID, map[string]interface{}{"email": "lead@example.com"}), &submitted)
+	subID := submitted["data"].(map[string]interface{})["id"].(string)
+	commentsPath := "/api/v1/submissions/" + subID + "/comments"
+
+	var comment map[string]interface{}
+	resp := ts.Request(t, "POST", commentsPath, map[string]interface{}{"body": "Called them back"})
+	ParseResponse(t, resp, &comment)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201, got %d %v", resp.StatusCode, comment)
+	}
+	commentID := comment["data"].(map[string]interface{})["id"].(string)
+
+	resp = ts.Request(t, "POST", commentsPath, map[string]interface{}{"body": "Follow-up booked", "parent_id": commentID})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201 for reply, got %d", resp.StatusCode)
+	}
+	resp = ts.Request(t, "POST", commentsPath, map[string]interface{}{"body": ""})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 for empty comment, got %d", resp.StatusCode)
+	}
+
+	var list map[string]interface{}
+	ParseResponse(t, ts.Request(t, "GET", commentsPath, nil), &list)
+	comments := list["data"].(map[string]interface{})["comments"].([]interface{})
+	if len(comments) != 2 || comments[0].(map[string]interface{})["author_name"] != "Reviewer" {
+		t.Fatalf("expected 2 comments by the reviewer, got %v", comments)
+	}
+
+	resp = ts.Request(t, "DELETE", commentsPath+"/"+commentID, nil)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 on delete, got %d", resp.StatusCode)
+	}
+	ParseResponse(t, ts.Request(t, "GET", commentsPath, nil), &list)
+	if comments := list["data"].(map[string]interface{})["comments"].([]interface{}); len(comments) != 0 {
+		t.Errorf("expected the reply to be deleted with its parent, got %d comments", len(comments))
+	}
+}
+
+func TestNotifications(t *testing.T) {
+	user := &domain.User{ID: "user-1", Email: "user@example.com", PasswordHash: "x", Name: "Owner", Role: "user"}
+	ts := NewUserTestServer(t, user)
+	defer ts.Close()
+	notifications := service.NewNotificationService(ts.Store)
+	api.NewNotificationHandler(notifications).RegisterRoutes(ts.Mux, ts.Auth)
+
+	var created map[string]interface{}
+	ParseResponse(t, ts.Request(t, "POST", "/api/v1/forms", map[string]interface{}{"name": "Leads"}), &created)
+	formID := created["data"].(map[string]interface{})["public_id"].(string)
+	resp := ts.Request(t, "PUT", "/api/v1/forms/"+formID+"/star", nil)
+	resp.Body.Close()
+
+	form, err := ts.Store.Form().GetByPublicID(context.Background(), formID)
+	if err != nil {
+		t.Fatalf("GetByPublicID failed: %v", err)
+	}
+	notifications.NotifySubmission(form, &domain.Submission{ID: "sub-1", FormID: form.ID})
+	notifications.NotifySubmission(form, &domain.Submission{ID: "sub-2", FormID: form.ID, IsTest: true})
+	notifications.NotifyWebhookFailed(formID)
+	notifications.NotifyWebhookFailed(formID) // Deduplicated while the first is unread
+
+	var count map[string]interface{}
+	ParseResponse(t, ts.Request(t, "GET", "/api/v1/notifications/unread-count", nil), &count)
+	if n := count["data"].(map[string]interface{})["unread_count"]; n != float64(2) {
+		t.Fatalf("expected 2 unread notifications, got %v", n)
+	}
+
+	var list map[string]interface{}
+	ParseResponse(t, ts.Request(t, "GET", "/api/v1/notifications", nil), &list)
+	items := list["data"].(map[string]interface{})["notifications"].([]interface{})
+	if len(items) != 2 {
+		t.Fatalf("expected 2 notifications, got %v", items)
+	}
+	var submissionNotification string
+	for _, item := range items {
+		n := item.(map[string]interface{})
+		if n["type"] == string(domain.NotificationNewSubmission) {
+			submissionNotification = n["id"].(string)
+			if n["submission_id"] != "sub-1" || n["form_id"] != formID {
+				t.Errorf("unexpected submission notification %v", n)
+			}
+		}
+	}
+	if submissionNotification == "" {
+		t.Fatalf("expected a new submission notification, got %v", items)
+	}
+
+	resp = ts.Request(t, "PUT", "/api/v1/notifications/"+submissionNotification+"/read", nil)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 marking read, got %d", resp.StatusCode)
+	}
+	resp = ts.Request(t, "PUT", "/api/v1/notifications/ntf_missing/read", nil)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404 for unknown notification, got %d", resp.StatusCode)
+	}
+
+	ParseResponse(t, ts.Request(t, "GET", "/api/v1/notifications?unread=true", nil), &list)
+	items = list["data"].(map[string]interface{})["notifications"].([]interface{})
+	if len(items) != 1 || items[0].(map[string]interface{})["type"] != string(domain.NotificationWebhookFailed) {
+		t.Fatalf("expected only the webhook notification unread, got %v", items)
+	}
+
+	var marked map[string]interface{}
+	ParseResponse(t, ts.Request(t, "PUT", "/api/v1/notifications/read-all", nil), &marked)
+	if n := marked["data"].(map[string]interface{})["marked"]; n != float64(1) {
+		t.Errorf("expected 1 marked read, got %v", n)
+	}
+	ParseResponse(t, ts.Request(t, "GET", "/api/v1/notifications/unread-count", nil), &count)
+	if n := count["data"].(map[string]interface{})["unread_count"]; n != float64(0) {
+		t.Errorf("expected no unread notifications, got %v", n)
+	}
+}
+
+// =============================================================================
+// Public Stats Tests
+// =============================================================================
+
+func TestPublicStats_OptIn(t *testing.T) {
+	ts := NewTestServer(t)
+	defer ts.Close()
+
+	createResp := ts.Request(t, "POST", "/api/v1/forms", map[string]interface{}{
+		"name": "Stats Form",
+	})
+	var createResult map[string]interface{}
+	ParseResponse(t, createResp, &createResult)
+	publicID := createResult["data"].(map[string]interface{})["public_id"].(string)
+
+	// Not exposed until the owner opts in
+	resp := ts.Request(t, "GET", "/api/v1/forms/"+publicID+"/public-stats", nil)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404 before opt-in, got %d", resp.StatusCode)
+	}
+
+	updateResp := ts.Request(t, "PUT", "/api/v1/forms/"+publicID, map[string]interface{}{
+		"name":         "Stats Form",
+		"status":       "active",
+		"public_stats": true,
+	})
+	updateResp.Body.Close()
+	ts.Request(t, "POST", "/api/v1/submissions/"+publicID, map[string]interface{}{"email": "a@example.com"}).Body.Close()
+
+	resp = ts.Request(t, "GET", "/api/v1/forms/"+publicID+"/public-stats", nil)
+	if resp.Header.Get("Cache-Control") == "" {
+		t.Error("expected Cache-Control header")
+	}
+	var result map[string]interface{}
+	ParseResponse(t, resp, &result)
+	stats := result["data"].(map[string]interface{})
+	if stats["responses"] != float64(1) || stats["label"] != "1 response" {
+		t.Errorf("unexpected stats: %v", stats)
+	}
+
+	badge := ts.Request(t, "GET", "/api/v1/forms/"+publicID+"/public-stats/badge.svg", nil)
+	defer badge.Body.Close()
+	if badge.StatusCode != http.StatusOK || badge.Header.Get("Content-Type") != "image/svg+xml" {
+		t.Errorf("unexpected badge response: %d %s", badge.StatusCode, badge.Header.Get("Content-Type"))
+	}
+}
+
+func TestAdminMaintenance(t *testing.T) {
+	store, err := sqlite.New(":memory:")
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	role := "admin"
+	asRole := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), middleware.RoleKey, role)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+	router := api.NewRouter(service.NewFormService(store), service.NewSubmissionService(store), service.NewStatsService(store))
+	maintenance := middleware.NewMaintenance()
+	router.SetMaintenance(maintenance)
+	mux := http.NewServeMux()
+	router.RegisterPublicRoutes(mux, asRole)
+	router.RegisterProtectedRoutes(mux, asRole)
+	ts := &TestServer{Server: httptest.NewServer(middleware.MaintenanceGuard(maintenance, middleware.MaintenanceAllowedRoutes)(mux)), Store: store}
+	defer ts.Server.Close()
+
+	var created map[string]interface{}
+	ParseResponse(t, ts.Request(t, "POST", "/api/v1/forms", map[string]interface{}{"name": "Contact"}), &created)
+	formID := created["data"].(map[string]interface{})["public_id"].(string)
+
+	enable := map[string]interface{}{"enabled": true, "message": "Backing up", "retry_after": 120}
+	if resp := ts.Request(t, "PUT", "/api/v1/admin/maintenance", enable); resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected 403 for admins, got %d", resp.StatusCode)
+	}
+
+	role = "super_admin"
+	var result map[string]interface{}
+	ParseResponse(t, ts.Request(t, "PUT", "/api/v1/admin/maintenance", enable), &result)
+	if state := result["data"].(map[string]interface{}); state["enabled"] != true || state["retry_after"] != float64(120) {
+		t.Fatalf("expected maintenance enabled, got %v", result)
+	}
+
+	// Submissions and dashboard writes are refused, reads still work
+	resp := ts.Request(t, "POST", "/api/v1/submissions/"+formID, map[string]string{"email": "a@example.com"})
+	ParseResponse(t, resp, &result)
+	if resp.StatusCode != http.StatusServiceUnavailable || resp.Header.Get("Retry-After") != "120" || result["code"] != "MAINTENANCE" {
+		t.Errorf("expected 503 MAINTENANCE with Retry-After, got %d %v %v", resp.StatusCode, resp.Header, result)
+	}
+	if resp := ts.Request(t, "POST", "/api/v1/forms", map[string]interface{}{"name": "Another"}); resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 for dashboard writes, got %d", resp.StatusCode)
+	}
+	if resp := ts.Request(t, "GET", "/api/v1/forms/"+formID, nil); resp.StatusCode != http.StatusOK {
+		t.Errorf("expected reads to work, got %d", resp.StatusCode)
+	}
+	ParseResponse(t, ts.Request(t, "GET", "/api/health", nil), &result)
+	if result["data"].(map[string]interface{})["maintenance"] != true {
+		t.Errorf("expected health to report maintenance, got %v", result)
+	}
+
+	ts.Request(t, "PUT", "/api/v1/admin/maintenance", map[string]interface{}{"enabled": false}).Body.Close()
+	if resp := ts.Request(t, "POST", "/api/v1/submissions/"+formID, map[string]string{"email": "a@example.com"}); resp.StatusCode != http.StatusCreated {
+		t.Errorf("expected submissions to be accepted again, got %d", resp.StatusCode)
+	}
+}
+
+func TestAdminHTTPStats(t *testing.T) {
+	store, err := sqlite.New(":memory:")
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	role := "admin"
+	asRole := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), middleware.RoleKey, role)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+	router := api.NewRouter(service.NewFormService(store), service.NewSubmissionService(store), service.NewStatsService(store))
+	collector := httpstats.NewCollector()
+	router.SetHTTPStats(collector)
+	mux := http.NewServeMux()
+	router.RegisterPublicRoutes(mux, asRole)
+	router.RegisterProtectedRoutes(mux, asRole)
+	ts := &TestServer{Server: httptest.NewServer(collector.Middleware(mux)), Store: store}
+	defer ts.Server.Close()
+
+	var created map[string]interface{}
+	ParseResponse(t, ts.Request(t, "POST", "/api/v1/forms", map[string]interface{}{"name": "Contact"}), &created)
+	formID := created["data"].(map[string]interface{})["public_id"].(string)
+	for i := 0; i < 3; i++ {
+		ts.Request(t, "POST", "/api/v1/submissions/"+formID, map[string]string{"email": "a@example.com"}).Body.Close()
+	}
+
+	if resp := ts.Request(t, "GET", "/api/v1/admin/http-stats", nil); resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected 403 for admins, got %d", resp.StatusCode)
+	}
+
+	role = "super_admin"
+	var result struct {
+		Data httpstats.Stats `json:"data"`
+	}
+	ParseResponse(t, ts.Request(t, "GET", "/api/v1/admin/http-stats", nil), &result)
+	var submit *httpstats.RouteStats
+	for i, rs := range result.Data.Routes {
+		if rs.Route == "POST /api/v1/submissions/{form_id}" {
+			submit = &result.Data.Routes[i]
+		}
+	}
+	if submit == nil || submit.Requests != 3 || submit.ServerErrors != 0 {
+		t.Fatalf("expected 3 submissions counted on their route, got %+v", result.Data.Routes)
+	}
+}
+
+func TestAdminSeed_GatedAndAsync(t *testing.T) {
+	store, err := sqlite.New(":memory:")
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	role := "user"
+	asRole := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), middleware.RoleKey, role)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+	statsService := service.NewStatsService(store)
+	router := api.NewRouter(service.NewFormService(store), service.NewSubmissionService(store), statsService)
+	tasks := service.NewTaskService(store)
+	router.SetSeeder(service.NewSeedService(store, tasks), false)
+	mux := http.NewServeMux()
+	router.RegisterProtectedRoutes(mux, asRole)
+	api.NewTaskHandler(tasks).RegisterRoutes(mux, asRole)
+	ts := &TestServer{Server: httptest.NewServer(mux), Store: store}
+	defer ts.Server.Close()
+
+	seed := map[string]interface{}{"forms": 2, "submissions_per_form": 5}
+	resp := ts.Request(t, "POST", "/api/v1/admin/seed", seed)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected 403 for non super admin, got %d", resp.StatusCode)
+	}
+
+	role = "super_admin"
+	var result map[string]interface{}
+	resp = ts.Request(t, "POST", "/api/v1/admin/seed", seed)
+	ParseResponse(t, resp, &result)
+	if resp.StatusCode != http.StatusForbidden || result["code"] != "SEED_DISABLED" {
+		t.Fatalf("expected SEED_DISABLED when not allowed, got %d %v", resp.StatusCode, result)
+	}
+
+	router.SetSeeder(service.NewSeedService(store, tasks), true)
+	for _, tooMuch := range []map[string]interface{}{
+		{"forms": service.MaxSeedForms + 1},
+		{"forms": 2, "submissions_per_form": service.MaxSeedSubmissionsPerForm + 1},
+	} {
+		resp = ts.Request(t, "POST", "/api/v1/admin/seed", tooMuch)
+		ParseResponse(t, resp, &result)
+		if resp.StatusCode != http.StatusBadRequest || result["code"] != "VALIDATION_ERROR" {
+			t.Fatalf("expected 400 for %v, got %d %v", tooMuch, resp.StatusCode, result)
+		}
+	}
+
+	resp = ts.Request(t, "POST", "/api/v1/admin/seed", seed)
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", resp.StatusCode)
+	}
+	ParseResponse(t, resp, &result)
+	taskID := result["data"].(map[string]interface{})["id"].(string)
+
+	var task map[string]interface{}
+	for i := 0; i < 200; i++ {
+		ParseResponse(t, ts.Request(t, "GET", "/api/v1/tasks/"+taskID, nil), &result)
+		task = result["data"].(map[string]interface{})
+		if task["status"] != "running" {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if task["status"] != "completed" || task["type"] != "seed" {
+		t.Fatalf("unexpected task state: %v", task)
+	}
+	if res := task["result"].(map[string]interface{}); res["submissions_created"] != float64(10) {
+		t.Errorf("expected 10 seeded submissions, got %v", res)
+	}
+
+	// Finished tasks can't be cancelled; the event stream ends with a done event
+	resp = ts.Request(t, "POST", "/api/v1/tasks/"+taskID+"/cancel", nil)
+	ParseResponse(t, resp, &result)
+	if resp.StatusCode != http.StatusConflict || result["code"] != "TASK_FINISHED" {
+		t.Errorf("expected TASK_FINISHED, got %d %v", resp.StatusCode, result)
+	}
+	resp = ts.Request(t, "GET", "/api/v1/tasks/"+taskID+"/events", nil)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" || !strings.HasPrefix(string(body), "event: done\ndata: {") {
+		t.Errorf("unexpected event stream %q: %s", ct, body)
+	}
+
+	// Seeded data is test data: not counted in stats
+	stats, err := statsService.GetDashboardStats(context.Background(), "")
+	if err != nil {
+		t.Fatalf("stats: %v", err)
+	}
+	if stats.TotalForms != 2 || stats.TotalSubmissions != 0 {
+		t.Errorf("expected 2 forms and no counted submissions, got %+v", stats)
+	}
+}
+
+func TestSampleData_OwnerScoped(t *testing.T) {
+	store, err := sqlite.New(":memory:")
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	userID := "user-a"
+	asUser := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), middleware.RoleKey, "user")
+			ctx = context.WithValue(ctx, middleware.UserIDKey, userID)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+	statsService := service.NewStatsService(store)
+	router := api.NewRouter(service.NewFormService(store), service.NewSubmissionService(store), statsService)
+	tasks := service.NewTaskService(store)
+	// Sample data doesn't need seeding to be allowed
+	router.SetSeeder(service.NewSeedService(store, tasks), false)
+	mux := http.NewServeMux()
+	router.RegisterProtectedRoutes(mux, asUser)
+	api.NewTaskHandler(tasks).RegisterRoutes(mux, asUser)
+	ts := &TestServer{Server: httptest.NewServer(mux), Store: store}
+	defer ts.Server.Close()
+
+	createSample := func(forms int) {
+		t.Helper()
+		var result map[string]interface{}
+		resp := ts.Request(t, "POST", "/api/v1/sample-data", map[string]interface{}{"forms": forms, "submissions_per_form": 20})
+		ParseResponse(t, resp, &result)
+		if resp.StatusCode != http.StatusAccepted {
+			t.Fatalf("expected 202, got %d %v", resp.StatusCode, result)
+		}
+		taskID := result["data"].(map[string]interface{})["id"].(string)
+		var task map[string]interface{}
+		for i := 0; i < 200; i++ {
+			ParseResponse(t, ts.Request(t, "GET", "/api/v1/tasks/"+taskID, nil), &result)
+			task = result["data"].(map[string]interface{})
+			if task["status"] != "running" {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		if task["status"] != "completed" || task["type"] != "sample_data" {
+			t.Fatalf("unexpected task state: %v", task)
+		}
+	}
+	createSample(2)
+	userID = "user-b"
+	createSample(1)
+	userID = "user-a"
+
+	real := &domain.Form{ID: "form-real", PublicID: "real", OwnerID: "user-a", Name: "Real", CreatedAt: time.Now()}
+	if err := store.Form().Create(context.Background(), real); err != nil {
+		t.Fatalf("create form: %v", err)
+	}
+
+	ctx := context.Background()
+	forms, _, err := store.Form().ListPaginated(ctx, domain.FormListQuery{OwnerID: "user-a", SampleOnly: true}, 10, 0)
+	if err != nil || len(forms) != 2 {
+		t.Fatalf("expected 2 sample forms for user-a, got %d (%v)", len(forms), err)
+	}
+	// Archived sample forms still count towards the limit and are purged
+	archived, _ := store.Form().GetByID(ctx, forms[1].ID)
+	archived.Status = domain.FormStatusArchived
+	if err := store.Form().Update(ctx, archived); err != nil {
+		t.Fatalf("archive form: %v", err)
+	}
+	subs, err := store.Submission().GetByFormID(ctx, forms[0].ID)
+	if err != nil || len(subs) != 20 {
+		t.Fatalf("expected 20 sample submissions, got %d (%v)", len(subs), err)
+	}
+	oldest := time.Now()
+	for _, sub := range subs {
+		if sub.IsTest || sub.Meta.Server.Country == "" || sub.Meta.Spam == nil {
+			t.Errorf("expected a counted submission with country and spam verdict, got %+v", sub)
+		}
+		if sub.CreatedAt.Before(oldest) {
+			oldest = sub.CreatedAt
+		}
+	}
+	if time.Since(oldest) < 24*time.Hour || time.Since(oldest) > 186*24*time.Hour {
+		t.Errorf("expected submissions spread over months, oldest is %v", oldest)
+	}
+
+	// Unlike seeded test data, sample submissions count in stats
+	stats, err := statsService.GetDashboardStats(ctx, "user-a")
+	if err != nil {
+		t.Fatalf("stats: %v", err)
+	}
+	if stats.TotalSubmissions != 60 {
+		t.Errorf("expected all 60 sample submissions counted, got %+v", stats)
+	}
+
+	// At most 10 sample forms per user
+	var result map[string]interface{}
+	resp := ts.Request(t, "POST", "/api/v1/sample-data", map[string]interface{}{"forms": 9})
+	ParseResponse(t, resp, &result)
+	if resp.StatusCode != http.StatusConflict || result["code"] != "SAMPLE_DATA_LIMIT" {
+		t.Errorf("expected SAMPLE_DATA_LIMIT, got %d %v", resp.StatusCode, result)
+	}
+
+	// Purging removes only the caller's sample forms, with their submissions
+	resp = ts.Request(t, "DELETE", "/api/v1/sample-data", nil)
+	ParseResponse(t, resp, &result)
+	if resp.StatusCode != http.StatusOK || result["data"].(map[string]interface{})["deleted"] != float64(2) {
+		t.Fatalf("expected 2 forms purged, got %d %v", resp.StatusCode, result)
+	}
+	if subs, _ := store.Submission().GetByFormID(ctx, forms[0].ID); len(subs) != 0 {
+		t.Errorf("expected sample submissions purged, got %d", len(subs))
+	}
+	if f, _ := store.Form().GetByID(ctx, archived.ID); f != nil {
+		t.Error("expected the archived sample form purged")
+	}
+	if f, _ := store.Form().GetByID(ctx, real.ID); f == nil {
+		t.Error("expected the real form to be kept")
+	}
+	if _, n, _ := store.Form().ListPaginated(ctx, domain.FormListQuery{OwnerID: "user-b", SampleOnly: true}, 10, 0); n != 1 {
+		t.Errorf("expected user-b's sample form to be kept, got %d", n)
+	}
+}
+
+func TestUserExportImport(t *testing.T) {
+	ctx := context.Background()
+	withAuth := func(ts *TestServer) *service.AuthService {
+		auth := service.NewAuthService(ts.Store, service.AuthConfig{JWTSecret: "test-secret"})
+		api.NewAuthHandler(auth, nil, "https://forms.example.com").RegisterProtectedRoutes(ts.Mux, ts.Auth)
+		return auth
+	}
+
+	// Old instance: a super admin, two users and their forms
+	old := NewUserTestServer(t, &domain.User{ID: "old-admin", Email: "admin@example.com", Role: domain.RoleSuperAdmin, CreatedAt: time.Now()})
+	defer old.Close()
+	oldAuth := withAuth(old)
+	if _, err := oldAuth.CreateUser(ctx, "ada@example.com", "correct horse", "Ada", domain.RoleAdmin); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	grace, err := oldAuth.CreateUser(ctx, "grace@example.com", "battery staple", "Grace", domain.RoleUser)
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	for _, f := range []*domain.Form{
+		{ID: "f1", PublicID: "contact", OwnerID: grace.ID, Name: "Contact", CreatedAt: time.Now()},
+		// Archived forms are exported too
+		{ID: "f2", PublicID: "gone", OwnerID: grace.ID, Name: "Not migrated", Status: domain.FormStatusArchived, CreatedAt: time.Now()},
+	} {
+		if err := old.Store.Form().Create(ctx, f); err != nil {
+			t.Fatalf("create form: %v", err)
+		}
+	}
+
+	var export domain.UserExport
+	resp := old.Request(t, "GET", "/api/v1/users/export", nil)
+	if resp.StatusCode != http.StatusOK || !strings.Contains(resp.Header.Get("Content-Disposition"), "attachment") {
+		t.Fatalf("expected an export download, got %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&export); err != nil {
+		t.Fatalf("decode export: %v", err)
+	}
+	resp.Body.Close()
+	if len(export.Users) != 3 || len(export.Forms) != 2 || export.Users[0].PasswordHash != "" {
+		t.Fatalf("unexpected export without hashes: %+v", export)
+	}
+	resp = old.Request(t, "GET", "/api/v1/users/export?include_password_hashes=true", nil)
+	export = domain.UserExport{}
+	_ = json.NewDecoder(resp.Body).Decode(&export)
+	resp.Body.Close()
+	// Ada keeps her password, Grace is invited to set one
+	for i := range export.Users {
+		switch export.Users[i].Email {
+		case "ada@example.com":
+			if export.Users[i].PasswordHash == "" {
+				t.Fatalf("expected password hashes, got %+v", export.Users[i])
+			}
+		case "grace@example.com":
+			export.Users[i].PasswordHash = ""
+		}
+	}
+
+	// New instance: its own super admin, and the migrated contact form
+	ts := NewUserTestServer(t, &domain.User{ID: "new-admin", Email: "admin@example.com", Role: domain.RoleSuperAdmin, CreatedAt: time.Now()})
+	defer ts.Close()
+	auth := withAuth(ts)
+	if err := ts.Store.Form().Create(ctx, &domain.Form{ID: "n1", PublicID: "contact", OwnerID: "new-admin", Name: "Contact", CreatedAt: time.Now()}); err != nil {
+		t.Fatalf("create form: %v", err)
+	}
+
+	var result map[string]interface{}
+	resp = ts.Request(t, "POST", "/api/v1/users/import?dry_run=true", export)
+	ParseResponse(t, resp, &result)
+	if resp.StatusCode != http.StatusOK || result["data"].(map[string]interface{})["dry_run"] != true {
+		t.Fatalf("expected dry run, got %d %v", resp.StatusCode, result)
+	}
+	if n, _ := ts.Store.User().Count(ctx); n != 1 {
+		t.Errorf("expected dry run to create nobody, got %d users", n)
+	}
+
+	resp = ts.Request(t, "POST", "/api/v1/users/import", export)
+	ParseResponse(t, resp, &result)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d %v", resp.StatusCode, result)
+	}
+	data := result["data"].(map[string]interface{})
+	if len(data["created"].([]interface{})) != 2 || len(data["existing"].([]interface{})) != 1 ||
+		len(data["invited"].([]interface{})) != 1 || data["forms_assigned"] != float64(1) {
+		t.Errorf("unexpected import result: %v", data)
+	}
+	if missing := data["forms_not_found"].([]interface{}); len(missing) != 1 || missing[0] != "gone" {
+		t.Errorf("expected the form not migrated to be reported, got %v", data["forms_not_found"])
+	}
+
+	if _, _, err := auth.Login(ctx, "ada@example.com", "correct horse"); err != nil {
+		t.Errorf("expected the imported password to work: %v", err)
+	}
+	if _, _, err := auth.Login(ctx, "grace@example.com", "battery staple"); err == nil {
+		t.Error("expected the invited user to need a new password")
+	}
+	newGrace, _ := ts.Store.User().GetByEmail(ctx, "grace@example.com")
+	if form, _ := ts.Store.Form().GetByPublicID(ctx, "contact"); form == nil || newGrace == nil || form.OwnerID != newGrace.ID {
+		t.Errorf("expected the contact form to belong to grace, got %+v", form)
+	}
+
+	// Importing again changes nothing
+	resp = ts.Request(t, "POST", "/api/v1/users/import", export)
+	ParseResponse(t, resp, &result)
+	if data := result["data"].(map[string]interface{}); len(data["created"].([]interface{})) != 0 || len(data["existing"].([]interface{})) != 3 {
+		t.Errorf("expected a repeated import to skip every user, got %v", data)
+	}
+
+	export.Version = 2
+	resp = ts.Request(t, "POST", "/api/v1/users/import", export)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 for an unsupported version, got %d", resp.StatusCode)
+	}
+}
+
+func TestAnonymousForm_DropsServerMeta(t *testing.T) {
+	ts := NewTestServer(t)
+	defer ts.Close()
+
+	createResp := ts.Request(t, "POST", "/api/v1/forms", map[string]interface{}{"name": "Anonymous Form"})
+	var createResult map[string]interface{}
+	ParseResponse(t, createResp, &createResult)
+	publicID := createResult["data"].(map[string]interface{})["public_id"].(string)
+
+	updateResp := ts.Request(t, "PUT", "/api/v1/forms/"+publicID, map[string]interface{}{
+		"name":      "Anonymous Form",
+		"status":    "active",
+		"anonymous": true,
+	})
+	var updateResult map[string]interface{}
+	ParseResponse(t, updateResp, &updateResult)
+	if updateResult["data"].(map[string]interface{})["anonymous"] != true {
+		t.Fatalf("expected anonymous to be enabled: %v", updateResult["data"])
+	}
+
+	ts.Request(t, "POST", "/api/v1/submissions/"+publicID, map[string]interface{}{"message": "hi"}).Body.Close()
+
+	listResp := ts.Request(t, "GET", "/api/v1/forms/"+publicID+"/submissions", nil)
+	var listResult map[string]interface{}
+	ParseResponse(t, listResp, &listResult)
+	subs := listResult["data"].(map[string]interface{})["submissions"].([]interface{})
+	if len(subs) != 1 {
+		t.Fatalf("expected 1 submission, got %d", len(subs))
+	}
+	server := subs[0].(map[string]interface{})["meta"].(map[string]interface{})["_server"].(map[string]interface{})
+	if server["ip"] != "" || server["user_agent"] != "" {
+		t.Errorf("expected IP and user agent to be dropped, got %v", server)
+	}
+	if server["anonymized"] != true || server["request_id"] == "" {
+		t.Errorf("expected anonymized meta with request ID, got %v", server)
+	}
+}
+
+func TestFormConsent_EnforcedAndExported(t *testing.T) {
+	ts := NewTestServer(t)
+	defer ts.Close()
+
+	createResp := ts.Request(t, "POST", "/api/v1/forms", map[string]interface{}{"name": "Consent Form"})
+	var createResult map[string]interface{}
+	ParseResponse(t, createResp, &createResult)
+	publicID := createResult["data"].(map[string]interface{})["public_id"].(string)
+
+	resp := ts.Request(t, "PUT", "/api/v1/forms/"+publicID+"/consent", map[string]interface{}{
+		"fields":  []string{"accept_terms"},
+		"version": "2026-01",
+	})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	resp.Body.Close()
+
+	resp = ts.Request(t, "POST", "/api/v1/submissions/"+publicID, map[string]interface{}{"email": "a@example.com"})
+	var errResult map[string]interface{}
+	ParseResponse(t, resp, &errResult)
+	if resp.StatusCode != http.StatusBadRequest || errResult["code"] != "CONSENT_REQUIRED" {
+		t.Fatalf("expected 400 CONSENT_REQUIRED, got %d %v", resp.StatusCode, errResult)
+	}
+
+	resp = ts.Request(t, "POST", "/api/v1/submissions/"+publicID, map[string]interface{}{"email": "a@example.com", "accept_terms": true})
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201 with consent, got %d", resp.StatusCode)
+	}
+	resp.Body.Close()
+
+	csvResp := ts.Request(t, "GET", "/api/v1/forms/"+publicID+"/export/csv", nil)
+	defer csvResp.Body.Close()
+	body, _ := io.ReadAll(csvResp.Body)
+	lines := strings.Split(strings.TrimSpace(string(body)), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], "consent_version,consent_accepted_at") || !strings.Contains(lines[1], ",2026-01,") {
+		t.Errorf("expected consent columns in export, got:\n%s", body)
+	}
+
+	// Timestamps follow date_format; unknown formats are rejected
+	csvResp = ts.Request(t, "GET", "/api/v1/forms/"+publicID+"/export/csv?date_format=unix", nil)
+	body, _ = io.ReadAll(csvResp.Body)
+	csvResp.Body.Close()
+	row := strings.Split(strings.Split(strings.TrimSpace(string(body)), "\n")[1], ",")
+	for _, column := range []int{1, 8} { // created_at, consent_accepted_at
+		if _, err := strconv.ParseInt(row[column], 10, 64); err != nil {
+			t.Errorf("expected unix timestamps, got %v", row)
+		}
+	}
+	var formatErr map[string]interface{}
+	resp = ts.Request(t, "GET", "/api/v1/forms/"+publicID+"/export/csv?date_format=plain", nil)
+	ParseResponse(t, resp, &formatErr)
+	if resp.StatusCode != http.StatusBadRequest || formatErr["code"] != "INVALID_DATE_FORMAT" {
+		t.Errorf("expected 400 INVALID_DATE_FORMAT, got %d %v", resp.StatusCode, formatErr)
+	}
+
+	// Removing the requirement lets submissions through without the checkbox
+	ts.Request(t, "DELETE", "/api/v1/forms/"+publicID+"/consent", nil).Body.Close()
+	resp = ts.Request(t, "POST", "/api/v1/submissions/"+publicID, map[string]interface{}{"email": "b@example.com"})
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("expected 201 after removing consent, got %d", resp.StatusCode)
+	}
+	resp.Body.Close()
+}
+
+// postMultipart submits fields and one file per entry of files (field -> content) as a browser would
+func postMultipart(t *testing.T, ts *TestServer, publicID string, fields, files map[string]string) *http.Response {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for k, v := range fields {
+		_ = mw.WriteField(k, v)
+	}
+	for field, content := range files {
+		part, err := mw.CreateFormFile(field, field+".pdf")
+		if err != nil {
+			t.Fatalf("create part: %v", err)
+		}
+		_, _ = part.Write([]byte(content))
+	}
+	_ = mw.Close()
+
+	resp, err := http.Post(ts.Server.URL+"/api/v1/submissions/"+publicID, mw.FormDataContentType(), &body)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	return resp
+}
+
+func TestFileUploads_StoredAndDownloaded(t *testing.T) {
+	ts := NewTestServer(t)
+	defer ts.Close()
+
+	createResp := ts.Request(t, "POST", "/api/v1/forms", map[string]interface{}{"name": "Job Applications"})
+	var createResult map[string]interface{}
+	ParseResponse(t, createResp, &createResult)
+	publicID := createResult["data"].(map[string]interface{})["public_id"].(string)
+	pdf := "%PDF-1.4 my resume"
+
+	// Forms reject files until uploads are enabled
+	resp := postMultipart(t, ts, publicID, map[string]string{"email": "a@example.com"}, map[string]string{"resume": pdf})
+	var errResult map[string]interface{}
+	ParseResponse(t, resp, &errResult)
+	if resp.StatusCode != http.StatusBadRequest || errResult["code"] != "UPLOADS_DISABLED" {
+		t.Fatalf("expected 400 UPLOADS_DISABLED, got %d %v", resp.StatusCode, errResult)
+	}
+
+	resp = ts.Request(t, "PUT", "/api/v1/forms/"+publicID+"/uploads", map[string]interface{}{
+		"max_file_size": 1024,
+		"allowed_types": []string{"application/pdf"},
+	})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	resp.Body.Close()
+
+	// A required file field is filled in by its upload
+	ts.Request(t, "PUT", "/api/v1/forms/"+publicID+"/fields", map[string]interface{}{
+		"fields": []map[string]interface{}{{"name": "resume", "required": true}},
+	}).Body.Close()
+	resp = postMultipart(t, ts, publicID, map[string]string{"email": "a@example.com"}, nil)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 without the required file, got %d", resp.StatusCode)
+	}
+	resp.Body.Close()
+
+	resp = postMultipart(t, ts, publicID, map[string]string{"email": "a@example.com"}, map[string]string{"resume": pdf})
+	var result map[string]interface{}
+	ParseResponse(t, resp, &result)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201, got %d %v", resp.StatusCode, result)
+	}
+	sub := result["data"].(map[string]interface{})
+	subID := sub["id"].(string)
+
+	data := sub["data"].(map[string]interface{})
+	file, _ := data["resume"].(map[string]interface{})
+	if data["email"] != "a@example.com" || file["name"] != "resume.pdf" || file["content_type"] != "application/pdf" {
+		t.Fatalf("expected the fields and the file reference in the data, got %v", data)
+	}
+
+	fileResp := ts.Request(t, "GET", "/api/v1/submissions/"+subID+"/files/"+file["file_id"].(string), nil)
+	body, _ := io.ReadAll(fileResp.Body)
+	fileResp.Body.Close()
+	if fileResp.StatusCode != http.StatusOK || string(body) != pdf {
+		t.Fatalf("expected the uploaded file, got %d %q", fileResp.StatusCode, body)
+	}
+	if cd := fileResp.Header.Get("Content-Disposition"); !strings.HasPrefix(cd, "attachment") {
+		t.Errorf("expected an attachment, got %q", cd)
+	}
+
+	// Content is checked, not the file name
+	resp = postMultipart(t, ts, publicID, nil, map[string]string{"resume": "<svg onload=alert(1)>"})
+	ParseResponse(t, resp, &errResult)
+	if resp.StatusCode != http.StatusUnsupportedMediaType || errResult["code"] != "FILE_TYPE_NOT_ALLOWED" {
+		t.Errorf("expected 415 FILE_TYPE_NOT_ALLOWED, got %d %v", resp.StatusCode, errResult)
+	}
+	resp = postMultipart(t, ts, publicID, nil, map[string]string{"resume": pdf + strings.Repeat(" ", 2048)})
+	ParseResponse(t, resp, &errResult)
+	if resp.StatusCode != http.StatusRequestEntityTooLarge || errResult["code"] != "FILE_TOO_LARGE" {
+		t.Errorf("expected 413 FILE_TOO_LARGE, got %d %v", resp.StatusCode, errResult)
+	}
+
+	// Deleting the submission deletes its files
+	ts.Request(t, "DELETE", "/api/v1/submissions/"+subID, nil).Body.Close()
+	fileResp = ts.Request(t, "GET", "/api/v1/submissions/"+subID+"/files/"+file["file_id"].(string), nil)
+	fileResp.Body.Close()
+	if fileResp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404 after deleting the submission, got %d", fileResp.StatusCode)
+	}
+}
+
+func TestExportCSV(t *testing.T) {
+	ts := NewTestServer(t)
+	defer ts.Close()
+
+	createResp := ts.Request(t, "POST", "/api/v1/forms", map[string]interface{}{"name": "Export Form"})
+	var createResult map[string]interface{}
+	ParseResponse(t, createResp, &createResult)
+	publicID := createResult["data"].(map[string]interface{})["public_id"].(string)
+
+	for _, data := range []map[string]interface{}{
+		{"name": "Doe, Jane", "message": "Said \"hi\"\nthen left"},
+		{"email": "b@example.com", "seats": 3},
+	} {
+		resp := ts.Request(t, "POST", "/api/v1/submissions/"+publicID, data)
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("expected 201, got %d", resp.StatusCode)
+		}
+		resp.Body.Close()
+	}
+
+	read := func(query string) [][]string {
+		resp := ts.Request(t, "GET", "/api/v1/forms/"+publicID+"/export/csv"+query, nil)
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/csv; charset=utf-8" {
+			t.Fatalf("expected CSV, got %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+		}
+		records, err := csv.NewReader(resp.Body).ReadAll()
+		if err != nil {
+			t.Fatalf("parse CSV: %v", err)
+		}
+		return records
+	}
+
+	records := read("")
+	if len(records) != 3 {
+		t.Fatalf("expected header and 2 rows, got %v", records)
+	}
+	if got := strings.Join(records[0][9:], ","); got != "email,message,name,seats" {
+		t.Errorf("expected sorted field columns, got %q", got)
+	}
+	// Newest first; values with commas, quotes and newlines survive the round trip
+	if records[1][9] != "b@example.com" || records[1][12] != "3" || records[2][10] != "Said \"hi\"\nthen left" || records[2][11] != "Doe, Jane" {
+		t.Errorf("unexpected rows: %q", records[1:])
+	}
+
+	missing := ts.Request(t, "GET", "/api/v1/forms/missing/export/csv", nil)
+	missing.Body.Close()
+	if missing.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404 for a missing form, got %d", missing.StatusCode)
+	}
+}
+
+func TestExportJSONAndXLSX(t *testing.T) {
+	ts := NewTestServer(t)
+	defer ts.Close()
+
+	createResp := ts.Request(t, "POST", "/api/v1/forms", map[string]interface{}{"name": "Export Form"})
+	var createResult map[string]interface{}
+	ParseResponse(t, createResp, &createResult)
+	publicID := createResult["data"].(map[string]interface{})["public_id"].(string)
+
+	resp := ts.Request(t, "POST", "/api/v1/submissions/"+publicID, map[string]interface{}{
+		"email":   "a@example.com",
+		"address": map[string]interface{}{"city": "Berlin", "zip": "10115"},
+	})
+	resp.Body.Close()
+
+	resp = ts.Request(t, "GET", "/api/v1/forms/"+publicID+"/export/json?date_format=unix", nil)
+	var rows []map[string]interface{}
+	ParseResponse(t, resp, &rows)
+	if resp.Header.Get("Content-Disposition") != `attachment; filename="Export Form_submissions.json"` || len(rows) != 1 {
+		t.Fatalf("unexpected JSON export: %v %v", resp.Header, rows)
+	}
+	data := rows[0]["data"].(map[string]interface{})
+	if data["address"].(map[string]interface{})["city"] != "Berlin" {
+		t.Errorf("expected nested fields to be kept, got %v", data)
+	}
+	if _, err := strconv.ParseInt(rows[0]["created_at"].(string), 10, 64); err != nil {
+		t.Errorf("expected a unix timestamp, got %v", rows[0]["created_at"])
+	}
+
+	resp = ts.Request(t, "GET", "/api/v1/forms/"+publicID+"/export/xlsx", nil)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet" {
+		t.Fatalf("expected a workbook, got %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	zr, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		t.Fatalf("open workbook: %v", err)
+	}
+	var sheet string
+	for _, f := range zr.File {
+		if f.Name == "xl/worksheets/sheet1.xml" {
+			rc, _ := f.Open()
+			b, _ := io.ReadAll(rc)
+			rc.Close()
+			sheet = string(b)
+		}
+	}
+	if !strings.Contains(sheet, ">a@example.com<") || !strings.Contains(sheet, `{&#34;city&#34;:&#34;Berlin&#34;`) {
+		t.Errorf("expected the submission in the sheet, got %s", sheet)
+	}
+
+	// The time format is checked like the CSV export's
+	resp = ts.Request(t, "GET", "/api/v1/forms/"+publicID+"/export/xlsx?date_format=plain", nil)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 for an unknown date format, got %d", resp.StatusCode)
+	}
+}
+
+func TestExportParquet_TaskAndDownload(t *testing.T) {
+	ts := NewTestServer(t)
+	defer ts.Close()
+	tasks := service.NewTaskService(ts.Store)
+	tasks.SetFileStorage(filestore.NewLocal(t.TempDir()))
+	ts.Router.SetTasks(tasks)
+	api.NewTaskHandler(tasks).RegisterRoutes(ts.Mux, func(h http.Handler) http.Handler { return h })
+
+	createResp := ts.Request(t, "POST", "/api/v1/forms", map[string]interface{}{"name": "Survey"})
+	var createResult map[string]interface{}
+	ParseResponse(t, createResp, &createResult)
+	publicID := createResult["data"].(map[string]interface{})["public_id"].(string)
+	for i := 0; i < 3; i++ {
+		resp := ts.Request(t, "POST", "/api/v1/submissions/"+publicID, map[string]interface{}{"age": 30 + i, "subscribed": i%2 == 0, "name": "Ada"})
+		resp.Body.Close()
+	}
+
+	resp := ts.Request(t, "POST", "/api/v1/forms/"+publicID+"/export/parquet", nil)
+	var result map[string]interface{}
+	ParseResponse(t, resp, &result)
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d %v", resp.StatusCode, result)
+	}
+	taskID := result["data"].(map[string]interface{})["id"].(string)
+
+	var task map[string]interface{}
+	for i := 0; i < 200; i++ {
+		ParseResponse(t, ts.Request(t, "GET", "/api/v1/tasks/"+taskID, nil), &result)
+		task = result["data"].(map[string]interface{})
+		if task["status"] != "running" {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if task["status"] != "completed" || task["type"] != "export_parquet" {
+		t.Fatalf("unexpected task state: %v", task)
+	}
+	// 9 fixed columns plus age, name and subscribed
+	if res := task["result"].(map[string]interface{}); res["rows"] != float64(3) || res["columns"] != float64(12) {
+		t.Errorf("unexpected result %v", res)
+	}
+	file := task["file"].(map[string]interface{})
+	if file["name"] != "Survey_submissions.parquet" {
+		t.Errorf("unexpected file %v", file)
+	}
+
+	download := ts.Request(t, "GET", "/api/v1/tasks/"+taskID+"/download", nil)
+	body, _ := io.ReadAll(download.Body)
+	download.Body.Close()
+	if download.StatusCode != http.StatusOK || download.Header.Get("Content-Type") != "application/vnd.apache.parquet" {
+		t.Fatalf("expected the Parquet file, got %d %s", download.StatusCode, download.Header.Get("Content-Type"))
+	}
+	if int64(len(body)) != int64(file["size"].(float64)) || !bytes.HasPrefix(body, []byte("PAR1")) || !bytes.HasSuffix(body, []byte("PAR1")) {
+		t.Errorf("expected a %v byte Parquet file, got %d bytes", file["size"], len(body))
+	}
+}
+
+func TestTestNotification_NotStored(t *testing.T) {
+	ts := NewTestServer(t)
+	defer ts.Close()
+
+	createResp := ts.Request(t, "POST", "/api/v1/forms", map[string]interface{}{"name": "Notify Form"})
+	var createResult map[string]interface{}
+	ParseResponse(t, createResp, &createResult)
+	publicID := createResult["data"].(map[string]interface{})["public_id"].(string)
+
+	resp := ts.Request(t, "POST", "/api/v1/forms/"+publicID+"/test-notification", map[string]interface{}{
+		"data": map[string]interface{}{"email": "sample@example.com"},
+	})
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", resp.StatusCode)
+	}
+	var result map[string]interface{}
+	ParseResponse(t, resp, &result)
+	sub := result["data"].(map[string]interface{})["submission"].(map[string]interface{})
+	if sub["is_test"] != true {
+		t.Errorf("expected submission marked as test, got %v", sub)
+	}
+
+	formResp := ts.Request(t, "GET", "/api/v1/forms/"+publicID, nil)
+	var formResult map[string]interface{}
+	ParseResponse(t, formResp, &formResult)
+	if count := formResult["data"].(map[string]interface{})["submission_count"]; count != float64(0) {
+		t.Errorf("expected submission count to stay 0, got %v", count)
+	}
+}
+
+// =============================================================================
+// Form Token Tests
+// =============================================================================
+
+func TestFormToken_ScopedReadOnly(t *testing.T) {
+	store, err := sqlite.New(":memory:")
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	formService := service.NewFormService(store)
+	tokenService := service.NewFormTokenService(store)
+	authService := service.NewAuthService(store, service.AuthConfig{JWTSecret: "test-secret"})
+
+	router := api.NewRouter(formService, service.NewSubmissionService(store), service.NewStatsService(store))
+	mux := http.NewServeMux()
+	router.RegisterProtectedRoutes(mux, middleware.AuthMiddleware(authService, tokenService))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	ctx := context.Background()
+	form, err := formService.CreateForm(ctx, "Scoped", "", nil, "", "", "owner-1", "public", "")
+	if err != nil {
+		t.Fatalf("create form: %v", err)
+	}
+	other, _ := formService.CreateForm(ctx, "Other", "", nil, "", "", "owner-1", "public", "")
+
+	_, plaintext, err := tokenService.CreateToken(ctx, form.PublicID, "dashboard", "owner-1")
+	if err != nil {
+		t.Fatalf("create token: %v", err)
+	}
+
+	get := func(method, path string) int {
+		req, _ := http.NewRequest(method, server.URL+path, nil)
+		req.Header.Set("Authorization", "Bearer "+plaintext)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if code := get("GET", "/api/v1/forms/"+form.PublicID+"/submissions"); code != http.StatusOK {
+		t.Errorf("expected 200 for own submissions, got %d", code)
+	}
+	if code := get("GET", "/api/v1/forms/"+other.PublicID+"/submissions"); code != http.StatusForbidden {
+		t.Errorf("expected 403 for another form, got %d", code)
+	}
+	if code := get("DELETE", "/api/v1/forms/"+form.PublicID); code != http.StatusForbidden {
+		t.Errorf("expected 403 for write, got %d", code)
+	}
+	if code := get("GET", "/api/v1/forms"); code != http.StatusForbidden {
+		t.Errorf("expected 403 for form list, got %d", code)
+	}
+
+	// Revoked tokens stop working
+	tokens, _ := tokenService.ListTokens(ctx, form.PublicID)
+	if len(tokens) != 1 || tokens[0].LastUsedAt == nil {
+		t.Fatalf("expected one used token, got %+v", tokens)
+	}
+	if err := tokenService.RevokeToken(ctx, form.PublicID, tokens[0].ID); err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+	if code := get("GET", "/api/v1/forms/"+form.PublicID+"/submissions"); code != http.StatusUnauthorized {
+		t.Errorf("expected 401 after revoke, got %d", code)
+	}
+}
+
+func TestEmailQueueEndpoint(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+	list := func(ts *TestServer, query string) (*http.Response, map[string]interface{}) {
+		t.Helper()
+		var result map[string]interface{}
+		resp := ts.Request(t, "GET", "/api/v1/admin/email-queue"+query, nil)
+		ParseResponse(t, resp, &result)
+		return resp, result
+	}
+
+	user := NewUserTestServer(t, &domain.User{ID: "user-1", Email: "user@example.com", PasswordHash: "x", Role: domain.RoleUser})
+	defer user.Close()
+	api.NewEmailQueueHandler(service.NewEmailQueueService(user.Store)).RegisterRoutes(user.Mux, user.Auth)
+	if resp, _ := list(user, ""); resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected 403 for users, got %d", resp.StatusCode)
+	}
+
+	ts := NewUserTestServer(t, &domain.User{ID: "admin-1", Email: "admin@example.com", PasswordHash: "x", Role: domain.RoleAdmin})
+	defer ts.Close()
+	api.NewEmailQueueHandler(service.NewEmailQueueService(ts.Store)).RegisterRoutes(ts.Mux, ts.Auth)
+	for i, e := range []*domain.QueuedEmail{
+		{ID: "eml_sent", To: []string{"a@example.com"}, Subject: "Sent", Status: domain.EmailSent, Attempts: 1, CreatedAt: now.Add(-2 * time.Minute)},
+		{ID: "eml_dead", To: []string{"b@example.com"}, Subject: "Dead", Status: domain.EmailDead, Attempts: 8, LastError: "550 rejected", CreatedAt: now.Add(-time.Minute)},
+		{ID: "eml_pending", To: []string{"c@example.com"}, Subject: "Pending", Status: domain.EmailPending, CreatedAt: now},
+	} {
+		e.Message, e.NextAttemptAt, e.UpdatedAt = []byte("Subject: x\r\n\r\nsecret body"), e.CreatedAt, e.CreatedAt
+		if err := ts.Store.EmailQueue().Create(ctx, e); err != nil {
+			t.Fatalf("create %d: %v", i, err)
+		}
+	}
+
+	resp, result := list(ts, "")
+	data, _ := result["data"].(map[string]interface{})
+	emails, _ := data["emails"].([]interface{})
+	if resp.StatusCode != http.StatusOK || len(emails) != 3 || emails[0].(map[string]interface{})["id"] != "eml_pending" {
+		t.Fatalf("expected 3 emails newest first, got %d %v", resp.StatusCode, result)
+	}
+	if _, ok := emails[0].(map[string]interface{})["message"]; ok {
+		t.Error("listed emails should leave out their messages")
+	}
+	counts := data["counts"].(map[string]interface{})
+	if counts["pending"] != float64(1) || counts["sent"] != float64(1) || counts["dead"] != float64(1) {
+		t.Errorf("unexpected counts %v", counts)
+	}
+
+	_, result = list(ts, "?status=dead")
+	emails = result["data"].(map[string]interface{})["emails"].([]interface{})
+	if len(emails) != 1 || emails[0].(map[string]interface{})["last_error"] != "550 rejected" {
+		t.Errorf("expected the dead email with its error, got %v", emails)
+	}
+	if resp, result := list(ts, "?status=bounced"); resp.StatusCode != http.StatusBadRequest || result["code"] != "VALIDATION_ERROR" {
+		t.Errorf("expected 400 VALIDATION_ERROR for an unknown status, got %d %v", resp.StatusCode, result)
+	}
+}
+
+func TestFormErrorPage(t *testing.T) {
+	ts := NewTestServer(t)
+	defer ts.Close()
+
+	var created map[string]interface{}
+	ParseResponse(t, ts.Request(t, "POST", "/api/v1/forms", map[string]interface{}{"name": "Contact"}), &created)
+	publicID := created["data"].(map[string]interface{})["public_id"].(string)
+	ts.Request(t, "PUT", "/api/v1/forms/"+publicID+"/filters", map[string]interface{}{
+		"filters": []interface{}{map[string]interface{}{"pattern": "casino", "action": "reject"}},
+	}).Body.Close()
+
+	// Browser form posts aren't redirected by the client, so the 303 can be checked
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	post := func(accept, message string) (*http.Response, string) {
+		t.Helper()
+		form := url.Values{"name": {"Ada"}, "message": {message}}
+		req, _ := http.NewRequest("POST", ts.Server.URL+"/api/v1/submissions/"+publicID, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("Accept", accept)
+		req.Header.Set("Referer", "https://example.com/contact")
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("post: %v", err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp, string(body)
+	}
+	const browser = "text/html,application/xhtml+xml,*/*;q=0.8"
+
+	// Without settings, people get the hosted page with the status the JSON error has
+	resp, body := post(browser, "win at the casino")
+	if resp.StatusCode != http.StatusBadRequest || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") ||
+		!strings.Contains(body, "Reason: content_rejected") || !strings.Contains(body, `href="https://example.com/contact"`) {
+		t.Fatalf("expected the hosted error page, got %d %s %s", resp.StatusCode, resp.Header.Get("Content-Type"), body)
+	}
+	// fetch() calls posting form data keep getting JSON
+	if resp, body := post("*/*", "win at the casino"); !strings.Contains(body, `"code":"CONTENT_REJECTED"`) {
+		t.Errorf("expected the JSON error, got %d %s", resp.StatusCode, body)
+	}
+
+	for _, cfg := range []map[string]interface{}{{}, {"url": "ftp://example.com/oops"}, {"url": "/oops"}, {"message": strings.Repeat("x", 1001)}} {
+		resp := ts.Request(t, "PUT", "/api/v1/forms/"+publicID+"/error-page", cfg)
+		var result map[string]interface{}
+		ParseResponse(t, resp, &result)
+		if resp.StatusCode != http.StatusBadRequest || result["code"] != "VALIDATION_ERROR" {
+			t.Errorf("expected 400 VALIDATION_ERROR for %v, got %d %v", cfg, resp.StatusCode, result)
+		}
+	}
+
+	ts.Request(t, "PUT", "/api/v1/forms/"+publicID+"/error-page", map[string]interface{}{"message": "  Please leave out links to gambling sites.  "}).Body.Close()
+	if _, body := post(browser, "casino"); !strings.Contains(body, "Please leave out links to gambling sites.") {
+		t.Errorf("expected the form's message on the hosted page, got %s", body)
+	}
+
+	var result map[string]interface{}
+	ParseResponse(t, ts.Request(t, "PUT", "/api/v1/forms/"+publicID+"/error-page", map[string]interface{}{"url": "https://example.com/oops?src=form"}), &result)
+	if page := result["data"].(map[string]interface{})["error_page"].(map[string]interface{}); page["url"] != "https://example.com/oops?src=form" {
+		t.Fatalf("expected the error page set, got %v", result)
+	}
+	resp, _ = post(browser, "casino")
+	if resp.StatusCode != http.StatusSeeOther || resp.Header.Get("Location") != "https://example.com/oops?reason=content_rejected&src=form" {
+		t.Errorf("expected a redirect with the reason, got %d %q", resp.StatusCode, resp.Header.Get("Location"))
+	}
+	// Field errors carry their own reason
+	ts.Request(t, "PUT", "/api/v1/forms/"+publicID+"/fields", map[string]interface{}{
+		"fields": []map[string]interface{}{{"name": "email", "required": true}},
+	}).Body.Close()
+	if resp, _ := post(browser, "hello"); resp.Header.Get("Location") != "https://example.com/oops?reason=invalid_fields&src=form" {
+		t.Errorf("expected invalid_fields, got %d %q", resp.StatusCode, resp.Header.Get("Location"))
+	}
+
+	ParseResponse(t, ts.Request(t, "DELETE", "/api/v1/forms/"+publicID+"/error-page", nil), &result)
+	if _, ok := result["data"].(map[string]interface{})["error_page"]; ok {
+		t.Errorf("expected the error page removed, got %v", result)
+	}
+}
+
+func TestOAuthLogin(t *testing.T) {
+	ts := NewUserTestServer(t, &domain.User{ID: "sso-admin", Email: "admin@example.com", Role: domain.RoleSuperAdmin, CreatedAt: time.Now()})
+	defer ts.Close()
+
+	// A fake OpenID Connect provider that signs in whoever's email is in the code
+	var provider *httptest.Server
+	provider = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			json.NewEncoder(w).Encode(map[string]string{
+				"issuer":                 provider.URL,
+				"authorization_endpoint": provider.URL + "/authorize",
+				"token_endpoint":         provider.URL + "/token",
+				"userinfo_endpoint":      provider.URL + "/userinfo",
+			})
+		case "/token":
+			if r.FormValue("client_secret") != "s3cret" || r.FormValue("code_verifier") == "" {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(map[string]string{"error": "invalid_client"})
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]string{"access_token": r.FormValue("code")})
+		case "/userinfo":
+			email := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			json.NewEncoder(w).Encode(map[string]interface{}{"sub": email, "email": email, "email_verified": true, "name": "Ada"})
+		}
+	}))
+	defer provider.Close()
+
+	auth := service.NewAuthService(ts.Store, service.AuthConfig{JWTSecret: "test-secret"})
+	authHandler := api.NewAuthHandler(auth, nil, ts.Server.URL)
+	sso := oauth.NewClient()
+	sso.Client = provider.Client()
+	authHandler.SetOAuthClient(sso)
+	authHandler.RegisterPublicRoutes(ts.Mux)
+	settingsHandler := api.NewSettingsHandler(ts.Store)
+	ts.Mux.Handle("GET /api/v1/settings/oauth", ts.Auth(http.HandlerFunc(settingsHandler.HandleGetOAuth)))
+	ts.Mux.Handle("PUT /api/v1/settings/oauth", ts.Auth(http.HandlerFunc(settingsHandler.HandleSetOAuth)))
+
+	// Password login can't be turned off without a provider
+	resp := ts.Request(t, "PUT", "/api/v1/settings/oauth", map[string]interface{}{"providers": []interface{}{}, "disable_password_login": true})
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 without providers, got %d", resp.StatusCode)
+	}
+	resp.Body.Close()
+
+	provSettings := map[string]interface{}{
+		"id": "sso", "type": "oidc", "name": "Company SSO", "issuer": provider.URL,
+		"client_id": "headless-forms", "client_secret": "s3cret", "allowed_domains": []string{"example.com"},
+	}
+	var result map[string]interface{}
+	ParseResponse(t, ts.Request(t, "PUT", "/api/v1/settings/oauth", map[string]interface{}{
+		"providers": []interface{}{provSettings}, "disable_password_login": true,
+	}), &result)
+	saved := result["data"].(map[string]interface{})["providers"].([]interface{})[0].(map[string]interface{})
+	if saved["client_secret"] != "********" {
+		t.Fatalf("expected the client secret masked, got %v", result)
+	}
+
+	// Saving the masked secret back keeps the real one
+	provSettings["client_secret"] = "********"
+	resp = ts.Request(t, "PUT", "/api/v1/settings/oauth", map[string]interface{}{
+		"providers": []interface{}{provSettings}, "disable_password_login": true,
+	})
+	resp.Body.Close()
+	if settings, _ := ts.Store.Settings().Get(context.Background()); settings.OAuth.Provider("sso").ClientSecret != "s3cret" {
+		t.Fatalf("expected the saved client secret kept, got %+v", settings.OAuth)
+	}
+
+	ParseResponse(t, ts.Request(t, "GET", "/api/v1/auth/setup", nil), &result)
+	setup := result["data"].(map[string]interface{})
+	providers := setup["oauth_providers"].([]interface{})
+	if setup["password_login"] != false || len(providers) != 1 || providers[0].(map[string]interface{})["start_url"] != "/api/v1/auth/oauth/sso/start" {
+		t.Fatalf("unexpected setup response %v", setup)
+	}
+	resp = ts.Request(t, "POST", "/api/v1/auth/login", map[string]string{"email": "admin@example.com", "password": "whatever1"})
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("expected password login refused, got %d", resp.StatusCode)
+	}
+	resp.Body.Close()
+
+	// signIn runs the flow for the email, returning where the dashboard is sent back to
+	signIn := func(email string, tamper bool) *url.URL {
+		t.Helper()
+		jar, _ := cookiejar.New(nil)
+		browser := &http.Client{Jar: jar, CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+		resp, err := browser.Get(ts.Server.URL + "/api/v1/auth/oauth/sso/start")
+		if err != nil || resp.StatusCode != http.StatusFound {
+			t.Fatalf("expected a redirect to the provider, got %v %v", resp, err)
+		}
+		authURL, _ := url.Parse(resp.Header.Get("Location"))
+		q := authURL.Query()
+		if !strings.HasPrefix(authURL.String(), provider.URL+"/authorize") || q.Get("code_challenge_method") != "S256" ||
+			q.Get("redirect_uri") != ts.Server.URL+"/api/v1/auth/oauth/sso/callback" {
+			t.Fatalf("unexpected authorization URL %s", authURL)
+		}
+		state := q.Get("state")
+		if tamper {
+			state = "forged"
+		}
+		resp, err = browser.Get(ts.Server.URL + "/api/v1/auth/oauth/sso/callback?" + url.Values{"code": {email}, "state": {state}}.Encode())
+		if err != nil || resp.StatusCode != http.StatusFound {
+			t.Fatalf("expected a redirect back to the dashboard, got %v %v", resp, err)
+		}
+		back, _ := url.Parse(resp.Header.Get("Location"))
+		return back
+	}
+
+	back := signIn("admin@example.com", false)
+	fragment, _ := url.ParseQuery(back.EscapedFragment())
+	claims, err := auth.ValidateToken(fragment.Get("token"))
+	if back.Path != "/login" || err != nil || claims.UserID != "sso-admin" {
+		t.Fatalf("expected a token for the linked account, got %s (%v)", back, err)
+	}
+
+	for email, want := range map[string]string{
+		"nobody@example.com": domain.ErrOAuthNoAccount.Error(),
+		"ada@elsewhere.org":  domain.ErrOAuthDomainNotAllowed.Error(),
+	} {
+		fragment, _ := url.ParseQuery(signIn(email, false).EscapedFragment())
+		if fragment.Get("error") != want || fragment.Get("token") != "" {
+			t.Errorf("%s: expected %q, got %v", email, want, fragment)
+		}
+	}
+	if fragment, _ := url.ParseQuery(signIn("admin@example.com", true).EscapedFragment()); fragment.Get("token") != "" {
+		t.Errorf("expected a forged state refused, got %v", fragment)
+	}
+
+	// Providers that create accounts sign new users in with the default role
+	provSettings["auto_create"] = true
+	resp = ts.Request(t, "PUT", "/api/v1/settings/oauth", map[string]interface{}{"providers": []interface{}{provSettings}})
+	resp.Body.Close()
+	fragment, _ = url.ParseQuery(signIn("nobody@example.com", false).EscapedFragment())
+	claims, err = auth.ValidateToken(fragment.Get("token"))
+	if err != nil || claims.Email != "nobody@example.com" || claims.Role != domain.RoleUser {
+		t.Errorf("expected a new user signed in, got %v (%v)", fragment, err)
+	}
+	resp = ts.Request(t, "POST", "/api/v1/auth/login", map[string]string{"email": "admin@example.com", "password": "whatever1"})
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected password login back on, got %d", resp.StatusCode)
+	}
+	resp.Body.Close()
+}
+
+func TestSubmissionSanitize(t *testing.T) {
+	ts := NewTestServer(t)
+	defer ts.Close()
+
+	var result map[string]interface{}
+	ParseResponse(t, ts.Request(t, "POST", "/api/v1/forms", map[string]interface{}{"name": "Sanitize"}), &result)
+	publicID := result["data"].(map[string]interface{})["public_id"].(string)
+
+	resp := ts.Request(t, "POST", "/api/v1/submissions/"+publicID, map[string]interface{}{
+		"message": `Hi <b>there</b><script>alert("x")</script>`,
+		"tags":    []string{"<img src=x onerror=alert(1)>ok"},
+	})
+	resp.Body.Close()
+
+	data := func(query string) map[string]interface{} {
+		t.Helper()
+		var result map[string]interface{}
+		ParseResponse(t, ts.Request(t, "GET", "/api/v1/forms/"+publicID+"/submissions"+query, nil), &result)
+		sub := result["data"].(map[string]interface{})["submissions"].([]interface{})[0].(map[string]interface{})
+		return sub["data"].(map[string]interface{})
+	}
+
+	if got := data("")["message"]; got != `Hi <b>there</b><script>alert("x")</script>` {
+		t.Errorf("expected the value as submitted by default, got %q", got)
+	}
+	escaped := data("?sanitize=escape")
+	if got := escaped["message"]; got != `Hi &lt;b&gt;there&lt;/b&gt;&lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt;` {
+		t.Errorf("unexpected escaped value %q", got)
+	}
+	stripped := data("?sanitize=strip")
+	if got := stripped["message"]; got != "Hi there" {
+		t.Errorf("unexpected stripped value %q", got)
+	}
+	if got := stripped["tags"].([]interface{})[0]; got != "ok" {
+		t.Errorf("expected lists stripped too, got %q", got)
+	}
+
+	resp = ts.Request(t, "GET", "/api/v1/forms/"+publicID+"/submissions?sanitize=yes", nil)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 for an unknown mode, got %d", resp.StatusCode)
+	}
+	resp.Body.Close()
+
+	// Stored data is left alone
+	if got := data("")["tags"].([]interface{})[0]; got != "<img src=x onerror=alert(1)>ok" {
+		t.Errorf("expected the stored value unchanged, got %q", got)
+	}
+}
+
+func TestTeams(t *testing.T) {
+	store, err := sqlite.New(":memory:")
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	authService := service.NewAuthService(store, service.AuthConfig{JWTSecret: "test-secret"})
+	formService := service.NewFormService(store)
+	router := api.NewRouter(formService, service.NewSubmissionService(store), service.NewStatsService(store))
+	mux := http.NewServeMux()
+	authMiddleware := middleware.AuthMiddleware(authService, nil)
+	router.RegisterProtectedRoutes(mux, authMiddleware)
+	api.NewTeamHandler(service.NewTeamService(store), formService).RegisterRoutes(mux, authMiddleware)
+	ts := &TestServer{Server: httptest.NewServer(mux), Store: store, Mux: mux, Router: router}
+	defer ts.Close()
+
+	ctx := context.Background()
+	tokens := map[string]string{}
+	userIDs := map[string]string{}
+	for _, name := range []string{"owner", "editor", "viewer", "outsider"} {
+		user, err := authService.CreateUser(ctx, name+"@example.com", "correct horse", name, domain.RoleUser)
+		if err != nil {
+			t.Fatalf("create %s: %v", name, err)
+		}
+		token, _, err := authService.Login(ctx, user.Email, "correct horse")
+		if err != nil {
+			t.Fatalf("login %s: %v", name, err)
+		}
+		tokens[name], userIDs[name] = token, user.ID
+	}
+	as := func(name, method, path string, body interface{}) *http.Response {
+		ts.Token = tokens[name]
+		return ts.Request(t, method, path, body)
+	}
+	status := func(resp *http.Response) int {
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	form, err := formService.CreateForm(ctx, "Shared", "", nil, "", "", userIDs["owner"], "public", "")
+	if err != nil {
+		t.Fatalf("create form: %v", err)
+	}
+	formPath := "/api/v1/forms/" + form.PublicID
+	stored, _ := store.Form().GetByID(ctx, form.ID)
+	stored.WebhookSecret, stored.SubmissionKey = "whsec_shared", "key_shared"
+	stored.Captcha = &domain.CaptchaConfig{Provider: domain.CaptchaTurnstile, Secret: "captcha_shared"}
+	if err := store.Form().Update(ctx, stored); err != nil {
+		t.Fatalf("update form: %v", err)
+	}
+	secrets := func(resp *http.Response) (string, string) {
+		var got struct {
+			Data domain.Form `json:"data"`
+		}
+		ParseResponse(t, resp, &got)
+		return got.Data.WebhookSecret, got.Data.SubmissionKey
+	}
+
+	var created struct {
+		Data domain.Team `json:"data"`
+	}
+	resp := as("owner", "POST", "/api/v1/teams", map[string]string{"name": " Marketing "})
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201 creating a team, got %d", resp.StatusCode)
+	}
+	ParseResponse(t, resp, &created)
+	team := created.Data
+	if team.Name != "Marketing" || len(team.Members) != 1 || team.Members[0].Role != domain.TeamRoleOwner {
+		t.Fatalf("expected the creator as sole owner, got %+v", team)
+	}
+	teamPath := "/api/v1/teams/" + team.ID
+
+	for name, role := range map[string]string{"editor": "editor", "viewer": "viewer"} {
+		if code := status(as("owner", "PUT", teamPath+"/members", map[string]string{"email": name + "@example.com", "role": role})); code != http.StatusOK {
+			t.Fatalf("expected 200 adding %s, got %d", name, code)
+		}
+	}
+	if code := status(as("owner", "PUT", teamPath+"/members", map[string]string{"email": "outsider@example.com", "role": "admin"})); code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an unknown role, got %d", code)
+	}
+	if code := status(as("editor", "PUT", teamPath+"/members", map[string]string{"email": "outsider@example.com", "role": "viewer"})); code != http.StatusForbidden {
+		t.Errorf("expected 403 for an editor adding members, got %d", code)
+	}
+	if code := status(as("outsider", "GET", teamPath, nil)); code != http.StatusNotFound {
+		t.Errorf("expected 404 for a team the user isn't in, got %d", code)
+	}
+
+	// Not shared yet: only the owner sees the form
+	if code := status(as("viewer", "GET", formPath, nil)); code != http.StatusForbidden {
+		t.Errorf("expected 403 before sharing, got %d", code)
+	}
+	if code := status(as("viewer", "PUT", formPath+"/team", map[string]string{"team_id": team.ID})); code != http.StatusForbidden {
+		t.Errorf("expected 403 for a non-owner sharing the form, got %d", code)
+	}
+	if code := status(as("owner", "PUT", formPath+"/team", map[string]string{"team_id": team.ID})); code != http.StatusOK {
+		t.Fatalf("expected 200 sharing the form, got %d", code)
+	}
+
+	// Viewers see it, editors can change it too, outsiders neither
+	if code := status(as("viewer", "GET", formPath, nil)); code != http.StatusOK {
+		t.Errorf("expected 200 for a team viewer, got %d", code)
+	}
+	if code := status(as("viewer", "PUT", formPath+"/timezone", map[string]string{"timezone": "Europe/Berlin"})); code != http.StatusForbidden {
+		t.Errorf("expected 403 for a team viewer changing the form, got %d", code)
+	}
+	if code := status(as("editor", "PUT", formPath+"/timezone", map[string]string{"timezone": "Europe/Berlin"})); code != http.StatusOK {
+		t.Errorf("expected 200 for a team editor changing the form, got %d", code)
+	}
+	if code := status(as("outsider", "GET", formPath, nil)); code != http.StatusForbidden {
+		t.Errorf("expected 403 for an outsider, got %d", code)
+	}
+
+	// Only those who may manage the form see its credentials
+	if secret, key := secrets(as("viewer", "GET", formPath, nil)); secret != "" || key != "" {
+		t.Errorf("expected a team viewer not to see the form's secrets, got %q %q", secret, key)
+	}
+	viewed := as("viewer", "GET", formPath, nil)
+	body, _ := io.ReadAll(viewed.Body)
+	viewed.Body.Close()
+	if strings.Contains(string(body), "captcha_shared") {
+		t.Errorf("expected a team viewer not to see the captcha secret, got %s", body)
+	}
+	if secret, key := secrets(as("editor", "GET", formPath, nil)); secret != "whsec_shared" || key != "key_shared" {
+		t.Errorf("expected a team editor to see the form's secrets, got %q %q", secret, key)
+	}
+	// An editor unsharing the form loses it, secrets included
+	if secret, key := secrets(as("editor", "PUT", formPath+"/team", map[string]string{"team_id": ""})); secret != "" || key != "" {
+		t.Errorf("expected no secrets once the editor can't manage the form, got %q %q", secret, key)
+	}
+	if code := status(as("owner", "PUT", formPath+"/team", map[string]string{"team_id": team.ID})); code != http.StatusOK {
+		t.Fatalf("expected 200 sharing the form again, got %d", code)
+	}
+
+	listed := func(name, query string) int {
+		var list struct {
+			Data struct {
+				Forms []domain.Form `json:"forms"`
+			} `json:"data"`
+		}
+		ParseResponse(t, as(name, "GET", "/api/v1/forms"+query, nil), &list)
+		return len(list.Data.Forms)
+	}
+	if n := listed("viewer", ""); n != 1 {
+		t.Errorf("expected the team's form in the viewer's list, got %d forms", n)
+	}
+	if n := listed("viewer", "?team_id="+team.ID); n != 1 {
+		t.Errorf("expected the form when filtering by team, got %d forms", n)
+	}
+	if n := listed("outsider", ""); n != 0 {
+		t.Errorf("expected no forms for an outsider, got %d", n)
+	}
+
+	var teams struct {
+		Data struct {
+			Teams []domain.Team `json:"teams"`
+		} `json:"data"`
+	}
+	ParseResponse(t, as("viewer", "GET", "/api/v1/teams", nil), &teams)
+	if len(teams.Data.Teams) != 1 || teams.Data.Teams[0].Role != domain.TeamRoleViewer {
+		t.Errorf("expected the viewer's one team with their role, got %+v", teams.Data.Teams)
+	}
+
+	// The last owner can't leave or be demoted; other members can leave
+	if code := status(as("owner", "DELETE", teamPath+"/members/"+userIDs["owner"], nil)); code != http.StatusConflict {
+		t.Errorf("expected 409 removing the last owner, got %d", code)
+	}
+	if code := status(as("owner", "PUT", teamPath+"/members", map[string]string{"email": "owner@example.com", "role": "editor"})); code != http.StatusConflict {
+		t.Errorf("expected 409 demoting the last owner, got %d", code)
+	}
+	if code := status(as("editor", "DELETE", teamPath+"/members/"+userIDs["editor"], nil)); code != http.StatusOK {
+		t.Errorf("expected 200 for a member leaving, got %d", code)
+	}
+	if code := status(as("editor", "GET", formPath, nil)); code != http.StatusForbidden {
+		t.Errorf("expected 403 after leaving the team, got %d", code)
+	}
+
+	// Deleting the team unshares its forms
+	if code := status(as("owner", "DELETE", teamPath, nil)); code != http.StatusOK {
+		t.Fatalf("expected 200 deleting the team, got %d", code)
+	}
+	if code := status(as("viewer", "GET", formPath, nil)); code != http.StatusForbidden {
+		t.Errorf("expected 403 once the team is gone, got %d", code)
+	}
+	if code := status(as("owner", "GET", formPath, nil)); code != http.StatusOK {
+		t.Errorf("expected the owner to keep the form, got %d", code)
+	}
+}
+
+// exportHash is how an export profile keyed with key hashes value
+func exportHash(key []byte, value string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestExportPermissions(t *testing.T) {
+	user := &domain.User{ID: "usr_exporter", Email: "exporter@example.com", Role: domain.RoleUser}
+	ts := NewUserTestServer(t, user)
+	defer ts.Close()
+
+	asSuperAdmin := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), middleware.UserIDKey, "usr_root")
+			ctx = context.WithValue(ctx, middleware.RoleKey, "super_admin")
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+	settingsHandler := api.NewSettingsHandler(ts.Store)
+	ts.Mux.Handle("GET /admin/settings/exports", asSuperAdmin(http.HandlerFunc(settingsHandler.HandleGetExports)))
+	ts.Mux.Handle("PUT /admin/settings/exports", asSuperAdmin(http.HandlerFunc(settingsHandler.HandleSetExports)))
+
+	var created map[string]interface{}
+	ParseResponse(t, ts.Request(t, "POST", "/api/v1/forms", map[string]interface{}{"name": "Export Form"}), &created)
+	publicID := created["data"].(map[string]interface{})["public_id"].(string)
+	fields := map[string]interface{}{"fields": []map[string]interface{}{{"name": "email", "type": "email"}, {"name": "note"}, {"name": "topic"}}}
+	if resp := ts.Request(t, "PUT", "/api/v1/forms/"+publicID+"/fields", fields); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 for fields, got %d", resp.StatusCode)
+	}
+	resp := ts.Request(t, "POST", "/api/v1/submissions/"+publicID, map[string]interface{}{"email": "ada@example.com", "note": "call me", "topic": "sales"})
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", resp.StatusCode)
+	}
+	resp.Body.Close()
+
+	exportCSV := func() (int, string) {
+		resp := ts.Request(t, "GET", "/api/v1/forms/"+publicID+"/export/csv", nil)
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+
+	// Without export settings everyone exports in full
+	if code, body := exportCSV(); code != http.StatusOK || !strings.Contains(body, "ada@example.com") || !strings.Contains(body, "call me") {
+		t.Fatalf("expected a full export, got %d:\n%s", code, body)
+	}
+
+	// Profiles must be known and roles must point at one of them
+	invalid := map[string]interface{}{"roles": map[string]string{"user": "missing"}}
+	if resp := ts.Request(t, "PUT", "/admin/settings/exports", invalid); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 for an unknown profile, got %d", resp.StatusCode)
+	}
+	var result map[string]interface{}
+	ParseResponse(t, ts.Request(t, "PUT", "/admin/settings/exports", map[string]interface{}{
+		"roles":    map[string]string{"user": "analyst", "team_viewer": "none"},
+		"profiles": []map[string]interface{}{{"id": "Analyst", "pii": "hash", "fields": map[string]string{"note": "remove"}}},
+	}), &result)
+	roles := result["data"].(map[string]interface{})["roles"].(map[string]interface{})
+	if roles["user"] != "analyst" || roles["form_token"] != "full" || roles["team_viewer"] != "none" {
+		t.Errorf("expected every role's permission, got %v", roles)
+	}
+
+	// Users now get the email hashed and the note left out
+	code, body := exportCSV()
+	if code != http.StatusOK || strings.Contains(body, "ada@example.com") || !strings.Contains(body, exportHash(nil, "ada@example.com")) {
+		t.Errorf("expected the email hashed, got %d:\n%s", code, body)
+	}
+	if strings.Contains(body, "note") || strings.Contains(body, "call me") || !strings.Contains(body, "sales") {
+		t.Errorf("expected the note removed and the topic kept, got:\n%s", body)
+	}
+
+	// A role set to none can't export at all
+	settings, _ := ts.Store.Settings().Get(context.Background())
+	settings.Exports.Roles[domain.ExportRoleUser] = domain.ExportNone
+	if err := ts.Store.Settings().Save(context.Background(), settings); err != nil {
+		t.Fatal(err)
+	}
+	resp = ts.Request(t, "GET", "/api/v1/forms/"+publicID+"/export/json", nil)
+	ParseResponse(t, resp, &result)
+	if resp.StatusCode != http.StatusForbidden || result["code"] != "EXPORT_NOT_ALLOWED" {
+		t.Errorf("expected 403 EXPORT_NOT_ALLOWED, got %d %v", resp.StatusCode, result)
+	}
+}
+
+func TestSubmissionReadsMasked(t *testing.T) {
+	store, err := sqlite.New(":memory:")
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	user := &domain.User{ID: "usr_reader", Email: "reader@example.com", Role: domain.RoleUser}
+	if err := store.User().Create(context.Background(), user); err != nil {
+		t.Fatal(err)
+	}
+	asUser := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), middleware.UserIDKey, user.ID)
+			ctx = context.WithValue(ctx, middleware.RoleKey, string(user.Role))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+	feed := service.NewSubmissionFeed()
+	submService := service.NewSubmissionService(store)
+	submService.SetExportHashKey([]byte("export-key"))
+	submService.SetFeed(feed)
+	submService.SetFileStorage(filestore.NewLocal(t.TempDir()))
+	router := api.NewRouter(service.NewFormService(store), submService, service.NewStatsService(store))
+	router.SetSubmissionFeed(feed)
+	mux := http.NewServeMux()
+	router.RegisterPublicRoutes(mux, asUser)
+	router.RegisterProtectedRoutes(mux, asUser)
+	ts := &TestServer{Server: httptest.NewServer(mux), Store: store, Mux: mux, Router: router}
+	defer ts.Close()
+
+	var created map[string]interface{}
+	ParseResponse(t, ts.Request(t, "POST", "/api/v1/forms", map[string]interface{}{"name": "Masked"}), &created)
+	publicID := created["data"].(map[string]interface{})["public_id"].(string)
+	ts.Request(t, "PUT", "/api/v1/forms/"+publicID+"/uploads", map[string]interface{}{"max_file_size": 1024, "allowed_types": []string{"application/pdf"}}).Body.Close()
+	ts.Request(t, "PUT", "/api/v1/forms/"+publicID+"/fields", map[string]interface{}{
+		"fields": []map[string]interface{}{{"name": "email", "type": "email"}, {"name": "note"}, {"name": "resume"}, {"name": "topic"}},
+	}).Body.Close()
+	var result map[string]interface{}
+	resp := postMultipart(t, ts, publicID, map[string]string{"email": "ada@example.com", "note": "call me", "topic": "sales"}, map[string]string{"resume": "%PDF-1.4 my resume"})
+	ParseResponse(t, resp, &result)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201, got %d %v", resp.StatusCode, result)
+	}
+	subID := result["data"].(map[string]interface{})["id"].(string)
+	fileID := result["data"].(map[string]interface{})["data"].(map[string]interface{})["resume"].(map[string]interface{})["file_id"].(string)
+
+	settings, _ := store.Settings().Get(context.Background())
+	settings.Exports = &domain.ExportSettings{
+		Roles:    map[domain.ExportRole]string{domain.ExportRoleUser: "analyst"},
+		Profiles: []domain.ExportProfile{{ID: "analyst", PII: domain.MaskHash, Fields: map[string]domain.MaskAction{"note": domain.MaskRemove, "resume": domain.MaskRemove}}},
+	}
+	if err := store.Settings().Save(context.Background(), settings); err != nil {
+		t.Fatal(err)
+	}
+	// Hashes are keyed, so they don't match a plain SHA-256 of a guess
+	hashed := exportHash([]byte("export-key"), "ada@example.com")
+	sum := sha256.Sum256([]byte("ada@example.com"))
+	masked := func(what, body string) {
+		t.Helper()
+		if strings.Contains(body, "ada@example.com") || strings.Contains(body, "call me") || strings.Contains(body, fileID) || strings.Contains(body, hex.EncodeToString(sum[:])) {
+			t.Errorf("expected %s masked, got:\n%s", what, body)
+		}
+		// The user agent and the client's own metadata are personal data too
+		if strings.Contains(body, "Go-http-client") || strings.Contains(body, "spring-campaign") {
+			t.Errorf("expected %s masked, got:\n%s", what, body)
+		}
+	}
+	read := func(path string) (int, string) {
+		resp := ts.Request(t, "GET", path, nil)
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+
+	// Every read path masks like the export does
+	code, body := read("/api/v1/forms/" + publicID + "/submissions")
+	if code != http.StatusOK || !strings.Contains(body, hashed) || !strings.Contains(body, "sales") {
+		t.Errorf("expected the list masked, got %d %s", code, body)
+	}
+	masked("the list", body)
+	code, body = read("/api/v1/submissions/" + subID)
+	if code != http.StatusOK || !strings.Contains(body, hashed) {
+		t.Errorf("expected the submission masked, got %d %s", code, body)
+	}
+	masked("the submission", body)
+	code, body = read("/api/v1/submissions/" + subID + "/pdf")
+	if code != http.StatusOK || !strings.Contains(body, "sales") {
+		t.Errorf("expected the PDF, got %d", code)
+	}
+	masked("the PDF", body)
+	if code, _ := read("/api/v1/submissions/" + subID + "/files/" + fileID); code != http.StatusForbidden {
+		t.Errorf("expected 403 for a masked field's file, got %d", code)
+	}
+	// Searching masked values would give them away
+	for _, query := range []string{"q=ada", "field.note=call+me", "field.email=ada@example.com"} {
+		if code, _ := read("/api/v1/forms/" + publicID + "/submissions?" + query); code != http.StatusForbidden {
+			t.Errorf("expected 403 for %s, got %d", query, code)
+		}
+	}
+	if code, _ := read("/api/v1/forms/" + publicID + "/submissions?field.topic=sales"); code != http.StatusOK {
+		t.Errorf("expected an unmasked field searchable, got %d", code)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", ts.Server.URL+"/api/v1/forms/"+publicID+"/submissions/stream", nil)
+	stream, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer stream.Body.Close()
+	events := bufio.NewReader(stream.Body)
+	if line, _ := events.ReadString('\n'); line != ": connected\n" {
+		t.Fatalf("expected the stream to open, got %q", line)
+	}
+	ts.Request(t, "POST", "/api/v1/submissions/"+publicID, map[string]interface{}{
+		"data": map[string]interface{}{"email": "ada@example.com", "note": "call me", "topic": "live"},
+		"meta": map[string]interface{}{"campaign": "spring-campaign"},
+	}).Body.Close()
+	for {
+		line, err := events.ReadString('\n')
+		if err != nil {
+			t.Fatalf("stream ended: %v", err)
+		}
+		if data, ok := strings.CutPrefix(line, "data: "); ok {
+			if !strings.Contains(data, "live") || !strings.Contains(data, hashed) {
+				t.Errorf("expected the streamed submission masked, got %s", data)
+			}
+			masked("the stream", data)
+			break
+		}
+	}
+	cancel()
+
+	// A role set to none can't read submissions at all
+	settings.Exports.Roles[domain.ExportRoleUser] = domain.ExportNone
+	if err := store.Settings().Save(context.Background(), settings); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{
+		"/api/v1/forms/" + publicID + "/submissions",
+		"/api/v1/forms/" + publicID + "/submissions/stream",
+		"/api/v1/submissions/" + subID,
+		"/api/v1/submissions/" + subID + "/pdf",
+		"/api/v1/submissions/" + subID + "/files/" + fileID,
+	} {
+		resp := ts.Request(t, "GET", path, nil)
+		ParseResponse(t, resp, &result)
+		if resp.StatusCode != http.StatusForbidden || result["code"] != "EXPORT_NOT_ALLOWED" {
+			t.Errorf("expected 403 EXPORT_NOT_ALLOWED for %s, got %d %v", path, resp.StatusCode, result)
+		}
+	}
+}
+
+func TestWebhookDeliveryPayloads(t *testing.T) {
+	user := &domain.User{ID: "usr_hooks", Email: "hooks@example.com", Role: domain.RoleUser}
+	ts := NewUserTestServer(t, user)
+	defer ts.Close()
+	ctx := context.Background()
+	api.NewWebhookDeliveryHandler(service.NewWebhookDeliveryService(ts.Store), service.NewFormService(ts.Store), service.NewSubmissionService(ts.Store)).RegisterRoutes(ts.Mux, ts.Auth)
+
+	var created map[string]interface{}
+	ParseResponse(t, ts.Request(t, "POST", "/api/v1/forms", map[string]interface{}{"name": "Hooks"}), &created)
+	publicID := created["data"].(map[string]interface{})["public_id"].(string)
+	form, _ := ts.Store.Form().GetByPublicID(ctx, publicID)
+	delivery := &domain.WebhookDelivery{ID: "whd_payload", FormID: form.ID, Event: "submission.created", URL: "https://hooks.example.com", Payload: json.RawMessage(`{"email":"ada@example.com"}`), Status: domain.WebhookDeliverySucceeded, CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	if err := ts.Store.WebhookDelivery().Create(ctx, delivery); err != nil {
+		t.Fatal(err)
+	}
+	path := "/api/v1/forms/" + publicID + "/webhook-deliveries/" + delivery.ID
+	read := func() string {
+		resp := ts.Request(t, "GET", path, nil)
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected 200, got %d", resp.StatusCode)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		return string(body)
+	}
+
+	// Owners reading submissions in full see what was sent
+	if body := read(); !strings.Contains(body, "ada@example.com") {
+		t.Errorf("expected the payload, got %s", body)
+	}
+
+	// A payload can't be masked, so a masked role gets none and can't replay it elsewhere
+	settings, _ := ts.Store.Settings().Get(ctx)
+	settings.Exports = &domain.ExportSettings{
+		Roles:    map[domain.ExportRole]string{domain.ExportRoleUser: "analyst"},
+		Profiles: []domain.ExportProfile{{ID: "analyst", PII: domain.MaskHash}},
+	}
+	if err := ts.Store.Settings().Save(ctx, settings); err != nil {
+		t.Fatal(err)
+	}
+	if body := read(); strings.Contains(body, "ada@example.com") || strings.Contains(body, `"payload"`) {
+		t.Errorf("expected the payload left out, got %s", body)
+	}
+	var result map[string]interface{}
+	resp := ts.Request(t, "POST", path+"/replay", nil)
+	ParseResponse(t, resp, &result)
+	if resp.StatusCode != http.StatusForbidden || result["code"] != "EXPORT_NOT_ALLOWED" {
+		t.Errorf("expected 403 EXPORT_NOT_ALLOWED for the replay, got %d %v", resp.StatusCode, result)
+	}
+}
+
+func TestSubmissionDetails(t *testing.T) {
+	ts := NewTestServer(t)
+	defer ts.Close()
+
+	var created map[string]interface{}
+	ParseResponse(t, ts.Request(t, "POST", "/api/v1/forms", map[string]interface{}{"name": "Details"}), &created)
+	publicID := created["data"].(map[string]interface{})["public_id"].(string)
+
+	body := `{"data": {"email": "ada@example.com"}, "meta": {"page": "/pricing"}}`
+	req, _ := http.NewRequest("POST", ts.Server.URL+"/api/v1/submissions/"+publicID, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Mozilla/5.0 (iPhone; CPU iPhone OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Mobile/15E148 Safari/604.1")
+	req.Header.Set("Accept-Language", "de-DE,de;q=0.9,en;q=0.8")
+	req.Header.Set("CF-IPCountry", "DE")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var submitted map[string]interface{}
+	ParseResponse(t, resp, &submitted)
+	subID := submitted["data"].(map[string]interface{})["id"].(string)
+
+	var result map[string]interface{}
+	ParseResponse(t, ts.Request(t, "GET", "/api/v1/submissions/"+subID, nil), &result)
+	data := result["data"].(map[string]interface{})
+	if data["id"] != subID || data["meta"] == nil {
+		t.Fatalf("expected the submission kept as it is, got %v", data)
+	}
+	details := data["details"].(map[string]interface{})
+	want := map[string]interface{}{
+		"browser": "Safari", "browser_version": "17", "os": "iOS", "device": "mobile",
+		"language": "de-DE", "country": "DE", "timezone": "Europe/Berlin", "is_spam": false,
+	}
+	for key, value := range want {
+		if details[key] != value {
+			t.Errorf("expected details.%s %v, got %v", key, value, details[key])
+		}
+	}
+	if _, ok := details["spam_score"].(float64); !ok || details["ip"] == "" {
+		t.Errorf("expected the spam score and ip, got %v", details)
+	}
+	if client, _ := details["client"].(map[string]interface{}); client["page"] != "/pricing" {
+		t.Errorf("expected the client meta, got %v", details["client"])
+	}
+}
+
+func TestSubmissionLinks(t *testing.T) {
+	user := &domain.User{ID: "usr_linker", Email: "linker@example.com", Role: domain.RoleUser}
+	ts := NewUserTestServer(t, user)
+	defer ts.Close()
+	ctx := context.Background()
+
+	createForm := func(name string) string {
+		var created map[string]interface{}
+		ParseResponse(t, ts.Request(t, "POST", "/api/v1/forms", map[string]interface{}{"name": name}), &created)
+		return created["data"].(map[string]interface{})["public_id"].(string)
+	}
+	submit := func(publicID string, data map[string]interface{}) string {
+		var submitted map[string]interface{}
+		ParseResponse(t, ts.Request(t, "POST", "/api/v1/submissions/"+publicID, data), &submitted)
+		return submitted["data"].(map[string]interface{})["id"].(string)
+	}
+	related := func(subID string) []interface{} {
+		var result map[string]interface{}
+		ParseResponse(t, ts.Request(t, "GET", "/api/v1/submissions/"+subID+"/related", nil), &result)
+		return result["data"].([]interface{})
+	}
+
+	applications := createForm("Applications")
+	interviews := createForm("Interviews")
+	tooLong := map[string]interface{}{"link_field": strings.Repeat("x", 501)}
+	if resp := ts.Request(t, "PUT", "/api/v1/forms/"+interviews+"/link-field", tooLong); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 for a too long link field, got %d", resp.StatusCode)
+	}
+	if resp := ts.Request(t, "PUT", "/api/v1/forms/"+interviews+"/link-field", map[string]interface{}{"link_field": " application_id "}); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 for the link field, got %d", resp.StatusCode)
+	}
+
+	// The interview names the application in its link field
+	application := submit(applications, map[string]interface{}{"name": "Ada"})
+	interview := submit(interviews, map[string]interface{}{"application_id": application, "verdict": "hire"})
+	refs := related(interview)
+	if len(refs) != 1 {
+		t.Fatalf("expected the application, got %v", refs)
+	}
+	ref := refs[0].(map[string]interface{})
+	if ref["relation"] != domain.RelationReferences || ref["source"] != string(domain.LinkSourceField) || ref["form_id"] != applications ||
+		ref["submission"].(map[string]interface{})["id"] != application {
+		t.Errorf("expected a field link to the application, got %v", ref)
+	}
+	backRefs := related(application)
+	if len(backRefs) != 1 || backRefs[0].(map[string]interface{})["relation"] != domain.RelationReferencedBy {
+		t.Errorf("expected the interview referencing the application, got %v", backRefs)
+	}
+
+	// Submissions of someone else's form are neither linked by field nor by hand
+	foreign := &domain.Form{ID: "form-foreign", PublicID: "foreign", OwnerID: "usr_other", Name: "Other", CreatedAt: time.Now()}
+	if err := ts.Store.Form().Create(ctx, foreign); err != nil {
+		t.Fatal(err)
+	}
+	foreignSub := &domain.Submission{ID: "sub_foreign", FormID: foreign.ID, Status: domain.SubmissionStatusUnread, Data: json.RawMessage(`{}`), CreatedAt: time.Now()}
+	if err := ts.Store.Submission().Create(ctx, foreignSub); err != nil {
+		t.Fatal(err)
+	}
+	if stray := submit(interviews, map[string]interface{}{"application_id": foreignSub.ID}); len(related(stray)) != 0 {
+		t.Error("expected no link to another owner's submission")
+	}
+	if resp := ts.Request(t, "POST", "/api/v1/submissions/"+interview+"/links", map[string]interface{}{"submission_id": foreignSub.ID}); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("expected 403 linking another owner's submission, got %d", resp.StatusCode)
+	}
+
+	// Links by hand
+	if resp := ts.Request(t, "POST", "/api/v1/submissions/"+interview+"/links", map[string]interface{}{"submission_id": interview}); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 linking a submission to itself, got %d", resp.StatusCode)
+	}
+	other := submit(applications, map[string]interface{}{"name": "Grace"})
+	var linked map[string]interface{}
+	ParseResponse(t, ts.Request(t, "POST", "/api/v1/submissions/"+interview+"/links", map[string]interface{}{"submission_id": other}), &linked)
+	link := linked["data"].(map[string]interface{})
+	if link["source"] != string(domain.LinkSourceManual) || link["created_by"] != user.ID {
+		t.Errorf("expected a manual link by the user, got %v", link)
+	}
+	if refs := related(interview); len(refs) != 2 {
+		t.Errorf("expected both applications, got %v", refs)
+	}
+
+	// Unlinking works from either side, once
+	if resp := ts.Request(t, "DELETE", "/api/v1/submissions/"+application+"/links/"+interview, nil); resp.StatusCode != http.StatusOK {
+		t.Errorf("expected 200 unlinking, got %d", resp.StatusCode)
+	}
+	if resp := ts.Request(t, "DELETE", "/api/v1/submissions/"+interview+"/links/"+application, nil); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404 for a removed link, got %d", resp.StatusCode)
+	}
+	if refs := related(interview); len(refs) != 1 || refs[0].(map[string]interface{})["submission"].(map[string]interface{})["id"] != other {
+		t.Errorf("expected only the manually linked application, got %v", refs)
+	}
+
+	// Links go with the submission
+	if resp := ts.Request(t, "DELETE", "/api/v1/submissions/"+other, nil); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 deleting, got %d", resp.StatusCode)
+	}
+	if refs := related(interview); len(refs) != 0 {
+		t.Errorf("expected no links left, got %v", refs)
+	}
+}
+
+func TestHiddenFields(t *testing.T) {
+	ts := NewTestServer(t)
+	defer ts.Close()
+
+	var created map[string]interface{}
+	ParseResponse(t, ts.Request(t, "POST", "/api/v1/forms", map[string]interface{}{"name": "Campaign"}), &created)
+	publicID := created["data"].(map[string]interface{})["public_id"].(string)
+
+	if resp := ts.Request(t, "PUT", "/api/v1/forms/"+publicID+"/hidden-fields", map[string]interface{}{"hidden_fields": map[string]string{" ": "x"}}); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 for an empty name, got %d", resp.StatusCode)
+	}
+	var result map[string]interface{}
+	ParseResponse(t, ts.Request(t, "PUT", "/api/v1/forms/"+publicID+"/hidden-fields", map[string]interface{}{
+		"hidden_fields": map[string]string{" campaign ": "spring2025", "source": "landing"},
+	}), &result)
+	if hidden, _ := result["data"].(map[string]interface{})["hidden_fields"].(map[string]interface{}); hidden["campaign"] != "spring2025" {
+		t.Fatalf("expected the hidden fields on the form, got %v", result["data"])
+	}
+
+	stored := func(subID string) map[string]interface{} {
+		var result map[string]interface{}
+		ParseResponse(t, ts.Request(t, "GET", "/api/v1/submissions/"+subID, nil), &result)
+		return result["data"].(map[string]interface{})["data"].(map[string]interface{})
+	}
+
+	// The client can't change them, as JSON or as a form post
+	var submitted map[string]interface{}
+	ParseResponse(t, ts.Request(t, "POST", "/api/v1/submissions/"+publicID, map[string]interface{}{"email": "ada@example.com", "campaign": "free-stuff"}), &submitted)
+	data := stored(submitted["data"].(map[string]interface{})["id"].(string))
+	if data["campaign"] != "spring2025" || data["source"] != "landing" || data["email"] != "ada@example.com" {
+		t.Errorf("expected the hidden fields over the client's, got %v", data)
+	}
+	req, _ := http.NewRequest("POST", ts.Server.URL+"/api/v1/submissions/"+publicID, strings.NewReader("email=grace%40example.com&source=ads"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ParseResponse(t, resp, &submitted)
+	if data := stored(submitted["data"].(map[string]interface{})["id"].(string)); data["source"] != "landing" || data["campaign"] != "spring2025" {
+		t.Errorf("expected the hidden fields in a form post, got %v", data)
+	}
+
+	// Removing them keeps what the client sends
+	if resp := ts.Request(t, "PUT", "/api/v1/forms/"+publicID+"/hidden-fields", map[string]interface{}{"hidden_fields": map[string]string{}}); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 removing hidden fields, got %d", resp.StatusCode)
+	}
+	ParseResponse(t, ts.Request(t, "POST", "/api/v1/submissions/"+publicID, map[string]interface{}{"campaign": "free-stuff"}), &submitted)
+	if data := stored(submitted["data"].(map[string]interface{})["id"].(string)); data["campaign"] != "free-stuff" || data["source"] != nil {
+		t.Errorf("expected the client's data only, got %v", data)
+	}
+}
+
+func TestFormArchiving(t *testing.T) {
+	ts := NewTestServer(t)
+	defer ts.Close()
+
+	var created map[string]interface{}
+	ParseResponse(t, ts.Request(t, "POST", "/api/v1/forms", map[string]interface{}{"name": "Spring Survey"}), &created)
+	publicID := created["data"].(map[string]interface{})["public_id"].(string)
+	ParseResponse(t, ts.Request(t, "POST", "/api/v1/forms", map[string]interface{}{"name": "Contact"}), &created)
+	resp := ts.Request(t, "POST", "/api/v1/submissions/"+publicID, map[string]interface{}{"answer": "yes"})
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", resp.StatusCode)
+	}
+	resp.Body.Close()
+
+	var result map[string]interface{}
+	ParseResponse(t, ts.Request(t, "PUT", "/api/v1/forms/"+publicID+"/archive", nil), &result)
+	if status := result["data"].(map[string]interface{})["status"]; status != string(domain.FormStatusArchived) {
+		t.Fatalf("expected the form archived, got %v", status)
+	}
+
+	// Closed to submissions, dry runs included
+	resp = ts.Request(t, "POST", "/api/v1/submissions/"+publicID, map[string]interface{}{"answer": "no"})
+	ParseResponse(t, resp, &result)
+	if resp.StatusCode != http.StatusBadRequest || result["message"] != domain.ErrFormArchived.Error() {
+		t.Errorf("expected 400 archived, got %d: %v", resp.StatusCode, result)
+	}
+	if resp := ts.Request(t, "POST", "/api/v1/submissions/"+publicID+"?dry_run=true", map[string]interface{}{}); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 for a dry run, got %d", resp.StatusCode)
+	}
+
+	listed := func(query string) []string {
+		var result map[string]interface{}
+		ParseResponse(t, ts.Request(t, "GET", "/api/v1/forms"+query, nil), &result)
+		var names []string
+		for _, f := range result["data"].(map[string]interface{})["forms"].([]interface{}) {
+			names = append(names, f.(map[string]interface{})["name"].(string))
+		}
+		return names
+	}
+	if names := listed(""); len(names) != 1 || names[0] != "Contact" {
+		t.Errorf("expected archived forms left out, got %v", names)
+	}
+	if names := listed("?include_archived=true"); len(names) != 2 {
+		t.Errorf("expected both forms with include_archived, got %v", names)
+	}
+	if names := listed("?status=archived"); len(names) != 1 || names[0] != "Spring Survey" {
+		t.Errorf("expected only the archived form, got %v", names)
+	}
+
+	// Its submissions are kept
+	ParseResponse(t, ts.Request(t, "GET", "/api/v1/forms/"+publicID+"/submissions", nil), &result)
+	if subs := result["data"].(map[string]interface{})["submissions"].([]interface{}); len(subs) != 1 {
+		t.Errorf("expected the submission kept, got %v", subs)
+	}
+
+	ParseResponse(t, ts.Request(t, "DELETE", "/api/v1/forms/"+publicID+"/archive", nil), &result)
+	if status := result["data"].(map[string]interface{})["status"]; status != string(domain.FormStatusActive) {
+		t.Errorf("expected the form active again, got %v", status)
+	}
+	if resp := ts.Request(t, "POST", "/api/v1/submissions/"+publicID, map[string]interface{}{"answer": "no"}); resp.StatusCode != http.StatusCreated {
+		t.Errorf("expected 201 after unarchiving, got %d", resp.StatusCode)
+	}
+}
+
+func TestWebhookRateSettings(t *testing.T) {
+	ts := NewTestServer(t)
+	defer ts.Close()
+
+	var created map[string]interface{}
+	ParseResponse(t, ts.Request(t, "POST", "/api/v1/forms", map[string]interface{}{"name": "Signups"}), &created)
+	publicID := created["data"].(map[string]interface{})["public_id"].(string)
+
+	for _, body := range []map[string]interface{}{{"max_per_minute": 6001}, {"batch_interval": 1}, {"max_batch_size": -1}} {
+		if resp := ts.Request(t, "PUT", "/api/v1/forms/"+publicID+"/webhook/rate", body); resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%v: expected 400, got %d", body, resp.StatusCode)
+		}
+	}
+	var result map[string]interface{}
+	ParseResponse(t, ts.Request(t, "PUT", "/api/v1/forms/"+publicID+"/webhook/rate", map[string]interface{}{"max_per_minute": 60, "batch_interval": 30}), &result)
+	rate, _ := result["data"].(map[string]interface{})["webhook_rate"].(map[string]interface{})
+	if rate["max_per_minute"] != float64(60) || rate["batch_interval"] != float64(30) {
+		t.Fatalf("expected the rate on the form, got %v", result["data"])
+	}
+	form, err := ts.Store.Form().GetByPublicID(context.Background(), publicID)
+	if err != nil || form.WebhookRate == nil || form.WebhookRate.MaxPerMinute != 60 {
+		t.Fatalf("expected the rate stored, got %+v (%v)", form, err)
+	}
+
+	ParseResponse(t, ts.Request(t, "DELETE", "/api/v1/forms/"+publicID+"/webhook/rate", nil), &result)
+	if result["data"].(map[string]interface{})["webhook_rate"] != nil {
+		t.Errorf("expected the rate removed, got %v", result["data"])
+	}
+}
+
+func TestFormSchedule(t *testing.T) {
+	ts := NewTestServer(t)
+	defer ts.Close()
+
+	var created map[string]interface{}
+	ParseResponse(t, ts.Request(t, "POST", "/api/v1/forms", map[string]interface{}{"name": "Registration"}), &created)
+	publicID := created["data"].(map[string]interface{})["public_id"].(string)
+
+	now := time.Now().UTC()
+	setSchedule := func(opensAt, closesAt time.Time) map[string]interface{} {
+		t.Helper()
+		body := map[string]interface{}{}
+		if !opensAt.IsZero() {
+			body["opens_at"] = opensAt.Format(time.RFC3339)
+		}
+		if !closesAt.IsZero() {
+			body["closes_at"] = closesAt.Format(time.RFC3339)
+		}
+		var result map[string]interface{}
+		resp := ts.Request(t, "PUT", "/api/v1/forms/"+publicID+"/schedule", body)
+		ParseResponse(t, resp, &result)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("set schedule: %d %v", resp.StatusCode, result)
+		}
+		return result["data"].(map[string]interface{})
+	}
+	submit := func() (int, map[string]interface{}) {
+		var result map[string]interface{}
+		resp := ts.Request(t, "POST", "/api/v1/submissions/"+publicID, map[string]interface{}{"email": "ada@example.com"})
+		ParseResponse(t, resp, &result)
+		return resp.StatusCode, result
+	}
+	listedSchedule := func() interface{} {
+		var result map[string]interface{}
+		ParseResponse(t, ts.Request(t, "GET", "/api/v1/forms", nil), &result)
+		for _, f := range result["data"].(map[string]interface{})["forms"].([]interface{}) {
+			if f := f.(map[string]interface{}); f["public_id"] == publicID {
+				return f["schedule"]
+			}
+		}
+		t.Fatal("form not listed")
+		return nil
+	}
+
+	if resp := ts.Request(t, "PUT", "/api/v1/forms/"+publicID+"/schedule", map[string]interface{}{
+		"opens_at": now.Format(time.RFC3339), "closes_at": now.Add(-time.Hour).Format(time.RFC3339),
+	}); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 for a form closing before it opens, got %d", resp.StatusCode)
+	}
+
+	// Not open yet
+	form := setSchedule(now.Add(time.Hour), now.Add(2*time.Hour))
+	if form["schedule"] != domain.ScheduleUpcoming || form["opens_at"] == nil {
+		t.Errorf("expected an upcoming schedule, got %v", form)
+	}
+	if status, result := submit(); status != http.StatusForbidden || result["code"] != "FORM_CLOSED" {
+		t.Errorf("expected 403 FORM_CLOSED before opens_at, got %d %v", status, result)
+	}
+	if s := listedSchedule(); s != domain.ScheduleUpcoming {
+		t.Errorf("expected the list to show upcoming, got %v", s)
+	}
+
+	// Open
+	setSchedule(now.Add(-time.Hour), now.Add(time.Hour))
+	if status, result := submit(); status != http.StatusCreated {
+		t.Errorf("expected 201 within the window, got %d %v", status, result)
+	}
+	if s := listedSchedule(); s != domain.ScheduleOpen {
+		t.Errorf("expected the list to show open, got %v", s)
+	}
+
+	// Closed, with no opening time
+	setSchedule(time.Time{}, now.Add(-time.Minute))
+	if status, result := submit(); status != http.StatusForbidden || result["code"] != "FORM_CLOSED" || result["message"] != domain.ErrFormClosed.Error() {
+		t.Errorf("expected 403 FORM_CLOSED after closes_at, got %d %v", status, result)
+	}
+	if resp := ts.Request(t, "POST", "/api/v1/submissions/"+publicID+"?dry_run=true", map[string]interface{}{}); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("expected 403 for a dry run, got %d", resp.StatusCode)
+	}
+	if s := listedSchedule(); s != domain.ScheduleClosed {
+		t.Errorf("expected the list to show closed, got %v", s)
+	}
+
+	var result map[string]interface{}
+	ParseResponse(t, ts.Request(t, "DELETE", "/api/v1/forms/"+publicID+"/schedule", nil), &result)
+	if data := result["data"].(map[string]interface{}); data["closes_at"] != nil || data["schedule"] != nil {
+		t.Errorf("expected the schedule removed, got %v", data)
+	}
+	if status, _ := submit(); status != http.StatusCreated {
+		t.Errorf("expected 201 without a schedule, got %d", status)
+	}
+
+	// Specs round-trip the window
+	spec := map[string]interface{}{"name": "Event", "opens_at": "2030-05-01T09:00:00+02:00", "closes_at": "2030-05-15T17:00:00Z"}
+	ParseResponse(t, ts.Request(t, "PUT", "/api/v1/form-specs/event", spec), &result)
+	ParseResponse(t, ts.Request(t, "PUT", "/api/v1/form-specs/event", spec), &result)
+	if data := result["data"].(map[string]interface{}); data["result"] != "unchanged" {
+		t.Errorf("expected re-applying a scheduled spec to be a no-op, got %v", data)
+	}
+}
+
+func TestWebhookSecretReferenceAdminOnly(t *testing.T) {
+	ts := NewUserTestServer(t, &domain.User{ID: "user-1", Email: "user@example.com", PasswordHash: "x", Role: domain.RoleUser})
+	defer ts.Close()
+
+	body := map[string]interface{}{"name": "Contact", "webhook_url": "https://hooks.example.com/x", "webhook_secret": "vault:secret/data/ops#token"}
+	if resp := ts.Request(t, "POST", "/api/v1/forms", body); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("expected 403 for a user referencing a secret manager, got %d", resp.StatusCode)
+	}
+	spec := map[string]interface{}{"name": "Contact", "webhook": map[string]interface{}{"url": "https://hooks.example.com/x", "secret": "aws-sm:prod/db#password"}}
+	if resp := ts.Request(t, "PUT", "/api/v1/form-specs/contact", spec); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("expected 403 for a spec referencing a secret manager, got %d", resp.StatusCode)
+	}
+	var created map[string]interface{}
+	body["webhook_secret"] = "whsec"
+	ParseResponse(t, ts.Request(t, "POST", "/api/v1/forms", body), &created)
+	publicID := created["data"].(map[string]interface{})["public_id"].(string)
+	body["webhook_secret"] = "vault:secret/data/ops#token"
+	if resp := ts.Request(t, "PUT", "/api/v1/forms/"+publicID, body); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("expected 403 for an update referencing a secret manager, got %d", resp.StatusCode)
+	}
+
+	admin := NewUserTestServer(t, &domain.User{ID: "admin-1", Email: "admin@example.com", PasswordHash: "x", Role: domain.RoleAdmin})
+	defer admin.Close()
+	var result map[string]interface{}
+	resp := admin.Request(t, "POST", "/api/v1/forms", body)
+	ParseResponse(t, resp, &result)
+	if resp.StatusCode != http.StatusCreated || result["data"].(map[string]interface{})["webhook_secret"] != "vault:secret/data/ops#token" {
+		t.Errorf("expected admins to store the reference as it is, got %d %v", resp.StatusCode, result["data"])
 	}
 }
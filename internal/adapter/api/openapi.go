@@ -4,6 +4,8 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+
+	"headless_form/internal/adapter/middleware"
 )
 
 // OpenAPIHandler serves the OpenAPI specification and Swagger UI
@@ -74,7 +76,7 @@ func (h *OpenAPIHandler) ServeSwaggerUI(w http.ResponseWriter, r *http.Request)
 </html>`
 
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	_, _ = w.Write([]byte(html))
+	_, _ = w.Write(middleware.InjectNonce([]byte(html), middleware.CSPNonce(r.Context())))
 }
 
 // RegisterDocsRoutes registers documentation routes
@@ -0,0 +1,690 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"maps"
+	"mime"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"headless_form/internal/adapter/api/request"
+	"headless_form/internal/adapter/api/response"
+	"headless_form/internal/adapter/middleware"
+	"headless_form/internal/adapter/spam"
+	"headless_form/internal/core/domain"
+	"headless_form/internal/core/service"
+)
+
+// =============================================================================
+// Submission Pipeline
+// =============================================================================
+
+// Built-in stage names, in execution order. Use them with InsertBefore/InsertAfter
+// to add stages (dedupe, custom checks, ...) without touching HandleSubmit.
+const (
+	StageCORS        = "cors"
+	StageParse       = "parse"
+	StageValidate    = "validate"
+	StageAccess      = "access"
+	StageIdempotency = "idempotency"
+	StageCaptcha     = "captcha"
+	StageConsent     = "consent"
+	StageSpam        = "spam"
+	StageEnrich      = "enrich"
+	StageLinks       = "links"
+	StageFiles       = "files"
+	StagePersist     = "persist"
+	StageNotify      = "notify"
+	StageRespond     = "respond"
+)
+
+// Submission bodies: the size allowed for forms without uploads, and how much of a
+// multipart body is kept in memory before files spill to temporary files
+const (
+	maxSubmissionBody = 1 << 20
+	multipartMemory   = 8 << 20
+)
+
+// linkCheckTimeout bounds verifying one file_url link
+const linkCheckTimeout = 5 * time.Second
+
+// Headers of signed server-to-server submissions to private forms
+const (
+	SignatureHeader          = "X-Form-Signature"
+	SignatureTimestampHeader = "X-Form-Timestamp"
+)
+
+// SubmissionContext carries the state of one submission through the pipeline
+type SubmissionContext struct {
+	W        http.ResponseWriter
+	R        *http.Request
+	PublicID string
+
+	Form           *domain.Form           // Set by cors (or validate if cors was removed)
+	Data           map[string]interface{} // Set by parse
+	Body           []byte                 // Raw body, kept by parse for signed requests
+	IdempotencyKey string                 // Set by idempotency when the client sent one
+	Files          []service.Upload       // Files of a multipart body, set by parse
+	Meta           domain.SubmissionMeta  // Built up by parse, access, consent, spam, enrich, links and files
+	Submission     *domain.Submission     // Set by persist
+
+	halted bool
+}
+
+// IsHTMLForm reports whether the request came from a browser form post
+func (c *SubmissionContext) IsHTMLForm() bool {
+	contentType := c.R.Header.Get("Content-Type")
+	return strings.Contains(contentType, "application/x-www-form-urlencoded") || strings.Contains(contentType, "multipart/form-data")
+}
+
+// Halt stops the pipeline after the current stage. The stage is responsible for writing the response.
+func (c *SubmissionContext) Halt() {
+	c.halted = true
+}
+
+// StageError is returned by a stage to reject a submission with a specific response
+type StageError struct {
+	Status  int
+	Message string
+	Code    string
+}
+
+func (e *StageError) Error() string {
+	return e.Message
+}
+
+// SubmissionStage is a named step of the submission pipeline
+type SubmissionStage struct {
+	Name string
+	Run  func(c *SubmissionContext) error
+}
+
+// SubmissionPipeline runs submission stages in order, stopping at the first error or Halt
+type SubmissionPipeline struct {
+	stages []SubmissionStage
+}
+
+// Use appends a stage to the end of the pipeline
+func (p *SubmissionPipeline) Use(stage SubmissionStage) {
+	p.stages = append(p.stages, stage)
+}
+
+// InsertBefore adds a stage before the named stage. Returns false if the name is unknown.
+func (p *SubmissionPipeline) InsertBefore(name string, stage SubmissionStage) bool {
+	return p.insertAt(name, 0, stage)
+}
+
+// InsertAfter adds a stage after the named stage. Returns false if the name is unknown.
+func (p *SubmissionPipeline) InsertAfter(name string, stage SubmissionStage) bool {
+	return p.insertAt(name, 1, stage)
+}
+
+func (p *SubmissionPipeline) insertAt(name string, offset int, stage SubmissionStage) bool {
+	for i, s := range p.stages {
+		if s.Name == name {
+			idx := i + offset
+			p.stages = append(p.stages[:idx], append([]SubmissionStage{stage}, p.stages[idx:]...)...)
+			return true
+		}
+	}
+	return false
+}
+
+// Stages returns the stage names in execution order
+func (p *SubmissionPipeline) Stages() []string {
+	names := make([]string, len(p.stages))
+	for i, s := range p.stages {
+		names[i] = s.Name
+	}
+	return names
+}
+
+// Run executes the pipeline for a request, writing an error response if a stage fails:
+// JSON, or for browser form posts the form's error page (see writeFormError)
+func (p *SubmissionPipeline) Run(w http.ResponseWriter, r *http.Request, publicID string) {
+	c := &SubmissionContext{W: w, R: r, PublicID: publicID}
+	for _, stage := range p.stages {
+		if err := stage.Run(c); err != nil {
+			if c.WantsHTML() {
+				writeFormError(c, err)
+				return
+			}
+			writeStageError(w, err)
+			return
+		}
+		if c.halted {
+			return
+		}
+	}
+}
+
+// writeStageError maps a stage error to an HTTP response
+func writeStageError(w http.ResponseWriter, err error) {
+	var stageErr *StageError
+	if errors.As(err, &stageErr) {
+		response.Error(w, stageErr.Status, stageErr.Message, stageErr.Code)
+		return
+	}
+	if response.HandleDomainError(w, err) {
+		return
+	}
+	response.Error(w, http.StatusBadRequest, err.Error(), response.CodeSubmissionFailed)
+}
+
+// newSubmissionPipeline builds the default pipeline:
+// cors → parse → validate → access → idempotency → consent → spam → enrich → links → files → persist → notify → respond
+func (h *Router) newSubmissionPipeline() *SubmissionPipeline {
+	p := &SubmissionPipeline{}
+	p.Use(SubmissionStage{Name: StageCORS, Run: h.stageCORS})
+	p.Use(SubmissionStage{Name: StageParse, Run: h.stageParse})
+	p.Use(SubmissionStage{Name: StageValidate, Run: h.stageValidate})
+	p.Use(SubmissionStage{Name: StageAccess, Run: h.stageAccess})
+	p.Use(SubmissionStage{Name: StageIdempotency, Run: h.stageIdempotency})
+	p.Use(SubmissionStage{Name: StageCaptcha, Run: h.stageCaptcha})
+	p.Use(SubmissionStage{Name: StageConsent, Run: h.stageConsent})
+	p.Use(SubmissionStage{Name: StageSpam, Run: h.stageSpam})
+	p.Use(SubmissionStage{Name: StageEnrich, Run: h.stageEnrich})
+	p.Use(SubmissionStage{Name: StageLinks, Run: h.stageLinks})
+	p.Use(SubmissionStage{Name: StageFiles, Run: h.stageFiles})
+	p.Use(SubmissionStage{Name: StagePersist, Run: h.stagePersist})
+	p.Use(SubmissionStage{Name: StageNotify, Run: h.stageNotify})
+	p.Use(SubmissionStage{Name: StageRespond, Run: h.stageRespond})
+	return p
+}
+
+// newDryRunPipeline builds the pipeline for ?dry_run=true, used by the heartbeat monitor.
+// It checks a submission would get past the form's origin, schedule and access rules,
+// without validating the data or storing anything: cors → parse → validate (open) → access → respond
+func (h *Router) newDryRunPipeline() *SubmissionPipeline {
+	p := &SubmissionPipeline{}
+	p.Use(SubmissionStage{Name: StageCORS, Run: h.stageCORS})
+	p.Use(SubmissionStage{Name: StageParse, Run: h.stageDryRunParse})
+	p.Use(SubmissionStage{Name: StageValidate, Run: stageDryRunOpen})
+	p.Use(SubmissionStage{Name: StageAccess, Run: h.stageAccess})
+	p.Use(SubmissionStage{Name: StageRespond, Run: stageDryRunRespond})
+	return p
+}
+
+// stageDryRunParse parses the body like parse, taking an empty one as no data
+func (h *Router) stageDryRunParse(c *SubmissionContext) error {
+	if c.R.ContentLength == 0 {
+		c.Data = make(map[string]interface{})
+		return nil
+	}
+	return h.stageParse(c)
+}
+
+// stageDryRunOpen checks the form is accepting submissions
+func stageDryRunOpen(c *SubmissionContext) error {
+	return c.Form.AcceptingSubmissions()
+}
+
+// stageDryRunRespond confirms the dry run passed
+func stageDryRunRespond(c *SubmissionContext) error {
+	response.Success(c.W, map[string]interface{}{"dry_run": true, "form_id": c.Form.PublicID})
+	return nil
+}
+
+// stageParse decodes the payload based on Content-Type
+func (h *Router) stageParse(c *SubmissionContext) error {
+	// Every body is limited before anything reads it; files are only worth reading
+	// up to the form's limits
+	multipart := strings.Contains(c.R.Header.Get("Content-Type"), "multipart/form-data")
+	limit := int64(maxSubmissionBody)
+	if multipart && c.Form != nil && c.Form.Uploads != nil {
+		limit = c.Form.Uploads.MaxRequestSize()
+	}
+	c.R.Body = http.MaxBytesReader(c.W, c.R.Body, limit)
+
+	if c.R.Header.Get(SignatureHeader) != "" {
+		// The signature covers the exact bytes sent, so keep them for access
+		body, err := io.ReadAll(c.R.Body)
+		if err != nil {
+			if tooLarge(err) {
+				return errBodyTooLarge
+			}
+			return &StageError{http.StatusBadRequest, "Invalid request body", response.CodeInvalidBody}
+		}
+		c.Body = body
+		c.R.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	if multipart {
+		return parseMultipart(c)
+	}
+
+	if c.IsHTMLForm() {
+		// Standard HTML Form
+		if err := c.R.ParseForm(); err != nil {
+			if tooLarge(err) {
+				return errBodyTooLarge
+			}
+			return &StageError{http.StatusBadRequest, "Invalid form data", response.CodeInvalidForm}
+		}
+		c.Data = make(map[string]interface{})
+		for k, v := range c.R.PostForm {
+			if len(v) > 0 {
+				c.Data[k] = v[0] // Simplify to single value for standard fields
+			}
+		}
+		return nil
+	}
+
+	// Default to JSON (API/Fetch)
+	var payload map[string]interface{}
+	if err := json.NewDecoder(c.R.Body).Decode(&payload); err != nil {
+		if tooLarge(err) {
+			return errBodyTooLarge
+		}
+		return &StageError{http.StatusBadRequest, "Invalid JSON body", response.CodeInvalidBody}
+	}
+
+	// Hybrid JSON Support (Flat vs Nested)
+	if nestedData, ok := payload["data"].(map[string]interface{}); ok {
+		c.Data = nestedData
+		if nestedMeta, ok := payload["meta"].(map[string]interface{}); ok {
+			c.Meta.Client = nestedMeta // Client-provided data (may be spoofed)
+		}
+	} else {
+		c.Data = payload
+	}
+	return nil
+}
+
+// parseMultipart reads the fields of a multipart body into Data and its files into Files
+func parseMultipart(c *SubmissionContext) error {
+	if err := c.R.ParseMultipartForm(multipartMemory); err != nil {
+		if tooLarge(err) {
+			return errBodyTooLarge
+		}
+		return &StageError{http.StatusBadRequest, "Invalid form data", response.CodeInvalidForm}
+	}
+	c.Data = make(map[string]interface{})
+	for k, v := range c.R.MultipartForm.Value {
+		if len(v) > 0 {
+			c.Data[k] = v[0]
+		}
+	}
+
+	fields := make([]string, 0, len(c.R.MultipartForm.File))
+	for field := range c.R.MultipartForm.File {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		for _, fh := range c.R.MultipartForm.File[field] {
+			c.Files = append(c.Files, service.Upload{
+				Field: field,
+				Name:  fh.Filename,
+				Size:  fh.Size,
+				Open:  func() (io.ReadCloser, error) { return fh.Open() },
+			})
+		}
+	}
+	return nil
+}
+
+// errBodyTooLarge is returned when a body is cut off at its size limit
+var errBodyTooLarge = &StageError{http.StatusRequestEntityTooLarge, "Request body is too large", response.CodeFileTooLarge}
+
+// tooLarge reports whether reading the body stopped at its size limit
+func tooLarge(err error) bool {
+	var maxErr *http.MaxBytesError
+	return errors.As(err, &maxErr)
+}
+
+// stageCORS loads the form and applies its CORS policy first, so browsers can read
+// every later response, errors included. Origins outside the form's allowed
+// origins are rejected before anything is parsed or stored.
+func (h *Router) stageCORS(c *SubmissionContext) error {
+	form, err := h.loadSubmitForm(c)
+	if err != nil {
+		return err
+	}
+	if !middleware.SetFormCORS(c.W, c.R, form) {
+		return domain.ErrOriginNotAllowed
+	}
+	c.Form = form
+	return nil
+}
+
+// loadSubmitForm looks up the submitted form. While the database is down it falls
+// back to the last copy seen, so submissions can still be checked and queued.
+// Submissions to a rotated-out public ID still in its grace period get deprecation headers.
+func (h *Router) loadSubmitForm(c *SubmissionContext) (*domain.Form, error) {
+	form, previous, err := h.formService.GetSubmitForm(c.R.Context(), c.PublicID)
+	if err != nil {
+		return h.submissionService.FallbackForm(c.PublicID, err)
+	}
+	if previous {
+		setPublicIDDeprecation(c.W, form)
+	}
+	h.submissionService.RememberForm(form)
+	return form, nil
+}
+
+// stageValidate loads the form unless cors did, checks it's accepting submissions,
+// normalizes the data if the form asks for it, sets its hidden fields and validates it
+// against its field rules
+func (h *Router) stageValidate(c *SubmissionContext) error {
+	form := c.Form
+	if form == nil {
+		var err error
+		if form, err = h.loadSubmitForm(c); err != nil {
+			return err
+		}
+	}
+	// Results of form posts targeting an iframe render inside the embedding page
+	middleware.AllowFraming(c.W, form.FrameAncestors)
+	if err := form.AcceptingSubmissions(); err != nil {
+		return err
+	}
+	c.Form = form
+	// Cleaned up text is what gets validated, stored and sent on
+	form.NormalizeData(c.Data)
+	form.ApplyHiddenFields(c.Data)
+
+	// Files are only stored by the files stage, so an uploaded file fills in its field here
+	data := c.Data
+	if len(c.Files) > 0 {
+		data = maps.Clone(c.Data)
+		for _, f := range c.Files {
+			data[f.Field] = map[string]interface{}{"name": f.Name}
+		}
+	}
+	return form.ValidateData(data)
+}
+
+// stageAccess enforces the form's access mode (submission key, login or signature)
+func (h *Router) stageAccess(c *SubmissionContext) error {
+	// For private forms: record the authenticated submitter
+	if uid, ok := c.R.Context().Value("user_id").(string); ok && uid != "" {
+		c.Meta.Auth = &domain.AuthMeta{UserID: uid}
+	}
+	if sig := c.R.Header.Get(SignatureHeader); sig != "" {
+		c.Meta.Signature = &domain.RequestSignature{
+			Value:     sig,
+			Timestamp: c.R.Header.Get(SignatureTimestampHeader),
+			Body:      c.Body,
+		}
+	}
+	if err := h.submissionService.CheckAccess(c.Form, c.Data, c.Meta); err != nil {
+		return err
+	}
+	if c.Meta.Signature != nil && c.Form.AccessMode == string(domain.AccessModePrivate) {
+		c.Meta.Auth = &domain.AuthMeta{Signed: true}
+	}
+	return nil
+}
+
+// stageIdempotency answers retries of an already stored submission with the original
+// response. It runs after access so a key alone doesn't reveal a submission.
+func (h *Router) stageIdempotency(c *SubmissionContext) error {
+	key := c.R.Header.Get(IdempotencyHeader)
+	if key == "" {
+		return nil
+	}
+	if len(key) > maxIdempotencyKeyLen {
+		return &StageError{http.StatusBadRequest, "Idempotency-Key must be at most 255 characters", response.CodeValidationError}
+	}
+	c.IdempotencyKey = key
+
+	id := h.idempotency.Get(c.Form.ID, key)
+	if id == "" {
+		return nil
+	}
+	subm, err := h.submissionService.GetSubmission(c.R.Context(), id)
+	if errors.Is(err, domain.ErrSubmissionNotFound) {
+		return nil // Deleted since; store it again
+	}
+	if err != nil {
+		return err
+	}
+	c.Submission = subm
+	c.W.Header().Set("Idempotent-Replayed", "true")
+	c.Halt()
+	return h.stageRespond(c)
+}
+
+// stageCaptcha verifies the token of forms requiring a captcha with the provider and removes
+// it from the data. It runs after idempotency, as tokens are single-use and a retry would
+// fail. If the provider can't be reached the submission is rejected, so it can be retried.
+func (h *Router) stageCaptcha(c *SubmissionContext) error {
+	cfg := c.Form.Captcha
+	if cfg == nil {
+		return nil
+	}
+	field := cfg.TokenField()
+	token, _ := c.Data[field].(string)
+	delete(c.Data, field)
+
+	remoteIP := ""
+	if !c.Form.Anonymous {
+		remoteIP = request.GetClientIP(c.R)
+	}
+	err := h.captcha.Verify(c.R.Context(), cfg, token, remoteIP)
+	if err != nil && !errors.Is(err, domain.ErrCaptchaFailed) {
+		log.Printf("[CAPTCHA] Verifying form %s: %v", c.Form.PublicID, err)
+		return &StageError{http.StatusServiceUnavailable, "Captcha could not be verified, please retry", response.CodeCaptchaUnavailable}
+	}
+	return err
+}
+
+// stageConsent rejects submissions missing a required consent checkbox and records what was accepted
+func (h *Router) stageConsent(c *SubmissionContext) error {
+	consent, err := h.submissionService.CheckConsent(c.Form, c.Data)
+	if err != nil {
+		return err
+	}
+	c.Meta.Consent = consent
+	return nil
+}
+
+// stageSpam checks the content filters, rejecting or flagging matches, then scores the
+// submission with the form's spam settings and the site's (using singleton detector for
+// rate limiting state) and asks Safe Browsing and the external spam service, if they're
+// configured. Forms that turned the check off aren't scored, and anonymous forms aren't
+// sent out.
+func (h *Router) stageSpam(c *SubmissionContext) error {
+	elapsed := spam.TakeElapsed(c.Data, time.Now())
+
+	// Banned content applies even to forms with the spam check turned off
+	filtered := h.spamDetector.Filter(c.R.Context(), c.Form.Filters, c.Data)
+	if rule := filtered.Rejected; rule != nil {
+		log.Printf("[FILTER] Rejected submission to form %s: filter %s matched", c.Form.PublicID, rule.ID)
+		return &StageError{http.StatusBadRequest, "Submission contains content that isn't accepted", response.CodeContentRejected}
+	}
+	if c.Form.Spam != nil && c.Form.Spam.Disabled {
+		if len(filtered.Flags) > 0 {
+			c.Meta.Spam = &domain.SpamMeta{Score: 100, IsSpam: true, Flags: filtered.Flags, Threshold: c.Form.Spam.Threshold}
+		}
+		return nil
+	}
+
+	clientIP := request.GetClientIP(c.R)
+	ip := clientIP
+	if c.Form.Anonymous {
+		// Rate limiting only needs to tell submitters apart, not know who they are
+		ip = h.ipHasher.Hash(ip)
+	}
+	// Counted per form, since each form has its own limit
+	key := c.Form.ID + "|" + ip
+	config := h.spamDetector.Tune(c.R.Context(), spamConfig(c.Form.Spam))
+	userAgent := c.R.Header.Get("User-Agent")
+	score := h.spamDetector.AnalyzeWith(config, key, userAgent, c.Data, elapsed)
+	h.spamDetector.RecordSubmissionWith(config, key) // Track for rate limiting
+	if len(filtered.Flags) > 0 {
+		score.Score = 100
+		score.IsSpam = true
+		score.Flags = append(score.Flags, filtered.Flags...)
+	}
+	if h.fingerprints != nil {
+		if fp := h.fingerprints.Match(c.R.Context(), c.Data); fp != nil {
+			score = h.spamDetector.Flag(config, score, domain.SpamFlagRepeat, fp.Hash[:12])
+		}
+	}
+	if !c.Form.Anonymous {
+		score = h.spamDetector.CheckLinks(c.R.Context(), config, c.Data, score)
+		score = h.spamDetector.Consult(c.R.Context(), config, spam.Request{
+			IP: clientIP, UserAgent: userAgent, Referer: c.R.Referer(), Data: c.Data,
+		}, score)
+	}
+
+	c.Meta.Spam = &domain.SpamMeta{
+		Score:     score.Score,
+		IsSpam:    score.IsSpam,
+		Flags:     score.Flags,
+		Threshold: score.Threshold,
+	}
+	return nil
+}
+
+// spamConfig returns the detector config for a form's spam settings, the defaults when nil
+func spamConfig(c *domain.SpamConfig) spam.Config {
+	config := spam.DefaultConfig()
+	if c == nil {
+		return config
+	}
+	config.ScoreThreshold = c.Threshold
+	config.RateLimitMax = c.RateLimitMax
+	config.RateLimitWindow = time.Duration(c.RateLimitWindow) * time.Second
+	config.Language = c.Language
+	if len(c.HoneypotFields) > 0 {
+		config.HoneypotFieldNames = c.HoneypotFields
+	}
+	return config
+}
+
+// stageEnrich collects server-side metadata (TRUSTED - auto-detected from request).
+// Anonymous forms keep only the request ID, timestamp and protocol details.
+func (h *Router) stageEnrich(c *SubmissionContext) error {
+	c.Meta.Server = request.GetServerMeta(c.R)
+	if c.Form.Anonymous {
+		c.Meta.Server = request.AnonymizeServerMeta(c.Meta.Server)
+	}
+	return nil
+}
+
+// stageLinks verifies the links of file_url fields that ask for it, recording what
+// they answered in the meta. Files too large or of a type the field doesn't allow
+// are rejected; links that can't be reached aren't.
+func (h *Router) stageLinks(c *SubmissionContext) error {
+	var errs domain.FieldErrors
+	for _, field := range c.Form.Fields {
+		if field.Type != domain.FieldTypeFileURL || field.FileURL == nil || !field.FileURL.Verify {
+			continue
+		}
+		link, _ := c.Data[field.Name].(string)
+		if link = strings.TrimSpace(link); link == "" {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(c.R.Context(), linkCheckTimeout)
+		result := h.links.Check(ctx, field.Name, link)
+		cancel()
+		c.Meta.Links = append(c.Meta.Links, result)
+		if err := field.CheckFileLink(result); err != nil {
+			errs = append(errs, *err)
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// stageFiles checks uploaded files against the form's limits, stores them and
+// references them from the submission's data and meta
+func (h *Router) stageFiles(c *SubmissionContext) error {
+	refs, err := h.submissionService.StoreUploads(c.R.Context(), c.Form, c.Files)
+	if err != nil {
+		return err
+	}
+	if len(refs) > 0 {
+		service.AttachFiles(c.Data, &c.Meta, refs)
+	}
+	return nil
+}
+
+// stagePersist stores the submission, or queues it while the database is down.
+// Files stored for a submission that couldn't be saved are removed.
+func (h *Router) stagePersist(c *SubmissionContext) error {
+	subm, err := h.submissionService.Save(c.R.Context(), c.Form, c.Data, c.Meta)
+	if err != nil {
+		h.submissionService.DeleteFiles(c.R.Context(), c.Form.ID, c.Meta.Files)
+		return err
+	}
+	c.Submission = subm
+	if c.IdempotencyKey != "" {
+		h.idempotency.Put(c.Form.ID, c.IdempotencyKey, subm.ID)
+	}
+	return nil
+}
+
+// stageNotify fires notifications (email, webhooks) for the new submission.
+// Queued submissions are notified about when they're stored.
+func (h *Router) stageNotify(c *SubmissionContext) error {
+	if !c.Submission.Queued {
+		h.submissionService.Notify(c.Form, c.Submission, c.Data)
+	}
+	return nil
+}
+
+// stageRespond redirects browser form posts or returns the created submission as JSON,
+// enveloped with 201 Created unless the form's submit response config (or the Accept
+// header) says otherwise. A queued submission is always answered with 202 Accepted.
+func (h *Router) stageRespond(c *SubmissionContext) error {
+	redirectURL := c.Form.RedirectURL
+	if q := c.R.URL.Query().Get("redirect_to"); q != "" {
+		redirectURL = q
+	}
+
+	// Only redirect if likely initiated by browser form (HTML content type)
+	if redirectURL != "" && c.IsHTMLForm() {
+		http.Redirect(c.W, c.R, redirectURL, http.StatusFound)
+		return nil
+	}
+
+	cfg := domain.SubmitResponseConfig{Status: http.StatusCreated}
+	if c.Form.SubmitResponse != nil {
+		cfg = *c.Form.SubmitResponse
+	}
+	if envelope, ok := acceptEnvelope(c.R); ok {
+		cfg.Raw = !envelope
+	}
+	status := cfg.Status
+	if c.Submission.Queued {
+		status = http.StatusAccepted
+	}
+	if cfg.Raw {
+		response.Raw(c.W, status, c.Submission)
+		return nil
+	}
+	response.Status(c.W, status, c.Submission)
+	return nil
+}
+
+// acceptEnvelope reads a per-request override of the form's response envelope from the
+// Accept header: "application/json; envelope=false" asks for the bare submission and
+// "envelope=true" for the usual {"status", "data"}. ok is false when neither is given.
+func acceptEnvelope(r *http.Request) (envelope, ok bool) {
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		_, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		switch params["envelope"] {
+		case "true":
+			return true, true
+		case "false":
+			return false, true
+		}
+	}
+	return false, false
+}
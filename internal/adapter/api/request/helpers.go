@@ -5,39 +5,12 @@ import (
 	"strings"
 	"time"
 
+	"headless_form/internal/adapter/middleware"
+	"headless_form/internal/core/domain"
+
 	"github.com/google/uuid"
 )
 
-// ServerMeta contains metadata auto-collected from HTTP request
-// This data is TRUSTED (collected server-side, cannot be spoofed by client)
-type ServerMeta struct {
-	// Core identification
-	IP        string    `json:"ip"`
-	RequestID string    `json:"request_id"`
-	Timestamp time.Time `json:"timestamp"`
-
-	// Browser/Client info
-	UserAgent  string `json:"user_agent"`
-	Language   string `json:"language,omitempty"`    // Accept-Language
-	IsMobile   string `json:"is_mobile,omitempty"`   // Sec-CH-UA-Mobile
-	Platform   string `json:"platform,omitempty"`    // Sec-CH-UA-Platform
-	ClientHint string `json:"client_hint,omitempty"` // Sec-CH-UA
-
-	// Request context
-	Referer     string `json:"referer,omitempty"`
-	Origin      string `json:"origin,omitempty"`
-	ContentType string `json:"content_type,omitempty"`
-	Protocol    string `json:"protocol,omitempty"` // http or https
-
-	// Cloudflare-specific (only present if behind Cloudflare)
-	Country     string `json:"country,omitempty"`      // CF-IPCountry
-	EstimatedTZ string `json:"estimated_tz,omitempty"` // Timezone estimated from country
-	CFRay       string `json:"cf_ray,omitempty"`       // CF-Ray (request ID)
-
-	// Privacy
-	DoNotTrack string `json:"dnt,omitempty"` // DNT header
-}
-
 // GetClientIP extracts the real client IP, handling proxies and Cloudflare
 // Priority: CF-Connecting-IP > True-Client-IP > X-Real-IP > X-Forwarded-For > RemoteAddr
 func GetClientIP(r *http.Request) string {
@@ -152,13 +125,19 @@ func getTimezoneFromCountry(country string) string {
 
 // GetServerMeta collects all server-detectable metadata from the HTTP request
 // This returns TRUSTED data that cannot be manipulated by the client
-func GetServerMeta(r *http.Request) ServerMeta {
+func GetServerMeta(r *http.Request) domain.ServerMeta {
 	country := r.Header.Get("CF-IPCountry")
 
-	return ServerMeta{
+	// Reuse the ID from the access log so submissions can be traced back to it
+	requestID := middleware.GetRequestID(r.Context())
+	if requestID == "" {
+		requestID = uuid.New().String()
+	}
+
+	return domain.ServerMeta{
 		// Core
 		IP:        GetClientIP(r),
-		RequestID: uuid.New().String(),
+		RequestID: requestID,
 		Timestamp: time.Now().UTC(),
 
 		// Browser info
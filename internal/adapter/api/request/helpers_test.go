@@ -3,7 +3,9 @@ package request
 import (
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestGetClientIP(t *testing.T) {
@@ -102,3 +104,91 @@ func TestGetServerMeta(t *testing.T) {
 		t.Errorf("Origin: got %v, want https://example.com", meta.Origin)
 	}
 }
+
+func TestIPHasher(t *testing.T) {
+	h := NewIPHasher(time.Hour)
+	now := time.Now()
+	h.now = func() time.Time { return now }
+
+	a := h.Hash("1.2.3.4")
+	if a == "1.2.3.4" || a != h.Hash("1.2.3.4") {
+		t.Fatalf("expected a stable hash within a period, got %q", a)
+	}
+	if a == h.Hash("5.6.7.8") {
+		t.Error("expected different IPs to hash differently")
+	}
+
+	now = now.Add(time.Hour)
+	if a == h.Hash("1.2.3.4") {
+		t.Error("expected the hash to change after the salt rotates")
+	}
+}
+
+func TestAnonymizeServerMeta(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/submissions/test123", nil)
+	req.Header.Set("CF-Connecting-IP", "1.2.3.4")
+	req.Header.Set("User-Agent", "Mozilla/5.0")
+	req.Header.Set("Referer", "https://example.com/contact")
+
+	meta := AnonymizeServerMeta(GetServerMeta(req))
+
+	if meta.IP != "" || meta.UserAgent != "" || meta.Referer != "" {
+		t.Errorf("expected identifying fields to be dropped, got %+v", meta)
+	}
+	if !meta.Anonymized || meta.RequestID == "" || meta.Timestamp.IsZero() {
+		t.Errorf("expected request ID and timestamp to be kept, got %+v", meta)
+	}
+}
+
+func TestPreferredLanguages(t *testing.T) {
+	tests := map[string]string{
+		"":                             "",
+		"de":                           "de",
+		"fr;q=0.5, de-AT, en;q=0.8":    "de-AT,en,fr",
+		"en;q=0, *;q=0.1, pt-BR;q=0.9": "pt-BR",
+		"es;q=bad, it":                 "it",
+	}
+	for header, want := range tests {
+		if got := strings.Join(ParseAcceptLanguage(header), ","); got != want {
+			t.Errorf("%q: expected %q, got %q", header, want, got)
+		}
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/?lang=nl", nil)
+	r.Header.Set("Accept-Language", "de, en;q=0.5")
+	if got := strings.Join(PreferredLanguages(r), ","); got != "nl,de,en" {
+		t.Errorf("expected ?lang= first, got %q", got)
+	}
+}
+
+func TestParseTimeFormat(t *testing.T) {
+	at := time.Date(2026, 3, 10, 23, 30, 5, 0, time.UTC)
+	tests := map[string]string{
+		"":                                   "2026-03-10 23:30:05",
+		"?date_format=iso8601":               "2026-03-10T23:30:05Z",
+		"?date_format=unix":                  "1773185405",
+		"?date_format=UNIX_MS":               "1773185405000",
+		"?date_format=02.01.2006":            "10.03.2026",
+		"?date_format=iso8601&tz=Asia/Tokyo": "2026-03-11T08:30:05+09:00",
+		"?tz=Asia/Tokyo":                     "2026-03-11 08:30:05",
+	}
+	for query, want := range tests {
+		f, err := ParseTimeFormat(httptest.NewRequest(http.MethodGet, "/"+query, nil), time.UTC)
+		if err != nil {
+			t.Errorf("%q: unexpected error %v", query, err)
+			continue
+		}
+		if got := f.Format(at); got != want {
+			t.Errorf("%q: expected %q, got %q", query, want, got)
+		}
+	}
+
+	for _, query := range []string{"?date_format=plain", "?tz=Mars/Olympus", "?tz=Local"} {
+		if _, err := ParseTimeFormat(httptest.NewRequest(http.MethodGet, "/"+query, nil), time.UTC); err == nil {
+			t.Errorf("%q: expected an error", query)
+		}
+	}
+	if got := (TimeFormat{Layout: DefaultDateLayout}).Format(time.Time{}); got != "" {
+		t.Errorf("expected the zero time to be empty, got %q", got)
+	}
+}
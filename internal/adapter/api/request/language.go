@@ -0,0 +1,53 @@
+package request
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ParseAcceptLanguage returns the language tags of an Accept-Language header,
+// most preferred first. Wildcards and tags with q=0 are left out.
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+	var langs []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q > 0 {
+			langs = append(langs, weighted{tag, q})
+		}
+	}
+	sort.SliceStable(langs, func(i, j int) bool { return langs[i].q > langs[j].q })
+
+	tags := make([]string, len(langs))
+	for i, l := range langs {
+		tags[i] = l.tag
+	}
+	return tags
+}
+
+// PreferredLanguages returns the languages a request asks for: the ?lang= parameter
+// if given, then its Accept-Language header
+func PreferredLanguages(r *http.Request) []string {
+	langs := ParseAcceptLanguage(r.Header.Get("Accept-Language"))
+	if lang := strings.TrimSpace(r.URL.Query().Get("lang")); lang != "" {
+		langs = append([]string{lang}, langs...)
+	}
+	return langs
+}
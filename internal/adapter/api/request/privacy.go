@@ -0,0 +1,55 @@
+package request
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"headless_form/internal/core/domain"
+)
+
+// IPHasher hashes client IPs with a random in-memory salt that rotates every period.
+// Hashes can be compared within a period (enough for rate limiting) but can't be
+// reversed or linked across periods, and nothing survives a restart.
+type IPHasher struct {
+	mu        sync.Mutex
+	period    time.Duration
+	salt      []byte
+	rotatedAt time.Time
+	now       func() time.Time
+}
+
+// NewIPHasher creates a hasher whose salt rotates every period
+func NewIPHasher(period time.Duration) *IPHasher {
+	return &IPHasher{period: period, now: time.Now}
+}
+
+// Hash returns a hex digest of ip under the current salt
+func (h *IPHasher) Hash(ip string) string {
+	h.mu.Lock()
+	if h.salt == nil || h.now().Sub(h.rotatedAt) >= h.period {
+		h.salt = make([]byte, 32)
+		_, _ = rand.Read(h.salt)
+		h.rotatedAt = h.now()
+	}
+	mac := hmac.New(sha256.New, h.salt)
+	h.mu.Unlock()
+
+	mac.Write([]byte(ip))
+	return hex.EncodeToString(mac.Sum(nil)[:16])
+}
+
+// AnonymizeServerMeta keeps only the non-identifying parts of server metadata,
+// for forms in anonymous mode
+func AnonymizeServerMeta(m domain.ServerMeta) domain.ServerMeta {
+	return domain.ServerMeta{
+		RequestID:   m.RequestID,
+		Timestamp:   m.Timestamp,
+		ContentType: m.ContentType,
+		Protocol:    m.Protocol,
+		Anonymized:  true,
+	}
+}
@@ -0,0 +1,84 @@
+package request
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"headless_form/internal/core/domain"
+)
+
+// ErrInvalidDateFormat is returned for a date_format that is neither a named format nor a Go layout
+var ErrInvalidDateFormat = errors.New("date_format must be iso8601, unix, unix_ms or a Go time layout like 02.01.2006 15:04")
+
+// DefaultDateLayout is how exports write timestamps unless asked otherwise
+const DefaultDateLayout = "2006-01-02 15:04:05"
+
+// maxDateLayout caps custom layouts, which are echoed into every row
+const maxDateLayout = 64
+
+// reference is a time whose every layout component differs from the layout's
+// own text, so a layout without any components formats to itself
+var reference = time.Date(2001, 3, 4, 5, 6, 7, 0, time.FixedZone("XYZ", 8*3600))
+
+// TimeFormat writes export timestamps in one layout and time zone
+type TimeFormat struct {
+	Layout   string // Go layout, or "unix" / "unix_ms" for epoch numbers
+	Location *time.Location
+}
+
+// ParseTimeFormat reads the ?date_format= and ?tz= query parameters of an export.
+// date_format is iso8601 (RFC 3339 with offset), unix, unix_ms or a Go layout;
+// it defaults to DefaultDateLayout. tz is an IANA zone and defaults to loc.
+func ParseTimeFormat(r *http.Request, loc *time.Location) (TimeFormat, error) {
+	f := TimeFormat{Layout: DefaultDateLayout, Location: loc}
+
+	switch v := strings.TrimSpace(r.URL.Query().Get("date_format")); strings.ToLower(v) {
+	case "", "default":
+	case "iso8601", "rfc3339":
+		f.Layout = time.RFC3339
+	case "unix", "unix_ms":
+		f.Layout = strings.ToLower(v)
+	default:
+		if len(v) > maxDateLayout || reference.Format(v) == v {
+			return TimeFormat{}, ErrInvalidDateFormat
+		}
+		f.Layout = v
+	}
+
+	if tz, err := ParseTimezone(r); err != nil {
+		return TimeFormat{}, err
+	} else if tz != nil {
+		f.Location = tz
+	}
+	return f, nil
+}
+
+// ParseTimezone reads the ?tz= query parameter, returning nil when it's absent
+func ParseTimezone(r *http.Request) (*time.Location, error) {
+	name, err := domain.NormalizeTimezone(r.URL.Query().Get("tz"))
+	if err != nil || name == "" {
+		return nil, err
+	}
+	return domain.LoadTimezone(name), nil
+}
+
+// Format writes t, or "" for the zero time
+func (f TimeFormat) Format(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	switch f.Layout {
+	case "unix":
+		return strconv.FormatInt(t.Unix(), 10)
+	case "unix_ms":
+		return strconv.FormatInt(t.UnixMilli(), 10)
+	}
+	loc := f.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	return t.In(loc).Format(f.Layout)
+}
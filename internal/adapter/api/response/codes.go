@@ -0,0 +1,160 @@
+package response
+
+import "net/http"
+
+// Error codes returned in the "code" field of error responses.
+// Every code must also be listed in Catalog so clients can map it to a message.
+const (
+	// 400 Bad Request
+	CodeValidationError      = "VALIDATION_ERROR"
+	CodeInvalidBody          = "INVALID_BODY"
+	CodeInvalidForm          = "INVALID_FORM"
+	CodeMissingFields        = "MISSING_FIELDS"
+	CodeEmailRequired        = "EMAIL_REQUIRED"
+	CodePasswordTooShort     = "PASSWORD_TOO_SHORT"
+	CodeInvalidRole          = "INVALID_ROLE"
+	CodeMissingUserID        = "MISSING_USER_ID"
+	CodeSelfDelete           = "SELF_DELETE"
+	CodeDeleteFailed         = "DELETE_FAILED"
+	CodeInvalidToken         = "INVALID_TOKEN"
+	CodeInvalidDate          = "INVALID_DATE"
+	CodeInvalidDateRange     = "INVALID_DATE_RANGE"
+	CodeInvalidDateFormat    = "INVALID_DATE_FORMAT"
+	CodeSubmissionFailed     = "SUBMISSION_FAILED"
+	CodeMissingSMTPConfig    = "MISSING_SMTP_CONFIG"
+	CodeMissingTestTo        = "MISSING_TEST_TO"
+	CodeSMTPTestFailed       = "SMTP_TEST_FAILED"
+	CodeInvalidTransform     = "INVALID_TRANSFORM"
+	CodeInvalidConfirmation  = "INVALID_CONFIRMATION"
+	CodeConsentRequired      = "CONSENT_REQUIRED"
+	CodeInvalidFields        = "INVALID_FIELDS"
+	CodeUploadsDisabled      = "UPLOADS_DISABLED"
+	CodeTooManyFiles         = "TOO_MANY_FILES"
+	CodeWebhookNotSet        = "WEBHOOK_NOT_SET"
+	CodeContentRejected      = "CONTENT_REJECTED"
+	CodeInvalidEmailTemplate = "INVALID_EMAIL_TEMPLATE"
+
+	// 401 Unauthorized
+	CodeUnauthorized       = "UNAUTHORIZED"
+	CodeAuthRequired       = "AUTH_REQUIRED"
+	CodeInvalidCredentials = "INVALID_CREDENTIALS"
+	CodeInvalidPassword    = "INVALID_PASSWORD"
+	CodeInvalidSignature   = "INVALID_SIGNATURE"
+
+	// 403 Forbidden
+	CodeForbidden             = "FORBIDDEN"
+	CodeInvalidKey            = "INVALID_KEY"
+	CodeCaptchaFailed         = "CAPTCHA_FAILED"
+	CodeOriginNotAllowed      = "ORIGIN_NOT_ALLOWED"
+	CodeSeedDisabled          = "SEED_DISABLED"
+	CodeDemoMode              = "DEMO_MODE"
+	CodePasswordLoginDisabled = "PASSWORD_LOGIN_DISABLED"
+	CodeExportNotAllowed      = "EXPORT_NOT_ALLOWED"
+	CodeFormClosed            = "FORM_CLOSED"
+
+	// 404 Not Found
+	CodeNotFound = "NOT_FOUND"
+
+	// 409 Conflict
+	CodeUserExists     = "USER_EXISTS"
+	CodeEmailExists    = "EMAIL_EXISTS"
+	CodeTaskInProgress = "TASK_IN_PROGRESS"
+	CodeTaskFinished   = "TASK_FINISHED"
+	CodeSlugInTrash    = "SLUG_IN_TRASH"
+	CodeNotReplayable  = "NOT_REPLAYABLE"
+	CodeSampleLimit    = "SAMPLE_DATA_LIMIT"
+	CodeLastTeamOwner  = "LAST_TEAM_OWNER"
+
+	// 413 Payload Too Large
+	CodeFileTooLarge = "FILE_TOO_LARGE"
+
+	// 415 Unsupported Media Type
+	CodeFileTypeNotAllowed = "FILE_TYPE_NOT_ALLOWED"
+
+	// 429 Too Many Requests
+	CodeRateLimited = "RATE_LIMITED"
+
+	// 500 Internal Server Error
+	CodeInternalError  = "INTERNAL_ERROR"
+	CodeRegisterFailed = "REGISTER_FAILED"
+	CodeTokenFailed    = "TOKEN_FAILED"
+	CodeCheckFailed    = "CHECK_FAILED"
+
+	// 503 Service Unavailable
+	CodeMaintenance        = "MAINTENANCE"
+	CodeCaptchaUnavailable = "CAPTCHA_UNAVAILABLE"
+
+	// 504 Gateway Timeout
+	CodeTimeout = "TIMEOUT"
+)
+
+// ErrorCode describes an error code for the public catalog
+type ErrorCode struct {
+	Code        string `json:"code"`
+	Status      int    `json:"status"`
+	Description string `json:"description"`
+}
+
+// Catalog lists every error code the API can return, with its HTTP status
+var Catalog = []ErrorCode{
+	{CodeValidationError, http.StatusBadRequest, "One or more fields failed validation"},
+	{CodeInvalidBody, http.StatusBadRequest, "Request body is not valid JSON"},
+	{CodeInvalidForm, http.StatusBadRequest, "Form-encoded body could not be parsed"},
+	{CodeMissingFields, http.StatusBadRequest, "Required fields are missing"},
+	{CodeEmailRequired, http.StatusBadRequest, "Email address is required"},
+	{CodePasswordTooShort, http.StatusBadRequest, "Password must be at least 8 characters"},
+	{CodeInvalidRole, http.StatusBadRequest, "Role must be super_admin, admin or user"},
+	{CodeMissingUserID, http.StatusBadRequest, "User ID is missing from the path"},
+	{CodeSelfDelete, http.StatusBadRequest, "Users cannot delete their own account"},
+	{CodeDeleteFailed, http.StatusBadRequest, "The resource could not be deleted"},
+	{CodeInvalidToken, http.StatusBadRequest, "Reset token is invalid or expired"},
+	{CodeInvalidDate, http.StatusBadRequest, "Date must be in YYYY-MM-DD format"},
+	{CodeInvalidDateRange, http.StatusBadRequest, "Date range is reversed or too long"},
+	{CodeInvalidDateFormat, http.StatusBadRequest, "Export date_format is not iso8601, unix, unix_ms or a Go time layout"},
+	{CodeSubmissionFailed, http.StatusBadRequest, "Submission was rejected (e.g. form inactive)"},
+	{CodeMissingSMTPConfig, http.StatusBadRequest, "SMTP host and port are required"},
+	{CodeMissingTestTo, http.StatusBadRequest, "Test email recipient is required"},
+	{CodeSMTPTestFailed, http.StatusBadRequest, "Sending the SMTP test email failed"},
+	{CodeInvalidTransform, http.StatusBadRequest, "Webhook transform script is invalid or failed to run"},
+	{CodeInvalidConfirmation, http.StatusBadRequest, "Delete confirmation token is invalid or expired"},
+	{CodeConsentRequired, http.StatusBadRequest, "A required consent checkbox was not checked"},
+	{CodeInvalidFields, http.StatusBadRequest, "Submitted values break the form's field rules; data.fields lists each one"},
+	{CodeUploadsDisabled, http.StatusBadRequest, "The form doesn't accept file uploads"},
+	{CodeTooManyFiles, http.StatusBadRequest, "More files than the form's max_files"},
+	{CodeWebhookNotSet, http.StatusBadRequest, "The form has no webhook URL to send to"},
+	{CodeInvalidEmailTemplate, http.StatusBadRequest, "Email template doesn't parse or uses template calls or unbounded loops"},
+	{CodeContentRejected, http.StatusBadRequest, "The submission contains content the form doesn't accept"},
+	{CodeUnauthorized, http.StatusUnauthorized, "Missing, invalid or expired authentication"},
+	{CodeAuthRequired, http.StatusUnauthorized, "This form only accepts authenticated submissions"},
+	{CodeInvalidSignature, http.StatusUnauthorized, "Submission signature does not match the body or its timestamp is too old"},
+	{CodeInvalidCredentials, http.StatusUnauthorized, "Email or password is incorrect"},
+	{CodeInvalidPassword, http.StatusUnauthorized, "Current password is incorrect"},
+	{CodeForbidden, http.StatusForbidden, "Authenticated user lacks permission for this resource"},
+	{CodeInvalidKey, http.StatusForbidden, "Submission key is missing or wrong"},
+	{CodeCaptchaFailed, http.StatusForbidden, "The captcha token is missing or the provider rejected it"},
+	{CodeOriginNotAllowed, http.StatusForbidden, "Request origin is not in the form's allowed origins"},
+	{CodeSeedDisabled, http.StatusForbidden, "Seeding is disabled in production"},
+	{CodeDemoMode, http.StatusForbidden, "Action is disabled on the public demo"},
+	{CodePasswordLoginDisabled, http.StatusForbidden, "Password login is disabled; sign in with SSO"},
+	{CodeExportNotAllowed, http.StatusForbidden, "The user's role may not read or export the form's submissions (see /settings/exports)"},
+	{CodeFormClosed, http.StatusForbidden, "The form isn't open yet or has closed (see its opens_at and closes_at)"},
+	{CodeNotFound, http.StatusNotFound, "Requested resource does not exist"},
+	{CodeUserExists, http.StatusConflict, "A user with this email already exists"},
+	{CodeEmailExists, http.StatusConflict, "Email is already used by another account"},
+	{CodeTaskInProgress, http.StatusConflict, "Another task of this type is still running"},
+	{CodeTaskFinished, http.StatusConflict, "The task has already finished"},
+	{CodeSlugInTrash, http.StatusConflict, "A deleted form still uses this slug"},
+	{CodeNotReplayable, http.StatusConflict, "The webhook delivery has no payload to replay (its transform failed)"},
+	{CodeSampleLimit, http.StatusConflict, "The user already has too many sample forms; purge the sample data first"},
+	{CodeLastTeamOwner, http.StatusConflict, "The change would leave the team without an owner"},
+	{CodeFileTooLarge, http.StatusRequestEntityTooLarge, "A file is over the form's max_file_size"},
+	{CodeFileTypeNotAllowed, http.StatusUnsupportedMediaType, "A file's type is not in the form's allowed_types"},
+	{CodeRateLimited, http.StatusTooManyRequests, "Too many requests, retry later"},
+	{CodeInternalError, http.StatusInternalServerError, "Unexpected server error"},
+	{CodeRegisterFailed, http.StatusInternalServerError, "Registration failed"},
+	{CodeTokenFailed, http.StatusInternalServerError, "Token could not be generated"},
+	{CodeCheckFailed, http.StatusInternalServerError, "Status check failed"},
+	{CodeMaintenance, http.StatusServiceUnavailable, "The instance is in read-only maintenance mode; retry after Retry-After seconds"},
+	{CodeCaptchaUnavailable, http.StatusServiceUnavailable, "The captcha provider couldn't be reached to verify the token; retry later"},
+	{CodeTimeout, http.StatusGatewayTimeout, "The operation exceeded its time limit"},
+}
@@ -0,0 +1,45 @@
+package response
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+// TestCatalog_Unique ensures every catalog entry has a unique code and a valid status
+func TestCatalog_Unique(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, c := range Catalog {
+		if seen[c.Code] {
+			t.Errorf("duplicate code %q in catalog", c.Code)
+		}
+		seen[c.Code] = true
+		if c.Status < 400 || c.Status > 599 {
+			t.Errorf("code %q has non-error status %d", c.Code, c.Status)
+		}
+		if c.Description == "" {
+			t.Errorf("code %q has no description", c.Code)
+		}
+	}
+}
+
+// TestCatalog_CoversConstants ensures every Code* constant declared in codes.go is in the catalog
+func TestCatalog_CoversConstants(t *testing.T) {
+	src, err := os.ReadFile(filepath.Join(".", "codes.go"))
+	if err != nil {
+		t.Fatalf("read codes.go: %v", err)
+	}
+
+	inCatalog := make(map[string]bool)
+	for _, c := range Catalog {
+		inCatalog[c.Code] = true
+	}
+
+	decl := regexp.MustCompile(`(?m)^\s+Code\w+\s+= "([A-Z_]+)"`)
+	for _, m := range decl.FindAllStringSubmatch(string(src), -1) {
+		if !inCatalog[m[1]] {
+			t.Errorf("code %q is declared but missing from Catalog", m[1])
+		}
+	}
+}
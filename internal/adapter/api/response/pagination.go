@@ -0,0 +1,67 @@
+package response
+
+import (
+	"maps"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Pagination is the page metadata every list endpoint returns under "pagination"
+type Pagination struct {
+	Page       int    `json:"page"`
+	Limit      int    `json:"limit"`
+	Total      int    `json:"total"` // Items on all pages
+	TotalPages int    `json:"total_pages"`
+	Next       string `json:"next,omitempty"` // Path and query of the next page, when there is one
+	Prev       string `json:"prev,omitempty"` // Path and query of the previous page, when there is one
+}
+
+// NewPagination describes page (1-based) of a list of total items, limit per page.
+// The next and previous links keep the request's other query parameters.
+func NewPagination(r *http.Request, page, limit, total int) Pagination {
+	p := Pagination{Page: page, Limit: limit, Total: total}
+	if limit > 0 {
+		p.TotalPages = (total + limit - 1) / limit
+	}
+	if page < p.TotalPages {
+		p.Next = pageLink(r, page+1, limit)
+	}
+	if page > 1 {
+		// Past the end, prev leads back to the last page
+		p.Prev = pageLink(r, min(page-1, max(p.TotalPages, 1)), limit)
+	}
+	return p
+}
+
+// pageLink returns the request's path and query with page and limit replaced
+func pageLink(r *http.Request, page, limit int) string {
+	q := r.URL.Query()
+	q.Set("page", strconv.Itoa(page))
+	q.Set("limit", strconv.Itoa(limit))
+	return (&url.URL{Path: r.URL.Path, RawQuery: q.Encode()}).String()
+}
+
+// Paginated sends a 200 OK with one page of a list under key, its pagination and any
+// extra fields (such as counts). The next and previous pages are also sent as a Link header.
+func Paginated(w http.ResponseWriter, r *http.Request, key string, items interface{}, page, limit, total int, extra map[string]interface{}) {
+	p := NewPagination(r, page, limit, total)
+
+	var links []string
+	if p.Next != "" {
+		links = append(links, "<"+p.Next+`>; rel="next"`)
+	}
+	if p.Prev != "" {
+		links = append(links, "<"+p.Prev+`>; rel="prev"`)
+	}
+	if len(links) > 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
+
+	data := make(map[string]interface{}, len(extra)+2)
+	maps.Copy(data, extra)
+	data[key] = items
+	data["pagination"] = p
+	Success(w, data)
+}
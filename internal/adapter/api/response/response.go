@@ -1,6 +1,7 @@
 package response
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"headless_form/internal/core/domain"
@@ -43,6 +44,43 @@ func Created(w http.ResponseWriter, data interface{}) {
 	})
 }
 
+// Accepted sends a 202 Accepted with the given data
+func Accepted(w http.ResponseWriter, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	writeJSON(w, Envelope{
+		Status: "success",
+		Data:   data,
+	})
+}
+
+// Raw sends data as the whole body, without the envelope, for clients that expect the
+// bare resource. A 204 No Content status sends no body.
+func Raw(w http.ResponseWriter, statusCode int, data interface{}) {
+	if statusCode == http.StatusNoContent {
+		w.WriteHeader(statusCode)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	writeJSON(w, data)
+}
+
+// Status sends data in the envelope with the given success status code.
+// A 204 No Content status sends no body.
+func Status(w http.ResponseWriter, statusCode int, data interface{}) {
+	if statusCode == http.StatusNoContent {
+		w.WriteHeader(statusCode)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	writeJSON(w, Envelope{
+		Status: "success",
+		Data:   data,
+	})
+}
+
 // Error sends a JSON error response with the specific status code
 func Error(w http.ResponseWriter, statusCode int, message string, code string) {
 	w.Header().Set("Content-Type", "application/json")
@@ -54,6 +92,19 @@ func Error(w http.ResponseWriter, statusCode int, message string, code string) {
 	})
 }
 
+// Fail sends a "fail" response: the request was understood but its data was rejected,
+// with details (e.g. per-field errors) in data
+func Fail(w http.ResponseWriter, statusCode int, message string, code string, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	writeJSON(w, Envelope{
+		Status:  "fail",
+		Data:    data,
+		Message: message,
+		Code:    code,
+	})
+}
+
 // BadRequest sends a 400 Bad Request
 func BadRequest(w http.ResponseWriter, message string, code string) {
 	Error(w, http.StatusBadRequest, message, code)
@@ -61,15 +112,20 @@ func BadRequest(w http.ResponseWriter, message string, code string) {
 
 // NotFound sends a 404 Not Found
 func NotFound(w http.ResponseWriter, message string) {
-	Error(w, http.StatusNotFound, message, "NOT_FOUND")
+	Error(w, http.StatusNotFound, message, CodeNotFound)
 }
 
 // HandleError checks if there is an error and handles it (Helper for "if err != nil")
 func HandleError(w http.ResponseWriter, err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		log.Printf("[ERROR] Operation timed out: %v", err)
+		Error(w, http.StatusGatewayTimeout, "The operation took too long, please retry", CodeTimeout)
+		return true
+	}
 	if err != nil {
 		// Log the actual error for debugging
 		log.Printf("[ERROR] Internal error: %v", err)
-		Error(w, http.StatusInternalServerError, "Internal Server Error", "INTERNAL_ERROR")
+		Error(w, http.StatusInternalServerError, "Internal Server Error", CodeInternalError)
 		return true
 	}
 	return false
@@ -88,7 +144,43 @@ func HandleDomainError(w http.ResponseWriter, err error) bool {
 		return true
 	}
 	if errors.Is(err, domain.ErrFormNameRequired) || errors.Is(err, domain.ErrFormNameTooLong) {
-		BadRequest(w, err.Error(), "VALIDATION_ERROR")
+		BadRequest(w, err.Error(), CodeValidationError)
+		return true
+	}
+	if errors.Is(err, domain.ErrConsentFieldRequired) || errors.Is(err, domain.ErrConsentVersionTooLong) {
+		BadRequest(w, err.Error(), CodeValidationError)
+		return true
+	}
+	if errors.Is(err, domain.ErrTransformRequired) || errors.Is(err, domain.ErrInvalidSignatureAlgorithm) || errors.Is(err, domain.ErrInvalidFrameAncestor) {
+		BadRequest(w, err.Error(), CodeValidationError)
+		return true
+	}
+	if errors.Is(err, domain.ErrFieldNameRequired) || errors.Is(err, domain.ErrDuplicateField) || errors.Is(err, domain.ErrTooManyFields) ||
+		errors.Is(err, domain.ErrFieldTextTooLong) || errors.Is(err, domain.ErrInvalidLocale) ||
+		errors.Is(err, domain.ErrInvalidFieldType) || errors.Is(err, domain.ErrInvalidFieldRule) || errors.Is(err, domain.ErrInvalidPattern) ||
+		errors.Is(err, domain.ErrInvalidFileURLRules) ||
+		errors.Is(err, domain.ErrInvalidEmailStatus) || errors.Is(err, domain.ErrInvalidErrorPage) {
+		BadRequest(w, err.Error(), CodeValidationError)
+		return true
+	}
+	if errors.Is(err, domain.ErrInvalidSlug) || errors.Is(err, domain.ErrInvalidFormStatus) || errors.Is(err, domain.ErrInvalidAccessMode) {
+		BadRequest(w, err.Error(), CodeValidationError)
+		return true
+	}
+	if errors.Is(err, domain.ErrSlugInTrash) {
+		Error(w, http.StatusConflict, err.Error(), CodeSlugInTrash)
+		return true
+	}
+	if errors.Is(err, domain.ErrInvalidDeleteToken) {
+		BadRequest(w, err.Error(), CodeInvalidConfirmation)
+		return true
+	}
+	if errors.Is(err, domain.ErrInvalidTimezone) || errors.Is(err, domain.ErrInvalidPublicIDGrace) || errors.Is(err, domain.ErrInvalidSubmitStatus) {
+		BadRequest(w, err.Error(), CodeValidationError)
+		return true
+	}
+	if errors.Is(err, domain.ErrInvalidDateRange) {
+		BadRequest(w, "Invalid date range", CodeInvalidDateRange)
 		return true
 	}
 
@@ -97,14 +189,151 @@ func HandleDomainError(w http.ResponseWriter, err error) bool {
 		NotFound(w, "Submission not found")
 		return true
 	}
+	if errors.Is(err, domain.ErrInvalidListQuery) {
+		BadRequest(w, err.Error(), CodeValidationError)
+		return true
+	}
+	if errors.Is(err, domain.ErrFilterRequired) {
+		BadRequest(w, err.Error(), CodeValidationError)
+		return true
+	}
+	if errors.Is(err, domain.ErrInvalidFieldMigration) || errors.Is(err, domain.ErrInvalidFieldTransform) {
+		BadRequest(w, err.Error(), CodeValidationError)
+		return true
+	}
+	var fieldErrs domain.FieldErrors
+	if errors.As(err, &fieldErrs) {
+		Fail(w, http.StatusBadRequest, "Some fields are invalid", CodeInvalidFields, map[string]interface{}{"fields": fieldErrs})
+		return true
+	}
+	if errors.Is(err, domain.ErrInvalidBulkAction) || errors.Is(err, domain.ErrInvalidBulkIDs) {
+		BadRequest(w, err.Error(), CodeValidationError)
+		return true
+	}
+	if errors.Is(err, domain.ErrConsentRequired) {
+		BadRequest(w, err.Error(), CodeConsentRequired)
+		return true
+	}
+
+	// Upload errors
+	if errors.Is(err, domain.ErrUploadsDisabled) {
+		BadRequest(w, err.Error(), CodeUploadsDisabled)
+		return true
+	}
+	if errors.Is(err, domain.ErrTooManyFiles) {
+		BadRequest(w, err.Error(), CodeTooManyFiles)
+		return true
+	}
+	if errors.Is(err, domain.ErrFileTooLarge) {
+		Error(w, http.StatusRequestEntityTooLarge, err.Error(), CodeFileTooLarge)
+		return true
+	}
+	if errors.Is(err, domain.ErrFileTypeNotAllowed) {
+		Error(w, http.StatusUnsupportedMediaType, err.Error(), CodeFileTypeNotAllowed)
+		return true
+	}
+	if errors.Is(err, domain.ErrInvalidUploadLimits) || errors.Is(err, domain.ErrInvalidFileType) || errors.Is(err, domain.ErrInvalidSpamConfig) ||
+		errors.Is(err, domain.ErrInvalidCaptchaConfig) || errors.Is(err, domain.ErrInvalidNormalizeConfig) ||
+		errors.Is(err, domain.ErrInvalidNotifyRule) || errors.Is(err, domain.ErrInvalidEmailTemplate) || errors.Is(err, domain.ErrInvalidAutoReply) ||
+		errors.Is(err, domain.ErrInvalidFilter) || errors.Is(err, domain.ErrInvalidChannel) ||
+		errors.Is(err, domain.ErrInvalidRescore) || errors.Is(err, domain.ErrSpamCheckDisabled) || errors.Is(err, domain.ErrInvalidUserExport) ||
+		errors.Is(err, domain.ErrInvalidRetention) || errors.Is(err, domain.ErrInvalidEmailFieldLimit) || errors.Is(err, domain.ErrInvalidLinkField) ||
+		errors.Is(err, domain.ErrInvalidHiddenFields) || errors.Is(err, domain.ErrInvalidWebhookRate) || errors.Is(err, domain.ErrInvalidSchedule) {
+		BadRequest(w, err.Error(), CodeValidationError)
+		return true
+	}
+	if errors.Is(err, domain.ErrFileNotFound) {
+		NotFound(w, "File not found")
+		return true
+	}
+
+	// Submission link errors
+	if errors.Is(err, domain.ErrSubmissionLinkNotFound) {
+		NotFound(w, "Link not found")
+		return true
+	}
+	if errors.Is(err, domain.ErrSubmissionLinkSelf) || errors.Is(err, domain.ErrSubmissionsNotLinkable) {
+		BadRequest(w, err.Error(), CodeValidationError)
+		return true
+	}
+
+	// Alert errors
+	if errors.Is(err, domain.ErrAlertNotFound) {
+		NotFound(w, "Alert not found")
+		return true
+	}
+	if errors.Is(err, domain.ErrFilterNotFound) {
+		NotFound(w, "Filter not found")
+		return true
+	}
+	if errors.Is(err, domain.ErrSpamFingerprintNotFound) {
+		NotFound(w, "Spam fingerprint not found")
+		return true
+	}
+	if errors.Is(err, domain.ErrInvalidAlertType) || errors.Is(err, domain.ErrInvalidThreshold) {
+		BadRequest(w, err.Error(), CodeValidationError)
+		return true
+	}
+
+	// Team errors
+	if errors.Is(err, domain.ErrTeamNotFound) {
+		NotFound(w, "Team not found")
+		return true
+	}
+	if errors.Is(err, domain.ErrNotTeamMember) {
+		NotFound(w, "Team member not found")
+		return true
+	}
+	if errors.Is(err, domain.ErrTeamNameRequired) || errors.Is(err, domain.ErrInvalidTeamRole) {
+		BadRequest(w, err.Error(), CodeValidationError)
+		return true
+	}
+	if errors.Is(err, domain.ErrLastTeamOwner) {
+		Error(w, http.StatusConflict, err.Error(), CodeLastTeamOwner)
+		return true
+	}
+
+	// Form token errors
+	if errors.Is(err, domain.ErrFormTokenNotFound) {
+		NotFound(w, "Token not found")
+		return true
+	}
+	if errors.Is(err, domain.ErrTokenNameRequired) {
+		BadRequest(w, err.Error(), CodeValidationError)
+		return true
+	}
 
 	// Access control errors
+	if errors.Is(err, domain.ErrExportNotAllowed) {
+		Error(w, http.StatusForbidden, err.Error(), CodeExportNotAllowed)
+		return true
+	}
+	if errors.Is(err, domain.ErrInvalidExportSettings) {
+		BadRequest(w, err.Error(), CodeValidationError)
+		return true
+	}
 	if errors.Is(err, domain.ErrInvalidSubmissionKey) {
-		Error(w, http.StatusForbidden, "Invalid or missing submission key", "INVALID_KEY")
+		Error(w, http.StatusForbidden, "Invalid or missing submission key", CodeInvalidKey)
+		return true
+	}
+	if errors.Is(err, domain.ErrCaptchaFailed) {
+		Error(w, http.StatusForbidden, err.Error(), CodeCaptchaFailed)
+		return true
+	}
+	if errors.Is(err, domain.ErrFormNotOpen) || errors.Is(err, domain.ErrFormClosed) {
+		Error(w, http.StatusForbidden, err.Error(), CodeFormClosed)
+		return true
+	}
+	if errors.Is(err, domain.ErrOriginNotAllowed) {
+		Error(w, http.StatusForbidden, err.Error(), CodeOriginNotAllowed)
 		return true
 	}
 	if errors.Is(err, domain.ErrAuthRequired) {
-		Error(w, http.StatusUnauthorized, "Authentication required for this form", "AUTH_REQUIRED")
+		Error(w, http.StatusUnauthorized, "Authentication required for this form", CodeAuthRequired)
+		return true
+	}
+	if errors.Is(err, domain.ErrInvalidSignature) {
+		Error(w, http.StatusUnauthorized, "Invalid or expired submission signature", CodeInvalidSignature)
 		return true
 	}
 
@@ -114,23 +343,83 @@ func HandleDomainError(w http.ResponseWriter, err error) bool {
 		return true
 	}
 	if errors.Is(err, domain.ErrUserExists) {
-		Error(w, http.StatusConflict, "User already exists", "USER_EXISTS")
+		Error(w, http.StatusConflict, "User already exists", CodeUserExists)
 		return true
 	}
 	if errors.Is(err, domain.ErrInvalidCredentials) {
-		Error(w, http.StatusUnauthorized, "Invalid credentials", "INVALID_CREDENTIALS")
+		Error(w, http.StatusUnauthorized, "Invalid credentials", CodeInvalidCredentials)
 		return true
 	}
 	if errors.Is(err, domain.ErrPasswordTooShort) {
-		BadRequest(w, "Password must be at least 8 characters", "PASSWORD_TOO_SHORT")
+		BadRequest(w, "Password must be at least 8 characters", CodePasswordTooShort)
 		return true
 	}
 	if errors.Is(err, domain.ErrEmailRequired) {
-		BadRequest(w, "Email is required", "EMAIL_REQUIRED")
+		BadRequest(w, "Email is required", CodeEmailRequired)
 		return true
 	}
 	if errors.Is(err, domain.ErrInvalidResetToken) {
-		BadRequest(w, "Invalid or expired reset token", "INVALID_TOKEN")
+		BadRequest(w, "Invalid or expired reset token", CodeInvalidToken)
+		return true
+	}
+
+	// Comment errors
+	if errors.Is(err, domain.ErrCommentNotFound) {
+		NotFound(w, "Comment not found")
+		return true
+	}
+	if errors.Is(err, domain.ErrCommentBodyRequired) || errors.Is(err, domain.ErrCommentTooLong) {
+		BadRequest(w, err.Error(), CodeValidationError)
+		return true
+	}
+	if errors.Is(err, domain.ErrNotCommentAuthor) {
+		Error(w, http.StatusForbidden, err.Error(), CodeForbidden)
+		return true
+	}
+
+	// Notification errors
+	if errors.Is(err, domain.ErrNotificationNotFound) {
+		NotFound(w, "Notification not found")
+		return true
+	}
+
+	// Webhook delivery errors
+	if errors.Is(err, domain.ErrWebhookDeliveryNotFound) {
+		NotFound(w, "Webhook delivery not found")
+		return true
+	}
+	if errors.Is(err, domain.ErrWebhookNotConfigured) {
+		BadRequest(w, err.Error(), CodeWebhookNotSet)
+		return true
+	}
+	if errors.Is(err, domain.ErrDeliveryNotReplayable) {
+		Error(w, http.StatusConflict, err.Error(), CodeNotReplayable)
+		return true
+	}
+
+	// Task errors
+	if errors.Is(err, domain.ErrTaskNotFound) {
+		NotFound(w, "Task not found")
+		return true
+	}
+	if errors.Is(err, domain.ErrTaskInProgress) {
+		Error(w, http.StatusConflict, "A task of this type is already running", CodeTaskInProgress)
+		return true
+	}
+	if errors.Is(err, domain.ErrTaskNoFile) {
+		NotFound(w, err.Error())
+		return true
+	}
+	if errors.Is(err, domain.ErrTaskFinished) {
+		Error(w, http.StatusConflict, "Task has already finished", CodeTaskFinished)
+		return true
+	}
+	if errors.Is(err, domain.ErrSeedLimit) {
+		BadRequest(w, err.Error(), CodeValidationError)
+		return true
+	}
+	if errors.Is(err, domain.ErrSampleDataLimit) {
+		Error(w, http.StatusConflict, err.Error(), CodeSampleLimit)
 		return true
 	}
 
@@ -0,0 +1,53 @@
+// Package archive writes final exports of forms and their submissions to disk
+// before the form is deleted, so data can be recovered after it's purged.
+package archive
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"headless_form/internal/core/domain"
+)
+
+// Store writes archives into a directory
+type Store struct {
+	dir string
+}
+
+// New creates an archive store rooted at dir
+func New(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// Archive is the content of an archive file
+type Archive struct {
+	ArchivedAt  time.Time            `json:"archived_at"`
+	Form        *domain.Form         `json:"form"`
+	Submissions []*domain.Submission `json:"submissions"`
+}
+
+// Write stores the form and its submissions as JSON and returns the file path
+func (s *Store) Write(form *domain.Form, submissions []*domain.Submission) (string, error) {
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return "", fmt.Errorf("create archive dir: %w", err)
+	}
+	if submissions == nil {
+		submissions = []*domain.Submission{}
+	}
+
+	now := time.Now().UTC()
+	data, err := json.MarshalIndent(Archive{ArchivedAt: now, Form: form, Submissions: submissions}, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("encode archive: %w", err)
+	}
+
+	name := fmt.Sprintf("%s_%s.json", filepath.Base(form.PublicID), now.Format("20060102T150405Z"))
+	path := filepath.Join(s.dir, name)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return "", fmt.Errorf("write archive: %w", err)
+	}
+	return path, nil
+}
@@ -0,0 +1,35 @@
+package archive
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"headless_form/internal/core/domain"
+)
+
+func TestStore_Write(t *testing.T) {
+	store := New(t.TempDir())
+	form := &domain.Form{ID: "f1", PublicID: "pub1", Name: "Contact"}
+	subs := []*domain.Submission{
+		{ID: "s1", FormID: "f1", Data: json.RawMessage(`{"email":"a@example.com"}`), CreatedAt: time.Now()},
+	}
+
+	path, err := store.Write(form, subs)
+	if err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	var archived Archive
+	if err := json.Unmarshal(raw, &archived); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if archived.Form.PublicID != "pub1" || len(archived.Submissions) != 1 || archived.Submissions[0].ID != "s1" {
+		t.Errorf("unexpected archive: %+v", archived)
+	}
+}
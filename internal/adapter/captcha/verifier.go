@@ -0,0 +1,87 @@
+// Package captcha verifies reCAPTCHA, hCaptcha and Turnstile tokens with the provider
+package captcha
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"headless_form/internal/core/domain"
+)
+
+// Endpoints are the providers' siteverify APIs. They share one protocol: a form post of
+// secret, response and remoteip, answered with {"success": bool, "error-codes": [...]}.
+var Endpoints = map[domain.CaptchaProvider]string{
+	domain.CaptchaRecaptcha: "https://www.google.com/recaptcha/api/siteverify",
+	domain.CaptchaHCaptcha:  "https://api.hcaptcha.com/siteverify",
+	domain.CaptchaTurnstile: "https://challenges.cloudflare.com/turnstile/v0/siteverify",
+}
+
+// Verifier checks captcha tokens server-side
+type Verifier struct {
+	Client    *http.Client
+	Endpoints map[domain.CaptchaProvider]string
+}
+
+// NewVerifier creates a verifier for the providers' public APIs
+func NewVerifier() *Verifier {
+	return &Verifier{
+		Client:    &http.Client{Timeout: 10 * time.Second},
+		Endpoints: Endpoints,
+	}
+}
+
+// verifyResponse is a siteverify answer
+type verifyResponse struct {
+	Success    bool     `json:"success"`
+	ErrorCodes []string `json:"error-codes"`
+}
+
+// Verify checks a token with the form's provider. remoteIP is optional. It returns
+// domain.ErrCaptchaFailed for missing or rejected tokens, and another error when the
+// provider couldn't be asked.
+func (v *Verifier) Verify(ctx context.Context, cfg *domain.CaptchaConfig, token, remoteIP string) error {
+	if token == "" {
+		return fmt.Errorf("%w: missing %s", domain.ErrCaptchaFailed, cfg.TokenField())
+	}
+	endpoint, ok := v.Endpoints[cfg.Provider]
+	if !ok {
+		return fmt.Errorf("captcha: unknown provider %q", cfg.Provider)
+	}
+
+	form := url.Values{"secret": {cfg.Secret}, "response": {token}}
+	if remoteIP != "" {
+		form.Set("remoteip", remoteIP)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := v.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("captcha: %s: %w", cfg.Provider, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("captcha: %s answered %d", cfg.Provider, resp.StatusCode)
+	}
+
+	var result verifyResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&result); err != nil {
+		return fmt.Errorf("captcha: %s: decode response: %w", cfg.Provider, err)
+	}
+	if !result.Success {
+		if len(result.ErrorCodes) > 0 {
+			return fmt.Errorf("%w: %s", domain.ErrCaptchaFailed, strings.Join(result.ErrorCodes, ", "))
+		}
+		return domain.ErrCaptchaFailed
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+// Package chat posts new submissions to Slack and Discord channels through their
+// incoming webhooks.
+package chat
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"headless_form/internal/adapter/logger"
+	"headless_form/internal/core/domain"
+)
+
+var log = logger.Module("chat")
+
+// maxValueRunes caps each field's value in a message; longer ones are cut with "…"
+const maxValueRunes = 300
+
+// Field is one submitted field as a message shows it
+type Field struct {
+	Label string
+	Value string
+}
+
+// Message is a new submission, ready to post to a channel
+type Message struct {
+	FormName     string
+	SubmissionID string
+	SubmittedAt  time.Time
+	Fields       []Field // The first few fields
+	More         int     // Fields left out
+	DashboardURL string
+	Test         bool // Synthetic submission from the dashboard's test notification
+}
+
+// Title is the message's heading
+func (m Message) Title() string {
+	title := "New submission: " + m.FormName
+	if m.Test {
+		title = "[Test] " + title
+	}
+	return title
+}
+
+// Channel formats messages for one chat service
+type Channel interface {
+	Name() string
+	// Payload returns the JSON body the service's webhook takes for msg
+	Payload(msg Message) ([]byte, error)
+}
+
+// channelFor returns the formatter of a channel's service, nil for unknown ones
+func channelFor(t domain.ChannelType) Channel {
+	switch t {
+	case domain.ChannelSlack:
+		return Slack{}
+	case domain.ChannelDiscord:
+		return Discord{}
+	}
+	return nil
+}
+
+// NewMessage builds the message for a submission, showing up to limit fields: the
+// form's fields in their order first, then the others by name. Fields starting with _
+// and empty values are left out; labels are the form's where it has them.
+func NewMessage(form *domain.Form, submission *domain.Submission, data map[string]interface{}, labels map[string]string, dashboardURL string, limit int) Message {
+	msg := Message{
+		FormName:     form.Name,
+		SubmissionID: submission.ID,
+		SubmittedAt:  submission.CreatedAt,
+		DashboardURL: dashboardURL,
+		Test:         submission.IsTest,
+	}
+
+	var names []string
+	seen := make(map[string]bool)
+	for _, field := range form.Fields {
+		if _, ok := data[field.Name]; ok && !seen[field.Name] {
+			names = append(names, field.Name)
+			seen[field.Name] = true
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(data)) {
+		if !seen[name] {
+			names = append(names, name)
+		}
+	}
+
+	for _, name := range names {
+		value := formatValue(data[name])
+		if value == "" || strings.HasPrefix(name, "_") {
+			continue
+		}
+		if len(msg.Fields) == limit {
+			msg.More++
+			continue
+		}
+		label := labels[name]
+		if label == "" {
+			label = name
+		}
+		msg.Fields = append(msg.Fields, Field{Label: label, Value: value})
+	}
+	return msg
+}
+
+// formatValue renders a submitted value as text: lists joined with commas, objects as JSON
+func formatValue(v interface{}) string {
+	var s string
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		s = t
+	case []interface{}:
+		parts := make([]string, 0, len(t))
+		for _, item := range t {
+			if p := formatValue(item); p != "" {
+				parts = append(parts, p)
+			}
+		}
+		s = strings.Join(parts, ", ")
+	case map[string]interface{}:
+		b, _ := json.Marshal(t)
+		s = string(b)
+	default:
+		s = fmt.Sprint(t)
+	}
+	return truncate(strings.TrimSpace(s), maxValueRunes)
+}
+
+// Service posts new submissions to the chat channels of their forms
+type Service struct {
+	client *http.Client
+}
+
+// NewService creates a chat service
+func NewService() *Service {
+	return &Service{client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// NotifySubmission posts a submission to each of the form's channels. Failures are
+// logged; a channel that fails doesn't stop the others.
+func (s *Service) NotifySubmission(ctx context.Context, form *domain.Form, submission *domain.Submission, data map[string]interface{}, labels map[string]string, dashboardURL string) {
+	for _, c := range form.Channels {
+		channel := channelFor(c.Type)
+		if channel == nil {
+			continue
+		}
+		limit := c.Fields
+		if limit == 0 {
+			limit = domain.DefaultChannelFields
+		}
+		msg := NewMessage(form, submission, data, labels, dashboardURL, limit)
+		if err := s.post(ctx, channel, c.URL, msg); err != nil {
+			log.Error("post failed", "form_id", form.PublicID, "channel", channel.Name(), "error", err)
+		}
+	}
+}
+
+// post sends msg to a channel's webhook URL
+func (s *Service) post(ctx context.Context, channel Channel, url string, msg Message) error {
+	body, err := channel.Payload(msg)
+	if err != nil {
+		return fmt.Errorf("build payload: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "HeadlessForms-Chat/1.0")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, bytes.TrimSpace(snippet))
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"headless_form/internal/core/domain"
+)
+
+func testSubmission() (*domain.Form, *domain.Submission, map[string]interface{}) {
+	form := &domain.Form{
+		PublicID: "frm_1",
+		Name:     "Contact",
+		Fields:   []domain.FormField{{Name: "name"}, {Name: "email"}, {Name: "message"}},
+	}
+	sub := &domain.Submission{ID: "sub_1", CreatedAt: time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)}
+	data := map[string]interface{}{
+		"message": "Hello <there> & welcome",
+		"email":   "ada@example.com",
+		"name":    "Ada",
+		"topics":  []interface{}{"sales", "support"},
+		"budget":  float64(5000),
+		"empty":   "",
+		"_hp":     "",
+	}
+	return form, sub, data
+}
+
+func TestNewMessage(t *testing.T) {
+	form, sub, data := testSubmission()
+	msg := NewMessage(form, sub, data, map[string]string{"email": "Email address"}, "https://forms.example.com/forms/frm_1", 4)
+
+	want := []Field{
+		{"name", "Ada"},
+		{"Email address", "ada@example.com"},
+		{"message", "Hello <there> & welcome"},
+		{"budget", "5000"},
+	}
+	if len(msg.Fields) != len(want) {
+		t.Fatalf("expected %d fields, got %+v", len(want), msg.Fields)
+	}
+	for i, f := range want {
+		if msg.Fields[i] != f {
+			t.Errorf("field %d: expected %+v, got %+v", i, f, msg.Fields[i])
+		}
+	}
+	if msg.More != 1 {
+		t.Errorf("expected 1 field left out, got %d", msg.More)
+	}
+
+	long := map[string]interface{}{"message": strings.Repeat("a", 500), "topics": []interface{}{"sales", "support"}}
+	msg = NewMessage(form, sub, long, nil, "", 5)
+	if got := []rune(msg.Fields[0].Value); len(got) != maxValueRunes || got[len(got)-1] != '…' {
+		t.Errorf("expected the value cut to %d runes, got %d", maxValueRunes, len(got))
+	}
+	if msg.Fields[1].Value != "sales, support" {
+		t.Errorf("expected lists joined, got %q", msg.Fields[1].Value)
+	}
+}
+
+func TestSlackPayload(t *testing.T) {
+	form, sub, data := testSubmission()
+	sub.IsTest = true
+	body, err := Slack{}.Payload(NewMessage(form, sub, data, nil, "https://forms.example.com/forms/frm_1", 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := string(body)
+	for _, want := range []string{
+		`"text":"[Test] New submission: Contact"`,
+		`"type":"header"`,
+		`*name*\nAda`,
+		`3 more field(s)`,
+		`"url":"https://forms.example.com/forms/frm_1"`,
+	} {
+		if !strings.Contains(s, want) {
+			t.Errorf("expected %s in %s", want, s)
+		}
+	}
+
+	body, _ = Slack{}.Payload(NewMessage(form, sub, data, nil, "", 5))
+	var got struct {
+		Blocks []struct {
+			Fields []struct {
+				Text string `json:"text"`
+			} `json:"fields"`
+		} `json:"blocks"`
+	}
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatal(err)
+	}
+	if text := got.Blocks[1].Fields[2].Text; text != "*message*\nHello &lt;there&gt; &amp; welcome" {
+		t.Errorf("expected mrkdwn escaped, got %q", text)
+	}
+}
+
+func TestDiscordPayload(t *testing.T) {
+	form, sub, data := testSubmission()
+	body, err := Discord{}.Payload(NewMessage(form, sub, data, nil, "https://forms.example.com/forms/frm_1", 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got struct {
+		Embeds []struct {
+			Title  string `json:"title"`
+			URL    string `json:"url"`
+			Fields []struct {
+				Name  string `json:"name"`
+				Value string `json:"value"`
+			} `json:"fields"`
+			Footer struct {
+				Text string `json:"text"`
+			} `json:"footer"`
+			Timestamp string `json:"timestamp"`
+		} `json:"embeds"`
+		AllowedMentions struct {
+			Parse []string `json:"parse"`
+		} `json:"allowed_mentions"`
+	}
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatal(err)
+	}
+	embed := got.Embeds[0]
+	if embed.Title != "New submission: Contact" || embed.URL != "https://forms.example.com/forms/frm_1" {
+		t.Errorf("unexpected title or url: %+v", embed)
+	}
+	if len(embed.Fields) != 2 || embed.Fields[1].Name != "email" || embed.Footer.Text != "3 more field(s) in the dashboard" {
+		t.Errorf("unexpected fields: %+v", embed)
+	}
+	if embed.Timestamp != "2026-03-10T12:00:00Z" {
+		t.Errorf("unexpected timestamp %q", embed.Timestamp)
+	}
+	if got.AllowedMentions.Parse == nil || len(got.AllowedMentions.Parse) != 0 {
+		t.Errorf("expected mentions turned off, got %+v", got.AllowedMentions)
+	}
+}
+
+func TestNotifySubmission(t *testing.T) {
+	var posts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		switch r.URL.Path {
+		case "/slack":
+			posts = append(posts, "slack")
+		case "/discord":
+			posts = append(posts, "discord")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		default:
+			http.Error(w, "no_service", http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	form, sub, data := testSubmission()
+	form.Channels = []domain.NotifyChannel{
+		{Type: domain.ChannelSlack, URL: server.URL + "/gone"},
+		{Type: domain.ChannelSlack, URL: server.URL + "/slack"},
+		{Type: domain.ChannelDiscord, URL: server.URL + "/discord"},
+	}
+	NewService().NotifySubmission(context.Background(), form, sub, data, nil, "")
+	if strings.Join(posts, ",") != "slack,discord" {
+		t.Errorf("expected the other channels posted after a failure, got %v", posts)
+	}
+}
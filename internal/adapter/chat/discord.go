@@ -0,0 +1,60 @@
+package chat
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// discordColor is the embed's accent, the dashboard's purple
+const discordColor = 0x667eea
+
+// Discord formats messages as an embed for Discord channel webhooks
+type Discord struct{}
+
+// Name identifies the channel in logs
+func (Discord) Name() string {
+	return "discord"
+}
+
+// Payload returns an embed titled with a link to the dashboard, one embed field per
+// submitted field and how many were left out in the footer. Mentions are turned off so
+// submitted text can't ping the channel.
+func (Discord) Payload(msg Message) ([]byte, error) {
+	type field struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	}
+	fields := make([]field, len(msg.Fields))
+	for i, f := range msg.Fields {
+		fields[i] = field{Name: truncate(f.Label, 256), Value: f.Value}
+	}
+
+	embed := map[string]interface{}{
+		"title":     truncate(msg.Title(), 256),
+		"color":     discordColor,
+		"fields":    fields,
+		"timestamp": msg.SubmittedAt.UTC().Format(time.RFC3339),
+	}
+	if msg.DashboardURL != "" {
+		embed["url"] = msg.DashboardURL
+	}
+	if msg.More > 0 {
+		embed["footer"] = map[string]string{"text": fmt.Sprintf("%d more field(s) in the dashboard", msg.More)}
+	}
+
+	return json.Marshal(map[string]interface{}{
+		"username":         "HeadlessForms",
+		"embeds":           []interface{}{embed},
+		"allowed_mentions": map[string]interface{}{"parse": []string{}},
+	})
+}
+
+// truncate cuts s to at most n runes, ending with "…" when it was longer
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
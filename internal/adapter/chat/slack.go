@@ -0,0 +1,58 @@
+package chat
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// slackEscaper escapes the characters Slack's mrkdwn gives meaning to
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// Slack formats messages as Block Kit blocks for Slack incoming webhooks
+type Slack struct{}
+
+// Name identifies the channel in logs
+func (Slack) Name() string {
+	return "slack"
+}
+
+// Payload returns a header, the fields as a two-column section, how many fields were
+// left out and when, and a button to the dashboard. text is the notification fallback.
+func (Slack) Payload(msg Message) ([]byte, error) {
+	type text struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	blocks := []map[string]interface{}{
+		{"type": "header", "text": text{"plain_text", msg.Title()}},
+	}
+	if len(msg.Fields) > 0 {
+		fields := make([]text, len(msg.Fields))
+		for i, f := range msg.Fields {
+			fields[i] = text{"mrkdwn", fmt.Sprintf("*%s*\n%s", slackEscaper.Replace(f.Label), slackEscaper.Replace(f.Value))}
+		}
+		blocks = append(blocks, map[string]interface{}{"type": "section", "fields": fields})
+	}
+
+	footer := "Received " + msg.SubmittedAt.UTC().Format("Jan 2, 2006 15:04 UTC")
+	if msg.More > 0 {
+		footer = fmt.Sprintf("%d more field(s) · %s", msg.More, footer)
+	}
+	blocks = append(blocks, map[string]interface{}{"type": "context", "elements": []text{{"mrkdwn", footer}}})
+	if msg.DashboardURL != "" {
+		blocks = append(blocks, map[string]interface{}{
+			"type": "actions",
+			"elements": []map[string]interface{}{{
+				"type": "button",
+				"text": text{"plain_text", "View in dashboard"},
+				"url":  msg.DashboardURL,
+			}},
+		})
+	}
+
+	return json.Marshal(map[string]interface{}{
+		"text":   msg.Title(),
+		"blocks": blocks,
+	})
+}
@@ -2,15 +2,27 @@ package email
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"html/template"
-	"log"
+	"maps"
 	"net/smtp"
+	"slices"
 	"strings"
 	"time"
+	"unicode/utf8"
+
+	"headless_form/internal/adapter/logger"
+	"headless_form/internal/core/domain"
+	"headless_form/internal/core/ids"
+	"headless_form/internal/core/ports"
 )
 
+var log = logger.Module("email")
+
 // Config holds SMTP configuration
 type Config struct {
 	Host     string
@@ -23,14 +35,43 @@ type Config struct {
 	Enabled  bool
 }
 
+// Retry policy of the email queue: the first retry waits a minute, each further one
+// twice as long, up to an hour, and an email is given up on after 8 attempts (~2 hours)
+const (
+	DefaultMaxAttempts = 8
+	DefaultBackoff     = time.Minute
+	DefaultMaxBackoff  = time.Hour
+)
+
+// queueBatch caps the emails sent per run of the queue worker
+const queueBatch = 50
+
 // Service provides email sending capabilities
 type Service struct {
 	config Config
+
+	queue       ports.EmailQueueRepository
+	kick        chan struct{} // Wakes the queue worker when an email is queued
+	maxAttempts int
+	backoff     time.Duration // Wait before the first retry, doubled for each further one
+	maxBackoff  time.Duration
 }
 
 // NewService creates a new email service
 func NewService(config Config) *Service {
-	return &Service{config: config}
+	return &Service{
+		config:      config,
+		kick:        make(chan struct{}, 1),
+		maxAttempts: DefaultMaxAttempts,
+		backoff:     DefaultBackoff,
+		maxBackoff:  DefaultMaxBackoff,
+	}
+}
+
+// SetQueue makes the service queue emails in repo instead of sending them right away.
+// The worker started with StartQueue sends them, retrying failures.
+func (s *Service) SetQueue(repo ports.EmailQueueRepository) {
+	s.queue = repo
 }
 
 // SubmissionData represents data for the submission notification email
@@ -40,14 +81,27 @@ type SubmissionData struct {
 	SubmissionID string
 	SubmittedAt  time.Time
 	Fields       map[string]interface{}
+	Labels       map[string]string // Field labels from the form's schema; other fields show their key
 	DashboardURL string
+	Attachments  []Attachment
+	FieldLimit   int       // Characters of each value the built-in bodies show; domain.DefaultEmailFieldLimit when 0
+	Test         bool      // Sent from the dashboard's test notification, not a real submission
+	Summary      bool      // Leave the fields and attachments out, for recipients who only need to know
+	Template     *Template // The form's own template, not used for summaries
+}
+
+// Attachment is a file attached to an email
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
 }
 
 // SendSubmissionNotification sends a notification email for a new submission
 func (s *Service) SendSubmissionNotification(to []string, data SubmissionData) error {
 	if !s.config.Enabled {
 		// Log instead of sending in dev mode
-		fmt.Printf("[EMAIL] Would send submission notification to %v for form %s\n", to, data.FormName)
+		log.Info("email disabled, skipping submission notification", "to", to, "form", data.FormName)
 		return nil
 	}
 
@@ -55,27 +109,85 @@ func (s *Service) SendSubmissionNotification(to []string, data SubmissionData) e
 		return nil
 	}
 
-	subject := fmt.Sprintf("New submission: %s", data.FormName)
-	htmlBody, err := s.renderSubmissionHTML(data)
-	if err != nil {
-		return fmt.Errorf("failed to render email template: %w", err)
+	// Parts the form's template defines replace the built-in ones
+	var subject, htmlBody, textBody string
+	if data.Template != nil && !data.Summary {
+		custom, err := compileTemplate(*data.Template)
+		if err != nil {
+			log.Warn("invalid custom email template, using the default", "form", data.FormID, "error", err)
+		} else {
+			subject, htmlBody, textBody = custom.renderCustom(data)
+		}
 	}
 
-	textBody := s.renderSubmissionText(data)
+	if subject == "" {
+		subject = fmt.Sprintf("New submission: %s", data.FormName)
+	}
+	if data.Test {
+		subject = "[Test] " + subject
+	}
+	if htmlBody == "" {
+		var err error
+		if htmlBody, err = s.renderSubmissionHTML(data); err != nil {
+			return fmt.Errorf("failed to render email template: %w", err)
+		}
+	}
+	if textBody == "" {
+		textBody = s.renderSubmissionText(data)
+	}
 
-	return s.sendEmail(to, subject, htmlBody, textBody)
+	attachments := data.Attachments
+	if data.Summary {
+		attachments = nil
+	}
+	return s.sendEmailWithAttachments(to, subject, htmlBody, textBody, attachments)
 }
 
 // sendEmail sends an email with both HTML and plain text parts
 func (s *Service) sendEmail(to []string, subject, htmlBody, textBody string) error {
+	return s.sendEmailWithAttachments(to, subject, htmlBody, textBody, nil)
+}
+
+// sendEmailWithAttachments sends an email with HTML and plain text parts plus optional attachments
+func (s *Service) sendEmailWithAttachments(to []string, subject, htmlBody, textBody string, attachments []Attachment) error {
+	return s.sendMessage(to, nil, subject, htmlBody, textBody, attachments)
+}
+
+// sendMessage sends an email with extra headers, leaving out an empty HTML or text part.
+// With a queue it's queued instead, and sent by the queue worker.
+func (s *Service) sendMessage(to []string, extraHeaders map[string]string, subject, htmlBody, textBody string, attachments []Attachment) error {
+	msg := s.buildMessage(to, extraHeaders, subject, htmlBody, textBody, attachments)
+	if s.queue != nil {
+		err := s.enqueue(to, subject, msg)
+		if err == nil {
+			return nil
+		}
+		log.Warn("failed to queue email, sending it now", "to", to, "error", err)
+	}
+	log.Debug("sending email", "to", to, "subject", subject, "attachments", len(attachments))
+	return s.transmit(to, msg)
+}
+
+// buildMessage renders an email with its headers, text and HTML parts and attachments
+func (s *Service) buildMessage(to []string, extraHeaders map[string]string, subject, htmlBody, textBody string, attachments []Attachment) []byte {
+
 	boundary := "BOUNDARY_HEADLESSFORMS_EMAIL"
+	mixedBoundary := "BOUNDARY_HEADLESSFORMS_MIXED"
+
+	contentType := fmt.Sprintf("multipart/alternative; boundary=%s", boundary)
+	if len(attachments) > 0 {
+		contentType = fmt.Sprintf("multipart/mixed; boundary=%s", mixedBoundary)
+	}
 
 	headers := map[string]string{
 		"From":         fmt.Sprintf("%s <%s>", s.config.FromName, s.config.From),
 		"To":           strings.Join(to, ", "),
 		"Subject":      subject,
 		"MIME-Version": "1.0",
-		"Content-Type": fmt.Sprintf("multipart/alternative; boundary=%s", boundary),
+		"Content-Type": contentType,
+	}
+	for k, v := range extraHeaders {
+		headers[k] = v
 	}
 
 	var msg bytes.Buffer
@@ -84,29 +196,159 @@ func (s *Service) sendEmail(to []string, subject, htmlBody, textBody string) err
 	}
 	msg.WriteString("\r\n")
 
+	// With attachments, the text/HTML alternatives are nested in a multipart/mixed body
+	if len(attachments) > 0 {
+		msg.WriteString(fmt.Sprintf("--%s\r\n", mixedBoundary))
+		msg.WriteString(fmt.Sprintf("Content-Type: multipart/alternative; boundary=%s\r\n\r\n", boundary))
+	}
+
 	// Plain text part
-	msg.WriteString(fmt.Sprintf("--%s\r\n", boundary))
-	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
-	msg.WriteString(textBody)
-	msg.WriteString("\r\n")
+	if textBody != "" || htmlBody == "" {
+		msg.WriteString(fmt.Sprintf("--%s\r\n", boundary))
+		msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+		msg.WriteString(textBody)
+		msg.WriteString("\r\n")
+	}
 
 	// HTML part
-	msg.WriteString(fmt.Sprintf("--%s\r\n", boundary))
-	msg.WriteString("Content-Type: text/html; charset=utf-8\r\n\r\n")
-	msg.WriteString(htmlBody)
-	msg.WriteString("\r\n")
+	if htmlBody != "" {
+		msg.WriteString(fmt.Sprintf("--%s\r\n", boundary))
+		msg.WriteString("Content-Type: text/html; charset=utf-8\r\n\r\n")
+		msg.WriteString(htmlBody)
+		msg.WriteString("\r\n")
+	}
 
 	msg.WriteString(fmt.Sprintf("--%s--\r\n", boundary))
 
-	// Send via SMTP
+	for _, a := range attachments {
+		msg.WriteString(fmt.Sprintf("--%s\r\n", mixedBoundary))
+		msg.WriteString(fmt.Sprintf("Content-Type: %s; name=%q\r\n", a.ContentType, a.Filename))
+		msg.WriteString(fmt.Sprintf("Content-Disposition: attachment; filename=%q\r\n", a.Filename))
+		msg.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+		encoded := base64.StdEncoding.EncodeToString(a.Data)
+		for len(encoded) > 76 {
+			msg.WriteString(encoded[:76] + "\r\n")
+			encoded = encoded[76:]
+		}
+		msg.WriteString(encoded + "\r\n")
+	}
+	if len(attachments) > 0 {
+		msg.WriteString(fmt.Sprintf("--%s--\r\n", mixedBoundary))
+	}
+
+	return msg.Bytes()
+}
+
+// transmit sends a rendered message over SMTP
+func (s *Service) transmit(to []string, msg []byte) error {
 	addr := fmt.Sprintf("%s:%d", s.config.Host, s.config.Port)
 	auth := smtp.PlainAuth("", s.config.Username, s.config.Password, s.config.Host)
 
 	if s.config.UseTLS {
-		return s.sendWithTLS(addr, auth, to, msg.Bytes())
+		return s.sendWithTLS(addr, auth, to, msg)
+	}
+
+	return smtp.SendMail(addr, auth, s.config.From, to, msg)
+}
+
+// enqueue stores a rendered message in the queue and wakes the worker
+func (s *Service) enqueue(to []string, subject string, msg []byte) error {
+	now := time.Now().UTC()
+	email := &domain.QueuedEmail{
+		ID:            ids.New(ids.Email),
+		To:            to,
+		Subject:       subject,
+		Message:       msg,
+		Status:        domain.EmailPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if err := s.queue.Create(context.Background(), email); err != nil {
+		return err
+	}
+	log.Debug("queued email", "id", email.ID, "to", to, "subject", subject)
+	select {
+	case s.kick <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// ProcessQueue sends the queued emails that are due. A failed email is retried after
+// a backoff until it has used its attempts, then it's marked dead and left in the
+// queue for inspection. Returns how many emails were sent and how many failed.
+func (s *Service) ProcessQueue(ctx context.Context) (sent, failed int, err error) {
+	if s.queue == nil {
+		return 0, 0, nil
+	}
+	due, err := s.queue.ListDue(ctx, time.Now(), queueBatch)
+	if err != nil {
+		return 0, 0, fmt.Errorf("list due emails: %w", err)
+	}
+	for _, e := range due {
+		if ctx.Err() != nil {
+			break
+		}
+		sendErr := s.transmit(e.To, e.Message)
+		now := time.Now().UTC()
+		e.Attempts++
+		e.UpdatedAt = now
+		switch {
+		case sendErr == nil:
+			e.Status = domain.EmailSent
+			e.LastError = ""
+			e.SentAt = &now
+			sent++
+			log.Debug("sent queued email", "id", e.ID, "to", e.To, "attempt", e.Attempts)
+		case e.Attempts >= s.maxAttempts:
+			e.Status = domain.EmailDead
+			e.LastError = sendErr.Error()
+			failed++
+			log.Error("email failed, giving up", "id", e.ID, "to", e.To, "attempts", e.Attempts, "error", sendErr)
+		default:
+			e.LastError = sendErr.Error()
+			e.NextAttemptAt = now.Add(s.retryDelay(e.Attempts))
+			failed++
+			log.Warn("email attempt failed", "id", e.ID, "to", e.To, "attempt", e.Attempts, "retry_at", e.NextAttemptAt, "error", sendErr)
+		}
+		if err := s.queue.Update(ctx, e); err != nil {
+			return sent, failed, fmt.Errorf("update queued email: %w", err)
+		}
 	}
+	return sent, failed, nil
+}
 
-	return smtp.SendMail(addr, auth, s.config.From, to, msg.Bytes())
+// retryDelay is the wait after the given number of failed attempts
+func (s *Service) retryDelay(attempts int) time.Duration {
+	d := s.backoff
+	for i := 1; i < attempts && d < s.maxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, s.maxBackoff)
+}
+
+// StartQueue runs ProcessQueue every interval, and as soon as an email is queued,
+// until ctx is cancelled, skipping runs while paused reports true (nil never pauses)
+func (s *Service) StartQueue(ctx context.Context, interval time.Duration, paused func() bool) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			case <-s.kick:
+			}
+			if paused != nil && paused() {
+				continue
+			}
+			if _, _, err := s.ProcessQueue(ctx); err != nil {
+				log.Error("email queue run failed", "error", err)
+			}
+		}
+	}()
 }
 
 func (s *Service) sendWithTLS(addr string, auth smtp.Auth, to []string, msg []byte) error {
@@ -120,7 +362,7 @@ func (s *Service) sendWithTLS(addr string, auth smtp.Auth, to []string, msg []by
 	}
 	defer func() {
 		if cerr := conn.Close(); cerr != nil {
-			log.Printf("[WARN] Failed to close TLS connection: %v", cerr)
+			log.Warn("failed to close TLS connection", "error", cerr)
 		}
 	}()
 
@@ -130,7 +372,7 @@ func (s *Service) sendWithTLS(addr string, auth smtp.Auth, to []string, msg []by
 	}
 	defer func() {
 		if cerr := client.Close(); cerr != nil {
-			log.Printf("[WARN] Failed to close SMTP client: %v", cerr)
+			log.Warn("failed to close SMTP client", "error", cerr)
 		}
 	}()
 
@@ -182,16 +424,23 @@ func (s *Service) renderSubmissionHTML(data SubmissionData) (string, error) {
   </div>
 
   <div style="background: white; padding: 25px; border: 1px solid #e9ecef; border-top: none; border-radius: 0 0 12px 12px;">
+    {{if .Summary}}
+    <p style="margin: 0; color: #333; font-size: 15px;">A new submission arrived. Open the dashboard to read it.</p>
+    {{else}}
     <h2 style="font-size: 16px; color: #333; margin: 0 0 20px; padding-bottom: 10px; border-bottom: 2px solid #f0f0f0;">Submission Details</h2>
     
-    <table style="width: 100%; border-collapse: collapse;">
-      {{range $key, $value := .Fields}}
+    <table style="width: 100%; border-collapse: collapse; table-layout: fixed;">
+      {{range .Rows}}
       <tr>
-        <td style="padding: 12px 0; border-bottom: 1px solid #f0f0f0; color: #666; font-size: 13px; text-transform: uppercase; letter-spacing: 0.5px; width: 35%; vertical-align: top;">{{$key}}</td>
-        <td style="padding: 12px 0; border-bottom: 1px solid #f0f0f0; color: #333; font-size: 15px;">{{$value}}</td>
+        <td style="padding: 12px 0; border-bottom: 1px solid #f0f0f0; color: #666; font-size: 13px; text-transform: uppercase; letter-spacing: 0.5px; width: 35%; vertical-align: top;">{{.Label}}</td>
+        <td style="padding: 12px 0; border-bottom: 1px solid #f0f0f0; color: #333; font-size: 15px; white-space: pre-wrap; word-break: break-word;">{{.Value}}</td>
       </tr>
       {{end}}
     </table>
+    {{if .Truncated}}
+    <p style="margin: 15px 0 0; color: #666; font-size: 13px;">Long values were shortened. <a href="{{.DashboardURL}}" style="color: #667eea;">View the full submission</a> in the dashboard.</p>
+    {{end}}
+    {{end}}
 
     <div style="margin-top: 25px; text-align: center;">
       <a href="{{.DashboardURL}}" style="display: inline-block; background: linear-gradient(135deg, #667eea 0%, #764ba2 100%); color: white; padding: 12px 30px; border-radius: 8px; text-decoration: none; font-weight: 600; font-size: 14px;">View in Dashboard</a>
@@ -209,8 +458,16 @@ func (s *Service) renderSubmissionHTML(data SubmissionData) (string, error) {
 		return "", err
 	}
 
+	// Values are escaped by html/template, whatever markup they hold
+	view := struct {
+		SubmissionData
+		Rows      []fieldRow
+		Truncated bool
+	}{SubmissionData: data}
+	view.Rows, view.Truncated = data.rows()
+
 	var buf bytes.Buffer
-	if err := t.Execute(&buf, data); err != nil {
+	if err := t.Execute(&buf, view); err != nil {
 		return "", err
 	}
 
@@ -221,19 +478,193 @@ func (s *Service) renderSubmissionText(data SubmissionData) string {
 	var sb strings.Builder
 
 	sb.WriteString(fmt.Sprintf("New Submission: %s\n", data.FormName))
+	if data.Test {
+		sb.WriteString("This is a test notification. No submission was stored.\n")
+	}
 	sb.WriteString(fmt.Sprintf("Received: %s\n\n", data.SubmittedAt.Format("January 2, 2006 at 3:04 PM")))
+	if data.Summary {
+		sb.WriteString("A new submission arrived. Open the dashboard to read it.\n")
+		sb.WriteString(fmt.Sprintf("\nView in Dashboard: %s\n", data.DashboardURL))
+		return sb.String()
+	}
 	sb.WriteString("Submission Details:\n")
 	sb.WriteString("-------------------\n\n")
 
-	for key, value := range data.Fields {
-		sb.WriteString(fmt.Sprintf("%s: %v\n", key, value))
+	rows, truncated := data.rows()
+	for _, row := range rows {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", row.Label, row.Value))
 	}
 
+	if truncated {
+		sb.WriteString("\nLong values were shortened. View the full submission in the dashboard.\n")
+	}
 	sb.WriteString(fmt.Sprintf("\nView in Dashboard: %s\n", data.DashboardURL))
 
 	return sb.String()
 }
 
+// fieldRow is a submitted field as the built-in bodies show it
+type fieldRow struct {
+	Label string
+	Value string
+}
+
+// rows returns the fields by name with their labels and formatted values, and whether
+// any value was cut to the field limit
+func (data SubmissionData) rows() ([]fieldRow, bool) {
+	limit := data.FieldLimit
+	if limit <= 0 {
+		limit = domain.DefaultEmailFieldLimit
+	}
+	truncated := false
+	rows := make([]fieldRow, 0, len(data.Fields))
+	for _, key := range slices.Sorted(maps.Keys(data.Fields)) {
+		label := key
+		if l := data.Labels[key]; l != "" {
+			label = l
+		}
+		value, cut := truncateRunes(formatValue(data.Fields[key]), limit)
+		truncated = truncated || cut
+		rows = append(rows, fieldRow{Label: label, Value: value})
+	}
+	return rows, truncated
+}
+
+// formatValue renders a submitted value as text: lists of plain values joined with
+// commas, objects and other lists as JSON
+func formatValue(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case []interface{}:
+		parts := make([]string, 0, len(t))
+		for _, item := range t {
+			switch item.(type) {
+			case map[string]interface{}, []interface{}:
+				b, _ := json.Marshal(t)
+				return string(b)
+			}
+			parts = append(parts, formatValue(item))
+		}
+		return strings.Join(parts, ", ")
+	case map[string]interface{}:
+		b, _ := json.Marshal(t)
+		return string(b)
+	}
+	return fmt.Sprint(v)
+}
+
+// truncateRunes cuts s to at most n runes, ending it with "…", and reports whether it did
+func truncateRunes(s string, n int) (string, bool) {
+	if utf8.RuneCountInString(s) <= n {
+		return s, false
+	}
+	r := []rune(s)
+	return string(r[:n-1]) + "…", true
+}
+
+// SendAutoReply emails a submitter the form's confirmation. The template's subject and
+// at least one body are required; a missing subject falls back to a generic one, and
+// nothing is sent when no body renders. Replies go to replyTo when it's set.
+func (s *Service) SendAutoReply(to, replyTo string, tmpl Template, data SubmissionData) error {
+	if !s.config.Enabled {
+		log.Info("email disabled, skipping auto-reply", "to", to, "form", data.FormName)
+		return nil
+	}
+
+	compiled, err := compileTemplate(tmpl)
+	if err != nil {
+		return fmt.Errorf("invalid auto-reply template: %w", err)
+	}
+	// Submitters don't get a link to the dashboard
+	data.DashboardURL = ""
+	subject, htmlBody, textBody := compiled.renderCustom(data)
+	if htmlBody == "" && textBody == "" {
+		return fmt.Errorf("auto-reply for form %s rendered no body", data.FormID)
+	}
+	if subject == "" {
+		subject = "We received your submission"
+	}
+
+	// Marked as automatic (RFC 3834) so mail servers don't answer it with their own auto-replies
+	headers := map[string]string{"Auto-Submitted": "auto-replied"}
+	if replyTo != "" {
+		headers["Reply-To"] = replyTo
+	}
+	return s.sendMessage([]string{to}, headers, subject, htmlBody, textBody, nil)
+}
+
+// SendAlert sends a form alert notification
+func (s *Service) SendAlert(to []string, formName, message, dashboardURL string) error {
+	if !s.config.Enabled {
+		log.Info("email disabled, skipping alert", "to", to, "form", formName, "message", message)
+		return nil
+	}
+
+	if len(to) == 0 {
+		return nil
+	}
+
+	subject := fmt.Sprintf("Alert: %s", formName)
+	textBody := fmt.Sprintf("Alert for form %s\n\n%s\n\nView in Dashboard: %s\n", formName, message, dashboardURL)
+	htmlBody := fmt.Sprintf(`<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <title>Form Alert</title>
+</head>
+<body style="font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif; max-width: 600px; margin: 0 auto; padding: 20px;">
+  <div style="background: #dc2626; padding: 30px 20px; border-radius: 12px 12px 0 0; text-align: center;">
+    <h1 style="color: white; margin: 0;">⚠️ Form Alert</h1>
+    <p style="color: rgba(255,255,255,0.9); margin: 10px 0 0;">%s</p>
+  </div>
+  <div style="background: white; padding: 25px; border: 1px solid #e9ecef; border-top: none; border-radius: 0 0 12px 12px;">
+    <p style="color: #333;">%s</p>
+    <div style="text-align: center; margin: 25px 0;">
+      <a href="%s" style="display: inline-block; background: #333; color: white; padding: 12px 30px; border-radius: 8px; text-decoration: none; font-weight: 600;">View in Dashboard</a>
+    </div>
+  </div>
+</body>
+</html>`, template.HTMLEscapeString(formName), template.HTMLEscapeString(message), template.HTMLEscapeString(dashboardURL))
+
+	return s.sendEmail(to, subject, htmlBody, textBody)
+}
+
+// SendMention tells a user they were mentioned in a comment on a submission
+func (s *Service) SendMention(to, authorName, formName, comment, dashboardURL string) error {
+	if !s.config.Enabled {
+		log.Info("email disabled, skipping mention", "to", to, "form", formName)
+		return nil
+	}
+
+	subject := fmt.Sprintf("%s mentioned you on %s", authorName, formName)
+	textBody := fmt.Sprintf("%s mentioned you in a comment on a %s submission:\n\n%s\n\nView in Dashboard: %s\n", authorName, formName, comment, dashboardURL)
+	htmlBody := fmt.Sprintf(`<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <title>New Mention</title>
+</head>
+<body style="font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif; max-width: 600px; margin: 0 auto; padding: 20px;">
+  <div style="background: linear-gradient(135deg, #667eea 0%%, #764ba2 100%%); padding: 30px 20px; border-radius: 12px 12px 0 0; text-align: center;">
+    <h1 style="color: white; margin: 0;">💬 You were mentioned</h1>
+    <p style="color: rgba(255,255,255,0.9); margin: 10px 0 0;">%s</p>
+  </div>
+  <div style="background: white; padding: 25px; border: 1px solid #e9ecef; border-top: none; border-radius: 0 0 12px 12px;">
+    <p style="color: #666;"><strong>%s</strong> wrote:</p>
+    <p style="color: #333; white-space: pre-wrap; border-left: 3px solid #667eea; padding-left: 12px;">%s</p>
+    <div style="text-align: center; margin: 25px 0;">
+      <a href="%s" style="display: inline-block; background: #333; color: white; padding: 12px 30px; border-radius: 8px; text-decoration: none; font-weight: 600;">View in Dashboard</a>
+    </div>
+  </div>
+</body>
+</html>`, template.HTMLEscapeString(formName), template.HTMLEscapeString(authorName), template.HTMLEscapeString(comment), template.HTMLEscapeString(dashboardURL))
+
+	return s.sendEmail([]string{to}, subject, htmlBody, textBody)
+}
+
 // IsEnabled returns whether email sending is enabled
 func (s *Service) IsEnabled() bool {
 	return s.config.Enabled
@@ -242,7 +673,7 @@ func (s *Service) IsEnabled() bool {
 // SendPasswordReset sends a password reset email
 func (s *Service) SendPasswordReset(to, resetURL string) error {
 	if !s.config.Enabled {
-		fmt.Printf("[EMAIL] Would send password reset to %s with URL: %s\n", to, resetURL)
+		log.Info("email disabled, skipping password reset", "to", to, "url", resetURL)
 		return nil
 	}
 
@@ -253,6 +684,40 @@ func (s *Service) SendPasswordReset(to, resetURL string) error {
 	return s.sendEmail([]string{to}, subject, htmlBody, textBody)
 }
 
+// SendInvite invites a user moved over from another instance to set a password on this one
+func (s *Service) SendInvite(to, setupURL string, validFor time.Duration) error {
+	if !s.config.Enabled {
+		log.Info("email disabled, skipping invite", "to", to, "url", setupURL)
+		return nil
+	}
+
+	days := max(int(validFor.Hours()/24), 1)
+	subject := "Your HeadlessForms account has moved"
+	textBody := fmt.Sprintf("Your account was moved to a new HeadlessForms server. Set a password to sign in: %s\n\nThis link expires in %d days.", setupURL, days)
+	htmlBody := fmt.Sprintf(`<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <title>Set Your Password</title>
+</head>
+<body style="font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif; max-width: 600px; margin: 0 auto; padding: 20px;">
+  <div style="background: linear-gradient(135deg, #667eea 0%%, #764ba2 100%%); padding: 30px 20px; border-radius: 12px 12px 0 0; text-align: center;">
+    <h1 style="color: white; margin: 0;">👋 Welcome Back</h1>
+  </div>
+  <div style="background: white; padding: 25px; border: 1px solid #e9ecef; border-top: none; border-radius: 0 0 12px 12px;">
+    <p style="color: #333;">Your HeadlessForms account, with your forms, was moved to a new server.</p>
+    <p style="color: #333;">Set a password to sign in:</p>
+    <div style="text-align: center; margin: 25px 0;">
+      <a href="%s" style="display: inline-block; background: linear-gradient(135deg, #667eea 0%%, #764ba2 100%%); color: white; padding: 14px 32px; border-radius: 8px; text-decoration: none; font-weight: 600;">Set Password</a>
+    </div>
+    <p style="color: #666; font-size: 14px;">This link will expire in %d days.</p>
+  </div>
+</body>
+</html>`, template.HTMLEscapeString(setupURL), days)
+
+	return s.sendEmail([]string{to}, subject, htmlBody, textBody)
+}
+
 func (s *Service) renderPasswordResetHTML(resetURL string) string {
 	return fmt.Sprintf(`<!DOCTYPE html>
 <html>
@@ -274,5 +739,5 @@ func (s *Service) renderPasswordResetHTML(resetURL string) string {
     <p style="color: #999; font-size: 12px;">If you didn't request this, you can safely ignore this email.</p>
   </div>
 </body>
-</html>`, resetURL)
+</html>`, template.HTMLEscapeString(resetURL))
 }
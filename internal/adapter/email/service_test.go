@@ -0,0 +1,387 @@
+package email
+
+import (
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"strings"
+	"testing"
+	"time"
+
+	"headless_form/internal/adapter/storage/sqlite"
+	"headless_form/internal/core/domain"
+	"headless_form/internal/testharness"
+)
+
+// parts returns the content of each leaf part of a received message by content type
+func parts(t *testing.T, msg testharness.Message) map[string]string {
+	t.Helper()
+	m, err := msg.Parse()
+	if err != nil {
+		t.Fatalf("parse message: %v", err)
+	}
+	found := make(map[string]string)
+	var walk func(r io.Reader, contentType string)
+	walk = func(r io.Reader, contentType string) {
+		mediaType, params, err := mime.ParseMediaType(contentType)
+		if err != nil {
+			t.Fatalf("parse content type %q: %v", contentType, err)
+		}
+		if !strings.HasPrefix(mediaType, "multipart/") {
+			body, _ := io.ReadAll(r)
+			found[mediaType] = string(body)
+			return
+		}
+		mr := multipart.NewReader(r, params["boundary"])
+		for {
+			p, err := mr.NextPart()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				t.Fatalf("read part: %v", err)
+			}
+			walk(p, p.Header.Get("Content-Type"))
+		}
+	}
+	walk(m.Body, m.Header.Get("Content-Type"))
+	return found
+}
+
+func newTestService(sink *testharness.SMTPSink) *Service {
+	return NewService(Config{
+		Host:     sink.Host(),
+		Port:     sink.Port(),
+		Username: "mailer",
+		Password: "s3cret",
+		From:     "forms@example.com",
+		FromName: "HeadlessForms",
+		Enabled:  true,
+	})
+}
+
+func TestSendSubmissionNotification(t *testing.T) {
+	sink := testharness.NewSMTPSink(t)
+	s := newTestService(sink)
+
+	err := s.SendSubmissionNotification([]string{"owner@example.com", "team@example.com"}, SubmissionData{
+		FormName:     "Contact",
+		SubmittedAt:  time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC),
+		Fields:       map[string]interface{}{"email": "ada@example.com", "message": "<b>Hello</b>"},
+		Labels:       map[string]string{"email": "Email address"},
+		DashboardURL: "https://forms.example.com/forms/contact",
+		Attachments:  []Attachment{{Filename: "cv.pdf", ContentType: "application/pdf", Data: []byte("%PDF-1.4")}},
+	})
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	msg := sink.Wait(t, 1)[0]
+	if msg.From != "forms@example.com" || strings.Join(msg.To, ",") != "owner@example.com,team@example.com" {
+		t.Errorf("unexpected envelope from %q to %v", msg.From, msg.To)
+	}
+	if msg.Username != "mailer" || msg.Password != "s3cret" {
+		t.Errorf("expected the configured credentials, got %q/%q", msg.Username, msg.Password)
+	}
+	m, _ := msg.Parse()
+	if got := m.Header.Get("Subject"); got != "New submission: Contact" {
+		t.Errorf("unexpected subject %q", got)
+	}
+	if got := m.Header.Get("From"); got != "HeadlessForms <forms@example.com>" {
+		t.Errorf("unexpected From %q", got)
+	}
+	if got := m.Header.Get("To"); got != "owner@example.com, team@example.com" {
+		t.Errorf("unexpected To %q", got)
+	}
+
+	p := parts(t, msg)
+	text := p["text/plain"]
+	if !strings.Contains(text, "Email address: ada@example.com") || !strings.Contains(text, "message: <b>Hello</b>") ||
+		!strings.Contains(text, "View in Dashboard: https://forms.example.com/forms/contact") {
+		t.Errorf("unexpected text part:\n%s", text)
+	}
+	if html := p["text/html"]; !strings.Contains(html, "&lt;b&gt;Hello&lt;/b&gt;") || strings.Contains(html, "<b>Hello</b>") {
+		t.Errorf("expected escaped field values in the HTML part:\n%s", html)
+	}
+	if pdf := p["application/pdf"]; pdf != "JVBERi0xLjQ=" {
+		t.Errorf("unexpected attachment %q", pdf)
+	}
+}
+
+func TestSendTruncatedFields(t *testing.T) {
+	sink := testharness.NewSMTPSink(t)
+	s := newTestService(sink)
+
+	err := s.SendSubmissionNotification([]string{"owner@example.com"}, SubmissionData{
+		FormName: "Contact",
+		Fields: map[string]interface{}{
+			"message": strings.Repeat("a", 50),
+			"address": map[string]interface{}{"city": "Paris"},
+			"tags":    []interface{}{"x", "y"},
+		},
+		DashboardURL: "https://forms.example.com/forms/contact",
+		FieldLimit:   20,
+	})
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	p := parts(t, sink.Wait(t, 1)[0])
+	text := p["text/plain"]
+	if !strings.Contains(text, "message: "+strings.Repeat("a", 19)+"…") || strings.Contains(text, strings.Repeat("a", 20)) {
+		t.Errorf("expected the message cut to 20 characters:\n%s", text)
+	}
+	if !strings.Contains(text, `address: {"city":"Paris"}`) || !strings.Contains(text, "tags: x, y") {
+		t.Errorf("expected objects as JSON and lists joined:\n%s", text)
+	}
+	if !strings.Contains(text, "Long values were shortened") || !strings.Contains(p["text/html"], "View the full submission") {
+		t.Errorf("expected a link to the full submission:\n%s\n%s", text, p["text/html"])
+	}
+}
+
+func TestSendSummaryNotification(t *testing.T) {
+	sink := testharness.NewSMTPSink(t)
+	s := newTestService(sink)
+
+	err := s.SendSubmissionNotification([]string{"manager@example.com"}, SubmissionData{
+		FormName:     "Contact",
+		Fields:       map[string]interface{}{"email": "ada@example.com"},
+		DashboardURL: "https://forms.example.com/forms/contact",
+		Attachments:  []Attachment{{Filename: "cv.pdf", ContentType: "application/pdf", Data: []byte("%PDF-1.4")}},
+		Summary:      true,
+	})
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	p := parts(t, sink.Wait(t, 1)[0])
+	for _, body := range []string{p["text/plain"], p["text/html"]} {
+		if strings.Contains(body, "ada@example.com") || !strings.Contains(body, "https://forms.example.com/forms/contact") {
+			t.Errorf("expected a link without the fields:\n%s", body)
+		}
+	}
+	if _, ok := p["application/pdf"]; ok {
+		t.Error("expected no attachment in a summary")
+	}
+}
+
+func TestSendCustomTemplate(t *testing.T) {
+	sink := testharness.NewSMTPSink(t)
+	s := newTestService(sink)
+
+	data := SubmissionData{
+		FormName:     "Contact",
+		Fields:       map[string]interface{}{"company": "Acme\r\nBcc: x@example.com", "message": "<b>Hi</b>"},
+		DashboardURL: "https://forms.example.com/forms/contact",
+		Template: &Template{
+			Subject: "New lead: {{.Fields.company}}",
+			HTML:    "<p>{{.Fields.message}}</p>",
+		},
+	}
+	if err := s.SendSubmissionNotification([]string{"owner@example.com"}, data); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	// Parts that fail to render fall back to the built-in ones
+	data.Template = &Template{Text: "{{index .Fields.tags 5}}"}
+	data.Test = true
+	if err := s.SendSubmissionNotification([]string{"owner@example.com"}, data); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	msgs := sink.Wait(t, 2)
+	m, _ := msgs[0].Parse()
+	if got := m.Header.Get("Subject"); got != "New lead: Acme Bcc: x@example.com" || m.Header.Get("Bcc") != "" {
+		t.Errorf("expected the subject on one line, got %q", got)
+	}
+	p := parts(t, msgs[0])
+	if html := p["text/html"]; !strings.Contains(html, "<p>&lt;b&gt;Hi&lt;/b&gt;</p>") {
+		t.Errorf("expected the custom HTML with escaped values, got:\n%s", html)
+	}
+	if !strings.Contains(p["text/plain"], "Submission Details:") {
+		t.Errorf("expected the built-in text part, got:\n%s", p["text/plain"])
+	}
+
+	m, _ = msgs[1].Parse()
+	if got := m.Header.Get("Subject"); got != "[Test] New submission: Contact" {
+		t.Errorf("unexpected subject %q", got)
+	}
+	if text := parts(t, msgs[1])["text/plain"]; !strings.Contains(text, "Submission Details:") {
+		t.Errorf("expected the built-in text part, got:\n%s", text)
+	}
+}
+
+func TestSendAutoReply(t *testing.T) {
+	sink := testharness.NewSMTPSink(t)
+	s := newTestService(sink)
+
+	data := SubmissionData{
+		FormName:     "Contact",
+		FormID:       "contact",
+		Fields:       map[string]interface{}{"name": "Ada", "email": "ada@example.com"},
+		DashboardURL: "https://forms.example.com/forms/contact",
+	}
+	tmpl := Template{Subject: "Thanks, {{.Fields.name}}", Text: "We'll be in touch.{{.DashboardURL}}"}
+	if err := s.SendAutoReply("ada@example.com", "support@example.com", tmpl, data); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	// Nothing is sent without a body
+	if err := s.SendAutoReply("ada@example.com", "", Template{Subject: "Thanks", HTML: "{{index .Fields.tags 5}}"}, data); err == nil {
+		t.Error("expected an error for an auto-reply without a body")
+	}
+
+	msg := sink.Wait(t, 1)[0]
+	if strings.Join(msg.To, ",") != "ada@example.com" {
+		t.Errorf("unexpected recipients %v", msg.To)
+	}
+	m, _ := msg.Parse()
+	if got := m.Header.Get("Subject"); got != "Thanks, Ada" {
+		t.Errorf("unexpected subject %q", got)
+	}
+	if m.Header.Get("Reply-To") != "support@example.com" || m.Header.Get("Auto-Submitted") != "auto-replied" {
+		t.Errorf("expected Reply-To and Auto-Submitted headers, got %v", m.Header)
+	}
+	p := parts(t, msg)
+	if p["text/plain"] != "We'll be in touch." {
+		t.Errorf("expected the text without the dashboard link, got %q", p["text/plain"])
+	}
+	if _, ok := p["text/html"]; ok {
+		t.Error("expected no HTML part for a text-only template")
+	}
+}
+
+func TestValidateTemplate(t *testing.T) {
+	valid := Template{
+		Subject: "{{.FormName}}: {{default \"no company\" .Fields.company}}",
+		HTML:    "{{range $k, $v := .Fields}}<b>{{index $.Labels $k}}</b> {{$v}}{{end}}",
+		Text:    "{{printf \"%s\" .DashboardURL}}",
+	}
+	if err := ValidateTemplate(valid); err != nil {
+		t.Errorf("expected a valid template, got %v", err)
+	}
+	for _, tmpl := range []Template{
+		{Subject: "{{.Fields.company"},
+		{HTML: `{{define "x"}}x{{end}}`},
+		{Text: `{{template "x"}}`},
+		{Text: "{{range 1000000000}}x{{end}}"},
+	} {
+		if err := ValidateTemplate(tmpl); err == nil {
+			t.Errorf("expected %+v to be rejected", tmpl)
+		}
+	}
+}
+
+func TestSendTestNotificationAndMention(t *testing.T) {
+	sink := testharness.NewSMTPSink(t)
+	s := newTestService(sink)
+
+	if err := s.SendSubmissionNotification([]string{"owner@example.com"}, SubmissionData{FormName: "Contact", Test: true}); err != nil {
+		t.Fatalf("send test notification: %v", err)
+	}
+	if err := s.SendMention("bob@example.com", "Ada", "Contact", "Can you <reply>?", "https://forms.example.com"); err != nil {
+		t.Fatalf("send mention: %v", err)
+	}
+
+	msgs := sink.Wait(t, 2)
+	m, _ := msgs[0].Parse()
+	if got := m.Header.Get("Subject"); got != "[Test] New submission: Contact" {
+		t.Errorf("unexpected subject %q", got)
+	}
+	if text := parts(t, msgs[0])["text/plain"]; !strings.Contains(text, "This is a test notification") {
+		t.Errorf("expected the test note, got:\n%s", text)
+	}
+
+	m, _ = msgs[1].Parse()
+	if got := m.Header.Get("Subject"); got != "Ada mentioned you on Contact" || strings.Join(msgs[1].To, ",") != "bob@example.com" {
+		t.Errorf("unexpected mention %q to %v", got, msgs[1].To)
+	}
+	if html := parts(t, msgs[1])["text/html"]; !strings.Contains(html, "Can you &lt;reply&gt;?") {
+		t.Errorf("expected the escaped comment, got:\n%s", html)
+	}
+}
+
+func TestSendEmailErrors(t *testing.T) {
+	sink := testharness.NewSMTPSink(t)
+	sink.Reject("gone@example.com")
+	s := newTestService(sink)
+
+	if err := s.SendPasswordReset("gone@example.com", "https://forms.example.com/reset?token=x"); err == nil {
+		t.Error("expected an error for a rejected recipient")
+	}
+
+	// Disabled services and empty recipient lists send nothing
+	disabled := NewService(Config{Host: sink.Host(), Port: sink.Port(), From: "forms@example.com"})
+	if err := disabled.SendAlert([]string{"owner@example.com"}, "Contact", "Failing", ""); err != nil {
+		t.Errorf("disabled: %v", err)
+	}
+	if err := s.SendAlert(nil, "Contact", "Failing", ""); err != nil {
+		t.Errorf("no recipients: %v", err)
+	}
+	if msgs := sink.Messages(); len(msgs) != 0 {
+		t.Errorf("expected no messages, got %d", len(msgs))
+	}
+}
+
+func TestEmailQueue(t *testing.T) {
+	ctx := context.Background()
+	store, err := sqlite.New(":memory:")
+	if err != nil {
+		t.Fatalf("create store: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	sink := testharness.NewSMTPSink(t)
+	sink.Reject("gone@example.com")
+	s := newTestService(sink)
+	s.SetQueue(store.EmailQueue())
+	s.maxAttempts = 2
+	s.backoff = 0
+
+	// Queued emails aren't sent until the worker runs, and failures aren't reported to the sender
+	if err := s.SendPasswordReset("user@example.com", "https://forms.example.com/reset?token=x"); err != nil {
+		t.Fatalf("queue: %v", err)
+	}
+	if err := s.SendPasswordReset("gone@example.com", "https://forms.example.com/reset?token=y"); err != nil {
+		t.Fatalf("queue rejected: %v", err)
+	}
+	if msgs := sink.Messages(); len(msgs) != 0 {
+		t.Fatalf("expected nothing sent before the worker runs, got %d", len(msgs))
+	}
+
+	if sent, failed, err := s.ProcessQueue(ctx); err != nil || sent != 1 || failed != 1 {
+		t.Fatalf("first run: sent %d, failed %d, %v", sent, failed, err)
+	}
+	if msgs := sink.Messages(); len(msgs) != 1 || msgs[0].To[0] != "user@example.com" {
+		t.Fatalf("expected the deliverable email sent, got %v", msgs)
+	}
+	pending, _, _ := store.EmailQueue().List(ctx, domain.EmailPending, 10, 0)
+	if len(pending) != 1 || pending[0].Attempts != 1 || pending[0].LastError == "" {
+		t.Fatalf("expected the rejected email pending a retry with its error, got %+v", pending)
+	}
+
+	// The last attempt fails too, so the email is dead and not retried again
+	if sent, failed, err := s.ProcessQueue(ctx); err != nil || sent != 0 || failed != 1 {
+		t.Fatalf("second run: sent %d, failed %d, %v", sent, failed, err)
+	}
+	dead, _, _ := store.EmailQueue().List(ctx, domain.EmailDead, 10, 0)
+	if len(dead) != 1 || dead[0].Attempts != 2 || dead[0].To[0] != "gone@example.com" {
+		t.Fatalf("expected the rejected email dead after 2 attempts, got %+v", dead)
+	}
+	if sent, failed, _ := s.ProcessQueue(ctx); sent != 0 || failed != 0 {
+		t.Errorf("expected nothing left to send, got sent %d, failed %d", sent, failed)
+	}
+
+	sent, _, _ := store.EmailQueue().List(ctx, domain.EmailSent, 10, 0)
+	if len(sent) != 1 || sent[0].SentAt == nil || sent[0].Attempts != 1 {
+		t.Errorf("expected the sent email recorded, got %+v", sent)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	s := NewService(Config{})
+	for attempts, want := range map[int]time.Duration{1: time.Minute, 2: 2 * time.Minute, 4: 8 * time.Minute, 7: time.Hour, 30: time.Hour} {
+		if got := s.retryDelay(attempts); got != want {
+			t.Errorf("after %d attempts: expected %v, got %v", attempts, want, got)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package email
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"headless_form/internal/adapter/transform"
+)
+
+// maxRenderSize caps a rendered template part
+const maxRenderSize = 256 * 1024
+
+// errRenderTooLarge aborts rendering a part over maxRenderSize
+var errRenderTooLarge = errors.New("email template output exceeds 256KB")
+
+// Template is a form's own submission notification. Parts are Go templates over
+// TemplateData; empty parts use the built-in ones. They're checked like webhook
+// transform scripts, and have the same helpers.
+type Template struct {
+	Subject string
+	HTML    string
+	Text    string
+}
+
+// TemplateData is what custom templates see, e.g. {{.Fields.email}} or
+// {{range $name, $value := .Fields}}
+type TemplateData struct {
+	FormName     string
+	FormID       string
+	SubmissionID string
+	SubmittedAt  time.Time
+	Fields       map[string]interface{}
+	Labels       map[string]string
+	DashboardURL string
+	Test         bool
+}
+
+// compiledTemplate holds the parsed parts of a Template; nil parts use the built-in ones
+type compiledTemplate struct {
+	subject *texttemplate.Template
+	html    *htmltemplate.Template
+	text    *texttemplate.Template
+}
+
+// ValidateTemplate checks that a template's parts parse and pass the transform checks
+func ValidateTemplate(t Template) error {
+	_, err := compileTemplate(t)
+	return err
+}
+
+func compileTemplate(t Template) (*compiledTemplate, error) {
+	c := &compiledTemplate{}
+	var err error
+	if t.Subject != "" {
+		if c.subject, err = parseText("subject", t.Subject); err != nil {
+			return nil, err
+		}
+	}
+	if t.Text != "" {
+		if c.text, err = parseText("text", t.Text); err != nil {
+			return nil, err
+		}
+	}
+	if t.HTML != "" {
+		c.html, err = htmltemplate.New("html").Option("missingkey=zero").Funcs(transform.Funcs()).Parse(t.HTML)
+		if err != nil {
+			return nil, fmt.Errorf("email template html: %w", err)
+		}
+		if len(c.html.Templates()) > 1 {
+			return nil, fmt.Errorf("email template html: %w", transform.ErrNestedTemplate)
+		}
+		if err := transform.CheckTree(c.html.Tree); err != nil {
+			return nil, fmt.Errorf("email template html: %w", err)
+		}
+	}
+	return c, nil
+}
+
+func parseText(part, source string) (*texttemplate.Template, error) {
+	tmpl, err := texttemplate.New(part).Option("missingkey=zero").Funcs(transform.Funcs()).Parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("email template %s: %w", part, err)
+	}
+	if len(tmpl.Templates()) > 1 {
+		return nil, fmt.Errorf("email template %s: %w", part, transform.ErrNestedTemplate)
+	}
+	if err := transform.CheckTree(tmpl.Tree); err != nil {
+		return nil, fmt.Errorf("email template %s: %w", part, err)
+	}
+	return tmpl, nil
+}
+
+// render executes one part with its output capped, returning "" when it fails
+func render(execute func(*limitedWriter) error) (string, error) {
+	w := &limitedWriter{}
+	if err := execute(w); err != nil {
+		return "", err
+	}
+	return w.buf.String(), nil
+}
+
+// limitedWriter fails writes past maxRenderSize, aborting template execution
+type limitedWriter struct {
+	buf bytes.Buffer
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	if w.buf.Len()+len(p) > maxRenderSize {
+		return 0, errRenderTooLarge
+	}
+	return w.buf.Write(p)
+}
+
+// renderCustom renders the form's template parts over data, leaving the parts it
+// doesn't define, or that fail to render, empty
+func (c *compiledTemplate) renderCustom(data SubmissionData) (subject, html, text string) {
+	view := TemplateData{
+		FormName:     data.FormName,
+		FormID:       data.FormID,
+		SubmissionID: data.SubmissionID,
+		SubmittedAt:  data.SubmittedAt,
+		Fields:       data.Fields,
+		Labels:       data.Labels,
+		DashboardURL: data.DashboardURL,
+		Test:         data.Test,
+	}
+	var err error
+	if c.subject != nil {
+		if subject, err = render(func(w *limitedWriter) error { return c.subject.Execute(w, view) }); err != nil {
+			log.Warn("custom email subject failed, using the default", "form", data.FormID, "error", err)
+		}
+		// Headers are a single line
+		subject = strings.Join(strings.Fields(subject), " ")
+	}
+	if c.html != nil {
+		if html, err = render(func(w *limitedWriter) error { return c.html.Execute(w, view) }); err != nil {
+			log.Warn("custom email HTML failed, using the default", "form", data.FormID, "error", err)
+		}
+	}
+	if c.text != nil {
+		if text, err = render(func(w *limitedWriter) error { return c.text.Execute(w, view) }); err != nil {
+			log.Warn("custom email text failed, using the default", "form", data.FormID, "error", err)
+		}
+	}
+	return subject, html, text
+}
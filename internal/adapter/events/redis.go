@@ -0,0 +1,362 @@
+// Package events shares real-time events between replicas over Redis pub/sub
+package events
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultChannel is the Redis channel events are published on
+const DefaultChannel = "headlessforms:events"
+
+const (
+	dialTimeout    = 5 * time.Second
+	commandTimeout = 5 * time.Second
+	maxRetryDelay  = 30 * time.Second
+)
+
+// Redis is an event bus over Redis pub/sub. Every topic shares one channel, each
+// message being the topic, a newline and the payload, so replicas only subscribe
+// once. A replica receives its own events back through Redis like everyone else's.
+type Redis struct {
+	addr     string
+	username string
+	password string
+	tls      *tls.Config
+	channel  string
+
+	retryDelay time.Duration // First wait before resubscribing after a lost connection
+
+	pubMu sync.Mutex
+	pub   *respConn // Connection for PUBLISH, dialed on first use
+
+	mu      sync.Mutex
+	subs    map[string]map[chan []byte]struct{}
+	subConn net.Conn // Current subscriber connection
+	closed  bool
+	done    chan struct{}
+}
+
+// NewRedis connects to the server at rawURL (redis://[user:password@]host:port, or
+// rediss:// for TLS) and starts listening on channel (DefaultChannel if empty).
+// Connection errors are logged and retried in the background.
+func NewRedis(rawURL, channel string) (*Redis, error) {
+	r, err := newRedis(rawURL, channel)
+	if err != nil {
+		return nil, err
+	}
+	go r.listen()
+	return r, nil
+}
+
+// newRedis sets up the bus without connecting
+func newRedis(rawURL, channel string) (*Redis, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis URL: %w", err)
+	}
+	if u.Scheme != "redis" && u.Scheme != "rediss" {
+		return nil, fmt.Errorf("redis URL must start with redis:// or rediss://")
+	}
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("redis URL has no host")
+	}
+	if channel == "" {
+		channel = DefaultChannel
+	}
+	port := u.Port()
+	if port == "" {
+		port = "6379"
+	}
+
+	r := &Redis{
+		addr:       net.JoinHostPort(u.Hostname(), port),
+		channel:    channel,
+		retryDelay: time.Second,
+		subs:       make(map[string]map[chan []byte]struct{}),
+		done:       make(chan struct{}),
+	}
+	if u.User != nil {
+		r.username = u.User.Username()
+		r.password, _ = u.User.Password()
+		if r.password == "" {
+			// redis://secret@host is a password without a user
+			r.username, r.password = "", r.username
+		}
+	}
+	if u.Scheme == "rediss" {
+		r.tls = &tls.Config{ServerName: u.Hostname(), MinVersion: tls.VersionTLS12}
+	}
+	return r, nil
+}
+
+// Publish sends an event to every replica's subscribers of topic
+func (r *Redis) Publish(ctx context.Context, topic string, payload []byte) error {
+	msg := topic + "\n" + string(payload)
+
+	r.pubMu.Lock()
+	defer r.pubMu.Unlock()
+	// A connection dropped since the last publish is redialed once
+	for attempt := 0; ; attempt++ {
+		if r.pub == nil {
+			c, err := r.dial(ctx)
+			if err != nil {
+				return err
+			}
+			r.pub = c
+		}
+		_ = r.pub.conn.SetDeadline(time.Now().Add(commandTimeout))
+		_, err := r.pub.do("PUBLISH", r.channel, msg)
+		if err == nil {
+			return nil
+		}
+		var replyErr redisError
+		if errors.As(err, &replyErr) {
+			return err // The server answered, so the connection is fine
+		}
+		_ = r.pub.conn.Close()
+		r.pub = nil
+		if attempt > 0 {
+			return fmt.Errorf("redis publish: %w", err)
+		}
+	}
+}
+
+// Subscribe receives topic's events until unsubscribe is called. The channel isn't
+// closed; events are dropped while it's full. Payloads are shared between
+// subscribers and must not be modified.
+func (r *Redis) Subscribe(topic string) (<-chan []byte, func()) {
+	ch := make(chan []byte, 16)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.subs[topic] == nil {
+		r.subs[topic] = make(map[chan []byte]struct{})
+	}
+	r.subs[topic][ch] = struct{}{}
+
+	return ch, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		delete(r.subs[topic], ch)
+		if len(r.subs[topic]) == 0 {
+			delete(r.subs, topic)
+		}
+	}
+}
+
+// Close stops listening and closes the connections
+func (r *Redis) Close() error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil
+	}
+	r.closed = true
+	close(r.done)
+	if r.subConn != nil {
+		_ = r.subConn.Close()
+	}
+	r.mu.Unlock()
+
+	r.pubMu.Lock()
+	defer r.pubMu.Unlock()
+	if r.pub != nil {
+		_ = r.pub.conn.Close()
+		r.pub = nil
+	}
+	return nil
+}
+
+// listen keeps a subscription to the channel open, resubscribing with a growing delay
+// when the connection is lost. Events published meanwhile are missed.
+func (r *Redis) listen() {
+	delay := r.retryDelay
+	for {
+		subscribed, err := r.subscribe()
+		select {
+		case <-r.done:
+			return
+		default:
+		}
+		if subscribed {
+			delay = r.retryDelay
+		}
+		log.Printf("[EVENTS] Redis subscription lost: %v (retrying in %s)", err, delay)
+		select {
+		case <-r.done:
+			return
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, maxRetryDelay)
+	}
+}
+
+// subscribe subscribes to the channel and delivers its messages until the connection fails
+func (r *Redis) subscribe() (subscribed bool, err error) {
+	c, err := r.dial(context.Background())
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = c.conn.Close() }()
+
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return false, nil
+	}
+	r.subConn = c.conn
+	r.mu.Unlock()
+
+	if err := c.send("SUBSCRIBE", r.channel); err != nil {
+		return false, err
+	}
+	for {
+		reply, err := c.read()
+		if err != nil {
+			return subscribed, err
+		}
+		msg, _ := reply.([]interface{})
+		if len(msg) < 3 {
+			continue
+		}
+		kind, _ := msg[0].([]byte)
+		switch string(kind) {
+		case "subscribe":
+			subscribed = true
+		case "message":
+			data, _ := msg[2].([]byte)
+			r.deliver(data)
+		}
+	}
+}
+
+// deliver hands a "<topic>\n<payload>" message to the topic's subscribers
+func (r *Redis) deliver(msg []byte) {
+	topic, payload, ok := bytes.Cut(msg, []byte("\n"))
+	if !ok {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for ch := range r.subs[string(topic)] {
+		select {
+		case ch <- payload:
+		default: // Slow subscriber
+		}
+	}
+}
+
+// dial opens an authenticated connection
+func (r *Redis) dial(ctx context.Context) (*respConn, error) {
+	dialer := &net.Dialer{Timeout: dialTimeout}
+	var conn net.Conn
+	var err error
+	if r.tls != nil {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: r.tls}).DialContext(ctx, "tcp", r.addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", r.addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("connect to redis: %w", err)
+	}
+
+	c := &respConn{conn: conn, r: bufio.NewReader(conn)}
+	if r.password != "" {
+		args := []string{"AUTH", r.password}
+		if r.username != "" {
+			args = []string{"AUTH", r.username, r.password}
+		}
+		_ = conn.SetDeadline(time.Now().Add(commandTimeout))
+		if _, err := c.do(args...); err != nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("redis auth: %w", err)
+		}
+		_ = conn.SetDeadline(time.Time{})
+	}
+	return c, nil
+}
+
+// redisError is an error reply from the server
+type redisError string
+
+func (e redisError) Error() string {
+	return "redis: " + string(e)
+}
+
+// respConn speaks RESP2, the Redis protocol, on a connection
+type respConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+func (c *respConn) send(args ...string) error {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	_, err := c.conn.Write(b.Bytes())
+	return err
+}
+
+func (c *respConn) do(args ...string) (interface{}, error) {
+	if err := c.send(args...); err != nil {
+		return nil, err
+	}
+	return c.read()
+}
+
+// read parses a reply: a string, int64, []byte, []interface{}, nil or a redisError
+func (c *respConn) read() (interface{}, error) {
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, errors.New("redis: malformed reply")
+	}
+	body := line[1 : len(line)-2]
+	switch line[0] {
+	case '+':
+		return body, nil
+	case '-':
+		return nil, redisError(body)
+	case ':':
+		return strconv.ParseInt(body, 10, 64)
+	case '$':
+		n, err := strconv.Atoi(body)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(c.r, buf); err != nil {
+			return nil, err
+		}
+		return buf[:n], nil
+	case '*':
+		n, err := strconv.Atoi(body)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		items := make([]interface{}, n)
+		for i := range items {
+			if items[i], err = c.read(); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("redis: unexpected reply %q", line[0])
+}
@@ -0,0 +1,48 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+)
+
+// csvExporter writes one line per submission; nested values are flattened to JSON text
+type csvExporter struct {
+	w      *csv.Writer
+	fields []string
+}
+
+// NewCSV returns an Exporter writing CSV. Output is buffered and sent in chunks.
+func NewCSV(w io.Writer) Exporter {
+	return &csvExporter{w: csv.NewWriter(w)}
+}
+
+func (e *csvExporter) WriteHeader(fields []string) error {
+	e.fields = fields
+	return e.w.Write(append(append([]string{}, MetaColumns...), fields...))
+}
+
+func (e *csvExporter) WriteRow(row Row) error {
+	record := make([]string, 0, len(MetaColumns)+len(e.fields))
+	for _, v := range row.metaValues() {
+		record = append(record, FieldText(v))
+	}
+	for _, f := range e.fields {
+		record = append(record, FieldText(row.Data[f]))
+	}
+	return e.w.Write(record)
+}
+
+func (e *csvExporter) Close() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// SafeCell keeps spreadsheet apps from running a text cell as a formula: text starting
+// with =, +, -, @, a tab or a carriage return gets a leading apostrophe
+func SafeCell(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
@@ -0,0 +1,135 @@
+// Package export writes form submissions as downloadable files: CSV, JSON and Excel
+// (xlsx). Every format streams rows as they're written, so large forms don't have to
+// fit in memory.
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+
+	"headless_form/internal/core/domain"
+)
+
+// MetaColumns start every tabular export, before the submitted fields
+var MetaColumns = []string{"id", "created_at", "status", "ip", "country", "spam_score", "is_spam", "consent_version", "consent_accepted_at"}
+
+// Exporter writes a form's submissions in one file format
+type Exporter interface {
+	// WriteHeader starts the file. fields are the submitted field columns, in order.
+	WriteHeader(fields []string) error
+	// WriteRow writes one submission
+	WriteRow(row Row) error
+	// Close finishes the file; it doesn't close the underlying writer
+	Close() error
+}
+
+// Format is an export file format
+type Format struct {
+	Name        string // Used in the export URL, e.g. /export/xlsx
+	ContentType string
+	Extension   string
+	// Tabular formats have one column per field, so the fields must be known before the
+	// first row; the others write each submission's data as is
+	Tabular bool
+	New     func(w io.Writer) Exporter
+}
+
+// Formats are the available export formats
+var Formats = []Format{
+	{Name: "csv", ContentType: "text/csv; charset=utf-8", Extension: "csv", Tabular: true, New: NewCSV},
+	{Name: "json", ContentType: "application/json", Extension: "json", New: NewJSON},
+	{Name: "xlsx", ContentType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", Extension: "xlsx", Tabular: true, New: NewXLSX},
+}
+
+// Lookup returns the format called name
+func Lookup(name string) (Format, bool) {
+	for _, f := range Formats {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return Format{}, false
+}
+
+// Row is one exported submission. Timestamps are already formatted the way the export
+// was asked for; Data keeps the submitted values with their nesting and number text.
+type Row struct {
+	ID                string                 `json:"id"`
+	CreatedAt         string                 `json:"created_at"`
+	Status            string                 `json:"status"`
+	IP                string                 `json:"ip,omitempty"`
+	Country           string                 `json:"country,omitempty"`
+	SpamScore         *int                   `json:"spam_score,omitempty"`
+	IsSpam            *bool                  `json:"is_spam,omitempty"`
+	ConsentVersion    string                 `json:"consent_version,omitempty"`
+	ConsentAcceptedAt string                 `json:"consent_accepted_at,omitempty"`
+	Data              map[string]interface{} `json:"data"`
+}
+
+// NewRow prepares a submission for export, formatting its timestamps with formatTime
+func NewRow(sub *domain.Submission, formatTime func(time.Time) string) Row {
+	row := Row{
+		ID:        sub.ID,
+		CreatedAt: formatTime(sub.CreatedAt),
+		Status:    string(sub.Status),
+		IP:        sub.Meta.Server.IP,
+		Country:   sub.Meta.Server.Country,
+	}
+	if sub.Meta.Server.Anonymized {
+		row.IP = "anonymized"
+	}
+	if sub.Meta.Spam != nil {
+		row.SpamScore = &sub.Meta.Spam.Score
+		row.IsSpam = &sub.Meta.Spam.IsSpam
+	}
+	if sub.Meta.Consent != nil {
+		row.ConsentVersion = sub.Meta.Consent.Version
+		row.ConsentAcceptedAt = formatTime(sub.Meta.Consent.AcceptedAt)
+	}
+
+	// Numbers stay json.Number so large integers keep their digits
+	dec := json.NewDecoder(bytes.NewReader(sub.Data))
+	dec.UseNumber()
+	_ = dec.Decode(&row.Data)
+	return row
+}
+
+// metaValues returns the row's MetaColumns values: strings, or int and bool where set
+func (r Row) metaValues() []interface{} {
+	values := []interface{}{r.ID, r.CreatedAt, r.Status, r.IP, r.Country, "", "", r.ConsentVersion, r.ConsentAcceptedAt}
+	if r.SpamScore != nil {
+		values[5] = *r.SpamScore
+	}
+	if r.IsSpam != nil {
+		values[6] = *r.IsSpam
+	}
+	return values
+}
+
+// FieldText formats a field value as text: strings and numbers as they are, booleans as
+// true/false, lists and objects as JSON, and missing values as ""
+func FieldText(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case json.Number:
+		return t.String()
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case int:
+		return strconv.Itoa(t)
+	case bool:
+		return strconv.FormatBool(t)
+	default:
+		// JSON encode complex types
+		if b, err := json.Marshal(v); err == nil {
+			return string(b)
+		}
+		return ""
+	}
+}
@@ -0,0 +1,169 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"headless_form/internal/core/domain"
+)
+
+func testRows() []Row {
+	created := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	format := func(t time.Time) string { return t.Format(time.RFC3339) }
+	return []Row{
+		NewRow(&domain.Submission{ID: "sub-1", Status: domain.SubmissionStatusRead, CreatedAt: created,
+			Data: []byte(`{"name":"Doe, Jane","seats":3,"phone":12345678901234567890,"beta":true,"address":{"city":"Berlin"}}`),
+			Meta: domain.SubmissionMeta{Spam: &domain.SpamMeta{Score: 20}}}, format),
+		NewRow(&domain.Submission{ID: "sub-2", Status: domain.SubmissionStatusUnread, CreatedAt: created,
+			Data: []byte(`{"name":"Said \"hi\"\n<then> left"}`)}, format),
+	}
+}
+
+func write(t *testing.T, f Format, fields []string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	e := f.New(&buf)
+	if err := e.WriteHeader(fields); err != nil {
+		t.Fatalf("header: %v", err)
+	}
+	for _, row := range testRows() {
+		if err := e.WriteRow(row); err != nil {
+			t.Fatalf("row: %v", err)
+		}
+	}
+	if err := e.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestCSV(t *testing.T) {
+	f, _ := Lookup("csv")
+	records, err := csv.NewReader(bytes.NewReader(write(t, f, []string{"address", "name", "phone"}))).ReadAll()
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(records) != 3 || strings.Join(records[0][len(MetaColumns):], ",") != "address,name,phone" {
+		t.Fatalf("unexpected header: %v", records)
+	}
+	want := []string{"sub-1", "2026-03-10T12:00:00Z", "read", "", "", "20", "false", "", "", `{"city":"Berlin"}`, "Doe, Jane", "12345678901234567890"}
+	if strings.Join(records[1], "|") != strings.Join(want, "|") {
+		t.Errorf("expected %q, got %q", want, records[1])
+	}
+	if records[2][10] != "Said \"hi\"\n<then> left" || records[2][5] != "" {
+		t.Errorf("unexpected second row: %q", records[2])
+	}
+}
+
+func TestSafeCell(t *testing.T) {
+	for in, want := range map[string]string{
+		"":                  "",
+		"example.com":       "example.com",
+		"=HYPERLINK(\"x\")": "'=HYPERLINK(\"x\")",
+		"+1":                "'+1",
+		"-1":                "'-1",
+		"@SUM(A1)":          "'@SUM(A1)",
+		"\tcmd":             "'\tcmd",
+	} {
+		if got := SafeCell(in); got != want {
+			t.Errorf("SafeCell(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestJSON(t *testing.T) {
+	f, _ := Lookup("json")
+	var rows []map[string]interface{}
+	out := write(t, f, nil)
+	if err := json.Unmarshal(out, &rows); err != nil {
+		t.Fatalf("parse: %v\n%s", err, out)
+	}
+	if len(rows) != 2 || rows[0]["id"] != "sub-1" || rows[0]["spam_score"] != float64(20) || rows[1]["spam_score"] != nil {
+		t.Fatalf("unexpected rows: %v", rows)
+	}
+	data := rows[0]["data"].(map[string]interface{})
+	if data["address"].(map[string]interface{})["city"] != "Berlin" || !strings.Contains(string(out), `"phone":12345678901234567890`) {
+		t.Errorf("expected nested values and exact numbers, got %s", out)
+	}
+
+	// No submissions is still an array
+	var buf bytes.Buffer
+	e := NewJSON(&buf)
+	_ = e.WriteHeader(nil)
+	_ = e.Close()
+	if strings.TrimSpace(buf.String()) != "[]" {
+		t.Errorf("expected empty array, got %q", buf.String())
+	}
+}
+
+// sheetCell is a cell of a parsed worksheet
+type sheetCell struct {
+	Type   string `xml:"t,attr"`
+	Style  string `xml:"s,attr"`
+	Value  string `xml:"v"`
+	Inline string `xml:"is>t"`
+}
+
+func TestXLSX(t *testing.T) {
+	f, _ := Lookup("xlsx")
+	out := write(t, f, []string{"address", "beta", "name", "phone", "seats"})
+	zr, err := zip.NewReader(bytes.NewReader(out), int64(len(out)))
+	if err != nil {
+		t.Fatalf("open workbook: %v", err)
+	}
+	parts := make(map[string][]byte)
+	for _, file := range zr.File {
+		rc, _ := file.Open()
+		parts[file.Name], _ = io.ReadAll(rc)
+		_ = rc.Close()
+	}
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/styles.xml"} {
+		if err := xml.Unmarshal(parts[name], new(struct{})); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+
+	var sheet struct {
+		Rows []struct {
+			R     string      `xml:"r,attr"`
+			Cells []sheetCell `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	if err := xml.Unmarshal(parts["xl/worksheets/sheet1.xml"], &sheet); err != nil {
+		t.Fatalf("parse sheet: %v", err)
+	}
+	if len(sheet.Rows) != 3 || sheet.Rows[2].R != "3" {
+		t.Fatalf("expected header and 2 rows, got %+v", sheet.Rows)
+	}
+	header, first, second := sheet.Rows[0].Cells, sheet.Rows[1].Cells, sheet.Rows[2].Cells
+	if header[0].Inline != "id" || header[0].Style != "1" || header[len(header)-1].Inline != "seats" {
+		t.Errorf("unexpected header: %+v", header)
+	}
+	n := len(MetaColumns)
+	cases := []struct {
+		name string
+		got  sheetCell
+		want sheetCell
+	}{
+		{"spam_score", first[5], sheetCell{Value: "20"}},
+		{"is_spam", first[6], sheetCell{Type: "b", Value: "0"}},
+		{"nested", first[n], sheetCell{Type: "inlineStr", Inline: `{"city":"Berlin"}`}},
+		{"boolean", first[n+1], sheetCell{Type: "b", Value: "1"}},
+		{"long number as text", first[n+3], sheetCell{Type: "inlineStr", Inline: "12345678901234567890"}},
+		{"number", first[n+4], sheetCell{Value: "3"}},
+		{"escaped text", second[n+2], sheetCell{Type: "inlineStr", Inline: "Said \"hi\"\n<then> left"}},
+		{"missing", second[n+4], sheetCell{}},
+	}
+	for _, tc := range cases {
+		if tc.got != tc.want {
+			t.Errorf("%s: expected %+v, got %+v", tc.name, tc.want, tc.got)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+)
+
+// jsonExporter writes an array with one object per submission, keeping nested values
+type jsonExporter struct {
+	w    *bufio.Writer
+	buf  bytes.Buffer
+	enc  *json.Encoder
+	rows int
+}
+
+// NewJSON returns an Exporter writing a JSON array of submissions, one per line
+func NewJSON(w io.Writer) Exporter {
+	e := &jsonExporter{w: bufio.NewWriter(w)}
+	e.enc = json.NewEncoder(&e.buf)
+	e.enc.SetEscapeHTML(false)
+	return e
+}
+
+// WriteHeader opens the array; the fields aren't needed since each object has its own
+func (e *jsonExporter) WriteHeader(fields []string) error {
+	_, err := e.w.WriteString("[")
+	return err
+}
+
+func (e *jsonExporter) WriteRow(row Row) error {
+	if row.Data == nil {
+		row.Data = map[string]interface{}{}
+	}
+	e.buf.Reset()
+	if err := e.enc.Encode(row); err != nil {
+		return err
+	}
+	sep := ",\n"
+	if e.rows == 0 {
+		sep = "\n"
+	}
+	e.rows++
+	if _, err := e.w.WriteString(sep); err != nil {
+		return err
+	}
+	_, err := e.w.Write(bytes.TrimSuffix(e.buf.Bytes(), []byte("\n")))
+	return err
+}
+
+func (e *jsonExporter) Close() error {
+	end := "]\n"
+	if e.rows > 0 {
+		end = "\n]\n"
+	}
+	if _, err := e.w.WriteString(end); err != nil {
+		return err
+	}
+	return e.w.Flush()
+}
@@ -0,0 +1,167 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Excel's limits
+const (
+	MaxXLSXRows    = 1 << 20 // Rows per sheet, the header included
+	maxCellText    = 32767   // Characters per cell; longer text is cut
+	maxExactDigits = 15      // Longer numbers are written as text so no digits are lost
+)
+
+// ErrTooManyRows is returned once a sheet is full
+var ErrTooManyRows = errors.New("export: more rows than an Excel sheet holds")
+
+// Static parts of the workbook. The sheet uses inline strings rather than a shared
+// string table, so rows can be written as they come.
+var xlsxParts = []struct{ name, body string }{
+	{"[Content_Types].xml", xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`},
+	{"xl/workbook.xml", xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="Submissions" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+	{"xl/_rels/workbook.xml.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+		`</Relationships>`},
+	// Style 1 is the bold header
+	{"xl/styles.xml", xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+		`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+		`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+		`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+		`<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/><xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs>` +
+		`</styleSheet>`},
+}
+
+// xlsxExporter writes a single-sheet workbook with a frozen header row. Numbers and
+// booleans get typed cells; everything else is text.
+type xlsxExporter struct {
+	zw     *zip.Writer
+	sheet  *bufio.Writer
+	fields []string
+	rows   int
+}
+
+// NewXLSX returns an Exporter writing an Excel workbook
+func NewXLSX(w io.Writer) Exporter {
+	return &xlsxExporter{zw: zip.NewWriter(w)}
+}
+
+func (e *xlsxExporter) WriteHeader(fields []string) error {
+	for _, part := range xlsxParts {
+		f, err := e.zw.CreateHeader(&zip.FileHeader{Name: part.name, Method: zip.Deflate})
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, part.body); err != nil {
+			return err
+		}
+	}
+	f, err := e.zw.CreateHeader(&zip.FileHeader{Name: "xl/worksheets/sheet1.xml", Method: zip.Deflate})
+	if err != nil {
+		return err
+	}
+	e.sheet = bufio.NewWriter(f)
+	e.fields = fields
+	_, _ = e.sheet.WriteString(xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>` +
+		`<sheetData>`)
+
+	header := make([]interface{}, 0, len(MetaColumns)+len(fields))
+	for _, c := range MetaColumns {
+		header = append(header, c)
+	}
+	for _, f := range fields {
+		header = append(header, f)
+	}
+	return e.writeRow(header, ` s="1"`)
+}
+
+func (e *xlsxExporter) WriteRow(row Row) error {
+	values := row.metaValues()
+	for _, f := range e.fields {
+		values = append(values, row.Data[f])
+	}
+	return e.writeRow(values, "")
+}
+
+// writeRow writes one <row>, giving every cell the style attribute style
+func (e *xlsxExporter) writeRow(values []interface{}, style string) error {
+	if e.rows == MaxXLSXRows {
+		return ErrTooManyRows
+	}
+	e.rows++
+	w := e.sheet
+	_, _ = w.WriteString(`<row r="` + strconv.Itoa(e.rows) + `">`)
+	for _, v := range values {
+		switch t := v.(type) {
+		case nil:
+			_, _ = w.WriteString(`<c` + style + `/>`)
+		case int:
+			_, _ = w.WriteString(`<c` + style + `><v>` + strconv.Itoa(t) + `</v></c>`)
+		case json.Number:
+			if digits := strings.TrimLeft(t.String(), "-0"); len(digits) > maxExactDigits {
+				writeText(w, style, t.String())
+				continue
+			}
+			_, _ = w.WriteString(`<c` + style + `><v>` + t.String() + `</v></c>`)
+		case bool:
+			b := "0"
+			if t {
+				b = "1"
+			}
+			_, _ = w.WriteString(`<c` + style + ` t="b"><v>` + b + `</v></c>`)
+		default:
+			writeText(w, style, FieldText(v))
+		}
+	}
+	_, err := w.WriteString(`</row>`)
+	return err
+}
+
+// writeText writes an inline string cell, cut to Excel's cell limit
+func writeText(w *bufio.Writer, style, s string) {
+	if s == "" {
+		_, _ = w.WriteString(`<c` + style + `/>`)
+		return
+	}
+	if utf8.RuneCountInString(s) > maxCellText {
+		s = string([]rune(s)[:maxCellText])
+	}
+	_, _ = w.WriteString(`<c` + style + ` t="inlineStr"><is><t xml:space="preserve">`)
+	_ = xml.EscapeText(w, []byte(s))
+	_, _ = w.WriteString(`</t></is></c>`)
+}
+
+func (e *xlsxExporter) Close() error {
+	if e.sheet == nil {
+		if err := e.WriteHeader(nil); err != nil {
+			return err
+		}
+	}
+	if _, err := e.sheet.WriteString(`</sheetData></worksheet>`); err != nil {
+		return err
+	}
+	if err := e.sheet.Flush(); err != nil {
+		return err
+	}
+	return e.zw.Close()
+}
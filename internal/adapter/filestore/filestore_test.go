@@ -0,0 +1,118 @@
+package filestore
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"headless_form/internal/core/domain"
+	"headless_form/internal/core/ports"
+)
+
+// roundTrip stores, reads and deletes a file through store
+func roundTrip(t *testing.T, store ports.FileStorage) {
+	t.Helper()
+	ctx := context.Background()
+
+	if err := store.Put(ctx, "frm_1/fil_1", strings.NewReader("hello"), 5, "text/plain"); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	r, err := store.Open(ctx, "frm_1/fil_1")
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	body, _ := io.ReadAll(r)
+	_ = r.Close()
+	if string(body) != "hello" {
+		t.Errorf("expected hello, got %q", body)
+	}
+
+	if err := store.Delete(ctx, "frm_1/fil_1"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if err := store.Delete(ctx, "frm_1/fil_1"); err != nil {
+		t.Errorf("deleting a missing file should be a no-op, got %v", err)
+	}
+	if _, err := store.Open(ctx, "frm_1/fil_1"); !errors.Is(err, domain.ErrFileNotFound) {
+		t.Errorf("expected ErrFileNotFound, got %v", err)
+	}
+}
+
+func TestLocal(t *testing.T) {
+	store := NewLocal(t.TempDir())
+	roundTrip(t, store)
+
+	for _, key := range []string{"../escape", "frm_1/../../escape", "/abs", "frm_1//x", ""} {
+		if err := store.Put(context.Background(), key, strings.NewReader("x"), 1, ""); err == nil {
+			t.Errorf("expected key %q to be rejected", key)
+		}
+	}
+}
+
+// fakeS3 is a minimal in-memory object store
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string]string
+	auth    []string
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.auth = append(f.auth, r.Header.Get("Authorization"))
+	switch r.Method {
+	case http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		f.objects[r.URL.Path] = string(body)
+	case http.MethodGet:
+		body, ok := f.objects[r.URL.Path]
+		if !ok {
+			http.Error(w, "<Error><Code>NoSuchKey</Code></Error>", http.StatusNotFound)
+			return
+		}
+		_, _ = io.WriteString(w, body)
+	case http.MethodDelete:
+		delete(f.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func TestS3(t *testing.T) {
+	fake := &fakeS3{objects: make(map[string]string)}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	store, err := NewS3(S3Config{Endpoint: srv.URL, Region: "eu-west-1", Bucket: "uploads", AccessKey: "AKID", SecretKey: "secret", PathStyle: true})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	if err := store.Put(context.Background(), "frm_1/fil_1", strings.NewReader("hello"), 5, "text/plain"); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	if _, ok := fake.objects["/uploads/frm_1/fil_1"]; !ok {
+		t.Fatalf("expected a path-style object, got %v", fake.objects)
+	}
+	if auth := fake.auth[0]; !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/") || !strings.Contains(auth, "/eu-west-1/s3/aws4_request") {
+		t.Errorf("unexpected Authorization header %q", auth)
+	}
+	roundTrip(t, store)
+}
+
+func TestS3_VirtualHostURL(t *testing.T) {
+	store, err := NewS3(S3Config{Endpoint: "https://s3.eu-west-1.amazonaws.com/", Bucket: "uploads"})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	u, err := store.objectURL("frm_1/my file.pdf")
+	if err != nil {
+		t.Fatalf("url: %v", err)
+	}
+	if got := u.String(); got != "https://uploads.s3.eu-west-1.amazonaws.com/frm_1/my%20file.pdf" {
+		t.Errorf("unexpected URL %s", got)
+	}
+}
@@ -0,0 +1,92 @@
+// Package filestore keeps files uploaded with submissions, on local disk or in an
+// S3-compatible bucket (AWS S3, MinIO, Cloudflare R2, ...).
+package filestore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"headless_form/internal/core/domain"
+)
+
+// errInvalidKey is returned for keys that would escape the storage root
+var errInvalidKey = errors.New("invalid file key")
+
+// Local stores files in a directory, one file per key
+type Local struct {
+	dir string
+}
+
+// NewLocal creates a store rooted at dir, which is created on the first upload
+func NewLocal(dir string) *Local {
+	return &Local{dir: dir}
+}
+
+// path maps a key like "<form_id>/<file_id>" to a path inside the root
+func (s *Local) path(key string) (string, error) {
+	if key == "" || strings.Contains(key, "\\") {
+		return "", errInvalidKey
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return "", errInvalidKey
+		}
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
+
+func (s *Local) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("create upload dir: %w", err)
+	}
+
+	// Written next to its final name, so readers never see a partial file
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("create upload file: %w", err)
+	}
+	_, err = io.Copy(tmp, r)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("write upload file: %w", err)
+	}
+	return nil
+}
+
+func (s *Local) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path) // #nosec G304 -- path is confined to the storage root
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, domain.ErrFileNotFound
+	}
+	return f, err
+}
+
+func (s *Local) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package filestore
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"headless_form/internal/core/domain"
+)
+
+// unsignedPayload skips hashing file bodies; requests are still signed and should go over HTTPS
+const unsignedPayload = "UNSIGNED-PAYLOAD"
+
+// S3Config points at a bucket of an S3-compatible service
+type S3Config struct {
+	Endpoint  string // e.g. https://s3.eu-west-1.amazonaws.com or http://minio:9000
+	Region    string // Default us-east-1
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	PathStyle bool // Bucket in the path (MinIO and most self-hosted services) instead of the host name
+}
+
+// S3 stores files as objects, signing requests with AWS Signature Version 4
+type S3 struct {
+	cfg    S3Config
+	client *http.Client
+}
+
+// NewS3 creates a store for the bucket in cfg
+func NewS3(cfg S3Config) (*S3, error) {
+	if cfg.Endpoint == "" || cfg.Bucket == "" {
+		return nil, fmt.Errorf("S3 endpoint and bucket are required")
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	if _, err := url.Parse(cfg.Endpoint); err != nil {
+		return nil, fmt.Errorf("invalid S3 endpoint: %w", err)
+	}
+	cfg.Endpoint = strings.TrimRight(cfg.Endpoint, "/")
+	return &S3{cfg: cfg, client: &http.Client{Timeout: 5 * time.Minute}}, nil
+}
+
+// objectURL returns the URL of the object stored under key
+func (s *S3) objectURL(key string) (*url.URL, error) {
+	u, err := url.Parse(s.cfg.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	path, rawPath := "/"+key, "/"+uriEncode(key, false)
+	if s.cfg.PathStyle {
+		path, rawPath = "/"+s.cfg.Bucket+path, "/"+uriEncode(s.cfg.Bucket, true)+rawPath
+	} else {
+		u.Host = s.cfg.Bucket + "." + u.Host
+	}
+	u.Path, u.RawPath = path, rawPath
+	return u, nil
+}
+
+func (s *S3) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	resp, err := s.do(ctx, http.MethodPut, key, r, size, contentType)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode/100 != 2 {
+		return s3Error("put", resp)
+	}
+	return nil
+}
+
+func (s *S3) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	resp, err := s.do(ctx, http.MethodGet, key, nil, 0, "")
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		_ = resp.Body.Close()
+		return nil, domain.ErrFileNotFound
+	case resp.StatusCode/100 != 2:
+		defer func() { _ = resp.Body.Close() }()
+		return nil, s3Error("get", resp)
+	}
+	return resp.Body, nil
+}
+
+func (s *S3) Delete(ctx context.Context, key string) error {
+	resp, err := s.do(ctx, http.MethodDelete, key, nil, 0, "")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode/100 != 2 && resp.StatusCode != http.StatusNotFound {
+		return s3Error("delete", resp)
+	}
+	return nil
+}
+
+// do sends a signed request for the object stored under key
+func (s *S3) do(ctx context.Context, method, key string, body io.Reader, size int64, contentType string) (*http.Response, error) {
+	u, err := s.objectURL(key)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.ContentLength = size
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	s.sign(req, time.Now().UTC())
+	return s.client.Do(req)
+}
+
+// sign adds the Signature Version 4 headers covering the host, date and payload hash
+func (s *S3) sign(req *http.Request, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", unsignedPayload)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		"host:" + req.URL.Host + "\nx-amz-content-sha256:" + unsignedPayload + "\nx-amz-date:" + amzDate + "\n",
+		signedHeaders,
+		unsignedPayload,
+	}, "\n")
+
+	scope := date + "/" + s.cfg.Region + "/s3/aws4_request"
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(hash[:])
+
+	key := hmacSHA256([]byte("AWS4"+s.cfg.SecretKey), date)
+	key = hmacSHA256(key, s.cfg.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.cfg.AccessKey, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// uriEncode percent-encodes everything but unreserved characters, as Signature
+// Version 4 requires. Slashes are kept unless encodeSlash is set.
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// s3Error reports a failed request with the start of the service's error document
+func s3Error(op string, resp *http.Response) error {
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("s3 %s: %s: %s", op, resp.Status, strings.TrimSpace(string(msg)))
+}
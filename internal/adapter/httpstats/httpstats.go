@@ -0,0 +1,180 @@
+// Package httpstats counts API requests per route, with error rates and latency
+// percentiles, so operators can tell whether an endpoint is failing or slow without
+// external monitoring.
+package httpstats
+
+import (
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// latencySamples is how many recent durations are kept per route for percentiles
+const latencySamples = 1000
+
+// unmatched is the route reported for requests no route matched
+const unmatched = "unmatched"
+
+// RouteStats summarizes the requests to one route since startup. Latency percentiles
+// cover the route's most recent requests.
+type RouteStats struct {
+	Route        string  `json:"route"` // The mux pattern, e.g. "POST /api/v1/submit/{public_id}"
+	Requests     int64   `json:"requests"`
+	ClientErrors int64   `json:"client_errors"` // 4xx
+	ServerErrors int64   `json:"server_errors"` // 5xx
+	ErrorRate    float64 `json:"error_rate"`    // Share of 5xx responses, 0-1
+	AvgMS        float64 `json:"avg_ms"`
+	P50MS        float64 `json:"p50_ms"`
+	P95MS        float64 `json:"p95_ms"`
+	MaxMS        float64 `json:"max_ms"`
+}
+
+// Stats summarizes all API requests observed since startup
+type Stats struct {
+	Since        time.Time    `json:"since"`
+	Requests     int64        `json:"requests"`
+	ServerErrors int64        `json:"server_errors"`
+	Routes       []RouteStats `json:"routes"` // Most requested first
+}
+
+// Collector records request outcomes per route. It is safe for concurrent use.
+type Collector struct {
+	since time.Time
+
+	mu     sync.Mutex
+	routes map[string]*route
+}
+
+type route struct {
+	requests     int64
+	clientErrors int64
+	serverErrors int64
+	total        time.Duration
+	max          time.Duration
+	recent       []time.Duration // ring buffer, next write at recent[next]
+	next         int
+}
+
+// NewCollector creates an empty collector
+func NewCollector() *Collector {
+	return &Collector{since: time.Now().UTC(), routes: make(map[string]*route)}
+}
+
+// Middleware records the API requests next serves. next must be the ServeMux itself
+// (or pass the request on unchanged), since the route is read from the request the mux
+// matched. Streams (text/event-stream) are left out: their duration isn't latency.
+func (c *Collector) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/api/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		if strings.HasPrefix(rec.Header().Get("Content-Type"), "text/event-stream") {
+			return
+		}
+		pattern := r.Pattern
+		if pattern == "" {
+			pattern = unmatched
+		}
+		c.Observe(pattern, rec.status, time.Since(start))
+	})
+}
+
+// Observe records one request to pattern
+func (c *Collector) Observe(pattern string, status int, d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	rt := c.routes[pattern]
+	if rt == nil {
+		rt = &route{}
+		c.routes[pattern] = rt
+	}
+	rt.requests++
+	rt.total += d
+	if d > rt.max {
+		rt.max = d
+	}
+	switch {
+	case status >= 500:
+		rt.serverErrors++
+	case status >= 400:
+		rt.clientErrors++
+	}
+	if len(rt.recent) < latencySamples {
+		rt.recent = append(rt.recent, d)
+		rt.next = len(rt.recent) % latencySamples
+	} else {
+		rt.recent[rt.next] = d
+		rt.next = (rt.next + 1) % latencySamples
+	}
+}
+
+// Stats returns a snapshot of the collected metrics
+func (c *Collector) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s := Stats{Since: c.since, Routes: make([]RouteStats, 0, len(c.routes))}
+	for pattern, rt := range c.routes {
+		sorted := slices.Clone(rt.recent)
+		slices.Sort(sorted)
+		rs := RouteStats{
+			Route:        pattern,
+			Requests:     rt.requests,
+			ClientErrors: rt.clientErrors,
+			ServerErrors: rt.serverErrors,
+			ErrorRate:    float64(rt.serverErrors) / float64(rt.requests),
+			AvgMS:        ms(rt.total) / float64(rt.requests),
+			P50MS:        ms(percentile(sorted, 0.50)),
+			P95MS:        ms(percentile(sorted, 0.95)),
+			MaxMS:        ms(rt.max),
+		}
+		s.Requests += rt.requests
+		s.ServerErrors += rt.serverErrors
+		s.Routes = append(s.Routes, rs)
+	}
+	sort.Slice(s.Routes, func(i, j int) bool {
+		if s.Routes[i].Requests != s.Routes[j].Requests {
+			return s.Routes[i].Requests > s.Routes[j].Requests
+		}
+		return s.Routes[i].Route < s.Routes[j].Route
+	})
+	return s
+}
+
+// percentile returns the nearest-rank percentile p (0-1) of sorted durations
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(p*float64(len(sorted))+0.5) - 1
+	rank = max(0, min(rank, len(sorted)-1))
+	return sorted[rank]
+}
+
+// statusRecorder captures the response status
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusRecorder) WriteHeader(code int) {
+	w.status = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (w *statusRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func ms(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
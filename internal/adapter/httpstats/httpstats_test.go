@@ -0,0 +1,58 @@
+package httpstats
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMiddleware_RecordsPerRoute(t *testing.T) {
+	c := NewCollector()
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/submit/{public_id}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("public_id") == "broken" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+	mux.HandleFunc("GET /api/v1/stream", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {})
+	handler := c.Middleware(mux)
+
+	for _, path := range []string{"/api/v1/submit/a", "/api/v1/submit/b", "/api/v1/submit/c", "/api/v1/submit/broken"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, path, nil))
+	}
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/stream", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/forms", nil))
+
+	stats := c.Stats()
+	if stats.Requests != 4 || len(stats.Routes) != 1 {
+		t.Fatalf("expected only the submit route counted, got %+v", stats)
+	}
+	rs := stats.Routes[0]
+	if rs.Route != "POST /api/v1/submit/{public_id}" || rs.Requests != 4 || rs.ServerErrors != 1 || rs.ErrorRate != 0.25 {
+		t.Errorf("unexpected route stats %+v", rs)
+	}
+}
+
+func TestCollector_Percentiles(t *testing.T) {
+	c := NewCollector()
+	for i := 1; i <= 100; i++ {
+		c.Observe("GET /api/v1/forms", http.StatusOK, time.Duration(i)*time.Millisecond)
+	}
+	c.Observe("GET /api/v1/forms", http.StatusNotFound, time.Millisecond)
+	c.Observe(unmatched, http.StatusNotFound, time.Millisecond)
+
+	stats := c.Stats()
+	if len(stats.Routes) != 2 || stats.Routes[0].Route != "GET /api/v1/forms" {
+		t.Fatalf("expected the busiest route first, got %+v", stats.Routes)
+	}
+	rs := stats.Routes[0]
+	if rs.P95MS != 95 || rs.MaxMS != 100 || rs.ClientErrors != 1 || rs.ErrorRate != 0 {
+		t.Errorf("unexpected route stats %+v", rs)
+	}
+}
@@ -0,0 +1,113 @@
+// Package linkcheck verifies links submitted to file_url fields with a HEAD request
+package linkcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+
+	"headless_form/internal/core/domain"
+)
+
+// maxRedirects caps the redirects followed to reach a file
+const maxRedirects = 5
+
+// errPrivateAddress refuses links resolving to this network or the server itself
+var errPrivateAddress = errors.New("link points to a private address")
+
+// Checker sends HEAD requests to submitted links
+type Checker struct {
+	Client *http.Client
+}
+
+// NewChecker creates a checker that only connects to public addresses, so submitted
+// links can't be used to probe the server's network
+func NewChecker() *Checker {
+	dialer := &net.Dialer{Timeout: 5 * time.Second, Control: publicOnly}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &Checker{
+		Client: &http.Client{
+			Timeout:   10 * time.Second,
+			Transport: transport,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) >= maxRedirects {
+					return fmt.Errorf("stopped after %d redirects", maxRedirects)
+				}
+				if req.URL.Scheme != "https" {
+					return errors.New("redirected away from https")
+				}
+				return nil
+			},
+		},
+	}
+}
+
+// publicOnly refuses connections to loopback, private, link-local and other non-public
+// addresses. It runs after the host is resolved, so DNS can't point around it.
+func publicOnly(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	ip = ip.Unmap()
+	if !ip.IsGlobalUnicast() || ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() {
+		return errPrivateAddress
+	}
+	return nil
+}
+
+// Check sends a HEAD request to the link submitted to a field and records what it
+// answered. Links that can't be reached are returned unverified with the reason.
+func (c *Checker) Check(ctx context.Context, field, link string) domain.FileLinkMeta {
+	result := domain.FileLinkMeta{Field: field, URL: link}
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, link, nil)
+	if err != nil {
+		result.Error = "invalid link"
+		return result
+	}
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		result.Error = unreachable(err)
+		return result
+	}
+	_ = resp.Body.Close()
+
+	result.Status = resp.StatusCode
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		result.Error = fmt.Sprintf("link answered %d", resp.StatusCode)
+		return result
+	}
+	result.Verified = true
+	if resp.ContentLength > 0 {
+		result.Size = resp.ContentLength
+	}
+	result.ContentType = resp.Header.Get("Content-Type")
+	return result
+}
+
+// unreachable describes a failed request without the internals of the error
+func unreachable(err error) string {
+	switch {
+	case errors.Is(err, errPrivateAddress):
+		return errPrivateAddress.Error()
+	case errors.Is(err, context.DeadlineExceeded):
+		return "link timed out"
+	default:
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			return "link timed out"
+		}
+		return "link could not be reached"
+	}
+}
@@ -4,34 +4,147 @@ package logger
 
 import (
 	"context"
+	"fmt"
+	"io"
 	"log/slog"
 	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
-var defaultLogger *slog.Logger
+// Config controls log output
+type Config struct {
+	Level        slog.Level            // Default level for all modules
+	ModuleLevels map[string]slog.Level // Per-module overrides (e.g. "webhook", "email", "sql")
+	Format       string                // "json" (default) or "text"
+	File         string                // Optional log file; stdout when empty
+	MaxSizeMB    int                   // Rotate the file once it exceeds this size (0 = 100MB)
+	MaxAge       time.Duration         // Delete rotated files older than this (0 = keep)
+}
+
+// state is the active configuration, swapped atomically by Init so loggers
+// created before Init (e.g. package-level module loggers) pick up changes.
+type state struct {
+	handler slog.Handler
+	level   slog.Level
+	modules map[string]slog.Level
+}
+
+var (
+	current       atomic.Pointer[state]
+	defaultLogger *slog.Logger
+
+	closeMu sync.Mutex
+	closer  io.Closer
+)
 
 func init() {
 	// Default to JSON handler for production-friendly structured logs
-	handler := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		Level: slog.LevelInfo,
+	current.Store(&state{
+		handler: slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}),
+		level:   slog.LevelInfo,
 	})
-	defaultLogger = slog.New(handler)
+	defaultLogger = slog.New(&moduleHandler{})
 	slog.SetDefault(defaultLogger)
 }
 
-// Init initializes the logger with custom options
-func Init(level slog.Level, jsonOutput bool) {
-	var handler slog.Handler
-	opts := &slog.HandlerOptions{Level: level}
+// Init configures the logger. The standard library log package is routed
+// through it as well, so existing log.Printf calls share the same output.
+func Init(cfg Config) error {
+	var out io.Writer = os.Stdout
+	var file *RotatingFile
+	if cfg.File != "" {
+		var err error
+		file, err = OpenRotatingFile(cfg.File, cfg.MaxSizeMB, cfg.MaxAge)
+		if err != nil {
+			return err
+		}
+		out = file
+	}
 
-	if jsonOutput {
-		handler = slog.NewJSONHandler(os.Stdout, opts)
-	} else {
-		handler = slog.NewTextHandler(os.Stdout, opts)
+	// Filtering happens in moduleHandler, so the base handler accepts everything
+	opts := &slog.HandlerOptions{Level: slog.Level(-8)}
+	var handler slog.Handler
+	switch cfg.Format {
+	case "", "json":
+		handler = slog.NewJSONHandler(out, opts)
+	case "text":
+		handler = slog.NewTextHandler(out, opts)
+	default:
+		if file != nil {
+			_ = file.Close()
+		}
+		return fmt.Errorf("unknown log format %q (want json or text)", cfg.Format)
 	}
 
-	defaultLogger = slog.New(handler)
+	current.Store(&state{handler: handler, level: cfg.Level, modules: cfg.ModuleLevels})
 	slog.SetDefault(defaultLogger)
+
+	closeMu.Lock()
+	prev := closer
+	closer = nil
+	if file != nil {
+		closer = file
+	}
+	closeMu.Unlock()
+	if prev != nil {
+		_ = prev.Close()
+	}
+	return nil
+}
+
+// Close flushes and closes the log file, if any
+func Close() error {
+	closeMu.Lock()
+	defer closeMu.Unlock()
+	if closer == nil {
+		return nil
+	}
+	err := closer.Close()
+	closer = nil
+	return err
+}
+
+// ParseLevels parses a level spec such as "info" or "info,webhook=debug,sql=warn".
+// The bare entry sets the default level; module=level entries override it per module.
+func ParseLevels(s string) (slog.Level, map[string]slog.Level, error) {
+	level := slog.LevelInfo
+	modules := make(map[string]slog.Level)
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		module, value, isModule := strings.Cut(part, "=")
+		if !isModule {
+			value = module
+		}
+		var l slog.Level
+		if err := l.UnmarshalText([]byte(strings.TrimSpace(value))); err != nil {
+			return 0, nil, fmt.Errorf("invalid log level %q", part)
+		}
+		if isModule {
+			modules[strings.TrimSpace(module)] = l
+		} else {
+			level = l
+		}
+	}
+	return level, modules, nil
+}
+
+// Module returns a logger for a subsystem. Its records carry a "module"
+// attribute and are filtered by that module's level override, if set.
+func Module(name string) *slog.Logger {
+	return slog.New(&moduleHandler{module: name}).With("module", name)
+}
+
+type ctxKey struct{}
+
+// NewContext returns a context carrying a request-scoped logger
+func NewContext(ctx context.Context, l *slog.Logger) context.Context {
+	return context.WithValue(ctx, ctxKey{}, l)
 }
 
 // Debug logs a debug message
@@ -59,8 +172,11 @@ func With(args ...any) *slog.Logger {
 	return defaultLogger.With(args...)
 }
 
-// WithContext returns a logger with context
+// WithContext returns the request-scoped logger stored in ctx, or the default logger
 func WithContext(ctx context.Context) *slog.Logger {
+	if l, ok := ctx.Value(ctxKey{}).(*slog.Logger); ok {
+		return l
+	}
 	return defaultLogger
 }
 
@@ -80,3 +196,42 @@ func WebhookError(event string, url string, err error, args ...any) {
 	attrs := append([]any{"event", event, "url", url, "error", err}, args...)
 	defaultLogger.Error("Webhook failed", attrs...)
 }
+
+// moduleHandler applies module level filtering and forwards records to the
+// current base handler. Attributes and groups are replayed onto whichever
+// handler is active at the time of the call.
+type moduleHandler struct {
+	module string
+	ops    []func(slog.Handler) slog.Handler
+}
+
+func (h *moduleHandler) Enabled(_ context.Context, level slog.Level) bool {
+	s := current.Load()
+	min := s.level
+	if l, ok := s.modules[h.module]; ok && h.module != "" {
+		min = l
+	}
+	return level >= min
+}
+
+func (h *moduleHandler) Handle(ctx context.Context, r slog.Record) error {
+	handler := current.Load().handler
+	for _, op := range h.ops {
+		handler = op(handler)
+	}
+	return handler.Handle(ctx, r)
+}
+
+func (h *moduleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return h.with(func(next slog.Handler) slog.Handler { return next.WithAttrs(attrs) })
+}
+
+func (h *moduleHandler) WithGroup(name string) slog.Handler {
+	return h.with(func(next slog.Handler) slog.Handler { return next.WithGroup(name) })
+}
+
+func (h *moduleHandler) with(op func(slog.Handler) slog.Handler) *moduleHandler {
+	ops := make([]func(slog.Handler) slog.Handler, len(h.ops), len(h.ops)+1)
+	copy(ops, h.ops)
+	return &moduleHandler{module: h.module, ops: append(ops, op)}
+}
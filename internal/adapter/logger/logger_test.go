@@ -0,0 +1,106 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseLevels(t *testing.T) {
+	level, modules, err := ParseLevels("warn, webhook=debug ,sql=error")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if level != slog.LevelWarn || modules["webhook"] != slog.LevelDebug || modules["sql"] != slog.LevelError {
+		t.Errorf("unexpected levels %v %v", level, modules)
+	}
+
+	if level, _, err := ParseLevels(""); err != nil || level != slog.LevelInfo {
+		t.Errorf("expected info default, got %v, %v", level, err)
+	}
+	if _, _, err := ParseLevels("loud"); err == nil {
+		t.Error("expected error for unknown level")
+	}
+}
+
+func TestModuleLevels(t *testing.T) {
+	var buf bytes.Buffer
+	prev := current.Load()
+	defer current.Store(prev)
+	current.Store(&state{
+		handler: slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.Level(-8)}),
+		level:   slog.LevelWarn,
+		modules: map[string]slog.Level{"webhook": slog.LevelDebug},
+	})
+
+	webhook := Module("webhook")
+	email := Module("email")
+	webhook.Debug("webhook debug")
+	email.Info("email info")
+	email.Warn("email warn")
+
+	out := buf.String()
+	if !strings.Contains(out, "webhook debug") || !strings.Contains(out, "module=webhook") {
+		t.Errorf("expected webhook debug line, got %q", out)
+	}
+	if strings.Contains(out, "email info") {
+		t.Errorf("email info should be filtered at warn, got %q", out)
+	}
+	if !strings.Contains(out, "email warn") {
+		t.Errorf("expected email warn line, got %q", out)
+	}
+
+	// Request-scoped loggers carry their attributes
+	buf.Reset()
+	ctx := NewContext(context.Background(), With("request_id", "req-1"))
+	WithContext(ctx).Warn("scoped")
+	if !strings.Contains(buf.String(), "request_id=req-1") {
+		t.Errorf("expected request_id attribute, got %q", buf.String())
+	}
+}
+
+func TestRotatingFile_RotatesAndPrunes(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	// A stale backup that should be pruned on the next rotation
+	old := filepath.Join(dir, "app-"+time.Now().Add(-48*time.Hour).UTC().Format(backupTimeFormat)+".log")
+	if err := os.WriteFile(old, []byte("old\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := OpenRotatingFile(path, 1, 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	line := bytes.Repeat([]byte("x"), 600*1024)
+	for i := 0; i < 2; i++ {
+		if _, err := f.Write(line); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, _ := os.ReadDir(dir)
+	var backups int
+	for _, e := range entries {
+		if e.Name() != "app.log" {
+			backups++
+		}
+	}
+	if backups != 1 {
+		t.Errorf("expected 1 fresh backup, got %d", backups)
+	}
+	if _, err := os.Stat(old); !os.IsNotExist(err) {
+		t.Error("expected stale backup to be pruned")
+	}
+	if info, _ := os.Stat(path); info.Size() != int64(len(line)) {
+		t.Errorf("expected current file to hold one write, got %d bytes", info.Size())
+	}
+}
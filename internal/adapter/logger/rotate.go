@@ -0,0 +1,139 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultMaxSizeMB is used when no rotation size is configured
+const defaultMaxSizeMB = 100
+
+// backupTimeFormat is appended to rotated file names, e.g. app-20060102T150405.log
+const backupTimeFormat = "20060102T150405"
+
+// RotatingFile is an io.Writer that appends to a file and rotates it once it
+// grows past a size limit. Rotated files older than maxAge are deleted.
+type RotatingFile struct {
+	mu      sync.Mutex
+	path    string
+	maxSize int64
+	maxAge  time.Duration
+	file    *os.File
+	size    int64
+	now     func() time.Time
+}
+
+// OpenRotatingFile opens (or creates) path for appending
+func OpenRotatingFile(path string, maxSizeMB int, maxAge time.Duration) (*RotatingFile, error) {
+	if maxSizeMB <= 0 {
+		maxSizeMB = defaultMaxSizeMB
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+	f := &RotatingFile{
+		path:    path,
+		maxSize: int64(maxSizeMB) * 1024 * 1024,
+		maxAge:  maxAge,
+		now:     time.Now,
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Write appends p, rotating first if it would push the file over the size limit
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+	if f.size > 0 && f.size+int64(len(p)) > f.maxSize {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Close closes the underlying file
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+func (f *RotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0640)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to stat log file: %w", err)
+	}
+	f.file = file
+	f.size = info.Size()
+	return nil
+}
+
+func (f *RotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	f.file = nil
+
+	ext := filepath.Ext(f.path)
+	backup := fmt.Sprintf("%s-%s%s", strings.TrimSuffix(f.path, ext), f.now().UTC().Format(backupTimeFormat), ext)
+	if err := os.Rename(f.path, backup); err != nil {
+		return fmt.Errorf("failed to rotate log file: %w", err)
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+	f.prune()
+	return nil
+}
+
+// prune removes rotated files older than maxAge, judged by the timestamp in their name
+func (f *RotatingFile) prune() {
+	if f.maxAge <= 0 {
+		return
+	}
+	ext := filepath.Ext(f.path)
+	prefix := filepath.Base(strings.TrimSuffix(f.path, ext)) + "-"
+	entries, err := os.ReadDir(filepath.Dir(f.path))
+	if err != nil {
+		return
+	}
+	cutoff := f.now().Add(-f.maxAge)
+	for _, e := range entries {
+		name := e.Name()
+		if !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+			continue
+		}
+		stamp := strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext)
+		t, err := time.Parse(backupTimeFormat, stamp)
+		if err != nil {
+			continue
+		}
+		if t.Before(cutoff) {
+			_ = os.Remove(filepath.Join(filepath.Dir(f.path), name))
+		}
+	}
+}
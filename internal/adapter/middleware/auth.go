@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"strings"
 
+	"headless_form/internal/core/domain"
 	"headless_form/internal/core/service"
 )
 
@@ -17,39 +18,90 @@ const (
 	UserIDKey ContextKey = "user_id"
 	EmailKey  ContextKey = "email"
 	RoleKey   ContextKey = "role"
+
+	// RequestIDKey holds the ID assigned to the request by LoggingMiddleware
+	RequestIDKey ContextKey = "request_id"
+	// FormScopeKey holds the public ID of the form a form API token is limited to
+	FormScopeKey ContextKey = "form_scope"
+	// CSPNonceKey holds the request's Content-Security-Policy nonce, set by SecurityHeaders
+	CSPNonceKey ContextKey = "csp_nonce"
+	// TeamRolesKey holds the user's role in each of their teams (map[string]domain.TeamRole)
+	TeamRolesKey ContextKey = "team_roles"
 )
 
-// AuthMiddleware creates authentication middleware
-func AuthMiddleware(authService *service.AuthService) func(http.Handler) http.Handler {
+// RoleFormToken is the role given to requests authenticated with a form API token
+const RoleFormToken = "form_token"
+
+// formTokenRoutes are the read-only routes a form API token may call.
+// The {form_id} in the path must match the token's form.
+var formTokenRoutes = map[string]bool{
+	"GET /api/v1/forms/{form_id}/submissions":        true,
+	"GET /api/v1/forms/{form_id}/submissions/stream": true,
+	"GET /api/v1/forms/{form_id}/export/csv":         true,
+	"GET /api/v1/forms/{form_id}/stats":              true,
+}
+
+// AuthMiddleware creates authentication middleware.
+// Bearer tokens are either dashboard JWTs or, when tokenService is set, read-only
+// form API tokens ("hft_..."), which are limited to formTokenRoutes for their own form.
+func AuthMiddleware(authService *service.AuthService, tokenService *service.FormTokenService) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Get token from Authorization header
 			authHeader := r.Header.Get("Authorization")
 			if authHeader == "" {
-				writeJSONError(w, `{"status":"fail","message":"Authorization header required"}`, http.StatusUnauthorized)
+				writeJSONError(w, `{"status":"fail","message":"Authorization header required","code":"UNAUTHORIZED"}`, http.StatusUnauthorized)
 				return
 			}
 
 			// Extract Bearer token
 			parts := strings.Split(authHeader, " ")
 			if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
-				writeJSONError(w, `{"status":"fail","message":"Invalid authorization header format"}`, http.StatusUnauthorized)
+				writeJSONError(w, `{"status":"fail","message":"Invalid authorization header format","code":"UNAUTHORIZED"}`, http.StatusUnauthorized)
 				return
 			}
 
 			tokenString := parts[1]
 
+			// Form API token: authenticate, then enforce its scope
+			if tokenService != nil && strings.HasPrefix(tokenString, domain.FormTokenPrefix) {
+				_, form, err := tokenService.Authenticate(r.Context(), tokenString)
+				if err != nil {
+					writeJSONError(w, `{"status":"fail","message":"Invalid or expired token","code":"UNAUTHORIZED"}`, http.StatusUnauthorized)
+					return
+				}
+				if !formTokenRoutes[r.Pattern] || r.PathValue("form_id") != form.PublicID {
+					writeJSONError(w, `{"status":"fail","message":"Token is not allowed to access this resource","code":"FORBIDDEN"}`, http.StatusForbidden)
+					return
+				}
+
+				// Act as the form owner for ownership checks; the route allowlist keeps it read-only
+				ctx := context.WithValue(r.Context(), UserIDKey, form.OwnerID)
+				ctx = context.WithValue(ctx, RoleKey, RoleFormToken)
+				ctx = context.WithValue(ctx, FormScopeKey, form.PublicID)
+
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+
 			// Validate token
 			claims, err := authService.ValidateToken(tokenString)
 			if err != nil {
-				writeJSONError(w, `{"status":"fail","message":"Invalid or expired token"}`, http.StatusUnauthorized)
+				writeJSONError(w, `{"status":"fail","message":"Invalid or expired token","code":"UNAUTHORIZED"}`, http.StatusUnauthorized)
 				return
 			}
 
+			// Team memberships decide access to shared forms; without them only owned forms are reachable
+			teams, err := authService.TeamRoles(r.Context(), claims.UserID)
+			if err != nil {
+				log.Printf("[AUTH] Failed to load teams of user %s: %v", claims.UserID, err)
+			}
+
 			// Add user info to context
 			ctx := context.WithValue(r.Context(), UserIDKey, claims.UserID)
 			ctx = context.WithValue(ctx, EmailKey, claims.Email)
 			ctx = context.WithValue(ctx, RoleKey, claims.Role)
+			ctx = context.WithValue(ctx, TeamRolesKey, teams)
 
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
@@ -84,7 +136,7 @@ func RequireRole(requiredRole string) func(http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			role, ok := r.Context().Value(RoleKey).(string)
 			if !ok || role != requiredRole {
-				writeJSONError(w, `{"status":"fail","message":"Insufficient permissions"}`, http.StatusForbidden)
+				writeJSONError(w, `{"status":"fail","message":"Insufficient permissions","code":"FORBIDDEN"}`, http.StatusForbidden)
 				return
 			}
 			next.ServeHTTP(w, r)
@@ -132,7 +184,7 @@ func RequireAnyRole(roles ...string) func(http.Handler) http.Handler {
 					return
 				}
 			}
-			writeJSONError(w, `{"status":"fail","message":"Insufficient permissions"}`, http.StatusForbidden)
+			writeJSONError(w, `{"status":"fail","message":"Insufficient permissions","code":"FORBIDDEN"}`, http.StatusForbidden)
 		})
 	}
 }
@@ -153,14 +205,54 @@ func CanManageAllForms(ctx context.Context) bool {
 	return IsAdmin(ctx)
 }
 
-// CanAccessForm checks if user can access a specific form (owner or admin)
-func CanAccessForm(ctx context.Context, formOwnerID string) bool {
+// GetTeamRoles returns the user's role in each of their teams, by team ID
+func GetTeamRoles(ctx context.Context) map[string]domain.TeamRole {
+	roles, _ := ctx.Value(TeamRolesKey).(map[string]domain.TeamRole)
+	return roles
+}
+
+// GetTeamIDs returns the IDs of the user's teams
+func GetTeamIDs(ctx context.Context) []string {
+	roles := GetTeamRoles(ctx)
+	teamIDs := make([]string, 0, len(roles))
+	for id := range roles {
+		teamIDs = append(teamIDs, id)
+	}
+	return teamIDs
+}
+
+// CanAccessForm checks if user can see a form and its submissions
+// (admin, owner, or any member of the form's team)
+func CanAccessForm(ctx context.Context, form *domain.Form) bool {
 	// Admins and super_admins can access all forms
-	if IsAdmin(ctx) {
+	if IsAdmin(ctx) || GetUserID(ctx) == form.OwnerID {
 		return true
 	}
-	// Users can only access their own forms
-	return GetUserID(ctx) == formOwnerID
+	_, ok := GetTeamRoles(ctx)[form.TeamID]
+	return ok && form.TeamID != ""
+}
+
+// CanManageForm checks if user can change or delete a form
+// (admin, owner, or an owner or editor of the form's team)
+func CanManageForm(ctx context.Context, form *domain.Form) bool {
+	if IsAdmin(ctx) || GetUserID(ctx) == form.OwnerID {
+		return true
+	}
+	return form.TeamID != "" && GetTeamRoles(ctx)[form.TeamID].CanManageForms()
+}
+
+// ExportRole returns who the export settings take the user for when exporting the form:
+// "" for admins, who always export in full, then form tokens, the owner and team members
+func ExportRole(ctx context.Context, form *domain.Form) domain.ExportRole {
+	switch {
+	case IsAdmin(ctx):
+		return ""
+	case GetUserRole(ctx) == RoleFormToken:
+		return domain.ExportRoleFormToken
+	case GetUserID(ctx) == form.OwnerID:
+		return domain.ExportRoleUser
+	}
+	return domain.TeamExportRole(GetTeamRoles(ctx)[form.TeamID])
 }
 
 // writeJSONError writes a JSON error response with proper Content-Type
@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+)
+
+// CSPReportPath is where browsers send Content-Security-Policy violation reports
+const CSPReportPath = "/api/v1/csp-report"
+
+// maxCSPReportSize caps report bodies; real reports are a few hundred bytes
+const maxCSPReportSize = 64 << 10
+
+// newNonce returns a random base64 nonce for one response's script tags
+func newNonce() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return base64.StdEncoding.EncodeToString(b)
+}
+
+// CSPNonce returns the request's CSP nonce set by SecurityHeaders, if any
+func CSPNonce(ctx context.Context) string {
+	if nonce, ok := ctx.Value(CSPNonceKey).(string); ok {
+		return nonce
+	}
+	return ""
+}
+
+// InjectNonce adds the nonce to every <script> tag and module preload of an HTML
+// page, so the page's own scripts run under DashboardPolicy
+func InjectNonce(html []byte, nonce string) []byte {
+	if nonce == "" {
+		return html
+	}
+	attr := ` nonce="` + nonce + `"`
+	html = bytes.ReplaceAll(html, []byte("<script"), []byte("<script"+attr))
+	return bytes.ReplaceAll(html, []byte(`<link rel="modulepreload"`), []byte(`<link rel="modulepreload"`+attr))
+}
+
+// cspViolation holds the fields of a violation report worth logging. Browsers send
+// either the legacy {"csp-report": {...}} body or Reporting API arrays of
+// {"type": "csp-violation", "body": {...}}, with camelCase keys.
+type cspViolation struct {
+	DocumentURI        string `json:"document-uri"`
+	BlockedURI         string `json:"blocked-uri"`
+	ViolatedDirective  string `json:"violated-directive"`
+	EffectiveDirective string `json:"effective-directive"`
+	SourceFile         string `json:"source-file"`
+	LineNumber         int    `json:"line-number"`
+
+	// Reporting API names
+	DocumentURL          string `json:"documentURL"`
+	BlockedURL           string `json:"blockedURL"`
+	EffectiveDirectiveV2 string `json:"effectiveDirective"`
+	SourceFileV2         string `json:"sourceFile"`
+	LineNumberV2         int    `json:"lineNumber"`
+}
+
+// HandleCSPReport: POST /api/v1/csp-report
+// Logs Content-Security-Policy violations reported by browsers and answers 204.
+// Malformed reports are ignored.
+func HandleCSPReport(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxCSPReportSize))
+	if err != nil {
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	var violations []cspViolation
+	var legacy struct {
+		Report *cspViolation `json:"csp-report"`
+	}
+	var reports []struct {
+		Type string       `json:"type"`
+		Body cspViolation `json:"body"`
+	}
+	if json.Unmarshal(body, &legacy) == nil && legacy.Report != nil {
+		violations = append(violations, *legacy.Report)
+	} else if json.Unmarshal(body, &reports) == nil {
+		for _, rep := range reports {
+			if rep.Type == "csp-violation" {
+				violations = append(violations, rep.Body)
+			}
+		}
+	}
+
+	for _, v := range violations {
+		requestLog.LogAttrs(r.Context(), slog.LevelWarn, "csp violation",
+			slog.String("document", firstNonEmpty(v.DocumentURI, v.DocumentURL)),
+			slog.String("blocked", firstNonEmpty(v.BlockedURI, v.BlockedURL)),
+			slog.String("directive", firstNonEmpty(v.EffectiveDirective, v.EffectiveDirectiveV2, v.ViolatedDirective)),
+			slog.String("source", firstNonEmpty(v.SourceFile, v.SourceFileV2)),
+			slog.Int("line", max(v.LineNumber, v.LineNumberV2)),
+			slog.String("user_agent", r.UserAgent()),
+		)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
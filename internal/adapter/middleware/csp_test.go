@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSecurityHeaders_Nonce(t *testing.T) {
+	var seen string
+	handler := SecurityHeaders()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = CSPNonce(r.Context())
+		_, _ = w.Write(InjectNonce([]byte(`<script src="/_app/start.js"></script><script>boot()</script>`), seen))
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if seen == "" {
+		t.Fatal("expected a nonce in the request context")
+	}
+	csp := w.Header().Get("Content-Security-Policy")
+	if !strings.Contains(csp, "script-src 'nonce-"+seen+"' 'strict-dynamic' 'self'") {
+		t.Errorf("expected the nonce in script-src, got %q", csp)
+	}
+	if strings.Contains(csp, "'unsafe-inline'; script-src") || strings.Contains(csp, "script-src 'self' 'unsafe-inline'") {
+		t.Errorf("expected no unsafe-inline scripts, got %q", csp)
+	}
+	if !strings.Contains(csp, "report-uri "+CSPReportPath) {
+		t.Errorf("expected a report-uri, got %q", csp)
+	}
+	if got := strings.Count(w.Body.String(), `<script nonce="`+seen+`"`); got != 2 {
+		t.Errorf("expected both script tags to carry the nonce, got %s", w.Body.String())
+	}
+
+	first := seen
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if seen == first {
+		t.Error("expected a new nonce per request")
+	}
+}
+
+func TestHandleCSPReport(t *testing.T) {
+	bodies := []string{
+		`{"csp-report": {"document-uri": "https://forms.example.com/", "blocked-uri": "inline", "effective-directive": "script-src-elem"}}`,
+		`[{"type": "csp-violation", "body": {"documentURL": "https://forms.example.com/", "blockedURL": "https://evil.example/x.js"}}]`,
+		`not json`,
+	}
+	for _, body := range bodies {
+		w := httptest.NewRecorder()
+		HandleCSPReport(w, httptest.NewRequest(http.MethodPost, CSPReportPath, strings.NewReader(body)))
+		if w.Code != http.StatusNoContent {
+			t.Errorf("%s: expected 204, got %d", body, w.Code)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	HandleCSPReport(w, httptest.NewRequest(http.MethodPost, CSPReportPath, strings.NewReader(strings.Repeat("x", maxCSPReportSize+1))))
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected 413 for an oversized report, got %d", w.Code)
+	}
+}
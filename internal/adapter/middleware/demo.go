@@ -0,0 +1,50 @@
+package middleware
+
+import "net/http"
+
+// DemoBlockedRoutes are the routes refused in demo mode: account and instance
+// settings changes, which would lock other visitors out or reach real mail servers
+var DemoBlockedRoutes = []string{
+	"POST /api/v1/auth/register",
+	"POST /api/v1/auth/forgot-password",
+	"POST /api/v1/auth/reset-password",
+	"PUT /api/v1/auth/profile",
+	"PUT /api/v1/auth/password",
+	"POST /api/v1/users",
+	"PUT /api/v1/users/{user_id}",
+	"DELETE /api/v1/users/{user_id}",
+	"POST /api/v1/users/import",
+	"PUT /api/v1/settings",
+	"PUT /api/v1/settings/filters",
+	"PUT /api/v1/settings/spam",
+	"PUT /api/v1/settings/oauth",
+	"PUT /api/v1/settings/exports",
+	"POST /api/v1/settings/test-smtp",
+	"POST /api/v1/forms/{form_id}/test-notification",
+	"PUT /api/v1/forms/{form_id}/auto-reply",
+	"POST /api/v1/admin/seed",
+	"PUT /api/v1/admin/maintenance",                 // Kept in memory, so a demo reset wouldn't undo it
+	"DELETE /api/v1/admin/spam-fingerprints/{hash}", // Instance-wide spam state
+}
+
+// DemoAllowedRoutes are the admin and settings changes deliberately left open in demo mode:
+// they only touch a form's own data, which the demo reset restores
+var DemoAllowedRoutes = []string{
+	"POST /api/v1/admin/forms/{form_id}/field-migrations/preview",
+	"POST /api/v1/admin/forms/{form_id}/field-migrations",
+}
+
+// DemoGuard rejects requests to the given route patterns (ServeMux syntax)
+// with 403 DEMO_MODE, leaving every other route untouched
+func DemoGuard(patterns []string) func(http.Handler) http.Handler {
+	blocked := matchRoutes(patterns)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if blocked(r) {
+				writeJSONError(w, `{"status":"fail","message":"This action is disabled in the demo","code":"DEMO_MODE"}`, http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestDemoGuard(t *testing.T) {
+	handler := DemoGuard(DemoBlockedRoutes)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		method, path string
+		want         int
+	}{
+		{http.MethodPut, "/api/v1/settings", http.StatusForbidden},
+		{http.MethodDelete, "/api/v1/users/usr_1", http.StatusForbidden},
+		{http.MethodPut, "/api/v1/auth/password", http.StatusForbidden},
+		{http.MethodPut, "/api/v1/settings/oauth", http.StatusForbidden},
+		{http.MethodPut, "/api/v1/settings/spam", http.StatusForbidden},
+		{http.MethodPut, "/api/v1/settings/exports", http.StatusForbidden},
+		{http.MethodPut, "/api/v1/admin/maintenance", http.StatusForbidden},
+		// Reads and other writes pass through
+		{http.MethodGet, "/api/v1/settings", http.StatusOK},
+		{http.MethodGet, "/api/v1/users", http.StatusOK},
+		{http.MethodPost, "/api/v1/auth/login", http.StatusOK},
+		{http.MethodPost, "/api/v1/forms", http.StatusOK},
+		{http.MethodGet, "/", http.StatusOK},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+		if w.Code != tt.want {
+			t.Errorf("%s %s: expected %d, got %d", tt.method, tt.path, tt.want, w.Code)
+		}
+		if tt.want == http.StatusForbidden && !strings.Contains(w.Body.String(), `"DEMO_MODE"`) {
+			t.Errorf("%s %s: expected DEMO_MODE code, got %s", tt.method, tt.path, w.Body.String())
+		}
+	}
+}
+
+// Every admin and settings change registered anywhere must be blocked in demo mode, or
+// listed in DemoAllowedRoutes on purpose: the demo user is a super admin.
+func TestDemoRoutesCoverAdminChanges(t *testing.T) {
+	route := regexp.MustCompile(`"((?:POST|PUT|PATCH|DELETE) /api/v1/(?:admin|settings|users)[^"]*)"`)
+	listed := make(map[string]bool)
+	for _, r := range append(append([]string{}, DemoBlockedRoutes...), DemoAllowedRoutes...) {
+		listed[r] = true
+	}
+
+	found := 0
+	err := filepath.WalkDir("../../..", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && (d.Name() == "node_modules" || d.Name() == ".git") {
+			return filepath.SkipDir
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") || strings.HasSuffix(path, "demo.go") {
+			return nil
+		}
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		for _, m := range route.FindAllStringSubmatch(string(src), -1) {
+			found++
+			if !listed[m[1]] {
+				t.Errorf("%s registers %q, which is neither in DemoBlockedRoutes nor DemoAllowedRoutes", path, m[1])
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found == 0 {
+		t.Fatal("found no admin routes; has the source moved?")
+	}
+}
@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultMaintenanceRetryAfter is the Retry-After sent while in maintenance mode unless another is set
+const DefaultMaintenanceRetryAfter = 5 * time.Minute
+
+// MaintenanceState describes whether the instance is read-only and why
+type MaintenanceState struct {
+	Enabled    bool       `json:"enabled"`
+	Message    string     `json:"message,omitempty"`
+	RetryAfter int        `json:"retry_after,omitempty"` // Seconds clients are told to wait
+	Since      *time.Time `json:"since,omitempty"`
+	EnabledBy  string     `json:"enabled_by,omitempty"` // User ID, empty when enabled at startup
+}
+
+// Maintenance is the instance-wide read-only switch (see MaintenanceGuard)
+type Maintenance struct {
+	mu    sync.RWMutex
+	state MaintenanceState
+}
+
+func NewMaintenance() *Maintenance {
+	return &Maintenance{}
+}
+
+// State returns the current maintenance state
+func (m *Maintenance) State() MaintenanceState {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.state
+}
+
+// Enabled reports whether the instance is read-only
+func (m *Maintenance) Enabled() bool {
+	return m.State().Enabled
+}
+
+// Enable makes the instance read-only. retryAfter <= 0 uses DefaultMaintenanceRetryAfter.
+func (m *Maintenance) Enable(message string, retryAfter time.Duration, userID string) MaintenanceState {
+	if retryAfter <= 0 {
+		retryAfter = DefaultMaintenanceRetryAfter
+	}
+	now := time.Now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.state = MaintenanceState{
+		Enabled:    true,
+		Message:    message,
+		RetryAfter: int(retryAfter.Seconds()),
+		Since:      &now,
+		EnabledBy:  userID,
+	}
+	return m.state
+}
+
+// Disable accepts writes again
+func (m *Maintenance) Disable() MaintenanceState {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.state = MaintenanceState{}
+	return m.state
+}
+
+// MaintenanceAllowedRoutes are the writes still accepted in maintenance mode:
+// logging in, and turning maintenance mode off again
+var MaintenanceAllowedRoutes = []string{
+	"POST /api/v1/auth/login",
+	"PUT /api/v1/admin/maintenance",
+	"POST " + CSPReportPath,
+}
+
+// MaintenanceGuard lets reads (GET, HEAD, OPTIONS) and the allowed route patterns
+// through while m is enabled, and answers every other request, submissions
+// included, with 503 MAINTENANCE and a Retry-After header
+func MaintenanceGuard(m *Maintenance, allowed []string) func(http.Handler) http.Handler {
+	isAllowed := matchRoutes(allowed)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			state := m.State()
+			if !state.Enabled || isAllowed(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			message := state.Message
+			if message == "" {
+				message = "The server is in read-only maintenance mode, please retry later"
+			}
+			body, _ := json.Marshal(map[string]string{"status": "fail", "message": message, "code": "MAINTENANCE"})
+			w.Header().Set("Retry-After", strconv.Itoa(state.RetryAfter))
+			writeJSONError(w, string(body), http.StatusServiceUnavailable)
+		})
+	}
+}
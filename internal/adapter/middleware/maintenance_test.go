@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMaintenanceGuard(t *testing.T) {
+	m := NewMaintenance()
+	handler := MaintenanceGuard(m, MaintenanceAllowedRoutes)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w
+	}
+
+	if w := serve(http.MethodPost, "/api/v1/submissions/frm_1"); w.Code != http.StatusOK {
+		t.Fatalf("expected writes to pass while disabled, got %d", w.Code)
+	}
+
+	m.Enable("Backing up", 10*time.Minute, "usr_1")
+	tests := []struct {
+		method, path string
+		want         int
+	}{
+		{http.MethodPost, "/api/v1/submissions/frm_1", http.StatusServiceUnavailable},
+		{http.MethodPut, "/api/v1/forms/frm_1", http.StatusServiceUnavailable},
+		{http.MethodDelete, "/api/v1/submissions/sub_1", http.StatusServiceUnavailable},
+		// Reads, logging in and turning maintenance off pass through
+		{http.MethodGet, "/api/v1/forms", http.StatusOK},
+		{http.MethodOptions, "/api/v1/submissions/frm_1", http.StatusOK},
+		{http.MethodPost, "/api/v1/auth/login", http.StatusOK},
+		{http.MethodPut, "/api/v1/admin/maintenance", http.StatusOK},
+	}
+	for _, tt := range tests {
+		w := serve(tt.method, tt.path)
+		if w.Code != tt.want {
+			t.Errorf("%s %s: expected %d, got %d", tt.method, tt.path, tt.want, w.Code)
+		}
+		if tt.want == http.StatusServiceUnavailable {
+			if w.Header().Get("Retry-After") != "600" || !strings.Contains(w.Body.String(), `"MAINTENANCE"`) || !strings.Contains(w.Body.String(), "Backing up") {
+				t.Errorf("%s %s: expected MAINTENANCE with Retry-After, got %v %s", tt.method, tt.path, w.Header(), w.Body.String())
+			}
+		}
+	}
+
+	m.Disable()
+	if w := serve(http.MethodPost, "/api/v1/submissions/frm_1"); w.Code != http.StatusOK {
+		t.Errorf("expected writes to pass again, got %d", w.Code)
+	}
+	if state := m.Enable("", 0, ""); state.RetryAfter != int(DefaultMaintenanceRetryAfter.Seconds()) {
+		t.Errorf("expected the default Retry-After, got %d", state.RetryAfter)
+	}
+}
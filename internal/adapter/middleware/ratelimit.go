@@ -1,17 +1,21 @@
 package middleware
 
 import (
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
 
-// RateLimiter implements a simple token bucket rate limiter per IP
+// RateLimiter implements a simple fixed-window rate limiter keyed by IP or identity
 type RateLimiter struct {
-	mu       sync.RWMutex
-	visitors map[string]*visitor
-	limit    int           // Max requests
-	window   time.Duration // Time window
+	mu         sync.RWMutex
+	visitors   map[string]*visitor
+	limit      int            // Max requests
+	window     time.Duration  // Time window
+	roleLimits map[string]int // Per-role overrides for IdentityMiddleware
 }
 
 type visitor struct {
@@ -22,83 +26,151 @@ type visitor struct {
 // NewRateLimiter creates a new rate limiter
 func NewRateLimiter(limit int, window time.Duration) *RateLimiter {
 	rl := &RateLimiter{
-		visitors: make(map[string]*visitor),
-		limit:    limit,
-		window:   window,
+		visitors:   make(map[string]*visitor),
+		limit:      limit,
+		window:     window,
+		roleLimits: make(map[string]int),
 	}
 	// Cleanup old entries periodically
 	go rl.cleanup()
 	return rl
 }
 
+// SetLimit changes the default number of requests allowed per window
+func (rl *RateLimiter) SetLimit(limit int) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.limit = limit
+}
+
+// SetRoleLimit overrides the limit for authenticated users with the given role
+func (rl *RateLimiter) SetRoleLimit(role string, limit int) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.roleLimits[role] = limit
+}
+
 func (rl *RateLimiter) cleanup() {
 	for {
 		time.Sleep(time.Minute)
 		rl.mu.Lock()
-		for ip, v := range rl.visitors {
+		for key, v := range rl.visitors {
 			if time.Since(v.lastReset) > rl.window*2 {
-				delete(rl.visitors, ip)
+				delete(rl.visitors, key)
 			}
 		}
 		rl.mu.Unlock()
 	}
 }
 
-func (rl *RateLimiter) getVisitor(ip string) *visitor {
-	rl.mu.Lock()
-	defer rl.mu.Unlock()
-
-	v, exists := rl.visitors[ip]
-	if !exists {
-		v = &visitor{
-			tokens:    rl.limit,
-			lastReset: time.Now(),
-		}
-		rl.visitors[ip] = v
-		return v
-	}
-
-	// Reset tokens if window has passed
-	if time.Since(v.lastReset) > rl.window {
-		v.tokens = rl.limit
-		v.lastReset = time.Now()
+// limitFor returns the limit for a role, falling back to the default
+func (rl *RateLimiter) limitFor(role string) int {
+	rl.mu.RLock()
+	defer rl.mu.RUnlock()
+	if limit, ok := rl.roleLimits[role]; ok {
+		return limit
 	}
-
-	return v
+	return rl.limit
 }
 
-func (rl *RateLimiter) allow(ip string) bool {
-	v := rl.getVisitor(ip)
-
+// take consumes a request for key. It returns whether the request is allowed,
+// how many requests remain in the window and when the window resets.
+func (rl *RateLimiter) take(key string, limit int) (bool, int, time.Time) {
 	rl.mu.Lock()
 	defer rl.mu.Unlock()
 
+	now := time.Now()
+	v, exists := rl.visitors[key]
+	if !exists || now.Sub(v.lastReset) > rl.window {
+		v = &visitor{tokens: limit, lastReset: now}
+		rl.visitors[key] = v
+	}
+	reset := v.lastReset.Add(rl.window)
+
 	if v.tokens > 0 {
 		v.tokens--
-		return true
+		return true, v.tokens, reset
 	}
-	return false
+	return false, 0, reset
 }
 
-// Middleware returns HTTP middleware that rate limits requests
+// limitRequest applies the limit for key, writing X-RateLimit-* headers.
+// Returns false (after writing a 429) when the limit is exceeded.
+func (rl *RateLimiter) limitRequest(w http.ResponseWriter, key string, limit int) bool {
+	allowed, remaining, reset := rl.take(key, limit)
+
+	h := w.Header()
+	h.Set("X-RateLimit-Limit", strconv.Itoa(limit))
+	h.Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+	h.Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+
+	if !allowed {
+		retryAfter := int(time.Until(reset).Seconds()) + 1
+		h.Set("Content-Type", "application/json")
+		h.Set("Retry-After", strconv.Itoa(retryAfter))
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"status":"fail","message":"Rate limit exceeded. Please try again later.","code":"RATE_LIMITED"}`))
+		return false
+	}
+	return true
+}
+
+// Middleware returns HTTP middleware that rate limits requests per client IP
 func (rl *RateLimiter) Middleware() func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			ip := getIP(r)
+			rl.mu.RLock()
+			limit := rl.limit
+			rl.mu.RUnlock()
 
-			if !rl.allow(ip) {
-				w.Header().Set("Content-Type", "application/json")
-				w.Header().Set("Retry-After", "60")
-				w.WriteHeader(http.StatusTooManyRequests)
-				_, _ = w.Write([]byte(`{"status":"fail","message":"Rate limit exceeded. Please try again later."}`))
+			if !rl.limitRequest(w, "ip:"+getIP(r), limit) {
 				return
 			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// IdentityMiddleware rate limits authenticated requests per user (or per form API token),
+// using the limit configured for the caller's role. It must run after AuthMiddleware;
+// requests without an identity fall back to the client IP.
+func (rl *RateLimiter) IdentityMiddleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			key := "ip:" + getIP(r)
+			if scope, ok := ctx.Value(FormScopeKey).(string); ok && scope != "" {
+				key = "token:" + scope
+			} else if userID := GetUserID(ctx); userID != "" {
+				key = "user:" + userID
+			}
 
+			if !rl.limitRequest(w, key, rl.limitFor(GetUserRole(ctx))) {
+				return
+			}
 			next.ServeHTTP(w, r)
 		})
 	}
 }
 
+// ParseRoleLimits parses per-role limits such as "admin=1000,user=200"
+func ParseRoleLimits(s string) (map[string]int, error) {
+	limits := make(map[string]int)
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		role, value, ok := strings.Cut(part, "=")
+		limit, err := strconv.Atoi(strings.TrimSpace(value))
+		if !ok || err != nil || limit <= 0 || strings.TrimSpace(role) == "" {
+			return nil, fmt.Errorf("invalid role limit %q (want role=number)", part)
+		}
+		limits[strings.TrimSpace(role)] = limit
+	}
+	return limits, nil
+}
+
 // getIP extracts the client IP from the request
 func getIP(r *http.Request) string {
 	// Check X-Forwarded-For header first (for reverse proxies)
@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestIdentityMiddleware_PerUserAndRoleLimits(t *testing.T) {
+	rl := NewRateLimiter(2, time.Minute)
+	rl.SetRoleLimit("admin", 3)
+	handler := rl.IdentityMiddleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	do := func(userID, role string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/forms", nil)
+		ctx := context.WithValue(req.Context(), UserIDKey, userID)
+		ctx = context.WithValue(ctx, RoleKey, role)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req.WithContext(ctx))
+		return w
+	}
+
+	// Users share an IP but get separate buckets
+	for i := 0; i < 2; i++ {
+		if w := do("usr_a", "user"); w.Code != http.StatusOK {
+			t.Fatalf("request %d: expected 200, got %d", i, w.Code)
+		}
+	}
+	w := do("usr_a", "user")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429, got %d", w.Code)
+	}
+	if w.Header().Get("X-RateLimit-Remaining") != "0" || w.Header().Get("Retry-After") == "" {
+		t.Errorf("unexpected headers: %v", w.Header())
+	}
+	if w := do("usr_b", "user"); w.Code != http.StatusOK {
+		t.Errorf("expected other user to be allowed, got %d", w.Code)
+	}
+
+	// Role override raises the quota
+	for i := 0; i < 3; i++ {
+		w := do("usr_admin", "admin")
+		if w.Code != http.StatusOK {
+			t.Fatalf("admin request %d: expected 200, got %d", i, w.Code)
+		}
+		if got := w.Header().Get("X-RateLimit-Limit"); got != "3" {
+			t.Errorf("expected limit header 3, got %q", got)
+		}
+	}
+	if w := do("usr_admin", "admin"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("expected admin to be limited after 3, got %d", w.Code)
+	}
+}
+
+func TestParseRoleLimits(t *testing.T) {
+	limits, err := ParseRoleLimits(" admin=1000, form_token=50 ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if limits["admin"] != 1000 || limits["form_token"] != 50 {
+		t.Errorf("unexpected limits %v", limits)
+	}
+	if limits, err := ParseRoleLimits(""); err != nil || len(limits) != 0 {
+		t.Errorf("expected empty result, got %v, %v", limits, err)
+	}
+	for _, bad := range []string{"admin", "admin=x", "=5", "user=0"} {
+		if _, err := ParseRoleLimits(bad); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
@@ -1,9 +1,18 @@
 package middleware
 
 import (
-	"log"
+	"context"
+	"log/slog"
 	"net/http"
+	"net/url"
 	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"headless_form/internal/adapter/logger"
+	"headless_form/internal/adapter/sqltrace"
+	"headless_form/internal/core/domain"
 )
 
 // SecurityConfig holds security middleware configuration
@@ -12,30 +21,100 @@ type SecurityConfig struct {
 	IsDevelopment  bool
 }
 
-// SecurityHeaders adds security headers to responses
+// HeaderPolicy is the framing and content policy sent with a response
+type HeaderPolicy struct {
+	CSP            string   // Content-Security-Policy, without script-src, frame-ancestors and report-uri
+	ScriptSrc      string   // script-src sources, preceded by the request's nonce when there is one
+	FrameAncestors []string // Origins allowed to embed the response; none sends X-Frame-Options: DENY
+	ReportURI      string   // Where browsers report violations (see HandleCSPReport)
+}
+
+// DashboardPolicy applies to every route by default. Scripts need the request's nonce
+// (see CSPNonce); scripts they load are trusted through 'strict-dynamic'. The admin
+// SPA is never framed.
+var DashboardPolicy = HeaderPolicy{
+	CSP: "default-src 'self'; " +
+		"style-src 'self' 'unsafe-inline' https://fonts.googleapis.com; " +
+		"font-src 'self' https://fonts.gstatic.com; " +
+		"img-src 'self' data: blob:; " +
+		"connect-src 'self'; " +
+		"object-src 'none'; " +
+		"base-uri 'self'",
+	ScriptSrc: "'self'",
+	ReportURI: CSPReportPath,
+}
+
+// HostedFormPolicy applies to a form's public responses (submission results, public
+// stats and badges). They load nothing, and forms can allow framing them with AllowFraming.
+var HostedFormPolicy = HeaderPolicy{
+	CSP: "default-src 'none'; style-src 'unsafe-inline'; img-src data:",
+}
+
+// Apply sets the policy's headers, replacing any set before. nonce is the
+// request's CSP nonce, or empty when scripts can't use one.
+func (p HeaderPolicy) Apply(h http.Header, nonce string) {
+	csp := p.CSP
+	if p.ScriptSrc != "" {
+		src := p.ScriptSrc
+		if nonce != "" {
+			src = "'nonce-" + nonce + "' 'strict-dynamic' " + src
+		}
+		csp += "; script-src " + src
+	}
+	if len(p.FrameAncestors) == 0 {
+		h.Set("X-Frame-Options", "DENY")
+		csp += "; frame-ancestors 'none'"
+	} else {
+		// X-Frame-Options can't express an allow-list; browsers use frame-ancestors instead
+		h.Del("X-Frame-Options")
+		csp += "; frame-ancestors " + strings.Join(p.FrameAncestors, " ")
+	}
+	if p.ReportURI != "" {
+		csp += "; report-uri " + p.ReportURI
+	}
+	h.Set("Content-Security-Policy", csp)
+}
+
+// SecurityHeaders adds security headers to responses, with DashboardPolicy
+// unless the route sets its own (see WithHeaderPolicy). Each request gets a
+// fresh CSP nonce, available to handlers through CSPNonce.
 func SecurityHeaders() func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			nonce := newNonce()
+
 			// Security headers
 			w.Header().Set("X-Content-Type-Options", "nosniff")
-			w.Header().Set("X-Frame-Options", "DENY")
 			w.Header().Set("X-XSS-Protection", "1; mode=block")
 			w.Header().Set("Referrer-Policy", "strict-origin-when-cross-origin")
+			DashboardPolicy.Apply(w.Header(), nonce)
 
-			// Content Security Policy (relaxed for admin SPA)
-			w.Header().Set("Content-Security-Policy",
-				"default-src 'self'; "+
-					"script-src 'self' 'unsafe-inline'; "+
-					"style-src 'self' 'unsafe-inline' https://fonts.googleapis.com; "+
-					"font-src 'self' https://fonts.gstatic.com; "+
-					"img-src 'self' data: blob:; "+
-					"connect-src 'self'")
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), CSPNonceKey, nonce)))
+		})
+	}
+}
 
+// WithHeaderPolicy replaces the default header policy for one route
+func WithHeaderPolicy(p HeaderPolicy) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p.Apply(w.Header(), CSPNonce(r.Context()))
 			next.ServeHTTP(w, r)
 		})
 	}
 }
 
+// AllowFraming lets a hosted form response be embedded by the form's frame ancestors.
+// Call it before writing the response; it does nothing when ancestors is empty.
+func AllowFraming(w http.ResponseWriter, ancestors []string) {
+	if len(ancestors) == 0 {
+		return
+	}
+	p := HostedFormPolicy
+	p.FrameAncestors = ancestors
+	p.Apply(w.Header(), "")
+}
+
 // HTTPSRedirect redirects HTTP to HTTPS in production
 // Enable by setting FORCE_HTTPS=true environment variable
 func HTTPSRedirect(forceHTTPS bool) func(http.Handler) http.Handler {
@@ -57,8 +136,14 @@ func HTTPSRedirect(forceHTTPS bool) func(http.Handler) http.Handler {
 
 // CORSMiddleware creates CORS middleware with configurable origins
 func CORSMiddleware(config SecurityConfig) func(http.Handler) http.Handler {
+	ownCORS := matchRoutes(FormCORSRoutes)
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if ownCORS(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
 			origin := r.Header.Get("Origin")
 
 			// Check if origin is allowed
@@ -97,7 +182,83 @@ func CORSMiddleware(config SecurityConfig) func(http.Handler) http.Handler {
 	}
 }
 
-// Logging middleware for request logging
+// FormCORSRoutes answer CORS themselves from the form's allowed origins (see
+// SetFormCORS), the same in every environment. CORSMiddleware leaves them alone.
+var FormCORSRoutes = []string{
+	"POST /api/v1/submissions/{form_id}",
+	"OPTIONS /api/v1/submissions/{form_id}",
+	"GET /api/v1/forms/{form_id}/fields",
+}
+
+// SetFormCORS sets the CORS headers for a request to a form's public endpoint and
+// reports whether its origin may use it. Forms open to every origin answer with
+// "Access-Control-Allow-Origin: *"; others echo the allowed origin. Requests without
+// an Origin header get no CORS headers and are checked against their Referer's
+// origin instead; with neither (server-side posts) they're allowed.
+func SetFormCORS(w http.ResponseWriter, r *http.Request, form *domain.Form) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		referer := r.Referer()
+		return referer == "" || form.AllowsOrigin(refererOrigin(referer))
+	}
+	h := w.Header()
+	if form.AllowsAnyOrigin() {
+		h.Set("Access-Control-Allow-Origin", "*")
+	} else if form.AllowsOrigin(origin) {
+		h.Set("Access-Control-Allow-Origin", origin)
+		h.Add("Vary", "Origin")
+	} else {
+		return false
+	}
+	h.Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+	h.Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With, Idempotency-Key")
+	h.Set("Access-Control-Max-Age", "86400")
+	return true
+}
+
+// refererOrigin returns the scheme and host of a Referer, "" if it has none
+func refererOrigin(referer string) string {
+	u, err := url.Parse(referer)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return ""
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// matchRoutes returns a function reporting whether a request matches one of the
+// route patterns, using ServeMux matching so patterns read like route registrations
+func matchRoutes(patterns []string) func(*http.Request) bool {
+	mux := http.NewServeMux()
+	for _, p := range patterns {
+		mux.Handle(p, http.NotFoundHandler())
+	}
+	return func(r *http.Request) bool {
+		_, pattern := mux.Handler(r)
+		return pattern != ""
+	}
+}
+
+// requestLog is the logger for per-request access logs
+var requestLog = logger.Module("http")
+
+// statusRecorder captures the response status for access logs
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusRecorder) WriteHeader(code int) {
+	w.status = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (w *statusRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// LoggingMiddleware assigns each request an ID (reusing a well-formed incoming
+// X-Request-ID), stores a request-scoped logger in the context and logs API requests.
 func LoggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Skip logging for static assets
@@ -107,11 +268,56 @@ func LoggingMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
-		// Log API requests for debugging
-		if strings.HasPrefix(r.URL.Path, "/api/") {
-			log.Printf("[API] %s %s", r.Method, r.URL.Path)
+		requestID := r.Header.Get("X-Request-ID")
+		if !validRequestID(requestID) {
+			requestID = uuid.New().String()
+		}
+		w.Header().Set("X-Request-ID", requestID)
+
+		reqLogger := logger.With("request_id", requestID)
+		ctx := context.WithValue(r.Context(), RequestIDKey, requestID)
+		ctx = logger.NewContext(ctx, reqLogger)
+		r = r.WithContext(ctx)
+
+		if !strings.HasPrefix(r.URL.Path, "/api/") {
+			next.ServeHTTP(w, r)
+			return
 		}
 
-		next.ServeHTTP(w, r)
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		r = r.WithContext(sqltrace.WithCounter(r.Context()))
+		next.ServeHTTP(rec, r)
+
+		requestLog.LogAttrs(ctx, slog.LevelInfo, "request",
+			slog.String("request_id", requestID),
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", rec.status),
+			slog.Duration("duration", time.Since(start)),
+			slog.Int64("queries", sqltrace.QueryCount(r.Context())),
+		)
 	})
 }
+
+// GetRequestID returns the ID assigned by LoggingMiddleware, if any
+func GetRequestID(ctx context.Context) string {
+	if id, ok := ctx.Value(RequestIDKey).(string); ok {
+		return id
+	}
+	return ""
+}
+
+// validRequestID accepts short header values made of safe characters only,
+// so client-supplied IDs can't inject into logs
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 64 {
+		return false
+	}
+	for _, c := range id {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == '.') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,207 @@
+// Package monitor provides a heartbeat monitor that periodically self-checks form endpoints
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"headless_form/internal/adapter/webhook"
+	"headless_form/internal/core/domain"
+)
+
+// historyWindow is how long check results are kept (used for uptime percentage)
+const historyWindow = 24 * time.Hour
+
+// Endpoint states reported by Status
+const (
+	StateUp      = "up"
+	StateDown    = "down"
+	StateUnknown = "unknown"
+)
+
+// Check is the result of a single dry-run submission
+type Check struct {
+	At        time.Time `json:"at"`
+	LatencyMS int64     `json:"latency_ms"`
+	OK        bool      `json:"ok"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// Status summarizes recent checks for a form endpoint
+type Status struct {
+	FormID      string     `json:"form_id"`
+	State       string     `json:"state"` // up, down, unknown
+	LastChecked *time.Time `json:"last_checked,omitempty"`
+	LatencyMS   int64      `json:"latency_ms"`
+	Uptime24h   float64    `json:"uptime_24h"` // Percentage of successful checks in the last 24h
+	Checks24h   int        `json:"checks_24h"`
+	LastError   string     `json:"last_error,omitempty"`
+}
+
+// Service performs periodic dry-run submissions against active forms
+type Service struct {
+	client    *http.Client
+	baseURL   string
+	listForms func(ctx context.Context) ([]*domain.Form, error)
+	getForm   func(ctx context.Context, publicID string) (*domain.Form, error)
+
+	mu      sync.RWMutex
+	history map[string][]Check // form public ID -> checks within historyWindow
+}
+
+// NewService creates a new monitor that checks forms via baseURL. getForm loads a listed
+// form with its access settings, which the checks need to get past them.
+func NewService(baseURL string, listForms func(ctx context.Context) ([]*domain.Form, error), getForm func(ctx context.Context, publicID string) (*domain.Form, error)) *Service {
+	return &Service{
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		baseURL:   baseURL,
+		listForms: listForms,
+		getForm:   getForm,
+		history:   make(map[string][]Check),
+	}
+}
+
+// Start runs a check round every interval until ctx is cancelled,
+// skipping rounds while paused reports true (nil never pauses)
+func (s *Service) Start(ctx context.Context, interval time.Duration, paused func() bool) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if paused != nil && paused() {
+					continue
+				}
+				s.RunOnce(ctx)
+			}
+		}
+	}()
+}
+
+// RunOnce checks every active form once
+func (s *Service) RunOnce(ctx context.Context) {
+	forms, err := s.listForms(ctx)
+	if err != nil {
+		log.Printf("[MONITOR] Failed to list forms: %v", err)
+		return
+	}
+
+	for _, form := range forms {
+		if form.Status != domain.FormStatusActive {
+			continue
+		}
+		full, err := s.getForm(ctx, form.PublicID)
+		if err != nil {
+			s.record(form.PublicID, Check{At: time.Now(), Error: err.Error()})
+			continue
+		}
+		// Only logged in users can submit to private forms without a key; they stay unknown
+		if full.AccessMode == string(domain.AccessModePrivate) && full.SubmissionKey == "" {
+			continue
+		}
+		s.record(form.PublicID, s.check(ctx, full))
+	}
+}
+
+// check performs a dry-run submission and measures latency. Dry runs enforce the form's
+// access mode, so the check gets past it as a client would: with the submission key, or
+// for private forms by signing the request with it.
+func (s *Service) check(ctx context.Context, form *domain.Form) Check {
+	url := fmt.Sprintf("%s/api/v1/submissions/%s?dry_run=true", s.baseURL, form.PublicID)
+	start := time.Now()
+	result := Check{At: start}
+
+	body := []byte(`{}`)
+	if form.AccessMode == string(domain.AccessModeWithKey) {
+		body, _ = json.Marshal(map[string]string{"_submission_key": form.SubmissionKey})
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "HeadlessForms-Monitor/1.0")
+	if form.AccessMode == string(domain.AccessModePrivate) && form.SubmissionKey != "" {
+		timestamp := start.UTC().Format(time.RFC3339)
+		req.Header.Set("X-Form-Signature", webhook.Sign(webhook.Signing{Secret: form.SubmissionKey, Timestamped: true}, timestamp, body))
+		req.Header.Set("X-Form-Timestamp", timestamp)
+	}
+
+	resp, err := s.client.Do(req)
+	result.LatencyMS = time.Since(start).Milliseconds()
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		result.OK = true
+	} else {
+		result.Error = fmt.Sprintf("unexpected status %d", resp.StatusCode)
+	}
+	return result
+}
+
+// record stores a check result and drops results older than the history window
+func (s *Service) record(publicID string, c Check) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cutoff := time.Now().Add(-historyWindow)
+	kept := s.history[publicID][:0]
+	for _, old := range s.history[publicID] {
+		if old.At.After(cutoff) {
+			kept = append(kept, old)
+		}
+	}
+	s.history[publicID] = append(kept, c)
+}
+
+// Status returns the current health summary for a form endpoint
+func (s *Service) Status(publicID string) Status {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	status := Status{FormID: publicID, State: StateUnknown}
+	checks := s.history[publicID]
+	if len(checks) == 0 {
+		return status
+	}
+
+	ok := 0
+	for _, c := range checks {
+		if c.OK {
+			ok++
+		}
+	}
+	last := checks[len(checks)-1]
+
+	status.LastChecked = &last.At
+	status.LatencyMS = last.LatencyMS
+	status.Checks24h = len(checks)
+	status.Uptime24h = float64(ok) * 100 / float64(len(checks))
+	status.LastError = last.Error
+	if last.OK {
+		status.State = StateUp
+	} else {
+		status.State = StateDown
+	}
+	return status
+}
@@ -0,0 +1,282 @@
+// Package oauth signs dashboard users in with Google, GitHub or an OpenID Connect
+// provider: the authorization code flow with PKCE, ending in the user's verified email.
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"headless_form/internal/core/domain"
+)
+
+// Endpoints are a provider's authorization, token and user info URLs
+type Endpoints struct {
+	AuthURL  string
+	TokenURL string
+	UserURL  string
+}
+
+// Known are the endpoints of the providers that don't need discovery
+var Known = map[domain.OAuthProviderType]Endpoints{
+	domain.OAuthGoogle: {
+		AuthURL:  "https://accounts.google.com/o/oauth2/v2/auth",
+		TokenURL: "https://oauth2.googleapis.com/token",
+		UserURL:  "https://openidconnect.googleapis.com/v1/userinfo",
+	},
+	domain.OAuthGitHub: {
+		AuthURL:  "https://github.com/login/oauth/authorize",
+		TokenURL: "https://github.com/login/oauth/access_token",
+		UserURL:  "https://api.github.com/user",
+	},
+}
+
+// discoveryTTL is how long an OIDC issuer's discovery document is reused
+const discoveryTTL = time.Hour
+
+// Identity is the account a provider vouched for
+type Identity struct {
+	Subject string
+	Email   string // Verified; empty when the provider has none
+	Name    string
+}
+
+// Client talks to the sign-in providers. It is safe for concurrent use.
+type Client struct {
+	Client *http.Client
+	Known  map[domain.OAuthProviderType]Endpoints
+
+	mu        sync.Mutex
+	discovery map[string]discovered // By issuer
+}
+
+type discovered struct {
+	endpoints Endpoints
+	fetched   time.Time
+}
+
+// NewClient creates a client for the providers' public endpoints
+func NewClient() *Client {
+	return &Client{
+		Client:    &http.Client{Timeout: 10 * time.Second},
+		Known:     Known,
+		discovery: make(map[string]discovered),
+	}
+}
+
+// NewVerifier returns a random PKCE code verifier, also fine as a state value
+func NewVerifier() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// AuthCodeURL returns where to send the user to sign in with the provider
+func (c *Client) AuthCodeURL(ctx context.Context, p *domain.OAuthProvider, redirectURL, state, verifier string) (string, error) {
+	ep, err := c.endpoints(ctx, p)
+	if err != nil {
+		return "", err
+	}
+	challenge := sha256.Sum256([]byte(verifier))
+	q := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.ClientID},
+		"redirect_uri":          {redirectURL},
+		"scope":                 {strings.Join(scopes(p), " ")},
+		"state":                 {state},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+	sep := "?"
+	if strings.Contains(ep.AuthURL, "?") {
+		sep = "&"
+	}
+	return ep.AuthURL + sep + q.Encode(), nil
+}
+
+// Exchange trades the code the provider sent back for the user's identity
+func (c *Client) Exchange(ctx context.Context, p *domain.OAuthProvider, redirectURL, code, verifier string) (*Identity, error) {
+	ep, err := c.endpoints(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	token, err := c.token(ctx, p, ep, redirectURL, code, verifier)
+	if err != nil {
+		return nil, err
+	}
+	if p.Type == domain.OAuthGitHub {
+		return c.githubIdentity(ctx, ep, token)
+	}
+
+	var info struct {
+		Subject       string `json:"sub"`
+		Email         string `json:"email"`
+		EmailVerified any    `json:"email_verified"` // Some providers send "true"
+		Name          string `json:"name"`
+	}
+	if err := c.getJSON(ctx, ep.UserURL, token, &info); err != nil {
+		return nil, fmt.Errorf("oauth: %s: user info: %w", p.ID, err)
+	}
+	id := &Identity{Subject: info.Subject, Name: info.Name}
+	if info.EmailVerified == true || info.EmailVerified == "true" {
+		id.Email = info.Email
+	}
+	return id, nil
+}
+
+// token redeems the authorization code for an access token
+func (c *Client) token(ctx context.Context, p *domain.OAuthProvider, ep Endpoints, redirectURL, code, verifier string) (string, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {redirectURL},
+		"client_id":     {p.ClientID},
+		"client_secret": {p.ClientSecret},
+		"code_verifier": {verifier},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ep.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json") // GitHub answers form-encoded otherwise
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("oauth: %s: token: %w", p.ID, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var result struct {
+		AccessToken string `json:"access_token"`
+		Error       string `json:"error"`
+		Description string `json:"error_description"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&result); err != nil {
+		return "", fmt.Errorf("oauth: %s: token: answered %d", p.ID, resp.StatusCode)
+	}
+	if result.AccessToken == "" {
+		msg := strings.TrimSpace(result.Error + " " + result.Description)
+		if msg == "" {
+			msg = fmt.Sprintf("answered %d without an access token", resp.StatusCode)
+		}
+		return "", fmt.Errorf("oauth: %s: token: %s", p.ID, msg)
+	}
+	return result.AccessToken, nil
+}
+
+// githubIdentity reads the GitHub user and their primary verified email, which /user
+// leaves out when it isn't public
+func (c *Client) githubIdentity(ctx context.Context, ep Endpoints, token string) (*Identity, error) {
+	var user struct {
+		ID    int64  `json:"id"`
+		Login string `json:"login"`
+		Name  string `json:"name"`
+	}
+	if err := c.getJSON(ctx, ep.UserURL, token, &user); err != nil {
+		return nil, fmt.Errorf("oauth: github: user: %w", err)
+	}
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := c.getJSON(ctx, ep.UserURL+"/emails", token, &emails); err != nil {
+		return nil, fmt.Errorf("oauth: github: emails: %w", err)
+	}
+
+	id := &Identity{Subject: fmt.Sprint(user.ID), Name: user.Name}
+	if id.Name == "" {
+		id.Name = user.Login
+	}
+	for _, e := range emails {
+		if e.Primary && e.Verified {
+			id.Email = e.Email
+		}
+	}
+	return id, nil
+}
+
+// endpoints returns the provider's endpoints, discovering an OIDC issuer's
+func (c *Client) endpoints(ctx context.Context, p *domain.OAuthProvider) (Endpoints, error) {
+	if p.Type != domain.OAuthOIDC {
+		ep, ok := c.Known[p.Type]
+		if !ok {
+			return Endpoints{}, fmt.Errorf("oauth: unknown provider type %q", p.Type)
+		}
+		return ep, nil
+	}
+
+	c.mu.Lock()
+	d, ok := c.discovery[p.Issuer]
+	c.mu.Unlock()
+	if ok && time.Since(d.fetched) < discoveryTTL {
+		return d.endpoints, nil
+	}
+
+	var doc struct {
+		Issuer   string `json:"issuer"`
+		AuthURL  string `json:"authorization_endpoint"`
+		TokenURL string `json:"token_endpoint"`
+		UserURL  string `json:"userinfo_endpoint"`
+	}
+	if err := c.getJSON(ctx, p.Issuer+"/.well-known/openid-configuration", "", &doc); err != nil {
+		return Endpoints{}, fmt.Errorf("oauth: %s: discovery: %w", p.ID, err)
+	}
+	if strings.TrimRight(doc.Issuer, "/") != p.Issuer || doc.AuthURL == "" || doc.TokenURL == "" || doc.UserURL == "" {
+		return Endpoints{}, fmt.Errorf("oauth: %s: discovery: issuer mismatch or missing endpoints", p.ID)
+	}
+
+	ep := Endpoints{AuthURL: doc.AuthURL, TokenURL: doc.TokenURL, UserURL: doc.UserURL}
+	c.mu.Lock()
+	c.discovery[p.Issuer] = discovered{endpoints: ep, fetched: time.Now()}
+	c.mu.Unlock()
+	return ep, nil
+}
+
+// getJSON fetches a JSON document, with the access token when one is given
+func (c *Client) getJSON(ctx context.Context, u, token string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("answered %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(v); err != nil {
+		return errors.New("invalid JSON")
+	}
+	return nil
+}
+
+// scopes returns the provider's scopes, or the ones that return the user's email
+func scopes(p *domain.OAuthProvider) []string {
+	if len(p.Scopes) > 0 {
+		return p.Scopes
+	}
+	if p.Type == domain.OAuthGitHub {
+		return []string{"read:user", "user:email"}
+	}
+	return []string{"openid", "email", "profile"}
+}
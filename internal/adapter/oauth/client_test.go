@@ -0,0 +1,66 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"headless_form/internal/core/domain"
+)
+
+func TestExchange_GitHubPrimaryVerifiedEmail(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/token":
+			if r.Header.Get("Accept") != "application/json" || r.FormValue("code") != "abc" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]string{"access_token": "gho_token"})
+		case "/user":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"id": 42, "login": "ada"})
+		case "/user/emails":
+			if r.Header.Get("Authorization") != "Bearer gho_token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_ = json.NewEncoder(w).Encode([]map[string]interface{}{
+				{"email": "old@example.com", "primary": false, "verified": true},
+				{"email": "ada@example.com", "primary": true, "verified": true},
+			})
+		}
+	}))
+	defer srv.Close()
+
+	c := NewClient()
+	c.Known = map[domain.OAuthProviderType]Endpoints{
+		domain.OAuthGitHub: {AuthURL: srv.URL + "/authorize", TokenURL: srv.URL + "/token", UserURL: srv.URL + "/user"},
+	}
+	p := &domain.OAuthProvider{ID: "github", Type: domain.OAuthGitHub, ClientID: "id", ClientSecret: "secret"}
+
+	authURL, err := c.AuthCodeURL(context.Background(), p, "https://forms.example.com/cb", "state", "verifier")
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+	u, _ := url.Parse(authURL)
+	if u.Query().Get("scope") != "read:user user:email" || u.Query().Get("code_challenge") == "" {
+		t.Errorf("unexpected authorization URL %s", authURL)
+	}
+
+	id, err := c.Exchange(context.Background(), p, "https://forms.example.com/cb", "abc", "verifier")
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	if id.Email != "ada@example.com" || id.Subject != "42" || id.Name != "ada" {
+		t.Errorf("unexpected identity %+v", id)
+	}
+
+	if _, err := c.Exchange(context.Background(), p, "https://forms.example.com/cb", "wrong", "verifier"); err == nil || !strings.Contains(err.Error(), "token") {
+		t.Errorf("expected a rejected code to fail, got %v", err)
+	}
+}
@@ -0,0 +1,371 @@
+// Package parquet writes flat tables as Apache Parquet files for analytics tools
+// (DuckDB, Spark, pandas, ...). Files are uncompressed and PLAIN-encoded, one data
+// page per column chunk, which every reader supports.
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// Type is the type of a column's values
+type Type int
+
+const (
+	String    Type = iota // UTF-8 text
+	Int64                 // 64-bit integer
+	Double                // 64-bit float
+	Boolean               // true or false
+	Timestamp             // time.Time, stored as UTC milliseconds since the Unix epoch
+)
+
+// Column describes a column of the table
+type Column struct {
+	Name     string
+	Type     Type
+	Required bool // Rows may not leave it null
+}
+
+// Row groups are flushed at whichever limit is reached first
+const (
+	DefaultRowGroupRows = 10000
+	maxRowGroupBytes    = 64 << 20
+)
+
+// Parquet format enums
+const (
+	physicalBoolean   = 0
+	physicalInt64     = 2
+	physicalDouble    = 5
+	physicalByteArray = 6
+
+	convertedUTF8            = 0
+	convertedTimestampMillis = 9
+
+	repetitionRequired = 0
+	repetitionOptional = 1
+
+	encodingPlain = 0
+	encodingRLE   = 3
+
+	pageTypeData = 0
+)
+
+var magic = []byte("PAR1")
+
+// ErrClosed is returned when writing to a closed Writer
+var ErrClosed = errors.New("parquet: writer is closed")
+
+// Writer writes rows to a Parquet file. Rows are buffered in memory per row group;
+// Close must be called to write the file's footer.
+type Writer struct {
+	w            io.Writer
+	offset       int64
+	columns      []Column
+	RowGroupRows int // Rows per row group (default DefaultRowGroupRows)
+
+	chunks  []columnBuffer
+	rows    int // Rows buffered in the current row group
+	size    int // Bytes buffered in the current row group
+	groups  []rowGroup
+	numRows int64
+	closed  bool
+}
+
+// columnBuffer holds a column's values for the current row group
+type columnBuffer struct {
+	present []bool       // Per row: whether the value is set
+	values  bytes.Buffer // PLAIN-encoded set values, except booleans
+	bools   []bool       // Set values of a Boolean column
+}
+
+// rowGroup records where a flushed row group's column chunks are
+type rowGroup struct {
+	numRows int64
+	chunks  []chunkMeta
+}
+
+type chunkMeta struct {
+	offset int64 // Of the data page header
+	size   int64 // Page header and body
+}
+
+// NewWriter starts a Parquet file with the given columns on w
+func NewWriter(w io.Writer, columns []Column) (*Writer, error) {
+	if len(columns) == 0 {
+		return nil, errors.New("parquet: no columns")
+	}
+	seen := make(map[string]bool)
+	for _, col := range columns {
+		if col.Name == "" || seen[col.Name] {
+			return nil, fmt.Errorf("parquet: column names must be unique and non-empty, got %q", col.Name)
+		}
+		seen[col.Name] = true
+	}
+	pw := &Writer{w: w, columns: columns, RowGroupRows: DefaultRowGroupRows, chunks: make([]columnBuffer, len(columns))}
+	if err := pw.write(magic); err != nil {
+		return nil, err
+	}
+	return pw, nil
+}
+
+// Write adds a row with one value per column: string, int64 (or int), float64,
+// bool or time.Time depending on the column's type, or nil for a null
+func (pw *Writer) Write(row []interface{}) error {
+	if pw.closed {
+		return ErrClosed
+	}
+	if len(row) != len(pw.columns) {
+		return fmt.Errorf("parquet: row has %d values for %d columns", len(row), len(pw.columns))
+	}
+	// Checked up front so a bad value doesn't leave a partial row behind
+	for i, v := range row {
+		if err := checkValue(pw.columns[i], v); err != nil {
+			return err
+		}
+	}
+
+	for i, v := range row {
+		chunk := &pw.chunks[i]
+		chunk.present = append(chunk.present, v != nil)
+		if v == nil {
+			continue
+		}
+		before := chunk.values.Len()
+		switch val := v.(type) {
+		case string:
+			_ = binary.Write(&chunk.values, binary.LittleEndian, uint32(len(val)))
+			chunk.values.WriteString(val)
+		case int:
+			_ = binary.Write(&chunk.values, binary.LittleEndian, int64(val))
+		case int64:
+			_ = binary.Write(&chunk.values, binary.LittleEndian, val)
+		case float64:
+			_ = binary.Write(&chunk.values, binary.LittleEndian, math.Float64bits(val))
+		case bool:
+			chunk.bools = append(chunk.bools, val)
+		case time.Time:
+			_ = binary.Write(&chunk.values, binary.LittleEndian, val.UnixMilli())
+		}
+		pw.size += chunk.values.Len() - before + 1
+	}
+	pw.rows++
+
+	if pw.rows >= pw.RowGroupRows || pw.size >= maxRowGroupBytes {
+		return pw.flush()
+	}
+	return nil
+}
+
+// checkValue reports values that don't fit the column
+func checkValue(col Column, v interface{}) error {
+	if v == nil {
+		if col.Required {
+			return fmt.Errorf("parquet: column %s is required", col.Name)
+		}
+		return nil
+	}
+	ok := false
+	switch v.(type) {
+	case string:
+		ok = col.Type == String
+	case int, int64:
+		ok = col.Type == Int64
+	case float64:
+		ok = col.Type == Double
+	case bool:
+		ok = col.Type == Boolean
+	case time.Time:
+		ok = col.Type == Timestamp
+	}
+	if !ok {
+		return fmt.Errorf("parquet: unexpected %T value for column %s", v, col.Name)
+	}
+	return nil
+}
+
+// NumRows returns how many rows were written so far
+func (pw *Writer) NumRows() int64 {
+	return pw.numRows + int64(pw.rows)
+}
+
+// flush writes the buffered rows as a row group, one data page per column
+func (pw *Writer) flush() error {
+	if pw.rows == 0 {
+		return nil
+	}
+	group := rowGroup{numRows: int64(pw.rows), chunks: make([]chunkMeta, len(pw.columns))}
+	for i, col := range pw.columns {
+		chunk := &pw.chunks[i]
+
+		var body bytes.Buffer
+		if !col.Required {
+			levels := bitPackedRun(chunk.present)
+			_ = binary.Write(&body, binary.LittleEndian, uint32(len(levels)))
+			body.Write(levels)
+		}
+		if col.Type == Boolean {
+			body.Write(packBits(chunk.bools))
+		} else {
+			body.Write(chunk.values.Bytes())
+		}
+
+		var header compact
+		header.structValue(func() {
+			header.i32Field(1, pageTypeData)
+			header.i32Field(2, int32(body.Len()))
+			header.i32Field(3, int32(body.Len()))
+			header.structField(5, func() {
+				header.i32Field(1, int32(pw.rows))
+				header.i32Field(2, encodingPlain)
+				header.i32Field(3, encodingRLE)
+				header.i32Field(4, encodingRLE)
+			})
+		})
+
+		group.chunks[i] = chunkMeta{offset: pw.offset, size: int64(header.buf.Len() + body.Len())}
+		if err := pw.write(header.buf.Bytes()); err != nil {
+			return err
+		}
+		if err := pw.write(body.Bytes()); err != nil {
+			return err
+		}
+		pw.chunks[i] = columnBuffer{}
+	}
+	pw.groups = append(pw.groups, group)
+	pw.numRows += group.numRows
+	pw.rows, pw.size = 0, 0
+	return nil
+}
+
+// Close flushes the last row group and writes the footer. It doesn't close the
+// underlying writer.
+func (pw *Writer) Close() error {
+	if pw.closed {
+		return ErrClosed
+	}
+	pw.closed = true
+	if err := pw.flush(); err != nil {
+		return err
+	}
+
+	footer := pw.footer()
+	if err := pw.write(footer); err != nil {
+		return err
+	}
+	var length [4]byte
+	binary.LittleEndian.PutUint32(length[:], uint32(len(footer)))
+	if err := pw.write(length[:]); err != nil {
+		return err
+	}
+	return pw.write(magic)
+}
+
+// footer encodes the FileMetaData struct: schema and row group locations
+func (pw *Writer) footer() []byte {
+	var c compact
+	c.structValue(func() {
+		c.i32Field(1, 1) // Format version
+		c.listField(2, thriftStruct, len(pw.columns)+1)
+		c.structValue(func() {
+			c.strField(4, "schema")
+			c.i32Field(5, int32(len(pw.columns)))
+		})
+		for _, col := range pw.columns {
+			c.structValue(func() {
+				physical, converted := physicalType(col.Type)
+				c.i32Field(1, physical)
+				repetition := int32(repetitionOptional)
+				if col.Required {
+					repetition = repetitionRequired
+				}
+				c.i32Field(3, repetition)
+				c.strField(4, col.Name)
+				if converted >= 0 {
+					c.i32Field(6, converted)
+				}
+			})
+		}
+		c.i64Field(3, pw.numRows)
+
+		c.listField(4, thriftStruct, len(pw.groups))
+		for _, group := range pw.groups {
+			c.structValue(func() {
+				var total int64
+				c.listField(1, thriftStruct, len(group.chunks))
+				for i, chunk := range group.chunks {
+					total += chunk.size
+					col := pw.columns[i]
+					c.structValue(func() {
+						c.i64Field(2, chunk.offset)
+						c.structField(3, func() {
+							physical, _ := physicalType(col.Type)
+							c.i32Field(1, physical)
+							c.listField(2, thriftI32, 2)
+							c.i32(encodingPlain)
+							c.i32(encodingRLE)
+							c.listField(3, thriftBinary, 1)
+							c.str(col.Name)
+							c.i32Field(4, 0) // Uncompressed
+							c.i64Field(5, group.numRows)
+							c.i64Field(6, chunk.size)
+							c.i64Field(7, chunk.size)
+							c.i64Field(9, chunk.offset)
+						})
+					})
+				}
+				c.i64Field(2, total)
+				c.i64Field(3, group.numRows)
+			})
+		}
+		c.strField(6, "headlessforms")
+	})
+	return c.buf.Bytes()
+}
+
+// physicalType maps a column type to its Parquet physical and converted types
+// (-1 when there's no converted type)
+func physicalType(t Type) (physical, converted int32) {
+	switch t {
+	case Int64:
+		return physicalInt64, -1
+	case Double:
+		return physicalDouble, -1
+	case Boolean:
+		return physicalBoolean, -1
+	case Timestamp:
+		return physicalInt64, convertedTimestampMillis
+	default:
+		return physicalByteArray, convertedUTF8
+	}
+}
+
+// bitPackedRun encodes definition levels (bit width 1) as a single bit-packed
+// run of the RLE/bit-packing hybrid encoding
+func bitPackedRun(present []bool) []byte {
+	groups := (len(present) + 7) / 8
+	out := binary.AppendUvarint(nil, uint64(groups)<<1|1)
+	return append(out, packBits(present)...)
+}
+
+// packBits packs booleans LSB first, padding the last byte with zeros
+func packBits(bits []bool) []byte {
+	out := make([]byte, (len(bits)+7)/8)
+	for i, b := range bits {
+		if b {
+			out[i/8] |= 1 << (i % 8)
+		}
+	}
+	return out
+}
+
+func (pw *Writer) write(b []byte) error {
+	n, err := pw.w.Write(b)
+	pw.offset += int64(n)
+	return err
+}
@@ -0,0 +1,212 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+)
+
+// thriftReader decodes compact-protocol structs into field id -> value maps
+type thriftReader struct {
+	b   []byte
+	pos int
+}
+
+func (r *thriftReader) uvarint() uint64 {
+	v, n := binary.Uvarint(r.b[r.pos:])
+	r.pos += n
+	return v
+}
+
+func (r *thriftReader) zigzag() int64 {
+	v := r.uvarint()
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+func (r *thriftReader) readStruct() map[int16]interface{} {
+	fields := make(map[int16]interface{})
+	var last int16
+	for {
+		h := r.b[r.pos]
+		r.pos++
+		if h == 0 {
+			return fields
+		}
+		id := last + int16(h>>4)
+		if h>>4 == 0 {
+			id = int16(r.zigzag())
+		}
+		last = id
+		fields[id] = r.value(h & 0x0f)
+	}
+}
+
+func (r *thriftReader) value(typ byte) interface{} {
+	switch typ {
+	case thriftI32, thriftI64:
+		return r.zigzag()
+	case thriftBinary:
+		n := int(r.uvarint())
+		s := string(r.b[r.pos : r.pos+n])
+		r.pos += n
+		return s
+	case thriftList:
+		h := r.b[r.pos]
+		r.pos++
+		n := int(h >> 4)
+		if n == 15 {
+			n = int(r.uvarint())
+		}
+		list := make([]interface{}, n)
+		for i := range list {
+			list[i] = r.value(h & 0x0f)
+		}
+		return list
+	case thriftStruct:
+		return r.readStruct()
+	}
+	panic("unexpected thrift type")
+}
+
+// readColumn decodes a column chunk's data page back into values (nil for nulls)
+func readColumn(t *testing.T, file []byte, offset int64, col Column) []interface{} {
+	t.Helper()
+	r := &thriftReader{b: file, pos: int(offset)}
+	header := r.readStruct()
+	numValues := int(header[5].(map[int16]interface{})[1].(int64))
+	body := file[r.pos : r.pos+int(header[3].(int64))]
+
+	present := make([]bool, numValues)
+	for i := range present {
+		present[i] = true
+	}
+	if !col.Required {
+		length := int(binary.LittleEndian.Uint32(body))
+		levels := body[4 : 4+length]
+		run, n := binary.Uvarint(levels)
+		if run&1 != 1 {
+			t.Fatalf("expected a bit-packed run, got header %d", run)
+		}
+		for i := range present {
+			present[i] = levels[n+i/8]&(1<<(i%8)) != 0
+		}
+		body = body[4+length:]
+	}
+
+	values := make([]interface{}, numValues)
+	bit := 0
+	for i := range values {
+		if !present[i] {
+			continue
+		}
+		switch col.Type {
+		case String:
+			n := int(binary.LittleEndian.Uint32(body))
+			values[i] = string(body[4 : 4+n])
+			body = body[4+n:]
+		case Int64, Timestamp:
+			values[i] = int64(binary.LittleEndian.Uint64(body))
+			body = body[8:]
+		case Double:
+			values[i] = math.Float64frombits(binary.LittleEndian.Uint64(body))
+			body = body[8:]
+		case Boolean:
+			values[i] = body[bit/8]&(1<<(bit%8)) != 0
+			bit++
+		}
+	}
+	return values
+}
+
+func TestWriter(t *testing.T) {
+	columns := []Column{
+		{Name: "id", Type: String, Required: true},
+		{Name: "created_at", Type: Timestamp, Required: true},
+		{Name: "age", Type: Double},
+		{Name: "subscribed", Type: Boolean},
+		{Name: "score", Type: Int64},
+		{Name: "comment", Type: String},
+	}
+	at := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	rows := [][]interface{}{
+		{"sub_1", at, 42.5, true, int64(3), "hello"},
+		{"sub_2", at.Add(time.Second), nil, false, nil, nil},
+		{"sub_3", at.Add(time.Minute), 7.0, nil, 12, "ünïcode"},
+	}
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, columns)
+	if err != nil {
+		t.Fatalf("new writer: %v", err)
+	}
+	w.RowGroupRows = 2
+	for _, row := range rows {
+		if err := w.Write(row); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	if err := w.Write([]interface{}{nil, at, nil, nil, nil, nil}); err == nil {
+		t.Error("expected a null in a required column to be rejected")
+	}
+	if err := w.Write([]interface{}{"sub_4", at, "42", nil, nil, nil}); err == nil {
+		t.Error("expected a string in a Double column to be rejected")
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	file := buf.Bytes()
+	if !bytes.HasPrefix(file, magic) || !bytes.HasSuffix(file, magic) {
+		t.Fatal("expected PAR1 at both ends")
+	}
+	footerLen := int(binary.LittleEndian.Uint32(file[len(file)-8:]))
+	footer := (&thriftReader{b: file[len(file)-8-footerLen : len(file)-8]}).readStruct()
+
+	if footer[3].(int64) != 3 {
+		t.Errorf("expected 3 rows, got %v", footer[3])
+	}
+	schema := footer[2].([]interface{})
+	if len(schema) != len(columns)+1 || schema[0].(map[int16]interface{})[5].(int64) != int64(len(columns)) {
+		t.Fatalf("unexpected schema %v", schema)
+	}
+	if created := schema[2].(map[int16]interface{}); created[4] != "created_at" || created[1].(int64) != physicalInt64 || created[6].(int64) != convertedTimestampMillis {
+		t.Errorf("unexpected created_at schema %v", created)
+	}
+
+	groups := footer[4].([]interface{})
+	if len(groups) != 2 {
+		t.Fatalf("expected 2 row groups, got %d", len(groups))
+	}
+	got := make([][]interface{}, len(columns))
+	for _, g := range groups {
+		chunks := g.(map[int16]interface{})[1].([]interface{})
+		for i, chunk := range chunks {
+			meta := chunk.(map[int16]interface{})[3].(map[int16]interface{})
+			got[i] = append(got[i], readColumn(t, file, meta[9].(int64), columns[i])...)
+		}
+	}
+
+	want := [][]interface{}{
+		{"sub_1", "sub_2", "sub_3"},
+		{at.UnixMilli(), at.Add(time.Second).UnixMilli(), at.Add(time.Minute).UnixMilli()},
+		{42.5, nil, 7.0},
+		{true, false, nil},
+		{int64(3), nil, int64(12)},
+		{"hello", nil, "ünïcode"},
+	}
+	for i := range want {
+		for j := range want[i] {
+			if got[i][j] != want[i][j] {
+				t.Errorf("%s row %d: expected %v, got %v", columns[i].Name, j, want[i][j], got[i][j])
+			}
+		}
+	}
+}
+
+func TestNewWriter_DuplicateColumns(t *testing.T) {
+	if _, err := NewWriter(&bytes.Buffer{}, []Column{{Name: "a"}, {Name: "a"}}); err == nil {
+		t.Error("expected duplicate column names to be rejected")
+	}
+}
@@ -0,0 +1,92 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// Thrift compact protocol type ids
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// compact encodes the Thrift structs of Parquet page headers and file metadata
+// with the compact protocol. Fields must be written in increasing id order.
+type compact struct {
+	buf   bytes.Buffer
+	last  int16   // Id of the previous field in the current struct
+	stack []int16 // last of the enclosing structs
+}
+
+func (c *compact) uvarint(v uint64) {
+	c.buf.Write(binary.AppendUvarint(nil, v))
+}
+
+func (c *compact) field(id int16, typ byte) {
+	if delta := id - c.last; delta > 0 && delta <= 15 {
+		c.buf.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		c.buf.WriteByte(typ)
+		c.uvarint(uint64(uint16((id << 1) ^ (id >> 15))))
+	}
+	c.last = id
+}
+
+func (c *compact) i32(v int32) {
+	c.uvarint(uint64(uint32((v << 1) ^ (v >> 31))))
+}
+
+func (c *compact) i64(v int64) {
+	c.uvarint(uint64((v << 1) ^ (v >> 63)))
+}
+
+func (c *compact) str(s string) {
+	c.uvarint(uint64(len(s)))
+	c.buf.WriteString(s)
+}
+
+func (c *compact) i32Field(id int16, v int32) {
+	c.field(id, thriftI32)
+	c.i32(v)
+}
+
+func (c *compact) i64Field(id int16, v int64) {
+	c.field(id, thriftI64)
+	c.i64(v)
+}
+
+func (c *compact) strField(id int16, s string) {
+	c.field(id, thriftBinary)
+	c.str(s)
+}
+
+// listField starts a list field of n elements; write the elements right after
+func (c *compact) listField(id int16, elemType byte, n int) {
+	c.field(id, thriftList)
+	if n < 15 {
+		c.buf.WriteByte(byte(n)<<4 | elemType)
+	} else {
+		c.buf.WriteByte(0xF0 | elemType)
+		c.uvarint(uint64(n))
+	}
+}
+
+// structField writes a nested struct whose fields are written by fn
+func (c *compact) structField(id int16, fn func()) {
+	c.field(id, thriftStruct)
+	c.structValue(fn)
+}
+
+// structValue writes a struct as a list element, or the top-level struct
+func (c *compact) structValue(fn func()) {
+	c.stack = append(c.stack, c.last)
+	c.last = 0
+	fn()
+	c.buf.WriteByte(0) // Stop
+	c.last = c.stack[len(c.stack)-1]
+	c.stack = c.stack[:len(c.stack)-1]
+}
@@ -0,0 +1,301 @@
+// Package pdf renders submissions as simple printable PDF documents.
+//
+// It writes PDF 1.4 directly using the standard Helvetica fonts, so there are
+// no external dependencies. Text outside the WinAnsi (Latin-1) range is
+// replaced with "?".
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"headless_form/internal/core/domain"
+)
+
+// Page layout (A4, points)
+const (
+	pageWidth    = 595.0
+	pageHeight   = 842.0
+	margin       = 50.0
+	titleSize    = 18.0
+	subtitleSize = 10.0
+	labelSize    = 10.0
+	valueSize    = 11.0
+	lineGap      = 1.4 // line height as a multiple of font size
+)
+
+// Field is a labelled value in the document
+type Field struct {
+	Label string
+	Value string
+}
+
+// Document is the content of a rendered PDF
+type Document struct {
+	Title    string
+	Subtitle string
+	Fields   []Field
+}
+
+// SubmissionDocument builds a document for a submission. Fields in the form's
+// schema come first, in schema order, then the rest sorted by key. When humanize
+// is true, fields are shown with their schema label in locale ("" for the default
+// text), or with keys like "first_name" as "First name".
+func SubmissionDocument(form *domain.Form, submission *domain.Submission, data map[string]interface{}, humanize bool, locale string) Document {
+	keys := make([]string, 0, len(data))
+	for _, f := range form.Fields {
+		if _, ok := data[f.Name]; ok {
+			keys = append(keys, f.Name)
+		}
+	}
+	schemaKeys := len(keys)
+	for k := range data {
+		if form.Field(k) == nil {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys[schemaKeys:])
+
+	labels := form.Labels(locale)
+	fields := make([]Field, 0, len(keys))
+	for _, k := range keys {
+		label := k
+		if humanize {
+			if label = labels[k]; label == "" {
+				label = HumanizeKey(k)
+			}
+		}
+		fields = append(fields, Field{Label: label, Value: formatValue(data[k])})
+	}
+
+	return Document{
+		Title:    form.Name,
+		Subtitle: fmt.Sprintf("Submission %s - %s", submission.ID, submission.CreatedAt.UTC().Format("2006-01-02 15:04 UTC")),
+		Fields:   fields,
+	}
+}
+
+// HumanizeKey turns a field key such as "first_name" or "firstName" into "First name"
+func HumanizeKey(key string) string {
+	var b strings.Builder
+	prevLower := false
+	for _, r := range key {
+		switch {
+		case r == '_' || r == '-' || r == '.':
+			b.WriteRune(' ')
+			prevLower = false
+			continue
+		case unicode.IsUpper(r) && prevLower:
+			b.WriteRune(' ')
+		}
+		b.WriteRune(unicode.ToLower(r))
+		prevLower = unicode.IsLower(r) || unicode.IsDigit(r)
+	}
+	label := strings.Join(strings.Fields(b.String()), " ")
+	if label == "" {
+		return key
+	}
+	return strings.ToUpper(label[:1]) + label[1:]
+}
+
+// formatValue renders a submission value as text
+func formatValue(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case []interface{}:
+		parts := make([]string, len(val))
+		for i, item := range val {
+			parts[i] = formatValue(item)
+		}
+		return strings.Join(parts, ", ")
+	case float64:
+		if val == float64(int64(val)) {
+			return fmt.Sprintf("%d", int64(val))
+		}
+		return fmt.Sprintf("%g", val)
+	case map[string]interface{}:
+		// Uploaded files are shown by name
+		if _, ok := val["file_id"]; ok {
+			if name, ok := val["name"].(string); ok {
+				return name
+			}
+		}
+		return fmt.Sprint(val)
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+// Render produces the PDF bytes for a document
+func Render(doc Document) []byte {
+	pages := layout(doc)
+
+	var out bytes.Buffer
+	var offsets []int
+	startObj := func() int {
+		offsets = append(offsets, out.Len())
+		return len(offsets)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// Fixed objects: 1 catalog, 2 page tree, 3 regular font, 4 bold font.
+	// Each page then takes two objects: the page and its content stream.
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+i*2)
+	}
+
+	startObj()
+	out.WriteString("1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>\nendobj\n")
+	startObj()
+	fmt.Fprintf(&out, "2 0 obj\n<< /Type /Pages /Kids [%s] /Count %d >>\nendobj\n", strings.Join(kids, " "), len(pages))
+	startObj()
+	out.WriteString("3 0 obj\n<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>\nendobj\n")
+	startObj()
+	out.WriteString("4 0 obj\n<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>\nendobj\n")
+
+	for _, content := range pages {
+		pageObj := startObj()
+		fmt.Fprintf(&out, "%d 0 obj\n<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>\nendobj\n",
+			pageObj, pageWidth, pageHeight, pageObj+1)
+		contentObj := startObj()
+		fmt.Fprintf(&out, "%d 0 obj\n<< /Length %d >>\nstream\n", contentObj, len(content))
+		out.Write(content)
+		out.WriteString("\nendstream\nendobj\n")
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return out.Bytes()
+}
+
+// layout splits the document into page content streams
+func layout(doc Document) [][]byte {
+	var pages [][]byte
+	var page bytes.Buffer
+	y := pageHeight - margin
+
+	newPage := func() {
+		pages = append(pages, append([]byte(nil), page.Bytes()...))
+		page.Reset()
+		y = pageHeight - margin
+	}
+	line := func(font string, size float64, text string) {
+		if y-size < margin {
+			newPage()
+		}
+		y -= size
+		fmt.Fprintf(&page, "BT /%s %.0f Tf %.1f %.1f Td (%s) Tj ET\n", font, size, margin, y, escape(text))
+		y -= size * (lineGap - 1)
+	}
+
+	for _, l := range wrap(doc.Title, titleSize) {
+		line("F2", titleSize, l)
+	}
+	if doc.Subtitle != "" {
+		line("F1", subtitleSize, doc.Subtitle)
+	}
+	y -= valueSize
+
+	for _, f := range doc.Fields {
+		// Keep a label together with at least the first line of its value
+		if y-(labelSize+valueSize)*lineGap < margin {
+			newPage()
+		}
+		line("F2", labelSize, f.Label)
+		value := f.Value
+		if value == "" {
+			value = "-"
+		}
+		for _, paragraph := range strings.Split(value, "\n") {
+			for _, l := range wrap(paragraph, valueSize) {
+				line("F1", valueSize, l)
+			}
+		}
+		y -= valueSize * 0.6
+	}
+
+	if page.Len() > 0 || len(pages) == 0 {
+		newPage()
+	}
+	return pages
+}
+
+// wrap breaks text into lines that fit the page width, using an average Helvetica glyph width
+func wrap(text string, size float64) []string {
+	maxChars := int((pageWidth - 2*margin) / (size * 0.52))
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		return []string{""}
+	}
+
+	var lines []string
+	current := ""
+	for _, word := range words {
+		for len([]rune(word)) > maxChars {
+			if current != "" {
+				lines = append(lines, current)
+				current = ""
+			}
+			r := []rune(word)
+			lines = append(lines, string(r[:maxChars]))
+			word = string(r[maxChars:])
+		}
+		switch {
+		case current == "":
+			current = word
+		case len([]rune(current))+1+len([]rune(word)) <= maxChars:
+			current += " " + word
+		default:
+			lines = append(lines, current)
+			current = word
+		}
+	}
+	if current != "" {
+		lines = append(lines, current)
+	}
+	return lines
+}
+
+// escape encodes text as a WinAnsi PDF string literal body
+func escape(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\t':
+			b.WriteByte(' ')
+		case r < 0x20:
+			// drop control characters
+		case r < 0x80 || (r >= 0xA0 && r <= 0xFF):
+			b.WriteByte(byte(r))
+		default:
+			if c, ok := winAnsi[r]; ok {
+				b.WriteByte(c)
+			} else {
+				b.WriteByte('?')
+			}
+		}
+	}
+	return b.String()
+}
+
+// winAnsi maps common punctuation outside Latin-1 to WinAnsiEncoding bytes
+var winAnsi = map[rune]byte{
+	'€': 0x80, '‚': 0x82, '„': 0x84, '…': 0x85, '‘': 0x91, '’': 0x92,
+	'“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '™': 0x99,
+}
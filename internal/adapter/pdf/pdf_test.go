@@ -0,0 +1,97 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"headless_form/internal/core/domain"
+)
+
+func TestHumanizeKey(t *testing.T) {
+	tests := map[string]string{
+		"first_name": "First name",
+		"firstName":  "First name",
+		"email":      "Email",
+		"zip-code":   "Zip code",
+		"_":          "_",
+	}
+	for in, want := range tests {
+		if got := HumanizeKey(in); got != want {
+			t.Errorf("HumanizeKey(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestSubmissionDocument_Labels(t *testing.T) {
+	form := &domain.Form{Name: "Contact", Fields: []domain.FormField{
+		{Name: "message", FieldText: domain.FieldText{Label: "Your message"}, Translations: map[string]domain.FieldText{"de": {Label: "Ihre Nachricht"}}},
+		{Name: "email", FieldText: domain.FieldText{Label: "Email address"}},
+	}}
+	sub := &domain.Submission{ID: "sub_1"}
+	data := map[string]interface{}{"email": "a@example.com", "message": "Hi", "zip_code": "12345", "age": 30}
+
+	labels := func(doc Document) string {
+		var out []string
+		for _, f := range doc.Fields {
+			out = append(out, f.Label)
+		}
+		return strings.Join(out, ",")
+	}
+	// Schema fields first in schema order, then the rest by key
+	if got := labels(SubmissionDocument(form, sub, data, true, "")); got != "Your message,Email address,Age,Zip code" {
+		t.Errorf("unexpected default labels %q", got)
+	}
+	if got := labels(SubmissionDocument(form, sub, data, true, "de")); got != "Ihre Nachricht,Email address,Age,Zip code" {
+		t.Errorf("unexpected German labels %q", got)
+	}
+	if got := labels(SubmissionDocument(form, sub, data, false, "de")); got != "message,email,age,zip_code" {
+		t.Errorf("unexpected raw labels %q", got)
+	}
+}
+
+func TestRender_ValidStructure(t *testing.T) {
+	doc := Document{Title: "Contact (EU)", Subtitle: "Submission sub_1"}
+	for i := 0; i < 80; i++ {
+		doc.Fields = append(doc.Fields, Field{Label: fmt.Sprintf("Field %d", i), Value: strings.Repeat("word ", 30)})
+	}
+
+	out := Render(doc)
+	if !bytes.HasPrefix(out, []byte("%PDF-1.4")) || !bytes.HasSuffix(out, []byte("%%EOF\n")) {
+		t.Fatal("missing PDF header or trailer")
+	}
+	if !bytes.Contains(out, []byte(`(Contact \(EU\)) Tj`)) {
+		t.Error("expected escaped title in content stream")
+	}
+
+	// Long documents must span several pages
+	count := regexp.MustCompile(`/Count (\d+)`).FindSubmatch(out)
+	if n, _ := strconv.Atoi(string(count[1])); n < 2 {
+		t.Errorf("expected multiple pages, got %d", n)
+	}
+
+	// startxref must point at the xref table
+	m := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(out)
+	off, _ := strconv.Atoi(string(m[1]))
+	if !bytes.HasPrefix(out[off:], []byte("xref\n")) {
+		t.Error("startxref does not point at xref table")
+	}
+
+	// Every xref entry must point at its object header
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(out, -1)
+	for i, e := range entries {
+		objOff, _ := strconv.Atoi(string(e[1]))
+		if !bytes.HasPrefix(out[objOff:], []byte(fmt.Sprintf("%d 0 obj", i+1))) {
+			t.Errorf("xref entry %d points at wrong offset", i+1)
+		}
+	}
+}
+
+func TestEscape(t *testing.T) {
+	if got := escape("a\\b “c” 日"); got != "a\\\\b \x93c\x94 ?" {
+		t.Errorf("unexpected escape result %q", got)
+	}
+}
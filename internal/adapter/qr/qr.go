@@ -0,0 +1,526 @@
+// Package qr encodes text as a QR code (ISO/IEC 18004, byte mode, versions 1-10)
+// and renders it as SVG or PNG.
+//
+// Versions 1-10 hold up to 271 bytes at level L (213 at M), enough for form URLs.
+package qr
+
+import (
+	"errors"
+	"strings"
+)
+
+// Level is the error correction level
+type Level int
+
+const (
+	LevelL Level = iota // ~7% recovery
+	LevelM              // ~15% recovery
+	LevelQ              // ~25% recovery
+	LevelH              // ~30% recovery
+)
+
+// MaxVersion is the largest supported QR version
+const MaxVersion = 10
+
+// ErrTooLong is returned when the text doesn't fit in a version 10 symbol
+var ErrTooLong = errors.New("text too long for QR code")
+
+// ParseLevel parses "L", "M", "Q" or "H" (case-insensitive), defaulting to M
+func ParseLevel(s string) Level {
+	switch strings.ToUpper(s) {
+	case "L":
+		return LevelL
+	case "Q":
+		return LevelQ
+	case "H":
+		return LevelH
+	}
+	return LevelM
+}
+
+// formatBits are the two error correction bits used in format information
+func (l Level) formatBits() int {
+	return [...]int{1, 0, 3, 2}[l]
+}
+
+// blockSpec describes the error correction block layout for one version/level
+type blockSpec struct {
+	ecPerBlock int
+	g1Blocks   int
+	g1Data     int
+	g2Blocks   int
+	g2Data     int
+}
+
+func (b blockSpec) dataCodewords() int {
+	return b.g1Blocks*b.g1Data + b.g2Blocks*b.g2Data
+}
+
+// blockSpecs[version-1][level]
+var blockSpecs = [MaxVersion][4]blockSpec{
+	{{7, 1, 19, 0, 0}, {10, 1, 16, 0, 0}, {13, 1, 13, 0, 0}, {17, 1, 9, 0, 0}},
+	{{10, 1, 34, 0, 0}, {16, 1, 28, 0, 0}, {22, 1, 22, 0, 0}, {28, 1, 16, 0, 0}},
+	{{15, 1, 55, 0, 0}, {26, 1, 44, 0, 0}, {18, 2, 17, 0, 0}, {22, 2, 13, 0, 0}},
+	{{20, 1, 80, 0, 0}, {18, 2, 32, 0, 0}, {26, 2, 24, 0, 0}, {16, 4, 9, 0, 0}},
+	{{26, 1, 108, 0, 0}, {24, 2, 43, 0, 0}, {18, 2, 15, 2, 16}, {22, 2, 11, 2, 12}},
+	{{18, 2, 68, 0, 0}, {16, 4, 27, 0, 0}, {24, 4, 19, 0, 0}, {28, 4, 15, 0, 0}},
+	{{20, 2, 78, 0, 0}, {18, 4, 31, 0, 0}, {18, 2, 14, 4, 15}, {26, 4, 13, 1, 14}},
+	{{24, 2, 97, 0, 0}, {22, 2, 38, 2, 39}, {22, 4, 18, 2, 19}, {26, 4, 14, 2, 15}},
+	{{30, 2, 116, 0, 0}, {22, 3, 36, 2, 37}, {20, 4, 16, 4, 17}, {24, 4, 12, 4, 13}},
+	{{18, 2, 68, 2, 69}, {26, 4, 43, 1, 44}, {24, 6, 19, 2, 20}, {28, 6, 15, 2, 16}},
+}
+
+// alignmentPositions[version-1] lists alignment pattern centre coordinates
+var alignmentPositions = [MaxVersion][]int{
+	nil, {6, 18}, {6, 22}, {6, 26}, {6, 30}, {6, 34},
+	{6, 22, 38}, {6, 24, 42}, {6, 26, 46}, {6, 28, 50},
+}
+
+// Code is an encoded QR symbol
+type Code struct {
+	Version int
+	Size    int // modules per side, excluding quiet zone
+	modules [][]bool
+}
+
+// Dark reports whether the module at column x, row y is dark
+func (c *Code) Dark(x, y int) bool {
+	return c.modules[y][x]
+}
+
+// Encode encodes text in byte mode using the smallest version that fits
+func Encode(text string, level Level) (*Code, error) {
+	data := []byte(text)
+
+	version := 0
+	for v := 1; v <= MaxVersion; v++ {
+		countBits := 8
+		if v >= 10 {
+			countBits = 16
+		}
+		if 4+countBits+8*len(data) <= blockSpecs[v-1][level].dataCodewords()*8 {
+			version = v
+			break
+		}
+	}
+	if version == 0 {
+		return nil, ErrTooLong
+	}
+
+	codewords := addErrorCorrection(encodeData(data, version, level), blockSpecs[version-1][level])
+
+	m := newMatrix(version)
+	m.drawFunctionPatterns()
+	m.placeCodewords(codewords)
+
+	// Pick the mask with the lowest penalty
+	bestMask, bestPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		m.applyMask(mask)
+		m.drawFormatBits(level, mask)
+		if p := m.penalty(); bestPenalty < 0 || p < bestPenalty {
+			bestMask, bestPenalty = mask, p
+		}
+		m.applyMask(mask) // XOR again to undo
+	}
+	m.applyMask(bestMask)
+	m.drawFormatBits(level, bestMask)
+
+	return &Code{Version: version, Size: m.size, modules: m.modules}, nil
+}
+
+// encodeData builds the data codewords: mode, length, payload, terminator and padding
+func encodeData(data []byte, version int, level Level) []byte {
+	capacity := blockSpecs[version-1][level].dataCodewords()
+	var bb bitBuffer
+
+	bb.append(0x4, 4) // byte mode
+	if version >= 10 {
+		bb.append(len(data), 16)
+	} else {
+		bb.append(len(data), 8)
+	}
+	for _, b := range data {
+		bb.append(int(b), 8)
+	}
+
+	// Terminator (up to 4 zero bits), then pad to a byte boundary
+	if remaining := capacity*8 - bb.len(); remaining < 4 {
+		bb.append(0, remaining)
+	} else {
+		bb.append(0, 4)
+	}
+	if r := bb.len() % 8; r != 0 {
+		bb.append(0, 8-r)
+	}
+
+	out := bb.bytes()
+	for pad := 0; len(out) < capacity; pad++ {
+		out = append(out, [...]byte{0xEC, 0x11}[pad%2])
+	}
+	return out
+}
+
+// addErrorCorrection splits data into blocks, appends Reed-Solomon codewords and interleaves
+func addErrorCorrection(data []byte, spec blockSpec) []byte {
+	var blocks, ecBlocks [][]byte
+	offset := 0
+	for i := 0; i < spec.g1Blocks+spec.g2Blocks; i++ {
+		n := spec.g1Data
+		if i >= spec.g1Blocks {
+			n = spec.g2Data
+		}
+		block := data[offset : offset+n]
+		offset += n
+		blocks = append(blocks, block)
+		ecBlocks = append(ecBlocks, reedSolomon(block, spec.ecPerBlock))
+	}
+
+	var out []byte
+	maxData := spec.g1Data
+	if spec.g2Data > maxData {
+		maxData = spec.g2Data
+	}
+	for i := 0; i < maxData; i++ {
+		for _, b := range blocks {
+			if i < len(b) {
+				out = append(out, b[i])
+			}
+		}
+	}
+	for i := 0; i < spec.ecPerBlock; i++ {
+		for _, ec := range ecBlocks {
+			out = append(out, ec[i])
+		}
+	}
+	return out
+}
+
+// =============================================================================
+// Reed-Solomon over GF(256), primitive polynomial x^8+x^4+x^3+x^2+1
+// =============================================================================
+
+var gfExp, gfLog [512]byte
+
+func init() {
+	x := 1
+	for i := 0; i < 255; i++ {
+		gfExp[i] = byte(x)
+		gfLog[x] = byte(i)
+		x <<= 1
+		if x&0x100 != 0 {
+			x ^= 0x11D
+		}
+	}
+	for i := 255; i < 512; i++ {
+		gfExp[i] = gfExp[i-255]
+	}
+}
+
+func gfMul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gfExp[int(gfLog[a])+int(gfLog[b])]
+}
+
+// reedSolomon returns n error correction codewords for data
+func reedSolomon(data []byte, n int) []byte {
+	// Generator polynomial: product of (x - a^i) for i in [0, n)
+	gen := []byte{1}
+	for i := 0; i < n; i++ {
+		next := make([]byte, len(gen)+1)
+		for j, c := range gen {
+			next[j] ^= c
+			next[j+1] ^= gfMul(c, gfExp[i])
+		}
+		gen = next
+	}
+
+	rem := make([]byte, n)
+	for _, b := range data {
+		factor := b ^ rem[0]
+		copy(rem, rem[1:])
+		rem[n-1] = 0
+		for j := 0; j < n; j++ {
+			rem[j] ^= gfMul(gen[j+1], factor)
+		}
+	}
+	return rem
+}
+
+// =============================================================================
+// Matrix construction
+// =============================================================================
+
+type matrix struct {
+	version    int
+	size       int
+	modules    [][]bool
+	isFunction [][]bool
+}
+
+func newMatrix(version int) *matrix {
+	size := 17 + 4*version
+	m := &matrix{version: version, size: size}
+	m.modules = make([][]bool, size)
+	m.isFunction = make([][]bool, size)
+	for i := range m.modules {
+		m.modules[i] = make([]bool, size)
+		m.isFunction[i] = make([]bool, size)
+	}
+	return m
+}
+
+func (m *matrix) setFunction(x, y int, dark bool) {
+	m.modules[y][x] = dark
+	m.isFunction[y][x] = true
+}
+
+func (m *matrix) drawFunctionPatterns() {
+	// Timing patterns
+	for i := 0; i < m.size; i++ {
+		m.setFunction(6, i, i%2 == 0)
+		m.setFunction(i, 6, i%2 == 0)
+	}
+
+	// Finder patterns with separators
+	m.drawFinder(3, 3)
+	m.drawFinder(m.size-4, 3)
+	m.drawFinder(3, m.size-4)
+
+	// Alignment patterns, skipping the three finder corners
+	pos := alignmentPositions[m.version-1]
+	for i, x := range pos {
+		for j, y := range pos {
+			last := len(pos) - 1
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+			for dy := -2; dy <= 2; dy++ {
+				for dx := -2; dx <= 2; dx++ {
+					m.setFunction(x+dx, y+dy, max(abs(dx), abs(dy)) != 1)
+				}
+			}
+		}
+	}
+
+	// Reserve format areas (real bits are drawn after masking) and version info
+	m.drawFormatBits(LevelL, 0)
+	m.drawVersion()
+}
+
+func (m *matrix) drawFinder(cx, cy int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			x, y := cx+dx, cy+dy
+			if x < 0 || x >= m.size || y < 0 || y >= m.size {
+				continue
+			}
+			dist := max(abs(dx), abs(dy))
+			m.setFunction(x, y, dist != 2 && dist != 4)
+		}
+	}
+}
+
+func (m *matrix) drawFormatBits(level Level, mask int) {
+	data := level.formatBits()<<3 | mask
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = (rem << 1) ^ ((rem >> 9) * 0x537)
+	}
+	bits := (data<<10 | rem) ^ 0x5412
+	bit := func(i int) bool { return (bits>>i)&1 != 0 }
+
+	// First copy, around the top-left finder
+	for i := 0; i <= 5; i++ {
+		m.setFunction(8, i, bit(i))
+	}
+	m.setFunction(8, 7, bit(6))
+	m.setFunction(8, 8, bit(7))
+	m.setFunction(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		m.setFunction(14-i, 8, bit(i))
+	}
+
+	// Second copy, split between the top-right and bottom-left finders
+	for i := 0; i < 8; i++ {
+		m.setFunction(m.size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		m.setFunction(8, m.size-15+i, bit(i))
+	}
+	m.setFunction(8, m.size-8, true) // always-dark module
+}
+
+func (m *matrix) drawVersion() {
+	if m.version < 7 {
+		return
+	}
+	rem := m.version
+	for i := 0; i < 12; i++ {
+		rem = (rem << 1) ^ ((rem >> 11) * 0x1F25)
+	}
+	bits := m.version<<12 | rem
+	for i := 0; i < 18; i++ {
+		dark := (bits>>i)&1 != 0
+		a, b := m.size-11+i%3, i/3
+		m.setFunction(a, b, dark)
+		m.setFunction(b, a, dark)
+	}
+}
+
+// placeCodewords fills non-function modules in the zigzag order
+func (m *matrix) placeCodewords(data []byte) {
+	i := 0
+	for right := m.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5 // skip the vertical timing pattern
+		}
+		for vert := 0; vert < m.size; vert++ {
+			for j := 0; j < 2; j++ {
+				x := right - j
+				y := vert
+				if (right+1)&2 == 0 {
+					y = m.size - 1 - vert // upward column pair
+				}
+				if !m.isFunction[y][x] && i < len(data)*8 {
+					m.modules[y][x] = (data[i>>3]>>(7-uint(i&7)))&1 != 0
+					i++
+				}
+				// Remainder bits stay light
+			}
+		}
+	}
+}
+
+// applyMask XORs the mask pattern onto non-function modules
+func (m *matrix) applyMask(mask int) {
+	for y := 0; y < m.size; y++ {
+		for x := 0; x < m.size; x++ {
+			if m.isFunction[y][x] {
+				continue
+			}
+			var invert bool
+			switch mask {
+			case 0:
+				invert = (x+y)%2 == 0
+			case 1:
+				invert = y%2 == 0
+			case 2:
+				invert = x%3 == 0
+			case 3:
+				invert = (x+y)%3 == 0
+			case 4:
+				invert = (x/3+y/2)%2 == 0
+			case 5:
+				invert = x*y%2+x*y%3 == 0
+			case 6:
+				invert = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				invert = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if invert {
+				m.modules[y][x] = !m.modules[y][x]
+			}
+		}
+	}
+}
+
+// penalty scores the matrix using the four QR mask evaluation rules
+func (m *matrix) penalty() int {
+	score := 0
+	finderLike := [][]bool{
+		{true, false, true, true, true, false, true, false, false, false, false},
+		{false, false, false, false, true, false, true, true, true, false, true},
+	}
+
+	line := func(get func(i int) bool) {
+		// Rule 1: runs of five or more same-colored modules
+		run := 1
+		for i := 1; i < m.size; i++ {
+			if get(i) == get(i-1) {
+				run++
+				continue
+			}
+			if run >= 5 {
+				score += run - 2
+			}
+			run = 1
+		}
+		if run >= 5 {
+			score += run - 2
+		}
+
+		// Rule 3: finder-like patterns
+		for i := 0; i+11 <= m.size; i++ {
+			for _, p := range finderLike {
+				match := true
+				for k, dark := range p {
+					if get(i+k) != dark {
+						match = false
+						break
+					}
+				}
+				if match {
+					score += 40
+				}
+			}
+		}
+	}
+
+	dark := 0
+	for y := 0; y < m.size; y++ {
+		line(func(i int) bool { return m.modules[y][i] })
+		line(func(i int) bool { return m.modules[i][y] })
+		for x := 0; x < m.size; x++ {
+			if m.modules[y][x] {
+				dark++
+			}
+			// Rule 2: 2x2 blocks of one color
+			if x+1 < m.size && y+1 < m.size {
+				c := m.modules[y][x]
+				if c == m.modules[y][x+1] && c == m.modules[y+1][x] && c == m.modules[y+1][x+1] {
+					score += 3
+				}
+			}
+		}
+	}
+
+	// Rule 4: balance of dark and light modules
+	total := m.size * m.size
+	score += abs(dark*100/total-50) / 5 * 10
+	return score
+}
+
+// bitBuffer accumulates bits most-significant first
+type bitBuffer struct {
+	bits []bool
+}
+
+func (b *bitBuffer) append(value, n int) {
+	for i := n - 1; i >= 0; i-- {
+		b.bits = append(b.bits, (value>>i)&1 != 0)
+	}
+}
+
+func (b *bitBuffer) len() int {
+	return len(b.bits)
+}
+
+func (b *bitBuffer) bytes() []byte {
+	out := make([]byte, (len(b.bits)+7)/8)
+	for i, bit := range b.bits {
+		if bit {
+			out[i/8] |= 1 << (7 - uint(i%8))
+		}
+	}
+	return out
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
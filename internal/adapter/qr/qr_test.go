@@ -0,0 +1,176 @@
+package qr
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func TestReedSolomon_KnownVector(t *testing.T) {
+	// "HELLO WORLD" at 1-M, from the ISO/IEC 18004 worked example
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	want := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+
+	got := reedSolomon(data, 10)
+	if !bytes.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestBlockSpecs_TotalCodewords(t *testing.T) {
+	totals := []int{26, 44, 70, 100, 134, 172, 196, 242, 292, 346}
+	for v, specs := range blockSpecs {
+		for level, s := range specs {
+			blocks := s.g1Blocks + s.g2Blocks
+			if got := s.dataCodewords() + blocks*s.ecPerBlock; got != totals[v] {
+				t.Errorf("version %d level %d: expected %d codewords, got %d", v+1, level, totals[v], got)
+			}
+		}
+	}
+}
+
+func TestEncode_FormatAndVersionBits(t *testing.T) {
+	m := newMatrix(7)
+	m.drawFormatBits(LevelM, 0)
+	m.drawVersion()
+
+	// Format bits for M/mask 0 are 101010000010010, read from bit 14 down
+	var format int
+	for i := 0; i <= 5; i++ {
+		format |= b2i(m.modules[i][8]) << i
+	}
+	format |= b2i(m.modules[7][8])<<6 | b2i(m.modules[8][8])<<7 | b2i(m.modules[8][7])<<8
+	for i := 9; i < 15; i++ {
+		format |= b2i(m.modules[8][14-i]) << i
+	}
+	if format != 0x5412 {
+		t.Errorf("expected format bits 0x5412, got %#x", format)
+	}
+
+	// Version 7 information is 000111110010010100
+	var version int
+	for i := 0; i < 18; i++ {
+		version |= b2i(m.modules[i/3][m.size-11+i%3]) << i
+	}
+	if version != 0x07C94 {
+		t.Errorf("expected version bits 0x07C94, got %#x", version)
+	}
+}
+
+func TestEncode_RoundTripsCodewords(t *testing.T) {
+	for _, text := range []string{"https://example.com/f/form_abc123", strings.Repeat("x", 200)} {
+		for _, level := range []Level{LevelL, LevelM, LevelQ, LevelH} {
+			code, err := Encode(text, level)
+			if len(text)+2 > blockSpecs[MaxVersion-1][level].dataCodewords() {
+				if err != ErrTooLong {
+					t.Errorf("expected ErrTooLong, got %v", err)
+				}
+				continue
+			}
+			if err != nil {
+				t.Fatalf("encode %d: %v", level, err)
+			}
+
+			// Rebuild the function pattern map, then read back and unmask the data area
+			m := newMatrix(code.Version)
+			m.drawFunctionPatterns()
+			mask := readMask(code)
+			for y := range m.modules {
+				copy(m.modules[y], code.modules[y])
+			}
+			m.applyMask(mask)
+
+			spec := blockSpecs[code.Version-1][level]
+			expected := addErrorCorrection(encodeData([]byte(text), code.Version, level), spec)
+			if got := readCodewords(m, len(expected)); !bytes.Equal(got, expected) {
+				t.Errorf("version %d level %d: codewords don't round-trip", code.Version, level)
+			}
+		}
+	}
+}
+
+func TestEncode_TooLong(t *testing.T) {
+	if _, err := Encode(strings.Repeat("a", 272), LevelL); err != ErrTooLong {
+		t.Errorf("expected ErrTooLong, got %v", err)
+	}
+}
+
+func TestRender_PNGAndSVG(t *testing.T) {
+	code, err := Encode("https://example.com", LevelM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := DefaultRenderOptions()
+
+	data, err := code.PNG(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("decode png: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 256 || b.Dy() != 256 {
+		t.Errorf("expected 256x256, got %v", b)
+	}
+
+	fg, _ := ParseColor("#336699")
+	opts.Foreground = fg
+	svg := string(code.SVG(opts))
+	if !strings.HasPrefix(svg, "<svg") || !strings.Contains(svg, `fill="#336699"`) {
+		t.Errorf("unexpected svg: %.120s", svg)
+	}
+}
+
+func TestParseColor(t *testing.T) {
+	c, err := ParseColor("ff8000")
+	if err != nil || c.R != 255 || c.G != 128 || c.B != 0 {
+		t.Errorf("unexpected color %v, %v", c, err)
+	}
+	if _, err := ParseColor("#12345"); err == nil {
+		t.Error("expected error for short color")
+	}
+}
+
+// readMask recovers the mask from the first copy of the format bits (bits 10-12)
+func readMask(c *Code) int {
+	var bits int
+	for i := 10; i <= 12; i++ {
+		bits |= b2i(c.modules[8][14-i]) << i
+	}
+	return ((bits ^ 0x5412) >> 10) & 7
+}
+
+// readCodewords reads n codewords in placement order
+func readCodewords(m *matrix, n int) []byte {
+	out := make([]byte, n)
+	i := 0
+	for right := m.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < m.size; vert++ {
+			for j := 0; j < 2; j++ {
+				x, y := right-j, vert
+				if (right+1)&2 == 0 {
+					y = m.size - 1 - vert
+				}
+				if !m.isFunction[y][x] && i < n*8 {
+					if m.modules[y][x] {
+						out[i>>3] |= 1 << (7 - uint(i&7))
+					}
+					i++
+				}
+			}
+		}
+	}
+	return out
+}
+
+func b2i(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,104 @@
+package qr
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+)
+
+// RenderOptions controls the rendered image
+type RenderOptions struct {
+	Size       int        // Output width/height in pixels (PNG) or user units (SVG)
+	QuietZone  int        // Light border in modules; the spec minimum is 4
+	Foreground color.RGBA // Dark module color
+	Background color.RGBA // Light module color
+}
+
+// DefaultRenderOptions returns black-on-white output at 256px with a 4 module quiet zone
+func DefaultRenderOptions() RenderOptions {
+	return RenderOptions{
+		Size:       256,
+		QuietZone:  4,
+		Foreground: color.RGBA{0, 0, 0, 255},
+		Background: color.RGBA{255, 255, 255, 255},
+	}
+}
+
+// PNG renders the code as a PNG image. Modules are scaled to whole pixels and
+// the image is padded with background to exactly opts.Size.
+func (c *Code) PNG(opts RenderOptions) ([]byte, error) {
+	total := c.Size + 2*opts.QuietZone
+	scale := opts.Size / total
+	if scale < 1 {
+		scale = 1
+	}
+	size := opts.Size
+	if size < total*scale {
+		size = total * scale
+	}
+	offset := (size - total*scale) / 2
+
+	img := image.NewPaletted(image.Rect(0, 0, size, size), color.Palette{opts.Background, opts.Foreground})
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if !c.Dark(x, y) {
+				continue
+			}
+			px := offset + (x+opts.QuietZone)*scale
+			py := offset + (y+opts.QuietZone)*scale
+			for dy := 0; dy < scale; dy++ {
+				for dx := 0; dx < scale; dx++ {
+					img.SetColorIndex(px+dx, py+dy, 1)
+				}
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// SVG renders the code as a scalable SVG document
+func (c *Code) SVG(opts RenderOptions) []byte {
+	total := c.Size + 2*opts.QuietZone
+
+	var path strings.Builder
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if c.Dark(x, y) {
+				fmt.Fprintf(&path, "M%d,%dh1v1h-1z", x+opts.QuietZone, y+opts.QuietZone)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`,
+		opts.Size, opts.Size, total, total)
+	fmt.Fprintf(&buf, `<rect width="100%%" height="100%%" fill="%s"/>`, hexColor(opts.Background))
+	fmt.Fprintf(&buf, `<path d="%s" fill="%s"/>`, path.String(), hexColor(opts.Foreground))
+	buf.WriteString("</svg>\n")
+	return buf.Bytes()
+}
+
+// ParseColor parses a "#rrggbb" or "rrggbb" hex color
+func ParseColor(s string) (color.RGBA, error) {
+	s = strings.TrimPrefix(s, "#")
+	var r, g, b uint8
+	if len(s) != 6 {
+		return color.RGBA{}, fmt.Errorf("invalid color %q", s)
+	}
+	if _, err := fmt.Sscanf(s, "%02x%02x%02x", &r, &g, &b); err != nil {
+		return color.RGBA{}, fmt.Errorf("invalid color %q", s)
+	}
+	return color.RGBA{r, g, b, 255}, nil
+}
+
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
@@ -0,0 +1,118 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// AWSSecretsManager reads secrets from AWS Secrets Manager, signing requests with AWS
+// Signature Version 4. IDs are secret names or ARNs. Fetch returns the secret string;
+// references pick a field of a JSON secret with "#field".
+type AWSSecretsManager struct {
+	Region       string
+	AccessKey    string
+	SecretKey    string
+	SessionToken string // For temporary credentials
+	Endpoint     string // Default https://secretsmanager.<region>.amazonaws.com
+	Client       *http.Client
+}
+
+// NewAWSSecretsManager creates a source for the region with static credentials
+func NewAWSSecretsManager(region, accessKey, secretKey, sessionToken string) *AWSSecretsManager {
+	return &AWSSecretsManager{
+		Region:       region,
+		AccessKey:    accessKey,
+		SecretKey:    secretKey,
+		SessionToken: sessionToken,
+		Endpoint:     "https://secretsmanager." + region + ".amazonaws.com",
+		Client:       &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (a *AWSSecretsManager) Fetch(ctx context.Context, id string) (string, error) {
+	body, _ := json.Marshal(map[string]string{"SecretId": id})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.Endpoint+"/", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	a.sign(req, body, time.Now().UTC())
+
+	resp, err := a.Client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", fmt.Errorf("secrets manager: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	var secret struct {
+		SecretString *string `json:"SecretString"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&secret); err != nil {
+		return "", fmt.Errorf("secrets manager: %w", err)
+	}
+	if secret.SecretString == nil {
+		return "", fmt.Errorf("secrets manager: %s is a binary secret", id)
+	}
+	return *secret.SecretString, nil
+}
+
+// sign adds the Signature Version 4 headers covering the host, date, target and payload
+func (a *AWSSecretsManager) sign(req *http.Request, body []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	if a.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", a.SessionToken)
+	}
+
+	headers := "content-type:" + req.Header.Get("Content-Type") + "\nhost:" + req.URL.Host + "\nx-amz-date:" + amzDate + "\n"
+	signedHeaders := "content-type;host;x-amz-date"
+	if a.SessionToken != "" {
+		headers += "x-amz-security-token:" + a.SessionToken + "\n"
+		signedHeaders += ";x-amz-security-token"
+	}
+	headers += "x-amz-target:" + req.Header.Get("X-Amz-Target") + "\n"
+	signedHeaders += ";x-amz-target"
+
+	payloadHash := sha256.Sum256(body)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		"/",
+		"",
+		headers,
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+
+	scope := date + "/" + a.Region + "/secretsmanager/aws4_request"
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(hash[:])
+
+	key := hmacSHA256([]byte("AWS4"+a.SecretKey), date)
+	key = hmacSHA256(key, a.Region)
+	key = hmacSHA256(key, "secretsmanager")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		a.AccessKey, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
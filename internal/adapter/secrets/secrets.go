@@ -0,0 +1,259 @@
+// Package secrets encrypts secret columns at rest and resolves references to secrets
+// kept in Vault or AWS Secrets Manager.
+//
+// Sealed values look like "enc:v1:<key id>:<base64 nonce and ciphertext>", encrypted with
+// AES-256-GCM under a key derived from the master key with HKDF-SHA256. References look
+// like "vault:<path>#<field>" or "aws-sm:<secret id>[#<field>]" and are stored as they are.
+// Anything else is a plaintext value from before encryption was enabled.
+package secrets
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	sealedPrefix = "enc:v1:"
+
+	// DefaultCacheTTL is how long secrets fetched from a secret manager are reused
+	DefaultCacheTTL = 5 * time.Minute
+
+	// MinKeyLength is the shortest master key accepted, in bytes
+	MinKeyLength = 32
+
+	// Reference schemes
+	SchemeVault = "vault"
+	SchemeAWS   = "aws-sm"
+)
+
+// ErrNoKey is returned when opening a sealed value without the key it was sealed with
+var ErrNoKey = errors.New("secret is encrypted with a key that isn't configured (SECRETS_KEY, SECRETS_PREVIOUS_KEYS)")
+
+// Source fetches secrets from a secret manager by ID
+type Source interface {
+	Fetch(ctx context.Context, id string) (string, error)
+}
+
+// Config configures a Codec. Without a key secrets are stored in plaintext, while
+// references to secret managers still work.
+type Config struct {
+	Key          string            // Master key sealing new values
+	PreviousKeys []string          // Retired master keys, still opening the values sealed with them
+	Sources      map[string]Source // Secret managers by reference scheme, SchemeVault or SchemeAWS
+	CacheTTL     time.Duration     // Default DefaultCacheTTL
+}
+
+// Codec seals and opens secret values. It implements ports.SecretCodec.
+type Codec struct {
+	current  *sealKey
+	keys     map[string]*sealKey // By key ID, the current one included
+	sources  map[string]Source
+	cacheTTL time.Duration
+
+	mu    sync.Mutex
+	cache map[string]cachedSecret
+}
+
+type sealKey struct {
+	id   string
+	aead cipher.AEAD
+}
+
+type cachedSecret struct {
+	value   string
+	expires time.Time
+}
+
+// New creates a codec from cfg
+func New(cfg Config) (*Codec, error) {
+	c := &Codec{
+		keys:     make(map[string]*sealKey),
+		sources:  cfg.Sources,
+		cacheTTL: cfg.CacheTTL,
+		cache:    make(map[string]cachedSecret),
+	}
+	if c.cacheTTL <= 0 {
+		c.cacheTTL = DefaultCacheTTL
+	}
+	if cfg.Key != "" {
+		k, err := deriveKey(cfg.Key)
+		if err != nil {
+			return nil, fmt.Errorf("SECRETS_KEY: %w", err)
+		}
+		c.current = k
+		c.keys[k.id] = k
+	}
+	for i, previous := range cfg.PreviousKeys {
+		if previous == "" {
+			continue
+		}
+		k, err := deriveKey(previous)
+		if err != nil {
+			return nil, fmt.Errorf("previous key %d: %w", i+1, err)
+		}
+		if _, ok := c.keys[k.id]; !ok {
+			c.keys[k.id] = k
+		}
+	}
+	return c, nil
+}
+
+// deriveKey derives the AES-256 key from a master key; its ID is a short hash of the result
+func deriveKey(master string) (*sealKey, error) {
+	if len(master) < MinKeyLength {
+		return nil, fmt.Errorf("must be at least %d bytes", MinKeyLength)
+	}
+	key, err := hkdf.Key(sha256.New, []byte(master), nil, "headless-forms secret columns", 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	id := sha256.Sum256(key)
+	return &sealKey{id: hex.EncodeToString(id[:4]), aead: aead}, nil
+}
+
+// Encrypts reports whether new values are encrypted
+func (c *Codec) Encrypts() bool {
+	return c.current != nil
+}
+
+// Seal encrypts secret with the current key. Empty values and references to secret
+// managers are stored as they are, and so is everything without a key.
+func (c *Codec) Seal(secret string) (string, error) {
+	if secret == "" || c.current == nil || c.reference(secret) {
+		return secret, nil
+	}
+	nonce := make([]byte, c.current.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := c.current.aead.Seal(nonce, nonce, []byte(secret), []byte(c.current.id))
+	return sealedPrefix + c.current.id + ":" + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts a sealed value or fetches a referenced one; plaintext is returned as it is
+func (c *Codec) Open(ctx context.Context, stored string) (string, error) {
+	if strings.HasPrefix(stored, sealedPrefix) {
+		return c.unseal(stored)
+	}
+	if c.reference(stored) {
+		return c.fetch(ctx, stored)
+	}
+	return stored, nil
+}
+
+// Unseal decrypts a sealed value; references and plaintext are returned as they are
+func (c *Codec) Unseal(stored string) (string, error) {
+	if strings.HasPrefix(stored, sealedPrefix) {
+		return c.unseal(stored)
+	}
+	return stored, nil
+}
+
+// Current reports whether stored needs no sealing: it's empty, a reference, sealed with
+// the current key, or plaintext when there is no key
+func (c *Codec) Current(stored string) bool {
+	if stored == "" || c.reference(stored) {
+		return true
+	}
+	if c.current == nil {
+		return !strings.HasPrefix(stored, sealedPrefix)
+	}
+	return strings.HasPrefix(stored, sealedPrefix+c.current.id+":")
+}
+
+func (c *Codec) unseal(stored string) (string, error) {
+	id, payload, ok := strings.Cut(strings.TrimPrefix(stored, sealedPrefix), ":")
+	if !ok {
+		return "", errors.New("malformed encrypted secret")
+	}
+	k := c.keys[id]
+	if k == nil {
+		return "", ErrNoKey
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(payload)
+	if err != nil || len(sealed) < k.aead.NonceSize() {
+		return "", errors.New("malformed encrypted secret")
+	}
+	nonce, ciphertext := sealed[:k.aead.NonceSize()], sealed[k.aead.NonceSize():]
+	plain, err := k.aead.Open(nil, nonce, ciphertext, []byte(id))
+	if err != nil {
+		return "", fmt.Errorf("decrypt secret: %w", err)
+	}
+	return string(plain), nil
+}
+
+// reference reports whether value names a secret in a secret manager, configured or not
+func (c *Codec) reference(value string) bool {
+	scheme, _, ok := strings.Cut(value, ":")
+	return ok && (scheme == SchemeVault || scheme == SchemeAWS)
+}
+
+// fetch resolves a reference, reusing what was fetched within the cache TTL, or later
+// when fetching it again fails
+func (c *Codec) fetch(ctx context.Context, ref string) (string, error) {
+	c.mu.Lock()
+	cached, ok := c.cache[ref]
+	c.mu.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		return cached.value, nil
+	}
+
+	scheme, rest, _ := strings.Cut(ref, ":")
+	id, field, _ := strings.Cut(rest, "#")
+	source := c.sources[scheme]
+	if source == nil {
+		return "", fmt.Errorf("secret %s references %s, which isn't configured", id, scheme)
+	}
+	raw, err := source.Fetch(ctx, id)
+	if err != nil {
+		// Keep using what was fetched last while the secret manager is unavailable
+		if ok {
+			return cached.value, nil
+		}
+		return "", fmt.Errorf("fetch %s secret %s: %w", scheme, id, err)
+	}
+	value := raw
+	if field != "" {
+		if value, err = jsonField(raw, field); err != nil {
+			return "", fmt.Errorf("%s secret %s: %w", scheme, id, err)
+		}
+	}
+
+	c.mu.Lock()
+	c.cache[ref] = cachedSecret{value: value, expires: time.Now().Add(c.cacheTTL)}
+	c.mu.Unlock()
+	return value, nil
+}
+
+// jsonField returns a string field of a JSON object
+func jsonField(raw, field string) (string, error) {
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(raw), &fields); err != nil {
+		return "", errors.New("not a JSON object")
+	}
+	value, ok := fields[field].(string)
+	if !ok {
+		return "", fmt.Errorf("no string field %q", field)
+	}
+	return value, nil
+}
@@ -0,0 +1,118 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+var (
+	testKey        = strings.Repeat("a", MinKeyLength)
+	rotatedKey     = strings.Repeat("b", MinKeyLength)
+	errUnavailable = errors.New("unavailable")
+)
+
+type fakeSource struct {
+	values map[string]string
+	calls  int
+	err    error
+}
+
+func (f *fakeSource) Fetch(ctx context.Context, id string) (string, error) {
+	f.calls++
+	if f.err != nil {
+		return "", f.err
+	}
+	v, ok := f.values[id]
+	if !ok {
+		return "", errors.New("not found")
+	}
+	return v, nil
+}
+
+func TestCodec_SealOpen(t *testing.T) {
+	c, err := New(Config{Key: testKey})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	sealed, err := c.Seal("hunter2")
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	if !strings.HasPrefix(sealed, sealedPrefix) || strings.Contains(sealed, "hunter2") {
+		t.Fatalf("expected an encrypted value, got %q", sealed)
+	}
+	if !c.Current(sealed) || c.Current("hunter2") {
+		t.Error("expected sealed values current and plaintext stale")
+	}
+	if got, err := c.Open(context.Background(), sealed); err != nil || got != "hunter2" {
+		t.Errorf("Open = %q, %v", got, err)
+	}
+	if got, _ := c.Open(context.Background(), "legacy-plaintext"); got != "legacy-plaintext" {
+		t.Errorf("expected plaintext returned as it is, got %q", got)
+	}
+	if empty, _ := c.Seal(""); empty != "" {
+		t.Errorf("expected empty secrets stored empty, got %q", empty)
+	}
+
+	tampered := sealed[:len(sealed)-2] + "AA"
+	if _, err := c.Open(context.Background(), tampered); err == nil {
+		t.Error("expected a tampered value to fail")
+	}
+}
+
+func TestCodec_Rotation(t *testing.T) {
+	old, _ := New(Config{Key: testKey})
+	sealed, _ := old.Seal("hunter2")
+
+	c, err := New(Config{Key: rotatedKey, PreviousKeys: []string{testKey}})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if c.Current(sealed) {
+		t.Error("expected a value sealed with a previous key to be stale")
+	}
+	if got, err := c.Open(context.Background(), sealed); err != nil || got != "hunter2" {
+		t.Errorf("Open = %q, %v", got, err)
+	}
+
+	without, _ := New(Config{Key: rotatedKey})
+	if _, err := without.Open(context.Background(), sealed); !errors.Is(err, ErrNoKey) {
+		t.Errorf("expected ErrNoKey, got %v", err)
+	}
+	if _, err := New(Config{Key: "short"}); err == nil {
+		t.Error("expected a short key to be rejected")
+	}
+}
+
+func TestCodec_References(t *testing.T) {
+	vault := &fakeSource{values: map[string]string{"secret/data/forms": `{"smtp":"from-vault"}`}}
+	c, err := New(Config{Key: testKey, Sources: map[string]Source{SchemeVault: vault}})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	ref := "vault:secret/data/forms#smtp"
+	if sealed, _ := c.Seal(ref); sealed != ref {
+		t.Errorf("expected references stored as they are, got %q", sealed)
+	}
+	for i := 0; i < 2; i++ {
+		if got, err := c.Open(context.Background(), ref); err != nil || got != "from-vault" {
+			t.Fatalf("Open = %q, %v", got, err)
+		}
+	}
+	if vault.calls != 1 {
+		t.Errorf("expected the secret fetched once and cached, got %d fetches", vault.calls)
+	}
+
+	// Stale values are used while the source is unavailable
+	c.cache[ref] = cachedSecret{value: "from-vault"}
+	vault.err = errUnavailable
+	if got, err := c.Open(context.Background(), ref); err != nil || got != "from-vault" {
+		t.Errorf("expected the last value while unavailable, got %q, %v", got, err)
+	}
+
+	if _, err := c.Open(context.Background(), "aws-sm:prod/forms"); err == nil {
+		t.Error("expected an unconfigured source to fail")
+	}
+}
@@ -0,0 +1,71 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Vault reads secrets from HashiCorp Vault's key/value engine. IDs are API paths below
+// /v1/, e.g. "secret/data/forms" for version 2 of the engine mounted at secret/. Fetch
+// returns the secret's fields as a JSON object; references pick one with "#field".
+type Vault struct {
+	Addr      string // e.g. https://vault.example.com:8200
+	Token     string
+	Namespace string // Vault Enterprise namespace, if any
+	Client    *http.Client
+}
+
+// NewVault creates a source for the Vault server at addr
+func NewVault(addr, token, namespace string) *Vault {
+	return &Vault{
+		Addr:      strings.TrimRight(addr, "/"),
+		Token:     token,
+		Namespace: namespace,
+		Client:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (v *Vault) Fetch(ctx context.Context, id string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.Addr+"/v1/"+strings.TrimLeft(id, "/"), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", v.Token)
+	if v.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.Namespace)
+	}
+	resp, err := v.Client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", fmt.Errorf("vault: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	var body struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("vault: %w", err)
+	}
+	// Version 2 of the engine nests the fields under data.data, next to data.metadata
+	fields := body.Data
+	if nested, ok := body.Data["data"]; ok && body.Data["metadata"] != nil {
+		fields = nil
+		if err := json.Unmarshal(nested, &fields); err != nil {
+			return "", fmt.Errorf("vault: %w", err)
+		}
+	}
+	if fields == nil {
+		return "", fmt.Errorf("vault: no secret at %s", id)
+	}
+	b, err := json.Marshal(fields)
+	return string(b), err
+}
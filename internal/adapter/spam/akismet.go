@@ -0,0 +1,122 @@
+package spam
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// AkismetEndpoint is Akismet's comment-check API
+const AkismetEndpoint = "https://rest.akismet.com/1.1/comment-check"
+
+// Fields sent to Akismet as the author's name and email rather than as content
+var (
+	akismetNameFields  = []string{"name", "full_name", "fullname", "first_name"}
+	akismetEmailFields = []string{"email", "e-mail", "mail"}
+)
+
+// Akismet checks submissions with Akismet's comment-check API
+type Akismet struct {
+	Key      string // API key
+	Site     string // The site's URL, sent as blog
+	Endpoint string
+	Client   *http.Client
+}
+
+// NewAkismet creates an Akismet client for key, registered for the site at siteURL
+func NewAkismet(key, siteURL string) *Akismet {
+	return &Akismet{
+		Key:      key,
+		Site:     siteURL,
+		Endpoint: AkismetEndpoint,
+		Client:   &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// Name identifies the provider in spam flags
+func (a *Akismet) Name() string {
+	return "akismet"
+}
+
+// Check sends the submission as a contact-form comment. Akismet answers "true" for spam,
+// with an "X-akismet-pro-tip: discard" header when it's blatant, or "false".
+func (a *Akismet) Check(ctx context.Context, req Request) (Verdict, error) {
+	form := url.Values{
+		"api_key":      {a.Key},
+		"blog":         {a.Site},
+		"user_ip":      {req.IP},
+		"user_agent":   {req.UserAgent},
+		"referrer":     {req.Referer},
+		"comment_type": {"contact-form"},
+		"blog_charset": {"UTF-8"},
+	}
+	author, email, content := akismetComment(req.Data)
+	if author != "" {
+		form.Set("comment_author", author)
+	}
+	if email != "" {
+		form.Set("comment_author_email", email)
+	}
+	form.Set("comment_content", content)
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, a.Endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return VerdictHam, err
+	}
+	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	httpReq.Header.Set("User-Agent", "HeadlessForms/1.0 | Akismet/1.0")
+
+	resp, err := a.Client.Do(httpReq)
+	if err != nil {
+		return VerdictHam, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if err != nil {
+		return VerdictHam, err
+	}
+
+	switch strings.TrimSpace(string(body)) {
+	case "true":
+		if resp.Header.Get("X-akismet-pro-tip") == "discard" {
+			return VerdictBlatant, nil
+		}
+		return VerdictSpam, nil
+	case "false":
+		return VerdictHam, nil
+	}
+	// "invalid" for a bad key, with the reason in a debug header
+	reason := resp.Header.Get("X-akismet-debug-help")
+	if reason == "" {
+		reason = strings.TrimSpace(string(body))
+	}
+	return VerdictHam, fmt.Errorf("akismet: unexpected response (%d): %s", resp.StatusCode, reason)
+}
+
+// akismetComment picks the author's name and email out of the submitted fields and joins
+// the other text values, sorted by field name, as the comment's content
+func akismetComment(data map[string]interface{}) (author, email, content string) {
+	var parts []string
+	for _, k := range slices.Sorted(maps.Keys(data)) {
+		s, ok := data[k].(string)
+		if !ok || s == "" {
+			continue
+		}
+		lower := strings.ToLower(k)
+		switch {
+		case author == "" && slices.Contains(akismetNameFields, lower):
+			author = s
+		case email == "" && slices.Contains(akismetEmailFields, lower):
+			email = s
+		case !strings.HasPrefix(k, "_"):
+			parts = append(parts, s)
+		}
+	}
+	return author, email, strings.Join(parts, "\n")
+}
@@ -0,0 +1,119 @@
+package spam
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAkismet_Check(t *testing.T) {
+	var got map[string]string
+	answer, tip := "false", ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		got = make(map[string]string)
+		for k := range r.PostForm {
+			got[k] = r.PostForm.Get(k)
+		}
+		if tip != "" {
+			w.Header().Set("X-akismet-pro-tip", tip)
+		}
+		if answer == "invalid" {
+			w.Header().Set("X-akismet-debug-help", "Empty \"api_key\" value")
+		}
+		_, _ = w.Write([]byte(answer))
+	}))
+	defer server.Close()
+
+	a := NewAkismet("key123", "https://forms.example.com")
+	a.Endpoint = server.URL
+	req := Request{
+		IP:        "203.0.113.7",
+		UserAgent: "Mozilla/5.0",
+		Data:      map[string]interface{}{"name": "Ada", "email": "ada@example.com", "message": "Hello", "subject": "Hi", "_hp": ""},
+	}
+
+	if v, err := a.Check(context.Background(), req); err != nil || v != VerdictHam {
+		t.Fatalf("expected ham, got %v (%v)", v, err)
+	}
+	want := map[string]string{
+		"api_key": "key123", "blog": "https://forms.example.com", "user_ip": "203.0.113.7",
+		"comment_type": "contact-form", "comment_author": "Ada", "comment_author_email": "ada@example.com",
+		"comment_content": "Hello\nHi",
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s: expected %q, got %q", k, v, got[k])
+		}
+	}
+
+	answer = "true"
+	if v, _ := a.Check(context.Background(), req); v != VerdictSpam {
+		t.Errorf("expected spam, got %v", v)
+	}
+	tip = "discard"
+	if v, _ := a.Check(context.Background(), req); v != VerdictBlatant {
+		t.Errorf("expected blatant spam, got %v", v)
+	}
+	answer, tip = "invalid", ""
+	if _, err := a.Check(context.Background(), req); err == nil {
+		t.Error("expected an error for an invalid key")
+	}
+}
+
+// fakeProvider answers every check with the same verdict
+type fakeProvider struct {
+	verdict Verdict
+	err     error
+	calls   int
+}
+
+func (p *fakeProvider) Name() string { return "fake" }
+
+func (p *fakeProvider) Check(ctx context.Context, req Request) (Verdict, error) {
+	p.calls++
+	return p.verdict, p.err
+}
+
+func TestDetector_Consult(t *testing.T) {
+	config := DefaultConfig()
+	detector := NewDetector(config)
+	heuristic := SpamScore{Score: 10, Threshold: 50}
+
+	// Without a provider the heuristic score stands
+	if got := detector.Consult(context.Background(), config, Request{}, heuristic); got.Score != 10 || got.IsSpam {
+		t.Errorf("expected the heuristic score, got %+v", got)
+	}
+
+	provider := &fakeProvider{verdict: VerdictSpam}
+	detector.SetProviderSource(func(ctx context.Context) Provider { return provider })
+	got := detector.Consult(context.Background(), config, Request{}, heuristic)
+	if got.Score != 60 || !got.IsSpam || !containsFlag(got.Flags, "provider_spam:fake") {
+		t.Errorf("expected the verdict added to the score, got %+v", got)
+	}
+
+	provider.verdict = VerdictBlatant
+	if got := detector.Consult(context.Background(), config, Request{}, heuristic); got.Score != 100 || !got.IsSpam {
+		t.Errorf("expected blatant spam scored 100, got %+v", got)
+	}
+
+	provider.verdict = VerdictHam
+	if got := detector.Consult(context.Background(), config, Request{}, heuristic); got.Score != 10 || got.IsSpam {
+		t.Errorf("expected ham to leave the score, got %+v", got)
+	}
+
+	// Failures fail open
+	provider.err = errors.New("timeout")
+	if got := detector.Consult(context.Background(), config, Request{}, heuristic); got.IsSpam || !containsFlag(got.Flags, "provider_error:fake") {
+		t.Errorf("expected the heuristic verdict with an error flag, got %+v", got)
+	}
+
+	// Certain spam isn't sent out
+	calls := provider.calls
+	detector.Consult(context.Background(), config, Request{}, SpamScore{Score: 100, IsSpam: true})
+	if provider.calls != calls {
+		t.Error("expected no provider call for a submission already scored 100")
+	}
+}
@@ -1,9 +1,14 @@
 package spam
 
 import (
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
+
+	"headless_form/internal/core/domain"
 )
 
 // SpamScore represents the spam analysis result
@@ -16,10 +21,30 @@ type SpamScore struct {
 
 // Config holds spam detection configuration
 type Config struct {
-	ScoreThreshold     int           // Score at which submission is marked spam (default: 50)
-	RateLimitWindow    time.Duration // Time window for rate limiting (default: 1 minute)
-	RateLimitMax       int           // Max submissions per IP in window (default: 10)
-	HoneypotFieldNames []string      // Hidden field names to detect bots
+	ScoreThreshold     int            // Score at which submission is marked spam (default: 50)
+	RateLimitWindow    time.Duration  // Time window for rate limiting (default: 1 minute)
+	RateLimitMax       int            // Max submissions per IP in window (default: 10)
+	HoneypotFieldNames []string       // Hidden field names to detect bots
+	Language           string         // Language code the form is filled in (see domain.SpamLanguages); unchecked when empty
+	Weights            map[string]int // Score per flag; domain.DefaultSpamWeights for the rest
+	BlockedDomains     []string       // Links to these domains or their subdomains are flagged
+	Links              LinkChecker    // Looks up submitted links, e.g. with Safe Browsing; none when nil
+}
+
+// weight returns what flag adds to the score
+func (c Config) weight(flag string) int {
+	if w, ok := c.Weights[flag]; ok {
+		return w
+	}
+	return domain.DefaultSpamWeights[flag]
+}
+
+// Flag adds flag's weight to score, recording it as flag:detail
+func (d *Detector) Flag(config Config, score SpamScore, flag, detail string) SpamScore {
+	score.Score = min(score.Score+config.weight(flag), 100)
+	score.Flags = append(score.Flags, flag+":"+detail)
+	score.IsSpam = score.Score >= config.ScoreThreshold
+	return score
 }
 
 // DefaultConfig returns sensible default configuration
@@ -36,6 +61,10 @@ func DefaultConfig() Config {
 type Detector struct {
 	config     Config
 	rateLimits map[string][]time.Time // IP -> submission timestamps
+	providers  ProviderSource         // External spam service consulted by Consult; none when nil
+	filters    FilterSource           // Global content filters checked by Filter; none when nil
+	settings   SettingsSource         // Site-wide spam settings applied by Tune; the defaults when nil
+	patterns   map[string]*regexp.Regexp
 	mu         sync.RWMutex
 }
 
@@ -44,36 +73,43 @@ func NewDetector(config Config) *Detector {
 	return &Detector{
 		config:     config,
 		rateLimits: make(map[string][]time.Time),
+		patterns:   make(map[string]*regexp.Regexp),
 	}
 }
 
 // Analyze checks submission for spam signals
 func (d *Detector) Analyze(ip string, userAgent string, data map[string]interface{}, submissionTime time.Duration) SpamScore {
+	return d.AnalyzeWith(d.config, ip, userAgent, data, submissionTime)
+}
+
+// AnalyzeWith checks submission for spam signals using config instead of the detector's
+// own, such as a form's settings. Rate limits are still tracked per ip across calls.
+func (d *Detector) AnalyzeWith(config Config, ip string, userAgent string, data map[string]interface{}, submissionTime time.Duration) SpamScore {
 	var score int
 	var flags []string
 
 	// 1. Check honeypot fields
-	for _, field := range d.config.HoneypotFieldNames {
+	for _, field := range config.HoneypotFieldNames {
 		if val, ok := data[field]; ok {
 			if str, isStr := val.(string); isStr && str != "" {
-				score += 100 // Guaranteed spam
-				flags = append(flags, "honeypot_filled:"+field)
+				score += config.weight(domain.SpamFlagHoneypot) // Guaranteed spam by default
+				flags = append(flags, domain.SpamFlagHoneypot+":"+field)
 			}
 		}
 	}
 
 	// 2. Check user agent
 	if userAgent == "" {
-		score += 30
-		flags = append(flags, "empty_user_agent")
+		score += config.weight(domain.SpamFlagEmptyAgent)
+		flags = append(flags, domain.SpamFlagEmptyAgent)
 	} else {
 		lowerUA := strings.ToLower(userAgent)
 		// Known bot patterns
 		botPatterns := []string{"bot", "crawler", "spider", "curl", "wget", "python", "scrapy", "headless"}
 		for _, pattern := range botPatterns {
 			if strings.Contains(lowerUA, pattern) {
-				score += 40
-				flags = append(flags, "bot_user_agent:"+pattern)
+				score += config.weight(domain.SpamFlagBotAgent)
+				flags = append(flags, domain.SpamFlagBotAgent+":"+pattern)
 				break
 			}
 		}
@@ -81,45 +117,82 @@ func (d *Detector) Analyze(ip string, userAgent string, data map[string]interfac
 
 	// 3. Check submission speed (if provided)
 	if submissionTime > 0 && submissionTime < 2*time.Second {
-		score += 25
-		flags = append(flags, "fast_submission")
+		score += config.weight(domain.SpamFlagFast)
+		flags = append(flags, domain.SpamFlagFast)
 	}
 
 	// 4. Check rate limit
-	if d.isRateLimited(ip) {
-		score += 30
-		flags = append(flags, "rate_limited")
+	if d.isRateLimited(ip, config) {
+		score += config.weight(domain.SpamFlagRateLimited)
+		flags = append(flags, domain.SpamFlagRateLimited)
 	}
 
-	// 5. Check for suspicious content patterns
+	// 5-7. Check the content
+	contentScore, contentFlags := checkContent(config, data)
+	score += contentScore
+	flags = append(flags, contentFlags...)
+
+	// Cap at 100
+	if score > 100 {
+		score = 100
+	}
+
+	return SpamScore{
+		Score:     score,
+		IsSpam:    score >= config.ScoreThreshold,
+		Flags:     flags,
+		Threshold: config.ScoreThreshold,
+	}
+}
+
+// checkContent scores the submitted content: links, blocked domains and language
+func checkContent(config Config, data map[string]interface{}) (int, []string) {
+	var score int
+	var flags []string
+
+	// Check for suspicious content patterns
 	for _, v := range data {
 		if str, ok := v.(string); ok {
 			// Links in message (common spam pattern)
 			if strings.Contains(str, "http://") || strings.Contains(str, "https://") {
 				linkCount := strings.Count(str, "http")
 				if linkCount > 2 {
-					score += 15
-					flags = append(flags, "multiple_links")
+					score += config.weight(domain.SpamFlagLinks)
+					flags = append(flags, domain.SpamFlagLinks)
 				}
 			}
 		}
 	}
 
-	// Cap at 100
-	if score > 100 {
-		score = 100
+	// Check links against the blocked domains
+	if host := blockedHost(links(data), config.BlockedDomains); host != "" {
+		score += config.weight(domain.SpamFlagBlockedDomain)
+		flags = append(flags, domain.SpamFlagBlockedDomain+":"+host)
 	}
 
-	return SpamScore{
-		Score:     score,
-		IsSpam:    score >= d.config.ScoreThreshold,
-		Flags:     flags,
-		Threshold: d.config.ScoreThreshold,
+	// Check the text is in the form's language
+	if scripts := domain.SpamLanguages[config.Language]; len(scripts) > 0 {
+		texts := contentTexts(data)
+		if foreignScript(texts, scripts) {
+			score += config.weight(domain.SpamFlagForeignScript)
+			flags = append(flags, domain.SpamFlagForeignScript)
+		} else if slices.Contains(scripts, "Latin") && gibberish(texts) {
+			score += config.weight(domain.SpamFlagGibberish)
+			flags = append(flags, domain.SpamFlagGibberish)
+		}
 	}
+
+	return score, flags
 }
 
 // RecordSubmission tracks a submission for rate limiting
 func (d *Detector) RecordSubmission(ip string) {
+	d.RecordSubmissionWith(d.config, ip)
+}
+
+// RecordSubmissionWith tracks a submission for rate limiting, keeping the timestamps
+// config's window needs
+func (d *Detector) RecordSubmissionWith(config Config, ip string) {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
@@ -127,11 +200,11 @@ func (d *Detector) RecordSubmission(ip string) {
 	d.rateLimits[ip] = append(d.rateLimits[ip], now)
 
 	// Cleanup old entries
-	d.cleanupRateLimits(ip)
+	d.cleanupRateLimits(ip, config.RateLimitWindow)
 }
 
-// isRateLimited checks if IP has exceeded rate limit
-func (d *Detector) isRateLimited(ip string) bool {
+// isRateLimited checks if IP has exceeded config's rate limit
+func (d *Detector) isRateLimited(ip string, config Config) bool {
 	d.mu.RLock()
 	defer d.mu.RUnlock()
 
@@ -140,7 +213,7 @@ func (d *Detector) isRateLimited(ip string) bool {
 		return false
 	}
 
-	cutoff := time.Now().Add(-d.config.RateLimitWindow)
+	cutoff := time.Now().Add(-config.RateLimitWindow)
 	count := 0
 	for _, ts := range timestamps {
 		if ts.After(cutoff) {
@@ -148,12 +221,12 @@ func (d *Detector) isRateLimited(ip string) bool {
 		}
 	}
 
-	return count >= d.config.RateLimitMax
+	return count >= config.RateLimitMax
 }
 
-// cleanupRateLimits removes old entries for an IP
-func (d *Detector) cleanupRateLimits(ip string) {
-	cutoff := time.Now().Add(-d.config.RateLimitWindow * 2)
+// cleanupRateLimits removes entries for an IP older than twice the window
+func (d *Detector) cleanupRateLimits(ip string, window time.Duration) {
+	cutoff := time.Now().Add(-window * 2)
 	timestamps := d.rateLimits[ip]
 
 	var cleaned []time.Time
@@ -170,6 +243,39 @@ func (d *Detector) cleanupRateLimits(ip string) {
 	}
 }
 
+// TimingField carries when the form was shown, in Unix milliseconds, so Analyze
+// can flag forms filled in faster than a person could
+const TimingField = "_ts"
+
+// TakeElapsed removes the timing field from data and returns how long ago it
+// says the form was shown, or 0 if it's missing or not plausible
+func TakeElapsed(data map[string]interface{}, now time.Time) time.Duration {
+	v, ok := data[TimingField]
+	if !ok {
+		return 0
+	}
+	delete(data, TimingField)
+
+	var ms int64
+	switch t := v.(type) {
+	case float64:
+		ms = int64(t)
+	case string:
+		n, err := strconv.ParseInt(t, 10, 64)
+		if err != nil {
+			return 0
+		}
+		ms = n
+	default:
+		return 0
+	}
+	elapsed := now.Sub(time.UnixMilli(ms))
+	if elapsed <= 0 || elapsed > 24*time.Hour {
+		return 0
+	}
+	return elapsed
+}
+
 // CheckHoneypot is a helper to check if honeypot field was filled
 func CheckHoneypot(data map[string]interface{}, fieldNames []string) bool {
 	for _, field := range fieldNames {
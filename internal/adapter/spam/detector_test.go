@@ -1,6 +1,7 @@
 package spam
 
 import (
+	"strconv"
 	"testing"
 	"time"
 )
@@ -112,6 +113,33 @@ func TestDetector_RateLimiting(t *testing.T) {
 	}
 }
 
+func TestDetector_AnalyzeWith(t *testing.T) {
+	detector := NewDetector(DefaultConfig())
+	config := Config{
+		ScoreThreshold:     20,
+		RateLimitWindow:    time.Hour,
+		RateLimitMax:       1,
+		HoneypotFieldNames: []string{"nickname"},
+	}
+	data := map[string]interface{}{"nickname": "bot", "website": "http://example.com"}
+
+	// Only the config's honeypot fields count
+	result := detector.AnalyzeWith(config, "10.0.0.1", "Mozilla/5.0", data, 0)
+	if !result.IsSpam || result.Threshold != 20 || !containsFlag(result.Flags, "honeypot_filled:nickname") || containsFlag(result.Flags, "honeypot_filled:website") {
+		t.Errorf("unexpected result %+v", result)
+	}
+
+	// A lower threshold catches what the default lets through
+	clean := map[string]interface{}{"name": "Ada"}
+	detector.RecordSubmissionWith(config, "10.0.0.2")
+	if result := detector.Analyze("10.0.0.2", "Mozilla/5.0", clean, 0); result.IsSpam {
+		t.Errorf("expected one submission within the default limit, got %+v", result)
+	}
+	if result := detector.AnalyzeWith(config, "10.0.0.2", "Mozilla/5.0", clean, 0); !result.IsSpam || !containsFlag(result.Flags, "rate_limited") {
+		t.Errorf("expected the config's rate limit to apply, got %+v", result)
+	}
+}
+
 func TestDetector_FastSubmission(t *testing.T) {
 	detector := NewDetector(DefaultConfig())
 	data := map[string]interface{}{"name": "Test"}
@@ -129,6 +157,32 @@ func TestDetector_FastSubmission(t *testing.T) {
 	}
 }
 
+func TestTakeElapsed(t *testing.T) {
+	now := time.Now()
+	shown := now.Add(-3 * time.Second).UnixMilli()
+
+	data := map[string]interface{}{"name": "Test", TimingField: float64(shown)}
+	if got := TakeElapsed(data, now); got < 2*time.Second || got > 4*time.Second {
+		t.Errorf("expected about 3s, got %v", got)
+	}
+	if _, ok := data[TimingField]; ok {
+		t.Error("expected the timing field removed from data")
+	}
+
+	// Form posts send strings; missing, malformed and future values count as unknown
+	if got := TakeElapsed(map[string]interface{}{TimingField: strconv.FormatInt(shown, 10)}, now); got < 2*time.Second {
+		t.Errorf("expected a string timestamp to parse, got %v", got)
+	}
+	for _, v := range []interface{}{"soon", float64(now.Add(time.Minute).UnixMilli()), true} {
+		if got := TakeElapsed(map[string]interface{}{TimingField: v}, now); got != 0 {
+			t.Errorf("%v: expected 0, got %v", v, got)
+		}
+	}
+	if got := TakeElapsed(map[string]interface{}{}, now); got != 0 {
+		t.Errorf("expected 0 without the field, got %v", got)
+	}
+}
+
 func TestDetector_MultipleLinks(t *testing.T) {
 	detector := NewDetector(DefaultConfig())
 
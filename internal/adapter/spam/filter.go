@@ -0,0 +1,110 @@
+package spam
+
+import (
+	"context"
+	"regexp"
+
+	"headless_form/internal/core/domain"
+	"headless_form/internal/core/ports"
+)
+
+// maxCachedPatterns bounds the compiled filter cache; it starts over once full
+const maxCachedPatterns = 1000
+
+// FilterSource returns the global content filters, checked before each form's own
+type FilterSource func(ctx context.Context) []domain.FilterRule
+
+// FilterResult lists the content filters a submission matched
+type FilterResult struct {
+	Rejected *domain.FilterRule // First matching reject rule; nil when none matched
+	Flags    []string           // "filter:<id>" for each matching flag rule
+}
+
+// SetFilterSource makes Filter check the global filters src returns
+func (d *Detector) SetFilterSource(src FilterSource) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.filters = src
+}
+
+// Filter checks submitted data against the global content filters and the form's own.
+// It stops at the first reject rule that matches.
+func (d *Detector) Filter(ctx context.Context, formRules []domain.FilterRule, data map[string]interface{}) FilterResult {
+	d.mu.RLock()
+	src := d.filters
+	d.mu.RUnlock()
+
+	var result FilterResult
+	var global []domain.FilterRule
+	if src != nil {
+		global = src(ctx)
+	}
+	for _, rules := range [][]domain.FilterRule{global, formRules} {
+		for i := range rules {
+			rule := &rules[i]
+			if !d.matches(rule, data) {
+				continue
+			}
+			if rule.Action == domain.FilterReject {
+				result.Rejected = rule
+				return result
+			}
+			result.Flags = append(result.Flags, "filter:"+rule.ID)
+		}
+	}
+	return result
+}
+
+// matches reports whether any text the rule checks matches its pattern
+func (d *Detector) matches(rule *domain.FilterRule, data map[string]interface{}) bool {
+	texts := rule.FilterTexts(data)
+	if len(texts) == 0 {
+		return false
+	}
+	re := d.compiled(rule)
+	if re == nil {
+		return false
+	}
+	for _, text := range texts {
+		if re.MatchString(text) {
+			return true
+		}
+	}
+	return false
+}
+
+// compiled returns the rule's expression from the cache, compiling it on first use.
+// Rules are validated when saved, so nil only comes from rules stored some other way.
+func (d *Detector) compiled(rule *domain.FilterRule) *regexp.Regexp {
+	key := "keyword:" + rule.Pattern
+	if rule.Regex {
+		key = "regex:" + rule.Pattern
+	}
+	d.mu.RLock()
+	re, ok := d.patterns[key]
+	d.mu.RUnlock()
+	if ok {
+		return re
+	}
+
+	re, _ = rule.Compile()
+	d.mu.Lock()
+	if len(d.patterns) >= maxCachedPatterns {
+		d.patterns = make(map[string]*regexp.Regexp)
+	}
+	d.patterns[key] = re
+	d.mu.Unlock()
+	return re
+}
+
+// SettingsFilters returns a FilterSource for the global filters in the site settings,
+// which are read on every call so changes apply to the next submission
+func SettingsFilters(repo ports.SettingsRepository) FilterSource {
+	return func(ctx context.Context) []domain.FilterRule {
+		settings, err := repo.Get(ctx)
+		if err != nil || settings == nil {
+			return nil
+		}
+		return settings.Filters
+	}
+}
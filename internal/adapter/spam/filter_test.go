@@ -0,0 +1,57 @@
+package spam
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"headless_form/internal/core/domain"
+)
+
+func TestDetector_Filter(t *testing.T) {
+	detector := NewDetector(DefaultConfig())
+	formRules := []domain.FilterRule{
+		{ID: "casino", Pattern: "casino", Action: domain.FilterFlag},
+		{ID: "crème", Pattern: "crème brûlée", Action: domain.FilterFlag},
+		{ID: "crypto", Pattern: `(?i)crypto\s+offer`, Regex: true, Action: domain.FilterReject},
+		{ID: "subject", Pattern: "urgent", Action: domain.FilterFlag, Fields: []string{"subject"}},
+	}
+
+	tests := []struct {
+		name     string
+		data     map[string]interface{}
+		rejected string
+		flags    string
+	}{
+		{"clean", map[string]interface{}{"message": "Hello there"}, "", ""},
+		{"keyword ignoring case", map[string]interface{}{"message": "Best CASINO in town"}, "", "filter:casino"},
+		{"keyword inside a word", map[string]interface{}{"message": "Occasionally"}, "", ""},
+		{"non-ASCII keyword", map[string]interface{}{"message": "Une Crème brûlée, svp"}, "", "filter:crème"},
+		{"non-ASCII word boundary", map[string]interface{}{"message": "crème brûléeé"}, "", ""},
+		{"nested values", map[string]interface{}{"tags": []interface{}{"x", map[string]interface{}{"y": "casino"}}}, "", "filter:casino"},
+		{"other fields are not checked", map[string]interface{}{"message": "urgent"}, "", ""},
+		{"field scoped rule", map[string]interface{}{"subject": "URGENT!"}, "", "filter:subject"},
+		{"reject", map[string]interface{}{"message": "CRYPTO  offer"}, "crypto", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := detector.Filter(context.Background(), formRules, tt.data)
+			rejected := ""
+			if result.Rejected != nil {
+				rejected = result.Rejected.ID
+			}
+			if rejected != tt.rejected || strings.Join(result.Flags, ",") != tt.flags {
+				t.Errorf("got rejected %q flags %v, want %q %q", rejected, result.Flags, tt.rejected, tt.flags)
+			}
+		})
+	}
+
+	// Global filters are checked before the form's own
+	detector.SetFilterSource(func(context.Context) []domain.FilterRule {
+		return []domain.FilterRule{{ID: "global", Pattern: "casino", Action: domain.FilterReject}}
+	})
+	result := detector.Filter(context.Background(), formRules, map[string]interface{}{"message": "casino"})
+	if result.Rejected == nil || result.Rejected.ID != "global" || len(result.Flags) != 0 {
+		t.Errorf("expected the global reject rule to match first, got %+v", result)
+	}
+}
@@ -0,0 +1,103 @@
+package spam
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Language heuristics only judge text with at least this many letters
+const minLanguageLetters = 20
+
+// latinVowels are the vowels of the Latin-script languages, with their accented forms
+const latinVowels = "aeiouyàáâãäåæèéêëìíîïòóôõöøùúûüýÿœąęėěįıőųűůū"
+
+// contentTexts returns the submitted text values, leaving out fields starting with _
+// and words that are links or email addresses, which are written in Latin letters
+// whatever the language
+func contentTexts(data map[string]interface{}) []string {
+	var texts []string
+	for k, v := range data {
+		s, ok := v.(string)
+		if !ok || s == "" || strings.HasPrefix(k, "_") {
+			continue
+		}
+		words := strings.Fields(s)
+		kept := words[:0]
+		for _, w := range words {
+			if !strings.Contains(w, "@") && !strings.Contains(w, "://") && !strings.HasPrefix(strings.ToLower(w), "www.") {
+				kept = append(kept, w)
+			}
+		}
+		if len(kept) > 0 {
+			texts = append(texts, strings.Join(kept, " "))
+		}
+	}
+	return texts
+}
+
+// foreignScript reports whether most letters in texts belong to none of scripts, which
+// are named as in unicode.Scripts. Letters shared by all scripts don't count either way.
+func foreignScript(texts []string, scripts []string) bool {
+	var native, foreign int
+	for _, text := range texts {
+		for _, r := range text {
+			if !unicode.IsLetter(r) || unicode.In(r, unicode.Common, unicode.Inherited) {
+				continue
+			}
+			if inScripts(r, scripts) {
+				native++
+			} else {
+				foreign++
+			}
+		}
+	}
+	return native+foreign >= minLanguageLetters && foreign > native
+}
+
+// inScripts reports whether r belongs to one of scripts
+func inScripts(r rune, scripts []string) bool {
+	for _, name := range scripts {
+		if table := unicode.Scripts[name]; table != nil && unicode.Is(table, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// gibberish reports whether at least half the longer words in texts, and two or more,
+// can't be pronounced: keyboard mashing such as "asdfgh qwrtzk", which has no vowels
+// or long runs of consonants
+func gibberish(texts []string) bool {
+	var words, nonsense int
+	for _, text := range texts {
+		for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool { return !unicode.IsLetter(r) }) {
+			if len([]rune(word)) < 4 || !inScripts([]rune(word)[0], []string{"Latin"}) {
+				continue
+			}
+			words++
+			if unpronounceable(word) {
+				nonsense++
+			}
+		}
+	}
+	return nonsense >= 2 && nonsense*2 >= words
+}
+
+// unpronounceable reports whether a word of five or more letters has no vowels, or
+// any word has five consonants in a row
+func unpronounceable(word string) bool {
+	var letters, vowels, run int
+	for _, r := range word {
+		letters++
+		if strings.ContainsRune(latinVowels, r) {
+			vowels++
+			run = 0
+			continue
+		}
+		run++
+		if run >= 5 {
+			return true
+		}
+	}
+	return vowels == 0 && letters >= 5
+}
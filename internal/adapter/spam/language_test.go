@@ -0,0 +1,49 @@
+package spam
+
+import "testing"
+
+func TestDetector_Language(t *testing.T) {
+	detector := NewDetector(DefaultConfig())
+
+	tests := []struct {
+		name     string
+		language string
+		message  string
+		wantFlag string
+	}{
+		{"english on an english form", "en", "Hello, I'd like to know more about your pricing plans.", ""},
+		{"cyrillic on an english form", "en", "Здравствуйте, предлагаем продвижение вашего сайта недорого", "foreign_script"},
+		{"russian on a russian form", "ru", "Здравствуйте, хочу узнать подробнее о ваших тарифах", ""},
+		{"russian with an email address", "ru", "Здравствуйте, напишите мне на ivan.petrov@example.com пожалуйста", ""},
+		{"chinese on a german form", "de", "您好，我们提供专业的网站推广服务，价格优惠，欢迎联系我们", "foreign_script"},
+		{"japanese on a japanese form", "ja", "こんにちは、料金プランについて詳しく教えてください。よろしくお願いします", ""},
+		{"too short to judge", "en", "Привет", ""},
+		{"keyboard mashing", "en", "asdfgh qwrtzk sdfjkl zxcvbnm", "gibberish"},
+		{"german compounds", "de", "Ich habe eine Frage zur Angstschweiß Versicherung und zum Vertrag", ""},
+		{"no language set", "", "Здравствуйте, предлагаем продвижение вашего сайта недорого", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.Language = tt.language
+			result := detector.AnalyzeWith(config, "1.2.3.4", "Mozilla/5.0", map[string]interface{}{"message": tt.message}, 0)
+			for _, flag := range []string{"foreign_script", "gibberish"} {
+				if got := containsFlag(result.Flags, flag); got != (flag == tt.wantFlag) {
+					t.Errorf("%s: got %v, flags %v", flag, got, result.Flags)
+				}
+			}
+		})
+	}
+}
+
+func TestUnpronounceable(t *testing.T) {
+	for word, want := range map[string]bool{
+		"hello": false, "strengths": true, "rhythm": false, "xkcd": false,
+		"qwrtzk": true, "asdfgh": true, "straße": false, "crwth": true,
+	} {
+		if got := unpronounceable(word); got != want {
+			t.Errorf("%s: expected %v, got %v", word, want, got)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package spam
+
+import (
+	"context"
+	"log"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	"headless_form/internal/core/domain"
+	"headless_form/internal/core/ports"
+)
+
+// maxLinks bounds the links taken from one submission
+const maxLinks = 100
+
+// linkPattern finds http(s) links in submitted text
+var linkPattern = regexp.MustCompile(`(?i)https?://[^\s<>"'()\[\]]+`)
+
+// LinkChecker looks up submitted links with an external service, such as Safe Browsing
+type LinkChecker interface {
+	Name() string
+	// Check returns the links the service lists as unsafe
+	Check(ctx context.Context, links []string) ([]string, error)
+}
+
+// SettingsSource returns the site-wide spam settings, nil for the defaults
+type SettingsSource func(ctx context.Context) *domain.SpamSettings
+
+// SetSettingsSource makes Tune apply the spam settings src returns
+func (d *Detector) SetSettingsSource(src SettingsSource) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.settings = src
+}
+
+// Tune returns config with the site-wide spam settings applied: the flag weights, the
+// blocked domains and, when it has a key, Safe Browsing to check links with
+func (d *Detector) Tune(ctx context.Context, config Config) Config {
+	d.mu.RLock()
+	src := d.settings
+	d.mu.RUnlock()
+	if src == nil {
+		return config
+	}
+	settings := src(ctx)
+	if settings == nil {
+		return config
+	}
+	config.Weights = settings.Weights
+	config.BlockedDomains = settings.BlockedDomains
+	if settings.SafeBrowsingKey != "" {
+		config.Links = NewSafeBrowsing(settings.SafeBrowsingKey)
+	}
+	return config
+}
+
+// CheckLinks asks config's link checker, if any, about the links in data and adds the
+// unsafe_link weight when it lists one. If the checker fails the score stands, flagged
+// link_check_error.
+func (d *Detector) CheckLinks(ctx context.Context, config Config, data map[string]interface{}, score SpamScore) SpamScore {
+	if config.Links == nil || score.Score >= 100 {
+		return score
+	}
+	found := links(data)
+	if len(found) == 0 {
+		return score
+	}
+
+	unsafe, err := config.Links.Check(ctx, found)
+	switch {
+	case err != nil:
+		log.Printf("[SPAM] %s link check failed: %v", config.Links.Name(), err)
+		score.Flags = append(score.Flags, "link_check_error:"+config.Links.Name())
+	case len(unsafe) > 0:
+		score.Score = min(score.Score+config.weight(domain.SpamFlagUnsafeLink), 100)
+		score.Flags = append(score.Flags, domain.SpamFlagUnsafeLink+":"+linkHost(unsafe[0]))
+	}
+	score.IsSpam = score.Score >= config.ScoreThreshold
+	return score
+}
+
+// links returns the distinct http(s) links in the submitted text values, leaving out
+// fields starting with _
+func links(data map[string]interface{}) []string {
+	var found []string
+	for k, v := range data {
+		s, ok := v.(string)
+		if !ok || strings.HasPrefix(k, "_") {
+			continue
+		}
+		for _, link := range linkPattern.FindAllString(s, -1) {
+			link = strings.TrimRight(link, ".,;:!?")
+			if !slices.Contains(found, link) {
+				found = append(found, link)
+			}
+			if len(found) == maxLinks {
+				return found
+			}
+		}
+	}
+	return found
+}
+
+// linkHost returns the lowercase host name of a link, "" if it has none
+func linkHost(link string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
+
+// blockedHost returns the host of the first link that's on one of the blocked domains
+// or their subdomains, "" when none is
+func blockedHost(found []string, blocked []string) string {
+	if len(blocked) == 0 {
+		return ""
+	}
+	for _, link := range found {
+		host := linkHost(link)
+		if host == "" {
+			continue
+		}
+		for _, d := range blocked {
+			if host == d || strings.HasSuffix(host, "."+d) {
+				return host
+			}
+		}
+	}
+	return ""
+}
+
+// SettingsSpam returns a SettingsSource for the spam settings in the site settings,
+// which are read on every call so changes apply to the next submission
+func SettingsSpam(repo ports.SettingsRepository) SettingsSource {
+	return func(ctx context.Context) *domain.SpamSettings {
+		settings, err := repo.Get(ctx)
+		if err != nil || settings == nil {
+			return nil
+		}
+		return settings.Spam
+	}
+}
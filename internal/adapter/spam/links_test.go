@@ -0,0 +1,175 @@
+package spam
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"headless_form/internal/core/domain"
+)
+
+func TestDetector_Weights(t *testing.T) {
+	detector := NewDetector(DefaultConfig())
+	data := map[string]interface{}{"name": "Test"}
+
+	if result := detector.Analyze("1.2.3.4", "", data, 0); result.Score != 30 {
+		t.Errorf("expected the default empty_user_agent weight, got %d", result.Score)
+	}
+
+	config := DefaultConfig()
+	config.Weights = map[string]int{"empty_user_agent": 60, "fast_submission": 0}
+	result := detector.AnalyzeWith(config, "1.2.3.4", "", data, 500_000_000)
+	if result.Score != 60 || !result.IsSpam {
+		t.Errorf("expected only the configured weight scored, got %+v", result)
+	}
+	if !containsFlag(result.Flags, "fast_submission") {
+		t.Errorf("expected a zero weight to keep the flag, got %v", result.Flags)
+	}
+}
+
+func TestDetector_BlockedDomains(t *testing.T) {
+	detector := NewDetector(DefaultConfig())
+	config := DefaultConfig()
+	config.BlockedDomains = []string{"spam.example"}
+
+	tests := []struct {
+		message  string
+		wantFlag string
+	}{
+		{"See https://spam.example/offer", "blocked_domain:spam.example"},
+		{"See http://Cheap.SPAM.example:8080/x.", "blocked_domain:cheap.spam.example"},
+		{"See https://notspam.example/", ""},
+		{"See https://example.com/?ref=spam.example", ""},
+		{"No links at all", ""},
+	}
+	for _, tt := range tests {
+		result := detector.AnalyzeWith(config, "1.2.3.4", "Mozilla/5.0", map[string]interface{}{"message": tt.message}, 0)
+		if tt.wantFlag == "" {
+			if result.Score != 0 {
+				t.Errorf("%q: expected no score, got %+v", tt.message, result)
+			}
+			continue
+		}
+		if result.Score != 50 || !containsFlag(result.Flags, tt.wantFlag) {
+			t.Errorf("%q: expected %s scored 50, got %+v", tt.message, tt.wantFlag, result)
+		}
+	}
+}
+
+func TestDetector_Tune(t *testing.T) {
+	detector := NewDetector(DefaultConfig())
+	if config := detector.Tune(context.Background(), DefaultConfig()); config.Weights != nil || config.Links != nil {
+		t.Errorf("expected the config unchanged without settings, got %+v", config)
+	}
+
+	detector.SetSettingsSource(func(context.Context) *domain.SpamSettings {
+		return &domain.SpamSettings{
+			Weights:         map[string]int{"rate_limited": 10},
+			BlockedDomains:  []string{"spam.example"},
+			SafeBrowsingKey: "key123",
+		}
+	})
+	config := detector.Tune(context.Background(), DefaultConfig())
+	if config.weight("rate_limited") != 10 || config.weight("fast_submission") != 25 {
+		t.Errorf("expected the settings' weights over the defaults, got %v", config.Weights)
+	}
+	if len(config.BlockedDomains) != 1 {
+		t.Errorf("expected the blocked domains, got %v", config.BlockedDomains)
+	}
+	if sb, ok := config.Links.(*SafeBrowsing); !ok || sb.Key != "key123" {
+		t.Errorf("expected Safe Browsing with the key, got %+v", config.Links)
+	}
+}
+
+func TestSafeBrowsing_Check(t *testing.T) {
+	var got struct {
+		ThreatInfo struct {
+			ThreatEntries []safeBrowsingEntry `json:"threatEntries"`
+		} `json:"threatInfo"`
+	}
+	var key string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key = r.URL.Query().Get("key")
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		if key == "bad" {
+			http.Error(w, `{"error": {"code": 400, "message": "API key not valid"}}`, http.StatusBadRequest)
+			return
+		}
+		var matches []map[string]interface{}
+		for _, e := range got.ThreatInfo.ThreatEntries {
+			if e.URL == "https://malware.example/x" {
+				matches = append(matches, map[string]interface{}{"threatType": "MALWARE", "threat": e})
+			}
+		}
+		if len(matches) == 0 {
+			_, _ = w.Write([]byte("{}"))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"matches": matches})
+	}))
+	defer server.Close()
+
+	sb := NewSafeBrowsing("key123")
+	sb.Endpoint = server.URL
+	unsafe, err := sb.Check(context.Background(), []string{"https://example.com", "https://malware.example/x"})
+	if err != nil || len(unsafe) != 1 || unsafe[0] != "https://malware.example/x" {
+		t.Fatalf("expected the malware link, got %v (%v)", unsafe, err)
+	}
+	if key != "key123" || len(got.ThreatInfo.ThreatEntries) != 2 {
+		t.Errorf("expected both links sent with the key, got %q %+v", key, got)
+	}
+	if unsafe, err := sb.Check(context.Background(), []string{"https://example.com"}); err != nil || len(unsafe) != 0 {
+		t.Errorf("expected no matches, got %v (%v)", unsafe, err)
+	}
+
+	sb.Key = "bad"
+	if _, err := sb.Check(context.Background(), []string{"https://example.com"}); err == nil {
+		t.Error("expected an error for an invalid key")
+	}
+}
+
+// fakeLinks lists the same links as unsafe for every check
+type fakeLinks struct {
+	unsafe []string
+	err    error
+	calls  int
+}
+
+func (f *fakeLinks) Name() string { return "fake" }
+
+func (f *fakeLinks) Check(ctx context.Context, links []string) ([]string, error) {
+	f.calls++
+	return f.unsafe, f.err
+}
+
+func TestDetector_CheckLinks(t *testing.T) {
+	detector := NewDetector(DefaultConfig())
+	config := DefaultConfig()
+	data := map[string]interface{}{"message": "Download https://malware.example/x now"}
+	heuristic := SpamScore{Score: 10, Threshold: 50}
+
+	if got := detector.CheckLinks(context.Background(), config, data, heuristic); got.Score != 10 {
+		t.Errorf("expected the score unchanged without a checker, got %+v", got)
+	}
+
+	checker := &fakeLinks{unsafe: []string{"https://malware.example/x"}}
+	config.Links = checker
+	got := detector.CheckLinks(context.Background(), config, data, heuristic)
+	if got.Score != 100 || !got.IsSpam || !containsFlag(got.Flags, "unsafe_link:malware.example") {
+		t.Errorf("expected the unsafe link scored, got %+v", got)
+	}
+
+	calls := checker.calls
+	detector.CheckLinks(context.Background(), config, map[string]interface{}{"message": "no links"}, heuristic)
+	if checker.calls != calls {
+		t.Error("expected no lookup for a submission without links")
+	}
+
+	checker.err = errors.New("timeout")
+	if got := detector.CheckLinks(context.Background(), config, data, heuristic); got.IsSpam || !containsFlag(got.Flags, "link_check_error:fake") {
+		t.Errorf("expected the heuristic verdict with an error flag, got %+v", got)
+	}
+}
@@ -0,0 +1,97 @@
+package spam
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"headless_form/internal/core/domain"
+	"headless_form/internal/core/ports"
+)
+
+// Verdict is an external spam service's opinion of a submission
+type Verdict int
+
+const (
+	VerdictHam     Verdict = iota // Looks legitimate
+	VerdictSpam                   // Spam
+	VerdictBlatant                // Spam so obvious the service says to drop it
+)
+
+// Request describes a submission to an external spam service
+type Request struct {
+	IP        string
+	UserAgent string
+	Referer   string
+	Data      map[string]interface{}
+}
+
+// Provider is an external spam service, such as Akismet
+type Provider interface {
+	Name() string
+	Check(ctx context.Context, req Request) (Verdict, error)
+}
+
+// ProviderSource returns the provider to consult, nil when none is configured
+type ProviderSource func(ctx context.Context) Provider
+
+// SetProviderSource makes Consult ask the provider src returns
+func (d *Detector) SetProviderSource(src ProviderSource) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.providers = src
+}
+
+// Consult asks the configured provider, if any, about a submission the heuristics scored,
+// and combines both: a spam verdict adds the provider_spam weight, a blatant one makes it 100.
+// If the provider fails the heuristic score stands, flagged provider_error.
+func (d *Detector) Consult(ctx context.Context, config Config, req Request, score SpamScore) SpamScore {
+	d.mu.RLock()
+	src := d.providers
+	d.mu.RUnlock()
+	if src == nil || score.Score >= 100 {
+		return score
+	}
+	provider := src(ctx)
+	if provider == nil {
+		return score
+	}
+
+	verdict, err := provider.Check(ctx, req)
+	switch {
+	case err != nil:
+		log.Printf("[SPAM] %s check failed: %v", provider.Name(), err)
+		score.Flags = append(score.Flags, "provider_error:"+provider.Name())
+	case verdict == VerdictBlatant:
+		score.Score = 100
+		score.Flags = append(score.Flags, "provider_blatant:"+provider.Name())
+	case verdict == VerdictSpam:
+		score.Score = min(score.Score+config.weight(domain.SpamFlagProvider), 100)
+		score.Flags = append(score.Flags, "provider_spam:"+provider.Name())
+	}
+	score.IsSpam = score.Score >= config.ScoreThreshold
+	return score
+}
+
+// SettingsProviders returns a ProviderSource for the spam provider chosen in the site
+// settings, which are read on every call so changes apply to the next submission
+func SettingsProviders(repo ports.SettingsRepository, client *http.Client) ProviderSource {
+	return func(ctx context.Context) Provider {
+		settings, err := repo.Get(ctx)
+		if err != nil || settings == nil {
+			return nil
+		}
+		switch settings.SpamProvider {
+		case domain.SpamProviderAkismet:
+			if settings.SpamProviderKey == "" {
+				return nil
+			}
+			a := NewAkismet(settings.SpamProviderKey, settings.SiteURL)
+			if client != nil {
+				a.Client = client
+			}
+			return a
+		}
+		return nil
+	}
+}
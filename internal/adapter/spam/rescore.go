@@ -0,0 +1,69 @@
+package spam
+
+import (
+	"context"
+	"strings"
+
+	"headless_form/internal/core/domain"
+)
+
+// recordedFlags are the flags a stored submission keeps when re-scored, since they
+// come from its request or from external lookups that aren't repeated. They're
+// weighted anew; the content checks and filters run again.
+var recordedFlags = map[string]bool{
+	domain.SpamFlagHoneypot:    true,
+	domain.SpamFlagEmptyAgent:  true,
+	domain.SpamFlagBotAgent:    true,
+	domain.SpamFlagFast:        true,
+	domain.SpamFlagRateLimited: true,
+	domain.SpamFlagUnsafeLink:  true,
+	domain.SpamFlagProvider:    true,
+	domain.SpamFlagRepeat:      true,
+}
+
+// Rescore scores a stored submission again with config, e.g. after the form's spam
+// settings changed. Flags recorded when it was submitted (previous, nil if none) that
+// can't be checked again keep counting with their current weight; the content checks
+// and content filters (global and formRules) run on data again.
+func (d *Detector) Rescore(ctx context.Context, config Config, formRules []domain.FilterRule, previous *domain.SpamMeta, data map[string]interface{}) SpamScore {
+	var score int
+	var flags []string
+	if previous != nil {
+		for _, flag := range previous.Flags {
+			name, _, _ := strings.Cut(flag, ":")
+			switch {
+			case recordedFlags[name]:
+				score += config.weight(name)
+			case name == "provider_blatant":
+				score += 100
+			case name == "link_check_error" || name == "provider_error":
+				// Informational, no weight
+			default:
+				continue // Checked again below
+			}
+			flags = append(flags, flag)
+		}
+	}
+
+	contentScore, contentFlags := checkContent(config, data)
+	score += contentScore
+	flags = append(flags, contentFlags...)
+
+	filtered := d.Filter(ctx, formRules, data)
+	if rule := filtered.Rejected; rule != nil {
+		// Stored before the rule rejected such content
+		filtered.Flags = append(filtered.Flags, "filter:"+rule.ID)
+	}
+	if len(filtered.Flags) > 0 {
+		score = 100
+		flags = append(flags, filtered.Flags...)
+	}
+
+	score = min(score, 100)
+	return SpamScore{
+		Score:     score,
+		IsSpam:    score >= config.ScoreThreshold,
+		Flags:     flags,
+		Threshold: config.ScoreThreshold,
+	}
+}
@@ -0,0 +1,47 @@
+package spam
+
+import (
+	"context"
+	"testing"
+
+	"headless_form/internal/core/domain"
+)
+
+func TestDetector_Rescore(t *testing.T) {
+	detector := NewDetector(DefaultConfig())
+	ctx := context.Background()
+	previous := &domain.SpamMeta{
+		Score:     45,
+		Flags:     []string{"empty_user_agent", "multiple_links", "link_check_error:safe_browsing"},
+		Threshold: 50,
+	}
+	data := map[string]interface{}{"message": "See https://shop.spam.example/deals"}
+
+	// The recorded request flag counts, the content is checked again
+	config := DefaultConfig()
+	result := detector.Rescore(ctx, config, nil, previous, data)
+	if result.Score != 30 || result.IsSpam || containsFlag(result.Flags, "multiple_links") {
+		t.Errorf("expected only empty_user_agent scored, got %+v", result)
+	}
+	if !containsFlag(result.Flags, "link_check_error:safe_browsing") {
+		t.Errorf("expected informational flags kept, got %v", result.Flags)
+	}
+
+	config.BlockedDomains = []string{"spam.example"}
+	config.ScoreThreshold = 70
+	result = detector.Rescore(ctx, config, nil, previous, data)
+	if result.Score != 80 || !result.IsSpam || result.Threshold != 70 || !containsFlag(result.Flags, "blocked_domain:shop.spam.example") {
+		t.Errorf("expected the blocked domain to quarantine, got %+v", result)
+	}
+
+	config.Weights = map[string]int{"empty_user_agent": 0, "blocked_domain": 10}
+	if result := detector.Rescore(ctx, config, nil, previous, data); result.Score != 10 || result.IsSpam {
+		t.Errorf("expected the new weights applied, got %+v", result)
+	}
+
+	// Content filters added since apply, reject rules included
+	rules := []domain.FilterRule{{ID: "flt_1", Pattern: "deals", Action: domain.FilterReject}}
+	if result := detector.Rescore(ctx, DefaultConfig(), rules, nil, data); result.Score != 100 || !containsFlag(result.Flags, "filter:flt_1") {
+		t.Errorf("expected the filter to quarantine, got %+v", result)
+	}
+}
@@ -0,0 +1,173 @@
+// Package spool keeps submissions on disk while the database is unavailable,
+// one file per submission, so they can be stored once it recovers.
+package spool
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"headless_form/internal/core/domain"
+)
+
+// DefaultMaxQueued caps how many submissions are kept, so a long outage can't fill the disk
+const DefaultMaxQueued = 10000
+
+// ErrFull is returned by Put once the spool holds its maximum number of submissions
+var ErrFull = errors.New("submission spool is full")
+
+// Spool queues submissions as JSON files in a directory
+type Spool struct {
+	dir string
+	max int
+
+	mu    sync.Mutex
+	files map[string]string // Submission ID → file name
+}
+
+// Open creates dir if needed and loads the submissions already queued in it.
+// max <= 0 uses DefaultMaxQueued.
+func Open(dir string, max int) (*Spool, error) {
+	if max <= 0 {
+		max = DefaultMaxQueued
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("create spool dir: %w", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read spool dir: %w", err)
+	}
+
+	s := &Spool{dir: dir, max: max, files: make(map[string]string)}
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".json") {
+			continue // Includes .tmp files left by a crash mid-write
+		}
+		if _, id, ok := strings.Cut(strings.TrimSuffix(name, ".json"), "_"); ok {
+			s.files[id] = name
+		}
+	}
+	return s, nil
+}
+
+// Put writes the submission to disk. The file is synced and renamed into place,
+// so a crash leaves either the whole submission or nothing.
+func (s *Spool) Put(submission *domain.Submission) error {
+	data, err := json.Marshal(submission)
+	if err != nil {
+		return fmt.Errorf("encode submission: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.files[submission.ID]; ok {
+		return nil
+	}
+	if len(s.files) >= s.max {
+		return ErrFull
+	}
+
+	// Names sort by queue time, so List can return them oldest first
+	name := fmt.Sprintf("%020d_%s.json", time.Now().UnixNano(), filepath.Base(submission.ID))
+	tmp := filepath.Join(s.dir, name+".tmp")
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("create spool file: %w", err)
+	}
+	if _, err = f.Write(data); err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, filepath.Join(s.dir, name))
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("write spool file: %w", err)
+	}
+	s.files[submission.ID] = name
+	return nil
+}
+
+// List returns the queued submissions, oldest first
+func (s *Spool) List() ([]*domain.Submission, error) {
+	s.mu.Lock()
+	names := make([]string, 0, len(s.files))
+	for _, name := range s.files {
+		names = append(names, name)
+	}
+	s.mu.Unlock()
+	sort.Strings(names)
+
+	submissions := make([]*domain.Submission, 0, len(names))
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(s.dir, name))
+		if errors.Is(err, os.ErrNotExist) {
+			continue // Removed since
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read spool file: %w", err)
+		}
+		var submission domain.Submission
+		if err := json.Unmarshal(data, &submission); err != nil {
+			return nil, fmt.Errorf("decode spool file %s: %w", name, err)
+		}
+		submissions = append(submissions, &submission)
+	}
+	return submissions, nil
+}
+
+// Remove deletes a queued submission; removing one that isn't queued is a no-op
+func (s *Spool) Remove(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	name, ok := s.files[id]
+	if !ok {
+		return nil
+	}
+	if err := os.Remove(filepath.Join(s.dir, name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("remove spool file: %w", err)
+	}
+	delete(s.files, id)
+	return nil
+}
+
+// FailedDir is the subdirectory of the spool dead-lettered submissions are moved to
+const FailedDir = "failed"
+
+// DeadLetter takes a submission the database refuses for good out of the queue. Its
+// file is moved to the failed subdirectory for an operator to look at, not deleted.
+func (s *Spool) DeadLetter(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	name, ok := s.files[id]
+	if !ok {
+		return nil
+	}
+	failed := filepath.Join(s.dir, FailedDir)
+	if err := os.MkdirAll(failed, 0o700); err != nil {
+		return fmt.Errorf("create failed dir: %w", err)
+	}
+	if err := os.Rename(filepath.Join(s.dir, name), filepath.Join(failed, name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("move spool file: %w", err)
+	}
+	delete(s.files, id)
+	return nil
+}
+
+// Len returns the number of queued submissions
+func (s *Spool) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.files)
+}
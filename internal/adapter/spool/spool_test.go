@@ -0,0 +1,79 @@
+package spool
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"headless_form/internal/core/domain"
+)
+
+func TestSpool_PutListRemove(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir, 2)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+
+	for _, id := range []string{"sub_b", "sub_a"} {
+		if err := s.Put(&domain.Submission{ID: id, FormID: "f1", Data: json.RawMessage(`{"n":1}`)}); err != nil {
+			t.Fatalf("put %s: %v", id, err)
+		}
+	}
+	if err := s.Put(&domain.Submission{ID: "sub_a"}); err != nil {
+		t.Errorf("putting a queued submission again should be a no-op, got %v", err)
+	}
+	if err := s.Put(&domain.Submission{ID: "sub_c"}); !errors.Is(err, ErrFull) {
+		t.Errorf("expected ErrFull, got %v", err)
+	}
+
+	// Reopening finds what was queued, in queue order
+	s, err = Open(dir, 2)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	queued, err := s.List()
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(queued) != 2 || queued[0].ID != "sub_b" || queued[1].ID != "sub_a" || string(queued[0].Data) != `{"n":1}` {
+		t.Fatalf("unexpected queue: %+v", queued)
+	}
+
+	if err := s.Remove("sub_b"); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if err := s.Remove("sub_b"); err != nil {
+		t.Errorf("removing twice should be a no-op, got %v", err)
+	}
+	if s.Len() != 1 {
+		t.Errorf("expected 1 queued, got %d", s.Len())
+	}
+}
+
+func TestSpool_DeadLetter(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir, 0)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if err := s.Put(&domain.Submission{ID: "sub_bad", FormID: "f1"}); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	if err := s.DeadLetter("sub_bad"); err != nil {
+		t.Fatalf("dead-letter: %v", err)
+	}
+	if s.Len() != 0 {
+		t.Errorf("expected the submission out of the queue, got %d", s.Len())
+	}
+	if failed, _ := os.ReadDir(filepath.Join(dir, FailedDir)); len(failed) != 1 {
+		t.Errorf("expected the file kept in %s, got %d files", FailedDir, len(failed))
+	}
+
+	// Reopening doesn't queue it again
+	if s, err = Open(dir, 0); err != nil || s.Len() != 0 {
+		t.Errorf("expected an empty queue after reopening, got %d (%v)", s.Len(), err)
+	}
+}
@@ -0,0 +1,188 @@
+// Package sqltrace wraps a database/sql driver to time every statement, log slow
+// queries with redacted parameters and count queries per request.
+package sqltrace
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"headless_form/internal/adapter/logger"
+)
+
+// maxRecentSlow is how many slow queries are kept for the stats endpoint
+const maxRecentSlow = 20
+
+// maxQueryLen truncates long statements in logs and stats
+const maxQueryLen = 500
+
+var log = logger.Module("sql")
+
+// SlowQuery is a statement that took longer than the monitor's threshold
+type SlowQuery struct {
+	At         time.Time `json:"at"`
+	Query      string    `json:"query"`
+	Args       []string  `json:"args"` // Redacted: strings and blobs are reduced to their length
+	DurationMS float64   `json:"duration_ms"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// Stats summarizes all statements observed since startup
+type Stats struct {
+	Queries         int64       `json:"queries"`
+	SlowQueries     int64       `json:"slow_queries"`
+	Errors          int64       `json:"errors"`
+	AvgMS           float64     `json:"avg_ms"`
+	MaxMS           float64     `json:"max_ms"`
+	SlowThresholdMS int64       `json:"slow_threshold_ms"`
+	RecentSlow      []SlowQuery `json:"recent_slow"` // Newest first
+}
+
+// Monitor collects query timings. It is safe for concurrent use.
+type Monitor struct {
+	threshold time.Duration
+
+	mu      sync.Mutex
+	queries int64
+	slow    int64
+	errors  int64
+	total   time.Duration
+	max     time.Duration
+	recent  []SlowQuery // ring buffer, next write at recent[next]
+	next    int
+}
+
+// NewMonitor creates a monitor that flags statements slower than threshold
+func NewMonitor(threshold time.Duration) *Monitor {
+	return &Monitor{threshold: threshold}
+}
+
+// Stats returns a snapshot of the collected metrics
+func (m *Monitor) Stats() Stats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s := Stats{
+		Queries:         m.queries,
+		SlowQueries:     m.slow,
+		Errors:          m.errors,
+		MaxMS:           ms(m.max),
+		SlowThresholdMS: m.threshold.Milliseconds(),
+		RecentSlow:      make([]SlowQuery, 0, len(m.recent)),
+	}
+	if m.queries > 0 {
+		s.AvgMS = ms(m.total) / float64(m.queries)
+	}
+	for i := 1; i <= len(m.recent); i++ {
+		s.RecentSlow = append(s.RecentSlow, m.recent[(m.next-i+len(m.recent))%len(m.recent)])
+	}
+	return s
+}
+
+// observe records one statement execution
+func (m *Monitor) observe(ctx context.Context, query string, args []driver.NamedValue, d time.Duration, err error) {
+	if c, ok := ctx.Value(counterKey{}).(*atomic.Int64); ok {
+		c.Add(1)
+	}
+
+	failed := err != nil
+	slow := d >= m.threshold
+
+	m.mu.Lock()
+	m.queries++
+	m.total += d
+	if d > m.max {
+		m.max = d
+	}
+	if failed {
+		m.errors++
+	}
+	if slow {
+		m.slow++
+	}
+	m.mu.Unlock()
+
+	if !slow && !log.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+
+	q := SlowQuery{
+		At:         time.Now().UTC(),
+		Query:      normalize(query),
+		Args:       redact(args),
+		DurationMS: ms(d),
+	}
+	if failed {
+		q.Error = err.Error()
+	}
+
+	if !slow {
+		log.Debug("query", "sql", q.Query, "args", q.Args, "duration_ms", q.DurationMS)
+		return
+	}
+	log.Warn("slow query", "sql", q.Query, "args", q.Args, "duration_ms", q.DurationMS, "error", q.Error)
+
+	m.mu.Lock()
+	if len(m.recent) < maxRecentSlow {
+		m.recent = append(m.recent, q)
+		m.next = len(m.recent) % maxRecentSlow
+	} else {
+		m.recent[m.next] = q
+		m.next = (m.next + 1) % maxRecentSlow
+	}
+	m.mu.Unlock()
+}
+
+type counterKey struct{}
+
+// WithCounter returns a context that counts the queries run with it (or its children)
+func WithCounter(ctx context.Context) context.Context {
+	return context.WithValue(ctx, counterKey{}, new(atomic.Int64))
+}
+
+// QueryCount returns the number of queries counted on ctx so far
+func QueryCount(ctx context.Context) int64 {
+	if c, ok := ctx.Value(counterKey{}).(*atomic.Int64); ok {
+		return c.Load()
+	}
+	return 0
+}
+
+// redact renders parameters without exposing submitted data:
+// numbers, booleans and times are shown as-is, text and blobs by length only
+func redact(args []driver.NamedValue) []string {
+	out := make([]string, len(args))
+	for i, a := range args {
+		switch v := a.Value.(type) {
+		case nil:
+			out[i] = "NULL"
+		case string:
+			out[i] = fmt.Sprintf("<string len=%d>", len(v))
+		case []byte:
+			out[i] = fmt.Sprintf("<bytes len=%d>", len(v))
+		case time.Time:
+			out[i] = v.UTC().Format(time.RFC3339)
+		default:
+			out[i] = fmt.Sprint(v)
+		}
+	}
+	return out
+}
+
+// normalize collapses whitespace and truncates long statements
+func normalize(query string) string {
+	q := strings.Join(strings.Fields(query), " ")
+	if len(q) > maxQueryLen {
+		q = q[:maxQueryLen] + "..."
+	}
+	return q
+}
+
+func ms(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
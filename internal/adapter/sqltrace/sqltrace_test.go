@@ -0,0 +1,93 @@
+package sqltrace
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+	"testing"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+func TestOpen_CountsAndFlagsSlowQueries(t *testing.T) {
+	// A zero threshold flags every statement as slow
+	mon := NewMonitor(0)
+	db, err := Open("sqlite", ":memory:", mon)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	ctx := WithCounter(context.Background())
+	if _, err := db.ExecContext(ctx, `CREATE TABLE t (id INTEGER, email TEXT)`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.ExecContext(ctx, `INSERT INTO t (id, email) VALUES (?, ?)`, 1, "alice@example.com"); err != nil {
+		t.Fatal(err)
+	}
+	var email string
+	if err := db.QueryRowContext(ctx, `SELECT email FROM t WHERE id = ?`, 1).Scan(&email); err != nil {
+		t.Fatal(err)
+	}
+	if email != "alice@example.com" {
+		t.Errorf("unexpected value %q", email)
+	}
+	if _, err := db.ExecContext(ctx, `SELECT * FROM missing`); err == nil {
+		t.Error("expected error for missing table")
+	}
+
+	if got := QueryCount(ctx); got != 4 {
+		t.Errorf("expected 4 queries counted, got %d", got)
+	}
+
+	stats := mon.Stats()
+	if stats.Queries < 4 || stats.SlowQueries != stats.Queries || stats.Errors != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+	if len(stats.RecentSlow) == 0 || !strings.Contains(stats.RecentSlow[0].Query, "missing") {
+		t.Fatalf("expected newest slow query first, got %+v", stats.RecentSlow)
+	}
+	for _, q := range stats.RecentSlow {
+		for _, a := range q.Args {
+			if strings.Contains(a, "alice") {
+				t.Errorf("parameter not redacted: %v", q.Args)
+			}
+		}
+	}
+}
+
+func TestMonitor_RecentSlowIsBounded(t *testing.T) {
+	mon := NewMonitor(time.Millisecond)
+	ctx := context.Background()
+	for i := 0; i < maxRecentSlow+5; i++ {
+		mon.observe(ctx, "SELECT "+strings.Repeat("x", i), nil, 2*time.Millisecond, nil)
+	}
+	mon.observe(ctx, "SELECT fast", nil, 0, nil)
+
+	stats := mon.Stats()
+	if stats.Queries != maxRecentSlow+6 || stats.SlowQueries != maxRecentSlow+5 {
+		t.Errorf("unexpected counts %+v", stats)
+	}
+	if len(stats.RecentSlow) != maxRecentSlow {
+		t.Fatalf("expected %d recent slow queries, got %d", maxRecentSlow, len(stats.RecentSlow))
+	}
+	if want := "SELECT " + strings.Repeat("x", maxRecentSlow+4); stats.RecentSlow[0].Query != want {
+		t.Errorf("expected newest first, got %q", stats.RecentSlow[0].Query)
+	}
+}
+
+func TestRedact(t *testing.T) {
+	got := redact([]driver.NamedValue{
+		{Value: int64(42)},
+		{Value: "secret"},
+		{Value: []byte{1, 2, 3}},
+		{Value: nil},
+		{Value: true},
+	})
+	want := []string{"42", "<string len=6>", "<bytes len=3>", "NULL", "true"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
@@ -3,9 +3,11 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"headless_form/internal/core/domain"
 	"headless_form/internal/core/ports"
+	"time"
 
 	_ "github.com/lib/pq" // Postgres driver
 )
@@ -53,6 +55,7 @@ func (s *Store) migrate() error {
 		status TEXT NOT NULL DEFAULT 'unread',
 		data JSONB NOT NULL,
 		meta JSONB NOT NULL,
+		is_test BOOLEAN NOT NULL DEFAULT FALSE,
 		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 	);
 
@@ -66,6 +69,12 @@ func (s *Store) migrate() error {
 		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 	);
 
+	CREATE TABLE IF NOT EXISTS locks (
+		name TEXT PRIMARY KEY,
+		owner TEXT NOT NULL,
+		expires_at TIMESTAMPTZ NOT NULL
+	);
+
 	CREATE INDEX IF NOT EXISTS idx_forms_public_id ON forms(public_id);
 	CREATE INDEX IF NOT EXISTS idx_submissions_form_id ON submissions(form_id);
 	CREATE INDEX IF NOT EXISTS idx_submissions_status ON submissions(status);
@@ -95,6 +104,50 @@ func (s *Store) Settings() ports.SettingsRepository {
 	return nil // Not implemented for postgres yet
 }
 
+func (s *Store) Alert() ports.AlertRepository {
+	return &AlertRepository{db: s.db}
+}
+
+func (s *Store) FormToken() ports.FormTokenRepository {
+	return &FormTokenRepository{db: s.db}
+}
+
+func (s *Store) Audit() ports.AuditRepository {
+	return &AuditRepository{db: s.db}
+}
+
+func (s *Store) Task() ports.TaskRepository {
+	return &TaskRepository{db: s.db}
+}
+
+func (s *Store) Comment() ports.CommentRepository {
+	return &CommentRepository{db: s.db}
+}
+
+func (s *Store) Notification() ports.NotificationRepository {
+	return &NotificationRepository{db: s.db}
+}
+
+func (s *Store) Lock() ports.LockRepository {
+	return &LockRepository{db: s.db}
+}
+
+func (s *Store) WebhookDelivery() ports.WebhookDeliveryRepository {
+	return &WebhookDeliveryRepository{db: s.db}
+}
+
+func (s *Store) EmailQueue() ports.EmailQueueRepository {
+	return &EmailQueueRepository{db: s.db}
+}
+
+func (s *Store) SpamFingerprint() ports.SpamFingerprintRepository {
+	return &SpamFingerprintRepository{db: s.db}
+}
+
+func (s *Store) Team() ports.TeamRepository {
+	return &TeamRepository{db: s.db}
+}
+
 func (s *Store) Tx(ctx context.Context, fn func(ports.Repository) error) error {
 	return fn(s)
 }
@@ -120,6 +173,14 @@ func (r *FormRepository) GetByPublicID(ctx context.Context, publicID string) (*d
 	return nil, nil
 }
 
+func (r *FormRepository) GetBySlug(ctx context.Context, slug string) (*domain.Form, error) {
+	return nil, nil
+}
+
+func (r *FormRepository) GetByPreviousPublicID(ctx context.Context, publicID string) (*domain.Form, error) {
+	return nil, nil
+}
+
 func (r *FormRepository) GetByID(ctx context.Context, id string) (*domain.Form, error) {
 	return nil, nil
 }
@@ -128,7 +189,7 @@ func (r *FormRepository) List(ctx context.Context) ([]*domain.Form, error) {
 	return nil, nil
 }
 
-func (r *FormRepository) ListPaginated(ctx context.Context, limit, offset int) ([]*domain.Form, int, error) {
+func (r *FormRepository) ListPaginated(ctx context.Context, query domain.FormListQuery, limit, offset int) ([]*domain.Form, int, error) {
 	return nil, 0, nil
 }
 
@@ -140,8 +201,36 @@ func (r *FormRepository) IncrementSubmissionCount(ctx context.Context, formID st
 	return nil
 }
 
-func (r *FormRepository) ListByOwnerPaginated(ctx context.Context, ownerID string, limit, offset int) ([]*domain.Form, int, error) {
-	return nil, 0, nil // Postgres not implemented - using SQLite
+func (r *FormRepository) ListPurgeable(ctx context.Context, before time.Time) ([]*domain.Form, error) {
+	return nil, nil
+}
+
+func (r *FormRepository) ListDeleted(ctx context.Context, ownerID string) ([]*domain.Form, error) {
+	return nil, nil
+}
+
+func (r *FormRepository) Star(ctx context.Context, userID, formID string) error {
+	return nil
+}
+
+func (r *FormRepository) Unstar(ctx context.Context, userID, formID string) error {
+	return nil
+}
+
+func (r *FormRepository) ListStarredBy(ctx context.Context, formID string) ([]string, error) {
+	return nil, nil
+}
+
+func (r *FormRepository) SetOwner(ctx context.Context, formID, ownerID string) error {
+	return nil
+}
+
+func (r *FormRepository) SetTeam(ctx context.Context, formID, teamID string) error {
+	return nil
+}
+
+func (r *FormRepository) ListWithRetention(ctx context.Context) ([]*domain.Form, error) {
+	return nil, nil
 }
 
 // SubmissionRepository for Postgres
@@ -161,8 +250,20 @@ func (r *SubmissionRepository) GetByFormID(ctx context.Context, formID string) (
 	return nil, nil
 }
 
-func (r *SubmissionRepository) GetByFormIDPaginated(ctx context.Context, formID string, limit, offset int) ([]*domain.Submission, int, error) {
-	return nil, 0, nil
+func (r *SubmissionRepository) EachByFormID(ctx context.Context, formID string, fn func(*domain.Submission) error) error {
+	return nil
+}
+
+func (r *SubmissionRepository) GetByFormIDPaginated(ctx context.Context, formID, viewerID string, limit, offset int) ([]*domain.Submission, domain.SubmissionCounts, error) {
+	return nil, domain.SubmissionCounts{}, nil
+}
+
+func (r *SubmissionRepository) SearchByFormID(ctx context.Context, formID, viewerID string, query domain.SubmissionListQuery, limit, offset int) ([]*domain.Submission, domain.SubmissionCounts, error) {
+	return nil, domain.SubmissionCounts{}, nil
+}
+
+func (r *SubmissionRepository) UpdateData(ctx context.Context, id string, data json.RawMessage) error {
+	return nil
 }
 
 func (r *SubmissionRepository) UpdateStatus(ctx context.Context, id string, status domain.SubmissionStatus) error {
@@ -173,19 +274,79 @@ func (r *SubmissionRepository) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+func (r *SubmissionRepository) DeleteMatching(ctx context.Context, formID string, filter domain.SubmissionFilter) (int, error) {
+	return 0, nil
+}
+
+func (r *SubmissionRepository) UpdateStatusBatch(ctx context.Context, formID string, ids []string, status domain.SubmissionStatus) (int, error) {
+	return 0, nil
+}
+
+func (r *SubmissionRepository) SetSpamBatch(ctx context.Context, formID string, ids []string, spam bool) (int, error) {
+	return 0, nil
+}
+
+func (r *SubmissionRepository) SetSpamVerdict(ctx context.Context, id string, spam domain.SpamMeta) error {
+	return nil
+}
+
+func (r *SubmissionRepository) DeleteBatch(ctx context.Context, formID string, ids []string) (int, error) {
+	return 0, nil
+}
+
+func (r *SubmissionRepository) DeleteTestBefore(ctx context.Context, before time.Time) ([]domain.Tombstone, error) {
+	return nil, nil
+}
+
+func (r *SubmissionRepository) ListExpired(ctx context.Context, formID string, before time.Time, limit int) ([]*domain.Submission, error) {
+	return nil, nil
+}
+
+func (r *SubmissionRepository) Anonymize(ctx context.Context, id string, data json.RawMessage, meta domain.SubmissionMeta) error {
+	return nil
+}
+
+func (r *SubmissionRepository) RecordView(ctx context.Context, submissionID, userID string, at time.Time) error {
+	return nil
+}
+
+func (r *SubmissionRepository) ListViews(ctx context.Context, submissionID string) ([]domain.SubmissionView, error) {
+	return nil, nil
+}
+
+func (r *SubmissionRepository) Link(ctx context.Context, link *domain.SubmissionLink) error {
+	return nil
+}
+
+func (r *SubmissionRepository) Unlink(ctx context.Context, submissionID, linkedID string) (bool, error) {
+	return false, nil
+}
+
+func (r *SubmissionRepository) ListLinks(ctx context.Context, submissionID string) ([]domain.SubmissionLink, error) {
+	return nil, nil
+}
+
 // StatsRepository for Postgres
 type StatsRepository struct {
 	db *sql.DB
 }
 
-func (r *StatsRepository) GetDashboardStats(ctx context.Context) (*domain.DashboardStats, error) {
+func (r *StatsRepository) GetDashboardStats(ctx context.Context, loc *time.Location) (*domain.DashboardStats, error) {
 	return &domain.DashboardStats{}, nil
 }
 
-func (r *StatsRepository) GetFormStats(ctx context.Context, formID string) (*domain.FormStats, error) {
+func (r *StatsRepository) GetFormStats(ctx context.Context, formID string, loc *time.Location) (*domain.FormStats, error) {
 	return &domain.FormStats{FormID: formID}, nil
 }
 
+func (r *StatsRepository) GetFormDailyStats(ctx context.Context, formID string, from, to time.Time, loc *time.Location) ([]domain.DailyFormStats, error) {
+	return nil, nil
+}
+
+func (r *StatsRepository) GetFormActivity(ctx context.Context, formID string, since time.Time) (*domain.FormActivity, error) {
+	return &domain.FormActivity{}, nil
+}
+
 // UserRepository for Postgres
 type UserRepository struct {
 	db *sql.DB
@@ -243,3 +404,300 @@ func (r *PasswordResetRepository) MarkAsUsed(ctx context.Context, tokenID string
 func (r *PasswordResetRepository) DeleteExpired(ctx context.Context) error {
 	return nil
 }
+
+// AlertRepository for Postgres
+type AlertRepository struct {
+	db *sql.DB
+}
+
+func (r *AlertRepository) Create(ctx context.Context, rule *domain.AlertRule) error {
+	return nil
+}
+
+func (r *AlertRepository) GetByID(ctx context.Context, id string) (*domain.AlertRule, error) {
+	return nil, nil
+}
+
+func (r *AlertRepository) ListByFormID(ctx context.Context, formID string) ([]*domain.AlertRule, error) {
+	return nil, nil
+}
+
+func (r *AlertRepository) ListEnabled(ctx context.Context) ([]*domain.AlertRule, error) {
+	return nil, nil
+}
+
+func (r *AlertRepository) MarkTriggered(ctx context.Context, id string, at time.Time) error {
+	return nil
+}
+
+func (r *AlertRepository) Delete(ctx context.Context, id string) error {
+	return nil
+}
+
+// FormTokenRepository for Postgres
+type FormTokenRepository struct {
+	db *sql.DB
+}
+
+func (r *FormTokenRepository) Create(ctx context.Context, token *domain.FormToken) error {
+	return nil
+}
+
+func (r *FormTokenRepository) GetByHash(ctx context.Context, hash string) (*domain.FormToken, error) {
+	return nil, nil
+}
+
+func (r *FormTokenRepository) ListByFormID(ctx context.Context, formID string) ([]*domain.FormToken, error) {
+	return nil, nil
+}
+
+func (r *FormTokenRepository) MarkUsed(ctx context.Context, id string, at time.Time) error {
+	return nil
+}
+
+func (r *FormTokenRepository) Delete(ctx context.Context, id string) error {
+	return nil
+}
+
+// AuditRepository for Postgres
+type AuditRepository struct {
+	db *sql.DB
+}
+
+func (r *AuditRepository) Create(ctx context.Context, entry *domain.AuditEntry) error {
+	return nil
+}
+
+func (r *AuditRepository) ListByFormID(ctx context.Context, formID string, limit int) ([]*domain.AuditEntry, error) {
+	return nil, nil
+}
+
+// TaskRepository for Postgres
+type TaskRepository struct {
+	db *sql.DB
+}
+
+func (r *TaskRepository) Create(ctx context.Context, task *domain.Task) error {
+	return nil
+}
+
+func (r *TaskRepository) Update(ctx context.Context, task *domain.Task) error {
+	return nil
+}
+
+func (r *TaskRepository) GetByID(ctx context.Context, id string) (*domain.Task, error) {
+	return nil, nil
+}
+
+func (r *TaskRepository) FailUnfinished(ctx context.Context, reason string, at time.Time) (int, error) {
+	return 0, nil
+}
+
+func (r *TaskRepository) ListWithFiles(ctx context.Context) ([]*domain.Task, error) {
+	return nil, nil
+}
+
+// CommentRepository for Postgres
+type CommentRepository struct {
+	db *sql.DB
+}
+
+func (r *CommentRepository) Create(ctx context.Context, comment *domain.Comment) error {
+	return nil
+}
+
+func (r *CommentRepository) Update(ctx context.Context, comment *domain.Comment) error {
+	return nil
+}
+
+func (r *CommentRepository) GetByID(ctx context.Context, id string) (*domain.Comment, error) {
+	return nil, nil
+}
+
+func (r *CommentRepository) ListBySubmissionID(ctx context.Context, submissionID string) ([]*domain.Comment, error) {
+	return nil, nil
+}
+
+func (r *CommentRepository) Delete(ctx context.Context, id string) error {
+	return nil
+}
+
+// NotificationRepository for Postgres
+type NotificationRepository struct {
+	db *sql.DB
+}
+
+func (r *NotificationRepository) Create(ctx context.Context, notification *domain.Notification) error {
+	return nil
+}
+
+func (r *NotificationRepository) ListByUserID(ctx context.Context, userID string, unreadOnly bool, limit, offset int) ([]*domain.Notification, error) {
+	return nil, nil
+}
+
+func (r *NotificationRepository) Count(ctx context.Context, userID string) (int, error) {
+	return 0, nil
+}
+
+func (r *NotificationRepository) CountUnread(ctx context.Context, userID string) (int, error) {
+	return 0, nil
+}
+
+func (r *NotificationRepository) HasUnread(ctx context.Context, userID string, notificationType domain.NotificationType, formID string) (bool, error) {
+	return false, nil
+}
+
+func (r *NotificationRepository) MarkRead(ctx context.Context, userID, id string, at time.Time) (bool, error) {
+	return false, nil
+}
+
+func (r *NotificationRepository) MarkAllRead(ctx context.Context, userID string, at time.Time) (int, error) {
+	return 0, nil
+}
+
+// LockRepository for Postgres. A locks table rather than advisory locks, which
+// belong to one connection of the pool and are lost when it's recycled.
+type LockRepository struct {
+	db *sql.DB
+}
+
+func (r *LockRepository) Acquire(ctx context.Context, name, owner string, until time.Time) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+		INSERT INTO locks (name, owner, expires_at) VALUES ($1, $2, $3)
+		ON CONFLICT (name) DO UPDATE SET owner = EXCLUDED.owner, expires_at = EXCLUDED.expires_at
+		WHERE locks.owner = EXCLUDED.owner OR locks.expires_at <= NOW()
+	`, name, owner, until)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+func (r *LockRepository) Release(ctx context.Context, name, owner string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM locks WHERE name = $1 AND owner = $2`, name, owner)
+	return err
+}
+
+// WebhookDeliveryRepository for Postgres
+type WebhookDeliveryRepository struct {
+	db *sql.DB
+}
+
+func (r *WebhookDeliveryRepository) Create(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	return nil
+}
+
+func (r *WebhookDeliveryRepository) Update(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	return nil
+}
+
+func (r *WebhookDeliveryRepository) GetByID(ctx context.Context, id string) (*domain.WebhookDelivery, error) {
+	return nil, nil
+}
+
+func (r *WebhookDeliveryRepository) ListByFormID(ctx context.Context, formID string, limit, offset int) ([]*domain.WebhookDelivery, int, error) {
+	return nil, 0, nil
+}
+
+func (r *WebhookDeliveryRepository) DeleteBefore(ctx context.Context, before time.Time) (int, error) {
+	return 0, nil
+}
+
+// EmailQueueRepository for Postgres
+type EmailQueueRepository struct {
+	db *sql.DB
+}
+
+func (r *EmailQueueRepository) Create(ctx context.Context, email *domain.QueuedEmail) error {
+	return nil
+}
+
+func (r *EmailQueueRepository) Update(ctx context.Context, email *domain.QueuedEmail) error {
+	return nil
+}
+
+func (r *EmailQueueRepository) ListDue(ctx context.Context, now time.Time, limit int) ([]*domain.QueuedEmail, error) {
+	return nil, nil
+}
+
+func (r *EmailQueueRepository) List(ctx context.Context, status domain.EmailStatus, limit, offset int) ([]*domain.QueuedEmail, int, error) {
+	return nil, 0, nil
+}
+
+func (r *EmailQueueRepository) CountByStatus(ctx context.Context) (map[domain.EmailStatus]int, error) {
+	return nil, nil
+}
+
+func (r *EmailQueueRepository) DeleteFinishedBefore(ctx context.Context, before time.Time) (int, error) {
+	return 0, nil
+}
+
+// SpamFingerprintRepository for Postgres
+type SpamFingerprintRepository struct {
+	db *sql.DB
+}
+
+func (r *SpamFingerprintRepository) Confirm(ctx context.Context, fp *domain.SpamFingerprint) error {
+	return nil
+}
+
+func (r *SpamFingerprintRepository) Get(ctx context.Context, hash string) (*domain.SpamFingerprint, error) {
+	return nil, nil
+}
+
+func (r *SpamFingerprintRepository) Hit(ctx context.Context, hash string, seenAt, expiresAt time.Time) error {
+	return nil
+}
+
+func (r *SpamFingerprintRepository) Delete(ctx context.Context, hash string) (bool, error) {
+	return false, nil
+}
+
+func (r *SpamFingerprintRepository) List(ctx context.Context, limit, offset int) ([]*domain.SpamFingerprint, int, error) {
+	return nil, 0, nil
+}
+
+func (r *SpamFingerprintRepository) DeleteExpired(ctx context.Context, before time.Time) (int, error) {
+	return 0, nil
+}
+
+// TeamRepository for Postgres
+type TeamRepository struct {
+	db *sql.DB
+}
+
+func (r *TeamRepository) Create(ctx context.Context, team *domain.Team) error {
+	return nil
+}
+
+func (r *TeamRepository) Update(ctx context.Context, team *domain.Team) error {
+	return nil
+}
+
+func (r *TeamRepository) GetByID(ctx context.Context, id string) (*domain.Team, error) {
+	return nil, nil
+}
+
+func (r *TeamRepository) List(ctx context.Context, userID string) ([]*domain.Team, error) {
+	return nil, nil
+}
+
+func (r *TeamRepository) Delete(ctx context.Context, id string) error {
+	return nil
+}
+
+func (r *TeamRepository) SetMember(ctx context.Context, member *domain.TeamMember) error {
+	return nil
+}
+
+func (r *TeamRepository) RemoveMember(ctx context.Context, teamID, userID string) (bool, error) {
+	return false, nil
+}
+
+func (r *TeamRepository) Roles(ctx context.Context, userID string) (map[string]domain.TeamRole, error) {
+	return nil, nil
+}
@@ -0,0 +1,86 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"headless_form/internal/core/domain"
+)
+
+// AlertRepository implements alert rule storage in SQLite
+type AlertRepository struct {
+	db dbtx
+}
+
+const alertColumns = `id, form_id, type, threshold, window_hours, enabled, last_triggered_at, created_at`
+
+func (r *AlertRepository) Create(ctx context.Context, a *domain.AlertRule) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO alert_rules (id, form_id, type, threshold, window_hours, enabled, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, a.ID, a.FormID, a.Type, a.Threshold, a.WindowHours, a.Enabled, a.CreatedAt)
+	return err
+}
+
+func (r *AlertRepository) GetByID(ctx context.Context, id string) (*domain.AlertRule, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+alertColumns+` FROM alert_rules WHERE id = ?`, id)
+	a, err := scanAlertRule(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("scan alert rule: %w", err)
+	}
+	return a, nil
+}
+
+func (r *AlertRepository) ListByFormID(ctx context.Context, formID string) ([]*domain.AlertRule, error) {
+	return r.list(ctx, `SELECT `+alertColumns+` FROM alert_rules WHERE form_id = ? ORDER BY created_at`, formID)
+}
+
+func (r *AlertRepository) ListEnabled(ctx context.Context) ([]*domain.AlertRule, error) {
+	return r.list(ctx, `SELECT `+alertColumns+` FROM alert_rules WHERE enabled = 1`)
+}
+
+func (r *AlertRepository) MarkTriggered(ctx context.Context, id string, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE alert_rules SET last_triggered_at = ? WHERE id = ?`, at, id)
+	return err
+}
+
+func (r *AlertRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM alert_rules WHERE id = ?`, id)
+	return err
+}
+
+func (r *AlertRepository) list(ctx context.Context, query string, args ...interface{}) ([]*domain.AlertRule, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var rules []*domain.AlertRule
+	for rows.Next() {
+		a, err := scanAlertRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, a)
+	}
+	return rules, rows.Err()
+}
+
+// scanAlertRule scans a row selected with alertColumns
+func scanAlertRule(row interface{ Scan(...interface{}) error }) (*domain.AlertRule, error) {
+	var a domain.AlertRule
+	var lastTriggered sql.NullTime
+	if err := row.Scan(&a.ID, &a.FormID, &a.Type, &a.Threshold, &a.WindowHours, &a.Enabled, &lastTriggered, &a.CreatedAt); err != nil {
+		return nil, err
+	}
+	if lastTriggered.Valid {
+		a.LastTriggeredAt = &lastTriggered.Time
+	}
+	return &a, nil
+}
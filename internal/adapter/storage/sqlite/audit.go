@@ -0,0 +1,50 @@
+package sqlite
+
+import (
+	"context"
+	"encoding/json"
+
+	"headless_form/internal/core/domain"
+)
+
+// AuditRepository implements audit log storage in SQLite
+type AuditRepository struct {
+	db dbtx
+}
+
+func (r *AuditRepository) Create(ctx context.Context, e *domain.AuditEntry) error {
+	details, err := json.Marshal(e.Details)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO audit_log (id, form_id, user_id, action, details, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, e.ID, e.FormID, e.UserID, e.Action, string(details), e.CreatedAt)
+	return err
+}
+
+func (r *AuditRepository) ListByFormID(ctx context.Context, formID string, limit int) ([]*domain.AuditEntry, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, form_id, COALESCE(user_id, ''), action, COALESCE(details, ''), created_at
+		FROM audit_log WHERE form_id = ? ORDER BY created_at DESC LIMIT ?
+	`, formID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var entries []*domain.AuditEntry
+	for rows.Next() {
+		var e domain.AuditEntry
+		var details string
+		if err := rows.Scan(&e.ID, &e.FormID, &e.UserID, &e.Action, &details, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		if details != "" {
+			_ = json.Unmarshal([]byte(details), &e.Details)
+		}
+		entries = append(entries, &e)
+	}
+	return entries, rows.Err()
+}
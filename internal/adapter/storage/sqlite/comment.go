@@ -0,0 +1,97 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"headless_form/internal/core/domain"
+)
+
+// CommentRepository implements submission comment storage in SQLite
+type CommentRepository struct {
+	db dbtx
+}
+
+// commentColumns selects a comment joined with its author (users u)
+const commentColumns = `c.id, c.submission_id, c.parent_id, c.user_id, COALESCE(u.name, ''), c.body, c.mentions, c.created_at, c.updated_at`
+
+const commentFrom = ` FROM submission_comments c LEFT JOIN users u ON u.id = c.user_id`
+
+func (r *CommentRepository) Create(ctx context.Context, c *domain.Comment) error {
+	mentions, _ := json.Marshal(c.Mentions)
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO submission_comments (id, submission_id, parent_id, user_id, body, mentions, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, c.ID, c.SubmissionID, sql.NullString{String: c.ParentID, Valid: c.ParentID != ""}, c.UserID, c.Body, string(mentions), c.CreatedAt)
+	return err
+}
+
+func (r *CommentRepository) Update(ctx context.Context, c *domain.Comment) error {
+	mentions, _ := json.Marshal(c.Mentions)
+	_, err := r.db.ExecContext(ctx, `UPDATE submission_comments SET body = ?, mentions = ?, updated_at = ? WHERE id = ?`,
+		c.Body, string(mentions), c.UpdatedAt, c.ID)
+	return err
+}
+
+func (r *CommentRepository) GetByID(ctx context.Context, id string) (*domain.Comment, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+commentColumns+commentFrom+` WHERE c.id = ?`, id)
+	c, err := scanComment(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("scan comment: %w", err)
+	}
+	return c, nil
+}
+
+func (r *CommentRepository) ListBySubmissionID(ctx context.Context, submissionID string) ([]*domain.Comment, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+commentColumns+commentFrom+` WHERE c.submission_id = ? ORDER BY c.created_at, c.id`, submissionID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var comments []*domain.Comment
+	for rows.Next() {
+		c, err := scanComment(rows)
+		if err != nil {
+			return nil, err
+		}
+		comments = append(comments, c)
+	}
+	return comments, rows.Err()
+}
+
+func (r *CommentRepository) Delete(ctx context.Context, id string) error {
+	// Replies go with the comment they answer
+	_, err := r.db.ExecContext(ctx, `
+		WITH RECURSIVE thread(id) AS (
+			SELECT id FROM submission_comments WHERE id = ?
+			UNION ALL
+			SELECT c.id FROM submission_comments c JOIN thread t ON c.parent_id = t.id
+		)
+		DELETE FROM submission_comments WHERE id IN (SELECT id FROM thread)`, id)
+	return err
+}
+
+// scanComment scans a row selected with commentColumns
+func scanComment(row interface{ Scan(...interface{}) error }) (*domain.Comment, error) {
+	var c domain.Comment
+	var parentID, mentions sql.NullString
+	var updatedAt sql.NullTime
+	if err := row.Scan(&c.ID, &c.SubmissionID, &parentID, &c.UserID, &c.AuthorName, &c.Body, &mentions, &c.CreatedAt, &updatedAt); err != nil {
+		return nil, err
+	}
+	c.ParentID = parentID.String
+	c.Mentions = []string{}
+	if mentions.String != "" {
+		_ = json.Unmarshal([]byte(mentions.String), &c.Mentions)
+	}
+	if updatedAt.Valid {
+		c.UpdatedAt = &updatedAt.Time
+	}
+	return &c, nil
+}
@@ -0,0 +1,120 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"headless_form/internal/core/domain"
+)
+
+// EmailQueueRepository implements the outgoing email queue in SQLite.
+// Recipients are stored as a JSON array.
+type EmailQueueRepository struct {
+	db dbtx
+}
+
+const emailQueueColumns = `id, recipients, subject, message, status, attempts, last_error, next_attempt_at, created_at, updated_at, sent_at`
+
+func (r *EmailQueueRepository) Create(ctx context.Context, e *domain.QueuedEmail) error {
+	to, err := json.Marshal(e.To)
+	if err != nil {
+		return fmt.Errorf("marshal recipients: %w", err)
+	}
+	_, err = r.db.ExecContext(ctx, `INSERT INTO email_queue (`+emailQueueColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		e.ID, to, e.Subject, e.Message, e.Status, e.Attempts, e.LastError, e.NextAttemptAt.UTC(), e.CreatedAt.UTC(), e.UpdatedAt.UTC(), e.SentAt)
+	return err
+}
+
+func (r *EmailQueueRepository) Update(ctx context.Context, e *domain.QueuedEmail) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE email_queue SET status = ?, attempts = ?, last_error = ?, next_attempt_at = ?, updated_at = ?, sent_at = ? WHERE id = ?`,
+		e.Status, e.Attempts, e.LastError, e.NextAttemptAt.UTC(), e.UpdatedAt.UTC(), e.SentAt, e.ID)
+	return err
+}
+
+func (r *EmailQueueRepository) ListDue(ctx context.Context, now time.Time, limit int) ([]*domain.QueuedEmail, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+emailQueueColumns+` FROM email_queue
+		WHERE status = ? AND substr(next_attempt_at, 1, 19) <= ? ORDER BY created_at, id LIMIT ?`,
+		domain.EmailPending, now.UTC().Format("2006-01-02 15:04:05"), limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanQueuedEmails(rows, true)
+}
+
+func (r *EmailQueueRepository) List(ctx context.Context, status domain.EmailStatus, limit, offset int) ([]*domain.QueuedEmail, int, error) {
+	where, args := "", []interface{}{}
+	if status != "" {
+		where, args = " WHERE status = ?", append(args, status)
+	}
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM email_queue`+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	rows, err := r.db.QueryContext(ctx, `SELECT `+emailQueueColumns+` FROM email_queue`+where+`
+		ORDER BY created_at DESC, id DESC LIMIT ? OFFSET ?`, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	emails, err := scanQueuedEmails(rows, false)
+	return emails, total, err
+}
+
+func (r *EmailQueueRepository) CountByStatus(ctx context.Context) (map[domain.EmailStatus]int, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT status, COUNT(*) FROM email_queue GROUP BY status`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	counts := make(map[domain.EmailStatus]int)
+	for rows.Next() {
+		var status domain.EmailStatus
+		var n int
+		if err := rows.Scan(&status, &n); err != nil {
+			return nil, err
+		}
+		counts[status] = n
+	}
+	return counts, rows.Err()
+}
+
+func (r *EmailQueueRepository) DeleteFinishedBefore(ctx context.Context, before time.Time) (int, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM email_queue WHERE status IN (?, ?) AND substr(updated_at, 1, 19) < ?`,
+		domain.EmailSent, domain.EmailDead, before.UTC().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+// scanQueuedEmails reads and closes rows, keeping the messages only when withMessage is set
+func scanQueuedEmails(rows *sql.Rows, withMessage bool) ([]*domain.QueuedEmail, error) {
+	defer func() { _ = rows.Close() }()
+
+	var emails []*domain.QueuedEmail
+	for rows.Next() {
+		var e domain.QueuedEmail
+		var to, message []byte
+		var lastError sql.NullString
+		var sentAt sql.NullTime
+		if err := rows.Scan(&e.ID, &to, &e.Subject, &message, &e.Status, &e.Attempts, &lastError, &e.NextAttemptAt, &e.CreatedAt, &e.UpdatedAt, &sentAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(to, &e.To); err != nil {
+			return nil, fmt.Errorf("unmarshal recipients: %w", err)
+		}
+		if withMessage {
+			e.Message = message
+		}
+		e.LastError = lastError.String
+		if sentAt.Valid {
+			e.SentAt = &sentAt.Time
+		}
+		emails = append(emails, &e)
+	}
+	return emails, rows.Err()
+}
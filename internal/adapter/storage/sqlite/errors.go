@@ -0,0 +1,40 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+
+	"headless_form/internal/core/domain"
+
+	sqlite "modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// unavailable wraps err with domain.ErrStoreUnavailable when retrying later may succeed:
+// the database is locked, out of space or can't be read or written, or the statement
+// ran out of time. Other errors, such as constraint violations, are returned as they are.
+func unavailable(err error) error {
+	if err == nil || !transient(err) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", domain.ErrStoreUnavailable, err)
+}
+
+func transient(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, sql.ErrConnDone) || errors.Is(err, driver.ErrBadConn) {
+		return true
+	}
+	var serr *sqlite.Error
+	if !errors.As(err, &serr) {
+		return false
+	}
+	switch serr.Code() & 0xff { // The primary result code, without the extended part
+	case sqlite3.SQLITE_BUSY, sqlite3.SQLITE_LOCKED, sqlite3.SQLITE_IOERR, sqlite3.SQLITE_FULL,
+		sqlite3.SQLITE_CANTOPEN, sqlite3.SQLITE_PROTOCOL, sqlite3.SQLITE_READONLY, sqlite3.SQLITE_NOMEM:
+		return true
+	}
+	return false
+}
@@ -6,10 +6,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"headless_form/internal/core/domain"
+	"headless_form/internal/core/ports"
+	"strings"
+	"time"
 )
 
 type FormRepository struct {
-	db *sql.DB
+	db      dbtx
+	secrets ports.SecretCodec
 }
 
 func (r *FormRepository) Create(ctx context.Context, f *domain.Form) error {
@@ -19,35 +23,48 @@ func (r *FormRepository) Create(ctx context.Context, f *domain.Form) error {
 
 	emailsJson, _ := json.Marshal(f.NotifyEmails)
 	originsJson, _ := json.Marshal(f.AllowedOrigins)
+	sealed, err := sealForm(r.secrets, f)
+	if err != nil {
+		return err
+	}
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err = r.db.ExecContext(ctx, query,
 		f.ID, f.PublicID, f.Name, string(emailsJson), string(originsJson),
 		f.RedirectURL, f.CreatedAt,
 	)
 
 	// Try to set new columns - ignore errors if they don't exist
 	if err == nil {
-		_, _ = r.db.ExecContext(ctx, `UPDATE forms SET status = ?, submission_count = ?, updated_at = ?, webhook_url = ?, webhook_secret = ?, access_mode = ?, submission_key = ?, owner_id = ? WHERE id = ?`,
-			f.Status, f.SubmissionCount, f.UpdatedAt, f.WebhookURL, f.WebhookSecret, f.AccessMode, f.SubmissionKey, f.OwnerID, f.ID)
+		_, _ = r.db.ExecContext(ctx, `UPDATE forms SET status = ?, submission_count = ?, updated_at = ?, webhook_url = ?, webhook_secret = ?, access_mode = ?, submission_key = ?, owner_id = ?, webhook_transform = ?, webhook_transform_enabled = ?, webhook_signature = ?, webhook_sign_timestamp = ?, webhook_include_meta = ?, attach_pdf = ?, public_stats = ?, anonymous = ?, consent = ?, frame_ancestors = ?, fields = ?, timezone = ?, uploads = ?, deleted_at = ?, purge_at = ?, archive_path = ?, previous_public_id = ?, previous_public_id_until = ?, submit_response = ?, spam = ?, captcha = ?, normalize = ?, notify_rules = ?, email_template = ?, filters = ?, auto_reply = ?, error_page = ?, channels = ?, sample = ?, retention_days = ?, retention_action = ?, email_field_limit = ?, link_field = ?, hidden_fields = ?, webhook_rate = ?, opens_at = ?, closes_at = ?, team_id = ? WHERE id = ?`,
+			f.Status, f.SubmissionCount, f.UpdatedAt, f.WebhookURL, sealed.webhookSecret, f.AccessMode, sealed.submissionKey, f.OwnerID, f.WebhookTransform, f.WebhookTransformEnabled, f.WebhookSignatureAlgorithm, f.WebhookSignTimestamp, f.WebhookIncludeMeta, f.AttachPDF, f.PublicStats, f.Anonymous, consentJSON(f.Consent), frameAncestorsJSON(f.FrameAncestors), fieldsJSON(f.Fields), f.Timezone, uploadsJSON(f.Uploads), f.DeletedAt, f.PurgeAt, f.ArchivePath, f.PreviousPublicID, f.PreviousPublicIDUntil, submitResponseJSON(f.SubmitResponse), spamJSON(f.Spam), sealed.captcha, normalizeJSON(f.Normalize), notifyRulesJSON(f.NotifyRules), emailTemplateJSON(f.EmailTemplate), filtersJSON(f.Filters), autoReplyJSON(f.AutoReply), errorPageJSON(f.ErrorPage), sealed.channels, f.Sample, f.RetentionDays, f.RetentionAction, f.EmailFieldLimit, f.LinkField, hiddenFieldsJSON(f.HiddenFields), webhookRateJSON(f.WebhookRate), f.OpensAt, f.ClosesAt, sql.NullString{String: f.TeamID, Valid: f.TeamID != ""}, f.ID)
+		if err = r.setSlug(ctx, f); err != nil {
+			// Don't leave a half-created form behind when the slug is taken
+			_, _ = r.db.ExecContext(ctx, `DELETE FROM forms WHERE id = ?`, f.ID)
+		}
 	}
 
 	return err
 }
 
 func (r *FormRepository) Update(ctx context.Context, f *domain.Form) error {
-	query := `UPDATE forms SET name = ?, notify_emails = ?, allowed_origins = ?, redirect_url = ? WHERE id = ?`
+	query := `UPDATE forms SET public_id = ?, name = ?, notify_emails = ?, allowed_origins = ?, redirect_url = ? WHERE id = ?`
 
 	emailsJson, _ := json.Marshal(f.NotifyEmails)
 	originsJson, _ := json.Marshal(f.AllowedOrigins)
+	sealed, err := sealForm(r.secrets, f)
+	if err != nil {
+		return err
+	}
 
-	_, err := r.db.ExecContext(ctx, query,
-		f.Name, string(emailsJson), string(originsJson), f.RedirectURL, f.ID,
+	_, err = r.db.ExecContext(ctx, query,
+		f.PublicID, f.Name, string(emailsJson), string(originsJson), f.RedirectURL, f.ID,
 	)
 
 	// Try to set new columns - ignore errors if they don't exist
 	if err == nil {
-		_, _ = r.db.ExecContext(ctx, `UPDATE forms SET status = ?, updated_at = ?, webhook_url = ?, webhook_secret = ?, access_mode = ?, submission_key = ? WHERE id = ?`,
-			f.Status, f.UpdatedAt, f.WebhookURL, f.WebhookSecret, f.AccessMode, f.SubmissionKey, f.ID)
+		_, _ = r.db.ExecContext(ctx, `UPDATE forms SET status = ?, updated_at = ?, webhook_url = ?, webhook_secret = ?, access_mode = ?, submission_key = ?, webhook_transform = ?, webhook_transform_enabled = ?, webhook_signature = ?, webhook_sign_timestamp = ?, webhook_include_meta = ?, attach_pdf = ?, public_stats = ?, anonymous = ?, consent = ?, frame_ancestors = ?, fields = ?, timezone = ?, uploads = ?, deleted_at = ?, purge_at = ?, archive_path = ?, previous_public_id = ?, previous_public_id_until = ?, submit_response = ?, spam = ?, captcha = ?, normalize = ?, notify_rules = ?, email_template = ?, filters = ?, auto_reply = ?, error_page = ?, channels = ?, sample = ?, retention_days = ?, retention_action = ?, email_field_limit = ?, link_field = ?, hidden_fields = ?, webhook_rate = ?, opens_at = ?, closes_at = ? WHERE id = ?`,
+			f.Status, f.UpdatedAt, f.WebhookURL, sealed.webhookSecret, f.AccessMode, sealed.submissionKey, f.WebhookTransform, f.WebhookTransformEnabled, f.WebhookSignatureAlgorithm, f.WebhookSignTimestamp, f.WebhookIncludeMeta, f.AttachPDF, f.PublicStats, f.Anonymous, consentJSON(f.Consent), frameAncestorsJSON(f.FrameAncestors), fieldsJSON(f.Fields), f.Timezone, uploadsJSON(f.Uploads), f.DeletedAt, f.PurgeAt, f.ArchivePath, f.PreviousPublicID, f.PreviousPublicIDUntil, submitResponseJSON(f.SubmitResponse), spamJSON(f.Spam), sealed.captcha, normalizeJSON(f.Normalize), notifyRulesJSON(f.NotifyRules), emailTemplateJSON(f.EmailTemplate), filtersJSON(f.Filters), autoReplyJSON(f.AutoReply), errorPageJSON(f.ErrorPage), sealed.channels, f.Sample, f.RetentionDays, f.RetentionAction, f.EmailFieldLimit, f.LinkField, hiddenFieldsJSON(f.HiddenFields), webhookRateJSON(f.WebhookRate), f.OpensAt, f.ClosesAt, f.ID)
+		err = r.setSlug(ctx, f)
 	}
 
 	return err
@@ -57,6 +74,41 @@ func (r *FormRepository) GetByPublicID(ctx context.Context, publicID string) (*d
 	return r.getByField(ctx, "public_id", publicID)
 }
 
+// setSlug is kept out of the statements above (whose errors are ignored) so a
+// slug already taken by another form is reported
+func (r *FormRepository) setSlug(ctx context.Context, f *domain.Form) error {
+	var slug interface{}
+	if f.Slug != "" {
+		slug = f.Slug
+	}
+	_, err := r.db.ExecContext(ctx, `UPDATE forms SET slug = ? WHERE id = ?`, slug, f.ID)
+	return err
+}
+
+func (r *FormRepository) GetBySlug(ctx context.Context, slug string) (*domain.Form, error) {
+	var id string
+	err := r.db.QueryRowContext(ctx, `SELECT id FROM forms WHERE slug = ?`, slug).Scan(&id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.getByField(ctx, "id", id)
+}
+
+func (r *FormRepository) GetByPreviousPublicID(ctx context.Context, publicID string) (*domain.Form, error) {
+	var id string
+	err := r.db.QueryRowContext(ctx, `SELECT id FROM forms WHERE previous_public_id = ?`, publicID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.getByField(ctx, "id", id)
+}
+
 func (r *FormRepository) GetByID(ctx context.Context, id string) (*domain.Form, error) {
 	return r.getByField(ctx, "id", id)
 }
@@ -88,23 +140,154 @@ func (r *FormRepository) getByField(ctx context.Context, field, value string) (*
 	// Try to read new columns if they exist
 	var status sql.NullString
 	var count int
-	var webhookURL, webhookSecret, accessMode, submissionKey, ownerID sql.NullString
+	var webhookURL, webhookSecret, accessMode, submissionKey, ownerID, transform, signature, consent, frameAncestors, fields, timezone, uploads, archivePath, slug, previousPublicID, submitResponse, spamConfig, captcha, normalize, notifyRules, emailTemplate, filters, autoReply, errorPage, channels, retentionAction, linkField, hiddenFields, webhookRate, teamID sql.NullString
+	var retentionDays, emailFieldLimit sql.NullInt64
+	var transformEnabled, signTimestamp, includeMeta, attachPDF, publicStats, anonymous, sample sql.NullBool
+	var deletedAt, purgeAt, updatedAt, lastSubmissionAt, previousPublicIDUntil, opensAt, closesAt sql.NullTime
 	// G201: field is internal constant, not user input
-	extQuery := fmt.Sprintf(`SELECT status, submission_count, webhook_url, webhook_secret, access_mode, submission_key, owner_id FROM forms WHERE %s = ?`, field) // #nosec G201
-	if err := r.db.QueryRowContext(ctx, extQuery, value).Scan(&status, &count, &webhookURL, &webhookSecret, &accessMode, &submissionKey, &ownerID); err == nil {
+	extQuery := fmt.Sprintf(`SELECT status, submission_count, webhook_url, webhook_secret, access_mode, submission_key, owner_id, webhook_transform, webhook_transform_enabled, webhook_signature, webhook_sign_timestamp, webhook_include_meta, attach_pdf, public_stats, anonymous, consent, frame_ancestors, fields, timezone, uploads, deleted_at, purge_at, archive_path, slug, updated_at, last_submission_at, previous_public_id, previous_public_id_until, submit_response, spam, captcha, normalize, notify_rules, email_template, filters, auto_reply, error_page, channels, sample, retention_days, retention_action, email_field_limit, link_field, hidden_fields, webhook_rate, opens_at, closes_at, team_id FROM forms WHERE %s = ?`, field) // #nosec G201
+	if err := r.db.QueryRowContext(ctx, extQuery, value).Scan(&status, &count, &webhookURL, &webhookSecret, &accessMode, &submissionKey, &ownerID, &transform, &transformEnabled, &signature, &signTimestamp, &includeMeta, &attachPDF, &publicStats, &anonymous, &consent, &frameAncestors, &fields, &timezone, &uploads, &deletedAt, &purgeAt, &archivePath, &slug, &updatedAt, &lastSubmissionAt, &previousPublicID, &previousPublicIDUntil, &submitResponse, &spamConfig, &captcha, &normalize, &notifyRules, &emailTemplate, &filters, &autoReply, &errorPage, &channels, &sample, &retentionDays, &retentionAction, &emailFieldLimit, &linkField, &hiddenFields, &webhookRate, &opensAt, &closesAt, &teamID); err == nil {
 		if status.Valid && status.String != "" {
 			f.Status = domain.FormStatus(status.String)
 		}
 		f.SubmissionCount = count
 		f.WebhookURL = webhookURL.String
-		f.WebhookSecret = webhookSecret.String
+		// References are kept: the webhook service resolves them when signing
+		if f.WebhookSecret, err = unsealSecret(r.secrets, webhookSecret.String); err != nil {
+			return nil, fmt.Errorf("webhook_secret: %w", err)
+		}
 		if accessMode.Valid && accessMode.String != "" {
 			f.AccessMode = accessMode.String
 		} else {
 			f.AccessMode = "public"
 		}
-		f.SubmissionKey = submissionKey.String
+		if f.SubmissionKey, err = unsealSecret(r.secrets, submissionKey.String); err != nil {
+			return nil, fmt.Errorf("submission_key: %w", err)
+		}
 		f.OwnerID = ownerID.String
+		f.TeamID = teamID.String
+		f.WebhookTransform = transform.String
+		f.WebhookTransformEnabled = transformEnabled.Bool
+		f.WebhookSignatureAlgorithm = domain.SignatureSHA256
+		if signature.Valid && signature.String != "" {
+			f.WebhookSignatureAlgorithm = signature.String
+		}
+		f.WebhookSignTimestamp = signTimestamp.Bool
+		f.WebhookIncludeMeta = includeMeta.Bool
+		f.AttachPDF = attachPDF.Bool
+		f.PublicStats = publicStats.Bool
+		f.Anonymous = anonymous.Bool
+		f.Sample = sample.Bool
+		f.RetentionDays = int(retentionDays.Int64)
+		f.EmailFieldLimit = int(emailFieldLimit.Int64)
+		f.LinkField = linkField.String
+		f.OpensAt, f.ClosesAt = utcTime(opensAt), utcTime(closesAt)
+		f.RetentionAction = retentionAction.String
+		if consent.Valid && consent.String != "" {
+			var c domain.ConsentConfig
+			if json.Unmarshal([]byte(consent.String), &c) == nil {
+				f.Consent = &c
+			}
+		}
+		if frameAncestors.Valid && frameAncestors.String != "" {
+			_ = json.Unmarshal([]byte(frameAncestors.String), &f.FrameAncestors)
+		}
+		if fields.Valid && fields.String != "" {
+			_ = json.Unmarshal([]byte(fields.String), &f.Fields)
+		}
+		f.Timezone = timezone.String
+		if uploads.Valid && uploads.String != "" {
+			var u domain.UploadConfig
+			if json.Unmarshal([]byte(uploads.String), &u) == nil {
+				f.Uploads = &u
+			}
+		}
+		if submitResponse.Valid && submitResponse.String != "" {
+			var c domain.SubmitResponseConfig
+			if json.Unmarshal([]byte(submitResponse.String), &c) == nil {
+				f.SubmitResponse = &c
+			}
+		}
+		if spamConfig.Valid && spamConfig.String != "" {
+			var c domain.SpamConfig
+			if json.Unmarshal([]byte(spamConfig.String), &c) == nil {
+				f.Spam = &c
+			}
+		}
+		if captcha.Valid && captcha.String != "" {
+			var c domain.CaptchaConfig
+			if json.Unmarshal([]byte(captcha.String), &c) == nil {
+				if c.Secret, err = unsealSecret(r.secrets, c.Secret); err != nil {
+					return nil, fmt.Errorf("captcha: %w", err)
+				}
+				f.Captcha = &c
+			}
+		}
+		if normalize.Valid && normalize.String != "" {
+			var c domain.NormalizeConfig
+			if json.Unmarshal([]byte(normalize.String), &c) == nil {
+				f.Normalize = &c
+			}
+		}
+		if hiddenFields.Valid && hiddenFields.String != "" {
+			_ = json.Unmarshal([]byte(hiddenFields.String), &f.HiddenFields)
+		}
+		if webhookRate.Valid && webhookRate.String != "" {
+			var c domain.WebhookRate
+			if json.Unmarshal([]byte(webhookRate.String), &c) == nil {
+				f.WebhookRate = &c
+			}
+		}
+		if notifyRules.Valid && notifyRules.String != "" {
+			_ = json.Unmarshal([]byte(notifyRules.String), &f.NotifyRules)
+		}
+		if emailTemplate.Valid && emailTemplate.String != "" {
+			var t domain.EmailTemplate
+			if json.Unmarshal([]byte(emailTemplate.String), &t) == nil {
+				f.EmailTemplate = &t
+			}
+		}
+		if filters.Valid && filters.String != "" {
+			_ = json.Unmarshal([]byte(filters.String), &f.Filters)
+		}
+		if autoReply.Valid && autoReply.String != "" {
+			var c domain.AutoReplyConfig
+			if json.Unmarshal([]byte(autoReply.String), &c) == nil {
+				f.AutoReply = &c
+			}
+		}
+		if errorPage.Valid && errorPage.String != "" {
+			var c domain.ErrorPageConfig
+			if json.Unmarshal([]byte(errorPage.String), &c) == nil {
+				f.ErrorPage = &c
+			}
+		}
+		if channels.Valid && channels.String != "" {
+			_ = json.Unmarshal([]byte(channels.String), &f.Channels)
+			for i := range f.Channels {
+				if f.Channels[i].URL, err = unsealSecret(r.secrets, f.Channels[i].URL); err != nil {
+					return nil, fmt.Errorf("channels: %w", err)
+				}
+			}
+		}
+		if deletedAt.Valid {
+			f.DeletedAt = &deletedAt.Time
+		}
+		if purgeAt.Valid {
+			f.PurgeAt = &purgeAt.Time
+		}
+		f.ArchivePath = archivePath.String
+		f.Slug = slug.String
+		if updatedAt.Valid {
+			f.UpdatedAt = updatedAt.Time
+		}
+		if lastSubmissionAt.Valid {
+			f.LastSubmissionAt = &lastSubmissionAt.Time
+		}
+		f.PreviousPublicID = previousPublicID.String
+		if previousPublicIDUntil.Valid {
+			f.PreviousPublicIDUntil = &previousPublicIDUntil.Time
+		}
 	}
 
 	return &f, nil
@@ -112,7 +295,7 @@ func (r *FormRepository) getByField(ctx context.Context, field, value string) (*
 
 func (r *FormRepository) List(ctx context.Context) ([]*domain.Form, error) {
 	// Use only original columns for compatibility
-	query := `SELECT id, public_id, name, notify_emails, allowed_origins, redirect_url, created_at FROM forms ORDER BY created_at DESC`
+	query := `SELECT id, public_id, name, notify_emails, allowed_origins, redirect_url, created_at FROM forms WHERE deleted_at IS NULL ORDER BY created_at DESC`
 
 	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
@@ -158,15 +341,75 @@ func (r *FormRepository) Delete(ctx context.Context, id string) error {
 	return err
 }
 
-func (r *FormRepository) ListPaginated(ctx context.Context, limit, offset int) ([]*domain.Form, int, error) {
-	// Get total count
-	var total int
-	_ = r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM forms`).Scan(&total)
+// formSortColumns maps each sort to its ORDER BY expression; indexes are created in migrate
+var formSortColumns = map[domain.FormSort]string{
+	domain.FormSortCreatedAt:        "created_at",
+	domain.FormSortUpdatedAt:        "COALESCE(updated_at, created_at)",
+	domain.FormSortName:             "name COLLATE NOCASE",
+	domain.FormSortSubmissionCount:  "submission_count",
+	domain.FormSortLastSubmissionAt: "last_submission_at",
+}
 
-	// Get paginated forms
-	query := `SELECT id, public_id, name, notify_emails, allowed_origins, redirect_url, created_at FROM forms ORDER BY created_at DESC LIMIT ? OFFSET ?`
+func (r *FormRepository) ListPaginated(ctx context.Context, q domain.FormListQuery, limit, offset int) ([]*domain.Form, int, error) {
+	where := []string{"deleted_at IS NULL"}
+	var args []interface{}
+	if q.OwnerID != "" && len(q.TeamIDs) > 0 {
+		in, teamArgs := inClause(q.TeamIDs)
+		where = append(where, "(owner_id = ? OR team_id IN "+in+")")
+		args = append(append(args, q.OwnerID), teamArgs...)
+	} else if q.OwnerID != "" {
+		where = append(where, "owner_id = ?")
+		args = append(args, q.OwnerID)
+	}
+	if q.TeamID != "" {
+		where = append(where, "team_id = ?")
+		args = append(args, q.TeamID)
+	}
+	if q.Status != "" {
+		where = append(where, "COALESCE(status, 'active') = ?")
+		args = append(args, q.Status)
+	} else if !q.IncludeArchived {
+		where = append(where, "COALESCE(status, 'active') != ?")
+		args = append(args, domain.FormStatusArchived)
+	}
+	if q.AccessMode != "" {
+		where = append(where, "COALESCE(access_mode, 'public') = ?")
+		args = append(args, q.AccessMode)
+	}
+	if q.Search != "" {
+		where = append(where, `name LIKE ? ESCAPE '\'`)
+		args = append(args, "%"+likeEscaper.Replace(q.Search)+"%")
+	}
+	if q.StarredOnly {
+		where = append(where, "id IN (SELECT form_id FROM user_form_stars WHERE user_id = ?)")
+		args = append(args, q.Viewer)
+	}
+	if q.SampleOnly {
+		where = append(where, "COALESCE(sample, 0) = 1")
+	}
+	whereSQL := strings.Join(where, " AND ")
 
-	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM forms WHERE `+whereSQL, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("count forms: %w", err)
+	}
+
+	sortColumn, ok := formSortColumns[q.Sort]
+	if !ok {
+		sortColumn = formSortColumns[domain.FormSortCreatedAt]
+	}
+	dir := "DESC"
+	if q.Ascending {
+		dir = "ASC"
+	}
+	// #nosec G202 -- sort column and direction come from fixed lists, values are bound
+	query := `SELECT id, public_id, name, notify_emails, allowed_origins, redirect_url, created_at,
+		status, submission_count, access_mode, owner_id, team_id, updated_at, last_submission_at, sample, opens_at, closes_at,
+		EXISTS(SELECT 1 FROM user_form_stars s WHERE s.form_id = forms.id AND s.user_id = ?)
+		FROM forms WHERE ` + whereSQL + ` ORDER BY ` + sortColumn + ` ` + dir + `, created_at DESC, id LIMIT ? OFFSET ?`
+
+	queryArgs := append([]interface{}{q.Viewer}, args...)
+	rows, err := r.db.QueryContext(ctx, query, append(queryArgs, limit, offset)...)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -176,82 +419,337 @@ func (r *FormRepository) ListPaginated(ctx context.Context, limit, offset int) (
 	for rows.Next() {
 		var f domain.Form
 		var emailsRaw, originsRaw string
-		if err := rows.Scan(&f.ID, &f.PublicID, &f.Name, &emailsRaw, &originsRaw, &f.RedirectURL, &f.CreatedAt); err != nil {
+		var status, accessMode, ownerID, teamID sql.NullString
+		var count sql.NullInt64
+		var updatedAt, lastSubmissionAt, opensAt, closesAt sql.NullTime
+		var sample sql.NullBool
+		if err := rows.Scan(&f.ID, &f.PublicID, &f.Name, &emailsRaw, &originsRaw, &f.RedirectURL, &f.CreatedAt,
+			&status, &count, &accessMode, &ownerID, &teamID, &updatedAt, &lastSubmissionAt, &sample, &opensAt, &closesAt, &f.Starred); err != nil {
 			return nil, 0, err
 		}
 		_ = json.Unmarshal([]byte(emailsRaw), &f.NotifyEmails)
 		_ = json.Unmarshal([]byte(originsRaw), &f.AllowedOrigins)
 
 		f.Status = domain.FormStatusActive
-		f.SubmissionCount = 0
+		if status.String != "" {
+			f.Status = domain.FormStatus(status.String)
+		}
+		f.AccessMode = string(domain.AccessModePublic)
+		if accessMode.String != "" {
+			f.AccessMode = accessMode.String
+		}
+		f.SubmissionCount = int(count.Int64)
+		f.OwnerID = ownerID.String
+		f.TeamID = teamID.String
+		f.Sample = sample.Bool
+		f.OpensAt, f.ClosesAt = utcTime(opensAt), utcTime(closesAt)
 		f.UpdatedAt = f.CreatedAt
+		if updatedAt.Valid {
+			f.UpdatedAt = updatedAt.Time
+		}
+		if lastSubmissionAt.Valid {
+			f.LastSubmissionAt = &lastSubmissionAt.Time
+		}
 
 		forms = append(forms, &f)
 	}
 
-	// Try to get extended data for all forms
-	for _, f := range forms {
-		var status string
-		var count int
-		if err := r.db.QueryRowContext(ctx, `SELECT status, submission_count FROM forms WHERE id = ?`, f.ID).Scan(&status, &count); err == nil {
-			if status != "" {
-				f.Status = domain.FormStatus(status)
-			}
-			f.SubmissionCount = count
-		}
-	}
-
-	return forms, total, nil
+	return forms, total, rows.Err()
 }
 
+// IncrementSubmissionCount counts a new submission and records when it arrived
 func (r *FormRepository) IncrementSubmissionCount(ctx context.Context, formID string) error {
-	_, err := r.db.ExecContext(ctx, `UPDATE forms SET submission_count = COALESCE(submission_count, 0) + 1 WHERE id = ?`, formID)
+	_, err := r.db.ExecContext(ctx, `UPDATE forms SET submission_count = COALESCE(submission_count, 0) + 1, last_submission_at = ? WHERE id = ?`,
+		time.Now().UTC(), formID)
 	return err
 }
 
-func (r *FormRepository) ListByOwnerPaginated(ctx context.Context, ownerID string, limit, offset int) ([]*domain.Form, int, error) {
-	// Get total count for this owner
-	var total int
-	_ = r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM forms WHERE owner_id = ?`, ownerID).Scan(&total)
+func (r *FormRepository) SetOwner(ctx context.Context, formID, ownerID string) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE forms SET owner_id = ?, updated_at = ? WHERE id = ?`, ownerID, time.Now().UTC(), formID)
+	return err
+}
 
-	// Get paginated forms for this owner
-	query := `SELECT id, public_id, name, notify_emails, allowed_origins, redirect_url, created_at FROM forms WHERE owner_id = ? ORDER BY created_at DESC LIMIT ? OFFSET ?`
+func (r *FormRepository) SetTeam(ctx context.Context, formID, teamID string) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE forms SET team_id = ?, updated_at = ? WHERE id = ?`, sql.NullString{String: teamID, Valid: teamID != ""}, time.Now().UTC(), formID)
+	return err
+}
 
-	rows, err := r.db.QueryContext(ctx, query, ownerID, limit, offset)
+func (r *FormRepository) Star(ctx context.Context, userID, formID string) error {
+	_, err := r.db.ExecContext(ctx, `INSERT OR IGNORE INTO user_form_stars (user_id, form_id, created_at) VALUES (?, ?, ?)`,
+		userID, formID, time.Now().UTC())
+	return err
+}
+
+func (r *FormRepository) Unstar(ctx context.Context, userID, formID string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM user_form_stars WHERE user_id = ? AND form_id = ?`, userID, formID)
+	return err
+}
+
+func (r *FormRepository) ListStarredBy(ctx context.Context, formID string) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT user_id FROM user_form_stars WHERE form_id = ?`, formID)
 	if err != nil {
-		return nil, 0, err
+		return nil, err
 	}
 	defer func() { _ = rows.Close() }()
 
-	var forms []*domain.Form
+	var userIDs []string
 	for rows.Next() {
-		var f domain.Form
-		var emailsRaw, originsRaw string
-		if err := rows.Scan(&f.ID, &f.PublicID, &f.Name, &emailsRaw, &originsRaw, &f.RedirectURL, &f.CreatedAt); err != nil {
-			return nil, 0, err
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
 		}
-		_ = json.Unmarshal([]byte(emailsRaw), &f.NotifyEmails)
-		_ = json.Unmarshal([]byte(originsRaw), &f.AllowedOrigins)
+		userIDs = append(userIDs, id)
+	}
+	return userIDs, rows.Err()
+}
 
-		f.Status = domain.FormStatusActive
-		f.SubmissionCount = 0
-		f.UpdatedAt = f.CreatedAt
-		f.OwnerID = ownerID
+// likeEscaper escapes LIKE wildcards in user input (used with ESCAPE '\')
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
 
-		forms = append(forms, &f)
+// ListPurgeable returns deleted forms whose grace period ended before the given time
+func (r *FormRepository) ListPurgeable(ctx context.Context, before time.Time) ([]*domain.Form, error) {
+	return r.listDeleted(ctx, `SELECT public_id FROM forms WHERE deleted_at IS NOT NULL AND substr(purge_at, 1, 19) <= ?`,
+		before.UTC().Format("2006-01-02 15:04:05"))
+}
+
+// ListDeleted returns forms in the trash, newest deletion first. An empty ownerID lists all owners.
+func (r *FormRepository) ListDeleted(ctx context.Context, ownerID string) ([]*domain.Form, error) {
+	if ownerID == "" {
+		return r.listDeleted(ctx, `SELECT public_id FROM forms WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC`)
 	}
+	return r.listDeleted(ctx, `SELECT public_id FROM forms WHERE deleted_at IS NOT NULL AND owner_id = ? ORDER BY deleted_at DESC`, ownerID)
+}
 
-	// Try to get extended data for all forms
-	for _, f := range forms {
-		var status string
-		var count int
-		if err := r.db.QueryRowContext(ctx, `SELECT status, submission_count FROM forms WHERE id = ?`, f.ID).Scan(&status, &count); err == nil {
-			if status != "" {
-				f.Status = domain.FormStatus(status)
-			}
-			f.SubmissionCount = count
+// ListWithRetention returns the forms outside the trash with a retention period
+func (r *FormRepository) ListWithRetention(ctx context.Context) ([]*domain.Form, error) {
+	return r.listDeleted(ctx, `SELECT public_id FROM forms WHERE deleted_at IS NULL AND COALESCE(retention_days, 0) > 0`)
+}
+
+// listDeleted loads the full forms for a query selecting public IDs
+func (r *FormRepository) listDeleted(ctx context.Context, query string, args ...interface{}) ([]*domain.Form, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	var publicIDs []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		publicIDs = append(publicIDs, id)
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	forms := make([]*domain.Form, 0, len(publicIDs))
+	for _, id := range publicIDs {
+		f, err := r.GetByPublicID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if f != nil {
+			forms = append(forms, f)
 		}
 	}
+	return forms, nil
+}
 
-	return forms, total, nil
+// consentJSON encodes a consent config for storage, NULL when the form has none
+func consentJSON(c *domain.ConsentConfig) interface{} {
+	if c == nil {
+		return nil
+	}
+	b, _ := json.Marshal(c)
+	return string(b)
+}
+
+// uploadsJSON encodes an upload config for storage, NULL when the form accepts no files
+func uploadsJSON(u *domain.UploadConfig) interface{} {
+	if u == nil {
+		return nil
+	}
+	b, _ := json.Marshal(u)
+	return string(b)
+}
+
+// submitResponseJSON encodes a submit response config for storage, NULL for the default response
+func submitResponseJSON(c *domain.SubmitResponseConfig) interface{} {
+	if c == nil {
+		return nil
+	}
+	b, _ := json.Marshal(c)
+	return string(b)
+}
+
+// spamJSON encodes a form's spam settings for storage, NULL for the defaults
+func spamJSON(c *domain.SpamConfig) interface{} {
+	if c == nil {
+		return nil
+	}
+	b, _ := json.Marshal(c)
+	return string(b)
+}
+
+// captchaJSON encodes a form's captcha settings for storage, NULL when it has none. The
+// secret is written out here, sealed, as the config's own JSON leaves it out.
+func captchaJSON(codec ports.SecretCodec, c *domain.CaptchaConfig) (interface{}, error) {
+	if c == nil {
+		return nil, nil
+	}
+	secret, err := sealSecret(codec, c.Secret)
+	if err != nil {
+		return nil, err
+	}
+	b, err := json.Marshal(storedCaptcha{Provider: c.Provider, Secret: secret})
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// storedCaptcha is how captcha settings are stored; they're read back into domain.CaptchaConfig
+type storedCaptcha struct {
+	Provider domain.CaptchaProvider `json:"provider"`
+	Secret   string                 `json:"secret"`
+}
+
+// notifyRulesJSON encodes a form's notification rules for storage, NULL when it has none
+func notifyRulesJSON(rules []domain.NotifyRule) interface{} {
+	if len(rules) == 0 {
+		return nil
+	}
+	b, _ := json.Marshal(rules)
+	return string(b)
+}
+
+// emailTemplateJSON encodes a form's email template for storage, NULL when it has none
+func emailTemplateJSON(t *domain.EmailTemplate) interface{} {
+	if t == nil {
+		return nil
+	}
+	b, _ := json.Marshal(t)
+	return string(b)
+}
+
+// autoReplyJSON encodes a form's auto-reply for storage, NULL when it has none
+func autoReplyJSON(c *domain.AutoReplyConfig) interface{} {
+	if c == nil {
+		return nil
+	}
+	b, _ := json.Marshal(c)
+	return string(b)
+}
+
+// errorPageJSON encodes a form's error page settings for storage, NULL when it has none
+func errorPageJSON(c *domain.ErrorPageConfig) interface{} {
+	if c == nil {
+		return nil
+	}
+	b, _ := json.Marshal(c)
+	return string(b)
+}
+
+// channelsJSON encodes a form's notification channels for storage, NULL when it has none.
+// The webhook URLs carry the service's token, so they're sealed.
+func channelsJSON(codec ports.SecretCodec, channels []domain.NotifyChannel) (interface{}, error) {
+	if len(channels) == 0 {
+		return nil, nil
+	}
+	sealed := make([]domain.NotifyChannel, len(channels))
+	for i, c := range channels {
+		var err error
+		if c.URL, err = sealSecret(codec, c.URL); err != nil {
+			return nil, fmt.Errorf("channel %d: %w", i, err)
+		}
+		sealed[i] = c
+	}
+	b, err := json.Marshal(sealed)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// formSecrets are a form's secret columns as they're stored
+type formSecrets struct {
+	webhookSecret, submissionKey string
+	captcha, channels            interface{}
+}
+
+// sealForm seals a form's secrets for storage: the webhook secret, the submission key,
+// the captcha secret and the channels' webhook URLs
+func sealForm(codec ports.SecretCodec, f *domain.Form) (formSecrets, error) {
+	var s formSecrets
+	var err error
+	if s.webhookSecret, err = sealSecret(codec, f.WebhookSecret); err != nil {
+		return s, fmt.Errorf("webhook_secret: %w", err)
+	}
+	if s.submissionKey, err = sealSecret(codec, f.SubmissionKey); err != nil {
+		return s, fmt.Errorf("submission_key: %w", err)
+	}
+	if s.captcha, err = captchaJSON(codec, f.Captcha); err != nil {
+		return s, fmt.Errorf("captcha: %w", err)
+	}
+	if s.channels, err = channelsJSON(codec, f.Channels); err != nil {
+		return s, fmt.Errorf("channels: %w", err)
+	}
+	return s, nil
+}
+
+// normalizeJSON encodes a form's normalize settings for storage, NULL when it has none
+func normalizeJSON(c *domain.NormalizeConfig) interface{} {
+	if c == nil {
+		return nil
+	}
+	b, _ := json.Marshal(c)
+	return string(b)
+}
+
+// webhookRateJSON encodes a webhook's rate limit and batch settings for storage, NULL when unset
+func webhookRateJSON(c *domain.WebhookRate) interface{} {
+	if c == nil {
+		return nil
+	}
+	b, _ := json.Marshal(c)
+	return string(b)
+}
+
+// hiddenFieldsJSON encodes a form's hidden fields for storage, NULL when there are none
+func hiddenFieldsJSON(fields map[string]string) interface{} {
+	if len(fields) == 0 {
+		return nil
+	}
+	b, _ := json.Marshal(fields)
+	return string(b)
+}
+
+// utcTime returns a stored time in UTC, or nil for NULL
+func utcTime(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	utc := t.Time.UTC()
+	return &utc
+}
+
+// frameAncestorsJSON encodes a frame-ancestors allow-list for storage, NULL when empty
+func frameAncestorsJSON(list []string) interface{} {
+	if len(list) == 0 {
+		return nil
+	}
+	b, _ := json.Marshal(list)
+	return string(b)
+}
+
+// fieldsJSON encodes a field schema for storage, NULL when the form has none
+func fieldsJSON(fields []domain.FormField) interface{} {
+	if len(fields) == 0 {
+		return nil
+	}
+	b, _ := json.Marshal(fields)
+	return string(b)
 }
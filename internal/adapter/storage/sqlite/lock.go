@@ -0,0 +1,33 @@
+package sqlite
+
+import (
+	"context"
+	"time"
+)
+
+// LockRepository implements named, expiring locks in SQLite.
+// Expiry is stored as Unix milliseconds so instances in any time zone compare alike.
+type LockRepository struct {
+	db dbtx
+}
+
+func (r *LockRepository) Acquire(ctx context.Context, name, owner string, until time.Time) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+		INSERT INTO locks (name, owner, expires_at) VALUES (?, ?, ?)
+		ON CONFLICT(name) DO UPDATE SET owner = excluded.owner, expires_at = excluded.expires_at
+		WHERE locks.owner = excluded.owner OR locks.expires_at <= ?
+	`, name, owner, until.UnixMilli(), time.Now().UnixMilli())
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+func (r *LockRepository) Release(ctx context.Context, name, owner string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM locks WHERE name = ? AND owner = ?`, name, owner)
+	return err
+}
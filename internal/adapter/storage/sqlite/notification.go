@@ -0,0 +1,92 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"headless_form/internal/core/domain"
+)
+
+// NotificationRepository implements in-app notification storage in SQLite
+type NotificationRepository struct {
+	db dbtx
+}
+
+const notificationColumns = `id, user_id, type, title, body, form_id, submission_id, read_at, created_at`
+
+func (r *NotificationRepository) Create(ctx context.Context, n *domain.Notification) error {
+	_, err := r.db.ExecContext(ctx, `INSERT INTO notifications (`+notificationColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		n.ID, n.UserID, n.Type, n.Title, n.Body, n.FormID, n.SubmissionID, n.ReadAt, n.CreatedAt)
+	return err
+}
+
+func (r *NotificationRepository) ListByUserID(ctx context.Context, userID string, unreadOnly bool, limit, offset int) ([]*domain.Notification, error) {
+	query := `SELECT ` + notificationColumns + ` FROM notifications WHERE user_id = ?`
+	if unreadOnly {
+		query += ` AND read_at IS NULL`
+	}
+	query += ` ORDER BY created_at DESC, id DESC LIMIT ? OFFSET ?`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var notifications []*domain.Notification
+	for rows.Next() {
+		var n domain.Notification
+		var body, formID, submissionID sql.NullString
+		var readAt sql.NullTime
+		if err := rows.Scan(&n.ID, &n.UserID, &n.Type, &n.Title, &body, &formID, &submissionID, &readAt, &n.CreatedAt); err != nil {
+			return nil, err
+		}
+		n.Body = body.String
+		n.FormID = formID.String
+		n.SubmissionID = submissionID.String
+		if readAt.Valid {
+			n.ReadAt = &readAt.Time
+		}
+		notifications = append(notifications, &n)
+	}
+	return notifications, rows.Err()
+}
+
+func (r *NotificationRepository) Count(ctx context.Context, userID string) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM notifications WHERE user_id = ?`, userID).Scan(&count)
+	return count, err
+}
+
+func (r *NotificationRepository) CountUnread(ctx context.Context, userID string) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM notifications WHERE user_id = ? AND read_at IS NULL`, userID).Scan(&count)
+	return count, err
+}
+
+func (r *NotificationRepository) HasUnread(ctx context.Context, userID string, notificationType domain.NotificationType, formID string) (bool, error) {
+	var exists bool
+	err := r.db.QueryRowContext(ctx, `
+		SELECT EXISTS(SELECT 1 FROM notifications WHERE user_id = ? AND read_at IS NULL AND type = ? AND form_id = ?)`,
+		userID, notificationType, formID).Scan(&exists)
+	return exists, err
+}
+
+func (r *NotificationRepository) MarkRead(ctx context.Context, userID, id string, at time.Time) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `UPDATE notifications SET read_at = COALESCE(read_at, ?) WHERE id = ? AND user_id = ?`, at, id, userID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (r *NotificationRepository) MarkAllRead(ctx context.Context, userID string, at time.Time) (int, error) {
+	res, err := r.db.ExecContext(ctx, `UPDATE notifications SET read_at = ? WHERE user_id = ? AND read_at IS NULL`, at, userID)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
@@ -9,7 +9,7 @@ import (
 )
 
 type PasswordResetRepository struct {
-	db *sql.DB
+	db dbtx
 }
 
 func (r *PasswordResetRepository) Create(ctx context.Context, token *domain.PasswordResetToken) error {
@@ -0,0 +1,260 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"headless_form/internal/core/domain"
+	"headless_form/internal/core/ports"
+)
+
+// secretColumns are the columns SealSecrets seals, by table. Their rows are keyed by id.
+var secretColumns = map[string][]string{
+	"forms":         {"webhook_secret", "submission_key"},
+	"site_settings": {"smtp_password", "spam_provider_key"},
+}
+
+// sealSecret prepares a secret column's value for storage; without a codec it's stored as it is
+func sealSecret(codec ports.SecretCodec, secret string) (string, error) {
+	if codec == nil {
+		return secret, nil
+	}
+	return codec.Seal(secret)
+}
+
+// openSecret returns the secret for a secret column's stored value
+func openSecret(ctx context.Context, codec ports.SecretCodec, stored string) (string, error) {
+	if codec == nil {
+		return stored, nil
+	}
+	return codec.Open(ctx, stored)
+}
+
+// unsealSecret returns a secret column's stored value decrypted, with references left for
+// the caller to resolve when the secret is used
+func unsealSecret(codec ports.SecretCodec, stored string) (string, error) {
+	if codec == nil {
+		return stored, nil
+	}
+	return codec.Unseal(stored)
+}
+
+// SealSecrets seals the stored secrets, including the sign-in providers' client secrets and
+// the forms' captcha secrets and channel URLs, that aren't sealed as the store's codec would
+// seal them now: plaintext from before encryption was enabled, and values sealed with
+// a previous key. Runs in one transaction and returns how many values were sealed.
+// A value that can't be opened, e.g. sealed with a key that's no longer configured,
+// fails the whole run.
+func (s *Store) SealSecrets(ctx context.Context) (int, error) {
+	if s.secrets == nil {
+		return 0, nil
+	}
+	sealed := 0
+	err := s.Tx(ctx, func(repo ports.Repository) error {
+		q := repo.(*Store).q
+		for table, columns := range secretColumns {
+			for _, column := range columns {
+				n, err := s.sealColumn(ctx, q, table, column)
+				if err != nil {
+					return fmt.Errorf("%s.%s: %w", table, column, err)
+				}
+				sealed += n
+			}
+		}
+		n, err := s.sealOAuth(ctx, q)
+		if err != nil {
+			return fmt.Errorf("site_settings.oauth: %w", err)
+		}
+		sealed += n
+		for column, reseal := range map[string]func(context.Context, string) (string, int, error){"captcha": s.resealCaptcha, "channels": s.resealChannels} {
+			n, err := s.sealFormJSON(ctx, q, column, reseal)
+			if err != nil {
+				return fmt.Errorf("forms.%s: %w", column, err)
+			}
+			sealed += n
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return sealed, nil
+}
+
+// sealColumn seals a column's values that aren't current
+func (s *Store) sealColumn(ctx context.Context, q dbtx, table, column string) (int, error) {
+	// #nosec G201 -- table and column come from secretColumns
+	rows, err := q.QueryContext(ctx, fmt.Sprintf(`SELECT id, %s FROM %s WHERE COALESCE(%s, '') != ''`, column, table, column))
+	if err != nil {
+		return 0, err
+	}
+	stale := make(map[string]string)
+	for rows.Next() {
+		var id, stored string
+		if err := rows.Scan(&id, &stored); err != nil {
+			_ = rows.Close()
+			return 0, err
+		}
+		if !s.secrets.Current(stored) {
+			stale[id] = stored
+		}
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for id, stored := range stale {
+		secret, err := s.secrets.Open(ctx, stored)
+		if err != nil {
+			return 0, fmt.Errorf("row %s: %w", id, err)
+		}
+		resealed, err := s.secrets.Seal(secret)
+		if err != nil {
+			return 0, fmt.Errorf("row %s: %w", id, err)
+		}
+		// #nosec G201 -- table and column come from secretColumns
+		if _, err := q.ExecContext(ctx, fmt.Sprintf(`UPDATE %s SET %s = ? WHERE id = ?`, table, column), resealed, id); err != nil {
+			return 0, err
+		}
+	}
+	return len(stale), nil
+}
+
+// sealOAuth seals the sign-in providers' client secrets that aren't current. They're
+// kept inside the site settings' oauth JSON, so secretColumns can't list them.
+func (s *Store) sealOAuth(ctx context.Context, q dbtx) (int, error) {
+	var stored sql.NullString
+	err := q.QueryRowContext(ctx, `SELECT oauth FROM site_settings WHERE id = 'default'`).Scan(&stored)
+	if err == sql.ErrNoRows || !stored.Valid || stored.String == "" {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	var raw domain.OAuthSettings
+	if err := json.Unmarshal([]byte(stored.String), &raw); err != nil {
+		return 0, err
+	}
+	stale := 0
+	for _, p := range raw.Providers {
+		if p.ClientSecret != "" && !s.secrets.Current(p.ClientSecret) {
+			stale++
+		}
+	}
+	if stale == 0 {
+		return 0, nil
+	}
+
+	settings, err := openOAuth(ctx, s.secrets, stored.String)
+	if err != nil {
+		return 0, err
+	}
+	resealed, err := oauthJSON(s.secrets, settings)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := q.ExecContext(ctx, `UPDATE site_settings SET oauth = ? WHERE id = 'default'`, resealed); err != nil {
+		return 0, err
+	}
+	return stale, nil
+}
+
+// sealFormJSON seals the secrets kept inside a form's JSON column, which reseal re-encodes
+// with the values that aren't current sealed, returning how many there were
+func (s *Store) sealFormJSON(ctx context.Context, q dbtx, column string, reseal func(context.Context, string) (string, int, error)) (int, error) {
+	// #nosec G201 -- column is one of SealSecrets' own
+	rows, err := q.QueryContext(ctx, fmt.Sprintf(`SELECT id, %s FROM forms WHERE COALESCE(%s, '') != ''`, column, column))
+	if err != nil {
+		return 0, err
+	}
+	stored := make(map[string]string)
+	for rows.Next() {
+		var id, value string
+		if err := rows.Scan(&id, &value); err != nil {
+			_ = rows.Close()
+			return 0, err
+		}
+		stored[id] = value
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	sealed := 0
+	for id, value := range stored {
+		resealed, n, err := reseal(ctx, value)
+		if err != nil {
+			return 0, fmt.Errorf("row %s: %w", id, err)
+		}
+		if n == 0 {
+			continue
+		}
+		// #nosec G201 -- column is one of SealSecrets' own
+		if _, err := q.ExecContext(ctx, fmt.Sprintf(`UPDATE forms SET %s = ? WHERE id = ?`, column), resealed, id); err != nil {
+			return 0, err
+		}
+		sealed += n
+	}
+	return sealed, nil
+}
+
+// resealSecret returns a stored secret sealed as the codec would seal it now
+func (s *Store) resealSecret(ctx context.Context, stored string) (string, error) {
+	secret, err := s.secrets.Open(ctx, stored)
+	if err != nil {
+		return "", err
+	}
+	return s.secrets.Seal(secret)
+}
+
+// resealCaptcha seals a stored captcha config's secret when it isn't current
+func (s *Store) resealCaptcha(ctx context.Context, stored string) (string, int, error) {
+	var c storedCaptcha
+	if err := json.Unmarshal([]byte(stored), &c); err != nil {
+		return "", 0, err
+	}
+	if s.secrets.Current(c.Secret) {
+		return stored, 0, nil
+	}
+	var err error
+	if c.Secret, err = s.resealSecret(ctx, c.Secret); err != nil {
+		return "", 0, err
+	}
+	b, err := json.Marshal(c)
+	if err != nil {
+		return "", 0, err
+	}
+	return string(b), 1, nil
+}
+
+// resealChannels seals the stored channels' webhook URLs that aren't current
+func (s *Store) resealChannels(ctx context.Context, stored string) (string, int, error) {
+	var channels []domain.NotifyChannel
+	if err := json.Unmarshal([]byte(stored), &channels); err != nil {
+		return "", 0, err
+	}
+	sealed := 0
+	for i := range channels {
+		if s.secrets.Current(channels[i].URL) {
+			continue
+		}
+		var err error
+		if channels[i].URL, err = s.resealSecret(ctx, channels[i].URL); err != nil {
+			return "", 0, fmt.Errorf("channel %d: %w", i, err)
+		}
+		sealed++
+	}
+	if sealed == 0 {
+		return stored, 0, nil
+	}
+	b, err := json.Marshal(channels)
+	if err != nil {
+		return "", 0, err
+	}
+	return string(b), sealed, nil
+}
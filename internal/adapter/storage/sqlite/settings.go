@@ -4,14 +4,17 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"fmt"
 	"time"
 
 	"headless_form/internal/core/domain"
+	"headless_form/internal/core/ports"
 )
 
 // SettingsRepository implements settings storage in SQLite
 type SettingsRepository struct {
-	db *sql.DB
+	db      dbtx
+	secrets ports.SecretCodec
 }
 
 func NewSettingsRepository(db *sql.DB) *SettingsRepository {
@@ -27,17 +30,18 @@ func (r *SettingsRepository) Get(ctx context.Context) (*domain.SiteSettings, err
 
 	row := r.db.QueryRowContext(ctx, `
 		SELECT site_name, site_url, smtp_host, smtp_port, smtp_user, smtp_password,
-		       smtp_from, smtp_from_name, smtp_secure, updated_at, updated_by
+		       smtp_from, smtp_from_name, smtp_secure, updated_at, updated_by,
+		       spam_provider, spam_provider_key, filters, spam, oauth, exports
 		FROM site_settings WHERE id = 'default'
 	`)
 
-	var siteName, siteURL, smtpHost, smtpUser, smtpPass, smtpFrom, smtpFromName, updatedBy sql.NullString
+	var siteName, siteURL, smtpHost, smtpUser, smtpPass, smtpFrom, smtpFromName, updatedBy, spamProvider, spamProviderKey, filters, spamSettings, oauth, exports sql.NullString
 	var smtpPort sql.NullInt32
 	var smtpSecure sql.NullBool
 	var updatedAt sql.NullTime
 
 	err := row.Scan(&siteName, &siteURL, &smtpHost, &smtpPort, &smtpUser, &smtpPass,
-		&smtpFrom, &smtpFromName, &smtpSecure, &updatedAt, &updatedBy)
+		&smtpFrom, &smtpFromName, &smtpSecure, &updatedAt, &updatedBy, &spamProvider, &spamProviderKey, &filters, &spamSettings, &oauth, &exports)
 	if err == sql.ErrNoRows {
 		// Return defaults
 		settings.SiteName = "Headless Forms"
@@ -55,12 +59,32 @@ func (r *SettingsRepository) Get(ctx context.Context) (*domain.SiteSettings, err
 	settings.SMTPHost = smtpHost.String
 	settings.SMTPPort = int(smtpPort.Int32)
 	settings.SMTPUser = smtpUser.String
-	settings.SMTPPassword = smtpPass.String
+	if settings.SMTPPassword, err = openSecret(ctx, r.secrets, smtpPass.String); err != nil {
+		return nil, fmt.Errorf("smtp_password: %w", err)
+	}
 	settings.SMTPFrom = smtpFrom.String
 	settings.SMTPFromName = smtpFromName.String
 	settings.SMTPSecure = smtpSecure.Bool
 	settings.UpdatedAt = updatedAt.Time
 	settings.UpdatedBy = updatedBy.String
+	settings.SpamProvider = spamProvider.String
+	if settings.SpamProviderKey, err = openSecret(ctx, r.secrets, spamProviderKey.String); err != nil {
+		return nil, fmt.Errorf("spam_provider_key: %w", err)
+	}
+	if filters.Valid && filters.String != "" {
+		_ = json.Unmarshal([]byte(filters.String), &settings.Filters)
+	}
+	if spamSettings.Valid && spamSettings.String != "" {
+		_ = json.Unmarshal([]byte(spamSettings.String), &settings.Spam)
+	}
+	if oauth.Valid && oauth.String != "" {
+		if settings.OAuth, err = openOAuth(ctx, r.secrets, oauth.String); err != nil {
+			return nil, fmt.Errorf("oauth: %w", err)
+		}
+	}
+	if exports.Valid && exports.String != "" {
+		_ = json.Unmarshal([]byte(exports.String), &settings.Exports)
+	}
 
 	return settings, nil
 }
@@ -68,11 +92,24 @@ func (r *SettingsRepository) Get(ctx context.Context) (*domain.SiteSettings, err
 // Save stores site settings (upsert)
 func (r *SettingsRepository) Save(ctx context.Context, settings *domain.SiteSettings) error {
 	settings.UpdatedAt = time.Now()
+	smtpPassword, err := sealSecret(r.secrets, settings.SMTPPassword)
+	if err != nil {
+		return fmt.Errorf("smtp_password: %w", err)
+	}
+	spamProviderKey, err := sealSecret(r.secrets, settings.SpamProviderKey)
+	if err != nil {
+		return fmt.Errorf("spam_provider_key: %w", err)
+	}
+	oauth, err := oauthJSON(r.secrets, settings.OAuth)
+	if err != nil {
+		return fmt.Errorf("oauth: %w", err)
+	}
 
-	_, err := r.db.ExecContext(ctx, `
+	_, err = r.db.ExecContext(ctx, `
 		INSERT INTO site_settings (id, site_name, site_url, smtp_host, smtp_port, smtp_user, smtp_password,
-		                           smtp_from, smtp_from_name, smtp_secure, updated_at, updated_by)
-		VALUES ('default', ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		                           smtp_from, smtp_from_name, smtp_secure, updated_at, updated_by,
+		                           spam_provider, spam_provider_key, filters, spam, oauth, exports)
+		VALUES ('default', ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(id) DO UPDATE SET
 			site_name = excluded.site_name,
 			site_url = excluded.site_url,
@@ -84,19 +121,88 @@ func (r *SettingsRepository) Save(ctx context.Context, settings *domain.SiteSett
 			smtp_from_name = excluded.smtp_from_name,
 			smtp_secure = excluded.smtp_secure,
 			updated_at = excluded.updated_at,
-			updated_by = excluded.updated_by
+			updated_by = excluded.updated_by,
+			spam_provider = excluded.spam_provider,
+			spam_provider_key = CASE WHEN excluded.spam_provider_key = '' THEN site_settings.spam_provider_key ELSE excluded.spam_provider_key END,
+			filters = excluded.filters,
+			spam = excluded.spam,
+			oauth = excluded.oauth,
+			exports = excluded.exports
 	`, settings.SiteName, settings.SiteURL, settings.SMTPHost, settings.SMTPPort,
-		settings.SMTPUser, settings.SMTPPassword, settings.SMTPFrom, settings.SMTPFromName,
-		settings.SMTPSecure, settings.UpdatedAt, settings.UpdatedBy)
+		settings.SMTPUser, smtpPassword, settings.SMTPFrom, settings.SMTPFromName,
+		settings.SMTPSecure, settings.UpdatedAt, settings.UpdatedBy,
+		settings.SpamProvider, spamProviderKey, filtersJSON(settings.Filters), spamSettingsJSON(settings.Spam), oauth, exportSettingsJSON(settings.Exports))
 
 	return err
 }
 
+// filtersJSON encodes content filters for storage, NULL when there are none
+func filtersJSON(rules []domain.FilterRule) interface{} {
+	if len(rules) == 0 {
+		return nil
+	}
+	b, _ := json.Marshal(rules)
+	return string(b)
+}
+
+// spamSettingsJSON encodes the spam settings for storage, NULL when there are none
+func spamSettingsJSON(s *domain.SpamSettings) interface{} {
+	if s == nil {
+		return nil
+	}
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+// exportSettingsJSON encodes the export settings for storage, NULL when there are none
+func exportSettingsJSON(s *domain.ExportSettings) interface{} {
+	if s == nil {
+		return nil
+	}
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+// oauthJSON encodes the sign-in providers for storage with their client secrets sealed,
+// NULL when there are none
+func oauthJSON(codec ports.SecretCodec, s *domain.OAuthSettings) (interface{}, error) {
+	if s == nil {
+		return nil, nil
+	}
+	sealed := *s
+	sealed.Providers = make([]domain.OAuthProvider, len(s.Providers))
+	for i, p := range s.Providers {
+		var err error
+		if p.ClientSecret, err = sealSecret(codec, p.ClientSecret); err != nil {
+			return nil, fmt.Errorf("provider %s: %w", p.ID, err)
+		}
+		sealed.Providers[i] = p
+	}
+	b, err := json.Marshal(sealed)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// openOAuth decodes stored sign-in providers and opens their client secrets
+func openOAuth(ctx context.Context, codec ports.SecretCodec, stored string) (*domain.OAuthSettings, error) {
+	var s domain.OAuthSettings
+	if err := json.Unmarshal([]byte(stored), &s); err != nil {
+		return nil, err
+	}
+	for i := range s.Providers {
+		secret, err := openSecret(ctx, codec, s.Providers[i].ClientSecret)
+		if err != nil {
+			return nil, fmt.Errorf("provider %s: %w", s.Providers[i].ID, err)
+		}
+		s.Providers[i].ClientSecret = secret
+	}
+	return &s, nil
+}
+
 // Compile-time interface check
 var _ interface {
 	Get(ctx context.Context) (*domain.SiteSettings, error)
 	Save(ctx context.Context, settings *domain.SiteSettings) error
 } = (*SettingsRepository)(nil)
-
-// Unused import placeholder
-var _ = json.Marshal
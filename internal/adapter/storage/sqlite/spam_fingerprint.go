@@ -0,0 +1,92 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"headless_form/internal/core/domain"
+)
+
+// SpamFingerprintRepository implements the confirmed spam fingerprints in SQLite
+type SpamFingerprintRepository struct {
+	db dbtx
+}
+
+const spamFingerprintColumns = `hash, sample, confirmations, hits, created_at, last_seen_at, expires_at`
+
+func (r *SpamFingerprintRepository) Confirm(ctx context.Context, fp *domain.SpamFingerprint) error {
+	_, err := r.db.ExecContext(ctx, `INSERT INTO spam_fingerprints (`+spamFingerprintColumns+`) VALUES (?, ?, 1, 0, ?, ?, ?)
+		ON CONFLICT(hash) DO UPDATE SET
+			confirmations = spam_fingerprints.confirmations + 1,
+			last_seen_at = excluded.last_seen_at,
+			expires_at = excluded.expires_at`,
+		fp.Hash, fp.Sample, fp.CreatedAt.UTC(), fp.LastSeenAt.UTC(), fp.ExpiresAt.UTC())
+	return err
+}
+
+func (r *SpamFingerprintRepository) Get(ctx context.Context, hash string) (*domain.SpamFingerprint, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+spamFingerprintColumns+` FROM spam_fingerprints WHERE hash = ?`, hash)
+	if err != nil {
+		return nil, err
+	}
+	fps, err := scanSpamFingerprints(rows)
+	if err != nil || len(fps) == 0 {
+		return nil, err
+	}
+	return fps[0], nil
+}
+
+func (r *SpamFingerprintRepository) Hit(ctx context.Context, hash string, seenAt, expiresAt time.Time) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE spam_fingerprints SET hits = hits + 1, last_seen_at = ?, expires_at = ? WHERE hash = ?`,
+		seenAt.UTC(), expiresAt.UTC(), hash)
+	return err
+}
+
+func (r *SpamFingerprintRepository) Delete(ctx context.Context, hash string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM spam_fingerprints WHERE hash = ?`, hash)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (r *SpamFingerprintRepository) List(ctx context.Context, limit, offset int) ([]*domain.SpamFingerprint, int, error) {
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM spam_fingerprints`).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	rows, err := r.db.QueryContext(ctx, `SELECT `+spamFingerprintColumns+` FROM spam_fingerprints
+		ORDER BY hits DESC, confirmations DESC, last_seen_at DESC, hash LIMIT ? OFFSET ?`, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	fps, err := scanSpamFingerprints(rows)
+	return fps, total, err
+}
+
+func (r *SpamFingerprintRepository) DeleteExpired(ctx context.Context, before time.Time) (int, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM spam_fingerprints WHERE substr(expires_at, 1, 19) < ?`,
+		before.UTC().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+// scanSpamFingerprints reads and closes rows
+func scanSpamFingerprints(rows *sql.Rows) ([]*domain.SpamFingerprint, error) {
+	defer func() { _ = rows.Close() }()
+
+	var fps []*domain.SpamFingerprint
+	for rows.Next() {
+		var fp domain.SpamFingerprint
+		if err := rows.Scan(&fp.Hash, &fp.Sample, &fp.Confirmations, &fp.Hits, &fp.CreatedAt, &fp.LastSeenAt, &fp.ExpiresAt); err != nil {
+			return nil, err
+		}
+		fps = append(fps, &fp)
+	}
+	return fps, rows.Err()
+}
@@ -2,15 +2,20 @@ package sqlite
 
 import (
 	"context"
-	"database/sql"
 	"headless_form/internal/core/domain"
+	"sort"
+	"time"
 )
 
+// topReferrersPerDay is how many referers are reported for each day in the stats export
+const topReferrersPerDay = 3
+
+// StatsRepository reports submission statistics. Test submissions (is_test) are never counted.
 type StatsRepository struct {
-	db *sql.DB
+	db dbtx
 }
 
-func (r *StatsRepository) GetDashboardStats(ctx context.Context) (*domain.DashboardStats, error) {
+func (r *StatsRepository) GetDashboardStats(ctx context.Context, loc *time.Location) (*domain.DashboardStats, error) {
 	stats := &domain.DashboardStats{}
 
 	// Total forms
@@ -20,59 +25,182 @@ func (r *StatsRepository) GetDashboardStats(ctx context.Context) (*domain.Dashbo
 	_ = r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM forms WHERE status = 'active' OR status IS NULL`).Scan(&stats.ActiveForms)
 
 	// Total submissions
-	_ = r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM submissions`).Scan(&stats.TotalSubmissions)
+	_ = r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM submissions WHERE COALESCE(is_test, 0) = 0`).Scan(&stats.TotalSubmissions)
 
 	// Unread submissions
-	_ = r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM submissions WHERE status = 'unread' OR status IS NULL`).Scan(&stats.UnreadSubmissions)
-
-	// Submissions today
-	_ = r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM submissions WHERE date(created_at) = date('now')`).Scan(&stats.SubmissionsToday)
-
-	// Submissions this week
-	_ = r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM submissions WHERE created_at >= date('now', '-7 days')`).Scan(&stats.SubmissionsThisWeek)
-
-	// Daily submissions for the last 7 days (for chart)
-	rows, err := r.db.QueryContext(ctx, `
-		WITH RECURSIVE dates(date) AS (
-			SELECT date('now', '-6 days')
-			UNION ALL
-			SELECT date(date, '+1 day')
-			FROM dates
-			WHERE date < date('now')
-		)
-		SELECT d.date, COALESCE(COUNT(s.id), 0) as count
-		FROM dates d
-		LEFT JOIN submissions s ON date(s.created_at) = d.date
-		GROUP BY d.date
-		ORDER BY d.date
-	`)
-	if err == nil {
-		defer func() { _ = rows.Close() }()
-		for rows.Next() {
-			var daily domain.DailySubmission
-			if err := rows.Scan(&daily.Date, &daily.Count); err == nil {
-				stats.DailySubmissions = append(stats.DailySubmissions, daily)
-			}
-		}
+	_ = r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM submissions WHERE (status = 'unread' OR status IS NULL) AND COALESCE(is_test, 0) = 0`).Scan(&stats.UnreadSubmissions)
+
+	// Submissions today, this week and per day for the last 7 days (for chart)
+	today := domain.StartOfDay(time.Now(), loc)
+	daily := make(map[string]int)
+	err := r.eachSubmission(ctx, "", today.AddDate(0, 0, -7), today.AddDate(0, 0, 1), loc, func(day string, _ bool, _ string) {
+		daily[day]++
+	})
+	if err != nil {
+		return nil, err
+	}
+	stats.SubmissionsToday, stats.SubmissionsThisWeek = countRecent(daily, today)
+	for day := today.AddDate(0, 0, -6); !day.After(today); day = day.AddDate(0, 0, 1) {
+		key := day.Format("2006-01-02")
+		stats.DailySubmissions = append(stats.DailySubmissions, domain.DailySubmission{Date: key, Count: daily[key]})
 	}
 
 	return stats, nil
 }
 
-func (r *StatsRepository) GetFormStats(ctx context.Context, formID string) (*domain.FormStats, error) {
+func (r *StatsRepository) GetFormStats(ctx context.Context, formID string, loc *time.Location) (*domain.FormStats, error) {
 	stats := &domain.FormStats{FormID: formID}
 
 	// Total submissions for this form
-	_ = r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM submissions WHERE form_id = ?`, formID).Scan(&stats.TotalSubmissions)
+	_ = r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM submissions WHERE form_id = ? AND COALESCE(is_test, 0) = 0`, formID).Scan(&stats.TotalSubmissions)
 
 	// Unread submissions
-	_ = r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM submissions WHERE form_id = ? AND (status = 'unread' OR status IS NULL)`, formID).Scan(&stats.UnreadSubmissions)
+	_ = r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM submissions WHERE form_id = ? AND (status = 'unread' OR status IS NULL) AND COALESCE(is_test, 0) = 0`, formID).Scan(&stats.UnreadSubmissions)
+
+	// Submissions today and this week
+	today := domain.StartOfDay(time.Now(), loc)
+	daily := make(map[string]int)
+	err := r.eachSubmission(ctx, formID, today.AddDate(0, 0, -7), today.AddDate(0, 0, 1), loc, func(day string, _ bool, _ string) {
+		daily[day]++
+	})
+	if err != nil {
+		return nil, err
+	}
+	stats.SubmissionsToday, stats.SubmissionsThisWeek = countRecent(daily, today)
 
-	// Submissions today
-	_ = r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM submissions WHERE form_id = ? AND date(created_at) = date('now')`, formID).Scan(&stats.SubmissionsToday)
+	return stats, nil
+}
 
-	// Submissions this week
-	_ = r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM submissions WHERE form_id = ? AND created_at >= date('now', '-7 days')`, formID).Scan(&stats.SubmissionsThisWeek)
+// countRecent sums per-day counts for today and for the week (the 7 days before today and today)
+func countRecent(daily map[string]int, today time.Time) (todayCount, weekCount int) {
+	todayCount = daily[today.Format("2006-01-02")]
+	for _, n := range daily {
+		weekCount += n
+	}
+	return todayCount, weekCount
+}
 
-	return stats, nil
+// eachSubmission calls fn with the day (YYYY-MM-DD in loc), spam flag and referer of every
+// non-test submission created in [start, end), of one form or of all forms when formID is "".
+//
+// Days are computed in Go because SQLite can't convert between time zones and created_at
+// is stored in Go's time.String() format, which SQLite date() can't parse. The text
+// comparison in SQL is widened by a day so rows stored with another UTC offset are
+// still read; the exact range is checked on the parsed time.
+func (r *StatsRepository) eachSubmission(ctx context.Context, formID string, start, end time.Time, loc *time.Location, fn func(day string, spam bool, referer string)) error {
+	query := `
+		SELECT created_at,
+		       COALESCE(json_extract(meta, '$._spam.is_spam'), 0),
+		       COALESCE(json_extract(meta, '$._server.referer'), '')
+		FROM submissions
+		WHERE substr(created_at, 1, 19) >= ? AND substr(created_at, 1, 19) < ?
+		  AND COALESCE(is_test, 0) = 0`
+	args := []interface{}{
+		start.AddDate(0, 0, -1).UTC().Format("2006-01-02 15:04:05"),
+		end.AddDate(0, 0, 1).UTC().Format("2006-01-02 15:04:05"),
+	}
+	if formID != "" {
+		query += ` AND form_id = ?`
+		args = append(args, formID)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var createdAt time.Time
+		var spam int
+		var referer string
+		if err := rows.Scan(&createdAt, &spam, &referer); err != nil {
+			return err
+		}
+		if createdAt.Before(start) || !createdAt.Before(end) {
+			continue
+		}
+		fn(createdAt.In(loc).Format("2006-01-02"), spam == 1, referer)
+	}
+	return rows.Err()
+}
+
+// GetFormActivity counts submissions and spam for a form since the given time
+func (r *StatsRepository) GetFormActivity(ctx context.Context, formID string, since time.Time) (*domain.FormActivity, error) {
+	activity := &domain.FormActivity{}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*),
+		       COALESCE(SUM(CASE WHEN json_extract(meta, '$._spam.is_spam') = 1 THEN 1 ELSE 0 END), 0)
+		FROM submissions
+		WHERE form_id = ? AND substr(created_at, 1, 19) >= ? AND COALESCE(is_test, 0) = 0
+	`, formID, since.Format("2006-01-02 15:04:05")).Scan(&activity.Submissions, &activity.Spam)
+	if err != nil {
+		return nil, err
+	}
+	return activity, nil
+}
+
+// GetFormDailyStats returns one row per calendar day in loc from the day of from
+// through the day of to for the given form
+func (r *StatsRepository) GetFormDailyStats(ctx context.Context, formID string, from, to time.Time, loc *time.Location) ([]domain.DailyFormStats, error) {
+	first := domain.StartOfDay(from, loc)
+	last := domain.StartOfDay(to, loc)
+
+	byDate := make(map[string]*domain.DailyFormStats)
+	referers := make(map[string]map[string]int)
+	err := r.eachSubmission(ctx, formID, first, last.AddDate(0, 0, 1), loc, func(day string, spam bool, referer string) {
+		d, ok := byDate[day]
+		if !ok {
+			d = &domain.DailyFormStats{Date: day}
+			byDate[day] = d
+			referers[day] = make(map[string]int)
+		}
+		d.Submissions++
+		if spam {
+			d.Spam++
+		}
+		if referer != "" {
+			referers[day][referer]++
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Fill the whole range so days without submissions are reported as zero
+	var series []domain.DailyFormStats
+	for day := first; !day.After(last); day = day.AddDate(0, 0, 1) {
+		key := day.Format("2006-01-02")
+		d, ok := byDate[key]
+		if !ok {
+			series = append(series, domain.DailyFormStats{Date: key})
+			continue
+		}
+		d.Conversions = d.Submissions - d.Spam
+		d.TopReferrers = topReferrers(referers[key])
+		series = append(series, *d)
+	}
+
+	return series, nil
+}
+
+// topReferrers ranks referers by hits, then alphabetically, and keeps the first topReferrersPerDay
+func topReferrers(hits map[string]int) []string {
+	ranked := make([]string, 0, len(hits))
+	for referer := range hits {
+		ranked = append(ranked, referer)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if hits[ranked[i]] != hits[ranked[j]] {
+			return hits[ranked[i]] > hits[ranked[j]]
+		}
+		return ranked[i] < ranked[j]
+	})
+	if len(ranked) > topReferrersPerDay {
+		ranked = ranked[:topReferrersPerDay]
+	}
+	if len(ranked) == 0 {
+		return nil
+	}
+	return ranked
 }
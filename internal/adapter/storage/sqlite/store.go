@@ -4,17 +4,46 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"headless_form/internal/adapter/logger"
+	"headless_form/internal/adapter/sqltrace"
 	"headless_form/internal/core/ports"
 
 	_ "modernc.org/sqlite" // Pure Go SQLite driver
 )
 
+var log = logger.Module("sql")
+
 type Store struct {
-	db *sql.DB
+	db      *sql.DB
+	q       dbtx              // db itself, or the open transaction for stores handed to a Tx callback
+	secrets ports.SecretCodec // Seals secret columns; stored as they are when nil
+}
+
+// dbtx is implemented by both *sql.DB and *sql.Tx so repositories can run inside a transaction
+type dbtx interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
 }
 
 func New(dbPath string) (*Store, error) {
-	db, err := sql.Open("sqlite", dbPath)
+	return open(dbPath, nil)
+}
+
+// NewWithMonitor opens the database with every statement timed by mon,
+// for slow query logging and per-request query counts
+func NewWithMonitor(dbPath string, mon *sqltrace.Monitor) (*Store, error) {
+	return open(dbPath, mon)
+}
+
+func open(dbPath string, mon *sqltrace.Monitor) (*Store, error) {
+	var db *sql.DB
+	var err error
+	if mon != nil {
+		db, err = sqltrace.Open("sqlite", dbPath, mon)
+	} else {
+		db, err = sql.Open("sqlite", dbPath)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
@@ -28,10 +57,11 @@ func New(dbPath string) (*Store, error) {
 		return nil, fmt.Errorf("failed to enable WAL: %w", err)
 	}
 
-	s := &Store{db: db}
+	s := &Store{db: db, q: db}
 	if err := s.migrate(); err != nil {
 		return nil, fmt.Errorf("migration failed: %w", err)
 	}
+	log.Debug("database ready", "path", dbPath)
 
 	return s, nil
 }
@@ -91,11 +121,57 @@ func (s *Store) migrate() error {
 		`ALTER TABLE forms ADD COLUMN access_mode TEXT DEFAULT 'public'`,
 		`ALTER TABLE forms ADD COLUMN submission_key TEXT`,
 		`ALTER TABLE forms ADD COLUMN owner_id TEXT`,
+		`ALTER TABLE forms ADD COLUMN webhook_transform TEXT`,
+		`ALTER TABLE forms ADD COLUMN webhook_transform_enabled INTEGER DEFAULT 0`,
+		`ALTER TABLE forms ADD COLUMN attach_pdf INTEGER DEFAULT 0`,
+		`ALTER TABLE forms ADD COLUMN public_stats INTEGER DEFAULT 0`,
+		`ALTER TABLE forms ADD COLUMN anonymous INTEGER DEFAULT 0`,
+		`ALTER TABLE forms ADD COLUMN consent TEXT`,
+		`ALTER TABLE forms ADD COLUMN deleted_at DATETIME`,
+		`ALTER TABLE forms ADD COLUMN purge_at DATETIME`,
+		`ALTER TABLE forms ADD COLUMN archive_path TEXT`,
+		`ALTER TABLE forms ADD COLUMN last_submission_at DATETIME`,
+		`ALTER TABLE forms ADD COLUMN webhook_signature TEXT`,
+		`ALTER TABLE forms ADD COLUMN webhook_sign_timestamp INTEGER DEFAULT 0`,
+		`ALTER TABLE forms ADD COLUMN slug TEXT`,
+		`ALTER TABLE forms ADD COLUMN frame_ancestors TEXT`,
+		`ALTER TABLE forms ADD COLUMN fields TEXT`,
+		`ALTER TABLE forms ADD COLUMN timezone TEXT`,
+		`ALTER TABLE forms ADD COLUMN uploads TEXT`,
+		`ALTER TABLE forms ADD COLUMN previous_public_id TEXT`,
+		`ALTER TABLE forms ADD COLUMN previous_public_id_until DATETIME`,
+		`ALTER TABLE forms ADD COLUMN submit_response TEXT`,
+		`ALTER TABLE forms ADD COLUMN spam TEXT`,
+		`ALTER TABLE forms ADD COLUMN captcha TEXT`,
+		`ALTER TABLE forms ADD COLUMN webhook_include_meta INTEGER DEFAULT 0`,
+		`ALTER TABLE forms ADD COLUMN normalize TEXT`,
+		`ALTER TABLE forms ADD COLUMN notify_rules TEXT`,
+		`ALTER TABLE forms ADD COLUMN email_template TEXT`,
+		`ALTER TABLE forms ADD COLUMN filters TEXT`,
+		`ALTER TABLE forms ADD COLUMN auto_reply TEXT`,
+		`ALTER TABLE forms ADD COLUMN error_page TEXT`,
+		`ALTER TABLE forms ADD COLUMN channels TEXT`,
+		`ALTER TABLE forms ADD COLUMN sample INTEGER DEFAULT 0`,
+		`ALTER TABLE forms ADD COLUMN retention_days INTEGER DEFAULT 0`,
+		`ALTER TABLE forms ADD COLUMN retention_action TEXT`,
+		`ALTER TABLE forms ADD COLUMN email_field_limit INTEGER DEFAULT 0`,
+		`ALTER TABLE forms ADD COLUMN team_id TEXT`,
+		`ALTER TABLE forms ADD COLUMN link_field TEXT`,
+		`ALTER TABLE forms ADD COLUMN hidden_fields TEXT`,
+		`ALTER TABLE forms ADD COLUMN webhook_rate TEXT`,
+		`ALTER TABLE forms ADD COLUMN opens_at DATETIME`,
+		`ALTER TABLE forms ADD COLUMN closes_at DATETIME`,
+		`ALTER TABLE users ADD COLUMN timezone TEXT`,
+		`ALTER TABLE tasks ADD COLUMN file TEXT`,
 		`ALTER TABLE submissions ADD COLUMN status TEXT DEFAULT 'unread'`,
+		`ALTER TABLE submissions ADD COLUMN is_test INTEGER DEFAULT 0`,
 	}
 
 	for _, m := range migrations {
-		_, _ = s.db.Exec(m)
+		// Fails with "duplicate column" once applied, which is expected
+		if _, err := s.db.Exec(m); err != nil {
+			log.Debug("migration skipped", "sql", m, "error", err)
+		}
 	}
 
 	// Create indexes for new columns
@@ -103,10 +179,37 @@ func (s *Store) migrate() error {
 		`CREATE INDEX IF NOT EXISTS idx_submissions_status ON submissions(status)`,
 		`CREATE INDEX IF NOT EXISTS idx_submissions_created_at ON submissions(created_at)`,
 		`CREATE INDEX IF NOT EXISTS idx_forms_owner_id ON forms(owner_id)`,
+		// Form list sorting, alone and scoped to an owner
+		`CREATE INDEX IF NOT EXISTS idx_forms_created_at ON forms(created_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_forms_owner_created_at ON forms(owner_id, created_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_forms_name ON forms(name COLLATE NOCASE)`,
+		`CREATE INDEX IF NOT EXISTS idx_forms_submission_count ON forms(submission_count)`,
+		`CREATE INDEX IF NOT EXISTS idx_forms_last_submission_at ON forms(last_submission_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_forms_updated_at ON forms(COALESCE(updated_at, created_at))`,
+		`CREATE INDEX IF NOT EXISTS idx_forms_status ON forms(status)`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_forms_slug ON forms(slug) WHERE slug IS NOT NULL`,
+		`CREATE INDEX IF NOT EXISTS idx_forms_previous_public_id ON forms(previous_public_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_forms_team_id ON forms(team_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_submissions_form_created ON submissions(form_id, created_at)`,
 	}
 
 	for _, idx := range indexes {
-		_, _ = s.db.Exec(idx)
+		if _, err := s.db.Exec(idx); err != nil {
+			log.Warn("failed to create index", "sql", idx, "error", err)
+		}
+	}
+
+	// Backfill last_submission_at for forms created before it was tracked
+	if _, err := s.db.Exec(`UPDATE forms SET last_submission_at = (
+		SELECT MAX(created_at) FROM submissions WHERE form_id = forms.id AND COALESCE(is_test, 0) = 0
+	) WHERE last_submission_at IS NULL AND submission_count > 0`); err != nil {
+		log.Warn("failed to backfill last_submission_at", "error", err)
+	}
+
+	// Quarantine spam stored before it had a status of its own
+	if _, err := s.db.Exec(`UPDATE submissions SET status = 'spam'
+		WHERE json_extract(meta, '$._spam.is_spam') = 1 AND COALESCE(status, 'unread') != 'spam'`); err != nil {
+		log.Warn("failed to backfill spam status", "error", err)
 	}
 
 	// Reset tokens table
@@ -144,37 +247,391 @@ func (s *Store) migrate() error {
 	);
 	`
 	_, _ = s.db.Exec(siteSettingsSchema)
+	for _, m := range []string{
+		`ALTER TABLE site_settings ADD COLUMN spam_provider TEXT`,
+		`ALTER TABLE site_settings ADD COLUMN spam_provider_key TEXT`,
+		`ALTER TABLE site_settings ADD COLUMN filters TEXT`,
+		`ALTER TABLE site_settings ADD COLUMN spam TEXT`,
+		`ALTER TABLE site_settings ADD COLUMN oauth TEXT`,
+		`ALTER TABLE site_settings ADD COLUMN exports TEXT`,
+	} {
+		if _, err := s.db.Exec(m); err != nil {
+			log.Debug("migration skipped", "sql", m, "error", err)
+		}
+	}
+
+	// Per-form alert rules
+	alertRulesSchema := `
+	CREATE TABLE IF NOT EXISTS alert_rules (
+		id TEXT PRIMARY KEY,
+		form_id TEXT NOT NULL,
+		type TEXT NOT NULL,
+		threshold REAL DEFAULT 0,
+		window_hours INTEGER DEFAULT 24,
+		enabled INTEGER DEFAULT 1,
+		last_triggered_at DATETIME,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY(form_id) REFERENCES forms(id) ON DELETE CASCADE
+	);
+	CREATE INDEX IF NOT EXISTS idx_alert_rules_form_id ON alert_rules(form_id);
+	`
+	_, _ = s.db.Exec(alertRulesSchema)
+
+	// Read-only API tokens scoped to one form
+	formTokensSchema := `
+	CREATE TABLE IF NOT EXISTS form_tokens (
+		id TEXT PRIMARY KEY,
+		form_id TEXT NOT NULL,
+		name TEXT NOT NULL,
+		hint TEXT,
+		token_hash TEXT NOT NULL UNIQUE,
+		created_by TEXT,
+		last_used_at DATETIME,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY(form_id) REFERENCES forms(id) ON DELETE CASCADE
+	);
+	CREATE INDEX IF NOT EXISTS idx_form_tokens_form_id ON form_tokens(form_id);
+	`
+	_, _ = s.db.Exec(formTokensSchema)
+
+	// Audit log of destructive actions. No foreign key: entries outlive purged forms.
+	auditSchema := `
+	CREATE TABLE IF NOT EXISTS audit_log (
+		id TEXT PRIMARY KEY,
+		form_id TEXT NOT NULL,
+		user_id TEXT,
+		action TEXT NOT NULL,
+		details TEXT,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_audit_log_form_id ON audit_log(form_id, created_at);
+	`
+	_, _ = s.db.Exec(auditSchema)
+
+	// Background tasks (seeding, long-running jobs) and their progress
+	tasksSchema := `
+	CREATE TABLE IF NOT EXISTS tasks (
+		id TEXT PRIMARY KEY,
+		type TEXT NOT NULL,
+		status TEXT NOT NULL,
+		progress REAL DEFAULT 0,
+		message TEXT,
+		result TEXT,
+		error TEXT,
+		file TEXT,
+		created_by TEXT,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		started_at DATETIME,
+		finished_at DATETIME
+	);
+	CREATE INDEX IF NOT EXISTS idx_tasks_status ON tasks(status);
+	`
+	_, _ = s.db.Exec(tasksSchema)
+
+	// Forms each user starred to keep at hand
+	formStarsSchema := `
+	CREATE TABLE IF NOT EXISTS user_form_stars (
+		user_id TEXT NOT NULL,
+		form_id TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY(user_id, form_id),
+		FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE,
+		FOREIGN KEY(form_id) REFERENCES forms(id) ON DELETE CASCADE
+	);
+	CREATE INDEX IF NOT EXISTS idx_user_form_stars_form_id ON user_form_stars(form_id);
+	`
+	_, _ = s.db.Exec(formStarsSchema)
+
+	// Who opened each submission, for per-user unread counts on shared forms
+	submissionViewsSchema := `
+	CREATE TABLE IF NOT EXISTS submission_views (
+		submission_id TEXT NOT NULL,
+		user_id TEXT NOT NULL,
+		first_viewed_at DATETIME NOT NULL,
+		last_viewed_at DATETIME NOT NULL,
+		PRIMARY KEY(submission_id, user_id),
+		FOREIGN KEY(submission_id) REFERENCES submissions(id) ON DELETE CASCADE,
+		FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+	);
+	CREATE INDEX IF NOT EXISTS idx_submission_views_user_id ON submission_views(user_id);
+	`
+	_, _ = s.db.Exec(submissionViewsSchema)
+
+	// Follow-up submissions and the ones they refer to, often on another form
+	submissionLinksSchema := `
+	CREATE TABLE IF NOT EXISTS submission_links (
+		submission_id TEXT NOT NULL,
+		linked_id TEXT NOT NULL,
+		source TEXT NOT NULL,
+		created_by TEXT,
+		created_at DATETIME NOT NULL,
+		PRIMARY KEY(submission_id, linked_id),
+		FOREIGN KEY(submission_id) REFERENCES submissions(id) ON DELETE CASCADE,
+		FOREIGN KEY(linked_id) REFERENCES submissions(id) ON DELETE CASCADE
+	);
+	CREATE INDEX IF NOT EXISTS idx_submission_links_linked_id ON submission_links(linked_id);
+	`
+	_, _ = s.db.Exec(submissionLinksSchema)
+
+	// Threaded comments on submissions
+	commentsSchema := `
+	CREATE TABLE IF NOT EXISTS submission_comments (
+		id TEXT PRIMARY KEY,
+		submission_id TEXT NOT NULL,
+		parent_id TEXT,
+		user_id TEXT NOT NULL,
+		body TEXT NOT NULL,
+		mentions TEXT,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME,
+		FOREIGN KEY(submission_id) REFERENCES submissions(id) ON DELETE CASCADE,
+		FOREIGN KEY(parent_id) REFERENCES submission_comments(id) ON DELETE CASCADE
+	);
+	CREATE INDEX IF NOT EXISTS idx_submission_comments_submission_id ON submission_comments(submission_id, created_at);
+	CREATE INDEX IF NOT EXISTS idx_submission_comments_parent_id ON submission_comments(parent_id);
+	`
+	_, _ = s.db.Exec(commentsSchema)
+
+	// In-app notification center
+	notificationsSchema := `
+	CREATE TABLE IF NOT EXISTS notifications (
+		id TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL,
+		type TEXT NOT NULL,
+		title TEXT NOT NULL,
+		body TEXT,
+		form_id TEXT,
+		submission_id TEXT,
+		read_at DATETIME,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+	);
+	CREATE INDEX IF NOT EXISTS idx_notifications_user ON notifications(user_id, created_at);
+	CREATE INDEX IF NOT EXISTS idx_notifications_unread ON notifications(user_id, read_at);
+	`
+	_, _ = s.db.Exec(notificationsSchema)
+
+	// Locks that keep scheduled jobs to one instance when several share the database
+	locksSchema := `
+	CREATE TABLE IF NOT EXISTS locks (
+		name TEXT PRIMARY KEY,
+		owner TEXT NOT NULL,
+		expires_at INTEGER NOT NULL
+	);
+	`
+	_, _ = s.db.Exec(locksSchema)
+
+	// Log of webhook deliveries, kept for debugging and replays
+	webhookDeliveriesSchema := `
+	CREATE TABLE IF NOT EXISTS webhook_deliveries (
+		id TEXT PRIMARY KEY,
+		form_id TEXT NOT NULL,
+		event TEXT NOT NULL,
+		url TEXT NOT NULL,
+		payload BLOB,
+		status TEXT NOT NULL,
+		attempts TEXT NOT NULL DEFAULT '[]',
+		error TEXT,
+		is_test BOOLEAN DEFAULT 0,
+		replay_of TEXT,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL,
+		FOREIGN KEY(form_id) REFERENCES forms(id) ON DELETE CASCADE
+	);
+	CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_form ON webhook_deliveries(form_id, created_at);
+	CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_created_at ON webhook_deliveries(created_at);
+	`
+	_, _ = s.db.Exec(webhookDeliveriesSchema)
+
+	// Outgoing emails, sent by the email queue worker with retries
+	emailQueueSchema := `
+	CREATE TABLE IF NOT EXISTS email_queue (
+		id TEXT PRIMARY KEY,
+		recipients TEXT NOT NULL,
+		subject TEXT NOT NULL,
+		message BLOB NOT NULL,
+		status TEXT NOT NULL,
+		attempts INTEGER NOT NULL DEFAULT 0,
+		last_error TEXT,
+		next_attempt_at DATETIME NOT NULL,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL,
+		sent_at DATETIME
+	);
+	CREATE INDEX IF NOT EXISTS idx_email_queue_due ON email_queue(status, next_attempt_at);
+	CREATE INDEX IF NOT EXISTS idx_email_queue_created_at ON email_queue(created_at);
+	`
+	_, _ = s.db.Exec(emailQueueSchema)
+
+	// Content of confirmed spam, quarantining repeats on any form until it expires
+	spamFingerprintSchema := `
+	CREATE TABLE IF NOT EXISTS spam_fingerprints (
+		hash TEXT PRIMARY KEY,
+		sample TEXT NOT NULL,
+		confirmations INTEGER NOT NULL DEFAULT 1,
+		hits INTEGER NOT NULL DEFAULT 0,
+		created_at DATETIME NOT NULL,
+		last_seen_at DATETIME NOT NULL,
+		expires_at DATETIME NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_spam_fingerprints_expires_at ON spam_fingerprints(expires_at);
+	`
+	_, _ = s.db.Exec(spamFingerprintSchema)
+
+	// Teams of users sharing forms (forms.team_id)
+	teamsSchema := `
+	CREATE TABLE IF NOT EXISTS teams (
+		id TEXT PRIMARY KEY,
+		name TEXT NOT NULL,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL
+	);
+	CREATE TABLE IF NOT EXISTS team_members (
+		team_id TEXT NOT NULL,
+		user_id TEXT NOT NULL,
+		role TEXT NOT NULL,
+		added_at DATETIME NOT NULL,
+		PRIMARY KEY(team_id, user_id),
+		FOREIGN KEY(team_id) REFERENCES teams(id) ON DELETE CASCADE,
+		FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+	);
+	CREATE INDEX IF NOT EXISTS idx_team_members_user_id ON team_members(user_id);
+	`
+	_, _ = s.db.Exec(teamsSchema)
 
 	return nil
 }
 
 // Implement Repository Interface
 func (s *Store) Form() ports.FormRepository {
-	return &FormRepository{db: s.db}
+	return &FormRepository{db: s.q, secrets: s.secrets}
 }
 
 func (s *Store) Submission() ports.SubmissionRepository {
-	return &SubmissionRepository{db: s.db}
+	return &SubmissionRepository{db: s.q}
+}
+
+func (s *Store) Comment() ports.CommentRepository {
+	return &CommentRepository{db: s.q}
+}
+
+func (s *Store) Notification() ports.NotificationRepository {
+	return &NotificationRepository{db: s.q}
 }
 
 func (s *Store) Stats() ports.StatsRepository {
-	return &StatsRepository{db: s.db}
+	return &StatsRepository{db: s.q}
 }
 
 func (s *Store) User() ports.UserRepository {
-	return &UserRepository{db: s.db}
+	return &UserRepository{db: s.q}
 }
 
 func (s *Store) PasswordReset() ports.PasswordResetRepository {
-	return &PasswordResetRepository{db: s.db}
+	return &PasswordResetRepository{db: s.q}
 }
 
 func (s *Store) Settings() ports.SettingsRepository {
-	return &SettingsRepository{db: s.db}
+	return &SettingsRepository{db: s.q, secrets: s.secrets}
+}
+
+func (s *Store) Team() ports.TeamRepository {
+	return &TeamRepository{db: s.q}
+}
+
+func (s *Store) Alert() ports.AlertRepository {
+	return &AlertRepository{db: s.q}
 }
 
+func (s *Store) FormToken() ports.FormTokenRepository {
+	return &FormTokenRepository{db: s.q}
+}
+
+func (s *Store) Audit() ports.AuditRepository {
+	return &AuditRepository{db: s.q}
+}
+
+func (s *Store) Task() ports.TaskRepository {
+	return &TaskRepository{db: s.q}
+}
+
+func (s *Store) Lock() ports.LockRepository {
+	return &LockRepository{db: s.q}
+}
+
+func (s *Store) WebhookDelivery() ports.WebhookDeliveryRepository {
+	return &WebhookDeliveryRepository{db: s.q}
+}
+
+func (s *Store) EmailQueue() ports.EmailQueueRepository {
+	return &EmailQueueRepository{db: s.q}
+}
+
+func (s *Store) SpamFingerprint() ports.SpamFingerprintRepository {
+	return &SpamFingerprintRepository{db: s.q}
+}
+
+// SetSecretCodec seals the secret columns (SMTP password, spam provider key, webhook
+// secrets, submission keys, captcha secrets, channel URLs) with codec from now on. Call SealSecrets to seal the values already stored.
+func (s *Store) SetSecretCodec(codec ports.SecretCodec) {
+	s.secrets = codec
+}
+
+// Tx runs fn with a store whose repositories share one transaction. The transaction
+// is committed when fn returns nil and rolled back otherwise. Nested calls join the outer one.
 func (s *Store) Tx(ctx context.Context, fn func(ports.Repository) error) error {
-	return fn(s)
+	if _, inTx := s.q.(*sql.Tx); inTx {
+		return fn(s)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	if err := fn(&Store{db: s.db, q: tx, secrets: s.secrets}); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// Reset deletes every row of every table in one transaction, keeping the schema.
+// Used by demo mode to wipe the database before reseeding it.
+func (s *Store) Reset(ctx context.Context) error {
+	rows, err := s.db.QueryContext(ctx, `SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%'`)
+	if err != nil {
+		return fmt.Errorf("list tables: %w", err)
+	}
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			_ = rows.Close()
+			return fmt.Errorf("list tables: %w", err)
+		}
+		tables = append(tables, name)
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("list tables: %w", err)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	// Foreign keys are checked at commit, once every table is empty
+	if _, err := tx.ExecContext(ctx, `PRAGMA defer_foreign_keys = ON`); err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("defer foreign keys: %w", err)
+	}
+	for _, table := range tables {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM "`+table+`"`); err != nil { // #nosec G202 -- names come from sqlite_master
+			_ = tx.Rollback()
+			return fmt.Errorf("clear %s: %w", table, err)
+		}
+	}
+	return tx.Commit()
 }
 
 func (s *Store) Close() error {
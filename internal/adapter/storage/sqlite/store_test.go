@@ -2,11 +2,19 @@ package sqlite
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
+	"headless_form/internal/adapter/secrets"
 	"headless_form/internal/core/domain"
+	"headless_form/internal/core/ports"
 )
 
 // TestNew verifies database creation and migration
@@ -31,6 +39,78 @@ func TestNew(t *testing.T) {
 	}
 }
 
+// TestStore_TxRollback verifies writes inside a failed transaction are discarded
+func TestStore_TxRollback(t *testing.T) {
+	store := setupTestStore(t)
+	t.Cleanup(func() { _ = store.Close() })
+
+	ctx := context.Background()
+	newForm := func(id string) *domain.Form {
+		return &domain.Form{ID: id, PublicID: id + "-public", Name: "Tx Form", NotifyEmails: []string{}, AllowedOrigins: []string{"*"}, CreatedAt: time.Now()}
+	}
+
+	err := store.Tx(ctx, func(repo ports.Repository) error {
+		if err := repo.Form().Create(ctx, newForm("tx-rolled-back")); err != nil {
+			return err
+		}
+		return fmt.Errorf("abort")
+	})
+	if err == nil || err.Error() != "abort" {
+		t.Fatalf("expected abort error, got %v", err)
+	}
+	if f, _ := store.Form().GetByID(ctx, "tx-rolled-back"); f != nil {
+		t.Error("expected form created in a rolled back transaction to be gone")
+	}
+
+	if err := store.Tx(ctx, func(repo ports.Repository) error {
+		return repo.Form().Create(ctx, newForm("tx-committed"))
+	}); err != nil {
+		t.Fatalf("Tx failed: %v", err)
+	}
+	if f, _ := store.Form().GetByID(ctx, "tx-committed"); f == nil {
+		t.Error("expected committed form to exist")
+	}
+}
+
+// TestStore_Reset verifies every table is emptied, including rows referenced by foreign keys
+func TestStore_Reset(t *testing.T) {
+	store := setupTestStore(t)
+	t.Cleanup(func() { _ = store.Close() })
+
+	ctx := context.Background()
+	form := &domain.Form{ID: "reset-form", PublicID: "reset-public", Name: "Reset Form", NotifyEmails: []string{}, AllowedOrigins: []string{"*"}, CreatedAt: time.Now()}
+	if err := store.Form().Create(ctx, form); err != nil {
+		t.Fatalf("create form: %v", err)
+	}
+	sub := &domain.Submission{ID: "reset-sub", FormID: form.ID, Status: domain.SubmissionStatusUnread, Data: []byte(`{}`), CreatedAt: time.Now()}
+	if err := store.Submission().Create(ctx, sub); err != nil {
+		t.Fatalf("create submission: %v", err)
+	}
+	user := &domain.User{ID: "reset-user", Email: "reset@example.com", PasswordHash: "x", Role: domain.RoleAdmin, CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	if err := store.User().Create(ctx, user); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	if err := store.Reset(ctx); err != nil {
+		t.Fatalf("Reset failed: %v", err)
+	}
+
+	if f, _ := store.Form().GetByID(ctx, form.ID); f != nil {
+		t.Error("expected form to be deleted")
+	}
+	if s, _ := store.Submission().GetByID(ctx, sub.ID); s != nil {
+		t.Error("expected submission to be deleted")
+	}
+	if n, err := store.User().Count(ctx); err != nil || n != 0 {
+		t.Errorf("expected no users, got %d (%v)", n, err)
+	}
+
+	// The schema is kept
+	if err := store.Form().Create(ctx, form); err != nil {
+		t.Errorf("create form after reset: %v", err)
+	}
+}
+
 // TestFormRepository_CRUD tests form create, read, update, delete operations
 func TestFormRepository_CRUD(t *testing.T) {
 	store := setupTestStore(t)
@@ -128,7 +208,7 @@ func TestSubmissionRepository_CRUD(t *testing.T) {
 		FormID:    form.ID,
 		Status:    domain.SubmissionStatusUnread,
 		Data:      []byte(`{"email":"test@example.com","message":"Hello"}`),
-		Meta:      []byte(`{"ip":"127.0.0.1"}`),
+		Meta:      domain.SubmissionMeta{Server: domain.ServerMeta{IP: "127.0.0.1"}},
 		CreatedAt: time.Now(),
 	}
 
@@ -145,6 +225,9 @@ func TestSubmissionRepository_CRUD(t *testing.T) {
 	if retrieved.ID != submission.ID {
 		t.Errorf("expected id %q, got %q", submission.ID, retrieved.ID)
 	}
+	if retrieved.Meta.Server.IP != "127.0.0.1" {
+		t.Errorf("expected meta ip 127.0.0.1, got %q", retrieved.Meta.Server.IP)
+	}
 
 	// Update Status
 	err = submRepo.UpdateStatus(ctx, submission.ID, domain.SubmissionStatusRead)
@@ -166,6 +249,55 @@ func TestSubmissionRepository_CRUD(t *testing.T) {
 		t.Errorf("expected 1 submission, got %d", len(subs))
 	}
 
+	// Paginated list counts every matching submission by status, not just the page
+	for i := 0; i < 2; i++ {
+		_ = submRepo.Create(ctx, &domain.Submission{
+			ID:        fmt.Sprintf("sub-id-extra-%d", i),
+			FormID:    form.ID,
+			Status:    domain.SubmissionStatusUnread,
+			Data:      []byte(`{}`),
+			CreatedAt: time.Now(),
+		})
+	}
+	page, counts, err := submRepo.GetByFormIDPaginated(ctx, form.ID, "", 1, 0)
+	if err != nil {
+		t.Fatalf("GetByFormIDPaginated failed: %v", err)
+	}
+	if len(page) != 1 {
+		t.Errorf("expected 1 submission on the page, got %d", len(page))
+	}
+	if counts != (domain.SubmissionCounts{Total: 3, Unread: 2, Read: 1, Unopened: 3}) {
+		t.Errorf("unexpected counts %+v", counts)
+	}
+
+	// Views are per user: opening twice keeps one entry, other users still see it unopened
+	_ = store.User().Create(ctx, &domain.User{ID: "viewer-1", Email: "viewer@example.com", PasswordHash: "x", Name: "Viewer", CreatedAt: time.Now(), UpdatedAt: time.Now()})
+	opened := time.Now().Add(-time.Minute)
+	for _, at := range []time.Time{opened, opened.Add(time.Minute)} {
+		if err := submRepo.RecordView(ctx, "sub-id-extra-0", "viewer-1", at); err != nil {
+			t.Fatalf("RecordView failed: %v", err)
+		}
+	}
+	views, err := submRepo.ListViews(ctx, "sub-id-extra-0")
+	if err != nil {
+		t.Fatalf("ListViews failed: %v", err)
+	}
+	if len(views) != 1 || views[0].Name != "Viewer" || !views[0].LastViewedAt.After(views[0].FirstViewedAt) {
+		t.Errorf("expected one view by Viewer with updated last_viewed_at, got %+v", views)
+	}
+	if _, counts, _ := submRepo.GetByFormIDPaginated(ctx, form.ID, "viewer-1", 10, 0); counts.Unopened != 2 {
+		t.Errorf("expected 2 unopened for viewer-1, got %d", counts.Unopened)
+	}
+	if _, counts, _ := submRepo.GetByFormIDPaginated(ctx, form.ID, "someone-else", 10, 0); counts.Unopened != 3 {
+		t.Errorf("expected 3 unopened for another user, got %d", counts.Unopened)
+	}
+	page, _, _ = submRepo.GetByFormIDPaginated(ctx, form.ID, "viewer-1", 10, 0)
+	for _, sub := range page {
+		if sub.Opened != (sub.ID == "sub-id-extra-0") {
+			t.Errorf("submission %s: unexpected opened=%v", sub.ID, sub.Opened)
+		}
+	}
+
 	// Delete
 	err = submRepo.Delete(ctx, submission.ID)
 	if err != nil {
@@ -173,6 +305,588 @@ func TestSubmissionRepository_CRUD(t *testing.T) {
 	}
 }
 
+func TestSubmissionRepository_Batch(t *testing.T) {
+	store := setupTestStore(t)
+	t.Cleanup(func() { _ = store.Close() })
+
+	ctx := context.Background()
+	submRepo := store.Submission()
+	for _, id := range []string{"form-a", "form-b"} {
+		_ = store.Form().Create(ctx, &domain.Form{ID: id, PublicID: id + "-public", Name: id, Status: domain.FormStatusActive, CreatedAt: time.Now()})
+	}
+	scored := domain.SubmissionMeta{Spam: &domain.SpamMeta{Score: 40, Flags: []string{"links"}, Threshold: 50}}
+	for i, formID := range []string{"form-a", "form-a", "form-a", "form-b"} {
+		meta := domain.SubmissionMeta{}
+		if i == 1 {
+			meta = scored
+		}
+		_ = submRepo.Create(ctx, &domain.Submission{
+			ID: fmt.Sprintf("sub-%d", i), FormID: formID, Status: domain.SubmissionStatusUnread,
+			Data: []byte(`{}`), Meta: meta, CreatedAt: time.Now(),
+		})
+	}
+	all := []string{"sub-0", "sub-1", "sub-3", "missing"}
+
+	// IDs of another form's submissions are left alone
+	if n, err := submRepo.UpdateStatusBatch(ctx, "form-a", all, domain.SubmissionStatusRead); err != nil || n != 2 {
+		t.Fatalf("UpdateStatusBatch: expected 2 updated, got %d (%v)", n, err)
+	}
+	if sub, _ := submRepo.GetByID(ctx, "sub-3"); sub.Status != domain.SubmissionStatusUnread {
+		t.Error("expected form-b's submission to stay unread")
+	}
+
+	if n, err := submRepo.SetSpamBatch(ctx, "form-a", all, true); err != nil || n != 2 {
+		t.Fatalf("SetSpamBatch: expected 2 marked, got %d (%v)", n, err)
+	}
+	sub, _ := submRepo.GetByID(ctx, "sub-0")
+	if !sub.Meta.IsSpam() || !sub.Meta.Spam.Manual || sub.Status != domain.SubmissionStatusSpam {
+		t.Errorf("expected unscored submission quarantined as manual spam, got %s %+v", sub.Status, sub.Meta.Spam)
+	}
+	sub, _ = submRepo.GetByID(ctx, "sub-1")
+	if !sub.Meta.IsSpam() || sub.Meta.Spam.Score != 40 || len(sub.Meta.Spam.Flags) != 1 {
+		t.Errorf("expected scored submission to keep its score and flags, got %+v", sub.Meta.Spam)
+	}
+	if activity, err := store.Stats().GetFormActivity(ctx, "form-a", time.Now().Add(-time.Hour)); err != nil || activity.Spam != 2 {
+		t.Errorf("expected 2 spam submissions counted by stats, got %+v (%v)", activity, err)
+	}
+	if subs, counts, err := submRepo.SearchByFormID(ctx, "form-a", "", domain.SubmissionListQuery{Status: domain.SubmissionListInbox}, 10, 0); err != nil || len(subs) != 1 || counts.Spam != 0 {
+		t.Errorf("expected only sub-2 in the inbox, got %d (%+v, %v)", len(subs), counts, err)
+	}
+
+	// Not spam releases a submission to the inbox as unread
+	if n, err := submRepo.SetSpamBatch(ctx, "form-a", []string{"sub-1"}, false); err != nil || n != 1 {
+		t.Fatalf("SetSpamBatch: expected 1 released, got %d (%v)", n, err)
+	}
+	sub, _ = submRepo.GetByID(ctx, "sub-1")
+	if sub.Meta.IsSpam() || !sub.Meta.Spam.Manual || sub.Status != domain.SubmissionStatusUnread {
+		t.Errorf("expected submission released as unread, got %s %+v", sub.Status, sub.Meta.Spam)
+	}
+	if _, counts, err := submRepo.SearchByFormID(ctx, "form-a", "", domain.SubmissionListQuery{}, 10, 0); err != nil || counts.Spam != 1 || counts.Unread != 2 {
+		t.Errorf("expected 1 spam and 2 unread counted, got %+v (%v)", counts, err)
+	}
+
+	if n, err := submRepo.DeleteBatch(ctx, "form-a", all); err != nil || n != 2 {
+		t.Fatalf("DeleteBatch: expected 2 deleted, got %d (%v)", n, err)
+	}
+	if subs, _ := submRepo.GetByFormID(ctx, "form-a"); len(subs) != 1 || subs[0].ID != "sub-2" {
+		t.Errorf("expected only sub-2 left on form-a, got %v", subs)
+	}
+	if subs, _ := submRepo.GetByFormID(ctx, "form-b"); len(subs) != 1 {
+		t.Errorf("expected form-b's submission kept, got %d", len(subs))
+	}
+}
+
+func TestSubmissionRepository_DeleteTestBefore(t *testing.T) {
+	store := setupTestStore(t)
+	t.Cleanup(func() { _ = store.Close() })
+
+	ctx := context.Background()
+	_ = store.Form().Create(ctx, &domain.Form{ID: "form-a", PublicID: "form-a-public", Name: "A", Status: domain.FormStatusActive, CreatedAt: time.Now()})
+	created := time.Now().Add(-48 * time.Hour).UTC().Truncate(time.Second)
+	for i, test := range []bool{true, false, true} {
+		at := created
+		if i == 2 {
+			at = time.Now() // Too recent
+		}
+		_ = store.Submission().Create(ctx, &domain.Submission{
+			ID: fmt.Sprintf("sub-%d", i), FormID: "form-a", Status: domain.SubmissionStatusUnread,
+			Data: []byte(`{}`), IsTest: test, CreatedAt: at,
+		})
+	}
+
+	deleted, err := store.Submission().DeleteTestBefore(ctx, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("DeleteTestBefore: %v", err)
+	}
+	if len(deleted) != 1 {
+		t.Fatalf("expected 1 tombstone, got %+v", deleted)
+	}
+	if d := deleted[0]; d.SubmissionID != "sub-0" || d.FormID != "form-a" || !d.IsTest || !d.CreatedAt.Equal(created) {
+		t.Errorf("unexpected tombstone %+v", d)
+	}
+	if sub, _ := store.Submission().GetByID(ctx, "sub-0"); sub != nil {
+		t.Error("expected the old test submission to be deleted")
+	}
+}
+
+func TestSubmissionRepository_Retention(t *testing.T) {
+	store := setupTestStore(t)
+	t.Cleanup(func() { _ = store.Close() })
+
+	ctx := context.Background()
+	_ = store.Form().Create(ctx, &domain.Form{ID: "form-a", PublicID: "form-a-public", Name: "A", Status: domain.FormStatusActive,
+		RetentionDays: 30, RetentionAction: domain.RetentionAnonymize, CreatedAt: time.Now()})
+	_ = store.Form().Create(ctx, &domain.Form{ID: "form-b", PublicID: "form-b-public", Name: "B", Status: domain.FormStatusActive, CreatedAt: time.Now()})
+	forms, err := store.Form().ListWithRetention(ctx)
+	if err != nil || len(forms) != 1 || forms[0].RetentionDays != 30 || forms[0].RetentionAction != domain.RetentionAnonymize {
+		t.Fatalf("expected form-a with its retention, got %+v (%v)", forms, err)
+	}
+
+	old := time.Now().Add(-60 * 24 * time.Hour)
+	for i, at := range []time.Time{old.Add(time.Hour), old, time.Now()} {
+		_ = store.Submission().Create(ctx, &domain.Submission{
+			ID: fmt.Sprintf("sub-%d", i), FormID: "form-a", Status: domain.SubmissionStatusUnread,
+			Data: []byte(`{"name":"Ada"}`), CreatedAt: at,
+		})
+	}
+	cutoff := time.Now().Add(-30 * 24 * time.Hour)
+	expired, err := store.Submission().ListExpired(ctx, "form-a", cutoff, 10)
+	if err != nil || len(expired) != 2 || expired[0].ID != "sub-1" || expired[1].ID != "sub-0" {
+		t.Fatalf("expected sub-1 then sub-0, got %v (%v)", expired, err)
+	}
+
+	sub := expired[0]
+	sub.Anonymize(time.Now())
+	if err := store.Submission().Anonymize(ctx, sub.ID, sub.Data, sub.Meta); err != nil {
+		t.Fatalf("Anonymize: %v", err)
+	}
+	if got, _ := store.Submission().GetByID(ctx, "sub-1"); string(got.Data) != `{"name":null}` || got.Meta.AnonymizedAt == nil {
+		t.Errorf("expected the stored submission anonymized, got %s %+v", got.Data, got.Meta)
+	}
+	if expired, _ := store.Submission().ListExpired(ctx, "form-a", cutoff, 10); len(expired) != 1 || expired[0].ID != "sub-0" {
+		t.Errorf("expected only sub-0 left to expire, got %v", expired)
+	}
+}
+
+// TestStatsRepository_GetFormDailyStats tests the daily time series used by the stats export
+func TestStatsRepository_GetFormDailyStats(t *testing.T) {
+	store := setupTestStore(t)
+	t.Cleanup(func() { _ = store.Close() })
+
+	ctx := context.Background()
+	form := &domain.Form{
+		ID:             "form-stats-1",
+		PublicID:       "form-stats-public-1",
+		Name:           "Stats Form",
+		NotifyEmails:   []string{},
+		AllowedOrigins: []string{"*"},
+		CreatedAt:      time.Now(),
+	}
+	_ = store.Form().Create(ctx, form)
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	metas := []domain.SubmissionMeta{
+		{Server: domain.ServerMeta{Referer: "https://a.example"}, Spam: &domain.SpamMeta{IsSpam: false}},
+		{Server: domain.ServerMeta{Referer: "https://a.example"}, Spam: &domain.SpamMeta{IsSpam: false}},
+		{Server: domain.ServerMeta{Referer: "https://b.example"}, Spam: &domain.SpamMeta{IsSpam: true}},
+	}
+	for i, meta := range metas {
+		_ = store.Submission().Create(ctx, &domain.Submission{
+			ID:        "stats-sub-" + string(rune('a'+i)),
+			FormID:    form.ID,
+			Status:    domain.SubmissionStatusUnread,
+			Data:      []byte(`{}`),
+			Meta:      meta,
+			CreatedAt: time.Now().UTC(),
+		})
+	}
+
+	series, err := store.Stats().GetFormDailyStats(ctx, form.ID, today.AddDate(0, 0, -2), today, time.UTC)
+	if err != nil {
+		t.Fatalf("GetFormDailyStats failed: %v", err)
+	}
+	if len(series) != 3 {
+		t.Fatalf("expected 3 days, got %d", len(series))
+	}
+
+	last := series[2]
+	if last.Submissions != 3 || last.Spam != 1 || last.Conversions != 2 {
+		t.Errorf("unexpected counts for today: %+v", last)
+	}
+	if len(last.TopReferrers) != 2 || last.TopReferrers[0] != "https://a.example" {
+		t.Errorf("unexpected top referrers: %v", last.TopReferrers)
+	}
+	if series[0].Submissions != 0 {
+		t.Errorf("expected empty day to be zero-filled, got %+v", series[0])
+	}
+
+	// Test submissions are left out of every stat
+	_ = store.Submission().Create(ctx, &domain.Submission{
+		ID:        "stats-sub-test",
+		FormID:    form.ID,
+		Status:    domain.SubmissionStatusUnread,
+		Data:      []byte(`{}`),
+		IsTest:    true,
+		CreatedAt: time.Now().UTC(),
+	})
+	series, _ = store.Stats().GetFormDailyStats(ctx, form.ID, today, today, time.UTC)
+	if series[0].Submissions != 3 {
+		t.Errorf("expected test submission to be excluded from daily stats, got %d", series[0].Submissions)
+	}
+	formStats, _ := store.Stats().GetFormStats(ctx, form.ID, time.UTC)
+	if formStats.TotalSubmissions != 3 {
+		t.Errorf("expected test submission to be excluded from form stats, got %d", formStats.TotalSubmissions)
+	}
+	retrieved, _ := store.Submission().GetByID(ctx, "stats-sub-test")
+	if retrieved == nil || !retrieved.IsTest {
+		t.Error("expected is_test to round-trip")
+	}
+}
+
+// TestAlertRepository_CRUD tests alert rule create, list, trigger and delete operations
+// TestStatsRepository_Timezone tests days are bucketed in the requested time zone
+func TestStatsRepository_Timezone(t *testing.T) {
+	store := setupTestStore(t)
+	t.Cleanup(func() { _ = store.Close() })
+
+	ctx := context.Background()
+	form := &domain.Form{
+		ID:             "form-tz-1",
+		PublicID:       "form-tz-public-1",
+		Name:           "Timezone Form",
+		NotifyEmails:   []string{},
+		AllowedOrigins: []string{"*"},
+		Timezone:       "America/New_York",
+		CreatedAt:      time.Now(),
+	}
+	_ = store.Form().Create(ctx, form)
+
+	retrieved, _ := store.Form().GetByPublicID(ctx, form.PublicID)
+	if retrieved == nil || retrieved.Timezone != "America/New_York" {
+		t.Fatalf("expected timezone to round-trip, got %+v", retrieved)
+	}
+
+	// 19:30 and 21:00 on March 10 in New York, but two different days in UTC
+	for i, at := range []time.Time{
+		time.Date(2026, 3, 10, 23, 30, 0, 0, time.UTC),
+		time.Date(2026, 3, 11, 1, 0, 0, 0, time.UTC),
+	} {
+		_ = store.Submission().Create(ctx, &domain.Submission{
+			ID:        "tz-sub-" + string(rune('a'+i)),
+			FormID:    form.ID,
+			Status:    domain.SubmissionStatusUnread,
+			Data:      []byte(`{}`),
+			CreatedAt: at,
+		})
+	}
+
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone database unavailable: %v", err)
+	}
+	from := time.Date(2026, 3, 10, 0, 0, 0, 0, newYork)
+	to := time.Date(2026, 3, 11, 0, 0, 0, 0, newYork)
+
+	series, err := store.Stats().GetFormDailyStats(ctx, form.ID, from, to, newYork)
+	if err != nil {
+		t.Fatalf("GetFormDailyStats failed: %v", err)
+	}
+	if len(series) != 2 || series[0].Date != "2026-03-10" || series[0].Submissions != 2 || series[1].Submissions != 0 {
+		t.Errorf("expected both submissions on March 10 in New York, got %+v", series)
+	}
+
+	series, _ = store.Stats().GetFormDailyStats(ctx, form.ID, from, to, time.UTC)
+	if len(series) != 2 || series[0].Submissions != 1 || series[1].Submissions != 1 {
+		t.Errorf("expected one submission per day in UTC, got %+v", series)
+	}
+}
+
+func TestAlertRepository_CRUD(t *testing.T) {
+	store := setupTestStore(t)
+	t.Cleanup(func() { _ = store.Close() })
+
+	ctx := context.Background()
+	form := &domain.Form{
+		ID:             "form-alert-1",
+		PublicID:       "form-alert-public-1",
+		Name:           "Alert Form",
+		NotifyEmails:   []string{},
+		AllowedOrigins: []string{"*"},
+		CreatedAt:      time.Now(),
+	}
+	_ = store.Form().Create(ctx, form)
+
+	rule := &domain.AlertRule{
+		ID:          "alert-1",
+		FormID:      form.ID,
+		Type:        domain.AlertSpamRate,
+		Threshold:   50,
+		WindowHours: 24,
+		Enabled:     true,
+		CreatedAt:   time.Now(),
+	}
+	if err := store.Alert().Create(ctx, rule); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	rules, err := store.Alert().ListEnabled(ctx)
+	if err != nil {
+		t.Fatalf("ListEnabled failed: %v", err)
+	}
+	if len(rules) != 1 || rules[0].Type != domain.AlertSpamRate || rules[0].LastTriggeredAt != nil {
+		t.Fatalf("unexpected rules: %+v", rules)
+	}
+
+	if err := store.Alert().MarkTriggered(ctx, rule.ID, time.Now()); err != nil {
+		t.Fatalf("MarkTriggered failed: %v", err)
+	}
+	retrieved, err := store.Alert().GetByID(ctx, rule.ID)
+	if err != nil {
+		t.Fatalf("GetByID failed: %v", err)
+	}
+	if retrieved.LastTriggeredAt == nil {
+		t.Error("expected last_triggered_at to be set")
+	}
+
+	if err := store.Alert().Delete(ctx, rule.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	rules, _ = store.Alert().ListByFormID(ctx, form.ID)
+	if len(rules) != 0 {
+		t.Errorf("expected 0 rules after delete, got %d", len(rules))
+	}
+}
+
+// TestFormRepository_SoftDelete tests deleted forms are hidden from lists and purged after purge_at
+func TestFormRepository_SoftDelete(t *testing.T) {
+	store := setupTestStore(t)
+	t.Cleanup(func() { _ = store.Close() })
+
+	ctx := context.Background()
+	form := &domain.Form{
+		ID:             "form-del-1",
+		PublicID:       "form-del-public-1",
+		Name:           "Deleted Form",
+		Status:         domain.FormStatusActive,
+		NotifyEmails:   []string{},
+		AllowedOrigins: []string{"*"},
+		CreatedAt:      time.Now(),
+	}
+	_ = store.Form().Create(ctx, form)
+
+	now := time.Now().UTC()
+	purgeAt := now.Add(time.Hour)
+	form.DeletedAt = &now
+	form.PurgeAt = &purgeAt
+	form.ArchivePath = "/tmp/archive.json"
+	if err := store.Form().Update(ctx, form); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	retrieved, _ := store.Form().GetByPublicID(ctx, form.PublicID)
+	if retrieved == nil || !retrieved.IsDeleted() || retrieved.ArchivePath != form.ArchivePath {
+		t.Fatalf("expected deleted form with archive path, got %+v", retrieved)
+	}
+	if forms, _ := store.Form().List(ctx); len(forms) != 0 {
+		t.Errorf("expected deleted form to be excluded from List, got %d", len(forms))
+	}
+
+	if forms, _ := store.Form().ListDeleted(ctx, ""); len(forms) != 1 {
+		t.Errorf("expected 1 form in trash, got %d", len(forms))
+	}
+	if forms, _ := store.Form().ListDeleted(ctx, "someone-else"); len(forms) != 0 {
+		t.Errorf("expected other owner's trash to be empty, got %d", len(forms))
+	}
+
+	if forms, _ := store.Form().ListPurgeable(ctx, now); len(forms) != 0 {
+		t.Errorf("expected nothing purgeable before purge_at, got %d", len(forms))
+	}
+	if forms, _ := store.Form().ListPurgeable(ctx, purgeAt.Add(time.Second)); len(forms) != 1 {
+		t.Errorf("expected 1 purgeable form after purge_at, got %d", len(forms))
+	}
+}
+
+// TestFormRepository_ListPaginated tests list filters and sort orders
+func TestFormRepository_ListPaginated(t *testing.T) {
+	store := setupTestStore(t)
+	t.Cleanup(func() { _ = store.Close() })
+
+	ctx := context.Background()
+	base := time.Now().UTC().Add(-time.Hour)
+	forms := []*domain.Form{
+		{ID: "f-1", PublicID: "p-1", Name: "beta survey", Status: domain.FormStatusActive, AccessMode: string(domain.AccessModePublic), OwnerID: "u-1", CreatedAt: base},
+		{ID: "f-2", PublicID: "p-2", Name: "Alpha 100% signup", Status: domain.FormStatusInactive, AccessMode: string(domain.AccessModePublic), OwnerID: "u-1", CreatedAt: base.Add(time.Minute)},
+		{ID: "f-3", PublicID: "p-3", Name: "Gamma survey", Status: domain.FormStatusActive, AccessMode: string(domain.AccessModeWithKey), OwnerID: "u-2", CreatedAt: base.Add(2 * time.Minute)},
+	}
+	for _, f := range forms {
+		f.NotifyEmails = []string{}
+		f.AllowedOrigins = []string{"*"}
+		if err := store.Form().Create(ctx, f); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+	}
+	_ = store.Form().IncrementSubmissionCount(ctx, "f-1")
+	_ = store.Form().IncrementSubmissionCount(ctx, "f-1")
+	_ = store.Form().IncrementSubmissionCount(ctx, "f-3")
+
+	names := func(list []*domain.Form) []string {
+		var out []string
+		for _, f := range list {
+			out = append(out, f.Name)
+		}
+		return out
+	}
+
+	tests := []struct {
+		name  string
+		query domain.FormListQuery
+		want  []string
+	}{
+		{"default newest first", domain.FormListQuery{}, []string{"Gamma survey", "Alpha 100% signup", "beta survey"}},
+		{"name ascending ignores case", domain.FormListQuery{Sort: domain.FormSortName, Ascending: true}, []string{"Alpha 100% signup", "beta survey", "Gamma survey"}},
+		{"most submissions", domain.FormListQuery{Sort: domain.FormSortSubmissionCount}, []string{"beta survey", "Gamma survey", "Alpha 100% signup"}},
+		{"owner", domain.FormListQuery{OwnerID: "u-1"}, []string{"Alpha 100% signup", "beta survey"}},
+		{"status", domain.FormListQuery{Status: domain.FormStatusActive}, []string{"Gamma survey", "beta survey"}},
+		{"access mode", domain.FormListQuery{AccessMode: domain.AccessModeWithKey}, []string{"Gamma survey"}},
+		{"search", domain.FormListQuery{Search: "SURVEY", Sort: domain.FormSortName, Ascending: true}, []string{"beta survey", "Gamma survey"}},
+		{"search escapes wildcards", domain.FormListQuery{Search: "100%"}, []string{"Alpha 100% signup"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.query.Validate(); err != nil {
+				t.Fatalf("Validate failed: %v", err)
+			}
+			list, total, err := store.Form().ListPaginated(ctx, tt.query, 10, 0)
+			if err != nil {
+				t.Fatalf("ListPaginated failed: %v", err)
+			}
+			if got := names(list); fmt.Sprint(got) != fmt.Sprint(tt.want) || total != len(tt.want) {
+				t.Errorf("got %v (total %d), want %v", got, total, tt.want)
+			}
+		})
+	}
+
+	list, _, _ := store.Form().ListPaginated(ctx, domain.FormListQuery{Sort: domain.FormSortLastSubmissionAt}, 10, 0)
+	if len(list) != 3 || list[0].LastSubmissionAt == nil || list[2].LastSubmissionAt != nil {
+		t.Errorf("expected forms without submissions last, got %v", names(list))
+	}
+}
+
+// TestFormRepository_Stars tests starring forms flags and filters them for that user only
+func TestFormRepository_Slug(t *testing.T) {
+	store := setupTestStore(t)
+	t.Cleanup(func() { _ = store.Close() })
+
+	ctx := context.Background()
+	form := &domain.Form{ID: "f-1", PublicID: "p-1", Slug: "contact", Name: "Contact", NotifyEmails: []string{}, AllowedOrigins: []string{"*"}, CreatedAt: time.Now()}
+	if err := store.Form().Create(ctx, form); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	got, err := store.Form().GetBySlug(ctx, "contact")
+	if err != nil || got == nil || got.ID != "f-1" || got.Slug != "contact" {
+		t.Fatalf("expected form f-1 by slug, got %+v (err=%v)", got, err)
+	}
+	if got, _ := store.Form().GetBySlug(ctx, "missing"); got != nil {
+		t.Errorf("expected nil for unknown slug, got %+v", got)
+	}
+
+	other := &domain.Form{ID: "f-2", PublicID: "p-2", Slug: "contact", Name: "Other", NotifyEmails: []string{}, AllowedOrigins: []string{"*"}, CreatedAt: time.Now()}
+	if err := store.Form().Create(ctx, other); err == nil {
+		t.Error("expected an error creating a second form with the same slug")
+	}
+	if got, _ := store.Form().GetByID(ctx, "f-2"); got != nil {
+		t.Error("expected the conflicting form not to be created")
+	}
+
+	// Forms without a slug don't collide
+	for _, id := range []string{"f-3", "f-4"} {
+		f := &domain.Form{ID: id, PublicID: "p-" + id, Name: id, NotifyEmails: []string{}, AllowedOrigins: []string{"*"}, CreatedAt: time.Now()}
+		if err := store.Form().Create(ctx, f); err != nil {
+			t.Fatalf("Create without slug failed: %v", err)
+		}
+	}
+}
+
+func TestFormRepository_Stars(t *testing.T) {
+	store := setupTestStore(t)
+	t.Cleanup(func() { _ = store.Close() })
+
+	ctx := context.Background()
+	for _, id := range []string{"u-1", "u-2"} {
+		if err := store.User().Create(ctx, &domain.User{ID: id, Email: id + "@example.com", PasswordHash: "x", Role: "user", CreatedAt: time.Now(), UpdatedAt: time.Now()}); err != nil {
+			t.Fatalf("Create user failed: %v", err)
+		}
+	}
+	for _, id := range []string{"f-1", "f-2"} {
+		if err := store.Form().Create(ctx, &domain.Form{ID: id, PublicID: id + "-public", Name: id, NotifyEmails: []string{}, AllowedOrigins: []string{"*"}, CreatedAt: time.Now()}); err != nil {
+			t.Fatalf("Create form failed: %v", err)
+		}
+	}
+
+	// Starring twice is a no-op
+	for i := 0; i < 2; i++ {
+		if err := store.Form().Star(ctx, "u-1", "f-1"); err != nil {
+			t.Fatalf("Star failed: %v", err)
+		}
+	}
+
+	list, total, err := store.Form().ListPaginated(ctx, domain.FormListQuery{Viewer: "u-1", StarredOnly: true}, 10, 0)
+	if err != nil {
+		t.Fatalf("ListPaginated failed: %v", err)
+	}
+	if total != 1 || len(list) != 1 || list[0].ID != "f-1" || !list[0].Starred {
+		t.Fatalf("expected only f-1, starred, got %d forms", total)
+	}
+
+	list, _, _ = store.Form().ListPaginated(ctx, domain.FormListQuery{Viewer: "u-2"}, 10, 0)
+	for _, f := range list {
+		if f.Starred {
+			t.Errorf("form %s should not be starred for another user", f.ID)
+		}
+	}
+	if _, total, _ := store.Form().ListPaginated(ctx, domain.FormListQuery{Viewer: "u-2", StarredOnly: true}, 10, 0); total != 0 {
+		t.Errorf("expected no starred forms for u-2, got %d", total)
+	}
+
+	if err := store.Form().Unstar(ctx, "u-1", "f-1"); err != nil {
+		t.Fatalf("Unstar failed: %v", err)
+	}
+	if _, total, _ := store.Form().ListPaginated(ctx, domain.FormListQuery{Viewer: "u-1", StarredOnly: true}, 10, 0); total != 0 {
+		t.Errorf("expected no starred forms after unstar, got %d", total)
+	}
+}
+
+// TestCommentRepository_Threads tests comments list with their author and replies go with their parent
+func TestCommentRepository_Threads(t *testing.T) {
+	store := setupTestStore(t)
+	t.Cleanup(func() { _ = store.Close() })
+
+	ctx := context.Background()
+	_ = store.User().Create(ctx, &domain.User{ID: "u-1", Email: "u1@example.com", PasswordHash: "x", Name: "Reviewer", CreatedAt: time.Now(), UpdatedAt: time.Now()})
+	_ = store.Form().Create(ctx, &domain.Form{ID: "f-1", PublicID: "p-1", Name: "Form", NotifyEmails: []string{}, AllowedOrigins: []string{"*"}, CreatedAt: time.Now()})
+	_ = store.Submission().Create(ctx, &domain.Submission{ID: "s-1", FormID: "f-1", Data: []byte(`{}`), CreatedAt: time.Now()})
+
+	now := time.Now().UTC()
+	comments := []*domain.Comment{
+		{ID: "c-1", SubmissionID: "s-1", UserID: "u-1", Body: "First", Mentions: []string{"u-1"}, CreatedAt: now},
+		{ID: "c-2", SubmissionID: "s-1", ParentID: "c-1", UserID: "u-1", Body: "Reply", CreatedAt: now.Add(time.Second)},
+		{ID: "c-3", SubmissionID: "s-1", ParentID: "c-2", UserID: "u-1", Body: "Nested reply", CreatedAt: now.Add(2 * time.Second)},
+		{ID: "c-4", SubmissionID: "s-1", UserID: "u-1", Body: "Second thread", CreatedAt: now.Add(3 * time.Second)},
+	}
+	for _, c := range comments {
+		if err := store.Comment().Create(ctx, c); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+	}
+
+	list, err := store.Comment().ListBySubmissionID(ctx, "s-1")
+	if err != nil {
+		t.Fatalf("ListBySubmissionID failed: %v", err)
+	}
+	if len(list) != 4 || list[0].AuthorName != "Reviewer" || list[1].ParentID != "c-1" || fmt.Sprint(list[0].Mentions) != "[u-1]" {
+		t.Fatalf("unexpected comments %+v", list)
+	}
+
+	edited := now.Add(time.Minute)
+	list[0].Body = "First (edited)"
+	list[0].UpdatedAt = &edited
+	if err := store.Comment().Update(ctx, list[0]); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if c, _ := store.Comment().GetByID(ctx, "c-1"); c == nil || c.Body != "First (edited)" || c.UpdatedAt == nil {
+		t.Errorf("expected edited comment, got %+v", c)
+	}
+
+	if err := store.Comment().Delete(ctx, "c-1"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	list, _ = store.Comment().ListBySubmissionID(ctx, "s-1")
+	if len(list) != 1 || list[0].ID != "c-4" {
+		t.Errorf("expected only the other thread to remain, got %d comments", len(list))
+	}
+}
+
 // TestUserRepository_CRUD tests user create, read, update, delete operations
 func TestUserRepository_CRUD(t *testing.T) {
 	store := setupTestStore(t)
@@ -217,10 +931,14 @@ func TestUserRepository_CRUD(t *testing.T) {
 
 	// Update
 	user.Name = "Updated User"
+	user.Timezone = "Europe/Berlin"
 	err = userRepo.Update(ctx, user)
 	if err != nil {
 		t.Fatalf("Update failed: %v", err)
 	}
+	if retrieved, _ = userRepo.GetByID(ctx, user.ID); retrieved.Timezone != "Europe/Berlin" {
+		t.Errorf("expected timezone to round-trip, got %q", retrieved.Timezone)
+	}
 
 	// Count
 	count, err := userRepo.Count(ctx)
@@ -276,3 +994,574 @@ func setupTestStore(t *testing.T) *Store {
 
 	return store
 }
+
+func TestNotificationRepository(t *testing.T) {
+	store := setupTestStore(t)
+	t.Cleanup(func() { _ = store.Close() })
+
+	ctx := context.Background()
+	for _, id := range []string{"u-1", "u-2"} {
+		_ = store.User().Create(ctx, &domain.User{ID: id, Email: id + "@example.com", PasswordHash: "x", CreatedAt: time.Now(), UpdatedAt: time.Now()})
+	}
+
+	now := time.Now().UTC()
+	notifications := []*domain.Notification{
+		{ID: "n-1", UserID: "u-1", Type: domain.NotificationWebhookFailed, Title: "Webhook failing", FormID: "p-1", CreatedAt: now},
+		{ID: "n-2", UserID: "u-1", Type: domain.NotificationNewSubmission, Title: "New submission", FormID: "p-1", SubmissionID: "s-1", CreatedAt: now.Add(time.Second)},
+		{ID: "n-3", UserID: "u-2", Type: domain.NotificationMention, Title: "Mentioned", CreatedAt: now},
+	}
+	for _, n := range notifications {
+		if err := store.Notification().Create(ctx, n); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+	}
+
+	list, err := store.Notification().ListByUserID(ctx, "u-1", false, 10, 0)
+	if err != nil {
+		t.Fatalf("ListByUserID failed: %v", err)
+	}
+	if len(list) != 2 || list[0].ID != "n-2" || list[0].SubmissionID != "s-1" || list[0].ReadAt != nil {
+		t.Fatalf("unexpected notifications %+v", list)
+	}
+
+	if unread, _ := store.Notification().HasUnread(ctx, "u-1", domain.NotificationWebhookFailed, "p-1"); !unread {
+		t.Error("expected an unread webhook notification")
+	}
+	if found, err := store.Notification().MarkRead(ctx, "u-2", "n-1", now); err != nil || found {
+		t.Errorf("expected another user's notification to be left alone, found=%v err=%v", found, err)
+	}
+	if found, err := store.Notification().MarkRead(ctx, "u-1", "n-1", now); err != nil || !found {
+		t.Fatalf("MarkRead failed: found=%v err=%v", found, err)
+	}
+	if unread, _ := store.Notification().HasUnread(ctx, "u-1", domain.NotificationWebhookFailed, "p-1"); unread {
+		t.Error("expected webhook notification to be read")
+	}
+	if n, _ := store.Notification().CountUnread(ctx, "u-1"); n != 1 {
+		t.Errorf("expected 1 unread, got %d", n)
+	}
+	if list, _ := store.Notification().ListByUserID(ctx, "u-1", true, 10, 0); len(list) != 1 || list[0].ID != "n-2" {
+		t.Errorf("expected only n-2 unread, got %+v", list)
+	}
+
+	if n, err := store.Notification().MarkAllRead(ctx, "u-1", now); err != nil || n != 1 {
+		t.Errorf("expected 1 marked read, got %d (err=%v)", n, err)
+	}
+	if n, _ := store.Notification().CountUnread(ctx, "u-2"); n != 1 {
+		t.Errorf("expected other user's notification to stay unread, got %d", n)
+	}
+}
+
+func TestLockRepository(t *testing.T) {
+	store := setupTestStore(t)
+	t.Cleanup(func() { _ = store.Close() })
+	ctx := context.Background()
+	locks := store.Lock()
+	later := time.Now().Add(time.Minute)
+
+	acquire := func(owner string, until time.Time) bool {
+		t.Helper()
+		ok, err := locks.Acquire(ctx, "purge", owner, until)
+		if err != nil {
+			t.Fatalf("acquire as %s: %v", owner, err)
+		}
+		return ok
+	}
+
+	if !acquire("a", later) {
+		t.Fatal("expected a to take the free lock")
+	}
+	if acquire("b", later) {
+		t.Error("b must not take a's lock")
+	}
+	if !acquire("a", later.Add(time.Minute)) {
+		t.Error("a should renew its own lock")
+	}
+
+	// Expired locks go to whoever asks next
+	if !acquire("a", time.Now().Add(-time.Second)) {
+		t.Fatal("a should renew its own lock")
+	}
+	if !acquire("b", later) {
+		t.Error("b should take the expired lock")
+	}
+
+	if err := locks.Release(ctx, "purge", "a"); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	if acquire("a", later) {
+		t.Error("releasing a lock someone else holds must not free it")
+	}
+	if err := locks.Release(ctx, "purge", "b"); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	if !acquire("a", later) {
+		t.Error("a should take the released lock")
+	}
+}
+
+func TestWebhookDeliveryRepository(t *testing.T) {
+	store := setupTestStore(t)
+	t.Cleanup(func() { _ = store.Close() })
+	ctx := context.Background()
+	form := &domain.Form{ID: "wh-form", PublicID: "wh-public", Name: "Webhooks", NotifyEmails: []string{}, AllowedOrigins: []string{"*"}, CreatedAt: time.Now()}
+	if err := store.Form().Create(ctx, form); err != nil {
+		t.Fatalf("create form: %v", err)
+	}
+	deliveries := store.WebhookDelivery()
+
+	now := time.Now().UTC().Truncate(time.Second)
+	newDelivery := func(id string, created time.Time) *domain.WebhookDelivery {
+		return &domain.WebhookDelivery{
+			ID: id, FormID: form.ID, Event: "submission.created", URL: "https://example.com/hook",
+			Payload: json.RawMessage(`{"a":1}`), Status: domain.WebhookDeliveryPending,
+			Attempts: []domain.WebhookAttempt{}, CreatedAt: created, UpdatedAt: created,
+		}
+	}
+	old := newDelivery("whd_old", now.Add(-48*time.Hour))
+	recent := newDelivery("whd_recent", now)
+	for _, d := range []*domain.WebhookDelivery{old, recent} {
+		if err := deliveries.Create(ctx, d); err != nil {
+			t.Fatalf("create %s: %v", d.ID, err)
+		}
+	}
+
+	recent.Status = domain.WebhookDeliverySucceeded
+	recent.Attempts = append(recent.Attempts, domain.WebhookAttempt{At: now, StatusCode: 503, LatencyMs: 12, Response: "busy", Error: "unexpected status 503"},
+		domain.WebhookAttempt{At: now, StatusCode: 200, LatencyMs: 8})
+	if err := deliveries.Update(ctx, recent); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	got, err := deliveries.GetByID(ctx, recent.ID)
+	if err != nil || got == nil {
+		t.Fatalf("get: %v, %v", got, err)
+	}
+	if got.Status != domain.WebhookDeliverySucceeded || len(got.Attempts) != 2 || got.Attempts[0].StatusCode != 503 || got.Attempts[0].Response != "busy" {
+		t.Errorf("unexpected delivery %+v", got)
+	}
+	if string(got.Payload) != `{"a":1}` || !got.CreatedAt.Equal(now) {
+		t.Errorf("unexpected payload %s or time %v", got.Payload, got.CreatedAt)
+	}
+	if missing, err := deliveries.GetByID(ctx, "whd_missing"); err != nil || missing != nil {
+		t.Errorf("expected nil for a missing delivery, got %v, %v", missing, err)
+	}
+
+	// Newest first, paginated
+	page, total, err := deliveries.ListByFormID(ctx, form.ID, 1, 0)
+	if err != nil || total != 2 || len(page) != 1 || page[0].ID != recent.ID {
+		t.Fatalf("first page: %v, %d, %v", page, total, err)
+	}
+	page, _, _ = deliveries.ListByFormID(ctx, form.ID, 1, 1)
+	if len(page) != 1 || page[0].ID != old.ID {
+		t.Errorf("second page: %v", page)
+	}
+
+	n, err := deliveries.DeleteBefore(ctx, now.Add(-time.Hour))
+	if err != nil || n != 1 {
+		t.Fatalf("delete before: %d, %v", n, err)
+	}
+	if _, total, _ = deliveries.ListByFormID(ctx, form.ID, 10, 0); total != 1 {
+		t.Errorf("expected 1 delivery left, got %d", total)
+	}
+}
+
+func TestEmailQueueRepository(t *testing.T) {
+	store := setupTestStore(t)
+	t.Cleanup(func() { _ = store.Close() })
+	ctx := context.Background()
+	queue := store.EmailQueue()
+
+	now := time.Now().UTC().Truncate(time.Second)
+	newEmail := func(id string, status domain.EmailStatus, next, updated time.Time) *domain.QueuedEmail {
+		return &domain.QueuedEmail{
+			ID: id, To: []string{"a@example.com", "b@example.com"}, Subject: id, Message: []byte("Subject: " + id + "\r\n\r\nbody"),
+			Status: status, NextAttemptAt: next, CreatedAt: updated, UpdatedAt: updated,
+		}
+	}
+	for _, e := range []*domain.QueuedEmail{
+		newEmail("eml_due", domain.EmailPending, now.Add(-time.Minute), now.Add(-time.Minute)),
+		newEmail("eml_later", domain.EmailPending, now.Add(time.Hour), now),
+		newEmail("eml_old_sent", domain.EmailSent, now, now.Add(-48*time.Hour)),
+		newEmail("eml_old_dead", domain.EmailDead, now, now.Add(-48*time.Hour)),
+	} {
+		if err := queue.Create(ctx, e); err != nil {
+			t.Fatalf("create %s: %v", e.ID, err)
+		}
+	}
+
+	due, err := queue.ListDue(ctx, now, 10)
+	if err != nil || len(due) != 1 || due[0].ID != "eml_due" {
+		t.Fatalf("expected only eml_due to be due, got %v, %v", due, err)
+	}
+	if len(due[0].To) != 2 || string(due[0].Message) != "Subject: eml_due\r\n\r\nbody" {
+		t.Errorf("unexpected recipients or message %v %q", due[0].To, due[0].Message)
+	}
+
+	due[0].Status, due[0].Attempts, due[0].SentAt = domain.EmailSent, 1, &now
+	if err := queue.Update(ctx, due[0]); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	list, total, err := queue.List(ctx, domain.EmailSent, 10, 0)
+	if err != nil || total != 2 || list[0].ID != "eml_due" || list[0].SentAt == nil || list[0].Message != nil {
+		t.Fatalf("unexpected sent list %v, %d, %v", list, total, err)
+	}
+	counts, err := queue.CountByStatus(ctx)
+	if err != nil || counts[domain.EmailPending] != 1 || counts[domain.EmailSent] != 2 || counts[domain.EmailDead] != 1 {
+		t.Errorf("unexpected counts %v, %v", counts, err)
+	}
+
+	// Only finished emails are pruned, pending ones stay whatever their age
+	if n, err := queue.DeleteFinishedBefore(ctx, now.Add(-24*time.Hour)); err != nil || n != 2 {
+		t.Errorf("expected 2 emails pruned, got %d, %v", n, err)
+	}
+	if _, total, _ := queue.List(ctx, "", 10, 0); total != 2 {
+		t.Errorf("expected 2 emails left, got %d", total)
+	}
+}
+
+func TestSpamFingerprintRepository(t *testing.T) {
+	store := setupTestStore(t)
+	t.Cleanup(func() { _ = store.Close() })
+	ctx := context.Background()
+	fps := store.SpamFingerprint()
+
+	now := time.Now().UTC().Truncate(time.Second)
+	confirm := func(hash string, expires time.Time) {
+		t.Helper()
+		if err := fps.Confirm(ctx, &domain.SpamFingerprint{Hash: hash, Sample: "sample " + hash, CreatedAt: now, LastSeenAt: now, ExpiresAt: expires}); err != nil {
+			t.Fatalf("confirm %s: %v", hash, err)
+		}
+	}
+	confirm("aaa", now.Add(time.Hour))
+	confirm("aaa", now.Add(2*time.Hour))
+	confirm("bbb", now.Add(-time.Hour))
+
+	fp, err := fps.Get(ctx, "aaa")
+	if err != nil || fp == nil || fp.Confirmations != 2 || fp.Hits != 0 || !fp.ExpiresAt.Equal(now.Add(2*time.Hour)) {
+		t.Fatalf("expected aaa confirmed twice and extended, got %+v, %v", fp, err)
+	}
+	if fp, err := fps.Get(ctx, "missing"); err != nil || fp != nil {
+		t.Errorf("expected no fingerprint, got %+v, %v", fp, err)
+	}
+
+	for _, expires := range []time.Time{now.Add(time.Hour), now.Add(-time.Hour)} {
+		if err := fps.Hit(ctx, "bbb", now, expires); err != nil {
+			t.Fatalf("hit: %v", err)
+		}
+	}
+	list, total, err := fps.List(ctx, 10, 0)
+	if err != nil || total != 2 || list[0].Hash != "bbb" || list[0].Hits != 2 || list[0].Sample != "sample bbb" {
+		t.Fatalf("expected bbb first by hits, got %v, %d, %v", list, total, err)
+	}
+
+	if n, err := fps.DeleteExpired(ctx, now); err != nil || n != 1 {
+		t.Fatalf("expected bbb pruned, got %d, %v", n, err)
+	}
+	if found, err := fps.Delete(ctx, "aaa"); err != nil || !found {
+		t.Errorf("expected aaa deleted, got %v, %v", found, err)
+	}
+	if found, _ := fps.Delete(ctx, "aaa"); found {
+		t.Error("expected nothing left to delete")
+	}
+}
+
+func TestTeamRepository(t *testing.T) {
+	store := setupTestStore(t)
+	t.Cleanup(func() { _ = store.Close() })
+
+	ctx := context.Background()
+	now := time.Now().UTC()
+	for _, id := range []string{"u-1", "u-2"} {
+		_ = store.User().Create(ctx, &domain.User{ID: id, Email: id + "@example.com", PasswordHash: "x", CreatedAt: now, UpdatedAt: now})
+	}
+	if err := store.Team().Create(ctx, &domain.Team{ID: "t-1", Name: "Support", CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	_ = store.Team().SetMember(ctx, &domain.TeamMember{TeamID: "t-1", UserID: "u-1", Role: domain.TeamRoleOwner, AddedAt: now})
+	_ = store.Team().SetMember(ctx, &domain.TeamMember{TeamID: "t-1", UserID: "u-2", Role: domain.TeamRoleViewer, AddedAt: now})
+	if err := store.Team().SetMember(ctx, &domain.TeamMember{TeamID: "t-1", UserID: "u-2", Role: domain.TeamRoleEditor, AddedAt: now}); err != nil {
+		t.Fatalf("SetMember on an existing member failed: %v", err)
+	}
+
+	team, err := store.Team().GetByID(ctx, "t-1")
+	if err != nil || team == nil {
+		t.Fatalf("GetByID failed: %v", err)
+	}
+	if len(team.Members) != 2 || team.Member("u-2").Role != domain.TeamRoleEditor || team.Member("u-2").Email != "u-2@example.com" {
+		t.Fatalf("unexpected members %+v", team.Members)
+	}
+	if roles, _ := store.Team().Roles(ctx, "u-2"); roles["t-1"] != domain.TeamRoleEditor {
+		t.Errorf("expected u-2 to be an editor, got %v", roles)
+	}
+
+	// Forms shared with the user's teams are listed alongside their own
+	for _, f := range []*domain.Form{
+		{ID: "f-1", PublicID: "p-1", Name: "Own", OwnerID: "u-2", CreatedAt: now},
+		{ID: "f-2", PublicID: "p-2", Name: "Shared", OwnerID: "u-1", TeamID: "t-1", CreatedAt: now},
+		{ID: "f-3", PublicID: "p-3", Name: "Private", OwnerID: "u-1", CreatedAt: now},
+	} {
+		if err := store.Form().Create(ctx, f); err != nil {
+			t.Fatalf("create form: %v", err)
+		}
+	}
+	_, total, err := store.Form().ListPaginated(ctx, domain.FormListQuery{OwnerID: "u-2", TeamIDs: []string{"t-1"}, Sort: domain.FormSortCreatedAt}, 10, 0)
+	if err != nil || total != 2 {
+		t.Errorf("expected own and shared forms, got %d (err=%v)", total, err)
+	}
+	if forms, _, _ := store.Form().ListPaginated(ctx, domain.FormListQuery{TeamID: "t-1"}, 10, 0); len(forms) != 1 || forms[0].TeamID != "t-1" {
+		t.Errorf("expected the shared form alone, got %+v", forms)
+	}
+
+	if found, err := store.Team().RemoveMember(ctx, "t-1", "u-2"); err != nil || !found {
+		t.Errorf("RemoveMember failed: found=%v err=%v", found, err)
+	}
+	if err := store.Team().Delete(ctx, "t-1"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if f, _ := store.Form().GetByID(ctx, "f-2"); f.TeamID != "" {
+		t.Errorf("expected the form to be unshared, got team %q", f.TeamID)
+	}
+	if roles, _ := store.Team().Roles(ctx, "u-1"); len(roles) != 0 {
+		t.Errorf("expected memberships to go with the team, got %v", roles)
+	}
+}
+
+func TestSubmissionRepository_SearchByFormID(t *testing.T) {
+	store := setupTestStore(t)
+	t.Cleanup(func() { _ = store.Close() })
+	ctx := context.Background()
+	form := &domain.Form{ID: "search-form", PublicID: "search-public", Name: "Search", NotifyEmails: []string{}, AllowedOrigins: []string{"*"}, CreatedAt: time.Now()}
+	if err := store.Form().Create(ctx, form); err != nil {
+		t.Fatalf("create form: %v", err)
+	}
+	day := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	for _, sub := range []*domain.Submission{
+		{ID: "berlin", Status: domain.SubmissionStatusRead, Data: []byte(`{"city":"Berlin","plan":"pro","seats":3,"tags":["Urgent"]}`), CreatedAt: day},
+		{ID: "paris", Status: domain.SubmissionStatusUnread, Data: []byte(`{"city":"Paris","plan":"free","seats":1,"beta":true}`), CreatedAt: day.AddDate(0, 0, 1)},
+		{ID: "spam", Status: domain.SubmissionStatusSpam, Data: []byte(`{"city":"100%_off","plan":"pro"}`), CreatedAt: day.AddDate(0, 0, 2),
+			Meta: domain.SubmissionMeta{Spam: &domain.SpamMeta{IsSpam: true}}},
+	} {
+		sub.FormID = form.ID
+		if err := store.Submission().Create(ctx, sub); err != nil {
+			t.Fatalf("create %s: %v", sub.ID, err)
+		}
+	}
+
+	cases := []struct {
+		name  string
+		query domain.SubmissionListQuery
+		want  []string
+	}{
+		{"everything", domain.SubmissionListQuery{}, []string{"spam", "paris", "berlin"}},
+		{"search is case-insensitive", domain.SubmissionListQuery{Search: "bERL"}, []string{"berlin"}},
+		{"search looks into arrays", domain.SubmissionListQuery{Search: "urgent"}, []string{"berlin"}},
+		{"search matches numbers", domain.SubmissionListQuery{Search: "3"}, []string{"berlin"}},
+		{"search escapes wildcards", domain.SubmissionListQuery{Search: "%_"}, []string{"spam"}},
+		{"status", domain.SubmissionListQuery{Status: "unread"}, []string{"paris"}},
+		{"spam", domain.SubmissionListQuery{Status: domain.SubmissionListSpam}, []string{"spam"}},
+		{"inbox", domain.SubmissionListQuery{Status: domain.SubmissionListInbox}, []string{"paris", "berlin"}},
+		{"date range", domain.SubmissionListQuery{From: day.AddDate(0, 0, 1), To: day.AddDate(0, 0, 2)}, []string{"paris"}},
+		{"field equals text", domain.SubmissionListQuery{Fields: map[string]string{"plan": "pro"}}, []string{"spam", "berlin"}},
+		{"field equals number", domain.SubmissionListQuery{Fields: map[string]string{"seats": "1"}}, []string{"paris"}},
+		{"field equals boolean", domain.SubmissionListQuery{Fields: map[string]string{"beta": "true"}}, []string{"paris"}},
+		{"filters combine", domain.SubmissionListQuery{Status: "read", Fields: map[string]string{"plan": "pro"}, Search: "berlin"}, []string{"berlin"}},
+		{"missing field", domain.SubmissionListQuery{Fields: map[string]string{"nope": ""}}, nil},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			subs, counts, err := store.Submission().SearchByFormID(ctx, form.ID, "", tc.query, 10, 0)
+			if err != nil {
+				t.Fatalf("search: %v", err)
+			}
+			var got []string
+			for _, sub := range subs {
+				got = append(got, sub.ID)
+			}
+			if !slices.Equal(got, tc.want) || counts.Total != len(tc.want) {
+				t.Errorf("got %v (total %d), want %v", got, counts.Total, tc.want)
+			}
+			// The in-memory filter agrees
+			for _, sub := range subs {
+				if !tc.query.Matches(sub) {
+					t.Errorf("Matches rejects %s", sub.ID)
+				}
+			}
+		})
+	}
+}
+
+func TestSubmissionRepository_EachByFormID(t *testing.T) {
+	store := setupTestStore(t)
+	t.Cleanup(func() { _ = store.Close() })
+	ctx := context.Background()
+	form := &domain.Form{ID: "each-form", PublicID: "each-public", Name: "Each", NotifyEmails: []string{}, AllowedOrigins: []string{"*"}, CreatedAt: time.Now()}
+	if err := store.Form().Create(ctx, form); err != nil {
+		t.Fatalf("create form: %v", err)
+	}
+
+	// More than one chunk, with rows sharing a created_at across the chunk boundary
+	const n = eachChunkSize + 20
+	start := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	err := store.Tx(ctx, func(repo ports.Repository) error {
+		for i := 0; i < n; i++ {
+			sub := &domain.Submission{ID: fmt.Sprintf("sub-%04d", i), FormID: form.ID, Data: []byte(`{}`), CreatedAt: start.Add(time.Duration(i/10) * time.Second)}
+			if err := repo.Submission().Create(ctx, sub); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("create submissions: %v", err)
+	}
+
+	var ids []string
+	if err := store.Submission().EachByFormID(ctx, form.ID, func(s *domain.Submission) error {
+		ids = append(ids, s.ID)
+		return nil
+	}); err != nil {
+		t.Fatalf("each: %v", err)
+	}
+	if len(ids) != n || ids[0] != fmt.Sprintf("sub-%04d", n-1) || ids[n-1] != "sub-0000" {
+		t.Fatalf("expected %d submissions newest first, got %d (%v ... %v)", n, len(ids), ids[:1], ids[len(ids)-1:])
+	}
+	if !slices.IsSortedFunc(ids, func(a, b string) int { return strings.Compare(b, a) }) {
+		t.Error("expected submissions newest first without repeats")
+	}
+
+	stop := errors.New("stop")
+	count := 0
+	err = store.Submission().EachByFormID(ctx, form.ID, func(s *domain.Submission) error {
+		count++
+		if count == 3 {
+			return stop
+		}
+		return nil
+	})
+	if !errors.Is(err, stop) || count != 3 {
+		t.Errorf("expected iteration to stop at the callback's error, got %v after %d", err, count)
+	}
+}
+
+// TestStore_SealSecrets verifies stored plaintext secrets are encrypted and stay readable
+func TestStore_SealSecrets(t *testing.T) {
+	store := setupTestStore(t)
+	t.Cleanup(func() { _ = store.Close() })
+	ctx := context.Background()
+
+	form := &domain.Form{ID: "sealed-form", PublicID: "sealed-public", Name: "Sealed", NotifyEmails: []string{}, AllowedOrigins: []string{"*"}, WebhookSecret: "whsec", SubmissionKey: "sk_form", CreatedAt: time.Now()}
+	form.Captcha = &domain.CaptchaConfig{Provider: domain.CaptchaTurnstile, Secret: "captcha-secret"}
+	form.Channels = []domain.NotifyChannel{{Type: domain.ChannelSlack, URL: "https://hooks.slack.com/services/T0/B0/slack-token"}}
+	if err := store.Form().Create(ctx, form); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	oauth := &domain.OAuthSettings{Providers: []domain.OAuthProvider{{ID: "google", Type: domain.OAuthGoogle, ClientID: "client", ClientSecret: "client-secret"}}}
+	if err := store.Settings().Save(ctx, &domain.SiteSettings{SMTPPassword: "smtp-pass", SpamProviderKey: "vault:secret/data/forms#akismet", OAuth: oauth}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	codec, err := secrets.New(secrets.Config{Key: strings.Repeat("k", secrets.MinKeyLength)})
+	if err != nil {
+		t.Fatalf("secrets.New: %v", err)
+	}
+	store.SetSecretCodec(codec)
+	sealed, err := store.SealSecrets(ctx)
+	if err != nil {
+		t.Fatalf("SealSecrets: %v", err)
+	}
+	if sealed != 6 {
+		t.Errorf("expected the webhook secret, submission key, captcha secret, channel URL, SMTP password and client secret sealed, got %d", sealed)
+	}
+	if again, _ := store.SealSecrets(ctx); again != 0 {
+		t.Errorf("expected nothing left to seal, got %d", again)
+	}
+
+	var stored, reference string
+	_ = store.db.QueryRow(`SELECT smtp_password, spam_provider_key FROM site_settings WHERE id = 'default'`).Scan(&stored, &reference)
+	if !strings.HasPrefix(stored, "enc:v1:") {
+		t.Errorf("expected the SMTP password encrypted at rest, got %q", stored)
+	}
+	if reference != "vault:secret/data/forms#akismet" {
+		t.Errorf("expected the reference stored as it is, got %q", reference)
+	}
+	var oauthStored string
+	_ = store.db.QueryRow(`SELECT oauth FROM site_settings WHERE id = 'default'`).Scan(&oauthStored)
+	if strings.Contains(oauthStored, "client-secret") || !strings.Contains(oauthStored, "enc:v1:") {
+		t.Errorf("expected the client secret encrypted at rest, got %s", oauthStored)
+	}
+
+	formSealed := func() {
+		t.Helper()
+		var key, captcha, channels string
+		_ = store.db.QueryRow(`SELECT submission_key, captcha, channels FROM forms WHERE id = 'sealed-form'`).Scan(&key, &captcha, &channels)
+		for _, c := range []struct{ stored, secret string }{{key, "sk_form"}, {captcha, "captcha-secret"}, {channels, "slack-token"}} {
+			if strings.Contains(c.stored, c.secret) || !strings.Contains(c.stored, "enc:v1:") {
+				t.Errorf("expected %s encrypted at rest, got %s", c.secret, c.stored)
+			}
+		}
+	}
+	formSealed()
+
+	got, err := store.Form().GetByID(ctx, "sealed-form")
+	if err != nil || got.WebhookSecret != "whsec" {
+		t.Fatalf("expected the webhook secret decrypted on read, got %q (%v)", got.WebhookSecret, err)
+	}
+	if got.SubmissionKey != "sk_form" || got.Captcha == nil || got.Captcha.Secret != "captcha-secret" || len(got.Channels) != 1 || got.Channels[0].URL != form.Channels[0].URL {
+		t.Errorf("expected the submission key, captcha secret and channel URL decrypted on read, got %q %+v %+v", got.SubmissionKey, got.Captcha, got.Channels)
+	}
+
+	// References are left for the webhook service to resolve, and stay references on update
+	got.WebhookSecret = "vault:secret/data/forms#webhook"
+	if err := store.Form().Update(ctx, got); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if got, err = store.Form().GetByID(ctx, "sealed-form"); err != nil || got.WebhookSecret != "vault:secret/data/forms#webhook" {
+			t.Fatalf("expected the reference kept, got %q (%v)", got.WebhookSecret, err)
+		}
+		if err := store.Form().Update(ctx, got); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+	}
+	formSealed()
+}
+
+func TestUnavailable(t *testing.T) {
+	store := setupTestStore(t)
+	t.Cleanup(func() { _ = store.Close() })
+	ctx := context.Background()
+
+	// Refused data isn't an outage
+	form := &domain.Form{ID: "f1", PublicID: "p1", Name: "Form", CreatedAt: time.Now()}
+	_ = store.Form().Create(ctx, form)
+	sub := &domain.Submission{ID: "s1", FormID: form.ID, Status: domain.SubmissionStatusUnread, Data: json.RawMessage(`{}`), CreatedAt: time.Now()}
+	if err := store.Submission().Create(ctx, sub); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := store.Submission().Create(ctx, sub); err == nil || errors.Is(err, domain.ErrStoreUnavailable) {
+		t.Errorf("expected a duplicate refused without being an outage, got %v", err)
+	}
+
+	// A locked database is
+	path := t.TempDir() + "/locked.db"
+	holder, _ := sql.Open("sqlite", path)
+	other, _ := sql.Open("sqlite", path)
+	t.Cleanup(func() { _ = holder.Close(); _ = other.Close() })
+	if _, err := holder.Exec(`CREATE TABLE t (n INTEGER)`); err != nil {
+		t.Fatal(err)
+	}
+	tx, err := holder.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	if _, err := tx.Exec(`INSERT INTO t VALUES (1)`); err != nil {
+		t.Fatal(err)
+	}
+	_, err = other.Exec(`INSERT INTO t VALUES (2)`)
+	if err == nil || !errors.Is(unavailable(err), domain.ErrStoreUnavailable) {
+		t.Errorf("expected a locked database to be an outage, got %v", err)
+	}
+	if !errors.Is(unavailable(fmt.Errorf("insert: %w", context.DeadlineExceeded)), domain.ErrStoreUnavailable) {
+		t.Error("expected a timeout to be an outage")
+	}
+}
@@ -6,30 +6,38 @@ import (
 	"encoding/json"
 	"fmt"
 	"headless_form/internal/core/domain"
+	"sort"
+	"strings"
+	"time"
 )
 
 type SubmissionRepository struct {
-	db *sql.DB
+	db dbtx
 }
 
 func (r *SubmissionRepository) Create(ctx context.Context, s *domain.Submission) error {
-	query := `INSERT INTO submissions (id, form_id, status, data, meta, created_at) VALUES (?, ?, ?, ?, ?, ?)`
+	query := `INSERT INTO submissions (id, form_id, status, data, meta, is_test, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)`
 
-	_, err := r.db.ExecContext(ctx, query,
-		s.ID, s.FormID, s.Status, string(s.Data), string(s.Meta), s.CreatedAt,
+	metaBytes, err := json.Marshal(s.Meta)
+	if err != nil {
+		return fmt.Errorf("marshal meta: %w", err)
+	}
+
+	_, err = r.db.ExecContext(ctx, query,
+		s.ID, s.FormID, s.Status, string(s.Data), string(metaBytes), s.IsTest, s.CreatedAt,
 	)
-	return err
+	return unavailable(err)
 }
 
 func (r *SubmissionRepository) GetByID(ctx context.Context, id string) (*domain.Submission, error) {
-	query := `SELECT id, form_id, COALESCE(status, 'unread'), data, meta, created_at FROM submissions WHERE id = ?`
+	query := `SELECT id, form_id, COALESCE(status, 'unread'), data, meta, COALESCE(is_test, 0), created_at FROM submissions WHERE id = ?`
 
 	row := r.db.QueryRowContext(ctx, query, id)
 
 	var s domain.Submission
 	var dataRaw, metaRaw []byte
 
-	if err := row.Scan(&s.ID, &s.FormID, &s.Status, &dataRaw, &metaRaw, &s.CreatedAt); err != nil {
+	if err := row.Scan(&s.ID, &s.FormID, &s.Status, &dataRaw, &metaRaw, &s.IsTest, &s.CreatedAt); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
@@ -37,13 +45,13 @@ func (r *SubmissionRepository) GetByID(ctx context.Context, id string) (*domain.
 	}
 
 	s.Data = json.RawMessage(dataRaw)
-	s.Meta = json.RawMessage(metaRaw)
+	s.Meta = decodeMeta(metaRaw)
 
 	return &s, nil
 }
 
 func (r *SubmissionRepository) GetByFormID(ctx context.Context, formID string) ([]*domain.Submission, error) {
-	query := `SELECT id, form_id, COALESCE(status, 'unread'), data, meta, created_at FROM submissions WHERE form_id = ? ORDER BY created_at DESC`
+	query := `SELECT id, form_id, COALESCE(status, 'unread'), data, meta, COALESCE(is_test, 0), created_at FROM submissions WHERE form_id = ? ORDER BY created_at DESC`
 
 	rows, err := r.db.QueryContext(ctx, query, formID)
 	if err != nil {
@@ -56,37 +64,314 @@ func (r *SubmissionRepository) GetByFormID(ctx context.Context, formID string) (
 		var s domain.Submission
 		var dataRaw, metaRaw []byte
 
-		if err := rows.Scan(&s.ID, &s.FormID, &s.Status, &dataRaw, &metaRaw, &s.CreatedAt); err != nil {
+		if err := rows.Scan(&s.ID, &s.FormID, &s.Status, &dataRaw, &metaRaw, &s.IsTest, &s.CreatedAt); err != nil {
 			return nil, err
 		}
 		s.Data = json.RawMessage(dataRaw)
-		s.Meta = json.RawMessage(metaRaw)
+		s.Meta = decodeMeta(metaRaw)
 		submissions = append(submissions, &s)
 	}
 	return submissions, nil
 }
 
+// eachChunkSize is how many submissions EachByFormID reads per query
+const eachChunkSize = 500
+
+// EachByFormID pages through the form's submissions by (created_at, id) rather than holding
+// one cursor open, so a slow consumer doesn't keep a read transaction open for the whole export
+func (r *SubmissionRepository) EachByFormID(ctx context.Context, formID string, fn func(*domain.Submission) error) error {
+	var afterCreated, afterID string
+	for {
+		query := `SELECT id, form_id, COALESCE(status, 'unread'), data, meta, COALESCE(is_test, 0), created_at, CAST(created_at AS TEXT) FROM submissions WHERE form_id = ?`
+		args := []interface{}{formID}
+		if afterID != "" {
+			query += ` AND (CAST(created_at AS TEXT) < ? OR (CAST(created_at AS TEXT) = ? AND id < ?))`
+			args = append(args, afterCreated, afterCreated, afterID)
+		}
+		query += ` ORDER BY CAST(created_at AS TEXT) DESC, id DESC LIMIT ?`
+		args = append(args, eachChunkSize)
+
+		chunk, err := r.scanChunk(ctx, query, args, &afterCreated)
+		if err != nil {
+			return err
+		}
+		for _, s := range chunk {
+			if err := fn(s); err != nil {
+				return err
+			}
+		}
+		if len(chunk) < eachChunkSize {
+			return nil
+		}
+		afterID = chunk[len(chunk)-1].ID
+	}
+}
+
+// scanChunk reads one EachByFormID chunk, leaving the last row's raw created_at in lastCreated
+func (r *SubmissionRepository) scanChunk(ctx context.Context, query string, args []interface{}, lastCreated *string) ([]*domain.Submission, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var chunk []*domain.Submission
+	for rows.Next() {
+		var s domain.Submission
+		var dataRaw, metaRaw []byte
+		if err := rows.Scan(&s.ID, &s.FormID, &s.Status, &dataRaw, &metaRaw, &s.IsTest, &s.CreatedAt, lastCreated); err != nil {
+			return nil, err
+		}
+		s.Data = json.RawMessage(dataRaw)
+		s.Meta = decodeMeta(metaRaw)
+		chunk = append(chunk, &s)
+	}
+	return chunk, rows.Err()
+}
+
 func (r *SubmissionRepository) UpdateStatus(ctx context.Context, id string, status domain.SubmissionStatus) error {
 	_, err := r.db.ExecContext(ctx, `UPDATE submissions SET status = ? WHERE id = ?`, status, id)
 	return err
 }
 
+func (r *SubmissionRepository) UpdateData(ctx context.Context, id string, data json.RawMessage) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE submissions SET data = ? WHERE id = ?`, []byte(data), id)
+	return err
+}
+
 func (r *SubmissionRepository) Delete(ctx context.Context, id string) error {
 	_, err := r.db.ExecContext(ctx, `DELETE FROM submissions WHERE id = ?`, id)
 	return err
 }
 
-func (r *SubmissionRepository) GetByFormIDPaginated(ctx context.Context, formID string, limit, offset int) ([]*domain.Submission, int, error) {
-	// Get total count
-	var total int
-	_ = r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM submissions WHERE form_id = ?`, formID).Scan(&total)
+func (r *SubmissionRepository) DeleteMatching(ctx context.Context, formID string, filter domain.SubmissionFilter) (int, error) {
+	query := `DELETE FROM submissions WHERE form_id = ?`
+	args := []interface{}{formID}
+	if filter.Status != "" {
+		query += ` AND COALESCE(status, 'unread') = ?`
+		args = append(args, filter.Status)
+	}
+	if !filter.Before.IsZero() {
+		query += ` AND substr(created_at, 1, 19) < ?`
+		args = append(args, filter.Before.UTC().Format("2006-01-02 15:04:05"))
+	}
+	if filter.IsTest != nil {
+		query += ` AND COALESCE(is_test, 0) = ?`
+		args = append(args, *filter.IsTest)
+	}
+
+	res, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+// UpdateStatusBatch sets the status of the listed submissions of a form
+func (r *SubmissionRepository) UpdateStatusBatch(ctx context.Context, formID string, ids []string, status domain.SubmissionStatus) (int, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	in, args := inClause(ids)
+	res, err := r.db.ExecContext(ctx, `UPDATE submissions SET status = ? WHERE form_id = ? AND id IN `+in,
+		append([]interface{}{status, formID}, args...)...)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+// SetSpamBatch sets is_spam and manual on the listed submissions' spam result, creating one
+// for submissions that were never scored, and moves them into or out of the spam status
+func (r *SubmissionRepository) SetSpamBatch(ctx context.Context, formID string, ids []string, spam bool) (int, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	flag, status := "false", `CASE WHEN status = 'spam' THEN 'unread' ELSE status END`
+	if spam {
+		flag, status = "true", `'spam'`
+	}
+	in, args := inClause(ids)
+	// #nosec G202 -- flag and status are fixed strings, values are bound
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE submissions SET status = `+status+`, meta = json_set(meta, '$._spam', json_set(
+			COALESCE(json_extract(meta, '$._spam'), json('{"score":0,"flags":[],"threshold":0}')),
+			'$.is_spam', json('`+flag+`'), '$.manual', json('true')))
+		WHERE form_id = ? AND id IN `+in,
+		append([]interface{}{formID}, args...)...)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+// SetSpamVerdict replaces a submission's spam verdict, moving it in or out of the quarantine
+func (r *SubmissionRepository) SetSpamVerdict(ctx context.Context, id string, spam domain.SpamMeta) error {
+	verdict, err := json.Marshal(spam)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.ExecContext(ctx, `
+		UPDATE submissions SET
+			status = CASE WHEN ? THEN 'spam' WHEN status = 'spam' THEN 'unread' ELSE status END,
+			meta = json_set(meta, '$._spam', json(?))
+		WHERE id = ?`,
+		spam.IsSpam, string(verdict), id)
+	return err
+}
+
+// DeleteBatch deletes the listed submissions of a form
+func (r *SubmissionRepository) DeleteBatch(ctx context.Context, formID string, ids []string) (int, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	in, args := inClause(ids)
+	res, err := r.db.ExecContext(ctx, `DELETE FROM submissions WHERE form_id = ? AND id IN `+in,
+		append([]interface{}{formID}, args...)...)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+// ListExpired returns up to limit of the form's submissions created before the given
+// time that weren't anonymized yet, oldest first
+func (r *SubmissionRepository) ListExpired(ctx context.Context, formID string, before time.Time, limit int) ([]*domain.Submission, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id, form_id, COALESCE(status, 'unread'), data, meta, COALESCE(is_test, 0), created_at FROM submissions
+		WHERE form_id = ? AND substr(created_at, 1, 19) < ? AND json_extract(meta, '$._anonymized_at') IS NULL
+		ORDER BY created_at LIMIT ?`, formID, before.UTC().Format("2006-01-02 15:04:05"), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var submissions []*domain.Submission
+	for rows.Next() {
+		var s domain.Submission
+		var dataRaw, metaRaw []byte
+		if err := rows.Scan(&s.ID, &s.FormID, &s.Status, &dataRaw, &metaRaw, &s.IsTest, &s.CreatedAt); err != nil {
+			return nil, err
+		}
+		s.Data = json.RawMessage(dataRaw)
+		s.Meta = decodeMeta(metaRaw)
+		submissions = append(submissions, &s)
+	}
+	return submissions, rows.Err()
+}
+
+// Anonymize replaces a submission's data and metadata with their anonymized versions
+func (r *SubmissionRepository) Anonymize(ctx context.Context, id string, data json.RawMessage, meta domain.SubmissionMeta) error {
+	metaBytes, err := json.Marshal(meta)
+	if err != nil {
+		return fmt.Errorf("marshal meta: %w", err)
+	}
+	_, err = r.db.ExecContext(ctx, `UPDATE submissions SET data = ?, meta = ? WHERE id = ?`, []byte(data), string(metaBytes), id)
+	return err
+}
+
+// inClause returns "(?, ?, ...)" with one placeholder per value, and the values as arguments
+func inClause(values []string) (string, []interface{}) {
+	args := make([]interface{}, len(values))
+	for i, v := range values {
+		args[i] = v
+	}
+	return "(?" + strings.Repeat(", ?", len(values)-1) + ")", args
+}
+
+// DeleteTestBefore removes test submissions of every form created before the given time
+// and returns their tombstones
+func (r *SubmissionRepository) DeleteTestBefore(ctx context.Context, before time.Time) ([]domain.Tombstone, error) {
+	rows, err := r.db.QueryContext(ctx, `DELETE FROM submissions WHERE COALESCE(is_test, 0) = 1 AND substr(created_at, 1, 19) < ?
+		RETURNING id, form_id, created_at`, before.UTC().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var deleted []domain.Tombstone
+	for rows.Next() {
+		t := domain.Tombstone{IsTest: true}
+		if err := rows.Scan(&t.SubmissionID, &t.FormID, &t.CreatedAt); err != nil {
+			return nil, err
+		}
+		deleted = append(deleted, t)
+	}
+	return deleted, rows.Err()
+}
+
+func (r *SubmissionRepository) GetByFormIDPaginated(ctx context.Context, formID, viewerID string, limit, offset int) ([]*domain.Submission, domain.SubmissionCounts, error) {
+	return r.SearchByFormID(ctx, formID, viewerID, domain.SubmissionListQuery{}, limit, offset)
+}
+
+// SearchByFormID filters with JSON1 functions so only the form's rows in the date range,
+// found through the (form_id, created_at) index, are looked into
+func (r *SubmissionRepository) SearchByFormID(ctx context.Context, formID, viewerID string, q domain.SubmissionListQuery, limit, offset int) ([]*domain.Submission, domain.SubmissionCounts, error) {
+	where := []string{"form_id = ?"}
+	args := []interface{}{formID}
+	switch q.Status {
+	case domain.SubmissionListInbox:
+		where = append(where, `COALESCE(status, 'unread') != 'spam'`)
+	case string(domain.SubmissionStatusRead), string(domain.SubmissionStatusUnread), domain.SubmissionListSpam:
+		where = append(where, `COALESCE(status, 'unread') = ?`)
+		args = append(args, q.Status)
+	}
+	if !q.From.IsZero() {
+		where = append(where, `substr(created_at, 1, 19) >= ?`)
+		args = append(args, q.From.UTC().Format("2006-01-02 15:04:05"))
+	}
+	if !q.To.IsZero() {
+		where = append(where, `substr(created_at, 1, 19) < ?`)
+		args = append(args, q.To.UTC().Format("2006-01-02 15:04:05"))
+	}
+	// Sorted so the statement is the same for the same filters
+	names := make([]string, 0, len(q.Fields))
+	for name := range q.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		where = append(where, `CASE json_type(data, ?) WHEN 'text' THEN json_extract(data, ?)
+			WHEN 'integer' THEN CAST(json_extract(data, ?) AS TEXT) WHEN 'real' THEN CAST(json_extract(data, ?) AS TEXT)
+			WHEN 'true' THEN 'true' WHEN 'false' THEN 'false' END = ?`)
+		path := `$."` + name + `"`
+		args = append(args, path, path, path, path, q.Fields[name])
+	}
+	if q.Search != "" {
+		where = append(where, `EXISTS (SELECT 1 FROM json_tree(submissions.data)
+			WHERE type IN ('text', 'integer', 'real') AND CAST(atom AS TEXT) LIKE ? ESCAPE '\')`)
+		args = append(args, "%"+likeEscaper.Replace(q.Search)+"%")
+	}
+	whereSQL := strings.Join(where, " AND ")
+
+	// Total, per-status and the viewer's unopened counts in one pass
+	var counts domain.SubmissionCounts
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*),
+			COALESCE(SUM(CASE WHEN COALESCE(status, 'unread') = 'unread' THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN status = 'read' THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN status = 'spam' THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN v.submission_id IS NULL THEN 1 ELSE 0 END), 0)
+		FROM submissions
+		LEFT JOIN submission_views v ON v.submission_id = submissions.id AND v.user_id = ?
+		WHERE `+whereSQL, append([]interface{}{viewerID}, args...)...).Scan(&counts.Total, &counts.Unread, &counts.Read, &counts.Spam, &counts.Unopened)
+	if err != nil {
+		return nil, counts, err
+	}
 
 	// Get paginated submissions
-	query := `SELECT id, form_id, COALESCE(status, 'unread'), data, meta, created_at FROM submissions WHERE form_id = ? ORDER BY created_at DESC LIMIT ? OFFSET ?`
+	// #nosec G202 -- the conditions are fixed strings, values are bound
+	query := `SELECT id, form_id, COALESCE(status, 'unread'), data, meta, COALESCE(is_test, 0), created_at,
+		EXISTS(SELECT 1 FROM submission_views v WHERE v.submission_id = submissions.id AND v.user_id = ?)
+		FROM submissions WHERE ` + whereSQL + ` ORDER BY created_at DESC LIMIT ? OFFSET ?`
 
-	rows, err := r.db.QueryContext(ctx, query, formID, limit, offset)
+	queryArgs := append([]interface{}{viewerID}, args...)
+	rows, err := r.db.QueryContext(ctx, query, append(queryArgs, limit, offset)...)
 	if err != nil {
-		return nil, 0, err
+		return nil, counts, err
 	}
 	defer func() { _ = rows.Close() }()
 
@@ -95,12 +380,89 @@ func (r *SubmissionRepository) GetByFormIDPaginated(ctx context.Context, formID
 		var s domain.Submission
 		var dataRaw, metaRaw []byte
 
-		if err := rows.Scan(&s.ID, &s.FormID, &s.Status, &dataRaw, &metaRaw, &s.CreatedAt); err != nil {
-			return nil, 0, err
+		if err := rows.Scan(&s.ID, &s.FormID, &s.Status, &dataRaw, &metaRaw, &s.IsTest, &s.CreatedAt, &s.Opened); err != nil {
+			return nil, counts, err
 		}
 		s.Data = json.RawMessage(dataRaw)
-		s.Meta = json.RawMessage(metaRaw)
+		s.Meta = decodeMeta(metaRaw)
 		submissions = append(submissions, &s)
 	}
-	return submissions, total, nil
+	return submissions, counts, nil
+}
+
+func (r *SubmissionRepository) RecordView(ctx context.Context, submissionID, userID string, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO submission_views (submission_id, user_id, first_viewed_at, last_viewed_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT(submission_id, user_id) DO UPDATE SET last_viewed_at = excluded.last_viewed_at`,
+		submissionID, userID, at.UTC(), at.UTC())
+	return err
+}
+
+func (r *SubmissionRepository) ListViews(ctx context.Context, submissionID string) ([]domain.SubmissionView, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT v.user_id, COALESCE(u.name, ''), COALESCE(u.email, ''), v.first_viewed_at, v.last_viewed_at
+		FROM submission_views v LEFT JOIN users u ON u.id = v.user_id
+		WHERE v.submission_id = ? ORDER BY v.last_viewed_at DESC`, submissionID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var views []domain.SubmissionView
+	for rows.Next() {
+		var v domain.SubmissionView
+		if err := rows.Scan(&v.UserID, &v.Name, &v.Email, &v.FirstViewedAt, &v.LastViewedAt); err != nil {
+			return nil, err
+		}
+		views = append(views, v)
+	}
+	return views, rows.Err()
+}
+
+func (r *SubmissionRepository) Link(ctx context.Context, link *domain.SubmissionLink) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO submission_links (submission_id, linked_id, source, created_by, created_at) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(submission_id, linked_id) DO NOTHING`,
+		link.SubmissionID, link.LinkedID, string(link.Source), sql.NullString{String: link.CreatedBy, Valid: link.CreatedBy != ""}, link.CreatedAt.UTC())
+	return err
+}
+
+func (r *SubmissionRepository) Unlink(ctx context.Context, submissionID, linkedID string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM submission_links WHERE submission_id = ? AND linked_id = ?`, submissionID, linkedID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (r *SubmissionRepository) ListLinks(ctx context.Context, submissionID string) ([]domain.SubmissionLink, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT submission_id, linked_id, source, COALESCE(created_by, ''), created_at
+		FROM submission_links WHERE submission_id = ? OR linked_id = ? ORDER BY created_at ASC`, submissionID, submissionID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var links []domain.SubmissionLink
+	for rows.Next() {
+		var l domain.SubmissionLink
+		var source string
+		if err := rows.Scan(&l.SubmissionID, &l.LinkedID, &source, &l.CreatedBy, &l.CreatedAt); err != nil {
+			return nil, err
+		}
+		l.Source = domain.LinkSource(source)
+		links = append(links, l)
+	}
+	return links, rows.Err()
+}
+
+// decodeMeta parses stored meta, tolerating empty or legacy rows
+func decodeMeta(raw []byte) domain.SubmissionMeta {
+	var meta domain.SubmissionMeta
+	if len(raw) > 0 {
+		_ = json.Unmarshal(raw, &meta)
+	}
+	return meta
 }
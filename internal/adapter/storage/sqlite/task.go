@@ -0,0 +1,117 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"headless_form/internal/core/domain"
+)
+
+// TaskRepository implements background task storage in SQLite
+type TaskRepository struct {
+	db dbtx
+}
+
+const taskColumns = `id, type, status, progress, message, result, error, file, created_by, created_at, started_at, finished_at`
+
+func (r *TaskRepository) Create(ctx context.Context, t *domain.Task) error {
+	_, err := r.db.ExecContext(ctx, `INSERT INTO tasks (`+taskColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		t.ID, t.Type, t.Status, t.Progress, t.Message, nullableJSON(t.Result), t.Error, taskFileJSON(t.File), t.CreatedBy, t.CreatedAt, t.StartedAt, t.FinishedAt)
+	return err
+}
+
+func (r *TaskRepository) Update(ctx context.Context, t *domain.Task) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE tasks SET status = ?, progress = ?, message = ?, result = ?, error = ?, file = ?, started_at = ?, finished_at = ?
+		WHERE id = ?
+	`, t.Status, t.Progress, t.Message, nullableJSON(t.Result), t.Error, taskFileJSON(t.File), t.StartedAt, t.FinishedAt, t.ID)
+	return err
+}
+
+func (r *TaskRepository) GetByID(ctx context.Context, id string) (*domain.Task, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+taskColumns+` FROM tasks WHERE id = ?`, id)
+	t, err := scanTask(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("scan task: %w", err)
+	}
+	return t, nil
+}
+
+func (r *TaskRepository) FailUnfinished(ctx context.Context, reason string, at time.Time) (int, error) {
+	res, err := r.db.ExecContext(ctx, `UPDATE tasks SET status = ?, error = ?, finished_at = ? WHERE status IN (?, ?)`,
+		domain.TaskFailed, reason, at, domain.TaskPending, domain.TaskRunning)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+func (r *TaskRepository) ListWithFiles(ctx context.Context) ([]*domain.Task, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+taskColumns+` FROM tasks WHERE file IS NOT NULL`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tasks []*domain.Task
+	for rows.Next() {
+		t, err := scanTask(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan task: %w", err)
+		}
+		tasks = append(tasks, t)
+	}
+	return tasks, rows.Err()
+}
+
+// scanTask scans a row selected with taskColumns
+func scanTask(row interface{ Scan(...interface{}) error }) (*domain.Task, error) {
+	var t domain.Task
+	var message, result, errMsg, file, createdBy sql.NullString
+	var startedAt, finishedAt sql.NullTime
+	if err := row.Scan(&t.ID, &t.Type, &t.Status, &t.Progress, &message, &result, &errMsg, &file, &createdBy, &t.CreatedAt, &startedAt, &finishedAt); err != nil {
+		return nil, err
+	}
+	t.Message = message.String
+	t.Error = errMsg.String
+	t.CreatedBy = createdBy.String
+	if result.Valid && result.String != "" {
+		t.Result = []byte(result.String)
+	}
+	if file.Valid && file.String != "" {
+		if err := json.Unmarshal([]byte(file.String), &t.File); err != nil {
+			return nil, fmt.Errorf("decode task file: %w", err)
+		}
+	}
+	if startedAt.Valid {
+		t.StartedAt = &startedAt.Time
+	}
+	if finishedAt.Valid {
+		t.FinishedAt = &finishedAt.Time
+	}
+	return &t, nil
+}
+
+// taskFileJSON stores a task's file as JSON, or NULL without one
+func taskFileJSON(f *domain.TaskFile) interface{} {
+	if f == nil {
+		return nil
+	}
+	raw, _ := json.Marshal(f)
+	return string(raw)
+}
+
+// nullableJSON stores empty JSON as NULL
+func nullableJSON(raw []byte) interface{} {
+	if len(raw) == 0 {
+		return nil
+	}
+	return string(raw)
+}
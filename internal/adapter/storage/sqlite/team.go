@@ -0,0 +1,127 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"headless_form/internal/core/domain"
+)
+
+// TeamRepository implements team storage in SQLite
+type TeamRepository struct {
+	db dbtx
+}
+
+func (r *TeamRepository) Create(ctx context.Context, t *domain.Team) error {
+	_, err := r.db.ExecContext(ctx, `INSERT INTO teams (id, name, created_at, updated_at) VALUES (?, ?, ?, ?)`,
+		t.ID, t.Name, t.CreatedAt, t.UpdatedAt)
+	return err
+}
+
+func (r *TeamRepository) Update(ctx context.Context, t *domain.Team) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE teams SET name = ?, updated_at = ? WHERE id = ?`, t.Name, t.UpdatedAt, t.ID)
+	return err
+}
+
+func (r *TeamRepository) GetByID(ctx context.Context, id string) (*domain.Team, error) {
+	var t domain.Team
+	err := r.db.QueryRowContext(ctx, `SELECT id, name, created_at, updated_at FROM teams WHERE id = ?`, id).
+		Scan(&t.ID, &t.Name, &t.CreatedAt, &t.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("scan team: %w", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT m.user_id, COALESCE(u.email, ''), COALESCE(u.name, ''), m.role, m.added_at
+		FROM team_members m LEFT JOIN users u ON u.id = m.user_id
+		WHERE m.team_id = ? ORDER BY m.added_at, m.user_id
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	t.Members = []domain.TeamMember{}
+	for rows.Next() {
+		m := domain.TeamMember{TeamID: t.ID}
+		if err := rows.Scan(&m.UserID, &m.Email, &m.Name, &m.Role, &m.AddedAt); err != nil {
+			return nil, err
+		}
+		t.Members = append(t.Members, m)
+	}
+	return &t, rows.Err()
+}
+
+func (r *TeamRepository) List(ctx context.Context, userID string) ([]*domain.Team, error) {
+	query := `SELECT id, name, created_at, updated_at, '' FROM teams ORDER BY name COLLATE NOCASE, id`
+	var args []interface{}
+	if userID != "" {
+		query = `SELECT t.id, t.name, t.created_at, t.updated_at, m.role
+			FROM teams t JOIN team_members m ON m.team_id = t.id
+			WHERE m.user_id = ? ORDER BY t.name COLLATE NOCASE, t.id`
+		args = append(args, userID)
+	}
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var teams []*domain.Team
+	for rows.Next() {
+		var t domain.Team
+		if err := rows.Scan(&t.ID, &t.Name, &t.CreatedAt, &t.UpdatedAt, &t.Role); err != nil {
+			return nil, err
+		}
+		teams = append(teams, &t)
+	}
+	return teams, rows.Err()
+}
+
+func (r *TeamRepository) Delete(ctx context.Context, id string) error {
+	if _, err := r.db.ExecContext(ctx, `UPDATE forms SET team_id = NULL WHERE team_id = ?`, id); err != nil {
+		return fmt.Errorf("unshare forms: %w", err)
+	}
+	_, err := r.db.ExecContext(ctx, `DELETE FROM teams WHERE id = ?`, id)
+	return err
+}
+
+func (r *TeamRepository) SetMember(ctx context.Context, m *domain.TeamMember) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO team_members (team_id, user_id, role, added_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT(team_id, user_id) DO UPDATE SET role = excluded.role
+	`, m.TeamID, m.UserID, m.Role, m.AddedAt)
+	return err
+}
+
+func (r *TeamRepository) RemoveMember(ctx context.Context, teamID, userID string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM team_members WHERE team_id = ? AND user_id = ?`, teamID, userID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (r *TeamRepository) Roles(ctx context.Context, userID string) (map[string]domain.TeamRole, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT team_id, role FROM team_members WHERE user_id = ?`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	roles := make(map[string]domain.TeamRole)
+	for rows.Next() {
+		var teamID string
+		var role domain.TeamRole
+		if err := rows.Scan(&teamID, &role); err != nil {
+			return nil, err
+		}
+		roles[teamID] = role
+	}
+	return roles, rows.Err()
+}
@@ -0,0 +1,81 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"headless_form/internal/core/domain"
+)
+
+// FormTokenRepository implements form API token storage in SQLite
+type FormTokenRepository struct {
+	db dbtx
+}
+
+const formTokenColumns = `id, form_id, name, hint, token_hash, created_by, last_used_at, created_at`
+
+func (r *FormTokenRepository) Create(ctx context.Context, t *domain.FormToken) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO form_tokens (id, form_id, name, hint, token_hash, created_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, t.ID, t.FormID, t.Name, t.Hint, t.TokenHash, t.CreatedBy, t.CreatedAt)
+	return err
+}
+
+func (r *FormTokenRepository) GetByHash(ctx context.Context, hash string) (*domain.FormToken, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+formTokenColumns+` FROM form_tokens WHERE token_hash = ?`, hash)
+	t, err := scanFormToken(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("scan form token: %w", err)
+	}
+	return t, nil
+}
+
+func (r *FormTokenRepository) ListByFormID(ctx context.Context, formID string) ([]*domain.FormToken, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+formTokenColumns+` FROM form_tokens WHERE form_id = ? ORDER BY created_at`, formID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var tokens []*domain.FormToken
+	for rows.Next() {
+		t, err := scanFormToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, t)
+	}
+	return tokens, rows.Err()
+}
+
+func (r *FormTokenRepository) MarkUsed(ctx context.Context, id string, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE form_tokens SET last_used_at = ? WHERE id = ?`, at, id)
+	return err
+}
+
+func (r *FormTokenRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM form_tokens WHERE id = ?`, id)
+	return err
+}
+
+// scanFormToken scans a row selected with formTokenColumns
+func scanFormToken(row interface{ Scan(...interface{}) error }) (*domain.FormToken, error) {
+	var t domain.FormToken
+	var hint, createdBy sql.NullString
+	var lastUsed sql.NullTime
+	if err := row.Scan(&t.ID, &t.FormID, &t.Name, &hint, &t.TokenHash, &createdBy, &lastUsed, &t.CreatedAt); err != nil {
+		return nil, err
+	}
+	t.Hint = hint.String
+	t.CreatedBy = createdBy.String
+	if lastUsed.Valid {
+		t.LastUsedAt = &lastUsed.Time
+	}
+	return &t, nil
+}
@@ -7,7 +7,7 @@ import (
 )
 
 type UserRepository struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewUserRepository(db *sql.DB) *UserRepository {
@@ -16,8 +16,8 @@ func NewUserRepository(db *sql.DB) *UserRepository {
 
 func (r *UserRepository) Create(ctx context.Context, user *domain.User) error {
 	query := `
-		INSERT INTO users (id, email, password_hash, name, role, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO users (id, email, password_hash, name, role, timezone, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
 	`
 	_, err := r.db.ExecContext(ctx, query,
 		user.ID,
@@ -25,6 +25,7 @@ func (r *UserRepository) Create(ctx context.Context, user *domain.User) error {
 		user.PasswordHash,
 		user.Name,
 		user.Role,
+		user.Timezone,
 		user.CreatedAt,
 		user.UpdatedAt,
 	)
@@ -32,7 +33,7 @@ func (r *UserRepository) Create(ctx context.Context, user *domain.User) error {
 }
 
 func (r *UserRepository) GetByID(ctx context.Context, id string) (*domain.User, error) {
-	query := `SELECT id, email, password_hash, name, role, created_at, updated_at FROM users WHERE id = ?`
+	query := `SELECT id, email, password_hash, name, role, COALESCE(timezone, ''), created_at, updated_at FROM users WHERE id = ?`
 	user := &domain.User{}
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&user.ID,
@@ -40,6 +41,7 @@ func (r *UserRepository) GetByID(ctx context.Context, id string) (*domain.User,
 		&user.PasswordHash,
 		&user.Name,
 		&user.Role,
+		&user.Timezone,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -53,7 +55,7 @@ func (r *UserRepository) GetByID(ctx context.Context, id string) (*domain.User,
 }
 
 func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
-	query := `SELECT id, email, password_hash, name, role, created_at, updated_at FROM users WHERE email = ?`
+	query := `SELECT id, email, password_hash, name, role, COALESCE(timezone, ''), created_at, updated_at FROM users WHERE email = ?`
 	user := &domain.User{}
 	err := r.db.QueryRowContext(ctx, query, email).Scan(
 		&user.ID,
@@ -61,6 +63,7 @@ func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*domain.
 		&user.PasswordHash,
 		&user.Name,
 		&user.Role,
+		&user.Timezone,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -76,7 +79,7 @@ func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*domain.
 func (r *UserRepository) Update(ctx context.Context, user *domain.User) error {
 	query := `
 		UPDATE users 
-		SET email = ?, password_hash = ?, name = ?, role = ?, updated_at = ?
+		SET email = ?, password_hash = ?, name = ?, role = ?, timezone = ?, updated_at = ?
 		WHERE id = ?
 	`
 	_, err := r.db.ExecContext(ctx, query,
@@ -84,6 +87,7 @@ func (r *UserRepository) Update(ctx context.Context, user *domain.User) error {
 		user.PasswordHash,
 		user.Name,
 		user.Role,
+		user.Timezone,
 		user.UpdatedAt,
 		user.ID,
 	)
@@ -97,7 +101,7 @@ func (r *UserRepository) Delete(ctx context.Context, id string) error {
 }
 
 func (r *UserRepository) List(ctx context.Context) ([]*domain.User, error) {
-	query := `SELECT id, email, password_hash, name, role, created_at, updated_at FROM users ORDER BY created_at DESC`
+	query := `SELECT id, email, password_hash, name, role, COALESCE(timezone, ''), created_at, updated_at FROM users ORDER BY created_at DESC`
 	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
@@ -113,6 +117,7 @@ func (r *UserRepository) List(ctx context.Context) ([]*domain.User, error) {
 			&user.PasswordHash,
 			&user.Name,
 			&user.Role,
+			&user.Timezone,
 			&user.CreatedAt,
 			&user.UpdatedAt,
 		)
@@ -0,0 +1,100 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"headless_form/internal/core/domain"
+)
+
+// WebhookDeliveryRepository implements the webhook delivery log in SQLite.
+// Attempts are stored as a JSON array.
+type WebhookDeliveryRepository struct {
+	db dbtx
+}
+
+const webhookDeliveryColumns = `id, form_id, event, url, payload, status, attempts, error, is_test, replay_of, created_at, updated_at`
+
+func (r *WebhookDeliveryRepository) Create(ctx context.Context, d *domain.WebhookDelivery) error {
+	attempts, err := json.Marshal(d.Attempts)
+	if err != nil {
+		return fmt.Errorf("marshal attempts: %w", err)
+	}
+	_, err = r.db.ExecContext(ctx, `INSERT INTO webhook_deliveries (`+webhookDeliveryColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		d.ID, d.FormID, d.Event, d.URL, []byte(d.Payload), d.Status, attempts, d.Error, d.Test, d.ReplayOf, d.CreatedAt.UTC(), d.UpdatedAt.UTC())
+	return err
+}
+
+func (r *WebhookDeliveryRepository) Update(ctx context.Context, d *domain.WebhookDelivery) error {
+	attempts, err := json.Marshal(d.Attempts)
+	if err != nil {
+		return fmt.Errorf("marshal attempts: %w", err)
+	}
+	_, err = r.db.ExecContext(ctx, `UPDATE webhook_deliveries SET status = ?, attempts = ?, error = ?, updated_at = ? WHERE id = ?`,
+		d.Status, attempts, d.Error, d.UpdatedAt.UTC(), d.ID)
+	return err
+}
+
+func (r *WebhookDeliveryRepository) GetByID(ctx context.Context, id string) (*domain.WebhookDelivery, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+webhookDeliveryColumns+` FROM webhook_deliveries WHERE id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+	deliveries, err := scanWebhookDeliveries(rows)
+	if err != nil || len(deliveries) == 0 {
+		return nil, err
+	}
+	return deliveries[0], nil
+}
+
+func (r *WebhookDeliveryRepository) ListByFormID(ctx context.Context, formID string, limit, offset int) ([]*domain.WebhookDelivery, int, error) {
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM webhook_deliveries WHERE form_id = ?`, formID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	rows, err := r.db.QueryContext(ctx, `SELECT `+webhookDeliveryColumns+` FROM webhook_deliveries
+		WHERE form_id = ? ORDER BY created_at DESC, id DESC LIMIT ? OFFSET ?`, formID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	deliveries, err := scanWebhookDeliveries(rows)
+	return deliveries, total, err
+}
+
+func (r *WebhookDeliveryRepository) DeleteBefore(ctx context.Context, before time.Time) (int, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM webhook_deliveries WHERE substr(created_at, 1, 19) < ?`,
+		before.UTC().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+// scanWebhookDeliveries reads and closes rows
+func scanWebhookDeliveries(rows *sql.Rows) ([]*domain.WebhookDelivery, error) {
+	defer func() { _ = rows.Close() }()
+
+	var deliveries []*domain.WebhookDelivery
+	for rows.Next() {
+		var d domain.WebhookDelivery
+		var payload, attempts []byte
+		var deliveryErr, replayOf sql.NullString
+		if err := rows.Scan(&d.ID, &d.FormID, &d.Event, &d.URL, &payload, &d.Status, &attempts, &deliveryErr, &d.Test, &replayOf, &d.CreatedAt, &d.UpdatedAt); err != nil {
+			return nil, err
+		}
+		if len(payload) > 0 {
+			d.Payload = json.RawMessage(payload)
+		}
+		if err := json.Unmarshal(attempts, &d.Attempts); err != nil {
+			return nil, fmt.Errorf("unmarshal attempts: %w", err)
+		}
+		d.Error = deliveryErr.String
+		d.ReplayOf = replayOf.String
+		deliveries = append(deliveries, &d)
+	}
+	return deliveries, rows.Err()
+}
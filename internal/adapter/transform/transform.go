@@ -0,0 +1,291 @@
+// Package transform runs per-form webhook transformation scripts.
+//
+// Scripts are Go text/template sources that must render a JSON object, e.g.
+//
+//	{"email": {{json .data.email}}, "name": {{json (printf "%s %s" .data.first .data.last)}}}
+//
+// Scripts only see the submission passed in and a fixed set of pure helper
+// functions. Template calls, ranges over anything but input data and deeply
+// nested ranges are rejected, so execution time is bounded by the size of the input;
+// an iteration budget, a timeout and an output cap are enforced on top of that. Scripts
+// run on the caller's goroutine and stop at the next loop iteration or write once the
+// timeout passes.
+package transform
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+)
+
+const (
+	// MaxScriptSize is the maximum length of a transformation script in bytes
+	MaxScriptSize = 16 * 1024
+	// MaxOutputSize is the maximum rendered payload size in bytes
+	MaxOutputSize = 256 * 1024
+	// DefaultTimeout bounds a single script execution
+	DefaultTimeout = 100 * time.Millisecond
+	// MaxIterations is the most range loop iterations a single execution may run, nested ones included
+	MaxIterations = 10_000
+)
+
+// Transformation errors
+var (
+	ErrScriptTooLarge = errors.New("transform script exceeds 16KB")
+	ErrNestedTemplate = errors.New("transform script may not define or call templates")
+	ErrUnboundedLoop  = errors.New("transform script may only range over submission data, at most two levels deep")
+	ErrOutputTooLarge = errors.New("transform output exceeds 256KB")
+	ErrTimeout        = errors.New("transform script timed out")
+	ErrTooManyLoops   = errors.New("transform script ran more than 10000 loop iterations")
+	ErrNotJSONObject  = errors.New("transform output must be a JSON object")
+)
+
+// Input is the data exposed to a script
+type Input struct {
+	FormID       string
+	FormName     string
+	SubmissionID string
+	Timestamp    time.Time
+	Data         map[string]interface{}
+}
+
+// Script is a compiled transformation script
+type Script struct {
+	tmpl *template.Template
+}
+
+// Compile parses and checks a script
+func Compile(source string) (*Script, error) {
+	if len(source) > MaxScriptSize {
+		return nil, ErrScriptTooLarge
+	}
+
+	tmpl, err := template.New("transform").Option("missingkey=zero").Funcs(funcs).Parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("parse transform: %w", err)
+	}
+	if len(tmpl.Templates()) > 1 {
+		return nil, ErrNestedTemplate
+	}
+	if err := checkTree(tmpl.Tree.Root, 0, true); err != nil {
+		return nil, err
+	}
+	return &Script{tmpl: tmpl}, nil
+}
+
+// Run renders the script for the given input and returns the resulting JSON object
+func (s *Script) Run(ctx context.Context, in Input) (map[string]interface{}, error) {
+	ctx, cancel := context.WithTimeout(ctx, DefaultTimeout)
+	defer cancel()
+
+	vars := map[string]interface{}{
+		"form_id":       in.FormID,
+		"form_name":     in.FormName,
+		"submission_id": in.SubmissionID,
+		"timestamp":     in.Timestamp.UTC().Format(time.RFC3339),
+		"data":          in.Data,
+	}
+
+	// The budget is per execution, so each run gets its own copy of the template to bind it to
+	tmpl, err := s.tmpl.Clone()
+	if err != nil {
+		return nil, fmt.Errorf("run transform: %w", err)
+	}
+	iterations := 0
+	tmpl.Funcs(template.FuncMap{iterationFunc: func() (string, error) {
+		if iterations++; iterations > MaxIterations {
+			return "", ErrTooManyLoops
+		}
+		if ctx.Err() != nil {
+			return "", ErrTimeout
+		}
+		return "", nil
+	}})
+
+	w := &limitedBuffer{ctx: ctx, limit: MaxOutputSize}
+	if err := tmpl.Execute(w, vars); err != nil {
+		for _, limit := range []error{ErrOutputTooLarge, ErrTooManyLoops, ErrTimeout} {
+			if errors.Is(err, limit) {
+				return nil, limit
+			}
+		}
+		return nil, fmt.Errorf("run transform: %w", err)
+	}
+
+	var payload map[string]interface{}
+	if err := json.Unmarshal(w.buf.Bytes(), &payload); err != nil || payload == nil {
+		return nil, ErrNotJSONObject
+	}
+	return payload, nil
+}
+
+// limitedBuffer fails writes once the limit is reached or the context is done, aborting
+// template execution
+type limitedBuffer struct {
+	ctx   context.Context
+	buf   bytes.Buffer
+	limit int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.ctx.Err() != nil {
+		return 0, ErrTimeout
+	}
+	if b.buf.Len()+len(p) > b.limit {
+		return 0, ErrOutputTooLarge
+	}
+	return b.buf.Write(p)
+}
+
+// CheckTree applies the script checks to another template's parse tree, for other
+// owner-written templates such as notification emails
+func CheckTree(tree *parse.Tree) error {
+	return checkTree(tree.Root, 0, false)
+}
+
+// Funcs returns a copy of the helpers available to scripts, printf included, for
+// templates checked with CheckTree
+func Funcs() template.FuncMap {
+	return maps.Clone(funcs)
+}
+
+// maxRangeDepth limits nested range loops so run time stays proportional to the input
+const maxRangeDepth = 2
+
+// iterationFunc is the function counting loop iterations against MaxIterations. Run binds
+// it; scripts can't call it, since it isn't defined when they're parsed.
+const iterationFunc = "_iteration"
+
+// checkTree rejects template calls, ranges over anything but input data, and deeply nested
+// ranges. With count set, it also makes every range body start by calling iterationFunc.
+func checkTree(node parse.Node, rangeDepth int, count bool) error {
+	switch n := node.(type) {
+	case *parse.TemplateNode:
+		return ErrNestedTemplate
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, child := range n.Nodes {
+			if err := checkTree(child, rangeDepth, count); err != nil {
+				return err
+			}
+		}
+	case *parse.IfNode:
+		return checkBranches(&n.BranchNode, rangeDepth, count)
+	case *parse.WithNode:
+		return checkBranches(&n.BranchNode, rangeDepth, count)
+	case *parse.RangeNode:
+		if rangeDepth+1 > maxRangeDepth || !rangesOverData(n.Pipe) {
+			return ErrUnboundedLoop
+		}
+		if err := checkBranches(&n.BranchNode, rangeDepth+1, count); err != nil {
+			return err
+		}
+		if count && n.List != nil {
+			n.List.Nodes = append([]parse.Node{countIteration(n.Position())}, n.List.Nodes...)
+		}
+	}
+	return nil
+}
+
+func checkBranches(n *parse.BranchNode, rangeDepth int, count bool) error {
+	if err := checkTree(n.List, rangeDepth, count); err != nil {
+		return err
+	}
+	return checkTree(n.ElseList, rangeDepth, count)
+}
+
+// countIteration returns the action {{_iteration}}, which prints nothing
+func countIteration(pos parse.Pos) parse.Node {
+	call := &parse.CommandNode{NodeType: parse.NodeCommand, Pos: pos, Args: []parse.Node{parse.NewIdentifier(iterationFunc).SetPos(pos)}}
+	return &parse.ActionNode{NodeType: parse.NodeAction, Pos: pos, Pipe: &parse.PipeNode{NodeType: parse.NodePipe, Pos: pos, Cmds: []*parse.CommandNode{call}}}
+}
+
+// rangesOverData reports whether a range pipeline iterates over input data
+// (.data.items, $.data.items or index ...) rather than a number or a variable
+func rangesOverData(pipe *parse.PipeNode) bool {
+	if len(pipe.Cmds) != 1 || len(pipe.Cmds[0].Args) == 0 {
+		return false
+	}
+	switch arg := pipe.Cmds[0].Args[0].(type) {
+	case *parse.FieldNode:
+		return true
+	case *parse.VariableNode:
+		return len(arg.Ident) > 1 && arg.Ident[0] == "$"
+	case *parse.IdentifierNode:
+		return arg.Ident == "index"
+	}
+	return false
+}
+
+// largeVerb matches format verbs with oversized or argument-supplied width/precision
+var largeVerb = regexp.MustCompile(`%[-+# 0]*(\*|\d{4,}|\d*\.(\*|\d{4,}))`)
+
+// safePrintf replaces the builtin printf so a script can't allocate huge padded strings
+func safePrintf(format string, args ...interface{}) (string, error) {
+	if largeVerb.MatchString(format) {
+		return "", ErrOutputTooLarge
+	}
+	return fmt.Sprintf(format, args...), nil
+}
+
+// funcs are the helpers available to scripts. All are pure and bounded by their input.
+var funcs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	"upper":     strings.ToUpper,
+	"lower":     strings.ToLower,
+	"trim":      strings.TrimSpace,
+	"contains":  strings.Contains,
+	"hasPrefix": strings.HasPrefix,
+	"hasSuffix": strings.HasSuffix,
+	"replace": func(s, old, new string) (string, error) {
+		if len(s)+strings.Count(s, old)*len(new) > MaxOutputSize {
+			return "", ErrOutputTooLarge
+		}
+		return strings.ReplaceAll(s, old, new), nil
+	},
+	"split": strings.Split,
+	"join": func(items interface{}, sep string) (string, error) {
+		var parts []string
+		switch v := items.(type) {
+		case []string:
+			parts = v
+		case []interface{}:
+			parts = make([]string, len(v))
+			for i, item := range v {
+				parts[i] = fmt.Sprint(item)
+			}
+		default:
+			return fmt.Sprint(items), nil
+		}
+		if len(parts)*len(sep) > MaxOutputSize {
+			return "", ErrOutputTooLarge
+		}
+		return strings.Join(parts, sep), nil
+	},
+	"default": func(def, v interface{}) interface{} {
+		if v == nil || v == "" {
+			return def
+		}
+		return v
+	},
+	"printf": safePrintf,
+	"toString": func(v interface{}) string {
+		if v == nil {
+			return ""
+		}
+		return fmt.Sprint(v)
+	},
+}
@@ -0,0 +1,138 @@
+package transform
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testInput() Input {
+	return Input{
+		FormID:       "form_1",
+		FormName:     "Contact",
+		SubmissionID: "sub_1",
+		Timestamp:    time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Data: map[string]interface{}{
+			"first": "Ada",
+			"last":  "Lovelace",
+			"email": "ADA@example.com",
+		},
+	}
+}
+
+func TestScript_Run(t *testing.T) {
+	script, err := Compile(`{"name": {{json (printf "%s %s" .data.first .data.last)}}, "email": {{json (lower .data.email)}}, "form": {{json .form_name}}, "phone": {{json (default "n/a" .data.phone)}}}`)
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+
+	out, err := script.Run(context.Background(), testInput())
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if out["name"] != "Ada Lovelace" {
+		t.Errorf("expected derived name, got %v", out["name"])
+	}
+	if out["email"] != "ada@example.com" {
+		t.Errorf("expected lowercased email, got %v", out["email"])
+	}
+	if out["form"] != "Contact" {
+		t.Errorf("expected form name, got %v", out["form"])
+	}
+	if out["phone"] != "n/a" {
+		t.Errorf("expected default phone, got %v", out["phone"])
+	}
+}
+
+func TestCompile_RejectsTemplateCalls(t *testing.T) {
+	sources := []string{
+		`{{define "x"}}{{template "x"}}{{end}}{}`,
+		`{{if true}}{{template "transform" .}}{{end}}`,
+	}
+	for _, src := range sources {
+		if _, err := Compile(src); !errors.Is(err, ErrNestedTemplate) {
+			t.Errorf("expected ErrNestedTemplate for %q, got %v", src, err)
+		}
+	}
+}
+
+func TestCompile_RejectsUnboundedLoops(t *testing.T) {
+	sources := []string{
+		`{{range 1000000000}}{{end}}{}`,
+		`{{$n := 1000000000}}{{range $n}}{{end}}{}`,
+		`{{range .data.a}}{{range .data.b}}{{range .data.c}}{{end}}{{end}}{{end}}{}`,
+	}
+	for _, src := range sources {
+		if _, err := Compile(src); !errors.Is(err, ErrUnboundedLoop) {
+			t.Errorf("expected ErrUnboundedLoop for %q, got %v", src, err)
+		}
+	}
+
+	if _, err := Compile(`{"tags": [{{range $i, $t := .data.tags}}{{if $i}},{{end}}{{json $t}}{{end}}]}`); err != nil {
+		t.Errorf("expected range over data to compile, got %v", err)
+	}
+}
+
+func TestCompile_RejectsLargeScript(t *testing.T) {
+	if _, err := Compile(strings.Repeat(" ", MaxScriptSize+1)); !errors.Is(err, ErrScriptTooLarge) {
+		t.Errorf("expected ErrScriptTooLarge, got %v", err)
+	}
+}
+
+func TestScript_Limits(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   error
+	}{
+		{"not an object", `[1, 2]`, ErrNotJSONObject},
+		{"huge padding", `{"x": "{{printf "%99999999d" 1}}"}`, ErrOutputTooLarge},
+		{"output cap", `{"x": "{{range .data.items}}` + strings.Repeat("a", 1024) + `{{end}}"}`, ErrOutputTooLarge},
+		{"loop budget", `{{range .data.items}}{{range $.data.items}}{{end}}{{end}}{}`, ErrTooManyLoops},
+	}
+
+	in := testInput()
+	items := make([]interface{}, 1000)
+	in.Data["items"] = items
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			script, err := Compile(tt.source)
+			if err != nil {
+				t.Fatalf("Compile failed: %v", err)
+			}
+			if _, err := script.Run(context.Background(), in); !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestScript_TimeoutStopsExecution(t *testing.T) {
+	script, err := Compile(`{{range .data.items}}{{range $.data.items}}{{end}}{{end}}{}`)
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	in := testInput()
+	in.Data["items"] = make([]interface{}, 300)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	before := runtime.NumGoroutine()
+	for i := 0; i < 50; i++ {
+		if _, err := script.Run(ctx, in); !errors.Is(err, ErrTimeout) {
+			t.Fatalf("expected ErrTimeout, got %v", err)
+		}
+	}
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("expected no goroutines left running, had %d, now %d", before, after)
+	}
+
+	in.Data["items"] = make([]interface{}, 50)
+	if _, err := script.Run(context.Background(), in); err != nil {
+		t.Errorf("expected the script to finish within its budget, got %v", err)
+	}
+}
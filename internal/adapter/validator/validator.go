@@ -110,7 +110,7 @@ func (v *Validator) Errors() ValidationErrors {
 
 // Fail writes validation error response to the http.ResponseWriter
 func (v *Validator) Fail(w http.ResponseWriter) {
-	response.Error(w, http.StatusBadRequest, v.errors[0].Message, "VALIDATION_ERROR")
+	response.Error(w, http.StatusBadRequest, v.errors[0].Message, response.CodeValidationError)
 }
 
 // FailWithDetails writes validation error response with all error details
@@ -2,17 +2,30 @@ package webhook
 
 import (
 	"bytes"
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/hex"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
+	"strings"
+	"sync"
 	"time"
 
+	"headless_form/internal/adapter/logger"
+	"headless_form/internal/adapter/transform"
 	"headless_form/internal/core/domain"
+	"headless_form/internal/core/ids"
+	"headless_form/internal/core/ports"
+)
+
+var log = logger.Module("webhook")
+
+// Webhook events, sent in the payload's event field and the X-Webhook-Event header
+const (
+	EventSubmissionCreated = "submission.created"
+	EventSubmissionDeleted = "submission.deleted"
+	EventSubmissionBatch   = "submission.batch"
 )
 
 // Payload represents the data sent to webhooks
@@ -23,12 +36,100 @@ type Payload struct {
 	SubmissionID string                 `json:"submission_id"`
 	Timestamp    time.Time              `json:"timestamp"`
 	Data         map[string]interface{} `json:"data"`
+	Meta         *PayloadMeta           `json:"meta,omitempty"` // Only for forms with webhook_include_meta
+	Test         bool                   `json:"test,omitempty"` // Synthetic submission from the dashboard's test notification
+}
+
+// PayloadMeta is the submission's request metadata and spam verdict, for receivers that
+// filter spam or route by location. Anonymous forms don't store the request fields.
+type PayloadMeta struct {
+	IP        string           `json:"ip,omitempty"`
+	Country   string           `json:"country,omitempty"`
+	Referer   string           `json:"referer,omitempty"`
+	UserAgent string           `json:"user_agent,omitempty"`
+	Spam      *domain.SpamMeta `json:"spam,omitempty"`
+}
+
+// payloadMeta returns the metadata sent for a submission to a form including it, nil otherwise
+func payloadMeta(form *domain.Form, submission *domain.Submission) *PayloadMeta {
+	if !form.WebhookIncludeMeta {
+		return nil
+	}
+	server := submission.Meta.Server
+	return &PayloadMeta{
+		IP:        server.IP,
+		Country:   server.Country,
+		Referer:   server.Referer,
+		UserAgent: server.UserAgent,
+		Spam:      submission.Meta.Spam,
+	}
 }
 
+// DeletionPayload is the tombstone sent when submissions are deleted: IDs and
+// timestamps only, so receivers mirroring the data can delete their copies
+type DeletionPayload struct {
+	Event       string                `json:"event"`
+	FormID      string                `json:"form_id"`
+	FormName    string                `json:"form_name"`
+	Reason      domain.DeletionReason `json:"reason"`
+	Timestamp   time.Time             `json:"timestamp"` // When they were deleted
+	Submissions []domain.Tombstone    `json:"submissions"`
+	Test        bool                  `json:"test,omitempty"` // Only test submissions were deleted
+}
+
+// BatchPayload is sent instead of one payload per submission by forms that batch their
+// webhooks. Each entry is what would have been sent for the submission on its own.
+type BatchPayload struct {
+	Event       string            `json:"event"`
+	FormID      string            `json:"form_id"`
+	FormName    string            `json:"form_name"`
+	Timestamp   time.Time         `json:"timestamp"` // When the batch was sent
+	Submissions []json.RawMessage `json:"submissions"`
+}
+
+// batch collects a form's new submissions until it's sent
+type batch struct {
+	form  *domain.Form // As of the latest submission, for its URL and signing
+	items []json.RawMessage
+	timer *time.Timer
+}
+
+// maxTombstones caps the submissions listed in one deletion payload
+const maxTombstones = 1000
+
+// deliveryHistoryWindow is how long delivery outcomes are kept for failure-rate alerts
+const deliveryHistoryWindow = 7 * 24 * time.Hour
+
+// deliveryResult is the final outcome of a webhook delivery (after retries)
+type deliveryResult struct {
+	at     time.Time
+	failed bool
+}
+
+// maxResponseSnippet is how much of a response body the delivery log keeps
+const maxResponseSnippet = 1024
+
+// maxQueuedDeliveries caps the deliveries waiting for one form's rate limit. Beyond it
+// deliveries are logged as failed without an attempt, so they can be replayed later.
+const maxQueuedDeliveries = 1000
+
 // Service handles webhook delivery
 type Service struct {
-	client  *http.Client
-	retries int
+	client      *http.Client
+	retries     int
+	backoff     time.Duration // Wait before the first retry, doubled for each further one
+	maxQueued   int           // Deliveries allowed to wait for one form's rate limit
+	deliveryLog ports.WebhookDeliveryRepository
+	secrets     ports.SecretCodec // Resolves webhook secrets referencing a secret manager
+
+	mu         sync.Mutex
+	deliveries map[string][]deliveryResult // form public ID -> recent outcomes
+	onFailure  func(formID string)
+	nextSlot   map[string]time.Time // form public ID -> earliest time its rate limit allows the next request
+	batches    map[string]*batch    // form public ID -> submissions waiting to be sent
+	queued     map[string]int       // form public ID -> deliveries waiting for its rate limit
+
+	inflight sync.WaitGroup // Deliveries running in the background
 }
 
 // NewService creates a new webhook service
@@ -37,95 +138,470 @@ func NewService() *Service {
 		client: &http.Client{
 			Timeout: 30 * time.Second,
 		},
-		retries: 3,
+		retries:    3,
+		backoff:    time.Second,
+		maxQueued:  maxQueuedDeliveries,
+		deliveries: make(map[string][]deliveryResult),
+		nextSlot:   make(map[string]time.Time),
+		batches:    make(map[string]*batch),
+		queued:     make(map[string]int),
+	}
+}
+
+// SetDeliveryLog records every delivery, with the outcome of each attempt, in repo
+func (s *Service) SetDeliveryLog(repo ports.WebhookDeliveryRepository) {
+	s.deliveryLog = repo
+}
+
+// SetSecretCodec resolves webhook secrets that reference a secret manager when payloads
+// are signed. Without it such a secret can't be resolved and its deliveries fail.
+func (s *Service) SetSecretCodec(codec ports.SecretCodec) {
+	s.secrets = codec
+}
+
+// resolveSecret returns the secret to sign with, fetching it if it's a reference
+func (s *Service) resolveSecret(secret string) (string, error) {
+	if !domain.SecretReference(secret) {
+		return secret, nil
 	}
+	if s.secrets == nil {
+		return "", errors.New("no secret manager configured")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return s.secrets.Open(ctx, secret)
 }
 
-// TriggerSubmission sends a webhook for a new submission
+// DeliveryStats returns the number of deliveries and failures for a form since the given time
+func (s *Service) DeliveryStats(formID string, since time.Time) (total, failed int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, d := range s.deliveries[formID] {
+		if d.at.Before(since) {
+			continue
+		}
+		total++
+		if d.failed {
+			failed++
+		}
+	}
+	return total, failed
+}
+
+// SetFailureCallback sets a callback run (async) with the form's public ID when a
+// non-test delivery finally fails
+func (s *Service) SetFailureCallback(fn func(formID string)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onFailure = fn
+}
+
+// recordResult stores a delivery outcome and drops entries older than the history window
+func (s *Service) recordResult(formID string, failed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if failed && s.onFailure != nil {
+		go s.onFailure(formID)
+	}
+	now := time.Now()
+	cutoff := now.Add(-deliveryHistoryWindow)
+	kept := s.deliveries[formID][:0]
+	for _, d := range s.deliveries[formID] {
+		if d.at.After(cutoff) {
+			kept = append(kept, d)
+		}
+	}
+	s.deliveries[formID] = append(kept, deliveryResult{at: now, failed: failed})
+}
+
+// TriggerSubmission sends a webhook for a new submission, or adds it to the form's next
+// batch if it batches them. Test submissions are always sent on their own.
 func (s *Service) TriggerSubmission(form *domain.Form, submission *domain.Submission, data map[string]interface{}) {
 	if form.WebhookURL == "" {
 		return
 	}
 
-	payload := Payload{
-		Event:        "submission.created",
+	var body []byte
+	var err error
+	if form.WebhookTransformEnabled && form.WebhookTransform != "" {
+		// Never fall back to the default payload: the script may exist to strip fields
+		body, err = applyTransform(form, submission, data)
+		if err != nil {
+			log.Error("transform failed", "form_id", form.PublicID, "error", err)
+			if !submission.IsTest {
+				s.recordResult(form.PublicID, true)
+			}
+			d := newDelivery(form, EventSubmissionCreated, nil, submission.IsTest)
+			d.Status = domain.WebhookDeliveryFailed
+			d.Error = "transform failed: " + err.Error()
+			s.saveDelivery(d, true)
+			return
+		}
+	} else {
+		body, err = json.Marshal(Payload{
+			Event:        EventSubmissionCreated,
+			FormID:       form.PublicID,
+			FormName:     form.Name,
+			SubmissionID: submission.ID,
+			Timestamp:    submission.CreatedAt,
+			Data:         data,
+			Meta:         payloadMeta(form, submission),
+			Test:         submission.IsTest,
+		})
+		if err != nil {
+			log.Error("failed to marshal payload", "form_id", form.PublicID, "error", err)
+			return
+		}
+	}
+
+	if form.WebhookRate.Batching() && !submission.IsTest {
+		s.addToBatch(form, body)
+		return
+	}
+	s.send(form, newDelivery(form, EventSubmissionCreated, body, submission.IsTest))
+}
+
+// addToBatch adds a submission's payload to the form's pending batch. The batch is sent
+// when it's full, or when the form's batch interval is up after its first submission.
+func (s *Service) addToBatch(form *domain.Form, item []byte) {
+	s.mu.Lock()
+	b := s.batches[form.PublicID]
+	if b == nil {
+		b = &batch{}
+		b.timer = time.AfterFunc(form.WebhookRate.Interval(), func() { s.sendBatch(form.PublicID, b) })
+		s.batches[form.PublicID] = b
+	}
+	b.form = form
+	b.items = append(b.items, item)
+	full := len(b.items) >= form.WebhookRate.BatchSize()
+	s.mu.Unlock()
+
+	if full {
+		s.sendBatch(form.PublicID, b)
+	}
+}
+
+// sendBatch sends b if it's still the form's pending batch, and starts a new one
+func (s *Service) sendBatch(formID string, b *batch) {
+	s.mu.Lock()
+	if s.batches[formID] != b {
+		s.mu.Unlock()
+		return
+	}
+	delete(s.batches, formID)
+	s.mu.Unlock()
+	b.timer.Stop()
+
+	body, err := json.Marshal(BatchPayload{
+		Event:       EventSubmissionBatch,
+		FormID:      b.form.PublicID,
+		FormName:    b.form.Name,
+		Timestamp:   time.Now().UTC(),
+		Submissions: b.items,
+	})
+	if err != nil {
+		log.Error("failed to marshal payload", "form_id", formID, "error", err)
+		return
+	}
+	s.send(b.form, newDelivery(b.form, EventSubmissionBatch, body, false))
+}
+
+// Flush sends every pending batch now rather than at the end of its interval, and waits
+// for the deliveries in progress to finish, e.g. on shutdown. If they're still running
+// when ctx is done it returns ctx's error; they stay in the delivery log as pending.
+func (s *Service) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	pending := make(map[string]*batch, len(s.batches))
+	for formID, b := range s.batches {
+		pending[formID] = b
+	}
+	s.mu.Unlock()
+	for formID, b := range pending {
+		s.sendBatch(formID, b)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		s.inflight.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// TriggerDeletion sends a submission.deleted webhook with the tombstones of a form's
+// deleted submissions, split into payloads of up to 1000. The form's transform script
+// doesn't apply, as tombstones carry no submission data.
+func (s *Service) TriggerDeletion(form *domain.Form, reason domain.DeletionReason, tombstones []domain.Tombstone) {
+	if form.WebhookURL == "" {
+		return
+	}
+
+	now := time.Now().UTC()
+	for len(tombstones) > 0 {
+		batch := tombstones[:min(len(tombstones), maxTombstones)]
+		tombstones = tombstones[len(batch):]
+
+		test := true
+		for _, t := range batch {
+			test = test && t.IsTest
+		}
+		body, err := json.Marshal(DeletionPayload{
+			Event:       EventSubmissionDeleted,
+			FormID:      form.PublicID,
+			FormName:    form.Name,
+			Reason:      reason,
+			Timestamp:   now,
+			Submissions: batch,
+			Test:        test,
+		})
+		if err != nil {
+			log.Error("failed to marshal payload", "form_id", form.PublicID, "error", err)
+			return
+		}
+		s.send(form, newDelivery(form, EventSubmissionDeleted, body, test))
+	}
+}
+
+// applyTransform runs the form's transform script and returns the resulting payload
+func applyTransform(form *domain.Form, submission *domain.Submission, data map[string]interface{}) ([]byte, error) {
+	script, err := transform.Compile(form.WebhookTransform)
+	if err != nil {
+		return nil, err
+	}
+	out, err := script.Run(context.Background(), transform.Input{
 		FormID:       form.PublicID,
 		FormName:     form.Name,
 		SubmissionID: submission.ID,
 		Timestamp:    submission.CreatedAt,
 		Data:         data,
+	})
+	if err != nil {
+		return nil, err
 	}
+	return json.Marshal(out)
+}
 
-	go s.deliver(form.WebhookURL, form.WebhookSecret, payload)
+// Replay sends the payload of an earlier delivery again as a new delivery, to the
+// form's current webhook URL and signed with its current secret. It returns the new
+// delivery, logged before it's attempted in the background.
+func (s *Service) Replay(form *domain.Form, original *domain.WebhookDelivery) (*domain.WebhookDelivery, error) {
+	if form.WebhookURL == "" {
+		return nil, domain.ErrWebhookNotConfigured
+	}
+	if len(original.Payload) == 0 {
+		return nil, domain.ErrDeliveryNotReplayable
+	}
+	d := newDelivery(form, original.Event, original.Payload, original.Test)
+	d.ReplayOf = original.ID
+	s.saveDelivery(d, true)
+	logged := *d // The background delivery keeps updating d
+	logged.Attempts = []domain.WebhookAttempt{}
+	s.run(SigningFor(form), form.WebhookRate, form.PublicID, d, false)
+	return &logged, nil
 }
 
-func (s *Service) deliver(url, secret string, payload Payload) {
-	body, err := json.Marshal(payload)
+// newDelivery returns the log entry of a webhook about to be sent to form
+func newDelivery(form *domain.Form, event string, body []byte, test bool) *domain.WebhookDelivery {
+	now := time.Now().UTC()
+	return &domain.WebhookDelivery{
+		ID:        ids.New(ids.Delivery),
+		FormID:    form.ID,
+		Event:     event,
+		URL:       form.WebhookURL,
+		Payload:   body,
+		Status:    domain.WebhookDeliveryPending,
+		Attempts:  []domain.WebhookAttempt{},
+		Test:      test,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// send logs and delivers d in the background
+func (s *Service) send(form *domain.Form, d *domain.WebhookDelivery) {
+	s.run(SigningFor(form), form.WebhookRate, form.PublicID, d, true)
+}
+
+// run delivers d in the background, first creating its log entry if create is set. When
+// too many deliveries are already waiting for the form's rate limit, d fails right away.
+func (s *Service) run(sig Signing, rate *domain.WebhookRate, formID string, d *domain.WebhookDelivery, create bool) {
+	limited := rate.Spacing() > 0
+	full := false
+	if limited {
+		s.mu.Lock()
+		if full = s.queued[formID] >= s.maxQueued; !full {
+			s.queued[formID]++
+		}
+		s.mu.Unlock()
+	}
+
+	s.inflight.Add(1)
+	go func() {
+		defer s.inflight.Done()
+		if create {
+			s.saveDelivery(d, true)
+		}
+		if full {
+			log.Warn("rate limit queue full, delivery not attempted", "form_id", formID, "delivery_id", d.ID)
+			s.failDelivery(formID, d, "rate limit queue is full")
+			return
+		}
+		if limited {
+			defer func() {
+				s.mu.Lock()
+				s.queued[formID]--
+				s.mu.Unlock()
+			}()
+		}
+		s.deliver(sig, rate, formID, d)
+	}()
+}
+
+// failDelivery logs d as failed without another attempt, with why in its last attempt
+func (s *Service) failDelivery(formID string, d *domain.WebhookDelivery, reason string) {
+	d.Attempts = append(d.Attempts, domain.WebhookAttempt{At: time.Now().UTC(), Error: reason})
+	d.Status = domain.WebhookDeliveryFailed
+	s.saveDelivery(d, false)
+	if !d.Test {
+		s.recordResult(formID, true)
+	}
+}
+
+// saveDelivery creates or updates the delivery's log entry. Failing to log doesn't stop the delivery.
+func (s *Service) saveDelivery(d *domain.WebhookDelivery, create bool) {
+	if s.deliveryLog == nil {
+		return
+	}
+	d.UpdatedAt = time.Now().UTC()
+	var err error
+	if create {
+		err = s.deliveryLog.Create(context.Background(), d)
+	} else {
+		err = s.deliveryLog.Update(context.Background(), d)
+	}
+	if err != nil {
+		log.Error("failed to log delivery", "form_id", d.FormID, "delivery_id", d.ID, "error", err)
+	}
+}
+
+// wait blocks until the form's rate limit allows another request to its webhook. Requests
+// are spaced out evenly, each taking the next free slot.
+func (s *Service) wait(formID string, rate *domain.WebhookRate) {
+	spacing := rate.Spacing()
+	if spacing == 0 {
+		return
+	}
+	s.mu.Lock()
+	now := time.Now()
+	slot := s.nextSlot[formID]
+	if slot.Before(now) {
+		slot = now
+	}
+	s.nextSlot[formID] = slot.Add(spacing)
+	s.mu.Unlock()
+	time.Sleep(time.Until(slot))
+}
+
+// deliver sends the payload with retries, logging each attempt. Every attempt waits for
+// the form's rate limit. Test deliveries are marked with an X-Webhook-Test header and left
+// out of the delivery stats used by alerts.
+func (s *Service) deliver(sig Signing, rate *domain.WebhookRate, formID string, d *domain.WebhookDelivery) {
+	secret, err := s.resolveSecret(sig.Secret)
 	if err != nil {
-		log.Printf("[WEBHOOK] Failed to marshal payload: %v", err)
+		log.Error("failed to resolve webhook secret", "form_id", formID, "error", err)
+		s.failDelivery(formID, d, "resolve webhook secret: "+err.Error())
 		return
 	}
+	sig.Secret = secret
 
 	for attempt := 1; attempt <= s.retries; attempt++ {
-		err := s.sendRequest(url, secret, body)
+		s.wait(formID, rate)
+		result, err := s.sendRequest(d.URL, sig, d.Event, d.Payload, d.Test)
+		d.Attempts = append(d.Attempts, result)
 		if err == nil {
-			log.Printf("[WEBHOOK] Delivered to %s (attempt %d)", url, attempt)
+			log.Info("delivered", "form_id", formID, "event", d.Event, "url", d.URL, "attempt", attempt, "test", d.Test)
+			d.Status = domain.WebhookDeliverySucceeded
+			s.saveDelivery(d, false)
+			if !d.Test {
+				s.recordResult(formID, false)
+			}
 			return
 		}
 
-		log.Printf("[WEBHOOK] Attempt %d failed for %s: %v", attempt, url, err)
+		log.Warn("delivery attempt failed", "form_id", formID, "url", d.URL, "attempt", attempt, "error", err)
 
 		if attempt < s.retries {
+			s.saveDelivery(d, false)
 			// Exponential backoff: 1s, 2s, 4s
-			time.Sleep(time.Duration(1<<(attempt-1)) * time.Second)
+			time.Sleep(s.backoff << (attempt - 1))
 		}
 	}
 
-	log.Printf("[WEBHOOK] Failed after %d attempts for %s", s.retries, url)
+	log.Error("delivery failed", "form_id", formID, "url", d.URL, "attempts", s.retries, "test", d.Test)
+	d.Status = domain.WebhookDeliveryFailed
+	s.saveDelivery(d, false)
+	if !d.Test {
+		s.recordResult(formID, true)
+	}
 }
 
-func (s *Service) sendRequest(url, secret string, body []byte) error {
-	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
-	if err != nil {
-		return fmt.Errorf("create request: %w", err)
-	}
+// sendRequest makes one delivery attempt and returns its outcome for the log
+func (s *Service) sendRequest(url string, sig Signing, event string, body []byte, test bool) (domain.WebhookAttempt, error) {
+	result := domain.WebhookAttempt{At: time.Now().UTC()}
+	err := func() error {
+		req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("create request: %w", err)
+		}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("User-Agent", "HeadlessForms-Webhook/1.0")
-	req.Header.Set("X-Webhook-Event", "submission.created")
-	req.Header.Set("X-Webhook-Timestamp", time.Now().UTC().Format(time.RFC3339))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", "HeadlessForms-Webhook/1.0")
+		req.Header.Set("X-Webhook-Event", event)
+		timestamp := time.Now().UTC().Format(time.RFC3339)
+		req.Header.Set("X-Webhook-Timestamp", timestamp)
+		if test {
+			req.Header.Set("X-Webhook-Test", "true")
+		}
 
-	// Sign payload with HMAC-SHA256 if secret is provided
-	if secret != "" {
-		signature := s.signPayload(body, secret)
-		req.Header.Set("X-Webhook-Signature", signature)
-	}
+		// Sign payload with HMAC if secret is provided
+		if sig.Secret != "" {
+			req.Header.Set("X-Webhook-Signature", Sign(sig, timestamp, body))
+		}
 
-	resp, err := s.client.Do(req)
-	if err != nil {
-		return fmt.Errorf("send request: %w", err)
-	}
-	defer func() {
-		// Read and discard body to allow connection reuse
-		_, _ = io.Copy(io.Discard, resp.Body)
-		_ = resp.Body.Close()
-	}()
+		resp, err := s.client.Do(req)
+		if err != nil {
+			return fmt.Errorf("send request: %w", err)
+		}
+		defer func() {
+			// Read and discard body to allow connection reuse
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}()
 
-	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-		return nil
+		result.StatusCode = resp.StatusCode
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseSnippet))
+		result.Response = strings.ToValidUTF8(string(snippet), "")
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			return nil
+		}
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}()
+	result.LatencyMs = time.Since(result.At).Milliseconds()
+	if err != nil {
+		result.Error = err.Error()
 	}
-
-	return fmt.Errorf("unexpected status %d", resp.StatusCode)
-}
-
-func (s *Service) signPayload(body []byte, secret string) string {
-	h := hmac.New(sha256.New, []byte(secret))
-	h.Write(body)
-	return "sha256=" + hex.EncodeToString(h.Sum(nil))
+	return result, err
 }
 
 // TestWebhook sends a test payload to verify webhook configuration
-func (s *Service) TestWebhook(url, secret string) error {
+func (s *Service) TestWebhook(url string, sig Signing) error {
 	payload := Payload{
 		Event:        "test",
 		FormID:       "test-form-id",
@@ -135,6 +611,7 @@ func (s *Service) TestWebhook(url, secret string) error {
 		Data: map[string]interface{}{
 			"message": "This is a test webhook from HeadlessForms",
 		},
+		Test: true,
 	}
 
 	body, err := json.Marshal(payload)
@@ -142,5 +619,6 @@ func (s *Service) TestWebhook(url, secret string) error {
 		return err
 	}
 
-	return s.sendRequest(url, secret, body)
+	_, err = s.sendRequest(url, sig, EventSubmissionCreated, body, true)
+	return err
 }
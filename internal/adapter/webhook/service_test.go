@@ -0,0 +1,467 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"headless_form/internal/core/domain"
+	"headless_form/internal/testharness"
+)
+
+func TestTriggerDeletion(t *testing.T) {
+	type delivery struct {
+		event   string
+		test    string
+		payload DeletionPayload
+	}
+	received := make(chan delivery, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var payload DeletionPayload
+		_ = json.Unmarshal(body, &payload)
+		received <- delivery{event: r.Header.Get("X-Webhook-Event"), test: r.Header.Get("X-Webhook-Test"), payload: payload}
+	}))
+	defer server.Close()
+
+	form := &domain.Form{PublicID: "contact", Name: "Contact", WebhookURL: server.URL}
+	created := time.Date(2026, 1, 5, 10, 0, 0, 0, time.UTC)
+	tombstones := make([]domain.Tombstone, maxTombstones+1)
+	for i := range tombstones {
+		tombstones[i] = domain.Tombstone{SubmissionID: "sub", FormID: "internal", CreatedAt: created}
+	}
+	tombstones[maxTombstones].IsTest = true
+
+	s := NewService()
+	s.TriggerDeletion(form, domain.DeletionManual, tombstones)
+
+	counts := map[int]bool{}
+	for i := 0; i < 2; i++ {
+		var d delivery
+		select {
+		case d = <-received:
+		case <-time.After(5 * time.Second):
+			t.Fatal("webhook not delivered")
+		}
+		p := d.payload
+		if d.event != EventSubmissionDeleted || p.Event != EventSubmissionDeleted || p.FormID != "contact" || p.Reason != domain.DeletionManual {
+			t.Errorf("unexpected delivery %+v", d)
+		}
+		if !p.Submissions[0].CreatedAt.Equal(created) || p.Submissions[0].SubmissionID != "sub" {
+			t.Errorf("unexpected tombstone %+v", p.Submissions[0])
+		}
+		// The last batch only holds the test submission
+		if test := len(p.Submissions) == 1; p.Test != test || (d.test == "true") != test {
+			t.Errorf("batch of %d: expected test=%v, got %v", len(p.Submissions), test, p.Test)
+		}
+		counts[len(p.Submissions)] = true
+	}
+	if !counts[maxTombstones] || !counts[1] {
+		t.Errorf("expected batches of %d and 1, got %v", maxTombstones, counts)
+	}
+
+	// Nothing is sent without a webhook URL
+	s.TriggerDeletion(&domain.Form{PublicID: "other"}, domain.DeletionManual, tombstones[:1])
+	select {
+	case d := <-received:
+		t.Errorf("unexpected delivery %+v", d)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// memoryDeliveryLog keeps copies of the logged deliveries, signalling each finished one
+type memoryDeliveryLog struct {
+	mu         sync.Mutex
+	deliveries map[string]domain.WebhookDelivery
+	done       chan string
+}
+
+func (m *memoryDeliveryLog) save(d *domain.WebhookDelivery) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cp := *d
+	cp.Attempts = append([]domain.WebhookAttempt(nil), d.Attempts...)
+	m.deliveries[d.ID] = cp
+	if d.Status != domain.WebhookDeliveryPending {
+		m.done <- d.ID
+	}
+}
+
+func (m *memoryDeliveryLog) Create(_ context.Context, d *domain.WebhookDelivery) error {
+	m.save(d)
+	return nil
+}
+
+func (m *memoryDeliveryLog) Update(_ context.Context, d *domain.WebhookDelivery) error {
+	m.save(d)
+	return nil
+}
+
+func (m *memoryDeliveryLog) GetByID(_ context.Context, id string) (*domain.WebhookDelivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	d, ok := m.deliveries[id]
+	if !ok {
+		return nil, nil
+	}
+	return &d, nil
+}
+
+func (m *memoryDeliveryLog) ListByFormID(context.Context, string, int, int) ([]*domain.WebhookDelivery, int, error) {
+	return nil, 0, nil
+}
+
+func (m *memoryDeliveryLog) DeleteBefore(context.Context, time.Time) (int, error) {
+	return 0, nil
+}
+
+func TestDeliveryLog(t *testing.T) {
+	var mu sync.Mutex
+	failures := 1
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte("try later"))
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	deliveries := &memoryDeliveryLog{deliveries: map[string]domain.WebhookDelivery{}, done: make(chan string, 4)}
+	s := NewService()
+	s.backoff = time.Millisecond
+	s.SetDeliveryLog(deliveries)
+	form := &domain.Form{ID: "internal", PublicID: "contact", Name: "Contact", WebhookURL: server.URL}
+
+	finished := func() *domain.WebhookDelivery {
+		t.Helper()
+		select {
+		case id := <-deliveries.done:
+			d, _ := deliveries.GetByID(context.Background(), id)
+			return d
+		case <-time.After(5 * time.Second):
+			t.Fatal("delivery not finished")
+		}
+		return nil
+	}
+
+	s.TriggerDeletion(form, domain.DeletionManual, []domain.Tombstone{{SubmissionID: "sub", CreatedAt: time.Now()}})
+	d := finished()
+	if d.Status != domain.WebhookDeliverySucceeded || d.FormID != "internal" || d.Event != EventSubmissionDeleted || len(d.Payload) == 0 {
+		t.Fatalf("unexpected delivery %+v", d)
+	}
+	if len(d.Attempts) != 2 {
+		t.Fatalf("expected 2 attempts, got %+v", d.Attempts)
+	}
+	if a := d.Attempts[0]; a.StatusCode != http.StatusServiceUnavailable || a.Response != "try later" || a.Error == "" {
+		t.Errorf("unexpected failed attempt %+v", a)
+	}
+	if a := d.Attempts[1]; a.StatusCode != http.StatusOK || a.Response != "ok" || a.Error != "" {
+		t.Errorf("unexpected successful attempt %+v", a)
+	}
+
+	// A replay is a new delivery of the same payload
+	replay, err := s.Replay(form, d)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if replay.ID == d.ID || replay.ReplayOf != d.ID || replay.Status != domain.WebhookDeliveryPending {
+		t.Errorf("unexpected replay %+v", replay)
+	}
+	if r := finished(); r.ID != replay.ID || r.Status != domain.WebhookDeliverySucceeded || string(r.Payload) != string(d.Payload) {
+		t.Errorf("unexpected replayed delivery %+v", r)
+	}
+
+	if _, err := s.Replay(&domain.Form{ID: "internal"}, d); !errors.Is(err, domain.ErrWebhookNotConfigured) {
+		t.Errorf("expected ErrWebhookNotConfigured, got %v", err)
+	}
+	if _, err := s.Replay(form, &domain.WebhookDelivery{ID: "whd_x", Event: EventSubmissionCreated}); !errors.Is(err, domain.ErrDeliveryNotReplayable) {
+		t.Errorf("expected ErrDeliveryNotReplayable, got %v", err)
+	}
+}
+
+func TestTriggerSubmissionEndToEnd(t *testing.T) {
+	receiver := testharness.NewWebhookReceiver(t)
+	receiver.FailNext(2, http.StatusBadGateway)
+
+	s := NewService()
+	s.backoff = time.Millisecond
+	form := &domain.Form{
+		ID: "internal", PublicID: "contact", Name: "Contact", WebhookURL: receiver.URL(),
+		WebhookSecret: "whsec", WebhookSignatureAlgorithm: domain.SignatureSHA512, WebhookSignTimestamp: true,
+	}
+	created := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	s.TriggerSubmission(form, &domain.Submission{ID: "sub_1", CreatedAt: created}, map[string]interface{}{"email": "ada@example.com"})
+
+	// Two failed attempts, then the delivery goes through
+	reqs := receiver.Wait(t, 3)
+	if reqs[0].Status != http.StatusBadGateway || reqs[1].Status != http.StatusBadGateway || reqs[2].Status != http.StatusOK {
+		t.Errorf("unexpected statuses %d, %d, %d", reqs[0].Status, reqs[1].Status, reqs[2].Status)
+	}
+	for i, r := range reqs {
+		if string(r.Body) != string(reqs[0].Body) {
+			t.Errorf("attempt %d: expected the same payload on every retry", i+1)
+		}
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" ||
+			r.Header.Get("User-Agent") != "HeadlessForms-Webhook/1.0" || r.Header.Get("X-Webhook-Event") != EventSubmissionCreated ||
+			r.Header.Get("X-Webhook-Test") != "" {
+			t.Errorf("attempt %d: unexpected request %s %v", i+1, r.Method, r.Header)
+		}
+		err := Verify(SigningFor(form), r.Header.Get("X-Webhook-Timestamp"), r.Body, r.Header.Get("X-Webhook-Signature"), DefaultTolerance, time.Now())
+		if err != nil || !strings.HasPrefix(r.Header.Get("X-Webhook-Signature"), domain.SignatureSHA512+"=") {
+			t.Errorf("attempt %d: signature %q doesn't verify: %v", i+1, r.Header.Get("X-Webhook-Signature"), err)
+		}
+	}
+
+	var payload Payload
+	if err := json.Unmarshal(reqs[2].Body, &payload); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	if payload.Event != EventSubmissionCreated || payload.FormID != "contact" || payload.FormName != "Contact" ||
+		payload.SubmissionID != "sub_1" || !payload.Timestamp.Equal(created) || payload.Data["email"] != "ada@example.com" || payload.Test {
+		t.Errorf("unexpected payload %+v", payload)
+	}
+	// The outcome is recorded once the response is read
+	deadline := time.Now().Add(5 * time.Second)
+	for total, _ := s.DeliveryStats("contact", created); total == 0 && time.Now().Before(deadline); total, _ = s.DeliveryStats("contact", created) {
+		time.Sleep(time.Millisecond)
+	}
+	if total, failed := s.DeliveryStats("contact", created); total != 1 || failed != 0 {
+		t.Errorf("expected 1 successful delivery in the stats, got %d (%d failed)", total, failed)
+	}
+
+	// A delivery failing every attempt counts as failed and isn't retried further
+	receiver.FailNext(3, http.StatusInternalServerError)
+	failures := make(chan string, 1)
+	s.SetFailureCallback(func(formID string) { failures <- formID })
+	s.TriggerSubmission(form, &domain.Submission{ID: "sub_2", CreatedAt: created}, map[string]interface{}{})
+	receiver.Wait(t, 6)
+	select {
+	case id := <-failures:
+		if id != "contact" {
+			t.Errorf("unexpected failure callback for %q", id)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("failure callback not called")
+	}
+	if n := len(receiver.Requests()); n != 6 {
+		t.Errorf("expected 3 attempts for the failing delivery, got %d requests in total", n)
+	}
+}
+
+func TestTestWebhookEndToEnd(t *testing.T) {
+	receiver := testharness.NewWebhookReceiver(t)
+	s := NewService()
+
+	if err := s.TestWebhook(receiver.URL(), Signing{Secret: "whsec"}); err != nil {
+		t.Fatalf("test webhook: %v", err)
+	}
+	r := receiver.Wait(t, 1)[0]
+	if r.Header.Get("X-Webhook-Test") != "true" {
+		t.Errorf("expected X-Webhook-Test, got %v", r.Header)
+	}
+	if err := Verify(Signing{Secret: "whsec"}, r.Header.Get("X-Webhook-Timestamp"), r.Body, r.Header.Get("X-Webhook-Signature"), DefaultTolerance, time.Now()); err != nil {
+		t.Errorf("signature doesn't verify: %v", err)
+	}
+
+	// Test webhooks aren't retried
+	receiver.FailNext(1, http.StatusNotFound)
+	if err := s.TestWebhook(receiver.URL(), Signing{}); err == nil {
+		t.Error("expected an error for a 404")
+	}
+	if r := receiver.Requests()[1]; r.Header.Get("X-Webhook-Signature") != "" {
+		t.Errorf("expected no signature without a secret, got %q", r.Header.Get("X-Webhook-Signature"))
+	}
+	if n := len(receiver.Requests()); n != 2 {
+		t.Errorf("expected 2 requests, got %d", n)
+	}
+}
+
+func TestTriggerSubmissionMeta(t *testing.T) {
+	receiver := testharness.NewWebhookReceiver(t)
+	s := NewService()
+	form := &domain.Form{PublicID: "contact", Name: "Contact", WebhookURL: receiver.URL()}
+	submission := &domain.Submission{ID: "sub_1", CreatedAt: time.Now(), Meta: domain.SubmissionMeta{
+		Server: domain.ServerMeta{IP: "203.0.113.7", Country: "DE", Referer: "https://site.example/contact", UserAgent: "Mozilla/5.0"},
+		Spam:   &domain.SpamMeta{Score: 20, Flags: []string{"no_timing"}, Threshold: 50},
+	}}
+
+	decode := func(r testharness.Request) map[string]interface{} {
+		t.Helper()
+		var payload map[string]interface{}
+		if err := json.Unmarshal(r.Body, &payload); err != nil {
+			t.Fatalf("decode payload: %v", err)
+		}
+		return payload
+	}
+
+	// Left out unless the form asks for it
+	s.TriggerSubmission(form, submission, map[string]interface{}{"email": "ada@example.com"})
+	if payload := decode(receiver.Wait(t, 1)[0]); payload["meta"] != nil {
+		t.Errorf("expected no meta, got %v", payload["meta"])
+	}
+
+	form.WebhookIncludeMeta = true
+	s.TriggerSubmission(form, submission, map[string]interface{}{"email": "ada@example.com"})
+	var payload Payload
+	if err := json.Unmarshal(receiver.Wait(t, 2)[1].Body, &payload); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	m := payload.Meta
+	if m == nil || m.IP != "203.0.113.7" || m.Country != "DE" || m.Referer != "https://site.example/contact" || m.UserAgent != "Mozilla/5.0" {
+		t.Fatalf("unexpected meta %+v", m)
+	}
+	if m.Spam == nil || m.Spam.Score != 20 || m.Spam.IsSpam || m.Spam.Threshold != 50 || len(m.Spam.Flags) != 1 {
+		t.Errorf("unexpected spam verdict %+v", m.Spam)
+	}
+}
+
+func TestWebhookRate(t *testing.T) {
+	receiver := testharness.NewWebhookReceiver(t)
+	s := NewService()
+	form := &domain.Form{PublicID: "contact", Name: "Contact", WebhookURL: receiver.URL(),
+		WebhookRate: &domain.WebhookRate{BatchInterval: 3600, MaxBatchSize: 3}}
+	data := map[string]interface{}{"email": "ada@example.com"}
+
+	// Test submissions skip the batch
+	s.TriggerSubmission(form, &domain.Submission{ID: "test_1", IsTest: true, CreatedAt: time.Now()}, data)
+	if r := receiver.Wait(t, 1)[0]; r.Header.Get("X-Webhook-Event") != EventSubmissionCreated {
+		t.Errorf("expected the test submission on its own, got %s", r.Header.Get("X-Webhook-Event"))
+	}
+
+	// A full batch is sent right away, without waiting for the interval
+	for _, id := range []string{"sub_1", "sub_2", "sub_3"} {
+		s.TriggerSubmission(form, &domain.Submission{ID: id, CreatedAt: time.Now()}, data)
+	}
+	r := receiver.Wait(t, 2)[1]
+	var payload BatchPayload
+	if err := json.Unmarshal(r.Body, &payload); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	if r.Header.Get("X-Webhook-Event") != EventSubmissionBatch || payload.Event != EventSubmissionBatch ||
+		payload.FormID != "contact" || len(payload.Submissions) != 3 {
+		t.Fatalf("unexpected batch %s %+v", r.Header.Get("X-Webhook-Event"), payload)
+	}
+	var first Payload
+	if err := json.Unmarshal(payload.Submissions[0], &first); err != nil || first.SubmissionID != "sub_1" || first.Data["email"] != "ada@example.com" {
+		t.Errorf("unexpected first submission %+v (%v)", first, err)
+	}
+
+	// A partial batch waits for the interval, or a flush
+	s.TriggerSubmission(form, &domain.Submission{ID: "sub_4", CreatedAt: time.Now()}, data)
+	time.Sleep(50 * time.Millisecond)
+	if n := len(receiver.Requests()); n != 2 {
+		t.Fatalf("expected the partial batch to wait, got %d requests", n)
+	}
+	if err := s.Flush(context.Background()); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	// Flushing waits for the delivery
+	if reqs := receiver.Requests(); len(reqs) != 3 {
+		t.Fatalf("expected the flushed batch delivered when Flush returns, got %d requests", len(reqs))
+	}
+	if err := json.Unmarshal(receiver.Requests()[2].Body, &payload); err != nil || len(payload.Submissions) != 1 {
+		t.Errorf("expected the flushed batch to hold 1 submission, got %+v (%v)", payload, err)
+	}
+
+	// Requests are spaced out to the rate limit
+	form.WebhookRate = &domain.WebhookRate{MaxPerMinute: 600}
+	for _, id := range []string{"sub_5", "sub_6", "sub_7"} {
+		s.TriggerSubmission(form, &domain.Submission{ID: id, CreatedAt: time.Now()}, data)
+	}
+	reqs := receiver.Wait(t, 6)[3:]
+	if gap := reqs[2].At.Sub(reqs[0].At); gap < 180*time.Millisecond {
+		t.Errorf("expected 3 requests at 600/minute to span at least 200ms, took %v", gap)
+	}
+}
+
+func TestWebhookRateQueueLimit(t *testing.T) {
+	receiver := testharness.NewWebhookReceiver(t)
+	deliveries := &memoryDeliveryLog{deliveries: map[string]domain.WebhookDelivery{}, done: make(chan string, 4)}
+	s := NewService()
+	s.maxQueued = 2
+	s.SetDeliveryLog(deliveries)
+	// One request a minute: the first is sent, the second waits for its slot
+	form := &domain.Form{ID: "internal", PublicID: "contact", Name: "Contact", WebhookURL: receiver.URL(),
+		WebhookRate: &domain.WebhookRate{MaxPerMinute: 1}}
+	data := map[string]interface{}{"email": "ada@example.com"}
+
+	for _, id := range []string{"sub_1", "sub_2", "sub_3"} {
+		s.TriggerSubmission(form, &domain.Submission{ID: id, CreatedAt: time.Now()}, data)
+	}
+	receiver.Wait(t, 1)
+
+	// The third doesn't fit in the queue and fails without being sent
+	var failed *domain.WebhookDelivery
+	for failed == nil {
+		select {
+		case id := <-deliveries.done:
+			if d, _ := deliveries.GetByID(context.Background(), id); d.Status == domain.WebhookDeliveryFailed {
+				failed = d
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("expected a delivery to fail with the queue full")
+		}
+	}
+	if len(failed.Attempts) != 1 || failed.Attempts[0].Error != "rate limit queue is full" || len(failed.Payload) == 0 {
+		t.Errorf("unexpected overflowing delivery %+v", failed)
+	}
+
+	// Flushing gives up on the waiting delivery at the deadline
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := s.Flush(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected Flush to stop at its deadline, got %v", err)
+	}
+	if n := len(receiver.Requests()); n != 1 {
+		t.Errorf("expected 1 request within the rate limit, got %d", n)
+	}
+}
+
+// fakeCodec resolves references from a map
+type fakeCodec map[string]string
+
+func (c fakeCodec) Seal(secret string) (string, error)   { return secret, nil }
+func (c fakeCodec) Unseal(stored string) (string, error) { return stored, nil }
+func (c fakeCodec) Current(string) bool                  { return true }
+func (c fakeCodec) Open(_ context.Context, stored string) (string, error) {
+	if v, ok := c[stored]; ok {
+		return v, nil
+	}
+	return "", errors.New("not found")
+}
+
+func TestSecretReference(t *testing.T) {
+	receiver := testharness.NewWebhookReceiver(t)
+	s := NewService()
+	s.backoff = time.Millisecond
+	form := &domain.Form{PublicID: "contact", Name: "Contact", WebhookURL: receiver.URL(), WebhookSecret: "vault:secret/data/forms#webhook"}
+
+	// Without a secret manager the delivery fails rather than signing with the reference
+	s.TriggerSubmission(form, &domain.Submission{ID: "sub_1", CreatedAt: time.Now()}, map[string]interface{}{})
+	time.Sleep(50 * time.Millisecond)
+	if n := len(receiver.Requests()); n != 0 {
+		t.Fatalf("expected no request without a resolvable secret, got %d", n)
+	}
+
+	s.SetSecretCodec(fakeCodec{"vault:secret/data/forms#webhook": "resolved"})
+	s.TriggerSubmission(form, &domain.Submission{ID: "sub_2", CreatedAt: time.Now()}, map[string]interface{}{})
+	r := receiver.Wait(t, 1)[0]
+	if err := Verify(Signing{Secret: "resolved"}, r.Header.Get("X-Webhook-Timestamp"), r.Body, r.Header.Get("X-Webhook-Signature"), DefaultTolerance, time.Now()); err != nil {
+		t.Errorf("expected the payload signed with the resolved secret: %v", err)
+	}
+	if form.WebhookSecret != "vault:secret/data/forms#webhook" {
+		t.Errorf("expected the form to keep its reference, got %q", form.WebhookSecret)
+	}
+}
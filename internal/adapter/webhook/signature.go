@@ -0,0 +1,71 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"headless_form/internal/core/domain"
+)
+
+// Signing is how a form's webhook payloads are signed
+type Signing struct {
+	Secret      string // No signature is sent without a secret
+	Algorithm   string // domain.SignatureSHA256 (default) or domain.SignatureSHA512
+	Timestamped bool   // Sign "timestamp.body" so receivers can reject replays
+}
+
+// SigningFor returns the form's webhook signing settings
+func SigningFor(form *domain.Form) Signing {
+	return Signing{Secret: form.WebhookSecret, Algorithm: form.WebhookSignatureAlgorithm, Timestamped: form.WebhookSignTimestamp}
+}
+
+// DefaultTolerance is how old a timestamped signature receivers should accept
+const DefaultTolerance = 5 * time.Minute
+
+// Signature verification errors
+var (
+	ErrSignatureMismatch = errors.New("webhook signature mismatch")
+	ErrTimestampExpired  = errors.New("webhook timestamp outside tolerance")
+)
+
+// Sign returns the X-Webhook-Signature value for a payload sent with the given
+// X-Webhook-Timestamp value: "<algorithm>=<hex HMAC>" over the body, or over
+// "timestamp.body" when sig.Timestamped is set.
+func Sign(sig Signing, timestamp string, body []byte) string {
+	alg := sig.Algorithm
+	hash := sha256.New
+	if alg == domain.SignatureSHA512 {
+		hash = sha512.New
+	} else {
+		alg = domain.SignatureSHA256
+	}
+
+	h := hmac.New(hash, []byte(sig.Secret))
+	if sig.Timestamped {
+		h.Write([]byte(timestamp + "."))
+	}
+	h.Write(body)
+	return alg + "=" + hex.EncodeToString(h.Sum(nil))
+}
+
+// Verify checks a received signature, as a receiver would. For timestamped
+// signatures the timestamp (RFC 3339) must also be within tolerance of now.
+func Verify(sig Signing, timestamp string, body []byte, signature string, tolerance time.Duration, now time.Time) error {
+	if !hmac.Equal([]byte(Sign(sig, timestamp, body)), []byte(signature)) {
+		return ErrSignatureMismatch
+	}
+	if sig.Timestamped {
+		at, err := time.Parse(time.RFC3339, timestamp)
+		if err != nil {
+			return ErrTimestampExpired
+		}
+		if d := now.Sub(at); d > tolerance || d < -tolerance {
+			return ErrTimestampExpired
+		}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	"headless_form/internal/core/domain"
+)
+
+func TestSign_DefaultMatchesLegacyScheme(t *testing.T) {
+	body := []byte(`{"event":"submission.created"}`)
+	h := hmac.New(sha256.New, []byte("secret"))
+	h.Write(body)
+	want := "sha256=" + hex.EncodeToString(h.Sum(nil))
+
+	if got := Sign(Signing{Secret: "secret"}, "2026-01-01T10:00:00Z", body); got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
+
+func TestSign_AlgorithmAndTimestamp(t *testing.T) {
+	body := []byte(`{}`)
+	ts := "2026-01-01T10:00:00Z"
+
+	sha512Sig := Sign(Signing{Secret: "secret", Algorithm: domain.SignatureSHA512}, ts, body)
+	if !strings.HasPrefix(sha512Sig, "sha512=") || len(sha512Sig) != len("sha512=")+128 {
+		t.Errorf("unexpected sha512 signature %s", sha512Sig)
+	}
+
+	plain := Sign(Signing{Secret: "secret"}, ts, body)
+	stamped := Sign(Signing{Secret: "secret", Timestamped: true}, ts, body)
+	if plain == stamped {
+		t.Error("expected the timestamp to change the signature")
+	}
+	if Sign(Signing{Secret: "secret", Timestamped: true}, "2026-01-01T10:00:01Z", body) == stamped {
+		t.Error("expected a different timestamp to change the signature")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"a":1}`)
+	sent := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	ts := sent.Format(time.RFC3339)
+	sig := Signing{Secret: "secret", Algorithm: domain.SignatureSHA512, Timestamped: true}
+	signature := Sign(sig, ts, body)
+
+	tests := []struct {
+		name      string
+		body      string
+		timestamp string
+		now       time.Time
+		want      error
+	}{
+		{"valid", `{"a":1}`, ts, sent.Add(time.Minute), nil},
+		{"tampered body", `{"a":2}`, ts, sent, ErrSignatureMismatch},
+		{"tampered timestamp", `{"a":1}`, sent.Add(time.Hour).Format(time.RFC3339), sent.Add(time.Hour), ErrSignatureMismatch},
+		{"replayed late", `{"a":1}`, ts, sent.Add(DefaultTolerance + time.Second), ErrTimestampExpired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Verify(sig, tt.timestamp, []byte(tt.body), signature, DefaultTolerance, tt.now); err != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
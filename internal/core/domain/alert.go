@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// AlertType identifies the condition an alert rule watches for
+type AlertType string
+
+const (
+	AlertNoSubmissions      AlertType = "no_submissions"       // No submissions within the window
+	AlertSpamRate           AlertType = "spam_rate"            // Spam percentage above threshold
+	AlertWebhookFailureRate AlertType = "webhook_failure_rate" // Webhook failure percentage above threshold
+)
+
+// Alert errors
+var (
+	ErrAlertNotFound    = errors.New("alert rule not found")
+	ErrInvalidAlertType = errors.New("alert type must be no_submissions, spam_rate or webhook_failure_rate")
+	ErrInvalidThreshold = errors.New("alert threshold must be between 0 and 100")
+)
+
+// AlertRule is a per-form threshold alert evaluated by the background alert job
+type AlertRule struct {
+	ID              string     `json:"id"`
+	FormID          string     `json:"form_id"`
+	Type            AlertType  `json:"type"`
+	Threshold       float64    `json:"threshold"`    // Percentage for rate alerts, unused for no_submissions
+	WindowHours     int        `json:"window_hours"` // Evaluation window (default 24)
+	Enabled         bool       `json:"enabled"`
+	LastTriggeredAt *time.Time `json:"last_triggered_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+// Validate checks the alert rule and applies defaults
+func (a *AlertRule) Validate() error {
+	switch a.Type {
+	case AlertNoSubmissions, AlertSpamRate, AlertWebhookFailureRate:
+	default:
+		return ErrInvalidAlertType
+	}
+	if a.Threshold < 0 || a.Threshold > 100 {
+		return ErrInvalidThreshold
+	}
+	if a.WindowHours <= 0 {
+		a.WindowHours = 24
+	}
+	return nil
+}
+
+// Window returns the evaluation window as a duration
+func (a *AlertRule) Window() time.Duration {
+	return time.Duration(a.WindowHours) * time.Hour
+}
+
+// FormActivity summarizes submissions for a form within a time window
+type FormActivity struct {
+	Submissions int `json:"submissions"`
+	Spam        int `json:"spam"`
+}
@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+// Audit actions
+const (
+	AuditActionSubmissionsDeleted = "submissions.bulk_deleted"
+	AuditActionFieldMigrated      = "submissions.field_migrated"
+	AuditActionSpamRescored       = "submissions.spam_rescored"
+	AuditActionRetentionApplied   = "submissions.retention_applied"
+)
+
+// AuditEntry records a destructive action taken on a form, for later review
+type AuditEntry struct {
+	ID        string                 `json:"id"`
+	FormID    string                 `json:"form_id"`
+	UserID    string                 `json:"user_id"`
+	Action    string                 `json:"action"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+	CreatedAt time.Time              `json:"created_at"`
+}
@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"errors"
+	"net/mail"
+	"strings"
+)
+
+// ErrInvalidAutoReply is returned for auto-replies that can't be sent
+var ErrInvalidAutoReply = errors.New("auto reply needs a field, a subject of at most 1000 bytes, an html or text body of at most 64KB, and a valid reply_to")
+
+// AutoReplyConfig emails submitters a confirmation, at the address they entered in
+// Field. Subject and bodies are Go templates over the submission, like EmailTemplate;
+// they're checked by the caller (see the email package).
+type AutoReplyConfig struct {
+	Field   string `json:"field"` // Field holding the submitter's email address
+	Subject string `json:"subject"`
+	HTML    string `json:"html,omitempty"`
+	Text    string `json:"text,omitempty"`
+	ReplyTo string `json:"reply_to,omitempty"` // Where answers to the confirmation go; the sender when empty
+}
+
+// Validate trims the field, subject and reply-to address and checks the limits
+func (c *AutoReplyConfig) Validate() error {
+	c.Field = strings.TrimSpace(c.Field)
+	c.Subject = strings.TrimSpace(c.Subject)
+	c.ReplyTo = strings.TrimSpace(c.ReplyTo)
+	if c.Field == "" || len(c.Field) > maxFieldText || c.Subject == "" || len(c.Subject) > MaxEmailSubjectTemplateSize {
+		return ErrInvalidAutoReply
+	}
+	if strings.TrimSpace(c.HTML) == "" && strings.TrimSpace(c.Text) == "" {
+		return ErrInvalidAutoReply
+	}
+	if len(c.HTML) > MaxEmailBodyTemplateSize || len(c.Text) > MaxEmailBodyTemplateSize {
+		return ErrInvalidAutoReply
+	}
+	if c.ReplyTo != "" && !isBareAddress(c.ReplyTo) {
+		return ErrInvalidAutoReply
+	}
+	return nil
+}
+
+// Recipient returns the address the submitter entered, or "" when the field doesn't
+// hold a single plain email address
+func (c *AutoReplyConfig) Recipient(data map[string]interface{}) string {
+	to, _ := data[c.Field].(string)
+	to = strings.TrimSpace(to)
+	if !isBareAddress(to) {
+		return ""
+	}
+	return to
+}
+
+// isBareAddress reports whether s is one email address without a display name
+func isBareAddress(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	return err == nil && addr.Address == s && addr.Name == ""
+}
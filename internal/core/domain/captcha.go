@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// Captcha errors
+var (
+	ErrInvalidCaptchaConfig = errors.New("captcha provider must be recaptcha, hcaptcha or turnstile, with its secret key")
+	ErrCaptchaFailed        = errors.New("captcha verification failed")
+)
+
+// CaptchaProvider is a captcha service whose tokens a form verifies
+type CaptchaProvider string
+
+const (
+	CaptchaRecaptcha CaptchaProvider = "recaptcha" // Google reCAPTCHA v2 or v3
+	CaptchaHCaptcha  CaptchaProvider = "hcaptcha"
+	CaptchaTurnstile CaptchaProvider = "turnstile" // Cloudflare Turnstile
+)
+
+// CaptchaConfig makes a form require a captcha token verified with the provider
+type CaptchaConfig struct {
+	Provider CaptchaProvider `json:"provider"`
+	Secret   string          `json:"secret"` // The provider's secret key; write-only, see MarshalJSON
+}
+
+// MarshalJSON leaves the secret out, so it's never sent back once set; has_secret tells
+// whether there is one. Stores keep the secret with their own encoding.
+func (c CaptchaConfig) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Provider  CaptchaProvider `json:"provider"`
+		HasSecret bool            `json:"has_secret"`
+	}{c.Provider, c.Secret != ""})
+}
+
+// Validate checks the provider and trims the secret
+func (c *CaptchaConfig) Validate() error {
+	c.Secret = strings.TrimSpace(c.Secret)
+	if c.Secret == "" || c.TokenField() == "" {
+		return ErrInvalidCaptchaConfig
+	}
+	return nil
+}
+
+// TokenField is the field the provider's widget adds to the form with the token
+func (c *CaptchaConfig) TokenField() string {
+	switch c.Provider {
+	case CaptchaRecaptcha:
+		return "g-recaptcha-response"
+	case CaptchaHCaptcha:
+		return "h-captcha-response"
+	case CaptchaTurnstile:
+		return "cf-turnstile-response"
+	}
+	return ""
+}
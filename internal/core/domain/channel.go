@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ErrInvalidChannel is returned for notification channels that can't be posted to
+var ErrInvalidChannel = errors.New("invalid notification channel")
+
+// Notification channel limits
+const (
+	MaxChannels          = 10
+	DefaultChannelFields = 5
+	MaxChannelFields     = 10
+)
+
+// ChannelType is the chat service a notification channel posts to
+type ChannelType string
+
+const (
+	ChannelSlack   ChannelType = "slack"   // Slack incoming webhook
+	ChannelDiscord ChannelType = "discord" // Discord channel webhook
+)
+
+// NotifyChannel posts new submissions to a chat channel through its incoming webhook,
+// alongside the notification emails
+type NotifyChannel struct {
+	Type   ChannelType `json:"type"`
+	URL    string      `json:"url"`              // The webhook URL the service gave out
+	Fields int         `json:"fields,omitempty"` // How many fields the message shows (default 5)
+}
+
+// Validate checks the URL is one of the service's webhooks and the field count,
+// defaulting it to DefaultChannelFields
+func (c *NotifyChannel) Validate() error {
+	c.URL = strings.TrimSpace(c.URL)
+	u, err := url.Parse(c.URL)
+	if err != nil || u.Scheme != "https" || u.User != nil {
+		return fmt.Errorf("%w: url must be an https webhook URL", ErrInvalidChannel)
+	}
+	host := strings.ToLower(u.Hostname())
+	switch c.Type {
+	case ChannelSlack:
+		if host != "hooks.slack.com" || !strings.HasPrefix(u.Path, "/services/") {
+			return fmt.Errorf("%w: slack url must be an incoming webhook, https://hooks.slack.com/services/…", ErrInvalidChannel)
+		}
+	case ChannelDiscord:
+		if host != "discord.com" && host != "discordapp.com" || !strings.HasPrefix(u.Path, "/api/webhooks/") {
+			return fmt.Errorf("%w: discord url must be a channel webhook, https://discord.com/api/webhooks/…", ErrInvalidChannel)
+		}
+	default:
+		return fmt.Errorf("%w: type must be slack or discord", ErrInvalidChannel)
+	}
+	if c.Fields == 0 {
+		c.Fields = DefaultChannelFields
+	}
+	if c.Fields < 1 || c.Fields > MaxChannelFields {
+		return fmt.Errorf("%w: fields must be 1-%d", ErrInvalidChannel, MaxChannelFields)
+	}
+	return nil
+}
+
+// ValidateChannels checks a form's notification channels
+func ValidateChannels(channels []NotifyChannel) error {
+	if len(channels) > MaxChannels {
+		return fmt.Errorf("%w: at most %d channels", ErrInvalidChannel, MaxChannels)
+	}
+	for i := range channels {
+		if err := channels[i].Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// MaxCommentLength caps the size of a comment body
+const MaxCommentLength = 5000
+
+// Comment errors
+var (
+	ErrCommentNotFound     = errors.New("comment not found")
+	ErrCommentBodyRequired = errors.New("comment body is required")
+	ErrCommentTooLong      = errors.New("comment must be at most 5000 characters")
+	ErrNotCommentAuthor    = errors.New("only the author can change this comment")
+)
+
+// Comment is a note left on a submission by a dashboard user. Replies point to
+// the comment they answer with ParentID.
+type Comment struct {
+	ID           string     `json:"id"`
+	SubmissionID string     `json:"submission_id"`
+	ParentID     string     `json:"parent_id,omitempty"`
+	UserID       string     `json:"user_id"`
+	AuthorName   string     `json:"author_name,omitempty"` // Filled in when listing
+	Body         string     `json:"body"`
+	Mentions     []string   `json:"mentions"` // IDs of the users mentioned in Body
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    *time.Time `json:"updated_at,omitempty"` // Set once the comment is edited
+}
+
+// Validate trims the body and checks its length
+func (c *Comment) Validate() error {
+	c.Body = strings.TrimSpace(c.Body)
+	if c.Body == "" {
+		return ErrCommentBodyRequired
+	}
+	if len([]rune(c.Body)) > MaxCommentLength {
+		return ErrCommentTooLong
+	}
+	return nil
+}
+
+// mentionRegex matches "@" followed by an email address, e.g. "@jane@example.com"
+var mentionRegex = regexp.MustCompile(`@([a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,})`)
+
+// MentionedEmails returns the distinct, lowercased emails mentioned in the body
+func (c *Comment) MentionedEmails() []string {
+	var emails []string
+	seen := make(map[string]bool)
+	for _, m := range mentionRegex.FindAllStringSubmatch(c.Body, -1) {
+		email := strings.ToLower(m[1])
+		if !seen[email] {
+			seen[email] = true
+			emails = append(emails, email)
+		}
+	}
+	return emails
+}
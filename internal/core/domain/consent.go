@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Consent errors
+var (
+	ErrConsentRequired       = errors.New("consent is required")
+	ErrConsentFieldRequired  = errors.New("at least one consent field is required")
+	ErrConsentVersionTooLong = errors.New("consent version must be less than 100 characters")
+)
+
+// ConsentConfig lists the checkboxes a submission must tick (terms, privacy policy, marketing opt-in...)
+type ConsentConfig struct {
+	Fields  []string `json:"fields"`  // Field names that must be present and checked
+	Version string   `json:"version"` // Version of the consent text shown to submitters, e.g. "2026-01"
+}
+
+// ConsentMeta records what a submitter agreed to
+type ConsentMeta struct {
+	Fields     []string  `json:"fields"`
+	Version    string    `json:"version,omitempty"`
+	AcceptedAt time.Time `json:"accepted_at"`
+}
+
+// Validate trims and de-duplicates the field names
+func (c *ConsentConfig) Validate() error {
+	seen := make(map[string]bool)
+	fields := make([]string, 0, len(c.Fields))
+	for _, f := range c.Fields {
+		f = strings.TrimSpace(f)
+		if f == "" || seen[f] {
+			continue
+		}
+		seen[f] = true
+		fields = append(fields, f)
+	}
+	if len(fields) == 0 {
+		return ErrConsentFieldRequired
+	}
+	c.Fields = fields
+	c.Version = strings.TrimSpace(c.Version)
+	if len(c.Version) > 100 {
+		return ErrConsentVersionTooLong
+	}
+	return nil
+}
+
+// Missing returns the consent fields that weren't checked in data
+func (c *ConsentConfig) Missing(data map[string]interface{}) []string {
+	var missing []string
+	for _, f := range c.Fields {
+		if !isChecked(data[f]) {
+			missing = append(missing, f)
+		}
+	}
+	return missing
+}
+
+// isChecked reports whether a submitted value means a ticked checkbox.
+// HTML forms send "on" (or the input's value); JSON clients send true.
+func isChecked(v interface{}) bool {
+	switch t := v.(type) {
+	case bool:
+		return t
+	case float64:
+		return t != 0
+	case string:
+		switch strings.ToLower(strings.TrimSpace(t)) {
+		case "", "0", "false", "off", "no":
+			return false
+		}
+		return true
+	}
+	return false
+}
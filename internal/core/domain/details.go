@@ -0,0 +1,136 @@
+package domain
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Device types told apart by the user agent
+const (
+	DeviceDesktop = "desktop"
+	DeviceMobile  = "mobile"
+	DeviceTablet  = "tablet"
+	DeviceBot     = "bot"
+)
+
+// SubmissionDetails are the submission's metadata sections read into flat fields, for the
+// dashboard's detail view. The sections themselves stay in the submission's meta.
+type SubmissionDetails struct {
+	// From _server
+	IP             string `json:"ip,omitempty"`
+	Country        string `json:"country,omitempty"`
+	Timezone       string `json:"timezone,omitempty"` // Estimated from the country
+	UserAgent      string `json:"user_agent,omitempty"`
+	Browser        string `json:"browser,omitempty"`         // e.g. Chrome, Firefox, Safari, Edge
+	BrowserVersion string `json:"browser_version,omitempty"` // Major version
+	OS             string `json:"os,omitempty"`              // e.g. Windows, macOS, iOS, Android, Linux
+	Device         string `json:"device,omitempty"`          // desktop, mobile, tablet or bot
+	Language       string `json:"language,omitempty"`        // The first language the browser asked for
+	Referer        string `json:"referer,omitempty"`
+	Origin         string `json:"origin,omitempty"`
+	Anonymized     bool   `json:"anonymized,omitempty"`
+
+	// From _spam
+	SpamScore     *int     `json:"spam_score,omitempty"`
+	SpamThreshold *int     `json:"spam_threshold,omitempty"`
+	IsSpam        bool     `json:"is_spam"`
+	SpamFlags     []string `json:"spam_flags,omitempty"`
+	MarkedSpam    bool     `json:"marked_spam,omitempty"` // By a user rather than the spam check
+
+	// From _client, as the client sent it
+	Client map[string]interface{} `json:"client,omitempty"`
+}
+
+// Details reads the metadata sections into flat fields
+func (m SubmissionMeta) Details() SubmissionDetails {
+	server := m.Server
+	d := SubmissionDetails{
+		IP:         server.IP,
+		Country:    server.Country,
+		Timezone:   server.EstimatedTZ,
+		UserAgent:  server.UserAgent,
+		Referer:    server.Referer,
+		Origin:     server.Origin,
+		Anonymized: server.Anonymized,
+		Client:     m.Client,
+	}
+	d.Browser, d.BrowserVersion, d.OS, d.Device = ParseUserAgent(server.UserAgent, server.IsMobile == "?1")
+	if lang, _, _ := strings.Cut(server.Language, ","); lang != "" {
+		lang, _, _ = strings.Cut(lang, ";")
+		d.Language = strings.TrimSpace(lang)
+	}
+	if m.Spam != nil {
+		d.SpamScore = &m.Spam.Score
+		d.SpamThreshold = &m.Spam.Threshold
+		d.IsSpam = m.Spam.IsSpam
+		d.SpamFlags = m.Spam.Flags
+		d.MarkedSpam = m.Spam.Manual
+	}
+	return d
+}
+
+// userAgentBrowsers are checked in order: most browsers also name the ones they're built on
+var userAgentBrowsers = []struct {
+	name    string
+	pattern *regexp.Regexp
+}{
+	{"Edge", regexp.MustCompile(`Edg(?:e|A|iOS)?/(\d+)`)},
+	{"Opera", regexp.MustCompile(`(?:OPR|Opera)/(\d+)`)},
+	{"Samsung Internet", regexp.MustCompile(`SamsungBrowser/(\d+)`)},
+	{"Firefox", regexp.MustCompile(`(?:Firefox|FxiOS)/(\d+)`)},
+	{"Chrome", regexp.MustCompile(`(?:Chrome|CriOS)/(\d+)`)},
+	{"Safari", regexp.MustCompile(`Version/(\d+)(?:\.\d+)*.*Safari/`)},
+}
+
+// userAgentOS are checked in order, for the same reason
+var userAgentOS = []struct {
+	name   string
+	marker string
+}{
+	{"iOS", "iPhone"},
+	{"iOS", "iPad"},
+	{"Android", "Android"},
+	{"Windows", "Windows"},
+	{"ChromeOS", "CrOS"},
+	{"macOS", "Mac OS X"},
+	{"Linux", "Linux"},
+}
+
+// userAgentBots mark crawlers and scripts
+var userAgentBots = regexp.MustCompile(`(?i)bot|crawl|spider|slurp|curl|wget|python|go-http-client|java/|headless`)
+
+// ParseUserAgent tells the browser with its major version, operating system and type of
+// device from a User-Agent header. mobile is the Sec-CH-UA-Mobile hint. Parts it can't
+// tell are empty.
+func ParseUserAgent(ua string, mobile bool) (browser, version, os, device string) {
+	if ua == "" {
+		if mobile {
+			device = DeviceMobile
+		}
+		return
+	}
+	for _, b := range userAgentBrowsers {
+		if m := b.pattern.FindStringSubmatch(ua); m != nil {
+			browser, version = b.name, m[1]
+			break
+		}
+	}
+	for _, o := range userAgentOS {
+		if strings.Contains(ua, o.marker) {
+			os = o.name
+			break
+		}
+	}
+
+	switch {
+	case userAgentBots.MatchString(ua):
+		device = DeviceBot
+	case strings.Contains(ua, "iPad") || strings.Contains(ua, "Tablet") || (os == "Android" && !strings.Contains(ua, "Mobile")):
+		device = DeviceTablet
+	case mobile || strings.Contains(ua, "Mobi") || os == "iOS":
+		device = DeviceMobile
+	default:
+		device = DeviceDesktop
+	}
+	return
+}
@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrInvalidEmailStatus is returned when filtering the email queue by an unknown status
+var ErrInvalidEmailStatus = errors.New("status must be pending, sent or dead")
+
+// EmailStatus is where a queued email stands
+type EmailStatus string
+
+const (
+	EmailPending EmailStatus = "pending" // Waiting for its first attempt or a retry
+	EmailSent    EmailStatus = "sent"
+	EmailDead    EmailStatus = "dead" // Every attempt failed; it won't be retried
+)
+
+// Valid reports whether s is a known status
+func (s EmailStatus) Valid() bool {
+	return s == EmailPending || s == EmailSent || s == EmailDead
+}
+
+// QueuedEmail is a rendered email waiting in the queue to be sent over SMTP, or the
+// record of one that was sent or given up on
+type QueuedEmail struct {
+	ID            string      `json:"id"`
+	To            []string    `json:"to"`
+	Subject       string      `json:"subject"`
+	Message       []byte      `json:"-"` // The full message, headers included, as sent over SMTP
+	Status        EmailStatus `json:"status"`
+	Attempts      int         `json:"attempts"`
+	LastError     string      `json:"last_error,omitempty"`
+	NextAttemptAt time.Time   `json:"next_attempt_at"`
+	CreatedAt     time.Time   `json:"created_at"`
+	UpdatedAt     time.Time   `json:"updated_at"`
+	SentAt        *time.Time  `json:"sent_at,omitempty"`
+}
@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrInvalidEmailTemplate is returned for empty or oversized email templates
+var ErrInvalidEmailTemplate = errors.New("email template needs a subject of at most 1000 bytes, or an html or text body of at most 64KB")
+
+// ErrInvalidEmailFieldLimit is returned for a field limit out of range
+var ErrInvalidEmailFieldLimit = errors.New("email_field_limit must be 0-100000 characters; 0 uses the default of 1000")
+
+// Email template limits
+const (
+	MaxEmailSubjectTemplateSize = 1000
+	MaxEmailBodyTemplateSize    = 64 * 1024
+)
+
+// Characters of each field value the built-in notification emails show; longer values
+// are cut, and the email links to the full submission
+const (
+	DefaultEmailFieldLimit = 1000
+	MaxEmailFieldLimit     = 100000
+)
+
+// ValidateEmailFieldLimit checks a form's field limit, 0 taking the default
+func ValidateEmailFieldLimit(limit int) error {
+	if limit < 0 || limit > MaxEmailFieldLimit {
+		return ErrInvalidEmailFieldLimit
+	}
+	return nil
+}
+
+// EmailTemplate replaces parts of the built-in submission notification with Go templates
+// over the submission, e.g. a subject of `New lead: {{.Fields.company}}`. Parts left empty
+// keep the built-in ones. Templates are checked by the caller (see the email package).
+type EmailTemplate struct {
+	Subject string `json:"subject,omitempty"`
+	HTML    string `json:"html,omitempty"`
+	Text    string `json:"text,omitempty"`
+}
+
+// Validate trims the subject and checks the template sets something within the limits
+func (t *EmailTemplate) Validate() error {
+	t.Subject = strings.TrimSpace(t.Subject)
+	if t.Subject == "" && strings.TrimSpace(t.HTML) == "" && strings.TrimSpace(t.Text) == "" {
+		return ErrInvalidEmailTemplate
+	}
+	if len(t.Subject) > MaxEmailSubjectTemplateSize || len(t.HTML) > MaxEmailBodyTemplateSize || len(t.Text) > MaxEmailBodyTemplateSize {
+		return ErrInvalidEmailTemplate
+	}
+	return nil
+}
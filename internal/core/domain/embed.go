@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+)
+
+// FrameSelf allows a form's hosted pages to be framed by this server's own pages
+const FrameSelf = "'self'"
+
+// ErrInvalidFrameAncestor is returned for frame_ancestors entries that aren't origins
+var ErrInvalidFrameAncestor = errors.New("frame_ancestors entries must be 'self' or origins like https://example.com or https://*.example.com")
+
+// maxFrameAncestors caps the allow-list so the Content-Security-Policy header stays small
+const maxFrameAncestors = 20
+
+// NormalizeFrameAncestors validates a frame-ancestors allow-list, lowercasing and
+// de-duplicating its entries. Entries are 'self' or http(s) origins, optionally
+// with a leading "*." subdomain wildcard. Paths, queries and bare "*" are rejected.
+func NormalizeFrameAncestors(list []string) ([]string, error) {
+	seen := make(map[string]bool)
+	out := make([]string, 0, len(list))
+	for _, a := range list {
+		a = strings.ToLower(strings.TrimSpace(a))
+		if a == "" || seen[a] {
+			continue
+		}
+		if a != FrameSelf && !validFrameOrigin(a) {
+			return nil, ErrInvalidFrameAncestor
+		}
+		seen[a] = true
+		out = append(out, a)
+	}
+	if len(out) > maxFrameAncestors {
+		return nil, ErrInvalidFrameAncestor
+	}
+	return out, nil
+}
+
+// validFrameOrigin reports whether s is scheme://host[:port] with an optional *. wildcard
+func validFrameOrigin(s string) bool {
+	u, err := url.Parse(strings.Replace(s, "://*.", "://wildcard.", 1))
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Hostname() == "" {
+		return false
+	}
+	if u.User != nil || (u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.Fragment != "" || strings.HasSuffix(s, "/") {
+		return false
+	}
+	// Only characters valid in hostnames, so an entry can't inject CSP directives
+	for _, r := range u.Host {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '.' || r == '-' || r == ':') {
+			return false
+		}
+	}
+	return true
+}
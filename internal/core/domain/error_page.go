@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+)
+
+// ErrInvalidErrorPage is returned for error page settings that can't be used
+var ErrInvalidErrorPage = errors.New("error page needs an http(s) url or a message, and a message of at most 1000 characters")
+
+// maxErrorPageMessage caps the message shown on the hosted error page
+const maxErrorPageMessage = 1000
+
+// ErrorPageConfig changes how rejected browser form posts are answered. Instead of the
+// JSON error, people are sent to URL with a ?reason= code (e.g. rate_limited,
+// content_rejected), or shown a hosted error page with Message when there's no URL.
+type ErrorPageConfig struct {
+	URL     string `json:"url,omitempty"`
+	Message string `json:"message,omitempty"` // Replaces the reason's own message on the hosted page
+}
+
+// Validate trims the settings and checks the URL is an absolute http(s) one
+func (c *ErrorPageConfig) Validate() error {
+	c.URL = strings.TrimSpace(c.URL)
+	c.Message = strings.TrimSpace(c.Message)
+	if (c.URL == "" && c.Message == "") || len(c.Message) > maxErrorPageMessage {
+		return ErrInvalidErrorPage
+	}
+	if c.URL != "" {
+		u, err := url.Parse(c.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return ErrInvalidErrorPage
+		}
+	}
+	return nil
+}
+
+// RedirectURL returns the URL with the reason added to its query
+func (c *ErrorPageConfig) RedirectURL(reason string) string {
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return c.URL
+	}
+	q := u.Query()
+	q.Set("reason", reason)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
@@ -0,0 +1,273 @@
+package domain
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// Export permission errors
+var (
+	ErrInvalidExportSettings = errors.New("export roles must be user, form_token, team_owner, team_editor or team_viewer, each set to full, none or a profile id; profiles need a unique id (a-z, 0-9, -) and hash or remove for pii and each field; at most 20 profiles")
+	ErrExportNotAllowed      = errors.New("your role may not read or export this form's submissions")
+)
+
+// MaxExportProfiles is how many masked export profiles the site can have
+const MaxExportProfiles = 20
+
+// ExportRole is who runs an export, as the export settings know them. Admins aren't
+// one: they may always export everything.
+type ExportRole string
+
+const (
+	ExportRoleUser       ExportRole = "user"        // A user exporting a form they own
+	ExportRoleFormToken  ExportRole = "form_token"  // A form API token
+	ExportRoleTeamOwner  ExportRole = "team_owner"  // An owner of the form's team
+	ExportRoleTeamEditor ExportRole = "team_editor" // An editor of the form's team
+	ExportRoleTeamViewer ExportRole = "team_viewer" // A viewer of the form's team
+)
+
+// TeamExportRole returns the export role of a member of the form's team with role r
+func TeamExportRole(r TeamRole) ExportRole {
+	return ExportRole("team_" + string(r))
+}
+
+// Valid reports whether r is a known role
+func (r ExportRole) Valid() bool {
+	switch r {
+	case ExportRoleUser, ExportRoleFormToken, ExportRoleTeamOwner, ExportRoleTeamEditor, ExportRoleTeamViewer:
+		return true
+	}
+	return false
+}
+
+// What a role may export, unless it's the ID of a profile masking the export
+const (
+	ExportFull = "full" // Every column as stored; the default
+	ExportNone = "none" // Nothing: exports are refused
+)
+
+// MaskAction is what a masked export does with a column
+type MaskAction string
+
+const (
+	MaskNone   MaskAction = ""       // Exported as stored
+	MaskHash   MaskAction = "hash"   // Replaced by the keyed hash of its value, so rows can still be matched up
+	MaskRemove MaskAction = "remove" // Left out of the export
+)
+
+// Valid reports whether a is a known action
+func (a MaskAction) Valid() bool {
+	return a == MaskNone || a == MaskHash || a == MaskRemove
+}
+
+// exportProfileID is what a profile's id, used in the role settings, may look like
+var exportProfileID = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,31}$`)
+
+// ExportProfile masks personal data in the exports of roles set to use it
+type ExportProfile struct {
+	ID     string                `json:"id"`
+	Name   string                `json:"name,omitempty"`
+	PII    MaskAction            `json:"pii,omitempty"`    // For the IP address, user agent, client metadata and every email field
+	Fields map[string]MaskAction `json:"fields,omitempty"` // By field name; overrides pii
+
+	// HashKey keys the hashes, so values can't be recovered by hashing guesses. It's the
+	// server's, set when the settings are loaded rather than stored with them.
+	HashKey []byte `json:"-"`
+}
+
+// ExportSettings decide who may read and export submissions, and what of them. The same
+// profile masks every way a role reads them: lists, single submissions, PDFs, the live
+// stream, file downloads and exports.
+type ExportSettings struct {
+	Roles    map[ExportRole]string `json:"roles"` // full (default), none or a profile ID
+	Profiles []ExportProfile       `json:"profiles"`
+}
+
+// Validate checks the roles and profiles and tidies their fields
+func (s *ExportSettings) Validate() error {
+	if len(s.Profiles) > MaxExportProfiles {
+		return ErrInvalidExportSettings
+	}
+	seen := make(map[string]bool, len(s.Profiles))
+	for i := range s.Profiles {
+		p := &s.Profiles[i]
+		p.ID = strings.ToLower(strings.TrimSpace(p.ID))
+		p.Name = strings.TrimSpace(p.Name)
+		if !exportProfileID.MatchString(p.ID) || p.ID == ExportFull || p.ID == ExportNone || seen[p.ID] || !p.PII.Valid() {
+			return ErrInvalidExportSettings
+		}
+		seen[p.ID] = true
+		for name, action := range p.Fields {
+			if name == "" || !action.Valid() {
+				return ErrInvalidExportSettings
+			}
+		}
+	}
+	for role, access := range s.Roles {
+		if !role.Valid() || (access != ExportFull && access != ExportNone && !seen[access]) {
+			return ErrInvalidExportSettings
+		}
+	}
+	return nil
+}
+
+// Profile returns the profile with the ID, or nil
+func (s *ExportSettings) Profile(id string) *ExportProfile {
+	if s == nil {
+		return nil
+	}
+	for i := range s.Profiles {
+		if s.Profiles[i].ID == id {
+			return &s.Profiles[i]
+		}
+	}
+	return nil
+}
+
+// Access returns the profile masking role's exports, nil for full exports, or
+// ErrExportNotAllowed. Without settings, and for admins (role ""), exports are full.
+func (s *ExportSettings) Access(role ExportRole) (*ExportProfile, error) {
+	if s == nil || role == "" {
+		return nil, nil
+	}
+	switch access := s.Roles[role]; access {
+	case "", ExportFull:
+		return nil, nil
+	case ExportNone:
+		return nil, ErrExportNotAllowed
+	default:
+		if p := s.Profile(access); p != nil {
+			return p, nil
+		}
+		// A role pointing at a profile that's gone exports nothing rather than everything
+		return nil, ErrExportNotAllowed
+	}
+}
+
+// action returns what the profile does with the field
+func (p *ExportProfile) action(name string, field *FormField) MaskAction {
+	if a, ok := p.Fields[name]; ok {
+		return a
+	}
+	if field != nil && field.Type == FieldTypeEmail {
+		return p.PII
+	}
+	return MaskNone
+}
+
+// Masks reports whether the profile hashes or removes the form's field name
+func (p *ExportProfile) Masks(name string, form *Form) bool {
+	return p != nil && p.action(name, form.Field(name)) != MaskNone
+}
+
+// Mask returns a copy of the submission with its fields, IP address, user agent and
+// client metadata hashed or removed as the profile says, and without the files uploaded
+// to masked fields or the links found in them. Without a profile it's sub itself.
+func (p *ExportProfile) Mask(sub *Submission, form *Form) *Submission {
+	if p == nil {
+		return sub
+	}
+	out := *sub
+	// Numbers stay json.Number so the fields left as they are keep their digits
+	var data map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(sub.Data))
+	dec.UseNumber()
+	if dec.Decode(&data) == nil && data != nil {
+		for name, value := range data {
+			switch p.action(name, form.Field(name)) {
+			case MaskHash:
+				data[name] = p.hashValue(value)
+			case MaskRemove:
+				delete(data, name)
+			}
+		}
+		out.Data, _ = json.Marshal(data)
+	}
+
+	// A file can't be hashed usefully, so a masked field's files are left out either way,
+	// and so is what checking its link found
+	out.Meta.Files = nil
+	for _, f := range sub.Meta.Files {
+		if !p.Masks(f.Field, form) {
+			out.Meta.Files = append(out.Meta.Files, f)
+		}
+	}
+	out.Meta.Links = nil
+	for _, l := range sub.Meta.Links {
+		if !p.Masks(l.Field, form) {
+			out.Meta.Links = append(out.Meta.Links, l)
+		}
+	}
+
+	switch p.PII {
+	case MaskHash:
+		out.Meta.Server.IP = p.hashText(out.Meta.Server.IP)
+		out.Meta.Server.UserAgent = p.hashText(out.Meta.Server.UserAgent)
+		if sub.Meta.Client != nil {
+			out.Meta.Client = make(map[string]interface{}, len(sub.Meta.Client))
+			for k, v := range sub.Meta.Client {
+				out.Meta.Client[k] = p.hashValue(v)
+			}
+		}
+	case MaskRemove:
+		out.Meta.Server.IP = ""
+		out.Meta.Server.UserAgent = ""
+		out.Meta.Client = nil
+	}
+	return &out
+}
+
+// MaskForm returns a copy of the form whose fields describe masked submissions:
+// removed fields are left out and hashed ones hold text. Without a profile it's form itself.
+func (p *ExportProfile) MaskForm(form *Form) *Form {
+	if p == nil {
+		return form
+	}
+	out := *form
+	out.Fields = nil
+	for _, f := range form.Fields {
+		switch p.action(f.Name, &f) {
+		case MaskHash:
+			f.Type = FieldTypeText
+		case MaskRemove:
+			continue
+		}
+		out.Fields = append(out.Fields, f)
+	}
+	return &out
+}
+
+// hashValue returns the hash of a value's text; empty values stay empty
+func (p *ExportProfile) hashValue(v interface{}) interface{} {
+	var text string
+	switch t := v.(type) {
+	case nil:
+		return nil
+	case string:
+		if t == "" {
+			return t
+		}
+		text = t
+	default:
+		b, _ := json.Marshal(t)
+		text = string(b)
+	}
+	return p.hashText(text)
+}
+
+// hashText returns the hex HMAC-SHA256 of s keyed with the profile's HashKey; empty
+// text stays empty
+func (p *ExportProfile) hashText(s string) string {
+	if s == "" {
+		return s
+	}
+	mac := hmac.New(sha256.New, p.HashKey)
+	mac.Write([]byte(s))
+	return hex.EncodeToString(mac.Sum(nil))
+}
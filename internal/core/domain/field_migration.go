@@ -0,0 +1,115 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+)
+
+// Field migration errors
+var (
+	ErrInvalidFieldMigration = errors.New("from is required, and to must differ from it unless a transform is set")
+	ErrInvalidFieldTransform = errors.New("transform must be trim, lower or upper")
+)
+
+// FieldTransform rewrites a field's text values during a migration
+type FieldTransform string
+
+const (
+	FieldTransformNone  FieldTransform = ""
+	FieldTransformTrim  FieldTransform = "trim"
+	FieldTransformLower FieldTransform = "lower"
+	FieldTransformUpper FieldTransform = "upper"
+)
+
+// MaxMigrationSamples caps the before/after examples in a migration preview
+const MaxMigrationSamples = 10
+
+// FieldMigration renames a field key across a form's submissions (e.g. "mail" to
+// "email"), optionally transforming its text values
+type FieldMigration struct {
+	From      string         `json:"from"`
+	To        string         `json:"to"`                  // Defaults to From, to only transform values
+	Transform FieldTransform `json:"transform,omitempty"` // Applied to text values; other values are kept as is
+	Overwrite bool           `json:"overwrite"`           // Replace values already under To; such submissions are skipped otherwise
+}
+
+// Validate checks the field names and transform, defaulting To to From
+func (m *FieldMigration) Validate() error {
+	m.From = strings.TrimSpace(m.From)
+	m.To = strings.TrimSpace(m.To)
+	if m.To == "" {
+		m.To = m.From
+	}
+	switch m.Transform {
+	case FieldTransformNone, FieldTransformTrim, FieldTransformLower, FieldTransformUpper:
+	default:
+		return ErrInvalidFieldTransform
+	}
+	if m.From == "" || (m.From == m.To && m.Transform == FieldTransformNone) {
+		return ErrInvalidFieldMigration
+	}
+	return nil
+}
+
+// Apply migrates one submission's data in place. It reports whether the data changed,
+// and whether it was left alone because To already holds a value and Overwrite is off.
+func (m FieldMigration) Apply(data map[string]interface{}) (changed, conflict bool) {
+	value, ok := data[m.From]
+	if !ok {
+		return false, false
+	}
+	if m.To != m.From {
+		if _, taken := data[m.To]; taken && !m.Overwrite {
+			return false, true
+		}
+	}
+	migrated := m.transform(value)
+	if m.To == m.From && migrated == value {
+		return false, false
+	}
+	delete(data, m.From)
+	data[m.To] = migrated
+	return true, false
+}
+
+func (m FieldMigration) transform(v interface{}) interface{} {
+	s, ok := v.(string)
+	if !ok {
+		return v
+	}
+	switch m.Transform {
+	case FieldTransformTrim:
+		return strings.TrimSpace(s)
+	case FieldTransformLower:
+		return strings.ToLower(s)
+	case FieldTransformUpper:
+		return strings.ToUpper(s)
+	}
+	return s
+}
+
+// FieldMigrationPreview shows what a migration would do without changing anything
+type FieldMigrationPreview struct {
+	Scanned   int                    `json:"scanned"`   // Submissions looked at
+	Matched   int                    `json:"matched"`   // Submissions that would change
+	Conflicts int                    `json:"conflicts"` // Submissions skipped because To already holds a value
+	Samples   []FieldMigrationSample `json:"samples"`   // Up to MaxMigrationSamples of the changes
+}
+
+// FieldMigrationSample is one submission's migrated field before and after
+type FieldMigrationSample struct {
+	SubmissionID string                 `json:"submission_id"`
+	Before       map[string]interface{} `json:"before"`
+	After        map[string]interface{} `json:"after"`
+}
+
+// FieldMigrationResult is the result of a field migration task
+type FieldMigrationResult struct {
+	FormID       string `json:"form_id"`
+	From         string `json:"from"`
+	To           string `json:"to"`
+	Scanned      int    `json:"scanned"`
+	Updated      int    `json:"updated"`
+	Conflicts    int    `json:"conflicts"`
+	FieldRenamed bool   `json:"field_renamed"` // The form's field settings (labels, type, rules) now use To
+}
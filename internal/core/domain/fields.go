@@ -0,0 +1,373 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Field schema errors
+var (
+	ErrFieldNameRequired = errors.New("every field needs a name")
+	ErrDuplicateField    = errors.New("field names must be unique")
+	ErrTooManyFields     = errors.New("a form can have at most 200 fields")
+	ErrFieldTextTooLong  = errors.New("field names, labels, placeholders and help text must be at most 500 characters")
+	ErrInvalidLocale     = errors.New("translations must be keyed by language tags like en, de or pt-BR")
+	ErrInvalidFieldType  = errors.New("field type must be text, email, number, url, date or file_url")
+	ErrInvalidFieldRule  = errors.New("min_length and max_length must be positive, with min_length at most max_length")
+	ErrInvalidPattern    = errors.New("field pattern must be a valid regular expression of at most 500 characters")
+)
+
+const (
+	maxFields    = 200
+	maxFieldText = 500
+)
+
+// localeRegex matches lowercased language tags like "de", "pt-br" or "zh-hant-tw"
+var localeRegex = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,8})*$`)
+
+// FieldText is what a form shows for a field in one language
+type FieldText struct {
+	Label       string `json:"label,omitempty"`
+	Placeholder string `json:"placeholder,omitempty"`
+	Help        string `json:"help,omitempty"`
+}
+
+// FieldType is the kind of value a field holds
+type FieldType string
+
+const (
+	FieldTypeText    FieldType = "text" // Default: any value
+	FieldTypeEmail   FieldType = "email"
+	FieldTypeNumber  FieldType = "number"
+	FieldTypeURL     FieldType = "url"
+	FieldTypeDate    FieldType = "date"     // A day like 2026-10-16, optionally with a time
+	FieldTypeFileURL FieldType = "file_url" // An https link to a file, checked against the field's FileURL rules
+)
+
+// FieldRules are the checks a submitted value must pass
+type FieldRules struct {
+	Type      FieldType `json:"type,omitempty"`
+	Required  bool      `json:"required,omitempty"`
+	MinLength int       `json:"min_length,omitempty"` // In characters
+	MaxLength int       `json:"max_length,omitempty"`
+	Pattern   string    `json:"pattern,omitempty"` // Must match the whole value, like HTML's pattern attribute
+
+	FileURL *FileURLRules `json:"file_url,omitempty"` // Only used by file_url fields
+}
+
+// FormField describes a field of the form: the key submissions use for it, its
+// default text, translations of that text keyed by language tag, and the rules
+// submitted values are validated against
+type FormField struct {
+	Name string `json:"name"`
+	FieldText
+	Translations map[string]FieldText `json:"translations,omitempty"`
+	FieldRules
+}
+
+// FieldError is why a submitted value was rejected
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"` // required, type, min_length, max_length, pattern, host or max_size
+	Message string `json:"message"`
+}
+
+// FieldErrors lists every field of a submission that broke the form's rules
+type FieldErrors []FieldError
+
+func (e FieldErrors) Error() string {
+	if len(e) == 0 {
+		return "invalid fields"
+	}
+	return e[0].Message
+}
+
+// NormalizeLocale lowercases a language tag ("pt_BR" -> "pt-br") and reports whether it's valid
+func NormalizeLocale(tag string) (string, bool) {
+	tag = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"))
+	return tag, localeRegex.MatchString(tag)
+}
+
+// NormalizeFields validates a field schema, trimming its text and lowercasing translation keys
+func NormalizeFields(fields []FormField) ([]FormField, error) {
+	if len(fields) > maxFields {
+		return nil, ErrTooManyFields
+	}
+	seen := make(map[string]bool)
+	out := make([]FormField, 0, len(fields))
+	for _, f := range fields {
+		f.Name = strings.TrimSpace(f.Name)
+		if f.Name == "" {
+			return nil, ErrFieldNameRequired
+		}
+		if seen[f.Name] {
+			return nil, ErrDuplicateField
+		}
+		seen[f.Name] = true
+
+		var ok bool
+		if f.FieldText, ok = normalizeFieldText(f.FieldText); !ok || len(f.Name) > maxFieldText {
+			return nil, ErrFieldTextTooLong
+		}
+		translations := make(map[string]FieldText, len(f.Translations))
+		for tag, text := range f.Translations {
+			locale, valid := NormalizeLocale(tag)
+			if !valid {
+				return nil, ErrInvalidLocale
+			}
+			if text, ok = normalizeFieldText(text); !ok {
+				return nil, ErrFieldTextTooLong
+			}
+			translations[locale] = text
+		}
+		f.Translations = nil
+		if len(translations) > 0 {
+			f.Translations = translations
+		}
+		if err := normalizeRules(&f.FieldRules); err != nil {
+			return nil, err
+		}
+		out = append(out, f)
+	}
+	return out, nil
+}
+
+func normalizeRules(r *FieldRules) error {
+	switch r.Type {
+	case "", FieldTypeText:
+		r.Type = ""
+	case FieldTypeEmail, FieldTypeNumber, FieldTypeURL, FieldTypeDate:
+	case FieldTypeFileURL:
+		if r.FileURL != nil {
+			if err := r.FileURL.Validate(); err != nil {
+				return err
+			}
+		}
+	default:
+		return ErrInvalidFieldType
+	}
+	if r.Type != FieldTypeFileURL {
+		r.FileURL = nil
+	}
+	if r.MinLength < 0 || r.MaxLength < 0 || (r.MaxLength > 0 && r.MinLength > r.MaxLength) {
+		return ErrInvalidFieldRule
+	}
+	if r.Pattern != "" {
+		if len(r.Pattern) > maxFieldText {
+			return ErrInvalidPattern
+		}
+		if _, err := r.compilePattern(); err != nil {
+			return ErrInvalidPattern
+		}
+	}
+	return nil
+}
+
+// compilePattern anchors the pattern so it has to match the whole value
+func (r FieldRules) compilePattern() (*regexp.Regexp, error) {
+	return regexp.Compile(`^(?:` + r.Pattern + `)$`)
+}
+
+func normalizeFieldText(t FieldText) (FieldText, bool) {
+	t.Label = strings.TrimSpace(t.Label)
+	t.Placeholder = strings.TrimSpace(t.Placeholder)
+	t.Help = strings.TrimSpace(t.Help)
+	return t, len(t.Label) <= maxFieldText && len(t.Placeholder) <= maxFieldText && len(t.Help) <= maxFieldText
+}
+
+// Text returns the field's text in locale ("" for the default text). Parts the
+// translation leaves out fall back to the default text.
+func (f FormField) Text(locale string) FieldText {
+	t, ok := f.Translations[locale]
+	if !ok {
+		return f.FieldText
+	}
+	if t.Label == "" {
+		t.Label = f.Label
+	}
+	if t.Placeholder == "" {
+		t.Placeholder = f.Placeholder
+	}
+	if t.Help == "" {
+		t.Help = f.Help
+	}
+	return t
+}
+
+// Locales returns the language tags the form's fields are translated into, sorted
+func (f *Form) Locales() []string {
+	seen := make(map[string]bool)
+	locales := []string{}
+	for _, field := range f.Fields {
+		for tag := range field.Translations {
+			if !seen[tag] {
+				seen[tag] = true
+				locales = append(locales, tag)
+			}
+		}
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// MatchLocale picks the form's best translation for the preferred language tags,
+// most preferred first. "de-at" matches a "de" translation and "de" matches
+// "de-at" if that's the only German one. Returns "" for the default text.
+func (f *Form) MatchLocale(preferred []string) string {
+	locales := f.Locales()
+	for _, want := range preferred {
+		want, ok := NormalizeLocale(want)
+		if !ok {
+			continue
+		}
+		for _, l := range locales {
+			if l == want {
+				return l
+			}
+		}
+		base, _, _ := strings.Cut(want, "-")
+		for _, l := range locales {
+			if l == base || strings.HasPrefix(l, base+"-") {
+				return l
+			}
+		}
+	}
+	return ""
+}
+
+// Field returns the schema of the named field, or nil if the schema doesn't list it
+func (f *Form) Field(name string) *FormField {
+	for i := range f.Fields {
+		if f.Fields[i].Name == name {
+			return &f.Fields[i]
+		}
+	}
+	return nil
+}
+
+// Labels returns each field's label in locale, leaving out fields without one
+func (f *Form) Labels(locale string) map[string]string {
+	labels := make(map[string]string, len(f.Fields))
+	for _, field := range f.Fields {
+		if label := field.Text(locale).Label; label != "" {
+			labels[field.Name] = label
+		}
+	}
+	return labels
+}
+
+// ValidateData checks submitted data against the rules of the form's fields and
+// returns one error per failing field, or nil. Keys outside the schema aren't checked.
+func (f *Form) ValidateData(data map[string]interface{}) error {
+	var errs FieldErrors
+	for _, field := range f.Fields {
+		if err := field.check(data[field.Name]); err != nil {
+			errs = append(errs, *err)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// check validates one submitted value; nil, "" and empty lists count as missing
+func (f FormField) check(v interface{}) *FieldError {
+	fail := func(rule, format string, args ...interface{}) *FieldError {
+		return &FieldError{Field: f.Name, Rule: rule, Message: f.Name + " " + fmt.Sprintf(format, args...)}
+	}
+
+	var text string
+	switch val := v.(type) {
+	case nil:
+	case string:
+		text = strings.TrimSpace(val)
+	case float64:
+		text = strconv.FormatFloat(val, 'f', -1, 64)
+	case bool:
+		text = strconv.FormatBool(val)
+	case []interface{}:
+		if len(val) > 0 {
+			text = "[list]"
+		}
+	default:
+		text = "[object]"
+	}
+	if text == "" {
+		if f.Required {
+			return fail("required", "is required")
+		}
+		return nil
+	}
+
+	switch f.Type {
+	case FieldTypeNumber:
+		if _, isNumber := v.(float64); !isNumber {
+			s, isText := v.(string)
+			if _, err := strconv.ParseFloat(strings.TrimSpace(s), 64); !isText || err != nil {
+				return fail("type", "must be a number")
+			}
+		}
+	case FieldTypeEmail:
+		if addr, err := mail.ParseAddress(text); err != nil || addr.Address != text || addr.Name != "" {
+			return fail("type", "must be a valid email address")
+		}
+	case FieldTypeURL:
+		if u, err := url.Parse(text); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fail("type", "must be an http or https URL")
+		}
+	case FieldTypeDate:
+		if _, ok := ParseFieldDate(text, time.UTC); !ok {
+			return fail("type", "must be a date like 2006-01-02")
+		}
+	case FieldTypeFileURL:
+		u, err := url.Parse(text)
+		if err != nil || u.Scheme != "https" || u.Host == "" || u.User != nil {
+			return fail("type", "must be an https link")
+		}
+		if f.FileURL != nil && !f.FileURL.AllowsHost(u.Hostname()) {
+			return fail("host", "must link to an allowed site")
+		}
+	}
+
+	// Length and pattern rules only apply to text
+	s, isText := v.(string)
+	if !isText {
+		return nil
+	}
+	s = strings.TrimSpace(s)
+	if n := utf8.RuneCountInString(s); f.MinLength > 0 && n < f.MinLength {
+		return fail("min_length", "must be at least %d characters", f.MinLength)
+	} else if f.MaxLength > 0 && n > f.MaxLength {
+		return fail("max_length", "must be at most %d characters", f.MaxLength)
+	}
+	if f.Pattern != "" {
+		if re, err := f.compilePattern(); err == nil && !re.MatchString(s) {
+			return fail("pattern", "has an invalid format")
+		}
+	}
+	return nil
+}
+
+// fieldDateLayouts are the formats date fields accept: HTML's date and
+// datetime-local inputs, and RFC 3339
+var fieldDateLayouts = []string{"2006-01-02", "2006-01-02T15:04", "2006-01-02T15:04:05", time.RFC3339}
+
+// ParseFieldDate parses a date field's value. Values without a timezone are read in loc.
+func ParseFieldDate(s string, loc *time.Location) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	for _, layout := range fieldDateLayouts {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
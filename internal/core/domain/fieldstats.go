@@ -0,0 +1,251 @@
+package domain
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	numberBuckets  = 10  // Equal-width buckets in a number field's histogram
+	maxDailySpan   = 92  // Date histograms switch from days to months past this many days
+	maxMonthlySpan = 120 // and from months to years past this many months
+)
+
+// FieldStats aggregates the submitted values of a number or date field
+type FieldStats struct {
+	Field   string       `json:"field"`
+	Type    FieldType    `json:"type"`
+	Count   int          `json:"count"`   // Submissions with a value of the field's type
+	Missing int          `json:"missing"` // Submissions with no value, or one that isn't of the field's type
+	Number  *NumberStats `json:"number,omitempty"`
+	Date    *DateStats   `json:"date,omitempty"`
+}
+
+// NumberStats summarizes a number field; nil when no submission has a number
+type NumberStats struct {
+	Sum       float64        `json:"sum"`
+	Average   float64        `json:"average"`
+	Min       float64        `json:"min"`
+	Max       float64        `json:"max"`
+	Histogram []NumberBucket `json:"histogram"`
+}
+
+// NumberBucket counts the values from From up to To; the last bucket includes To
+type NumberBucket struct {
+	From  float64 `json:"from"`
+	To    float64 `json:"to"`
+	Count int     `json:"count"`
+}
+
+// DateStats summarizes a date field; nil when no submission has a date
+type DateStats struct {
+	Min       string       `json:"min"` // YYYY-MM-DD
+	Max       string       `json:"max"`
+	Interval  string       `json:"interval"` // "day", "month" past three months or "year" past ten years
+	Histogram []DateBucket `json:"histogram"`
+}
+
+// DateBucket counts the values of the day, month or year starting on Date
+type DateBucket struct {
+	Date  string `json:"date"`
+	Count int    `json:"count"`
+}
+
+// FieldStats aggregates the form's number and date fields over the submissions each
+// calls fn with, leaving out test and spam submissions. Dates are counted as days in loc.
+// each is called twice, for the ranges and then for the histograms, so the submissions
+// are never all held at once; ones newer than those of the first pass are skipped in
+// the second.
+func (f *Form) FieldStats(each func(fn func(*Submission) error) error, loc *time.Location) ([]FieldStats, error) {
+	var fields []FormField
+	for _, field := range f.Fields {
+		if field.Type == FieldTypeNumber || field.Type == FieldTypeDate {
+			fields = append(fields, field)
+		}
+	}
+	stats := make([]FieldStats, len(fields))
+	if len(fields) == 0 {
+		return stats, nil
+	}
+	for i, field := range fields {
+		stats[i] = FieldStats{Field: field.Name, Type: field.Type}
+	}
+
+	// First pass: counts, sums and ranges
+	var until time.Time
+	counted := 0
+	first := make([]time.Time, len(fields))
+	last := make([]time.Time, len(fields))
+	err := each(func(sub *Submission) error {
+		if sub.CreatedAt.After(until) {
+			until = sub.CreatedAt
+		}
+		data, ok := statsData(sub)
+		if !ok {
+			return nil
+		}
+		counted++
+		for i, field := range fields {
+			st := &stats[i]
+			if field.Type == FieldTypeNumber {
+				n, ok := fieldNumber(data[field.Name])
+				if !ok {
+					continue
+				}
+				if st.Number == nil {
+					st.Number = &NumberStats{Min: n, Max: n}
+				}
+				st.Count++
+				st.Number.Sum += n
+				st.Number.Min = math.Min(st.Number.Min, n)
+				st.Number.Max = math.Max(st.Number.Max, n)
+			} else if t, ok := fieldDate(data[field.Name], loc); ok {
+				if st.Count == 0 || t.Before(first[i]) {
+					first[i] = t
+				}
+				if st.Count == 0 || t.After(last[i]) {
+					last[i] = t
+				}
+				st.Count++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	buckets := make([]func(time.Time) string, len(fields))
+	index := make([]map[string]int, len(fields))
+	for i := range stats {
+		st := &stats[i]
+		st.Missing = counted - st.Count
+		if st.Number != nil {
+			st.Number.Average = st.Number.Sum / float64(st.Count)
+			st.Number.Histogram = numberHistogram(st.Number)
+		} else if st.Type == FieldTypeDate && st.Count > 0 {
+			st.Date, buckets[i], index[i] = dateHistogram(first[i], last[i], loc)
+		}
+	}
+
+	// Second pass: the histograms
+	err = each(func(sub *Submission) error {
+		if sub.CreatedAt.After(until) {
+			return nil
+		}
+		data, ok := statsData(sub)
+		if !ok {
+			return nil
+		}
+		for i, field := range fields {
+			st := &stats[i]
+			if st.Number != nil {
+				if n, ok := fieldNumber(data[field.Name]); ok {
+					st.Number.add(n)
+				}
+			} else if st.Date != nil {
+				if t, ok := fieldDate(data[field.Name], loc); ok {
+					if b, ok := index[i][buckets[i](t)]; ok {
+						st.Date.Histogram[b].Count++
+					}
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// statsData returns the data of a submission the stats count, false for test and spam
+// submissions and ones whose data can't be read
+func statsData(sub *Submission) (map[string]interface{}, bool) {
+	if sub.IsTest || sub.Meta.IsSpam() {
+		return nil, false
+	}
+	var data map[string]interface{}
+	if err := json.Unmarshal(sub.Data, &data); err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// fieldNumber reads a number sent as JSON or, by HTML forms, as text
+func fieldNumber(v interface{}) (float64, bool) {
+	switch val := v.(type) {
+	case float64:
+		return val, true
+	case string:
+		n, err := strconv.ParseFloat(strings.TrimSpace(val), 64)
+		return n, err == nil && !math.IsInf(n, 0) && !math.IsNaN(n)
+	}
+	return 0, false
+}
+
+// fieldDate reads a date field's value as a time in loc
+func fieldDate(v interface{}, loc *time.Location) (time.Time, bool) {
+	s, ok := v.(string)
+	if !ok {
+		return time.Time{}, false
+	}
+	t, ok := ParseFieldDate(s, loc)
+	return t.In(loc), ok
+}
+
+// numberHistogram returns the empty buckets for values from st.Min to st.Max
+func numberHistogram(st *NumberStats) []NumberBucket {
+	if st.Min == st.Max {
+		return []NumberBucket{{From: st.Min, To: st.Max}}
+	}
+	width := (st.Max - st.Min) / numberBuckets
+	histogram := make([]NumberBucket, numberBuckets)
+	for i := range histogram {
+		histogram[i].From = st.Min + float64(i)*width
+		histogram[i].To = st.Min + float64(i+1)*width
+	}
+	histogram[numberBuckets-1].To = st.Max
+	return histogram
+}
+
+// add counts v in its bucket; values out of the range are left out
+func (st *NumberStats) add(v float64) {
+	if v < st.Min || v > st.Max {
+		return
+	}
+	i := 0
+	if st.Min != st.Max {
+		i = min(int((v-st.Min)/((st.Max-st.Min)/numberBuckets)), len(st.Histogram)-1)
+	}
+	st.Histogram[i].Count++
+}
+
+// dateHistogram returns the stats of dates from first to last with their empty buckets,
+// the bucket a date falls in and each bucket's index by date
+func dateHistogram(first, last time.Time, loc *time.Location) (*DateStats, func(time.Time) string, map[string]int) {
+	st := &DateStats{Min: first.Format("2006-01-02"), Max: last.Format("2006-01-02"), Interval: "day"}
+
+	// Buckets run from the first day (or month, or year) to the last, empty ones included
+	bucket := func(t time.Time) time.Time { return StartOfDay(t, loc) }
+	next := func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }
+	if months := (last.Year()-first.Year())*12 + int(last.Month()-first.Month()); months > maxMonthlySpan {
+		st.Interval = "year"
+		bucket = func(t time.Time) time.Time { return time.Date(t.Year(), 1, 1, 0, 0, 0, 0, loc) }
+		next = func(t time.Time) time.Time { return t.AddDate(1, 0, 0) }
+	} else if bucket(last).Sub(bucket(first)) > maxDailySpan*24*time.Hour {
+		st.Interval = "month"
+		bucket = func(t time.Time) time.Time { return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc) }
+		next = func(t time.Time) time.Time { return t.AddDate(0, 1, 0) }
+	}
+	index := make(map[string]int)
+	for t := bucket(first); !t.After(last); t = next(t) {
+		day := t.Format("2006-01-02")
+		index[day] = len(st.Histogram)
+		st.Histogram = append(st.Histogram, DateBucket{Date: day})
+	}
+	return st, func(t time.Time) string { return bucket(t).Format("2006-01-02") }, index
+}
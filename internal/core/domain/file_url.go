@@ -0,0 +1,107 @@
+package domain
+
+import (
+	"errors"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// ErrInvalidFileURLRules is returned for file URL rules that can't be used
+var ErrInvalidFileURLRules = errors.New("file_url needs at most 50 allowed_hosts like example.com or *.example.com, and a max_size of 0 or more")
+
+// MaxFileURLHosts caps the hosts a file URL field allows
+const MaxFileURLHosts = 50
+
+// hostPatternRegex matches allowed hosts: a host name, optionally with a "*." wildcard
+var hostPatternRegex = regexp.MustCompile(`^(\*\.)?([a-z0-9]([a-z0-9-]*[a-z0-9])?\.)*[a-z0-9]([a-z0-9-]*[a-z0-9])?$`)
+
+// FileURLRules are the checks of a file_url field, where submitters paste a link to a
+// file (a shared drive, a portfolio) instead of uploading it
+type FileURLRules struct {
+	AllowedHosts []string `json:"allowed_hosts,omitempty"` // example.com, or *.example.com for its subdomains too; any host when empty
+	Verify       bool     `json:"verify,omitempty"`        // HEAD-request the link and record what it answered in the submission's meta
+	MaxSize      int64    `json:"max_size,omitempty"`      // Bytes; enforced when verifying finds the size
+	AllowedTypes []string `json:"allowed_types,omitempty"` // Like uploads' allowed_types; enforced when verifying
+}
+
+// Validate lowercases and deduplicates the hosts and types
+func (r *FileURLRules) Validate() error {
+	if r.MaxSize < 0 {
+		return ErrInvalidFileURLRules
+	}
+	seen := make(map[string]bool)
+	hosts := make([]string, 0, len(r.AllowedHosts))
+	for _, h := range r.AllowedHosts {
+		h = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(h)), ".")
+		if h == "" || seen[h] {
+			continue
+		}
+		if !hostPatternRegex.MatchString(h) {
+			return ErrInvalidFileURLRules
+		}
+		seen[h] = true
+		hosts = append(hosts, h)
+	}
+	if len(hosts) > MaxFileURLHosts {
+		return ErrInvalidFileURLRules
+	}
+	r.AllowedHosts = hosts
+
+	types, err := normalizeFileTypes(r.AllowedTypes)
+	if err != nil {
+		return err
+	}
+	r.AllowedTypes = types
+	return nil
+}
+
+// AllowsHost reports whether links may point to host
+func (r *FileURLRules) AllowsHost(host string) bool {
+	if len(r.AllowedHosts) == 0 {
+		return true
+	}
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	for _, allowed := range r.AllowedHosts {
+		if domain, ok := strings.CutPrefix(allowed, "*."); ok {
+			if host == domain || strings.HasSuffix(host, "."+domain) {
+				return true
+			}
+		} else if host == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// FileLinkMeta is what verifying a file_url field's link found
+type FileLinkMeta struct {
+	Field       string `json:"field"`
+	URL         string `json:"url"`
+	Verified    bool   `json:"verified"`               // The link answered with a 2xx status
+	Status      int    `json:"status,omitempty"`       // HTTP status of the answer
+	Size        int64  `json:"size,omitempty"`         // Content-Length, when sent
+	ContentType string `json:"content_type,omitempty"` // Content-Type, when sent
+	Error       string `json:"error,omitempty"`        // Why the link couldn't be checked
+}
+
+// CheckFileLink checks what verifying the field's link found against its size and type
+// limits. Links that couldn't be verified pass: their meta records why.
+func (f FormField) CheckFileLink(link FileLinkMeta) *FieldError {
+	r := f.FileURL
+	if r == nil || !link.Verified {
+		return nil
+	}
+	if r.MaxSize > 0 && link.Size > r.MaxSize {
+		return &FieldError{Field: f.Name, Rule: "max_size", Message: f.Name + " links to a file that is too large"}
+	}
+	name := ""
+	if u, err := url.Parse(link.URL); err == nil {
+		name = path.Base(u.Path)
+	}
+	if !fileTypeAllowed(r.AllowedTypes, name, link.ContentType) {
+		return &FieldError{Field: f.Name, Rule: "type", Message: f.Name + " links to a file type that is not allowed"}
+	}
+	return nil
+}
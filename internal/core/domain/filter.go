@@ -0,0 +1,127 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Filter errors
+var (
+	ErrInvalidFilter  = errors.New("invalid content filter")
+	ErrFilterNotFound = errors.New("content filter not found")
+)
+
+// Content filter limits
+const (
+	MaxFilters             = 100 // Per form, and globally
+	MaxFilterPatternSize   = 500
+	MaxFilterFieldsPerRule = 20
+)
+
+// FilterAction is what happens to submissions matching a content filter
+type FilterAction string
+
+const (
+	FilterFlag   FilterAction = "flag"   // Quarantine as spam, with the rule in the spam flags
+	FilterReject FilterAction = "reject" // Refuse the submission
+)
+
+// FilterRule bans a keyword or regular expression from submitted text. Keywords match
+// whole words, ignoring case; regular expressions use Go syntax, e.g. (?i)casino\s+bonus.
+type FilterRule struct {
+	ID      string       `json:"id"`
+	Pattern string       `json:"pattern"`
+	Regex   bool         `json:"regex,omitempty"`
+	Action  FilterAction `json:"action"`
+	Fields  []string     `json:"fields,omitempty"` // Fields to check; every text value when empty
+}
+
+// Validate checks the pattern and fields, defaulting the action to flag
+func (r *FilterRule) Validate() error {
+	if len(r.ID) > 64 {
+		return fmt.Errorf("%w: id must be at most 64 characters", ErrInvalidFilter)
+	}
+	if !r.Regex {
+		r.Pattern = strings.TrimSpace(r.Pattern)
+	}
+	if r.Pattern == "" || len(r.Pattern) > MaxFilterPatternSize {
+		return fmt.Errorf("%w: pattern must be 1-%d characters", ErrInvalidFilter, MaxFilterPatternSize)
+	}
+	if _, err := r.Compile(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidFilter, err)
+	}
+	switch r.Action {
+	case "":
+		r.Action = FilterFlag
+	case FilterFlag, FilterReject:
+	default:
+		return fmt.Errorf("%w: action must be flag or reject", ErrInvalidFilter)
+	}
+	if len(r.Fields) > MaxFilterFieldsPerRule {
+		return fmt.Errorf("%w: at most %d fields", ErrInvalidFilter, MaxFilterFieldsPerRule)
+	}
+	return nil
+}
+
+// Compile returns the expression the rule matches text with
+func (r *FilterRule) Compile() (*regexp.Regexp, error) {
+	if r.Regex {
+		return regexp.Compile(r.Pattern)
+	}
+	// \b only knows ASCII words, so letters and digits of any script bound the keyword
+	return regexp.Compile(`(?i)(?:^|[^\pL\pN_])` + regexp.QuoteMeta(r.Pattern) + `(?:$|[^\pL\pN_])`)
+}
+
+// ValidateFilters checks a list of content filters and that their IDs are unique
+func ValidateFilters(rules []FilterRule) error {
+	if len(rules) > MaxFilters {
+		return fmt.Errorf("%w: at most %d filters", ErrInvalidFilter, MaxFilters)
+	}
+	seen := make(map[string]bool)
+	for i := range rules {
+		if err := rules[i].Validate(); err != nil {
+			return err
+		}
+		if id := rules[i].ID; id != "" {
+			if seen[id] {
+				return fmt.Errorf("%w: duplicate id %q", ErrInvalidFilter, id)
+			}
+			seen[id] = true
+		}
+	}
+	return nil
+}
+
+// FilterTexts returns the submitted text a rule checks: the strings of its fields, or of
+// every field, including those nested in lists and objects
+func (r *FilterRule) FilterTexts(data map[string]interface{}) []string {
+	var texts []string
+	if len(r.Fields) == 0 {
+		for _, v := range data {
+			texts = collectTexts(v, texts)
+		}
+		return texts
+	}
+	for _, name := range r.Fields {
+		texts = collectTexts(data[name], texts)
+	}
+	return texts
+}
+
+func collectTexts(v interface{}, texts []string) []string {
+	switch val := v.(type) {
+	case string:
+		texts = append(texts, val)
+	case []interface{}:
+		for _, item := range val {
+			texts = collectTexts(item, texts)
+		}
+	case map[string]interface{}:
+		for _, item := range val {
+			texts = collectTexts(item, texts)
+		}
+	}
+	return texts
+}
@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// Form schedule errors
+var (
+	ErrFormNotOpen     = errors.New("form is not open for submissions yet")
+	ErrFormClosed      = errors.New("form is closed and no longer accepts submissions")
+	ErrInvalidSchedule = errors.New("closes_at must be after opens_at")
+)
+
+// Where the present falls in a form's schedule
+const (
+	ScheduleUpcoming = "upcoming" // opens_at is still ahead
+	ScheduleOpen     = "open"     // Between opens_at and closes_at
+	ScheduleClosed   = "closed"   // closes_at has passed
+)
+
+// NormalizeSchedule checks the form opens before it closes, and returns the times in UTC
+// to the second. Either may be nil for no limit on that side.
+func NormalizeSchedule(opensAt, closesAt *time.Time) (*time.Time, *time.Time, error) {
+	opensAt, closesAt = scheduleTime(opensAt), scheduleTime(closesAt)
+	if opensAt != nil && closesAt != nil && !closesAt.After(*opensAt) {
+		return nil, nil, ErrInvalidSchedule
+	}
+	return opensAt, closesAt, nil
+}
+
+func scheduleTime(t *time.Time) *time.Time {
+	if t == nil || t.IsZero() {
+		return nil
+	}
+	utc := t.UTC().Truncate(time.Second)
+	return &utc
+}
+
+// ScheduleAt returns where now falls in the form's schedule, or "" if it has none
+func (f *Form) ScheduleAt(now time.Time) string {
+	switch {
+	case f.OpensAt == nil && f.ClosesAt == nil:
+		return ""
+	case f.OpensAt != nil && now.Before(*f.OpensAt):
+		return ScheduleUpcoming
+	case f.ClosesAt != nil && !now.Before(*f.ClosesAt):
+		return ScheduleClosed
+	}
+	return ScheduleOpen
+}
+
+// SetSchedule sets Schedule from the present, for forms shown to users
+func (f *Form) SetSchedule() {
+	f.Schedule = f.ScheduleAt(time.Now())
+}
+
+// openAt returns why the form's schedule doesn't take submissions at now, or nil if it does
+func (f *Form) openAt(now time.Time) error {
+	switch f.ScheduleAt(now) {
+	case ScheduleUpcoming:
+		return ErrFormNotOpen
+	case ScheduleClosed:
+		return ErrFormClosed
+	}
+	return nil
+}
@@ -0,0 +1,267 @@
+package domain
+
+import (
+	"errors"
+	"maps"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Form spec errors
+var (
+	ErrInvalidSlug       = errors.New("slug must be 1-64 lowercase letters, digits or single hyphens")
+	ErrInvalidFormStatus = errors.New("status must be active, inactive or archived")
+	ErrInvalidAccessMode = errors.New("access_mode must be public, with_key or private")
+	ErrSlugInTrash       = errors.New("a deleted form still uses this slug; restore or purge it first")
+)
+
+// slugRegex matches slugs like "contact" or "newsletter-signup-2026"
+var slugRegex = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// ValidSlug reports whether s can be used as a form slug
+func ValidSlug(s string) bool {
+	return len(s) <= 64 && slugRegex.MatchString(s)
+}
+
+// ApplyResult says what applying a form spec did
+type ApplyResult string
+
+const (
+	ApplyCreated   ApplyResult = "created"
+	ApplyUpdated   ApplyResult = "updated"
+	ApplyUnchanged ApplyResult = "unchanged"
+)
+
+// FormSpec declares a form's whole configuration, for managing forms from code
+// (PUT /api/v1/form-specs/{slug}). Omitted settings take their defaults, so
+// applying a spec always converges the form to it; applying it again is a no-op.
+type FormSpec struct {
+	Name            string            `json:"name"`
+	Status          FormStatus        `json:"status,omitempty"`          // Default active
+	RedirectURL     string            `json:"redirect_url,omitempty"`    // Hosted thank-you page when empty
+	AllowedOrigins  []string          `json:"allowed_origins,omitempty"` // Default ["*"]
+	AccessMode      string            `json:"access_mode,omitempty"`     // Default public
+	SubmissionKey   string            `json:"submission_key,omitempty"`
+	NotifyEmails    []string          `json:"notify_emails,omitempty"`
+	NotifyRules     []NotifyRule      `json:"notify_rules,omitempty"`      // Conditional recipients; notify_emails gets every submission when empty
+	EmailTemplate   *EmailTemplate    `json:"email_template,omitempty"`    // Custom notification email; the built-in one when nil
+	EmailFieldLimit int               `json:"email_field_limit,omitempty"` // Characters of each value notification emails show; default 1000
+	LinkField       string            `json:"link_field,omitempty"`        // Field holding the ID of a submission each new one follows up on
+	HiddenFields    map[string]string `json:"hidden_fields,omitempty"`     // Set in every submission server-side, e.g. {"campaign": "spring2025"}
+	OpensAt         *time.Time        `json:"opens_at,omitempty"`          // Submissions are refused before
+	ClosesAt        *time.Time        `json:"closes_at,omitempty"`         // Submissions are refused from then on
+	AttachPDF       bool              `json:"attach_pdf,omitempty"`
+	PublicStats     bool              `json:"public_stats,omitempty"`
+	Anonymous       bool              `json:"anonymous,omitempty"`
+	Consent         *ConsentConfig    `json:"consent,omitempty"`          // Required checkbox fields
+	FrameAncestors  []string          `json:"frame_ancestors,omitempty"`  // Origins allowed to embed the form; none denies framing
+	Fields          []FormField       `json:"fields,omitempty"`           // Field labels and their translations
+	Timezone        string            `json:"timezone,omitempty"`         // IANA zone for stats and exports
+	Uploads         *UploadConfig     `json:"uploads,omitempty"`          // File uploads; none accepted when nil
+	RetentionDays   int               `json:"retention_days,omitempty"`   // Submissions expire after this many days; kept forever when 0
+	RetentionAction string            `json:"retention_action,omitempty"` // delete (default) or anonymize
+	Webhook         *WebhookSpec      `json:"webhook,omitempty"`          // No webhook when nil
+}
+
+// WebhookSpec declares a form's webhook
+type WebhookSpec struct {
+	URL                string       `json:"url"`
+	Secret             string       `json:"secret,omitempty"`
+	SignatureAlgorithm string       `json:"signature_algorithm,omitempty"` // Default sha256
+	SignTimestamp      bool         `json:"sign_timestamp,omitempty"`
+	IncludeMeta        bool         `json:"include_meta,omitempty"` // Request metadata and spam verdict in payloads
+	Transform          string       `json:"transform,omitempty"`
+	TransformEnabled   bool         `json:"transform_enabled,omitempty"`
+	Rate               *WebhookRate `json:"rate,omitempty"` // Rate limit and batching; none when nil
+}
+
+// Normalize fills in defaults and validates the spec. Transform scripts and email
+// templates are checked by the caller (see the transform and email packages).
+func (s *FormSpec) Normalize() error {
+	s.Name = strings.TrimSpace(s.Name)
+	if s.Name == "" {
+		return ErrFormNameRequired
+	}
+	if len(s.Name) > 100 {
+		return ErrFormNameTooLong
+	}
+	if s.Status == "" {
+		s.Status = FormStatusActive
+	}
+	switch s.Status {
+	case FormStatusActive, FormStatusInactive, FormStatusArchived:
+	default:
+		return ErrInvalidFormStatus
+	}
+	if s.AccessMode == "" {
+		s.AccessMode = string(AccessModePublic)
+	}
+	switch AccessMode(s.AccessMode) {
+	case AccessModePublic, AccessModeWithKey, AccessModePrivate:
+	default:
+		return ErrInvalidAccessMode
+	}
+	if len(s.AllowedOrigins) == 0 {
+		s.AllowedOrigins = []string{"*"}
+	}
+	if s.NotifyEmails == nil {
+		s.NotifyEmails = []string{}
+	}
+	if err := ValidateNotifyRules(s.NotifyRules); err != nil {
+		return err
+	}
+	if s.EmailTemplate != nil {
+		if err := s.EmailTemplate.Validate(); err != nil {
+			return err
+		}
+	}
+	if err := ValidateEmailFieldLimit(s.EmailFieldLimit); err != nil {
+		return err
+	}
+	linkField, err := NormalizeLinkField(s.LinkField)
+	if err != nil {
+		return err
+	}
+	s.LinkField = linkField
+	if s.HiddenFields, err = NormalizeHiddenFields(s.HiddenFields); err != nil {
+		return err
+	}
+	if s.OpensAt, s.ClosesAt, err = NormalizeSchedule(s.OpensAt, s.ClosesAt); err != nil {
+		return err
+	}
+	if s.Consent != nil {
+		if err := s.Consent.Validate(); err != nil {
+			return err
+		}
+	}
+	ancestors, err := NormalizeFrameAncestors(s.FrameAncestors)
+	if err != nil {
+		return err
+	}
+	s.FrameAncestors = ancestors
+	if s.Fields, err = NormalizeFields(s.Fields); err != nil {
+		return err
+	}
+	if s.Timezone, err = NormalizeTimezone(s.Timezone); err != nil {
+		return err
+	}
+	if s.Uploads != nil {
+		if err := s.Uploads.Validate(); err != nil {
+			return err
+		}
+	}
+	if s.RetentionAction, err = NormalizeRetention(s.RetentionDays, s.RetentionAction); err != nil {
+		return err
+	}
+	if w := s.Webhook; w != nil {
+		if w.SignatureAlgorithm == "" {
+			w.SignatureAlgorithm = SignatureSHA256
+		}
+		if !ValidSignatureAlgorithm(w.SignatureAlgorithm) {
+			return ErrInvalidSignatureAlgorithm
+		}
+		if w.TransformEnabled && strings.TrimSpace(w.Transform) == "" {
+			return ErrTransformRequired
+		}
+		if w.Rate != nil {
+			if err := w.Rate.Validate(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Apply sets the form's configuration from a normalized spec and reports whether anything changed
+func (s *FormSpec) Apply(f *Form) bool {
+	before := f.Spec()
+
+	f.Name = s.Name
+	f.Status = s.Status
+	f.RedirectURL = s.RedirectURL
+	f.AllowedOrigins = s.AllowedOrigins
+	f.AccessMode = s.AccessMode
+	f.SubmissionKey = s.SubmissionKey
+	f.NotifyEmails = s.NotifyEmails
+	f.NotifyRules = s.NotifyRules
+	f.EmailTemplate = s.EmailTemplate
+	f.EmailFieldLimit = s.EmailFieldLimit
+	f.LinkField = s.LinkField
+	f.HiddenFields = s.HiddenFields
+	f.OpensAt = s.OpensAt
+	f.ClosesAt = s.ClosesAt
+	f.AttachPDF = s.AttachPDF
+	f.PublicStats = s.PublicStats
+	f.Anonymous = s.Anonymous
+	f.Consent = s.Consent
+	f.FrameAncestors = s.FrameAncestors
+	f.Fields = s.Fields
+	f.Timezone = s.Timezone
+	f.Uploads = s.Uploads
+	f.RetentionDays = s.RetentionDays
+	f.RetentionAction = s.RetentionAction
+	w := s.Webhook
+	if w == nil {
+		w = &WebhookSpec{SignatureAlgorithm: SignatureSHA256}
+	}
+	f.WebhookURL = w.URL
+	f.WebhookSecret = w.Secret
+	f.WebhookSignatureAlgorithm = w.SignatureAlgorithm
+	f.WebhookSignTimestamp = w.SignTimestamp
+	f.WebhookIncludeMeta = w.IncludeMeta
+	f.WebhookTransform = w.Transform
+	f.WebhookTransformEnabled = w.TransformEnabled
+	f.WebhookRate = w.Rate
+
+	return !reflect.DeepEqual(before, f.Spec())
+}
+
+// Spec returns the form's configuration as a normalized spec
+func (f *Form) Spec() FormSpec {
+	s := FormSpec{
+		Name:           f.Name,
+		Status:         f.Status,
+		RedirectURL:    f.RedirectURL,
+		AllowedOrigins: append([]string{}, f.AllowedOrigins...),
+		AccessMode:     f.AccessMode,
+		SubmissionKey:  f.SubmissionKey,
+		NotifyEmails:   append([]string{}, f.NotifyEmails...),
+		NotifyRules:    f.NotifyRules,
+		EmailTemplate:  f.EmailTemplate,
+		AttachPDF:      f.AttachPDF,
+		PublicStats:    f.PublicStats,
+		Anonymous:      f.Anonymous,
+		Consent:        f.Consent,
+		FrameAncestors: append([]string{}, f.FrameAncestors...),
+		Fields:         append([]FormField{}, f.Fields...),
+		Timezone:       f.Timezone,
+		Uploads:        f.Uploads,
+
+		RetentionDays:   f.RetentionDays,
+		RetentionAction: f.RetentionAction,
+		EmailFieldLimit: f.EmailFieldLimit,
+		LinkField:       f.LinkField,
+		HiddenFields:    maps.Clone(f.HiddenFields),
+		OpensAt:         f.OpensAt,
+		ClosesAt:        f.ClosesAt,
+	}
+	customSigning := f.WebhookSignTimestamp || (f.WebhookSignatureAlgorithm != "" && f.WebhookSignatureAlgorithm != SignatureSHA256)
+	if f.WebhookURL != "" || f.WebhookSecret != "" || f.WebhookTransform != "" || f.WebhookTransformEnabled || customSigning || f.WebhookIncludeMeta || f.WebhookRate != nil {
+		s.Webhook = &WebhookSpec{
+			URL:                f.WebhookURL,
+			Secret:             f.WebhookSecret,
+			SignatureAlgorithm: f.WebhookSignatureAlgorithm,
+			SignTimestamp:      f.WebhookSignTimestamp,
+			IncludeMeta:        f.WebhookIncludeMeta,
+			Transform:          f.WebhookTransform,
+			TransformEnabled:   f.WebhookTransformEnabled,
+			Rate:               f.WebhookRate,
+		}
+		if s.Webhook.SignatureAlgorithm == "" {
+			s.Webhook.SignatureAlgorithm = SignatureSHA256
+		}
+	}
+	return s
+}
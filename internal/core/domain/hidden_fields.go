@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrInvalidHiddenFields is returned for too many hidden fields, or names or values that are
+// empty or too long
+var ErrInvalidHiddenFields = errors.New("hidden_fields must map at most 50 field names to values, each of at most 500 characters")
+
+// MaxHiddenFields caps the hidden fields of a form
+const MaxHiddenFields = 50
+
+// NormalizeHiddenFields trims the names of a form's hidden fields and checks them and their
+// values. Returns nil for none.
+func NormalizeHiddenFields(fields map[string]string) (map[string]string, error) {
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	if len(fields) > MaxHiddenFields {
+		return nil, ErrInvalidHiddenFields
+	}
+	normalized := make(map[string]string, len(fields))
+	for name, value := range fields {
+		name = strings.TrimSpace(name)
+		if name == "" || len(name) > maxFieldText || len(value) > maxFieldText {
+			return nil, ErrInvalidHiddenFields
+		}
+		normalized[name] = value
+	}
+	return normalized, nil
+}
+
+// ApplyHiddenFields sets the form's hidden fields in submitted data, over whatever the
+// client sent for them
+func (f *Form) ApplyHiddenFields(data map[string]interface{}) {
+	for name, value := range f.HiddenFields {
+		data[name] = value
+	}
+}
@@ -0,0 +1,88 @@
+package domain
+
+import "time"
+
+// SubmissionMeta is the metadata stored alongside each submission.
+// JSON keys are underscore-prefixed to keep them apart from user data in exports and the dashboard.
+type SubmissionMeta struct {
+	Server  ServerMeta             `json:"_server"`            // Trusted, collected server-side
+	Client  map[string]interface{} `json:"_client,omitempty"`  // Client-provided (may be spoofed)
+	Spam    *SpamMeta              `json:"_spam,omitempty"`    // Spam detection result
+	Auth    *AuthMeta              `json:"_auth,omitempty"`    // Authenticated submitter (private forms)
+	Consent *ConsentMeta           `json:"_consent,omitempty"` // Consent given (forms with a consent config)
+	Files   []FileRef              `json:"_files,omitempty"`   // Files uploaded with the submission
+	Links   []FileLinkMeta         `json:"_links,omitempty"`   // What verifying the file_url fields' links found
+
+	AnonymizedAt *time.Time `json:"_anonymized_at,omitempty"` // When the data was erased after the form's retention period
+
+	Signature *RequestSignature `json:"-"` // Signature of a server-to-server submission, checked but never stored
+}
+
+// ServerMeta contains metadata auto-collected from the HTTP request
+// This data is TRUSTED (collected server-side, cannot be spoofed by client)
+type ServerMeta struct {
+	// Core identification
+	IP        string    `json:"ip"`
+	RequestID string    `json:"request_id"`
+	Timestamp time.Time `json:"timestamp"`
+
+	// Browser/Client info
+	UserAgent  string `json:"user_agent"`
+	Language   string `json:"language,omitempty"`    // Accept-Language
+	IsMobile   string `json:"is_mobile,omitempty"`   // Sec-CH-UA-Mobile
+	Platform   string `json:"platform,omitempty"`    // Sec-CH-UA-Platform
+	ClientHint string `json:"client_hint,omitempty"` // Sec-CH-UA
+
+	// Request context
+	Referer     string `json:"referer,omitempty"`
+	Origin      string `json:"origin,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Protocol    string `json:"protocol,omitempty"` // http or https
+
+	// Cloudflare-specific (only present if behind Cloudflare)
+	Country     string `json:"country,omitempty"`      // CF-IPCountry
+	EstimatedTZ string `json:"estimated_tz,omitempty"` // Timezone estimated from country
+	CFRay       string `json:"cf_ray,omitempty"`       // CF-Ray (request ID)
+
+	// Privacy
+	DoNotTrack string `json:"dnt,omitempty"`        // DNT header
+	Anonymized bool   `json:"anonymized,omitempty"` // Collected for an anonymous form: identifying fields were dropped
+}
+
+// SpamMeta records the spam analysis for a submission
+type SpamMeta struct {
+	Score     int      `json:"score"`            // 0-100, higher = more likely spam
+	IsSpam    bool     `json:"is_spam"`          // true if score >= threshold
+	Flags     []string `json:"flags"`            // Reasons for the score
+	Threshold int      `json:"threshold"`        // Score threshold used
+	Manual    bool     `json:"manual,omitempty"` // Marked as spam by a user rather than detected
+}
+
+// AuthMeta identifies who made a submission to a private form: a logged-in
+// user, or a backend that signed the request with the form's submission key
+type AuthMeta struct {
+	UserID string `json:"user_id,omitempty"`
+	Signed bool   `json:"signed,omitempty"`
+}
+
+// RequestSignature is an HMAC signature sent with a submission instead of a login
+type RequestSignature struct {
+	Value     string // "<algorithm>=<hex HMAC>" of "timestamp.body", keyed with the form's submission key
+	Timestamp string // Required RFC 3339 time the request was signed at, checked against the clock
+	Body      []byte // Raw request body
+}
+
+// IsSpam reports whether the submission was flagged as spam
+func (m SubmissionMeta) IsSpam() bool {
+	return m.Spam != nil && m.Spam.IsSpam
+}
+
+// File returns the submission's uploaded file with the given ID
+func (m SubmissionMeta) File(fileID string) (FileRef, bool) {
+	for _, f := range m.Files {
+		if f.FileID == fileID {
+			return f, true
+		}
+	}
+	return FileRef{}, false
+}
@@ -3,6 +3,7 @@ package domain
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"strings"
 	"time"
 )
@@ -14,6 +15,22 @@ var (
 	ErrInvalidEmail       = errors.New("invalid email format")
 	ErrFormNotFound       = errors.New("form not found")
 	ErrSubmissionNotFound = errors.New("submission not found")
+	ErrFormInactive       = errors.New("form is not accepting submissions")
+	ErrFormArchived       = errors.New("form is archived and no longer accepts submissions")
+	ErrOriginNotAllowed   = errors.New("this site is not allowed to submit to the form")
+	ErrTransformRequired  = errors.New("a transform script is required to enable webhook transformation")
+	ErrInvalidDateRange   = errors.New("invalid date range")
+	ErrInvalidDeleteToken = errors.New("delete confirmation token is invalid or expired")
+	ErrFilterRequired     = errors.New("at least one filter is required")
+	ErrInvalidListQuery   = errors.New("invalid sort or filter")
+	ErrInvalidBulkAction  = errors.New("action must be delete, mark_read, mark_unread, mark_spam or mark_not_spam")
+	ErrInvalidBulkIDs     = errors.New("ids must list between 1 and 1000 submission IDs")
+
+	ErrInvalidSignatureAlgorithm = errors.New("webhook signature algorithm must be sha256 or sha512")
+
+	// ErrStoreUnavailable wraps storage errors that go away by retrying later, such as a
+	// locked, full or unreachable database, as opposed to data the database refuses
+	ErrStoreUnavailable = errors.New("database is unavailable")
 )
 
 // FormStatus represents the state of a form
@@ -22,6 +39,7 @@ type FormStatus string
 const (
 	FormStatusActive   FormStatus = "active"
 	FormStatusInactive FormStatus = "inactive"
+	FormStatusArchived FormStatus = "archived" // Closed for good and hidden from form lists, with its submissions kept
 )
 
 // AccessMode determines who can submit to a form
@@ -33,29 +51,162 @@ const (
 	AccessModePrivate AccessMode = "private"  // Only authenticated users can submit
 )
 
+// Webhook signature algorithms (HMAC hash functions)
+const (
+	SignatureSHA256 = "sha256" // Default
+	SignatureSHA512 = "sha512"
+)
+
+// ValidSignatureAlgorithm reports whether alg is a supported webhook signature algorithm
+func ValidSignatureAlgorithm(alg string) bool {
+	return alg == SignatureSHA256 || alg == SignatureSHA512
+}
+
+// SecretReference reports whether a secret names one held in a secret manager
+// ("vault:<path>#<field>" or "aws-sm:<secret id>[#<field>]") rather than being the secret
+func SecretReference(secret string) bool {
+	scheme, _, ok := strings.Cut(secret, ":")
+	return ok && (scheme == "vault" || scheme == "aws-sm")
+}
+
 // Access control errors
 var (
 	ErrInvalidSubmissionKey = errors.New("invalid submission key")
 	ErrAuthRequired         = errors.New("authentication required for this form")
+	ErrInvalidSignature     = errors.New("invalid or expired submission signature")
 )
 
 // Form represents a form endpoint configuration
 type Form struct {
-	ID              string     `json:"id"`
-	OwnerID         string     `json:"owner_id"` // User who created this form
-	PublicID        string     `json:"public_id"`
-	Name            string     `json:"name"`
-	Status          FormStatus `json:"status"`
-	NotifyEmails    []string   `json:"notify_emails"`
-	AllowedOrigins  []string   `json:"allowed_origins"`
-	RedirectURL     string     `json:"redirect_url"`
-	WebhookURL      string     `json:"webhook_url,omitempty"`
-	WebhookSecret   string     `json:"webhook_secret,omitempty"`
-	AccessMode      string     `json:"access_mode"` // public, with_key, private
-	SubmissionKey   string     `json:"submission_key,omitempty"`
-	SubmissionCount int        `json:"submission_count"`
-	CreatedAt       time.Time  `json:"created_at"`
-	UpdatedAt       time.Time  `json:"updated_at"`
+	ID             string     `json:"id"`
+	OwnerID        string     `json:"owner_id"`          // User who created this form
+	TeamID         string     `json:"team_id,omitempty"` // Team sharing the form (see Team)
+	PublicID       string     `json:"public_id"`
+	Slug           string     `json:"slug,omitempty"` // Stable name for forms managed from code (see FormSpec)
+	Name           string     `json:"name"`
+	Status         FormStatus `json:"status"`
+	NotifyEmails   []string   `json:"notify_emails"`
+	AllowedOrigins []string   `json:"allowed_origins"`
+	RedirectURL    string     `json:"redirect_url"`
+	WebhookURL     string     `json:"webhook_url,omitempty"`
+	WebhookSecret  string     `json:"webhook_secret,omitempty"`
+	AccessMode     string     `json:"access_mode"` // public, with_key, private
+	SubmissionKey  string     `json:"submission_key,omitempty"`
+
+	WebhookTransform          string                `json:"webhook_transform,omitempty"` // Template mapping submissions to the webhook payload
+	WebhookTransformEnabled   bool                  `json:"webhook_transform_enabled"`
+	WebhookSignatureAlgorithm string                `json:"webhook_signature_algorithm"` // sha256 (default) or sha512
+	WebhookSignTimestamp      bool                  `json:"webhook_sign_timestamp"`      // Sign "timestamp.body" rather than the body alone
+	WebhookIncludeMeta        bool                  `json:"webhook_include_meta"`        // Add the submitter's IP, country, referer, user agent and the spam verdict to payloads
+	WebhookRate               *WebhookRate          `json:"webhook_rate,omitempty"`      // Rate limit and batching of webhook deliveries; one request per event when nil
+	AttachPDF                 bool                  `json:"attach_pdf"`                  // Attach a PDF of the submission to notification emails
+	PublicStats               bool                  `json:"public_stats"`                // Expose the response count on the public stats endpoint and badge
+	Anonymous                 bool                  `json:"anonymous"`                   // Privacy mode: don't store IP, user agent or other request metadata
+	Consent                   *ConsentConfig        `json:"consent,omitempty"`           // Checkboxes every submission must tick
+	FrameAncestors            []string              `json:"frame_ancestors,omitempty"`   // Origins allowed to embed the form's public responses in an iframe; none denies framing
+	Fields                    []FormField           `json:"fields,omitempty"`            // Labels, placeholders and help text per field, with translations
+	Timezone                  string                `json:"timezone,omitempty"`          // IANA zone for stats and exports; the owner's timezone when empty
+	Uploads                   *UploadConfig         `json:"uploads,omitempty"`           // File upload limits; files are rejected when nil
+	SubmitResponse            *SubmitResponseConfig `json:"submit_response,omitempty"`   // Envelope and status code of successful submissions; enveloped 201 when nil
+	Spam                      *SpamConfig           `json:"spam,omitempty"`              // Spam check settings; the defaults when nil
+	Captcha                   *CaptchaConfig        `json:"captcha,omitempty"`           // Captcha every submission must pass; none when nil
+	Normalize                 *NormalizeConfig      `json:"normalize,omitempty"`         // Clean up submitted text before it's stored; kept as sent when nil
+	NotifyRules               []NotifyRule          `json:"notify_rules,omitempty"`      // Who is emailed about which submissions; NotifyEmails gets them all when empty
+	EmailTemplate             *EmailTemplate        `json:"email_template,omitempty"`    // Replaces parts of the built-in notification email
+	Filters                   []FilterRule          `json:"filters,omitempty"`           // Banned keywords and patterns, checked after the global ones
+	AutoReply                 *AutoReplyConfig      `json:"auto_reply,omitempty"`        // Confirmation emailed to the submitter; none when nil
+	ErrorPage                 *ErrorPageConfig      `json:"error_page,omitempty"`        // Where rejected browser form posts go; the hosted error page when nil
+	Channels                  []NotifyChannel       `json:"channels,omitempty"`          // Slack and Discord channels new submissions are posted to
+	Sample                    bool                  `json:"sample,omitempty"`            // Created with sample data for its owner to explore; purged along with it
+	RetentionDays             int                   `json:"retention_days,omitempty"`    // Age in days at which submissions expire; kept forever when 0
+	RetentionAction           string                `json:"retention_action,omitempty"`  // What happens to expired submissions: delete or anonymize
+	EmailFieldLimit           int                   `json:"email_field_limit,omitempty"` // Characters of each value notification emails show; DefaultEmailFieldLimit when 0
+	LinkField                 string                `json:"link_field,omitempty"`        // Field holding the ID of a submission each new one follows up on; none when empty
+	HiddenFields              map[string]string     `json:"hidden_fields,omitempty"`     // Set in every submission's data server-side, over what the client sent
+	OpensAt                   *time.Time            `json:"opens_at,omitempty"`          // Submissions are refused before; open from creation when nil
+	ClosesAt                  *time.Time            `json:"closes_at,omitempty"`         // Submissions are refused from then on; open indefinitely when nil
+	Schedule                  string                `json:"schedule,omitempty"`          // upcoming, open or closed for forms with opens_at or closes_at; only set by form lists and lookups
+	SubmissionCount           int                   `json:"submission_count"`
+	LastSubmissionAt          *time.Time            `json:"last_submission_at,omitempty"`
+	Starred                   bool                  `json:"starred,omitempty"` // Starred by the user listing forms; only set by form lists
+	CreatedAt                 time.Time             `json:"created_at"`
+	UpdatedAt                 time.Time             `json:"updated_at"`
+
+	// Set while a deleted form waits out its grace period before being purged
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+	PurgeAt     *time.Time `json:"purge_at,omitempty"`
+	ArchivePath string     `json:"archive_path,omitempty"` // Final export written before deletion
+
+	// Set after the public ID is rotated with a grace period, while submissions to the old one are still accepted
+	PreviousPublicID      string     `json:"previous_public_id,omitempty"`
+	PreviousPublicIDUntil *time.Time `json:"previous_public_id_until,omitempty"`
+}
+
+// IsDeleted reports whether the form is pending deletion
+func (f *Form) IsDeleted() bool {
+	return f.DeletedAt != nil
+}
+
+// WithoutSecrets returns a copy of the form without the credentials only those who may
+// manage it should see: the webhook secret, the submission key, the captcha provider's
+// secret and the chat channels' webhook URLs, which anyone holding them can post with
+func (f *Form) WithoutSecrets() *Form {
+	out := *f
+	out.WebhookSecret = ""
+	out.SubmissionKey = ""
+	if f.Captcha != nil {
+		captcha := *f.Captcha
+		captcha.Secret = ""
+		out.Captcha = &captcha
+	}
+	if f.Channels != nil {
+		out.Channels = make([]NotifyChannel, len(f.Channels))
+		for i, c := range f.Channels {
+			c.URL = ""
+			out.Channels[i] = c
+		}
+	}
+	return &out
+}
+
+// AcceptingSubmissions returns why the form doesn't take new submissions, or nil if it does.
+// Active forms only take them within their schedule.
+func (f *Form) AcceptingSubmissions() error {
+	switch f.Status {
+	case FormStatusActive:
+		return f.openAt(time.Now())
+	case FormStatusArchived:
+		return ErrFormArchived
+	}
+	return ErrFormInactive
+}
+
+// AllowsOrigin reports whether pages on origin (the request's Origin header) may
+// submit to the form. "*" or an empty allow-list accepts every origin.
+func (f *Form) AllowsOrigin(origin string) bool {
+	if f.AllowsAnyOrigin() {
+		return true
+	}
+	origin = strings.TrimSuffix(origin, "/")
+	for _, o := range f.AllowedOrigins {
+		if strings.EqualFold(strings.TrimSuffix(o, "/"), origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsAnyOrigin reports whether the form accepts submissions from every origin
+func (f *Form) AllowsAnyOrigin() bool {
+	if len(f.AllowedOrigins) == 0 {
+		return true
+	}
+	for _, o := range f.AllowedOrigins {
+		if o == "*" {
+			return true
+		}
+	}
+	return false
 }
 
 // Validate checks if the form data is valid
@@ -70,12 +221,68 @@ func (f *Form) Validate() error {
 	return nil
 }
 
-// SubmissionStatus represents the read state of a submission
+// FormSort is a field forms can be listed by
+type FormSort string
+
+const (
+	FormSortCreatedAt        FormSort = "created_at" // Default
+	FormSortUpdatedAt        FormSort = "updated_at"
+	FormSortName             FormSort = "name"
+	FormSortSubmissionCount  FormSort = "submission_count"
+	FormSortLastSubmissionAt FormSort = "last_submission_at"
+)
+
+// FormListQuery filters and orders a forms listing. Zero fields match every form.
+type FormListQuery struct {
+	OwnerID    string     // Only forms of this owner
+	TeamIDs    []string   // With OwnerID: also forms of these teams
+	TeamID     string     // Only forms of this team
+	Status     FormStatus // Only forms with this status
+	AccessMode AccessMode // Only forms with this access mode
+	Search     string     // Case-insensitive substring of the form name
+	Sort       FormSort   // Defaults to created_at
+	Ascending  bool       // Sort order; newest/largest first by default
+
+	Viewer      string // User the list is for: forms they starred have Starred set
+	StarredOnly bool   // Only forms starred by Viewer
+	SampleOnly  bool   // Only forms created with sample data
+
+	IncludeArchived bool // Archived forms too; they're left out unless Status asks for them
+}
+
+// Validate checks the sort field and filter values, defaulting an empty sort
+func (q *FormListQuery) Validate() error {
+	switch q.Sort {
+	case "":
+		q.Sort = FormSortCreatedAt
+	case FormSortCreatedAt, FormSortUpdatedAt, FormSortName, FormSortSubmissionCount, FormSortLastSubmissionAt:
+	default:
+		return fmt.Errorf("%w: unknown sort %q", ErrInvalidListQuery, q.Sort)
+	}
+	switch q.Status {
+	case "", FormStatusActive, FormStatusInactive, FormStatusArchived:
+	default:
+		return fmt.Errorf("%w: unknown status %q", ErrInvalidListQuery, q.Status)
+	}
+	switch q.AccessMode {
+	case "", AccessModePublic, AccessModeWithKey, AccessModePrivate:
+	default:
+		return fmt.Errorf("%w: unknown access mode %q", ErrInvalidListQuery, q.AccessMode)
+	}
+	if q.StarredOnly && q.Viewer == "" {
+		return fmt.Errorf("%w: starred filter needs a signed-in user", ErrInvalidListQuery)
+	}
+	q.Search = strings.TrimSpace(q.Search)
+	return nil
+}
+
+// SubmissionStatus represents the read state of a submission, or that it's quarantined as spam
 type SubmissionStatus string
 
 const (
 	SubmissionStatusUnread SubmissionStatus = "unread"
 	SubmissionStatusRead   SubmissionStatus = "read"
+	SubmissionStatusSpam   SubmissionStatus = "spam" // Quarantined: kept out of the inbox and notifications
 )
 
 // Submission represents a form submission
@@ -84,8 +291,97 @@ type Submission struct {
 	FormID    string           `json:"form_id"`
 	Status    SubmissionStatus `json:"status"`
 	Data      json.RawMessage  `json:"data"`
-	Meta      json.RawMessage  `json:"meta"`
+	Meta      SubmissionMeta   `json:"meta"`
+	IsTest    bool             `json:"is_test"` // Seeded or test data: left out of stats and, by default, exports
 	CreatedAt time.Time        `json:"created_at"`
+
+	// Per-user open tracking, separate from the shared read Status
+	Opened   bool             `json:"opened,omitempty"`    // The requesting user has opened it; set by lists
+	OpenedBy []SubmissionView `json:"opened_by,omitempty"` // Everyone who opened it; set by the detail view
+
+	// Queued is set when the database was unavailable and the submission waits on disk to be stored
+	Queued bool `json:"queued,omitempty"`
+}
+
+// Tombstone identifies a deleted submission, without any of its data
+type Tombstone struct {
+	SubmissionID string    `json:"submission_id"`
+	FormID       string    `json:"-"` // Internal form ID
+	CreatedAt    time.Time `json:"created_at"`
+	IsTest       bool      `json:"test,omitempty"`
+}
+
+// Tombstone returns what's left of the submission once it's deleted
+func (s *Submission) Tombstone() Tombstone {
+	return Tombstone{SubmissionID: s.ID, FormID: s.FormID, CreatedAt: s.CreatedAt, IsTest: s.IsTest}
+}
+
+// DeletionReason says why submissions were deleted
+type DeletionReason string
+
+const (
+	DeletionManual    DeletionReason = "manual"    // By a user: one at a time, by filter or in bulk
+	DeletionRetention DeletionReason = "retention" // Past the test data or the form's retention period
+)
+
+// SubmissionView records a user opening a submission
+type SubmissionView struct {
+	UserID        string    `json:"user_id"`
+	Name          string    `json:"name,omitempty"`
+	Email         string    `json:"email,omitempty"`
+	FirstViewedAt time.Time `json:"first_viewed_at"`
+	LastViewedAt  time.Time `json:"last_viewed_at"`
+}
+
+// SubmissionCounts breaks down the submissions matching a list query by status
+type SubmissionCounts struct {
+	Total    int `json:"total"`
+	Unread   int `json:"unread"`
+	Read     int `json:"read"`
+	Spam     int `json:"spam"`
+	Unopened int `json:"unopened"` // Not yet opened by the requesting user, whatever their shared status
+}
+
+// SubmissionFilter selects submissions for bulk operations. Zero fields match everything.
+type SubmissionFilter struct {
+	Status SubmissionStatus // Only submissions with this status
+	Before time.Time        // Only submissions created before this time
+	IsTest *bool            // Only test (true) or real (false) submissions
+}
+
+// IsEmpty reports whether the filter would match every submission
+func (f SubmissionFilter) IsEmpty() bool {
+	return f.Status == "" && f.Before.IsZero() && f.IsTest == nil
+}
+
+// Matches reports whether the filter selects the submission
+func (f SubmissionFilter) Matches(s *Submission) bool {
+	return (f.Status == "" || s.Status == f.Status) &&
+		(f.Before.IsZero() || s.CreatedAt.Before(f.Before)) &&
+		(f.IsTest == nil || s.IsTest == *f.IsTest)
+}
+
+// BulkAction is an action applied to many submissions at once
+type BulkAction string
+
+const (
+	BulkActionDelete     BulkAction = "delete"
+	BulkActionMarkRead   BulkAction = "mark_read"
+	BulkActionMarkUnread BulkAction = "mark_unread"
+	BulkActionMarkSpam   BulkAction = "mark_spam"
+	BulkActionNotSpam    BulkAction = "mark_not_spam"
+)
+
+// MaxBulkIDs caps the submissions a single bulk action can touch
+const MaxBulkIDs = 1000
+
+// IsValid reports whether the action is known
+func (a BulkAction) IsValid() bool {
+	switch a {
+	case BulkActionDelete, BulkActionMarkRead, BulkActionMarkUnread, BulkActionMarkSpam, BulkActionNotSpam:
+		return true
+	}
+	return false
 }
 
 // DailySubmission represents submission count for a day
@@ -113,3 +409,12 @@ type FormStats struct {
 	SubmissionsToday    int    `json:"submissions_today"`
 	SubmissionsThisWeek int    `json:"submissions_this_week"`
 }
+
+// DailyFormStats contains a single day of analytics for a form (used by stats export)
+type DailyFormStats struct {
+	Date         string   `json:"date"`
+	Submissions  int      `json:"submissions"`
+	Spam         int      `json:"spam"`
+	Conversions  int      `json:"conversions"`   // Submissions not flagged as spam
+	TopReferrers []string `json:"top_referrers"` // Most frequent referers for the day
+}
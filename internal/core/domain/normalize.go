@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+
+	"headless_form/internal/core/textnorm"
+)
+
+// ErrInvalidNormalizeConfig is returned for too many email fields
+var ErrInvalidNormalizeConfig = errors.New("email_fields must list at most 50 field names")
+
+// MaxNormalizeEmailFields caps the fields a normalize config lowercases
+const MaxNormalizeEmailFields = 50
+
+// NormalizeConfig makes a form clean up submitted text before it's validated and stored:
+// Unicode NFC, control characters collapsed to a space and surrounding whitespace trimmed.
+type NormalizeConfig struct {
+	LowercaseEmails bool     `json:"lowercase_emails"`       // Lowercase the email fields too
+	EmailFields     []string `json:"email_fields,omitempty"` // Fields to lowercase; the form's email fields, or "email", when empty
+}
+
+// Validate trims and deduplicates the email field names
+func (c *NormalizeConfig) Validate() error {
+	seen := make(map[string]bool)
+	fields := make([]string, 0, len(c.EmailFields))
+	for _, name := range c.EmailFields {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		fields = append(fields, name)
+	}
+	if len(fields) > MaxNormalizeEmailFields {
+		return ErrInvalidNormalizeConfig
+	}
+	c.EmailFields = fields
+	return nil
+}
+
+// NormalizeData cleans the submitted text of forms with a normalize config, in place.
+// Strings nested in objects and lists are cleaned too; other values are left alone.
+func (f *Form) NormalizeData(data map[string]interface{}) {
+	c := f.Normalize
+	if c == nil {
+		return
+	}
+	for k, v := range data {
+		data[k] = normalizeValue(v)
+	}
+	if !c.LowercaseEmails {
+		return
+	}
+	for _, name := range f.normalizeEmailFields() {
+		if s, ok := data[name].(string); ok {
+			data[name] = strings.ToLower(s)
+		}
+	}
+}
+
+// normalizeEmailFields returns the fields lowercased by the form's normalize config
+func (f *Form) normalizeEmailFields() []string {
+	if len(f.Normalize.EmailFields) > 0 {
+		return f.Normalize.EmailFields
+	}
+	var names []string
+	for _, field := range f.Fields {
+		if field.Type == FieldTypeEmail {
+			names = append(names, field.Name)
+		}
+	}
+	if len(names) == 0 {
+		names = []string{"email"}
+	}
+	return names
+}
+
+func normalizeValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case string:
+		return textnorm.Clean(val)
+	case []interface{}:
+		for i, item := range val {
+			val[i] = normalizeValue(item)
+		}
+	case map[string]interface{}:
+		for k, item := range val {
+			val[k] = normalizeValue(item)
+		}
+	}
+	return v
+}
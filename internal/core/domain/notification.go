@@ -0,0 +1,32 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// NotificationType identifies the event an in-app notification is about
+type NotificationType string
+
+const (
+	NotificationNewSubmission NotificationType = "new_submission" // New submission on a form the user starred
+	NotificationMention       NotificationType = "mention"        // Mentioned in a submission comment
+	NotificationWebhookFailed NotificationType = "webhook_failed" // A form's webhook delivery failed after all retries
+	NotificationAlert         NotificationType = "alert"          // An alert rule on the user's form triggered
+)
+
+// ErrNotificationNotFound is returned for unknown notifications or those of another user
+var ErrNotificationNotFound = errors.New("notification not found")
+
+// Notification is an entry in a user's in-dashboard notification center
+type Notification struct {
+	ID           string           `json:"id"`
+	UserID       string           `json:"user_id"`
+	Type         NotificationType `json:"type"`
+	Title        string           `json:"title"`
+	Body         string           `json:"body,omitempty"`
+	FormID       string           `json:"form_id,omitempty"` // Public ID of the form it concerns
+	SubmissionID string           `json:"submission_id,omitempty"`
+	ReadAt       *time.Time       `json:"read_at,omitempty"`
+	CreatedAt    time.Time        `json:"created_at"`
+}
@@ -0,0 +1,166 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidNotifyRule is returned for notification rules that can't be used
+var ErrInvalidNotifyRule = errors.New("invalid notification rule")
+
+// Notification rule limits
+const (
+	MaxNotifyRules          = 20
+	MaxNotifyRuleRecipients = 50
+)
+
+// NotifyTemplate is the email a notification rule sends
+type NotifyTemplate string
+
+const (
+	NotifyTemplateFull    NotifyTemplate = "full"    // Every field, and the PDF copy if the form attaches one
+	NotifyTemplateSummary NotifyTemplate = "summary" // Only that a submission arrived, with a link to the dashboard
+)
+
+// NotifyRule emails its recipients about the submissions matching its condition.
+// Conditions compare a top-level field with a value: `topic == "sales"`, `rating != 5`.
+// Values are JSON literals, or bare text; list fields match when any item is equal.
+type NotifyRule struct {
+	Recipients []string       `json:"recipients"`
+	Condition  string         `json:"condition,omitempty"` // Every submission matches when empty
+	Template   NotifyTemplate `json:"template"`
+}
+
+// Validate checks the recipients, condition and template, defaulting to the full template
+func (r *NotifyRule) Validate() error {
+	if len(r.Recipients) == 0 || len(r.Recipients) > MaxNotifyRuleRecipients {
+		return fmt.Errorf("%w: between 1 and %d recipients are required", ErrInvalidNotifyRule, MaxNotifyRuleRecipients)
+	}
+	for i, to := range r.Recipients {
+		to = strings.TrimSpace(to)
+		if addr, err := mail.ParseAddress(to); err != nil || addr.Address != to {
+			return fmt.Errorf("%w: invalid recipient %q", ErrInvalidNotifyRule, to)
+		}
+		r.Recipients[i] = to
+	}
+	r.Condition = strings.TrimSpace(r.Condition)
+	if r.Condition != "" {
+		if _, _, _, err := parseCondition(r.Condition); err != nil {
+			return err
+		}
+	}
+	switch r.Template {
+	case "":
+		r.Template = NotifyTemplateFull
+	case NotifyTemplateFull, NotifyTemplateSummary:
+	default:
+		return fmt.Errorf("%w: template must be full or summary", ErrInvalidNotifyRule)
+	}
+	return nil
+}
+
+// Matches reports whether the submitted data meets the rule's condition
+func (r *NotifyRule) Matches(data map[string]interface{}) bool {
+	if r.Condition == "" {
+		return true
+	}
+	field, op, want, err := parseCondition(r.Condition)
+	if err != nil {
+		return false
+	}
+	equal := false
+	if items, ok := data[field].([]interface{}); ok {
+		for _, item := range items {
+			if got, ok := fieldText(item); ok && got == want {
+				equal = true
+				break
+			}
+		}
+	} else if got, ok := fieldText(data[field]); ok {
+		equal = got == want
+	}
+	return equal == (op == "==")
+}
+
+// parseCondition splits `field op value` into its parts, with the value as text
+func parseCondition(cond string) (field, op, value string, err error) {
+	i := strings.Index(cond, "==")
+	if j := strings.Index(cond, "!="); j >= 0 && (i < 0 || j < i) {
+		i = j
+	}
+	if i < 0 {
+		return "", "", "", fmt.Errorf("%w: condition %q must be `field == value` or `field != value`", ErrInvalidNotifyRule, cond)
+	}
+	field = strings.TrimSpace(cond[:i])
+	op = cond[i : i+2]
+	value = strings.TrimSpace(cond[i+2:])
+	if field == "" || strings.ContainsAny(field, " \t\"") {
+		return "", "", "", fmt.Errorf("%w: condition %q needs a field name", ErrInvalidNotifyRule, cond)
+	}
+	if strings.HasPrefix(value, `"`) {
+		if value, err = strconv.Unquote(value); err != nil {
+			return "", "", "", fmt.Errorf("%w: condition %q has an unterminated string", ErrInvalidNotifyRule, cond)
+		}
+	}
+	return field, op, value, nil
+}
+
+// ValidateNotifyRules checks a form's notification rules
+func ValidateNotifyRules(rules []NotifyRule) error {
+	if len(rules) > MaxNotifyRules {
+		return fmt.Errorf("%w: at most %d rules", ErrInvalidNotifyRule, MaxNotifyRules)
+	}
+	for i := range rules {
+		if err := rules[i].Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NotifyRecipients returns who to email about a submission, by template. Forms without
+// rules email their notify list in full. A recipient matched by several rules gets one
+// email, the full one if any of their rules sends it.
+func (f *Form) NotifyRecipients(data map[string]interface{}) map[NotifyTemplate][]string {
+	if len(f.NotifyRules) == 0 {
+		if len(f.NotifyEmails) == 0 {
+			return nil
+		}
+		return map[NotifyTemplate][]string{NotifyTemplateFull: f.NotifyEmails}
+	}
+
+	templates := make(map[string]NotifyTemplate)
+	var order []string
+	for i := range f.NotifyRules {
+		rule := &f.NotifyRules[i]
+		if !rule.Matches(data) {
+			continue
+		}
+		template := rule.Template
+		if template == "" {
+			template = NotifyTemplateFull
+		}
+		for _, to := range rule.Recipients {
+			key := strings.ToLower(to)
+			current, seen := templates[key]
+			if !seen {
+				order = append(order, to)
+			}
+			if !seen || current == NotifyTemplateSummary {
+				templates[key] = template
+			}
+		}
+	}
+	if len(order) == 0 {
+		return nil
+	}
+	recipients := make(map[NotifyTemplate][]string)
+	for _, to := range order {
+		t := templates[strings.ToLower(to)]
+		recipients[t] = append(recipients[t], to)
+	}
+	return recipients
+}
@@ -0,0 +1,152 @@
+package domain
+
+import (
+	"errors"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Sign-in provider errors
+var (
+	ErrInvalidOAuthSettings  = errors.New("each provider needs a unique id (a-z, 0-9, -), a type of google, github or oidc, a client_id and client_secret, and an https issuer for oidc; at most 10 providers, and password login can only be disabled with one configured")
+	ErrPasswordLoginDisabled = errors.New("password login is disabled, sign in with one of the configured providers")
+	ErrOAuthNoAccount        = errors.New("no account uses this email; ask an admin to invite you")
+	ErrOAuthEmailNotVerified = errors.New("the provider did not return a verified email")
+	ErrOAuthDomainNotAllowed = errors.New("this email's domain may not sign in with this provider")
+)
+
+// MaxOAuthProviders is how many sign-in providers the site can have
+const MaxOAuthProviders = 10
+
+// OAuthProviderType is the protocol a sign-in provider speaks
+type OAuthProviderType string
+
+const (
+	OAuthGoogle OAuthProviderType = "google"
+	OAuthGitHub OAuthProviderType = "github"
+	OAuthOIDC   OAuthProviderType = "oidc" // Any OpenID Connect provider, found through discovery
+)
+
+// oauthProviderID is what a provider's id, used in its login and callback URLs, may look like
+var oauthProviderID = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,31}$`)
+
+// OAuthProvider lets dashboard users sign in with an external account. Users are
+// matched to accounts by the verified email the provider returns.
+type OAuthProvider struct {
+	ID             string            `json:"id"`   // Used in the URLs, e.g. /api/v1/auth/oauth/{id}/start
+	Type           OAuthProviderType `json:"type"` // google, github or oidc
+	Name           string            `json:"name,omitempty"`
+	ClientID       string            `json:"client_id"`
+	ClientSecret   string            `json:"client_secret,omitempty"` // Masked in responses
+	Issuer         string            `json:"issuer,omitempty"`        // oidc only, e.g. https://login.example.com
+	Scopes         []string          `json:"scopes,omitempty"`        // Defaults to the scopes that return the email
+	AllowedDomains []string          `json:"allowed_domains,omitempty"`
+	AutoCreate     bool              `json:"auto_create"` // Create a user for emails without an account
+}
+
+// OAuthSettings are the site's sign-in providers
+type OAuthSettings struct {
+	Providers            []OAuthProvider `json:"providers"`
+	DisablePasswordLogin bool            `json:"disable_password_login"`
+}
+
+// Validate checks the providers and tidies their fields
+func (s *OAuthSettings) Validate() error {
+	if len(s.Providers) > MaxOAuthProviders {
+		return ErrInvalidOAuthSettings
+	}
+	seen := make(map[string]bool, len(s.Providers))
+	for i := range s.Providers {
+		p := &s.Providers[i]
+		p.ID = strings.ToLower(strings.TrimSpace(p.ID))
+		p.Name = strings.TrimSpace(p.Name)
+		p.ClientID = strings.TrimSpace(p.ClientID)
+		p.ClientSecret = strings.TrimSpace(p.ClientSecret)
+		p.Issuer = strings.TrimRight(strings.TrimSpace(p.Issuer), "/")
+		if !oauthProviderID.MatchString(p.ID) || seen[p.ID] || p.ClientID == "" || p.ClientSecret == "" {
+			return ErrInvalidOAuthSettings
+		}
+		seen[p.ID] = true
+
+		switch p.Type {
+		case OAuthGoogle, OAuthGitHub:
+			p.Issuer = ""
+		case OAuthOIDC:
+			u, err := url.Parse(p.Issuer)
+			if err != nil || u.Scheme != "https" || u.Host == "" {
+				return ErrInvalidOAuthSettings
+			}
+		default:
+			return ErrInvalidOAuthSettings
+		}
+
+		domains := make([]string, 0, len(p.AllowedDomains))
+		for _, d := range p.AllowedDomains {
+			d = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(d), "@"))
+			if d != "" && !slices.Contains(domains, d) {
+				domains = append(domains, d)
+			}
+		}
+		p.AllowedDomains = domains
+	}
+	if s.DisablePasswordLogin && len(s.Providers) == 0 {
+		return ErrInvalidOAuthSettings
+	}
+	return nil
+}
+
+// Provider returns the provider with the id, or nil
+func (s *OAuthSettings) Provider(id string) *OAuthProvider {
+	if s == nil {
+		return nil
+	}
+	for i := range s.Providers {
+		if s.Providers[i].ID == id {
+			return &s.Providers[i]
+		}
+	}
+	return nil
+}
+
+// PasswordLogin reports whether users may sign in with their password
+func (s *OAuthSettings) PasswordLogin() bool {
+	return s == nil || !s.DisablePasswordLogin || len(s.Providers) == 0
+}
+
+// ToPublic returns the settings with the client secrets masked
+func (s *OAuthSettings) ToPublic() *OAuthSettings {
+	copy := *s
+	copy.Providers = make([]OAuthProvider, len(s.Providers))
+	for i, p := range s.Providers {
+		if p.ClientSecret != "" {
+			p.ClientSecret = "********"
+		}
+		copy.Providers[i] = p
+	}
+	return &copy
+}
+
+// Label is the provider's name on the login page
+func (p *OAuthProvider) Label() string {
+	if p.Name != "" {
+		return p.Name
+	}
+	switch p.Type {
+	case OAuthGoogle:
+		return "Google"
+	case OAuthGitHub:
+		return "GitHub"
+	}
+	return p.ID
+}
+
+// AllowsEmail reports whether the email may sign in with the provider
+func (p *OAuthProvider) AllowsEmail(email string) bool {
+	if len(p.AllowedDomains) == 0 {
+		return true
+	}
+	at := strings.LastIndex(email, "@")
+	return at >= 0 && slices.Contains(p.AllowedDomains, strings.ToLower(email[at+1:]))
+}
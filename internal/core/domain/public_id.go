@@ -0,0 +1,19 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// MaxPublicIDGrace is the longest the old public ID keeps working after a rotation
+const MaxPublicIDGrace = 30 * 24 * time.Hour
+
+// ErrInvalidPublicIDGrace is returned for grace periods outside 0 to MaxPublicIDGrace
+var ErrInvalidPublicIDGrace = errors.New("grace_hours must be between 0 and 720")
+
+// AcceptsPreviousPublicID reports whether publicID is the form's rotated-out public ID
+// and its grace period hasn't ended at now
+func (f *Form) AcceptsPreviousPublicID(publicID string, now time.Time) bool {
+	return f.PreviousPublicID != "" && f.PreviousPublicID == publicID &&
+		f.PreviousPublicIDUntil != nil && now.Before(*f.PreviousPublicIDUntil)
+}
@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Retention actions: what happens to submissions older than their form's retention period
+const (
+	RetentionDelete    = "delete"
+	RetentionAnonymize = "anonymize"
+
+	// MaxRetentionDays caps retention periods at ten years
+	MaxRetentionDays = 3650
+)
+
+// ErrInvalidRetention is returned for an out of range retention period or an unknown action
+var ErrInvalidRetention = errors.New("retention_days must be 0-3650 and retention_action delete or anonymize")
+
+// NormalizeRetention validates a retention period in days, 0 keeping submissions forever,
+// and returns the action taken after it: delete by default, none without a period
+func NormalizeRetention(days int, action string) (string, error) {
+	if days < 0 || days > MaxRetentionDays {
+		return "", ErrInvalidRetention
+	}
+	if days == 0 {
+		return "", nil
+	}
+	switch action {
+	case "":
+		return RetentionDelete, nil
+	case RetentionDelete, RetentionAnonymize:
+		return action, nil
+	}
+	return "", ErrInvalidRetention
+}
+
+// RetentionCutoff returns the time before which the form's submissions have expired,
+// and false if it keeps them forever
+func (f *Form) RetentionCutoff(now time.Time) (time.Time, bool) {
+	if f.RetentionDays <= 0 {
+		return time.Time{}, false
+	}
+	return now.AddDate(0, 0, -f.RetentionDays), true
+}
+
+// Anonymize erases what identifies the submitter once the submission outlived its form's
+// retention period: every field keeps its name but loses its value, and only the
+// non-identifying metadata, the spam verdict and the consent record are kept. Uploaded
+// files are dropped from the metadata; the caller deletes them.
+func (s *Submission) Anonymize(at time.Time) {
+	var data map[string]interface{}
+	if json.Unmarshal(s.Data, &data) == nil && data != nil {
+		for key := range data {
+			data[key] = nil
+		}
+		s.Data, _ = json.Marshal(data)
+	} else {
+		s.Data = json.RawMessage("{}")
+	}
+
+	server := s.Meta.Server
+	s.Meta = SubmissionMeta{
+		Server: ServerMeta{
+			RequestID:   server.RequestID,
+			Timestamp:   server.Timestamp,
+			ContentType: server.ContentType,
+			Protocol:    server.Protocol,
+			Anonymized:  true,
+		},
+		Spam:         s.Meta.Spam,
+		Consent:      s.Meta.Consent,
+		AnonymizedAt: &at,
+	}
+}
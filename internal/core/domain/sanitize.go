@@ -0,0 +1,97 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"html"
+	"regexp"
+	"strings"
+)
+
+// ErrInvalidSanitizeMode is returned for an unknown ?sanitize= value
+var ErrInvalidSanitizeMode = errors.New("sanitize must be escape or strip")
+
+// SanitizeMode is how submitted text is made safe to put into HTML as it is, for API
+// consumers that render values without escaping them. Stored submissions are never changed.
+type SanitizeMode string
+
+const (
+	SanitizeNone   SanitizeMode = ""       // Values as submitted
+	SanitizeEscape SanitizeMode = "escape" // <, >, &, ' and " become HTML entities
+	SanitizeStrip  SanitizeMode = "strip"  // Tags, scripts and styles removed, leaving the text
+)
+
+var (
+	// htmlDangerousBlock matches elements whose content isn't text
+	htmlDangerousBlock = regexp.MustCompile(`(?is)<(script|style|iframe|object|template)\b.*?</(script|style|iframe|object|template)\s*>`)
+	// htmlTagOrComment matches a tag or comment, including one left unclosed at the end
+	htmlTagOrComment = regexp.MustCompile(`(?s)<!--.*?(-->|$)|</?[a-zA-Z!/?][^>]*(>|$)`)
+)
+
+// ParseSanitizeMode reads a ?sanitize= value
+func ParseSanitizeMode(s string) (SanitizeMode, error) {
+	switch m := SanitizeMode(strings.ToLower(strings.TrimSpace(s))); m {
+	case SanitizeNone, SanitizeEscape, SanitizeStrip:
+		return m, nil
+	}
+	return "", ErrInvalidSanitizeMode
+}
+
+// String makes one value safe
+func (m SanitizeMode) String(s string) string {
+	switch m {
+	case SanitizeEscape:
+		return html.EscapeString(s)
+	case SanitizeStrip:
+		s = htmlDangerousBlock.ReplaceAllString(s, "")
+		s = htmlTagOrComment.ReplaceAllString(s, "")
+		// Whatever is left is text: decode entities, then drop the brackets a browser
+		// could still read as markup
+		s = html.UnescapeString(s)
+		return strings.NewReplacer("<", "", ">", "").Replace(s)
+	}
+	return s
+}
+
+// Value makes every string in a decoded JSON value safe, object keys included
+func (m SanitizeMode) Value(v interface{}) interface{} {
+	switch t := v.(type) {
+	case string:
+		return m.String(t)
+	case []interface{}:
+		out := make([]interface{}, len(t))
+		for i, item := range t {
+			out[i] = m.Value(item)
+		}
+		return out
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(t))
+		for k, item := range t {
+			out[m.String(k)] = m.Value(item)
+		}
+		return out
+	}
+	return v
+}
+
+// Sanitized returns a copy of the submission with its data, client meta and the
+// request headers kept in its server meta made safe. Without a mode it's s itself.
+func (s *Submission) Sanitized(m SanitizeMode) *Submission {
+	if m == SanitizeNone {
+		return s
+	}
+	out := *s
+	var data interface{}
+	if json.Unmarshal(s.Data, &data) == nil {
+		out.Data, _ = json.Marshal(m.Value(data))
+	}
+	if s.Meta.Client != nil {
+		out.Meta.Client = m.Value(s.Meta.Client).(map[string]interface{})
+	}
+	server := &out.Meta.Server
+	for _, field := range []*string{&server.UserAgent, &server.Language, &server.Platform, &server.ClientHint,
+		&server.Referer, &server.Origin, &server.ContentType} {
+		*field = m.String(*field)
+	}
+	return &out
+}
@@ -1,6 +1,15 @@
 package domain
 
-import "time"
+import (
+	"errors"
+	"time"
+)
+
+// SpamProviderAkismet checks submissions with Akismet (akismet.com)
+const SpamProviderAkismet = "akismet"
+
+// ErrInvalidSpamProvider is returned for an unknown spam provider, or one without an API key
+var ErrInvalidSpamProvider = errors.New("spam_provider must be empty or akismet, with spam_provider_key set")
 
 // SiteSettings represents global site configuration
 type SiteSettings struct {
@@ -17,6 +26,22 @@ type SiteSettings struct {
 	SMTPFromName string `json:"smtp_from_name"`
 	SMTPSecure   bool   `json:"smtp_secure"` // TLS
 
+	// External spam service, consulted on top of the built-in checks
+	SpamProvider    string `json:"spam_provider"`               // "" (none) or akismet
+	SpamProviderKey string `json:"spam_provider_key,omitempty"` // API key; masked in responses
+
+	// Content filters checked on every form's submissions; managed with /settings/filters
+	Filters []FilterRule `json:"filters,omitempty"`
+
+	// Flag weights, blocked link domains and Safe Browsing; managed with /settings/spam
+	Spam *SpamSettings `json:"spam,omitempty"`
+
+	// Dashboard sign-in with Google, GitHub or OIDC providers; managed with /settings/oauth
+	OAuth *OAuthSettings `json:"oauth,omitempty"`
+
+	// Which roles may export submissions, and the profiles masking their exports; managed with /settings/exports
+	Exports *ExportSettings `json:"exports,omitempty"`
+
 	// System Info (read-only)
 	Version   string    `json:"version"`
 	UpdatedAt time.Time `json:"updated_at"`
@@ -34,6 +59,9 @@ type SiteSettingsUpdate struct {
 	SMTPFrom     *string `json:"smtp_from,omitempty"`
 	SMTPFromName *string `json:"smtp_from_name,omitempty"`
 	SMTPSecure   *bool   `json:"smtp_secure,omitempty"`
+
+	SpamProvider    *string `json:"spam_provider,omitempty"`
+	SpamProviderKey *string `json:"spam_provider_key,omitempty"`
 }
 
 // SMTPConfig returns SMTP configuration for email service
@@ -46,11 +74,33 @@ func (s *SiteSettings) HasSMTPConfig() bool {
 	return s.SMTPHost != "" && s.SMTPPort > 0
 }
 
-// ToPublic returns settings safe for API response (masks password)
+// ValidateSpamProvider checks the spam provider is known and has an API key
+func (s *SiteSettings) ValidateSpamProvider() error {
+	switch s.SpamProvider {
+	case "":
+		return nil
+	case SpamProviderAkismet:
+		if s.SpamProviderKey != "" {
+			return nil
+		}
+	}
+	return ErrInvalidSpamProvider
+}
+
+// ToPublic returns settings safe for API response (masks password, API keys and client secrets)
 func (s *SiteSettings) ToPublic() *SiteSettings {
 	copy := *s
 	if copy.SMTPPassword != "" {
 		copy.SMTPPassword = "********" // Mask password
 	}
+	if copy.SpamProviderKey != "" {
+		copy.SpamProviderKey = "********"
+	}
+	if copy.Spam != nil {
+		copy.Spam = copy.Spam.ToPublic()
+	}
+	if copy.OAuth != nil {
+		copy.OAuth = copy.OAuth.ToPublic()
+	}
 	return &copy
 }
@@ -0,0 +1,198 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Spam settings errors
+var (
+	ErrInvalidSpamConfig   = errors.New("threshold must be 1-100, rate_limit_max 1-1000, rate_limit_window 1-86400 seconds, honeypot_fields at most 10 field names and language a supported language code")
+	ErrInvalidSpamSettings = errors.New("invalid spam settings")
+	ErrInvalidRescore      = errors.New("days must be 1-365")
+	ErrSpamCheckDisabled   = errors.New("the form's spam check is turned off")
+)
+
+// Spam check defaults, used for forms without a spam config of their own
+const (
+	DefaultSpamThreshold   = 50
+	DefaultRateLimitMax    = 10
+	DefaultRateLimitWindow = 60 // Seconds
+	MaxHoneypotFields      = 10
+	MaxBlockedDomains      = 1000
+	DefaultRescoreDays     = 30 // Submissions re-scored after a spam config change, unless asked otherwise
+	MaxRescoreDays         = 365
+)
+
+// Spam flags that can be weighted in the site's spam settings. Flags carry a detail after
+// a colon, e.g. bot_user_agent:curl; the weight applies to all of them.
+const (
+	SpamFlagHoneypot      = "honeypot_filled"
+	SpamFlagEmptyAgent    = "empty_user_agent"
+	SpamFlagBotAgent      = "bot_user_agent"
+	SpamFlagFast          = "fast_submission"
+	SpamFlagRateLimited   = "rate_limited"
+	SpamFlagLinks         = "multiple_links"
+	SpamFlagForeignScript = "foreign_script"
+	SpamFlagGibberish     = "gibberish"
+	SpamFlagBlockedDomain = "blocked_domain"
+	SpamFlagUnsafeLink    = "unsafe_link"
+	SpamFlagProvider      = "provider_spam"
+	SpamFlagRepeat        = "repeat_spam"
+)
+
+// DefaultSpamWeights is what each flag adds to a submission's spam score unless the
+// site's spam settings say otherwise
+var DefaultSpamWeights = map[string]int{
+	SpamFlagHoneypot:      100,
+	SpamFlagEmptyAgent:    30,
+	SpamFlagBotAgent:      40,
+	SpamFlagFast:          25,
+	SpamFlagRateLimited:   30,
+	SpamFlagLinks:         15,
+	SpamFlagForeignScript: 30,
+	SpamFlagGibberish:     25,
+	SpamFlagBlockedDomain: 50,
+	SpamFlagUnsafeLink:    100,
+	SpamFlagProvider:      50,
+	SpamFlagRepeat:        100,
+}
+
+// SpamLanguages maps the language codes a form can be marked with to the Unicode scripts
+// (as named by the unicode package) its text is written in
+var SpamLanguages = map[string][]string{
+	"cs": {"Latin"}, "da": {"Latin"}, "de": {"Latin"}, "en": {"Latin"}, "es": {"Latin"},
+	"et": {"Latin"}, "fi": {"Latin"}, "fr": {"Latin"}, "hr": {"Latin"}, "hu": {"Latin"},
+	"id": {"Latin"}, "it": {"Latin"}, "lt": {"Latin"}, "lv": {"Latin"}, "nl": {"Latin"},
+	"no": {"Latin"}, "pl": {"Latin"}, "pt": {"Latin"}, "ro": {"Latin"}, "sk": {"Latin"},
+	"sl": {"Latin"}, "sv": {"Latin"}, "tr": {"Latin"}, "vi": {"Latin"},
+	"be": {"Cyrillic"}, "bg": {"Cyrillic"}, "kk": {"Cyrillic"}, "mk": {"Cyrillic"},
+	"ru": {"Cyrillic"}, "uk": {"Cyrillic"},
+	"el": {"Greek"},
+	"ar": {"Arabic"}, "fa": {"Arabic"}, "ur": {"Arabic"},
+	"he": {"Hebrew"},
+	"hi": {"Devanagari"}, "mr": {"Devanagari"}, "ne": {"Devanagari"},
+	"th": {"Thai"},
+	"zh": {"Han"},
+	"ja": {"Han", "Hiragana", "Katakana"},
+	"ko": {"Hangul", "Han"},
+}
+
+// SpamConfig tunes the spam check for one form
+type SpamConfig struct {
+	Disabled        bool     `json:"disabled"`                  // Skip the check: nothing is scored or quarantined
+	Threshold       int      `json:"threshold"`                 // Score at which a submission is quarantined (default 50)
+	HoneypotFields  []string `json:"honeypot_fields,omitempty"` // Hidden fields only bots fill in; the built-in list when empty
+	RateLimitMax    int      `json:"rate_limit_max"`            // Submissions from one IP within the window before it scores higher (default 10)
+	RateLimitWindow int      `json:"rate_limit_window"`         // Rate limit window in seconds (default 60)
+	Language        string   `json:"language,omitempty"`        // Language the form is filled in, e.g. en; text in other scripts scores higher
+}
+
+// Validate fills in the defaults, checks the ranges and normalizes the honeypot field names
+func (c *SpamConfig) Validate() error {
+	if c.Threshold == 0 {
+		c.Threshold = DefaultSpamThreshold
+	}
+	if c.RateLimitMax == 0 {
+		c.RateLimitMax = DefaultRateLimitMax
+	}
+	if c.RateLimitWindow == 0 {
+		c.RateLimitWindow = DefaultRateLimitWindow
+	}
+	if c.Threshold < 1 || c.Threshold > 100 || c.RateLimitMax < 1 || c.RateLimitMax > 1000 ||
+		c.RateLimitWindow < 1 || c.RateLimitWindow > 86400 {
+		return ErrInvalidSpamConfig
+	}
+	c.Language = strings.ToLower(strings.TrimSpace(c.Language))
+	if _, ok := SpamLanguages[c.Language]; c.Language != "" && !ok {
+		return ErrInvalidSpamConfig
+	}
+
+	seen := make(map[string]bool)
+	fields := make([]string, 0, len(c.HoneypotFields))
+	for _, name := range c.HoneypotFields {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		fields = append(fields, name)
+	}
+	if len(fields) > MaxHoneypotFields {
+		return ErrInvalidSpamConfig
+	}
+	c.HoneypotFields = fields
+	return nil
+}
+
+// SpamSettings tunes the spam check for every form; kept in the site settings
+type SpamSettings struct {
+	Weights         map[string]int `json:"weights,omitempty"`           // Score per flag, e.g. {"rate_limited": 10}; DefaultSpamWeights for the rest
+	BlockedDomains  []string       `json:"blocked_domains,omitempty"`   // Links to these domains or their subdomains score blocked_domain
+	SafeBrowsingKey string         `json:"safe_browsing_key,omitempty"` // Google Safe Browsing API key; links it lists score unsafe_link
+}
+
+// Validate checks the weights and normalizes the blocked domains to lowercase host names
+func (s *SpamSettings) Validate() error {
+	for flag, weight := range s.Weights {
+		if _, ok := DefaultSpamWeights[flag]; !ok {
+			return fmt.Errorf("%w: unknown flag %q", ErrInvalidSpamSettings, flag)
+		}
+		if weight < 0 || weight > 100 {
+			return fmt.Errorf("%w: weight of %s must be 0-100", ErrInvalidSpamSettings, flag)
+		}
+	}
+
+	domains := make([]string, 0, len(s.BlockedDomains))
+	for _, d := range s.BlockedDomains {
+		d = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(d)), "*.")
+		d = strings.Trim(d, ".")
+		if d == "" || slices.Contains(domains, d) {
+			continue
+		}
+		if len(d) > 253 || strings.ContainsAny(d, "/:@ \t?#") {
+			return fmt.Errorf("%w: %q isn't a domain name", ErrInvalidSpamSettings, d)
+		}
+		domains = append(domains, d)
+	}
+	if len(domains) > MaxBlockedDomains {
+		return fmt.Errorf("%w: at most %d blocked domains", ErrInvalidSpamSettings, MaxBlockedDomains)
+	}
+	s.BlockedDomains = domains
+	s.SafeBrowsingKey = strings.TrimSpace(s.SafeBrowsingKey)
+	return nil
+}
+
+// Weight returns what flag adds to the spam score
+func (s *SpamSettings) Weight(flag string) int {
+	if s != nil {
+		if w, ok := s.Weights[flag]; ok {
+			return w
+		}
+	}
+	return DefaultSpamWeights[flag]
+}
+
+// ToPublic returns the settings with the Safe Browsing key masked
+func (s *SpamSettings) ToPublic() *SpamSettings {
+	copy := *s
+	if copy.SafeBrowsingKey != "" {
+		copy.SafeBrowsingKey = "********"
+	}
+	return &copy
+}
+
+// SpamRescoreResult is the outcome of re-scoring a form's recent submissions with its
+// current spam settings
+type SpamRescoreResult struct {
+	FormID      string    `json:"form_id"`
+	Since       time.Time `json:"since"`
+	Scanned     int       `json:"scanned"`
+	Rescored    int       `json:"rescored"`    // Submissions whose score or flags changed
+	Quarantined int       `json:"quarantined"` // Moved from the inbox to the quarantine
+	Released    int       `json:"released"`    // Moved from the quarantine to the inbox
+	Skipped     int       `json:"skipped"`     // Marked by a user, whose verdict stands
+}
@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"maps"
+	"net/mail"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ErrSpamFingerprintNotFound is returned for fingerprints that aren't tracked
+var ErrSpamFingerprintNotFound = errors.New("spam fingerprint not found")
+
+// Content fingerprint limits
+const (
+	MinFingerprintContent = 20  // Runes of normalized text below which content isn't fingerprinted
+	maxFingerprintSample  = 200 // Runes of normalized text kept to show admins
+)
+
+// SpamFingerprint tracks content users confirmed as spam. Submissions with the same
+// content, on any form, are quarantined until it expires.
+type SpamFingerprint struct {
+	Hash          string    `json:"hash"`          // SHA-256 of the normalized content
+	Sample        string    `json:"sample"`        // Start of the normalized content
+	Confirmations int       `json:"confirmations"` // Times users marked the content as spam
+	Hits          int       `json:"hits"`          // Submissions quarantined because of it
+	CreatedAt     time.Time `json:"created_at"`
+	LastSeenAt    time.Time `json:"last_seen_at"` // Last confirmation or hit
+	ExpiresAt     time.Time `json:"expires_at"`   // Extended by every confirmation and hit
+}
+
+// ContentFingerprint returns the hash of a submission's normalized text, and the start
+// of that text, or "" when there's too little text to tell submissions apart. Text is
+// lowercased with punctuation and whitespace collapsed, and the values are sorted, so
+// field names and order don't matter. Fields starting with _ and bare email addresses,
+// which spammers vary between submissions, are left out.
+func ContentFingerprint(data map[string]interface{}) (hash, sample string) {
+	var values []string
+	for _, k := range slices.Sorted(maps.Keys(data)) {
+		if !strings.HasPrefix(k, "_") {
+			values = appendFingerprintText(values, data[k])
+		}
+	}
+	slices.Sort(values)
+	content := strings.Join(values, "\n")
+	runes := []rune(content)
+	if len(runes) < MinFingerprintContent {
+		return "", ""
+	}
+	sum := sha256.Sum256([]byte(content))
+	if len(runes) > maxFingerprintSample {
+		runes = runes[:maxFingerprintSample]
+	}
+	return hex.EncodeToString(sum[:]), string(runes)
+}
+
+// appendFingerprintText appends the normalized text of a value, and of the items of
+// lists, to values
+func appendFingerprintText(values []string, v interface{}) []string {
+	switch t := v.(type) {
+	case string:
+		if addr, err := mail.ParseAddress(strings.TrimSpace(t)); err == nil && addr.Address == strings.TrimSpace(t) {
+			return values
+		}
+		words := strings.FieldsFunc(strings.ToLower(t), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+		})
+		if len(words) > 0 {
+			values = append(values, strings.Join(words, " "))
+		}
+	case []interface{}:
+		for _, item := range t {
+			values = appendFingerprintText(values, item)
+		}
+	}
+	return values
+}
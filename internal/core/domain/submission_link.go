@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Submission link errors
+var (
+	ErrInvalidLinkField       = errors.New("link_field must be a field name of at most 500 characters, or empty")
+	ErrSubmissionLinkSelf     = errors.New("a submission can't be linked to itself")
+	ErrSubmissionsNotLinkable = errors.New("submissions can only be linked across forms with the same owner or team")
+	ErrSubmissionLinkNotFound = errors.New("submission link not found")
+)
+
+// LinkSource is how two submissions came to be linked
+type LinkSource string
+
+const (
+	LinkSourceField  LinkSource = "field"  // The follow-up held the other's ID in its form's link field
+	LinkSourceManual LinkSource = "manual" // A user linked them
+)
+
+// Which way a related submission is linked, seen from the submission asked about
+const (
+	RelationReferences   = "references"    // The submission follows up on it
+	RelationReferencedBy = "referenced_by" // It follows up on the submission
+)
+
+// SubmissionLink ties a follow-up submission to the one it refers to, e.g. interview
+// feedback to the application, usually across forms
+type SubmissionLink struct {
+	SubmissionID string     `json:"submission_id"` // The follow-up
+	LinkedID     string     `json:"linked_id"`     // The submission it refers to
+	Source       LinkSource `json:"source"`
+	CreatedBy    string     `json:"created_by,omitempty"` // The user who linked them by hand
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// RelatedSubmission is a submission linked to another, with the form it belongs to
+type RelatedSubmission struct {
+	Relation   string      `json:"relation"` // references or referenced_by
+	Source     LinkSource  `json:"source"`
+	FormID     string      `json:"form_id"` // Public ID
+	FormName   string      `json:"form_name"`
+	Submission *Submission `json:"submission"`
+	LinkedAt   time.Time   `json:"linked_at"`
+
+	Form *Form `json:"-"` // For access checks
+}
+
+// NormalizeLinkField trims the name of a form's link field and checks its length
+func NormalizeLinkField(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if len(name) > maxFieldText {
+		return "", ErrInvalidLinkField
+	}
+	return name, nil
+}
+
+// LinkReference returns the submission ID held in the form's link field, or ""
+func (f *Form) LinkReference(data map[string]interface{}) string {
+	if f.LinkField == "" {
+		return ""
+	}
+	ref, _ := data[f.LinkField].(string)
+	return strings.TrimSpace(ref)
+}
+
+// CanLink reports whether submissions of the form may be linked to those of other:
+// both have the same owner, or are shared with the same team
+func (f *Form) CanLink(other *Form) bool {
+	return f.OwnerID == other.OwnerID || (f.TeamID != "" && f.TeamID == other.TeamID)
+}
@@ -0,0 +1,122 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// List status filters beyond the submission statuses themselves
+const (
+	SubmissionListSpam  = string(SubmissionStatusSpam) // Quarantined submissions
+	SubmissionListInbox = "inbox"                      // Read and unread submissions, without the quarantined ones
+)
+
+// MaxFieldFilters caps the field-value filters in a submission list query
+const MaxFieldFilters = 10
+
+// SubmissionListQuery filters a form's submission list. Zero fields match every submission.
+type SubmissionListQuery struct {
+	Search string            // Case-insensitive substring of any submitted text or number
+	Status string            // read, unread, spam or inbox
+	From   time.Time         // Only submissions created at or after this time
+	To     time.Time         // Only submissions created before this time
+	Fields map[string]string // Only submissions whose top-level field has this value, compared as text
+}
+
+// Validate checks the status, date range and field names, trimming the search
+func (q *SubmissionListQuery) Validate() error {
+	switch q.Status {
+	case "", string(SubmissionStatusRead), string(SubmissionStatusUnread), SubmissionListSpam, SubmissionListInbox:
+	default:
+		return fmt.Errorf("%w: status must be read, unread, spam or inbox", ErrInvalidListQuery)
+	}
+	if !q.From.IsZero() && !q.To.IsZero() && !q.From.Before(q.To) {
+		return fmt.Errorf("%w: from must be before to", ErrInvalidListQuery)
+	}
+	if len(q.Fields) > MaxFieldFilters {
+		return fmt.Errorf("%w: at most %d field filters", ErrInvalidListQuery, MaxFieldFilters)
+	}
+	for name := range q.Fields {
+		// Names end up in a JSON path
+		if name == "" || strings.ContainsAny(name, `"\`) {
+			return fmt.Errorf("%w: invalid field name %q", ErrInvalidListQuery, name)
+		}
+	}
+	q.Search = strings.TrimSpace(q.Search)
+	return nil
+}
+
+// Matches reports whether the query selects the submission; repositories filter the same way in SQL
+func (q SubmissionListQuery) Matches(s *Submission) bool {
+	status := s.Status
+	if status == "" {
+		status = SubmissionStatusUnread
+	}
+	switch q.Status {
+	case SubmissionListInbox:
+		if status == SubmissionStatusSpam {
+			return false
+		}
+	case string(SubmissionStatusRead), string(SubmissionStatusUnread), SubmissionListSpam:
+		if string(status) != q.Status {
+			return false
+		}
+	}
+	if (!q.From.IsZero() && s.CreatedAt.Before(q.From)) || (!q.To.IsZero() && !s.CreatedAt.Before(q.To)) {
+		return false
+	}
+	if q.Search == "" && len(q.Fields) == 0 {
+		return true
+	}
+
+	var data map[string]interface{}
+	if err := json.Unmarshal(s.Data, &data); err != nil {
+		return false
+	}
+	for name, want := range q.Fields {
+		got, ok := fieldText(data[name])
+		if !ok || got != want {
+			return false
+		}
+	}
+	return q.Search == "" || containsText(data, strings.ToLower(q.Search))
+}
+
+// fieldText renders a scalar JSON value as text: strings as is, numbers in their
+// shortest form and booleans as true or false
+func fieldText(v interface{}) (string, bool) {
+	switch val := v.(type) {
+	case string:
+		return val, true
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64), true
+	case bool:
+		return strconv.FormatBool(val), true
+	}
+	return "", false
+}
+
+// containsText reports whether any string or number in v, at any depth, contains the lowercase search
+func containsText(v interface{}, search string) bool {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for _, item := range val {
+			if containsText(item, search) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, item := range val {
+			if containsText(item, search) {
+				return true
+			}
+		}
+	case string, float64:
+		text, _ := fieldText(val)
+		return strings.Contains(strings.ToLower(text), search)
+	}
+	return false
+}
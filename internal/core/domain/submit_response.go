@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"errors"
+	"net/http"
+)
+
+// ErrInvalidSubmitStatus is returned for a success status other than 200, 201 or 204
+var ErrInvalidSubmitStatus = errors.New("status must be 200, 201 or 204")
+
+// SubmitResponseConfig changes how successful submissions are answered, for client
+// frameworks that expect a bare object or a plain 200. Errors keep the usual envelope.
+type SubmitResponseConfig struct {
+	Raw    bool `json:"raw"`    // Return the submission itself instead of {"status", "data"}
+	Status int  `json:"status"` // 200, 201 (default) or 204 without a body
+}
+
+// Validate defaults the status to 201 Created and checks it's one clients can rely on
+func (c *SubmitResponseConfig) Validate() error {
+	if c.Status == 0 {
+		c.Status = http.StatusCreated
+	}
+	switch c.Status {
+	case http.StatusOK, http.StatusCreated, http.StatusNoContent:
+		return nil
+	}
+	return ErrInvalidSubmitStatus
+}
@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Task errors
+var (
+	ErrTaskNotFound   = errors.New("task not found")
+	ErrTaskInProgress = errors.New("a task of this type is already running")
+	ErrTaskFinished   = errors.New("task has already finished")
+	ErrTaskNoFile     = errors.New("task has no file to download, or it has expired")
+
+	// ErrSeedLimit is returned when a seed request asks for more forms or submissions than allowed
+	ErrSeedLimit = errors.New("too much test data requested")
+	// ErrSampleDataLimit is returned when creating sample data would leave a user with more sample forms than allowed
+	ErrSampleDataLimit = errors.New("too many sample forms; purge the sample data first")
+)
+
+// TaskType identifies the operation a background task runs
+type TaskType string
+
+const (
+	TaskTypeSeed           TaskType = "seed"            // Test data seeding (admin)
+	TaskTypeExportParquet  TaskType = "export_parquet"  // Form submissions as a Parquet file
+	TaskTypeFieldMigration TaskType = "field_migration" // Renaming a field across a form's submissions (admin)
+	TaskTypeSpamRescore    TaskType = "spam_rescore"    // Re-scoring a form's recent submissions after a spam config change
+	TaskTypeSampleData     TaskType = "sample_data"     // Realistic sample forms and submissions for a user to explore
+)
+
+// TaskStatus is the state of a background task
+type TaskStatus string
+
+const (
+	TaskPending   TaskStatus = "pending"
+	TaskRunning   TaskStatus = "running"
+	TaskCompleted TaskStatus = "completed"
+	TaskFailed    TaskStatus = "failed"
+	TaskCancelled TaskStatus = "cancelled"
+)
+
+// IsFinal reports whether the task has stopped and won't change anymore
+func (s TaskStatus) IsFinal() bool {
+	return s == TaskCompleted || s == TaskFailed || s == TaskCancelled
+}
+
+// Task is a long-running operation executed in the background, with its progress
+type Task struct {
+	ID         string          `json:"id"`
+	Type       TaskType        `json:"type"`
+	Status     TaskStatus      `json:"status"`
+	Progress   float64         `json:"progress"`          // Percentage done, 0-100
+	Message    string          `json:"message,omitempty"` // Human-readable progress note
+	Result     json.RawMessage `json:"result,omitempty"`  // Operation-specific output once completed
+	Error      string          `json:"error,omitempty"`
+	File       *TaskFile       `json:"file,omitempty"` // Output to download, until it expires
+	CreatedBy  string          `json:"created_by"`
+	CreatedAt  time.Time       `json:"created_at"`
+	StartedAt  *time.Time      `json:"started_at,omitempty"`
+	FinishedAt *time.Time      `json:"finished_at,omitempty"`
+}
+
+// TaskFile is a file produced by a task, such as an export, kept in file storage
+// and downloaded from GET /api/v1/tasks/{task_id}/download
+type TaskFile struct {
+	Name        string    `json:"name"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// TaskFileKey returns where a task's file is kept in file storage
+func TaskFileKey(taskID string) string {
+	return "tasks/" + taskID
+}
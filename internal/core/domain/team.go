@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Team errors
+var (
+	ErrTeamNotFound     = errors.New("team not found")
+	ErrTeamNameRequired = errors.New("team name is required (at most 100 characters)")
+	ErrInvalidTeamRole  = errors.New("team role must be owner, editor or viewer")
+	ErrNotTeamMember    = errors.New("user is not a member of this team")
+	ErrLastTeamOwner    = errors.New("a team needs at least one owner; make someone else owner first")
+)
+
+// MaxTeamNameLength is the longest team name, in characters
+const MaxTeamNameLength = 100
+
+// TeamRole is what a member may do with the team and its forms
+type TeamRole string
+
+const (
+	TeamRoleOwner  TeamRole = "owner"  // Manages the team's forms and its members
+	TeamRoleEditor TeamRole = "editor" // Manages the team's forms
+	TeamRoleViewer TeamRole = "viewer" // Sees the team's forms and their submissions
+)
+
+// Valid reports whether r is a known role
+func (r TeamRole) Valid() bool {
+	return r == TeamRoleOwner || r == TeamRoleEditor || r == TeamRoleViewer
+}
+
+// CanManageForms reports whether the role may change the team's forms
+func (r TeamRole) CanManageForms() bool {
+	return r == TeamRoleOwner || r == TeamRoleEditor
+}
+
+// Team is a group of users sharing forms. A form belongs to at most one team;
+// its owner keeps full access either way.
+type Team struct {
+	ID        string       `json:"id"`
+	Name      string       `json:"name"`
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+	Members   []TeamMember `json:"members,omitempty"` // Set by the detail view
+	Role      TeamRole     `json:"role,omitempty"`    // The requesting user's role; set by lists
+}
+
+// TeamMember is a user's membership in a team
+type TeamMember struct {
+	TeamID  string    `json:"-"`
+	UserID  string    `json:"user_id"`
+	Email   string    `json:"email"`
+	Name    string    `json:"name,omitempty"`
+	Role    TeamRole  `json:"role"`
+	AddedAt time.Time `json:"added_at"`
+}
+
+// NormalizeTeamName trims the name and checks its length
+func NormalizeTeamName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > MaxTeamNameLength {
+		return "", ErrTeamNameRequired
+	}
+	return name, nil
+}
+
+// Owners counts the team's owners
+func (t *Team) Owners() int {
+	n := 0
+	for _, m := range t.Members {
+		if m.Role == TeamRoleOwner {
+			n++
+		}
+	}
+	return n
+}
+
+// Member returns the user's membership, or nil
+func (t *Team) Member(userID string) *TeamMember {
+	for i := range t.Members {
+		if t.Members[i].UserID == userID {
+			return &t.Members[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// ErrInvalidTimezone is returned for names that aren't IANA time zones
+var ErrInvalidTimezone = errors.New("timezone must be an IANA time zone name like Europe/Berlin or America/New_York")
+
+// NormalizeTimezone trims an IANA time zone name and checks it exists. An empty
+// name is valid and means "not set". "Local" is rejected because it depends on
+// the server the API happens to run on.
+func NormalizeTimezone(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", nil
+	}
+	if strings.EqualFold(name, "Local") {
+		return "", ErrInvalidTimezone
+	}
+	if _, err := time.LoadLocation(name); err != nil {
+		return "", ErrInvalidTimezone
+	}
+	return name, nil
+}
+
+// LoadTimezone returns the location of the first valid, non-empty time zone
+// name, falling back to UTC
+func LoadTimezone(names ...string) *time.Location {
+	for _, name := range names {
+		if name == "" || strings.EqualFold(name, "Local") {
+			continue
+		}
+		if loc, err := time.LoadLocation(name); err == nil {
+			return loc
+		}
+	}
+	return time.UTC
+}
+
+// StartOfDay returns midnight of the day t falls on in loc
+func StartOfDay(t time.Time, loc *time.Location) time.Time {
+	y, m, d := t.In(loc).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, loc)
+}
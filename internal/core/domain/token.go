@@ -0,0 +1,29 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// FormTokenPrefix marks API tokens so the auth middleware can tell them apart from JWTs
+const FormTokenPrefix = "hft_"
+
+// Form token errors
+var (
+	ErrFormTokenNotFound = errors.New("form token not found")
+	ErrInvalidFormToken  = errors.New("invalid form token")
+	ErrTokenNameRequired = errors.New("token name is required")
+)
+
+// FormToken is a read-only API token scoped to a single form's submissions.
+// Only the SHA-256 hash of the token is stored; the plaintext is shown once on creation.
+type FormToken struct {
+	ID         string     `json:"id"`
+	FormID     string     `json:"form_id"`
+	Name       string     `json:"name"`
+	Hint       string     `json:"hint"` // Last characters of the token, to help users tell tokens apart
+	TokenHash  string     `json:"-"`
+	CreatedBy  string     `json:"created_by"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
@@ -0,0 +1,134 @@
+package domain
+
+import (
+	"errors"
+	"mime"
+	"path"
+	"strings"
+)
+
+// Upload errors
+var (
+	ErrUploadsDisabled     = errors.New("this form doesn't accept file uploads")
+	ErrFileTooLarge        = errors.New("file is too large")
+	ErrTooManyFiles        = errors.New("too many files")
+	ErrFileTypeNotAllowed  = errors.New("file type is not allowed")
+	ErrFileNotFound        = errors.New("file not found")
+	ErrInvalidUploadLimits = errors.New("max_file_size must be at most 100 MB and max_files at most 20")
+	ErrInvalidFileType     = errors.New("allowed_types entries must be MIME types like image/png or image/*, or extensions like .pdf")
+)
+
+// Upload limits
+const (
+	DefaultMaxFileSize = 10 << 20  // 10 MB
+	MaxFileSizeLimit   = 100 << 20 // 100 MB
+	DefaultMaxFiles    = 5
+	MaxFilesLimit      = 20
+)
+
+// UploadConfig lets a form accept files with its submissions. Forms without one reject files.
+type UploadConfig struct {
+	MaxFileSize  int64    `json:"max_file_size"`           // Bytes per file (default 10 MB)
+	MaxFiles     int      `json:"max_files"`               // Files per submission (default 5)
+	AllowedTypes []string `json:"allowed_types,omitempty"` // MIME types (image/png, image/*) or extensions (.pdf); empty allows any
+}
+
+// Validate fills in the default limits and normalizes the allowed types
+func (c *UploadConfig) Validate() error {
+	if c.MaxFileSize == 0 {
+		c.MaxFileSize = DefaultMaxFileSize
+	}
+	if c.MaxFiles == 0 {
+		c.MaxFiles = DefaultMaxFiles
+	}
+	if c.MaxFileSize < 0 || c.MaxFileSize > MaxFileSizeLimit || c.MaxFiles < 0 || c.MaxFiles > MaxFilesLimit {
+		return ErrInvalidUploadLimits
+	}
+
+	types, err := normalizeFileTypes(c.AllowedTypes)
+	if err != nil {
+		return err
+	}
+	c.AllowedTypes = types
+	return nil
+}
+
+// normalizeFileTypes lowercases and deduplicates allowed file types, checking each
+// is a MIME type or an extension
+func normalizeFileTypes(allowed []string) ([]string, error) {
+	seen := make(map[string]bool)
+	types := make([]string, 0, len(allowed))
+	for _, t := range allowed {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t == "" || seen[t] {
+			continue
+		}
+		if !strings.HasPrefix(t, ".") {
+			major, minor, ok := strings.Cut(t, "/")
+			if !ok || major == "" || minor == "" || major == "*" || strings.ContainsAny(t, " ;,") {
+				return nil, ErrInvalidFileType
+			}
+		} else if len(t) < 2 || strings.ContainsAny(t, "/\\ ") {
+			return nil, ErrInvalidFileType
+		}
+		seen[t] = true
+		types = append(types, t)
+	}
+	return types, nil
+}
+
+// Allows reports whether a file may be uploaded. Extensions are matched against
+// the file name, MIME types against contentType, which should be sniffed from
+// the file's content rather than taken from the client.
+func (c *UploadConfig) Allows(name, contentType string) bool {
+	return fileTypeAllowed(c.AllowedTypes, name, contentType)
+}
+
+// fileTypeAllowed matches a file against allowed types; an empty list allows any
+func fileTypeAllowed(allowed []string, name, contentType string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	ext := strings.ToLower(path.Ext(name))
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = strings.ToLower(contentType)
+	}
+	major, _, _ := strings.Cut(mediaType, "/")
+	for _, t := range allowed {
+		switch {
+		case strings.HasPrefix(t, "."):
+			if ext == t {
+				return true
+			}
+		case strings.HasSuffix(t, "/*"):
+			if major+"/*" == t {
+				return true
+			}
+		case mediaType == t:
+			return true
+		}
+	}
+	return false
+}
+
+// MaxRequestSize is the largest multipart body worth reading for the form:
+// every file at its maximum size plus room for the other fields
+func (c *UploadConfig) MaxRequestSize() int64 {
+	return int64(c.MaxFiles)*c.MaxFileSize + 1<<20
+}
+
+// FileRef describes a file uploaded with a submission. The submission's data holds
+// it under the file input's name; its meta lists every file (see SubmissionMeta.Files).
+type FileRef struct {
+	FileID      string `json:"file_id"`
+	Field       string `json:"field"`
+	Name        string `json:"name"`
+	Size        int64  `json:"size"`
+	ContentType string `json:"content_type"`
+}
+
+// FileKey returns where a form's uploaded file is kept in file storage
+func FileKey(formID, fileID string) string {
+	return formID + "/" + fileID
+}
@@ -6,7 +6,7 @@ import (
 	"strings"
 	"time"
 
-	"golang.org/x/crypto/bcrypt"
+	"headless_form/internal/core/passhash"
 )
 
 // User roles
@@ -49,6 +49,7 @@ type User struct {
 	PasswordHash string    `json:"-"` // Never expose in JSON
 	Name         string    `json:"name"`
 	Role         UserRole  `json:"role"`
+	Timezone     string    `json:"timezone,omitempty"` // IANA zone for the dashboard stats; UTC when empty
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
 }
@@ -58,18 +59,23 @@ func (u *User) SetPassword(password string) error {
 	if len(password) < 8 {
 		return ErrPasswordTooShort
 	}
-	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	hash, err := passhash.Hash(password)
 	if err != nil {
 		return err
 	}
-	u.PasswordHash = string(hash)
+	u.PasswordHash = hash
 	return nil
 }
 
 // CheckPassword verifies the password against the stored hash
 func (u *User) CheckPassword(password string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password))
-	return err == nil
+	return passhash.Verify(u.PasswordHash, password)
+}
+
+// PasswordNeedsRehash reports whether the stored hash uses another algorithm or
+// weaker parameters than currently configured
+func (u *User) PasswordNeedsRehash() bool {
+	return passhash.NeedsRehash(u.PasswordHash)
 }
 
 // Validate validates user fields
@@ -91,6 +97,7 @@ type UserPublic struct {
 	Email     string    `json:"email"`
 	Name      string    `json:"name"`
 	Role      UserRole  `json:"role"`
+	Timezone  string    `json:"timezone,omitempty"`
 	CreatedAt time.Time `json:"created_at"`
 }
 
@@ -101,6 +108,7 @@ func (u *User) ToPublic() *UserPublic {
 		Email:     u.Email,
 		Name:      u.Name,
 		Role:      u.Role,
+		Timezone:  u.Timezone,
 		CreatedAt: u.CreatedAt,
 	}
 }
@@ -0,0 +1,99 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"headless_form/internal/core/passhash"
+)
+
+// UserExportVersion is the format version of user exports
+const UserExportVersion = 1
+
+// ErrInvalidUserExport is returned when importing a malformed user export
+var ErrInvalidUserExport = errors.New("invalid user export")
+
+// UserExport carries an instance's users and who owns which form to another
+// instance, e.g. when moving a self-hosted server or from SQLite to Postgres
+type UserExport struct {
+	Version    int             `json:"version"`
+	ExportedAt time.Time       `json:"exported_at"`
+	Users      []ExportedUser  `json:"users"`
+	Forms      []FormOwnership `json:"forms"`
+}
+
+// ExportedUser is a user in an export. Without a password hash the user is
+// invited to set a password on the instance they're imported into.
+type ExportedUser struct {
+	Email        string    `json:"email"`
+	Name         string    `json:"name,omitempty"`
+	Role         UserRole  `json:"role"`
+	Timezone     string    `json:"timezone,omitempty"`
+	PasswordHash string    `json:"password_hash,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// FormOwnership maps a form, by public ID or else slug, to its owner's email
+type FormOwnership struct {
+	PublicID string `json:"public_id"`
+	Slug     string `json:"slug,omitempty"`
+	Name     string `json:"name,omitempty"` // For people reading the export
+	Owner    string `json:"owner"`
+}
+
+// Validate checks the export's version, that every user has a unique valid email and a
+// known role, and that password hashes come from a supported algorithm. Empty roles
+// default to user.
+func (e *UserExport) Validate() error {
+	if e.Version != UserExportVersion {
+		return fmt.Errorf("%w: unsupported version %d", ErrInvalidUserExport, e.Version)
+	}
+	seen := make(map[string]bool, len(e.Users))
+	for i := range e.Users {
+		u := &e.Users[i]
+		u.Email = strings.TrimSpace(u.Email)
+		if !emailRegex.MatchString(u.Email) {
+			return fmt.Errorf("%w: user %d has an invalid email", ErrInvalidUserExport, i+1)
+		}
+		key := strings.ToLower(u.Email)
+		if seen[key] {
+			return fmt.Errorf("%w: %s is listed twice", ErrInvalidUserExport, u.Email)
+		}
+		seen[key] = true
+		switch u.Role {
+		case "":
+			u.Role = RoleUser
+		case RoleUser, RoleAdmin, RoleSuperAdmin:
+		default:
+			return fmt.Errorf("%w: %s has unknown role %q", ErrInvalidUserExport, u.Email, u.Role)
+		}
+		if u.PasswordHash != "" && !passhash.Recognized(u.PasswordHash) {
+			return fmt.Errorf("%w: %s has an unsupported password hash", ErrInvalidUserExport, u.Email)
+		}
+	}
+	for i, f := range e.Forms {
+		if f.PublicID == "" && f.Slug == "" {
+			return fmt.Errorf("%w: form %d has no public_id or slug", ErrInvalidUserExport, i+1)
+		}
+		if f.Owner == "" {
+			return fmt.Errorf("%w: form %d has no owner", ErrInvalidUserExport, i+1)
+		}
+	}
+	return nil
+}
+
+// UserImportResult reports what importing a user export did
+type UserImportResult struct {
+	DryRun          bool     `json:"dry_run,omitempty"`
+	Created         []string `json:"created"`                    // Emails of the users created
+	Existing        []string `json:"existing"`                   // Emails of the users already here, left unchanged
+	Invited         []string `json:"invited"`                    // Created without a password: sent a link to set one
+	FormsAssigned   int      `json:"forms_assigned"`             // Forms handed to their owner
+	FormsNotFound   []string `json:"forms_not_found,omitempty"`  // Listed forms that aren't on this instance
+	FormsUnassigned []string `json:"forms_unassigned,omitempty"` // Forms whose owner isn't a user here
+
+	// InviteTokens are the password setup tokens of the invited users, by email, for the caller to send
+	InviteTokens map[string]string `json:"-"`
+}
@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Webhook delivery errors
+var (
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+	ErrWebhookNotConfigured    = errors.New("the form has no webhook URL")
+	ErrDeliveryNotReplayable   = errors.New("the delivery has no payload to replay because its transform failed")
+)
+
+// WebhookDeliveryStatus is where a delivery stands
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending" // Being attempted or waiting to retry
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "succeeded"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed" // Every attempt failed
+)
+
+// WebhookDelivery records a webhook sent for a form, with the outcome of each attempt
+type WebhookDelivery struct {
+	ID        string                `json:"id"`
+	FormID    string                `json:"-"` // Internal form ID
+	Event     string                `json:"event"`
+	URL       string                `json:"url"`
+	Payload   json.RawMessage       `json:"payload,omitempty"` // Body sent, kept for replays
+	Status    WebhookDeliveryStatus `json:"status"`
+	Attempts  []WebhookAttempt      `json:"attempts"`
+	Error     string                `json:"error,omitempty"`     // Why nothing could be sent, e.g. a failed transform
+	Test      bool                  `json:"test,omitempty"`      // Left out of the stats used by alerts
+	ReplayOf  string                `json:"replay_of,omitempty"` // Delivery this one replays
+	CreatedAt time.Time             `json:"created_at"`
+	UpdatedAt time.Time             `json:"updated_at"`
+}
+
+// WebhookAttempt is the outcome of one request of a delivery
+type WebhookAttempt struct {
+	At         time.Time `json:"at"`
+	StatusCode int       `json:"status_code,omitempty"` // 0 when no response came back
+	LatencyMs  int64     `json:"latency_ms"`
+	Response   string    `json:"response,omitempty"` // Start of the response body
+	Error      string    `json:"error,omitempty"`
+}
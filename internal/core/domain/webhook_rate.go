@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrInvalidWebhookRate is returned for rate limits or batch settings out of range
+var ErrInvalidWebhookRate = errors.New("max_per_minute must be 0-6000, batch_interval 0 or 5-3600 seconds and max_batch_size 0-1000")
+
+// Webhook rate limit and batch bounds
+const (
+	MaxWebhookPerMinute     = 6000
+	MinWebhookBatchInterval = 5    // Seconds
+	MaxWebhookBatchInterval = 3600 // Seconds
+	MaxWebhookBatchSize     = 1000
+	DefaultWebhookBatchSize = 100
+)
+
+// WebhookRate keeps a busy form from overwhelming its webhook: requests are spaced out to
+// at most MaxPerMinute, and with a BatchInterval new submissions are collected and sent
+// together as one payload
+type WebhookRate struct {
+	MaxPerMinute  int `json:"max_per_minute,omitempty"` // Requests per minute, retries and replays included; unlimited when 0
+	BatchInterval int `json:"batch_interval,omitempty"` // Seconds submissions are collected for before they're sent; one by one when 0
+	MaxBatchSize  int `json:"max_batch_size,omitempty"` // Submissions per batch, sent early when reached; DefaultWebhookBatchSize when 0
+}
+
+// Validate checks the limits are in range
+func (c *WebhookRate) Validate() error {
+	if c.MaxPerMinute < 0 || c.MaxPerMinute > MaxWebhookPerMinute ||
+		(c.BatchInterval != 0 && (c.BatchInterval < MinWebhookBatchInterval || c.BatchInterval > MaxWebhookBatchInterval)) ||
+		c.MaxBatchSize < 0 || c.MaxBatchSize > MaxWebhookBatchSize {
+		return ErrInvalidWebhookRate
+	}
+	return nil
+}
+
+// Spacing returns the least time between two requests to the webhook, 0 when unlimited.
+// Safe to call on nil.
+func (c *WebhookRate) Spacing() time.Duration {
+	if c == nil || c.MaxPerMinute == 0 {
+		return 0
+	}
+	return time.Minute / time.Duration(c.MaxPerMinute)
+}
+
+// Batching reports whether new submissions are sent in batches. Safe to call on nil.
+func (c *WebhookRate) Batching() bool {
+	return c != nil && c.BatchInterval > 0
+}
+
+// Interval returns how long submissions are collected for before a batch is sent
+func (c *WebhookRate) Interval() time.Duration {
+	return time.Duration(c.BatchInterval) * time.Second
+}
+
+// BatchSize returns how many submissions a batch holds at most
+func (c *WebhookRate) BatchSize() int {
+	if c.MaxBatchSize == 0 {
+		return DefaultWebhookBatchSize
+	}
+	return c.MaxBatchSize
+}
@@ -0,0 +1,212 @@
+// Package ids provides configurable ID generation for entities.
+// By default every entity gets a UUID (the historical format); short prefixed IDs
+// such as "form_x7Ab3kQz9LmP" can be enabled per entity type. Lookups treat IDs as
+// opaque strings, so existing UUIDs keep working after switching styles.
+package ids
+
+import (
+	"crypto/rand"
+	"fmt"
+	"regexp"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// Entity identifies the kind of object an ID is generated for
+type Entity string
+
+const (
+	Form          Entity = "form"
+	FormPublic    Entity = "form_public" // Public submission URL identifier
+	Submission    Entity = "submission"
+	User          Entity = "user"
+	PasswordReset Entity = "password_reset"
+	Alert         Entity = "alert"
+	FormToken     Entity = "form_token"
+	AuditEntry    Entity = "audit_entry"
+	Task          Entity = "task"
+	Comment       Entity = "comment"
+	Notification  Entity = "notification"
+	File          Entity = "file"
+	Delivery      Entity = "webhook_delivery"
+	Filter        Entity = "filter"
+	Email         Entity = "email"
+	Team          Entity = "team"
+)
+
+// Style selects how IDs are generated
+type Style string
+
+const (
+	StyleUUID  Style = "uuid"
+	StyleShort Style = "short"
+)
+
+// DefaultShortLength is the number of random base62 characters in a short ID (~71 bits)
+const DefaultShortLength = 12
+
+// Bounds on the random part of short IDs: shorter ones collide too easily, longer ones
+// only make URLs unwieldy
+const (
+	MinShortLength = 8  // ~47 bits
+	MaxShortLength = 64 // ~381 bits
+)
+
+// validPrefix is what a short ID prefix may look like, so IDs stay URL-safe
+var validPrefix = regexp.MustCompile(`^[A-Za-z0-9_-]{0,16}$`)
+
+// DefaultPrefixes are used for short IDs when no prefix is configured
+var DefaultPrefixes = map[Entity]string{
+	Form:          "frm_",
+	FormPublic:    "form_",
+	Submission:    "sub_",
+	User:          "usr_",
+	PasswordReset: "pwr_",
+	Alert:         "alr_",
+	FormToken:     "tok_",
+	AuditEntry:    "aud_",
+	Task:          "task_",
+	Comment:       "cmt_",
+	Notification:  "ntf_",
+	File:          "fil_",
+	Delivery:      "whd_",
+	Filter:        "flt_",
+	Email:         "eml_",
+	Team:          "team_",
+}
+
+// EntityConfig configures ID generation for one entity type
+type EntityConfig struct {
+	Style  Style
+	Prefix string
+	Length int // Random characters for short IDs (default DefaultShortLength)
+}
+
+// Config configures the generator. Entities without an entry use Default.
+type Config struct {
+	Default  EntityConfig
+	Entities map[Entity]EntityConfig
+}
+
+// Validate checks the styles, prefixes and lengths of short IDs
+func (c Config) Validate() error {
+	if err := c.Default.validate(); err != nil {
+		return fmt.Errorf("default: %w", err)
+	}
+	for entity, cfg := range c.Entities {
+		if err := cfg.validate(); err != nil {
+			return fmt.Errorf("%s: %w", entity, err)
+		}
+	}
+	return nil
+}
+
+func (c EntityConfig) validate() error {
+	switch c.Style {
+	case "", StyleUUID, StyleShort:
+	default:
+		return fmt.Errorf("unknown ID style %q (want uuid or short)", c.Style)
+	}
+	if c.Length != 0 && (c.Length < MinShortLength || c.Length > MaxShortLength) {
+		return fmt.Errorf("short ID length %d is out of range (%d-%d)", c.Length, MinShortLength, MaxShortLength)
+	}
+	if !validPrefix.MatchString(c.Prefix) {
+		return fmt.Errorf("short ID prefix %q must be at most 16 letters, digits, _ or -", c.Prefix)
+	}
+	return nil
+}
+
+// Generator creates IDs according to its configuration
+type Generator struct {
+	config Config
+}
+
+// NewGenerator creates a generator from config
+func NewGenerator(config Config) *Generator {
+	if config.Default.Style == "" {
+		config.Default.Style = StyleUUID
+	}
+	return &Generator{config: config}
+}
+
+// ShortConfig returns a config that uses short IDs with the default prefixes for every entity
+func ShortConfig(length int) Config {
+	entities := make(map[Entity]EntityConfig, len(DefaultPrefixes))
+	for entity, prefix := range DefaultPrefixes {
+		entities[entity] = EntityConfig{Style: StyleShort, Prefix: prefix, Length: length}
+	}
+	return Config{Default: EntityConfig{Style: StyleShort, Length: length}, Entities: entities}
+}
+
+// New returns a new ID for the entity
+func (g *Generator) New(entity Entity) string {
+	cfg, ok := g.config.Entities[entity]
+	if !ok {
+		cfg = g.config.Default
+	}
+
+	if cfg.Style != StyleShort {
+		return uuid.New().String()
+	}
+
+	length := cfg.Length
+	if length <= 0 {
+		length = DefaultShortLength
+	}
+	return cfg.Prefix + randomString(length)
+}
+
+// base62 is the alphabet for short IDs (URL-safe, no punctuation)
+const base62 = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// base62Limit is the largest multiple of len(base62) a byte can hold; random bytes at or
+// above it are skipped, so every character is equally likely
+const base62Limit = 256 / len(base62) * len(base62)
+
+// randomString returns n characters drawn uniformly from base62 using crypto/rand
+func randomString(n int) string {
+	b := make([]byte, 0, n)
+	buf := make([]byte, n+n/4+1) // Room for the bytes rejected on average
+	for len(b) < n {
+		_, _ = rand.Read(buf) // Never fails: the program crashes if the OS can't provide randomness
+		for _, r := range buf {
+			if int(r) < base62Limit {
+				b = append(b, base62[int(r)%len(base62)])
+				if len(b) == n {
+					break
+				}
+			}
+		}
+	}
+	return string(b)
+}
+
+var (
+	mu               sync.RWMutex
+	defaultGenerator = NewGenerator(Config{})
+)
+
+// SetDefault replaces the package-level generator used by New
+func SetDefault(g *Generator) {
+	mu.Lock()
+	defer mu.Unlock()
+	defaultGenerator = g
+}
+
+// New returns a new ID for the entity using the package-level generator
+func New(entity Entity) string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return defaultGenerator.New(entity)
+}
+
+// Unique generates IDs until exists reports false, giving up after a few attempts.
+// Use it for IDs that must be unique but aren't protected by a primary key.
+func Unique(entity Entity, exists func(id string) bool) string {
+	id := New(entity)
+	for attempt := 0; attempt < 5 && exists(id); attempt++ {
+		id = New(entity)
+	}
+	return id
+}
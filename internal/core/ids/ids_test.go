@@ -0,0 +1,105 @@
+package ids
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerator_DefaultIsUUID(t *testing.T) {
+	g := NewGenerator(Config{})
+	id := g.New(Form)
+	if len(id) != 36 || strings.Count(id, "-") != 4 {
+		t.Errorf("expected UUID, got %q", id)
+	}
+}
+
+func TestGenerator_ShortPrefixed(t *testing.T) {
+	g := NewGenerator(ShortConfig(0))
+
+	id := g.New(FormPublic)
+	if !strings.HasPrefix(id, "form_") {
+		t.Errorf("expected form_ prefix, got %q", id)
+	}
+	if len(id) != len("form_")+DefaultShortLength {
+		t.Errorf("unexpected length for %q", id)
+	}
+
+	if id := g.New(Submission); !strings.HasPrefix(id, "sub_") {
+		t.Errorf("expected sub_ prefix, got %q", id)
+	}
+}
+
+func TestGenerator_PerEntityConfig(t *testing.T) {
+	g := NewGenerator(Config{
+		Entities: map[Entity]EntityConfig{
+			Submission: {Style: StyleShort, Prefix: "s-", Length: 6},
+		},
+	})
+
+	if id := g.New(Submission); !strings.HasPrefix(id, "s-") || len(id) != 8 {
+		t.Errorf("unexpected submission id %q", id)
+	}
+	if id := g.New(Form); len(id) != 36 {
+		t.Errorf("expected UUID for unconfigured entity, got %q", id)
+	}
+}
+
+func TestGenerator_ShortIDsAreUnique(t *testing.T) {
+	g := NewGenerator(ShortConfig(0))
+	seen := make(map[string]bool)
+	for i := 0; i < 10000; i++ {
+		id := g.New(Submission)
+		if seen[id] {
+			t.Fatalf("duplicate id %q after %d iterations", id, i)
+		}
+		seen[id] = true
+	}
+}
+
+func TestGenerator_LongShortIDs(t *testing.T) {
+	g := NewGenerator(ShortConfig(MaxShortLength))
+	id := strings.TrimPrefix(g.New(Submission), "sub_")
+	if len(id) != MaxShortLength {
+		t.Fatalf("expected %d random characters, got %q", MaxShortLength, id)
+	}
+	for _, r := range id {
+		if !strings.ContainsRune(base62, r) {
+			t.Fatalf("expected only base62 characters, got %q", id)
+		}
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	if err := ShortConfig(0).Validate(); err != nil {
+		t.Errorf("expected the default short config valid, got %v", err)
+	}
+	if err := (Config{}).Validate(); err != nil {
+		t.Errorf("expected the empty config valid, got %v", err)
+	}
+	for _, length := range []int{-1, MinShortLength - 1, MaxShortLength + 1} {
+		if err := ShortConfig(length).Validate(); err == nil {
+			t.Errorf("expected length %d rejected", length)
+		}
+	}
+	bad := []EntityConfig{
+		{Style: "snowflake"},
+		{Style: StyleShort, Prefix: "sub/"},
+		{Style: StyleShort, Prefix: strings.Repeat("x", 17)},
+	}
+	for _, cfg := range bad {
+		if err := (Config{Entities: map[Entity]EntityConfig{Submission: cfg}}).Validate(); err == nil {
+			t.Errorf("expected %+v rejected", cfg)
+		}
+	}
+}
+
+func TestUnique_RetriesOnCollision(t *testing.T) {
+	calls := 0
+	id := Unique(Form, func(candidate string) bool {
+		calls++
+		return calls < 3 // first two candidates "exist"
+	})
+	if id == "" || calls != 3 {
+		t.Errorf("expected 3 existence checks, got %d (id %q)", calls, id)
+	}
+}
@@ -0,0 +1,215 @@
+// Package passhash hashes and verifies user passwords.
+// bcrypt (the historical format) and Argon2id are supported; hashes record their
+// algorithm and parameters, so stored hashes keep verifying after the configuration
+// changes and NeedsRehash tells when one should be upgraded on the next login.
+package passhash
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Algorithm names a password hashing algorithm
+type Algorithm string
+
+const (
+	AlgorithmBcrypt   Algorithm = "bcrypt"
+	AlgorithmArgon2id Algorithm = "argon2id"
+)
+
+// Hasher creates password hashes with fixed parameters
+type Hasher interface {
+	Hash(password string) (string, error)
+	// NeedsRehash reports whether hash uses another algorithm or weaker parameters than this hasher
+	NeedsRehash(hash string) bool
+}
+
+// Bcrypt hashes with bcrypt at the given cost
+type Bcrypt struct {
+	Cost int // bcrypt.MinCost to bcrypt.MaxCost; 0 means bcrypt.DefaultCost
+}
+
+func (b Bcrypt) cost() int {
+	if b.Cost == 0 {
+		return bcrypt.DefaultCost
+	}
+	return b.Cost
+}
+
+func (b Bcrypt) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), b.cost())
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+func (b Bcrypt) NeedsRehash(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost < b.cost()
+}
+
+// Argon2id hashes with Argon2id. Zero fields use the DefaultArgon2id values.
+type Argon2id struct {
+	Memory      uint32 // KiB
+	Iterations  uint32
+	Parallelism uint8
+}
+
+// DefaultArgon2id follows the OWASP baseline (64 MiB, 3 passes, 2 lanes)
+var DefaultArgon2id = Argon2id{Memory: 64 * 1024, Iterations: 3, Parallelism: 2}
+
+const (
+	argon2SaltLength = 16
+	argon2KeyLength  = 32
+)
+
+// Limits on the Argon2id parameters accepted, in hashes and in the configuration. Hashes
+// can come from other instances (user imports), so they bound the work one login may cost.
+const (
+	Argon2MaxMemory     = 1 << 20 // KiB (1 GiB)
+	Argon2MaxIterations = 64
+	argon2MinSalt       = 8
+	argon2MaxSalt       = 64
+	argon2MinKey        = 16
+	argon2MaxKey        = 128
+)
+
+// Validate checks the parameters are within the accepted limits; zero fields take the defaults
+func (a Argon2id) Validate() error {
+	a = a.withDefaults()
+	if a.Memory > Argon2MaxMemory {
+		return fmt.Errorf("argon2 memory %d KiB is above the limit of %d", a.Memory, Argon2MaxMemory)
+	}
+	if a.Iterations > Argon2MaxIterations {
+		return fmt.Errorf("argon2 iterations %d is above the limit of %d", a.Iterations, Argon2MaxIterations)
+	}
+	// Argon2 needs 8 KiB of memory per lane
+	if a.Memory < 8*uint32(a.Parallelism) {
+		return fmt.Errorf("argon2 memory %d KiB is below 8 KiB per lane", a.Memory)
+	}
+	return nil
+}
+
+func (a Argon2id) withDefaults() Argon2id {
+	if a.Memory == 0 {
+		a.Memory = DefaultArgon2id.Memory
+	}
+	if a.Iterations == 0 {
+		a.Iterations = DefaultArgon2id.Iterations
+	}
+	if a.Parallelism == 0 {
+		a.Parallelism = DefaultArgon2id.Parallelism
+	}
+	return a
+}
+
+// Hash returns the hash in PHC format: $argon2id$v=19$m=65536,t=3,p=2$<salt>$<key>
+func (a Argon2id) Hash(password string) (string, error) {
+	a = a.withDefaults()
+	salt := make([]byte, argon2SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("generate salt: %w", err)
+	}
+	key := argon2.IDKey([]byte(password), salt, a.Iterations, a.Memory, a.Parallelism, argon2KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, a.Memory, a.Iterations, a.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+func (a Argon2id) NeedsRehash(hash string) bool {
+	a = a.withDefaults()
+	stored, _, _, err := parseArgon2id(hash)
+	return err != nil || stored.Memory < a.Memory || stored.Iterations < a.Iterations || stored.Parallelism < a.Parallelism
+}
+
+// parseArgon2id splits a PHC-format Argon2id hash into its parameters, salt and key
+func parseArgon2id(hash string) (params Argon2id, salt, key []byte, err error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != string(AlgorithmArgon2id) {
+		return params, nil, nil, fmt.Errorf("not an argon2id hash")
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, fmt.Errorf("unsupported argon2 version %q", parts[2])
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return params, nil, nil, fmt.Errorf("invalid argon2 parameters: %w", err)
+	}
+	// Zero parameters are invalid in a hash rather than defaults
+	if params.Memory == 0 || params.Iterations == 0 || params.Parallelism == 0 {
+		return params, nil, nil, fmt.Errorf("invalid argon2 parameters %q", parts[3])
+	}
+	if err := params.Validate(); err != nil {
+		return params, nil, nil, err
+	}
+	if salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return params, nil, nil, fmt.Errorf("invalid argon2 salt: %w", err)
+	}
+	if len(salt) < argon2MinSalt || len(salt) > argon2MaxSalt {
+		return params, nil, nil, fmt.Errorf("argon2 salt of %d bytes (want %d-%d)", len(salt), argon2MinSalt, argon2MaxSalt)
+	}
+	if key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil {
+		return params, nil, nil, fmt.Errorf("invalid argon2 key: %w", err)
+	}
+	if len(key) < argon2MinKey || len(key) > argon2MaxKey {
+		return params, nil, nil, fmt.Errorf("argon2 key of %d bytes (want %d-%d)", len(key), argon2MinKey, argon2MaxKey)
+	}
+	return params, salt, key, nil
+}
+
+// Verify reports whether password matches hash, whichever supported algorithm produced it
+func Verify(hash, password string) bool {
+	if strings.HasPrefix(hash, "$argon2id$") {
+		params, salt, key, err := parseArgon2id(hash)
+		if err != nil {
+			return false
+		}
+		derived := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, uint32(len(key)))
+		return subtle.ConstantTimeCompare(derived, key) == 1
+	}
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// Recognized reports whether hash was produced by one of the supported algorithms with
+// parameters within the accepted limits, e.g. before storing a hash imported from another instance
+func Recognized(hash string) bool {
+	if strings.HasPrefix(hash, "$argon2id$") {
+		_, _, _, err := parseArgon2id(hash)
+		return err == nil
+	}
+	_, err := bcrypt.Cost([]byte(hash))
+	return err == nil
+}
+
+var (
+	mu            sync.RWMutex
+	defaultHasher Hasher = Bcrypt{}
+)
+
+// SetDefault replaces the package-level hasher used by Hash and NeedsRehash
+func SetDefault(h Hasher) {
+	mu.Lock()
+	defer mu.Unlock()
+	defaultHasher = h
+}
+
+// Hash hashes password with the package-level hasher
+func Hash(password string) (string, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+	return defaultHasher.Hash(password)
+}
+
+// NeedsRehash reports whether hash should be replaced by one from the package-level hasher
+func NeedsRehash(hash string) bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return defaultHasher.NeedsRehash(hash)
+}
@@ -0,0 +1,120 @@
+package passhash
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// testArgon2id keeps tests fast; production uses DefaultArgon2id
+var testArgon2id = Argon2id{Memory: 1024, Iterations: 1, Parallelism: 1}
+
+func TestBcrypt_HashVerifyAndRehash(t *testing.T) {
+	h := Bcrypt{Cost: bcrypt.MinCost}
+	hash, err := h.Hash("correct horse")
+	if err != nil {
+		t.Fatalf("hash: %v", err)
+	}
+	if !Verify(hash, "correct horse") || Verify(hash, "wrong horse") {
+		t.Error("bcrypt hash did not verify correctly")
+	}
+
+	if h.NeedsRehash(hash) {
+		t.Error("hash with the configured cost should not need a rehash")
+	}
+	if !(Bcrypt{Cost: bcrypt.MinCost + 1}).NeedsRehash(hash) {
+		t.Error("hash with a lower cost should need a rehash")
+	}
+	if !h.NeedsRehash("$argon2id$v=19$m=1024,t=1,p=1$c2FsdA$a2V5") {
+		t.Error("argon2id hash should need a rehash when bcrypt is configured")
+	}
+}
+
+func TestArgon2id_HashVerifyAndRehash(t *testing.T) {
+	hash, err := testArgon2id.Hash("correct horse")
+	if err != nil {
+		t.Fatalf("hash: %v", err)
+	}
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$") {
+		t.Errorf("unexpected hash format %q", hash)
+	}
+	if !Verify(hash, "correct horse") || Verify(hash, "wrong horse") {
+		t.Error("argon2id hash did not verify correctly")
+	}
+
+	if testArgon2id.NeedsRehash(hash) {
+		t.Error("hash with the configured parameters should not need a rehash")
+	}
+	stronger := testArgon2id
+	stronger.Iterations = 2
+	if !stronger.NeedsRehash(hash) {
+		t.Error("hash with fewer iterations should need a rehash")
+	}
+
+	bcryptHash, _ := Bcrypt{Cost: bcrypt.MinCost}.Hash("correct horse")
+	if !testArgon2id.NeedsRehash(bcryptHash) {
+		t.Error("bcrypt hash should need a rehash when argon2id is configured")
+	}
+}
+
+func TestVerify_RejectsMalformedHashes(t *testing.T) {
+	for _, hash := range []string{"", "plain", "$argon2id$v=19$m=1024$x$y", "$argon2id$v=18$m=1024,t=1,p=1$c2FsdA$a2V5"} {
+		if Verify(hash, "plain") {
+			t.Errorf("expected %q to be rejected", hash)
+		}
+		if Recognized(hash) {
+			t.Errorf("expected %q not to be recognized", hash)
+		}
+	}
+
+	bcryptHash, _ := Bcrypt{Cost: bcrypt.MinCost}.Hash("correct horse")
+	argonHash, _ := testArgon2id.Hash("correct horse")
+	if !Recognized(bcryptHash) || !Recognized(argonHash) {
+		t.Error("expected bcrypt and argon2id hashes to be recognized")
+	}
+}
+
+func TestRecognized_Argon2idLimits(t *testing.T) {
+	salt := base64.RawStdEncoding.EncodeToString([]byte("saltsalt"))
+	key := base64.RawStdEncoding.EncodeToString(make([]byte, 32))
+	tests := []struct {
+		name string
+		hash string
+		want bool
+	}{
+		{"valid", "$argon2id$v=19$m=1024,t=1,p=1$" + salt + "$" + key, true},
+		{"empty key", "$argon2id$v=19$m=8,t=1,p=1$c2FsdHNhbHQ$", false},
+		{"short key", "$argon2id$v=19$m=1024,t=1,p=1$" + salt + "$" + base64.RawStdEncoding.EncodeToString(make([]byte, 8)), false},
+		{"short salt", "$argon2id$v=19$m=1024,t=1,p=1$c2FsdA$" + key, false},
+		{"zero parallelism", "$argon2id$v=19$m=1024,t=1,p=0$" + salt + "$" + key, false},
+		{"zero iterations", "$argon2id$v=19$m=1024,t=0,p=1$" + salt + "$" + key, false},
+		{"zero memory", "$argon2id$v=19$m=0,t=1,p=1$" + salt + "$" + key, false},
+		{"memory below the lanes", "$argon2id$v=19$m=8,t=1,p=4$" + salt + "$" + key, false},
+		{"huge memory", "$argon2id$v=19$m=4294967295,t=1,p=1$" + salt + "$" + key, false},
+		{"huge iterations", "$argon2id$v=19$m=1024,t=100000,p=1$" + salt + "$" + key, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Recognized(tt.hash); got != tt.want {
+				t.Errorf("Recognized(%q) = %v, want %v", tt.hash, got, tt.want)
+			}
+			if !tt.want && Verify(tt.hash, "plain") {
+				t.Errorf("expected %q to be rejected", tt.hash)
+			}
+		})
+	}
+}
+
+func TestArgon2id_Validate(t *testing.T) {
+	if err := (Argon2id{}).Validate(); err != nil {
+		t.Errorf("expected the defaults valid, got %v", err)
+	}
+	if err := (Argon2id{Memory: Argon2MaxMemory + 1}).Validate(); err == nil {
+		t.Error("expected memory above the limit rejected")
+	}
+	if err := (Argon2id{Iterations: Argon2MaxIterations + 1}).Validate(); err == nil {
+		t.Error("expected iterations above the limit rejected")
+	}
+}
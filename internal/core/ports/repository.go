@@ -2,6 +2,10 @@ package ports
 
 import (
 	"context"
+	"encoding/json"
+	"io"
+	"time"
+
 	"headless_form/internal/core/domain"
 )
 
@@ -18,32 +22,116 @@ type Repository interface {
 	User() UserRepository
 	PasswordReset() PasswordResetRepository
 	Settings() SettingsRepository
+	Alert() AlertRepository
+	FormToken() FormTokenRepository
+	Audit() AuditRepository
+	Task() TaskRepository
+	Comment() CommentRepository
+	Notification() NotificationRepository
+	Lock() LockRepository
+	WebhookDelivery() WebhookDeliveryRepository
+	EmailQueue() EmailQueueRepository
+	SpamFingerprint() SpamFingerprintRepository
+	Team() TeamRepository
 }
 
 type FormRepository interface {
 	Create(ctx context.Context, form *domain.Form) error
 	Update(ctx context.Context, form *domain.Form) error
 	GetByPublicID(ctx context.Context, publicID string) (*domain.Form, error)
+	// GetBySlug returns the form with the slug, including deleted ones, or nil
+	GetBySlug(ctx context.Context, slug string) (*domain.Form, error)
+	// GetByPreviousPublicID returns the form whose rotated-out public ID is publicID, or nil
+	GetByPreviousPublicID(ctx context.Context, publicID string) (*domain.Form, error)
 	GetByID(ctx context.Context, id string) (*domain.Form, error)
 	List(ctx context.Context) ([]*domain.Form, error)
-	ListPaginated(ctx context.Context, limit, offset int) ([]*domain.Form, int, error)
-	ListByOwnerPaginated(ctx context.Context, ownerID string, limit, offset int) ([]*domain.Form, int, error)
+	// ListPaginated returns one page of the forms matching query, and how many match in total
+	ListPaginated(ctx context.Context, query domain.FormListQuery, limit, offset int) ([]*domain.Form, int, error)
 	Delete(ctx context.Context, id string) error
 	IncrementSubmissionCount(ctx context.Context, formID string) error
+	ListPurgeable(ctx context.Context, before time.Time) ([]*domain.Form, error)
+	ListDeleted(ctx context.Context, ownerID string) ([]*domain.Form, error)
+	// Star pins a form for a user; starring an already starred form is a no-op
+	Star(ctx context.Context, userID, formID string) error
+	Unstar(ctx context.Context, userID, formID string) error
+	// ListStarredBy returns the IDs of the users who starred the form
+	ListStarredBy(ctx context.Context, formID string) ([]string, error)
+	// SetOwner hands the form to another user
+	SetOwner(ctx context.Context, formID, ownerID string) error
+	// SetTeam shares the form with a team, or with none when teamID is empty
+	SetTeam(ctx context.Context, formID, teamID string) error
+	// ListWithRetention returns the forms outside the trash that have a retention period
+	ListWithRetention(ctx context.Context) ([]*domain.Form, error)
 }
 
 type SubmissionRepository interface {
+	// Create wraps errors retrying may get past, such as a locked database, with domain.ErrStoreUnavailable
 	Create(ctx context.Context, submission *domain.Submission) error
 	GetByID(ctx context.Context, id string) (*domain.Submission, error)
 	GetByFormID(ctx context.Context, formID string) ([]*domain.Submission, error)
-	GetByFormIDPaginated(ctx context.Context, formID string, limit, offset int) ([]*domain.Submission, int, error)
+	// EachByFormID calls fn with each of a form's submissions, newest first, reading them in
+	// chunks instead of all at once. It stops at fn's first error and returns it.
+	EachByFormID(ctx context.Context, formID string, fn func(*domain.Submission) error) error
+	// GetByFormIDPaginated returns one page of submissions plus status counts for all of them.
+	// Opened and the Unopened count are relative to viewerID.
+	GetByFormIDPaginated(ctx context.Context, formID, viewerID string, limit, offset int) ([]*domain.Submission, domain.SubmissionCounts, error)
+	// SearchByFormID is GetByFormIDPaginated for the submissions matching query, with the counts
+	// covering only those
+	SearchByFormID(ctx context.Context, formID, viewerID string, query domain.SubmissionListQuery, limit, offset int) ([]*domain.Submission, domain.SubmissionCounts, error)
 	UpdateStatus(ctx context.Context, id string, status domain.SubmissionStatus) error
+	// UpdateData replaces a submission's submitted data
+	UpdateData(ctx context.Context, id string, data json.RawMessage) error
 	Delete(ctx context.Context, id string) error
+	// DeleteMatching deletes a form's submissions matching filter in one statement and returns how many were removed
+	DeleteMatching(ctx context.Context, formID string, filter domain.SubmissionFilter) (int, error)
+	// UpdateStatusBatch sets the status of the listed submissions of a form in one statement
+	// and returns how many were updated; IDs of other forms are ignored
+	UpdateStatusBatch(ctx context.Context, formID string, ids []string, status domain.SubmissionStatus) (int, error)
+	// SetSpamBatch manually marks the listed submissions of a form as spam, quarantining them,
+	// or as not spam, releasing quarantined ones to the inbox as unread
+	SetSpamBatch(ctx context.Context, formID string, ids []string, spam bool) (int, error)
+	// SetSpamVerdict replaces a submission's spam verdict, quarantining it when spam is
+	// detected or releasing it to the inbox as unread when it no longer is
+	SetSpamVerdict(ctx context.Context, id string, spam domain.SpamMeta) error
+	// DeleteBatch deletes the listed submissions of a form in one statement
+	DeleteBatch(ctx context.Context, formID string, ids []string) (int, error)
+	// DeleteTestBefore removes test submissions (all forms) created before the given time
+	// and returns their tombstones
+	DeleteTestBefore(ctx context.Context, before time.Time) ([]domain.Tombstone, error)
+	// ListExpired returns up to limit of a form's submissions created before the given time
+	// that weren't anonymized yet, oldest first
+	ListExpired(ctx context.Context, formID string, before time.Time, limit int) ([]*domain.Submission, error)
+	// Anonymize replaces a submission's data and metadata with their anonymized versions
+	Anonymize(ctx context.Context, id string, data json.RawMessage, meta domain.SubmissionMeta) error
+	// RecordView notes that userID opened the submission at the given time
+	RecordView(ctx context.Context, submissionID, userID string, at time.Time) error
+	// ListViews returns who opened the submission, most recent first
+	ListViews(ctx context.Context, submissionID string) ([]domain.SubmissionView, error)
+	// Link records that a submission refers to another; linking them again keeps the first link
+	Link(ctx context.Context, link *domain.SubmissionLink) error
+	// Unlink removes the link from submissionID to linkedID, reporting whether there was one
+	Unlink(ctx context.Context, submissionID, linkedID string) (bool, error)
+	// ListLinks returns the links from and to the submission, oldest first
+	ListLinks(ctx context.Context, submissionID string) ([]domain.SubmissionLink, error)
 }
 
+type CommentRepository interface {
+	Create(ctx context.Context, comment *domain.Comment) error
+	Update(ctx context.Context, comment *domain.Comment) error
+	GetByID(ctx context.Context, id string) (*domain.Comment, error)
+	// ListBySubmissionID returns a submission's comments, oldest first
+	ListBySubmissionID(ctx context.Context, submissionID string) ([]*domain.Comment, error)
+	// Delete removes a comment and its replies
+	Delete(ctx context.Context, id string) error
+}
+
+// StatsRepository reports submission statistics. Days, "today" and "this week"
+// are calendar days in loc.
 type StatsRepository interface {
-	GetDashboardStats(ctx context.Context) (*domain.DashboardStats, error)
-	GetFormStats(ctx context.Context, formID string) (*domain.FormStats, error)
+	GetDashboardStats(ctx context.Context, loc *time.Location) (*domain.DashboardStats, error)
+	GetFormStats(ctx context.Context, formID string, loc *time.Location) (*domain.FormStats, error)
+	GetFormDailyStats(ctx context.Context, formID string, from, to time.Time, loc *time.Location) ([]domain.DailyFormStats, error)
+	GetFormActivity(ctx context.Context, formID string, since time.Time) (*domain.FormActivity, error)
 }
 
 type UserRepository interface {
@@ -67,3 +155,167 @@ type SettingsRepository interface {
 	Get(ctx context.Context) (*domain.SiteSettings, error)
 	Save(ctx context.Context, settings *domain.SiteSettings) error
 }
+
+type AlertRepository interface {
+	Create(ctx context.Context, rule *domain.AlertRule) error
+	GetByID(ctx context.Context, id string) (*domain.AlertRule, error)
+	ListByFormID(ctx context.Context, formID string) ([]*domain.AlertRule, error)
+	ListEnabled(ctx context.Context) ([]*domain.AlertRule, error)
+	MarkTriggered(ctx context.Context, id string, at time.Time) error
+	Delete(ctx context.Context, id string) error
+}
+
+type FormTokenRepository interface {
+	Create(ctx context.Context, token *domain.FormToken) error
+	GetByHash(ctx context.Context, hash string) (*domain.FormToken, error)
+	ListByFormID(ctx context.Context, formID string) ([]*domain.FormToken, error)
+	MarkUsed(ctx context.Context, id string, at time.Time) error
+	Delete(ctx context.Context, id string) error
+}
+
+type AuditRepository interface {
+	Create(ctx context.Context, entry *domain.AuditEntry) error
+	ListByFormID(ctx context.Context, formID string, limit int) ([]*domain.AuditEntry, error)
+}
+
+type TaskRepository interface {
+	Create(ctx context.Context, task *domain.Task) error
+	Update(ctx context.Context, task *domain.Task) error
+	GetByID(ctx context.Context, id string) (*domain.Task, error)
+	// FailUnfinished marks tasks left pending or running (e.g. by a restart) as failed
+	FailUnfinished(ctx context.Context, reason string, at time.Time) (int, error)
+	// ListWithFiles returns the tasks whose file hasn't been removed yet
+	ListWithFiles(ctx context.Context) ([]*domain.Task, error)
+}
+
+type NotificationRepository interface {
+	Create(ctx context.Context, notification *domain.Notification) error
+	// ListByUserID returns a user's notifications, newest first
+	ListByUserID(ctx context.Context, userID string, unreadOnly bool, limit, offset int) ([]*domain.Notification, error)
+	// Count returns how many notifications the user has, read or not
+	Count(ctx context.Context, userID string) (int, error)
+	CountUnread(ctx context.Context, userID string) (int, error)
+	// HasUnread reports whether the user has an unread notification of this type about the form
+	HasUnread(ctx context.Context, userID string, notificationType domain.NotificationType, formID string) (bool, error)
+	// MarkRead marks one of the user's notifications read; it returns false if the user has no such notification
+	MarkRead(ctx context.Context, userID, id string, at time.Time) (bool, error)
+	MarkAllRead(ctx context.Context, userID string, at time.Time) (int, error)
+}
+
+// LockRepository holds named, expiring locks shared by every instance using the database
+type LockRepository interface {
+	// Acquire takes the lock for owner until the given time, or extends it if owner holds it.
+	// It returns false while another owner holds an unexpired lock.
+	Acquire(ctx context.Context, name, owner string, until time.Time) (bool, error)
+	// Release gives up the lock if owner holds it
+	Release(ctx context.Context, name, owner string) error
+}
+
+// WebhookDeliveryRepository stores the webhook delivery log
+type WebhookDeliveryRepository interface {
+	Create(ctx context.Context, delivery *domain.WebhookDelivery) error
+	// Update saves the delivery's status, attempts and error
+	Update(ctx context.Context, delivery *domain.WebhookDelivery) error
+	GetByID(ctx context.Context, id string) (*domain.WebhookDelivery, error)
+	// ListByFormID returns one page of a form's deliveries, newest first, and how many it has in total
+	ListByFormID(ctx context.Context, formID string, limit, offset int) ([]*domain.WebhookDelivery, int, error)
+	// DeleteBefore removes deliveries created before the given time
+	DeleteBefore(ctx context.Context, before time.Time) (int, error)
+}
+
+// EmailQueueRepository stores outgoing emails until they're sent, and the outcome after
+type EmailQueueRepository interface {
+	Create(ctx context.Context, email *domain.QueuedEmail) error
+	// Update saves the email's status, attempts, error and next attempt time
+	Update(ctx context.Context, email *domain.QueuedEmail) error
+	// ListDue returns pending emails whose next attempt is due at now, oldest first
+	ListDue(ctx context.Context, now time.Time, limit int) ([]*domain.QueuedEmail, error)
+	// List returns one page of emails, newest first, with the given status or any when
+	// it's empty, and how many match in total
+	List(ctx context.Context, status domain.EmailStatus, limit, offset int) ([]*domain.QueuedEmail, int, error)
+	// CountByStatus returns how many emails have each status
+	CountByStatus(ctx context.Context) (map[domain.EmailStatus]int, error)
+	// DeleteFinishedBefore removes sent and dead emails last updated before the given time
+	DeleteFinishedBefore(ctx context.Context, before time.Time) (int, error)
+}
+
+// SpamFingerprintRepository tracks the content of confirmed spam
+type SpamFingerprintRepository interface {
+	// Confirm adds the fingerprint, or counts another confirmation of a tracked one and
+	// moves its last seen and expiry times to the given ones
+	Confirm(ctx context.Context, fp *domain.SpamFingerprint) error
+	// Get returns the fingerprint with the hash, nil when it isn't tracked
+	Get(ctx context.Context, hash string) (*domain.SpamFingerprint, error)
+	// Hit counts a submission quarantined because of the fingerprint
+	Hit(ctx context.Context, hash string, seenAt, expiresAt time.Time) error
+	// Delete stops tracking the fingerprint, reporting whether it was tracked
+	Delete(ctx context.Context, hash string) (bool, error)
+	// List returns one page of fingerprints, most hits first, and how many there are
+	List(ctx context.Context, limit, offset int) ([]*domain.SpamFingerprint, int, error)
+	// DeleteExpired removes fingerprints that expired before the given time
+	DeleteExpired(ctx context.Context, before time.Time) (int, error)
+}
+
+// TeamRepository stores teams and their members
+type TeamRepository interface {
+	Create(ctx context.Context, team *domain.Team) error
+	// Update saves the team's name
+	Update(ctx context.Context, team *domain.Team) error
+	// GetByID returns the team with its members, or nil
+	GetByID(ctx context.Context, id string) (*domain.Team, error)
+	// List returns the teams userID belongs to, with Role set, or every team when userID is empty
+	List(ctx context.Context, userID string) ([]*domain.Team, error)
+	// Delete removes the team and its memberships, and unshares its forms
+	Delete(ctx context.Context, id string) error
+	// SetMember adds the member, or changes the role of an existing one
+	SetMember(ctx context.Context, member *domain.TeamMember) error
+	// RemoveMember reports whether the user was a member
+	RemoveMember(ctx context.Context, teamID, userID string) (bool, error)
+	// Roles returns the user's role in each of their teams, by team ID
+	Roles(ctx context.Context, userID string) (map[string]domain.TeamRole, error)
+}
+
+// SubmissionQueue holds submissions that couldn't be stored while the database was
+// unavailable, outside the database, until they're replayed
+type SubmissionQueue interface {
+	Put(submission *domain.Submission) error
+	// List returns the queued submissions, oldest first
+	List() ([]*domain.Submission, error)
+	Remove(id string) error
+	// DeadLetter takes a submission that can't ever be stored out of the queue, keeping it aside
+	DeadLetter(id string) error
+	Len() int
+}
+
+// FileStorage keeps files uploaded with submissions (see domain.FileKey)
+type FileStorage interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Open returns domain.ErrFileNotFound for a key that isn't stored
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the file; deleting a missing key is a no-op
+	Delete(ctx context.Context, key string) error
+}
+
+// SecretCodec protects secret columns (SMTP password, spam provider key, webhook secrets,
+// submission keys, ...) at rest. Seal prepares a secret for storage, Open returns the
+// secret for a stored value, fetching it from a secret manager when the value references one.
+type SecretCodec interface {
+	Seal(secret string) (string, error)
+	Open(ctx context.Context, stored string) (string, error)
+	// Unseal decrypts a sealed value without resolving references, which are returned as
+	// they are along with plaintext, for secrets resolved only when they're used
+	Unseal(stored string) (string, error)
+	// Current reports whether a stored value is as Seal would store it now, so a
+	// migration needn't seal it again
+	Current(stored string) bool
+}
+
+// EventBus carries real-time events (task progress, ...) between replicas, so a
+// dashboard connected to one sees events generated on another
+type EventBus interface {
+	Publish(ctx context.Context, topic string, payload []byte) error
+	// Subscribe receives the topic's events, including this replica's, until
+	// unsubscribe is called. Events may be dropped for slow subscribers or while
+	// the transport is disconnected.
+	Subscribe(topic string) (events <-chan []byte, unsubscribe func())
+}
@@ -0,0 +1,219 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"headless_form/internal/core/domain"
+	"headless_form/internal/core/ids"
+	"headless_form/internal/core/ports"
+)
+
+// AlertService manages per-form alert rules and evaluates them periodically
+type AlertService struct {
+	repo         ports.Repository
+	timeouts     Timeouts
+	webhookStats func(formPublicID string, since time.Time) (total, failed int)
+	onAlert      func(form *domain.Form, rule *domain.AlertRule, message string)
+}
+
+// NewAlertService creates a new alert service
+func NewAlertService(repo ports.Repository) *AlertService {
+	return &AlertService{repo: repo, timeouts: DefaultTimeouts}
+}
+
+// SetTimeouts overrides the per-operation timeouts (see DefaultTimeouts)
+func (s *AlertService) SetTimeouts(t Timeouts) {
+	s.timeouts = t
+}
+
+// SetWebhookStatsProvider sets the source of webhook delivery outcomes (for webhook_failure_rate rules)
+func (s *AlertService) SetWebhookStatsProvider(fn func(formPublicID string, since time.Time) (total, failed int)) {
+	s.webhookStats = fn
+}
+
+// SetAlertCallback sets the callback used to deliver triggered alerts
+func (s *AlertService) SetAlertCallback(fn func(form *domain.Form, rule *domain.AlertRule, message string)) {
+	s.onAlert = fn
+}
+
+// CreateRule adds an alert rule to a form
+func (s *AlertService) CreateRule(ctx context.Context, publicID string, alertType domain.AlertType, threshold float64, windowHours int) (*domain.AlertRule, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
+	form, err := s.repo.Form().GetByPublicID(ctx, publicID)
+	if err != nil {
+		return nil, fmt.Errorf("get form: %w", err)
+	}
+	if form == nil {
+		return nil, domain.ErrFormNotFound
+	}
+
+	rule := &domain.AlertRule{
+		ID:          ids.New(ids.Alert),
+		FormID:      form.ID,
+		Type:        alertType,
+		Threshold:   threshold,
+		WindowHours: windowHours,
+		Enabled:     true,
+		CreatedAt:   time.Now(),
+	}
+	if err := rule.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Alert().Create(ctx, rule); err != nil {
+		return nil, fmt.Errorf("create alert rule: %w", err)
+	}
+	return rule, nil
+}
+
+// ListRules returns all alert rules for a form
+func (s *AlertService) ListRules(ctx context.Context, publicID string) ([]*domain.AlertRule, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpList)
+	defer cancel()
+	form, err := s.repo.Form().GetByPublicID(ctx, publicID)
+	if err != nil {
+		return nil, fmt.Errorf("get form: %w", err)
+	}
+	if form == nil {
+		return nil, domain.ErrFormNotFound
+	}
+	return s.repo.Alert().ListByFormID(ctx, form.ID)
+}
+
+// DeleteRule removes an alert rule, verifying it belongs to the given form
+func (s *AlertService) DeleteRule(ctx context.Context, publicID, ruleID string) error {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
+	form, err := s.repo.Form().GetByPublicID(ctx, publicID)
+	if err != nil {
+		return fmt.Errorf("get form: %w", err)
+	}
+	if form == nil {
+		return domain.ErrFormNotFound
+	}
+
+	rule, err := s.repo.Alert().GetByID(ctx, ruleID)
+	if err != nil {
+		return fmt.Errorf("get alert rule: %w", err)
+	}
+	if rule == nil || rule.FormID != form.ID {
+		return domain.ErrAlertNotFound
+	}
+	return s.repo.Alert().Delete(ctx, ruleID)
+}
+
+// Start runs Evaluate every interval until ctx is cancelled,
+// skipping runs while paused reports true (nil never pauses)
+func (s *AlertService) Start(ctx context.Context, interval time.Duration, paused func() bool) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if paused != nil && paused() {
+					continue
+				}
+				if _, err := s.Evaluate(ctx); err != nil {
+					log.Printf("[ALERT] Evaluation failed: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// Evaluate checks every enabled rule and fires the alert callback for those that trip.
+// A rule fires at most once per window. Returns the number of alerts triggered.
+func (s *AlertService) Evaluate(ctx context.Context) (int, error) {
+	rules, err := s.repo.Alert().ListEnabled(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("list alert rules: %w", err)
+	}
+
+	now := time.Now()
+	triggered := 0
+	for _, rule := range rules {
+		if rule.LastTriggeredAt != nil && now.Sub(*rule.LastTriggeredAt) < rule.Window() {
+			continue
+		}
+
+		form, err := s.repo.Form().GetByID(ctx, rule.FormID)
+		if err != nil || form == nil || form.Status != domain.FormStatusActive {
+			continue
+		}
+
+		message, fire, err := s.check(ctx, form, rule, now)
+		if err != nil {
+			log.Printf("[ALERT] Failed to check rule %s: %v", rule.ID, err)
+			continue
+		}
+		if !fire {
+			continue
+		}
+
+		if err := s.repo.Alert().MarkTriggered(ctx, rule.ID, now); err != nil {
+			log.Printf("[ALERT] Failed to mark rule %s as triggered: %v", rule.ID, err)
+		}
+		triggered++
+		if s.onAlert != nil {
+			s.onAlert(form, rule, message)
+		}
+	}
+	return triggered, nil
+}
+
+// check evaluates a single rule and returns a human-readable message if it trips
+func (s *AlertService) check(ctx context.Context, form *domain.Form, rule *domain.AlertRule, now time.Time) (string, bool, error) {
+	since := now.Add(-rule.Window())
+
+	switch rule.Type {
+	case domain.AlertNoSubmissions:
+		// Don't alert on forms younger than the window
+		if form.CreatedAt.After(since) {
+			return "", false, nil
+		}
+		activity, err := s.repo.Stats().GetFormActivity(ctx, form.ID, since)
+		if err != nil {
+			return "", false, err
+		}
+		if activity.Submissions == 0 {
+			return fmt.Sprintf("No submissions received in the last %d hours", rule.WindowHours), true, nil
+		}
+
+	case domain.AlertSpamRate:
+		activity, err := s.repo.Stats().GetFormActivity(ctx, form.ID, since)
+		if err != nil {
+			return "", false, err
+		}
+		if activity.Submissions == 0 {
+			return "", false, nil
+		}
+		rate := float64(activity.Spam) * 100 / float64(activity.Submissions)
+		if rate > rule.Threshold {
+			return fmt.Sprintf("Spam rate is %.1f%% (%d of %d submissions) in the last %d hours, above %.1f%%",
+				rate, activity.Spam, activity.Submissions, rule.WindowHours, rule.Threshold), true, nil
+		}
+
+	case domain.AlertWebhookFailureRate:
+		if s.webhookStats == nil {
+			return "", false, nil
+		}
+		total, failed := s.webhookStats(form.PublicID, since)
+		if total == 0 {
+			return "", false, nil
+		}
+		rate := float64(failed) * 100 / float64(total)
+		if rate > rule.Threshold {
+			return fmt.Sprintf("Webhook failure rate is %.1f%% (%d of %d deliveries) in the last %d hours, above %.1f%%",
+				rate, failed, total, rule.WindowHours, rule.Threshold), true, nil
+		}
+	}
+
+	return "", false, nil
+}
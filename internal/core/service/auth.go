@@ -5,13 +5,15 @@ import (
 	"crypto/rand"
 	"encoding/base64"
 	"errors"
+	"log"
+	"strings"
 	"time"
 
 	"headless_form/internal/core/domain"
+	"headless_form/internal/core/ids"
 	"headless_form/internal/core/ports"
 
 	"github.com/golang-jwt/jwt/v5"
-	"github.com/google/uuid"
 )
 
 var (
@@ -22,12 +24,17 @@ var (
 type AuthConfig struct {
 	JWTSecret     string
 	TokenDuration time.Duration
+
+	// ForcePasswordLogin keeps password login working even when the site settings turn
+	// it off, for admins locked out by a broken sign-in provider
+	ForcePasswordLogin bool
 }
 
 // AuthService handles authentication operations
 type AuthService struct {
-	repo   ports.Repository
-	config AuthConfig
+	repo     ports.Repository
+	config   AuthConfig
+	timeouts Timeouts
 }
 
 // NewAuthService creates a new auth service
@@ -35,7 +42,12 @@ func NewAuthService(repo ports.Repository, config AuthConfig) *AuthService {
 	if config.TokenDuration == 0 {
 		config.TokenDuration = 24 * time.Hour // Default 24 hours
 	}
-	return &AuthService{repo: repo, config: config}
+	return &AuthService{repo: repo, config: config, timeouts: DefaultTimeouts}
+}
+
+// SetTimeouts overrides the per-operation timeouts (see DefaultTimeouts)
+func (s *AuthService) SetTimeouts(t Timeouts) {
+	s.timeouts = t
 }
 
 // Claims represents JWT claims
@@ -48,6 +60,9 @@ type Claims struct {
 
 // Register creates a new user account
 func (s *AuthService) Register(ctx context.Context, email, password, name string) (*domain.User, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
+
 	// Check if user already exists
 	existing, _ := s.repo.User().GetByEmail(ctx, email)
 	if existing != nil {
@@ -62,7 +77,7 @@ func (s *AuthService) Register(ctx context.Context, email, password, name string
 	}
 
 	user := &domain.User{
-		ID:        uuid.New().String(),
+		ID:        ids.New(ids.User),
 		Email:     email,
 		Name:      name,
 		Role:      role,
@@ -87,6 +102,8 @@ func (s *AuthService) Register(ctx context.Context, email, password, name string
 
 // Login authenticates a user and returns a JWT token
 func (s *AuthService) Login(ctx context.Context, email, password string) (string, *domain.User, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpLookup)
+	defer cancel()
 	user, err := s.repo.User().GetByEmail(ctx, email)
 	if err != nil {
 		return "", nil, domain.ErrInvalidCredentials
@@ -96,6 +113,15 @@ func (s *AuthService) Login(ctx context.Context, email, password string) (string
 		return "", nil, domain.ErrInvalidCredentials
 	}
 
+	// Upgrade the stored hash to the current algorithm and parameters while we have the password
+	if user.PasswordNeedsRehash() {
+		if err := user.SetPassword(password); err != nil {
+			log.Printf("[AUTH] Failed to rehash password for user %s: %v", user.ID, err)
+		} else if err := s.repo.User().Update(ctx, user); err != nil {
+			log.Printf("[AUTH] Failed to save rehashed password for user %s: %v", user.ID, err)
+		}
+	}
+
 	token, err := s.generateToken(user)
 	if err != nil {
 		return "", nil, err
@@ -124,9 +150,18 @@ func (s *AuthService) ValidateToken(tokenString string) (*Claims, error) {
 
 // GetUserByID retrieves a user by ID
 func (s *AuthService) GetUserByID(ctx context.Context, id string) (*domain.User, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpLookup)
+	defer cancel()
 	return s.repo.User().GetByID(ctx, id)
 }
 
+// TeamRoles returns the user's role in each of their teams, by team ID, for form access checks
+func (s *AuthService) TeamRoles(ctx context.Context, userID string) (map[string]domain.TeamRole, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpLookup)
+	defer cancel()
+	return s.repo.Team().Roles(ctx, userID)
+}
+
 // generateToken creates a new JWT token for a user
 func (s *AuthService) generateToken(user *domain.User) (string, error) {
 	claims := &Claims{
@@ -146,6 +181,8 @@ func (s *AuthService) generateToken(user *domain.User) (string, error) {
 
 // HasUsers returns true if there are any users in the system
 func (s *AuthService) HasUsers(ctx context.Context) (bool, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpLookup)
+	defer cancel()
 	count, err := s.repo.User().Count(ctx)
 	if err != nil {
 		return false, err
@@ -153,13 +190,90 @@ func (s *AuthService) HasUsers(ctx context.Context) (bool, error) {
 	return count > 0, nil
 }
 
+// OAuthSettings returns the site's sign-in providers, nil when there are none
+func (s *AuthService) OAuthSettings(ctx context.Context) (*domain.OAuthSettings, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpLookup)
+	defer cancel()
+	settings, err := s.repo.Settings().Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return settings.OAuth, nil
+}
+
+// PasswordLoginEnabled reports whether users may sign in, reset or register with a password
+func (s *AuthService) PasswordLoginEnabled(ctx context.Context) (bool, error) {
+	if s.config.ForcePasswordLogin {
+		return true, nil
+	}
+	oauth, err := s.OAuthSettings(ctx)
+	if err != nil {
+		return false, err
+	}
+	return oauth.PasswordLogin(), nil
+}
+
+// LoginWithProvider signs in the user a sign-in provider vouched for, matched by their
+// verified email, and returns a JWT token. Unknown emails get an account when the
+// provider auto-creates them (the first user becomes super_admin, as with Register).
+func (s *AuthService) LoginWithProvider(ctx context.Context, p *domain.OAuthProvider, email, name string) (string, *domain.User, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
+
+	email = strings.TrimSpace(strings.ToLower(email))
+	if email == "" {
+		return "", nil, domain.ErrOAuthEmailNotVerified
+	}
+	if !p.AllowsEmail(email) {
+		return "", nil, domain.ErrOAuthDomainNotAllowed
+	}
+
+	user, _ := s.repo.User().GetByEmail(ctx, email)
+	if user == nil {
+		if !p.AutoCreate {
+			return "", nil, domain.ErrOAuthNoAccount
+		}
+		role := domain.RoleUser
+		if count, _ := s.repo.User().Count(ctx); count == 0 {
+			role = domain.RoleSuperAdmin
+		}
+		// No password: the account signs in through the provider until one is set with a reset
+		user = &domain.User{
+			ID:        ids.New(ids.User),
+			Email:     email,
+			Name:      name,
+			Role:      role,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}
+		if err := user.Validate(); err != nil {
+			return "", nil, err
+		}
+		if err := s.repo.User().Create(ctx, user); err != nil {
+			return "", nil, err
+		}
+		log.Printf("[AUTH] Created user %s on first sign-in with %s", user.ID, p.ID)
+	}
+
+	token, err := s.generateToken(user)
+	if err != nil {
+		return "", nil, err
+	}
+	return token, user, nil
+}
+
 // ListUsers returns all users in the system (admin only)
 func (s *AuthService) ListUsers(ctx context.Context) ([]*domain.User, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpList)
+	defer cancel()
 	return s.repo.User().List(ctx)
 }
 
 // DeleteUser removes a user from the system (admin only)
 func (s *AuthService) DeleteUser(ctx context.Context, userID string) error {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
+
 	// Prevent deleting the last admin
 	user, err := s.repo.User().GetByID(ctx, userID)
 	if err != nil {
@@ -191,6 +305,9 @@ func (s *AuthService) DeleteUser(ctx context.Context, userID string) error {
 
 // CreateUser creates a new user with a specified role (admin only)
 func (s *AuthService) CreateUser(ctx context.Context, email, password, name string, role domain.UserRole) (*domain.User, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
+
 	// Check if user already exists
 	existing, _ := s.repo.User().GetByEmail(ctx, email)
 	if existing != nil {
@@ -198,7 +315,7 @@ func (s *AuthService) CreateUser(ctx context.Context, email, password, name stri
 	}
 
 	user := &domain.User{
-		ID:        uuid.New().String(),
+		ID:        ids.New(ids.User),
 		Email:     email,
 		Name:      name,
 		Role:      role,
@@ -223,6 +340,8 @@ func (s *AuthService) CreateUser(ctx context.Context, email, password, name stri
 
 // UpdateUser updates a user's profile information
 func (s *AuthService) UpdateUser(ctx context.Context, userID string, name, email string, role *domain.UserRole) (*domain.User, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
 	user, err := s.repo.User().GetByID(ctx, userID)
 	if err != nil {
 		return nil, err
@@ -262,8 +381,36 @@ func (s *AuthService) UpdateUser(ctx context.Context, userID string, name, email
 	return user, nil
 }
 
+// SetTimezone sets the IANA time zone the user's dashboard stats, and the stats of
+// their forms without a timezone of their own, are counted in. Empty means UTC.
+func (s *AuthService) SetTimezone(ctx context.Context, userID, timezone string) (*domain.User, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
+	timezone, err := domain.NormalizeTimezone(timezone)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.repo.User().GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, domain.ErrUserNotFound
+	}
+
+	user.Timezone = timezone
+	user.UpdatedAt = time.Now()
+
+	if err := s.repo.User().Update(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
 // UpdatePassword changes a user's password (requires current password verification)
 func (s *AuthService) UpdatePassword(ctx context.Context, userID, currentPassword, newPassword string) error {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
 	user, err := s.repo.User().GetByID(ctx, userID)
 	if err != nil {
 		return err
@@ -288,6 +435,8 @@ func (s *AuthService) UpdatePassword(ctx context.Context, userID, currentPasswor
 
 // RequestPasswordReset creates a password reset token for the given email
 func (s *AuthService) RequestPasswordReset(ctx context.Context, email string) (*domain.PasswordResetToken, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
 	user, err := s.repo.User().GetByEmail(ctx, email)
 	if err != nil {
 		return nil, err
@@ -308,7 +457,7 @@ func (s *AuthService) RequestPasswordReset(ctx context.Context, email string) (*
 	tokenStr := base64.URLEncoding.EncodeToString(tokenBytes)
 
 	resetToken := &domain.PasswordResetToken{
-		ID:        uuid.New().String(),
+		ID:        ids.New(ids.PasswordReset),
 		UserID:    user.ID,
 		Token:     tokenStr,
 		ExpiresAt: time.Now().Add(1 * time.Hour), // 1 hour expiry
@@ -324,6 +473,8 @@ func (s *AuthService) RequestPasswordReset(ctx context.Context, email string) (*
 
 // ResetPassword resets the password using a valid reset token
 func (s *AuthService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
 	resetToken, err := s.repo.PasswordReset().GetByToken(ctx, token)
 	if err != nil {
 		return err
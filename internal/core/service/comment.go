@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"headless_form/internal/core/domain"
+	"headless_form/internal/core/ids"
+	"headless_form/internal/core/ports"
+)
+
+// Mention describes a user being mentioned in a comment, for notifications
+type Mention struct {
+	Comment    *domain.Comment
+	Submission *domain.Submission
+	Form       *domain.Form
+	Author     *domain.User
+	Mentioned  *domain.User
+}
+
+// CommentService manages threaded comments on submissions and notifies mentioned users
+type CommentService struct {
+	repo      ports.Repository
+	timeouts  Timeouts
+	onMention func(m Mention)
+}
+
+// NewCommentService creates a new comment service
+func NewCommentService(repo ports.Repository) *CommentService {
+	return &CommentService{repo: repo, timeouts: DefaultTimeouts}
+}
+
+// SetTimeouts overrides the per-operation timeouts (see DefaultTimeouts)
+func (s *CommentService) SetTimeouts(t Timeouts) {
+	s.timeouts = t
+}
+
+// SetMentionCallback sets the callback notifying users mentioned in a new or edited comment
+func (s *CommentService) SetMentionCallback(fn func(m Mention)) {
+	s.onMention = fn
+}
+
+// ListComments returns a submission's comments, oldest first. Replies carry their parent's ID.
+func (s *CommentService) ListComments(ctx context.Context, submissionID string) ([]*domain.Comment, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpList)
+	defer cancel()
+	return s.repo.Comment().ListBySubmissionID(ctx, submissionID)
+}
+
+// AddComment adds a comment (or, with parentID, a reply) to a submission by authorID.
+// Users mentioned as "@email" who can see the form are notified.
+func (s *CommentService) AddComment(ctx context.Context, submission *domain.Submission, form *domain.Form, authorID, parentID, body string) (*domain.Comment, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
+
+	comment := &domain.Comment{
+		ID:           ids.New(ids.Comment),
+		SubmissionID: submission.ID,
+		ParentID:     parentID,
+		UserID:       authorID,
+		Body:         body,
+		CreatedAt:    time.Now().UTC(),
+	}
+	if err := comment.Validate(); err != nil {
+		return nil, err
+	}
+	if parentID != "" {
+		parent, err := s.repo.Comment().GetByID(ctx, parentID)
+		if err != nil {
+			return nil, fmt.Errorf("get parent comment: %w", err)
+		}
+		if parent == nil || parent.SubmissionID != submission.ID {
+			return nil, domain.ErrCommentNotFound
+		}
+	}
+
+	mentioned, err := s.resolveMentions(ctx, comment, form)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Comment().Create(ctx, comment); err != nil {
+		return nil, fmt.Errorf("create comment: %w", err)
+	}
+
+	s.notify(ctx, comment, submission, form, mentioned)
+	return comment, nil
+}
+
+// UpdateComment replaces the body of a comment. Only its author may edit it; users
+// newly mentioned by the edit are notified.
+func (s *CommentService) UpdateComment(ctx context.Context, submission *domain.Submission, form *domain.Form, commentID, userID, body string) (*domain.Comment, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
+
+	comment, err := s.getComment(ctx, submission.ID, commentID)
+	if err != nil {
+		return nil, err
+	}
+	if comment.UserID != userID {
+		return nil, domain.ErrNotCommentAuthor
+	}
+
+	previous := make(map[string]bool, len(comment.Mentions))
+	for _, id := range comment.Mentions {
+		previous[id] = true
+	}
+	comment.Body = body
+	if err := comment.Validate(); err != nil {
+		return nil, err
+	}
+	mentioned, err := s.resolveMentions(ctx, comment, form)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	comment.UpdatedAt = &now
+	if err := s.repo.Comment().Update(ctx, comment); err != nil {
+		return nil, fmt.Errorf("update comment: %w", err)
+	}
+
+	var added []*domain.User
+	for _, u := range mentioned {
+		if !previous[u.ID] {
+			added = append(added, u)
+		}
+	}
+	s.notify(ctx, comment, submission, form, added)
+	return comment, nil
+}
+
+// DeleteComment removes a comment and its replies. Authors may delete their own
+// comments; admins (canModerate) may delete any.
+func (s *CommentService) DeleteComment(ctx context.Context, submissionID, commentID, userID string, canModerate bool) error {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
+
+	comment, err := s.getComment(ctx, submissionID, commentID)
+	if err != nil {
+		return err
+	}
+	if comment.UserID != userID && !canModerate {
+		return domain.ErrNotCommentAuthor
+	}
+	return s.repo.Comment().Delete(ctx, comment.ID)
+}
+
+func (s *CommentService) getComment(ctx context.Context, submissionID, commentID string) (*domain.Comment, error) {
+	comment, err := s.repo.Comment().GetByID(ctx, commentID)
+	if err != nil {
+		return nil, fmt.Errorf("get comment: %w", err)
+	}
+	if comment == nil || comment.SubmissionID != submissionID {
+		return nil, domain.ErrCommentNotFound
+	}
+	return comment, nil
+}
+
+// resolveMentions looks up the users mentioned in the comment and records their IDs.
+// Unknown emails and users who can't see the form (not its owner, a member of its team
+// or an admin) are skipped so a mention never leaks submission content.
+func (s *CommentService) resolveMentions(ctx context.Context, comment *domain.Comment, form *domain.Form) ([]*domain.User, error) {
+	var users []*domain.User
+	comment.Mentions = []string{}
+	for _, email := range comment.MentionedEmails() {
+		user, err := s.repo.User().GetByEmail(ctx, email)
+		if errors.Is(err, domain.ErrUserNotFound) || (err == nil && user == nil) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("lookup mentioned user: %w", err)
+		}
+		if user.ID != form.OwnerID && user.Role != domain.RoleAdmin && user.Role != domain.RoleSuperAdmin {
+			if form.TeamID == "" {
+				continue
+			}
+			roles, err := s.repo.Team().Roles(ctx, user.ID)
+			if err != nil {
+				return nil, fmt.Errorf("lookup mentioned user's teams: %w", err)
+			}
+			if _, ok := roles[form.TeamID]; !ok {
+				continue
+			}
+		}
+		users = append(users, user)
+		comment.Mentions = append(comment.Mentions, user.ID)
+	}
+	return users, nil
+}
+
+// notify calls the mention callback (async) for each mentioned user other than the author
+func (s *CommentService) notify(ctx context.Context, comment *domain.Comment, submission *domain.Submission, form *domain.Form, mentioned []*domain.User) {
+	if s.onMention == nil || len(mentioned) == 0 {
+		return
+	}
+	author, err := s.repo.User().GetByID(ctx, comment.UserID)
+	if err != nil || author == nil {
+		log.Printf("[COMMENT] Mention notification without author details for comment %s: %v", comment.ID, err)
+		author = &domain.User{ID: comment.UserID}
+	}
+	for _, user := range mentioned {
+		if user.ID == comment.UserID {
+			continue
+		}
+		go s.onMention(Mention{Comment: comment, Submission: submission, Form: form, Author: author, Mentioned: user})
+	}
+}
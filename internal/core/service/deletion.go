@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"time"
+
+	"headless_form/internal/core/domain"
+	"headless_form/internal/core/ports"
+)
+
+const (
+	// DeleteConfirmTTL is how long a delete confirmation token stays valid
+	DeleteConfirmTTL = 10 * time.Minute
+	// DefaultDeleteGracePeriod is how long a deleted form stays in the trash before it's purged
+	DefaultDeleteGracePeriod = 30 * 24 * time.Hour
+)
+
+// pendingDelete is an issued but not yet confirmed delete request
+type pendingDelete struct {
+	token     string
+	expiresAt time.Time
+}
+
+// SetArchiver sets the function that stores a final export of a form before it's deleted.
+// It returns where the archive was written. Deletion is aborted if it fails.
+func (s *FormService) SetArchiver(fn func(form *domain.Form, submissions []*domain.Submission) (string, error)) {
+	s.archiver = fn
+}
+
+// SetFileStorage sets where submission uploads are kept, so they're removed with the form
+func (s *FormService) SetFileStorage(fs ports.FileStorage) {
+	s.files = fs
+}
+
+// SetDeleteGracePeriod sets how long deleted forms can be restored before they're purged
+func (s *FormService) SetDeleteGracePeriod(d time.Duration) {
+	if d > 0 {
+		s.gracePeriod = d
+	}
+}
+
+// RequestDelete starts a two-step delete and returns the token needed to confirm it.
+// A new request replaces any earlier token for the form.
+func (s *FormService) RequestDelete(ctx context.Context, publicID string) (string, time.Time, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpLookup)
+	defer cancel()
+	if _, err := s.GetForm(ctx, publicID); err != nil {
+		return "", time.Time{}, err
+	}
+
+	b := make([]byte, 18)
+	if _, err := rand.Read(b); err != nil {
+		return "", time.Time{}, err
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+	expiresAt := time.Now().Add(DeleteConfirmTTL)
+
+	s.pendingMu.Lock()
+	s.pendingDeletes[publicID] = pendingDelete{token: token, expiresAt: expiresAt}
+	s.pendingMu.Unlock()
+
+	return token, expiresAt, nil
+}
+
+// ConfirmDelete checks the confirmation token, archives the form with all submissions,
+// and moves it into the grace period. The form stops accepting submissions immediately.
+func (s *FormService) ConfirmDelete(ctx context.Context, publicID, token string) (*domain.Form, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpExport)
+	defer cancel()
+	s.pendingMu.Lock()
+	pending, ok := s.pendingDeletes[publicID]
+	valid := ok && time.Now().Before(pending.expiresAt) && subtle.ConstantTimeCompare([]byte(pending.token), []byte(token)) == 1
+	if valid {
+		delete(s.pendingDeletes, publicID)
+	}
+	s.pendingMu.Unlock()
+	if !valid {
+		return nil, domain.ErrInvalidDeleteToken
+	}
+
+	form, err := s.GetForm(ctx, publicID)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.archiver != nil {
+		submissions, err := s.repo.Submission().GetByFormID(ctx, form.ID)
+		if err != nil {
+			return nil, fmt.Errorf("load submissions for archive: %w", err)
+		}
+		path, err := s.archiver(form, submissions)
+		if err != nil {
+			return nil, fmt.Errorf("archive form: %w", err)
+		}
+		form.ArchivePath = path
+	}
+
+	now := time.Now().UTC()
+	purgeAt := now.Add(s.gracePeriod)
+	form.DeletedAt = &now
+	form.PurgeAt = &purgeAt
+	form.UpdatedAt = now
+	if err := s.repo.Form().Update(ctx, form); err != nil {
+		return nil, fmt.Errorf("mark form deleted: %w", err)
+	}
+	return form, nil
+}
+
+// GetDeletedForm returns a form that is in its deletion grace period
+func (s *FormService) GetDeletedForm(ctx context.Context, publicID string) (*domain.Form, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpLookup)
+	defer cancel()
+	form, err := s.repo.Form().GetByPublicID(ctx, publicID)
+	if err != nil {
+		return nil, fmt.Errorf("get form: %w", err)
+	}
+	if form == nil || !form.IsDeleted() {
+		return nil, domain.ErrFormNotFound
+	}
+	return form, nil
+}
+
+// ListTrash returns deleted forms that can still be restored. An empty ownerID lists all owners.
+func (s *FormService) ListTrash(ctx context.Context, ownerID string) ([]*domain.Form, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpList)
+	defer cancel()
+	return s.repo.Form().ListDeleted(ctx, ownerID)
+}
+
+// RestoreForm brings a deleted form back before its grace period ends
+func (s *FormService) RestoreForm(ctx context.Context, publicID string) (*domain.Form, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
+	form, err := s.GetDeletedForm(ctx, publicID)
+	if err != nil {
+		return nil, err
+	}
+
+	form.DeletedAt = nil
+	form.PurgeAt = nil
+	form.UpdatedAt = time.Now()
+	if err := s.repo.Form().Update(ctx, form); err != nil {
+		return nil, fmt.Errorf("restore form: %w", err)
+	}
+	return form, nil
+}
+
+// PurgeDeleted permanently removes forms whose grace period has ended.
+// Returns the number of forms purged.
+func (s *FormService) PurgeDeleted(ctx context.Context) (int, error) {
+	forms, err := s.repo.Form().ListPurgeable(ctx, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("list purgeable forms: %w", err)
+	}
+
+	purged := 0
+	for _, form := range forms {
+		var files []domain.FileRef
+		if s.files != nil {
+			submissions, err := s.repo.Submission().GetByFormID(ctx, form.ID)
+			if err != nil {
+				log.Printf("[FORMS] Failed to list files of form %s: %v", form.PublicID, err)
+				continue
+			}
+			for _, submission := range submissions {
+				files = append(files, submission.Meta.Files...)
+			}
+		}
+		if err := s.repo.Form().Delete(ctx, form.ID); err != nil {
+			log.Printf("[FORMS] Failed to purge form %s: %v", form.PublicID, err)
+			continue
+		}
+		deleteFiles(ctx, s.files, form.ID, files)
+		purged++
+	}
+	return purged, nil
+}
+
+// StartPurge runs PurgeDeleted every interval until ctx is cancelled, skipping
+// runs while paused reports true (nil never pauses)
+func (s *FormService) StartPurge(ctx context.Context, interval time.Duration, paused func() bool) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if paused != nil && paused() {
+					continue
+				}
+				if n, err := s.PurgeDeleted(ctx); err != nil {
+					log.Printf("[FORMS] Purge failed: %v", err)
+				} else if n > 0 {
+					log.Printf("[FORMS] Purged %d deleted form(s)", n)
+				}
+			}
+		}
+	}()
+}
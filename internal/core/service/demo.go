@@ -0,0 +1,264 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"headless_form/internal/core/domain"
+	"headless_form/internal/core/ids"
+	"headless_form/internal/core/ports"
+)
+
+// Demo mode defaults
+const (
+	DefaultDemoEmail         = "demo@example.com"
+	DefaultDemoPassword      = "demo-password"
+	DefaultDemoResetInterval = time.Hour
+)
+
+// ErrDemoDatabaseInUse is returned when demo mode is enabled on a database that
+// already holds real users, which the scheduled reset would wipe
+var ErrDemoDatabaseInUse = errors.New("database already has users; demo mode needs an empty or demo database")
+
+// DemoCredentials is the public login of a demo instance
+type DemoCredentials struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// DemoService runs a public demo instance (DEMO_MODE): it seeds a demo user with
+// sample forms and submissions, and periodically wipes and reseeds the database
+// so visitors always start from the same data.
+type DemoService struct {
+	repo        ports.Repository
+	reset       func(ctx context.Context) error
+	credentials DemoCredentials
+	userID      string // Kept across resets so visitors' sessions stay valid
+	timeouts    Timeouts
+}
+
+// NewDemoService creates a new demo service. reset must empty the whole database.
+func NewDemoService(repo ports.Repository, reset func(ctx context.Context) error, credentials DemoCredentials) *DemoService {
+	if credentials.Email == "" {
+		credentials.Email = DefaultDemoEmail
+	}
+	if credentials.Password == "" {
+		credentials.Password = DefaultDemoPassword
+	}
+	return &DemoService{repo: repo, reset: reset, credentials: credentials, timeouts: DefaultTimeouts}
+}
+
+// SetTimeouts overrides the per-operation timeouts (see DefaultTimeouts)
+func (s *DemoService) SetTimeouts(t Timeouts) {
+	s.timeouts = t
+}
+
+// Credentials returns the demo login, shown on the login page
+func (s *DemoService) Credentials() DemoCredentials {
+	return s.credentials
+}
+
+// Seed creates the demo user and sample data unless the demo user already exists.
+// It refuses to run on a database with other users (ErrDemoDatabaseInUse).
+func (s *DemoService) Seed(ctx context.Context) error {
+	ctx, cancel := s.timeouts.bound(ctx, OpExport)
+	defer cancel()
+
+	existing, err := s.repo.User().GetByEmail(ctx, s.credentials.Email)
+	if err != nil && !errors.Is(err, domain.ErrUserNotFound) {
+		return fmt.Errorf("lookup demo user: %w", err)
+	}
+	if existing != nil {
+		s.userID = existing.ID
+		return nil
+	}
+	n, err := s.repo.User().Count(ctx)
+	if err != nil {
+		return fmt.Errorf("count users: %w", err)
+	}
+	if n > 0 {
+		return ErrDemoDatabaseInUse
+	}
+
+	if s.userID == "" {
+		s.userID = ids.New(ids.User)
+	}
+	now := time.Now()
+	user := &domain.User{
+		ID:        s.userID,
+		Email:     s.credentials.Email,
+		Name:      "Demo User",
+		Role:      domain.RoleSuperAdmin,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := user.SetPassword(s.credentials.Password); err != nil {
+		return err
+	}
+
+	return s.repo.Tx(ctx, func(repo ports.Repository) error {
+		if err := repo.User().Create(ctx, user); err != nil {
+			return fmt.Errorf("create demo user: %w", err)
+		}
+		for i, sample := range demoForms {
+			form := demoForm(ctx, repo, user.ID, sample, now)
+			if err := repo.Form().Create(ctx, form); err != nil {
+				return fmt.Errorf("create form %q: %w", sample.slug, err)
+			}
+			for j := 0; j < sample.submissions; j++ {
+				if err := repo.Submission().Create(ctx, demoSubmission(form, sample, i, j, now)); err != nil {
+					return fmt.Errorf("create submission: %w", err)
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// Reset wipes the database and seeds it again
+func (s *DemoService) Reset(ctx context.Context) error {
+	if err := s.reset(ctx); err != nil {
+		return fmt.Errorf("wipe database: %w", err)
+	}
+	return s.Seed(ctx)
+}
+
+// Start resets the demo data every interval until ctx is cancelled,
+// skipping runs while paused reports true (nil never pauses)
+func (s *DemoService) Start(ctx context.Context, interval time.Duration, paused func() bool) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if paused != nil && paused() {
+					continue
+				}
+				if err := s.Reset(ctx); err != nil {
+					log.Printf("[DEMO] Reset failed: %v", err)
+				} else {
+					log.Printf("[DEMO] Demo data reset")
+				}
+			}
+		}
+	}()
+}
+
+// demoSample describes one sample form and how to fill its submissions
+type demoSample struct {
+	slug        string
+	name        string
+	submissions int
+	fields      func(j int) map[string]interface{}
+}
+
+var (
+	demoNames = []string{"Ada Lovelace", "Grace Hopper", "Alan Turing", "Katherine Johnson", "Linus Torvalds",
+		"Margaret Hamilton", "Dennis Ritchie", "Barbara Liskov", "Ken Thompson", "Frances Allen"}
+	demoMessages = []string{
+		"Hi, I'd like to know more about your pricing for teams.",
+		"Is there a way to export all submissions as CSV?",
+		"Great product! Do you offer a self-hosted plan?",
+		"We're interested in a partnership, who should we talk to?",
+		"The contact page on mobile looks broken for me.",
+	}
+	demoFeedback = []string{
+		"Setup took five minutes, love it.",
+		"Would be nice to have dark mode.",
+		"Webhooks work great with our CRM.",
+		"Docs could use more examples.",
+	}
+)
+
+// demoForms are the forms created for the demo user, with realistic submissions
+var demoForms = []demoSample{
+	{slug: "contact", name: "Contact", submissions: 42, fields: func(j int) map[string]interface{} {
+		return map[string]interface{}{
+			"name":    demoNames[j%len(demoNames)],
+			"email":   demoEmail(j),
+			"message": demoMessages[j%len(demoMessages)],
+		}
+	}},
+	{slug: "newsletter", name: "Newsletter Signup", submissions: 27, fields: func(j int) map[string]interface{} {
+		return map[string]interface{}{
+			"email": demoEmail(j),
+		}
+	}},
+	{slug: "feedback", name: "Product Feedback", submissions: 15, fields: func(j int) map[string]interface{} {
+		return map[string]interface{}{
+			"rating":   3 + j%3,
+			"feedback": demoFeedback[j%len(demoFeedback)],
+			"email":    demoEmail(j),
+		}
+	}},
+}
+
+// demoEmail derives a submitter's email from their name
+func demoEmail(j int) string {
+	var local []byte
+	for _, r := range demoNames[j%len(demoNames)] {
+		switch {
+		case r >= 'A' && r <= 'Z':
+			local = append(local, byte(r-'A'+'a'))
+		case r == ' ':
+			local = append(local, '.')
+		default:
+			local = append(local, byte(r))
+		}
+	}
+	return fmt.Sprintf("%s%d@example.com", local, j/len(demoNames)+1)
+}
+
+// demoForm builds a sample form owned by ownerID
+func demoForm(ctx context.Context, repo ports.Repository, ownerID string, sample demoSample, now time.Time) *domain.Form {
+	createdAt := now.AddDate(0, 0, -30)
+	return &domain.Form{
+		ID:      ids.New(ids.Form),
+		OwnerID: ownerID,
+		PublicID: ids.Unique(ids.FormPublic, func(candidate string) bool {
+			existing, _ := repo.Form().GetByPublicID(ctx, candidate)
+			return existing != nil
+		}),
+		Slug:           sample.slug,
+		Name:           sample.name,
+		Status:         domain.FormStatusActive,
+		AllowedOrigins: []string{"*"},
+		AccessMode:     string(domain.AccessModePublic),
+		NotifyEmails:   []string{},
+		CreatedAt:      createdAt,
+		UpdatedAt:      createdAt,
+	}
+}
+
+// demoSubmission builds the j-th submission of the i-th sample form, spread
+// over the last 30 days so stats and charts have something to show
+func demoSubmission(form *domain.Form, sample demoSample, i, j int, now time.Time) *domain.Submission {
+	data, _ := json.Marshal(sample.fields(j))
+	createdAt := now.Add(-time.Duration((j*17+i*5)%(30*24)) * time.Hour)
+	status := domain.SubmissionStatusUnread
+	if j%3 != 0 {
+		status = domain.SubmissionStatusRead
+	}
+	return &domain.Submission{
+		ID:     ids.New(ids.Submission),
+		FormID: form.ID,
+		Status: status,
+		Data:   data,
+		Meta: domain.SubmissionMeta{
+			Server: domain.ServerMeta{
+				IP:        fmt.Sprintf("203.0.113.%d", j%250+1),
+				UserAgent: "Mozilla/5.0 (Macintosh; Intel Mac OS X 14_0) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.0 Safari/605.1.15",
+				Timestamp: createdAt.UTC(),
+			},
+			Client: map[string]interface{}{"source": "demo"},
+		},
+		CreatedAt: createdAt,
+	}
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"headless_form/internal/core/domain"
+	"headless_form/internal/core/ports"
+)
+
+// DefaultEmailQueueRetention is how long sent and dead emails stay in the queue
+const DefaultEmailQueueRetention = 7 * 24 * time.Hour
+
+// EmailQueueService reads the outgoing email queue and prunes finished emails.
+// The email adapter fills the queue and sends from it.
+type EmailQueueService struct {
+	repo      ports.Repository
+	timeouts  Timeouts
+	retention time.Duration
+}
+
+// NewEmailQueueService creates a new email queue service
+func NewEmailQueueService(repo ports.Repository) *EmailQueueService {
+	return &EmailQueueService{repo: repo, timeouts: DefaultTimeouts, retention: DefaultEmailQueueRetention}
+}
+
+// SetTimeouts overrides the per-operation timeouts (see DefaultTimeouts)
+func (s *EmailQueueService) SetTimeouts(t Timeouts) {
+	s.timeouts = t
+}
+
+// SetRetention sets how long sent and dead emails are kept before they're pruned
+func (s *EmailQueueService) SetRetention(d time.Duration) {
+	if d > 0 {
+		s.retention = d
+	}
+}
+
+// List returns one page of queued emails, newest first, with the given status or any
+// when it's empty, how many match in total, and how many emails have each status
+func (s *EmailQueueService) List(ctx context.Context, status domain.EmailStatus, page, limit int) ([]*domain.QueuedEmail, int, map[domain.EmailStatus]int, error) {
+	if status != "" && !status.Valid() {
+		return nil, 0, nil, domain.ErrInvalidEmailStatus
+	}
+	ctx, cancel := s.timeouts.bound(ctx, OpList)
+	defer cancel()
+	emails, total, err := s.repo.EmailQueue().List(ctx, status, limit, (page-1)*limit)
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("list email queue: %w", err)
+	}
+	counts, err := s.repo.EmailQueue().CountByStatus(ctx)
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("count email queue: %w", err)
+	}
+	// Every status is reported, with 0 for the ones no email has
+	all := map[domain.EmailStatus]int{domain.EmailPending: 0, domain.EmailSent: 0, domain.EmailDead: 0}
+	for st, n := range counts {
+		all[st] = n
+	}
+	return emails, total, all, nil
+}
+
+// Prune deletes sent and dead emails older than the retention period.
+// Returns the number of emails deleted.
+func (s *EmailQueueService) Prune(ctx context.Context) (int, error) {
+	n, err := s.repo.EmailQueue().DeleteFinishedBefore(ctx, time.Now().Add(-s.retention))
+	if err != nil {
+		return 0, fmt.Errorf("prune email queue: %w", err)
+	}
+	return n, nil
+}
+
+// StartPrune runs Prune every interval until ctx is cancelled,
+// skipping runs while paused reports true (nil never pauses)
+func (s *EmailQueueService) StartPrune(ctx context.Context, interval time.Duration, paused func() bool) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if paused != nil && paused() {
+					continue
+				}
+				if n, err := s.Prune(ctx); err != nil {
+					log.Printf("[EMAIL] Queue pruning failed: %v", err)
+				} else if n > 0 {
+					log.Printf("[EMAIL] Pruned %d emails from the queue", n)
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"headless_form/internal/core/domain"
+	"headless_form/internal/core/ports"
+)
+
+// SubmissionFeed fans out new submissions to the dashboards following their form, so
+// they update live instead of polling the submission list
+type SubmissionFeed struct {
+	events ports.EventBus // Shares submissions with other replicas, if set
+	origin string         // Tells this replica's events apart on the bus
+
+	mu          sync.Mutex
+	subscribers map[string]map[chan domain.Submission]struct{} // By form ID
+}
+
+// feedEvent is a submission shared on the event bus
+type feedEvent struct {
+	Origin     string            `json:"origin"`
+	Submission domain.Submission `json:"submission"`
+}
+
+// NewSubmissionFeed creates a new, in-process submission feed
+func NewSubmissionFeed() *SubmissionFeed {
+	return &SubmissionFeed{
+		origin:      uuid.NewString(),
+		subscribers: make(map[string]map[chan domain.Submission]struct{}),
+	}
+}
+
+// SetEventBus also shares submissions through bus, so dashboards connected to any
+// replica see submissions stored by another
+func (f *SubmissionFeed) SetEventBus(bus ports.EventBus) {
+	f.events = bus
+}
+
+// feedTopic is the event bus topic of a form's new submissions
+func feedTopic(formID string) string {
+	return "submissions:" + formID
+}
+
+// Publish sends a new submission to its form's subscribers. It doesn't block:
+// subscribers too slow to take it miss it.
+func (f *SubmissionFeed) Publish(submission *domain.Submission) {
+	f.deliver(*submission)
+	if f.events == nil {
+		return
+	}
+	data, err := json.Marshal(feedEvent{Origin: f.origin, Submission: *submission})
+	if err != nil {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := f.events.Publish(ctx, feedTopic(submission.FormID), data); err != nil {
+			log.Printf("[SUBMISSIONS] Failed to publish submission %s: %v", submission.ID, err)
+		}
+	}()
+}
+
+// deliver hands a submission to the local subscribers of its form
+func (f *SubmissionFeed) deliver(submission domain.Submission) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for ch := range f.subscribers[submission.FormID] {
+		select {
+		case ch <- submission:
+		default: // Slow subscriber: it can catch up from the submission list
+		}
+	}
+}
+
+// Subscribe returns a channel receiving the form's new submissions until
+// unsubscribe is called. With an event bus, submissions stored by other replicas
+// arrive through it; this replica's are delivered directly, even while the bus is down.
+func (f *SubmissionFeed) Subscribe(formID string) (submissions <-chan domain.Submission, unsubscribe func()) {
+	ch := make(chan domain.Submission, 16)
+	f.mu.Lock()
+	if f.subscribers[formID] == nil {
+		f.subscribers[formID] = make(map[chan domain.Submission]struct{})
+	}
+	f.subscribers[formID][ch] = struct{}{}
+	f.mu.Unlock()
+
+	stopRemote := func() {}
+	if f.events != nil {
+		stopRemote = f.followRemote(formID, ch)
+	}
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			stopRemote()
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			delete(f.subscribers[formID], ch)
+			if len(f.subscribers[formID]) == 0 {
+				delete(f.subscribers, formID)
+			}
+		})
+	}
+}
+
+// followRemote relays the form's submissions other replicas publish on the event
+// bus to ch, until the returned function is called
+func (f *SubmissionFeed) followRemote(formID string, ch chan domain.Submission) func() {
+	events, unsubscribe := f.events.Subscribe(feedTopic(formID))
+	stop := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			case data := <-events:
+				var event feedEvent
+				if err := json.Unmarshal(data, &event); err != nil || event.Origin == f.origin {
+					continue
+				}
+				select {
+				case ch <- event.Submission:
+				default:
+				}
+			}
+		}
+	}()
+	return func() {
+		unsubscribe()
+		close(stop)
+	}
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"headless_form/internal/core/domain"
+)
+
+// SetLinkField sets the field whose value links the form's new submissions to the
+// submission they follow up on; "" turns linking by field off
+func (s *FormService) SetLinkField(ctx context.Context, publicID, field string) (*domain.Form, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
+	field, err := domain.NormalizeLinkField(field)
+	if err != nil {
+		return nil, err
+	}
+	form, err := s.repo.Form().GetByPublicID(ctx, publicID)
+	if err != nil {
+		return nil, fmt.Errorf("get form: %w", err)
+	}
+	if form == nil {
+		return nil, domain.ErrFormNotFound
+	}
+
+	form.LinkField = field
+	form.UpdatedAt = time.Now()
+
+	if err := s.repo.Form().Update(ctx, form); err != nil {
+		return nil, fmt.Errorf("update form: %w", err)
+	}
+	return form, nil
+}
+
+// linkFromField links a newly stored submission to the one its form's link field names,
+// if that one exists on a form it may be linked to. Failures are only logged: the
+// submission is kept either way.
+func (s *SubmissionService) linkFromField(ctx context.Context, form *domain.Form, submission *domain.Submission, data map[string]interface{}) {
+	ref := form.LinkReference(data)
+	if ref == "" || ref == submission.ID {
+		return
+	}
+	linked, err := s.repo.Submission().GetByID(ctx, ref)
+	if err != nil {
+		log.Printf("[SUBMISSIONS] Failed to look up %s linked from %s: %v", ref, submission.ID, err)
+		return
+	}
+	if linked == nil {
+		return
+	}
+	linkedForm := form
+	if linked.FormID != form.ID {
+		if linkedForm, err = s.repo.Form().GetByID(ctx, linked.FormID); err != nil || linkedForm == nil {
+			return
+		}
+	}
+	if !form.CanLink(linkedForm) {
+		return
+	}
+
+	link := &domain.SubmissionLink{
+		SubmissionID: submission.ID,
+		LinkedID:     linked.ID,
+		Source:       domain.LinkSourceField,
+		CreatedAt:    submission.CreatedAt,
+	}
+	if err := s.repo.Submission().Link(ctx, link); err != nil {
+		log.Printf("[SUBMISSIONS] Failed to link %s to %s: %v", submission.ID, linked.ID, err)
+	}
+}
+
+// LinkSubmissions links a submission to another it refers to, on behalf of userID. Both
+// forms need the same owner or team.
+func (s *SubmissionService) LinkSubmissions(ctx context.Context, submission, linked *domain.Submission, userID string) (*domain.SubmissionLink, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
+	if submission.ID == linked.ID {
+		return nil, domain.ErrSubmissionLinkSelf
+	}
+	form, err := s.repo.Form().GetByID(ctx, submission.FormID)
+	if err != nil || form == nil {
+		return nil, domain.ErrFormNotFound
+	}
+	linkedForm, err := s.repo.Form().GetByID(ctx, linked.FormID)
+	if err != nil || linkedForm == nil {
+		return nil, domain.ErrFormNotFound
+	}
+	if !form.CanLink(linkedForm) {
+		return nil, domain.ErrSubmissionsNotLinkable
+	}
+
+	link := &domain.SubmissionLink{
+		SubmissionID: submission.ID,
+		LinkedID:     linked.ID,
+		Source:       domain.LinkSourceManual,
+		CreatedBy:    userID,
+		CreatedAt:    time.Now(),
+	}
+	if err := s.repo.Submission().Link(ctx, link); err != nil {
+		return nil, fmt.Errorf("link submissions: %w", err)
+	}
+	return link, nil
+}
+
+// UnlinkSubmissions removes the link between two submissions, whichever way it goes
+func (s *SubmissionService) UnlinkSubmissions(ctx context.Context, submissionID, linkedID string) error {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
+	for _, pair := range [][2]string{{submissionID, linkedID}, {linkedID, submissionID}} {
+		removed, err := s.repo.Submission().Unlink(ctx, pair[0], pair[1])
+		if err != nil {
+			return fmt.Errorf("unlink submissions: %w", err)
+		}
+		if removed {
+			return nil
+		}
+	}
+	return domain.ErrSubmissionLinkNotFound
+}
+
+// RelatedSubmissions returns the submissions linked to the submission either way, with
+// their forms, oldest link first. Those on deleted forms are left out.
+func (s *SubmissionService) RelatedSubmissions(ctx context.Context, submission *domain.Submission) ([]domain.RelatedSubmission, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpList)
+	defer cancel()
+	links, err := s.repo.Submission().ListLinks(ctx, submission.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list links: %w", err)
+	}
+
+	forms := make(map[string]*domain.Form)
+	related := make([]domain.RelatedSubmission, 0, len(links))
+	for _, link := range links {
+		otherID, relation := link.LinkedID, domain.RelationReferences
+		if link.LinkedID == submission.ID {
+			otherID, relation = link.SubmissionID, domain.RelationReferencedBy
+		}
+		other, err := s.repo.Submission().GetByID(ctx, otherID)
+		if err != nil {
+			return nil, fmt.Errorf("get submission: %w", err)
+		}
+		if other == nil {
+			continue
+		}
+		form, ok := forms[other.FormID]
+		if !ok {
+			if form, err = s.repo.Form().GetByID(ctx, other.FormID); err != nil {
+				return nil, fmt.Errorf("get form: %w", err)
+			}
+			forms[other.FormID] = form
+		}
+		if form == nil || form.IsDeleted() {
+			continue
+		}
+		related = append(related, domain.RelatedSubmission{
+			Relation:   relation,
+			Source:     link.Source,
+			FormID:     form.PublicID,
+			FormName:   form.Name,
+			Submission: other,
+			LinkedAt:   link.CreatedAt,
+			Form:       form,
+		})
+	}
+	return related, nil
+}
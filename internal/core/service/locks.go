@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"headless_form/internal/core/ports"
+)
+
+// Scheduled jobs coordinated by JobLocks
+const (
+	JobFormPurge  = "form_purge"
+	JobTestPurge  = "test_purge"
+	JobAlerts     = "alerts"
+	JobDemoReset  = "demo_reset"
+	JobTaskFiles  = "task_files"
+	JobWebhookLog = "webhook_log"
+	JobEmailQueue = "email_queue"
+	JobEmailPrune = "email_prune"
+	JobSpamPrune  = "spam_prune"
+	JobRetention  = "retention"
+	JobMonitor    = "monitor"
+)
+
+// JobLocks keeps each scheduled job to one instance when several share a database.
+// The instance that takes a job's lock keeps running it, renewing the lock on every
+// run, until it stops; another instance takes over once the lock expires.
+type JobLocks struct {
+	repo  ports.Repository
+	owner string
+
+	mu       sync.Mutex
+	held     map[string]bool
+	released bool // Set by ReleaseAll: no lock is taken again
+}
+
+// NewJobLocks creates locks held under owner, which must differ between instances.
+// An empty owner uses the host name with a random suffix.
+func NewJobLocks(repo ports.Repository, owner string) *JobLocks {
+	if owner == "" {
+		owner = defaultLockOwner()
+	}
+	return &JobLocks{repo: repo, owner: owner, held: make(map[string]bool)}
+}
+
+func defaultLockOwner() string {
+	host, _ := os.Hostname()
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	return fmt.Sprintf("%s-%s", host, hex.EncodeToString(suffix))
+}
+
+// Owner returns the name this instance holds locks under
+func (l *JobLocks) Owner() string {
+	return l.owner
+}
+
+// Acquire takes or renews the named lock for ttl. It returns false when another
+// instance holds it, or when the database can't be reached, so jobs don't run twice,
+// and always once ReleaseAll was called.
+func (l *JobLocks) Acquire(ctx context.Context, name string, ttl time.Duration) bool {
+	l.mu.Lock()
+	released := l.released
+	l.mu.Unlock()
+	if released {
+		return false
+	}
+
+	ok, err := l.repo.Lock().Acquire(ctx, name, l.owner, time.Now().Add(ttl))
+	if err != nil {
+		log.Printf("[LOCKS] Acquiring %s failed: %v", name, err)
+		ok = false
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.released {
+		// ReleaseAll ran while the lock was being taken: give it up again
+		if ok {
+			if err := l.repo.Lock().Release(ctx, name, l.owner); err != nil {
+				log.Printf("[LOCKS] Releasing %s failed: %v", name, err)
+			}
+		}
+		return false
+	}
+	if ok && !l.held[name] {
+		log.Printf("[LOCKS] Running %s on this instance (%s)", name, l.owner)
+	}
+	l.held[name] = ok
+	return ok
+}
+
+// Paused wraps the paused func of a job started with the given interval, such as
+// FormService.StartPurge, so a run is also skipped unless this instance holds the
+// job's lock. The lock outlives one interval, so its holder keeps the job.
+func (l *JobLocks) Paused(name string, interval time.Duration, paused func() bool) func() bool {
+	return func() bool {
+		if paused != nil && paused() {
+			return true
+		}
+		return !l.Acquire(context.Background(), name, 2*interval)
+	}
+}
+
+// ReleaseAll gives up every lock this instance holds, so other instances take
+// over its jobs without waiting for the locks to expire, and stops taking them again.
+// Call it on shutdown, once the jobs were stopped.
+func (l *JobLocks) ReleaseAll(ctx context.Context) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.released = true
+	for name, held := range l.held {
+		if !held {
+			continue
+		}
+		if err := l.repo.Lock().Release(ctx, name, l.owner); err != nil {
+			log.Printf("[LOCKS] Releasing %s failed: %v", name, err)
+		}
+		delete(l.held, name)
+	}
+}
@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"headless_form/internal/core/domain"
+	"headless_form/internal/core/ids"
+	"headless_form/internal/core/ports"
+)
+
+// migrationBatchSize is how many submissions are read and rewritten per transaction
+const migrationBatchSize = 500
+
+// FieldMigrationService renames or transforms a field across a form's submissions,
+// for when a frontend changes a field name and old data no longer lines up
+type FieldMigrationService struct {
+	repo      ports.Repository
+	tasks     *TaskService
+	timeouts  Timeouts
+	batchSize int
+}
+
+// NewFieldMigrationService creates a new field migration service running its jobs through tasks
+func NewFieldMigrationService(repo ports.Repository, tasks *TaskService) *FieldMigrationService {
+	return &FieldMigrationService{repo: repo, tasks: tasks, timeouts: DefaultTimeouts, batchSize: migrationBatchSize}
+}
+
+// SetTimeouts overrides the per-operation timeouts (see DefaultTimeouts)
+func (s *FieldMigrationService) SetTimeouts(t Timeouts) {
+	s.timeouts = t
+}
+
+// Preview runs the migration over the form's submissions without saving anything
+func (s *FieldMigrationService) Preview(ctx context.Context, publicID string, m domain.FieldMigration) (*domain.FieldMigrationPreview, error) {
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	ctx, cancel := s.timeouts.bound(ctx, OpExport)
+	defer cancel()
+	form, err := s.form(ctx, publicID)
+	if err != nil {
+		return nil, err
+	}
+	submissions, err := s.repo.Submission().GetByFormID(ctx, form.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list submissions: %w", err)
+	}
+
+	preview := &domain.FieldMigrationPreview{Scanned: len(submissions), Samples: []domain.FieldMigrationSample{}}
+	for _, sub := range submissions {
+		var data map[string]interface{}
+		if json.Unmarshal(sub.Data, &data) != nil {
+			continue
+		}
+		before := map[string]interface{}{m.From: data[m.From]}
+		if v, ok := data[m.To]; ok {
+			before[m.To] = v
+		}
+		changed, conflict := m.Apply(data)
+		if conflict {
+			preview.Conflicts++
+		}
+		if !changed {
+			continue
+		}
+		preview.Matched++
+		if len(preview.Samples) < domain.MaxMigrationSamples {
+			preview.Samples = append(preview.Samples, domain.FieldMigrationSample{
+				SubmissionID: sub.ID,
+				Before:       before,
+				After:        map[string]interface{}{m.To: data[m.To]},
+			})
+		}
+	}
+	return preview, nil
+}
+
+// Start validates the migration and runs it as a background task created by userID.
+// Submissions are rewritten in transactions of at most batchSize, the form's field
+// settings follow the rename, and the outcome is recorded in the form's audit log.
+func (s *FieldMigrationService) Start(ctx context.Context, publicID, userID string, m domain.FieldMigration) (*domain.Task, error) {
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	lookupCtx, cancel := s.timeouts.bound(ctx, OpLookup)
+	defer cancel()
+	form, err := s.form(lookupCtx, publicID)
+	if err != nil {
+		return nil, err
+	}
+	return s.tasks.Start(domain.TaskTypeFieldMigration, userID, func(ctx context.Context, p *TaskProgress) (interface{}, error) {
+		return s.run(ctx, p, form, userID, m)
+	})
+}
+
+func (s *FieldMigrationService) form(ctx context.Context, publicID string) (*domain.Form, error) {
+	form, err := s.repo.Form().GetByPublicID(ctx, publicID)
+	if err != nil {
+		return nil, fmt.Errorf("get form: %w", err)
+	}
+	if form == nil || form.IsDeleted() {
+		return nil, domain.ErrFormNotFound
+	}
+	return form, nil
+}
+
+// run migrates the form's submissions batch by batch, stopping between batches once
+// ctx is cancelled. Batches already written stay migrated and are audited either way.
+func (s *FieldMigrationService) run(ctx context.Context, p *TaskProgress, form *domain.Form, userID string, m domain.FieldMigration) (*domain.FieldMigrationResult, error) {
+	res := &domain.FieldMigrationResult{FormID: form.PublicID, From: m.From, To: m.To}
+	err := s.migrate(ctx, p, form, m, res)
+	if err == nil && m.From != m.To {
+		res.FieldRenamed, err = s.renameField(ctx, form, m)
+	}
+
+	// Audit what was done, even when the task stopped partway
+	auditCtx, cancel := s.timeouts.bound(context.WithoutCancel(ctx), OpWrite)
+	defer cancel()
+	details := map[string]interface{}{
+		"from": m.From, "to": m.To, "overwrite": m.Overwrite,
+		"updated": res.Updated, "conflicts": res.Conflicts, "completed": err == nil,
+	}
+	if m.Transform != domain.FieldTransformNone {
+		details["transform"] = m.Transform
+	}
+	if auditErr := s.repo.Audit().Create(auditCtx, &domain.AuditEntry{
+		ID:        ids.New(ids.AuditEntry),
+		FormID:    form.ID,
+		UserID:    userID,
+		Action:    domain.AuditActionFieldMigrated,
+		Details:   details,
+		CreatedAt: time.Now().UTC(),
+	}); auditErr != nil && err == nil {
+		err = fmt.Errorf("record audit entry: %w", auditErr)
+	}
+	return res, err
+}
+
+func (s *FieldMigrationService) migrate(ctx context.Context, p *TaskProgress, form *domain.Form, m domain.FieldMigration, res *domain.FieldMigrationResult) error {
+	// Newest first: submissions arriving meanwhile push the rest back, so none are missed
+	for offset := 0; ; offset += s.batchSize {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		batch, counts, err := s.repo.Submission().GetByFormIDPaginated(ctx, form.ID, "", s.batchSize, offset)
+		if err != nil {
+			return fmt.Errorf("list submissions: %w", err)
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+		err = s.repo.Tx(ctx, func(repo ports.Repository) error {
+			for _, sub := range batch {
+				var data map[string]interface{}
+				if json.Unmarshal(sub.Data, &data) != nil {
+					continue
+				}
+				changed, conflict := m.Apply(data)
+				if conflict {
+					res.Conflicts++
+				}
+				if !changed {
+					continue
+				}
+				raw, err := json.Marshal(data)
+				if err != nil {
+					return fmt.Errorf("encode submission %s: %w", sub.ID, err)
+				}
+				if err := repo.Submission().UpdateData(ctx, sub.ID, raw); err != nil {
+					return fmt.Errorf("update submission %s: %w", sub.ID, err)
+				}
+				res.Updated++
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		res.Scanned += len(batch)
+		p.Update(float64(res.Scanned)*100/float64(max(counts.Total, res.Scanned)),
+			fmt.Sprintf("%d/%d submissions, %d updated", res.Scanned, counts.Total, res.Updated))
+	}
+}
+
+// renameField moves the form's field settings from m.From to m.To, unless To already has its own
+func (s *FieldMigrationService) renameField(ctx context.Context, form *domain.Form, m domain.FieldMigration) (bool, error) {
+	current, err := s.repo.Form().GetByID(ctx, form.ID)
+	if err != nil || current == nil {
+		return false, err
+	}
+	index := -1
+	for i, field := range current.Fields {
+		if field.Name == m.To {
+			return false, nil
+		}
+		if field.Name == m.From {
+			index = i
+		}
+	}
+	if index < 0 {
+		return false, nil
+	}
+	current.Fields[index].Name = m.To
+	current.UpdatedAt = time.Now()
+	if err := s.repo.Form().Update(ctx, current); err != nil {
+		return false, fmt.Errorf("update form: %w", err)
+	}
+	return true, nil
+}
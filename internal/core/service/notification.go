@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"headless_form/internal/core/domain"
+	"headless_form/internal/core/ids"
+	"headless_form/internal/core/ports"
+)
+
+// NotificationService keeps each user's in-app notification center. The Notify*
+// methods turn events (submissions, mentions, webhook failures, alerts) into
+// notifications for the users concerned; they're called from the event callbacks
+// wired up at startup and only log failures.
+type NotificationService struct {
+	repo     ports.Repository
+	timeouts Timeouts
+}
+
+// NewNotificationService creates a new notification service
+func NewNotificationService(repo ports.Repository) *NotificationService {
+	return &NotificationService{repo: repo, timeouts: DefaultTimeouts}
+}
+
+// SetTimeouts overrides the per-operation timeouts (see DefaultTimeouts)
+func (s *NotificationService) SetTimeouts(t Timeouts) {
+	s.timeouts = t
+}
+
+// ListNotifications returns one page of the user's notifications, newest first, how many
+// there are on all pages (only unread ones with unreadOnly) and how many are unread in total
+func (s *NotificationService) ListNotifications(ctx context.Context, userID string, unreadOnly bool, page, limit int) (notifications []*domain.Notification, total, unread int, err error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpList)
+	defer cancel()
+	notifications, err = s.repo.Notification().ListByUserID(ctx, userID, unreadOnly, limit, (page-1)*limit)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("list notifications: %w", err)
+	}
+	unread, err = s.repo.Notification().CountUnread(ctx, userID)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("count unread notifications: %w", err)
+	}
+	total = unread
+	if !unreadOnly {
+		if total, err = s.repo.Notification().Count(ctx, userID); err != nil {
+			return nil, 0, 0, fmt.Errorf("count notifications: %w", err)
+		}
+	}
+	return notifications, total, unread, nil
+}
+
+// UnreadCount returns how many unread notifications the user has
+func (s *NotificationService) UnreadCount(ctx context.Context, userID string) (int, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpLookup)
+	defer cancel()
+	return s.repo.Notification().CountUnread(ctx, userID)
+}
+
+// MarkRead marks one of the user's notifications read
+func (s *NotificationService) MarkRead(ctx context.Context, userID, id string) error {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
+	found, err := s.repo.Notification().MarkRead(ctx, userID, id, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("mark notification read: %w", err)
+	}
+	if !found {
+		return domain.ErrNotificationNotFound
+	}
+	return nil
+}
+
+// MarkAllRead marks all of the user's notifications read and returns how many were unread
+func (s *NotificationService) MarkAllRead(ctx context.Context, userID string) (int, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
+	return s.repo.Notification().MarkAllRead(ctx, userID, time.Now().UTC())
+}
+
+// NotifySubmission notifies the users who starred the form of a new submission.
+// Test submissions are ignored.
+func (s *NotificationService) NotifySubmission(form *domain.Form, submission *domain.Submission) {
+	if submission.IsTest {
+		return
+	}
+	ctx, cancel := s.timeouts.bound(context.Background(), OpWrite)
+	defer cancel()
+
+	userIDs, err := s.repo.Form().ListStarredBy(ctx, form.ID)
+	if err != nil {
+		log.Printf("[NOTIFY] Failed to list users who starred %s: %v", form.PublicID, err)
+		return
+	}
+	for _, userID := range userIDs {
+		s.create(ctx, &domain.Notification{
+			UserID:       userID,
+			Type:         domain.NotificationNewSubmission,
+			Title:        fmt.Sprintf("New submission on %s", form.Name),
+			FormID:       form.PublicID,
+			SubmissionID: submission.ID,
+		})
+	}
+}
+
+// NotifyMention notifies a user mentioned in a comment
+func (s *NotificationService) NotifyMention(m Mention) {
+	ctx, cancel := s.timeouts.bound(context.Background(), OpWrite)
+	defer cancel()
+
+	author := m.Author.Name
+	if author == "" {
+		author = m.Author.Email
+	}
+	s.create(ctx, &domain.Notification{
+		UserID:       m.Mentioned.ID,
+		Type:         domain.NotificationMention,
+		Title:        fmt.Sprintf("%s mentioned you on %s", author, m.Form.Name),
+		Body:         m.Comment.Body,
+		FormID:       m.Form.PublicID,
+		SubmissionID: m.Submission.ID,
+	})
+}
+
+// NotifyWebhookFailed notifies the form's owner that a webhook delivery failed.
+// While an earlier failure notification for the form is unread, no new one is added.
+func (s *NotificationService) NotifyWebhookFailed(formPublicID string) {
+	ctx, cancel := s.timeouts.bound(context.Background(), OpWrite)
+	defer cancel()
+
+	form, err := s.repo.Form().GetByPublicID(ctx, formPublicID)
+	if err != nil || form == nil || form.OwnerID == "" {
+		return
+	}
+	unread, err := s.repo.Notification().HasUnread(ctx, form.OwnerID, domain.NotificationWebhookFailed, form.PublicID)
+	if err != nil {
+		log.Printf("[NOTIFY] Failed to check webhook notifications for %s: %v", form.PublicID, err)
+		return
+	}
+	if unread {
+		return
+	}
+	s.create(ctx, &domain.Notification{
+		UserID: form.OwnerID,
+		Type:   domain.NotificationWebhookFailed,
+		Title:  fmt.Sprintf("Webhook failing for %s", form.Name),
+		Body:   "A webhook delivery failed after all retries. Check that the endpoint is reachable.",
+		FormID: form.PublicID,
+	})
+}
+
+// NotifyAlert notifies the form's owner that one of its alert rules triggered
+func (s *NotificationService) NotifyAlert(form *domain.Form, rule *domain.AlertRule, message string) {
+	if form.OwnerID == "" {
+		return
+	}
+	ctx, cancel := s.timeouts.bound(context.Background(), OpWrite)
+	defer cancel()
+
+	s.create(ctx, &domain.Notification{
+		UserID: form.OwnerID,
+		Type:   domain.NotificationAlert,
+		Title:  fmt.Sprintf("%s alert on %s", rule.Type, form.Name),
+		Body:   message,
+		FormID: form.PublicID,
+	})
+}
+
+func (s *NotificationService) create(ctx context.Context, n *domain.Notification) {
+	n.ID = ids.New(ids.Notification)
+	n.CreatedAt = time.Now().UTC()
+	if err := s.repo.Notification().Create(ctx, n); err != nil {
+		log.Printf("[NOTIFY] Failed to save %s notification for user %s: %v", n.Type, n.UserID, err)
+	}
+}
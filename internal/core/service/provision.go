@@ -0,0 +1,230 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"headless_form/internal/core/domain"
+	"headless_form/internal/core/ids"
+	"headless_form/internal/core/ports"
+
+	"sigs.k8s.io/yaml"
+)
+
+// ProvisionConfig declares users and forms to create or keep in sync at startup
+// (FORMS_CONFIG_FILE), so a fresh deployment can be set up entirely from code
+type ProvisionConfig struct {
+	Users []ProvisionUser `json:"users"`
+	Forms []ProvisionForm `json:"forms"`
+}
+
+// ProvisionUser declares a dashboard user. The password is only needed to create
+// the user; when given for an existing user whose password differs, it is reset.
+type ProvisionUser struct {
+	Email    string          `json:"email"`
+	Name     string          `json:"name,omitempty"`
+	Role     domain.UserRole `json:"role,omitempty"` // Default user
+	Password string          `json:"password,omitempty"`
+}
+
+// ProvisionForm declares a form by slug. Owner is the email of the user who owns
+// it (only used when the form is created); the remaining keys are a FormSpec,
+// including its webhook.
+type ProvisionForm struct {
+	Slug  string `json:"slug"`
+	Owner string `json:"owner"`
+	domain.FormSpec
+}
+
+// ProvisionReport counts what a provisioning run changed
+type ProvisionReport struct {
+	UsersCreated int
+	UsersUpdated int
+	FormsCreated int
+	FormsUpdated int
+	Unchanged    int
+}
+
+// envRef matches ${VAR} references, expanded from the environment so secrets
+// (passwords, webhook secrets) can stay out of the config file
+var envRef = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// ParseProvisionConfig parses a JSON provisioning config after expanding ${VAR}
+// references. Unknown keys are rejected so typos don't silently drop settings.
+func ParseProvisionConfig(data []byte) (*ProvisionConfig, error) {
+	var missing []string
+	data = envRef.ReplaceAllFunc(data, func(ref []byte) []byte {
+		name := string(envRef.FindSubmatch(ref)[1])
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			missing = append(missing, name)
+		}
+		// Escape the value so it can't break out of the JSON string it's in
+		quoted, _ := json.Marshal(v)
+		return quoted[1 : len(quoted)-1]
+	})
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("undefined environment variables: %s", strings.Join(missing, ", "))
+	}
+
+	var cfg ProvisionConfig
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("parse config: %w", err)
+	}
+	return &cfg, nil
+}
+
+// ParseProvisionYAML parses a YAML provisioning config. It's converted to JSON and parsed
+// by ParseProvisionConfig, so it fills the same structs with the same checks.
+func ParseProvisionYAML(data []byte) (*ProvisionConfig, error) {
+	converted, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse config: %w", err)
+	}
+	return ParseProvisionConfig(converted)
+}
+
+// ProvisionService reconciles a ProvisionConfig into the database: missing users
+// and forms are created, drifted ones updated, and nothing else is touched.
+type ProvisionService struct {
+	repo     ports.Repository
+	forms    *FormService
+	timeouts Timeouts
+}
+
+// NewProvisionService creates a new provisioning service applying forms through forms
+func NewProvisionService(repo ports.Repository, forms *FormService) *ProvisionService {
+	return &ProvisionService{repo: repo, forms: forms, timeouts: DefaultTimeouts}
+}
+
+// SetTimeouts overrides the per-operation timeouts (see DefaultTimeouts)
+func (s *ProvisionService) SetTimeouts(t Timeouts) {
+	s.timeouts = t
+}
+
+// Apply reconciles the config, users first so forms can refer to them.
+// It stops at the first invalid entry.
+func (s *ProvisionService) Apply(ctx context.Context, cfg *ProvisionConfig) (ProvisionReport, error) {
+	var report ProvisionReport
+
+	for _, u := range cfg.Users {
+		result, err := s.applyUser(ctx, u)
+		if err != nil {
+			return report, fmt.Errorf("user %q: %w", u.Email, err)
+		}
+		switch result {
+		case domain.ApplyCreated:
+			report.UsersCreated++
+		case domain.ApplyUpdated:
+			report.UsersUpdated++
+		default:
+			report.Unchanged++
+		}
+	}
+
+	for _, f := range cfg.Forms {
+		result, err := s.applyForm(ctx, f)
+		if err != nil {
+			return report, fmt.Errorf("form %q: %w", f.Slug, err)
+		}
+		switch result {
+		case domain.ApplyCreated:
+			report.FormsCreated++
+		case domain.ApplyUpdated:
+			report.FormsUpdated++
+		default:
+			report.Unchanged++
+		}
+	}
+	return report, nil
+}
+
+func (s *ProvisionService) applyUser(ctx context.Context, u ProvisionUser) (domain.ApplyResult, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
+
+	if u.Role == "" {
+		u.Role = domain.RoleUser
+	}
+	switch u.Role {
+	case domain.RoleSuperAdmin, domain.RoleAdmin, domain.RoleUser:
+	default:
+		return "", fmt.Errorf("invalid role %q", u.Role)
+	}
+
+	email := strings.ToLower(strings.TrimSpace(u.Email))
+	user, err := s.repo.User().GetByEmail(ctx, email)
+	if err != nil && !errors.Is(err, domain.ErrUserNotFound) {
+		return "", fmt.Errorf("lookup user: %w", err)
+	}
+
+	if user == nil {
+		if u.Password == "" {
+			return "", domain.ErrPasswordRequired
+		}
+		now := time.Now()
+		user = &domain.User{ID: ids.New(ids.User), Email: email, Name: u.Name, Role: u.Role, CreatedAt: now, UpdatedAt: now}
+		if err := user.Validate(); err != nil {
+			return "", err
+		}
+		if err := user.SetPassword(u.Password); err != nil {
+			return "", err
+		}
+		if err := s.repo.User().Create(ctx, user); err != nil {
+			return "", fmt.Errorf("create user: %w", err)
+		}
+		return domain.ApplyCreated, nil
+	}
+
+	changed := false
+	if u.Name != "" && user.Name != u.Name {
+		user.Name = u.Name
+		changed = true
+	}
+	if user.Role != u.Role {
+		user.Role = u.Role
+		changed = true
+	}
+	if u.Password != "" && !user.CheckPassword(u.Password) {
+		if err := user.SetPassword(u.Password); err != nil {
+			return "", err
+		}
+		changed = true
+	}
+	if !changed {
+		return domain.ApplyUnchanged, nil
+	}
+	user.UpdatedAt = time.Now()
+	if err := s.repo.User().Update(ctx, user); err != nil {
+		return "", fmt.Errorf("update user: %w", err)
+	}
+	return domain.ApplyUpdated, nil
+}
+
+func (s *ProvisionService) applyForm(ctx context.Context, f ProvisionForm) (domain.ApplyResult, error) {
+	lookupCtx, cancel := s.timeouts.bound(ctx, OpLookup)
+	defer cancel()
+
+	if f.Owner == "" {
+		return "", errors.New("owner is required")
+	}
+	owner, err := s.repo.User().GetByEmail(lookupCtx, strings.ToLower(strings.TrimSpace(f.Owner)))
+	if errors.Is(err, domain.ErrUserNotFound) || (err == nil && owner == nil) {
+		return "", fmt.Errorf("owner %q not found", f.Owner)
+	}
+	if err != nil {
+		return "", fmt.Errorf("lookup owner: %w", err)
+	}
+
+	_, result, err := s.forms.ApplyFormSpec(ctx, f.Slug, owner.ID, f.FormSpec)
+	return result, err
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"headless_form/internal/core/domain"
+	"headless_form/internal/core/ids"
+	"headless_form/internal/core/ports"
+)
+
+// rescoreBatchSize is how many submissions are read and re-scored per transaction
+const rescoreBatchSize = 500
+
+// SpamScorer scores a stored submission of form again from its data and the verdict it
+// was stored with (nil if it had none), using the form's current spam settings
+type SpamScorer func(ctx context.Context, form *domain.Form, data map[string]interface{}, previous *domain.SpamMeta) domain.SpamMeta
+
+// RescoreSpam scores the form's submissions created since then again with score, moving
+// them between the inbox and the quarantine as the new verdicts say. Submissions users
+// marked as spam or not spam keep their verdict. The outcome is recorded in the form's
+// audit log as done by userID. Meant to run as a background task reporting to p.
+func (s *SubmissionService) RescoreSpam(ctx context.Context, p *TaskProgress, form *domain.Form, since time.Time, userID string, score SpamScorer) (*domain.SpamRescoreResult, error) {
+	res := &domain.SpamRescoreResult{FormID: form.PublicID, Since: since}
+	err := s.rescore(ctx, p, form, score, res)
+
+	// Audit what was done, even when the task stopped partway
+	auditCtx, cancel := s.timeouts.bound(context.WithoutCancel(ctx), OpWrite)
+	defer cancel()
+	if auditErr := s.repo.Audit().Create(auditCtx, &domain.AuditEntry{
+		ID:     ids.New(ids.AuditEntry),
+		FormID: form.ID,
+		UserID: userID,
+		Action: domain.AuditActionSpamRescored,
+		Details: map[string]interface{}{
+			"since": since.Format(time.RFC3339), "scanned": res.Scanned, "rescored": res.Rescored,
+			"quarantined": res.Quarantined, "released": res.Released, "completed": err == nil,
+		},
+		CreatedAt: time.Now().UTC(),
+	}); auditErr != nil && err == nil {
+		err = fmt.Errorf("record audit entry: %w", auditErr)
+	}
+	return res, err
+}
+
+func (s *SubmissionService) rescore(ctx context.Context, p *TaskProgress, form *domain.Form, score SpamScorer, res *domain.SpamRescoreResult) error {
+	query := domain.SubmissionListQuery{From: res.Since}
+	// Newest first: submissions arriving meanwhile push the rest back, so none are missed
+	for offset := 0; ; offset += rescoreBatchSize {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		batch, counts, err := s.repo.Submission().SearchByFormID(ctx, form.ID, "", query, rescoreBatchSize, offset)
+		if err != nil {
+			return fmt.Errorf("list submissions: %w", err)
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+		err = s.repo.Tx(ctx, func(repo ports.Repository) error {
+			for _, sub := range batch {
+				res.Scanned++
+				previous := sub.Meta.Spam
+				if previous != nil && previous.Manual {
+					res.Skipped++
+					continue
+				}
+				var data map[string]interface{}
+				if json.Unmarshal(sub.Data, &data) != nil {
+					continue
+				}
+				next := score(ctx, form, data, previous)
+				wasSpam := sub.Status == domain.SubmissionStatusSpam
+				if !spamChanged(previous, next) && next.IsSpam == wasSpam {
+					continue
+				}
+				if err := repo.Submission().SetSpamVerdict(ctx, sub.ID, next); err != nil {
+					return fmt.Errorf("update submission %s: %w", sub.ID, err)
+				}
+				res.Rescored++
+				switch {
+				case next.IsSpam && !wasSpam:
+					res.Quarantined++
+				case !next.IsSpam && wasSpam:
+					res.Released++
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		p.Update(float64(res.Scanned)*100/float64(max(counts.Total, res.Scanned)),
+			fmt.Sprintf("%d/%d submissions, %d quarantined, %d released", res.Scanned, counts.Total, res.Quarantined, res.Released))
+	}
+}
+
+// spamChanged reports whether the new verdict differs from the stored one; a clean
+// verdict for a submission stored without one doesn't
+func spamChanged(previous *domain.SpamMeta, next domain.SpamMeta) bool {
+	if previous == nil {
+		return next.Score > 0 || len(next.Flags) > 0
+	}
+	return previous.Score != next.Score || previous.Threshold != next.Threshold || !slices.Equal(previous.Flags, next.Flags)
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"headless_form/internal/core/domain"
+	"headless_form/internal/core/ids"
+	"headless_form/internal/core/ports"
+)
+
+// retentionBatchSize is how many expired submissions are deleted or anonymized per transaction
+const retentionBatchSize = 500
+
+// ApplyRetention deletes or anonymizes, per each form's retention action, the submissions
+// older than the form's retention period. Forms without one keep their submissions
+// forever. Returns how many submissions were deleted and how many anonymized.
+func (s *SubmissionService) ApplyRetention(ctx context.Context) (deleted, anonymized int, err error) {
+	forms, err := s.repo.Form().ListWithRetention(ctx)
+	if err != nil {
+		return 0, 0, fmt.Errorf("list forms: %w", err)
+	}
+	now := time.Now()
+	for _, form := range forms {
+		cutoff, ok := form.RetentionCutoff(now)
+		if !ok {
+			continue
+		}
+		n, err := s.applyRetention(ctx, form, cutoff)
+		if form.RetentionAction == domain.RetentionAnonymize {
+			anonymized += n
+		} else {
+			deleted += n
+		}
+		if err != nil {
+			return deleted, anonymized, fmt.Errorf("form %s: %w", form.PublicID, err)
+		}
+	}
+	return deleted, anonymized, nil
+}
+
+// applyRetention deletes or anonymizes the form's submissions created before cutoff and
+// records how many in the form's audit log
+func (s *SubmissionService) applyRetention(ctx context.Context, form *domain.Form, cutoff time.Time) (int, error) {
+	var n int
+	var err error
+	for {
+		var expired []*domain.Submission
+		if expired, err = s.repo.Submission().ListExpired(ctx, form.ID, cutoff, retentionBatchSize); err != nil || len(expired) == 0 {
+			break
+		}
+		if form.RetentionAction == domain.RetentionAnonymize {
+			err = s.anonymizeExpired(ctx, form, expired)
+		} else {
+			err = s.deleteExpired(ctx, form, expired)
+		}
+		if err != nil {
+			break
+		}
+		n += len(expired)
+		if len(expired) < retentionBatchSize {
+			break
+		}
+	}
+	if n == 0 {
+		return 0, err
+	}
+
+	// Audit what was done, even when a batch failed
+	auditCtx, cancel := s.timeouts.bound(context.WithoutCancel(ctx), OpWrite)
+	defer cancel()
+	if auditErr := s.repo.Audit().Create(auditCtx, &domain.AuditEntry{
+		ID:     ids.New(ids.AuditEntry),
+		FormID: form.ID,
+		Action: domain.AuditActionRetentionApplied,
+		Details: map[string]interface{}{
+			"action": form.RetentionAction, "retention_days": form.RetentionDays,
+			"before": cutoff.UTC().Format(time.RFC3339), "submissions": n,
+		},
+		CreatedAt: time.Now().UTC(),
+	}); auditErr != nil && err == nil {
+		err = fmt.Errorf("record audit entry: %w", auditErr)
+	}
+	return n, err
+}
+
+// deleteExpired deletes a batch of expired submissions along with their files
+func (s *SubmissionService) deleteExpired(ctx context.Context, form *domain.Form, expired []*domain.Submission) error {
+	submissionIDs := make([]string, len(expired))
+	var files []domain.FileRef
+	tombstones := make([]domain.Tombstone, len(expired))
+	for i, submission := range expired {
+		submissionIDs[i] = submission.ID
+		files = append(files, submission.Meta.Files...)
+		tombstones[i] = submission.Tombstone()
+	}
+	if _, err := s.repo.Submission().DeleteBatch(ctx, form.ID, submissionIDs); err != nil {
+		return fmt.Errorf("delete submissions: %w", err)
+	}
+	s.DeleteFiles(ctx, form.ID, files)
+	s.notifyDeleted(form, domain.DeletionRetention, tombstones)
+	return nil
+}
+
+// anonymizeExpired anonymizes a batch of expired submissions, deleting their files
+func (s *SubmissionService) anonymizeExpired(ctx context.Context, form *domain.Form, expired []*domain.Submission) error {
+	var files []domain.FileRef
+	now := time.Now().UTC()
+	err := s.repo.Tx(ctx, func(repo ports.Repository) error {
+		for _, submission := range expired {
+			files = append(files, submission.Meta.Files...)
+			submission.Anonymize(now)
+			if err := repo.Submission().Anonymize(ctx, submission.ID, submission.Data, submission.Meta); err != nil {
+				return fmt.Errorf("anonymize submission %s: %w", submission.ID, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	s.DeleteFiles(ctx, form.ID, files)
+	return nil
+}
+
+// StartRetention runs ApplyRetention every interval until ctx is cancelled,
+// skipping runs while paused reports true (nil never pauses)
+func (s *SubmissionService) StartRetention(ctx context.Context, interval time.Duration, paused func() bool) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if paused != nil && paused() {
+					continue
+				}
+				deleted, anonymized, err := s.ApplyRetention(ctx)
+				if err != nil {
+					log.Printf("[SUBMISSIONS] Retention run failed: %v", err)
+				}
+				if deleted > 0 || anonymized > 0 {
+					log.Printf("[SUBMISSIONS] Retention deleted %d and anonymized %d submission(s)", deleted, anonymized)
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,350 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"headless_form/internal/core/domain"
+	"headless_form/internal/core/ids"
+	"headless_form/internal/core/ports"
+)
+
+// Sample data limits
+const (
+	DefaultSampleForms              = 3
+	DefaultSampleSubmissionsPerForm = 150
+	MaxSampleForms                  = 10 // Per user, counting the sample forms they already have
+	MaxSampleSubmissionsPerForm     = 500
+
+	// sampleMonths is how far back sample submissions go
+	sampleMonths = 6
+	// sampleSpamRate is the share of sample submissions that are spam
+	sampleSpamRate = 0.12
+)
+
+// StartSample validates the request and starts a task creating realistic sample forms
+// and submissions owned by ownerID, so they can explore stats and analytics before real
+// submissions arrive. Unlike seeded test data, sample submissions count in stats: they
+// are spread over the last months, come from several countries and include some spam.
+// The forms are marked as samples and removed together with PurgeSample.
+func (s *SeedService) StartSample(ctx context.Context, ownerID string, forms, submissionsPerForm int) (*domain.Task, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpList)
+	defer cancel()
+	if forms <= 0 {
+		forms = DefaultSampleForms
+	}
+	if submissionsPerForm <= 0 {
+		submissionsPerForm = DefaultSampleSubmissionsPerForm
+	}
+	_, existing, err := s.repo.Form().ListPaginated(ctx, domain.FormListQuery{OwnerID: ownerID, SampleOnly: true, IncludeArchived: true}, 1, 0)
+	if err != nil {
+		return nil, fmt.Errorf("count sample forms: %w", err)
+	}
+	if existing+forms > MaxSampleForms {
+		return nil, fmt.Errorf("%w: %d of %d exist", domain.ErrSampleDataLimit, existing, MaxSampleForms)
+	}
+
+	res := &SeedResult{
+		Forms:              forms,
+		SubmissionsPerForm: min(submissionsPerForm, MaxSampleSubmissionsPerForm),
+	}
+	gen := newSampleGenerator(time.Now())
+	return s.tasks.Start(domain.TaskTypeSampleData, ownerID, func(ctx context.Context, p *TaskProgress) (interface{}, error) {
+		return res, s.run(ctx, p, res, seedBuilder{
+			form: func(ctx context.Context, repo ports.Repository, i int) *domain.Form {
+				return gen.form(ctx, repo, ownerID, existing+i, res.SubmissionsPerForm)
+			},
+			submission: func(form *domain.Form, i, j int) *domain.Submission {
+				return gen.submission(form, existing+i)
+			},
+		})
+	})
+}
+
+// PurgeSample deletes ownerID's sample forms, including ones in the trash, along with
+// their submissions. Returns how many forms were deleted.
+func (s *SeedService) PurgeSample(ctx context.Context, ownerID string) (int, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpExport)
+	defer cancel()
+
+	purged := 0
+	deleteForms := func(forms []*domain.Form) error {
+		for _, form := range forms {
+			if !form.Sample || form.OwnerID != ownerID {
+				continue
+			}
+			if err := s.repo.Form().Delete(ctx, form.ID); err != nil {
+				return fmt.Errorf("delete form %s: %w", form.PublicID, err)
+			}
+			purged++
+		}
+		return nil
+	}
+
+	// Deleted forms leave the list, so the first page is read until it comes back empty
+	query := domain.FormListQuery{OwnerID: ownerID, SampleOnly: true, IncludeArchived: true}
+	for {
+		forms, _, err := s.repo.Form().ListPaginated(ctx, query, 100, 0)
+		if err != nil {
+			return purged, fmt.Errorf("list sample forms: %w", err)
+		}
+		if len(forms) == 0 {
+			break
+		}
+		if err := deleteForms(forms); err != nil {
+			return purged, err
+		}
+	}
+	trashed, err := s.repo.Form().ListDeleted(ctx, ownerID)
+	if err != nil {
+		return purged, fmt.Errorf("list deleted forms: %w", err)
+	}
+	return purged, deleteForms(trashed)
+}
+
+// sampleTemplate describes a kind of sample form and how its submitters fill it in
+type sampleTemplate struct {
+	name   string
+	fields func(g *sampleGenerator, p samplePerson) map[string]interface{}
+}
+
+// sampleCountry is where sample submitters come from, weighted by how many do
+type sampleCountry struct {
+	code, language string
+	weight         int
+}
+
+// samplePerson is a made-up submitter
+type samplePerson struct {
+	first, last, email string
+	country            sampleCountry
+}
+
+var (
+	sampleFirstNames = []string{"Olivia", "Liam", "Emma", "Noah", "Sofia", "Lucas", "Mia", "Mateo", "Hannah", "Felix",
+		"Chloe", "Hugo", "Priya", "Arjun", "Yuki", "Haruto", "Ana", "Pedro", "Fatima", "Omar", "Lena", "Jonas",
+		"Grace", "Daniel", "Sara", "Luca", "Amelia", "Ethan", "Chiara", "Ravi"}
+	sampleLastNames = []string{"Smith", "Johnson", "Brown", "Garcia", "Martin", "Muller", "Schmidt", "Rossi", "Silva",
+		"Santos", "Dubois", "Bernard", "Tanaka", "Sato", "Patel", "Sharma", "Nguyen", "Kowalski", "Jansen", "de Vries",
+		"Wilson", "Taylor", "Lopez", "Fischer", "Moreau"}
+	// sampleMailDomains are reserved example domains, so nobody gets mail by accident
+	sampleMailDomains = []string{"example.com", "example.org", "example.net", "mail.example", "inbox.example"}
+	sampleCountries   = []sampleCountry{
+		{"US", "en-US", 30}, {"GB", "en-GB", 12}, {"DE", "de-DE", 11}, {"FR", "fr-FR", 8}, {"CA", "en-CA", 7},
+		{"IN", "en-IN", 7}, {"BR", "pt-BR", 6}, {"ES", "es-ES", 5}, {"NL", "nl-NL", 5}, {"AU", "en-AU", 5},
+		{"JP", "ja-JP", 4},
+	}
+	sampleAgents = []struct{ agent, platform, mobile string }{
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36", `"Windows"`, "?0"},
+		{"Mozilla/5.0 (Macintosh; Intel Mac OS X 14_5) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.5 Safari/605.1.15", `"macOS"`, "?0"},
+		{"Mozilla/5.0 (X11; Linux x86_64; rv:127.0) Gecko/20100101 Firefox/127.0", `"Linux"`, "?0"},
+		{"Mozilla/5.0 (iPhone; CPU iPhone OS 17_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.5 Mobile/15E148 Safari/604.1", `"iOS"`, "?1"},
+		{"Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Mobile Safari/537.36", `"Android"`, "?1"},
+	}
+	sampleReferers = []string{"https://www.google.com/", "https://duckduckgo.com/", "https://www.linkedin.com/", "https://news.ycombinator.com/", ""}
+
+	sampleMessages = []string{
+		"Hi! Could you send me a quote for a team of 12?",
+		"Is there a discount for non-profits?",
+		"I can't find the invoice for last month, can you resend it?",
+		"We'd love to feature your product in our newsletter.",
+		"Your checkout page throws an error on Safari.",
+		"Do you ship to Canada, and how long does it take?",
+		"Can we schedule a demo next week?",
+		"Quick question about the API rate limits.",
+	}
+	sampleFeedback = []string{
+		"Setup was quick and painless.",
+		"Love it, but the mobile view needs work.",
+		"Support answered within the hour, impressive.",
+		"Pricing is a bit steep for small teams.",
+		"Exactly what we needed, thanks!",
+		"The export feature saved me hours.",
+	}
+	sampleSpam = []struct{ name, message string }{
+		{"SEO Expert", "Boost your rankings today! Visit https://seo-boost.spam.example and https://links.spam.example now"},
+		{"Crypto Deals", "Earn 500% returns in 7 days: https://coin.spam.example/join https://coin.spam.example/bonus"},
+		{"Best Pharmacy", "Cheap meds without prescription https://pills.spam.example"},
+		{"qwzxkjv", "asdkjh qwpeoi zmxncb lkjhg https://x.spam.example"},
+	}
+)
+
+// sampleTemplates are the kinds of sample forms, used in turn
+var sampleTemplates = []sampleTemplate{
+	{name: "Contact", fields: func(g *sampleGenerator, p samplePerson) map[string]interface{} {
+		return map[string]interface{}{
+			"name":    p.first + " " + p.last,
+			"email":   p.email,
+			"message": pick(g.rng, sampleMessages),
+		}
+	}},
+	{name: "Newsletter Signup", fields: func(g *sampleGenerator, p samplePerson) map[string]interface{} {
+		return map[string]interface{}{
+			"email":      p.email,
+			"first_name": p.first,
+		}
+	}},
+	{name: "Product Feedback", fields: func(g *sampleGenerator, p samplePerson) map[string]interface{} {
+		return map[string]interface{}{
+			"rating":   []int{2, 3, 4, 4, 5, 5}[g.rng.IntN(6)],
+			"feedback": pick(g.rng, sampleFeedback),
+			"email":    p.email,
+		}
+	}},
+	{name: "Event Registration", fields: func(g *sampleGenerator, p samplePerson) map[string]interface{} {
+		return map[string]interface{}{
+			"name":    p.first + " " + p.last,
+			"email":   p.email,
+			"tickets": 1 + g.rng.IntN(3),
+			"diet":    pick(g.rng, []string{"none", "none", "vegetarian", "vegan", "gluten-free"}),
+		}
+	}},
+	{name: "Job Application", fields: func(g *sampleGenerator, p samplePerson) map[string]interface{} {
+		return map[string]interface{}{
+			"name":     p.first + " " + p.last,
+			"email":    p.email,
+			"position": pick(g.rng, []string{"Frontend Engineer", "Product Designer", "Support Specialist", "Marketing Manager"}),
+			"country":  p.country.code,
+		}
+	}},
+}
+
+// sampleGenerator makes up sample forms and submissions. It's used by one task at a time.
+type sampleGenerator struct {
+	rng *rand.Rand
+	now time.Time
+}
+
+func newSampleGenerator(now time.Time) *sampleGenerator {
+	seed := uint64(now.UnixNano())
+	return &sampleGenerator{rng: rand.New(rand.NewPCG(seed, seed>>32)), now: now}
+}
+
+// form builds the i-th sample form of ownerID, created when its oldest submissions could arrive
+func (g *sampleGenerator) form(ctx context.Context, repo ports.Repository, ownerID string, i, submissions int) *domain.Form {
+	createdAt := g.now.AddDate(0, -sampleMonths, 0)
+	return &domain.Form{
+		ID:      ids.New(ids.Form),
+		OwnerID: ownerID,
+		PublicID: ids.Unique(ids.FormPublic, func(candidate string) bool {
+			existing, _ := repo.Form().GetByPublicID(ctx, candidate)
+			return existing != nil
+		}),
+		Name:            sampleTemplates[i%len(sampleTemplates)].name + " (sample)",
+		Status:          domain.FormStatusActive,
+		AllowedOrigins:  []string{"*"},
+		AccessMode:      string(domain.AccessModePublic),
+		NotifyEmails:    []string{},
+		Sample:          true,
+		SubmissionCount: submissions,
+		CreatedAt:       createdAt,
+		UpdatedAt:       createdAt,
+	}
+}
+
+// submission builds a submission to the i-th sample form: mostly genuine, some spam
+func (g *sampleGenerator) submission(form *domain.Form, i int) *domain.Submission {
+	createdAt := g.timestamp()
+	person := g.person()
+	agent := pick(g.rng, sampleAgents)
+
+	var data map[string]interface{}
+	spam := &domain.SpamMeta{Flags: []string{}, Threshold: domain.DefaultSpamThreshold}
+	status := domain.SubmissionStatusUnread
+	if g.now.Sub(createdAt) > 3*24*time.Hour || g.rng.IntN(3) == 0 {
+		status = domain.SubmissionStatusRead
+	}
+	if g.rng.Float64() < sampleSpamRate {
+		sample := pick(g.rng, sampleSpam)
+		data = map[string]interface{}{
+			"name":    sample.name,
+			"email":   fmt.Sprintf("%s%d@spam.example", strings.ToLower(strings.ReplaceAll(sample.name, " ", "")), g.rng.IntN(1000)),
+			"message": sample.message,
+		}
+		spam.Flags = append(spam.Flags, domain.SpamFlagLinks, domain.SpamFlagBlockedDomain+":spam.example")
+		if g.rng.IntN(2) == 0 {
+			spam.Flags = append(spam.Flags, domain.SpamFlagFast)
+		}
+		spam.Score = 60 + g.rng.IntN(41)
+		spam.IsSpam = true
+		status = domain.SubmissionStatusSpam
+	} else {
+		data = sampleTemplates[i%len(sampleTemplates)].fields(g, person)
+		if g.rng.IntN(10) == 0 {
+			spam.Flags = append(spam.Flags, domain.SpamFlagFast)
+			spam.Score = 20
+		}
+	}
+
+	encoded, _ := json.Marshal(data)
+	return &domain.Submission{
+		ID:     ids.New(ids.Submission),
+		FormID: form.ID,
+		Status: status,
+		Data:   encoded,
+		Meta: domain.SubmissionMeta{
+			Server: domain.ServerMeta{
+				IP:        fmt.Sprintf("198.51.100.%d", 1+g.rng.IntN(254)),
+				Timestamp: createdAt.UTC(),
+				UserAgent: agent.agent,
+				Language:  person.country.language,
+				IsMobile:  agent.mobile,
+				Platform:  agent.platform,
+				Referer:   pick(g.rng, sampleReferers),
+				Protocol:  "https",
+				Country:   person.country.code,
+			},
+			Client: map[string]interface{}{"source": "sample"},
+			Spam:   spam,
+		},
+		CreatedAt: createdAt,
+	}
+}
+
+// timestamp picks when a sample submission arrived: within the last sampleMonths, more
+// of them recently as if the form were gaining traction, and mostly during the day
+func (g *sampleGenerator) timestamp() time.Time {
+	span := g.now.Sub(g.now.AddDate(0, -sampleMonths, 0))
+	u := g.rng.Float64()
+	t := g.now.Add(-time.Duration(float64(span) * u * u))
+	if h := t.Hour(); h < 7 {
+		t = t.Add(time.Duration(7+g.rng.IntN(10)) * time.Hour)
+	}
+	if t.After(g.now) {
+		t = g.now.Add(-time.Duration(1+g.rng.IntN(60)) * time.Minute)
+	}
+	return t
+}
+
+// person makes up a submitter from a weighted random country
+func (g *sampleGenerator) person() samplePerson {
+	total := 0
+	for _, c := range sampleCountries {
+		total += c.weight
+	}
+	n := g.rng.IntN(total)
+	country := sampleCountries[0]
+	for _, c := range sampleCountries {
+		if n < c.weight {
+			country = c
+			break
+		}
+		n -= c.weight
+	}
+
+	first, last := pick(g.rng, sampleFirstNames), pick(g.rng, sampleLastNames)
+	local := strings.ToLower(first + "." + strings.ReplaceAll(last, " ", ""))
+	if g.rng.IntN(3) == 0 {
+		local += fmt.Sprint(g.rng.IntN(100))
+	}
+	return samplePerson{first: first, last: last, email: local + "@" + pick(g.rng, sampleMailDomains), country: country}
+}
+
+// pick returns a random element of items
+func pick[T any](rng *rand.Rand, items []T) T {
+	return items[rng.IntN(len(items))]
+}
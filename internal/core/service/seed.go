@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"headless_form/internal/core/domain"
+	"headless_form/internal/core/ids"
+	"headless_form/internal/core/ports"
+)
+
+// Seed limits
+const (
+	MaxSeedForms              = 10000
+	MaxSeedSubmissionsPerForm = 1000
+	// seedBatchSize is how many rows are inserted per transaction
+	seedBatchSize = 500
+)
+
+// SeedService creates test forms and submissions as a background task.
+// Only one seed task runs at a time.
+type SeedService struct {
+	repo      ports.Repository
+	tasks     *TaskService
+	batchSize int
+	timeouts  Timeouts
+}
+
+// SeedResult is the result of a seed task
+type SeedResult struct {
+	Forms              int `json:"forms"`
+	SubmissionsPerForm int `json:"submissions_per_form"`
+	FormsCreated       int `json:"forms_created"`
+	SubmissionsCreated int `json:"submissions_created"`
+}
+
+// NewSeedService creates a new seed service running its jobs through tasks
+func NewSeedService(repo ports.Repository, tasks *TaskService) *SeedService {
+	return &SeedService{
+		repo:      repo,
+		tasks:     tasks,
+		batchSize: seedBatchSize,
+		timeouts:  DefaultTimeouts,
+	}
+}
+
+// SetTimeouts overrides the per-operation timeouts (see DefaultTimeouts)
+func (s *SeedService) SetTimeouts(t Timeouts) {
+	s.timeouts = t
+}
+
+// seedBuilder makes the forms and submissions of a seed task
+type seedBuilder struct {
+	form       func(ctx context.Context, repo ports.Repository, i int) *domain.Form
+	submission func(form *domain.Form, i, j int) *domain.Submission
+}
+
+// Start validates the request and starts a seed task owned by ownerID. Counts below 1
+// are raised to 1; counts above MaxSeedForms or MaxSeedSubmissionsPerForm are refused
+// with ErrSeedLimit.
+func (s *SeedService) Start(ownerID string, forms, submissionsPerForm int) (*domain.Task, error) {
+	if forms > MaxSeedForms || submissionsPerForm > MaxSeedSubmissionsPerForm {
+		return nil, fmt.Errorf("%w: forms may be at most %d and submissions_per_form at most %d",
+			domain.ErrSeedLimit, MaxSeedForms, MaxSeedSubmissionsPerForm)
+	}
+	res := &SeedResult{
+		Forms:              max(forms, 1),
+		SubmissionsPerForm: max(submissionsPerForm, 1),
+	}
+	return s.tasks.StartExclusive(domain.TaskTypeSeed, ownerID, func(ctx context.Context, p *TaskProgress) (interface{}, error) {
+		return res, s.run(ctx, p, res, seedBuilder{
+			form: func(ctx context.Context, repo ports.Repository, i int) *domain.Form {
+				return seedForm(ctx, repo, ownerID, i)
+			},
+			submission: seedSubmission,
+		})
+	})
+}
+
+// run inserts the forms and their submissions made by build in transactions of at most
+// batchSize rows, stopping between batches once ctx is cancelled
+func (s *SeedService) run(ctx context.Context, p *TaskProgress, res *SeedResult, build seedBuilder) error {
+	total := res.Forms * res.SubmissionsPerForm
+	for i := 0; i < res.Forms; i++ {
+		var form *domain.Form
+		for start := 0; start < res.SubmissionsPerForm; start += s.batchSize {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			end := min(start+s.batchSize, res.SubmissionsPerForm)
+			err := s.repo.Tx(ctx, func(repo ports.Repository) error {
+				if form == nil {
+					form = build.form(ctx, repo, i)
+					if err := repo.Form().Create(ctx, form); err != nil {
+						return fmt.Errorf("create form: %w", err)
+					}
+				}
+				for j := start; j < end; j++ {
+					if err := repo.Submission().Create(ctx, build.submission(form, i, j)); err != nil {
+						return fmt.Errorf("create submission: %w", err)
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+
+			if start == 0 {
+				res.FormsCreated++
+			}
+			res.SubmissionsCreated += end - start
+			p.Update(float64(res.SubmissionsCreated)*100/float64(total),
+				fmt.Sprintf("%d/%d forms, %d/%d submissions", res.FormsCreated, res.Forms, res.SubmissionsCreated, total))
+		}
+	}
+	return nil
+}
+
+// seedForm builds the i-th test form
+func seedForm(ctx context.Context, repo ports.Repository, ownerID string, i int) *domain.Form {
+	now := time.Now()
+	return &domain.Form{
+		ID:      ids.New(ids.Form),
+		OwnerID: ownerID,
+		PublicID: ids.Unique(ids.FormPublic, func(candidate string) bool {
+			existing, _ := repo.Form().GetByPublicID(ctx, candidate)
+			return existing != nil
+		}),
+		Name:           "Test Form " + string(rune('A'+i%26)) + "-" + strconv.Itoa(i+1),
+		Status:         domain.FormStatusActive,
+		AllowedOrigins: []string{"*"},
+		AccessMode:     string(domain.AccessModePublic),
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+}
+
+// seedSubmission builds the j-th test submission of the i-th seeded form
+func seedSubmission(form *domain.Form, i, j int) *domain.Submission {
+	data, _ := json.Marshal(map[string]interface{}{
+		"name":    "User " + strconv.Itoa(j+1),
+		"email":   "user" + strconv.Itoa(j+1) + "@example.com",
+		"message": "Test message from user " + strconv.Itoa(j+1) + " on form " + strconv.Itoa(i+1),
+		"phone":   "555-" + strconv.Itoa(1000+j),
+	})
+	now := time.Now()
+	return &domain.Submission{
+		ID:     ids.New(ids.Submission),
+		FormID: form.ID,
+		Status: domain.SubmissionStatusUnread,
+		Data:   data,
+		Meta: domain.SubmissionMeta{
+			Server: domain.ServerMeta{UserAgent: "SeedBot/1.0", Timestamp: now.UTC()},
+			Client: map[string]interface{}{"source": "seed"},
+		},
+		IsTest:    true,
+		CreatedAt: now,
+	}
+}
@@ -2,28 +2,59 @@ package service
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 
 	"headless_form/internal/core/domain"
+	"headless_form/internal/core/ids"
 	"headless_form/internal/core/ports"
-
-	"github.com/google/uuid"
 )
 
 // FormService handles form-related business logic
 type FormService struct {
-	repo ports.Repository
+	repo     ports.Repository
+	timeouts Timeouts
+
+	// Two-step deletion (see deletion.go)
+	archiver       func(form *domain.Form, submissions []*domain.Submission) (string, error)
+	gracePeriod    time.Duration
+	pendingMu      sync.Mutex
+	pendingDeletes map[string]pendingDelete // public ID -> confirmation
+	files          ports.FileStorage        // Uploaded files, removed when the form is purged
 }
 
 func NewFormService(repo ports.Repository) *FormService {
-	return &FormService{repo: repo}
+	return &FormService{
+		repo:           repo,
+		timeouts:       DefaultTimeouts,
+		gracePeriod:    DefaultDeleteGracePeriod,
+		pendingDeletes: make(map[string]pendingDelete),
+	}
+}
+
+// SetTimeouts overrides the per-operation timeouts (see DefaultTimeouts)
+func (s *FormService) SetTimeouts(t Timeouts) {
+	s.timeouts = t
 }
 
 func (s *FormService) CreateForm(ctx context.Context, name, redirectURL string, notifyEmails []string, webhookURL, webhookSecret, ownerID, accessMode, submissionKey string) (*domain.Form, error) {
-	id := uuid.New().String()
-	publicID := uuid.New().String()
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
+	id := ids.New(ids.Form)
+	publicID := ids.Unique(ids.FormPublic, func(candidate string) bool {
+		existing, _ := s.repo.Form().GetByPublicID(ctx, candidate)
+		return existing != nil
+	})
 	now := time.Now()
 
 	// Set default access mode if not provided
@@ -32,21 +63,22 @@ func (s *FormService) CreateForm(ctx context.Context, name, redirectURL string,
 	}
 
 	form := &domain.Form{
-		ID:              id,
-		OwnerID:         ownerID,
-		PublicID:        publicID,
-		Name:            name,
-		Status:          domain.FormStatusActive,
-		NotifyEmails:    notifyEmails,
-		AllowedOrigins:  []string{"*"},
-		RedirectURL:     redirectURL,
-		WebhookURL:      webhookURL,
-		WebhookSecret:   webhookSecret,
-		AccessMode:      accessMode,
-		SubmissionKey:   submissionKey,
-		SubmissionCount: 0,
-		CreatedAt:       now,
-		UpdatedAt:       now,
+		ID:                        id,
+		OwnerID:                   ownerID,
+		PublicID:                  publicID,
+		Name:                      name,
+		Status:                    domain.FormStatusActive,
+		NotifyEmails:              notifyEmails,
+		AllowedOrigins:            []string{"*"},
+		RedirectURL:               redirectURL,
+		WebhookURL:                webhookURL,
+		WebhookSecret:             webhookSecret,
+		AccessMode:                accessMode,
+		WebhookSignatureAlgorithm: domain.SignatureSHA256,
+		SubmissionKey:             submissionKey,
+		SubmissionCount:           0,
+		CreatedAt:                 now,
+		UpdatedAt:                 now,
 	}
 
 	// Validate form
@@ -62,43 +94,116 @@ func (s *FormService) CreateForm(ctx context.Context, name, redirectURL string,
 }
 
 func (s *FormService) GetForm(ctx context.Context, publicID string) (*domain.Form, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpLookup)
+	defer cancel()
 	form, err := s.repo.Form().GetByPublicID(ctx, publicID)
 	if err != nil {
 		return nil, fmt.Errorf("get form: %w", err)
 	}
-	if form == nil {
+	if form == nil || form.IsDeleted() {
 		return nil, domain.ErrFormNotFound
 	}
+	form.SetSchedule()
 	return form, nil
 }
 
-func (s *FormService) ListForms(ctx context.Context) ([]*domain.Form, error) {
-	return s.repo.Form().List(ctx)
+// GetSubmitForm looks up the form a public submission URL points to. Within the grace
+// period after a rotation the old public ID still finds the form, and previous reports it.
+func (s *FormService) GetSubmitForm(ctx context.Context, publicID string) (form *domain.Form, previous bool, err error) {
+	form, err = s.GetForm(ctx, publicID)
+	if !errors.Is(err, domain.ErrFormNotFound) {
+		return form, false, err
+	}
+	ctx, cancel := s.timeouts.bound(ctx, OpLookup)
+	defer cancel()
+	rotated, lookupErr := s.repo.Form().GetByPreviousPublicID(ctx, publicID)
+	if lookupErr != nil {
+		return nil, false, fmt.Errorf("get form: %w", lookupErr)
+	}
+	if rotated == nil || rotated.IsDeleted() || !rotated.AcceptsPreviousPublicID(publicID, time.Now()) {
+		return nil, false, err
+	}
+	return rotated, true, nil
 }
 
-func (s *FormService) ListFormsPaginated(ctx context.Context, page, limit int) ([]*domain.Form, int, error) {
-	offset := (page - 1) * limit
-	return s.repo.Form().ListPaginated(ctx, limit, offset)
+// RotatePublicID gives the form a new public ID, for when the old one is abused. With a
+// grace period the old ID keeps accepting submissions until it ends; otherwise it stops
+// working at once. An earlier rotation's old ID is replaced either way.
+func (s *FormService) RotatePublicID(ctx context.Context, publicID string, grace time.Duration) (*domain.Form, error) {
+	if grace < 0 || grace > domain.MaxPublicIDGrace {
+		return nil, domain.ErrInvalidPublicIDGrace
+	}
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
+	form, err := s.repo.Form().GetByPublicID(ctx, publicID)
+	if err != nil {
+		return nil, fmt.Errorf("get form: %w", err)
+	}
+	if form == nil || form.IsDeleted() {
+		return nil, domain.ErrFormNotFound
+	}
+
+	now := time.Now()
+	form.PreviousPublicID, form.PreviousPublicIDUntil = "", nil
+	if grace > 0 {
+		until := now.Add(grace)
+		form.PreviousPublicID, form.PreviousPublicIDUntil = form.PublicID, &until
+	}
+	// New IDs mustn't match another form's old one either
+	form.PublicID = ids.Unique(ids.FormPublic, func(candidate string) bool {
+		existing, _ := s.repo.Form().GetByPublicID(ctx, candidate)
+		rotated, _ := s.repo.Form().GetByPreviousPublicID(ctx, candidate)
+		return existing != nil || rotated != nil
+	})
+	form.UpdatedAt = now
+	if err := s.repo.Form().Update(ctx, form); err != nil {
+		return nil, fmt.Errorf("update form: %w", err)
+	}
+	return form, nil
+}
+
+func (s *FormService) ListForms(ctx context.Context) ([]*domain.Form, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpList)
+	defer cancel()
+	return s.repo.Form().List(ctx)
 }
 
-func (s *FormService) ListFormsByOwnerPaginated(ctx context.Context, ownerID string, page, limit int) ([]*domain.Form, int, error) {
+// ListFormsPaginated returns one page of the forms matching query and the total number of matches
+func (s *FormService) ListFormsPaginated(ctx context.Context, query domain.FormListQuery, page, limit int) ([]*domain.Form, int, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpList)
+	defer cancel()
+	if err := query.Validate(); err != nil {
+		return nil, 0, err
+	}
 	offset := (page - 1) * limit
-	return s.repo.Form().ListByOwnerPaginated(ctx, ownerID, limit, offset)
+	forms, total, err := s.repo.Form().ListPaginated(ctx, query, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, f := range forms {
+		f.SetSchedule()
+	}
+	return forms, total, nil
 }
 
 // GetFormByID retrieves a form by its internal ID (not public_id)
 func (s *FormService) GetFormByID(ctx context.Context, id string) (*domain.Form, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpLookup)
+	defer cancel()
 	form, err := s.repo.Form().GetByID(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("get form by id: %w", err)
 	}
-	if form == nil {
+	if form == nil || form.IsDeleted() {
 		return nil, domain.ErrFormNotFound
 	}
 	return form, nil
 }
 
-func (s *FormService) UpdateForm(ctx context.Context, publicID string, name, redirectURL string, notifyEmails []string, status domain.FormStatus, webhookURL, webhookSecret, accessMode, submissionKey string) (*domain.Form, error) {
+// UpdateForm replaces a form's settings. attachPDF, publicStats and anonymous are left unchanged when nil.
+func (s *FormService) UpdateForm(ctx context.Context, publicID string, name, redirectURL string, notifyEmails []string, status domain.FormStatus, webhookURL, webhookSecret, accessMode, submissionKey string, attachPDF, publicStats, anonymous *bool) (*domain.Form, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
 	form, err := s.repo.Form().GetByPublicID(ctx, publicID)
 	if err != nil {
 		return nil, fmt.Errorf("get form: %w", err)
@@ -115,6 +220,15 @@ func (s *FormService) UpdateForm(ctx context.Context, publicID string, name, red
 	form.WebhookSecret = webhookSecret
 	form.AccessMode = accessMode
 	form.SubmissionKey = submissionKey
+	if attachPDF != nil {
+		form.AttachPDF = *attachPDF
+	}
+	if publicStats != nil {
+		form.PublicStats = *publicStats
+	}
+	if anonymous != nil {
+		form.Anonymous = *anonymous
+	}
 	form.UpdatedAt = time.Now()
 
 	if err := form.Validate(); err != nil {
@@ -128,165 +242,1764 @@ func (s *FormService) UpdateForm(ctx context.Context, publicID string, name, red
 	return form, nil
 }
 
-func (s *FormService) DeleteForm(ctx context.Context, publicID string) error {
+// SetWebhookTransform stores a form's webhook transformation script and whether it is applied.
+// The script must already have been checked by the caller (see the transform package).
+func (s *FormService) SetWebhookTransform(ctx context.Context, publicID, script string, enabled bool) (*domain.Form, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
 	form, err := s.repo.Form().GetByPublicID(ctx, publicID)
 	if err != nil {
-		return fmt.Errorf("get form: %w", err)
+		return nil, fmt.Errorf("get form: %w", err)
 	}
 	if form == nil {
-		return domain.ErrFormNotFound
+		return nil, domain.ErrFormNotFound
 	}
-
-	if err := s.repo.Form().Delete(ctx, form.ID); err != nil {
-		return fmt.Errorf("delete form: %w", err)
+	if enabled && strings.TrimSpace(script) == "" {
+		return nil, domain.ErrTransformRequired
 	}
 
-	return nil
+	form.WebhookTransform = script
+	form.WebhookTransformEnabled = enabled
+	form.UpdatedAt = time.Now()
+
+	if err := s.repo.Form().Update(ctx, form); err != nil {
+		return nil, fmt.Errorf("update form: %w", err)
+	}
+	return form, nil
 }
 
-// SubmissionService handles submission-related business logic
-type SubmissionService struct {
-	repo            ports.Repository
-	onNewSubmission func(form *domain.Form, submission *domain.Submission, data map[string]interface{})
+// GetFormBySlug retrieves a form by its slug, including forms in the trash (check IsDeleted)
+func (s *FormService) GetFormBySlug(ctx context.Context, slug string) (*domain.Form, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpLookup)
+	defer cancel()
+	if !domain.ValidSlug(slug) {
+		return nil, domain.ErrInvalidSlug
+	}
+	form, err := s.repo.Form().GetBySlug(ctx, slug)
+	if err != nil {
+		return nil, fmt.Errorf("get form by slug: %w", err)
+	}
+	if form == nil {
+		return nil, domain.ErrFormNotFound
+	}
+	return form, nil
 }
 
-func NewSubmissionService(repo ports.Repository) *SubmissionService {
-	return &SubmissionService{repo: repo}
+// ApplyFormSpec creates the form with the given slug from spec (owned by ownerID),
+// or brings the existing one in line with it. Applying the same spec twice leaves
+// the form untouched. Forms in the trash are not resurrected.
+func (s *FormService) ApplyFormSpec(ctx context.Context, slug, ownerID string, spec domain.FormSpec) (*domain.Form, domain.ApplyResult, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
+	if !domain.ValidSlug(slug) {
+		return nil, "", domain.ErrInvalidSlug
+	}
+	if err := spec.Normalize(); err != nil {
+		return nil, "", err
+	}
+
+	form, err := s.repo.Form().GetBySlug(ctx, slug)
+	if err != nil {
+		return nil, "", fmt.Errorf("get form by slug: %w", err)
+	}
+	if form != nil && form.IsDeleted() {
+		return nil, "", domain.ErrSlugInTrash
+	}
+
+	if form == nil {
+		now := time.Now()
+		form = &domain.Form{
+			ID:        ids.New(ids.Form),
+			OwnerID:   ownerID,
+			Slug:      slug,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		form.PublicID = ids.Unique(ids.FormPublic, func(candidate string) bool {
+			existing, _ := s.repo.Form().GetByPublicID(ctx, candidate)
+			return existing != nil
+		})
+		spec.Apply(form)
+		if err := s.repo.Form().Create(ctx, form); err != nil {
+			return nil, "", fmt.Errorf("create form: %w", err)
+		}
+		return form, domain.ApplyCreated, nil
+	}
+
+	if !spec.Apply(form) {
+		return form, domain.ApplyUnchanged, nil
+	}
+	form.UpdatedAt = time.Now()
+	if err := s.repo.Form().Update(ctx, form); err != nil {
+		return nil, "", fmt.Errorf("update form: %w", err)
+	}
+	return form, domain.ApplyUpdated, nil
 }
 
-// SetNotificationCallback sets a callback for new submissions (for email notifications)
-func (s *SubmissionService) SetNotificationCallback(fn func(form *domain.Form, submission *domain.Submission, data map[string]interface{})) {
-	s.onNewSubmission = fn
+// SetWebhookSigning sets the HMAC algorithm used to sign the form's webhooks and whether
+// the delivery timestamp is signed along with the body (signature over "timestamp.body").
+func (s *FormService) SetWebhookSigning(ctx context.Context, publicID, algorithm string, timestamped bool) (*domain.Form, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
+	if algorithm == "" {
+		algorithm = domain.SignatureSHA256
+	}
+	if !domain.ValidSignatureAlgorithm(algorithm) {
+		return nil, domain.ErrInvalidSignatureAlgorithm
+	}
+	form, err := s.repo.Form().GetByPublicID(ctx, publicID)
+	if err != nil {
+		return nil, fmt.Errorf("get form: %w", err)
+	}
+	if form == nil {
+		return nil, domain.ErrFormNotFound
+	}
+
+	form.WebhookSignatureAlgorithm = algorithm
+	form.WebhookSignTimestamp = timestamped
+	form.UpdatedAt = time.Now()
+
+	if err := s.repo.Form().Update(ctx, form); err != nil {
+		return nil, fmt.Errorf("update form: %w", err)
+	}
+	return form, nil
 }
 
-func (s *SubmissionService) Submit(ctx context.Context, publicID string, data map[string]interface{}, meta map[string]interface{}) (*domain.Submission, error) {
+// SetWebhookIncludeMeta sets whether the form's webhook payloads carry the submission's
+// request metadata (IP, country, referer, user agent) and spam verdict
+func (s *FormService) SetWebhookIncludeMeta(ctx context.Context, publicID string, include bool) (*domain.Form, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
 	form, err := s.repo.Form().GetByPublicID(ctx, publicID)
 	if err != nil {
-		return nil, fmt.Errorf("invalid form: %w", err)
+		return nil, fmt.Errorf("get form: %w", err)
 	}
 	if form == nil {
 		return nil, domain.ErrFormNotFound
 	}
 
-	// Check if form is active
-	if form.Status != domain.FormStatusActive {
-		return nil, fmt.Errorf("form is not accepting submissions")
+	form.WebhookIncludeMeta = include
+	form.UpdatedAt = time.Now()
+
+	if err := s.repo.Form().Update(ctx, form); err != nil {
+		return nil, fmt.Errorf("update form: %w", err)
 	}
+	return form, nil
+}
 
-	// Access control validation based on form's access mode
-	switch form.AccessMode {
-	case string(domain.AccessModeWithKey):
-		// Validate submission key from hidden field
-		submittedKey, _ := data["_submission_key"].(string)
-		if submittedKey == "" || submittedKey != form.SubmissionKey {
-			return nil, domain.ErrInvalidSubmissionKey
-		}
-		// Remove the key from data so it's not stored
-		delete(data, "_submission_key")
-	case string(domain.AccessModePrivate):
-		// For private forms, we need to check if request has auth context
-		// This is passed via meta from the handler
-		if authUserID, ok := meta["_auth_user_id"].(string); !ok || authUserID == "" {
-			return nil, domain.ErrAuthRequired
+// SetWebhookRate sets how fast the form's webhook may be called and whether new
+// submissions are sent to it in batches. A nil config sends each event right away again.
+func (s *FormService) SetWebhookRate(ctx context.Context, publicID string, rate *domain.WebhookRate) (*domain.Form, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
+	if rate != nil {
+		if err := rate.Validate(); err != nil {
+			return nil, err
 		}
-		// Remove internal auth field from meta
-		delete(meta, "_auth_user_id")
-		// case "public" or empty - no validation needed
+	}
+	form, err := s.repo.Form().GetByPublicID(ctx, publicID)
+	if err != nil {
+		return nil, fmt.Errorf("get form: %w", err)
+	}
+	if form == nil {
+		return nil, domain.ErrFormNotFound
 	}
 
-	dataBytes, _ := json.Marshal(data)
-	metaBytes, _ := json.Marshal(meta)
+	form.WebhookRate = rate
+	form.UpdatedAt = time.Now()
 
-	submission := &domain.Submission{
-		ID:        uuid.New().String(),
-		FormID:    form.ID,
-		Status:    domain.SubmissionStatusUnread,
-		Data:      json.RawMessage(dataBytes),
-		Meta:      json.RawMessage(metaBytes),
-		CreatedAt: time.Now(),
+	if err := s.repo.Form().Update(ctx, form); err != nil {
+		return nil, fmt.Errorf("update form: %w", err)
 	}
+	return form, nil
+}
 
-	if err := s.repo.Submission().Create(ctx, submission); err != nil {
-		return nil, fmt.Errorf("save submission: %w", err)
+// SetSchedule sets when the form opens and closes to submissions. Either may be nil for
+// no limit on that side; both nil removes the schedule.
+func (s *FormService) SetSchedule(ctx context.Context, publicID string, opensAt, closesAt *time.Time) (*domain.Form, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
+	opensAt, closesAt, err := domain.NormalizeSchedule(opensAt, closesAt)
+	if err != nil {
+		return nil, err
+	}
+	form, err := s.repo.Form().GetByPublicID(ctx, publicID)
+	if err != nil {
+		return nil, fmt.Errorf("get form: %w", err)
+	}
+	if form == nil {
+		return nil, domain.ErrFormNotFound
 	}
 
-	// Increment submission count
-	_ = s.repo.Form().IncrementSubmissionCount(ctx, form.ID)
+	form.OpensAt, form.ClosesAt = opensAt, closesAt
+	form.UpdatedAt = time.Now()
 
-	// Trigger email notification (async, don't block submission)
-	if s.onNewSubmission != nil {
-		go s.onNewSubmission(form, submission, data)
+	if err := s.repo.Form().Update(ctx, form); err != nil {
+		return nil, fmt.Errorf("update form: %w", err)
 	}
-
-	return submission, nil
+	form.SetSchedule()
+	return form, nil
 }
 
-func (s *SubmissionService) ListSubmissions(ctx context.Context, publicID string) ([]*domain.Submission, error) {
+// SetConsent sets the checkboxes submissions must tick and the consent text version.
+// A nil config removes the requirement.
+func (s *FormService) SetConsent(ctx context.Context, publicID string, consent *domain.ConsentConfig) (*domain.Form, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
 	form, err := s.repo.Form().GetByPublicID(ctx, publicID)
 	if err != nil {
-		return nil, fmt.Errorf("lookup form: %w", err)
+		return nil, fmt.Errorf("get form: %w", err)
 	}
 	if form == nil {
 		return nil, domain.ErrFormNotFound
 	}
+	if consent != nil {
+		if err := consent.Validate(); err != nil {
+			return nil, err
+		}
+	}
 
-	return s.repo.Submission().GetByFormID(ctx, form.ID)
+	form.Consent = consent
+	form.UpdatedAt = time.Now()
+
+	if err := s.repo.Form().Update(ctx, form); err != nil {
+		return nil, fmt.Errorf("update form: %w", err)
+	}
+	return form, nil
 }
 
-func (s *SubmissionService) ListSubmissionsPaginated(ctx context.Context, publicID string, page, limit int) ([]*domain.Submission, int, error) {
+// SetUploads lets the form accept files with its submissions. A nil config
+// rejects files again; files already uploaded are kept.
+func (s *FormService) SetUploads(ctx context.Context, publicID string, uploads *domain.UploadConfig) (*domain.Form, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
 	form, err := s.repo.Form().GetByPublicID(ctx, publicID)
 	if err != nil {
-		return nil, 0, fmt.Errorf("lookup form: %w", err)
+		return nil, fmt.Errorf("get form: %w", err)
 	}
 	if form == nil {
-		return nil, 0, domain.ErrFormNotFound
+		return nil, domain.ErrFormNotFound
+	}
+	if uploads != nil {
+		if err := uploads.Validate(); err != nil {
+			return nil, err
+		}
 	}
 
-	offset := (page - 1) * limit
-	return s.repo.Submission().GetByFormIDPaginated(ctx, form.ID, limit, offset)
-}
+	form.Uploads = uploads
+	form.UpdatedAt = time.Now()
 
-func (s *SubmissionService) MarkAsRead(ctx context.Context, submissionID string) error {
-	return s.repo.Submission().UpdateStatus(ctx, submissionID, domain.SubmissionStatusRead)
+	if err := s.repo.Form().Update(ctx, form); err != nil {
+		return nil, fmt.Errorf("update form: %w", err)
+	}
+	return form, nil
 }
 
-func (s *SubmissionService) MarkAsUnread(ctx context.Context, submissionID string) error {
-	return s.repo.Submission().UpdateStatus(ctx, submissionID, domain.SubmissionStatusUnread)
+// SetSubmitResponse sets how successful submissions are answered (envelope and status
+// code). A nil config restores the default enveloped 201 Created.
+func (s *FormService) SetSubmitResponse(ctx context.Context, publicID string, cfg *domain.SubmitResponseConfig) (*domain.Form, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
+	if cfg != nil {
+		if err := cfg.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	form, err := s.repo.Form().GetByPublicID(ctx, publicID)
+	if err != nil {
+		return nil, fmt.Errorf("get form: %w", err)
+	}
+	if form == nil {
+		return nil, domain.ErrFormNotFound
+	}
+
+	form.SubmitResponse = cfg
+	form.UpdatedAt = time.Now()
+
+	if err := s.repo.Form().Update(ctx, form); err != nil {
+		return nil, fmt.Errorf("update form: %w", err)
+	}
+	return form, nil
 }
 
-func (s *SubmissionService) DeleteSubmission(ctx context.Context, submissionID string) error {
-	return s.repo.Submission().Delete(ctx, submissionID)
+// SetSpam sets the form's spam check settings. A nil config restores the defaults.
+func (s *FormService) SetSpam(ctx context.Context, publicID string, cfg *domain.SpamConfig) (*domain.Form, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
+	if cfg != nil {
+		if err := cfg.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	form, err := s.repo.Form().GetByPublicID(ctx, publicID)
+	if err != nil {
+		return nil, fmt.Errorf("get form: %w", err)
+	}
+	if form == nil {
+		return nil, domain.ErrFormNotFound
+	}
+
+	form.Spam = cfg
+	form.UpdatedAt = time.Now()
+
+	if err := s.repo.Form().Update(ctx, form); err != nil {
+		return nil, fmt.Errorf("update form: %w", err)
+	}
+	return form, nil
 }
 
-// GetSubmission retrieves a single submission by ID
-func (s *SubmissionService) GetSubmission(ctx context.Context, submissionID string) (*domain.Submission, error) {
-	submission, err := s.repo.Submission().GetByID(ctx, submissionID)
+// SetCaptcha makes the form require a captcha token verified with the provider, or
+// stops requiring one when cfg is nil
+func (s *FormService) SetCaptcha(ctx context.Context, publicID string, cfg *domain.CaptchaConfig) (*domain.Form, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
+	if cfg != nil {
+		if err := cfg.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	form, err := s.repo.Form().GetByPublicID(ctx, publicID)
 	if err != nil {
-		return nil, fmt.Errorf("get submission: %w", err)
+		return nil, fmt.Errorf("get form: %w", err)
 	}
-	if submission == nil {
-		return nil, domain.ErrSubmissionNotFound
+	if form == nil {
+		return nil, domain.ErrFormNotFound
 	}
-	return submission, nil
+
+	form.Captcha = cfg
+	form.UpdatedAt = time.Now()
+
+	if err := s.repo.Form().Update(ctx, form); err != nil {
+		return nil, fmt.Errorf("update form: %w", err)
+	}
+	return form, nil
 }
 
-// StatsService handles statistics business logic
-type StatsService struct {
-	repo ports.Repository
+// PrepareFilters validates content filters and gives the new ones an ID
+func PrepareFilters(rules []domain.FilterRule) error {
+	if err := domain.ValidateFilters(rules); err != nil {
+		return err
+	}
+	for i := range rules {
+		if rules[i].ID == "" {
+			rules[i].ID = ids.New(ids.Filter)
+		}
+	}
+	return nil
 }
 
-func NewStatsService(repo ports.Repository) *StatsService {
-	return &StatsService{repo: repo}
+// SetFilters replaces the form's content filters
+func (s *FormService) SetFilters(ctx context.Context, publicID string, rules []domain.FilterRule) (*domain.Form, error) {
+	if err := PrepareFilters(rules); err != nil {
+		return nil, err
+	}
+	return s.updateFilters(ctx, publicID, func([]domain.FilterRule) ([]domain.FilterRule, error) {
+		return rules, nil
+	})
 }
 
-func (s *StatsService) GetDashboardStats(ctx context.Context) (*domain.DashboardStats, error) {
-	return s.repo.Stats().GetDashboardStats(ctx)
+// AddFilter adds a content filter to the form and returns it with its ID
+func (s *FormService) AddFilter(ctx context.Context, publicID string, rule domain.FilterRule) (*domain.FilterRule, error) {
+	rule.ID = ""
+	rules := []domain.FilterRule{rule}
+	if err := PrepareFilters(rules); err != nil {
+		return nil, err
+	}
+	_, err := s.updateFilters(ctx, publicID, func(current []domain.FilterRule) ([]domain.FilterRule, error) {
+		if len(current) >= domain.MaxFilters {
+			return nil, fmt.Errorf("%w: at most %d filters", domain.ErrInvalidFilter, domain.MaxFilters)
+		}
+		return append(current, rules[0]), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &rules[0], nil
 }
 
-func (s *StatsService) GetFormStats(ctx context.Context, publicID string) (*domain.FormStats, error) {
-	form, err := s.repo.Form().GetByPublicID(ctx, publicID)
-	if err != nil || form == nil {
+// DeleteFilter removes one of the form's content filters
+func (s *FormService) DeleteFilter(ctx context.Context, publicID, filterID string) (*domain.Form, error) {
+	return s.updateFilters(ctx, publicID, func(current []domain.FilterRule) ([]domain.FilterRule, error) {
+		for i, rule := range current {
+			if rule.ID == filterID {
+				return slices.Delete(current, i, i+1), nil
+			}
+		}
+		return nil, domain.ErrFilterNotFound
+	})
+}
+
+// updateFilters sets the form's content filters to what change makes of the current ones
+func (s *FormService) updateFilters(ctx context.Context, publicID string, change func([]domain.FilterRule) ([]domain.FilterRule, error)) (*domain.Form, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
+	form, err := s.repo.Form().GetByPublicID(ctx, publicID)
+	if err != nil {
+		return nil, fmt.Errorf("get form: %w", err)
+	}
+	if form == nil {
+		return nil, domain.ErrFormNotFound
+	}
+
+	rules, err := change(form.Filters)
+	if err != nil {
+		return nil, err
+	}
+	form.Filters = rules
+	form.UpdatedAt = time.Now()
+
+	if err := s.repo.Form().Update(ctx, form); err != nil {
+		return nil, fmt.Errorf("update form: %w", err)
+	}
+	return form, nil
+}
+
+// SetEmailTemplate replaces parts of the form's notification email, or goes back to the
+// built-in one when t is nil. The template is checked by the caller (see the email package).
+func (s *FormService) SetEmailTemplate(ctx context.Context, publicID string, t *domain.EmailTemplate) (*domain.Form, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
+	if t != nil {
+		if err := t.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	form, err := s.repo.Form().GetByPublicID(ctx, publicID)
+	if err != nil {
+		return nil, fmt.Errorf("get form: %w", err)
+	}
+	if form == nil {
+		return nil, domain.ErrFormNotFound
+	}
+
+	form.EmailTemplate = t
+	form.UpdatedAt = time.Now()
+
+	if err := s.repo.Form().Update(ctx, form); err != nil {
+		return nil, fmt.Errorf("update form: %w", err)
+	}
+	return form, nil
+}
+
+// SetAutoReply sets the confirmation emailed to submitters, or stops sending it when c
+// is nil. The templates are checked by the caller (see the email package).
+func (s *FormService) SetAutoReply(ctx context.Context, publicID string, c *domain.AutoReplyConfig) (*domain.Form, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
+	if c != nil {
+		if err := c.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	form, err := s.repo.Form().GetByPublicID(ctx, publicID)
+	if err != nil {
+		return nil, fmt.Errorf("get form: %w", err)
+	}
+	if form == nil {
+		return nil, domain.ErrFormNotFound
+	}
+
+	form.AutoReply = c
+	form.UpdatedAt = time.Now()
+
+	if err := s.repo.Form().Update(ctx, form); err != nil {
+		return nil, fmt.Errorf("update form: %w", err)
+	}
+	return form, nil
+}
+
+// SetErrorPage sets where rejected browser form posts are sent, or the message of the
+// hosted error page. A nil config shows the hosted page with each reason's own message.
+func (s *FormService) SetErrorPage(ctx context.Context, publicID string, c *domain.ErrorPageConfig) (*domain.Form, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
+	if c != nil {
+		if err := c.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	form, err := s.repo.Form().GetByPublicID(ctx, publicID)
+	if err != nil {
+		return nil, fmt.Errorf("get form: %w", err)
+	}
+	if form == nil {
+		return nil, domain.ErrFormNotFound
+	}
+
+	form.ErrorPage = c
+	form.UpdatedAt = time.Now()
+
+	if err := s.repo.Form().Update(ctx, form); err != nil {
+		return nil, fmt.Errorf("update form: %w", err)
+	}
+	return form, nil
+}
+
+// SetChannels sets the Slack and Discord channels new submissions are posted to, or
+// stops posting when channels is empty
+func (s *FormService) SetChannels(ctx context.Context, publicID string, channels []domain.NotifyChannel) (*domain.Form, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
+	if err := domain.ValidateChannels(channels); err != nil {
+		return nil, err
+	}
+	form, err := s.repo.Form().GetByPublicID(ctx, publicID)
+	if err != nil {
+		return nil, fmt.Errorf("get form: %w", err)
+	}
+	if form == nil {
+		return nil, domain.ErrFormNotFound
+	}
+
+	form.Channels = channels
+	form.UpdatedAt = time.Now()
+
+	if err := s.repo.Form().Update(ctx, form); err != nil {
+		return nil, fmt.Errorf("update form: %w", err)
+	}
+	return form, nil
+}
+
+// SetNotifyRules sets who is emailed about which submissions. With no rules the form's
+// notify list gets every submission again.
+func (s *FormService) SetNotifyRules(ctx context.Context, publicID string, rules []domain.NotifyRule) (*domain.Form, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
+	if err := domain.ValidateNotifyRules(rules); err != nil {
+		return nil, err
+	}
+	form, err := s.repo.Form().GetByPublicID(ctx, publicID)
+	if err != nil {
+		return nil, fmt.Errorf("get form: %w", err)
+	}
+	if form == nil {
+		return nil, domain.ErrFormNotFound
+	}
+
+	form.NotifyRules = rules
+	form.UpdatedAt = time.Now()
+
+	if err := s.repo.Form().Update(ctx, form); err != nil {
+		return nil, fmt.Errorf("update form: %w", err)
+	}
+	return form, nil
+}
+
+// SetNormalize makes the form clean up submitted text before it's stored, or keep it as
+// sent when cfg is nil
+func (s *FormService) SetNormalize(ctx context.Context, publicID string, cfg *domain.NormalizeConfig) (*domain.Form, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
+	if cfg != nil {
+		if err := cfg.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	form, err := s.repo.Form().GetByPublicID(ctx, publicID)
+	if err != nil {
+		return nil, fmt.Errorf("get form: %w", err)
+	}
+	if form == nil {
+		return nil, domain.ErrFormNotFound
+	}
+
+	form.Normalize = cfg
+	form.UpdatedAt = time.Now()
+
+	if err := s.repo.Form().Update(ctx, form); err != nil {
+		return nil, fmt.Errorf("update form: %w", err)
+	}
+	return form, nil
+}
+
+// SetFrameAncestors sets the origins allowed to embed the form's public responses
+// in an iframe. An empty list denies framing again.
+func (s *FormService) SetFrameAncestors(ctx context.Context, publicID string, ancestors []string) (*domain.Form, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
+	ancestors, err := domain.NormalizeFrameAncestors(ancestors)
+	if err != nil {
+		return nil, err
+	}
+	form, err := s.repo.Form().GetByPublicID(ctx, publicID)
+	if err != nil {
+		return nil, fmt.Errorf("get form: %w", err)
+	}
+	if form == nil {
+		return nil, domain.ErrFormNotFound
+	}
+
+	form.FrameAncestors = ancestors
+	form.UpdatedAt = time.Now()
+
+	if err := s.repo.Form().Update(ctx, form); err != nil {
+		return nil, fmt.Errorf("update form: %w", err)
+	}
+	return form, nil
+}
+
+// SetFields replaces the form's field schema (labels, placeholders, help text and their
+// translations, and validation rules)
+func (s *FormService) SetFields(ctx context.Context, publicID string, fields []domain.FormField) (*domain.Form, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
+	fields, err := domain.NormalizeFields(fields)
+	if err != nil {
+		return nil, err
+	}
+	form, err := s.repo.Form().GetByPublicID(ctx, publicID)
+	if err != nil {
+		return nil, fmt.Errorf("get form: %w", err)
+	}
+	if form == nil {
+		return nil, domain.ErrFormNotFound
+	}
+
+	form.Fields = fields
+	form.UpdatedAt = time.Now()
+
+	if err := s.repo.Form().Update(ctx, form); err != nil {
+		return nil, fmt.Errorf("update form: %w", err)
+	}
+	return form, nil
+}
+
+// SetTimezone sets the IANA time zone the form's stats and exports use; an empty
+// name falls back to the owner's timezone
+func (s *FormService) SetTimezone(ctx context.Context, publicID, timezone string) (*domain.Form, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
+	timezone, err := domain.NormalizeTimezone(timezone)
+	if err != nil {
+		return nil, err
+	}
+	form, err := s.repo.Form().GetByPublicID(ctx, publicID)
+	if err != nil {
+		return nil, fmt.Errorf("get form: %w", err)
+	}
+	if form == nil {
+		return nil, domain.ErrFormNotFound
+	}
+
+	form.Timezone = timezone
+	form.UpdatedAt = time.Now()
+
+	if err := s.repo.Form().Update(ctx, form); err != nil {
+		return nil, fmt.Errorf("update form: %w", err)
+	}
+	return form, nil
+}
+
+// SetRetention sets the age in days at which the form's submissions are deleted or
+// anonymized, per action; 0 days keeps them forever
+func (s *FormService) SetRetention(ctx context.Context, publicID string, days int, action string) (*domain.Form, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
+	action, err := domain.NormalizeRetention(days, action)
+	if err != nil {
+		return nil, err
+	}
+	form, err := s.repo.Form().GetByPublicID(ctx, publicID)
+	if err != nil {
+		return nil, fmt.Errorf("get form: %w", err)
+	}
+	if form == nil {
+		return nil, domain.ErrFormNotFound
+	}
+
+	form.RetentionDays = days
+	form.RetentionAction = action
+	form.UpdatedAt = time.Now()
+
+	if err := s.repo.Form().Update(ctx, form); err != nil {
+		return nil, fmt.Errorf("update form: %w", err)
+	}
+	return form, nil
+}
+
+// SetEmailFieldLimit sets how many characters of each field value the form's notification
+// emails show; 0 takes domain.DefaultEmailFieldLimit
+func (s *FormService) SetEmailFieldLimit(ctx context.Context, publicID string, limit int) (*domain.Form, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
+	if err := domain.ValidateEmailFieldLimit(limit); err != nil {
+		return nil, err
+	}
+	form, err := s.repo.Form().GetByPublicID(ctx, publicID)
+	if err != nil {
+		return nil, fmt.Errorf("get form: %w", err)
+	}
+	if form == nil {
+		return nil, domain.ErrFormNotFound
+	}
+
+	form.EmailFieldLimit = limit
+	form.UpdatedAt = time.Now()
+
+	if err := s.repo.Form().Update(ctx, form); err != nil {
+		return nil, fmt.Errorf("update form: %w", err)
+	}
+	return form, nil
+}
+
+// SetHiddenFields sets the fields merged into every submission of the form server-side,
+// over what the client sent. An empty map removes them.
+func (s *FormService) SetHiddenFields(ctx context.Context, publicID string, fields map[string]string) (*domain.Form, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
+	fields, err := domain.NormalizeHiddenFields(fields)
+	if err != nil {
+		return nil, err
+	}
+	form, err := s.repo.Form().GetByPublicID(ctx, publicID)
+	if err != nil {
+		return nil, fmt.Errorf("get form: %w", err)
+	}
+	if form == nil {
+		return nil, domain.ErrFormNotFound
+	}
+
+	form.HiddenFields = fields
+	form.UpdatedAt = time.Now()
+
+	if err := s.repo.Form().Update(ctx, form); err != nil {
+		return nil, fmt.Errorf("update form: %w", err)
+	}
+	return form, nil
+}
+
+// Location returns the time zone the form's stats and exports use: the form's
+// own, else its owner's, else UTC
+func (s *FormService) Location(ctx context.Context, form *domain.Form) *time.Location {
+	ctx, cancel := s.timeouts.bound(ctx, OpLookup)
+	defer cancel()
+	return formLocation(ctx, s.repo, form)
+}
+
+func formLocation(ctx context.Context, repo ports.Repository, form *domain.Form) *time.Location {
+	if form.Timezone != "" || form.OwnerID == "" {
+		return domain.LoadTimezone(form.Timezone)
+	}
+	owner, err := repo.User().GetByID(ctx, form.OwnerID)
+	if err != nil || owner == nil {
+		return time.UTC
+	}
+	return domain.LoadTimezone(owner.Timezone)
+}
+
+// SetStarred stars or unstars a form for userID and returns the form with Starred set accordingly
+func (s *FormService) SetStarred(ctx context.Context, publicID, userID string, starred bool) (*domain.Form, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
+	form, err := s.repo.Form().GetByPublicID(ctx, publicID)
+	if err != nil {
+		return nil, fmt.Errorf("get form: %w", err)
+	}
+	if form == nil || form.IsDeleted() {
+		return nil, domain.ErrFormNotFound
+	}
+
+	if starred {
+		err = s.repo.Form().Star(ctx, userID, form.ID)
+	} else {
+		err = s.repo.Form().Unstar(ctx, userID, form.ID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("set form star: %w", err)
+	}
+	form.Starred = starred
+	return form, nil
+}
+
+// SetArchived archives the form, closing it to submissions and hiding it from form lists
+// while keeping its submissions, or makes an archived form active again
+func (s *FormService) SetArchived(ctx context.Context, publicID string, archived bool) (*domain.Form, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
+	form, err := s.repo.Form().GetByPublicID(ctx, publicID)
+	if err != nil {
+		return nil, fmt.Errorf("get form: %w", err)
+	}
+	if form == nil || form.IsDeleted() {
+		return nil, domain.ErrFormNotFound
+	}
+
+	switch {
+	case archived:
+		form.Status = domain.FormStatusArchived
+	case form.Status == domain.FormStatusArchived:
+		form.Status = domain.FormStatusActive
+	default:
+		return form, nil
+	}
+	form.UpdatedAt = time.Now()
+
+	if err := s.repo.Form().Update(ctx, form); err != nil {
+		return nil, fmt.Errorf("update form: %w", err)
+	}
+	return form, nil
+}
+
+func (s *FormService) DeleteForm(ctx context.Context, publicID string) error {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
+	form, err := s.repo.Form().GetByPublicID(ctx, publicID)
+	if err != nil {
+		return fmt.Errorf("get form: %w", err)
+	}
+	if form == nil {
+		return domain.ErrFormNotFound
+	}
+
+	if err := s.repo.Form().Delete(ctx, form.ID); err != nil {
+		return fmt.Errorf("delete form: %w", err)
+	}
+
+	return nil
+}
+
+// SubmissionService handles submission-related business logic
+type SubmissionService struct {
+	repo            ports.Repository
+	timeouts        Timeouts
+	onNewSubmission func(form *domain.Form, submission *domain.Submission, data map[string]interface{})
+	onDeleted       func(form *domain.Form, reason domain.DeletionReason, tombstones []domain.Tombstone)
+	onSpamMarked    func(ctx context.Context, submissions []*domain.Submission, spam bool)
+	testRetention   time.Duration
+	files           ports.FileStorage
+	feed            *SubmissionFeed
+	exportHashKey   []byte
+
+	// Queue-and-forward while the database is down (see SetQueue)
+	queue     ports.SubmissionQueue
+	formsMu   sync.RWMutex
+	lastForms map[string]*domain.Form // By public ID, for validating submissions during an outage
+}
+
+// DefaultTestRetention is how long test submissions are kept before they're purged
+const DefaultTestRetention = 7 * 24 * time.Hour
+
+func NewSubmissionService(repo ports.Repository) *SubmissionService {
+	return &SubmissionService{repo: repo, timeouts: DefaultTimeouts, testRetention: DefaultTestRetention}
+}
+
+// SetTimeouts overrides the per-operation timeouts (see DefaultTimeouts)
+func (s *SubmissionService) SetTimeouts(t Timeouts) {
+	s.timeouts = t
+}
+
+// SetTestRetention sets how long test submissions are kept before they're purged
+func (s *SubmissionService) SetTestRetention(d time.Duration) {
+	if d > 0 {
+		s.testRetention = d
+	}
+}
+
+// SetDeletionCallback sets a callback run with the tombstones of deleted submissions,
+// grouped by form, e.g. to tell webhooks mirroring the data to delete their copies
+func (s *SubmissionService) SetDeletionCallback(fn func(form *domain.Form, reason domain.DeletionReason, tombstones []domain.Tombstone)) {
+	s.onDeleted = fn
+}
+
+// notifyDeleted runs the deletion callback, if set, for deleted submissions of form
+func (s *SubmissionService) notifyDeleted(form *domain.Form, reason domain.DeletionReason, tombstones []domain.Tombstone) {
+	if s.onDeleted != nil && len(tombstones) > 0 {
+		s.onDeleted(form, reason, tombstones)
+	}
+}
+
+// SetExportHashKey sets the key export profiles hash masked values with (see
+// domain.ExportProfile.HashKey)
+func (s *SubmissionService) SetExportHashKey(key []byte) {
+	s.exportHashKey = key
+}
+
+// SetFeed publishes every stored submission, spam and tests included, to feed
+func (s *SubmissionService) SetFeed(feed *SubmissionFeed) {
+	s.feed = feed
+}
+
+// publish sends a stored submission to the feed, if there is one
+func (s *SubmissionService) publish(submission *domain.Submission) {
+	if s.feed != nil {
+		s.feed.Publish(submission)
+	}
+}
+
+// SetSpamCallback sets a callback run with the submissions users marked as spam, or
+// released as not spam, e.g. to learn their content
+func (s *SubmissionService) SetSpamCallback(fn func(ctx context.Context, submissions []*domain.Submission, spam bool)) {
+	s.onSpamMarked = fn
+}
+
+// notifySpamMarked runs the spam callback, if set, for the listed submissions of the
+// form with the given (internal) ID
+func (s *SubmissionService) notifySpamMarked(ctx context.Context, formID string, submissionIDs []string, spam bool) {
+	if s.onSpamMarked == nil {
+		return
+	}
+	var marked []*domain.Submission
+	if len(submissionIDs) == 1 {
+		submission, err := s.repo.Submission().GetByID(ctx, submissionIDs[0])
+		if err == nil && submission != nil && submission.FormID == formID {
+			marked = append(marked, submission)
+		}
+	} else {
+		all, err := s.repo.Submission().GetByFormID(ctx, formID)
+		if err != nil {
+			return
+		}
+		listed := make(map[string]bool, len(submissionIDs))
+		for _, id := range submissionIDs {
+			listed[id] = true
+		}
+		for _, submission := range all {
+			if listed[submission.ID] {
+				marked = append(marked, submission)
+			}
+		}
+	}
+	if len(marked) > 0 {
+		s.onSpamMarked(ctx, marked, spam)
+	}
+}
+
+// SetQueue makes submissions that can't be stored go to q instead of failing.
+// ReplayQueue stores them once the database is back.
+func (s *SubmissionService) SetQueue(q ports.SubmissionQueue) {
+	s.queue = q
+	s.lastForms = make(map[string]*domain.Form)
+}
+
+// QueueLen returns how many submissions wait to be stored, 0 without a queue
+func (s *SubmissionService) QueueLen() int {
+	if s.queue == nil {
+		return 0
+	}
+	return s.queue.Len()
+}
+
+// RememberForm keeps the form so submissions to it can still be checked while
+// the database is down. It's a no-op without a queue.
+func (s *SubmissionService) RememberForm(form *domain.Form) {
+	if s.queue == nil {
+		return
+	}
+	s.formsMu.Lock()
+	s.lastForms[form.PublicID] = form
+	s.formsMu.Unlock()
+}
+
+// FallbackForm returns the form last seen by RememberForm when looking it up
+// failed with lookupErr because of the database. Otherwise it returns lookupErr.
+func (s *SubmissionService) FallbackForm(publicID string, lookupErr error) (*domain.Form, error) {
+	if s.queue == nil || errors.Is(lookupErr, domain.ErrFormNotFound) {
+		return nil, lookupErr
+	}
+	s.formsMu.RLock()
+	form := s.lastForms[publicID]
+	s.formsMu.RUnlock()
+	if form == nil {
+		return nil, lookupErr
+	}
+	return form, nil
+}
+
+// SetNotificationCallback sets a callback for new submissions (for email notifications)
+func (s *SubmissionService) SetNotificationCallback(fn func(form *domain.Form, submission *domain.Submission, data map[string]interface{})) {
+	s.onNewSubmission = fn
+}
+
+// Submit runs the full submission flow: form lookup, access control, persistence and notification.
+// The HTTP submit endpoint runs the same steps as separate pipeline stages.
+func (s *SubmissionService) Submit(ctx context.Context, publicID string, data map[string]interface{}, meta domain.SubmissionMeta) (*domain.Submission, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
+	form, err := s.repo.Form().GetByPublicID(ctx, publicID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid form: %w", err)
+	}
+	if form == nil {
+		return nil, domain.ErrFormNotFound
+	}
+
+	if err := form.AcceptingSubmissions(); err != nil {
+		return nil, err
+	}
+
+	if err := s.CheckAccess(form, data, meta); err != nil {
+		return nil, err
+	}
+	form.ApplyHiddenFields(data)
+	if err := form.ValidateData(data); err != nil {
+		return nil, err
+	}
+	if meta.Consent, err = s.CheckConsent(form, data); err != nil {
+		return nil, err
+	}
+
+	submission, err := s.Save(ctx, form, data, meta)
+	if err != nil {
+		return nil, err
+	}
+
+	if !submission.Queued {
+		s.Notify(form, submission, data)
+	}
+	return submission, nil
+}
+
+// CheckAccess validates a submission against the form's access mode.
+// For key-protected forms the key is removed from data so it's not stored.
+func (s *SubmissionService) CheckAccess(form *domain.Form, data map[string]interface{}, meta domain.SubmissionMeta) error {
+	switch form.AccessMode {
+	case string(domain.AccessModeWithKey):
+		// Validate submission key from hidden field
+		submittedKey, _ := data["_submission_key"].(string)
+		if submittedKey == "" || submittedKey != form.SubmissionKey {
+			return domain.ErrInvalidSubmissionKey
+		}
+		// Remove the key from data so it's not stored
+		delete(data, "_submission_key")
+	case string(domain.AccessModePrivate):
+		// Backends sign the request with the submission key instead of logging in
+		if meta.Signature != nil {
+			return verifySignature(form.SubmissionKey, meta.Signature, time.Now())
+		}
+		// For private forms, we need to check if request has auth context
+		// This is passed via meta from the handler
+		if meta.Auth == nil || meta.Auth.UserID == "" {
+			return domain.ErrAuthRequired
+		}
+		// case "public" or empty - no validation needed
+	}
+	return nil
+}
+
+// SignatureTolerance is how far a signed submission's timestamp may be from the server clock
+const SignatureTolerance = 5 * time.Minute
+
+// verifySignature checks a submission signed with the form's submission key, using
+// the same "<algorithm>=<hex HMAC>" format as outgoing webhooks. The HMAC covers
+// "<timestamp>.<body>" and the timestamp must be within SignatureTolerance, so a
+// captured request can't be replayed later. Forms without a key can't be submitted to this way.
+func verifySignature(key string, sig *domain.RequestSignature, now time.Time) error {
+	alg, _, _ := strings.Cut(sig.Value, "=")
+	hash := sha256.New
+	switch alg {
+	case domain.SignatureSHA256:
+	case domain.SignatureSHA512:
+		hash = sha512.New
+	default:
+		return domain.ErrInvalidSignature
+	}
+	if key == "" || sig.Timestamp == "" {
+		return domain.ErrInvalidSignature
+	}
+
+	mac := hmac.New(hash, []byte(key))
+	mac.Write([]byte(sig.Timestamp + "."))
+	mac.Write(sig.Body)
+	if !hmac.Equal([]byte(alg+"="+hex.EncodeToString(mac.Sum(nil))), []byte(sig.Value)) {
+		return domain.ErrInvalidSignature
+	}
+
+	at, err := time.Parse(time.RFC3339, sig.Timestamp)
+	if err != nil {
+		return domain.ErrInvalidSignature
+	}
+	if d := now.Sub(at); d > SignatureTolerance || d < -SignatureTolerance {
+		return domain.ErrInvalidSignature
+	}
+	return nil
+}
+
+// CheckConsent verifies every consent field of the form was checked and returns
+// the record to store with the submission. Forms without a consent config return nil.
+func (s *SubmissionService) CheckConsent(form *domain.Form, data map[string]interface{}) (*domain.ConsentMeta, error) {
+	if form.Consent == nil || len(form.Consent.Fields) == 0 {
+		return nil, nil
+	}
+	if missing := form.Consent.Missing(data); len(missing) > 0 {
+		return nil, fmt.Errorf("%w: %s", domain.ErrConsentRequired, strings.Join(missing, ", "))
+	}
+	return &domain.ConsentMeta{
+		Fields:     form.Consent.Fields,
+		Version:    form.Consent.Version,
+		AcceptedAt: time.Now().UTC(),
+	}, nil
+}
+
+// Save stores a submission for the form and increments its submission count.
+// Submissions the spam check flagged are quarantined with the spam status.
+// With a queue set, a submission the database refuses is queued and returned
+// with Queued set; notifications for it are sent when it's replayed.
+func (s *SubmissionService) Save(ctx context.Context, form *domain.Form, data map[string]interface{}, meta domain.SubmissionMeta) (*domain.Submission, error) {
+	return s.save(ctx, form, data, meta, false)
+}
+
+// SaveTest stores a submission flagged as test data (seeding, fixtures). It doesn't count
+// towards the form's submission count or stats, and no notifications are sent.
+func (s *SubmissionService) SaveTest(ctx context.Context, form *domain.Form, data map[string]interface{}, meta domain.SubmissionMeta) (*domain.Submission, error) {
+	return s.save(ctx, form, data, meta, true)
+}
+
+func (s *SubmissionService) save(ctx context.Context, form *domain.Form, data map[string]interface{}, meta domain.SubmissionMeta, isTest bool) (*domain.Submission, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
+	dataBytes, _ := json.Marshal(data)
+
+	status := domain.SubmissionStatusUnread
+	if meta.IsSpam() {
+		status = domain.SubmissionStatusSpam
+	}
+	submission := &domain.Submission{
+		ID:        ids.New(ids.Submission),
+		FormID:    form.ID,
+		Status:    status,
+		Data:      json.RawMessage(dataBytes),
+		Meta:      meta,
+		IsTest:    isTest,
+		CreatedAt: time.Now(),
+	}
+
+	if err := s.repo.Submission().Create(ctx, submission); err != nil {
+		// Only outages are queued: a submission the database refuses would be refused again
+		if s.queue == nil || isTest || !errors.Is(err, domain.ErrStoreUnavailable) {
+			return nil, fmt.Errorf("save submission: %w", err)
+		}
+		if qerr := s.queue.Put(submission); qerr != nil {
+			return nil, fmt.Errorf("save submission: %w (queue: %v)", err, qerr)
+		}
+		log.Printf("[SUBMISSIONS] Queued submission %s, database unavailable: %v", submission.ID, err)
+		submission.Queued = true
+		return submission, nil
+	}
+
+	// Increment submission count
+	if !isTest {
+		_ = s.repo.Form().IncrementSubmissionCount(ctx, form.ID)
+	}
+	s.linkFromField(ctx, form, submission, data)
+	s.publish(submission)
+
+	return submission, nil
+}
+
+// Notify triggers the new-submission callback (async, doesn't block the submission).
+// Quarantined spam is stored without notifying anyone.
+func (s *SubmissionService) Notify(form *domain.Form, submission *domain.Submission, data map[string]interface{}) {
+	if submission.Status == domain.SubmissionStatusSpam {
+		return
+	}
+	if s.onNewSubmission != nil {
+		go s.onNewSubmission(form, submission, data)
+	}
+}
+
+// testNotificationData is sent when the caller doesn't provide sample fields
+var testNotificationData = map[string]interface{}{
+	"name":    "Test User",
+	"email":   "test@example.com",
+	"message": "This is a test submission sent from the dashboard.",
+}
+
+// SendTestNotification runs the notification callback (email, webhooks) for a synthetic
+// submission marked as a test. Nothing is stored, so stats and counts are unaffected.
+func (s *SubmissionService) SendTestNotification(ctx context.Context, publicID string, data map[string]interface{}) (*domain.Submission, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpLookup)
+	defer cancel()
+	form, err := s.repo.Form().GetByPublicID(ctx, publicID)
+	if err != nil {
+		return nil, fmt.Errorf("lookup form: %w", err)
+	}
+	if form == nil {
+		return nil, domain.ErrFormNotFound
+	}
+	if len(data) == 0 {
+		data = testNotificationData
+	}
+	dataBytes, _ := json.Marshal(data)
+
+	now := time.Now()
+	submission := &domain.Submission{
+		ID:        ids.New(ids.Submission),
+		FormID:    form.ID,
+		Status:    domain.SubmissionStatusUnread,
+		Data:      json.RawMessage(dataBytes),
+		Meta:      domain.SubmissionMeta{Server: domain.ServerMeta{Timestamp: now}},
+		IsTest:    true,
+		CreatedAt: now,
+	}
+
+	s.Notify(form, submission, data)
+	return submission, nil
+}
+
+func (s *SubmissionService) ListSubmissions(ctx context.Context, publicID string) ([]*domain.Submission, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpExport)
+	defer cancel()
+	form, err := s.repo.Form().GetByPublicID(ctx, publicID)
+	if err != nil {
+		return nil, fmt.Errorf("lookup form: %w", err)
+	}
+	if form == nil {
+		return nil, domain.ErrFormNotFound
+	}
+
+	return s.repo.Submission().GetByFormID(ctx, form.ID)
+}
+
+// EachSubmission streams a form's submissions, newest first, to fn. Unlike ListSubmissions
+// it isn't bounded by the export timeout, since how long it takes depends on how fast fn
+// (e.g. a client downloading an export) consumes them; it stops when ctx is done.
+func (s *SubmissionService) EachSubmission(ctx context.Context, publicID string, fn func(*domain.Submission) error) error {
+	lookupCtx, cancel := s.timeouts.bound(ctx, OpLookup)
+	defer cancel()
+	form, err := s.repo.Form().GetByPublicID(lookupCtx, publicID)
+	if err != nil {
+		return fmt.Errorf("lookup form: %w", err)
+	}
+	if form == nil {
+		return domain.ErrFormNotFound
+	}
+
+	return s.repo.Submission().EachByFormID(ctx, form.ID, fn)
+}
+
+// ExportSettings returns who may export submissions and the profiles masking their
+// exports, nil when every role exports in full
+func (s *SubmissionService) ExportSettings(ctx context.Context) (*domain.ExportSettings, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpLookup)
+	defer cancel()
+	settings, err := s.repo.Settings().Get(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get settings: %w", err)
+	}
+	if settings.Exports != nil {
+		for i := range settings.Exports.Profiles {
+			settings.Exports.Profiles[i].HashKey = s.exportHashKey
+		}
+	}
+	return settings.Exports, nil
+}
+
+// ListSubmissionsPaginated returns one page of a form's submissions matching query and
+// per-status counts of the matches. Submissions viewerID opened are flagged Opened, and
+// counts include how many they haven't.
+func (s *SubmissionService) ListSubmissionsPaginated(ctx context.Context, publicID, viewerID string, query domain.SubmissionListQuery, page, limit int) ([]*domain.Submission, domain.SubmissionCounts, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpList)
+	defer cancel()
+	if err := query.Validate(); err != nil {
+		return nil, domain.SubmissionCounts{}, err
+	}
+	form, err := s.repo.Form().GetByPublicID(ctx, publicID)
+	if err != nil {
+		return nil, domain.SubmissionCounts{}, fmt.Errorf("lookup form: %w", err)
+	}
+	if form == nil {
+		return nil, domain.SubmissionCounts{}, domain.ErrFormNotFound
+	}
+
+	offset := (page - 1) * limit
+	return s.repo.Submission().SearchByFormID(ctx, form.ID, viewerID, query, limit, offset)
+}
+
+func (s *SubmissionService) MarkAsRead(ctx context.Context, submissionID string) error {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
+	return s.repo.Submission().UpdateStatus(ctx, submissionID, domain.SubmissionStatusRead)
+}
+
+func (s *SubmissionService) MarkAsUnread(ctx context.Context, submissionID string) error {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
+	return s.repo.Submission().UpdateStatus(ctx, submissionID, domain.SubmissionStatusUnread)
+}
+
+// MarkAsSpam quarantines a submission of the form, recording that a user flagged it
+func (s *SubmissionService) MarkAsSpam(ctx context.Context, formID, submissionID string) error {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
+	if _, err := s.repo.Submission().SetSpamBatch(ctx, formID, []string{submissionID}, true); err != nil {
+		return err
+	}
+	s.notifySpamMarked(ctx, formID, []string{submissionID}, true)
+	return nil
+}
+
+// MarkAsNotSpam clears a submission's spam flag, releasing it to the inbox as unread if it
+// was quarantined. Notifications skipped while it was quarantined aren't sent.
+func (s *SubmissionService) MarkAsNotSpam(ctx context.Context, formID, submissionID string) error {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
+	if _, err := s.repo.Submission().SetSpamBatch(ctx, formID, []string{submissionID}, false); err != nil {
+		return err
+	}
+	s.notifySpamMarked(ctx, formID, []string{submissionID}, false)
+	return nil
+}
+
+func (s *SubmissionService) DeleteSubmission(ctx context.Context, submissionID string) error {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
+	if s.files == nil && s.onDeleted == nil {
+		return s.repo.Submission().Delete(ctx, submissionID)
+	}
+
+	submission, err := s.repo.Submission().GetByID(ctx, submissionID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Submission().Delete(ctx, submissionID); err != nil {
+		return err
+	}
+	if submission == nil {
+		return nil
+	}
+	s.DeleteFiles(ctx, submission.FormID, submission.Meta.Files)
+	if s.onDeleted != nil {
+		if form, err := s.repo.Form().GetByID(ctx, submission.FormID); err == nil && form != nil {
+			s.notifyDeleted(form, domain.DeletionManual, []domain.Tombstone{submission.Tombstone()})
+		}
+	}
+	return nil
+}
+
+// DeleteSubmissions deletes all of a form's submissions matching filter in one statement
+// and records the deletion in the audit log. It returns the number deleted.
+func (s *SubmissionService) DeleteSubmissions(ctx context.Context, publicID string, filter domain.SubmissionFilter, userID string) (int, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
+	if filter.IsEmpty() {
+		return 0, domain.ErrFilterRequired
+	}
+	form, err := s.repo.Form().GetByPublicID(ctx, publicID)
+	if err != nil {
+		return 0, fmt.Errorf("lookup form: %w", err)
+	}
+	if form == nil {
+		return 0, domain.ErrFormNotFound
+	}
+
+	// Files and tombstones of the matching submissions, for once they're deleted
+	var files []domain.FileRef
+	var tombstones []domain.Tombstone
+	if s.files != nil || s.onDeleted != nil {
+		all, err := s.repo.Submission().GetByFormID(ctx, form.ID)
+		if err != nil {
+			return 0, fmt.Errorf("list submissions: %w", err)
+		}
+		for _, submission := range all {
+			if filter.Matches(submission) {
+				files = append(files, submission.Meta.Files...)
+				tombstones = append(tombstones, submission.Tombstone())
+			}
+		}
+	}
+
+	var deleted int
+	err = s.repo.Tx(ctx, func(repo ports.Repository) error {
+		n, err := repo.Submission().DeleteMatching(ctx, form.ID, filter)
+		if err != nil {
+			return fmt.Errorf("delete submissions: %w", err)
+		}
+		deleted = n
+
+		details := map[string]interface{}{"deleted": n}
+		if filter.Status != "" {
+			details["status"] = filter.Status
+		}
+		if !filter.Before.IsZero() {
+			details["before"] = filter.Before.UTC()
+		}
+		if filter.IsTest != nil {
+			details["is_test"] = *filter.IsTest
+		}
+		return repo.Audit().Create(ctx, &domain.AuditEntry{
+			ID:        ids.New(ids.AuditEntry),
+			FormID:    form.ID,
+			UserID:    userID,
+			Action:    domain.AuditActionSubmissionsDeleted,
+			Details:   details,
+			CreatedAt: time.Now().UTC(),
+		})
+	})
+	if err != nil {
+		return 0, err
+	}
+	s.DeleteFiles(ctx, form.ID, files)
+	s.notifyDeleted(form, domain.DeletionManual, tombstones)
+	return deleted, nil
+}
+
+// BulkAction applies action to the listed submissions of a form with one batch query and
+// returns how many were affected. IDs that don't belong to the form are skipped. Deletions
+// are recorded in the audit log like DeleteSubmissions.
+func (s *SubmissionService) BulkAction(ctx context.Context, publicID string, action domain.BulkAction, submissionIDs []string, userID string) (int, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
+	if !action.IsValid() {
+		return 0, domain.ErrInvalidBulkAction
+	}
+	submissionIDs = uniqueIDs(submissionIDs)
+	if len(submissionIDs) == 0 || len(submissionIDs) > domain.MaxBulkIDs {
+		return 0, domain.ErrInvalidBulkIDs
+	}
+	form, err := s.repo.Form().GetByPublicID(ctx, publicID)
+	if err != nil {
+		return 0, fmt.Errorf("lookup form: %w", err)
+	}
+	if form == nil {
+		return 0, domain.ErrFormNotFound
+	}
+
+	switch action {
+	case domain.BulkActionMarkRead:
+		return s.repo.Submission().UpdateStatusBatch(ctx, form.ID, submissionIDs, domain.SubmissionStatusRead)
+	case domain.BulkActionMarkUnread:
+		return s.repo.Submission().UpdateStatusBatch(ctx, form.ID, submissionIDs, domain.SubmissionStatusUnread)
+	case domain.BulkActionMarkSpam, domain.BulkActionNotSpam:
+		spam := action == domain.BulkActionMarkSpam
+		n, err := s.repo.Submission().SetSpamBatch(ctx, form.ID, submissionIDs, spam)
+		if err != nil {
+			return 0, err
+		}
+		s.notifySpamMarked(ctx, form.ID, submissionIDs, spam)
+		return n, nil
+	}
+
+	// Files and tombstones of the listed submissions, for once they're deleted
+	var files []domain.FileRef
+	var tombstones []domain.Tombstone
+	if s.files != nil || s.onDeleted != nil {
+		all, err := s.repo.Submission().GetByFormID(ctx, form.ID)
+		if err != nil {
+			return 0, fmt.Errorf("list submissions: %w", err)
+		}
+		listed := make(map[string]bool, len(submissionIDs))
+		for _, id := range submissionIDs {
+			listed[id] = true
+		}
+		for _, submission := range all {
+			if listed[submission.ID] {
+				files = append(files, submission.Meta.Files...)
+				tombstones = append(tombstones, submission.Tombstone())
+			}
+		}
+	}
+
+	var deleted int
+	err = s.repo.Tx(ctx, func(repo ports.Repository) error {
+		n, err := repo.Submission().DeleteBatch(ctx, form.ID, submissionIDs)
+		if err != nil {
+			return fmt.Errorf("delete submissions: %w", err)
+		}
+		deleted = n
+		return repo.Audit().Create(ctx, &domain.AuditEntry{
+			ID:        ids.New(ids.AuditEntry),
+			FormID:    form.ID,
+			UserID:    userID,
+			Action:    domain.AuditActionSubmissionsDeleted,
+			Details:   map[string]interface{}{"deleted": n, "requested": len(submissionIDs)},
+			CreatedAt: time.Now().UTC(),
+		})
+	})
+	if err != nil {
+		return 0, err
+	}
+	s.DeleteFiles(ctx, form.ID, files)
+	s.notifyDeleted(form, domain.DeletionManual, tombstones)
+	return deleted, nil
+}
+
+// uniqueIDs drops empty and repeated IDs, keeping the first occurrence's order
+func uniqueIDs(list []string) []string {
+	seen := make(map[string]bool, len(list))
+	out := make([]string, 0, len(list))
+	for _, id := range list {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+// PurgeTestSubmissions deletes test submissions older than the test retention period.
+// Returns the number of submissions deleted.
+func (s *SubmissionService) PurgeTestSubmissions(ctx context.Context) (int, error) {
+	deleted, err := s.repo.Submission().DeleteTestBefore(ctx, time.Now().Add(-s.testRetention))
+	if err != nil {
+		return 0, fmt.Errorf("purge test submissions: %w", err)
+	}
+	if s.onDeleted != nil {
+		// Grouped by form, in the order the forms first appear
+		var formIDs []string
+		byForm := make(map[string][]domain.Tombstone)
+		for _, t := range deleted {
+			if _, seen := byForm[t.FormID]; !seen {
+				formIDs = append(formIDs, t.FormID)
+			}
+			byForm[t.FormID] = append(byForm[t.FormID], t)
+		}
+		for _, id := range formIDs {
+			if form, err := s.repo.Form().GetByID(ctx, id); err == nil && form != nil {
+				s.notifyDeleted(form, domain.DeletionRetention, byForm[id])
+			}
+		}
+	}
+	return len(deleted), nil
+}
+
+// StartTestPurge runs PurgeTestSubmissions every interval until ctx is cancelled,
+// skipping runs while paused reports true (nil never pauses)
+func (s *SubmissionService) StartTestPurge(ctx context.Context, interval time.Duration, paused func() bool) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if paused != nil && paused() {
+					continue
+				}
+				if n, err := s.PurgeTestSubmissions(ctx); err != nil {
+					log.Printf("[SUBMISSIONS] Test data purge failed: %v", err)
+				} else if n > 0 {
+					log.Printf("[SUBMISSIONS] Purged %d test submission(s)", n)
+				}
+			}
+		}
+	}()
+}
+
+// ReplayQueue stores queued submissions, oldest first, and sends their notifications.
+// It stops while the database is still unavailable, leaving the rest queued. Submissions
+// the database refuses for another reason are dead-lettered, so they don't hold up the
+// ones behind them, and those to forms deleted meanwhile are dropped. Returns how many were stored.
+func (s *SubmissionService) ReplayQueue(ctx context.Context) (int, error) {
+	if s.queue == nil || s.queue.Len() == 0 {
+		return 0, nil
+	}
+	queued, err := s.queue.List()
+	if err != nil {
+		return 0, fmt.Errorf("list queued submissions: %w", err)
+	}
+
+	stored := 0
+	for _, submission := range queued {
+		ok, err := s.replay(ctx, submission)
+		if errors.Is(err, errRefused) {
+			log.Printf("[SUBMISSIONS] Dead-lettered queued submission %s: %v", submission.ID, err)
+			if err := s.queue.DeadLetter(submission.ID); err != nil {
+				return stored, fmt.Errorf("dead-letter queued submission: %w", err)
+			}
+			continue
+		}
+		if err != nil {
+			return stored, err
+		}
+		if ok {
+			stored++
+		}
+		if err := s.queue.Remove(submission.ID); err != nil {
+			return stored, fmt.Errorf("remove queued submission: %w", err)
+		}
+	}
+	return stored, nil
+}
+
+// errRefused marks a queued submission the database refuses for good
+var errRefused = errors.New("database refused the submission")
+
+// replay stores one queued submission. It returns false if it was dropped or already stored.
+func (s *SubmissionService) replay(ctx context.Context, submission *domain.Submission) (bool, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
+
+	// A crash between storing it and removing it from the queue stores it twice otherwise
+	existing, err := s.repo.Submission().GetByID(ctx, submission.ID)
+	if err != nil {
+		return false, fmt.Errorf("check queued submission: %w", err)
+	}
+	if existing != nil {
+		return false, nil
+	}
+
+	form, err := s.repo.Form().GetByID(ctx, submission.FormID)
+	if err != nil {
+		return false, fmt.Errorf("get form: %w", err)
+	}
+	if form == nil || form.IsDeleted() {
+		log.Printf("[SUBMISSIONS] Dropped queued submission %s, form %s no longer exists", submission.ID, submission.FormID)
+		return false, nil
+	}
+
+	if err := s.repo.Submission().Create(ctx, submission); err != nil {
+		if !errors.Is(err, domain.ErrStoreUnavailable) {
+			return false, fmt.Errorf("%w: %w", errRefused, err)
+		}
+		return false, fmt.Errorf("save submission: %w", err)
+	}
+	_ = s.repo.Form().IncrementSubmissionCount(ctx, form.ID)
+
+	var data map[string]interface{}
+	_ = json.Unmarshal(submission.Data, &data)
+	s.linkFromField(ctx, form, submission, data)
+	s.publish(submission)
+	s.Notify(form, submission, data)
+	return true, nil
+}
+
+// StartQueueReplay runs ReplayQueue every interval until ctx is cancelled,
+// skipping runs while paused reports true (nil never pauses)
+func (s *SubmissionService) StartQueueReplay(ctx context.Context, interval time.Duration, paused func() bool) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if paused != nil && paused() {
+					continue
+				}
+				n, err := s.ReplayQueue(ctx)
+				if n > 0 {
+					log.Printf("[SUBMISSIONS] Stored %d queued submission(s), %d still queued", n, s.QueueLen())
+				}
+				if err != nil {
+					log.Printf("[SUBMISSIONS] Queue replay stopped: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// ListAuditLog returns a form's most recent audit entries, newest first
+func (s *SubmissionService) ListAuditLog(ctx context.Context, publicID string, limit int) ([]*domain.AuditEntry, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpList)
+	defer cancel()
+	form, err := s.repo.Form().GetByPublicID(ctx, publicID)
+	if err != nil {
+		return nil, fmt.Errorf("lookup form: %w", err)
+	}
+	if form == nil {
+		return nil, domain.ErrFormNotFound
+	}
+	return s.repo.Audit().ListByFormID(ctx, form.ID, limit)
+}
+
+// GetSubmission retrieves a single submission by ID
+func (s *SubmissionService) GetSubmission(ctx context.Context, submissionID string) (*domain.Submission, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpLookup)
+	defer cancel()
+	submission, err := s.repo.Submission().GetByID(ctx, submissionID)
+	if err != nil {
+		return nil, fmt.Errorf("get submission: %w", err)
+	}
+	if submission == nil {
+		return nil, domain.ErrSubmissionNotFound
+	}
+	return submission, nil
+}
+
+// OpenSubmission records that userID opened the submission and fills in its OpenedBy list
+func (s *SubmissionService) OpenSubmission(ctx context.Context, submission *domain.Submission, userID string) error {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
+	if err := s.repo.Submission().RecordView(ctx, submission.ID, userID, time.Now()); err != nil {
+		return fmt.Errorf("record view: %w", err)
+	}
+	views, err := s.repo.Submission().ListViews(ctx, submission.ID)
+	if err != nil {
+		return fmt.Errorf("list views: %w", err)
+	}
+	submission.Opened = true
+	submission.OpenedBy = views
+	return nil
+}
+
+// StatsService handles statistics business logic
+type StatsService struct {
+	repo     ports.Repository
+	timeouts Timeouts
+}
+
+func NewStatsService(repo ports.Repository) *StatsService {
+	return &StatsService{repo: repo, timeouts: DefaultTimeouts}
+}
+
+// SetTimeouts overrides the per-operation timeouts (see DefaultTimeouts)
+func (s *StatsService) SetTimeouts(t Timeouts) {
+	s.timeouts = t
+}
+
+// GetDashboardStats returns stats across all forms, with days counted in the
+// timezone of the user viewing them (UTC for an empty userID)
+func (s *StatsService) GetDashboardStats(ctx context.Context, userID string) (*domain.DashboardStats, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpList)
+	defer cancel()
+	loc := time.UTC
+	if userID != "" {
+		if user, err := s.repo.User().GetByID(ctx, userID); err == nil && user != nil {
+			loc = domain.LoadTimezone(user.Timezone)
+		}
+	}
+	return s.repo.Stats().GetDashboardStats(ctx, loc)
+}
+
+// GetFormStats returns a form's stats, with days counted in the form's timezone
+func (s *StatsService) GetFormStats(ctx context.Context, publicID string) (*domain.FormStats, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpList)
+	defer cancel()
+	form, err := s.repo.Form().GetByPublicID(ctx, publicID)
+	if err != nil || form == nil {
+		return nil, domain.ErrFormNotFound
+	}
+	return s.repo.Stats().GetFormStats(ctx, form.ID, formLocation(ctx, s.repo, form))
+}
+
+// GetFieldStats aggregates the values of a form's number and date fields, with
+// dates counted in the form's timezone
+func (s *StatsService) GetFieldStats(ctx context.Context, publicID string) ([]domain.FieldStats, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpExport)
+	defer cancel()
+	form, err := s.repo.Form().GetByPublicID(ctx, publicID)
+	if err != nil || form == nil {
+		return nil, domain.ErrFormNotFound
+	}
+	each := func(fn func(*domain.Submission) error) error {
+		return s.repo.Submission().EachByFormID(ctx, form.ID, fn)
+	}
+	stats, err := form.FieldStats(each, formLocation(ctx, s.repo, form))
+	if err != nil {
+		return nil, fmt.Errorf("read submissions: %w", err)
+	}
+	return stats, nil
+}
+
+// maxStatsExportDays caps the range of a stats export to roughly a year
+const maxStatsExportDays = 366
+
+// GetFormDailyStats returns the daily time series for a form between the days of from
+// and to (inclusive), counted in loc or, when nil, the form's timezone
+func (s *StatsService) GetFormDailyStats(ctx context.Context, publicID string, from, to time.Time, loc *time.Location) ([]domain.DailyFormStats, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpExport)
+	defer cancel()
+	if to.Before(from) || to.Sub(from) > maxStatsExportDays*24*time.Hour {
+		return nil, domain.ErrInvalidDateRange
+	}
+	form, err := s.repo.Form().GetByPublicID(ctx, publicID)
+	if err != nil || form == nil {
 		return nil, domain.ErrFormNotFound
 	}
-	return s.repo.Stats().GetFormStats(ctx, form.ID)
+	if loc == nil {
+		loc = formLocation(ctx, s.repo, form)
+	}
+	return s.repo.Stats().GetFormDailyStats(ctx, form.ID, from, to, loc)
 }
@@ -2,22 +2,49 @@ package service
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"headless_form/internal/core/domain"
+	"headless_form/internal/core/passhash"
 	"headless_form/internal/core/ports"
+
+	"golang.org/x/crypto/bcrypt"
 )
 
 // MockRepository implements ports.Repository for testing
 type MockRepository struct {
 	forms       map[string]*domain.Form
 	submissions map[string][]*domain.Submission
+	audit       *MockAuditRepository
+	tasks       *MockTaskRepository
+	users       map[string]*domain.User // by email
+	comments    *MockCommentRepository
+	deliveries  *MockWebhookDeliveryRepository
+	locks       *MockLockRepository
 }
 
 func NewMockRepository() *MockRepository {
 	return &MockRepository{
 		forms:       make(map[string]*domain.Form),
 		submissions: make(map[string][]*domain.Submission),
+		audit:       &MockAuditRepository{},
+		tasks:       &MockTaskRepository{tasks: make(map[string]domain.Task)},
+		users:       make(map[string]*domain.User),
+		comments:    &MockCommentRepository{comments: make(map[string]*domain.Comment)},
+		deliveries:  &MockWebhookDeliveryRepository{deliveries: make(map[string]*domain.WebhookDelivery)},
+		locks:       &MockLockRepository{owners: make(map[string]string)},
 	}
 }
 
@@ -38,26 +65,41 @@ func (m *MockRepository) Stats() ports.StatsRepository {
 }
 
 func (m *MockRepository) User() ports.UserRepository {
-	return &MockUserRepository{}
+	return &MockUserRepository{users: m.users}
 }
 
-// MockUserRepository for testing
-type MockUserRepository struct{}
+// MockUserRepository stores users in memory, keyed by email
+type MockUserRepository struct {
+	users map[string]*domain.User
+}
 
 func (r *MockUserRepository) Create(ctx context.Context, user *domain.User) error {
+	saved := *user
+	r.users[user.Email] = &saved
 	return nil
 }
 
 func (r *MockUserRepository) GetByID(ctx context.Context, id string) (*domain.User, error) {
-	return nil, nil
+	for _, user := range r.users {
+		if user.ID == id {
+			found := *user
+			return &found, nil
+		}
+	}
+	return nil, domain.ErrUserNotFound
 }
 
 func (r *MockUserRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
-	return nil, nil
+	user, ok := r.users[email]
+	if !ok {
+		return nil, domain.ErrUserNotFound
+	}
+	found := *user
+	return &found, nil
 }
 
 func (r *MockUserRepository) Update(ctx context.Context, user *domain.User) error {
-	return nil
+	return r.Create(ctx, user)
 }
 
 func (r *MockUserRepository) Delete(ctx context.Context, id string) error {
@@ -69,7 +111,7 @@ func (r *MockUserRepository) List(ctx context.Context) ([]*domain.User, error) {
 }
 
 func (r *MockUserRepository) Count(ctx context.Context) (int, error) {
-	return 0, nil
+	return len(r.users), nil
 }
 
 func (m *MockRepository) PasswordReset() ports.PasswordResetRepository {
@@ -94,6 +136,219 @@ func (m *MockRepository) Settings() ports.SettingsRepository {
 	return nil // Not used in current tests
 }
 
+func (m *MockRepository) Alert() ports.AlertRepository {
+	return nil // Not used in current tests
+}
+
+func (m *MockRepository) FormToken() ports.FormTokenRepository {
+	return nil // Not used in current tests
+}
+
+func (m *MockRepository) Audit() ports.AuditRepository {
+	return m.audit
+}
+
+func (m *MockRepository) Task() ports.TaskRepository {
+	return m.tasks
+}
+
+func (m *MockRepository) Notification() ports.NotificationRepository {
+	return nil // Not used in service tests
+}
+
+func (m *MockRepository) Lock() ports.LockRepository {
+	return m.locks
+}
+
+func (m *MockRepository) WebhookDelivery() ports.WebhookDeliveryRepository {
+	return m.deliveries
+}
+
+func (m *MockRepository) EmailQueue() ports.EmailQueueRepository {
+	return nil // Not used in service tests
+}
+
+func (m *MockRepository) SpamFingerprint() ports.SpamFingerprintRepository {
+	return nil // Not used in service tests
+}
+
+func (m *MockRepository) Team() ports.TeamRepository {
+	return nil // Not used in service tests
+}
+
+// MockWebhookDeliveryRepository stores webhook deliveries in memory
+type MockWebhookDeliveryRepository struct {
+	mu         sync.Mutex
+	deliveries map[string]*domain.WebhookDelivery
+}
+
+func (r *MockWebhookDeliveryRepository) Create(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	saved := *delivery
+	r.deliveries[delivery.ID] = &saved
+	return nil
+}
+
+func (r *MockWebhookDeliveryRepository) Update(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	return r.Create(ctx, delivery)
+}
+
+func (r *MockWebhookDeliveryRepository) GetByID(ctx context.Context, id string) (*domain.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if d, ok := r.deliveries[id]; ok {
+		copied := *d
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (r *MockWebhookDeliveryRepository) ListByFormID(ctx context.Context, formID string, limit, offset int) ([]*domain.WebhookDelivery, int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var list []*domain.WebhookDelivery
+	for _, d := range r.deliveries {
+		if d.FormID == formID {
+			copied := *d
+			list = append(list, &copied)
+		}
+	}
+	slices.SortFunc(list, func(a, b *domain.WebhookDelivery) int { return b.CreatedAt.Compare(a.CreatedAt) })
+	total := len(list)
+	list = list[min(offset, total):min(offset+limit, total)]
+	return list, total, nil
+}
+
+func (r *MockWebhookDeliveryRepository) DeleteBefore(ctx context.Context, before time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := 0
+	for id, d := range r.deliveries {
+		if d.CreatedAt.Before(before) {
+			delete(r.deliveries, id)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (m *MockRepository) Comment() ports.CommentRepository {
+	return m.comments
+}
+
+// MockCommentRepository stores comments in memory
+type MockCommentRepository struct {
+	comments map[string]*domain.Comment
+}
+
+func (r *MockCommentRepository) Create(ctx context.Context, comment *domain.Comment) error {
+	saved := *comment
+	r.comments[comment.ID] = &saved
+	return nil
+}
+
+func (r *MockCommentRepository) Update(ctx context.Context, comment *domain.Comment) error {
+	return r.Create(ctx, comment)
+}
+
+func (r *MockCommentRepository) GetByID(ctx context.Context, id string) (*domain.Comment, error) {
+	comment, ok := r.comments[id]
+	if !ok {
+		return nil, nil
+	}
+	found := *comment
+	return &found, nil
+}
+
+func (r *MockCommentRepository) ListBySubmissionID(ctx context.Context, submissionID string) ([]*domain.Comment, error) {
+	var list []*domain.Comment
+	for _, c := range r.comments {
+		if c.SubmissionID == submissionID {
+			list = append(list, c)
+		}
+	}
+	return list, nil
+}
+
+func (r *MockCommentRepository) Delete(ctx context.Context, id string) error {
+	delete(r.comments, id)
+	return nil
+}
+
+// MockTaskRepository stores tasks in memory; tasks are saved from their own goroutine
+type MockTaskRepository struct {
+	mu    sync.Mutex
+	tasks map[string]domain.Task
+}
+
+func (r *MockTaskRepository) Create(ctx context.Context, task *domain.Task) error {
+	return r.Update(ctx, task)
+}
+
+func (r *MockTaskRepository) Update(ctx context.Context, task *domain.Task) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tasks[task.ID] = *task
+	return nil
+}
+
+func (r *MockTaskRepository) GetByID(ctx context.Context, id string) (*domain.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	task, ok := r.tasks[id]
+	if !ok {
+		return nil, nil
+	}
+	return &task, nil
+}
+
+func (r *MockTaskRepository) ListWithFiles(ctx context.Context) ([]*domain.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var tasks []*domain.Task
+	for _, task := range r.tasks {
+		if task.File != nil {
+			tasks = append(tasks, &task)
+		}
+	}
+	return tasks, nil
+}
+
+func (r *MockTaskRepository) FailUnfinished(ctx context.Context, reason string, at time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := 0
+	for id, task := range r.tasks {
+		if !task.Status.IsFinal() {
+			task.Status, task.Error, task.FinishedAt = domain.TaskFailed, reason, &at
+			r.tasks[id] = task
+			n++
+		}
+	}
+	return n, nil
+}
+
+// MockAuditRepository records audit entries in memory
+type MockAuditRepository struct {
+	entries []*domain.AuditEntry
+}
+
+func (r *MockAuditRepository) Create(ctx context.Context, entry *domain.AuditEntry) error {
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+func (r *MockAuditRepository) ListByFormID(ctx context.Context, formID string, limit int) ([]*domain.AuditEntry, error) {
+	var out []*domain.AuditEntry
+	for _, e := range r.entries {
+		if e.FormID == formID {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
 // MockFormRepository
 type MockFormRepository struct {
 	forms map[string]*domain.Form
@@ -105,6 +360,12 @@ func (r *MockFormRepository) Create(ctx context.Context, f *domain.Form) error {
 }
 
 func (r *MockFormRepository) Update(ctx context.Context, f *domain.Form) error {
+	// The public ID changes when it's rotated
+	for publicID, existing := range r.forms {
+		if existing.ID == f.ID {
+			delete(r.forms, publicID)
+		}
+	}
 	r.forms[f.PublicID] = f
 	return nil
 }
@@ -113,6 +374,24 @@ func (r *MockFormRepository) GetByPublicID(ctx context.Context, publicID string)
 	return r.forms[publicID], nil
 }
 
+func (r *MockFormRepository) GetBySlug(ctx context.Context, slug string) (*domain.Form, error) {
+	for _, f := range r.forms {
+		if f.Slug == slug {
+			return f, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *MockFormRepository) GetByPreviousPublicID(ctx context.Context, publicID string) (*domain.Form, error) {
+	for _, f := range r.forms {
+		if f.PreviousPublicID == publicID {
+			return f, nil
+		}
+	}
+	return nil, nil
+}
+
 func (r *MockFormRepository) GetByID(ctx context.Context, id string) (*domain.Form, error) {
 	for _, f := range r.forms {
 		if f.ID == id {
@@ -150,30 +429,82 @@ func (r *MockFormRepository) IncrementSubmissionCount(ctx context.Context, formI
 	return nil
 }
 
-func (r *MockFormRepository) ListPaginated(ctx context.Context, limit, offset int) ([]*domain.Form, int, error) {
+func (r *MockFormRepository) ListPurgeable(ctx context.Context, before time.Time) ([]*domain.Form, error) {
 	var list []*domain.Form
 	for _, f := range r.forms {
-		list = append(list, f)
+		if f.IsDeleted() && f.PurgeAt != nil && !f.PurgeAt.After(before) {
+			list = append(list, f)
+		}
 	}
-	// Simple pagination simulation
-	total := len(list)
-	if offset >= len(list) {
-		return []*domain.Form{}, total, nil
+	return list, nil
+}
+
+func (r *MockFormRepository) ListDeleted(ctx context.Context, ownerID string) ([]*domain.Form, error) {
+	var list []*domain.Form
+	for _, f := range r.forms {
+		if f.IsDeleted() && (ownerID == "" || f.OwnerID == ownerID) {
+			list = append(list, f)
+		}
 	}
-	end := offset + limit
-	if end > len(list) {
-		end = len(list)
+	return list, nil
+}
+
+func (r *MockFormRepository) Star(ctx context.Context, userID, formID string) error {
+	return nil
+}
+
+func (r *MockFormRepository) Unstar(ctx context.Context, userID, formID string) error {
+	return nil
+}
+
+func (r *MockFormRepository) ListStarredBy(ctx context.Context, formID string) ([]string, error) {
+	return nil, nil
+}
+
+func (r *MockFormRepository) SetTeam(ctx context.Context, formID, teamID string) error {
+	for _, f := range r.forms {
+		if f.ID == formID {
+			f.TeamID = teamID
+		}
 	}
-	return list[offset:end], total, nil
+	return nil
+}
+
+func (r *MockFormRepository) SetOwner(ctx context.Context, formID, ownerID string) error {
+	for _, f := range r.forms {
+		if f.ID == formID {
+			f.OwnerID = ownerID
+			break
+		}
+	}
+	return nil
 }
 
-func (r *MockFormRepository) ListByOwnerPaginated(ctx context.Context, ownerID string, limit, offset int) ([]*domain.Form, int, error) {
+func (r *MockFormRepository) ListWithRetention(ctx context.Context) ([]*domain.Form, error) {
 	var list []*domain.Form
 	for _, f := range r.forms {
-		if f.OwnerID == ownerID {
+		if !f.IsDeleted() && f.RetentionDays > 0 {
 			list = append(list, f)
 		}
 	}
+	return list, nil
+}
+
+func (r *MockFormRepository) ListPaginated(ctx context.Context, query domain.FormListQuery, limit, offset int) ([]*domain.Form, int, error) {
+	var list []*domain.Form
+	for _, f := range r.forms {
+		if query.OwnerID != "" && f.OwnerID != query.OwnerID {
+			continue
+		}
+		if query.Status != "" && f.Status != query.Status {
+			continue
+		}
+		if query.Search != "" && !strings.Contains(strings.ToLower(f.Name), strings.ToLower(query.Search)) {
+			continue
+		}
+		list = append(list, f)
+	}
+	// Simple pagination simulation
 	total := len(list)
 	if offset >= len(list) {
 		return []*domain.Form{}, total, nil
@@ -211,17 +542,182 @@ func (r *MockSubmissionRepository) GetByFormID(ctx context.Context, formID strin
 	return r.submissions[formID], nil
 }
 
-func (r *MockSubmissionRepository) GetByFormIDPaginated(ctx context.Context, formID string, limit, offset int) ([]*domain.Submission, int, error) {
-	subs := r.submissions[formID]
-	total := len(subs)
+func (r *MockSubmissionRepository) EachByFormID(ctx context.Context, formID string, fn func(*domain.Submission) error) error {
+	for _, s := range r.submissions[formID] {
+		if err := fn(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *MockSubmissionRepository) GetByFormIDPaginated(ctx context.Context, formID, viewerID string, limit, offset int) ([]*domain.Submission, domain.SubmissionCounts, error) {
+	return r.SearchByFormID(ctx, formID, viewerID, domain.SubmissionListQuery{}, limit, offset)
+}
+
+func (r *MockSubmissionRepository) SearchByFormID(ctx context.Context, formID, viewerID string, query domain.SubmissionListQuery, limit, offset int) ([]*domain.Submission, domain.SubmissionCounts, error) {
+	var subs []*domain.Submission
+	for _, s := range r.submissions[formID] {
+		if query.Matches(s) {
+			subs = append(subs, s)
+		}
+	}
+	counts := domain.SubmissionCounts{Total: len(subs)}
+	for _, s := range subs {
+		switch s.Status {
+		case domain.SubmissionStatusRead:
+			counts.Read++
+		case domain.SubmissionStatusSpam:
+			counts.Spam++
+		default:
+			counts.Unread++
+		}
+	}
 	if offset >= len(subs) {
-		return []*domain.Submission{}, total, nil
+		return []*domain.Submission{}, counts, nil
 	}
 	end := offset + limit
 	if end > len(subs) {
 		end = len(subs)
 	}
-	return subs[offset:end], total, nil
+	return subs[offset:end], counts, nil
+}
+
+func (r *MockSubmissionRepository) DeleteMatching(ctx context.Context, formID string, filter domain.SubmissionFilter) (int, error) {
+	var kept []*domain.Submission
+	for _, s := range r.submissions[formID] {
+		if (filter.Status == "" || s.Status == filter.Status) &&
+			(filter.Before.IsZero() || s.CreatedAt.Before(filter.Before)) &&
+			(filter.IsTest == nil || s.IsTest == *filter.IsTest) {
+			continue
+		}
+		kept = append(kept, s)
+	}
+	deleted := len(r.submissions[formID]) - len(kept)
+	r.submissions[formID] = kept
+	return deleted, nil
+}
+
+func (r *MockSubmissionRepository) UpdateStatusBatch(ctx context.Context, formID string, ids []string, status domain.SubmissionStatus) (int, error) {
+	updated := 0
+	for _, s := range r.submissions[formID] {
+		if slices.Contains(ids, s.ID) {
+			s.Status = status
+			updated++
+		}
+	}
+	return updated, nil
+}
+
+func (r *MockSubmissionRepository) SetSpamBatch(ctx context.Context, formID string, ids []string, spam bool) (int, error) {
+	marked := 0
+	for _, s := range r.submissions[formID] {
+		if slices.Contains(ids, s.ID) {
+			if s.Meta.Spam == nil {
+				s.Meta.Spam = &domain.SpamMeta{}
+			}
+			s.Meta.Spam.IsSpam, s.Meta.Spam.Manual = spam, true
+			if spam {
+				s.Status = domain.SubmissionStatusSpam
+			} else if s.Status == domain.SubmissionStatusSpam {
+				s.Status = domain.SubmissionStatusUnread
+			}
+			marked++
+		}
+	}
+	return marked, nil
+}
+
+func (r *MockSubmissionRepository) SetSpamVerdict(ctx context.Context, id string, spam domain.SpamMeta) error {
+	for _, subs := range r.submissions {
+		for _, s := range subs {
+			if s.ID != id {
+				continue
+			}
+			s.Meta.Spam = &spam
+			if spam.IsSpam {
+				s.Status = domain.SubmissionStatusSpam
+			} else if s.Status == domain.SubmissionStatusSpam {
+				s.Status = domain.SubmissionStatusUnread
+			}
+		}
+	}
+	return nil
+}
+
+func (r *MockSubmissionRepository) DeleteBatch(ctx context.Context, formID string, ids []string) (int, error) {
+	var kept []*domain.Submission
+	for _, s := range r.submissions[formID] {
+		if !slices.Contains(ids, s.ID) {
+			kept = append(kept, s)
+		}
+	}
+	deleted := len(r.submissions[formID]) - len(kept)
+	r.submissions[formID] = kept
+	return deleted, nil
+}
+
+func (r *MockSubmissionRepository) DeleteTestBefore(ctx context.Context, before time.Time) ([]domain.Tombstone, error) {
+	var deleted []domain.Tombstone
+	for formID, subs := range r.submissions {
+		var kept []*domain.Submission
+		for _, s := range subs {
+			if s.IsTest && s.CreatedAt.Before(before) {
+				deleted = append(deleted, s.Tombstone())
+				continue
+			}
+			kept = append(kept, s)
+		}
+		r.submissions[formID] = kept
+	}
+	return deleted, nil
+}
+
+func (r *MockSubmissionRepository) ListExpired(ctx context.Context, formID string, before time.Time, limit int) ([]*domain.Submission, error) {
+	var expired []*domain.Submission
+	for _, s := range r.submissions[formID] {
+		if s.CreatedAt.Before(before) && s.Meta.AnonymizedAt == nil {
+			expired = append(expired, s)
+		}
+	}
+	slices.SortFunc(expired, func(a, b *domain.Submission) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	if len(expired) > limit {
+		expired = expired[:limit]
+	}
+	return expired, nil
+}
+
+func (r *MockSubmissionRepository) Anonymize(ctx context.Context, id string, data json.RawMessage, meta domain.SubmissionMeta) error {
+	for _, subs := range r.submissions {
+		for _, s := range subs {
+			if s.ID == id {
+				s.Data = data
+				s.Meta = meta
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+func (r *MockSubmissionRepository) RecordView(ctx context.Context, submissionID, userID string, at time.Time) error {
+	return nil
+}
+
+func (r *MockSubmissionRepository) ListViews(ctx context.Context, submissionID string) ([]domain.SubmissionView, error) {
+	return nil, nil
+}
+
+func (r *MockSubmissionRepository) Link(ctx context.Context, link *domain.SubmissionLink) error {
+	return nil
+}
+
+func (r *MockSubmissionRepository) Unlink(ctx context.Context, submissionID, linkedID string) (bool, error) {
+	return false, nil
+}
+
+func (r *MockSubmissionRepository) ListLinks(ctx context.Context, submissionID string) ([]domain.SubmissionLink, error) {
+	return nil, nil
 }
 
 func (r *MockSubmissionRepository) UpdateStatus(ctx context.Context, id string, status domain.SubmissionStatus) error {
@@ -236,6 +732,17 @@ func (r *MockSubmissionRepository) UpdateStatus(ctx context.Context, id string,
 	return nil
 }
 
+func (r *MockSubmissionRepository) UpdateData(ctx context.Context, id string, data json.RawMessage) error {
+	for _, subs := range r.submissions {
+		for _, s := range subs {
+			if s.ID == id {
+				s.Data = data
+			}
+		}
+	}
+	return nil
+}
+
 func (r *MockSubmissionRepository) Delete(ctx context.Context, id string) error {
 	for formID, subs := range r.submissions {
 		for i, s := range subs {
@@ -254,7 +761,7 @@ type MockStatsRepository struct {
 	submissions map[string][]*domain.Submission
 }
 
-func (r *MockStatsRepository) GetDashboardStats(ctx context.Context) (*domain.DashboardStats, error) {
+func (r *MockStatsRepository) GetDashboardStats(ctx context.Context, loc *time.Location) (*domain.DashboardStats, error) {
 	total := 0
 	for _, subs := range r.submissions {
 		total += len(subs)
@@ -266,13 +773,21 @@ func (r *MockStatsRepository) GetDashboardStats(ctx context.Context) (*domain.Da
 	}, nil
 }
 
-func (r *MockStatsRepository) GetFormStats(ctx context.Context, formID string) (*domain.FormStats, error) {
+func (r *MockStatsRepository) GetFormStats(ctx context.Context, formID string, loc *time.Location) (*domain.FormStats, error) {
 	return &domain.FormStats{
 		FormID:           formID,
 		TotalSubmissions: len(r.submissions[formID]),
 	}, nil
 }
 
+func (r *MockStatsRepository) GetFormDailyStats(ctx context.Context, formID string, from, to time.Time, loc *time.Location) ([]domain.DailyFormStats, error) {
+	return nil, nil
+}
+
+func (r *MockStatsRepository) GetFormActivity(ctx context.Context, formID string, since time.Time) (*domain.FormActivity, error) {
+	return &domain.FormActivity{Submissions: len(r.submissions[formID])}, nil
+}
+
 // Tests
 func TestFormService_CreateForm(t *testing.T) {
 	repo := NewMockRepository()
@@ -316,14 +831,374 @@ func TestFormService_ListForms(t *testing.T) {
 	}
 }
 
-func TestSubmissionService_Submit(t *testing.T) {
+func TestFormService_RotatePublicIDGrace(t *testing.T) {
 	repo := NewMockRepository()
-	formSvc := NewFormService(repo)
+	svc := NewFormService(repo)
+	ctx := context.Background()
+
+	form, _ := svc.CreateForm(ctx, "Scraped", "", nil, "", "", "", "public", "")
+	oldID := form.PublicID
+	rotated, err := svc.RotatePublicID(ctx, oldID, time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, previous, err := svc.GetSubmitForm(ctx, oldID)
+	if err != nil || !previous || got.PublicID != rotated.PublicID {
+		t.Fatalf("expected the old ID to find the form during the grace period, got %v, %v, %v", got, previous, err)
+	}
+	if _, previous, err := svc.GetSubmitForm(ctx, rotated.PublicID); err != nil || previous {
+		t.Errorf("expected the new ID to be current, got %v, %v", previous, err)
+	}
+
+	// Once the grace period ends the old ID is unknown
+	ended := time.Now().Add(-time.Minute)
+	rotated.PreviousPublicIDUntil = &ended
+	if _, _, err := svc.GetSubmitForm(ctx, oldID); !errors.Is(err, domain.ErrFormNotFound) {
+		t.Errorf("expected ErrFormNotFound after the grace period, got %v", err)
+	}
+
+	if _, err := svc.RotatePublicID(ctx, rotated.PublicID, -time.Hour); !errors.Is(err, domain.ErrInvalidPublicIDGrace) {
+		t.Errorf("expected ErrInvalidPublicIDGrace, got %v", err)
+	}
+}
+
+func TestFormService_TwoStepDeleteAndPurge(t *testing.T) {
+	repo := NewMockRepository()
+	svc := NewFormService(repo)
+	ctx := context.Background()
+
+	var archived *domain.Form
+	svc.SetArchiver(func(form *domain.Form, submissions []*domain.Submission) (string, error) {
+		archived = form
+		return "/archives/" + form.PublicID + ".json", nil
+	})
+
+	form, _ := svc.CreateForm(ctx, "Doomed", "", nil, "", "", "", "public", "")
+	if _, err := svc.ConfirmDelete(ctx, form.PublicID, "guess"); err != domain.ErrInvalidDeleteToken {
+		t.Fatalf("expected ErrInvalidDeleteToken without a request, got %v", err)
+	}
+
+	token, _, err := svc.RequestDelete(ctx, form.PublicID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	deleted, err := svc.ConfirmDelete(ctx, form.PublicID, token)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if archived == nil || deleted.ArchivePath == "" || !deleted.IsDeleted() {
+		t.Fatalf("expected archived, deleted form, got %+v", deleted)
+	}
+	if _, err := svc.GetForm(ctx, form.PublicID); err != domain.ErrFormNotFound {
+		t.Errorf("expected deleted form to be hidden, got %v", err)
+	}
+
+	// Tokens are single-use
+	if _, err := svc.ConfirmDelete(ctx, form.PublicID, token); err != domain.ErrInvalidDeleteToken {
+		t.Errorf("expected reused token to be rejected, got %v", err)
+	}
+
+	// Not purged during the grace period, purged after it
+	if n, _ := svc.PurgeDeleted(ctx); n != 0 {
+		t.Errorf("expected nothing purged yet, got %d", n)
+	}
+	past := time.Now().Add(-time.Minute)
+	deleted.PurgeAt = &past
+	if n, _ := svc.PurgeDeleted(ctx); n != 1 {
+		t.Errorf("expected 1 purged form, got %d", n)
+	}
+	if _, err := svc.RestoreForm(ctx, form.PublicID); err != domain.ErrFormNotFound {
+		t.Errorf("expected purged form to be gone, got %v", err)
+	}
+}
+
+// waitForTask polls a task until it leaves the running state
+func waitForTask(t *testing.T, tasks *TaskService, id string) *domain.Task {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		task, err := tasks.Get(context.Background(), id)
+		if err != nil {
+			t.Fatalf("get task: %v", err)
+		}
+		if task.Status.IsFinal() {
+			return task
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("task %s still %s", id, task.Status)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestTaskService_ProgressAndCancel(t *testing.T) {
+	repo := NewMockRepository()
+	tasks := NewTaskService(repo)
+	ctx := context.Background()
+
+	started := make(chan struct{})
+	task, err := tasks.StartExclusive("import", "user-1", func(ctx context.Context, p *TaskProgress) (interface{}, error) {
+		p.Update(40, "halfway-ish")
+		close(started)
+		<-ctx.Done()
+		return map[string]int{"rows": 4}, ctx.Err()
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	updates, unsubscribe := tasks.Subscribe(task.ID)
+	defer unsubscribe()
+	<-started
+
+	if got, _ := tasks.Get(ctx, task.ID); got.Status != domain.TaskRunning || got.Progress != 40 || got.Message != "halfway-ish" {
+		t.Errorf("unexpected running state: %+v", got)
+	}
+	if _, err := tasks.StartExclusive("import", "user-1", nil); err != domain.ErrTaskInProgress {
+		t.Errorf("expected ErrTaskInProgress, got %v", err)
+	}
+
+	if _, err := tasks.Cancel(ctx, task.ID); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	final := waitForTask(t, tasks, task.ID)
+	if final.Status != domain.TaskCancelled || string(final.Result) != `{"rows":4}` || final.FinishedAt == nil {
+		t.Errorf("unexpected final state: %+v", final)
+	}
+	for range updates {
+		// Drained; the channel is closed once the task finishes
+	}
+
+	if _, err := tasks.Cancel(ctx, task.ID); err != domain.ErrTaskFinished {
+		t.Errorf("expected ErrTaskFinished, got %v", err)
+	}
+	if _, err := tasks.Get(ctx, "missing"); err != domain.ErrTaskNotFound {
+		t.Errorf("expected ErrTaskNotFound, got %v", err)
+	}
+
+	// Tasks left running by a previous process are failed on startup
+	_ = repo.Task().Create(ctx, &domain.Task{ID: "stale", Type: "import", Status: domain.TaskRunning})
+	if n, _ := tasks.FailInterrupted(ctx); n != 1 {
+		t.Errorf("expected 1 interrupted task, got %d", n)
+	}
+	if stale, _ := tasks.Get(ctx, "stale"); stale.Status != domain.TaskFailed {
+		t.Errorf("expected stale task failed, got %s", stale.Status)
+	}
+}
+
+// memBus is an in-memory ports.EventBus standing in for Redis between two replicas
+type memBus struct {
+	mu   sync.Mutex
+	subs map[string][]chan []byte
+}
+
+func (b *memBus) Publish(ctx context.Context, topic string, payload []byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, ch := range b.subs[topic] {
+		select {
+		case ch <- payload:
+		default:
+		}
+	}
+	return nil
+}
+
+func (b *memBus) Subscribe(topic string) (<-chan []byte, func()) {
+	ch := make(chan []byte, 16)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.subs == nil {
+		b.subs = make(map[string][]chan []byte)
+	}
+	b.subs[topic] = append(b.subs[topic], ch)
+	return ch, func() {}
+}
+
+func TestTaskService_FollowsOtherReplicas(t *testing.T) {
+	repo := NewMockRepository()
+	bus := &memBus{}
+	runner, viewer := NewTaskService(repo), NewTaskService(repo)
+	runner.SetEventBus(bus)
+	viewer.SetEventBus(bus)
+
+	release := make(chan struct{})
+	task, err := runner.Start("import", "user-1", func(ctx context.Context, p *TaskProgress) (interface{}, error) {
+		<-release
+		p.Update(50, "halfway")
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The viewer replica isn't running the task, so it follows it through the bus
+	updates, unsubscribe := viewer.Subscribe(task.ID)
+	defer unsubscribe()
+	close(release)
+
+	var got []domain.Task
+	timeout := time.After(5 * time.Second)
+	for done := false; !done; {
+		select {
+		case update, ok := <-updates:
+			if !ok {
+				done = true
+				break
+			}
+			got = append(got, update)
+		case <-timeout:
+			t.Fatal("updates channel not closed")
+		}
+	}
+	if len(got) != 2 || got[0].Progress != 50 || got[0].Message != "halfway" || got[1].Status != domain.TaskCompleted {
+		t.Errorf("expected progress then the final state, got %+v", got)
+	}
+
+	// Without a bus, tasks running elsewhere can't be followed
+	local, _ := NewTaskService(repo).Subscribe(task.ID)
+	if _, ok := <-local; ok {
+		t.Error("expected a closed channel without an event bus")
+	}
+}
+
+func TestSubmissionFeed(t *testing.T) {
+	receive := func(ch <-chan domain.Submission) *domain.Submission {
+		t.Helper()
+		select {
+		case sub := <-ch:
+			return &sub
+		case <-time.After(5 * time.Second):
+			return nil
+		}
+	}
+
+	feed := NewSubmissionFeed()
+	updates, unsubscribe := feed.Subscribe("form-1")
+	other, unsubscribeOther := feed.Subscribe("form-2")
+	defer unsubscribeOther()
+	feed.Publish(&domain.Submission{ID: "sub-1", FormID: "form-1"})
+	if sub := receive(updates); sub == nil || sub.ID != "sub-1" {
+		t.Fatalf("expected sub-1, got %+v", sub)
+	}
+	select {
+	case sub := <-other:
+		t.Errorf("expected nothing for another form, got %+v", sub)
+	default:
+	}
+	unsubscribe()
+	feed.Publish(&domain.Submission{ID: "sub-2", FormID: "form-1"})
+	if len(updates) != 0 {
+		t.Error("expected nothing after unsubscribing")
+	}
+
+	// Replicas sharing a bus see each other's submissions
+	bus := &memBus{}
+	storing, viewing := NewSubmissionFeed(), NewSubmissionFeed()
+	storing.SetEventBus(bus)
+	viewing.SetEventBus(bus)
+	remote, unsubscribeRemote := viewing.Subscribe("form-1")
+	defer unsubscribeRemote()
+	storing.Publish(&domain.Submission{ID: "sub-3", FormID: "form-1", Status: domain.SubmissionStatusUnread})
+	if sub := receive(remote); sub == nil || sub.ID != "sub-3" || sub.Status != domain.SubmissionStatusUnread {
+		t.Errorf("expected sub-3 through the bus, got %+v", sub)
+	}
+}
+
+func TestTaskService_Files(t *testing.T) {
+	repo := NewMockRepository()
+	tasks := NewTaskService(repo)
+	files := &memFiles{files: make(map[string]string)}
+	tasks.SetFileStorage(files)
+	ctx := context.Background()
+
+	task, err := tasks.Start(domain.TaskTypeExportParquet, "user-1", func(ctx context.Context, p *TaskProgress) (interface{}, error) {
+		return nil, p.SaveFile(ctx, "export.parquet", "application/vnd.apache.parquet", strings.NewReader("PAR1"), 4)
+	})
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	final := waitForTask(t, tasks, task.ID)
+	if final.File == nil || final.File.Name != "export.parquet" || final.File.Size != 4 {
+		t.Fatalf("expected the file on the task, got %+v", final.File)
+	}
+
+	file, r, err := tasks.OpenFile(ctx, final)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	body, _ := io.ReadAll(r)
+	_ = r.Close()
+	if file.ContentType != "application/vnd.apache.parquet" || string(body) != "PAR1" {
+		t.Errorf("unexpected file %+v: %q", file, body)
+	}
+
+	// Nothing is removed before the file expires
+	if n, err := tasks.PurgeFiles(ctx); err != nil || n != 0 {
+		t.Errorf("expected no purge, got %d, %v", n, err)
+	}
+	stored, _ := repo.Task().GetByID(ctx, task.ID)
+	stored.File.ExpiresAt = time.Now().Add(-time.Minute)
+	_ = repo.Task().Update(ctx, stored)
+	if _, _, err := tasks.OpenFile(ctx, stored); !errors.Is(err, domain.ErrTaskNoFile) {
+		t.Errorf("expected ErrTaskNoFile once expired, got %v", err)
+	}
+	if n, err := tasks.PurgeFiles(ctx); err != nil || n != 1 {
+		t.Fatalf("expected 1 purged file, got %d, %v", n, err)
+	}
+	if len(files.files) != 0 {
+		t.Errorf("expected the file to be deleted, got %v", files.files)
+	}
+	if got, _ := tasks.Get(ctx, task.ID); got.File != nil {
+		t.Errorf("expected the file to be cleared from the task, got %+v", got.File)
+	}
+}
+
+func TestSeedService_BatchedTestData(t *testing.T) {
+	repo := NewMockRepository()
+	tasks := NewTaskService(repo)
+	svc := NewSeedService(repo, tasks)
+	svc.batchSize = 2
+
+	task, err := svc.Start("owner-1", 2, 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if task.Type != domain.TaskTypeSeed || task.CreatedBy != "owner-1" {
+		t.Errorf("unexpected task: %+v", task)
+	}
+
+	task = waitForTask(t, tasks, task.ID)
+	var result SeedResult
+	_ = json.Unmarshal(task.Result, &result)
+	if task.Status != domain.TaskCompleted || result.FormsCreated != 2 || result.SubmissionsCreated != 6 || task.Progress != 100 {
+		t.Fatalf("unexpected task state: %+v (result %+v)", task, result)
+	}
+
+	for _, form := range repo.forms {
+		if form.OwnerID != "owner-1" {
+			t.Errorf("expected seeded form owned by owner-1, got %q", form.OwnerID)
+		}
+		subs := repo.submissions[form.ID]
+		if len(subs) != 3 {
+			t.Errorf("expected 3 submissions for %s, got %d", form.PublicID, len(subs))
+		}
+		for _, s := range subs {
+			if !s.IsTest {
+				t.Errorf("expected seeded submission %s to be marked as test", s.ID)
+			}
+		}
+	}
+}
+
+func TestSubmissionService_Submit(t *testing.T) {
+	repo := NewMockRepository()
+	formSvc := NewFormService(repo)
 	submSvc := NewSubmissionService(repo)
 
 	form, _ := formSvc.CreateForm(context.Background(), "Test Form", "", nil, "", "", "", "public", "")
 
-	sub, err := submSvc.Submit(context.Background(), form.PublicID, map[string]interface{}{"email": "test@example.com"}, nil)
+	sub, err := submSvc.Submit(context.Background(), form.PublicID, map[string]interface{}{"email": "test@example.com"}, domain.SubmissionMeta{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -335,30 +1210,1289 @@ func TestSubmissionService_Submit(t *testing.T) {
 	}
 }
 
-func TestSubmissionService_Submit_FormNotFound(t *testing.T) {
+func TestSubmissionService_Submit_PrivateRequiresAuth(t *testing.T) {
 	repo := NewMockRepository()
+	formSvc := NewFormService(repo)
 	submSvc := NewSubmissionService(repo)
 
-	_, err := submSvc.Submit(context.Background(), "nonexistent", nil, nil)
-	if err != domain.ErrFormNotFound {
-		t.Errorf("expected ErrFormNotFound, got %v", err)
+	form, _ := formSvc.CreateForm(context.Background(), "Private Form", "", nil, "", "", "", "private", "")
+	data := map[string]interface{}{"email": "test@example.com"}
+
+	if _, err := submSvc.Submit(context.Background(), form.PublicID, data, domain.SubmissionMeta{}); err != domain.ErrAuthRequired {
+		t.Errorf("expected ErrAuthRequired, got %v", err)
+	}
+
+	meta := domain.SubmissionMeta{Auth: &domain.AuthMeta{UserID: "user-1"}}
+	sub, err := submSvc.Submit(context.Background(), form.PublicID, data, meta)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sub.Meta.Auth == nil || sub.Meta.Auth.UserID != "user-1" {
+		t.Errorf("expected auth user recorded in meta, got %+v", sub.Meta.Auth)
 	}
 }
 
-func TestSubmissionService_ListSubmissions(t *testing.T) {
+func TestSubmissionService_CheckAccess_Signature(t *testing.T) {
 	repo := NewMockRepository()
 	formSvc := NewFormService(repo)
 	submSvc := NewSubmissionService(repo)
 
-	form, _ := formSvc.CreateForm(context.Background(), "Test Form", "", nil, "", "", "", "public", "")
-	_, _ = submSvc.Submit(context.Background(), form.PublicID, map[string]interface{}{"email": "a@b.com"}, nil)
-	_, _ = submSvc.Submit(context.Background(), form.PublicID, map[string]interface{}{"email": "c@d.com"}, nil)
+	form, _ := formSvc.CreateForm(context.Background(), "Backend Form", "", nil, "", "", "", "private", "s3cret")
+	body := []byte(`{"email":"test@example.com"}`)
+	sign := func(key, prefix string) string {
+		mac := hmac.New(sha256.New, []byte(key))
+		mac.Write([]byte(prefix))
+		mac.Write(body)
+		return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	}
+	now := time.Now().UTC().Format(time.RFC3339)
+	old := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
 
-	subs, err := submSvc.ListSubmissions(context.Background(), form.PublicID)
+	cases := []struct {
+		name string
+		sig  domain.RequestSignature
+		want error
+	}{
+		{"valid", domain.RequestSignature{Value: sign("s3cret", now+"."), Timestamp: now, Body: body}, nil},
+		{"missing timestamp", domain.RequestSignature{Value: sign("s3cret", ""), Body: body}, domain.ErrInvalidSignature},
+		{"malformed timestamp", domain.RequestSignature{Value: sign("s3cret", "yesterday."), Timestamp: "yesterday", Body: body}, domain.ErrInvalidSignature},
+		{"wrong key", domain.RequestSignature{Value: sign("other", now+"."), Timestamp: now, Body: body}, domain.ErrInvalidSignature},
+		{"tampered body", domain.RequestSignature{Value: sign("s3cret", now+"."), Timestamp: now, Body: []byte(`{"email":"x@example.com"}`)}, domain.ErrInvalidSignature},
+		{"expired timestamp", domain.RequestSignature{Value: sign("s3cret", old+"."), Timestamp: old, Body: body}, domain.ErrInvalidSignature},
+		{"unknown algorithm", domain.RequestSignature{Value: "md5=abc", Body: body}, domain.ErrInvalidSignature},
+	}
+	for _, tc := range cases {
+		sig := tc.sig
+		if err := submSvc.CheckAccess(form, map[string]interface{}{}, domain.SubmissionMeta{Signature: &sig}); err != tc.want {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.want, err)
+		}
+	}
+
+	// Forms without a submission key can't be signed for
+	keyless, _ := formSvc.CreateForm(context.Background(), "Keyless", "", nil, "", "", "", "private", "")
+	sig := domain.RequestSignature{Value: sign("", now+"."), Timestamp: now, Body: body}
+	if err := submSvc.CheckAccess(keyless, map[string]interface{}{}, domain.SubmissionMeta{Signature: &sig}); err != domain.ErrInvalidSignature {
+		t.Errorf("expected ErrInvalidSignature without a key, got %v", err)
+	}
+}
+
+func TestSubmissionService_Submit_RequiresConsent(t *testing.T) {
+	repo := NewMockRepository()
+	formSvc := NewFormService(repo)
+	submSvc := NewSubmissionService(repo)
+
+	form, _ := formSvc.CreateForm(context.Background(), "Newsletter", "", nil, "", "", "", "public", "")
+	if _, err := formSvc.SetConsent(context.Background(), form.PublicID, &domain.ConsentConfig{Fields: []string{" "}}); !errors.Is(err, domain.ErrConsentFieldRequired) {
+		t.Fatalf("expected ErrConsentFieldRequired, got %v", err)
+	}
+	if _, err := formSvc.SetConsent(context.Background(), form.PublicID, &domain.ConsentConfig{Fields: []string{"terms", "marketing", "terms"}, Version: "v2"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err := submSvc.Submit(context.Background(), form.PublicID, map[string]interface{}{"terms": "on", "marketing": "off"}, domain.SubmissionMeta{})
+	if !errors.Is(err, domain.ErrConsentRequired) || !strings.Contains(err.Error(), "marketing") {
+		t.Errorf("expected ErrConsentRequired naming marketing, got %v", err)
+	}
+
+	sub, err := submSvc.Submit(context.Background(), form.PublicID, map[string]interface{}{"terms": "on", "marketing": true}, domain.SubmissionMeta{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if len(subs) != 2 {
-		t.Errorf("expected 2 submissions, got %d", len(subs))
+	if c := sub.Meta.Consent; c == nil || c.Version != "v2" || len(c.Fields) != 2 || c.AcceptedAt.IsZero() {
+		t.Errorf("expected consent recorded in meta, got %+v", sub.Meta.Consent)
+	}
+}
+
+func TestSubmissionService_Submit_ValidatesFields(t *testing.T) {
+	repo := NewMockRepository()
+	formSvc := NewFormService(repo)
+	submSvc := NewSubmissionService(repo)
+
+	form, _ := formSvc.CreateForm(context.Background(), "Signup", "", nil, "", "", "", "public", "")
+	for name, field := range map[string]domain.FormField{
+		"unknown type":    {Name: "a", FieldRules: domain.FieldRules{Type: "phone"}},
+		"reversed length": {Name: "a", FieldRules: domain.FieldRules{MinLength: 5, MaxLength: 2}},
+		"bad pattern":     {Name: "a", FieldRules: domain.FieldRules{Pattern: "[a-"}},
+	} {
+		if _, err := formSvc.SetFields(context.Background(), form.PublicID, []domain.FormField{field}); err == nil {
+			t.Errorf("%s: expected the schema to be rejected", name)
+		}
+	}
+	_, err := formSvc.SetFields(context.Background(), form.PublicID, []domain.FormField{
+		{Name: "email", FieldRules: domain.FieldRules{Type: domain.FieldTypeEmail, Required: true}},
+		{Name: "age", FieldRules: domain.FieldRules{Type: domain.FieldTypeNumber}},
+		{Name: "website", FieldRules: domain.FieldRules{Type: domain.FieldTypeURL}},
+		{Name: "name", FieldRules: domain.FieldRules{MinLength: 2, MaxLength: 5}},
+		{Name: "zip", FieldRules: domain.FieldRules{Pattern: `[0-9]{5}`}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err = submSvc.Submit(context.Background(), form.PublicID, map[string]interface{}{
+		"age": "twelve", "website": "ftp://example.com", "name": "Ébenézer", "zip": "123456",
+	}, domain.SubmissionMeta{})
+	var fieldErrs domain.FieldErrors
+	if !errors.As(err, &fieldErrs) {
+		t.Fatalf("expected FieldErrors, got %v", err)
+	}
+	rules := map[string]string{}
+	for _, e := range fieldErrs {
+		rules[e.Field] = e.Rule
+	}
+	want := map[string]string{"email": "required", "age": "type", "website": "type", "name": "max_length", "zip": "pattern"}
+	for field, rule := range want {
+		if rules[field] != rule {
+			t.Errorf("%s: expected %s error, got %q", field, rule, rules[field])
+		}
+	}
+
+	// Numbers may arrive as JSON numbers or form-encoded text; unknown keys aren't checked
+	for _, age := range []interface{}{42.0, "42"} {
+		data := map[string]interface{}{"email": "ada@example.com", "age": age, "website": "https://example.com", "name": "Ada", "zip": "10115", "other": 1}
+		if _, err := submSvc.Submit(context.Background(), form.PublicID, data, domain.SubmissionMeta{}); err != nil {
+			t.Errorf("age %v: unexpected error: %v", age, err)
+		}
+	}
+}
+
+func TestStatsService_GetFieldStats(t *testing.T) {
+	repo := NewMockRepository()
+	formSvc := NewFormService(repo)
+	submSvc := NewSubmissionService(repo)
+	statsSvc := NewStatsService(repo)
+
+	form, _ := formSvc.CreateForm(context.Background(), "Signup", "", nil, "", "", "", "public", "")
+	_, err := formSvc.SetFields(context.Background(), form.PublicID, []domain.FormField{
+		{Name: "name"},
+		{Name: "budget", FieldRules: domain.FieldRules{Type: domain.FieldTypeNumber}},
+		{Name: "signup", FieldRules: domain.FieldRules{Type: domain.FieldTypeDate}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := submSvc.Submit(context.Background(), form.PublicID, map[string]interface{}{"signup": "yesterday"}, domain.SubmissionMeta{}); err == nil {
+		t.Error("expected an invalid date to be rejected")
+	}
+
+	for _, data := range []map[string]interface{}{
+		{"budget": 100.0, "signup": "2026-01-05"},
+		{"budget": "250", "signup": "2026-01-05T18:30"},
+		{"budget": 1000.0, "signup": "2026-01-07T10:00:00Z"},
+		{"name": "Ada"},
+	} {
+		if _, err := submSvc.Submit(context.Background(), form.PublicID, data, domain.SubmissionMeta{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	// Spam isn't counted
+	spam, _ := json.Marshal(map[string]interface{}{"budget": 1e9})
+	_ = repo.Submission().Create(context.Background(), &domain.Submission{ID: "spam", FormID: form.ID, Data: spam, Meta: domain.SubmissionMeta{Spam: &domain.SpamMeta{IsSpam: true}}})
+
+	stats, err := statsSvc.GetFieldStats(context.Background(), form.PublicID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(stats) != 2 || stats[0].Field != "budget" || stats[1].Field != "signup" {
+		t.Fatalf("expected stats for budget and signup, got %+v", stats)
+	}
+
+	budget := stats[0]
+	if budget.Count != 3 || budget.Missing != 1 || budget.Number == nil {
+		t.Fatalf("unexpected budget stats %+v", budget)
+	}
+	if n := budget.Number; n.Sum != 1350 || n.Average != 450 || n.Min != 100 || n.Max != 1000 {
+		t.Errorf("unexpected budget numbers %+v", n)
+	}
+	if h := budget.Number.Histogram; len(h) != 10 || h[0].Count != 1 || h[1].Count != 1 || h[9].Count != 1 || h[9].To != 1000 {
+		t.Errorf("unexpected budget histogram %+v", h)
+	}
+
+	signup := stats[1].Date
+	if stats[1].Count != 3 || signup == nil || signup.Min != "2026-01-05" || signup.Max != "2026-01-07" || signup.Interval != "day" {
+		t.Fatalf("unexpected signup stats %+v %+v", stats[1], signup)
+	}
+	want := []domain.DateBucket{{Date: "2026-01-05", Count: 2}, {Date: "2026-01-06"}, {Date: "2026-01-07", Count: 1}}
+	if !slices.Equal(signup.Histogram, want) {
+		t.Errorf("expected %+v, got %+v", want, signup.Histogram)
+	}
+
+	// Dates spanning years are counted by month, and by year past a decade
+	for _, tc := range []struct{ from, to, interval string }{
+		{"2025-11-20", "2026-03-02", "month"},
+		{"2001-05-01", "2026-03-02", "year"},
+	} {
+		form, _ := formSvc.CreateForm(context.Background(), "Dates", "", nil, "", "", "", "public", "")
+		_, _ = formSvc.SetFields(context.Background(), form.PublicID, []domain.FormField{{Name: "d", FieldRules: domain.FieldRules{Type: domain.FieldTypeDate}}})
+		for _, d := range []string{tc.from, tc.to} {
+			_, _ = submSvc.Submit(context.Background(), form.PublicID, map[string]interface{}{"d": d}, domain.SubmissionMeta{})
+		}
+		stats, _ := statsSvc.GetFieldStats(context.Background(), form.PublicID)
+		h := stats[0].Date.Histogram
+		if stats[0].Date.Interval != tc.interval || h[0].Count != 1 || h[len(h)-1].Count != 1 {
+			t.Errorf("%s: unexpected stats %+v", tc.interval, stats[0].Date)
+		}
+	}
+
+	// Submissions are read twice rather than held; one arriving in between is left out
+	// of the histograms so they match the counts
+	subs, _ := repo.Submission().GetByFormID(context.Background(), form.ID)
+	passes := 0
+	each := func(fn func(*domain.Submission) error) error {
+		if passes++; passes == 2 {
+			subs = append(subs, &domain.Submission{Data: []byte(`{"budget": 500}`), CreatedAt: time.Now().Add(time.Minute)})
+		}
+		for _, sub := range subs {
+			if err := fn(sub); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	stats, err = form.FieldStats(each, time.UTC)
+	if err != nil || passes != 2 {
+		t.Fatalf("expected two passes, got %d (%v)", passes, err)
+	}
+	counted := 0
+	for _, b := range stats[0].Number.Histogram {
+		counted += b.Count
+	}
+	if stats[0].Count != 3 || counted != 3 {
+		t.Errorf("expected the late submission left out, got count %d and %d in the histogram", stats[0].Count, counted)
+	}
+}
+
+func TestSubmissionService_SendTestNotification(t *testing.T) {
+	repo := NewMockRepository()
+	formSvc := NewFormService(repo)
+	submSvc := NewSubmissionService(repo)
+
+	notified := make(chan *domain.Submission, 1)
+	submSvc.SetNotificationCallback(func(form *domain.Form, submission *domain.Submission, data map[string]interface{}) {
+		notified <- submission
+	})
+
+	form, _ := formSvc.CreateForm(context.Background(), "Contact", "", nil, "", "", "", "public", "")
+	sub, err := submSvc.SendTestNotification(context.Background(), form.PublicID, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !sub.IsTest {
+		t.Error("expected submission to be marked as test")
+	}
+
+	select {
+	case got := <-notified:
+		if got.ID != sub.ID {
+			t.Errorf("expected callback for %s, got %s", sub.ID, got.ID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("notification callback not called")
+	}
+
+	subs, _ := submSvc.ListSubmissions(context.Background(), form.PublicID)
+	if len(subs) != 0 {
+		t.Errorf("expected test submission not to be stored, got %d", len(subs))
+	}
+
+	if _, err := submSvc.SendTestNotification(context.Background(), "missing", nil); err != domain.ErrFormNotFound {
+		t.Errorf("expected ErrFormNotFound, got %v", err)
+	}
+}
+
+func TestSubmissionService_SpamQuarantine(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMockRepository()
+	form, _ := NewFormService(repo).CreateForm(ctx, "Contact", "", nil, "", "", "", "public", "")
+	submSvc := NewSubmissionService(repo)
+	notified := make(chan string, 2)
+	submSvc.SetNotificationCallback(func(form *domain.Form, submission *domain.Submission, data map[string]interface{}) {
+		notified <- submission.ID
+	})
+
+	spam, err := submSvc.Submit(ctx, form.PublicID, map[string]interface{}{"name": "Bot"}, domain.SubmissionMeta{Spam: &domain.SpamMeta{Score: 100, IsSpam: true}})
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	if spam.Status != domain.SubmissionStatusSpam {
+		t.Errorf("expected flagged submission quarantined, got %s", spam.Status)
+	}
+	ham, _ := submSvc.Submit(ctx, form.PublicID, map[string]interface{}{"name": "Ada"}, domain.SubmissionMeta{Spam: &domain.SpamMeta{Score: 10}})
+	if ham.Status != domain.SubmissionStatusUnread {
+		t.Errorf("expected submission under the threshold unread, got %s", ham.Status)
+	}
+
+	// Only the genuine submission is notified about
+	select {
+	case id := <-notified:
+		if id != ham.ID {
+			t.Errorf("expected notification for %s, got %s", ham.ID, id)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("notification callback not called")
+	}
+	select {
+	case id := <-notified:
+		t.Errorf("unexpected notification for %s", id)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if err := submSvc.MarkAsNotSpam(ctx, spam.FormID, spam.ID); err != nil {
+		t.Fatalf("mark not spam: %v", err)
+	}
+	inbox, counts, _ := submSvc.ListSubmissionsPaginated(ctx, form.PublicID, "", domain.SubmissionListQuery{Status: domain.SubmissionListInbox}, 1, 10)
+	if len(inbox) != 2 || counts.Unread != 2 {
+		t.Errorf("expected both submissions unread in the inbox, got %d (%+v)", len(inbox), counts)
+	}
+}
+
+func TestSubmissionService_SaveTestAndPurge(t *testing.T) {
+	repo := NewMockRepository()
+	formSvc := NewFormService(repo)
+	submSvc := NewSubmissionService(repo)
+
+	form, _ := formSvc.CreateForm(context.Background(), "Seeded", "", nil, "", "", "", "public", "")
+	sub, err := submSvc.SaveTest(context.Background(), form, map[string]interface{}{"name": "Seed"}, domain.SubmissionMeta{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !sub.IsTest {
+		t.Error("expected submission to be marked as test")
+	}
+	if form.SubmissionCount != 0 {
+		t.Errorf("expected test submission not to be counted, got %d", form.SubmissionCount)
+	}
+	_, _ = submSvc.Save(context.Background(), form, map[string]interface{}{"name": "Real"}, domain.SubmissionMeta{})
+
+	// Within the retention period nothing is purged
+	if n, _ := submSvc.PurgeTestSubmissions(context.Background()); n != 0 {
+		t.Errorf("expected nothing purged yet, got %d", n)
+	}
+
+	sub.CreatedAt = time.Now().Add(-DefaultTestRetention - time.Hour)
+	n, err := submSvc.PurgeTestSubmissions(context.Background())
+	if err != nil || n != 1 {
+		t.Fatalf("expected 1 test submission purged, got %d (%v)", n, err)
+	}
+	subs, _ := submSvc.ListSubmissions(context.Background(), form.PublicID)
+	if len(subs) != 1 || subs[0].IsTest {
+		t.Errorf("expected only the real submission to remain, got %v", subs)
+	}
+}
+
+func TestSubmissionService_ApplyRetention(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMockRepository()
+	formSvc := NewFormService(repo)
+	submSvc := NewSubmissionService(repo)
+
+	if _, err := formSvc.SetRetention(ctx, "missing", -1, ""); !errors.Is(err, domain.ErrInvalidRetention) {
+		t.Errorf("expected ErrInvalidRetention for negative days, got %v", err)
+	}
+	deleting, _ := formSvc.CreateForm(ctx, "Deleting", "", nil, "", "", "", "public", "")
+	anonymizing, _ := formSvc.CreateForm(ctx, "Anonymizing", "", nil, "", "", "", "public", "")
+	keeping, _ := formSvc.CreateForm(ctx, "Keeping", "", nil, "", "", "", "public", "")
+	if f, err := formSvc.SetRetention(ctx, deleting.PublicID, 30, ""); err != nil || f.RetentionAction != domain.RetentionDelete {
+		t.Fatalf("expected delete by default, got %+v (%v)", f, err)
+	}
+	if _, err := formSvc.SetRetention(ctx, anonymizing.PublicID, 30, domain.RetentionAnonymize); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	save := func(form *domain.Form, age time.Duration) *domain.Submission {
+		sub, _ := submSvc.Save(ctx, form, map[string]interface{}{"email": "ada@example.com"},
+			domain.SubmissionMeta{Server: domain.ServerMeta{IP: "203.0.113.7", UserAgent: "Firefox"}})
+		sub.CreatedAt = time.Now().Add(-age)
+		return sub
+	}
+	const month = 31 * 24 * time.Hour
+	expired, recent := save(deleting, month), save(deleting, time.Hour)
+	anonymized := save(anonymizing, month)
+	save(keeping, 10*month)
+
+	var deletedIDs []string
+	submSvc.SetDeletionCallback(func(form *domain.Form, reason domain.DeletionReason, tombstones []domain.Tombstone) {
+		for _, t := range tombstones {
+			deletedIDs = append(deletedIDs, t.SubmissionID)
+		}
+	})
+	deleted, anonymizedCount, err := submSvc.ApplyRetention(ctx)
+	if err != nil || deleted != 1 || anonymizedCount != 1 {
+		t.Fatalf("expected 1 deleted and 1 anonymized, got %d, %d (%v)", deleted, anonymizedCount, err)
+	}
+	if !slices.Equal(deletedIDs, []string{expired.ID}) {
+		t.Errorf("expected a tombstone for %s, got %v", expired.ID, deletedIDs)
+	}
+	if subs, _ := submSvc.ListSubmissions(ctx, deleting.PublicID); len(subs) != 1 || subs[0].ID != recent.ID {
+		t.Errorf("expected only the recent submission to remain, got %v", subs)
+	}
+	if string(anonymized.Data) != `{"email":null}` || anonymized.Meta.Server.IP != "" || anonymized.Meta.AnonymizedAt == nil {
+		t.Errorf("expected the submission anonymized, got %s %+v", anonymized.Data, anonymized.Meta)
+	}
+	if subs, _ := submSvc.ListSubmissions(ctx, keeping.PublicID); len(subs) != 1 {
+		t.Errorf("expected the form without retention to keep its submission, got %d", len(subs))
+	}
+
+	// Anonymized submissions aren't anonymized again
+	if deleted, anonymizedCount, _ := submSvc.ApplyRetention(ctx); deleted != 0 || anonymizedCount != 0 {
+		t.Errorf("expected nothing left to do, got %d deleted and %d anonymized", deleted, anonymizedCount)
+	}
+}
+
+func TestSubmissionService_DeletionTombstones(t *testing.T) {
+	repo := NewMockRepository()
+	formSvc := NewFormService(repo)
+	submSvc := NewSubmissionService(repo)
+
+	type deletion struct {
+		formID string
+		reason domain.DeletionReason
+		ids    []string
+	}
+	var deletions []deletion
+	submSvc.SetDeletionCallback(func(form *domain.Form, reason domain.DeletionReason, tombstones []domain.Tombstone) {
+		d := deletion{formID: form.PublicID, reason: reason}
+		for _, t := range tombstones {
+			d.ids = append(d.ids, t.SubmissionID)
+		}
+		deletions = append(deletions, d)
+	})
+
+	form, _ := formSvc.CreateForm(context.Background(), "Mirrored", "", nil, "", "", "", "public", "")
+	var subs []*domain.Submission
+	for i := 0; i < 4; i++ {
+		sub, _ := submSvc.Save(context.Background(), form, map[string]interface{}{"n": i}, domain.SubmissionMeta{})
+		subs = append(subs, sub)
+	}
+	_ = submSvc.MarkAsRead(context.Background(), subs[3].ID)
+	old, _ := submSvc.SaveTest(context.Background(), form, map[string]interface{}{"n": "test"}, domain.SubmissionMeta{})
+	old.CreatedAt = time.Now().Add(-DefaultTestRetention - time.Hour)
+
+	if err := submSvc.DeleteSubmission(context.Background(), subs[0].ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := submSvc.BulkAction(context.Background(), form.PublicID, domain.BulkActionDelete, []string{subs[1].ID, "missing"}, "user"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := submSvc.DeleteSubmissions(context.Background(), form.PublicID, domain.SubmissionFilter{Status: domain.SubmissionStatusRead}, "user"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Nothing matched, so nothing is reported
+	_, _ = submSvc.DeleteSubmissions(context.Background(), form.PublicID, domain.SubmissionFilter{Status: domain.SubmissionStatusRead}, "user")
+	if _, err := submSvc.PurgeTestSubmissions(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []deletion{
+		{form.PublicID, domain.DeletionManual, []string{subs[0].ID}},
+		{form.PublicID, domain.DeletionManual, []string{subs[1].ID}},
+		{form.PublicID, domain.DeletionManual, []string{subs[3].ID}},
+		{form.PublicID, domain.DeletionRetention, []string{old.ID}},
+	}
+	if len(deletions) != len(want) {
+		t.Fatalf("expected %d deletions, got %+v", len(want), deletions)
+	}
+	for i, d := range deletions {
+		if d.formID != want[i].formID || d.reason != want[i].reason || !slices.Equal(d.ids, want[i].ids) {
+			t.Errorf("deletion %d: expected %+v, got %+v", i, want[i], d)
+		}
+	}
+}
+
+func TestSubmissionService_Submit_FormNotFound(t *testing.T) {
+	repo := NewMockRepository()
+	submSvc := NewSubmissionService(repo)
+
+	_, err := submSvc.Submit(context.Background(), "nonexistent", nil, domain.SubmissionMeta{})
+	if err != domain.ErrFormNotFound {
+		t.Errorf("expected ErrFormNotFound, got %v", err)
+	}
+}
+
+func TestSubmissionService_ListSubmissions(t *testing.T) {
+	repo := NewMockRepository()
+	formSvc := NewFormService(repo)
+	submSvc := NewSubmissionService(repo)
+
+	form, _ := formSvc.CreateForm(context.Background(), "Test Form", "", nil, "", "", "", "public", "")
+	_, _ = submSvc.Submit(context.Background(), form.PublicID, map[string]interface{}{"email": "a@b.com"}, domain.SubmissionMeta{})
+	_, _ = submSvc.Submit(context.Background(), form.PublicID, map[string]interface{}{"email": "c@d.com"}, domain.SubmissionMeta{})
+
+	subs, err := submSvc.ListSubmissions(context.Background(), form.PublicID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(subs) != 2 {
+		t.Errorf("expected 2 submissions, got %d", len(subs))
+	}
+}
+
+// slowRepository blocks form lookups until the caller's context is done
+type slowRepository struct {
+	*MockRepository
+}
+
+func (m *slowRepository) Form() ports.FormRepository {
+	return &slowFormRepository{MockFormRepository{forms: m.forms}}
+}
+
+type slowFormRepository struct {
+	MockFormRepository
+}
+
+func (r *slowFormRepository) GetByPublicID(ctx context.Context, publicID string) (*domain.Form, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestFormService_LookupTimeout(t *testing.T) {
+	svc := NewFormService(&slowRepository{NewMockRepository()})
+	svc.SetTimeouts(Timeouts{OpLookup: 20 * time.Millisecond})
+
+	start := time.Now()
+	_, err := svc.GetForm(context.Background(), "any")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("lookup not bounded by its timeout, took %v", elapsed)
+	}
+}
+
+// downRepository can't store submissions while down is set, and refuses those refuse matches
+type downRepository struct {
+	*MockRepository
+	down   bool
+	refuse func(*domain.Submission) bool
+}
+
+func (m *downRepository) Submission() ports.SubmissionRepository {
+	return &downSubmissionRepository{MockSubmissionRepository{submissions: m.submissions, forms: m.forms}, m}
+}
+
+type downSubmissionRepository struct {
+	MockSubmissionRepository
+	repo *downRepository
+}
+
+func (r *downSubmissionRepository) Create(ctx context.Context, s *domain.Submission) error {
+	if r.repo.down {
+		return fmt.Errorf("%w: database is locked", domain.ErrStoreUnavailable)
+	}
+	if r.repo.refuse != nil && r.repo.refuse(s) {
+		return errors.New("CHECK constraint failed")
+	}
+	return r.MockSubmissionRepository.Create(ctx, s)
+}
+
+// memQueue is an in-memory ports.SubmissionQueue
+type memQueue struct {
+	submissions []*domain.Submission
+	dead        []string
+}
+
+func (q *memQueue) DeadLetter(id string) error {
+	q.dead = append(q.dead, id)
+	return q.Remove(id)
+}
+
+func (q *memQueue) Put(s *domain.Submission) error {
+	copied := *s
+	q.submissions = append(q.submissions, &copied)
+	return nil
+}
+
+func (q *memQueue) List() ([]*domain.Submission, error) {
+	return append([]*domain.Submission(nil), q.submissions...), nil
+}
+
+func (q *memQueue) Remove(id string) error {
+	for i, s := range q.submissions {
+		if s.ID == id {
+			q.submissions = append(q.submissions[:i], q.submissions[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (q *memQueue) Len() int {
+	return len(q.submissions)
+}
+
+func TestSubmissionService_QueueDuringOutage(t *testing.T) {
+	ctx := context.Background()
+	repo := &downRepository{MockRepository: NewMockRepository()}
+	form, _ := NewFormService(repo).CreateForm(ctx, "Test Form", "", nil, "", "", "", "public", "")
+	svc := NewSubmissionService(repo)
+	notified := make(chan string, 1)
+	svc.SetNotificationCallback(func(form *domain.Form, submission *domain.Submission, data map[string]interface{}) {
+		notified <- submission.ID
+	})
+
+	// Without a queue the submission fails
+	repo.down = true
+	if _, err := svc.Submit(ctx, form.PublicID, map[string]interface{}{"email": "a@example.com"}, domain.SubmissionMeta{}); err == nil {
+		t.Fatal("expected an error without a queue")
+	}
+
+	queue := &memQueue{}
+	svc.SetQueue(queue)
+	sub, err := svc.Submit(ctx, form.PublicID, map[string]interface{}{"email": "a@example.com"}, domain.SubmissionMeta{})
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	if !sub.Queued || svc.QueueLen() != 1 {
+		t.Fatalf("expected the submission to be queued, got queued=%v len=%d", sub.Queued, svc.QueueLen())
+	}
+
+	// Still down: replay keeps it queued
+	if n, err := svc.ReplayQueue(ctx); err == nil || n != 0 || svc.QueueLen() != 1 {
+		t.Fatalf("expected replay to fail while down, got n=%d err=%v len=%d", n, err, svc.QueueLen())
+	}
+
+	repo.down = false
+	n, err := svc.ReplayQueue(ctx)
+	if err != nil || n != 1 || svc.QueueLen() != 0 {
+		t.Fatalf("expected 1 replayed, got n=%d err=%v len=%d", n, err, svc.QueueLen())
+	}
+	stored := repo.submissions[form.ID]
+	if len(stored) != 1 || stored[0].ID != sub.ID || stored[0].Queued {
+		t.Errorf("unexpected stored submissions: %+v", stored)
+	}
+	if form.SubmissionCount != 1 {
+		t.Errorf("expected submission count 1, got %d", form.SubmissionCount)
+	}
+	select {
+	case id := <-notified:
+		if id != sub.ID {
+			t.Errorf("notified about %s, want %s", id, sub.ID)
+		}
+	case <-time.After(time.Second):
+		t.Error("expected a notification once the submission was stored")
+	}
+
+	// Replaying a submission that was already stored doesn't store it twice
+	_ = queue.Put(stored[0])
+	if n, err := svc.ReplayQueue(ctx); err != nil || n != 0 || len(repo.submissions[form.ID]) != 1 {
+		t.Errorf("expected the duplicate to be skipped, got n=%d err=%v", n, err)
+	}
+}
+
+func TestSubmissionService_QueueOnlyOutages(t *testing.T) {
+	ctx := context.Background()
+	repo := &downRepository{MockRepository: NewMockRepository()}
+	form, _ := NewFormService(repo).CreateForm(ctx, "Test Form", "", nil, "", "", "", "public", "")
+	svc := NewSubmissionService(repo)
+	queue := &memQueue{}
+	svc.SetQueue(queue)
+
+	// A submission the database refuses fails rather than being queued
+	repo.refuse = func(*domain.Submission) bool { return true }
+	if _, err := svc.Submit(ctx, form.PublicID, map[string]interface{}{"email": "a@example.com"}, domain.SubmissionMeta{}); err == nil {
+		t.Fatal("expected the refused submission to fail")
+	}
+	if svc.QueueLen() != 0 {
+		t.Fatalf("expected nothing queued, got %d", svc.QueueLen())
+	}
+
+	// Replay dead-letters a refused submission and goes on with the ones behind it
+	repo.refuse = func(s *domain.Submission) bool { return s.ID == "sub_refused" }
+	for _, id := range []string{"sub_refused", "sub_ok"} {
+		_ = queue.Put(&domain.Submission{ID: id, FormID: form.ID, Status: domain.SubmissionStatusUnread, Data: json.RawMessage(`{}`), CreatedAt: time.Now()})
+	}
+	n, err := svc.ReplayQueue(ctx)
+	if err != nil || n != 1 || svc.QueueLen() != 0 {
+		t.Fatalf("expected 1 replayed and none left, got n=%d err=%v len=%d", n, err, svc.QueueLen())
+	}
+	if len(queue.dead) != 1 || queue.dead[0] != "sub_refused" {
+		t.Errorf("expected the refused submission dead-lettered, got %v", queue.dead)
+	}
+	if stored := repo.submissions[form.ID]; len(stored) != 1 || stored[0].ID != "sub_ok" {
+		t.Errorf("expected the next submission stored, got %+v", stored)
+	}
+}
+
+func TestSubmissionService_FallbackForm(t *testing.T) {
+	svc := NewSubmissionService(NewMockRepository())
+	form := &domain.Form{ID: "f1", PublicID: "pub1"}
+	down := errors.New("database is locked")
+
+	svc.RememberForm(form)
+	if _, err := svc.FallbackForm("pub1", down); err != down {
+		t.Errorf("expected no fallback without a queue, got %v", err)
+	}
+
+	svc.SetQueue(&memQueue{})
+	svc.RememberForm(form)
+	if got, err := svc.FallbackForm("pub1", down); err != nil || got != form {
+		t.Errorf("expected the remembered form, got %v, %v", got, err)
+	}
+	if _, err := svc.FallbackForm("pub1", domain.ErrFormNotFound); !errors.Is(err, domain.ErrFormNotFound) {
+		t.Errorf("a missing form must not fall back, got %v", err)
+	}
+	if _, err := svc.FallbackForm("other", down); err != down {
+		t.Errorf("expected the lookup error for an unseen form, got %v", err)
+	}
+}
+
+// memFiles is an in-memory FileStorage
+type memFiles struct {
+	files map[string]string
+}
+
+func (m *memFiles) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	m.files[key] = string(body)
+	return nil
+}
+
+func (m *memFiles) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	body, ok := m.files[key]
+	if !ok {
+		return nil, domain.ErrFileNotFound
+	}
+	return io.NopCloser(strings.NewReader(body)), nil
+}
+
+func (m *memFiles) Delete(ctx context.Context, key string) error {
+	delete(m.files, key)
+	return nil
+}
+
+func upload(field, name, body string) Upload {
+	return Upload{
+		Field: field,
+		Name:  name,
+		Size:  int64(len(body)),
+		Open:  func() (io.ReadCloser, error) { return io.NopCloser(strings.NewReader(body)), nil },
+	}
+}
+
+func TestSubmissionService_StoreUploads(t *testing.T) {
+	ctx := context.Background()
+	files := &memFiles{files: make(map[string]string)}
+	svc := NewSubmissionService(NewMockRepository())
+	form := &domain.Form{ID: "f1", PublicID: "pub1"}
+	pdf := "%PDF-1.4 resume"
+
+	if _, err := svc.StoreUploads(ctx, form, []Upload{upload("cv", "cv.pdf", pdf)}); !errors.Is(err, domain.ErrUploadsDisabled) {
+		t.Fatalf("expected ErrUploadsDisabled, got %v", err)
+	}
+
+	svc.SetFileStorage(files)
+	form.Uploads = &domain.UploadConfig{MaxFileSize: 100, MaxFiles: 2, AllowedTypes: []string{"application/pdf", ".txt"}}
+	if err := form.Uploads.Validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+
+	refs, err := svc.StoreUploads(ctx, form, []Upload{upload("cv", "../../cv.pdf", pdf), upload("notes", "notes.txt", "hello")})
+	if err != nil {
+		t.Fatalf("store: %v", err)
+	}
+	if len(refs) != 2 || refs[0].Name != "cv.pdf" || refs[0].ContentType != "application/pdf" || refs[1].Field != "notes" {
+		t.Fatalf("unexpected refs: %+v", refs)
+	}
+	if files.files[domain.FileKey("f1", refs[0].FileID)] != pdf {
+		t.Errorf("expected the PDF to be stored under the form, got %v", files.files)
+	}
+
+	// The claimed extension doesn't matter for MIME types: the content is sniffed
+	if _, err := svc.StoreUploads(ctx, form, []Upload{upload("cv", "cv.pdf", "<html><script>")}); !errors.Is(err, domain.ErrFileTypeNotAllowed) {
+		t.Errorf("expected ErrFileTypeNotAllowed, got %v", err)
+	}
+	if _, err := svc.StoreUploads(ctx, form, []Upload{upload("cv", "cv.pdf", strings.Repeat("x", 101))}); !errors.Is(err, domain.ErrFileTooLarge) {
+		t.Errorf("expected ErrFileTooLarge, got %v", err)
+	}
+	three := []Upload{upload("a", "a.txt", "a"), upload("b", "b.txt", "b"), upload("c", "c.txt", "c")}
+	if _, err := svc.StoreUploads(ctx, form, three); !errors.Is(err, domain.ErrTooManyFiles) {
+		t.Errorf("expected ErrTooManyFiles, got %v", err)
+	}
+
+	// A rejected file removes the ones stored before it
+	stored := len(files.files)
+	if _, err := svc.StoreUploads(ctx, form, []Upload{upload("a", "a.txt", "a"), upload("b", "b.exe", "MZ")}); err == nil {
+		t.Fatal("expected the second file to be rejected")
+	}
+	if len(files.files) != stored {
+		t.Errorf("expected %d files after rollback, got %d", stored, len(files.files))
+	}
+
+	// Files are only served when the submission's meta lists them
+	data := map[string]interface{}{}
+	var meta domain.SubmissionMeta
+	AttachFiles(data, &meta, refs)
+	if _, ok := data["cv"].(domain.FileRef); !ok {
+		t.Errorf("expected the file in the data, got %v", data)
+	}
+	submission := &domain.Submission{FormID: "f1", Meta: meta}
+	ref, r, err := svc.OpenFile(ctx, submission, refs[1].FileID)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	body, _ := io.ReadAll(r)
+	if ref.Name != "notes.txt" || string(body) != "hello" {
+		t.Errorf("unexpected file %+v: %q", ref, body)
+	}
+	if _, _, err := svc.OpenFile(ctx, &domain.Submission{FormID: "f1"}, refs[1].FileID); !errors.Is(err, domain.ErrFileNotFound) {
+		t.Errorf("expected ErrFileNotFound for a file not in meta, got %v", err)
+	}
+}
+
+func TestParseTimeouts(t *testing.T) {
+	timeouts, err := ParseTimeouts("lookup=2s, export=1m")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if timeouts[OpLookup] != 2*time.Second || timeouts[OpExport] != time.Minute || timeouts[OpWrite] != DefaultTimeouts[OpWrite] {
+		t.Errorf("unexpected timeouts: %v", timeouts)
+	}
+	if DefaultTimeouts[OpLookup] != 5*time.Second {
+		t.Errorf("defaults were modified: %v", DefaultTimeouts)
+	}
+
+	for _, bad := range []string{"lookup", "lookup=fast", "lookup=-1s", "query=5s"} {
+		if _, err := ParseTimeouts(bad); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestAuthService_Login_RehashesWeakerHash(t *testing.T) {
+	defer passhash.SetDefault(passhash.Bcrypt{})
+	repo := NewMockRepository()
+	svc := NewAuthService(repo, AuthConfig{JWTSecret: "test-secret"})
+	ctx := context.Background()
+
+	passhash.SetDefault(passhash.Bcrypt{Cost: bcrypt.MinCost})
+	if _, err := svc.Register(ctx, "owner@example.com", "password123", "Owner"); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	oldHash := repo.users["owner@example.com"].PasswordHash
+
+	// Same parameters: the stored hash is left alone
+	if _, _, err := svc.Login(ctx, "owner@example.com", "password123"); err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	if repo.users["owner@example.com"].PasswordHash != oldHash {
+		t.Error("expected hash to be unchanged")
+	}
+
+	// Switching to argon2id upgrades the hash on the next successful login only
+	passhash.SetDefault(passhash.Argon2id{Memory: 1024, Iterations: 1, Parallelism: 1})
+	if _, _, err := svc.Login(ctx, "owner@example.com", "wrong-password"); err != domain.ErrInvalidCredentials {
+		t.Fatalf("expected invalid credentials, got %v", err)
+	}
+	if repo.users["owner@example.com"].PasswordHash != oldHash {
+		t.Error("expected hash to be unchanged after a failed login")
+	}
+	if _, _, err := svc.Login(ctx, "owner@example.com", "password123"); err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	newHash := repo.users["owner@example.com"].PasswordHash
+	if !strings.HasPrefix(newHash, "$argon2id$") {
+		t.Errorf("expected argon2id hash after login, got %q", newHash)
+	}
+	if _, _, err := svc.Login(ctx, "owner@example.com", "password123"); err != nil {
+		t.Errorf("login with upgraded hash: %v", err)
+	}
+}
+
+func TestCommentService_MentionsAndThreads(t *testing.T) {
+	repo := NewMockRepository()
+	ctx := context.Background()
+	for _, u := range []*domain.User{
+		{ID: "owner", Email: "owner@example.com", Name: "Owner", Role: domain.RoleUser},
+		{ID: "admin", Email: "admin@example.com", Name: "Admin", Role: domain.RoleAdmin},
+		{ID: "outsider", Email: "outsider@example.com", Role: domain.RoleUser},
+	} {
+		_ = repo.User().Create(ctx, u)
+	}
+	form := &domain.Form{ID: "form-1", PublicID: "pub-1", OwnerID: "owner"}
+	sub := &domain.Submission{ID: "sub-1", FormID: form.ID}
+
+	mentions := make(chan Mention, 4)
+	svc := NewCommentService(repo)
+	svc.SetMentionCallback(func(m Mention) { mentions <- m })
+
+	comment, err := svc.AddComment(ctx, sub, form, "admin", "", "Can you reply, @Owner@example.com? cc @outsider@example.com @nobody@example.com @admin@example.com")
+	if err != nil {
+		t.Fatalf("AddComment: %v", err)
+	}
+	// Outsiders can't see the form and unknown emails are ignored; the author isn't notified
+	if fmt.Sprint(comment.Mentions) != "[owner admin]" {
+		t.Errorf("unexpected mentions %v", comment.Mentions)
+	}
+	select {
+	case m := <-mentions:
+		if m.Mentioned.ID != "owner" || m.Author.Name != "Admin" || m.Form != form {
+			t.Errorf("unexpected mention %+v", m)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the owner to be notified")
+	}
+
+	if _, err := svc.AddComment(ctx, sub, form, "owner", comment.ID, "  "); err != domain.ErrCommentBodyRequired {
+		t.Errorf("expected ErrCommentBodyRequired, got %v", err)
+	}
+	if _, err := svc.AddComment(ctx, sub, form, "owner", "missing", "Done"); err != domain.ErrCommentNotFound {
+		t.Errorf("expected ErrCommentNotFound for unknown parent, got %v", err)
+	}
+	reply, err := svc.AddComment(ctx, sub, form, "owner", comment.ID, "Done")
+	if err != nil || reply.ParentID != comment.ID {
+		t.Fatalf("expected reply to comment, got %+v, %v", reply, err)
+	}
+
+	if _, err := svc.UpdateComment(ctx, sub, form, reply.ID, "admin", "Edited"); err != domain.ErrNotCommentAuthor {
+		t.Errorf("expected ErrNotCommentAuthor, got %v", err)
+	}
+	updated, err := svc.UpdateComment(ctx, sub, form, reply.ID, "owner", "Done, thanks @admin@example.com")
+	if err != nil || updated.UpdatedAt == nil {
+		t.Fatalf("UpdateComment: %+v, %v", updated, err)
+	}
+	select {
+	case m := <-mentions:
+		if m.Mentioned.ID != "admin" {
+			t.Errorf("expected admin to be notified of the new mention, got %s", m.Mentioned.ID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the newly mentioned admin to be notified")
+	}
+
+	if err := svc.DeleteComment(ctx, sub.ID, reply.ID, "outsider", false); err != domain.ErrNotCommentAuthor {
+		t.Errorf("expected ErrNotCommentAuthor, got %v", err)
+	}
+	if err := svc.DeleteComment(ctx, sub.ID, reply.ID, "admin", true); err != nil {
+		t.Errorf("expected admins to delete any comment, got %v", err)
+	}
+}
+
+func TestProvisionService_Apply(t *testing.T) {
+	t.Setenv("PROVISION_TEST_PASSWORD", `pa"ssword-1`)
+	cfg, err := ParseProvisionConfig([]byte(`{
+		"users": [{"email": "Ops@Example.com", "name": "Ops", "role": "admin", "password": "${PROVISION_TEST_PASSWORD}"}],
+		"forms": [{"slug": "contact", "owner": "ops@example.com", "name": "Contact", "webhook": {"url": "https://hooks.example.com"}}]
+	}`))
+	if err != nil {
+		t.Fatalf("ParseProvisionConfig: %v", err)
+	}
+
+	repo := NewMockRepository()
+	ctx := context.Background()
+	svc := NewProvisionService(repo, NewFormService(repo))
+
+	report, err := svc.Apply(ctx, cfg)
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if report.UsersCreated != 1 || report.FormsCreated != 1 {
+		t.Fatalf("unexpected first report %+v", report)
+	}
+	user, _ := repo.User().GetByEmail(ctx, "ops@example.com")
+	if user == nil || user.Role != domain.RoleAdmin || !user.CheckPassword(`pa"ssword-1`) {
+		t.Fatalf("expected provisioned admin with the expanded password, got %+v", user)
+	}
+	form, _ := repo.Form().GetBySlug(ctx, "contact")
+	if form == nil || form.OwnerID != user.ID || form.WebhookURL != "https://hooks.example.com" {
+		t.Fatalf("expected provisioned form owned by the admin, got %+v", form)
+	}
+
+	// Booting again with the same config changes nothing
+	if report, err = svc.Apply(ctx, cfg); err != nil || report.Unchanged != 2 {
+		t.Fatalf("expected an idempotent second run, got %+v (err=%v)", report, err)
+	}
+
+	// Drift is corrected
+	cfg.Forms[0].Name = "Contact us"
+	cfg.Users[0].Role = domain.RoleUser
+	if report, err = svc.Apply(ctx, cfg); err != nil || report.UsersUpdated != 1 || report.FormsUpdated != 1 {
+		t.Fatalf("expected drift to be corrected, got %+v (err=%v)", report, err)
+	}
+
+	for name, raw := range map[string]string{
+		"unknown key":   `{"forms": [{"slug": "x", "owner": "ops@example.com", "name": "X", "colour": "red"}]}`,
+		"undefined env": `{"users": [{"email": "a@example.com", "password": "${PROVISION_TEST_UNDEFINED}"}]}`,
+	} {
+		if _, err := ParseProvisionConfig([]byte(raw)); err == nil {
+			t.Errorf("%s: expected a parse error", name)
+		}
+	}
+	bad, _ := ParseProvisionConfig([]byte(`{"forms": [{"slug": "x", "owner": "nobody@example.com", "name": "X"}]}`))
+	if _, err := svc.Apply(ctx, bad); err == nil {
+		t.Error("expected an error for an unknown owner")
+	}
+}
+
+func TestParseProvisionYAML(t *testing.T) {
+	t.Setenv("PROVISION_TEST_PASSWORD", `pa"ss: word`)
+	fromYAML, err := ParseProvisionYAML([]byte(`
+users:
+  - email: ops@example.com
+    role: admin
+    password: ${PROVISION_TEST_PASSWORD}
+forms:
+  - slug: contact
+    owner: ops@example.com
+    name: Contact
+    notify_emails: [team@example.com]
+    webhook:
+      url: https://hooks.example.com
+`))
+	if err != nil {
+		t.Fatalf("ParseProvisionYAML: %v", err)
+	}
+	fromJSON, err := ParseProvisionConfig([]byte(`{
+		"users": [{"email": "ops@example.com", "role": "admin", "password": "${PROVISION_TEST_PASSWORD}"}],
+		"forms": [{"slug": "contact", "owner": "ops@example.com", "name": "Contact", "notify_emails": ["team@example.com"], "webhook": {"url": "https://hooks.example.com"}}]
+	}`))
+	if err != nil {
+		t.Fatalf("ParseProvisionConfig: %v", err)
+	}
+	if !reflect.DeepEqual(fromYAML, fromJSON) {
+		t.Errorf("expected YAML to parse like the same JSON, got %+v, want %+v", fromYAML, fromJSON)
+	}
+
+	// The same checks apply
+	for name, raw := range map[string]string{
+		"unknown key": "forms:\n  - slug: x\n    owner: ops@example.com\n    name: X\n    colour: red\n",
+		"invalid":     "forms: [slug: x",
+	} {
+		if _, err := ParseProvisionYAML([]byte(raw)); err == nil {
+			t.Errorf("%s: expected a parse error", name)
+		}
+	}
+}
+
+// TestDemoService_SeedAndReset verifies the demo data is seeded once and recreated after a reset
+func TestDemoService_SeedAndReset(t *testing.T) {
+	repo := NewMockRepository()
+	ctx := context.Background()
+	resets := 0
+	svc := NewDemoService(repo, func(ctx context.Context) error {
+		resets++
+		*repo = *NewMockRepository()
+		return nil
+	}, DemoCredentials{})
+
+	if err := svc.Seed(ctx); err != nil {
+		t.Fatalf("Seed: %v", err)
+	}
+	user, _ := repo.User().GetByEmail(ctx, DefaultDemoEmail)
+	if user == nil || !user.CheckPassword(DefaultDemoPassword) {
+		t.Fatalf("expected demo user with the default password, got %+v", user)
+	}
+	form, _ := repo.Form().GetBySlug(ctx, "contact")
+	if form == nil || form.OwnerID != user.ID {
+		t.Fatalf("expected sample contact form owned by the demo user, got %+v", form)
+	}
+	subs, _ := repo.Submission().GetByFormID(ctx, form.ID)
+	if len(subs) == 0 || subs[0].IsTest {
+		t.Fatalf("expected real sample submissions, got %d", len(subs))
+	}
+
+	// Seeding again is a no-op
+	if err := svc.Seed(ctx); err != nil || len(repo.forms) != len(demoForms) {
+		t.Fatalf("expected second seed to do nothing, got %d forms (err=%v)", len(repo.forms), err)
+	}
+
+	if err := svc.Reset(ctx); err != nil || resets != 1 {
+		t.Fatalf("Reset: %v (resets=%d)", err, resets)
+	}
+	// The demo user keeps its ID so existing sessions stay valid
+	if u, _ := repo.User().GetByEmail(ctx, DefaultDemoEmail); u == nil || u.ID != user.ID {
+		t.Errorf("expected the demo user to be recreated with the same ID, got %+v", u)
+	}
+	if len(repo.forms) != len(demoForms) {
+		t.Errorf("expected %d sample forms after reset, got %d", len(demoForms), len(repo.forms))
+	}
+
+	// Real users are never wiped by accident
+	other := NewMockRepository()
+	_ = other.User().Create(ctx, &domain.User{ID: "usr_1", Email: "owner@example.com", Role: domain.RoleSuperAdmin})
+	if err := NewDemoService(other, nil, DemoCredentials{}).Seed(ctx); !errors.Is(err, ErrDemoDatabaseInUse) {
+		t.Errorf("expected ErrDemoDatabaseInUse, got %v", err)
+	}
+}
+
+func TestWebhookDeliveryService(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMockRepository()
+	formSvc := NewFormService(repo)
+	svc := NewWebhookDeliveryService(repo)
+
+	form, _ := formSvc.CreateForm(ctx, "Hooks", "", nil, "", "", "", "public", "")
+	other, _ := formSvc.CreateForm(ctx, "Other", "", nil, "", "", "", "public", "")
+	now := time.Now().UTC()
+	for i, d := range []*domain.WebhookDelivery{
+		{ID: "whd_old", FormID: form.ID, Payload: []byte(`{}`), CreatedAt: now.Add(-DefaultWebhookDeliveryRetention - time.Hour)},
+		{ID: "whd_new", FormID: form.ID, Payload: []byte(`{}`), CreatedAt: now},
+		{ID: "whd_other", FormID: other.ID, Payload: []byte(`{}`), CreatedAt: now},
+	} {
+		if err := repo.WebhookDelivery().Create(ctx, d); err != nil {
+			t.Fatalf("create %d: %v", i, err)
+		}
+	}
+
+	list, total, err := svc.ListDeliveries(ctx, form.PublicID, 1, 20)
+	if err != nil || total != 2 || len(list) != 2 || list[0].ID != "whd_new" {
+		t.Fatalf("unexpected list %v, %d, %v", list, total, err)
+	}
+	if list[0].Payload != nil {
+		t.Error("listed deliveries should leave out their payloads")
+	}
+	if d, err := svc.GetDelivery(ctx, form.PublicID, "whd_new"); err != nil || len(d.Payload) == 0 {
+		t.Errorf("expected the delivery with its payload, got %v, %v", d, err)
+	}
+	if _, err := svc.GetDelivery(ctx, form.PublicID, "whd_other"); !errors.Is(err, domain.ErrWebhookDeliveryNotFound) {
+		t.Errorf("another form's delivery: expected ErrWebhookDeliveryNotFound, got %v", err)
+	}
+
+	// Without a replayer there's nothing to send with
+	if _, err := svc.Replay(ctx, form.PublicID, "whd_new"); !errors.Is(err, domain.ErrWebhookNotConfigured) {
+		t.Errorf("expected ErrWebhookNotConfigured, got %v", err)
+	}
+	svc.SetReplayer(func(f *domain.Form, d *domain.WebhookDelivery) (*domain.WebhookDelivery, error) {
+		return &domain.WebhookDelivery{ID: "whd_replay", FormID: f.ID, ReplayOf: d.ID}, nil
+	})
+	if d, err := svc.Replay(ctx, form.PublicID, "whd_new"); err != nil || d.ReplayOf != "whd_new" {
+		t.Errorf("unexpected replay %v, %v", d, err)
+	}
+	if _, err := svc.Replay(ctx, form.PublicID, "whd_other"); !errors.Is(err, domain.ErrWebhookDeliveryNotFound) {
+		t.Errorf("replaying another form's delivery: expected ErrWebhookDeliveryNotFound, got %v", err)
+	}
+
+	if n, err := svc.PruneDeliveries(ctx); err != nil || n != 1 {
+		t.Errorf("expected 1 delivery pruned, got %d, %v", n, err)
+	}
+	if _, total, _ := svc.ListDeliveries(ctx, form.PublicID, 1, 20); total != 1 {
+		t.Errorf("expected 1 delivery left, got %d", total)
+	}
+}
+
+func TestFieldMigrationService(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMockRepository()
+	tasks := NewTaskService(repo)
+	formSvc := NewFormService(repo)
+	submSvc := NewSubmissionService(repo)
+	svc := NewFieldMigrationService(repo, tasks)
+	svc.batchSize = 2
+
+	form, _ := formSvc.CreateForm(ctx, "Contact", "", nil, "", "", "", "public", "")
+	form.Fields = []domain.FormField{{Name: "mail"}}
+	_ = repo.Form().Update(ctx, form)
+	for _, data := range []map[string]interface{}{
+		{"mail": " A@Example.com "},
+		{"mail": "b@example.com", "email": "kept@example.com"},
+		{"name": "No mail"},
+		{"mail": "C@EXAMPLE.COM"},
+	} {
+		if _, err := submSvc.Submit(ctx, form.PublicID, data, domain.SubmissionMeta{}); err != nil {
+			t.Fatalf("submit: %v", err)
+		}
+	}
+
+	if _, err := svc.Preview(ctx, form.PublicID, domain.FieldMigration{From: "mail"}); !errors.Is(err, domain.ErrInvalidFieldMigration) {
+		t.Errorf("expected ErrInvalidFieldMigration, got %v", err)
+	}
+	if _, err := svc.Preview(ctx, form.PublicID, domain.FieldMigration{From: "mail", Transform: "title"}); !errors.Is(err, domain.ErrInvalidFieldTransform) {
+		t.Errorf("expected ErrInvalidFieldTransform, got %v", err)
+	}
+
+	m := domain.FieldMigration{From: "mail", To: "email", Transform: domain.FieldTransformLower}
+	preview, err := svc.Preview(ctx, form.PublicID, m)
+	if err != nil {
+		t.Fatalf("preview: %v", err)
+	}
+	if preview.Scanned != 4 || preview.Matched != 2 || preview.Conflicts != 1 || len(preview.Samples) != 2 {
+		t.Fatalf("unexpected preview: %+v", preview)
+	}
+	for _, s := range repo.submissions[form.ID] {
+		if strings.Contains(string(s.Data), `"email":"c@example.com"`) {
+			t.Fatalf("preview changed submission %s", s.ID)
+		}
+	}
+
+	task, err := svc.Start(ctx, form.PublicID, "admin-1", m)
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	task = waitForTask(t, tasks, task.ID)
+	var result domain.FieldMigrationResult
+	_ = json.Unmarshal(task.Result, &result)
+	if task.Status != domain.TaskCompleted || result.Scanned != 4 || result.Updated != 2 || result.Conflicts != 1 || !result.FieldRenamed {
+		t.Fatalf("unexpected task state: %+v (result %+v)", task, result)
+	}
+
+	var emails []string
+	for _, s := range repo.submissions[form.ID] {
+		var data map[string]interface{}
+		_ = json.Unmarshal(s.Data, &data)
+		if v, ok := data["email"].(string); ok {
+			emails = append(emails, v)
+		}
+	}
+	slices.Sort(emails)
+	if !slices.Equal(emails, []string{" a@example.com ", "c@example.com", "kept@example.com"}) {
+		t.Errorf("unexpected migrated values: %q", emails)
+	}
+
+	updated, _ := repo.Form().GetByID(ctx, form.ID)
+	if len(updated.Fields) != 1 || updated.Fields[0].Name != "email" {
+		t.Errorf("expected field settings renamed to email, got %+v", updated.Fields)
+	}
+	entries, _ := repo.Audit().ListByFormID(ctx, form.ID, 10)
+	if len(entries) != 1 || entries[0].Action != domain.AuditActionFieldMigrated || entries[0].UserID != "admin-1" || entries[0].Details["updated"] != 2 {
+		t.Errorf("unexpected audit entries: %+v", entries)
+	}
+}
+
+// MockLockRepository keeps lock owners in memory, without expiry
+type MockLockRepository struct {
+	mu     sync.Mutex
+	owners map[string]string
+}
+
+func (r *MockLockRepository) Acquire(_ context.Context, name, owner string, _ time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if held, ok := r.owners[name]; ok && held != owner {
+		return false, nil
+	}
+	r.owners[name] = owner
+	return true, nil
+}
+
+func (r *MockLockRepository) Release(_ context.Context, name, owner string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.owners[name] == owner {
+		delete(r.owners, name)
+	}
+	return nil
+}
+
+func TestJobLocks_ReleaseAll(t *testing.T) {
+	repo := NewMockRepository()
+	ctx := context.Background()
+	a, b := NewJobLocks(repo, "a"), NewJobLocks(repo, "b")
+
+	if !a.Acquire(ctx, JobAlerts, time.Minute) || b.Acquire(ctx, JobAlerts, time.Minute) {
+		t.Fatal("expected the first instance to hold the lock")
+	}
+	a.ReleaseAll(ctx)
+	if !b.Acquire(ctx, JobAlerts, time.Minute) {
+		t.Error("expected the other instance to take over after ReleaseAll")
+	}
+
+	// A released instance doesn't take locks again, e.g. from a job still running at shutdown
+	if a.Acquire(ctx, JobFormPurge, time.Minute) || !a.Paused(JobFormPurge, time.Minute, nil)() {
+		t.Error("expected no lock taken after ReleaseAll")
+	}
+	if !b.Acquire(ctx, JobFormPurge, time.Minute) {
+		t.Error("expected the lock free for other instances")
 	}
 }
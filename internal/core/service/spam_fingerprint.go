@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"headless_form/internal/core/domain"
+	"headless_form/internal/core/ports"
+)
+
+// DefaultSpamFingerprintTTL is how long a fingerprint keeps quarantining its content
+// after it was last confirmed or seen
+const DefaultSpamFingerprintTTL = 30 * 24 * time.Hour
+
+// SpamFingerprintService learns the content of submissions users mark as spam and
+// quarantines submissions with the same content on any form of the instance
+type SpamFingerprintService struct {
+	repo     ports.Repository
+	timeouts Timeouts
+	ttl      time.Duration
+}
+
+// NewSpamFingerprintService creates a new spam fingerprint service
+func NewSpamFingerprintService(repo ports.Repository) *SpamFingerprintService {
+	return &SpamFingerprintService{repo: repo, timeouts: DefaultTimeouts, ttl: DefaultSpamFingerprintTTL}
+}
+
+// SetTimeouts overrides the per-operation timeouts (see DefaultTimeouts)
+func (s *SpamFingerprintService) SetTimeouts(t Timeouts) {
+	s.timeouts = t
+}
+
+// SetTTL sets how long fingerprints last after they were last confirmed or seen
+func (s *SpamFingerprintService) SetTTL(d time.Duration) {
+	if d > 0 {
+		s.ttl = d
+	}
+}
+
+// Learn fingerprints the content of submissions users marked as spam, or forgets it
+// when they released them, so the same content isn't quarantined again. Submissions
+// with too little text aren't fingerprinted.
+func (s *SpamFingerprintService) Learn(ctx context.Context, submissions []*domain.Submission, spam bool) {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
+	now := time.Now().UTC()
+	for _, submission := range submissions {
+		var data map[string]interface{}
+		if json.Unmarshal(submission.Data, &data) != nil {
+			continue
+		}
+		hash, sample := domain.ContentFingerprint(data)
+		if hash == "" {
+			continue
+		}
+		var err error
+		if spam {
+			err = s.repo.SpamFingerprint().Confirm(ctx, &domain.SpamFingerprint{
+				Hash: hash, Sample: sample, CreatedAt: now, LastSeenAt: now, ExpiresAt: now.Add(s.ttl),
+			})
+		} else {
+			_, err = s.repo.SpamFingerprint().Delete(ctx, hash)
+		}
+		if err != nil {
+			log.Printf("[SPAM] Fingerprint of submission %s not updated: %v", submission.ID, err)
+		}
+	}
+}
+
+// Match returns the fingerprint of confirmed spam the data's content has, counting the
+// hit and extending it, or nil when the content isn't known spam. Lookup failures are
+// logged and count as no match.
+func (s *SpamFingerprintService) Match(ctx context.Context, data map[string]interface{}) *domain.SpamFingerprint {
+	hash, _ := domain.ContentFingerprint(data)
+	if hash == "" {
+		return nil
+	}
+	ctx, cancel := s.timeouts.bound(ctx, OpLookup)
+	defer cancel()
+	fp, err := s.repo.SpamFingerprint().Get(ctx, hash)
+	if err != nil {
+		log.Printf("[SPAM] Fingerprint lookup failed: %v", err)
+		return nil
+	}
+	now := time.Now().UTC()
+	if fp == nil || !fp.ExpiresAt.After(now) {
+		return nil
+	}
+	fp.Hits++
+	fp.LastSeenAt, fp.ExpiresAt = now, now.Add(s.ttl)
+	if err := s.repo.SpamFingerprint().Hit(ctx, hash, fp.LastSeenAt, fp.ExpiresAt); err != nil {
+		log.Printf("[SPAM] Fingerprint hit not recorded: %v", err)
+	}
+	return fp
+}
+
+// List returns one page of fingerprints, most hits first, and how many there are
+func (s *SpamFingerprintService) List(ctx context.Context, page, limit int) ([]*domain.SpamFingerprint, int, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpList)
+	defer cancel()
+	fps, total, err := s.repo.SpamFingerprint().List(ctx, limit, (page-1)*limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list spam fingerprints: %w", err)
+	}
+	return fps, total, nil
+}
+
+// Delete stops quarantining the fingerprint's content
+func (s *SpamFingerprintService) Delete(ctx context.Context, hash string) error {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
+	found, err := s.repo.SpamFingerprint().Delete(ctx, hash)
+	if err != nil {
+		return fmt.Errorf("delete spam fingerprint: %w", err)
+	}
+	if !found {
+		return domain.ErrSpamFingerprintNotFound
+	}
+	return nil
+}
+
+// Prune deletes expired fingerprints. Returns the number deleted.
+func (s *SpamFingerprintService) Prune(ctx context.Context) (int, error) {
+	n, err := s.repo.SpamFingerprint().DeleteExpired(ctx, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("prune spam fingerprints: %w", err)
+	}
+	return n, nil
+}
+
+// StartPrune runs Prune every interval until ctx is cancelled,
+// skipping runs while paused reports true (nil never pauses)
+func (s *SpamFingerprintService) StartPrune(ctx context.Context, interval time.Duration, paused func() bool) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if paused != nil && paused() {
+					continue
+				}
+				if n, err := s.Prune(ctx); err != nil {
+					log.Printf("[SPAM] Fingerprint pruning failed: %v", err)
+				} else if n > 0 {
+					log.Printf("[SPAM] Pruned %d expired spam fingerprints", n)
+				}
+			}
+		}
+	}()
+}
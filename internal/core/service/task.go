@@ -0,0 +1,414 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+	"time"
+
+	"headless_form/internal/core/domain"
+	"headless_form/internal/core/ids"
+	"headless_form/internal/core/ports"
+)
+
+// DefaultTaskFileRetention is how long files produced by tasks can be downloaded
+const DefaultTaskFileRetention = 24 * time.Hour
+
+// TaskFunc is the body of a background task. It should return early once ctx is
+// cancelled and report progress through p. A non-nil result is stored as JSON.
+type TaskFunc func(ctx context.Context, p *TaskProgress) (interface{}, error)
+
+// TaskService runs long operations in the background, persisting their status and
+// progress so they can be polled, streamed to the dashboard and cancelled.
+type TaskService struct {
+	repo          ports.Repository
+	events        ports.EventBus // Shares progress with other replicas, if set
+	files         ports.FileStorage
+	fileRetention time.Duration
+
+	mu      sync.Mutex
+	running map[string]*runningTask
+}
+
+// runningTask is the in-memory state of a task executing in this process
+type runningTask struct {
+	task        domain.Task
+	cancel      context.CancelFunc
+	subscribers map[chan domain.Task]struct{}
+}
+
+// NewTaskService creates a new task service
+func NewTaskService(repo ports.Repository) *TaskService {
+	return &TaskService{
+		repo:          repo,
+		fileRetention: DefaultTaskFileRetention,
+		running:       make(map[string]*runningTask),
+	}
+}
+
+// SetEventBus publishes the progress of tasks running here for other replicas, and
+// lets Subscribe follow tasks running on another replica
+func (s *TaskService) SetEventBus(bus ports.EventBus) {
+	s.events = bus
+}
+
+// taskTopic is the event bus topic of a task's updates
+func taskTopic(id string) string {
+	return "task:" + id
+}
+
+// publish shares a task's state on the event bus, if there is one
+func (s *TaskService) publish(task domain.Task) {
+	if s.events == nil {
+		return
+	}
+	data, err := json.Marshal(task)
+	if err != nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.events.Publish(ctx, taskTopic(task.ID), data); err != nil {
+		log.Printf("[TASKS] Failed to publish update of task %s: %v", task.ID, err)
+	}
+}
+
+// SetFileStorage sets where files produced by tasks are kept. Without one,
+// tasks can't produce files.
+func (s *TaskService) SetFileStorage(fs ports.FileStorage) {
+	s.files = fs
+}
+
+// SetFileRetention sets how long task files can be downloaded before they're removed
+func (s *TaskService) SetFileRetention(d time.Duration) {
+	s.fileRetention = d
+}
+
+// Start runs fn in the background as a task of the given type created by userID
+func (s *TaskService) Start(taskType domain.TaskType, userID string, fn TaskFunc) (*domain.Task, error) {
+	return s.start(taskType, userID, false, fn)
+}
+
+// StartExclusive is like Start but fails with ErrTaskInProgress while another
+// task of the same type is still running
+func (s *TaskService) StartExclusive(taskType domain.TaskType, userID string, fn TaskFunc) (*domain.Task, error) {
+	return s.start(taskType, userID, true, fn)
+}
+
+func (s *TaskService) start(taskType domain.TaskType, userID string, exclusive bool, fn TaskFunc) (*domain.Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if exclusive {
+		for _, rt := range s.running {
+			if rt.task.Type == taskType && !rt.task.Status.IsFinal() {
+				return nil, domain.ErrTaskInProgress
+			}
+		}
+	}
+
+	now := time.Now().UTC()
+	task := domain.Task{
+		ID:        ids.New(ids.Task),
+		Type:      taskType,
+		Status:    domain.TaskRunning,
+		CreatedBy: userID,
+		CreatedAt: now,
+		StartedAt: &now,
+	}
+	if err := s.repo.Task().Create(context.Background(), &task); err != nil {
+		return nil, fmt.Errorf("create task: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.running[task.ID] = &runningTask{task: task, cancel: cancel, subscribers: make(map[chan domain.Task]struct{})}
+
+	go s.run(ctx, task.ID, fn)
+	return &task, nil
+}
+
+// run executes fn and records how it ended
+func (s *TaskService) run(ctx context.Context, id string, fn TaskFunc) {
+	result, err := fn(ctx, &TaskProgress{svc: s, id: id})
+
+	s.mu.Lock()
+	rt := s.running[id]
+	rt.cancel()
+	now := time.Now().UTC()
+	rt.task.FinishedAt = &now
+	if result != nil {
+		rt.task.Result, _ = json.Marshal(result)
+	}
+	switch {
+	case err == nil:
+		rt.task.Status = domain.TaskCompleted
+		rt.task.Progress = 100
+	case errors.Is(err, context.Canceled):
+		rt.task.Status = domain.TaskCancelled
+	default:
+		rt.task.Status = domain.TaskFailed
+		rt.task.Error = err.Error()
+	}
+	task := rt.task
+	s.mu.Unlock()
+
+	// Saved before the task leaves the running set so Get never sees a stale row
+	if err := s.repo.Task().Update(context.Background(), &task); err != nil {
+		log.Printf("[TASKS] Failed to save task %s: %v", id, err)
+	}
+	s.publish(task)
+	if task.Status == domain.TaskFailed {
+		log.Printf("[TASKS] %s task %s failed: %s", task.Type, id, task.Error)
+	} else {
+		log.Printf("[TASKS] %s task %s %s", task.Type, id, task.Status)
+	}
+
+	s.mu.Lock()
+	delete(s.running, id)
+	// Subscribers see the channel close and read the final state with Get
+	for ch := range rt.subscribers {
+		close(ch)
+	}
+	s.mu.Unlock()
+}
+
+// Get returns a task, with live progress while it is running
+func (s *TaskService) Get(ctx context.Context, id string) (*domain.Task, error) {
+	s.mu.Lock()
+	if rt, ok := s.running[id]; ok {
+		task := rt.task
+		s.mu.Unlock()
+		return &task, nil
+	}
+	s.mu.Unlock()
+
+	task, err := s.repo.Task().GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("lookup task: %w", err)
+	}
+	if task == nil {
+		return nil, domain.ErrTaskNotFound
+	}
+	return task, nil
+}
+
+// Cancel asks a running task to stop. The task becomes cancelled once its
+// current step finishes; work already done is kept.
+func (s *TaskService) Cancel(ctx context.Context, id string) (*domain.Task, error) {
+	s.mu.Lock()
+	if rt, ok := s.running[id]; ok {
+		task := rt.task
+		s.mu.Unlock()
+		if task.Status.IsFinal() {
+			return nil, domain.ErrTaskFinished
+		}
+		rt.cancel()
+		return &task, nil
+	}
+	s.mu.Unlock()
+
+	task, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if task.Status.IsFinal() {
+		return nil, domain.ErrTaskFinished
+	}
+	return task, nil
+}
+
+// Subscribe returns a channel receiving the task's state on every progress update.
+// The channel is closed when the task finishes; call unsubscribe when done listening.
+// Without an event bus it's closed immediately for tasks not running here. With one,
+// tasks running on other replicas are followed through it, and callers should check
+// the task's state with Get after subscribing.
+func (s *TaskService) Subscribe(id string) (updates <-chan domain.Task, unsubscribe func()) {
+	ch := make(chan domain.Task, 16)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rt, ok := s.running[id]
+	if !ok {
+		if s.events != nil {
+			return s.subscribeRemote(id, ch)
+		}
+		close(ch)
+		return ch, func() {}
+	}
+	rt.subscribers[ch] = struct{}{}
+
+	return ch, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(rt.subscribers, ch)
+	}
+}
+
+// subscribeRemote follows a task running on another replica through the event bus,
+// closing ch once its final state arrives
+func (s *TaskService) subscribeRemote(id string, ch chan domain.Task) (<-chan domain.Task, func()) {
+	events, unsubscribe := s.events.Subscribe(taskTopic(id))
+	stop := make(chan struct{})
+	go func() {
+		defer close(ch)
+		for {
+			select {
+			case <-stop:
+				return
+			case data := <-events:
+				var task domain.Task
+				if err := json.Unmarshal(data, &task); err != nil {
+					continue
+				}
+				select {
+				case ch <- task:
+				default: // Slow subscriber: skip this update, a later one will catch up
+				}
+				if task.Status.IsFinal() {
+					return
+				}
+			}
+		}
+	}()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			unsubscribe()
+			close(stop)
+		})
+	}
+}
+
+// FailInterrupted marks tasks left unfinished by a previous run of the server as failed.
+// Call it once at startup, before any task is started.
+func (s *TaskService) FailInterrupted(ctx context.Context) (int, error) {
+	return s.repo.Task().FailUnfinished(ctx, "interrupted by server restart", time.Now().UTC())
+}
+
+// TaskProgress lets a running task report how far along it is
+type TaskProgress struct {
+	svc *TaskService
+	id  string
+}
+
+// Update records the task's progress (0-100) with an optional message,
+// persists it and notifies subscribers
+func (p *TaskProgress) Update(percent float64, message string) {
+	s := p.svc
+	s.mu.Lock()
+	rt, ok := s.running[p.id]
+	if !ok {
+		s.mu.Unlock()
+		return
+	}
+	rt.task.Progress = min(max(percent, 0), 100)
+	rt.task.Message = message
+	task := rt.task
+	for ch := range rt.subscribers {
+		select {
+		case ch <- task:
+		default: // Slow subscriber: skip this update, a later one will catch up
+		}
+	}
+	s.mu.Unlock()
+
+	if err := s.repo.Task().Update(context.Background(), &task); err != nil {
+		log.Printf("[TASKS] Failed to save progress of task %s: %v", p.id, err)
+	}
+	s.publish(task)
+}
+
+// SaveFile stores the task's output file, replacing any saved before. It can be
+// downloaded until the retention period has passed.
+func (p *TaskProgress) SaveFile(ctx context.Context, name, contentType string, r io.Reader, size int64) error {
+	s := p.svc
+	if s.files == nil {
+		return errors.New("no file storage configured")
+	}
+	if err := s.files.Put(ctx, domain.TaskFileKey(p.id), r, size, contentType); err != nil {
+		return fmt.Errorf("store task file: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if rt, ok := s.running[p.id]; ok {
+		rt.task.File = &domain.TaskFile{
+			Name:        name,
+			ContentType: contentType,
+			Size:        size,
+			ExpiresAt:   time.Now().UTC().Add(s.fileRetention),
+		}
+	}
+	return nil
+}
+
+// OpenFile returns the task's file and its content, or ErrTaskNoFile once it has expired
+func (s *TaskService) OpenFile(ctx context.Context, task *domain.Task) (*domain.TaskFile, io.ReadCloser, error) {
+	if task.File == nil || s.files == nil || time.Now().After(task.File.ExpiresAt) {
+		return nil, nil, domain.ErrTaskNoFile
+	}
+	r, err := s.files.Open(ctx, domain.TaskFileKey(task.ID))
+	if errors.Is(err, domain.ErrFileNotFound) {
+		return nil, nil, domain.ErrTaskNoFile
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return task.File, r, nil
+}
+
+// PurgeFiles removes task files past their expiry. Returns the number removed.
+func (s *TaskService) PurgeFiles(ctx context.Context) (int, error) {
+	if s.files == nil {
+		return 0, nil
+	}
+	tasks, err := s.repo.Task().ListWithFiles(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("list task files: %w", err)
+	}
+	now := time.Now()
+	purged := 0
+	for _, task := range tasks {
+		if now.Before(task.File.ExpiresAt) {
+			continue
+		}
+		if err := s.files.Delete(ctx, domain.TaskFileKey(task.ID)); err != nil {
+			return purged, fmt.Errorf("delete task file: %w", err)
+		}
+		task.File = nil
+		if err := s.repo.Task().Update(ctx, task); err != nil {
+			return purged, fmt.Errorf("update task: %w", err)
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+// StartFilePurge runs PurgeFiles every interval until ctx is cancelled,
+// skipping runs while paused reports true (nil never pauses)
+func (s *TaskService) StartFilePurge(ctx context.Context, interval time.Duration, paused func() bool) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if paused != nil && paused() {
+					continue
+				}
+				if n, err := s.PurgeFiles(ctx); err != nil {
+					log.Printf("[TASKS] File purge failed: %v", err)
+				} else if n > 0 {
+					log.Printf("[TASKS] Removed %d expired task file(s)", n)
+				}
+			}
+		}
+	}()
+}
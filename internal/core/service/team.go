@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"headless_form/internal/core/domain"
+	"headless_form/internal/core/ids"
+	"headless_form/internal/core/ports"
+)
+
+// TeamService manages teams, their members and the forms shared with them
+type TeamService struct {
+	repo     ports.Repository
+	timeouts Timeouts
+}
+
+// NewTeamService creates a new team service
+func NewTeamService(repo ports.Repository) *TeamService {
+	return &TeamService{repo: repo, timeouts: DefaultTimeouts}
+}
+
+// SetTimeouts overrides the per-operation timeouts (see DefaultTimeouts)
+func (s *TeamService) SetTimeouts(t Timeouts) {
+	s.timeouts = t
+}
+
+// CreateTeam creates a team with ownerID as its first owner
+func (s *TeamService) CreateTeam(ctx context.Context, name, ownerID string) (*domain.Team, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
+	name, err := domain.NormalizeTeamName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	team := &domain.Team{ID: ids.New(ids.Team), Name: name, CreatedAt: now, UpdatedAt: now}
+	err = s.repo.Tx(ctx, func(repo ports.Repository) error {
+		if err := repo.Team().Create(ctx, team); err != nil {
+			return fmt.Errorf("create team: %w", err)
+		}
+		return repo.Team().SetMember(ctx, &domain.TeamMember{TeamID: team.ID, UserID: ownerID, Role: domain.TeamRoleOwner, AddedAt: now})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.Team().GetByID(ctx, team.ID)
+}
+
+// ListTeams returns the teams userID belongs to, or every team when userID is empty
+func (s *TeamService) ListTeams(ctx context.Context, userID string) ([]*domain.Team, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpList)
+	defer cancel()
+	teams, err := s.repo.Team().List(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list teams: %w", err)
+	}
+	if teams == nil {
+		teams = []*domain.Team{}
+	}
+	return teams, nil
+}
+
+// GetTeam returns the team with its members
+func (s *TeamService) GetTeam(ctx context.Context, id string) (*domain.Team, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpLookup)
+	defer cancel()
+	team, err := s.repo.Team().GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get team: %w", err)
+	}
+	if team == nil {
+		return nil, domain.ErrTeamNotFound
+	}
+	return team, nil
+}
+
+// RenameTeam changes the team's name
+func (s *TeamService) RenameTeam(ctx context.Context, id, name string) (*domain.Team, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
+	name, err := domain.NormalizeTeamName(name)
+	if err != nil {
+		return nil, err
+	}
+	team, err := s.GetTeam(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	team.Name = name
+	team.UpdatedAt = time.Now().UTC()
+	if err := s.repo.Team().Update(ctx, team); err != nil {
+		return nil, fmt.Errorf("update team: %w", err)
+	}
+	return team, nil
+}
+
+// DeleteTeam removes the team. Its forms stay with their owners, unshared.
+func (s *TeamService) DeleteTeam(ctx context.Context, id string) error {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
+	if _, err := s.GetTeam(ctx, id); err != nil {
+		return err
+	}
+	return s.repo.Tx(ctx, func(repo ports.Repository) error {
+		return repo.Team().Delete(ctx, id)
+	})
+}
+
+// SetMember adds the user with the email to the team, or changes their role
+func (s *TeamService) SetMember(ctx context.Context, teamID, email string, role domain.TeamRole) (*domain.Team, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
+	if !role.Valid() {
+		return nil, domain.ErrInvalidTeamRole
+	}
+	team, err := s.GetTeam(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.repo.User().GetByEmail(ctx, email)
+	if errors.Is(err, domain.ErrUserNotFound) || (err == nil && user == nil) {
+		return nil, domain.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("lookup user: %w", err)
+	}
+
+	existing := team.Member(user.ID)
+	if existing != nil && existing.Role == domain.TeamRoleOwner && role != domain.TeamRoleOwner && team.Owners() == 1 {
+		return nil, domain.ErrLastTeamOwner
+	}
+	member := &domain.TeamMember{TeamID: team.ID, UserID: user.ID, Role: role, AddedAt: time.Now().UTC()}
+	if err := s.repo.Team().SetMember(ctx, member); err != nil {
+		return nil, fmt.Errorf("set team member: %w", err)
+	}
+	return s.GetTeam(ctx, team.ID)
+}
+
+// RemoveMember takes the user out of the team
+func (s *TeamService) RemoveMember(ctx context.Context, teamID, userID string) error {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
+	team, err := s.GetTeam(ctx, teamID)
+	if err != nil {
+		return err
+	}
+	member := team.Member(userID)
+	if member == nil {
+		return domain.ErrNotTeamMember
+	}
+	if member.Role == domain.TeamRoleOwner && team.Owners() == 1 {
+		return domain.ErrLastTeamOwner
+	}
+	if _, err := s.repo.Team().RemoveMember(ctx, teamID, userID); err != nil {
+		return fmt.Errorf("remove team member: %w", err)
+	}
+	return nil
+}
+
+// Roles returns the user's role in each of their teams, by team ID
+func (s *TeamService) Roles(ctx context.Context, userID string) (map[string]domain.TeamRole, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpLookup)
+	defer cancel()
+	return s.repo.Team().Roles(ctx, userID)
+}
+
+// SetFormTeam shares the form with the team, or stops sharing it when teamID is empty
+func (s *TeamService) SetFormTeam(ctx context.Context, publicID, teamID string) (*domain.Form, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
+	form, err := s.repo.Form().GetByPublicID(ctx, publicID)
+	if err != nil {
+		return nil, fmt.Errorf("get form: %w", err)
+	}
+	if form == nil {
+		return nil, domain.ErrFormNotFound
+	}
+	if teamID != "" {
+		if _, err := s.GetTeam(ctx, teamID); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.repo.Form().SetTeam(ctx, form.ID, teamID); err != nil {
+		return nil, fmt.Errorf("set form team: %w", err)
+	}
+	form.TeamID = teamID
+	return form, nil
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Operation is a kind of service call with its own time budget
+type Operation string
+
+const (
+	OpLookup Operation = "lookup" // Single record reads (form lookup, get submission)
+	OpList   Operation = "list"   // Paginated lists and stats
+	OpWrite  Operation = "write"  // Creates, updates and deletes
+	OpExport Operation = "export" // Full exports and archiving
+)
+
+// Timeouts bounds how long each kind of operation may hold the database.
+// Operations without an entry (or with zero) only stop when the caller's context does.
+type Timeouts map[Operation]time.Duration
+
+// DefaultTimeouts are used by services unless overridden with SetTimeouts
+var DefaultTimeouts = Timeouts{
+	OpLookup: 5 * time.Second,
+	OpList:   15 * time.Second,
+	OpWrite:  10 * time.Second,
+	OpExport: 30 * time.Second,
+}
+
+// ParseTimeouts parses overrides such as "lookup=2s,export=1m" on top of DefaultTimeouts
+func ParseTimeouts(s string) (Timeouts, error) {
+	timeouts := make(Timeouts, len(DefaultTimeouts))
+	for op, d := range DefaultTimeouts {
+		timeouts[op] = d
+	}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(part, "=")
+		op := Operation(strings.TrimSpace(name))
+		d, err := time.ParseDuration(strings.TrimSpace(value))
+		if _, known := DefaultTimeouts[op]; !ok || !known || err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid timeout %q (want lookup|list|write|export=duration)", part)
+		}
+		timeouts[op] = d
+	}
+	return timeouts, nil
+}
+
+// bound derives a context that expires after op's timeout
+func (t Timeouts) bound(ctx context.Context, op Operation) (context.Context, context.CancelFunc) {
+	if d := t[op]; d > 0 {
+		return context.WithTimeout(ctx, d)
+	}
+	return context.WithCancel(ctx)
+}
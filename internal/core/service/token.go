@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"headless_form/internal/core/domain"
+	"headless_form/internal/core/ids"
+	"headless_form/internal/core/ports"
+)
+
+// FormTokenService manages read-only API tokens scoped to a single form
+type FormTokenService struct {
+	repo     ports.Repository
+	timeouts Timeouts
+}
+
+// NewFormTokenService creates a new form token service
+func NewFormTokenService(repo ports.Repository) *FormTokenService {
+	return &FormTokenService{repo: repo, timeouts: DefaultTimeouts}
+}
+
+// SetTimeouts overrides the per-operation timeouts (see DefaultTimeouts)
+func (s *FormTokenService) SetTimeouts(t Timeouts) {
+	s.timeouts = t
+}
+
+// CreateToken generates a token for a form. The plaintext token is only returned here.
+func (s *FormTokenService) CreateToken(ctx context.Context, publicID, name, createdBy string) (*domain.FormToken, string, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
+	form, err := s.getForm(ctx, publicID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, "", domain.ErrTokenNameRequired
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, "", err
+	}
+	plaintext := domain.FormTokenPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	token := &domain.FormToken{
+		ID:        ids.New(ids.FormToken),
+		FormID:    form.ID,
+		Name:      name,
+		Hint:      plaintext[len(plaintext)-4:],
+		TokenHash: hashFormToken(plaintext),
+		CreatedBy: createdBy,
+		CreatedAt: time.Now(),
+	}
+	if err := s.repo.FormToken().Create(ctx, token); err != nil {
+		return nil, "", fmt.Errorf("create form token: %w", err)
+	}
+	return token, plaintext, nil
+}
+
+// ListTokens returns the tokens for a form (without secrets)
+func (s *FormTokenService) ListTokens(ctx context.Context, publicID string) ([]*domain.FormToken, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpList)
+	defer cancel()
+	form, err := s.getForm(ctx, publicID)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.FormToken().ListByFormID(ctx, form.ID)
+}
+
+// RevokeToken deletes a token, verifying it belongs to the given form
+func (s *FormTokenService) RevokeToken(ctx context.Context, publicID, tokenID string) error {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
+	form, err := s.getForm(ctx, publicID)
+	if err != nil {
+		return err
+	}
+
+	tokens, err := s.repo.FormToken().ListByFormID(ctx, form.ID)
+	if err != nil {
+		return fmt.Errorf("list form tokens: %w", err)
+	}
+	for _, t := range tokens {
+		if t.ID == tokenID {
+			return s.repo.FormToken().Delete(ctx, tokenID)
+		}
+	}
+	return domain.ErrFormTokenNotFound
+}
+
+// Authenticate resolves a plaintext token to its form and records its use
+func (s *FormTokenService) Authenticate(ctx context.Context, plaintext string) (*domain.FormToken, *domain.Form, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpLookup)
+	defer cancel()
+	if !strings.HasPrefix(plaintext, domain.FormTokenPrefix) {
+		return nil, nil, domain.ErrInvalidFormToken
+	}
+
+	token, err := s.repo.FormToken().GetByHash(ctx, hashFormToken(plaintext))
+	if err != nil {
+		return nil, nil, fmt.Errorf("get form token: %w", err)
+	}
+	if token == nil {
+		return nil, nil, domain.ErrInvalidFormToken
+	}
+
+	form, err := s.repo.Form().GetByID(ctx, token.FormID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get form: %w", err)
+	}
+	if form == nil {
+		return nil, nil, domain.ErrInvalidFormToken
+	}
+
+	_ = s.repo.FormToken().MarkUsed(ctx, token.ID, time.Now())
+	return token, form, nil
+}
+
+func (s *FormTokenService) getForm(ctx context.Context, publicID string) (*domain.Form, error) {
+	form, err := s.repo.Form().GetByPublicID(ctx, publicID)
+	if err != nil {
+		return nil, fmt.Errorf("get form: %w", err)
+	}
+	if form == nil {
+		return nil, domain.ErrFormNotFound
+	}
+	return form, nil
+}
+
+// hashFormToken returns the stored representation of a token. Tokens carry 256 bits
+// of randomness, so a plain SHA-256 is enough (no salt or slow hash needed).
+func hashFormToken(plaintext string) string {
+	sum := sha256.Sum256([]byte(plaintext))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"headless_form/internal/core/domain"
+	"headless_form/internal/core/ids"
+	"headless_form/internal/core/ports"
+)
+
+// Upload is a file sent with a submission
+type Upload struct {
+	Field string // Name of the file input
+	Name  string // File name given by the client
+	Size  int64
+	Open  func() (io.ReadCloser, error)
+}
+
+// SetFileStorage sets where files uploaded with submissions are kept.
+// Without one, submissions with files are rejected.
+func (s *SubmissionService) SetFileStorage(fs ports.FileStorage) {
+	s.files = fs
+}
+
+// StoreUploads checks files against the form's upload limits and stores them.
+// The content type is sniffed from the file rather than taken from the client.
+// On error, none of the files is kept.
+func (s *SubmissionService) StoreUploads(ctx context.Context, form *domain.Form, uploads []Upload) ([]domain.FileRef, error) {
+	if len(uploads) == 0 {
+		return nil, nil
+	}
+	cfg := form.Uploads
+	if cfg == nil || s.files == nil {
+		return nil, domain.ErrUploadsDisabled
+	}
+	if len(uploads) > cfg.MaxFiles {
+		return nil, fmt.Errorf("%w: at most %d per submission", domain.ErrTooManyFiles, cfg.MaxFiles)
+	}
+	for _, u := range uploads {
+		if u.Size > cfg.MaxFileSize {
+			return nil, fmt.Errorf("%w: %s is over %d bytes", domain.ErrFileTooLarge, fileName(u.Name), cfg.MaxFileSize)
+		}
+	}
+
+	refs := make([]domain.FileRef, 0, len(uploads))
+	for _, u := range uploads {
+		ref, err := s.storeUpload(ctx, form, u)
+		if err != nil {
+			s.DeleteFiles(ctx, form.ID, refs)
+			return nil, err
+		}
+		refs = append(refs, ref)
+	}
+	return refs, nil
+}
+
+func (s *SubmissionService) storeUpload(ctx context.Context, form *domain.Form, u Upload) (domain.FileRef, error) {
+	f, err := u.Open()
+	if err != nil {
+		return domain.FileRef{}, fmt.Errorf("open upload: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return domain.FileRef{}, fmt.Errorf("read upload: %w", err)
+	}
+	head = head[:n]
+
+	ref := domain.FileRef{
+		FileID:      ids.New(ids.File),
+		Field:       u.Field,
+		Name:        fileName(u.Name),
+		Size:        u.Size,
+		ContentType: http.DetectContentType(head),
+	}
+	if !form.Uploads.Allows(ref.Name, ref.ContentType) {
+		return domain.FileRef{}, fmt.Errorf("%w: %s (%s)", domain.ErrFileTypeNotAllowed, ref.Name, ref.ContentType)
+	}
+
+	body := io.MultiReader(bytes.NewReader(head), f)
+	if err := s.files.Put(ctx, domain.FileKey(form.ID, ref.FileID), body, u.Size, ref.ContentType); err != nil {
+		return domain.FileRef{}, fmt.Errorf("store upload: %w", err)
+	}
+	return ref, nil
+}
+
+// fileName keeps the base name of a client-provided file name
+func fileName(name string) string {
+	name = strings.TrimSpace(filepath.Base(strings.ReplaceAll(name, "\\", "/")))
+	if name == "" || name == "." || name == "/" {
+		return "file"
+	}
+	if len(name) > 255 {
+		name = name[:255]
+	}
+	return name
+}
+
+// AttachFiles records uploaded files in a submission's data, under their input's
+// name (a list when one input sent several), and in its meta
+func AttachFiles(data map[string]interface{}, meta *domain.SubmissionMeta, refs []domain.FileRef) {
+	byField := make(map[string][]domain.FileRef)
+	for _, ref := range refs {
+		byField[ref.Field] = append(byField[ref.Field], ref)
+	}
+	for field, list := range byField {
+		if len(list) == 1 {
+			data[field] = list[0]
+		} else {
+			data[field] = list
+		}
+	}
+	meta.Files = append(meta.Files, refs...)
+}
+
+// DeleteFiles removes uploaded files of a form. Failures are logged, not returned,
+// since the files are no longer referenced either way.
+func (s *SubmissionService) DeleteFiles(ctx context.Context, formID string, refs []domain.FileRef) {
+	deleteFiles(ctx, s.files, formID, refs)
+}
+
+func deleteFiles(ctx context.Context, fs ports.FileStorage, formID string, refs []domain.FileRef) {
+	if fs == nil {
+		return
+	}
+	for _, ref := range refs {
+		if err := fs.Delete(ctx, domain.FileKey(formID, ref.FileID)); err != nil {
+			log.Printf("[FILES] Failed to delete file %s: %v", ref.FileID, err)
+		}
+	}
+}
+
+// OpenFile returns a file uploaded with the submission and its content
+func (s *SubmissionService) OpenFile(ctx context.Context, submission *domain.Submission, fileID string) (domain.FileRef, io.ReadCloser, error) {
+	ref, ok := submission.Meta.File(fileID)
+	if !ok || s.files == nil {
+		return domain.FileRef{}, nil, domain.ErrFileNotFound
+	}
+	r, err := s.files.Open(ctx, domain.FileKey(submission.FormID, ref.FileID))
+	if err != nil {
+		return domain.FileRef{}, nil, err
+	}
+	return ref, r, nil
+}
@@ -0,0 +1,235 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"headless_form/internal/core/domain"
+	"headless_form/internal/core/ids"
+	"headless_form/internal/core/ports"
+)
+
+// UserInviteTTL is how long imported users without a password can use their setup link
+const UserInviteTTL = 7 * 24 * time.Hour
+
+// errDryRun rolls back the transaction of a dry run import
+var errDryRun = errors.New("dry run")
+
+// ExportUsers exports every user and who owns which form, including forms in the trash,
+// for ImportUsers on another instance. Password hashes are only included withPasswords,
+// letting users keep their password; otherwise imported users are invited to set one.
+func (s *AuthService) ExportUsers(ctx context.Context, withPasswords bool) (*domain.UserExport, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpExport)
+	defer cancel()
+
+	users, err := s.repo.User().List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list users: %w", err)
+	}
+	export := &domain.UserExport{
+		Version:    domain.UserExportVersion,
+		ExportedAt: time.Now().UTC(),
+		Users:      make([]domain.ExportedUser, 0, len(users)),
+		Forms:      []domain.FormOwnership{},
+	}
+	emails := make(map[string]string, len(users))
+	for _, u := range users {
+		emails[u.ID] = u.Email
+		exported := domain.ExportedUser{
+			Email:     u.Email,
+			Name:      u.Name,
+			Role:      u.Role,
+			Timezone:  u.Timezone,
+			CreatedAt: u.CreatedAt,
+		}
+		if withPasswords {
+			exported.PasswordHash = u.PasswordHash
+		}
+		export.Users = append(export.Users, exported)
+	}
+
+	addForm := func(f *domain.Form) {
+		if owner, ok := emails[f.OwnerID]; ok {
+			export.Forms = append(export.Forms, domain.FormOwnership{PublicID: f.PublicID, Slug: f.Slug, Name: f.Name, Owner: owner})
+		}
+	}
+	const pageSize = 500
+	for offset := 0; ; offset += pageSize {
+		page, _, err := s.repo.Form().ListPaginated(ctx, domain.FormListQuery{Sort: domain.FormSortCreatedAt, Ascending: true, IncludeArchived: true}, pageSize, offset)
+		if err != nil {
+			return nil, fmt.Errorf("list forms: %w", err)
+		}
+		for _, f := range page {
+			// Lists leave out the slug
+			full, err := s.repo.Form().GetByID(ctx, f.ID)
+			if err != nil {
+				return nil, fmt.Errorf("get form %s: %w", f.PublicID, err)
+			}
+			if full != nil {
+				addForm(full)
+			}
+		}
+		if len(page) < pageSize {
+			break
+		}
+	}
+	trashed, err := s.repo.Form().ListDeleted(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("list deleted forms: %w", err)
+	}
+	for _, f := range trashed {
+		addForm(f)
+	}
+	return export, nil
+}
+
+// ImportUsers creates the users of an export that don't exist here yet, matched by
+// email, and hands each listed form found here (by public ID, else slug) to its owner.
+// Existing users are left unchanged. Users exported without a password hash are
+// invited: they get a password reset token valid for UserInviteTTL, in the result's
+// InviteTokens for the caller to send. With dryRun nothing is saved.
+func (s *AuthService) ImportUsers(ctx context.Context, export *domain.UserExport, dryRun bool) (*domain.UserImportResult, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpExport)
+	defer cancel()
+	if err := export.Validate(); err != nil {
+		return nil, err
+	}
+
+	var res *domain.UserImportResult
+	err := s.repo.Tx(ctx, func(repo ports.Repository) error {
+		res = &domain.UserImportResult{
+			DryRun:       dryRun,
+			Created:      []string{},
+			Existing:     []string{},
+			Invited:      []string{},
+			InviteTokens: make(map[string]string),
+		}
+		if err := importUsers(ctx, repo, export.Users, res); err != nil {
+			return err
+		}
+		if err := importOwnership(ctx, repo, export.Forms, res); err != nil {
+			return err
+		}
+		if dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if dryRun && errors.Is(err, errDryRun) {
+		res.InviteTokens = nil
+		return res, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// importUsers creates the exported users missing from repo
+func importUsers(ctx context.Context, repo ports.Repository, users []domain.ExportedUser, res *domain.UserImportResult) error {
+	now := time.Now()
+	for _, u := range users {
+		existing, err := repo.User().GetByEmail(ctx, u.Email)
+		if err != nil && !errors.Is(err, domain.ErrUserNotFound) {
+			return fmt.Errorf("lookup user %s: %w", u.Email, err)
+		}
+		if existing != nil {
+			res.Existing = append(res.Existing, u.Email)
+			continue
+		}
+
+		user := &domain.User{
+			ID:           ids.New(ids.User),
+			Email:        u.Email,
+			PasswordHash: u.PasswordHash,
+			Name:         u.Name,
+			Role:         u.Role,
+			Timezone:     u.Timezone,
+			CreatedAt:    u.CreatedAt,
+			UpdatedAt:    now,
+		}
+		if user.CreatedAt.IsZero() {
+			user.CreatedAt = now
+		}
+		if err := repo.User().Create(ctx, user); err != nil {
+			return fmt.Errorf("create user %s: %w", u.Email, err)
+		}
+		res.Created = append(res.Created, u.Email)
+		if u.PasswordHash != "" {
+			continue
+		}
+
+		tokenBytes := make([]byte, 32)
+		if _, err := rand.Read(tokenBytes); err != nil {
+			return err
+		}
+		invite := &domain.PasswordResetToken{
+			ID:        ids.New(ids.PasswordReset),
+			UserID:    user.ID,
+			Token:     base64.URLEncoding.EncodeToString(tokenBytes),
+			ExpiresAt: now.Add(UserInviteTTL),
+			CreatedAt: now,
+		}
+		if err := repo.PasswordReset().Create(ctx, invite); err != nil {
+			return fmt.Errorf("create invite for %s: %w", u.Email, err)
+		}
+		res.Invited = append(res.Invited, u.Email)
+		res.InviteTokens[u.Email] = invite.Token
+	}
+	return nil
+}
+
+// importOwnership hands the listed forms found in repo to their owner
+func importOwnership(ctx context.Context, repo ports.Repository, forms []domain.FormOwnership, res *domain.UserImportResult) error {
+	owners := make(map[string]string) // User IDs by lowercased email
+	for _, f := range forms {
+		form, err := repo.Form().GetByPublicID(ctx, f.PublicID)
+		if err == nil && form == nil && f.Slug != "" {
+			form, err = repo.Form().GetBySlug(ctx, f.Slug)
+		}
+		if err != nil {
+			return fmt.Errorf("lookup form %s: %w", f.PublicID, err)
+		}
+		if form == nil {
+			res.FormsNotFound = append(res.FormsNotFound, formRef(f))
+			continue
+		}
+
+		key := strings.ToLower(strings.TrimSpace(f.Owner))
+		ownerID, ok := owners[key]
+		if !ok {
+			owner, err := repo.User().GetByEmail(ctx, strings.TrimSpace(f.Owner))
+			if err != nil && !errors.Is(err, domain.ErrUserNotFound) {
+				return fmt.Errorf("lookup user %s: %w", f.Owner, err)
+			}
+			if owner != nil {
+				ownerID = owner.ID
+			}
+			owners[key] = ownerID
+		}
+		if ownerID == "" {
+			res.FormsUnassigned = append(res.FormsUnassigned, formRef(f))
+			continue
+		}
+		if form.OwnerID != ownerID {
+			if err := repo.Form().SetOwner(ctx, form.ID, ownerID); err != nil {
+				return fmt.Errorf("set owner of form %s: %w", form.PublicID, err)
+			}
+		}
+		res.FormsAssigned++
+	}
+	return nil
+}
+
+// formRef names an exported form by public ID, or slug if it has none
+func formRef(f domain.FormOwnership) string {
+	if f.PublicID != "" {
+		return f.PublicID
+	}
+	return f.Slug
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"headless_form/internal/core/domain"
+	"headless_form/internal/core/ports"
+)
+
+// DefaultWebhookDeliveryRetention is how long the webhook delivery log is kept
+const DefaultWebhookDeliveryRetention = 7 * 24 * time.Hour
+
+// WebhookDeliveryService reads the webhook delivery log and replays deliveries.
+// The webhook adapter writes the log as it delivers.
+type WebhookDeliveryService struct {
+	repo      ports.Repository
+	timeouts  Timeouts
+	retention time.Duration
+	replay    func(form *domain.Form, delivery *domain.WebhookDelivery) (*domain.WebhookDelivery, error)
+}
+
+// NewWebhookDeliveryService creates a new webhook delivery service
+func NewWebhookDeliveryService(repo ports.Repository) *WebhookDeliveryService {
+	return &WebhookDeliveryService{repo: repo, timeouts: DefaultTimeouts, retention: DefaultWebhookDeliveryRetention}
+}
+
+// SetTimeouts overrides the per-operation timeouts (see DefaultTimeouts)
+func (s *WebhookDeliveryService) SetTimeouts(t Timeouts) {
+	s.timeouts = t
+}
+
+// SetRetention sets how long deliveries are kept before they're pruned
+func (s *WebhookDeliveryService) SetRetention(d time.Duration) {
+	if d > 0 {
+		s.retention = d
+	}
+}
+
+// SetReplayer sets the function that sends a delivery's payload again and returns
+// the new delivery
+func (s *WebhookDeliveryService) SetReplayer(fn func(form *domain.Form, delivery *domain.WebhookDelivery) (*domain.WebhookDelivery, error)) {
+	s.replay = fn
+}
+
+// ListDeliveries returns one page of a form's deliveries, newest first, without their
+// payloads, and how many the form has in total
+func (s *WebhookDeliveryService) ListDeliveries(ctx context.Context, publicID string, page, limit int) ([]*domain.WebhookDelivery, int, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpList)
+	defer cancel()
+	form, err := s.repo.Form().GetByPublicID(ctx, publicID)
+	if err != nil || form == nil {
+		return nil, 0, domain.ErrFormNotFound
+	}
+	deliveries, total, err := s.repo.WebhookDelivery().ListByFormID(ctx, form.ID, limit, (page-1)*limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list webhook deliveries: %w", err)
+	}
+	for _, d := range deliveries {
+		d.Payload = nil
+	}
+	return deliveries, total, nil
+}
+
+// GetDelivery returns one of the form's deliveries, with its payload
+func (s *WebhookDeliveryService) GetDelivery(ctx context.Context, publicID, id string) (*domain.WebhookDelivery, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpLookup)
+	defer cancel()
+	_, delivery, err := s.lookup(ctx, publicID, id)
+	return delivery, err
+}
+
+// Replay sends a delivery's payload again to the form's current webhook URL, as a
+// new delivery attempted in the background, and returns the new delivery
+func (s *WebhookDeliveryService) Replay(ctx context.Context, publicID, id string) (*domain.WebhookDelivery, error) {
+	ctx, cancel := s.timeouts.bound(ctx, OpWrite)
+	defer cancel()
+	form, delivery, err := s.lookup(ctx, publicID, id)
+	if err != nil {
+		return nil, err
+	}
+	if s.replay == nil {
+		return nil, domain.ErrWebhookNotConfigured
+	}
+	return s.replay(form, delivery)
+}
+
+// lookup returns the form and its delivery, or ErrWebhookDeliveryNotFound for
+// deliveries of other forms
+func (s *WebhookDeliveryService) lookup(ctx context.Context, publicID, id string) (*domain.Form, *domain.WebhookDelivery, error) {
+	form, err := s.repo.Form().GetByPublicID(ctx, publicID)
+	if err != nil || form == nil {
+		return nil, nil, domain.ErrFormNotFound
+	}
+	delivery, err := s.repo.WebhookDelivery().GetByID(ctx, id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get webhook delivery: %w", err)
+	}
+	if delivery == nil || delivery.FormID != form.ID {
+		return nil, nil, domain.ErrWebhookDeliveryNotFound
+	}
+	return form, delivery, nil
+}
+
+// PruneDeliveries deletes deliveries older than the retention period.
+// Returns the number of deliveries deleted.
+func (s *WebhookDeliveryService) PruneDeliveries(ctx context.Context) (int, error) {
+	n, err := s.repo.WebhookDelivery().DeleteBefore(ctx, time.Now().Add(-s.retention))
+	if err != nil {
+		return 0, fmt.Errorf("prune webhook deliveries: %w", err)
+	}
+	return n, nil
+}
+
+// StartPrune runs PruneDeliveries every interval until ctx is cancelled,
+// skipping runs while paused reports true (nil never pauses)
+func (s *WebhookDeliveryService) StartPrune(ctx context.Context, interval time.Duration, paused func() bool) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if paused != nil && paused() {
+					continue
+				}
+				if n, err := s.PruneDeliveries(ctx); err != nil {
+					log.Printf("[WEBHOOKS] Delivery log pruning failed: %v", err)
+				} else if n > 0 {
+					log.Printf("[WEBHOOKS] Pruned %d webhook deliveries", n)
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,151 @@
+// Package textnorm normalizes submitted text: Unicode NFC, control characters and whitespace
+package textnorm
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Hangul syllables are composed and decomposed algorithmically
+const (
+	hangulSBase  = 0xAC00
+	hangulLBase  = 0x1100
+	hangulVBase  = 0x1161
+	hangulTBase  = 0x11A7
+	hangulLCount = 19
+	hangulVCount = 21
+	hangulTCount = 28
+	hangulNCount = hangulVCount * hangulTCount
+	hangulSCount = hangulLCount * hangulNCount
+)
+
+// Clean returns s as NFC with invalid UTF-8 dropped, line breaks as "\n", every run of other
+// control characters replaced by a space, and leading and trailing whitespace trimmed.
+// Tabs and newlines inside the text are kept, so multi-line answers keep their shape.
+func Clean(s string) string {
+	s = strings.ToValidUTF8(s, "")
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.ReplaceAll(s, "\r", "\n")
+
+	var b strings.Builder
+	b.Grow(len(s))
+	inRun := false
+	for _, r := range s {
+		if unicode.IsControl(r) && r != '\n' && r != '\t' {
+			if !inRun {
+				b.WriteByte(' ')
+				inRun = true
+			}
+			continue
+		}
+		inRun = false
+		b.WriteRune(r)
+	}
+	return strings.TrimSpace(NFC(b.String()))
+}
+
+// NFC returns s in Unicode Normalization Form C, so the same text typed on different
+// systems (e.g. "é" as one character or as "e" and a combining accent) compares equal
+func NFC(s string) string {
+	if quickNFC(s) {
+		return s
+	}
+	runes := decompose(s)
+	reorder(runes)
+	return string(compose(runes))
+}
+
+// quickNFC reports whether s only holds runes that are NFC in any context
+func quickNFC(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			for _, r := range s[i:] {
+				if r >= 0x0300 {
+					return false
+				}
+			}
+			return true
+		}
+	}
+	return true
+}
+
+// decompose returns the canonical decomposition of s
+func decompose(s string) []rune {
+	runes := make([]rune, 0, len(s))
+	for _, r := range s {
+		if r >= hangulSBase && r < hangulSBase+hangulSCount {
+			i := r - hangulSBase
+			runes = append(runes, hangulLBase+i/hangulNCount, hangulVBase+(i%hangulNCount)/hangulTCount)
+			if t := i % hangulTCount; t != 0 {
+				runes = append(runes, hangulTBase+t)
+			}
+			continue
+		}
+		if d, ok := decompositions[r]; ok {
+			runes = append(runes, []rune(d)...)
+			continue
+		}
+		runes = append(runes, r)
+	}
+	return runes
+}
+
+// reorder sorts each run of combining marks by combining class, keeping the order of equal classes
+func reorder(runes []rune) {
+	for i := 1; i < len(runes); i++ {
+		c := combiningClass[runes[i]]
+		if c == 0 {
+			continue
+		}
+		for j := i; j > 0; j-- {
+			prev := combiningClass[runes[j-1]]
+			if prev <= c {
+				break
+			}
+			runes[j-1], runes[j] = runes[j], runes[j-1]
+		}
+	}
+}
+
+// compose joins each starter with the following marks it isn't blocked from
+func compose(runes []rune) []rune {
+	if len(runes) == 0 {
+		return runes
+	}
+	out := runes[:1]
+	starter := 0
+	var lastClass uint8
+	if combiningClass[runes[0]] != 0 {
+		lastClass = 255 // No starter yet: nothing composes until one comes
+	}
+	for _, r := range runes[1:] {
+		c := combiningClass[r]
+		if lastClass < c || lastClass == 0 {
+			if composite, ok := composePair(out[starter], r); ok {
+				out[starter] = composite
+				continue
+			}
+		}
+		if c == 0 {
+			starter = len(out)
+		}
+		lastClass = c
+		out = append(out, r)
+	}
+	return out
+}
+
+// composePair returns the primary composite of a and b, if there is one
+func composePair(a, b rune) (rune, bool) {
+	if a >= hangulLBase && a < hangulLBase+hangulLCount && b >= hangulVBase && b < hangulVBase+hangulVCount {
+		return hangulSBase + ((a-hangulLBase)*hangulVCount+(b-hangulVBase))*hangulTCount, true
+	}
+	if a >= hangulSBase && a < hangulSBase+hangulSCount && (a-hangulSBase)%hangulTCount == 0 &&
+		b > hangulTBase && b < hangulTBase+hangulTCount {
+		return a + (b - hangulTBase), true
+	}
+	r, ok := compositions[[2]rune{a, b}]
+	return r, ok
+}
@@ -0,0 +1,51 @@
+package textnorm
+
+import "testing"
+
+func TestNFC(t *testing.T) {
+	// Expected values from Python's unicodedata.normalize("NFC", ...)
+	cases := []struct{ in, want string }{
+		{"plain ascii", "plain ascii"},
+		{"caf\u00E9", "caf\u00E9"},
+		{"cafe\u0301", "caf\u00E9"},
+		{"e\u0301\u0323", "\u1EB9\u0301"},
+		{"\u1EC7", "\u1EC7"},
+		{"e\u0323\u0302", "\u1EC7"},
+		{"\u1100\u1161\u11A8", "\uAC01"},
+		{"\u1100\u1161", "\uAC00"},
+		{"\u212B", "\u00C5"},
+		{"A\u030A", "\u00C5"},
+		{"\u0344", "\u0308\u0301"},
+		{"a\u0308\u0301x", "\u00E4\u0301x"},
+		{"\u0301a", "\u0301a"},
+		{"\u0915\u093C", "\u0915\u093C"},
+		{"\u00C5\u0327", "\u00C5\u0327"},
+		{"ni\u0303o Sa\u0303o Paulo", "n\u0129o S\u00E3o Paulo"},
+		{"\u03B1\u0313\u0301\u0345", "\u1F84"},
+		{"\uAC00\u11A8", "\uAC01"},
+		{"\u05D0\u05B7\u05BC", "\u05D0\u05B7\u05BC"},
+		{"o\u031B\u0300", "\u1EDD"},
+	}
+	for _, c := range cases {
+		if got := NFC(c.in); got != c.want {
+			t.Errorf("NFC(%+q) = %+q, want %+q", c.in, got, c.want)
+		}
+	}
+}
+
+func TestClean(t *testing.T) {
+	cases := []struct{ in, want string }{
+		{"  Ada Lovelace \n", "Ada Lovelace"},
+		{"line one\r\nline two\rthree", "line one\nline two\nthree"},
+		{"tab\tkept", "tab\tkept"},
+		{"a\x00\x01\x02b", "a b"},
+		{"zero\u0085width", "zero width"},
+		{"bad \xff utf8", "bad  utf8"},
+		{" Jose\u0301 ", "Jos\u00e9"},
+	}
+	for _, c := range cases {
+		if got := Clean(c.in); got != c.want {
+			t.Errorf("Clean(%+q) = %+q, want %+q", c.in, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+// Package testharness provides local stand-ins for the services HeadlessForms talks to, an SMTP
+// sink and a webhook receiver, so tests can check what's really sent over the wire
+package testharness
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/mail"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Message is an email received by the SMTP sink
+type Message struct {
+	From     string   // MAIL FROM address
+	To       []string // RCPT TO addresses
+	Username string   // Credentials sent with AUTH PLAIN, if any
+	Password string
+	Data     string // The raw message, headers and body
+}
+
+// Parse returns the message's headers and body
+func (m Message) Parse() (*mail.Message, error) {
+	return mail.ReadMessage(strings.NewReader(m.Data))
+}
+
+// SMTPSink is an SMTP server on localhost that accepts every message and keeps it. It offers
+// AUTH PLAIN but no STARTTLS, so clients send in plain text. net/smtp allows that for localhost.
+type SMTPSink struct {
+	ln net.Listener
+
+	mu       sync.Mutex
+	messages []Message
+	received chan struct{}
+	rejectTo map[string]bool
+}
+
+// NewSMTPSink starts an SMTP sink, stopped when the test ends
+func NewSMTPSink(t testing.TB) *SMTPSink {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("smtp sink: listen: %v", err)
+	}
+	s := &SMTPSink{ln: ln, received: make(chan struct{}, 100), rejectTo: make(map[string]bool)}
+	t.Cleanup(func() { _ = ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+// Host is the host to connect to, "localhost" so net/smtp sends credentials without TLS
+func (s *SMTPSink) Host() string {
+	return "localhost"
+}
+
+// Port is the port the sink listens on
+func (s *SMTPSink) Port() int {
+	return s.ln.Addr().(*net.TCPAddr).Port
+}
+
+// Reject makes the sink refuse mail to addr with a 550
+func (s *SMTPSink) Reject(addr string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rejectTo[strings.ToLower(addr)] = true
+}
+
+// Messages returns the messages received so far
+func (s *SMTPSink) Messages() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Message(nil), s.messages...)
+}
+
+// Wait waits up to 5 seconds for n messages in total and returns them
+func (s *SMTPSink) Wait(t testing.TB, n int) []Message {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		if msgs := s.Messages(); len(msgs) >= n {
+			return msgs
+		}
+		select {
+		case <-s.received:
+		case <-timeout:
+			t.Fatalf("smtp sink: expected %d messages, got %d", n, len(s.Messages()))
+		}
+	}
+}
+
+func (s *SMTPSink) serve(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+	_ = conn.SetDeadline(time.Now().Add(30 * time.Second))
+	r := bufio.NewReader(conn)
+	reply := func(format string, args ...interface{}) {
+		_, _ = fmt.Fprintf(conn, format+"\r\n", args...)
+	}
+
+	reply("220 localhost ESMTP testharness")
+	var msg Message
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO":
+			reply("250-localhost")
+			reply("250-8BITMIME")
+			reply("250 AUTH PLAIN")
+		case "HELO":
+			reply("250 localhost")
+		case "AUTH":
+			mech, initial, _ := strings.Cut(arg, " ")
+			if !strings.EqualFold(mech, "PLAIN") {
+				reply("504 unsupported mechanism")
+				continue
+			}
+			creds, err := base64.StdEncoding.DecodeString(initial)
+			parts := strings.Split(string(creds), "\x00")
+			if err != nil || len(parts) != 3 {
+				reply("501 malformed credentials")
+				continue
+			}
+			msg.Username, msg.Password = parts[1], parts[2]
+			reply("235 authenticated")
+		case "MAIL":
+			msg.From = address(arg)
+			msg.To = nil
+			reply("250 ok")
+		case "RCPT":
+			to := address(arg)
+			s.mu.Lock()
+			rejected := s.rejectTo[strings.ToLower(to)]
+			s.mu.Unlock()
+			if rejected {
+				reply("550 mailbox unavailable")
+				continue
+			}
+			msg.To = append(msg.To, to)
+			reply("250 ok")
+		case "DATA":
+			reply("354 end data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" || l == ".\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(l, ".")) // Undo dot-stuffing
+			}
+			msg.Data = data.String()
+			s.mu.Lock()
+			s.messages = append(s.messages, msg)
+			s.mu.Unlock()
+			select {
+			case s.received <- struct{}{}:
+			default:
+			}
+			msg = Message{Username: msg.Username, Password: msg.Password}
+			reply("250 queued")
+		case "RSET":
+			msg = Message{Username: msg.Username, Password: msg.Password}
+			reply("250 ok")
+		case "NOOP":
+			reply("250 ok")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 command not implemented")
+		}
+	}
+}
+
+// address returns the address in a MAIL FROM:<...> or RCPT TO:<...> argument
+func address(arg string) string {
+	_, addr, _ := strings.Cut(arg, ":")
+	addr, _, _ = strings.Cut(strings.TrimSpace(addr), " ")
+	return strings.Trim(addr, "<>")
+}
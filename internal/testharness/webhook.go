@@ -0,0 +1,95 @@
+package testharness
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Request is a request received by the webhook receiver
+type Request struct {
+	Method string
+	Header http.Header
+	Body   []byte
+	At     time.Time
+	Status int // What the receiver answered
+}
+
+// WebhookReceiver is an HTTP server that records every request. It answers 200, or the
+// status set with FailNext for the next requests, so tests can exercise retries.
+type WebhookReceiver struct {
+	server *httptest.Server
+
+	mu       sync.Mutex
+	requests []Request
+	failures []int
+	received chan struct{}
+}
+
+// NewWebhookReceiver starts a webhook receiver, stopped when the test ends
+func NewWebhookReceiver(t testing.TB) *WebhookReceiver {
+	t.Helper()
+	w := &WebhookReceiver{received: make(chan struct{}, 100)}
+	w.server = httptest.NewServer(http.HandlerFunc(w.handle))
+	t.Cleanup(w.server.Close)
+	return w
+}
+
+// URL is the receiver's address, to set as a form's webhook URL
+func (w *WebhookReceiver) URL() string {
+	return w.server.URL
+}
+
+// FailNext makes the receiver answer the next n requests with status
+func (w *WebhookReceiver) FailNext(n, status int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for i := 0; i < n; i++ {
+		w.failures = append(w.failures, status)
+	}
+}
+
+// Requests returns the requests received so far
+func (w *WebhookReceiver) Requests() []Request {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]Request(nil), w.requests...)
+}
+
+// Wait waits up to 5 seconds for n requests in total and returns them
+func (w *WebhookReceiver) Wait(t testing.TB, n int) []Request {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		if reqs := w.Requests(); len(reqs) >= n {
+			return reqs
+		}
+		select {
+		case <-w.received:
+		case <-timeout:
+			t.Fatalf("webhook receiver: expected %d requests, got %d", n, len(w.Requests()))
+		}
+	}
+}
+
+func (w *WebhookReceiver) handle(rw http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	w.mu.Lock()
+	status := http.StatusOK
+	if len(w.failures) > 0 {
+		status, w.failures = w.failures[0], w.failures[1:]
+	}
+	w.requests = append(w.requests, Request{Method: r.Method, Header: r.Header.Clone(), Body: body, At: time.Now(), Status: status})
+	w.mu.Unlock()
+
+	rw.WriteHeader(status)
+	_, _ = rw.Write([]byte(http.StatusText(status)))
+	select {
+	case w.received <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,72 @@
+package submit
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Error codes the submission endpoint can return (GET /api/v1/meta/errors lists them all)
+const (
+	CodeValidationError    = "VALIDATION_ERROR"    // 400: e.g. an oversized Idempotency-Key
+	CodeInvalidBody        = "INVALID_BODY"        // 400: body is not valid JSON
+	CodeInvalidForm        = "INVALID_FORM"        // 400: form-encoded body could not be parsed
+	CodeConsentRequired    = "CONSENT_REQUIRED"    // 400: a required consent checkbox was not checked
+	CodeSubmissionFailed   = "SUBMISSION_FAILED"   // 400: e.g. the form is inactive
+	CodeAuthRequired       = "AUTH_REQUIRED"       // 401: private form without a login or signature
+	CodeInvalidSignature   = "INVALID_SIGNATURE"   // 401: signature doesn't match or is too old
+	CodeInvalidKey         = "INVALID_KEY"         // 403: submission key is missing or wrong
+	CodeCaptchaFailed      = "CAPTCHA_FAILED"      // 403: captcha token missing or rejected by the provider
+	CodeOriginNotAllowed   = "ORIGIN_NOT_ALLOWED"  // 403: origin not in the form's allowed origins
+	CodeFormClosed         = "FORM_CLOSED"         // 403: before the form's opens_at or after its closes_at
+	CodeNotFound           = "NOT_FOUND"           // 404: no form with this ID
+	CodeRateLimited        = "RATE_LIMITED"        // 429: too many requests; retried automatically
+	CodeInternalError      = "INTERNAL_ERROR"      // 500: retried automatically
+	CodeCaptchaUnavailable = "CAPTCHA_UNAVAILABLE" // 503: captcha provider unreachable; retried automatically
+	CodeTimeout            = "TIMEOUT"             // 504: retried automatically
+)
+
+// Error is a submission the server rejected
+type Error struct {
+	StatusCode int    // HTTP status
+	Code       string // One of the Code constants; empty if the server sent none
+	Message    string
+}
+
+func (e *Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("submit: %d %s", e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("submit: %d %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+// CodeOf returns the API error code of err, or "" if it isn't an *Error
+func CodeOf(err error) string {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Code
+	}
+	return ""
+}
+
+// networkError is a request that never got a response
+type networkError struct {
+	err error
+}
+
+func (e *networkError) Error() string { return "submit: " + e.err.Error() }
+func (e *networkError) Unwrap() error { return e.err }
+
+// retryable reports whether another attempt might succeed: network errors,
+// rate limits and server errors, but not rejected submissions
+func retryable(err error) bool {
+	var netErr *networkError
+	if errors.As(err, &netErr) {
+		return true
+	}
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode >= 500
+	}
+	return false
+}
@@ -0,0 +1,296 @@
+// Package submit is a client for the public submission endpoint of a headless form server.
+//
+// It retries network errors, rate limits and server errors with the same
+// Idempotency-Key, so a retried submission is never stored twice, and reports
+// rejected submissions as *Error values carrying the API's error code.
+//
+//	client := submit.New("https://forms.example.com")
+//	sub, err := client.Submit(ctx, "frm_abc123", map[string]any{"email": "visitor@example.com"})
+//	if submit.CodeOf(err) == submit.CodeConsentRequired {
+//		// ask the visitor to tick the box
+//	}
+package submit
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Request headers and fields understood by the submission endpoint
+const (
+	IdempotencyHeader        = "Idempotency-Key"
+	SignatureHeader          = "X-Form-Signature"
+	SignatureTimestampHeader = "X-Form-Timestamp"
+	TimingField              = "_ts" // When the form was shown, in Unix milliseconds
+)
+
+// Defaults for New
+const (
+	DefaultRetries = 3
+	DefaultBackoff = 500 * time.Millisecond
+	DefaultTimeout = 15 * time.Second
+)
+
+// Submission is a stored submission as returned by the server
+type Submission struct {
+	ID        string                 `json:"id"`
+	FormID    string                 `json:"form_id"`
+	Status    string                 `json:"status"`
+	Data      map[string]interface{} `json:"data"`
+	Meta      map[string]interface{} `json:"meta"`
+	CreatedAt time.Time              `json:"created_at"`
+
+	// Replayed is set when the server answered a retry with the submission an
+	// earlier attempt already stored
+	Replayed bool `json:"-"`
+}
+
+// Client submits to forms on one server. It's safe for concurrent use.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	retries    int
+	backoff    time.Duration
+	signingKey string
+	token      string
+	userAgent  string
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client used for requests
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = hc
+	}
+}
+
+// WithRetries sets how many times a failed attempt is retried (0 disables retries)
+func WithRetries(n int) Option {
+	return func(c *Client) {
+		c.retries = n
+	}
+}
+
+// WithBackoff sets the delay before the first retry; it doubles on every retry
+func WithBackoff(d time.Duration) Option {
+	return func(c *Client) {
+		c.backoff = d
+	}
+}
+
+// WithSigningKey signs every request with a private form's submission key,
+// so backends can submit without a user token
+func WithSigningKey(key string) Option {
+	return func(c *Client) {
+		c.signingKey = key
+	}
+}
+
+// WithToken sends a bearer token, for private forms that require a login
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// WithUserAgent sets the User-Agent header. The server's spam check scores
+// generic HTTP library agents higher, so set one that names your integration.
+func WithUserAgent(ua string) Option {
+	return func(c *Client) {
+		c.userAgent = ua
+	}
+}
+
+// New creates a client for the server at baseURL (e.g. "https://forms.example.com")
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: DefaultTimeout},
+		retries:    DefaultRetries,
+		backoff:    DefaultBackoff,
+		userAgent:  "headless-form-submit-go/1",
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// SubmitOption configures one submission
+type SubmitOption func(*submitOptions)
+
+type submitOptions struct {
+	idempotencyKey string
+	shownAt        time.Time
+	meta           map[string]interface{}
+}
+
+// WithIdempotencyKey sets the key identifying this submission across retries,
+// e.g. an order ID. A random key is used by default.
+func WithIdempotencyKey(key string) SubmitOption {
+	return func(o *submitOptions) {
+		o.idempotencyKey = key
+	}
+}
+
+// WithShownAt sends when the form was shown to the visitor, so the server can
+// flag forms filled in faster than a person could
+func WithShownAt(t time.Time) SubmitOption {
+	return func(o *submitOptions) {
+		o.shownAt = t
+	}
+}
+
+// WithMeta sends client metadata, stored apart from the submitted fields
+func WithMeta(meta map[string]interface{}) SubmitOption {
+	return func(o *submitOptions) {
+		o.meta = meta
+	}
+}
+
+// Submit sends data to the form with the given public ID. Honeypot fields in
+// data are passed through as they are for the server's spam check.
+func (c *Client) Submit(ctx context.Context, formID string, data map[string]interface{}, opts ...SubmitOption) (*Submission, error) {
+	o := submitOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.idempotencyKey == "" {
+		o.idempotencyKey = newKey()
+	}
+
+	fields := make(map[string]interface{}, len(data)+1)
+	for k, v := range data {
+		fields[k] = v
+	}
+	if !o.shownAt.IsZero() {
+		fields[TimingField] = o.shownAt.UnixMilli()
+	}
+	var payload interface{} = fields
+	if o.meta != nil {
+		payload = map[string]interface{}{"data": fields, "meta": o.meta}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("encode submission: %w", err)
+	}
+
+	endpoint := c.baseURL + "/api/v1/submissions/" + url.PathEscape(formID)
+	delay := c.backoff
+	for attempt := 0; ; attempt++ {
+		sub, retryAfter, err := c.do(ctx, endpoint, body, o.idempotencyKey)
+		if err == nil || attempt >= c.retries || !retryable(err) {
+			return sub, err
+		}
+
+		wait := delay
+		if retryAfter > wait {
+			wait = retryAfter
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+		delay *= 2
+	}
+}
+
+// do makes one attempt, returning the server's Retry-After delay if it sent one
+func (c *Client) do(ctx context.Context, endpoint string, body []byte, idempotencyKey string) (*Submission, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", c.userAgent)
+	req.Header.Set(IdempotencyHeader, idempotencyKey)
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if c.signingKey != "" {
+		timestamp := time.Now().UTC().Format(time.RFC3339)
+		req.Header.Set(SignatureTimestampHeader, timestamp)
+		req.Header.Set(SignatureHeader, Sign(c.signingKey, timestamp, body))
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, 0, &networkError{err}
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, 0, &networkError{err}
+	}
+
+	var envelope struct {
+		Status  string          `json:"status"`
+		Data    json.RawMessage `json:"data"`
+		Message string          `json:"message"`
+		Code    string          `json:"code"`
+	}
+	decodeErr := json.Unmarshal(raw, &envelope)
+
+	if resp.StatusCode >= 300 {
+		apiErr := &Error{StatusCode: resp.StatusCode, Code: envelope.Code, Message: envelope.Message}
+		if decodeErr != nil || apiErr.Message == "" {
+			apiErr.Message = http.StatusText(resp.StatusCode)
+		}
+		return nil, retryAfter(resp.Header.Get("Retry-After")), apiErr
+	}
+	if decodeErr != nil {
+		return nil, 0, fmt.Errorf("decode response: %w", decodeErr)
+	}
+
+	var sub Submission
+	if err := json.Unmarshal(envelope.Data, &sub); err != nil {
+		return nil, 0, fmt.Errorf("decode submission: %w", err)
+	}
+	sub.Replayed = resp.Header.Get("Idempotent-Replayed") == "true"
+	return &sub, 0, nil
+}
+
+// Sign returns the X-Form-Signature value for a body sent with the given
+// X-Form-Timestamp: an HMAC-SHA256 of "timestamp.body" keyed with the form's
+// submission key. The timestamp is required: an RFC 3339 time within five
+// minutes of the server's clock.
+func Sign(key, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// newKey returns a random idempotency key
+func newKey() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// retryAfter parses a Retry-After header given in seconds
+func retryAfter(v string) time.Duration {
+	secs, err := strconv.Atoi(v)
+	if err != nil || secs < 0 {
+		return 0
+	}
+	return time.Duration(secs) * time.Second
+}
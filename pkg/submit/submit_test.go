@@ -0,0 +1,124 @@
+package submit
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSubmit_RetriesWithSameKey(t *testing.T) {
+	var mu sync.Mutex
+	var keys []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		keys = append(keys, r.Header.Get(IdempotencyHeader))
+		attempt := len(keys)
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		switch attempt {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"status":"fail","message":"Rate limit exceeded","code":"RATE_LIMITED"}`))
+		default:
+			w.Header().Set("Idempotent-Replayed", "true")
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"status":"success","data":{"id":"sub_1","form_id":"frm_1","status":"unread","data":{"email":"a@example.com"}}}`))
+		}
+	}))
+	defer srv.Close()
+
+	client := New(srv.URL, WithBackoff(time.Millisecond))
+	sub, err := client.Submit(context.Background(), "frm_1", map[string]interface{}{"email": "a@example.com"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sub.ID != "sub_1" || sub.Data["email"] != "a@example.com" || !sub.Replayed {
+		t.Errorf("unexpected submission %+v", sub)
+	}
+	if len(keys) != 3 || keys[0] == "" || keys[0] != keys[1] || keys[1] != keys[2] {
+		t.Errorf("expected 3 attempts with one idempotency key, got %v", keys)
+	}
+}
+
+func TestSubmit_TypedErrors(t *testing.T) {
+	attempts := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"status":"error","message":"Consent required: terms","code":"CONSENT_REQUIRED"}`))
+	}))
+	defer srv.Close()
+
+	_, err := New(srv.URL, WithBackoff(time.Millisecond)).Submit(context.Background(), "frm_1", nil)
+	if CodeOf(err) != CodeConsentRequired {
+		t.Fatalf("expected CONSENT_REQUIRED, got %v", err)
+	}
+	if apiErr := err.(*Error); apiErr.StatusCode != http.StatusBadRequest || apiErr.Message != "Consent required: terms" {
+		t.Errorf("unexpected error %+v", apiErr)
+	}
+	if attempts != 1 {
+		t.Errorf("expected rejected submissions not to be retried, got %d attempts", attempts)
+	}
+
+	// Network errors are retried, then reported without a code
+	srv.Close()
+	_, err = New(srv.URL, WithRetries(1), WithBackoff(time.Millisecond)).Submit(context.Background(), "frm_1", nil)
+	if err == nil || CodeOf(err) != "" {
+		t.Errorf("expected a network error, got %v", err)
+	}
+}
+
+func TestSubmit_SignsAndSendsTiming(t *testing.T) {
+	shown := time.Now().Add(-10 * time.Second)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		ts := r.Header.Get(SignatureTimestampHeader)
+		if ts == "" || r.Header.Get(SignatureHeader) != Sign("s3cret", ts, body) {
+			t.Errorf("expected a valid timestamped signature, got %q", r.Header.Get(SignatureHeader))
+		}
+
+		var payload struct {
+			Data map[string]interface{} `json:"data"`
+			Meta map[string]interface{} `json:"meta"`
+		}
+		_ = json.Unmarshal(body, &payload)
+		if payload.Data[TimingField] != float64(shown.UnixMilli()) || payload.Data["_hp"] != "" {
+			t.Errorf("expected timing and honeypot fields, got %v", payload.Data)
+		}
+		if payload.Meta["source"] != "checkout" {
+			t.Errorf("expected client meta, got %v", payload.Meta)
+		}
+		if r.Header.Get(IdempotencyHeader) != "order-42" {
+			t.Errorf("expected the given idempotency key, got %q", r.Header.Get(IdempotencyHeader))
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"status":"success","data":{"id":"sub_1"}}`))
+	}))
+	defer srv.Close()
+
+	client := New(srv.URL+"/", WithSigningKey("s3cret"))
+	_, err := client.Submit(context.Background(), "frm_1", map[string]interface{}{"email": "a@example.com", "_hp": ""},
+		WithShownAt(shown), WithIdempotencyKey("order-42"), WithMeta(map[string]interface{}{"source": "checkout"}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestSign_CoversTimestamp(t *testing.T) {
+	// printf '%s' '2026-01-02T15:04:05Z.{"a":1}' | openssl dgst -sha256 -hmac s3cret
+	want := "sha256=bf7f8a1583b682ac2d56dd27c95b78a69d14c23b915af278c29e5f3c7b3a7458"
+	if got := Sign("s3cret", "2026-01-02T15:04:05Z", []byte(`{"a":1}`)); got != want {
+		t.Errorf("expected the timestamp and body signed, got %s", got)
+	}
+}
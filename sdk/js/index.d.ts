@@ -0,0 +1,83 @@
+export declare const IDEMPOTENCY_HEADER: "Idempotency-Key";
+export declare const TIMING_FIELD: "_ts";
+export declare const HONEYPOT_FIELD: "_hp";
+
+export declare const ErrorCodes: {
+  readonly VALIDATION_ERROR: "VALIDATION_ERROR";
+  readonly INVALID_BODY: "INVALID_BODY";
+  readonly INVALID_FORM: "INVALID_FORM";
+  readonly CONSENT_REQUIRED: "CONSENT_REQUIRED";
+  readonly SUBMISSION_FAILED: "SUBMISSION_FAILED";
+  readonly AUTH_REQUIRED: "AUTH_REQUIRED";
+  readonly INVALID_SIGNATURE: "INVALID_SIGNATURE";
+  readonly INVALID_KEY: "INVALID_KEY";
+  readonly CAPTCHA_FAILED: "CAPTCHA_FAILED";
+  readonly ORIGIN_NOT_ALLOWED: "ORIGIN_NOT_ALLOWED";
+  readonly FORM_CLOSED: "FORM_CLOSED";
+  readonly NOT_FOUND: "NOT_FOUND";
+  readonly RATE_LIMITED: "RATE_LIMITED";
+  readonly INTERNAL_ERROR: "INTERNAL_ERROR";
+  readonly CAPTCHA_UNAVAILABLE: "CAPTCHA_UNAVAILABLE";
+  readonly TIMEOUT: "TIMEOUT";
+  readonly NETWORK_ERROR: "NETWORK_ERROR";
+};
+
+export type ErrorCode = (typeof ErrorCodes)[keyof typeof ErrorCodes];
+
+export declare class SubmitError extends Error {
+  /** HTTP status, or 0 if no response was received */
+  readonly status: number;
+  /** API error code; empty if the server sent none */
+  readonly code: ErrorCode | "";
+  /** Whether another attempt might succeed */
+  readonly retryable: boolean;
+}
+
+export interface ClientOptions {
+  /** Server URL, e.g. "https://forms.example.com". Empty for the current origin. */
+  baseUrl?: string;
+  /** Retries after network errors, rate limits and server errors. Default 3. */
+  retries?: number;
+  /** Delay before the first retry in ms, doubled on every retry. Default 500. */
+  backoff?: number;
+  /** Bearer token for private forms that require a login */
+  token?: string;
+  /** fetch implementation, for runtimes without a global one */
+  fetch?: typeof fetch;
+}
+
+export interface SubmitOptions {
+  /** Identifies the submission across retries. Random by default. */
+  idempotencyKey?: string;
+  /** When the form was shown, for the server's too-fast-to-be-human check */
+  shownAt?: Date | number;
+  /** Client metadata, stored apart from the submitted fields */
+  meta?: Record<string, unknown>;
+  signal?: AbortSignal;
+}
+
+export interface Submission {
+  id: string;
+  form_id: string;
+  status: string;
+  data: Record<string, unknown>;
+  meta: Record<string, unknown>;
+  created_at: string;
+  /** The server answered a retry with the submission an earlier attempt stored */
+  replayed: boolean;
+}
+
+export interface BindOptions {
+  /** Name of the hidden honeypot field added to the form. Default "_hp"; false to skip. */
+  honeypot?: string | false;
+  onSuccess?: (submission: Submission) => void;
+  onError?: (error: SubmitError) => void;
+}
+
+export interface Client {
+  submit(formId: string, data: Record<string, unknown>, options?: SubmitOptions): Promise<Submission>;
+  /** Submits the form with fetch; returns a function that unbinds it */
+  bind(form: HTMLFormElement, formId: string, options?: BindOptions): () => void;
+}
+
+export declare function createClient(options?: ClientOptions): Client;
@@ -0,0 +1,157 @@
+// Submit client for the HeadlessForms public submission endpoint.
+// Works in browsers and Node 18+ (anything with fetch). No dependencies.
+
+export const IDEMPOTENCY_HEADER = "Idempotency-Key";
+export const TIMING_FIELD = "_ts";
+export const HONEYPOT_FIELD = "_hp";
+
+/** Error codes the submission endpoint can return (GET /api/v1/meta/errors lists them all) */
+export const ErrorCodes = Object.freeze({
+  VALIDATION_ERROR: "VALIDATION_ERROR",
+  INVALID_BODY: "INVALID_BODY",
+  INVALID_FORM: "INVALID_FORM",
+  CONSENT_REQUIRED: "CONSENT_REQUIRED",
+  SUBMISSION_FAILED: "SUBMISSION_FAILED",
+  AUTH_REQUIRED: "AUTH_REQUIRED",
+  INVALID_SIGNATURE: "INVALID_SIGNATURE",
+  INVALID_KEY: "INVALID_KEY",
+  CAPTCHA_FAILED: "CAPTCHA_FAILED",
+  ORIGIN_NOT_ALLOWED: "ORIGIN_NOT_ALLOWED",
+  FORM_CLOSED: "FORM_CLOSED",
+  NOT_FOUND: "NOT_FOUND",
+  RATE_LIMITED: "RATE_LIMITED",
+  INTERNAL_ERROR: "INTERNAL_ERROR",
+  CAPTCHA_UNAVAILABLE: "CAPTCHA_UNAVAILABLE",
+  TIMEOUT: "TIMEOUT",
+  NETWORK_ERROR: "NETWORK_ERROR", // Client-side: no response was received
+});
+
+/** A submission the server rejected, or one that never got a response */
+export class SubmitError extends Error {
+  constructor(message, { status = 0, code = "", cause } = {}) {
+    super(message, { cause });
+    this.name = "SubmitError";
+    this.status = status;
+    this.code = code;
+  }
+
+  /** Whether another attempt might succeed */
+  get retryable() {
+    return this.code === ErrorCodes.NETWORK_ERROR || this.status === 429 || this.status >= 500;
+  }
+}
+
+function newKey() {
+  if (globalThis.crypto?.randomUUID) return globalThis.crypto.randomUUID();
+  return `${Date.now().toString(36)}-${Math.random().toString(36).slice(2)}`;
+}
+
+function sleep(ms, signal) {
+  return new Promise((resolve, reject) => {
+    if (signal?.aborted) return reject(signal.reason);
+    const timer = setTimeout(resolve, ms);
+    signal?.addEventListener(
+      "abort",
+      () => {
+        clearTimeout(timer);
+        reject(signal.reason);
+      },
+      { once: true },
+    );
+  });
+}
+
+/**
+ * Creates a client for one server.
+ * @param {import("./index").ClientOptions} options
+ */
+export function createClient({ baseUrl, retries = 3, backoff = 500, token, fetch: fetchImpl } = {}) {
+  const doFetch = fetchImpl ?? globalThis.fetch.bind(globalThis);
+  const root = (baseUrl ?? "").replace(/\/+$/, "");
+
+  async function attempt(url, body, key, signal) {
+    const headers = {
+      "Content-Type": "application/json",
+      Accept: "application/json",
+      [IDEMPOTENCY_HEADER]: key,
+    };
+    if (token) headers.Authorization = `Bearer ${token}`;
+
+    let res;
+    try {
+      res = await doFetch(url, { method: "POST", headers, body, signal });
+    } catch (err) {
+      if (signal?.aborted) throw err;
+      throw new SubmitError("Network error", { code: ErrorCodes.NETWORK_ERROR, cause: err });
+    }
+
+    const json = await res.json().catch(() => null);
+    if (!res.ok) {
+      const error = new SubmitError(json?.message || res.statusText || `HTTP ${res.status}`, {
+        status: res.status,
+        code: json?.code ?? "",
+      });
+      error.retryAfter = Number(res.headers.get("Retry-After")) * 1000 || 0;
+      throw error;
+    }
+    return { ...json?.data, replayed: res.headers.get("Idempotent-Replayed") === "true" };
+  }
+
+  /**
+   * Submits data to the form with the given public ID. The same idempotency key
+   * is sent on every retry, so a submission is stored at most once.
+   */
+  async function submit(formId, data, { idempotencyKey, shownAt, meta, signal } = {}) {
+    const fields = { ...data };
+    if (shownAt != null) fields[TIMING_FIELD] = shownAt instanceof Date ? shownAt.getTime() : shownAt;
+    const body = JSON.stringify(meta ? { data: fields, meta } : fields);
+    const url = `${root}/api/v1/submissions/${encodeURIComponent(formId)}`;
+    const key = idempotencyKey ?? newKey();
+
+    for (let n = 0, delay = backoff; ; n++, delay *= 2) {
+      try {
+        return await attempt(url, body, key, signal);
+      } catch (err) {
+        if (!(err instanceof SubmitError) || !err.retryable || n >= retries) throw err;
+        await sleep(Math.max(delay, err.retryAfter ?? 0), signal);
+      }
+    }
+  }
+
+  /**
+   * Submits an HTML form with fetch instead of a page load. Records when the form
+   * was bound for the timing check and adds a hidden honeypot field bots fill in.
+   * Returns a function that unbinds the form.
+   */
+  function bind(form, formId, { honeypot = HONEYPOT_FIELD, onSuccess, onError } = {}) {
+    const shownAt = Date.now();
+    if (honeypot && !form.elements.namedItem(honeypot)) {
+      const trap = document.createElement("input");
+      Object.assign(trap, { type: "text", name: honeypot, tabIndex: -1, autocomplete: "off" });
+      trap.setAttribute("aria-hidden", "true");
+      trap.style.cssText = "position:absolute;left:-10000px;width:1px;height:1px;overflow:hidden";
+      form.appendChild(trap);
+    }
+
+    let key = newKey();
+    async function onSubmit(event) {
+      event.preventDefault();
+      const data = {};
+      for (const [name, value] of new FormData(form)) {
+        if (typeof value === "string") data[name] = value;
+      }
+      try {
+        const submission = await submit(formId, data, { idempotencyKey: key, shownAt });
+        key = newKey(); // The next submission of this form is a new one
+        onSuccess?.(submission);
+      } catch (err) {
+        if (onError) onError(err);
+        else throw err;
+      }
+    }
+    form.addEventListener("submit", onSubmit);
+    return () => form.removeEventListener("submit", onSubmit);
+  }
+
+  return { submit, bind };
+}
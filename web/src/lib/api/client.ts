@@ -21,6 +21,8 @@ export interface PaginatedResponse<T> {
 		limit: number;
 		total: number;
 		total_pages: number;
+		next?: string;
+		prev?: string;
 	};
 }
 
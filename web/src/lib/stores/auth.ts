@@ -6,6 +6,7 @@ interface User {
   email: string;
   name: string;
   role: "super_admin" | "admin" | "user";
+  timezone?: string;
 }
 
 interface AuthState {
@@ -82,6 +83,35 @@ function createAuthStore() {
       return { success: false, message: json.message || "Login failed" };
     },
 
+    // Finishes an SSO sign-in: the callback sends the token in the URL fragment
+    loginWithToken: async (token: string) => {
+      const res = await fetch("/api/v1/auth/me", {
+        headers: { Authorization: `Bearer ${token}` },
+      });
+
+      const json = await res.json();
+
+      if (json.status === "success") {
+        const user = json.data;
+
+        if (browser) {
+          localStorage.setItem("auth_token", token);
+          localStorage.setItem("auth_user", JSON.stringify(user));
+        }
+
+        set({
+          user,
+          token,
+          isAuthenticated: true,
+          isLoading: false,
+        });
+
+        return { success: true };
+      }
+
+      return { success: false, message: json.message || "Sign-in failed" };
+    },
+
     register: async (email: string, password: string, name: string) => {
       const res = await fetch("/api/v1/auth/register", {
         method: "POST",